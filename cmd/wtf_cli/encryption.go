@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"wtf_cli/pkg/ai/auth"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/historysync"
+	"wtf_cli/pkg/sealed"
+
+	"golang.org/x/term"
+)
+
+// passphraseAttempts is how often a wrong passphrase may be entered at
+// startup before giving up.
+const passphraseAttempts = 3
+
+// sealedFiles are the files `wtf_cli encrypt-config` encrypts.
+func sealedFiles() []string {
+	return []string{config.GetConfigPath(), auth.DefaultAuthPath(), historysync.DefaultStatePath()}
+}
+
+// unlockSealedFiles unlocks sealed.Default for the encrypted files among
+// paths, prompting for the passphrase on the terminal or reading the key from
+// the OS keyring. It does nothing when no file is encrypted.
+func unlockSealedFiles(paths []string) error {
+	var method sealed.Method
+	var encrypted [][]byte
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if m, ok := sealed.MethodOf(data); ok {
+			method = m
+			encrypted = append(encrypted, data)
+		}
+	}
+	if len(encrypted) == 0 {
+		return nil
+	}
+
+	verify := func() error {
+		for _, data := range encrypted {
+			if _, err := sealed.Default.Open(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch method {
+	case sealed.MethodKeyring:
+		key, err := sealed.SystemKeyring.Get()
+		if err != nil {
+			return fmt.Errorf("config is encrypted with a keyring key: %w", err)
+		}
+		if err := sealed.Default.UnlockKey(key); err != nil {
+			return err
+		}
+		return verify()
+	case sealed.MethodPassphrase:
+		for attempt := 1; ; attempt++ {
+			passphrase, err := readPassphrase("Passphrase for wtf_cli config: ")
+			if err != nil {
+				return err
+			}
+			sealed.Default.UnlockPassphrase(passphrase)
+			err = verify()
+			if !errors.Is(err, sealed.ErrWrongSecret) || attempt == passphraseAttempts {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "Wrong passphrase, try again.")
+		}
+	default:
+		return fmt.Errorf("config is encrypted with an unknown method %q", method)
+	}
+}
+
+// readPassphrase prompts on the controlling terminal, so it works while
+// stdin carries piped command output.
+func readPassphrase(prompt string) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, errors.New("a terminal is needed to enter the passphrase")
+	}
+	defer tty.Close()
+	fmt.Fprint(tty, prompt)
+	passphrase, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+	if err != nil {
+		return nil, fmt.Errorf("could not read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+// runEncryptConfig encrypts config.json and auth.json in place with a
+// passphrase or a key kept in the OS keyring.
+func runEncryptConfig(args []string) int {
+	fs := flag.NewFlagSet("encrypt-config", flag.ContinueOnError)
+	useKeyring := fs.Bool("keyring", false, "keep a random key in the OS keyring instead of asking for a passphrase")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	plain, err := readSealedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	if len(plain) == 0 {
+		fmt.Fprintln(os.Stderr, "wtf_cli: nothing to encrypt; start wtf_cli once to create the config")
+		return 1
+	}
+
+	if *useKeyring {
+		key, err := sealed.NewKeyringKey()
+		if err == nil {
+			err = sealed.SystemKeyring.Set(key)
+		}
+		if err == nil {
+			err = sealed.Default.UnlockKey(key)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+			return 1
+		}
+	} else {
+		passphrase, err := readPassphrase("New passphrase: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+			return 1
+		}
+		if len(passphrase) == 0 {
+			fmt.Fprintln(os.Stderr, "wtf_cli: the passphrase must not be empty")
+			return 1
+		}
+		again, err := readPassphrase("Repeat passphrase: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+			return 1
+		}
+		if !bytes.Equal(passphrase, again) {
+			fmt.Fprintln(os.Stderr, "wtf_cli: the passphrases do not match; nothing changed")
+			return 1
+		}
+		sealed.Default.UnlockPassphrase(passphrase)
+	}
+
+	if err := writeSealedFiles(plain); err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	if *useKeyring {
+		fmt.Println("Encrypted with a key stored in the OS keyring.")
+	} else {
+		fmt.Println("Encrypted with your passphrase; wtf_cli asks for it once per start.")
+	}
+	return 0
+}
+
+// runDecryptConfig turns encrypted config.json and auth.json back into
+// plain files.
+func runDecryptConfig(args []string) int {
+	fs := flag.NewFlagSet("decrypt-config", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	plain, err := readSealedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	sealed.Default.Lock()
+	if err := writeSealedFiles(plain); err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	fmt.Println("Decrypted; the files are protected by their permissions (0600) only.")
+	return 0
+}
+
+// readSealedFiles unlocks and reads the existing sealedFiles by path.
+func readSealedFiles() (map[string][]byte, error) {
+	if err := unlockSealedFiles(sealedFiles()); err != nil {
+		return nil, err
+	}
+	plain := make(map[string][]byte)
+	for _, path := range sealedFiles() {
+		data, err := sealed.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		plain[path] = data
+	}
+	return plain, nil
+}
+
+func writeSealedFiles(plain map[string][]byte) error {
+	for path, data := range plain {
+		if err := sealed.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return nil
+}
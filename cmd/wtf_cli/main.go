@@ -1,18 +1,28 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	"wtf_cli/pkg/capture"
 	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/control"
+	"wtf_cli/pkg/hooks"
 	"wtf_cli/pkg/logging"
+	"wtf_cli/pkg/observe"
 	"wtf_cli/pkg/pty"
+	"wtf_cli/pkg/shellquote"
 	"wtf_cli/pkg/ui"
+	"wtf_cli/pkg/ui/styles"
 
-	// Import providers package to register all LLM providers via init()
-	_ "wtf_cli/pkg/ai/providers"
+	// The providers package registers all LLM providers via init()
+	"wtf_cli/pkg/ai/providers"
 
 	tea "charm.land/bubbletea/v2"
 )
@@ -23,12 +33,51 @@ func main() {
 		printVersion()
 		os.Exit(0)
 	}
+	if len(os.Args) > 1 {
+		if code, ok := runSubcommand(os.Args[1], os.Args[2:]); ok {
+			os.Exit(code)
+		}
+	}
+	os.Exit(runTUI("wtf_cli", os.Args[1:], false))
+}
+
+// runTUI parses the TUI flags in args and runs the terminal until it quits,
+// returning the process exit code. With exec, the arguments after the flags
+// are a command run in the PTY in place of the user's shell, and its exit
+// status is returned.
+func runTUI(name string, args []string, exec bool) int {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	share := fs.String("share", "", "publish this session on a Unix socket for `wtf_cli observe`")
+	controlPath := fs.String("control", "", "accept automation calls (JSON-RPC) on a Unix socket")
+	contextFile := fs.String("context-file", "", "preload a file, e.g. a captured log, into the terminal as context")
+	ask := fs.String("ask", "", "open the chat sidebar and ask this question once the shell is ready")
+	readOnly := fs.Bool("read-only", false, "keep keys, pastes and commands from the shell, for screen sharing")
+	_ = fs.Parse(args)
+	var command []string
+	if exec {
+		if command = fs.Args(); len(command) == 0 {
+			fmt.Fprintf(os.Stderr, "usage: %s [flags] -- <command> [args...]\n", name)
+			return 2
+		}
+	}
 
-	// Load configuration
+	// Read the imported context before the TUI takes over stdin
+	importName, importData, err := readImportedContext(*contextFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading context: %v\n", err)
+		return 1
+	}
+
+	// Unlock an encrypted config, then load it
+	if err := unlockSealedFiles(sealedFiles()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error unlocking config: %v\n", err)
+		return 1
+	}
+	autoMigrateLegacyConfig()
 	cfg, err := config.Load(config.GetConfigPath())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	if _, err := logging.Init(cfg); err != nil {
@@ -40,33 +89,172 @@ func main() {
 		"model", getModelForProvider(cfg),
 		"log_level", cfg.LogLevel,
 		"log_format", cfg.LogFormat,
+		"color_mode", cfg.ColorMode,
 		"log_file", cfg.LogFile,
 	)
 
-	// Spawn the shell in a PTY with buffer
-	wrapper, err := pty.SpawnShellWithBuffer(cfg.BufferSize)
+	// Spawn the shell, or the exec command, in a PTY with buffer
+	var wrapper *pty.BufferedWrapper
+	if len(command) > 0 {
+		wrapper, err = pty.SpawnCommandWithBuffer(cfg.BufferSize, command)
+	} else {
+		wrapper, err = pty.SpawnShellWithBuffer(cfg.BufferSize)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error spawning shell: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 	defer wrapper.Close()
 
+	// Resolve the terminal's color depth before any styles are copied into
+	// components, so limited terminals and NO_COLOR get a readable theme.
+	colorProfile := styles.DetectProfile(cfg.ColorMode, os.Stdout, os.Environ())
+	styles.SetProfile(colorProfile)
+
 	// Initialize session context
 	session := capture.NewSessionContext()
 
 	// Create Bubble Tea model with shell's cwd function
-	model := ui.NewModel(wrapper.GetPTY(), wrapper.GetBuffer(), session, wrapper.GetCwd)
+	model := ui.NewModelWithConfig(cfg, wrapper.GetPTY(), wrapper.GetBuffer(), session, wrapper.GetCwd)
+	model.SetEnvironFunc(wrapper.GetEnviron)
+	model.SetJobsFunc(wrapper.GetJobs)
+	model.SetHistoryAppendFunc(capture.AppendShellHistory)
+	if cfg.Sync.Enabled() {
+		model.SetSyncedHistoryFunc(syncedHistory)
+	}
+	if len(importData) > 0 {
+		model.ImportContext(importName, importData)
+	}
+	model.SetStartupQuestion(*ask)
+	model.SetReadOnly(*readOnly)
+	if len(command) > 0 {
+		model.SetExecCommand(shellquote.Join(command), wrapper.ExitCode)
+	}
+	model.SetPaneSpawner(func() (ui.PaneShell, error) {
+		pane, err := pty.SpawnShellWithBuffer(cfg.BufferSize)
+		if err != nil {
+			return ui.PaneShell{}, err
+		}
+		return ui.PaneShell{
+			PTY:     pane.GetPTY(),
+			Buffer:  pane.GetBuffer(),
+			Cwd:     pane.GetCwd,
+			Environ: pane.GetEnviron,
+			Jobs:    pane.GetJobs,
+			Process: pane,
+		}, nil
+	})
+
+	if *share != "" {
+		hub, err := observe.Listen(*share)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sharing session: %v\n", err)
+			return 1
+		}
+		defer hub.Close()
+		model.SetObserveHub(hub)
+	}
+
+	if *controlPath != "" {
+		srv, err := control.Listen(*controlPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening control socket: %v\n", err)
+			return 1
+		}
+		defer srv.Close()
+		model.SetControlServer(srv)
+	}
+
+	suspender := newSuspendForwarder()
+	if suspender != nil {
+		model.SetSuspendRequest(suspender.Request)
+	}
 
 	// Create Bubble Tea program
 	// Note: In v2, AltScreen is set via View.AltScreen in the Model's View() method
 	// Mouse events are handled in-app for scrollback/sidebar scrolling and text selection.
-	p := tea.NewProgram(model, tea.WithFilter(ui.MouseEventFilter))
+	p := tea.NewProgram(model, tea.WithFilter(ui.MouseEventFilter), tea.WithColorProfile(colorProfile))
+	suspender.Start(p)
+	defer providers.CloseCopilotPool()
+
+	// session_start runs beside the TUI so a slow hook does not hold up
+	// the prompt; session_end waits for it, then runs before wtf_cli exits.
+	sessionHooks := hooks.New(cfg.Hooks)
+	sessionDir, _ := os.Getwd()
+	sessionStart := time.Now()
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		sessionHooks.Run(hooks.Event{Event: config.HookEventSessionStart, Time: sessionStart, Dir: sessionDir, Command: shellquote.Join(command)})
+	}()
 
 	// Run the program
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if m, ok := final.(ui.Model); ok {
+		m.CloseShells()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+	if cfg.Sync.Enabled() {
+		recordSessionHistory(cfg.Sync, session)
+	}
+	<-started
+	end := hooks.Event{
+		Event:           config.HookEventSessionEnd,
+		Dir:             sessionDir,
+		DurationSeconds: time.Since(sessionStart).Round(time.Second).Seconds(),
+	}
+	if len(command) == 0 {
+		sessionHooks.Run(end)
+		return 0
+	}
+	// Quitting while the command runs hangs up on it, as closing a
+	// terminal would, and the status says so.
+	_ = wrapper.Close()
+	status := wrapper.ExitCode()
+	end.Command, end.ExitCode = shellquote.Join(command), &status
+	sessionHooks.Run(end)
+	return status
+}
+
+// maxImportedContextBytes bounds how much of an imported file is kept. The
+// tail is kept, since that is where a log usually shows what went wrong.
+const maxImportedContextBytes = 8 << 20
+
+// readImportedContext reads the file given with --context-file or, without
+// one, a file or pipe redirected to stdin (`wtf_cli < build.log`). It
+// returns no data when stdin is a terminal.
+func readImportedContext(path string) (string, []byte, error) {
+	var (
+		name = "stdin"
+		in   = os.Stdin
+	)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", nil, err
+		}
+		defer f.Close()
+		name, in = filepath.Base(path), f
+	} else {
+		info, err := os.Stdin.Stat()
+		if err != nil || !(info.Mode().IsRegular() || info.Mode()&os.ModeNamedPipe != 0) {
+			return "", nil, nil
+		}
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(data) > maxImportedContextBytes {
+		data = data[len(data)-maxImportedContextBytes:]
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
 	}
+	return name, data, nil
 }
 
 // getModelForProvider returns the model name for the currently selected provider
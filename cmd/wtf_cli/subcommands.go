@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/logging"
+	"wtf_cli/pkg/observe"
+	"wtf_cli/pkg/pty"
+	"wtf_cli/pkg/shellintegration"
+	"wtf_cli/pkg/ui"
+
+	tea "charm.land/bubbletea/v2"
+	"golang.org/x/term"
+)
+
+// runSubcommand handles the non-TUI entry points. It reports whether name was
+// a subcommand and the process exit code.
+func runSubcommand(name string, args []string) (int, bool) {
+	switch name {
+	case "explain":
+		return runExplain(args), true
+	case "exec":
+		return runTUI("wtf_cli exec", args, true), true
+	case "install-shell-integration":
+		return runInstallShellIntegration(args), true
+	case "observe":
+		return runObserve(args), true
+	case "migrate-config":
+		return runMigrateConfig(args), true
+	case "trust-init":
+		return runTrustInit(args), true
+	case "encrypt-config":
+		return runEncryptConfig(args), true
+	case "decrypt-config":
+		return runDecryptConfig(args), true
+	case "sync":
+		return runSync(args), true
+	case "config-env":
+		return runConfigEnv(args), true
+	}
+	return 0, false
+}
+
+// runExplain answers once for a command and its output read from stdin.
+func runExplain(args []string) int {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	command := fs.String("command", "", "command line to explain")
+	exitCode := fs.Int("exit-code", -1, "exit status of the command")
+	cwd := fs.String("cwd", "", "directory the command ran in (default: current directory)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := unlockSealedFiles(sealedFiles()); err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	autoMigrateLegacyConfig()
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	if _, err := logging.Init(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logger: %v\n", err)
+	}
+
+	dir := *cwd
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	var output io.Reader
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		output = os.Stdin
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = commands.RunOneShot(ctx, commands.OneShotRequest{
+		Command:    *command,
+		ExitCode:   *exitCode,
+		WorkingDir: dir,
+		Output:     output,
+	}, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runInstallShellIntegration writes the shell widget and sources it from the
+// user's rc file.
+func runInstallShellIntegration(args []string) int {
+	fs := flag.NewFlagSet("install-shell-integration", flag.ContinueOnError)
+	shell := fs.String("shell", "", "zsh or bash (default: from $SHELL)")
+	key := fs.String("key", shellintegration.DefaultKey, "key binding: alt+<letter> or ctrl+<letter>")
+	printOnly := fs.Bool("print", false, "print the widget instead of installing it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *shell == "" {
+		detected, err := shellintegration.DetectShell(os.Getenv("SHELL"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+			return 1
+		}
+		*shell = detected
+	}
+	binary, err := os.Executable()
+	if err != nil {
+		binary = "wtf_cli"
+	}
+
+	if *printOnly {
+		script, err := shellintegration.Script(*shell, *key, binary)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+			return 1
+		}
+		fmt.Print(script)
+		return 0
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: cannot find home directory: %v\n", err)
+		return 1
+	}
+	res, err := shellintegration.Install(shellintegration.Options{
+		Shell:   *shell,
+		Key:     *key,
+		Binary:  binary,
+		HomeDir: home,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Wrote %s\n", res.ScriptPath)
+	if res.RCUpdated {
+		fmt.Printf("Added a source line to %s\n", res.RCPath)
+	} else {
+		fmt.Printf("%s already sources the integration\n", res.RCPath)
+	}
+	fmt.Printf("Open a new %s (or source %s) and press %s to explain the last command.\n", *shell, res.RCPath, *key)
+	fmt.Println("Run `wtf-record` first to also send command output.")
+	return 0
+}
+
+// runObserve attaches a read-only view to a session started with --share.
+func runObserve(args []string) int {
+	fs := flag.NewFlagSet("observe", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: wtf_cli observe <socket>")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	conn, err := observe.Dial(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: cannot attach to %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	defer conn.Close()
+
+	if _, err := tea.NewProgram(ui.NewObserverModel(conn)).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runTrustInit allows a project's .wtf_init.sh to be sourced into shells
+// started in its directory, until the file changes.
+func runTrustInit(args []string) int {
+	fs := flag.NewFlagSet("trust-init", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wtf_cli trust-init [path]  (default: ./%s)\n", pty.ProjectInitScript)
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	path := pty.ProjectInitScript
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	script, err := pty.TrustInitScript(pty.DefaultTrustedInitPath(), path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Trusted %s (sha256 %s)\n", script.Path, script.SHA256[:12])
+	fmt.Println("It is sourced into shells wtf_cli starts in its directory until the file changes.")
+	return 0
+}
+
+// runConfigEnv lists the environment variables that override config
+// fields, marking those set. Values are not printed, as they may be keys.
+func runConfigEnv(args []string) int {
+	fs := flag.NewFlagSet("config-env", flag.ContinueOnError)
+	setOnly := fs.Bool("set", false, "list only the variables that are set")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	for _, path := range config.EnvPaths() {
+		name := config.EnvVar(path)
+		_, set := os.LookupEnv(name)
+		switch {
+		case set:
+			fmt.Printf("%s  (set, overrides %s)\n", name, path)
+		case !*setOnly:
+			fmt.Println(name)
+		}
+	}
+	return 0
+}
+
+// runMigrateConfig translates the legacy ~/.wtf/config.json into the current
+// config, showing what maps where and asking before it writes.
+func runMigrateConfig(args []string) int {
+	fs := flag.NewFlagSet("migrate-config", flag.ContinueOnError)
+	from := fs.String("from", config.LegacyConfigPath(), "legacy config to read")
+	to := fs.String("to", config.GetConfigPath(), "config file to write")
+	yes := fs.Bool("yes", false, "write without asking")
+	dryRun := fs.Bool("dry-run", false, "only report what would be migrated")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Unlock so an encrypted config stays encrypted when it is replaced.
+	if err := unlockSealedFiles([]string{*to}); err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	data, err := os.ReadFile(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: cannot read legacy config: %v\n", err)
+		return 1
+	}
+	m, err := config.MigrateLegacy(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	printMigrationReport(os.Stdout, *from, m)
+	if *dryRun {
+		return 0
+	}
+
+	if !*yes {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintln(os.Stderr, "wtf_cli: not a terminal; pass --yes to write the migrated config")
+			return 1
+		}
+		if _, err := os.Stat(*to); err == nil {
+			fmt.Printf("%s already exists and will be replaced (a backup is kept).\n", *to)
+		}
+		fmt.Printf("Write %s? [y/N] ", *to)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Nothing written.")
+			return 0
+		}
+	}
+
+	backups, err := config.WriteMigration(*from, *to, m)
+	for _, backup := range backups {
+		fmt.Printf("Backed up to %s\n", backup)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Wrote %s\n", *to)
+	return 0
+}
+
+func printMigrationReport(w io.Writer, from string, m config.Migration) {
+	fmt.Fprintf(w, "Migrating %s\n", from)
+	for _, f := range m.Mapped {
+		fmt.Fprintf(w, "  %s -> %s\n", f.From, f.To)
+	}
+	if len(m.Unmapped) > 0 {
+		fmt.Fprintln(w, "Not migrated:")
+		for _, f := range m.Unmapped {
+			fmt.Fprintf(w, "  %s (%s)\n", f.Name, f.Reason)
+		}
+	}
+}
+
+// autoMigrateLegacyConfig migrates the legacy config on the first run after
+// upgrading, before Load writes defaults over the user's settings.
+func autoMigrateLegacyConfig() {
+	from, to := config.LegacyConfigPath(), config.GetConfigPath()
+	if !config.NeedsLegacyMigration(from, to) {
+		return
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return
+	}
+	m, err := config.MigrateLegacy(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: found %s but could not migrate it: %v\n", from, err)
+		fmt.Fprintln(os.Stderr, "Run `wtf_cli migrate-config --dry-run` for details.")
+		return
+	}
+	printMigrationReport(os.Stderr, from, m)
+	backups, err := config.WriteMigration(from, to, m)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Migrated settings to %s (backup: %s)\n", to, strings.Join(backups, ", "))
+}
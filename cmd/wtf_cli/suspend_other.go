@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package main
+
+import tea "charm.land/bubbletea/v2"
+
+// suspendForwarder does nothing on platforms without job control.
+type suspendForwarder struct{}
+
+func newSuspendForwarder() *suspendForwarder { return nil }
+
+func (f *suspendForwarder) Request() {}
+
+func (f *suspendForwarder) Start(p *tea.Program) {}
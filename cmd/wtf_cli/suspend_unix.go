@@ -0,0 +1,76 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// suspendForwarder turns an external SIGTSTP (e.g. `kill -TSTP`) and Ctrl+Z
+// at the prompt into a Bubble Tea suspend, so the terminal is restored
+// before the process stops instead of being left in raw mode on the
+// alternate screen.
+//
+// While SIGTSTP is being caught it cannot stop the process, so the handler is
+// reset before suspending (Bubble Tea re-raises SIGTSTP to actually stop) and
+// re-armed once SIGCONT arrives. Both ways in go through that sequence;
+// returning tea.Suspend directly would leave the handler armed and the
+// process waiting for a SIGCONT that never comes.
+type suspendForwarder struct {
+	tstp     chan os.Signal
+	cont     chan os.Signal
+	requests chan struct{}
+	reset    func() // Stops catching SIGTSTP
+	notify   func() // Catches SIGTSTP again
+}
+
+func newSuspendForwarder() *suspendForwarder {
+	f := &suspendForwarder{
+		tstp:     make(chan os.Signal, 1),
+		cont:     make(chan os.Signal, 1),
+		requests: make(chan struct{}, 1),
+	}
+	f.reset = func() { signal.Reset(syscall.SIGTSTP) }
+	f.notify = func() { signal.Notify(f.tstp, syscall.SIGTSTP) }
+	f.notify()
+	signal.Notify(f.cont, syscall.SIGCONT)
+	return f
+}
+
+// Request suspends wtf_cli as SIGTSTP would. It does not block; a request
+// made while one is pending is dropped.
+func (f *suspendForwarder) Request() {
+	select {
+	case f.requests <- struct{}{}:
+	default:
+	}
+}
+
+// Start handles signals and requests, suspending p for each.
+func (f *suspendForwarder) Start(p *tea.Program) {
+	go f.run(p.Send)
+}
+
+func (f *suspendForwarder) run(send func(tea.Msg)) {
+	for {
+		select {
+		case <-f.tstp:
+			slog.Info("suspend_signal")
+		case <-f.requests:
+			slog.Info("suspend_request")
+		}
+		select {
+		case <-f.cont: // drop a SIGCONT left over from an unrelated stop
+		default:
+		}
+		f.reset()
+		send(tea.SuspendMsg{})
+		<-f.cont
+		f.notify()
+	}
+}
@@ -0,0 +1,56 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSuspendForwarder_RequestResetsSIGTSTP(t *testing.T) {
+	events := make(chan string, 8)
+	f := &suspendForwarder{
+		tstp:     make(chan os.Signal, 1),
+		cont:     make(chan os.Signal, 1),
+		requests: make(chan struct{}, 1),
+		reset:    func() { events <- "reset" },
+		notify:   func() { events <- "notify" },
+	}
+	go f.run(func(msg tea.Msg) {
+		if _, ok := msg.(tea.SuspendMsg); ok {
+			events <- "suspend"
+		}
+	})
+
+	next := func() string {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the forwarder")
+			return ""
+		}
+	}
+
+	f.Request()
+	got := []string{next(), next()}
+	if want := []string{"reset", "suspend"}; !slices.Equal(got, want) {
+		t.Fatalf("Ctrl+Z ran %v, want %v", got, want)
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("SIGTSTP handler touched before SIGCONT: %s", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	f.cont <- syscall.SIGCONT
+	if e := next(); e != "notify" {
+		t.Fatalf("after SIGCONT got %q, want notify", e)
+	}
+}
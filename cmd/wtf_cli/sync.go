@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/historysync"
+	"wtf_cli/pkg/logging"
+)
+
+// autoSyncTimeout bounds the sync run when wtf_cli exits, so an unreachable
+// remote does not hold the terminal.
+const autoSyncTimeout = 20 * time.Second
+
+// runSync merges this machine's history and scripts with the remote copy.
+func runSync(args []string) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := unlockSealedFiles(sealedFiles()); err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	if _, err := logging.Init(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logger: %v\n", err)
+	}
+
+	passphrase, err := historysync.Passphrase(cfg.Sync)
+	if errors.Is(err, historysync.ErrNoKey) {
+		passphrase, err = readPassphrase("Sync passphrase: ")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := syncHistory(ctx, cfg.Sync, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: sync failed: %v\n", err)
+		return 1
+	}
+	switch {
+	case result.FirstUpload:
+		fmt.Printf("Uploaded %d command(s) and %d script(s).\n", result.History, result.Scripts)
+	default:
+		fmt.Printf("Synced: %d command(s), %d new here; %d script(s).\n", result.History, result.NewHistory, result.Scripts)
+	}
+	return 0
+}
+
+// syncHistory runs one sync with the configured backend.
+func syncHistory(ctx context.Context, cfg config.SyncConfig, passphrase []byte) (historysync.Result, error) {
+	backend, err := historysync.NewBackend(cfg)
+	if err != nil {
+		return historysync.Result{}, err
+	}
+	syncer := &historysync.Syncer{
+		Backend:    backend,
+		Passphrase: passphrase,
+		StatePath:  historysync.DefaultStatePath(),
+		ScriptsDir: historysync.DefaultScriptsDir(),
+	}
+	return syncer.Sync(ctx)
+}
+
+// recordSessionHistory keeps the commands of the session that just ended for
+// the next sync, and syncs right away when sync.auto is set.
+func recordSessionHistory(cfg config.SyncConfig, session *capture.SessionContext) {
+	host, _ := os.Hostname()
+	var entries []historysync.Entry
+	for _, record := range session.GetHistory() {
+		entries = append(entries, historysync.Entry{Command: record.Command, Time: record.StartTime, Host: host})
+	}
+	if err := historysync.RecordHistory(historysync.DefaultStatePath(), entries); err != nil {
+		slog.Error("sync_record_error", "error", err)
+		return
+	}
+	if !cfg.Auto {
+		return
+	}
+
+	passphrase, err := historysync.Passphrase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtf_cli: history not synced: %v\n", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), autoSyncTimeout)
+	defer cancel()
+	result, err := syncHistory(ctx, cfg, passphrase)
+	if err != nil {
+		slog.Error("sync_error", "error", err)
+		fmt.Fprintf(os.Stderr, "wtf_cli: history not synced: %v\n", err)
+		return
+	}
+	slog.Info("sync_done", "history", result.History, "new", result.NewHistory, "scripts", result.Scripts)
+}
+
+// syncedHistory returns the commands of the local sync state as records for
+// the history picker, oldest first.
+func syncedHistory() []capture.CommandRecord {
+	state, err := historysync.LoadState(historysync.DefaultStatePath())
+	if err != nil {
+		slog.Warn("sync_state_load_error", "error", err)
+		return nil
+	}
+	records := make([]capture.CommandRecord, 0, len(state.History))
+	for _, e := range state.History {
+		records = append(records, capture.CommandRecord{Command: e.Command, StartTime: e.Time})
+	}
+	return records
+}
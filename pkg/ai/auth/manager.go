@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"wtf_cli/pkg/sealed"
 )
 
 // StoredCredentials holds authentication credentials for a provider.
@@ -162,7 +164,7 @@ func (m *AuthManager) ListProviders() []string {
 
 // loadStore reads the auth store from disk.
 func (m *AuthManager) loadStore() (*authStore, error) {
-	data, err := os.ReadFile(m.configPath)
+	data, err := sealed.ReadFile(m.configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &authStore{Credentials: make(map[string]StoredCredentials)}, nil
@@ -194,7 +196,7 @@ func (m *AuthManager) saveStore(store *authStore) error {
 		return fmt.Errorf("failed to marshal auth data: %w", err)
 	}
 
-	if err := os.WriteFile(m.configPath, data, 0600); err != nil {
+	if err := sealed.WriteFile(m.configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write auth file: %w", err)
 	}
 
@@ -4,4 +4,18 @@ package ai
 type ChatMessage struct {
 	Role    string // "user" | "assistant" | "system"
 	Content string
+	Pinned  bool // Kept in the request even when older history is trimmed
+	// Fresh marks a user message sent without the conversation before it;
+	// the request for it starts at this message.
+	Fresh bool
+	// Refusal is why the provider refused to answer, e.g. "content
+	// filter"; the content is its refusal. Empty for a normal answer.
+	Refusal string
+	// Dir is the shell's directory when an assistant message was started;
+	// the commands it suggests are meant to run there.
+	Dir string
+
+	// Reasoning is what the model streamed before answering. It is shown
+	// collapsed in the sidebar and never sent back to the model.
+	Reasoning string
 }
@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+const citationFieldDefinitions = "Output lines may start with their line number in the terminal, as in \"1204| text\"; the number is not part of the output. When your answer relies on a specific output line, cite it with its number in <line>...</line> tags, e.g. <line>1204</line>, so the user can jump to it."
+
+// numberLines prefixes each line of output with its line number in the
+// terminal, counting back from last, the number of the final line. The
+// "[truncated]" marker of truncated output is left unnumbered. output is
+// returned unchanged when last is not set.
+func numberLines(output string, last int, truncated bool) string {
+	if last <= 0 || output == "" {
+		return output
+	}
+	lines := strings.Split(output, "\n")
+	first := 0
+	if truncated && len(lines) > 0 && lines[0] == "[truncated]" {
+		first = 1
+	}
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		n := last - (len(lines) - 1 - i)
+		if i < first || n <= 0 {
+			sb.WriteString(line)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%d| %s", n, line))
+	}
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildContexts_NumberOutputLines(t *testing.T) {
+	lines := [][]byte{[]byte("$ make"), []byte("error: something failed")}
+	meta := TerminalMetadata{LastLine: 1204}
+
+	for name, ctx := range map[string]TerminalContext{
+		"explain": BuildTerminalContext(lines, meta),
+		"chat":    BuildChatContext(lines, meta),
+	} {
+		if !strings.Contains(ctx.UserPrompt, "1203| $ make\n1204| error: something failed") {
+			t.Errorf("%s: expected numbered output, got %q", name, ctx.UserPrompt)
+		}
+		if ctx.Output != "$ make\nerror: something failed" {
+			t.Errorf("%s: expected Output unnumbered, got %q", name, ctx.Output)
+		}
+		if !strings.Contains(ctx.SystemPrompt, "<line>1204</line>") {
+			t.Errorf("%s: expected citation instruction, got %q", name, ctx.SystemPrompt)
+		}
+	}
+}
+
+func TestNumberLines(t *testing.T) {
+	if got := numberLines("a\nb", 0, false); got != "a\nb" {
+		t.Fatalf("Expected output unchanged without LastLine, got %q", got)
+	}
+	if got, want := numberLines("[truncated]\nxx\ny", 40, true), "[truncated]\n39| xx\n40| y"; got != want {
+		t.Fatalf("numberLines() = %q, want %q", got, want)
+	}
+}
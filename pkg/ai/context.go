@@ -16,6 +16,25 @@ type TerminalMetadata struct {
 	WorkingDir  string
 	LastCommand string
 	ExitCode    int
+	Toolchain   ToolchainInfo
+	Project     ProjectInfo
+	Env         []EnvVar // Variables the user chose to share via /env
+	REPL        REPLSession
+	Jobs        []BackgroundJob
+	Pinned      []PinnedBlock
+	Workspace   WorkspaceIndex // Map of the project; empty when not indexed
+	LastLine    int            // Terminal line number of the last output line; 0 leaves lines unnumbered
+	System      SystemFacts
+
+	// MaxLines raises the cap on output lines above DefaultContextLines.
+	MaxLines int
+	// ContextSize explains how many lines were chosen, when that was
+	// decided from the output; see ContextSize.Describe.
+	ContextSize string
+
+	// Task is what the user asks about the output. Empty asks to explain it
+	// and fix any issues.
+	Task string
 }
 
 // TerminalContext contains the assembled prompts and output.
@@ -25,11 +44,14 @@ type TerminalContext struct {
 	Truncated    bool
 	SystemPrompt string
 	UserPrompt   string
+	// ErrorDomain is the kind of error found in Output (DomainPython, ...),
+	// or "" when none stands out.
+	ErrorDomain string
 }
 
 // BuildTerminalContext assembles prompts and sanitized output.
 func BuildTerminalContext(lines [][]byte, meta TerminalMetadata) TerminalContext {
-	limited := limitLines(lines, DefaultContextLines)
+	limited := limitLines(lines, max(DefaultContextLines, meta.MaxLines))
 	output := sanitizeLines(limited)
 	output, truncated := truncateOutput(output, DefaultContextBytes)
 
@@ -38,6 +60,7 @@ func BuildTerminalContext(lines [][]byte, meta TerminalMetadata) TerminalContext
 		LineCount:    len(limited),
 		Truncated:    truncated,
 		SystemPrompt: wtfSystemPrompt(),
+		ErrorDomain:  DetectErrorDomain(output, meta.LastCommand),
 	}
 	ctx.UserPrompt = buildUserPrompt(meta, ctx)
 
@@ -122,13 +145,18 @@ func truncateOutput(output string, maxBytes int) (string, bool) {
 func buildUserPrompt(meta TerminalMetadata, ctx TerminalContext) string {
 	workingDir := strings.TrimSpace(meta.WorkingDir)
 	lastCommand := strings.TrimSpace(meta.LastCommand)
-	output := ctx.Output
+	output := numberLines(ctx.Output, meta.LastLine, ctx.Truncated)
 	if strings.TrimSpace(output) == "" {
 		output = "<no output captured>"
 	}
 
+	task := strings.TrimSpace(meta.Task)
+	if task == "" {
+		task = "Please check user`s terminal output, and explain what's going on. Check if there are any issues and suggest fixes in that case."
+	}
+
 	var sb strings.Builder
-	sb.WriteString("Please check user`s terminal output, and explain what's going on. Check if there are any issues and suggest fixes in that case.\n")
+	sb.WriteString(task + "\n")
 	sb.WriteString("Terminal metadata (captured fields):\n")
 	if workingDir != "" {
 		sb.WriteString(fmt.Sprintf("cwd: %s\n", workingDir))
@@ -139,12 +167,38 @@ func buildUserPrompt(meta TerminalMetadata, ctx TerminalContext) string {
 	if meta.ExitCode >= 0 {
 		sb.WriteString(fmt.Sprintf("last_exit_code: %d\n", meta.ExitCode))
 	}
+	for _, line := range meta.REPL.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range jobsPromptLines(meta.Jobs) {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range meta.Toolchain.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range meta.Project.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range envPromptLines(meta.Env) {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range meta.System.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
 	sb.WriteString(fmt.Sprintf("output_lines: %d\n", ctx.LineCount))
+	if meta.ContextSize != "" {
+		sb.WriteString(fmt.Sprintf("context_size: %s\n", meta.ContextSize))
+	}
+	if ctx.ErrorDomain != "" {
+		sb.WriteString(fmt.Sprintf("error_domain: %s\n", ctx.ErrorDomain))
+	}
 	if ctx.Truncated {
 		sb.WriteString("note: output truncated\n")
 	}
 	sb.WriteString("\nRecent output (most recent lines, oldest -> newest):\n")
 	sb.WriteString(output)
+	sb.WriteString(pinnedBlocksPrompt(meta.Pinned))
+	sb.WriteString(meta.Workspace.Prompt())
 
 	return sb.String()
 }
@@ -166,6 +220,51 @@ func AppendToolInstructions(prompt string, tools []ToolDefinition) string {
 	return prompt + " " + instructions
 }
 
+// ResponseLanguageAuto is the picker entry for "answer in the language of
+// the question"; it is stored as an empty response_language.
+const ResponseLanguageAuto = "auto"
+
+// ResponseLanguageOptions are the languages offered by the /language picker.
+// response_language accepts any other language name as well.
+var ResponseLanguageOptions = []string{
+	ResponseLanguageAuto,
+	"English",
+	"Chinese",
+	"French",
+	"German",
+	"Italian",
+	"Japanese",
+	"Korean",
+	"Polish",
+	"Portuguese",
+	"Russian",
+	"Spanish",
+	"Turkish",
+	"Ukrainian",
+}
+
+// AppendLanguageInstruction asks the model to answer in language regardless
+// of the language of the terminal output. Returns prompt unchanged when
+// language is empty or ResponseLanguageAuto.
+func AppendLanguageInstruction(prompt, language string) string {
+	language = strings.TrimSpace(language)
+	if language == "" || strings.EqualFold(language, ResponseLanguageAuto) {
+		return prompt
+	}
+	return fmt.Sprintf("%s Always write your answer in %s, even when the terminal output, metadata, or question are in another language. "+
+		"Keep commands, file paths, and quoted output unchanged.", prompt, language)
+}
+
+const toolchainFieldDefinitions = "Toolchain fields, when present, describe the active environment: python_venv is the active virtualenv; node_version, go_version, and rust_toolchain are the versions pinned for the project; container names the container runtime the shell runs in. Tailor install and version advice to them."
+
+const projectFieldDefinitions = "project, when present, lists the detected project types (from package.json, go.mod, Cargo.toml, a Makefile or a compose file) rooted at project_root; project_tasks are commands defined by the project, such as npm scripts and Makefile targets. When suggesting how to build, test or run the project, prefer them over generic commands."
+
+const envFieldDefinitions = "env.* fields are environment variables the user chose to share; a value of " + RedactedEnvValue + " means the variable is set but its value is a secret."
+
+// quickReplyInstructions asks for the likely answers to a clarifying
+// question as <reply> tags, which the sidebar offers as quick replies.
+const quickReplyInstructions = "When you cannot answer without a choice only the user can make (e.g. which environment or which service), ask one short question and end your answer with the likely answers, each in <reply>...</reply> tags, e.g. <reply>staging</reply> <reply>production</reply>. Offer at most 9 short answers, and only when you need the choice."
+
 func wtfSystemPrompt() string {
 	platform := GetPlatformInfo()
 	return strings.Join([]string{
@@ -175,9 +274,17 @@ func wtfSystemPrompt() string {
 		"When suggesting CLI commands the user can run, wrap each command in <cmd>...</cmd> tags, e.g. <cmd>ls -la</cmd>. Only wrap safe, single-line shell commands. Do not wrap multi-line scripts, code snippets, or explanations.",
 		"If last_command is provided, focus on that command and its output first.",
 		"If a metadata field is missing, do not assume or invent it.",
-		"Field definitions: cwd is the current working directory; last_command is the most recent captured command; last_exit_code is the exit code for last_command; output_lines is the number of lines in the output block; output may be truncated when noted.",
+		"Field definitions: cwd is the current working directory; last_command is the most recent captured command; last_exit_code is the exit code for last_command; output_lines is the number of lines in the output block; output may be truncated when noted; error_domain, when present, is the kind of error detected in the output (python, go, javascript, kubectl or compiler).",
+		toolchainFieldDefinitions,
+		projectFieldDefinitions,
+		envFieldDefinitions,
+		systemFactsFieldDefinitions,
+		replFieldDefinitions,
+		jobsFieldDefinitions,
+		citationFieldDefinitions,
 		"Provide concise, actionable suggestions and likely causes.",
 		"If you need more information, ask focused questions.",
+		quickReplyInstructions,
 	}, " ")
 }
 
@@ -190,14 +297,21 @@ func chatSystemPrompt() string {
 		"When suggesting CLI commands the user can run, wrap each command in <cmd>...</cmd> tags, e.g. <cmd>ls -la</cmd>. Only wrap safe, single-line shell commands. Do not wrap multi-line scripts, code snippets, or explanations.",
 		"If a metadata field is missing, do not assume or invent it.",
 		"Field definitions: cwd is the current working directory; last_command is the most recent captured command; last_exit_code is the exit code for last_command; output_lines is the number of lines in the output block; output may be truncated when noted.",
+		toolchainFieldDefinitions,
+		projectFieldDefinitions,
+		envFieldDefinitions,
+		replFieldDefinitions,
+		jobsFieldDefinitions,
+		citationFieldDefinitions,
 		"Be concise and helpful. If you need more information, ask focused questions.",
+		quickReplyInstructions,
 	}, " ")
 }
 
 func buildChatUserPrompt(meta TerminalMetadata, ctx TerminalContext) string {
 	workingDir := strings.TrimSpace(meta.WorkingDir)
 	lastCommand := strings.TrimSpace(meta.LastCommand)
-	output := ctx.Output
+	output := numberLines(ctx.Output, meta.LastLine, ctx.Truncated)
 	if strings.TrimSpace(output) == "" {
 		output = "<no output captured>"
 	}
@@ -214,12 +328,29 @@ func buildChatUserPrompt(meta TerminalMetadata, ctx TerminalContext) string {
 	if meta.ExitCode >= 0 {
 		sb.WriteString(fmt.Sprintf("last_exit_code: %d\n", meta.ExitCode))
 	}
+	for _, line := range meta.REPL.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range jobsPromptLines(meta.Jobs) {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range meta.Toolchain.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range meta.Project.PromptLines() {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range envPromptLines(meta.Env) {
+		sb.WriteString(line + "\n")
+	}
 	sb.WriteString(fmt.Sprintf("output_lines: %d\n", ctx.LineCount))
 	if ctx.Truncated {
 		sb.WriteString("note: output truncated\n")
 	}
 	sb.WriteString("\nRecent output (most recent lines, oldest -> newest):\n")
 	sb.WriteString(output)
+	sb.WriteString(pinnedBlocksPrompt(meta.Pinned))
+	sb.WriteString(meta.Workspace.Prompt())
 
 	return sb.String()
 }
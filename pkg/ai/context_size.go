@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Output classes ChooseContextSize tells apart.
+const (
+	OutputTraceback  = "traceback"   // A stack trace or panic
+	OutputShortError = "short_error" // An error on the last few lines
+	OutputErrorLog   = "error_log"   // Errors spread through the output
+	OutputPlain      = "plain"       // No errors found
+)
+
+// ContextSize is how many lines of output to send, and why.
+type ContextSize struct {
+	Lines int
+	Class string
+	// ErrorLines counts the lines that look like errors in the lines
+	// considered.
+	ErrorLines int
+}
+
+// Describe renders the choice for the request, e.g.
+// "auto, 48 lines (traceback)".
+func (s ContextSize) Describe() string {
+	return fmt.Sprintf("auto, %d lines (%s)", s.Lines, strings.ReplaceAll(s.Class, "_", " "))
+}
+
+const (
+	// shortErrorSpan is how close to the end the first error must be for
+	// the output to count as a short error.
+	shortErrorSpan = 3
+	// errorLogDensity is the share of error lines, from the first error
+	// on, above which the whole range is an error log.
+	errorLogDensity = 0.25
+	// contextLeadLines are kept before a traceback or the first error, for
+	// the command and what it was doing.
+	contextLeadLines = 5
+)
+
+// tracebackStart matches the first line of a stack trace.
+var tracebackStart = regexp.MustCompile(`^(Traceback \(most recent call last\):|panic: |goroutine \d+ \[|fatal error: |Exception in thread |Unhandled exception|thread '.*' panicked at|stack backtrace:|\S+(Error|Exception)(: |$))`)
+
+// tracebackFrame matches a frame line of a stack trace.
+var tracebackFrame = regexp.MustCompile(`^\s+(at |File "|\S+\.go:\d+)|^\s+\d+: `)
+
+// noErrors matches summaries that report the absence of errors.
+var noErrors = regexp.MustCompile(`(?i)\b(0|no) (errors?|failures?)\b`)
+
+// errorLine matches lines that report a failure.
+var errorLine = regexp.MustCompile(`(?i)\b(error|errors|failed|failure|fatal|exception|panic|denied|not found|cannot|unable to|segmentation fault|no such file or directory)\b|\w(Error|Exception)\b|^E\d{3,}|^\s*✗`)
+
+// ChooseContextSize picks how many of the last lines to send, between
+// minLines and maxLines. A stack trace is sent whole, from a few lines
+// before it starts; an error on the last lines needs only minLines; errors
+// spread through the output get as many lines as they span.
+func ChooseContextSize(lines [][]byte, minLines, maxLines int) ContextSize {
+	if maxLines < minLines {
+		maxLines = minLines
+	}
+	window := limitLines(lines, maxLines)
+	text := make([]string, len(window))
+	for i, line := range window {
+		text[i] = stripANSICodes(string(line))
+	}
+
+	firstError, errorCount := -1, 0
+	traceStart, frames := -1, 0
+	for i, line := range text {
+		if errorLine.MatchString(line) && !noErrors.MatchString(line) {
+			errorCount++
+			if firstError < 0 {
+				firstError = i
+			}
+		}
+		if tracebackFrame.MatchString(line) {
+			frames++
+		}
+		if traceStart < 0 && tracebackStart.MatchString(line) {
+			traceStart = i
+		}
+	}
+
+	size := ContextSize{ErrorLines: errorCount}
+	switch {
+	case traceStart >= 0 && frames > 0:
+		size.Class = OutputTraceback
+		size.Lines = len(text) - traceStart + contextLeadLines
+	case frames >= shortErrorSpan:
+		// Frames without a recognised header: the trace started earlier.
+		size.Class = OutputTraceback
+		size.Lines = maxLines
+	case firstError < 0:
+		size.Class = OutputPlain
+		size.Lines = len(text)
+	case len(text)-firstError <= shortErrorSpan:
+		size.Class = OutputShortError
+		size.Lines = minLines
+	default:
+		span := len(text) - firstError
+		size.Class = OutputErrorLog
+		size.Lines = span + contextLeadLines
+		if float64(errorCount)/float64(span) >= errorLogDensity {
+			size.Lines = maxLines
+		}
+	}
+	size.Lines = min(max(size.Lines, minLines), maxLines)
+	return size
+}
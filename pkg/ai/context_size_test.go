@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func outputLines(text string) [][]byte {
+	var lines [][]byte
+	for _, l := range strings.Split(text, "\n") {
+		lines = append(lines, []byte(l))
+	}
+	return lines
+}
+
+func filler(n int) string {
+	var sb strings.Builder
+	for i := range n {
+		fmt.Fprintf(&sb, "compiling module %d\n", i)
+	}
+	return sb.String()
+}
+
+func TestChooseContextSize(t *testing.T) {
+	cases := []struct {
+		name      string
+		output    string
+		wantClass string
+		wantLines int
+	}{
+		{
+			name:      "one-line error",
+			output:    filler(150) + "$ cat missing.txt\ncat: missing.txt: No such file or directory",
+			wantClass: OutputShortError,
+			wantLines: 5,
+		},
+		{
+			name:      "error on the last line",
+			output:    filler(150) + "$ make\nmake: *** [all] Error 2",
+			wantClass: OutputShortError,
+			wantLines: 5,
+		},
+		{
+			name: "python traceback",
+			output: filler(150) + "Traceback (most recent call last):\n" +
+				"  File \"app.py\", line 3, in <module>\n    main()\n" +
+				"  File \"app.py\", line 2, in main\n    1/0\n" +
+				"ZeroDivisionError: division by zero",
+			wantClass: OutputTraceback,
+			wantLines: 6 + contextLeadLines,
+		},
+		{
+			name:      "go panic",
+			output:    filler(40) + "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/src/main.go:5 +0x25\nexit status 2",
+			wantClass: OutputTraceback,
+			wantLines: 6 + contextLeadLines,
+		},
+		{
+			name:      "sparse errors",
+			output:    "warning: deprecated\nerror: first\n" + filler(60) + "error: second",
+			wantClass: OutputErrorLog,
+			wantLines: 62 + contextLeadLines,
+		},
+		{
+			name:      "dense errors",
+			output:    filler(150) + strings.Repeat("error: bad value\nnote: here\n", 10),
+			wantClass: OutputErrorLog,
+			wantLines: 200,
+		},
+		{
+			name:      "clean summary",
+			output:    filler(10) + "Build succeeded: 0 errors, 0 warnings",
+			wantClass: OutputPlain,
+			wantLines: 11,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ChooseContextSize(outputLines(tc.output), 5, 200)
+			if got.Class != tc.wantClass || got.Lines != tc.wantLines {
+				t.Fatalf("ChooseContextSize() = %+v, want %s with %d lines", got, tc.wantClass, tc.wantLines)
+			}
+		})
+	}
+}
+
+func TestBuildTerminalContext_ContextSize(t *testing.T) {
+	lines := outputLines(filler(150))
+	ctx := BuildTerminalContext(lines, TerminalMetadata{ExitCode: -1, MaxLines: 150, ContextSize: "auto, 150 lines (plain)"})
+	if ctx.LineCount != 150 {
+		t.Fatalf("LineCount = %d, want MaxLines to lift the default cap", ctx.LineCount)
+	}
+	if !strings.Contains(ctx.UserPrompt, "context_size: auto, 150 lines (plain)\n") {
+		t.Fatalf("expected the choice in the prompt:\n%s", ctx.UserPrompt[:300])
+	}
+}
@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"wtf_cli/pkg/redact"
+)
+
+const (
+	envSelectionsFilename = "env_selections.json"
+
+	// RedactedEnvValue replaces the value of a secret variable in prompts.
+	RedactedEnvValue = "[REDACTED]"
+
+	// maxEnvValueLen bounds how much of one value is sent, so a huge PATH
+	// or exported JSON blob cannot crowd out the terminal output.
+	maxEnvValueLen = 300
+)
+
+// EnvVar is one variable of the shell's environment.
+type EnvVar struct {
+	Name  string
+	Value string
+	// Secret marks values that must never leave the machine.
+	Secret bool
+}
+
+// PromptValue returns the value as it may be sent to a model.
+func (v EnvVar) PromptValue() string {
+	if v.Secret {
+		return RedactedEnvValue
+	}
+	if runes := []rune(v.Value); len(runes) > maxEnvValueLen {
+		return string(runes[:maxEnvValueLen]) + "..."
+	}
+	return v.Value
+}
+
+// hiddenEnvVars are shell bookkeeping and display settings that say nothing
+// useful about the problem at hand.
+var hiddenEnvVars = map[string]bool{
+	"_":              true,
+	"OLDPWD":         true,
+	"SHLVL":          true,
+	"LS_COLORS":      true,
+	"LSCOLORS":       true,
+	"TERMCAP":        true,
+	"PS1":            true,
+	"PS2":            true,
+	"PS4":            true,
+	"PROMPT_COMMAND": true,
+	"HISTCONTROL":    true,
+	"HISTSIZE":       true,
+	"HISTFILESIZE":   true,
+	"LESSOPEN":       true,
+	"LESSCLOSE":      true,
+}
+
+// secretEnvWords are name segments (split on "_") that conventionally mark
+// a variable holding a credential, as in OPENAI_API_KEY or GH_TOKEN.
+var secretEnvWords = map[string]bool{
+	"KEY":         true,
+	"APIKEY":      true,
+	"TOKEN":       true,
+	"SECRET":      true,
+	"PASS":        true,
+	"PASSWORD":    true,
+	"PASSWD":      true,
+	"CREDENTIAL":  true,
+	"CREDENTIALS": true,
+	"AUTH":        true,
+	"COOKIE":      true,
+	"PRIVATE":     true,
+	"DSN":         true,
+}
+
+// urlUserinfo matches a password embedded in a URL, e.g. DATABASE_URL.
+var urlUserinfo = regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`)
+
+// ParseEnviron turns NAME=value entries, as read from /proc/<pid>/environ,
+// into a curated list sorted by name. Bookkeeping variables and exported
+// bash functions are dropped; credentials are kept but marked Secret.
+func ParseEnviron(entries []string) []EnvVar {
+	seen := map[string]bool{}
+	vars := make([]EnvVar, 0, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || seen[name] || hiddenEnvVars[name] || strings.HasPrefix(name, "BASH_FUNC_") {
+			continue
+		}
+		seen[name] = true
+		vars = append(vars, EnvVar{
+			Name:   name,
+			Value:  value,
+			Secret: isSecretEnv(name, value),
+		})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+func isSecretEnv(name, value string) bool {
+	for _, word := range strings.Split(strings.ToUpper(name), "_") {
+		if secretEnvWords[word] {
+			return true
+		}
+	}
+	return urlUserinfo.MatchString(value) || redact.Contains(value)
+}
+
+// SelectEnv returns the variables of vars whose names are in names.
+func SelectEnv(vars []EnvVar, names []string) []EnvVar {
+	var selected []EnvVar
+	for _, v := range vars {
+		if slices.Contains(names, v.Name) {
+			selected = append(selected, v)
+		}
+	}
+	return selected
+}
+
+// envPromptLines renders shared variables as metadata lines.
+func envPromptLines(vars []EnvVar) []string {
+	lines := make([]string, 0, len(vars))
+	for _, v := range vars {
+		lines = append(lines, fmt.Sprintf("env.%s: %s", v.Name, v.PromptValue()))
+	}
+	return lines
+}
+
+// ProjectRoot returns the directory environment selections are remembered
+// for: the nearest parent of dir holding a .git entry, or dir itself.
+func ProjectRoot(dir string) string {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return ""
+	}
+	dir = filepath.Clean(dir)
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// DefaultEnvSelectionsPath returns the default path for remembered
+// environment selections.
+func DefaultEnvSelectionsPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", envSelectionsFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", envSelectionsFilename)
+}
+
+// LoadEnvSelection returns the variable names chosen for project. A missing
+// or unreadable file means nothing is shared.
+func LoadEnvSelection(path, project string) []string {
+	selections, err := readEnvSelections(path)
+	if err != nil {
+		return nil
+	}
+	return selections[project]
+}
+
+// SaveEnvSelection remembers names for project, replacing its previous
+// choice. An empty selection forgets the project.
+func SaveEnvSelection(path, project string, names []string) error {
+	selections, err := readEnvSelections(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if selections == nil {
+		selections = map[string][]string{}
+	}
+	if len(names) == 0 {
+		delete(selections, project)
+	} else {
+		names = slices.Clone(names)
+		sort.Strings(names)
+		selections[project] = names
+	}
+
+	data, err := json.MarshalIndent(selections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal env selections: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create env selections directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write env selections: %w", err)
+	}
+	return nil
+}
+
+func readEnvSelections(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var selections map[string][]string
+	if err := json.Unmarshal(data, &selections); err != nil {
+		return nil, fmt.Errorf("parse env selections: %w", err)
+	}
+	return selections, nil
+}
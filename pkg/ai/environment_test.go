@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnviron_CuratesAndMarksSecrets(t *testing.T) {
+	vars := ParseEnviron([]string{
+		"PATH=/usr/bin",
+		"SHLVL=2",
+		"BASH_FUNC_foo%%=() {  echo; }",
+		"OPENAI_API_KEY=abc",
+		"DATABASE_URL=postgres://app:hunter2@db/app",
+		"NOTES=token=sk-abcdefghijklmnopqrstuvwxyz",
+		"XDG_SESSION_TYPE=wayland",
+		"GOPATH=/home/me/go",
+		"PATH=/ignored",
+		"malformed",
+	})
+
+	var names []string
+	secret := map[string]bool{}
+	for _, v := range vars {
+		names = append(names, v.Name)
+		secret[v.Name] = v.Secret
+	}
+	want := []string{"DATABASE_URL", "GOPATH", "NOTES", "OPENAI_API_KEY", "PATH", "XDG_SESSION_TYPE"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	for _, name := range []string{"DATABASE_URL", "NOTES", "OPENAI_API_KEY"} {
+		if !secret[name] {
+			t.Errorf("expected %s to be secret", name)
+		}
+	}
+	for _, name := range []string{"GOPATH", "PATH", "XDG_SESSION_TYPE"} {
+		if secret[name] {
+			t.Errorf("expected %s not to be secret", name)
+		}
+	}
+	if vars[4].Value != "/usr/bin" {
+		t.Errorf("expected first PATH to win, got %q", vars[4].Value)
+	}
+}
+
+func TestEnvVar_PromptValue(t *testing.T) {
+	if got := (EnvVar{Name: "GH_TOKEN", Value: "ghp_x", Secret: true}).PromptValue(); got != RedactedEnvValue {
+		t.Fatalf("expected redacted value, got %q", got)
+	}
+	long := EnvVar{Name: "BLOB", Value: strings.Repeat("x", maxEnvValueLen+10)}
+	if got := long.PromptValue(); len(got) != maxEnvValueLen+3 || !strings.HasSuffix(got, "...") {
+		t.Fatalf("expected truncated value, got %d bytes", len(got))
+	}
+}
+
+func TestBuildWtfMessages_IncludesSharedEnv(t *testing.T) {
+	meta := TerminalMetadata{ExitCode: -1, Env: []EnvVar{
+		{Name: "GOFLAGS", Value: "-mod=vendor"},
+		{Name: "GH_TOKEN", Value: "ghp_x", Secret: true},
+	}}
+	messages, ctx := BuildWtfMessages(nil, meta)
+	if !strings.Contains(ctx.UserPrompt, "env.GOFLAGS: -mod=vendor\n") {
+		t.Fatalf("expected shared variable in prompt, got %q", ctx.UserPrompt)
+	}
+	if !strings.Contains(ctx.UserPrompt, "env.GH_TOKEN: "+RedactedEnvValue) || strings.Contains(ctx.UserPrompt, "ghp_x") {
+		t.Fatalf("expected secret to be redacted, got %q", ctx.UserPrompt)
+	}
+	if !strings.Contains(messages[0].Content, "env.* fields") {
+		t.Fatal("expected env field definitions in system prompt")
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ProjectRoot(sub); got != root {
+		t.Fatalf("ProjectRoot(%q) = %q, want %q", sub, got, root)
+	}
+
+	plain := t.TempDir()
+	if got := ProjectRoot(plain); got != plain {
+		t.Fatalf("expected dir without a repo to be its own project, got %q", got)
+	}
+}
+
+func TestEnvSelection_SaveAndLoadPerProject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env_selections.json")
+
+	if got := LoadEnvSelection(path, "/a"); got != nil {
+		t.Fatalf("expected no selection before save, got %v", got)
+	}
+	if err := SaveEnvSelection(path, "/a", []string{"PATH", "GOFLAGS"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveEnvSelection(path, "/b", []string{"NODE_ENV"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadEnvSelection(path, "/a"); !reflect.DeepEqual(got, []string{"GOFLAGS", "PATH"}) {
+		t.Fatalf("unexpected selection for /a: %v", got)
+	}
+
+	if err := SaveEnvSelection(path, "/a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadEnvSelection(path, "/a"); got != nil {
+		t.Fatalf("expected empty selection to forget /a, got %v", got)
+	}
+	if got := LoadEnvSelection(path, "/b"); !reflect.DeepEqual(got, []string{"NODE_ENV"}) {
+		t.Fatalf("expected /b to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Error domains DetectErrorDomain tells apart.
+const (
+	DomainPython     = "python"     // Python traceback or exception
+	DomainGo         = "go"         // Go panic or build error
+	DomainJavaScript = "javascript" // Node.js or browser stack
+	DomainKubernetes = "kubectl"    // kubectl or API server error
+	DomainCompiler   = "compiler"   // C, C++, Rust, Java or TypeScript compiler error
+)
+
+// errorDomainThreshold is the score the best domain needs; below it the
+// output gets the general prompt.
+const errorDomainThreshold = 2
+
+// errorDomainRule adds weight to domain for every line matching pattern.
+type errorDomainRule struct {
+	domain  string
+	pattern *regexp.Regexp
+	weight  int
+}
+
+var errorDomainRules = []errorDomainRule{
+	{DomainPython, regexp.MustCompile(`^Traceback \(most recent call last\):`), 3},
+	{DomainPython, regexp.MustCompile(`^\s+File ".+", line \d+`), 1},
+	{DomainPython, regexp.MustCompile(`^(\w+\.)*(ModuleNotFoundError|ImportError|IndentationError|AttributeError|KeyError|NameError|ValueError)\b`), 1},
+
+	{DomainGo, regexp.MustCompile(`^(panic: |goroutine \d+ \[|fatal error: all goroutines)`), 3},
+	{DomainGo, regexp.MustCompile(`\S+\.go:\d+`), 1},
+	{DomainGo, regexp.MustCompile(`^(go: |# [\w./-]+$)`), 1},
+
+	{DomainJavaScript, regexp.MustCompile(`^\s+at .+[(\s]\S+\.(m?js|cjs|jsx|tsx?):\d+:\d+\)?$`), 1},
+	{DomainJavaScript, regexp.MustCompile(`^(Uncaught )?(TypeError|ReferenceError|RangeError): |^npm (ERR!|error) |node:internal/`), 2},
+
+	{DomainKubernetes, regexp.MustCompile(`^Error from server( \(\w+\))?:|The connection to the server .+ was refused`), 3},
+	{DomainKubernetes, regexp.MustCompile(`\b(CrashLoopBackOff|ImagePullBackOff|ErrImagePull|OOMKilled|CreateContainerConfigError)\b`), 2},
+	{DomainKubernetes, regexp.MustCompile(`^error: .*\b(namespace|resource|context|kubeconfig|pods?|deployments?)\b`), 1},
+
+	{DomainCompiler, regexp.MustCompile(`^\S+\.(c|cc|cpp|cxx|h|hpp|m|java|kt|swift|cs|tsx?):\d+(:\d+)?: (fatal )?error`), 2},
+	{DomainCompiler, regexp.MustCompile(`^error(\[E\d+\])?: |^\s+--> \S+:\d+:\d+$|error TS\d+:`), 1},
+	{DomainCompiler, regexp.MustCompile(`undefined reference to|^collect2: |^ld: |linker command failed`), 2},
+}
+
+// errorDomainCommands adds a point to a domain when the last command is
+// one of its tools.
+var errorDomainCommands = map[string][]string{
+	DomainPython:     {"python", "python3", "pytest", "pip", "pip3", "poetry", "uv"},
+	DomainGo:         {"go"},
+	DomainJavaScript: {"node", "npm", "npx", "yarn", "pnpm", "deno", "bun"},
+	DomainKubernetes: {"kubectl", "helm", "oc"},
+	DomainCompiler:   {"gcc", "g++", "cc", "clang", "clang++", "rustc", "cargo", "javac", "tsc", "make", "cmake"},
+}
+
+// errorDomainOrder breaks ties, most specific first.
+var errorDomainOrder = []string{DomainPython, DomainGo, DomainJavaScript, DomainKubernetes, DomainCompiler}
+
+// DetectErrorDomain classifies the errors in output, helped by the command
+// that produced it. It returns "" when no domain stands out, which leaves
+// the general prompt alone.
+func DetectErrorDomain(output, lastCommand string) string {
+	scores := map[string]int{}
+	for line := range strings.SplitSeq(output, "\n") {
+		matched := map[string]bool{}
+		for _, rule := range errorDomainRules {
+			if matched[rule.domain] || !rule.pattern.MatchString(line) {
+				continue
+			}
+			matched[rule.domain] = true
+			scores[rule.domain] += rule.weight
+		}
+	}
+	if fields := strings.Fields(lastCommand); len(fields) > 0 && len(scores) > 0 {
+		tool := filepath.Base(fields[0])
+		for domain, tools := range errorDomainCommands {
+			if slices.Contains(tools, tool) {
+				scores[domain]++
+			}
+		}
+	}
+
+	best := ""
+	for _, domain := range errorDomainOrder {
+		if scores[domain] >= errorDomainThreshold && scores[domain] > scores[best] {
+			best = domain
+		}
+	}
+	return best
+}
+
+// errorDomainGuidance is what the system prompt adds for each domain.
+var errorDomainGuidance = map[string]string{
+	DomainPython:     "The output is a Python error. Read the traceback from the bottom: the last line is the exception and the last frame in the user's own code is usually where to fix it, not frames inside site-packages. For ImportError and ModuleNotFoundError, check which interpreter or virtualenv is active before suggesting pip install.",
+	DomainGo:         "The output is a Go error. For a panic, the first goroutine's trace shows where it happened: name the top frame in the user's module and explain the panic value (nil map or pointer, index out of range, closed channel). For build errors, fix the first reported error first; later ones often follow from it.",
+	DomainJavaScript: "The output is a JavaScript or Node.js error. Point to the first stack frame outside node_modules and node:internal. Consider missing or mismatched dependencies, ESM/CommonJS import mismatches and an unsupported Node version before code changes.",
+	DomainKubernetes: "The output is a Kubernetes or kubectl error. Tell apart client problems (kubeconfig, context, namespace, RBAC) from cluster state (pod status, events, image pulls, resource limits), and suggest read-only kubectl commands, such as describe, get events or logs --previous, to confirm the cause before changing anything.",
+	DomainCompiler:   "The output is a compiler or linker error. Fix the first error first, since later ones often cascade from it; quote its file and line. For linker errors, look for missing libraries, link order and declarations without definitions.",
+}
+
+// AppendErrorDomainGuidance adds advice for reading domain's errors to a
+// system prompt. Returns prompt unchanged when domain is empty or unknown.
+func AppendErrorDomainGuidance(prompt, domain string) string {
+	guidance, ok := errorDomainGuidance[domain]
+	if !ok {
+		return prompt
+	}
+	return prompt + " " + guidance
+}
@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectErrorDomain(t *testing.T) {
+	cases := []struct {
+		name    string
+		output  string
+		command string
+		want    string
+	}{
+		{
+			name: "python traceback",
+			output: "Traceback (most recent call last):\n" +
+				"  File \"/app/main.py\", line 3, in <module>\n" +
+				"    import requests\n" +
+				"ModuleNotFoundError: No module named 'requests'",
+			command: "python main.py",
+			want:    DomainPython,
+		},
+		{
+			name: "go panic",
+			output: "panic: assignment to entry in nil map\n\n" +
+				"goroutine 1 [running]:\n" +
+				"main.main()\n" +
+				"\t/home/me/app/main.go:8 +0x2c\n" +
+				"exit status 2",
+			command: "go run .",
+			want:    DomainGo,
+		},
+		{
+			name:    "go build error",
+			output:  "# example.com/app\n./main.go:12:3: undefined: foo\n./main.go:14:9: too many return values",
+			command: "go build ./...",
+			want:    DomainGo,
+		},
+		{
+			name: "node stack",
+			output: "/app/index.js:4\n" +
+				"TypeError: Cannot read properties of undefined (reading 'map')\n" +
+				"    at render (/app/index.js:4:18)\n" +
+				"    at Object.<anonymous> (/app/index.js:9:1)\n" +
+				"    at node:internal/main/run_main_module:28:49",
+			command: "node index.js",
+			want:    DomainJavaScript,
+		},
+		{
+			name:    "kubectl",
+			output:  "Error from server (NotFound): pods \"web-0\" not found",
+			command: "kubectl logs web-0",
+			want:    DomainKubernetes,
+		},
+		{
+			name:    "pod status",
+			output:  "NAME    READY   STATUS             RESTARTS   AGE\nweb-0   0/1     CrashLoopBackOff   5          3m",
+			command: "kubectl get pods",
+			want:    DomainKubernetes,
+		},
+		{
+			name:    "c compiler",
+			output:  "main.c:5:5: error: use of undeclared identifier 'x'\n    x = 1;\n    ^\n1 error generated.",
+			command: "clang main.c",
+			want:    DomainCompiler,
+		},
+		{
+			name:    "rust compiler",
+			output:  "error[E0425]: cannot find value `x` in this scope\n --> src/main.rs:2:5\n  |\n2 |     x\n  |     ^ not found in this scope",
+			command: "cargo build",
+			want:    DomainCompiler,
+		},
+		{
+			name:    "linker",
+			output:  "/usr/bin/ld: main.o: in function `main':\nmain.c:(.text+0x5): undefined reference to `helper'\ncollect2: error: ld returned 1 exit status",
+			command: "gcc main.c",
+			want:    DomainCompiler,
+		},
+		{
+			name:    "plain error",
+			output:  "cat: missing.txt: No such file or directory",
+			command: "cat missing.txt",
+			want:    "",
+		},
+		{
+			name:    "tool without errors",
+			output:  "ok  \texample.com/app\t0.012s",
+			command: "go test ./...",
+			want:    "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectErrorDomain(tc.output, tc.command); got != tc.want {
+				t.Fatalf("DetectErrorDomain() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildWtfMessages_ErrorDomain(t *testing.T) {
+	lines := outputLines("panic: runtime error: index out of range [3] with length 3\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:6 +0x1d")
+	_, ctx := BuildWtfMessages(lines, TerminalMetadata{LastCommand: "go run .", ExitCode: 2})
+	if ctx.ErrorDomain != DomainGo {
+		t.Fatalf("ErrorDomain = %q, want %q", ctx.ErrorDomain, DomainGo)
+	}
+	if !strings.Contains(ctx.UserPrompt, "error_domain: go\n") {
+		t.Fatalf("user prompt is missing error_domain:\n%s", ctx.UserPrompt)
+	}
+
+	prompt := AppendErrorDomainGuidance("Base.", ctx.ErrorDomain)
+	if !strings.HasPrefix(prompt, "Base. The output is a Go error.") {
+		t.Fatalf("AppendErrorDomainGuidance() = %q", prompt)
+	}
+	if got := AppendErrorDomainGuidance("Base.", ""); got != "Base." {
+		t.Fatalf("expected the prompt unchanged without a domain, got %q", got)
+	}
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const feedbackFilename = "feedback.jsonl"
+
+// FeedbackEntry records an answer the user flagged as bad, kept locally so
+// prompts can be tuned against real failures.
+type FeedbackEntry struct {
+	Time     time.Time `json:"time"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Question string    `json:"question"`
+	Answer   string    `json:"answer"`
+}
+
+// DefaultFeedbackPath returns the default path for the feedback log.
+func DefaultFeedbackPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", feedbackFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", feedbackFilename)
+}
+
+// AppendFeedback appends entry as one JSON line to the feedback log at path.
+func AppendFeedback(path string, entry FeedbackEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal feedback: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create feedback directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open feedback log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write feedback: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendFeedback_WritesOneLinePerEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "feedback.jsonl")
+
+	for _, answer := range []string{"first", "second"} {
+		if err := AppendFeedback(path, FeedbackEntry{Question: "why?", Answer: answer, Model: "m"}); err != nil {
+			t.Fatalf("AppendFeedback() error: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open feedback log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []FeedbackEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry FeedbackEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[1].Answer != "second" || entries[1].Time.IsZero() {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected 0600 permissions, got %o", perm)
+	}
+}
@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// Providers, model fetchers and the prewarmer build their HTTP clients with
+// NewHTTPClient. Without a client certificate or proxy a client uses
+// http.DefaultTransport, so they all share one connection pool. With
+// either, the client uses a transport shared by every client with the same
+// certificate and proxy, which keeps pre-warmed connections usable.
+
+var (
+	transportsMu sync.Mutex
+	transports   = map[config.ProviderTransport]*http.Transport{}
+)
+
+// NewHTTPClient returns a client with the given timeout that presents the
+// client certificate and goes through the proxy of transport. It fails when
+// the certificate or key cannot be loaded or the proxy URL cannot be
+// parsed, so a broken setting shows up before the first request.
+func NewHTTPClient(timeout time.Duration, transport config.ProviderTransport) (*http.Client, error) {
+	client := &http.Client{Timeout: timeout}
+	if transport.IsZero() {
+		return client, nil
+	}
+	cert := transport.ClientCert
+	if !cert.IsZero() {
+		if _, err := LoadClientCert(cert); err != nil {
+			return nil, err
+		}
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	t, ok := transports[transport]
+	if !ok {
+		t = http.DefaultTransport.(*http.Transport).Clone()
+		if !cert.IsZero() {
+			t.TLSClientConfig = &tls.Config{
+				// Loaded on each handshake, so a renewed certificate is used
+				// without restarting.
+				GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return LoadClientCert(cert)
+				},
+			}
+		}
+		if err := applyProxy(t, transport.Proxy); err != nil {
+			return nil, err
+		}
+		transports[transport] = t
+	}
+	client.Transport = t
+	return client, nil
+}
+
+// applyProxy sends t's connections through proxy. net/http speaks HTTP and
+// SOCKS5 proxies itself; an SSH tunnel replaces the dialer.
+func applyProxy(t *http.Transport, proxy config.ProxyConfig) error {
+	if rawURL := strings.TrimSpace(proxy.URL); rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("proxy url: %w", err)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if target := strings.TrimSpace(proxy.SSHTunnel); target != "" {
+		tunnel := sshTunnelFor(target)
+		t.Proxy = nil // The jump host makes the connections, not a proxy
+		t.DialContext = tunnel.DialContext
+	}
+	return nil
+}
+
+// LoadClientCert reads the certificate and key of cert. The returned
+// certificate's Leaf is the first certificate in the file.
+func LoadClientCert(cert config.ClientCertConfig) (*tls.Certificate, error) {
+	pair, err := tls.LoadX509KeyPair(expandHome(cert.CertFile), expandHome(cert.KeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("load client certificate %s: %w", cert.CertFile, err)
+	}
+	return &pair, nil
+}
+
+// ActiveTransport returns the client certificate and proxy configured for
+// cfg's active provider.
+func ActiveTransport(cfg config.Config) config.ProviderTransport {
+	switch cfg.LLMProvider {
+	case string(ProviderOpenAI):
+		return cfg.Providers.OpenAI.Transport()
+	case string(ProviderAnthropic):
+		return cfg.Providers.Anthropic.Transport()
+	case string(ProviderGoogle):
+		return cfg.Providers.Google.Transport()
+	case string(ProviderCopilot):
+		return config.ProviderTransport{}
+	default:
+		return cfg.OpenRouter.Transport()
+	}
+}
+
+// expandHome replaces a leading "~/" with the home directory.
+func expandHome(path string) string {
+	path = strings.TrimSpace(path)
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
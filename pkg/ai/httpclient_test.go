@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to
+// dir.
+func writeClientCert(t *testing.T, dir string) config.ClientCertConfig {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "wtf-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := config.ClientCertConfig{CertFile: filepath.Join(dir, "client.pem"), KeyFile: filepath.Join(dir, "client.key")}
+	if err := os.WriteFile(cert.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cert.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestNewHTTPClient_PresentsClientCert(t *testing.T) {
+	cert := writeClientCert(t, t.TempDir())
+
+	var gotCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			gotCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	client, err := NewHTTPClient(5*time.Second, config.ProviderTransport{ClientCert: cert})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error: %v", err)
+	}
+	// Trust the test server like the shared transport trusts real gateways.
+	client.Transport.(*http.Transport).TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	resp.Body.Close()
+	if gotCN != "wtf-test-client" {
+		t.Fatalf("server saw client certificate %q", gotCN)
+	}
+
+	again, err := NewHTTPClient(time.Second, config.ProviderTransport{ClientCert: cert})
+	if err != nil || again.Transport != client.Transport {
+		t.Fatal("expected clients with the same certificate to share a transport")
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client, err := NewHTTPClient(time.Second, config.ProviderTransport{})
+	if err != nil || client.Transport != nil {
+		t.Fatalf("expected the default transport without a certificate, got %v, %v", client.Transport, err)
+	}
+
+	missing := config.ClientCertConfig{CertFile: filepath.Join(t.TempDir(), "none.pem"), KeyFile: "none.key"}
+	if _, err := NewHTTPClient(time.Second, config.ProviderTransport{ClientCert: missing}); err == nil {
+		t.Fatal("expected an error for a missing certificate")
+	}
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var gotURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String() // Absolute when sent to a proxy
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(5*time.Second, config.ProviderTransport{Proxy: config.ProxyConfig{URL: proxy.URL}})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error: %v", err)
+	}
+	resp, err := client.Get("http://llm.internal.example/v1/models")
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	resp.Body.Close()
+	if gotURL != "http://llm.internal.example/v1/models" {
+		t.Fatalf("proxy saw %q", gotURL)
+	}
+}
+
+func TestNewHTTPClient_SSHTunnel(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	client, err := NewHTTPClient(5*time.Second, config.ProviderTransport{Proxy: config.ProxyConfig{SSHTunnel: "me@127.0.0.1:1"}})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error: %v", err)
+	}
+	_, err = client.Get("http://llm.internal.example/v1/models")
+	if err == nil || !strings.Contains(err.Error(), "ssh tunnel me@127.0.0.1:1: no ssh agent") {
+		t.Fatalf("expected the request to go through the tunnel, got %v", err)
+	}
+}
+
+func TestActiveTransport(t *testing.T) {
+	cfg := config.Default()
+	cfg.LLMProvider = string(ProviderAnthropic)
+	cfg.Providers.Anthropic.ClientCert = config.ClientCertConfig{CertFile: "a.pem", KeyFile: "a.key"}
+	cfg.Providers.Anthropic.Proxy = config.ProxyConfig{URL: "socks5://proxy:1080"}
+	if got := ActiveTransport(cfg); got.ClientCert.CertFile != "a.pem" || got.Proxy.URL != "socks5://proxy:1080" {
+		t.Fatalf("ActiveTransport() = %+v", got)
+	}
+	cfg.LLMProvider = string(ProviderCopilot)
+	if got := ActiveTransport(cfg); !got.IsZero() {
+		t.Fatalf("expected no transport settings for copilot, got %+v", got)
+	}
+}
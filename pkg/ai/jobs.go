@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"wtf_cli/pkg/redact"
+)
+
+const (
+	// maxPromptJobs bounds the background jobs listed in the metadata.
+	maxPromptJobs = 10
+	// maxJobCommandLen bounds one job's command line in the metadata.
+	maxJobCommandLen = 200
+)
+
+// BackgroundJob is a job the user's shell runs in the background, e.g. a
+// dev server started with & or an editor suspended with Ctrl+Z.
+type BackgroundJob struct {
+	PID     int // Process group leader
+	Command string
+	Stopped bool
+}
+
+// jobsPromptLines renders jobs as "background_job: ..." metadata lines, with
+// secrets in their command lines redacted.
+func jobsPromptLines(jobs []BackgroundJob) []string {
+	lines := make([]string, 0, min(len(jobs), maxPromptJobs))
+	for i, job := range jobs {
+		if i == maxPromptJobs {
+			lines = append(lines, fmt.Sprintf("background_jobs_omitted: %d", len(jobs)-maxPromptJobs))
+			break
+		}
+		command := redact.Redact(strings.Join(strings.Fields(job.Command), " "))
+		if runes := []rune(command); len(runes) > maxJobCommandLen {
+			command = string(runes[:maxJobCommandLen]) + "..."
+		}
+		state := "running"
+		if job.Stopped {
+			state = "stopped"
+		}
+		lines = append(lines, fmt.Sprintf("background_job: pid=%d %s %s", job.PID, state, command))
+	}
+	return lines
+}
+
+const jobsFieldDefinitions = "background_job lines are jobs the user's shell runs in the background (started with & or suspended with Ctrl+Z), with the PID of their process group, whether they are running or stopped, and their command line; they may hold ports, locks or files that explain errors in the foreground."
@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// ActiveAPIURL returns the api_url of cfg's active provider, or "" for
+// providers without one.
+func ActiveAPIURL(cfg config.Config) string {
+	switch cfg.LLMProvider {
+	case string(ProviderOpenAI):
+		return cfg.Providers.OpenAI.APIURL
+	case string(ProviderAnthropic):
+		return cfg.Providers.Anthropic.APIURL
+	case string(ProviderCopilot), string(ProviderGoogle):
+		return ""
+	default:
+		return cfg.OpenRouter.APIURL
+	}
+}
+
+// IsLocalEndpoint reports whether apiURL points at this machine.
+func IsLocalEndpoint(apiURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(apiURL))
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// UsesLocalModel reports whether cfg's active provider is served from this
+// machine, e.g. by Ollama behind its OpenAI-compatible API.
+func UsesLocalModel(cfg config.Config) bool {
+	return IsLocalEndpoint(ActiveAPIURL(cfg))
+}
+
+// ContextLines returns how many terminal lines to send with a request:
+// local_models.context_lines for local models when set, otherwise
+// DefaultContextLines.
+func ContextLines(cfg config.Config) int {
+	if n := cfg.LocalModels.ContextLines; n > 0 && n < DefaultContextLines && UsesLocalModel(cfg) {
+		return n
+	}
+	return DefaultContextLines
+}
+
+// localModelFailures maps error text of local model servers to what the user
+// can do about it.
+var localModelFailures = []struct {
+	markers []string
+	hint    string
+}{
+	{
+		[]string{"out of memory", "requires more system memory", "insufficient memory", "cudamalloc failed"},
+		"the model does not fit in memory; try a smaller or more quantized model, or close other GPU/CPU-heavy programs",
+	},
+	{
+		[]string{"failed to load model", "error loading model", "llama runner process has terminated", "unable to load model"},
+		"the server could not load the model; check its logs, the model file and available memory",
+	},
+	{
+		[]string{"not found, try pulling", "model not found", "no such model"},
+		"the model is not installed on the server; pull it first (e.g. `ollama pull <model>`)",
+	},
+	{
+		[]string{"connection refused"},
+		"the local model server is not running; start it (e.g. `ollama serve`)",
+	},
+}
+
+// LocalModelHint explains a failure of a local model server, or returns "".
+func LocalModelHint(err error) string {
+	if err == nil {
+		return ""
+	}
+	text := strings.ToLower(err.Error())
+	for _, f := range localModelFailures {
+		for _, marker := range f.markers {
+			if strings.Contains(text, marker) {
+				return f.hint
+			}
+		}
+	}
+	return ""
+}
+
+// ExplainLocalModelError adds LocalModelHint to err when there is one.
+func ExplainLocalModelError(err error) error {
+	if hint := LocalModelHint(err); hint != "" {
+		return fmt.Errorf("%w (%s)", err, hint)
+	}
+	return err
+}
+
+// WarmupProgress describes the load of a local model.
+type WarmupProgress struct {
+	Model   string
+	Loading bool
+	Elapsed time.Duration
+	Err     error
+}
+
+// LocalWarmup loads a local model ahead of the first request, so the load
+// shows up as progress rather than a slow first answer. Requests made while
+// it runs wait for it with Wait.
+type LocalWarmup struct {
+	mu      sync.Mutex
+	model   string
+	started time.Time
+	elapsed time.Duration
+	done    chan struct{}
+	err     error
+
+	now func() time.Time
+}
+
+// DefaultLocalWarmup is the warmup shared by the UI and the AI handlers.
+var DefaultLocalWarmup = &LocalWarmup{}
+
+// Start loads model with a one-token request unless it is already loading
+// or loaded; a failed load is retried. It reports whether a load was started.
+func (w *LocalWarmup) Start(provider Provider, model string, timeout time.Duration) bool {
+	w.mu.Lock()
+	if w.model == model && w.done != nil && w.err == nil {
+		w.mu.Unlock()
+		return false
+	}
+	w.model = model
+	w.started = w.clock()
+	w.elapsed = 0
+	w.err = nil
+	done := make(chan struct{})
+	w.done = done
+	w.mu.Unlock()
+
+	slog.Info("local_model_load_start", "model", model, "timeout_seconds", int(timeout.Seconds()))
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		maxTokens := 1
+		_, err := provider.CreateChatCompletion(ctx, ChatRequest{
+			Model:     model,
+			Messages:  []Message{{Role: "user", Content: "ping"}},
+			MaxTokens: &maxTokens,
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("model did not load within %s", timeout)
+		}
+		w.finish(done, ExplainLocalModelError(err))
+	}()
+	return true
+}
+
+func (w *LocalWarmup) finish(done chan struct{}, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	close(done)
+	if w.done != done {
+		return // A newer Start replaced this load
+	}
+	w.elapsed = w.clock().Sub(w.started)
+	w.err = err
+	if err != nil {
+		slog.Error("local_model_load_error", "model", w.model, "elapsed_ms", w.elapsed.Milliseconds(), "error", err)
+		return
+	}
+	slog.Info("local_model_load_done", "model", w.model, "elapsed_ms", w.elapsed.Milliseconds())
+}
+
+// Wait blocks until the current load finishes or ctx ends. It returns at
+// once when no load was started.
+func (w *LocalWarmup) Wait(ctx context.Context) error {
+	w.mu.Lock()
+	done := w.done
+	w.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Progress returns the state of the current load.
+func (w *LocalWarmup) Progress() WarmupProgress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p := WarmupProgress{Model: w.model, Elapsed: w.elapsed, Err: w.err}
+	if w.done == nil {
+		return p
+	}
+	select {
+	case <-w.done:
+	default:
+		p.Loading = true
+		p.Elapsed = w.clock().Sub(w.started)
+	}
+	return p
+}
+
+func (w *LocalWarmup) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
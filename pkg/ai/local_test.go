@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// warmupProvider answers completions after release is closed, with err.
+type warmupProvider struct {
+	release chan struct{}
+	err     error
+	calls   int
+}
+
+func (p *warmupProvider) CreateChatCompletion(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	p.calls++
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return ChatResponse{}, ctx.Err()
+	}
+	return ChatResponse{}, p.err
+}
+
+func (p *warmupProvider) CreateChatCompletionStream(ctx context.Context, req ChatRequest) (ChatStream, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *warmupProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{}
+}
+
+func TestIsLocalEndpoint(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"http://127.0.0.1:8080/v1", true},
+		{"http://[::1]:11434/v1", true},
+		{"https://openrouter.ai/api/v1", false},
+		{"http://192.168.1.20:11434/v1", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsLocalEndpoint(tt.url); got != tt.want {
+			t.Errorf("IsLocalEndpoint(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestContextLines(t *testing.T) {
+	cfg := config.Default()
+	cfg.LocalModels.ContextLines = 30
+	if got := ContextLines(cfg); got != DefaultContextLines {
+		t.Fatalf("ContextLines(remote) = %d, want %d", got, DefaultContextLines)
+	}
+
+	cfg.OpenRouter.APIURL = "http://localhost:11434/v1"
+	if got := ContextLines(cfg); got != 30 {
+		t.Fatalf("ContextLines(local) = %d, want 30", got)
+	}
+
+	cfg.LocalModels.ContextLines = 0
+	if got := ContextLines(cfg); got != DefaultContextLines {
+		t.Fatalf("ContextLines(local, unset) = %d, want %d", got, DefaultContextLines)
+	}
+}
+
+func TestLocalModelHint(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.New("model requires more system memory (12 GiB) than is available"), "does not fit in memory"},
+		{errors.New(`model "llama3" not found, try pulling it first`), "not installed"},
+		{errors.New("dial tcp 127.0.0.1:11434: connect: connection refused"), "not running"},
+		{errors.New("no room left in the zoom meeting"), ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		got := LocalModelHint(tt.err)
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("LocalModelHint(%v) = %q, want it to contain %q", tt.err, got, tt.want)
+		}
+	}
+	err := ExplainLocalModelError(errors.New("connection refused"))
+	if !strings.Contains(err.Error(), "connection refused (") {
+		t.Fatalf("ExplainLocalModelError() = %q, want the hint appended", err)
+	}
+}
+
+func TestLocalWarmup(t *testing.T) {
+	now := time.Unix(0, 0)
+	w := &LocalWarmup{now: func() time.Time { return now }}
+	if err := w.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() without a load error: %v", err)
+	}
+
+	provider := &warmupProvider{release: make(chan struct{})}
+	if !w.Start(provider, "llama3", time.Minute) {
+		t.Fatal("expected the first Start to begin a load")
+	}
+	if w.Start(provider, "llama3", time.Minute) {
+		t.Fatal("expected a second Start of the same model to be a no-op")
+	}
+	now = now.Add(3 * time.Second)
+	if p := w.Progress(); !p.Loading || p.Model != "llama3" || p.Elapsed != 3*time.Second {
+		t.Fatalf("unexpected progress while loading: %+v", p)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() while loading = %v, want deadline exceeded", err)
+	}
+
+	close(provider.release)
+	if err := w.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error: %v", err)
+	}
+	if p := w.Progress(); p.Loading || p.Err != nil || p.Elapsed != 3*time.Second {
+		t.Fatalf("unexpected progress after loading: %+v", p)
+	}
+}
+
+func TestLocalWarmup_RetriesFailedLoad(t *testing.T) {
+	w := &LocalWarmup{}
+	failing := &warmupProvider{release: make(chan struct{}), err: errors.New("llama runner process has terminated")}
+	close(failing.release)
+	w.Start(failing, "llama3", time.Minute)
+	_ = w.Wait(context.Background())
+	if p := w.Progress(); p.Err == nil || !strings.Contains(p.Err.Error(), "could not load the model") {
+		t.Fatalf("expected a load error with a hint, got %+v", p)
+	}
+
+	ok := &warmupProvider{release: make(chan struct{})}
+	close(ok.release)
+	if !w.Start(ok, "llama3", time.Minute) {
+		t.Fatal("expected a failed load to be retried")
+	}
+	_ = w.Wait(context.Background())
+	if p := w.Progress(); p.Err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", p.Err)
+	}
+}
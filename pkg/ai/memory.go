@@ -0,0 +1,146 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	memoriesFilename = "memories.json"
+
+	// MaxMemories bounds the facts remembered per project, so they cannot
+	// crowd out the terminal output in the system prompt.
+	MaxMemories = 50
+	// MaxMemoryLen bounds one fact, in characters.
+	MaxMemoryLen = 300
+)
+
+// Memory is a fact the user asked to remember for a project, such as "our
+// staging host is sg-02".
+type Memory struct {
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
+// DefaultMemoriesPath returns the default path for remembered facts.
+func DefaultMemoriesPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", memoriesFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", memoriesFilename)
+}
+
+// LoadMemories returns the facts remembered for project, oldest first. A
+// missing or unreadable file means nothing is remembered.
+func LoadMemories(path, project string) []Memory {
+	memories, err := readMemories(path)
+	if err != nil {
+		return nil
+	}
+	return memories[project]
+}
+
+// AddMemory remembers text for project. Repeating a remembered fact is not
+// an error; it is kept once.
+func AddMemory(path, project, text string) error {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return fmt.Errorf("nothing to remember")
+	}
+	if n := len([]rune(text)); n > MaxMemoryLen {
+		return fmt.Errorf("fact is %d characters; at most %d can be remembered", n, MaxMemoryLen)
+	}
+	return updateMemories(path, project, func(list []Memory) ([]Memory, error) {
+		for _, m := range list {
+			if strings.EqualFold(m.Text, text) {
+				return list, nil
+			}
+		}
+		if len(list) >= MaxMemories {
+			return nil, fmt.Errorf("at most %d facts can be remembered per project; forget some in /memories", MaxMemories)
+		}
+		return append(list, Memory{Text: text, Created: time.Now()}), nil
+	})
+}
+
+// ForgetMemory removes the i-th fact remembered for project, as listed by
+// LoadMemories.
+func ForgetMemory(path, project string, i int) error {
+	return updateMemories(path, project, func(list []Memory) ([]Memory, error) {
+		if i < 0 || i >= len(list) {
+			return nil, fmt.Errorf("no remembered fact %d", i+1)
+		}
+		return append(list[:i:i], list[i+1:]...), nil
+	})
+}
+
+// ForgetMemories removes every fact remembered for project.
+func ForgetMemories(path, project string) error {
+	return updateMemories(path, project, func([]Memory) ([]Memory, error) {
+		return nil, nil
+	})
+}
+
+func updateMemories(path, project string, update func([]Memory) ([]Memory, error)) error {
+	memories, err := readMemories(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if memories == nil {
+		memories = map[string][]Memory{}
+	}
+	list, err := update(memories[project])
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		delete(memories, project)
+	} else {
+		memories[project] = list
+	}
+
+	data, err := json.MarshalIndent(memories, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal memories: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create memories directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write memories: %w", err)
+	}
+	return nil
+}
+
+func readMemories(path string) (map[string][]Memory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var memories map[string][]Memory
+	if err := json.Unmarshal(data, &memories); err != nil {
+		return nil, fmt.Errorf("parse memories: %w", err)
+	}
+	return memories, nil
+}
+
+// AppendMemories adds the facts the user asked to remember to a system
+// prompt. Returns prompt unchanged when there are none.
+func AppendMemories(prompt string, memories []Memory) string {
+	if len(memories) == 0 {
+		return prompt
+	}
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\n\nFacts the user asked you to remember about this project; rely on them unless the terminal output contradicts them:")
+	for _, m := range memories {
+		sb.WriteString("\n- ")
+		sb.WriteString(m.Text)
+	}
+	return sb.String()
+}
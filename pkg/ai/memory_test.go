@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemories_AddForget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memories.json")
+
+	if got := LoadMemories(path, "/work/app"); got != nil {
+		t.Fatalf("missing file: got %v", got)
+	}
+	for _, text := range []string{"our staging host is sg-02", "  deploys go through   make release ", "Our staging host is SG-02"} {
+		if err := AddMemory(path, "/work/app", text); err != nil {
+			t.Fatalf("add %q: %v", text, err)
+		}
+	}
+	if err := AddMemory(path, "/work/other", "unrelated"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := LoadMemories(path, "/work/app")
+	if len(got) != 2 || got[0].Text != "our staging host is sg-02" || got[1].Text != "deploys go through make release" {
+		t.Fatalf("unexpected memories: %+v", got)
+	}
+
+	if err := ForgetMemory(path, "/work/app", 0); err != nil {
+		t.Fatalf("forget: %v", err)
+	}
+	if got := LoadMemories(path, "/work/app"); len(got) != 1 || got[0].Text != "deploys go through make release" {
+		t.Fatalf("after forget: %+v", got)
+	}
+	if err := ForgetMemory(path, "/work/app", 5); err == nil {
+		t.Fatal("expected an error for a missing fact")
+	}
+	if err := ForgetMemories(path, "/work/app"); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadMemories(path, "/work/app"); got != nil {
+		t.Fatalf("after forgetting all: %+v", got)
+	}
+	if got := LoadMemories(path, "/work/other"); len(got) != 1 {
+		t.Fatalf("expected other projects untouched, got %+v", got)
+	}
+}
+
+func TestMemories_Limits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memories.json")
+	if err := AddMemory(path, "/p", "   "); err == nil {
+		t.Fatal("expected an error for an empty fact")
+	}
+	if err := AddMemory(path, "/p", strings.Repeat("x", MaxMemoryLen+1)); err == nil {
+		t.Fatal("expected an error for an overlong fact")
+	}
+	for i := range MaxMemories {
+		if err := AddMemory(path, "/p", strings.Repeat("f", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := AddMemory(path, "/p", "one too many"); err == nil {
+		t.Fatal("expected an error once the project is full")
+	}
+}
+
+func TestAppendMemories(t *testing.T) {
+	if got := AppendMemories("base", nil); got != "base" {
+		t.Fatalf("expected prompt unchanged, got %q", got)
+	}
+	got := AppendMemories("base", []Memory{{Text: "staging is sg-02"}, {Text: "use pnpm"}})
+	if !strings.HasPrefix(got, "base\n\n") || !strings.Contains(got, "\n- staging is sg-02\n- use pnpm") {
+		t.Fatalf("unexpected prompt %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+package ai
+
+import "strings"
+
+// defaultSuggestedMaxTokens caps the suggestion for models with very large
+// output ceilings; a terminal answer rarely needs more.
+const defaultSuggestedMaxTokens = 8192
+
+// outputCeilings lists the documented maximum output tokens per model family.
+// Entries are matched by prefix, longest first, against the model ID with any
+// OpenRouter vendor prefix ("anthropic/") removed.
+var outputCeilings = []struct {
+	prefix string
+	limit  int
+}{
+	{"gpt-3.5-turbo", 4096},
+	{"gpt-4-turbo", 4096},
+	{"gpt-4o-mini", 16384},
+	{"gpt-4o", 16384},
+	{"gpt-4.1", 32768},
+	{"gpt-4", 8192},
+	{"gpt-5", 128000},
+	{"o1-mini", 65536},
+	{"o1-preview", 32768},
+	{"o1", 100000},
+	{"o3", 100000},
+	{"o4-mini", 100000},
+	{"claude-3-5-sonnet", 8192},
+	{"claude-3.5-sonnet", 8192},
+	{"claude-3-5-haiku", 8192},
+	{"claude-3.5-haiku", 8192},
+	{"claude-3-7-sonnet", 64000},
+	{"claude-3.7-sonnet", 64000},
+	{"claude-3-opus", 4096},
+	{"claude-3-sonnet", 4096},
+	{"claude-3-haiku", 4096},
+	{"claude-sonnet-4", 64000},
+	{"claude-opus-4", 32000},
+	{"claude-haiku-4", 64000},
+	{"gemini-1.5", 8192},
+	{"gemini-2.0", 8192},
+	{"gemini-2.5", 65536},
+	{"gemini-3", 65536},
+}
+
+// MaxOutputTokens returns the largest max_tokens modelID accepts, or 0 when
+// nothing is known about it. contextLength is the model's context window
+// (ModelInfo.ContextLength, 0 if unknown) and bounds the result when the
+// model has no documented output ceiling.
+func MaxOutputTokens(modelID string, contextLength int) int {
+	id := strings.ToLower(strings.TrimSpace(modelID))
+	if _, after, ok := strings.Cut(id, "/"); ok {
+		id = after
+	}
+
+	limit, matched := 0, 0
+	for _, c := range outputCeilings {
+		if strings.HasPrefix(id, c.prefix) && len(c.prefix) > matched {
+			limit, matched = c.limit, len(c.prefix)
+		}
+	}
+	if contextLength > 0 && (limit == 0 || contextLength < limit) {
+		limit = contextLength
+	}
+	return limit
+}
+
+// SuggestedMaxTokens returns a sane max_tokens for a model whose ceiling is
+// limit, or 0 when the limit is unknown.
+func SuggestedMaxTokens(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return min(limit, defaultSuggestedMaxTokens)
+}
@@ -0,0 +1,39 @@
+package ai
+
+import "testing"
+
+func TestMaxOutputTokens(t *testing.T) {
+	tests := []struct {
+		model         string
+		contextLength int
+		want          int
+	}{
+		{"gpt-4o", 0, 16384},
+		{"gpt-4o-mini", 0, 16384},
+		{"gpt-4", 0, 8192},
+		{"o1-mini", 0, 65536},
+		{"claude-3-5-sonnet-20241022", 0, 8192},
+		{"anthropic/claude-3.5-sonnet", 200000, 8192},
+		{"gemini-2.5-pro", 0, 65536},
+		{"some/unknown-model", 32768, 32768},
+		{"tiny-model", 0, 0},
+		{"gpt-4o", 4096, 4096},
+	}
+	for _, tt := range tests {
+		if got := MaxOutputTokens(tt.model, tt.contextLength); got != tt.want {
+			t.Errorf("MaxOutputTokens(%q, %d) = %d, want %d", tt.model, tt.contextLength, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestedMaxTokens(t *testing.T) {
+	if got := SuggestedMaxTokens(0); got != 0 {
+		t.Errorf("SuggestedMaxTokens(0) = %d, want 0", got)
+	}
+	if got := SuggestedMaxTokens(4096); got != 4096 {
+		t.Errorf("SuggestedMaxTokens(4096) = %d, want 4096", got)
+	}
+	if got := SuggestedMaxTokens(65536); got != defaultSuggestedMaxTokens {
+		t.Errorf("SuggestedMaxTokens(65536) = %d, want %d", got, defaultSuggestedMaxTokens)
+	}
+}
@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// modelListTTL is how long a fetched model list is reused before the
+// provider is asked again.
+const modelListTTL = 10 * time.Minute
+
+// ModelSource lists the models a provider offers, for the model pickers.
+type ModelSource interface {
+	// Provider is the provider the models belong to.
+	Provider() ProviderType
+	// Known returns the models available without a request: a list fetched
+	// earlier in the session, the cache on disk or a built-in list.
+	Known() []ModelInfo
+	// CanList reports whether List can ask the provider. When it cannot,
+	// e.g. without an API key, Known is all there is.
+	CanList() bool
+	// List returns the provider's current models. Errors are
+	// *ModelListError.
+	List(ctx context.Context) ([]ModelInfo, error)
+}
+
+// ModelSourceFactory builds a provider's ModelSource from config.
+type ModelSourceFactory func(cfg config.Config) ModelSource
+
+// Kinds of ModelListError.
+const (
+	ModelListUnavailable = "unavailable" // The provider cannot be asked, e.g. without an API key
+	ModelListRejected    = "rejected"    // The provider refused the credentials
+	ModelListFailed      = "failed"      // Anything else: network, server or decoding errors
+)
+
+// ModelListError is a model list that could not be fetched. Known still
+// has the models to offer.
+type ModelListError struct {
+	Provider ProviderType
+	Kind     string // One of the ModelList* kinds
+	Err      error
+}
+
+func (e *ModelListError) Error() string {
+	return fmt.Sprintf("list %s models: %v", e.Provider, e.Err)
+}
+
+func (e *ModelListError) Unwrap() error { return e.Err }
+
+// ModelListErrorKind returns the kind of a model list error, or "" when err
+// is not one.
+func ModelListErrorKind(err error) string {
+	var listErr *ModelListError
+	if errors.As(err, &listErr) {
+		return listErr.Kind
+	}
+	return ""
+}
+
+// modelStatusError is a models endpoint answering with an error status.
+type modelStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *modelStatusError) Error() string {
+	return fmt.Sprintf("models request failed (%d): %s", e.StatusCode, e.Body)
+}
+
+func newModelListError(provider ProviderType, err error) *ModelListError {
+	kind := ModelListFailed
+	var statusErr *modelStatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+		kind = ModelListRejected
+	}
+	return &ModelListError{Provider: provider, Kind: kind, Err: err}
+}
+
+// FetchedModelSource is a ModelSource over a fetch function. Lists it
+// fetches are kept in memory for modelListTTL, shared by every source with
+// the same provider and key, so a picker opened again shows them at once.
+type FetchedModelSource struct {
+	Type ProviderType
+	// Key says where the list comes from, e.g. the endpoint and API key;
+	// only a hash of it is kept.
+	Key string
+	// Builtin returns the models offered before anything was fetched.
+	Builtin func() []ModelInfo
+	// Fetch asks the provider. Nil when it cannot be asked.
+	Fetch func(ctx context.Context) ([]ModelInfo, error)
+}
+
+var (
+	modelListsMu sync.Mutex
+	modelLists   = map[[sha256.Size]byte]cachedModelList{}
+)
+
+type cachedModelList struct {
+	models    []ModelInfo
+	fetchedAt time.Time
+}
+
+func (s FetchedModelSource) Provider() ProviderType { return s.Type }
+
+func (s FetchedModelSource) Known() []ModelInfo {
+	if models, ok := s.cached(); ok {
+		return models
+	}
+	if s.Builtin == nil {
+		return nil
+	}
+	return s.Builtin()
+}
+
+func (s FetchedModelSource) CanList() bool { return s.Fetch != nil }
+
+func (s FetchedModelSource) List(ctx context.Context) ([]ModelInfo, error) {
+	if models, ok := s.cached(); ok {
+		return models, nil
+	}
+	if s.Fetch == nil {
+		return nil, &ModelListError{Provider: s.Type, Kind: ModelListUnavailable, Err: errors.New("no credentials to ask with")}
+	}
+	models, err := s.Fetch(ctx)
+	if err != nil {
+		return nil, newModelListError(s.Type, err)
+	}
+	modelListsMu.Lock()
+	modelLists[s.cacheKey()] = cachedModelList{models: models, fetchedAt: time.Now()}
+	modelListsMu.Unlock()
+	return models, nil
+}
+
+func (s FetchedModelSource) cached() ([]ModelInfo, bool) {
+	modelListsMu.Lock()
+	defer modelListsMu.Unlock()
+	list, ok := modelLists[s.cacheKey()]
+	if !ok || time.Since(list.fetchedAt) > modelListTTL {
+		return nil, false
+	}
+	return list.models, true
+}
+
+func (s FetchedModelSource) cacheKey() [sha256.Size]byte {
+	return sha256.Sum256([]byte(string(s.Type) + "\x00" + s.Key))
+}
+
+// forgetModelLists drops the lists fetched so far. Used by tests.
+func forgetModelLists() {
+	modelListsMu.Lock()
+	defer modelListsMu.Unlock()
+	clear(modelLists)
+}
+
+var (
+	modelSourcesMu sync.RWMutex
+	modelSources   = map[ProviderType]ModelSourceFactory{}
+)
+
+// RegisterModelSource sets how the model pickers list provider's models.
+func RegisterModelSource(provider ProviderType, factory ModelSourceFactory) {
+	modelSourcesMu.Lock()
+	defer modelSourcesMu.Unlock()
+	modelSources[provider] = factory
+}
+
+// GetModelSource returns the ModelSource of provider. A provider that
+// registered none gets its built-in list from GetProviderModels.
+func GetModelSource(provider ProviderType, cfg config.Config) ModelSource {
+	modelSourcesMu.RLock()
+	factory, ok := modelSources[provider]
+	modelSourcesMu.RUnlock()
+	if ok {
+		return factory(cfg)
+	}
+	return FetchedModelSource{Type: provider, Builtin: func() []ModelInfo { return GetProviderModels(string(provider)) }}
+}
+
+func init() {
+	RegisterModelSource(ProviderOpenRouter, func(cfg config.Config) ModelSource {
+		apiURL := strings.TrimSpace(cfg.OpenRouter.APIURL)
+		transport := cfg.OpenRouter.Transport()
+		source := FetchedModelSource{
+			Type: ProviderOpenRouter,
+			Key:  fmt.Sprintf("%s\x00%v", apiURL, transport),
+			Builtin: func() []ModelInfo {
+				cache, _ := LoadModelCache(DefaultModelCachePath())
+				return cache.Models
+			},
+		}
+		if apiURL != "" {
+			source.Fetch = func(ctx context.Context) ([]ModelInfo, error) {
+				cache, err := RefreshOpenRouterModelCache(ctx, apiURL, DefaultModelCachePath(), transport)
+				return cache.Models, err
+			}
+		}
+		return source
+	})
+	RegisterModelSource(ProviderOpenAI, func(cfg config.Config) ModelSource {
+		return apiKeyModelSource(ProviderOpenAI, cfg.Providers.OpenAI.APIKey, cfg.Providers.OpenAI.Transport(), FetchOpenAIModels)
+	})
+	RegisterModelSource(ProviderAnthropic, func(cfg config.Config) ModelSource {
+		return apiKeyModelSource(ProviderAnthropic, cfg.Providers.Anthropic.APIKey, cfg.Providers.Anthropic.Transport(), FetchAnthropicModels)
+	})
+	RegisterModelSource(ProviderGoogle, func(cfg config.Config) ModelSource {
+		return apiKeyModelSource(ProviderGoogle, cfg.Providers.Google.APIKey, cfg.Providers.Google.Transport(), FetchGoogleModels)
+	})
+	RegisterModelSource(ProviderCopilot, func(config.Config) ModelSource {
+		return FetchedModelSource{Type: ProviderCopilot, Builtin: GetCopilotModels, Fetch: FetchCopilotModels}
+	})
+}
+
+// apiKeyModelSource lists the models of a provider asked with an API key,
+// offering the built-in list until one is set.
+func apiKeyModelSource(provider ProviderType, apiKey string, transport config.ProviderTransport, fetch func(context.Context, string, config.ProviderTransport) ([]ModelInfo, error)) ModelSource {
+	source := FetchedModelSource{
+		Type:    provider,
+		Key:     fmt.Sprintf("%s\x00%v", apiKey, transport),
+		Builtin: func() []ModelInfo { return GetProviderModels(string(provider)) },
+	}
+	if strings.TrimSpace(apiKey) != "" {
+		source.Fetch = func(ctx context.Context) ([]ModelInfo, error) {
+			return fetch(ctx, apiKey, transport)
+		}
+	}
+	return source
+}
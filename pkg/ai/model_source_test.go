@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wtf_cli/pkg/config"
+)
+
+func TestFetchedModelSource_CachesFetchedList(t *testing.T) {
+	t.Cleanup(forgetModelLists)
+	calls := 0
+	source := FetchedModelSource{
+		Type:    ProviderOpenAI,
+		Key:     "key-1",
+		Builtin: func() []ModelInfo { return []ModelInfo{{ID: "builtin"}} },
+		Fetch: func(context.Context) ([]ModelInfo, error) {
+			calls++
+			return []ModelInfo{{ID: "fetched"}}, nil
+		},
+	}
+
+	if got := source.Known(); len(got) != 1 || got[0].ID != "builtin" {
+		t.Fatalf("Known() before a fetch = %v, want the built-in list", got)
+	}
+	for range 2 {
+		models, err := source.List(context.Background())
+		if err != nil || len(models) != 1 || models[0].ID != "fetched" {
+			t.Fatalf("List() = %v, %v", models, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fetched %d times, want the second List served from memory", calls)
+	}
+	if got := source.Known(); got[0].ID != "fetched" {
+		t.Errorf("Known() after a fetch = %v, want the fetched list", got)
+	}
+
+	other := source
+	other.Key = "key-2"
+	if got := other.Known(); got[0].ID != "builtin" {
+		t.Errorf("a source with another key should not share the list, got %v", got)
+	}
+}
+
+func TestFetchedModelSource_ErrorKinds(t *testing.T) {
+	t.Cleanup(forgetModelLists)
+	tests := []struct {
+		name  string
+		fetch func(context.Context) ([]ModelInfo, error)
+		want  string
+	}{
+		{"no fetch", nil, ModelListUnavailable},
+		{"rejected key", func(context.Context) ([]ModelInfo, error) {
+			return nil, &modelStatusError{StatusCode: 401, Body: "invalid key"}
+		}, ModelListRejected},
+		{"server error", func(context.Context) ([]ModelInfo, error) {
+			return nil, &modelStatusError{StatusCode: 502}
+		}, ModelListFailed},
+		{"network", func(context.Context) ([]ModelInfo, error) {
+			return nil, errors.New("connection refused")
+		}, ModelListFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := FetchedModelSource{Type: ProviderAnthropic, Key: tt.name, Fetch: tt.fetch}
+			_, err := source.List(context.Background())
+			if got := ModelListErrorKind(err); got != tt.want {
+				t.Errorf("ModelListErrorKind(%v) = %q, want %q", err, got, tt.want)
+			}
+		})
+	}
+	if got := ModelListErrorKind(errors.New("other")); got != "" {
+		t.Errorf("ModelListErrorKind of a plain error = %q, want empty", got)
+	}
+}
+
+func TestGetModelSource(t *testing.T) {
+	t.Cleanup(forgetModelLists)
+	cfg := config.Default()
+
+	source := GetModelSource(ProviderOpenAI, cfg)
+	if source.Provider() != ProviderOpenAI || len(source.Known()) == 0 {
+		t.Fatalf("OpenAI source should offer the built-in list, got %v", source.Known())
+	}
+	if source.CanList() {
+		t.Error("OpenAI without an API key should not be listable")
+	}
+	if _, err := source.List(context.Background()); ModelListErrorKind(err) != ModelListUnavailable {
+		t.Errorf("OpenAI without an API key: List() error = %v, want unavailable", err)
+	}
+
+	cfg.Providers.OpenAI.APIKey = "sk-test"
+	if !GetModelSource(ProviderOpenAI, cfg).CanList() {
+		t.Error("OpenAI with an API key should be listable")
+	}
+
+	custom := ProviderType("custom-test")
+	if got := GetModelSource(custom, cfg).Known(); got != nil {
+		t.Errorf("unregistered provider Known() = %v, want nil", got)
+	}
+	RegisterModelSource(custom, func(config.Config) ModelSource {
+		return FetchedModelSource{Type: custom, Builtin: func() []ModelInfo { return []ModelInfo{{ID: "custom-1"}} }}
+	})
+	t.Cleanup(func() {
+		modelSourcesMu.Lock()
+		delete(modelSources, custom)
+		modelSourcesMu.Unlock()
+	})
+	if got := GetModelSource(custom, cfg).Known(); len(got) != 1 || got[0].ID != "custom-1" {
+		t.Errorf("registered provider Known() = %v", got)
+	}
+}
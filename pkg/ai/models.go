@@ -14,11 +14,16 @@ import (
 	"strings"
 	"time"
 
+	"wtf_cli/pkg/config"
+
 	copilot "github.com/github/copilot-sdk/go"
 	"google.golang.org/genai"
 )
 
-const modelCacheFilename = "models_cache.json"
+const (
+	modelCacheFilename = "models_cache.json"
+	modelFetchTimeout  = 15 * time.Second
+)
 
 type httpDoer interface {
 	Do(*http.Request) (*http.Response, error)
@@ -52,10 +57,14 @@ func DefaultModelCachePath() string {
 	return filepath.Join(homeDir, ".wtf_cli", modelCacheFilename)
 }
 
-// FetchOpenRouterModels retrieves the OpenRouter model list from the API.
-func FetchOpenRouterModels(ctx context.Context, apiURL string) ([]ModelInfo, error) {
+// FetchOpenRouterModels retrieves the OpenRouter model list from the API,
+// going through transport.
+func FetchOpenRouterModels(ctx context.Context, apiURL string, transport config.ProviderTransport) ([]ModelInfo, error) {
 	slog.Debug("openrouter_models_fetch_start", "api_url", apiURL)
-	client := &http.Client{Timeout: 15 * time.Second}
+	client, err := NewHTTPClient(modelFetchTimeout, transport)
+	if err != nil {
+		return nil, err
+	}
 	return fetchOpenRouterModels(ctx, apiURL, client)
 }
 
@@ -84,7 +93,7 @@ func fetchOpenRouterModels(ctx context.Context, apiURL string, client httpDoer)
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
 		slog.Debug("openrouter_models_fetch_failed", "status", resp.StatusCode)
-		return nil, fmt.Errorf("models request failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, &modelStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
 	var payload modelListResponse
@@ -100,8 +109,8 @@ func fetchOpenRouterModels(ctx context.Context, apiURL string, client httpDoer)
 }
 
 // RefreshOpenRouterModelCache fetches models and writes the cache to disk.
-func RefreshOpenRouterModelCache(ctx context.Context, apiURL, cachePath string) (ModelCache, error) {
-	models, err := FetchOpenRouterModels(ctx, apiURL)
+func RefreshOpenRouterModelCache(ctx context.Context, apiURL, cachePath string, transport config.ProviderTransport) (ModelCache, error) {
+	models, err := FetchOpenRouterModels(ctx, apiURL, transport)
 	if err != nil {
 		return ModelCache{}, err
 	}
@@ -152,9 +161,12 @@ func SaveModelCache(path string, cache ModelCache) error {
 
 // FetchOpenAIModels retrieves the model list from OpenAI API.
 // Endpoint: GET https://api.openai.com/v1/models
-func FetchOpenAIModels(ctx context.Context, apiKey string) ([]ModelInfo, error) {
+func FetchOpenAIModels(ctx context.Context, apiKey string, transport config.ProviderTransport) ([]ModelInfo, error) {
 	slog.Debug("openai_models_fetch_start", "has_key", apiKey != "")
-	client := &http.Client{Timeout: 15 * time.Second}
+	client, err := NewHTTPClient(modelFetchTimeout, transport)
+	if err != nil {
+		return nil, err
+	}
 	return fetchOpenAIModels(ctx, apiKey, client)
 }
 
@@ -183,7 +195,7 @@ func fetchOpenAIModels(ctx context.Context, apiKey string, client httpDoer) ([]M
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
 		slog.Debug("openai_models_fetch_failed", "status", resp.StatusCode)
-		return nil, fmt.Errorf("models request failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, &modelStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
 	var payload struct {
@@ -217,9 +229,12 @@ func fetchOpenAIModels(ctx context.Context, apiKey string, client httpDoer) ([]M
 
 // FetchAnthropicModels retrieves the model list from Anthropic API.
 // Endpoint: GET https://api.anthropic.com/v1/models
-func FetchAnthropicModels(ctx context.Context, apiKey string) ([]ModelInfo, error) {
+func FetchAnthropicModels(ctx context.Context, apiKey string, transport config.ProviderTransport) ([]ModelInfo, error) {
 	slog.Debug("anthropic_models_fetch_start", "has_key", apiKey != "")
-	client := &http.Client{Timeout: 15 * time.Second}
+	client, err := NewHTTPClient(modelFetchTimeout, transport)
+	if err != nil {
+		return nil, err
+	}
 	return fetchAnthropicModels(ctx, apiKey, client)
 }
 
@@ -249,7 +264,7 @@ func fetchAnthropicModels(ctx context.Context, apiKey string, client httpDoer) (
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
 		slog.Debug("anthropic_models_fetch_failed", "status", resp.StatusCode)
-		return nil, fmt.Errorf("models request failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, &modelStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
 	var payload struct {
@@ -337,10 +352,11 @@ var copilotClientFactory = func() copilotSDKClient {
 	return &copilotSDKClientWrapper{client: copilot.NewClient(nil)}
 }
 
-var fetchGoogleModelList = func(ctx context.Context, apiKey string) ([]*genai.Model, error) {
+var fetchGoogleModelList = func(ctx context.Context, apiKey string, httpClient *http.Client) ([]*genai.Model, error) {
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: httpClient,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create google client: %w", err)
@@ -446,7 +462,7 @@ func FetchCopilotModels(ctx context.Context) ([]ModelInfo, error) {
 }
 
 // FetchGoogleModels retrieves the model list via the Google AI SDK.
-func FetchGoogleModels(ctx context.Context, apiKey string) ([]ModelInfo, error) {
+func FetchGoogleModels(ctx context.Context, apiKey string, transport config.ProviderTransport) ([]ModelInfo, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -458,7 +474,15 @@ func FetchGoogleModels(ctx context.Context, apiKey string) ([]ModelInfo, error)
 	}
 
 	slog.Debug("google_models_fetch_start", "has_key", apiKey != "")
-	rawModels, err := fetchGoogleModelList(ctx, apiKey)
+	var httpClient *http.Client // Nil lets the SDK use its default client
+	if !transport.IsZero() {
+		client, err := NewHTTPClient(modelFetchTimeout, transport)
+		if err != nil {
+			return nil, err
+		}
+		httpClient = client
+	}
+	rawModels, err := fetchGoogleModelList(ctx, apiKey, httpClient)
 	if err != nil {
 		slog.Debug("google_models_fetch_error", "error", err)
 		return nil, err
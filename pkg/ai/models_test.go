@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"wtf_cli/pkg/config"
+
 	copilot "github.com/github/copilot-sdk/go"
 	"google.golang.org/genai"
 )
@@ -237,7 +239,7 @@ func TestFetchGoogleModels(t *testing.T) {
 		fetchGoogleModelList = origFetch
 	}()
 
-	fetchGoogleModelList = func(ctx context.Context, apiKey string) ([]*genai.Model, error) {
+	fetchGoogleModelList = func(ctx context.Context, apiKey string, httpClient *http.Client) ([]*genai.Model, error) {
 		if apiKey != "test-google-key" {
 			t.Fatalf("Expected API key 'test-google-key', got %q", apiKey)
 		}
@@ -262,7 +264,7 @@ func TestFetchGoogleModels(t *testing.T) {
 		}, nil
 	}
 
-	models, err := FetchGoogleModels(context.Background(), "test-google-key")
+	models, err := FetchGoogleModels(context.Background(), "test-google-key", config.ProviderTransport{})
 	if err != nil {
 		t.Fatalf("FetchGoogleModels() error: %v", err)
 	}
@@ -289,12 +291,12 @@ func TestFetchGoogleModels_EmptyAPIKey(t *testing.T) {
 		fetchGoogleModelList = origFetch
 	}()
 
-	fetchGoogleModelList = func(ctx context.Context, apiKey string) ([]*genai.Model, error) {
+	fetchGoogleModelList = func(ctx context.Context, apiKey string, httpClient *http.Client) ([]*genai.Model, error) {
 		t.Fatal("fetchGoogleModelList should not be called when API key is empty")
 		return nil, nil
 	}
 
-	_, err := FetchGoogleModels(context.Background(), "")
+	_, err := FetchGoogleModels(context.Background(), "", config.ProviderTransport{})
 	if err == nil {
 		t.Fatal("Expected error for empty API key")
 	}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxPinnedBlocks is how many output blocks can be pinned at once.
+	MaxPinnedBlocks = 5
+	// MaxPinnedBlockBytes caps one pinned block; longer output keeps its end.
+	MaxPinnedBlockBytes = 8000
+)
+
+// PinnedBlock is a block of terminal output the user pinned so it is sent
+// with every AI request of the session.
+type PinnedBlock struct {
+	Label   string // Where the block came from, e.g. the command that printed it
+	Content string
+}
+
+// NewPinnedBlock strips escape sequences from lines and truncates the result
+// to MaxPinnedBlockBytes.
+func NewPinnedBlock(label string, lines [][]byte) PinnedBlock {
+	content, _ := truncateOutput(strings.TrimSpace(sanitizeLines(lines)), MaxPinnedBlockBytes)
+	return PinnedBlock{Label: strings.TrimSpace(label), Content: content}
+}
+
+// Tokens estimates the tokens the block adds to each request.
+func (b PinnedBlock) Tokens() int {
+	return EstimateTokens(b.Label) + EstimateTokens(b.Content)
+}
+
+// EstimateTokens approximates the token count of text at four characters
+// per token, close enough to show what a block costs.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// pinnedBlocksPrompt renders pinned blocks for the user prompt, or "" when
+// there are none.
+func pinnedBlocksPrompt(blocks []PinnedBlock) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nPinned output (blocks the user pinned to every request; treat them as important context):\n")
+	for i, b := range blocks {
+		sb.WriteString(fmt.Sprintf("--- pinned %d: %s ---\n", i+1, b.Label))
+		sb.WriteString(b.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
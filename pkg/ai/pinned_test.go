@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPinnedBlock_StripsEscapesAndTruncates(t *testing.T) {
+	block := NewPinnedBlock(" make test ", [][]byte{[]byte("\x1b[31mFAIL\x1b[0m pkg/ui"), []byte("")})
+	if block.Label != "make test" || block.Content != "FAIL pkg/ui" {
+		t.Fatalf("block = %+v", block)
+	}
+
+	long := NewPinnedBlock("log", [][]byte{[]byte(strings.Repeat("x", MaxPinnedBlockBytes+100))})
+	if len(long.Content) != MaxPinnedBlockBytes || !strings.HasPrefix(long.Content, "[truncated]") {
+		t.Fatalf("expected content truncated to %d bytes, got %d", MaxPinnedBlockBytes, len(long.Content))
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Fatalf("EstimateTokens(\"\") = %d", got)
+	}
+	if got := EstimateTokens("abcde"); got != 2 {
+		t.Fatalf("EstimateTokens(abcde) = %d, want 2", got)
+	}
+	block := PinnedBlock{Label: "ls", Content: "12345678"}
+	if got := block.Tokens(); got != 3 {
+		t.Fatalf("Tokens() = %d, want 3", got)
+	}
+}
+
+func TestBuildContexts_IncludePinnedBlocks(t *testing.T) {
+	lines := [][]byte{[]byte("latest output")}
+	meta := TerminalMetadata{Pinned: []PinnedBlock{{Label: "go test ./...", Content: "--- FAIL: TestParse"}}}
+
+	for name, prompt := range map[string]string{
+		"explain": BuildTerminalContext(lines, meta).UserPrompt,
+		"chat":    BuildChatContext(lines, meta).UserPrompt,
+	} {
+		if !strings.Contains(prompt, "--- pinned 1: go test ./... ---\n--- FAIL: TestParse") {
+			t.Errorf("%s prompt should include the pinned block, got: %q", name, prompt)
+		}
+	}
+
+	if prompt := BuildChatContext(lines, TerminalMetadata{}).UserPrompt; strings.Contains(prompt, "Pinned output") {
+		t.Errorf("prompt without pins should not mention them, got: %q", prompt)
+	}
+}
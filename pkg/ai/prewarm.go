@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// Pre-warming opens the HTTPS connection to the active provider before the
+// first request, so that request does not also pay for DNS, TCP and TLS
+// setup. The pre-warm request goes through the same transport as the
+// provider's client (see NewHTTPClient), whose pool hands the open
+// connection to the next request. The pre-warm request is a
+// HEAD of the API URL: it sends no completion, so it costs no tokens.
+
+const (
+	// prewarmFresh is how long a warmed connection is trusted to still be
+	// open; http.DefaultTransport closes idle connections after 90s.
+	prewarmFresh   = 60 * time.Second
+	prewarmTimeout = 10 * time.Second
+)
+
+// prewarmDefaultURLs are the endpoints of providers whose api_url may be
+// left empty, as the providers package defaults them.
+var prewarmDefaultURLs = map[ProviderType]string{
+	ProviderOpenAI:    "https://api.openai.com/v1",
+	ProviderAnthropic: "https://api.anthropic.com/v1",
+	ProviderGoogle:    "https://generativelanguage.googleapis.com/",
+}
+
+// PrewarmURL returns the endpoint to warm for cfg's active provider, or ""
+// when there is nothing to warm: Copilot runs through its own CLI and is
+// billed per request, and local models are loaded by DefaultLocalWarmup.
+func PrewarmURL(cfg config.Config) string {
+	provider := ProviderType(cfg.LLMProvider)
+	if provider == ProviderCopilot || UsesLocalModel(cfg) {
+		return ""
+	}
+	if provider == "" {
+		provider = ProviderOpenRouter
+	}
+	if u := strings.TrimSpace(ActiveAPIURL(cfg)); u != "" {
+		return u
+	}
+	return prewarmDefaultURLs[provider]
+}
+
+// Prewarmer opens provider connections in the background, at most once per
+// prewarmFresh for each URL.
+type Prewarmer struct {
+	mu     sync.Mutex
+	warmed map[string]time.Time
+
+	client httpDoer         // Nil uses NewHTTPClient
+	now    func() time.Time // Nil is time.Now
+}
+
+// DefaultPrewarmer is the prewarmer shared by the UI.
+var DefaultPrewarmer = &Prewarmer{}
+
+// Start warms url, going through transport, unless url is
+// empty or was warmed recently. It reports whether a request was started.
+// Failures are only logged: the first real request reports them.
+func (p *Prewarmer) Start(url string, transport config.ProviderTransport) bool {
+	if url == "" {
+		return false
+	}
+	p.mu.Lock()
+	now := p.clock()
+	if at, ok := p.warmed[url]; ok && now.Sub(at) < prewarmFresh {
+		p.mu.Unlock()
+		return false
+	}
+	if p.warmed == nil {
+		p.warmed = make(map[string]time.Time)
+	}
+	p.warmed[url] = now
+	p.mu.Unlock()
+
+	go p.warm(url, transport)
+	return true
+}
+
+func (p *Prewarmer) warm(url string, transport config.ProviderTransport) {
+	ctx, cancel := context.WithTimeout(context.Background(), prewarmTimeout)
+	defer cancel()
+	start := p.clock()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		slog.Debug("provider_prewarm_failed", "url", url, "error", err)
+		return
+	}
+	client := p.client
+	if client == nil {
+		if client, err = NewHTTPClient(prewarmTimeout, transport); err != nil {
+			slog.Debug("provider_prewarm_failed", "url", url, "error", err)
+			return
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		slog.Debug("provider_prewarm_failed", "url", url, "error", err)
+		p.mu.Lock()
+		delete(p.warmed, url)
+		p.mu.Unlock()
+		return
+	}
+	// Any status will do; closing the body returns the connection to the
+	// pool.
+	resp.Body.Close()
+	slog.Debug("provider_prewarm_done", "url", url, "status", resp.StatusCode, "duration_ms", p.clock().Sub(start).Milliseconds())
+}
+
+func (p *Prewarmer) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+func TestPrewarmURL(t *testing.T) {
+	cfg := config.Default()
+	cfg.LLMProvider = string(ProviderAnthropic)
+	cfg.Providers.Anthropic.APIURL = ""
+	if got := PrewarmURL(cfg); got != "https://api.anthropic.com/v1" {
+		t.Errorf("PrewarmURL(anthropic) = %q, want the default endpoint", got)
+	}
+
+	cfg.LLMProvider = string(ProviderOpenRouter)
+	cfg.OpenRouter.APIURL = "https://openrouter.ai/api/v1"
+	if got := PrewarmURL(cfg); got != "https://openrouter.ai/api/v1" {
+		t.Errorf("PrewarmURL(openrouter) = %q", got)
+	}
+
+	cfg.OpenRouter.APIURL = "http://localhost:11434/v1"
+	if got := PrewarmURL(cfg); got != "" {
+		t.Errorf("PrewarmURL(local) = %q, want empty", got)
+	}
+
+	cfg.LLMProvider = string(ProviderCopilot)
+	if got := PrewarmURL(cfg); got != "" {
+		t.Errorf("PrewarmURL(copilot) = %q, want empty", got)
+	}
+}
+
+func TestPrewarmer_WarmsOncePerWindow(t *testing.T) {
+	var heads atomic.Int32
+	done := make(chan struct{}, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		done <- struct{}{}
+	}))
+	defer server.Close()
+
+	var now atomic.Int64
+	now.Store(1700000000)
+	p := &Prewarmer{client: server.Client(), now: func() time.Time { return time.Unix(now.Load(), 0) }}
+
+	if !p.Start(server.URL, config.ProviderTransport{}) {
+		t.Fatal("expected the first Start to warm")
+	}
+	<-done
+	if p.Start(server.URL, config.ProviderTransport{}) {
+		t.Fatal("expected a second Start within the window to be skipped")
+	}
+	if p.Start("", config.ProviderTransport{}) {
+		t.Fatal("expected an empty URL to be skipped")
+	}
+
+	now.Add(int64(prewarmFresh / time.Second))
+	if !p.Start(server.URL, config.ProviderTransport{}) {
+		t.Fatal("expected Start to warm again once the window passed")
+	}
+	<-done
+	if got := heads.Load(); got != 2 {
+		t.Fatalf("server saw %d HEAD requests, want 2", got)
+	}
+}
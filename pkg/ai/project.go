@@ -0,0 +1,223 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProjectTask is a command likely to be useful in a project.
+type ProjectTask struct {
+	Command     string
+	Description string
+}
+
+// ProjectInfo describes the project a directory belongs to. Fields are empty
+// when no project marker was found.
+type ProjectInfo struct {
+	Root  string   // Directory holding the markers
+	Types []string // e.g. "node", "go", "rust", "make", "docker-compose"
+	Tasks []ProjectTask
+}
+
+const (
+	// maxProjectTasks bounds the tasks collected from project files.
+	maxProjectTasks = 40
+	// maxPromptTasks bounds the tasks listed in the prompt metadata.
+	maxPromptTasks = 15
+)
+
+// projectDetectors recognise a project type from its marker file and list
+// its tasks. They run in this order, so build files come before task runners.
+var projectDetectors = []struct {
+	kind    string
+	markers []string
+	tasks   func(dir, marker string) []ProjectTask
+}{
+	{"node", []string{"package.json"}, nodeTasks},
+	{"go", []string{"go.mod"}, goTasks},
+	{"rust", []string{"Cargo.toml"}, rustTasks},
+	{"make", []string{"GNUmakefile", "Makefile", "makefile"}, makeTasks},
+	{"docker-compose", []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}, composeTasks},
+}
+
+// DetectProject finds the closest directory at or above dir holding project
+// markers (package.json, go.mod, Cargo.toml, Makefile, docker-compose.yml)
+// and lists the tasks defined there. Like DetectToolchain it only reads small
+// files, so it is cheap enough to call for every AI request.
+func DetectProject(dir string) ProjectInfo {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return ProjectInfo{}
+	}
+	dir = filepath.Clean(dir)
+	for depth := 0; depth < maxToolchainDepth; depth++ {
+		if info := detectProjectIn(dir); len(info.Types) > 0 {
+			return info
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ProjectInfo{}
+}
+
+func detectProjectIn(dir string) ProjectInfo {
+	info := ProjectInfo{Root: dir}
+	for _, d := range projectDetectors {
+		for _, marker := range d.markers {
+			path := filepath.Join(dir, marker)
+			if st, err := os.Stat(path); err != nil || !st.Mode().IsRegular() {
+				continue
+			}
+			info.Types = append(info.Types, d.kind)
+			for _, task := range d.tasks(dir, path) {
+				if len(info.Tasks) < maxProjectTasks {
+					info.Tasks = append(info.Tasks, task)
+				}
+			}
+			break
+		}
+	}
+	return info
+}
+
+// PromptLines renders the project as "key: value" metadata lines.
+func (p ProjectInfo) PromptLines() []string {
+	if len(p.Types) == 0 {
+		return nil
+	}
+	lines := []string{
+		fmt.Sprintf("project: %s", strings.Join(p.Types, ", ")),
+		fmt.Sprintf("project_root: %s", p.Root),
+	}
+	if len(p.Tasks) > 0 {
+		commands := make([]string, 0, min(len(p.Tasks), maxPromptTasks))
+		for _, t := range p.Tasks[:min(len(p.Tasks), maxPromptTasks)] {
+			commands = append(commands, t.Command)
+		}
+		lines = append(lines, fmt.Sprintf("project_tasks: %s", strings.Join(commands, "; ")))
+	}
+	return lines
+}
+
+// nodePackageManager picks the package manager from the lockfile in dir.
+func nodePackageManager(dir string) string {
+	for _, lock := range []struct{ file, manager string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			return lock.manager
+		}
+	}
+	return "npm"
+}
+
+func nodeTasks(dir, path string) []ProjectTask {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	manager := nodePackageManager(dir)
+	tasks := []ProjectTask{{Command: manager + " install", Description: "Install dependencies"}}
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tasks = append(tasks, ProjectTask{Command: nodeScriptCommand(manager, name), Description: pkg.Scripts[name]})
+	}
+	return tasks
+}
+
+// nodeScriptCommand runs a package.json script with manager.
+func nodeScriptCommand(manager, script string) string {
+	switch {
+	case manager == "npm" && (script == "test" || script == "start"):
+		return "npm " + script
+	case manager == "npm" || manager == "bun":
+		return manager + " run " + script
+	default:
+		return manager + " " + script
+	}
+}
+
+func goTasks(dir, _ string) []ProjectTask {
+	tasks := []ProjectTask{
+		{Command: "go build ./...", Description: "Build all packages"},
+		{Command: "go test ./...", Description: "Run all tests"},
+		{Command: "go vet ./...", Description: "Report suspicious constructs"},
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err == nil {
+		tasks = append(tasks, ProjectTask{Command: "go run .", Description: "Run the main package"})
+	}
+	return tasks
+}
+
+func rustTasks(dir, _ string) []ProjectTask {
+	tasks := []ProjectTask{
+		{Command: "cargo build", Description: "Build the crate"},
+		{Command: "cargo test", Description: "Run all tests"},
+		{Command: "cargo clippy", Description: "Lint the crate"},
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "main.rs")); err == nil {
+		tasks = append(tasks, ProjectTask{Command: "cargo run", Description: "Run the binary"})
+	}
+	return tasks
+}
+
+// makeTargetLine matches "target: deps ## description" rule lines, but not
+// variable assignments such as "CC := gcc".
+var makeTargetLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*:([^=].*)?$`)
+
+func makeTasks(_, path string) []ProjectTask {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var tasks []ProjectTask
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := makeTargetLine.FindStringSubmatch(scanner.Text())
+		if m == nil || seen[m[1]] || strings.Contains(m[1], "%") {
+			continue
+		}
+		seen[m[1]] = true
+		var description string
+		if _, comment, ok := strings.Cut(m[2], "##"); ok {
+			description = strings.TrimSpace(comment)
+		}
+		tasks = append(tasks, ProjectTask{Command: "make " + m[1], Description: description})
+	}
+	return tasks
+}
+
+// composeTasks covers the compose files docker compose finds on its own.
+func composeTasks(_, _ string) []ProjectTask {
+	return []ProjectTask{
+		{Command: "docker compose up -d", Description: "Start the services in the background"},
+		{Command: "docker compose ps", Description: "List the services"},
+		{Command: "docker compose logs -f", Description: "Follow the service logs"},
+		{Command: "docker compose down", Description: "Stop and remove the services"},
+	}
+}
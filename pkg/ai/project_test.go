@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func taskCommands(tasks []ProjectTask) []string {
+	commands := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		commands = append(commands, t.Command)
+	}
+	return commands
+}
+
+func TestDetectProject_NodeAndMake(t *testing.T) {
+	root := t.TempDir()
+	writeToolchainFile(t, filepath.Join(root, "package.json"), `{"scripts":{"test":"vitest","build":"vite build"}}`)
+	writeToolchainFile(t, filepath.Join(root, "yarn.lock"), "")
+	writeToolchainFile(t, filepath.Join(root, "Makefile"), strings.Join([]string{
+		"CC := gcc",
+		".PHONY: test lint",
+		"build: deps ## Build the binary",
+		"test:",
+		"\tgo test ./...",
+		"%.o: %.c",
+		"test: more",
+		"",
+	}, "\n"))
+	sub := filepath.Join(root, "src", "components")
+	writeToolchainFile(t, filepath.Join(sub, "App.tsx"), "")
+
+	info := DetectProject(sub)
+	if info.Root != root {
+		t.Fatalf("Root = %q, want %q", info.Root, root)
+	}
+	if !slices.Equal(info.Types, []string{"node", "make"}) {
+		t.Fatalf("Types = %v, want [node make]", info.Types)
+	}
+	want := []string{"yarn install", "yarn build", "yarn test", "make build", "make test"}
+	if got := taskCommands(info.Tasks); !slices.Equal(got, want) {
+		t.Fatalf("tasks = %v, want %v", got, want)
+	}
+	if info.Tasks[3].Description != "Build the binary" {
+		t.Fatalf("make build description = %q, want the ## comment", info.Tasks[3].Description)
+	}
+}
+
+func TestDetectProject_GoRustCompose(t *testing.T) {
+	root := t.TempDir()
+	writeToolchainFile(t, filepath.Join(root, "go.mod"), "module example.com/x\n")
+	writeToolchainFile(t, filepath.Join(root, "main.go"), "package main\n")
+	writeToolchainFile(t, filepath.Join(root, "compose.yaml"), "services: {}\n")
+
+	info := DetectProject(root)
+	if !slices.Equal(info.Types, []string{"go", "docker-compose"}) {
+		t.Fatalf("Types = %v", info.Types)
+	}
+	got := taskCommands(info.Tasks)
+	for _, want := range []string{"go test ./...", "go run .", "docker compose up -d"} {
+		if !slices.Contains(got, want) {
+			t.Fatalf("tasks %v lack %q", got, want)
+		}
+	}
+
+	crate := t.TempDir()
+	writeToolchainFile(t, filepath.Join(crate, "Cargo.toml"), "[package]\nname = \"x\"\n")
+	if got := taskCommands(DetectProject(crate).Tasks); slices.Contains(got, "cargo run") || !slices.Contains(got, "cargo test") {
+		t.Fatalf("library crate tasks = %v, want cargo test but no cargo run", got)
+	}
+}
+
+func TestDetectProject_NoneAndNpmScripts(t *testing.T) {
+	if info := DetectProject(t.TempDir()); len(info.Types) != 0 || info.PromptLines() != nil {
+		t.Fatalf("expected no project, got %+v", info)
+	}
+	if got := nodeScriptCommand("npm", "test"); got != "npm test" {
+		t.Fatalf("npm test = %q", got)
+	}
+	if got := nodeScriptCommand("npm", "lint"); got != "npm run lint" {
+		t.Fatalf("npm lint = %q", got)
+	}
+	if got := nodeScriptCommand("pnpm", "lint"); got != "pnpm lint" {
+		t.Fatalf("pnpm lint = %q", got)
+	}
+}
+
+func TestProjectInfo_PromptLines(t *testing.T) {
+	info := ProjectInfo{
+		Root:  "/src/app",
+		Types: []string{"go", "make"},
+		Tasks: []ProjectTask{{Command: "make test"}, {Command: "go build ./..."}},
+	}
+	want := []string{"project: go, make", "project_root: /src/app", "project_tasks: make test; go build ./..."}
+	if got := info.PromptLines(); !slices.Equal(got, want) {
+		t.Fatalf("PromptLines() = %q, want %q", got, want)
+	}
+}
@@ -29,6 +29,9 @@ type ChatRequest struct {
 	Messages    []Message
 	Temperature *float64
 	MaxTokens   *int
+	// TopP, when set, samples only from the most likely tokens making up
+	// that share of the probability. Nil leaves it to the provider.
+	TopP *float64
 
 	// Tools, when non-empty, are advertised to the model. Providers that do not
 	// support tool calling (Capabilities().Tools == false) must ignore this field.
@@ -39,6 +42,19 @@ type ChatRequest struct {
 	ToolChoice string
 }
 
+// SamplingParams override the configured sampling settings, e.g. for one
+// conversation. Nil fields keep the configured value.
+type SamplingParams struct {
+	Temperature *float64
+	MaxTokens   *int
+	TopP        *float64
+}
+
+// IsZero reports whether p overrides nothing.
+func (p SamplingParams) IsZero() bool {
+	return p.Temperature == nil && p.MaxTokens == nil && p.TopP == nil
+}
+
 // ChatResponse is a normalized response from an LLM.
 type ChatResponse struct {
 	Content    string
@@ -60,6 +76,9 @@ type ChatResponse struct {
 //     expose the assembled list at end-of-stream — never partial.
 //   - StopReason() returns the provider's stop reason (e.g. "stop",
 //     "tool_calls", "length"), if known. Only valid after Next() returns false.
+//
+// Streams that also report reasoning, tool activity, usage or notices
+// implement EventStream; read events with CurrentEvent.
 type ChatStream interface {
 	Next() bool
 	Content() string
@@ -14,6 +14,7 @@ import (
 
 	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/ai/auth"
+	"wtf_cli/pkg/config"
 )
 
 const (
@@ -41,6 +42,7 @@ type AnthropicProvider struct {
 	defaultModel       string
 	defaultTemperature float64
 	defaultMaxTokens   int
+	overrides          config.RequestOverrides
 }
 
 // NewAnthropicProvider creates a new Anthropic provider from config.
@@ -77,7 +79,11 @@ func NewAnthropicProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
 		timeout = anthropicDefaultTimeout
 	}
 
-	httpClient := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	httpClient, err := ai.NewHTTPClient(time.Duration(timeout)*time.Second, providerCfg.Transport())
+	if err != nil {
+		return nil, err
+	}
+	logRequestOverrides("anthropic", providerCfg.RequestOverrides)
 
 	slog.Debug("anthropic_provider_ready",
 		"auth_source", authSource,
@@ -92,6 +98,7 @@ func NewAnthropicProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
 		defaultModel:       model,
 		defaultTemperature: providerCfg.Temperature,
 		defaultMaxTokens:   providerCfg.MaxTokens,
+		overrides:          providerCfg.RequestOverrides,
 	}, nil
 }
 
@@ -101,6 +108,7 @@ type anthropicRequest struct {
 	Messages    []anthropicMessage   `json:"messages"`
 	MaxTokens   int                  `json:"max_tokens"`
 	Temperature float64              `json:"temperature,omitempty"`
+	TopP        *float64             `json:"top_p,omitempty"`
 	System      string               `json:"system,omitempty"`
 	Stream      bool                 `json:"stream,omitempty"`
 	Tools       []anthropicTool      `json:"tools,omitempty"`
@@ -201,11 +209,23 @@ type anthropicStreamEvent struct {
 	Index        int                         `json:"index,omitempty"`
 	Delta        anthropicStreamEventDelta   `json:"delta,omitempty"`
 	ContentBlock anthropicStreamContentBlock `json:"content_block,omitempty"`
+	Message      struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	Usage anthropicUsage `json:"usage,omitempty"`
+}
+
+// anthropicUsage is reported on message_start (input tokens) and updated on
+// message_delta (cumulative output tokens).
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
 }
 
 type anthropicStreamEventDelta struct {
 	Type        string `json:"type,omitempty"`
 	Text        string `json:"text,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
 	PartialJSON string `json:"partial_json,omitempty"`
 	StopReason  string `json:"stop_reason,omitempty"`
 }
@@ -236,6 +256,9 @@ func (p *AnthropicProvider) CreateChatCompletion(ctx context.Context, req ai.Cha
 	if err != nil {
 		return ai.ChatResponse{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
+	if body, err = mergeRequestBody(body, p.overrides.Body); err != nil {
+		return ai.ChatResponse{}, err
+	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiURL+"/messages", bytes.NewReader(body))
 	if err != nil {
@@ -309,6 +332,9 @@ func (p *AnthropicProvider) CreateChatCompletionStream(ctx context.Context, req
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
+	if body, err = mergeRequestBody(body, p.overrides.Body); err != nil {
+		return nil, err
+	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiURL+"/messages", bytes.NewReader(body))
 	if err != nil {
@@ -413,6 +439,7 @@ func (p *AnthropicProvider) buildRequest(req ai.ChatRequest, stream bool) (*anth
 		Messages:    messages,
 		MaxTokens:   maxTokens,
 		Temperature: temperature,
+		TopP:        req.TopP,
 		System:      systemPrompt,
 		Stream:      stream,
 	}
@@ -487,12 +514,16 @@ func (p *AnthropicProvider) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", p.apiKey)
 	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	for name, value := range p.overrides.Headers {
+		req.Header.Set(name, value)
+	}
 }
 
 type anthropicStream struct {
 	reader  *bufio.Reader
 	body    io.ReadCloser
-	current string
+	current ai.StreamEvent
+	usage   ai.Usage
 	err     error
 	done    bool
 
@@ -551,6 +582,10 @@ func (s *anthropicStream) Next() bool {
 		}
 
 		switch event.Type {
+		case "message_start":
+			if event.Message.Usage != (anthropicUsage{}) {
+				return s.setUsage(event.Message.Usage)
+			}
 		case "content_block_start":
 			if event.ContentBlock.Type == "tool_use" {
 				if s.pendingByIdx == nil {
@@ -562,12 +597,23 @@ func (s *anthropicStream) Next() bool {
 					isToolUse: true,
 				}
 				s.blockOrder = append(s.blockOrder, event.Index)
+				s.current = ai.StreamEvent{
+					Kind:       ai.StreamEventToolCall,
+					ToolCallID: event.ContentBlock.ID,
+					ToolName:   event.ContentBlock.Name,
+				}
+				return true
 			}
 		case "content_block_delta":
 			switch event.Delta.Type {
 			case "text_delta":
 				if event.Delta.Text != "" {
-					s.current = event.Delta.Text
+					s.current = ai.StreamEvent{Kind: ai.StreamEventContent, Text: event.Delta.Text}
+					return true
+				}
+			case "thinking_delta":
+				if event.Delta.Thinking != "" {
+					s.current = ai.StreamEvent{Kind: ai.StreamEventReasoning, Text: event.Delta.Thinking}
 					return true
 				}
 			case "input_json_delta":
@@ -579,6 +625,9 @@ func (s *anthropicStream) Next() bool {
 			if event.Delta.StopReason != "" {
 				s.stopReason = event.Delta.StopReason
 			}
+			if event.Usage != (anthropicUsage{}) {
+				return s.setUsage(event.Usage)
+			}
 		case "message_stop":
 			s.done = true
 			return false
@@ -586,7 +635,22 @@ func (s *anthropicStream) Next() bool {
 	}
 }
 
+// setUsage folds a usage report into the running totals and makes it the
+// current event.
+func (s *anthropicStream) setUsage(u anthropicUsage) bool {
+	s.usage = s.usage.Merge(ai.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens})
+	s.current = ai.StreamEvent{Kind: ai.StreamEventUsage, Usage: s.usage}
+	return true
+}
+
 func (s *anthropicStream) Content() string {
+	if s.current.Kind != ai.StreamEventContent {
+		return ""
+	}
+	return s.current.Text
+}
+
+func (s *anthropicStream) Event() ai.StreamEvent {
 	return s.current
 }
 
@@ -638,6 +702,7 @@ func (p *AnthropicProvider) Capabilities() ai.ProviderCapabilities {
 
 // Ensure interface compliance
 var _ ai.Provider = (*AnthropicProvider)(nil)
+var _ ai.EventStream = (*anthropicStream)(nil)
 
 // Blank import to ensure auth package is available
 var _ = auth.DefaultAuthPath
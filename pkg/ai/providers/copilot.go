@@ -104,6 +104,7 @@ type CopilotProvider struct {
 	defaultTemperature float64
 	defaultMaxTokens   int
 	timeout            time.Duration
+	pool               *copilotPool
 }
 
 // NewCopilotProvider creates a new GitHub Copilot provider from config.
@@ -120,9 +121,16 @@ func NewCopilotProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
 		timeout = copilotDefaultTimeout
 	}
 
+	idle := copilotDefaultIdle
+	if providerCfg.SessionIdleSeconds != 0 {
+		idle = time.Duration(providerCfg.SessionIdleSeconds) * time.Second
+	}
+	defaultCopilotPool.setIdleTimeout(idle)
+
 	slog.Debug("copilot_provider_ready",
 		"model", model,
 		"timeout_seconds", timeout,
+		"session_idle_seconds", int(idle.Seconds()),
 	)
 	return &CopilotProvider{
 		client:             newCopilotClient(),
@@ -130,6 +138,7 @@ func NewCopilotProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
 		defaultTemperature: providerCfg.Temperature,
 		defaultMaxTokens:   providerCfg.MaxTokens,
 		timeout:            time.Duration(timeout) * time.Second,
+		pool:               defaultCopilotPool,
 	}, nil
 }
 
@@ -140,7 +149,7 @@ func (p *CopilotProvider) CreateChatCompletion(ctx context.Context, req ai.ChatR
 		return ai.ChatResponse{}, err
 	}
 
-	systemMsg, prompt, err := buildCopilotPrompt(req)
+	systemMsg, parts, err := buildCopilotPromptParts(req)
 	if err != nil {
 		return ai.ChatResponse{}, err
 	}
@@ -156,24 +165,36 @@ func (p *CopilotProvider) CreateChatCompletion(ctx context.Context, req ai.ChatR
 	)
 	logCopilotUnsupportedOptions(req, p.defaultTemperature, p.defaultMaxTokens)
 
-	if err := p.client.Start(ctx); err != nil {
-		return ai.ChatResponse{}, fmt.Errorf("copilot client start: %w", err)
-	}
-	defer stopCopilotClient(p.client)
-
-	if err := ensureCopilotAuthenticated(ctx, p.client); err != nil {
+	key := copilotSessionKey{model: model}
+	lease, prompt, err := p.pool.acquire(ctx, key, systemMsg, parts, true)
+	if err != nil {
 		return ai.ChatResponse{}, err
 	}
-
-	slog.Debug("copilot_session_create_start", "model", model, "streaming", false)
-	session, err := p.client.CreateSession(ctx, newCopilotSessionConfig(model, false, systemMsg))
+	content, err := sendCopilotAndWait(ctx, lease.Session(), prompt, requestTimeout)
+	if err != nil && lease.reused && ctx.Err() == nil && !errors.Is(err, context.DeadlineExceeded) {
+		// The pooled session may have gone bad; start over on a fresh one.
+		slog.Debug("copilot_session_reuse_failed", "error", err)
+		lease.Done("", false)
+		if lease, prompt, err = p.pool.acquire(ctx, key, systemMsg, parts, false); err != nil {
+			return ai.ChatResponse{}, err
+		}
+		content, err = sendCopilotAndWait(ctx, lease.Session(), prompt, requestTimeout)
+	}
+	lease.Done(content, err == nil)
 	if err != nil {
-		return ai.ChatResponse{}, fmt.Errorf("copilot session create: %w", err)
+		return ai.ChatResponse{}, err
 	}
-	slog.Debug("copilot_session_create_done", "model", model)
-	defer session.Disconnect()
 
-	sendCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	return ai.ChatResponse{
+		Content: content,
+		Model:   model,
+	}, nil
+}
+
+// sendCopilotAndWait sends prompt on session and waits for the answer,
+// aborting the turn when ctx ends or timeout passes.
+func sendCopilotAndWait(ctx context.Context, session copilotSession, prompt string, timeout time.Duration) (string, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	abortDone := watchCopilotContext(sendCtx, session)
 	defer close(abortDone)
@@ -181,21 +202,16 @@ func (p *CopilotProvider) CreateChatCompletion(ctx context.Context, req ai.ChatR
 	slog.Debug("copilot_session_send_start", "prompt_chars", len(prompt))
 	resp, err := session.SendAndWait(sendCtx, copilot.MessageOptions{Prompt: prompt})
 	if err != nil {
-		return ai.ChatResponse{}, err
+		return "", err
 	}
 	slog.Debug("copilot_session_send_done")
 
-	content := ""
 	if resp != nil {
 		if data, ok := resp.Data.(*copilot.AssistantMessageData); ok {
-			content = data.Content
+			return data.Content, nil
 		}
 	}
-
-	return ai.ChatResponse{
-		Content: content,
-		Model:   model,
-	}, nil
+	return "", nil
 }
 
 // CreateChatCompletionStream sends a streaming chat completion request.
@@ -205,7 +221,7 @@ func (p *CopilotProvider) CreateChatCompletionStream(ctx context.Context, req ai
 		return nil, err
 	}
 
-	systemMsg, prompt, err := buildCopilotPrompt(req)
+	systemMsg, parts, err := buildCopilotPromptParts(req)
 	if err != nil {
 		return nil, err
 	}
@@ -220,24 +236,15 @@ func (p *CopilotProvider) CreateChatCompletionStream(ctx context.Context, req ai
 	)
 	logCopilotUnsupportedOptions(req, p.defaultTemperature, p.defaultMaxTokens)
 
-	if err := p.client.Start(ctx); err != nil {
-		return nil, fmt.Errorf("copilot client start: %w", err)
-	}
-
-	if err := ensureCopilotAuthenticated(ctx, p.client); err != nil {
-		stopCopilotClient(p.client)
-		return nil, err
-	}
-
-	slog.Debug("copilot_session_create_start", "model", model, "streaming", true)
-	session, err := p.client.CreateSession(ctx, newCopilotSessionConfig(model, true, systemMsg))
+	key := copilotSessionKey{model: model, streaming: true}
+	lease, prompt, err := p.pool.acquire(ctx, key, systemMsg, parts, true)
 	if err != nil {
-		stopCopilotClient(p.client)
-		return nil, fmt.Errorf("copilot session create: %w", err)
+		return nil, err
 	}
-	slog.Debug("copilot_session_create_done", "model", model)
 
-	stream := newCopilotStream(ctx, p.client, session)
+	stream := newCopilotStream(ctx, lease, func() (*copilotLease, string, error) {
+		return p.pool.acquire(ctx, key, systemMsg, parts, false)
+	})
 	stream.start(prompt)
 	return stream, nil
 }
@@ -325,8 +332,18 @@ func copilotSystemMessage(content string) *copilot.SystemMessageConfig {
 }
 
 func buildCopilotPrompt(req ai.ChatRequest) (string, string, error) {
+	systemMsg, parts, err := buildCopilotPromptParts(req)
+	if err != nil {
+		return "", "", err
+	}
+	return systemMsg, strings.Join(parts, "\n\n"), nil
+}
+
+// buildCopilotPromptParts returns the system message and the conversation,
+// one labelled part per message.
+func buildCopilotPromptParts(req ai.ChatRequest) (string, []string, error) {
 	if len(req.Messages) == 0 {
-		return "", "", fmt.Errorf("messages are required")
+		return "", nil, fmt.Errorf("messages are required")
 	}
 
 	var systemParts []string
@@ -349,12 +366,9 @@ func buildCopilotPrompt(req ai.ChatRequest) (string, string, error) {
 	}
 
 	if len(promptParts) == 0 {
-		return "", "", fmt.Errorf("messages are required")
+		return "", nil, fmt.Errorf("messages are required")
 	}
-
-	systemMsg := strings.Join(systemParts, "\n\n")
-	prompt := strings.Join(promptParts, "\n\n")
-	return systemMsg, prompt, nil
+	return strings.Join(systemParts, "\n\n"), promptParts, nil
 }
 
 func roleLabel(role string) string {
@@ -375,10 +389,13 @@ func logCopilotUnsupportedOptions(req ai.ChatRequest, defaultTemp float64, defau
 	} else if defaultMaxTokens > 0 {
 		slog.Debug("copilot_option_ignored", "option", "max_tokens", "value", defaultMaxTokens)
 	}
+	if req.TopP != nil {
+		slog.Debug("copilot_option_ignored", "option", "top_p", "value", *req.TopP)
+	}
 }
 
 type copilotStreamEvent struct {
-	delta string
+	event ai.StreamEvent
 	err   error
 	done  bool
 }
@@ -386,31 +403,33 @@ type copilotStreamEvent struct {
 type copilotStream struct {
 	ctx          context.Context
 	events       chan copilotStreamEvent
-	current      string
+	current      ai.StreamEvent
 	err          error
 	cleanupOnce  sync.Once
-	cleanup      func()
-	unsubscribe  func()
-	session      copilotSession
-	sawDelta     bool
-	eventCount   int
-	deltaCount   int
+	closed       chan struct{}
 	closeEvents  func()
 	closeEventMu sync.Once
+
+	mu          sync.Mutex
+	lease       *copilotLease
+	unsubscribe func()
+	// reopen checks out a fresh session when sending on a reused one
+	// fails; it is used at most once.
+	reopen     func() (*copilotLease, string, error)
+	reply      strings.Builder
+	completed  bool
+	sawDelta   bool
+	eventCount int
+	deltaCount int
 }
 
-func newCopilotStream(ctx context.Context, client copilotClient, session copilotSession) *copilotStream {
+func newCopilotStream(ctx context.Context, lease *copilotLease, reopen func() (*copilotLease, string, error)) *copilotStream {
 	events := make(chan copilotStreamEvent, 32)
 	stream := &copilotStream{
 		ctx:    normalizeCopilotContext(ctx),
 		events: events,
-		cleanup: func() {
-			if session != nil {
-				_ = session.Disconnect()
-			}
-			stopCopilotClient(client)
-		},
-		session: session,
+		closed: make(chan struct{}),
+		reopen: reopen,
 	}
 
 	stream.closeEvents = func() {
@@ -419,15 +438,17 @@ func newCopilotStream(ctx context.Context, client copilotClient, session copilot
 		})
 	}
 
-	stream.unsubscribe = session.On(func(event copilot.SessionEvent) {
-		stream.handleEvent(event)
-	})
+	stream.attach(lease)
 
 	if done := stream.ctx.Done(); done != nil {
 		go func() {
-			<-done
+			select {
+			case <-done:
+			case <-stream.closed:
+				return
+			}
 			slog.Debug("copilot_session_abort", "reason", stream.ctx.Err())
-			abortCopilotSession(session)
+			abortCopilotSession(stream.session())
 			stream.sendEvent(copilotStreamEvent{err: stream.ctx.Err(), done: true})
 			stream.closeEvents()
 		}()
@@ -436,10 +457,28 @@ func newCopilotStream(ctx context.Context, client copilotClient, session copilot
 	return stream
 }
 
+// attach makes lease's session the one the stream sends on and listens to.
+func (s *copilotStream) attach(lease *copilotLease) {
+	unsubscribe := lease.Session().On(func(event copilot.SessionEvent) {
+		s.handleEvent(event)
+	})
+	s.mu.Lock()
+	s.lease, s.unsubscribe = lease, unsubscribe
+	s.mu.Unlock()
+}
+
+func (s *copilotStream) session() copilotSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lease.Session()
+}
+
 func (s *copilotStream) start(prompt string) {
 	go func() {
-		slog.Debug("copilot_session_send_start", "prompt_chars", len(prompt))
 		_, err := s.sessionSend(prompt)
+		if err != nil && s.canReopen() {
+			err = s.resend(err)
+		}
 		if err != nil {
 			s.sendEvent(copilotStreamEvent{err: err, done: true})
 			s.closeEvents()
@@ -449,25 +488,95 @@ func (s *copilotStream) start(prompt string) {
 	}()
 }
 
+// canReopen reports whether sending may be retried on a fresh session: the
+// failed session was reused, and nothing has arrived on it.
+func (s *copilotStream) canReopen() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reopen != nil && s.lease.reused && s.eventCount == 0 && s.ctx.Err() == nil
+}
+
+// resend moves the stream to a fresh session after sending on a reused
+// one failed with err.
+func (s *copilotStream) resend(err error) error {
+	slog.Debug("copilot_session_reuse_failed", "error", err)
+	s.mu.Lock()
+	reopen, old, unsubscribe := s.reopen, s.lease, s.unsubscribe
+	s.reopen = nil
+	s.mu.Unlock()
+	unsubscribe()
+	old.Done("", false)
+
+	lease, prompt, err := reopen()
+	if err != nil {
+		return err
+	}
+	s.attach(lease)
+	_, err = s.sessionSend(prompt)
+	return err
+}
+
 func (s *copilotStream) sessionSend(prompt string) (string, error) {
 	if s == nil {
 		return "", fmt.Errorf("stream not initialized")
 	}
-	return s.session.Send(s.ctx, copilot.MessageOptions{Prompt: prompt})
+	slog.Debug("copilot_session_send_start", "prompt_chars", len(prompt))
+	return s.session().Send(s.ctx, copilot.MessageOptions{Prompt: prompt})
 }
 
 func (s *copilotStream) handleEvent(event copilot.SessionEvent) {
+	s.mu.Lock()
 	s.eventCount++
+	s.mu.Unlock()
 	switch event.Type() {
 	case copilot.SessionEventTypeAssistantMessageDelta:
 		if data, ok := event.Data.(*copilot.AssistantMessageDeltaData); ok && data.DeltaContent != "" {
+			s.mu.Lock()
 			s.sawDelta = true
 			s.deltaCount++
-			s.sendEvent(copilotStreamEvent{delta: data.DeltaContent})
+			s.reply.WriteString(data.DeltaContent)
+			s.mu.Unlock()
+			s.sendEvent(copilotStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventContent, Text: data.DeltaContent}})
 		}
 	case copilot.SessionEventTypeAssistantMessage:
-		if data, ok := event.Data.(*copilot.AssistantMessageData); ok && !s.sawDelta && data.Content != "" {
-			s.sendEvent(copilotStreamEvent{delta: data.Content})
+		if data, ok := event.Data.(*copilot.AssistantMessageData); ok && data.Content != "" {
+			s.mu.Lock()
+			whole := !s.sawDelta
+			if whole {
+				s.reply.WriteString(data.Content)
+			}
+			s.mu.Unlock()
+			if whole {
+				s.sendEvent(copilotStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventContent, Text: data.Content}})
+			}
+		}
+	case copilot.SessionEventTypeAssistantReasoningDelta:
+		if data, ok := event.Data.(*copilot.AssistantReasoningDeltaData); ok && data.DeltaContent != "" {
+			s.sendEvent(copilotStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventReasoning, Text: data.DeltaContent}})
+		}
+	case copilot.SessionEventTypeToolExecutionStart:
+		// The SDK runs its own tools; surface them as activity only.
+		if data, ok := event.Data.(*copilot.ToolExecutionStartData); ok && data.ToolName != "" {
+			s.sendEvent(copilotStreamEvent{event: ai.StreamEvent{
+				Kind:       ai.StreamEventToolCall,
+				ToolCallID: data.ToolCallID,
+				ToolName:   data.ToolName,
+			}})
+		}
+	case copilot.SessionEventTypeAssistantUsage:
+		if data, ok := event.Data.(*copilot.AssistantUsageData); ok {
+			usage := ai.Usage{
+				InputTokens:     int(derefInt64(data.InputTokens)),
+				OutputTokens:    int(derefInt64(data.OutputTokens)),
+				ReasoningTokens: int(derefInt64(data.ReasoningTokens)),
+			}
+			if !usage.IsZero() {
+				s.sendEvent(copilotStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventUsage, Usage: usage}})
+			}
+		}
+	case copilot.SessionEventTypeSessionWarning:
+		if data, ok := event.Data.(*copilot.SessionWarningData); ok && strings.TrimSpace(data.Message) != "" {
+			s.sendEvent(copilotStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventNotice, Text: strings.TrimSpace(data.Message)}})
 		}
 	case copilot.SessionEventTypeSessionError:
 		errMsg := "copilot session error"
@@ -478,7 +587,12 @@ func (s *copilotStream) handleEvent(event copilot.SessionEvent) {
 		s.sendEvent(copilotStreamEvent{err: errors.New(errMsg), done: true})
 		s.closeEvents()
 	case copilot.SessionEventTypeSessionIdle:
+		s.mu.Lock()
+		// An aborted turn leaves the session's history without an answer.
+		data, _ := event.Data.(*copilot.SessionIdleData)
+		s.completed = data == nil || data.Aborted == nil || !*data.Aborted
 		slog.Debug("copilot_session_idle", "events", s.eventCount, "deltas", s.deltaCount)
+		s.mu.Unlock()
 		s.sendEvent(copilotStreamEvent{done: true})
 		s.closeEvents()
 	}
@@ -505,27 +619,45 @@ func (s *copilotStream) Next() bool {
 	if evt.done {
 		return false
 	}
-	s.current = evt.delta
+	s.current = evt.event
 	return true
 }
 
 func (s *copilotStream) Content() string {
+	if s.current.Kind != ai.StreamEventContent {
+		return ""
+	}
+	return s.current.Text
+}
+
+func (s *copilotStream) Event() ai.StreamEvent {
 	return s.current
 }
 
+func derefInt64(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
 func (s *copilotStream) Err() error {
 	return s.err
 }
 
 func (s *copilotStream) Close() error {
 	s.cleanupOnce.Do(func() {
+		close(s.closed)
+		s.mu.Lock()
 		slog.Debug("copilot_session_close", "events", s.eventCount, "deltas", s.deltaCount)
-		if s.unsubscribe != nil {
-			s.unsubscribe()
-		}
-		if s.cleanup != nil {
-			s.cleanup()
+		lease, unsubscribe := s.lease, s.unsubscribe
+		reply, completed := s.reply.String(), s.completed
+		s.mu.Unlock()
+		if unsubscribe != nil {
+			unsubscribe()
 		}
+		// A finished turn returns the session to the pool for the next one.
+		lease.Done(reply, completed && s.err == nil)
 		s.closeEvents()
 	})
 	return nil
@@ -552,6 +684,12 @@ func watchCopilotContext(ctx context.Context, session copilotSession) chan struc
 	go func() {
 		select {
 		case <-ctx.Done():
+			select {
+			case <-done:
+				// The send finished first; the session may be pooled by now.
+				return
+			default:
+			}
 			abortCopilotSession(session)
 		case <-done:
 		}
@@ -570,3 +708,4 @@ func abortCopilotSession(session copilotSession) {
 
 // Ensure interface compliance
 var _ ai.Provider = (*CopilotProvider)(nil)
+var _ ai.EventStream = (*copilotStream)(nil)
@@ -0,0 +1,325 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Starting the Copilot client spawns the Copilot CLI, and creating a session
+// is another round trip: together they add seconds to every message. The
+// pool keeps one started client and the sessions of recent conversations.
+// A request whose messages continue a pooled session's conversation sends
+// only its new messages on that session; when the system message has
+// changed since, as it does when the terminal output has, the new one is
+// sent ahead of them. Sessions idle for longer than the
+// idle timeout are disconnected, and the client is stopped once none are
+// left. Any error drops the session, so the next request starts a fresh one.
+
+const (
+	copilotDefaultIdle = 5 * time.Minute
+	// copilotHealthInterval is how long a successful auth check is trusted
+	// before a pooled client is checked again.
+	copilotHealthInterval  = time.Minute
+	copilotMaxIdleSessions = 4
+)
+
+// copilotSessionKey is what a pooled session was created with; a request
+// can only reuse a session created with the same.
+type copilotSessionKey struct {
+	model     string
+	streaming bool
+}
+
+// copilotUpdatedSystem introduces a system message sent on a reused
+// session.
+const copilotUpdatedSystem = "SYSTEM (updated; replaces the earlier system message and terminal context):\n"
+
+type pooledCopilotSession struct {
+	key     copilotSessionKey
+	client  copilotClient
+	session copilotSession
+	system  string // The system message the session last had
+	// history is the prompt parts the session has seen, its answers
+	// included.
+	history  []string
+	lastUsed time.Time
+}
+
+type copilotPool struct {
+	mu          sync.Mutex
+	newClient   func() copilotClient // Nil uses newCopilotClient
+	idleTimeout time.Duration        // Negative turns pooling off
+	now         func() time.Time     // Nil is time.Now
+
+	client    copilotClient
+	checkedAt time.Time
+	idle      []*pooledCopilotSession
+	inUse     int
+	lastUsed  time.Time
+	reaper    *time.Timer
+}
+
+// defaultCopilotPool is shared by every CopilotProvider, as a provider is
+// created per request.
+var defaultCopilotPool = &copilotPool{idleTimeout: copilotDefaultIdle}
+
+// CloseCopilotPool disconnects pooled Copilot sessions and stops the
+// Copilot client. Call it on exit.
+func CloseCopilotPool() {
+	defaultCopilotPool.close()
+}
+
+// copilotLease is a session checked out of the pool for one request.
+type copilotLease struct {
+	pool    *copilotPool
+	entry   *pooledCopilotSession
+	system  string
+	parts   []string
+	reused  bool
+	release sync.Once
+}
+
+// Session returns the leased session.
+func (l *copilotLease) Session() copilotSession {
+	return l.entry.session
+}
+
+// Done returns the session to the pool with reply as its answer, or drops
+// it when ok is false.
+func (l *copilotLease) Done(reply string, ok bool) {
+	l.release.Do(func() {
+		l.pool.put(l, reply, ok)
+	})
+}
+
+func (p *copilotPool) setIdleTimeout(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idleTimeout = d
+}
+
+// acquire checks out a session for a conversation made of system and parts
+// and returns the prompt to send on it. With reuse, a pooled session that has seen the
+// start of the conversation is preferred; otherwise a new session is
+// created.
+func (p *copilotPool) acquire(ctx context.Context, key copilotSessionKey, system string, parts []string, reuse bool) (*copilotLease, string, error) {
+	p.mu.Lock()
+	p.reapLocked()
+	client, fresh, err := p.clientLocked(ctx)
+	if err != nil {
+		p.mu.Unlock()
+		return nil, "", err
+	}
+	var entry *pooledCopilotSession
+	if reuse {
+		entry = p.takeLocked(key, parts)
+	}
+	p.inUse++
+	p.mu.Unlock()
+
+	if entry != nil {
+		slog.Debug("copilot_session_reused", "model", key.model, "new_parts", len(parts)-len(entry.history))
+		prompt := strings.Join(parts[len(entry.history):], "\n\n")
+		if system != entry.system && system != "" {
+			prompt = copilotUpdatedSystem + system + "\n\n" + prompt
+		}
+		return &copilotLease{pool: p, entry: entry, system: system, parts: parts, reused: true}, prompt, nil
+	}
+
+	session, err := createCopilotSession(ctx, client, key, system)
+	if err != nil && !fresh && ctx.Err() == nil {
+		// The pooled client may have died since its last check.
+		slog.Debug("copilot_session_create_retry", "error", err)
+		p.mu.Lock()
+		p.dropClientLocked(client)
+		client, _, err = p.clientLocked(ctx)
+		p.mu.Unlock()
+		if err == nil {
+			session, err = createCopilotSession(ctx, client, key, system)
+		}
+	}
+	if err != nil {
+		p.mu.Lock()
+		p.inUse--
+		p.reapLocked()
+		p.mu.Unlock()
+		return nil, "", err
+	}
+	entry = &pooledCopilotSession{key: key, client: client, session: session}
+	return &copilotLease{pool: p, entry: entry, system: system, parts: parts}, strings.Join(parts, "\n\n"), nil
+}
+
+// clientLocked returns the started client, starting one when there is none
+// and checking a pooled one that has not been checked for a while. fresh
+// reports whether the client was just started.
+func (p *copilotPool) clientLocked(ctx context.Context) (copilotClient, bool, error) {
+	now := p.clock()
+	if p.client != nil {
+		if now.Sub(p.checkedAt) < copilotHealthInterval {
+			return p.client, false, nil
+		}
+		err := ensureCopilotAuthenticated(ctx, p.client)
+		if err == nil {
+			p.checkedAt = now
+			return p.client, false, nil
+		}
+		slog.Debug("copilot_client_unhealthy", "error", err)
+		p.dropClientLocked(p.client)
+	}
+
+	newClient := p.newClient
+	if newClient == nil {
+		newClient = newCopilotClient
+	}
+	client := newClient()
+	if err := client.Start(ctx); err != nil {
+		return nil, false, fmt.Errorf("copilot client start: %w", err)
+	}
+	if err := ensureCopilotAuthenticated(ctx, client); err != nil {
+		stopCopilotClient(client)
+		return nil, false, err
+	}
+	p.client, p.checkedAt = client, now
+	return client, true, nil
+}
+
+func createCopilotSession(ctx context.Context, client copilotClient, key copilotSessionKey, system string) (copilotSession, error) {
+	slog.Debug("copilot_session_create_start", "model", key.model, "streaming", key.streaming)
+	session, err := client.CreateSession(ctx, newCopilotSessionConfig(key.model, key.streaming, system))
+	if err != nil {
+		return nil, fmt.Errorf("copilot session create: %w", err)
+	}
+	slog.Debug("copilot_session_create_done", "model", key.model)
+	return session, nil
+}
+
+// takeLocked removes and returns the idle session with the longest history
+// that parts continue.
+func (p *copilotPool) takeLocked(key copilotSessionKey, parts []string) *pooledCopilotSession {
+	best := -1
+	for i, e := range p.idle {
+		if e.key != key || e.client != p.client || len(e.history) >= len(parts) ||
+			!slices.Equal(e.history, parts[:len(e.history)]) {
+			continue
+		}
+		if best < 0 || len(e.history) > len(p.idle[best].history) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	entry := p.idle[best]
+	p.idle = slices.Delete(p.idle, best, best+1)
+	return entry
+}
+
+func (p *copilotPool) put(l *copilotLease, reply string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse--
+	p.lastUsed = p.clock()
+	entry := l.entry
+	reply = strings.TrimSpace(reply)
+	if !ok || reply == "" || p.idleTimeout < 0 || entry.client != p.client {
+		disconnectCopilotSession(entry.session)
+		p.scheduleReapLocked()
+		return
+	}
+	entry.system = l.system
+	entry.history = append(slices.Clone(l.parts), roleLabel("assistant")+": "+reply)
+	entry.lastUsed = p.lastUsed
+	p.idle = append(p.idle, entry)
+	if len(p.idle) > copilotMaxIdleSessions {
+		disconnectCopilotSession(p.idle[0].session)
+		p.idle = slices.Delete(p.idle, 0, 1)
+	}
+	p.scheduleReapLocked()
+}
+
+// reapLocked disconnects sessions idle for longer than the idle timeout,
+// and stops the client once nothing has used it for as long.
+func (p *copilotPool) reapLocked() {
+	now := p.clock()
+	kept := p.idle[:0]
+	for _, e := range p.idle {
+		if p.idleTimeout >= 0 && now.Sub(e.lastUsed) < p.idleTimeout && e.client == p.client {
+			kept = append(kept, e)
+			continue
+		}
+		slog.Debug("copilot_session_expired", "model", e.key.model)
+		disconnectCopilotSession(e.session)
+	}
+	clear(p.idle[len(kept):])
+	p.idle = kept
+	if p.client != nil && len(p.idle) == 0 && p.inUse == 0 &&
+		(p.idleTimeout < 0 || now.Sub(p.lastUsed) >= p.idleTimeout) {
+		slog.Debug("copilot_client_idle_stop")
+		stopCopilotClient(p.client)
+		p.client = nil
+	}
+}
+
+func (p *copilotPool) scheduleReapLocked() {
+	if p.reaper != nil {
+		p.reaper.Stop()
+	}
+	if p.idleTimeout < 0 {
+		p.reapLocked()
+		return
+	}
+	p.reaper = time.AfterFunc(p.idleTimeout, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.reapLocked()
+		if len(p.idle) > 0 || p.client != nil && p.inUse == 0 {
+			p.scheduleReapLocked()
+		}
+	})
+}
+
+// dropClientLocked stops client and disconnects its idle sessions.
+// Sessions checked out on it are dropped when they are returned.
+func (p *copilotPool) dropClientLocked(client copilotClient) {
+	if p.client != client {
+		return
+	}
+	for _, e := range p.idle {
+		disconnectCopilotSession(e.session)
+	}
+	p.idle = nil
+	stopCopilotClient(p.client)
+	p.client = nil
+}
+
+func (p *copilotPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reaper != nil {
+		p.reaper.Stop()
+	}
+	if p.client != nil {
+		p.dropClientLocked(p.client)
+	}
+}
+
+func (p *copilotPool) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+func disconnectCopilotSession(session copilotSession) {
+	if session == nil {
+		return
+	}
+	if err := session.Disconnect(); err != nil {
+		slog.Debug("copilot_session_disconnect_error", "error", err)
+	}
+}
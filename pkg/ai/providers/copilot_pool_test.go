@@ -0,0 +1,251 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/ai"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+type fakeCopilotClient struct {
+	mu       sync.Mutex
+	starts   int
+	stops    int
+	sessions []*fakeCopilotSession
+	failNext bool // The next session's first send fails
+}
+
+func (c *fakeCopilotClient) Start(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.starts++
+	return nil
+}
+
+func (c *fakeCopilotClient) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stops++
+	return nil
+}
+
+func (c *fakeCopilotClient) GetAuthStatus(context.Context) (*copilot.GetAuthStatusResponse, error) {
+	return &copilot.GetAuthStatusResponse{IsAuthenticated: true}, nil
+}
+
+func (c *fakeCopilotClient) CreateSession(_ context.Context, cfg *copilot.SessionConfig) (copilotSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := &fakeCopilotSession{id: len(c.sessions) + 1, config: cfg, fail: c.failNext}
+	c.failNext = false
+	c.sessions = append(c.sessions, s)
+	return s, nil
+}
+
+type fakeCopilotSession struct {
+	mu           sync.Mutex
+	id           int
+	config       *copilot.SessionConfig
+	prompts      []string
+	handler      copilot.SessionEventHandler
+	fail         bool
+	disconnected bool
+}
+
+func (s *fakeCopilotSession) answer(prompt string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		s.fail = false
+		return "", errors.New("session not found")
+	}
+	s.prompts = append(s.prompts, prompt)
+	return fmt.Sprintf("answer %d.%d", s.id, len(s.prompts)), nil
+}
+
+func (s *fakeCopilotSession) Send(_ context.Context, opts copilot.MessageOptions) (string, error) {
+	reply, err := s.answer(opts.Prompt)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	handler := s.handler
+	s.mu.Unlock()
+	go func() {
+		handler(copilot.SessionEvent{Data: &copilot.AssistantMessageDeltaData{DeltaContent: reply}})
+		handler(copilot.SessionEvent{Data: &copilot.SessionIdleData{}})
+	}()
+	return "msg", nil
+}
+
+func (s *fakeCopilotSession) SendAndWait(_ context.Context, opts copilot.MessageOptions) (*copilot.SessionEvent, error) {
+	reply, err := s.answer(opts.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &copilot.SessionEvent{Data: &copilot.AssistantMessageData{Content: reply}}, nil
+}
+
+func (s *fakeCopilotSession) On(handler copilot.SessionEventHandler) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+	return func() {}
+}
+
+func (s *fakeCopilotSession) Abort(context.Context) error { return nil }
+
+func (s *fakeCopilotSession) Disconnect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disconnected = true
+	return nil
+}
+
+func newPooledCopilotProvider(t *testing.T, idle time.Duration) (*CopilotProvider, *fakeCopilotClient, *time.Time) {
+	t.Helper()
+	client := &fakeCopilotClient{}
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	pool := &copilotPool{
+		newClient:   func() copilotClient { return client },
+		idleTimeout: idle,
+		now:         func() time.Time { return now },
+	}
+	t.Cleanup(pool.close)
+	return &CopilotProvider{defaultModel: "gpt-4o", timeout: time.Second, pool: pool}, client, &now
+}
+
+func chat(system string, turns ...string) ai.ChatRequest {
+	req := ai.ChatRequest{Messages: []ai.Message{{Role: "system", Content: system}}}
+	for i, turn := range turns {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		req.Messages = append(req.Messages, ai.Message{Role: role, Content: turn})
+	}
+	return req
+}
+
+func TestCopilotPool_ReusesSessionAcrossTurns(t *testing.T) {
+	p, client, _ := newPooledCopilotProvider(t, time.Minute)
+	ctx := context.Background()
+
+	resp, err := p.CreateChatCompletion(ctx, chat("rules", "hi"))
+	if err != nil || resp.Content != "answer 1.1" {
+		t.Fatalf("first turn = %q, %v", resp.Content, err)
+	}
+	resp, err = p.CreateChatCompletion(ctx, chat("rules", "hi", "answer 1.1", "and then?"))
+	if err != nil || resp.Content != "answer 1.2" {
+		t.Fatalf("second turn = %q, %v", resp.Content, err)
+	}
+	resp, err = p.CreateChatCompletion(ctx, chat("new output", "hi", "answer 1.1", "and then?", "answer 1.2", "why?"))
+	if err != nil || resp.Content != "answer 1.3" {
+		t.Fatalf("third turn = %q, %v", resp.Content, err)
+	}
+
+	if client.starts != 1 || len(client.sessions) != 1 {
+		t.Fatalf("expected one client start and one session, got %d and %d", client.starts, len(client.sessions))
+	}
+	prompts := client.sessions[0].prompts
+	if prompts[0] != "User: hi" || prompts[1] != "User: and then?" {
+		t.Fatalf("expected only new messages on the reused session, got %q", prompts)
+	}
+	if prompts[2] != copilotUpdatedSystem+"SYSTEM:\nnew output\n\nUser: why?" {
+		t.Fatalf("expected the changed system message ahead of the turn, got %q", prompts[2])
+	}
+
+	// A different conversation gets its own session.
+	if _, err := p.CreateChatCompletion(ctx, chat("rules", "other")); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.sessions) != 2 || client.sessions[1].prompts[0] != "User: other" {
+		t.Fatalf("expected a new session for a new conversation, got %d", len(client.sessions))
+	}
+}
+
+func TestCopilotPool_FallsBackToFreshSession(t *testing.T) {
+	p, client, _ := newPooledCopilotProvider(t, time.Minute)
+	ctx := context.Background()
+	if _, err := p.CreateChatCompletion(ctx, chat("rules", "hi")); err != nil {
+		t.Fatal(err)
+	}
+	client.sessions[0].fail = true
+
+	resp, err := p.CreateChatCompletion(ctx, chat("rules", "hi", "answer 1.1", "more"))
+	if err != nil || resp.Content != "answer 2.1" {
+		t.Fatalf("expected the turn to be answered on a fresh session, got %q, %v", resp.Content, err)
+	}
+	if !client.sessions[0].disconnected {
+		t.Fatal("expected the failed session to be dropped")
+	}
+	if got := client.sessions[1].prompts[0]; got != "User: hi\n\nAssistant: answer 1.1\n\nUser: more" {
+		t.Fatalf("expected the whole conversation on the fresh session, got %q", got)
+	}
+}
+
+func TestCopilotPool_IdleTimeout(t *testing.T) {
+	p, client, now := newPooledCopilotProvider(t, time.Minute)
+	ctx := context.Background()
+	if _, err := p.CreateChatCompletion(ctx, chat("rules", "hi")); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(2 * time.Minute)
+	if _, err := p.CreateChatCompletion(ctx, chat("rules", "hi", "answer 1.1", "more")); err != nil {
+		t.Fatal(err)
+	}
+	if !client.sessions[0].disconnected || client.stops != 1 || client.starts != 2 {
+		t.Fatalf("expected the idle session and client to be dropped, got stops=%d starts=%d", client.stops, client.starts)
+	}
+
+	off, offClient, _ := newPooledCopilotProvider(t, -1)
+	if _, err := off.CreateChatCompletion(ctx, chat("rules", "hi")); err != nil {
+		t.Fatal(err)
+	}
+	if !offClient.sessions[0].disconnected || offClient.stops != 1 {
+		t.Fatal("expected no pooling with a negative idle timeout")
+	}
+}
+
+func TestCopilotPool_StreamReturnsSession(t *testing.T) {
+	p, client, _ := newPooledCopilotProvider(t, time.Minute)
+	ctx := context.Background()
+
+	read := func(req ai.ChatRequest) string {
+		t.Helper()
+		stream, err := p.CreateChatCompletionStream(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stream.Close()
+		text := ""
+		for stream.Next() {
+			text += stream.Content()
+		}
+		if err := stream.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return text
+	}
+
+	if got := read(chat("rules", "hi")); got != "answer 1.1" {
+		t.Fatalf("first stream = %q", got)
+	}
+	if got := read(chat("rules", "hi", "answer 1.1", "more")); got != "answer 1.2" {
+		t.Fatalf("second stream = %q", got)
+	}
+	if len(client.sessions) != 1 {
+		t.Fatalf("expected the stream's session to be reused, got %d sessions", len(client.sessions))
+	}
+
+	client.sessions[0].fail = true
+	if got := read(chat("rules", "hi", "answer 1.1", "more", "answer 1.2", "last")); got != "answer 2.1" {
+		t.Fatalf("expected a fresh session after the reused one failed, got %q", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 
@@ -67,10 +68,27 @@ func NewGoogleProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
 		timeoutSeconds = googleDefaultTimeout
 	}
 
-	client, err := newGoogleClient(context.Background(), &genai.ClientConfig{
+	clientCfg := &genai.ClientConfig{
 		APIKey:  apiKey,
 		Backend: genai.BackendGeminiAPI,
-	})
+	}
+	if overrides := providerCfg.RequestOverrides; !overrides.IsEmpty() {
+		clientCfg.HTTPOptions.ExtraBody = overrides.Body
+		clientCfg.HTTPOptions.Headers = http.Header{}
+		for name, value := range overrides.Headers {
+			clientCfg.HTTPOptions.Headers.Set(name, value)
+		}
+		logRequestOverrides("google", overrides)
+	}
+	if !providerCfg.Transport().IsZero() {
+		// Requests are timed out through their context, not the client.
+		httpClient, err := ai.NewHTTPClient(0, providerCfg.Transport())
+		if err != nil {
+			return nil, err
+		}
+		clientCfg.HTTPClient = httpClient
+	}
+	client, err := newGoogleClient(context.Background(), clientCfg)
 	if err != nil {
 		return nil, fmt.Errorf("create google client: %w", err)
 	}
@@ -216,6 +234,9 @@ func (p *GoogleProvider) buildRequest(req ai.ChatRequest) (string, []*genai.Cont
 	if maxTokens > 0 {
 		config.MaxOutputTokens = int32(maxTokens)
 	}
+	if req.TopP != nil {
+		config.TopP = genai.Ptr(float32(*req.TopP))
+	}
 
 	if len(req.Tools) > 0 {
 		tools, err := toGoogleTools(req.Tools)
@@ -381,15 +402,16 @@ func (p *GoogleProvider) withTimeout(ctx context.Context) (context.Context, cont
 }
 
 type googleStreamEvent struct {
-	delta string
+	event ai.StreamEvent
 	err   error
 	done  bool
 }
 
 type googleStream struct {
 	events     chan googleStreamEvent
-	current    string
+	current    ai.StreamEvent
 	output     string
+	thoughts   string
 	err        error
 	done       bool
 	cancel     context.CancelFunc
@@ -408,6 +430,7 @@ func newGoogleStream(stream iter.Seq2[*genai.GenerateContentResponse, error], ca
 		defer close(s.events)
 		var collected []ai.ToolCall
 		var lastStop string
+		var lastUsage ai.Usage
 		for resp, err := range stream {
 			if err != nil {
 				s.events <- googleStreamEvent{err: err}
@@ -417,26 +440,29 @@ func newGoogleStream(stream iter.Seq2[*genai.GenerateContentResponse, error], ca
 
 			if calls, cerr := extractFunctionCalls(resp); cerr == nil && len(calls) > 0 {
 				collected = append(collected, calls...)
+				for _, call := range calls {
+					s.events <- googleStreamEvent{event: ai.StreamEvent{
+						Kind:       ai.StreamEventToolCall,
+						ToolCallID: call.ID,
+						ToolName:   call.Name,
+					}}
+				}
 			}
 			if reason := extractFinishReason(resp); reason != "" {
 				lastStop = reason
 			}
 
-			fullText := extractVisibleText(resp)
-			if fullText == "" {
-				continue
+			if delta := textDelta(extractThoughtText(resp), &s.thoughts); delta != "" {
+				s.events <- googleStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventReasoning, Text: delta}}
 			}
-
-			delta := fullText
-			if strings.HasPrefix(fullText, s.output) {
-				delta = fullText[len(s.output):]
-				s.output = fullText
-			} else {
-				s.output += delta
+			if delta := textDelta(extractVisibleText(resp), &s.output); delta != "" {
+				s.events <- googleStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventContent, Text: delta}}
 			}
 
-			if delta != "" {
-				s.events <- googleStreamEvent{delta: delta}
+			// Usage metadata is repeated on every chunk; report it when it moves.
+			if usage := extractUsage(resp); !usage.IsZero() && usage != lastUsage {
+				lastUsage = usage
+				s.events <- googleStreamEvent{event: ai.StreamEvent{Kind: ai.StreamEventUsage, Usage: usage}}
 			}
 		}
 		s.publishFinalState(collected, lastStop)
@@ -470,10 +496,7 @@ func (s *googleStream) Next() bool {
 			s.done = true
 			return false
 		}
-		if ev.delta == "" {
-			continue
-		}
-		s.current = ev.delta
+		s.current = ev.event
 		return true
 	}
 
@@ -482,6 +505,13 @@ func (s *googleStream) Next() bool {
 }
 
 func (s *googleStream) Content() string {
+	if s.current.Kind != ai.StreamEventContent {
+		return ""
+	}
+	return s.current.Text
+}
+
+func (s *googleStream) Event() ai.StreamEvent {
 	return s.current
 }
 
@@ -525,6 +555,52 @@ func (p *GoogleProvider) Capabilities() ai.ProviderCapabilities {
 
 // Ensure interface compliance
 var _ ai.Provider = (*GoogleProvider)(nil)
+var _ ai.EventStream = (*googleStream)(nil)
+
+// textDelta returns the new part of a streamed text. Chunks usually carry
+// only the new text, but some carry everything so far; seen tracks what was
+// already returned.
+func textDelta(fullText string, seen *string) string {
+	if fullText == "" {
+		return ""
+	}
+	if strings.HasPrefix(fullText, *seen) {
+		delta := fullText[len(*seen):]
+		*seen = fullText
+		return delta
+	}
+	*seen += fullText
+	return fullText
+}
+
+func extractThoughtText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0] == nil || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if part == nil || !part.Thought || part.Text == "" {
+			continue
+		}
+		sb.WriteString(part.Text)
+	}
+	return sb.String()
+}
+
+func extractUsage(resp *genai.GenerateContentResponse) ai.Usage {
+	if resp == nil || resp.UsageMetadata == nil {
+		return ai.Usage{}
+	}
+	u := resp.UsageMetadata
+	// Gemini counts thoughts separately from candidates; fold them into the
+	// output like the other providers do.
+	return ai.Usage{
+		InputTokens:     int(u.PromptTokenCount),
+		OutputTokens:    int(u.CandidatesTokenCount + u.ThoughtsTokenCount),
+		ReasoningTokens: int(u.ThoughtsTokenCount),
+	}
+}
 
 func extractVisibleText(resp *genai.GenerateContentResponse) string {
 	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0] == nil || resp.Candidates[0].Content == nil {
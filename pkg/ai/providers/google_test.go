@@ -5,6 +5,7 @@ import (
 	"errors"
 	"iter"
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -411,3 +412,46 @@ func TestGoogleProvider_CreateChatCompletionStream_Error(t *testing.T) {
 		t.Fatal("Expected stream error to be reported")
 	}
 }
+
+func TestGoogleProvider_StreamEvents(t *testing.T) {
+	thought := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{
+			Role:  genai.RoleModel,
+			Parts: []*genai.Part{{Text: "Weighing options.", Thought: true}},
+		}}},
+	}
+	answer := googleTextResponse("Done.")
+	answer.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount: 300, CandidatesTokenCount: 12, ThoughtsTokenCount: 30,
+	}
+	stub := &stubGoogleModelsClient{
+		streamSeq: func(yield func(*genai.GenerateContentResponse, error) bool) {
+			if !yield(thought, nil) {
+				return
+			}
+			if !yield(answer, nil) {
+				return
+			}
+			yield(answer, nil) // Repeated usage is not reported twice
+		},
+	}
+	provider := &GoogleProvider{models: stub, defaultModel: "google-default"}
+
+	stream, err := provider.CreateChatCompletionStream(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "stream"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream() error: %v", err)
+	}
+	defer stream.Close()
+
+	events := collectStreamEvents(t, stream)
+	want := []ai.StreamEvent{
+		{Kind: ai.StreamEventReasoning, Text: "Weighing options."},
+		{Kind: ai.StreamEventContent, Text: "Done."},
+		{Kind: ai.StreamEventUsage, Usage: ai.Usage{InputTokens: 300, OutputTokens: 42, ReasoningTokens: 30}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"strings"
 	"time"
 
@@ -72,13 +71,18 @@ func NewOpenAIProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
 		timeout = openAIDefaultTimeout
 	}
 
-	httpClient := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	httpClient, err := ai.NewHTTPClient(time.Duration(timeout)*time.Second, providerCfg.Transport())
+	if err != nil {
+		return nil, err
+	}
 
 	opts := []option.RequestOption{
 		option.WithAPIKey(apiKey),
 		option.WithBaseURL(apiURL),
 		option.WithHTTPClient(httpClient),
 	}
+	opts = append(opts, requestOverrideOptions(providerCfg.RequestOverrides)...)
+	logRequestOverrides("openai", providerCfg.RequestOverrides)
 
 	client := openai.NewClient(opts...)
 
@@ -114,14 +118,19 @@ func (p *OpenAIProvider) CreateChatCompletion(ctx context.Context, req ai.ChatRe
 		return ai.ChatResponse{}, err
 	}
 
-	content := ""
+	content, stopReason := "", ""
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
+		stopReason = resp.Choices[0].FinishReason
+		if refusal := resp.Choices[0].Message.Refusal; refusal != "" && content == "" {
+			content, stopReason = refusal, ai.RefusalStopReason
+		}
 	}
 
 	return ai.ChatResponse{
-		Content: content,
-		Model:   resp.Model,
+		Content:    content,
+		Model:      resp.Model,
+		StopReason: stopReason,
 	}, nil
 }
 
@@ -139,6 +148,8 @@ func (p *OpenAIProvider) CreateChatCompletionStream(ctx context.Context, req ai.
 		"has_temperature", req.Temperature != nil,
 		"has_max_tokens", req.MaxTokens != nil,
 	)
+	// Ask for a final usage chunk so the UI can show token counts.
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
 	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
 	if err := stream.Err(); err != nil {
 		return nil, err
@@ -188,6 +199,9 @@ func (p *OpenAIProvider) buildChatParams(req ai.ChatRequest) (openai.ChatComplet
 	if maxTokens > 0 {
 		params.MaxTokens = openai.Int(int64(maxTokens))
 	}
+	if req.TopP != nil {
+		params.TopP = openai.Float(*req.TopP)
+	}
 
 	if len(req.Tools) > 0 {
 		tools, err := toOpenAIToolUnionParams(req.Tools)
@@ -133,19 +133,26 @@ func argsAsString(raw json.RawMessage) string {
 // optionally an ID, and partial Function.Name/Function.Arguments. We
 // accumulate by Index so the assembled list is whole only after Next() returns
 // false.
+//
+// One chunk can carry several typed events (reasoning, the start of a tool
+// call, content, usage); they are queued and returned one per Next().
 type openaiCompatStream struct {
 	stream         *ssestream.Stream[openai.ChatCompletionChunk]
 	toolCallsByIdx map[int64]*pendingToolCall
 	toolCallOrder  []int64
 	finishReason   string
+	refused        bool // The model sent a refusal instead of content
 	finalized      bool
 	finalizedCalls []ai.ToolCall
+	queued         []ai.StreamEvent
+	current        ai.StreamEvent
 }
 
 type pendingToolCall struct {
 	ID        string
 	Name      string
 	Arguments strings.Builder
+	announced bool // A tool-call event was emitted for it
 }
 
 func newOpenAICompatStream(s *ssestream.Stream[openai.ChatCompletionChunk]) *openaiCompatStream {
@@ -156,27 +163,74 @@ func newOpenAICompatStream(s *ssestream.Stream[openai.ChatCompletionChunk]) *ope
 }
 
 func (s *openaiCompatStream) Next() bool {
-	for s.stream.Next() {
-		chunk := s.stream.Current()
-		if len(chunk.Choices) == 0 {
-			continue
+	for len(s.queued) == 0 {
+		if !s.stream.Next() {
+			return false
 		}
-		choice := chunk.Choices[0]
+		s.absorbChunk(s.stream.Current())
+	}
+	s.current = s.queued[0]
+	s.queued = s.queued[1:]
+	return true
+}
 
-		if choice.FinishReason != "" {
-			s.finishReason = choice.FinishReason
-		}
+func (s *openaiCompatStream) absorbChunk(chunk openai.ChatCompletionChunk) {
+	// Usage arrives on a final chunk with no choices when the request sets
+	// stream_options.include_usage; other chunks carry null.
+	if chunk.JSON.Usage.Valid() {
+		s.queued = append(s.queued, ai.StreamEvent{
+			Kind: ai.StreamEventUsage,
+			Usage: ai.Usage{
+				InputTokens:     int(chunk.Usage.PromptTokens),
+				OutputTokens:    int(chunk.Usage.CompletionTokens),
+				ReasoningTokens: int(chunk.Usage.CompletionTokensDetails.ReasoningTokens),
+			},
+		})
+	}
+	if len(chunk.Choices) == 0 {
+		return
+	}
+	choice := chunk.Choices[0]
 
-		// Accumulate tool-call deltas (don't emit them as text).
-		for _, d := range choice.Delta.ToolCalls {
-			s.absorbToolCallDelta(d)
-		}
+	if choice.FinishReason != "" {
+		s.finishReason = choice.FinishReason
+	}
+
+	if reasoning := deltaReasoning(choice.Delta); reasoning != "" {
+		s.queued = append(s.queued, ai.StreamEvent{Kind: ai.StreamEventReasoning, Text: reasoning})
+	}
 
-		if choice.Delta.Content != "" {
-			return true
+	// Accumulate tool-call deltas (don't emit them as text).
+	for _, d := range choice.Delta.ToolCalls {
+		s.absorbToolCallDelta(d)
+	}
+
+	if choice.Delta.Content != "" {
+		s.queued = append(s.queued, ai.StreamEvent{Kind: ai.StreamEventContent, Text: choice.Delta.Content})
+	}
+	// A refusal comes in place of content; it is shown as the answer.
+	if choice.Delta.Refusal != "" {
+		s.refused = true
+		s.queued = append(s.queued, ai.StreamEvent{Kind: ai.StreamEventContent, Text: choice.Delta.Refusal})
+	}
+}
+
+// deltaReasoning returns the reasoning text of a delta. It is not part of
+// the OpenAI schema: OpenRouter sends it as "reasoning" and several
+// OpenAI-compatible servers as "reasoning_content".
+func deltaReasoning(delta openai.ChatCompletionChunkChoiceDelta) string {
+	for _, key := range []string{"reasoning", "reasoning_content"} {
+		// The SDK marks fields outside its schema invalid but keeps the raw JSON.
+		field, ok := delta.JSON.ExtraFields[key]
+		if !ok || field.Raw() == "" {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal([]byte(field.Raw()), &text); err == nil && text != "" {
+			return text
 		}
 	}
-	return false
+	return ""
 }
 
 func (s *openaiCompatStream) absorbToolCallDelta(d openai.ChatCompletionChunkChoiceDeltaToolCall) {
@@ -196,14 +250,25 @@ func (s *openaiCompatStream) absorbToolCallDelta(d openai.ChatCompletionChunkCho
 	if d.Function.Arguments != "" {
 		pending.Arguments.WriteString(d.Function.Arguments)
 	}
+	if !pending.announced && pending.Name != "" {
+		pending.announced = true
+		s.queued = append(s.queued, ai.StreamEvent{
+			Kind:       ai.StreamEventToolCall,
+			ToolCallID: pending.ID,
+			ToolName:   pending.Name,
+		})
+	}
 }
 
 func (s *openaiCompatStream) Content() string {
-	chunk := s.stream.Current()
-	if len(chunk.Choices) == 0 {
+	if s.current.Kind != ai.StreamEventContent {
 		return ""
 	}
-	return chunk.Choices[0].Delta.Content
+	return s.current.Text
+}
+
+func (s *openaiCompatStream) Event() ai.StreamEvent {
+	return s.current
 }
 
 func (s *openaiCompatStream) Err() error {
@@ -222,6 +287,9 @@ func (s *openaiCompatStream) ToolCalls() []ai.ToolCall {
 }
 
 func (s *openaiCompatStream) StopReason() string {
+	if s.refused && s.finishReason != "content_filter" {
+		return ai.RefusalStopReason
+	}
 	return s.finishReason
 }
 
@@ -246,6 +314,6 @@ func (s *openaiCompatStream) finalize() {
 	s.finalizedCalls = out
 }
 
-// Compile-time assertion that openaiCompatStream satisfies the ChatStream
+// Compile-time assertion that openaiCompatStream satisfies the EventStream
 // interface.
-var _ ai.ChatStream = (*openaiCompatStream)(nil)
+var _ ai.EventStream = (*openaiCompatStream)(nil)
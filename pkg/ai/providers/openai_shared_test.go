@@ -3,7 +3,9 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -364,3 +366,121 @@ func TestOpenRouterProvider_CapabilitiesReportsTools(t *testing.T) {
 		t.Error("expected Capabilities().Streaming = true")
 	}
 }
+
+func TestOpenRouterProvider_StreamEvents(t *testing.T) {
+	var body map[string]any
+	client := newTestClient(func(req *http.Request) (*http.Response, error) {
+		raw, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(raw, &body)
+
+		chunk := func(delta map[string]any, finish string) map[string]any {
+			return map[string]any{
+				"id": "s", "object": "chat.completion.chunk", "created": 1, "model": "m",
+				"choices": []any{map[string]any{"index": 0, "delta": delta, "finish_reason": finish}},
+			}
+		}
+		chunks := []map[string]any{
+			chunk(map[string]any{"role": "assistant", "reasoning": "Think first."}, ""),
+			chunk(map[string]any{"content": "Let me look."}, ""),
+			chunk(map[string]any{"tool_calls": []any{map[string]any{
+				"index": 0, "id": "call_a", "type": "function",
+				"function": map[string]any{"name": "read_file", "arguments": `{"path":`},
+			}}}, ""),
+			chunk(map[string]any{"tool_calls": []any{map[string]any{
+				"index": 0, "function": map[string]any{"arguments": `"a"}`},
+			}}}, "tool_calls"),
+			{
+				"id": "s", "object": "chat.completion.chunk", "created": 1, "model": "m",
+				"choices": []any{},
+				"usage": map[string]any{
+					"prompt_tokens": 900, "completion_tokens": 60, "total_tokens": 960,
+					"completion_tokens_details": map[string]any{"reasoning_tokens": 20},
+				},
+			},
+		}
+		var sb strings.Builder
+		for _, c := range chunks {
+			b, _ := json.Marshal(c)
+			sb.WriteString("data: ")
+			sb.Write(b)
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("data: [DONE]\n\n")
+		return newHTTPResponse(req, http.StatusOK, "text/event-stream", []byte(sb.String())), nil
+	})
+
+	cfg := config.OpenRouterConfig{
+		APIKey: "k", APIURL: "https://or.test", Model: "test",
+		Temperature: 0.5, MaxTokens: 100, APITimeoutSeconds: 5,
+	}
+	provider, err := newOpenRouterProviderWithHTTPClient(cfg, client)
+	if err != nil {
+		t.Fatalf("provider: %v", err)
+	}
+	stream, err := provider.CreateChatCompletionStream(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "go"}},
+		Tools:    []ai.ToolDefinition{{Name: "read_file", JSONSchema: json.RawMessage(`{}`)}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer stream.Close()
+
+	events := collectStreamEvents(t, stream)
+	want := []ai.StreamEvent{
+		{Kind: ai.StreamEventReasoning, Text: "Think first."},
+		{Kind: ai.StreamEventContent, Text: "Let me look."},
+		{Kind: ai.StreamEventToolCall, ToolCallID: "call_a", ToolName: "read_file"},
+		{Kind: ai.StreamEventUsage, Usage: ai.Usage{InputTokens: 900, OutputTokens: 60, ReasoningTokens: 20}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	if calls := stream.ToolCalls(); len(calls) != 1 || string(calls[0].Arguments) != `{"path":"a"}` {
+		t.Fatalf("ToolCalls() = %+v, want one call with assembled arguments", calls)
+	}
+
+	opts, _ := body["stream_options"].(map[string]any)
+	if opts["include_usage"] != true {
+		t.Errorf("stream_options = %v, want include_usage=true", body["stream_options"])
+	}
+}
+
+func TestOpenRouterProvider_StreamRefusal(t *testing.T) {
+	var body map[string]any
+	client := newTestClient(func(req *http.Request) (*http.Response, error) {
+		raw, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(raw, &body)
+		chunk, _ := json.Marshal(map[string]any{
+			"id": "s", "object": "chat.completion.chunk", "created": 1, "model": "m",
+			"choices": []any{map[string]any{"index": 0, "delta": map[string]any{"refusal": "I can't help with that."}, "finish_reason": "stop"}},
+		})
+		return newHTTPResponse(req, http.StatusOK, "text/event-stream", []byte("data: "+string(chunk)+"\n\ndata: [DONE]\n\n")), nil
+	})
+
+	cfg := config.OpenRouterConfig{APIKey: "k", APIURL: "https://or.test", Model: "test", APITimeoutSeconds: 5}
+	provider, err := newOpenRouterProviderWithHTTPClient(cfg, client)
+	if err != nil {
+		t.Fatalf("provider: %v", err)
+	}
+	topP := 0.9
+	stream, err := provider.CreateChatCompletionStream(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "go"}},
+		TopP:     &topP,
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer stream.Close()
+
+	events := collectStreamEvents(t, stream)
+	if want := []ai.StreamEvent{{Kind: ai.StreamEventContent, Text: "I can't help with that."}}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v, want the refusal as content", events)
+	}
+	if got := stream.StopReason(); got != ai.RefusalStopReason {
+		t.Fatalf("StopReason() = %q, want %q", got, ai.RefusalStopReason)
+	}
+	if body["top_p"] != 0.9 {
+		t.Errorf("top_p = %v, want 0.9", body["top_p"])
+	}
+}
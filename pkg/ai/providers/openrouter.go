@@ -32,18 +32,24 @@ type OpenRouterProvider struct {
 	defaultModel       string
 	defaultTemperature float64
 	defaultMaxTokens   int
+	routing            map[string]any // Extra body fields for provider routing
 }
 
 // NewOpenRouterProvider creates a new OpenRouter provider from config.
 func NewOpenRouterProvider(cfg ai.ProviderConfig) (ai.Provider, error) {
-	orCfg := cfg.Config.OpenRouter
-	httpClient := &http.Client{Timeout: time.Duration(orCfg.APITimeoutSeconds) * time.Second}
-	return newOpenRouterProviderWithHTTPClient(orCfg, httpClient)
+	provider, err := NewOpenRouterProviderFromConfig(cfg.Config.OpenRouter)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
 }
 
 // NewOpenRouterProviderFromConfig creates a provider directly from OpenRouterConfig.
 func NewOpenRouterProviderFromConfig(cfg config.OpenRouterConfig) (*OpenRouterProvider, error) {
-	httpClient := &http.Client{Timeout: time.Duration(cfg.APITimeoutSeconds) * time.Second}
+	httpClient, err := ai.NewHTTPClient(time.Duration(cfg.APITimeoutSeconds)*time.Second, cfg.Transport())
+	if err != nil {
+		return nil, err
+	}
 	return newOpenRouterProviderWithHTTPClient(cfg, httpClient)
 }
 
@@ -78,6 +84,8 @@ func newOpenRouterProviderWithHTTPClient(cfg config.OpenRouterConfig, httpClient
 		httpClient = &http.Client{Timeout: time.Duration(cfg.APITimeoutSeconds) * time.Second}
 	}
 	opts = append(opts, option.WithHTTPClient(httpClient))
+	opts = append(opts, requestOverrideOptions(cfg.RequestOverrides)...)
+	logRequestOverrides("openrouter", cfg.RequestOverrides)
 
 	client := openai.NewClient(opts...)
 
@@ -85,15 +93,37 @@ func newOpenRouterProviderWithHTTPClient(cfg config.OpenRouterConfig, httpClient
 		"api_url", cfg.APIURL,
 		"model", cfg.Model,
 		"timeout_seconds", cfg.APITimeoutSeconds,
+		"provider_order", cfg.Provider.Order,
+		"data_collection", cfg.Provider.DataCollection,
+		"zdr", cfg.Provider.ZDR,
+		"route", cfg.Route,
 	)
 	return &OpenRouterProvider{
 		client:             client,
 		defaultModel:       cfg.Model,
 		defaultTemperature: cfg.Temperature,
 		defaultMaxTokens:   cfg.MaxTokens,
+		routing:            openRouterRoutingFields(cfg),
 	}, nil
 }
 
+// openRouterRoutingFields returns the `provider` and `route` request fields
+// for the configured routing preferences, or nil when none are set.
+// request_overrides body fields of the same name still take precedence.
+func openRouterRoutingFields(cfg config.OpenRouterConfig) map[string]any {
+	fields := map[string]any{}
+	if !cfg.Provider.IsZero() {
+		fields["provider"] = cfg.Provider
+	}
+	if route := strings.TrimSpace(cfg.Route); route != "" {
+		fields["route"] = route
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
 // CreateChatCompletion sends a non-streaming chat completion request.
 func (p *OpenRouterProvider) CreateChatCompletion(ctx context.Context, req ai.ChatRequest) (ai.ChatResponse, error) {
 	params, err := p.buildChatParams(req)
@@ -112,14 +142,19 @@ func (p *OpenRouterProvider) CreateChatCompletion(ctx context.Context, req ai.Ch
 		return ai.ChatResponse{}, err
 	}
 
-	content := ""
+	content, stopReason := "", ""
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
+		stopReason = resp.Choices[0].FinishReason
+		if refusal := resp.Choices[0].Message.Refusal; refusal != "" && content == "" {
+			content, stopReason = refusal, ai.RefusalStopReason
+		}
 	}
 
 	return ai.ChatResponse{
-		Content: content,
-		Model:   resp.Model,
+		Content:    content,
+		Model:      resp.Model,
+		StopReason: stopReason,
 	}, nil
 }
 
@@ -137,6 +172,8 @@ func (p *OpenRouterProvider) CreateChatCompletionStream(ctx context.Context, req
 		"has_temperature", req.Temperature != nil,
 		"has_max_tokens", req.MaxTokens != nil,
 	)
+	// Ask for a final usage chunk so the UI can show token counts.
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
 	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
 	if err := stream.Err(); err != nil {
 		return nil, err
@@ -184,6 +221,9 @@ func (p *OpenRouterProvider) buildChatParams(req ai.ChatRequest) (openai.ChatCom
 	if maxTokens > 0 {
 		params.MaxTokens = openai.Int(int64(maxTokens))
 	}
+	if req.TopP != nil {
+		params.TopP = openai.Float(*req.TopP)
+	}
 
 	if len(req.Tools) > 0 {
 		tools, err := toOpenAIToolUnionParams(req.Tools)
@@ -194,6 +234,10 @@ func (p *OpenRouterProvider) buildChatParams(req ai.ChatRequest) (openai.ChatCom
 		params.ToolChoice = toOpenAIToolChoice(req.ToolChoice)
 	}
 
+	if p.routing != nil {
+		params.SetExtraFields(p.routing)
+	}
+
 	return params, nil
 }
 
@@ -9,6 +9,7 @@ import (
 	"math"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -704,3 +705,66 @@ func withTempHome(t *testing.T) {
 		os.Setenv("HOME", oldHome)
 	})
 }
+
+// collectStreamEvents drains stream and returns its typed events.
+func collectStreamEvents(t *testing.T, stream ai.ChatStream) []ai.StreamEvent {
+	t.Helper()
+	var events []ai.StreamEvent
+	for stream.Next() {
+		events = append(events, ai.CurrentEvent(stream))
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	return events
+}
+
+func TestAnthropicProvider_StreamEvents(t *testing.T) {
+	client := newTestClient(func(req *http.Request) (*http.Response, error) {
+		var stream strings.Builder
+		for _, data := range []string{
+			`{"type":"message_start","message":{"usage":{"input_tokens":120,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"thinking"}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Check the path."}}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"text"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Reading it."}}`,
+			`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"tu_1","name":"read_file"}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{}"}}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":42}}`,
+			`{"type":"message_stop"}`,
+		} {
+			stream.WriteString("data: " + data + "\n\n")
+		}
+		return newHTTPResponse(req, http.StatusOK, "text/event-stream", []byte(stream.String())), nil
+	})
+
+	provider := &AnthropicProvider{
+		apiKey:           "test-key",
+		apiURL:           "https://api.anthropic.test/v1",
+		httpClient:       client,
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		defaultMaxTokens: 1000,
+	}
+	stream, err := provider.CreateChatCompletionStream(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream() error: %v", err)
+	}
+	defer stream.Close()
+
+	events := collectStreamEvents(t, stream)
+	want := []ai.StreamEvent{
+		{Kind: ai.StreamEventUsage, Usage: ai.Usage{InputTokens: 120, OutputTokens: 1}},
+		{Kind: ai.StreamEventReasoning, Text: "Check the path."},
+		{Kind: ai.StreamEventContent, Text: "Reading it."},
+		{Kind: ai.StreamEventToolCall, ToolCallID: "tu_1", ToolName: "read_file"},
+		{Kind: ai.StreamEventUsage, Usage: ai.Usage{InputTokens: 120, OutputTokens: 42}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	if calls := stream.ToolCalls(); len(calls) != 1 || calls[0].Name != "read_file" {
+		t.Fatalf("ToolCalls() = %+v, want one read_file call", calls)
+	}
+}
@@ -0,0 +1,82 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+
+	"wtf_cli/pkg/config"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// mergeRequestBody merges overrides into the top level of a JSON object
+// body. Override values replace fields of the same name.
+func mergeRequestBody(body []byte, overrides map[string]any) ([]byte, error) {
+	if len(overrides) == 0 {
+		return body, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("apply request_overrides: %w", err)
+	}
+	maps.Copy(fields, overrides)
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("apply request_overrides: %w", err)
+	}
+	return merged, nil
+}
+
+// requestOverrideOptions converts overrides into openai-go client options.
+// Body fields are merged by a middleware so only requests that carry a JSON
+// body (i.e. completions, not model listings) are touched.
+func requestOverrideOptions(overrides config.RequestOverrides) []option.RequestOption {
+	if overrides.IsEmpty() {
+		return nil
+	}
+	var opts []option.RequestOption
+	for _, name := range slices.Sorted(maps.Keys(overrides.Headers)) {
+		opts = append(opts, option.WithHeader(name, overrides.Headers[name]))
+	}
+	if len(overrides.Body) > 0 {
+		opts = append(opts, option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			if req.Body == nil || req.Method != http.MethodPost {
+				return next(req)
+			}
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			merged, err := mergeRequestBody(body, overrides.Body)
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(merged))
+			req.ContentLength = int64(len(merged))
+			req.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(merged)), nil
+			}
+			return next(req)
+		}))
+	}
+	return opts
+}
+
+// logRequestOverrides records which overrides a provider applies. Only
+// names are logged; values may carry secrets.
+func logRequestOverrides(provider string, overrides config.RequestOverrides) {
+	if overrides.IsEmpty() {
+		return
+	}
+	slog.Info(provider+"_request_overrides",
+		"body_fields", slices.Sorted(maps.Keys(overrides.Body)),
+		"headers", slices.Sorted(maps.Keys(overrides.Headers)),
+	)
+}
@@ -0,0 +1,242 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+)
+
+func TestMergeRequestBody(t *testing.T) {
+	body := []byte(`{"model":"m","temperature":0.5}`)
+
+	merged, err := mergeRequestBody(body, map[string]any{"temperature": 0.1, "top_k": 40})
+	if err != nil {
+		t.Fatalf("mergeRequestBody() error: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(merged, &got); err != nil {
+		t.Fatalf("merged body is not JSON: %v", err)
+	}
+	if got["model"] != "m" {
+		t.Fatalf("Expected model to be kept, got %v", got["model"])
+	}
+	if got["temperature"] != 0.1 {
+		t.Fatalf("Expected temperature override 0.1, got %v", got["temperature"])
+	}
+	if got["top_k"] != float64(40) {
+		t.Fatalf("Expected top_k 40, got %v", got["top_k"])
+	}
+
+	unchanged, err := mergeRequestBody(body, nil)
+	if err != nil {
+		t.Fatalf("mergeRequestBody(nil) error: %v", err)
+	}
+	if string(unchanged) != string(body) {
+		t.Fatalf("Expected body unchanged without overrides, got %s", unchanged)
+	}
+
+	if _, err := mergeRequestBody([]byte("not json"), map[string]any{"a": 1}); err == nil {
+		t.Fatal("Expected error for a non-JSON body")
+	}
+}
+
+func TestOpenRouterProvider_RequestOverrides(t *testing.T) {
+	var gotHeader string
+	var gotPayload map[string]any
+
+	client := newTestClient(func(req *http.Request) (*http.Response, error) {
+		gotHeader = req.Header.Get("X-Custom")
+		if err := json.NewDecoder(req.Body).Decode(&gotPayload); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		_ = req.Body.Close()
+
+		resp := map[string]any{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"created": 1,
+			"model":   "test-model",
+			"choices": []any{
+				map[string]any{
+					"index":         0,
+					"message":       map[string]any{"role": "assistant", "content": "ok"},
+					"finish_reason": "stop",
+				},
+			},
+		}
+		return newJSONResponse(t, req, http.StatusOK, resp), nil
+	})
+
+	cfg := config.OpenRouterConfig{
+		APIKey:            "test-key",
+		APIURL:            "https://openrouter.test",
+		Model:             "test-model",
+		APITimeoutSeconds: 5,
+		RequestOverrides: config.RequestOverrides{
+			Body:    map[string]any{"provider": map[string]any{"order": []any{"groq"}}},
+			Headers: map[string]string{"X-Custom": "yes"},
+		},
+	}
+	provider, err := newOpenRouterProviderWithHTTPClient(cfg, client)
+	if err != nil {
+		t.Fatalf("NewOpenRouterProvider() error: %v", err)
+	}
+
+	if _, err := provider.CreateChatCompletion(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "hello"}},
+	}); err != nil {
+		t.Fatalf("CreateChatCompletion() error: %v", err)
+	}
+
+	if gotHeader != "yes" {
+		t.Fatalf("Expected X-Custom header 'yes', got %q", gotHeader)
+	}
+	if _, ok := gotPayload["provider"]; !ok {
+		t.Fatalf("Expected provider field in payload, got %v", gotPayload)
+	}
+	if gotPayload["model"] != "test-model" {
+		t.Fatalf("Expected model to be kept, got %v", gotPayload["model"])
+	}
+}
+
+func TestAnthropicProvider_RequestOverrides(t *testing.T) {
+	var gotBeta string
+	var gotAPIKey string
+	var gotPayload map[string]any
+
+	client := newTestClient(func(req *http.Request) (*http.Response, error) {
+		gotBeta = req.Header.Get("anthropic-beta")
+		gotAPIKey = req.Header.Get("x-api-key")
+		if err := json.NewDecoder(req.Body).Decode(&gotPayload); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		_ = req.Body.Close()
+
+		resp := anthropicResponse{
+			ID:         "msg-1",
+			Type:       "message",
+			Role:       "assistant",
+			Model:      "claude-test",
+			Content:    []anthropicResponseBlock{{Type: "text", Text: "ok"}},
+			StopReason: "end_turn",
+		}
+		return newJSONResponse(t, req, http.StatusOK, resp), nil
+	})
+
+	provider := &AnthropicProvider{
+		apiKey:           "test-anthropic-key",
+		apiURL:           "https://api.anthropic.test/v1",
+		httpClient:       client,
+		defaultModel:     "claude-test",
+		defaultMaxTokens: 100,
+		overrides: config.RequestOverrides{
+			Body:    map[string]any{"metadata": map[string]any{"user_id": "u1"}},
+			Headers: map[string]string{"anthropic-beta": "feature-2025"},
+		},
+	}
+
+	if _, err := provider.CreateChatCompletion(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "Hello"}},
+	}); err != nil {
+		t.Fatalf("CreateChatCompletion() error: %v", err)
+	}
+
+	if gotBeta != "feature-2025" {
+		t.Fatalf("Expected anthropic-beta header, got %q", gotBeta)
+	}
+	if gotAPIKey != "test-anthropic-key" {
+		t.Fatalf("Expected x-api-key header to be kept, got %q", gotAPIKey)
+	}
+	metadata, ok := gotPayload["metadata"].(map[string]any)
+	if !ok || metadata["user_id"] != "u1" {
+		t.Fatalf("Expected metadata override in payload, got %v", gotPayload["metadata"])
+	}
+	if gotPayload["model"] != "claude-test" {
+		t.Fatalf("Expected model to be kept, got %v", gotPayload["model"])
+	}
+}
+
+func TestOpenRouterProvider_Routing(t *testing.T) {
+	var gotPayload map[string]any
+
+	client := newTestClient(func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&gotPayload); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		_ = req.Body.Close()
+
+		resp := map[string]any{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"created": 1,
+			"model":   "test-model",
+			"choices": []any{
+				map[string]any{
+					"index":         0,
+					"message":       map[string]any{"role": "assistant", "content": "ok"},
+					"finish_reason": "stop",
+				},
+			},
+		}
+		return newJSONResponse(t, req, http.StatusOK, resp), nil
+	})
+
+	noFallbacks := false
+	cfg := config.OpenRouterConfig{
+		APIKey:            "test-key",
+		APIURL:            "https://openrouter.test",
+		Model:             "test-model",
+		APITimeoutSeconds: 5,
+		Provider: config.OpenRouterProviderPreferences{
+			Order:          []string{"anthropic", "azure"},
+			AllowFallbacks: &noFallbacks,
+			DataCollection: config.OpenRouterDataCollectionDeny,
+			ZDR:            true,
+		},
+		Route: config.OpenRouterRouteFallback,
+	}
+	provider, err := newOpenRouterProviderWithHTTPClient(cfg, client)
+	if err != nil {
+		t.Fatalf("NewOpenRouterProvider() error: %v", err)
+	}
+
+	if _, err := provider.CreateChatCompletion(context.Background(), ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "hello"}},
+	}); err != nil {
+		t.Fatalf("CreateChatCompletion() error: %v", err)
+	}
+
+	prefs, ok := gotPayload["provider"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected provider object in payload, got %v", gotPayload)
+	}
+	order, _ := prefs["order"].([]any)
+	if len(order) != 2 || order[0] != "anthropic" || order[1] != "azure" {
+		t.Fatalf("Expected order [anthropic azure], got %v", prefs["order"])
+	}
+	if prefs["allow_fallbacks"] != false {
+		t.Fatalf("Expected allow_fallbacks false, got %v", prefs["allow_fallbacks"])
+	}
+	if prefs["data_collection"] != "deny" {
+		t.Fatalf("Expected data_collection deny, got %v", prefs["data_collection"])
+	}
+	if prefs["zdr"] != true {
+		t.Fatalf("Expected zdr true, got %v", prefs["zdr"])
+	}
+	if gotPayload["route"] != "fallback" {
+		t.Fatalf("Expected route fallback, got %v", gotPayload["route"])
+	}
+	if gotPayload["model"] != "test-model" {
+		t.Fatalf("Expected model to be kept, got %v", gotPayload["model"])
+	}
+}
+
+func TestOpenRouterRoutingFields_Unset(t *testing.T) {
+	if fields := openRouterRoutingFields(config.OpenRouterConfig{}); fields != nil {
+		t.Fatalf("Expected no routing fields, got %v", fields)
+	}
+}
@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+
+	"wtf_cli/pkg/redact"
+)
+
+// redactingProvider masks credentials in every message before it reaches
+// the wrapped provider. Used when an organization policy forces redaction.
+type redactingProvider struct {
+	Provider
+}
+
+// withForcedRedaction wraps p so requests are redacted.
+func withForcedRedaction(p Provider) Provider {
+	return redactingProvider{Provider: p}
+}
+
+func (p redactingProvider) CreateChatCompletion(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	return p.Provider.CreateChatCompletion(ctx, redactRequest(req))
+}
+
+func (p redactingProvider) CreateChatCompletionStream(ctx context.Context, req ChatRequest) (ChatStream, error) {
+	return p.Provider.CreateChatCompletionStream(ctx, redactRequest(req))
+}
+
+// redactRequest returns req with the content of its messages redacted,
+// leaving the caller's slice untouched.
+func redactRequest(req ChatRequest) ChatRequest {
+	messages := make([]Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = redact.Redact(msg.Content)
+		messages[i] = msg
+	}
+	req.Messages = messages
+	return req
+}
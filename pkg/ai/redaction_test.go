@@ -0,0 +1,34 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type recordingProvider struct {
+	Provider
+	got ChatRequest
+}
+
+func (p *recordingProvider) CreateChatCompletion(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	p.got = req
+	return ChatResponse{}, nil
+}
+
+func TestForcedRedaction_MasksMessages(t *testing.T) {
+	inner := &recordingProvider{}
+	secret := "ghp_" + strings.Repeat("a", 36)
+	messages := []Message{{Role: "user", Content: "export GITHUB_TOKEN=" + secret}}
+
+	_, err := withForcedRedaction(inner).CreateChatCompletion(context.Background(), ChatRequest{Messages: messages})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(inner.got.Messages[0].Content, secret) {
+		t.Fatalf("Expected the token to be redacted, got %q", inner.got.Messages[0].Content)
+	}
+	if !strings.Contains(messages[0].Content, secret) {
+		t.Fatal("Expected the caller's messages to be left unchanged")
+	}
+}
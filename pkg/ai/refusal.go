@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// RefusalStopReason is the stop reason of a stream whose provider marks a
+// refusal in the answer rather than in its finish reason, e.g. OpenAI's
+// refusal field.
+const RefusalStopReason = "refusal"
+
+// refusalMaxRunes caps the answers taken for a refusal from their wording:
+// a long answer that opens with an apology usually goes on to help.
+const refusalMaxRunes = 300
+
+// refusalOpenings start the stock refusals models give, in lower case.
+var refusalOpenings = []string{
+	"i'm sorry, but i can't",
+	"i’m sorry, but i can’t",
+	"i'm sorry, but i cannot",
+	"sorry, but i can't",
+	"i can't help with",
+	"i can’t help with",
+	"i cannot help with",
+	"i can't assist with",
+	"i can’t assist with",
+	"i cannot assist with",
+	"i'm unable to help with",
+	"i'm not able to help with",
+	"i won't help with",
+}
+
+// RefusalReason returns why the provider refused to answer, e.g. "content
+// filter", or "" when the answer is not a refusal. The stop reason decides
+// when the provider reports one; otherwise a short, complete answer that
+// opens with a stock refusal is taken for one.
+func RefusalReason(stopReason, content string) string {
+	switch stopReason {
+	case "content_filter":
+		return "content filter"
+	case RefusalStopReason:
+		return "declined by the model"
+	case "SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST", "SPII", "IMAGE_SAFETY":
+		return "safety filter"
+	}
+	text := strings.TrimSpace(content)
+	// A cut-off answer is not judged by its opening words.
+	if text == "" || TruncationNotice(stopReason) != "" || utf8.RuneCountInString(text) > refusalMaxRunes {
+		return ""
+	}
+	lower := strings.ToLower(text)
+	for _, opening := range refusalOpenings {
+		if strings.HasPrefix(lower, opening) {
+			return "declined by the model"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRefusalReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		stopReason string
+		content    string
+		want       string
+	}{
+		{"openai content filter", "content_filter", "", "content filter"},
+		{"refusal field", RefusalStopReason, "I won't do that.", "declined by the model"},
+		{"gemini safety", "SAFETY", "", "safety filter"},
+		{"stock refusal", "stop", "I'm sorry, but I can't help with that request.", "declined by the model"},
+		{"curly apostrophe", "end_turn", "I can’t help with creating malware.", "declined by the model"},
+		{"normal answer", "stop", "The build failed because make could not find gcc.", ""},
+		{"long answer opening with an apology", "stop", "I'm sorry, but I can't run it for you. " + strings.Repeat("Here is how to fix it. ", 20), ""},
+		{"truncated", "length", "I can't help with", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RefusalReason(tt.stopReason, tt.content); got != tt.want {
+				t.Fatalf("RefusalReason(%q, %q) = %q, want %q", tt.stopReason, tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -152,7 +152,8 @@ func ValidateProviderType(s string) (ProviderType, bool) {
 }
 
 // GetProviderFromConfig creates a provider based on the config's LLMProvider setting.
-// It handles auth manager creation and provider instantiation.
+// It handles auth manager creation and provider instantiation. Requests are
+// redacted when the organization policy forces it.
 func GetProviderFromConfig(cfg config.Config) (Provider, error) {
 	providerType, ok := ValidateProviderType(cfg.LLMProvider)
 	if !ok {
@@ -175,5 +176,9 @@ func GetProviderFromConfig(cfg config.Config) (Provider, error) {
 		AuthManager: authMgr,
 	}
 
-	return GetProvider(providerCfg)
+	provider, err := GetProvider(providerCfg)
+	if err != nil || !cfg.Policy.ForceRedaction {
+		return provider, err
+	}
+	return withForcedRedaction(provider), nil
 }
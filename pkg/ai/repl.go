@@ -0,0 +1,35 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxREPLStatementLen bounds one statement in the metadata, so a pasted
+// query cannot crowd out the terminal output.
+const maxREPLStatementLen = 200
+
+// REPLSession describes the interactive interpreter the user is typing
+// into, such as psql or a Python shell.
+type REPLSession struct {
+	Label      string   // e.g. "PostgreSQL (psql)"
+	Statements []string // Latest statements typed into it, oldest first
+}
+
+// PromptLines renders the session as "key: value" metadata lines.
+func (r REPLSession) PromptLines() []string {
+	if r.Label == "" {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("repl: %s", r.Label)}
+	for _, s := range r.Statements {
+		s = strings.Join(strings.Fields(s), " ")
+		if runes := []rune(s); len(runes) > maxREPLStatementLen {
+			s = string(runes[:maxREPLStatementLen]) + "..."
+		}
+		lines = append(lines, fmt.Sprintf("repl_statement: %s", s))
+	}
+	return lines
+}
+
+const replFieldDefinitions = "repl, when present, names the interactive interpreter the user is inside (e.g. a psql or Python session), which last_command started; repl_statement lines are the latest statements typed into it, oldest first. Suggest statements for that interpreter rather than shell commands, and do not wrap them in <cmd> tags."
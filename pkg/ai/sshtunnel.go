@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"wtf_cli/pkg/config"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshDialTimeout bounds connecting and authenticating to a jump host.
+const sshDialTimeout = 15 * time.Second
+
+var (
+	sshTunnelsMu sync.Mutex
+	sshTunnels   = map[string]*sshTunnel{}
+)
+
+// sshTunnel opens connections from a jump host over one SSH connection,
+// which is made on first use and again after it drops.
+type sshTunnel struct {
+	target string // [user@]host[:port]
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// sshTunnelFor returns the tunnel through target, shared by every
+// transport that uses it.
+func sshTunnelFor(target string) *sshTunnel {
+	sshTunnelsMu.Lock()
+	defer sshTunnelsMu.Unlock()
+	tunnel, ok := sshTunnels[target]
+	if !ok {
+		tunnel = &sshTunnel{target: target}
+		sshTunnels[target] = tunnel
+	}
+	return tunnel
+}
+
+// DialContext connects to addr from the jump host. A failed dial on a
+// connection made earlier is retried once on a new one, since the jump host
+// may have dropped it while idle.
+func (t *sshTunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, reused, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, network, addr)
+	if err == nil || !reused || ctx.Err() != nil {
+		return conn, err
+	}
+	slog.Debug("ssh_tunnel_reconnect", "target", t.target, "error", err)
+	t.drop(client)
+	if client, _, err = t.connect(ctx); err != nil {
+		return nil, err
+	}
+	return client.DialContext(ctx, network, addr)
+}
+
+// connect returns the SSH connection to the jump host, making it when there
+// is none. reused reports whether it was made before this call.
+func (t *sshTunnel) connect(ctx context.Context) (client *ssh.Client, reused bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, true, nil
+	}
+	client, err = dialSSH(ctx, t.target)
+	if err != nil {
+		return nil, false, fmt.Errorf("ssh tunnel %s: %w", t.target, err)
+	}
+	t.client = client
+	go func() {
+		// Forget the connection once it closes so the next dial makes one.
+		_ = client.Wait()
+		t.drop(client)
+	}()
+	slog.Info("ssh_tunnel_connected", "target", t.target)
+	return client, false, nil
+}
+
+func (t *sshTunnel) drop(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client == client {
+		t.client = nil
+	}
+	_ = client.Close()
+}
+
+// dialSSH connects to target with the keys of the local ssh agent,
+// checking its host key against ~/.ssh/known_hosts.
+func dialSSH(ctx context.Context, target string) (*ssh.Client, error) {
+	userName, addr, err := config.ParseSSHTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if userName == "" {
+		if userName, err = localUserName(); err != nil {
+			return nil, err
+		}
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, errors.New("no ssh agent: SSH_AUTH_SOCK is not set")
+	}
+	agentConn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("connect to ssh agent: %w", err)
+	}
+	defer agentConn.Close()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	hostKeys, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("load known_hosts: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sshDialTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            userName,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers)},
+		HostKeyCallback: hostKeys,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+func localUserName() (string, error) {
+	if name := os.Getenv("USER"); name != "" {
+		return name, nil
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("no ssh user: %w", err)
+	}
+	return u.Username, nil
+}
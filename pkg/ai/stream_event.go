@@ -0,0 +1,122 @@
+package ai
+
+// StreamEventKind identifies what a StreamEvent carries.
+type StreamEventKind int
+
+const (
+	// StreamEventContent is a delta of the visible answer.
+	StreamEventContent StreamEventKind = iota
+	// StreamEventReasoning is a delta of the model's reasoning, shown to the
+	// user but never sent back to the model as part of the answer.
+	StreamEventReasoning
+	// StreamEventToolCall reports that the model started composing a tool
+	// call. The assembled call is still only available from ToolCalls().
+	StreamEventToolCall
+	// StreamEventUsage reports token usage for the request so far.
+	StreamEventUsage
+	// StreamEventNotice is an informational message from the provider, e.g.
+	// a warning or a note that the answer was cut off.
+	StreamEventNotice
+)
+
+func (k StreamEventKind) String() string {
+	switch k {
+	case StreamEventReasoning:
+		return "reasoning"
+	case StreamEventToolCall:
+		return "tool_call"
+	case StreamEventUsage:
+		return "usage"
+	case StreamEventNotice:
+		return "notice"
+	}
+	return "content"
+}
+
+// Usage is the token count reported by a provider. Values are totals for
+// the request, so a later report replaces an earlier one.
+type Usage struct {
+	InputTokens     int
+	OutputTokens    int
+	ReasoningTokens int // Included in OutputTokens where the provider reports it
+}
+
+// IsZero reports whether no counts are set.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// Merge returns u with the non-zero counts of other applied, for providers
+// that report input and output tokens in separate events.
+func (u Usage) Merge(other Usage) Usage {
+	if other.InputTokens > 0 {
+		u.InputTokens = other.InputTokens
+	}
+	if other.OutputTokens > 0 {
+		u.OutputTokens = other.OutputTokens
+	}
+	if other.ReasoningTokens > 0 {
+		u.ReasoningTokens = other.ReasoningTokens
+	}
+	return u
+}
+
+// Add returns the sum of u and other, for totalling the provider calls of
+// one agent run.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:     u.InputTokens + other.InputTokens,
+		OutputTokens:    u.OutputTokens + other.OutputTokens,
+		ReasoningTokens: u.ReasoningTokens + other.ReasoningTokens,
+	}
+}
+
+// StreamEvent is one typed event of a streaming response.
+type StreamEvent struct {
+	Kind StreamEventKind
+
+	// Text is the delta for content and reasoning events, or the message of
+	// a notice.
+	Text string
+
+	// ToolCallID and ToolName identify the call of a tool-call event.
+	ToolCallID string
+	ToolName   string
+
+	// Usage is set on usage events.
+	Usage Usage
+}
+
+// EventStream is implemented by streams that report more than text deltas.
+//
+// For an EventStream, Next() advances to the next event of any kind, and
+// Content() returns the text of content events only ("" otherwise), so
+// callers that only read Content() keep seeing the answer text.
+type EventStream interface {
+	ChatStream
+	// Event returns the event Next() advanced to.
+	Event() StreamEvent
+}
+
+// CurrentEvent returns the event stream advanced to. Streams that only
+// carry text report every delta as a content event.
+func CurrentEvent(stream ChatStream) StreamEvent {
+	if es, ok := stream.(EventStream); ok {
+		return es.Event()
+	}
+	return StreamEvent{Kind: StreamEventContent, Text: stream.Content()}
+}
+
+// truncationNotice is reported when a provider stops because the answer hit
+// the max-tokens limit.
+const truncationNotice = "The answer was cut off at the max tokens limit."
+
+// TruncationNotice returns a notice for stop reasons that mean the answer
+// was cut off, or "" for any other reason.
+func TruncationNotice(stopReason string) string {
+	switch stopReason {
+	case "length", "max_tokens", "MAX_TOKENS":
+		return truncationNotice
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package ai
+
+import "testing"
+
+type textOnlyStream struct{ text string }
+
+func (s *textOnlyStream) Next() bool            { return false }
+func (s *textOnlyStream) Content() string       { return s.text }
+func (s *textOnlyStream) Err() error            { return nil }
+func (s *textOnlyStream) Close() error          { return nil }
+func (s *textOnlyStream) ToolCalls() []ToolCall { return nil }
+func (s *textOnlyStream) StopReason() string    { return "" }
+
+func TestCurrentEvent_TextOnlyStreamReportsContent(t *testing.T) {
+	got := CurrentEvent(&textOnlyStream{text: "hi"})
+	if got.Kind != StreamEventContent || got.Text != "hi" {
+		t.Fatalf("CurrentEvent() = %+v, want content event with text", got)
+	}
+}
+
+func TestUsage_MergeAndAdd(t *testing.T) {
+	u := Usage{InputTokens: 100, OutputTokens: 1}.Merge(Usage{OutputTokens: 40})
+	if u != (Usage{InputTokens: 100, OutputTokens: 40}) {
+		t.Fatalf("Merge() = %+v", u)
+	}
+	total := u.Add(Usage{InputTokens: 150, OutputTokens: 10, ReasoningTokens: 5})
+	if total != (Usage{InputTokens: 250, OutputTokens: 50, ReasoningTokens: 5}) {
+		t.Fatalf("Add() = %+v", total)
+	}
+	if !(Usage{}).IsZero() || total.IsZero() {
+		t.Fatal("IsZero() mismatch")
+	}
+}
+
+func TestTruncationNotice(t *testing.T) {
+	for _, reason := range []string{"length", "max_tokens", "MAX_TOKENS"} {
+		if TruncationNotice(reason) == "" {
+			t.Errorf("TruncationNotice(%q) is empty", reason)
+		}
+	}
+	for _, reason := range []string{"", "stop", "end_turn", "tool_calls"} {
+		if got := TruncationNotice(reason); got != "" {
+			t.Errorf("TruncationNotice(%q) = %q, want empty", reason, got)
+		}
+	}
+}
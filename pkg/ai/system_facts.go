@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemFacts describe the state of the machine, so a root cause in the
+// environment (a full disk, low memory, an overloaded host, running as the
+// wrong user) can be told apart from the symptom in the output. Fields are
+// empty when they could not be read.
+type SystemFacts struct {
+	Shell   string
+	User    string
+	CPUs    int
+	LoadAvg string // 1, 5 and 15 minute load averages
+	Memory  string // Available of total, e.g. "1.2 GiB available of 15.5 GiB"
+	Disk    string // Free space of the filesystem holding the working directory
+	Uptime  string
+}
+
+// procDir is where Linux exposes load, memory and uptime. Variable for tests.
+var procDir = "/proc"
+
+// dfTimeout bounds the df call, so a hung network mount does not hold the
+// request.
+const dfTimeout = 2 * time.Second
+
+// CollectSystemFacts reads the facts of this machine. Disk space is read for
+// dir and left out when dir is empty.
+func CollectSystemFacts(dir string) SystemFacts {
+	facts := SystemFacts{
+		Shell: filepath.Base(os.Getenv("SHELL")),
+		CPUs:  runtime.NumCPU(),
+	}
+	if facts.Shell == "." {
+		facts.Shell = ""
+	}
+	if u, err := user.Current(); err == nil {
+		facts.User = u.Username
+	}
+	if data, err := os.ReadFile(filepath.Join(procDir, "loadavg")); err == nil {
+		facts.LoadAvg = parseLoadAvg(string(data))
+	}
+	if data, err := os.ReadFile(filepath.Join(procDir, "meminfo")); err == nil {
+		facts.Memory = parseMeminfo(string(data))
+	}
+	if data, err := os.ReadFile(filepath.Join(procDir, "uptime")); err == nil {
+		facts.Uptime = parseUptime(string(data))
+	}
+	if dir = strings.TrimSpace(dir); dir != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), dfTimeout)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, "df", "-Pk", dir).Output(); err == nil {
+			facts.Disk = parseDf(string(out))
+		}
+	}
+	return facts
+}
+
+// PromptLines returns the facts as metadata lines.
+func (f SystemFacts) PromptLines() []string {
+	var lines []string
+	add := func(name, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("system.%s: %s", name, value))
+		}
+	}
+	add("shell", f.Shell)
+	add("user", f.User)
+	if f.CPUs > 0 {
+		add("cpus", strconv.Itoa(f.CPUs))
+	}
+	add("load_average", f.LoadAvg)
+	add("memory", f.Memory)
+	add("disk", f.Disk)
+	add("uptime", f.Uptime)
+	return lines
+}
+
+const systemFactsFieldDefinitions = "system.* fields, when present, describe the machine: load_average is over 1, 5 and 15 minutes; memory and disk give what is free of the total, disk for the filesystem holding cwd. Consider them as possible causes, e.g. a full disk behind a failed write."
+
+// parseLoadAvg returns the three load averages of /proc/loadavg.
+func parseLoadAvg(data string) string {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return ""
+	}
+	return strings.Join(fields[:3], " ")
+}
+
+// parseMeminfo returns available and total memory from /proc/meminfo.
+func parseMeminfo(data string) string {
+	var total, available int64
+	for _, line := range strings.Split(data, "\n") {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		kib, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "MemTotal":
+			total = kib
+		case "MemAvailable":
+			available = kib
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s available of %s", formatKiB(available), formatKiB(total))
+}
+
+// parseDf returns free and total space from POSIX df -Pk output.
+func parseDf(data string) string {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) < 2 {
+		return ""
+	}
+	// Filesystem 1024-blocks Used Available Capacity Mounted-on; the
+	// filesystem name may contain spaces, so count from the end.
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return ""
+	}
+	n := len(fields)
+	total, err1 := strconv.ParseInt(fields[n-5], 10, 64)
+	free, err2 := strconv.ParseInt(fields[n-3], 10, 64)
+	if err1 != nil || err2 != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s free of %s (%s used) on %s", formatKiB(free), formatKiB(total), fields[n-2], fields[n-1])
+}
+
+// parseUptime returns the uptime of /proc/uptime, rounded to minutes.
+func parseUptime(data string) string {
+	fields := strings.Fields(data)
+	if len(fields) == 0 {
+		return ""
+	}
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return ""
+	}
+	return (time.Duration(secs) * time.Second).Round(time.Minute).String()
+}
+
+func formatKiB(kib int64) string {
+	switch {
+	case kib >= 1<<20:
+		return fmt.Sprintf("%.1f GiB", float64(kib)/(1<<20))
+	case kib >= 1<<10:
+		return fmt.Sprintf("%.1f MiB", float64(kib)/(1<<10))
+	}
+	return fmt.Sprintf("%d KiB", kib)
+}
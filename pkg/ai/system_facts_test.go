@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectSystemFacts_ReadsProc(t *testing.T) {
+	dir := t.TempDir()
+	old := procDir
+	procDir = dir
+	t.Cleanup(func() { procDir = old })
+	os.WriteFile(filepath.Join(dir, "loadavg"), []byte("0.52 0.58 0.59 1/467 12345\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "meminfo"), []byte("MemTotal:       16265612 kB\nMemFree:         1024000 kB\nMemAvailable:    1258291 kB\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "uptime"), []byte("93784.20 180000.00\n"), 0600)
+
+	facts := CollectSystemFacts("")
+	if facts.LoadAvg != "0.52 0.58 0.59" {
+		t.Errorf("LoadAvg = %q", facts.LoadAvg)
+	}
+	if facts.Memory != "1.2 GiB available of 15.5 GiB" {
+		t.Errorf("Memory = %q", facts.Memory)
+	}
+	if facts.Uptime != "26h3m0s" {
+		t.Errorf("Uptime = %q", facts.Uptime)
+	}
+	if facts.Disk != "" {
+		t.Errorf("Disk = %q, want none without a directory", facts.Disk)
+	}
+}
+
+func TestParseDf(t *testing.T) {
+	out := "Filesystem     1024-blocks     Used Available Capacity Mounted on\n" +
+		"/dev/nvme0n1p2   490691512 466157412    0      100% /home\n"
+	if got, want := parseDf(out), "0 KiB free of 468.0 GiB (100% used) on /home"; got != want {
+		t.Errorf("parseDf = %q, want %q", got, want)
+	}
+	if got := parseDf("df: /nope: No such file or directory\n"); got != "" {
+		t.Errorf("parseDf of an error = %q, want empty", got)
+	}
+}
+
+func TestBuildUserPrompt_TaskAndSystemFacts(t *testing.T) {
+	meta := TerminalMetadata{
+		ExitCode: -1,
+		Task:     "Find the root cause.",
+		System:   SystemFacts{Shell: "zsh", Disk: "0 KiB free of 468.0 GiB (100% used) on /home"},
+	}
+	_, ctx := BuildWtfMessages([][]byte{[]byte("write error: No space left on device")}, meta)
+
+	if !strings.HasPrefix(ctx.UserPrompt, "Find the root cause.\n") {
+		t.Errorf("prompt does not start with the task:\n%s", ctx.UserPrompt)
+	}
+	for _, want := range []string{"system.shell: zsh\n", "system.disk: 0 KiB free of 468.0 GiB (100% used) on /home\n"} {
+		if !strings.Contains(ctx.UserPrompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, ctx.UserPrompt)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package ai
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ToolchainInfo describes the language toolchains active for a directory.
+// Fields are empty when nothing was detected.
+type ToolchainInfo struct {
+	PythonVenv    string // Path of the active or project-local virtualenv
+	PythonVersion string // Version from the virtualenv's pyvenv.cfg
+	NodeVersion   string // From .nvmrc or .node-version
+	GoVersion     string // From .go-version or the go.mod go directive
+	RustToolchain string // From rust-toolchain(.toml)
+	Container     string // "docker" or "podman" when running inside a container
+}
+
+// maxToolchainDepth bounds how many parent directories are searched for
+// project markers, so a deep cwd never walks the whole tree on every request.
+const maxToolchainDepth = 8
+
+// containerMarkers maps files that exist only inside containers to the
+// runtime name. Variable for tests.
+var containerMarkers = []struct {
+	path    string
+	runtime string
+}{
+	{"/.dockerenv", "docker"},
+	{"/run/.containerenv", "podman"},
+}
+
+// DetectToolchain inspects dir and its parents for toolchain markers, with
+// virtualEnv the $VIRTUAL_ENV of the user's shell. It only reads small files
+// and never runs external commands, so it is cheap enough to call for every
+// AI request.
+func DetectToolchain(dir, virtualEnv string) ToolchainInfo {
+	var info ToolchainInfo
+
+	if venv := strings.TrimSpace(virtualEnv); venv != "" {
+		info.PythonVenv = venv
+		info.PythonVersion = readPyvenvVersion(venv)
+	}
+
+	dir = strings.TrimSpace(dir)
+	if dir != "" {
+		dir = filepath.Clean(dir)
+		for depth := 0; depth < maxToolchainDepth; depth++ {
+			detectToolchainIn(dir, &info)
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+
+	for _, marker := range containerMarkers {
+		if _, err := os.Stat(marker.path); err == nil {
+			info.Container = marker.runtime
+			break
+		}
+	}
+	return info
+}
+
+// detectToolchainIn fills fields still empty in info from markers in dir.
+// Closer directories win because they are visited first.
+func detectToolchainIn(dir string, info *ToolchainInfo) {
+	if info.PythonVenv == "" {
+		for _, name := range []string{".venv", "venv"} {
+			venv := filepath.Join(dir, name)
+			if _, err := os.Stat(filepath.Join(venv, "pyvenv.cfg")); err == nil {
+				info.PythonVenv = venv
+				info.PythonVersion = readPyvenvVersion(venv)
+				break
+			}
+		}
+	}
+	if info.NodeVersion == "" {
+		for _, name := range []string{".nvmrc", ".node-version"} {
+			if v := readFirstLine(filepath.Join(dir, name)); v != "" {
+				info.NodeVersion = v
+				break
+			}
+		}
+	}
+	if info.GoVersion == "" {
+		if v := readFirstLine(filepath.Join(dir, ".go-version")); v != "" {
+			info.GoVersion = v
+		} else if v := readGoModVersion(filepath.Join(dir, "go.mod")); v != "" {
+			info.GoVersion = v
+		}
+	}
+	if info.RustToolchain == "" {
+		if v := readRustToolchainToml(filepath.Join(dir, "rust-toolchain.toml")); v != "" {
+			info.RustToolchain = v
+		} else if v := readFirstLine(filepath.Join(dir, "rust-toolchain")); v != "" {
+			info.RustToolchain = v
+		}
+	}
+}
+
+// PromptLines renders detected fields as "key: value" metadata lines.
+func (t ToolchainInfo) PromptLines() []string {
+	var lines []string
+	if t.PythonVenv != "" {
+		if t.PythonVersion != "" {
+			lines = append(lines, fmt.Sprintf("python_venv: %s (python %s)", t.PythonVenv, t.PythonVersion))
+		} else {
+			lines = append(lines, fmt.Sprintf("python_venv: %s", t.PythonVenv))
+		}
+	}
+	if t.NodeVersion != "" {
+		lines = append(lines, fmt.Sprintf("node_version: %s", t.NodeVersion))
+	}
+	if t.GoVersion != "" {
+		lines = append(lines, fmt.Sprintf("go_version: %s", t.GoVersion))
+	}
+	if t.RustToolchain != "" {
+		lines = append(lines, fmt.Sprintf("rust_toolchain: %s", t.RustToolchain))
+	}
+	if t.Container != "" {
+		lines = append(lines, fmt.Sprintf("container: %s", t.Container))
+	}
+	return lines
+}
+
+// readFirstLine returns the first non-empty, non-comment line of path.
+func readFirstLine(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// readKeyValue returns the value of the first "key = value" line in path,
+// with surrounding quotes trimmed.
+func readKeyValue(path, key string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || strings.TrimSpace(name) != key {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return ""
+}
+
+func readPyvenvVersion(venv string) string {
+	cfg := filepath.Join(venv, "pyvenv.cfg")
+	if v := readKeyValue(cfg, "version_info"); v != "" {
+		return v
+	}
+	return readKeyValue(cfg, "version")
+}
+
+// readGoModVersion returns the go directive of a go.mod file.
+func readGoModVersion(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+func readRustToolchainToml(path string) string {
+	return readKeyValue(path, "channel")
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeToolchainFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func withContainerMarkers(t *testing.T, paths map[string]string) {
+	t.Helper()
+	orig := containerMarkers
+	containerMarkers = nil
+	for path, runtime := range paths {
+		containerMarkers = append(containerMarkers, struct {
+			path    string
+			runtime string
+		}{path, runtime})
+	}
+	t.Cleanup(func() { containerMarkers = orig })
+}
+
+func TestDetectToolchain_ProjectMarkers(t *testing.T) {
+	withContainerMarkers(t, nil)
+
+	root := t.TempDir()
+	writeToolchainFile(t, filepath.Join(root, ".nvmrc"), "# pinned\nv20.11.0\n")
+	writeToolchainFile(t, filepath.Join(root, "go.mod"), "module example.com/x\n\ngo 1.22.3\n")
+	writeToolchainFile(t, filepath.Join(root, "rust-toolchain.toml"), "[toolchain]\nchannel = \"1.78.0\"\n")
+	writeToolchainFile(t, filepath.Join(root, ".venv", "pyvenv.cfg"), "home = /usr/bin\nversion_info = 3.12.1.final.0\n")
+	sub := filepath.Join(root, "pkg", "inner")
+	writeToolchainFile(t, filepath.Join(sub, ".node-version"), "18.19.0\n")
+
+	info := DetectToolchain(sub, "")
+
+	if info.NodeVersion != "18.19.0" {
+		t.Errorf("NodeVersion = %q, want closest marker 18.19.0", info.NodeVersion)
+	}
+	if info.GoVersion != "1.22.3" {
+		t.Errorf("GoVersion = %q, want 1.22.3", info.GoVersion)
+	}
+	if info.RustToolchain != "1.78.0" {
+		t.Errorf("RustToolchain = %q, want 1.78.0", info.RustToolchain)
+	}
+	if info.PythonVenv != filepath.Join(root, ".venv") || info.PythonVersion != "3.12.1.final.0" {
+		t.Errorf("Python = %q (%q), want project .venv", info.PythonVenv, info.PythonVersion)
+	}
+	if info.Container != "" {
+		t.Errorf("Container = %q, want empty", info.Container)
+	}
+}
+
+func TestDetectToolchain_ActiveVirtualEnvWins(t *testing.T) {
+	withContainerMarkers(t, nil)
+	active := t.TempDir()
+	writeToolchainFile(t, filepath.Join(active, "pyvenv.cfg"), "version = 3.11.4\n")
+	// wtf_cli's own environment does not count; the shell's does.
+	t.Setenv("VIRTUAL_ENV", t.TempDir())
+
+	project := t.TempDir()
+	writeToolchainFile(t, filepath.Join(project, "venv", "pyvenv.cfg"), "version = 3.9.0\n")
+
+	info := DetectToolchain(project, active)
+	if info.PythonVenv != active || info.PythonVersion != "3.11.4" {
+		t.Errorf("Python = %q (%q), want active venv", info.PythonVenv, info.PythonVersion)
+	}
+}
+
+func TestDetectToolchain_Container(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), ".dockerenv")
+	writeToolchainFile(t, marker, "")
+	withContainerMarkers(t, map[string]string{marker: "docker"})
+
+	if got := DetectToolchain("", "").Container; got != "docker" {
+		t.Errorf("Container = %q, want docker", got)
+	}
+}
+
+func TestBuildTerminalContext_IncludesToolchain(t *testing.T) {
+	meta := TerminalMetadata{
+		WorkingDir: "/src",
+		ExitCode:   1,
+		Toolchain:  ToolchainInfo{NodeVersion: "v20.11.0", Container: "docker"},
+	}
+	for _, prompt := range []string{
+		BuildTerminalContext(nil, meta).UserPrompt,
+		BuildChatContext(nil, meta).UserPrompt,
+	} {
+		if !strings.Contains(prompt, "node_version: v20.11.0\n") || !strings.Contains(prompt, "container: docker\n") {
+			t.Errorf("expected toolchain lines in prompt, got %q", prompt)
+		}
+		if strings.Contains(prompt, "go_version") {
+			t.Errorf("expected undetected fields to be omitted, got %q", prompt)
+		}
+	}
+}
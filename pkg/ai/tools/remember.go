@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+)
+
+const (
+	rememberName = "remember"
+
+	rememberDescription = "Remember a lasting fact about the user's project for future conversations, such as a host name, " +
+		"a deploy procedure or a team convention. Use it only when the user states such a fact or asks you to remember something; " +
+		"do not store guesses, secrets or one-off command output. The user approves each call and can review facts with /memories."
+)
+
+var rememberSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "fact": {
+      "type": "string",
+      "description": "The fact as one short self-contained sentence, e.g. \"The staging host is sg-02\"."
+    }
+  },
+  "required": ["fact"]
+}`)
+
+// RememberArgs is the JSON shape the model must produce.
+type RememberArgs struct {
+	Fact string `json:"fact"`
+}
+
+// Remember stores facts in the memory file for one project.
+type Remember struct {
+	Path    string // Memory file, see ai.DefaultMemoriesPath
+	Project string // Project root the facts belong to
+}
+
+// NewRemember returns a remember tool writing to path for project.
+func NewRemember(path, project string) *Remember {
+	return &Remember{Path: path, Project: project}
+}
+
+func (t *Remember) Name() string { return rememberName }
+
+func (t *Remember) Definition() ai.ToolDefinition {
+	return ai.ToolDefinition{
+		Name:        rememberName,
+		Description: rememberDescription,
+		JSONSchema:  rememberSchema,
+	}
+}
+
+func (t *Remember) Execute(ctx context.Context, raw json.RawMessage, _ ExecGrant) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	var args RememberArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return errResult("invalid arguments: %v", err), nil
+	}
+	fact := strings.TrimSpace(args.Fact)
+	if err := ai.AddMemory(t.Path, t.Project, fact); err != nil {
+		return errResult("could not remember: %v", err), nil
+	}
+	return Result{Content: "Remembered: " + fact}, nil
+}
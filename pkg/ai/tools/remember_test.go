@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+)
+
+func TestRemember_StoresFactForProject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memories.json")
+	tool := NewRemember(path, "/work/app")
+
+	res, err := tool.Execute(context.Background(), json.RawMessage(`{"fact":"The staging host is sg-02"}`), ExecGrant{})
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected result %+v, %v", res, err)
+	}
+	if got := ai.LoadMemories(path, "/work/app"); len(got) != 1 || got[0].Text != "The staging host is sg-02" {
+		t.Fatalf("unexpected memories %+v", got)
+	}
+
+	res, err = tool.Execute(context.Background(), json.RawMessage(`{"fact":"  "}`), ExecGrant{})
+	if err != nil || !res.IsError {
+		t.Fatalf("expected a recoverable error for an empty fact, got %+v, %v", res, err)
+	}
+}
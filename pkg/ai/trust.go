@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const workspaceTrustFilename = "workspace_trust.json"
+
+// WorkspaceTrust is the user's decision about how much of a workspace may
+// be sent to a model along with the terminal output.
+type WorkspaceTrust string
+
+const (
+	// TrustUndecided means the user has not been asked yet. It shares as
+	// much as TrustRestricted.
+	TrustUndecided WorkspaceTrust = ""
+	// TrustTrusted shares project files (through the file tools), project
+	// tasks and the environment variables picked in /env.
+	TrustTrusted WorkspaceTrust = "trusted"
+	// TrustRestricted shares what can be told from marker files — project
+	// type and pinned toolchain versions — but no file contents.
+	TrustRestricted WorkspaceTrust = "restricted"
+	// TrustNever shares nothing about the workspace, not even its path.
+	TrustNever WorkspaceTrust = "never"
+)
+
+// SharesFiles reports whether project files, tasks and environment
+// variables may be sent.
+func (t WorkspaceTrust) SharesFiles() bool {
+	return t == TrustTrusted
+}
+
+// SharesSummary reports whether the working directory, project type and
+// toolchain may be sent.
+func (t WorkspaceTrust) SharesSummary() bool {
+	return t != TrustNever
+}
+
+// Valid reports whether t is a decision that can be remembered.
+func (t WorkspaceTrust) Valid() bool {
+	return t == TrustTrusted || t == TrustRestricted || t == TrustNever
+}
+
+// DefaultWorkspaceTrustPath returns the default path for remembered
+// workspace trust decisions.
+func DefaultWorkspaceTrustPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", workspaceTrustFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", workspaceTrustFilename)
+}
+
+// LookupWorkspaceTrust returns the decision covering dir: the one remembered
+// for dir or its nearest parent. A missing or unreadable file means nothing
+// was decided.
+func LookupWorkspaceTrust(path, dir string) WorkspaceTrust {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return TrustUndecided
+	}
+	decisions, err := readWorkspaceTrust(path)
+	if err != nil {
+		return TrustUndecided
+	}
+	for current := filepath.Clean(dir); ; {
+		if trust := WorkspaceTrust(decisions[current]); trust.Valid() {
+			return trust
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return TrustUndecided
+		}
+		current = parent
+	}
+}
+
+// SaveWorkspaceTrust remembers trust for workspace and everything below it.
+// TrustUndecided forgets the workspace, so the user is asked again.
+func SaveWorkspaceTrust(path, workspace string, trust WorkspaceTrust) error {
+	if trust != TrustUndecided && !trust.Valid() {
+		return fmt.Errorf("invalid workspace trust %q", trust)
+	}
+	decisions, err := readWorkspaceTrust(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if decisions == nil {
+		decisions = map[string]string{}
+	}
+	workspace = filepath.Clean(workspace)
+	if trust == TrustUndecided {
+		delete(decisions, workspace)
+	} else {
+		decisions[workspace] = string(trust)
+	}
+
+	data, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal workspace trust: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create workspace trust directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write workspace trust: %w", err)
+	}
+	return nil
+}
+
+func readWorkspaceTrust(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var decisions map[string]string
+	if err := json.Unmarshal(data, &decisions); err != nil {
+		return nil, fmt.Errorf("parse workspace trust: %w", err)
+	}
+	return decisions, nil
+}
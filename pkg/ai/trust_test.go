@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceTrust_NearestDecisionWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workspace_trust.json")
+
+	if got := LookupWorkspaceTrust(path, "/work/app"); got != TrustUndecided {
+		t.Fatalf("missing file: got %q, want undecided", got)
+	}
+	if err := SaveWorkspaceTrust(path, "/work", TrustTrusted); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := SaveWorkspaceTrust(path, "/work/client/", TrustNever); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cases := map[string]WorkspaceTrust{
+		"/work":                TrustTrusted,
+		"/work/app/src":        TrustTrusted,
+		"/work/client":         TrustNever,
+		"/work/client/secrets": TrustNever,
+		"/elsewhere":           TrustUndecided,
+		"":                     TrustUndecided,
+	}
+	for dir, want := range cases {
+		if got := LookupWorkspaceTrust(path, dir); got != want {
+			t.Errorf("LookupWorkspaceTrust(%q) = %q, want %q", dir, got, want)
+		}
+	}
+
+	if err := SaveWorkspaceTrust(path, "/work/client", TrustUndecided); err != nil {
+		t.Fatalf("forget: %v", err)
+	}
+	if got := LookupWorkspaceTrust(path, "/work/client"); got != TrustTrusted {
+		t.Fatalf("after forgetting: got %q, want the parent's decision", got)
+	}
+}
+
+func TestWorkspaceTrust_RejectsUnknownLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workspace_trust.json")
+	if err := SaveWorkspaceTrust(path, "/work", "maybe"); err == nil {
+		t.Fatal("expected an error for an unknown trust level")
+	}
+	if err := os.WriteFile(path, []byte(`{"/work": "maybe"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := LookupWorkspaceTrust(path, "/work"); got != TrustUndecided {
+		t.Fatalf("unknown stored level: got %q, want undecided", got)
+	}
+}
+
+func TestWorkspaceTrust_Shares(t *testing.T) {
+	cases := []struct {
+		trust          WorkspaceTrust
+		files, summary bool
+	}{
+		{TrustTrusted, true, true},
+		{TrustRestricted, false, true},
+		{TrustUndecided, false, true},
+		{TrustNever, false, false},
+	}
+	for _, tc := range cases {
+		if got := tc.trust.SharesFiles(); got != tc.files {
+			t.Errorf("%q.SharesFiles() = %v, want %v", tc.trust, got, tc.files)
+		}
+		if got := tc.trust.SharesSummary(); got != tc.summary {
+			t.Errorf("%q.SharesSummary() = %v, want %v", tc.trust, got, tc.summary)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	usageFilename = "usage.json"
+
+	// usageMonthsKept bounds the history in the usage file.
+	usageMonthsKept = 12
+
+	// BudgetWarnPercent is the share of a monthly budget after which the
+	// user is warned.
+	BudgetWarnPercent = 80
+)
+
+// ProviderUsage is the token usage recorded for a provider in one month.
+type ProviderUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Total returns the tokens counted against a budget.
+func (u ProviderUsage) Total() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// BudgetState says how much of a monthly budget is used.
+type BudgetState int
+
+const (
+	BudgetUnlimited BudgetState = iota // No budget is set
+	BudgetOK
+	BudgetWarn     // At least BudgetWarnPercent used
+	BudgetExceeded // All of it used
+)
+
+// CheckBudget returns the state of a budget of limit tokens with used spent.
+// A limit of zero or less means no budget.
+func CheckBudget(used, limit int) BudgetState {
+	switch {
+	case limit <= 0:
+		return BudgetUnlimited
+	case used >= limit:
+		return BudgetExceeded
+	case used*100 >= limit*BudgetWarnPercent:
+		return BudgetWarn
+	}
+	return BudgetOK
+}
+
+// FormatTokens renders a token count compactly, e.g. "1.2k" or "3.4M".
+func FormatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// DefaultUsagePath returns the default path for recorded token usage.
+func DefaultUsagePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", usageFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", usageFilename)
+}
+
+// UsageMonth returns the key of the month t falls in, e.g. "2026-10".
+func UsageMonth(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// LoadUsage returns the usage recorded per provider in month. A missing or
+// unreadable file means nothing was recorded.
+func LoadUsage(path, month string) map[string]ProviderUsage {
+	usage, err := readUsage(path)
+	if err != nil {
+		return nil
+	}
+	return usage[month]
+}
+
+// RecordUsage adds usage to what provider used in month and returns the new
+// total. Only the last usageMonthsKept months are kept.
+func RecordUsage(path, month, provider string, usage Usage) (ProviderUsage, error) {
+	all, err := readUsage(path)
+	if err != nil && !os.IsNotExist(err) {
+		return ProviderUsage{}, err
+	}
+	if all == nil {
+		all = map[string]map[string]ProviderUsage{}
+	}
+	if all[month] == nil {
+		all[month] = map[string]ProviderUsage{}
+	}
+	total := all[month][provider]
+	total.InputTokens += usage.InputTokens
+	total.OutputTokens += usage.OutputTokens
+	all[month][provider] = total
+
+	months := make([]string, 0, len(all))
+	for m := range all {
+		months = append(months, m)
+	}
+	slices.Sort(months)
+	for _, m := range months[:max(len(months)-usageMonthsKept, 0)] {
+		delete(all, m)
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return ProviderUsage{}, fmt.Errorf("marshal usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return ProviderUsage{}, fmt.Errorf("create usage directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return ProviderUsage{}, fmt.Errorf("write usage: %w", err)
+	}
+	return total, nil
+}
+
+func readUsage(path string) (map[string]map[string]ProviderUsage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var usage map[string]map[string]ProviderUsage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("parse usage: %w", err)
+	}
+	return usage, nil
+}
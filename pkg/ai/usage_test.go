@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUsage_RecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	month := UsageMonth(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	if month != "2026-10" {
+		t.Fatalf("UsageMonth = %q", month)
+	}
+	if got := LoadUsage(path, month); got != nil {
+		t.Fatalf("missing file: got %v", got)
+	}
+
+	if _, err := RecordUsage(path, month, "openrouter", Usage{InputTokens: 1000, OutputTokens: 200}); err != nil {
+		t.Fatal(err)
+	}
+	total, err := RecordUsage(path, month, "openrouter", Usage{InputTokens: 500, OutputTokens: 50, ReasoningTokens: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.InputTokens != 1500 || total.OutputTokens != 250 || total.Total() != 1750 {
+		t.Fatalf("unexpected total %+v", total)
+	}
+	if _, err := RecordUsage(path, "2026-09", "openrouter", Usage{InputTokens: 9}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := LoadUsage(path, month)
+	if got["openrouter"] != total || len(got) != 1 {
+		t.Fatalf("unexpected usage %+v", got)
+	}
+}
+
+func TestUsage_KeepsRecentMonths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	for i := 1; i <= usageMonthsKept+2; i++ {
+		month := fmt.Sprintf("2025-%02d", i)
+		if i > 12 {
+			month = fmt.Sprintf("2026-%02d", i-12)
+		}
+		if _, err := RecordUsage(path, month, "openai", Usage{InputTokens: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := LoadUsage(path, "2025-02"); got != nil {
+		t.Fatalf("expected the oldest months dropped, got %v", got)
+	}
+	if got := LoadUsage(path, "2025-03"); got["openai"].InputTokens != 3 {
+		t.Fatalf("expected 2025-03 kept, got %v", got)
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	tests := []struct {
+		used, limit int
+		want        BudgetState
+	}{
+		{500, 0, BudgetUnlimited},
+		{0, 1000, BudgetOK},
+		{799, 1000, BudgetOK},
+		{800, 1000, BudgetWarn},
+		{1000, 1000, BudgetExceeded},
+		{1200, 1000, BudgetExceeded},
+	}
+	for _, tt := range tests {
+		if got := CheckBudget(tt.used, tt.limit); got != tt.want {
+			t.Errorf("CheckBudget(%d, %d) = %v, want %v", tt.used, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// WorkspaceIndex is a small map of a project: its file tree and the start
+// of the files that tell how to build, test and run it. IndexWorkspace
+// builds it in the background, so a question such as "how do I run the
+// tests here" is answered from the project rather than from guesses.
+type WorkspaceIndex struct {
+	Root      string
+	Files     []string // Slash-separated paths relative to Root, in walk order
+	Total     int      // Files seen, listed or not
+	Truncated bool     // Files were left out: over the file limit or the walk was cut short
+	Excerpts  []WorkspaceExcerpt
+}
+
+// WorkspaceExcerpt is the start of a key file.
+type WorkspaceExcerpt struct {
+	Path      string
+	Text      string
+	Truncated bool // The file goes on past Text
+}
+
+// WorkspaceIndexLimits bound what IndexWorkspace collects.
+type WorkspaceIndexLimits struct {
+	MaxFiles     int // Files listed
+	ExcerptBytes int // Read from each key file
+}
+
+const (
+	// maxWorkspaceEntries bounds the files and directories walked, so a
+	// huge tree costs no more than a large one.
+	maxWorkspaceEntries = 20000
+	// maxWorkspaceExcerpts bounds the key files included.
+	maxWorkspaceExcerpts = 6
+)
+
+// workspaceKeyFiles are the files at the root whose start is included, in
+// order of preference.
+var workspaceKeyFiles = []string{
+	"README.md", "README", "README.rst", "README.txt",
+	"CONTRIBUTING.md",
+	"Makefile", "GNUmakefile", "makefile", "justfile", "Justfile", "Taskfile.yml",
+	"package.json", "go.mod", "Cargo.toml", "pyproject.toml", "tox.ini",
+	"compose.yaml", "docker-compose.yml",
+}
+
+// workspaceSkipDirs are never walked into, even when no .gitignore says so.
+var workspaceSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	".venv":        true,
+	"__pycache__":  true,
+}
+
+// WorkspaceRoot returns the directory to index for dir: the repository it
+// is in, else the closest directory with project markers. It returns ""
+// outside any project, so a home directory is never walked.
+func WorkspaceRoot(dir string) string {
+	root := ProjectRoot(dir)
+	if root == "" {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+		return root
+	}
+	return DetectProject(dir).Root
+}
+
+// IndexWorkspace walks root, leaving out what its .gitignore files ignore,
+// and reads the start of its key files. It stops early when ctx is done.
+func IndexWorkspace(ctx context.Context, root string, limits WorkspaceIndexLimits) WorkspaceIndex {
+	index := WorkspaceIndex{Root: root}
+	var patterns []gitignore.Pattern
+	entries := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			index.Truncated = true
+			return filepath.SkipAll
+		}
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		var parts []string
+		if rel != "." {
+			parts = strings.Split(filepath.ToSlash(rel), "/")
+			if workspaceSkipDirs[d.Name()] && d.IsDir() ||
+				gitignore.NewMatcher(patterns).Match(parts, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if entries++; entries > maxWorkspaceEntries {
+			index.Truncated = true
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			patterns = append(patterns, readGitignore(path, parts)...)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		index.Total++
+		if len(index.Files) < limits.MaxFiles {
+			index.Files = append(index.Files, filepath.ToSlash(rel))
+		} else {
+			index.Truncated = true
+		}
+		return nil
+	})
+
+	for _, name := range workspaceKeyFiles {
+		if len(index.Excerpts) == maxWorkspaceExcerpts || ctx.Err() != nil {
+			break
+		}
+		if excerpt, ok := readExcerpt(filepath.Join(root, name), limits.ExcerptBytes); ok {
+			excerpt.Path = name
+			index.Excerpts = append(index.Excerpts, excerpt)
+		}
+	}
+	return index
+}
+
+// readGitignore reads the patterns of the .gitignore in dir, which is at
+// domain within the root.
+func readGitignore(dir string, domain []string) []gitignore.Pattern {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var patterns []gitignore.Pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+	return patterns
+}
+
+// readExcerpt reads up to limit bytes of the regular text file at path,
+// cut at the last full line.
+func readExcerpt(path string, limit int) (WorkspaceExcerpt, bool) {
+	if limit <= 0 {
+		return WorkspaceExcerpt{}, false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return WorkspaceExcerpt{}, false
+	}
+	defer f.Close()
+	if st, err := f.Stat(); err != nil || !st.Mode().IsRegular() {
+		return WorkspaceExcerpt{}, false
+	}
+	data, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return WorkspaceExcerpt{}, false
+	}
+	excerpt := WorkspaceExcerpt{}
+	if len(data) > limit {
+		data = data[:limit]
+		if i := bytes.LastIndexByte(data, '\n'); i > 0 {
+			data = data[:i]
+		}
+		excerpt.Truncated = true
+	}
+	excerpt.Text = strings.TrimSpace(string(data))
+	return excerpt, excerpt.Text != ""
+}
+
+// Prompt renders the map for the user prompt, or "" when there is none.
+func (w WorkspaceIndex) Prompt() string {
+	if w.Root == "" || (len(w.Files) == 0 && len(w.Excerpts) == 0) {
+		return ""
+	}
+	var sb strings.Builder
+	count := fmt.Sprintf("%d files", w.Total)
+	if w.Truncated {
+		count = fmt.Sprintf("%d of %d+ files", len(w.Files), w.Total)
+	}
+	sb.WriteString(fmt.Sprintf("\n\nWorkspace map (%s under %s, .gitignore applied; use it to ground answers about this project):\n", count, w.Root))
+	for _, file := range w.Files {
+		sb.WriteString(file + "\n")
+	}
+	for _, e := range w.Excerpts {
+		note := ""
+		if e.Truncated {
+			note = " (start)"
+		}
+		sb.WriteString(fmt.Sprintf("--- %s%s ---\n", e.Path, note))
+		sb.WriteString(e.Text + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeWorkspaceFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexWorkspace_AppliesGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFile(t, root, ".gitignore", "# build output\n/dist\n*.log\n")
+	writeWorkspaceFile(t, root, "Makefile", "test:\n\tgo test ./...\n")
+	writeWorkspaceFile(t, root, "cmd/app/main.go", "package main\n")
+	writeWorkspaceFile(t, root, "dist/app", "binary")
+	writeWorkspaceFile(t, root, "debug.log", "noise")
+	writeWorkspaceFile(t, root, "web/.gitignore", "generated/\n")
+	writeWorkspaceFile(t, root, "web/generated/api.ts", "export {}")
+	writeWorkspaceFile(t, root, "web/index.ts", "export {}")
+	writeWorkspaceFile(t, root, "node_modules/left-pad/index.js", "")
+	writeWorkspaceFile(t, root, ".git/HEAD", "ref: refs/heads/main\n")
+
+	index := IndexWorkspace(context.Background(), root, WorkspaceIndexLimits{MaxFiles: 100, ExcerptBytes: 1024})
+
+	want := []string{".gitignore", "Makefile", "cmd/app/main.go", "web/.gitignore", "web/index.ts"}
+	if !slices.Equal(index.Files, want) {
+		t.Fatalf("Files = %v, want %v", index.Files, want)
+	}
+	if index.Truncated || index.Total != len(want) {
+		t.Fatalf("Total = %d, Truncated = %v", index.Total, index.Truncated)
+	}
+	if len(index.Excerpts) != 1 || index.Excerpts[0].Path != "Makefile" || !strings.Contains(index.Excerpts[0].Text, "go test ./...") {
+		t.Fatalf("Excerpts = %+v, want the Makefile", index.Excerpts)
+	}
+}
+
+func TestIndexWorkspace_Limits(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFile(t, root, "README.md", "# Tool\n\nRun the tests with make test.\n"+strings.Repeat("More prose here.\n", 50))
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		writeWorkspaceFile(t, root, name, "package x\n")
+	}
+
+	index := IndexWorkspace(context.Background(), root, WorkspaceIndexLimits{MaxFiles: 2, ExcerptBytes: 64})
+
+	if len(index.Files) != 2 || index.Total != 4 || !index.Truncated {
+		t.Fatalf("Files = %v, Total = %d, Truncated = %v; want 2 of 4 listed", index.Files, index.Total, index.Truncated)
+	}
+	readme := index.Excerpts[0]
+	if !readme.Truncated || len(readme.Text) > 64 || !strings.HasPrefix(readme.Text, "# Tool") || strings.HasSuffix(readme.Text, "More") {
+		t.Fatalf("README excerpt = %+v, want its first full lines", readme)
+	}
+	prompt := index.Prompt()
+	if !strings.Contains(prompt, "Workspace map (2 of 4+ files under "+root) || !strings.Contains(prompt, "--- README.md (start) ---") {
+		t.Fatalf("unexpected prompt:\n%s", prompt)
+	}
+}
+
+func TestWorkspaceRoot(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(repo, "pkg", "x")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := WorkspaceRoot(sub); got != repo {
+		t.Errorf("WorkspaceRoot in a repository = %q, want %q", got, repo)
+	}
+
+	plain := t.TempDir()
+	if got := WorkspaceRoot(plain); got != "" {
+		t.Errorf("WorkspaceRoot outside a project = %q, want empty", got)
+	}
+	writeWorkspaceFile(t, plain, "go.mod", "module x\n")
+	if got := WorkspaceRoot(plain); got != plain {
+		t.Errorf("WorkspaceRoot with go.mod = %q, want %q", got, plain)
+	}
+}
@@ -11,6 +11,7 @@ type CircularBuffer struct {
 	capacity int      // Maximum number of lines
 	size     int      // Current number of lines
 	head     int      // Write position
+	total    int      // Lines ever written; keeps counting across wrap and Clear
 }
 
 // New creates a new circular buffer with the specified capacity (in lines)
@@ -42,6 +43,44 @@ func (cb *CircularBuffer) Write(line []byte) {
 	if cb.size < cb.capacity {
 		cb.size++
 	}
+	cb.total++
+}
+
+// Total returns the number of lines ever written. Use it as a mark to read
+// back what was written after a point in time with LinesBetween.
+func (cb *CircularBuffer) Total() int {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.total
+}
+
+// LinesBetween returns the lines written between marks start and end (as
+// returned by Total), oldest first. Lines that have already been overwritten
+// or cleared are skipped.
+func (cb *CircularBuffer) LinesBetween(start, end int) [][]byte {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	oldest := cb.total - cb.size
+	if start < oldest {
+		start = oldest
+	}
+	if end > cb.total {
+		end = cb.total
+	}
+	if start >= end {
+		return [][]byte{}
+	}
+
+	result := make([][]byte, 0, end-start)
+	for mark := start; mark < end; mark++ {
+		// The line written at mark sits (total - mark) slots behind head.
+		pos := (cb.head - (cb.total - mark) + cb.capacity) % cb.capacity
+		line := make([]byte, len(cb.data[pos]))
+		copy(line, cb.data[pos])
+		result = append(result, line)
+	}
+	return result
 }
 
 // GetLastN retrieves the last N lines from the buffer
@@ -276,3 +276,33 @@ func contains_str(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLinesBetween(t *testing.T) {
+	cb := New(3)
+
+	cb.Write([]byte("line1"))
+	mark := cb.Total()
+	cb.Write([]byte("line2"))
+	cb.Write([]byte("line3"))
+
+	lines := cb.LinesBetween(mark, cb.Total())
+	if len(lines) != 2 || string(lines[0]) != "line2" || string(lines[1]) != "line3" {
+		t.Fatalf("Expected [line2 line3], got %q", lines)
+	}
+
+	// Wrap around: line2 is overwritten, only line3..line5 remain.
+	cb.Write([]byte("line4"))
+	cb.Write([]byte("line5"))
+	lines = cb.LinesBetween(mark, mark+3)
+	if len(lines) != 2 || string(lines[0]) != "line3" || string(lines[1]) != "line4" {
+		t.Fatalf("Expected [line3 line4] after wrap, got %q", lines)
+	}
+
+	cb.Clear()
+	if lines := cb.LinesBetween(mark, cb.Total()); len(lines) != 0 {
+		t.Fatalf("Expected no lines after Clear, got %q", lines)
+	}
+	if cb.Total() != 5 {
+		t.Fatalf("Expected Total to survive Clear, got %d", cb.Total())
+	}
+}
@@ -2,17 +2,20 @@ package capture
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
+	"time"
 )
 
-// detectShell determines which shell is being used based on $SHELL and OS platform.
-// Returns "zsh", "bash", or "unknown".
-func detectShell() string {
-	// Check $SHELL environment variable first
-	shell := os.Getenv("SHELL")
+// detectShell determines which shell is being used based on the $SHELL
+// value and OS platform. Returns "zsh" or "bash".
+func detectShell(shell string) string {
+	// Check $SHELL first
 	if strings.HasSuffix(shell, "/zsh") || strings.HasSuffix(shell, "\\zsh") {
 		return "zsh"
 	}
@@ -29,27 +32,159 @@ func detectShell() string {
 	return "bash" // default fallback
 }
 
+// HistoryFilePath returns the shell history file: $HISTFILE, or the default
+// for the detected shell.
+func HistoryFilePath() (string, error) {
+	return HistoryFilePathFor(os.Getenv("SHELL"), os.Getenv("HISTFILE"))
+}
+
+// HistoryFilePathFor returns the history file of a shell with the given
+// $SHELL and $HISTFILE values, such as those of the wrapped shell's
+// environment: histFile when set, or the default for the shell.
+func HistoryFilePathFor(shell, histFile string) (string, error) {
+	if histFile != "" {
+		return histFile, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if detectShell(shell) == "zsh" {
+		return filepath.Join(homeDir, ".zsh_history"), nil
+	}
+	return filepath.Join(homeDir, ".bash_history"), nil
+}
+
+// historyTailBytes is how much of the history file AppendHistory reads to
+// detect its format and the last entry.
+const historyTailBytes = 4096
+
+// AppendHistory appends command to the history file at path, so a command
+// wtf_cli typed at the prompt can be recalled from plain shells even when
+// the wrapped shell never flushes its history. Entries follow the file's
+// format: zsh extended (": <time>:0;cmd") or bash with "#<time>" lines when
+// the file already uses them, plain lines otherwise. A command equal to the
+// last entry is skipped, since the shell may have written it already.
+// Multi-line commands are skipped; each shell stores them differently.
+func AppendHistory(path, command string, now time.Time) error {
+	command = strings.TrimSpace(command)
+	if command == "" || strings.ContainsAny(command, "\r\n") {
+		return nil
+	}
+
+	last, extended, timestamps, err := historyTail(path)
+	if err != nil {
+		return err
+	}
+	if last == command {
+		return nil
+	}
+
+	var entry string
+	switch {
+	case extended:
+		entry = fmt.Sprintf(": %d:0;%s\n", now.Unix(), command)
+	case timestamps:
+		entry = fmt.Sprintf("#%d\n%s\n", now.Unix(), command)
+	default:
+		entry = command + "\n"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("append history: %w", err)
+	}
+	return nil
+}
+
+// AppendShellHistory appends command to the shell's history file.
+func AppendShellHistory(command string) error {
+	path, err := HistoryFilePath()
+	if err != nil {
+		return err
+	}
+	return AppendHistory(path, command, time.Now())
+}
+
+// historyTail reads the end of a history file and reports its last command
+// and whether it uses zsh extended entries or bash timestamp lines. A
+// missing file is empty.
+func historyTail(path string) (last string, extended, timestamps bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, false, nil
+		}
+		return "", false, false, fmt.Errorf("open history file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", false, false, fmt.Errorf("stat history file: %w", err)
+	}
+	offset := max(info.Size()-historyTailBytes, 0)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return "", false, false, fmt.Errorf("read history file: %w", err)
+	}
+
+	lines := strings.Split(string(buf), "\n")
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:] // Likely cut mid-line
+	}
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, ": ") && strings.Contains(line, ";"):
+			extended = true
+			_, line, _ = strings.Cut(line, ";")
+		case isHistoryTimestamp(line):
+			timestamps = true
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			last = strings.TrimSpace(line)
+		}
+	}
+	return last, extended, timestamps, nil
+}
+
+// isHistoryTimestamp reports whether line is a bash "#<unix time>" line.
+func isHistoryTimestamp(line string) bool {
+	digits, ok := strings.CutPrefix(line, "#")
+	if !ok || digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // ReadBashHistory reads commands from the shell history file.
 // It supports both bash and zsh history formats.
 // It uses $HISTFILE environment variable, falling back to shell-specific defaults.
 // Returns up to maxLines commands in reverse chronological order (most recent first).
 func ReadBashHistory(maxLines int) ([]string, error) {
-	histFile := os.Getenv("HISTFILE")
-	if histFile == "" {
-		// Detect shell and use appropriate default history file
-		shell := detectShell()
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-
-		if shell == "zsh" {
-			histFile = filepath.Join(homeDir, ".zsh_history")
-		} else {
-			histFile = filepath.Join(homeDir, ".bash_history")
-		}
+	histFile, err := HistoryFilePath()
+	if err != nil {
+		return nil, err
 	}
+	return ReadHistoryFile(histFile, maxLines)
+}
 
+// ReadHistoryFile reads commands from the bash or zsh history file at path,
+// most recent first and up to maxLines of them. A missing file is empty.
+func ReadHistoryFile(histFile string, maxLines int) ([]string, error) {
 	file, err := os.Open(histFile)
 	if err != nil {
 		// If history file doesn't exist, return empty list (not an error)
@@ -108,30 +243,93 @@ func ReadBashHistory(maxLines int) ([]string, error) {
 	return reversed, nil
 }
 
-// MergeHistory combines bash history with session history, deduplicating entries.
-// Session history takes precedence (appears first). Most recent items are at the beginning.
-func MergeHistory(bashHistory []string, sessionHistory []CommandRecord) []string {
-	// Use a map to track seen commands (for deduplication)
-	seen := make(map[string]bool)
-	var result []string
+// HistoryEntry is one distinct command of the merged history.
+type HistoryEntry struct {
+	Command  string    // Most recent spelling, trimmed
+	Count    int       // Times it appears across both histories
+	LastUsed time.Time // Latest run this session; zero when only the history file has it
+}
 
-	// First, add session history (most recent session commands)
-	for i := len(sessionHistory) - 1; i >= 0; i-- {
-		cmd := strings.TrimSpace(sessionHistory[i].Command)
-		if cmd != "" && !seen[cmd] {
-			result = append(result, cmd)
-			seen[cmd] = true
+// HistoryFilter hides commands from the merged history.
+type HistoryFilter struct {
+	// Ignore patterns match a command alone or with arguments ("ls" hides
+	// "ls -la"); * matches any text.
+	Ignore []string
+	// IgnoreSpace hides commands typed with a leading space, the ones bash
+	// keeps out of its history file under HISTCONTROL=ignorespace.
+	IgnoreSpace bool
+}
+
+// matcher compiles the ignore patterns into a function reporting whether a
+// raw history line is hidden.
+func (f HistoryFilter) matcher() func(raw string) bool {
+	patterns := make([]*regexp.Regexp, 0, len(f.Ignore))
+	for _, p := range f.Ignore {
+		p = NormalizeHistoryCommand(p)
+		if p == "" {
+			continue
 		}
+		expr := strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+		patterns = append(patterns, regexp.MustCompile("^"+expr+"( .*)?$"))
 	}
+	return func(raw string) bool {
+		if f.IgnoreSpace && strings.HasPrefix(raw, " ") {
+			return true
+		}
+		cmd := NormalizeHistoryCommand(raw)
+		for _, re := range patterns {
+			if re.MatchString(cmd) {
+				return true
+			}
+		}
+		return false
+	}
+}
 
-	// Then add bash history (already in reverse chronological order)
-	for _, cmd := range bashHistory {
-		cmd = strings.TrimSpace(cmd)
-		if cmd != "" && !seen[cmd] {
-			result = append(result, cmd)
-			seen[cmd] = true
+// NormalizeHistoryCommand trims cmd and collapses runs of whitespace, so
+// variants that differ only in spacing compare equal.
+func NormalizeHistoryCommand(cmd string) string {
+	return strings.Join(strings.Fields(cmd), " ")
+}
+
+// MergeHistoryEntries combines session history with shell history (most
+// recent first), one entry per normalized command. Session commands come
+// first; an entry keeps the spelling of its most recent use and counts every
+// use. Commands hidden by filter are left out.
+func MergeHistoryEntries(bashHistory []string, sessionHistory []CommandRecord, filter HistoryFilter) []HistoryEntry {
+	hidden := filter.matcher()
+	index := make(map[string]int)
+	var result []HistoryEntry
+
+	add := func(raw string, used time.Time) {
+		key := NormalizeHistoryCommand(raw)
+		if key == "" || hidden(raw) {
+			return
+		}
+		if i, ok := index[key]; ok {
+			result[i].Count++
+			return
 		}
+		index[key] = len(result)
+		result = append(result, HistoryEntry{Command: strings.TrimSpace(raw), Count: 1, LastUsed: used})
 	}
+	for i := len(sessionHistory) - 1; i >= 0; i-- {
+		add(sessionHistory[i].Command, sessionHistory[i].StartTime)
+	}
+	for _, cmd := range bashHistory {
+		add(cmd, time.Time{})
+	}
+	return result
+}
 
+// MergeHistory combines bash history with session history, deduplicating entries.
+// Session history takes precedence (appears first). Most recent items are at the beginning.
+// See MergeHistoryEntries.
+func MergeHistory(bashHistory []string, sessionHistory []CommandRecord) []string {
+	entries := MergeHistoryEntries(bashHistory, sessionHistory, HistoryFilter{})
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Command)
+	}
 	return result
 }
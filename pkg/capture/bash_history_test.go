@@ -3,6 +3,7 @@ package capture
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -285,3 +286,148 @@ func TestMergeHistory_IgnoreEmptyCommands(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeHistoryEntries_NormalizesAndCounts(t *testing.T) {
+	ran := time.Date(2026, 1, 2, 14, 5, 0, 0, time.UTC)
+	bashHistory := []string{"git  status", "  make test", "git status", "ls"}
+	sessionHistory := []CommandRecord{
+		{Command: "make   test", StartTime: ran.Add(-time.Minute)},
+		{Command: "make test ", StartTime: ran},
+	}
+
+	got := MergeHistoryEntries(bashHistory, sessionHistory, HistoryFilter{})
+	want := []HistoryEntry{
+		{Command: "make test", Count: 3, LastUsed: ran},
+		{Command: "git  status", Count: 2},
+		{Command: "ls", Count: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("MergeHistoryEntries() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMergeHistoryEntries_Filter(t *testing.T) {
+	bashHistory := []string{"ls", "ls -la", "lsof -i :8080", " export TOKEN=x", "cd /tmp", "git push --force", "git pull"}
+	filter := HistoryFilter{Ignore: []string{"ls", "cd", "git * --force"}, IgnoreSpace: true}
+
+	var got []string
+	for _, e := range MergeHistoryEntries(bashHistory, nil, filter) {
+		got = append(got, e.Command)
+	}
+	want := []string{"lsof -i :8080", "git pull"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("filtered history = %q, want %q", got, want)
+	}
+
+	// Without IgnoreSpace the command is kept, trimmed.
+	got = nil
+	for _, e := range MergeHistoryEntries([]string{" export TOKEN=x"}, nil, HistoryFilter{}) {
+		got = append(got, e.Command)
+	}
+	if len(got) != 1 || got[0] != "export TOKEN=x" {
+		t.Fatalf("unfiltered history = %q", got)
+	}
+}
+
+func TestAppendHistory_MatchesFileFormat(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name:     "missing file",
+			existing: "",
+			want:     "make test\n",
+		},
+		{
+			name:     "plain bash",
+			existing: "ls\n",
+			want:     "ls\nmake test\n",
+		},
+		{
+			name:     "bash timestamps",
+			existing: "#1699999999\nls\n",
+			want:     "#1699999999\nls\n#1700000000\nmake test\n",
+		},
+		{
+			name:     "zsh extended",
+			existing: ": 1699999999:0;ls\n",
+			want:     ": 1699999999:0;ls\n: 1700000000:0;make test\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0600); err != nil {
+					t.Fatalf("write history: %v", err)
+				}
+			}
+			if err := AppendHistory(path, "  make test ", now); err != nil {
+				t.Fatalf("AppendHistory failed: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read history: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("history = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendHistory_SkipsDuplicateAndMultiline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	content := ": 1699999999:0;git status\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+
+	for _, command := range []string{"git status", "echo a\necho b", "   "} {
+		if err := AppendHistory(path, command, time.Now()); err != nil {
+			t.Fatalf("AppendHistory(%q) failed: %v", command, err)
+		}
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != content {
+		t.Errorf("history = %q, want unchanged %q", got, content)
+	}
+}
+
+func TestHistoryFilePath_UsesHISTFILE(t *testing.T) {
+	t.Setenv("HISTFILE", "/tmp/custom_history")
+	path, err := HistoryFilePath()
+	if err != nil {
+		t.Fatalf("HistoryFilePath failed: %v", err)
+	}
+	if path != "/tmp/custom_history" {
+		t.Errorf("path = %q, want /tmp/custom_history", path)
+	}
+}
+
+func TestHistoryFilePathFor_FollowsTheGivenShell(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("HISTFILE", "")
+
+	path, err := HistoryFilePathFor("/usr/bin/zsh", "")
+	if err != nil {
+		t.Fatalf("HistoryFilePathFor failed: %v", err)
+	}
+	if path != "/home/tester/.zsh_history" {
+		t.Errorf("path = %q, want the zsh history", path)
+	}
+	path, _ = HistoryFilePathFor("/usr/bin/zsh", "/tmp/zsh_hist")
+	if path != "/tmp/zsh_hist" {
+		t.Errorf("path = %q, want the shell's HISTFILE", path)
+	}
+}
@@ -0,0 +1,177 @@
+package capture
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// REPL describes an interactive interpreter whose prompts are recognised,
+// so statements typed into it are not mistaken for shell commands.
+type REPL struct {
+	Name  string // Short identifier, e.g. "psql"
+	Label string // How the REPL is named to the model, e.g. "PostgreSQL (psql)"
+
+	programs     []string // Launcher basenames; "python" also matches "python3.12"
+	prefixMatch  bool     // Whether programs match as prefixes
+	needsNoArgs  bool     // Only a bare launch opens the REPL (`node`, not `node app.js`)
+	prompt       *regexp.Regexp
+	continuation *regexp.Regexp
+}
+
+// repls lists the recognised interpreters. Each prompt captures the text
+// typed after it.
+var repls = []*REPL{
+	{
+		Name:         "python",
+		Label:        "Python",
+		programs:     []string{"python"},
+		prefixMatch:  true,
+		needsNoArgs:  true,
+		prompt:       regexp.MustCompile(`^>>> ?(.*)$`),
+		continuation: regexp.MustCompile(`^\.\.\. ?(.*)$`),
+	},
+	{
+		Name:         "ipython",
+		Label:        "IPython",
+		programs:     []string{"ipython", "ipython3"},
+		prompt:       regexp.MustCompile(`^In \[\d+\]: ?(.*)$`),
+		continuation: regexp.MustCompile(`^\s+\.\.\.: ?(.*)$`),
+	},
+	{
+		Name:         "psql",
+		Label:        "PostgreSQL (psql)",
+		programs:     []string{"psql"},
+		prompt:       regexp.MustCompile(`^[\w.-]+=[#>] ?(.*)$`),
+		continuation: regexp.MustCompile(`^[\w.-]+[-'"(][#>] ?(.*)$`),
+	},
+	{
+		Name:         "node",
+		Label:        "Node.js",
+		programs:     []string{"node", "nodejs"},
+		needsNoArgs:  true,
+		prompt:       regexp.MustCompile(`^> (.*)$`),
+		continuation: regexp.MustCompile(`^\.\.\. ?(.*)$`),
+	},
+}
+
+// Statement returns what was typed after r's prompt on line, and whether
+// line starts with one of r's prompts at all.
+func (r *REPL) Statement(line string) (string, bool) {
+	line = strings.TrimRight(line, " \t")
+	for _, re := range []*regexp.Regexp{r.prompt, r.continuation} {
+		if m := re.FindStringSubmatch(line + " "); m != nil {
+			return strings.TrimSpace(m[1]), true
+		}
+	}
+	return "", false
+}
+
+// LaunchedREPL returns the REPL that command starts, or nil. `sudo`, `env`
+// and variable assignments in front of the program are skipped.
+func LaunchedREPL(command string) *REPL {
+	fields := strings.Fields(command)
+	for len(fields) > 0 && (fields[0] == "sudo" || fields[0] == "env" || fields[0] == "exec" || isAssignment(fields[0])) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	program := filepath.Base(fields[0])
+	args := fields[1:]
+	for _, r := range repls {
+		if !r.launchedBy(program) {
+			continue
+		}
+		if r.needsNoArgs && hasPositionalArg(args) {
+			return nil
+		}
+		return r
+	}
+	return nil
+}
+
+func (r *REPL) launchedBy(program string) bool {
+	for _, p := range r.programs {
+		if program == p || (r.prefixMatch && strings.HasPrefix(program, p) && isVersionSuffix(program[len(p):])) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPositionalArg reports whether args name a script or command to run,
+// which makes the interpreter exit instead of prompting. -i keeps it
+// interactive.
+func hasPositionalArg(args []string) bool {
+	for _, a := range args {
+		if a == "-i" {
+			return false
+		}
+		if !strings.HasPrefix(a, "-") || a == "-c" || a == "-m" || a == "-e" || a == "-p" {
+			return true
+		}
+	}
+	return false
+}
+
+func isVersionSuffix(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAssignment(field string) bool {
+	name, _, ok := strings.Cut(field, "=")
+	return ok && name != "" && !strings.ContainsAny(name, "/-.")
+}
+
+// REPLTracker follows whether lines typed at the terminal go to the shell
+// or to an interpreter the shell started. It is not safe for concurrent use.
+type REPLTracker struct {
+	launched *REPL // Interpreter started by the last shell command, if any
+}
+
+// Submit classifies a line the user submitted. promptLine is the terminal
+// line it was typed on, prompt included. When the last shell command
+// started a REPL and promptLine shows its prompt, Submit returns the REPL
+// and the statement; otherwise the line is a shell command and nil is
+// returned.
+func (t *REPLTracker) Submit(promptLine, typed string) (*REPL, string) {
+	if t.launched != nil {
+		if statement, ok := t.launched.Statement(promptLine); ok {
+			if statement == "" {
+				statement = strings.TrimSpace(typed)
+			}
+			return t.launched, statement
+		}
+	}
+	t.launched = LaunchedREPL(typed)
+	return nil, ""
+}
+
+// Active returns the REPL the terminal is in, judged by the prompt on the
+// current line, or nil at the shell.
+func (t *REPLTracker) Active(promptLine string) *REPL {
+	if t.launched == nil {
+		return nil
+	}
+	if _, ok := t.launched.Statement(promptLine); ok {
+		return t.launched
+	}
+	return nil
+}
+
+// Owns reports whether an output line is a prompt of the REPL started by
+// the last shell command, i.e. an echoed statement rather than a shell
+// command line.
+func (t *REPLTracker) Owns(line string) bool {
+	if t.launched == nil {
+		return false
+	}
+	_, ok := t.launched.Statement(line)
+	return ok
+}
@@ -0,0 +1,89 @@
+package capture
+
+import "testing"
+
+func TestLaunchedREPL(t *testing.T) {
+	cases := map[string]string{
+		"python3":               "python",
+		"python3.12 -q":         "python",
+		"/usr/bin/python":       "python",
+		"python -i script.py":   "python",
+		"python script.py":      "",
+		"python -m http.server": "",
+		"python3 -c 'print(1)'": "",
+		"pythonista":            "",
+		"ipython":               "ipython",
+		"psql -U app mydb":      "psql",
+		"sudo -u postgres psql": "",
+		"sudo psql":             "psql",
+		"PGHOST=db psql":        "psql",
+		"env NODE_ENV=dev node": "node",
+		"node":                  "node",
+		"node server.js":        "",
+		"node --inspect":        "node",
+		"ls -la":                "",
+		"":                      "",
+	}
+	for command, want := range cases {
+		got := ""
+		if r := LaunchedREPL(command); r != nil {
+			got = r.Name
+		}
+		if got != want {
+			t.Errorf("LaunchedREPL(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestREPLStatement(t *testing.T) {
+	psql := LaunchedREPL("psql")
+	cases := []struct {
+		line      string
+		statement string
+		ok        bool
+	}{
+		{"mydb=# select * from users", "select * from users", true},
+		{"mydb=> \\dt", "\\dt", true},
+		{"mydb-# where id = 1;", "where id = 1;", true},
+		{"mydb(# )", ")", true},
+		{"mydb=#", "", true},
+		{"user@host:~$ ls", "", false},
+		{" id | name", "", false},
+	}
+	for _, tc := range cases {
+		statement, ok := psql.Statement(tc.line)
+		if statement != tc.statement || ok != tc.ok {
+			t.Errorf("Statement(%q) = %q, %v; want %q, %v", tc.line, statement, ok, tc.statement, tc.ok)
+		}
+	}
+}
+
+func TestREPLTracker_FollowsShellAndREPL(t *testing.T) {
+	var tr REPLTracker
+
+	if r, _ := tr.Submit("user@host:~$ python3", "python3"); r != nil {
+		t.Fatalf("launching the REPL is a shell command, got %q", r.Name)
+	}
+	if tr.Active("user@host:~$ ") != nil {
+		t.Fatal("expected no REPL before its prompt shows")
+	}
+	if tr.Active(">>> ") == nil {
+		t.Fatal("expected python to be active at its prompt")
+	}
+
+	r, statement := tr.Submit(">>> import os", "import os")
+	if r == nil || r.Name != "python" || statement != "import os" {
+		t.Fatalf("expected a python statement, got %v %q", r, statement)
+	}
+	if !tr.Owns(">>> import os") || tr.Owns("user@host:~$ ls") {
+		t.Fatal("expected only REPL prompt lines to be owned")
+	}
+
+	// Back at the shell after exit(): the prompt no longer matches.
+	if r, _ := tr.Submit("user@host:~$ ls", "ls"); r != nil {
+		t.Fatalf("expected a shell command, got %q", r.Name)
+	}
+	if tr.Owns(">>> import os") {
+		t.Fatal("expected the REPL to be forgotten after a shell command")
+	}
+}
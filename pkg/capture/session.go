@@ -1,6 +1,7 @@
 package capture
 
 import (
+	"slices"
 	"sync"
 	"time"
 )
@@ -12,14 +13,32 @@ type CommandRecord struct {
 	StartTime   time.Time
 	EndTime     time.Time
 	WorkingDir  string
-	BufferStart int // Position in buffer where this command's output starts
-	BufferEnd   int // Position in buffer where this command's output ends
+	BufferStart int      // Buffer mark (CircularBuffer.Total) where this command's output starts
+	BufferEnd   int      // Buffer mark where this command's output ends; 0 while running
+	Output      []string // First lines of output, snapshotted when the next command starts
+	// Marked is set when the shell delimits commands with OSC 133 marks:
+	// BufferStart and BufferEnd are then exact and ExitCode is what the
+	// shell reported (-1 until the command finishes or if it did not say).
+	Marked bool
 }
 
+// REPLStatement is a line typed into an interpreter started from the shell.
+// It is kept apart from CommandRecord so REPL input does not read as shell
+// history.
+type REPLStatement struct {
+	REPL      string // REPL.Name
+	Statement string
+	Time      time.Time
+}
+
+// maxREPLStatements bounds the REPL statements kept.
+const maxREPLStatements = 200
+
 // SessionContext tracks the current terminal session state
 type SessionContext struct {
 	mu           sync.RWMutex
 	history      []CommandRecord
+	statements   []REPLStatement
 	currentDir   string
 	maxHistory   int // Maximum number of commands to keep
 	sessionStart time.Time
@@ -54,6 +73,60 @@ func (sc *SessionContext) AddCommand(record CommandRecord) {
 	}
 }
 
+// SetLastOutput records the output snapshot of the most recent command.
+// It is a no-op when there is no command or its output was already set.
+func (sc *SessionContext) SetLastOutput(end int, output []string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if len(sc.history) == 0 {
+		return
+	}
+	last := &sc.history[len(sc.history)-1]
+	if last.BufferEnd != 0 {
+		return
+	}
+	last.BufferEnd = end
+	last.Output = append([]string(nil), output...)
+}
+
+// SetLastOutputStart moves where the output of the most recent command
+// starts, once the shell marks it. It is a no-op when that command already
+// finished.
+func (sc *SessionContext) SetLastOutputStart(start int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if len(sc.history) == 0 {
+		return
+	}
+	last := &sc.history[len(sc.history)-1]
+	if last.BufferEnd != 0 {
+		return
+	}
+	last.BufferStart = start
+}
+
+// FinishLastCommand records that the most recent command exited with
+// exitCode at buffer mark end, along with its output snapshot. It is a no-op
+// when there is no command or it already finished.
+func (sc *SessionContext) FinishLastCommand(end, exitCode int, output []string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if len(sc.history) == 0 {
+		return
+	}
+	last := &sc.history[len(sc.history)-1]
+	if last.BufferEnd != 0 {
+		return
+	}
+	last.BufferEnd = end
+	last.ExitCode = exitCode
+	last.EndTime = time.Now()
+	last.Output = append([]string(nil), output...)
+}
+
 // GetHistory returns all command records
 func (sc *SessionContext) GetHistory() []CommandRecord {
 	sc.mu.RLock()
@@ -84,6 +157,33 @@ func (sc *SessionContext) GetLastN(n int) []CommandRecord {
 	return result
 }
 
+// AddREPLStatement records a statement typed into a REPL.
+func (sc *SessionContext) AddREPLStatement(statement REPLStatement) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.statements = append(sc.statements, statement)
+	if len(sc.statements) > maxREPLStatements {
+		sc.statements = sc.statements[len(sc.statements)-maxREPLStatements:]
+	}
+}
+
+// LastREPLStatements returns up to n of the latest statements typed into
+// repl, oldest first.
+func (sc *SessionContext) LastREPLStatements(repl string, n int) []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	var result []string
+	for i := len(sc.statements) - 1; i >= 0 && len(result) < n; i-- {
+		if sc.statements[i].REPL == repl {
+			result = append(result, sc.statements[i].Statement)
+		}
+	}
+	slices.Reverse(result)
+	return result
+}
+
 // GetCurrentDir returns the current working directory
 func (sc *SessionContext) GetCurrentDir() string {
 	sc.mu.RLock()
@@ -115,4 +215,5 @@ func (sc *SessionContext) Clear() {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 	sc.history = make([]CommandRecord, 0)
+	sc.statements = nil
 }
@@ -244,3 +244,58 @@ func TestSessionDuration(t *testing.T) {
 		t.Errorf("Expected duration >= 10ms, got %v", duration)
 	}
 }
+
+func TestSetLastOutput(t *testing.T) {
+	sc := NewSessionContext()
+	sc.SetLastOutput(5, []string{"ignored"}) // no history yet
+
+	sc.AddCommand(CommandRecord{Command: "make", BufferStart: 3})
+	sc.SetLastOutput(7, []string{"building", "done"})
+	sc.SetLastOutput(9, []string{"overwritten"})
+
+	last := sc.GetLastN(1)[0]
+	if last.BufferEnd != 7 {
+		t.Errorf("Expected BufferEnd 7, got %d", last.BufferEnd)
+	}
+	if len(last.Output) != 2 || last.Output[1] != "done" {
+		t.Errorf("Expected first snapshot to be kept, got %q", last.Output)
+	}
+}
+
+func TestFinishLastCommand(t *testing.T) {
+	sc := NewSessionContext()
+	sc.FinishLastCommand(5, 1, nil) // no history yet
+
+	sc.AddCommand(CommandRecord{Command: "make", BufferStart: 3, ExitCode: -1, Marked: true})
+	sc.SetLastOutputStart(4)
+	sc.FinishLastCommand(8, 2, []string{"make: *** Error 2"})
+	sc.FinishLastCommand(9, 0, nil)
+	sc.SetLastOutputStart(6)
+
+	last := sc.GetLastN(1)[0]
+	if last.BufferStart != 4 || last.BufferEnd != 8 {
+		t.Errorf("Expected output between marks 4 and 8, got %d-%d", last.BufferStart, last.BufferEnd)
+	}
+	if last.ExitCode != 2 {
+		t.Errorf("Expected exit code 2, got %d", last.ExitCode)
+	}
+	if len(last.Output) != 1 {
+		t.Errorf("Expected output snapshot, got %q", last.Output)
+	}
+}
+
+func TestSessionContext_REPLStatements(t *testing.T) {
+	sc := NewSessionContext()
+	sc.AddCommand(CommandRecord{Command: "psql"})
+	for _, s := range []string{"\\dt", "select 1;", "select 2;"} {
+		sc.AddREPLStatement(REPLStatement{REPL: "psql", Statement: s})
+	}
+	sc.AddREPLStatement(REPLStatement{REPL: "python", Statement: "import os"})
+
+	if got := sc.LastREPLStatements("psql", 2); len(got) != 2 || got[0] != "select 1;" || got[1] != "select 2;" {
+		t.Fatalf("unexpected statements: %q", got)
+	}
+	if sc.HistorySize() != 1 {
+		t.Fatalf("expected REPL statements to stay out of the command history, got %d records", sc.HistorySize())
+	}
+}
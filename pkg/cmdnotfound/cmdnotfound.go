@@ -0,0 +1,131 @@
+// Package cmdnotfound recognizes shell "command not found" errors, suggests
+// the command that was probably meant, and looks up how to install the
+// missing command with the host's package manager.
+package cmdnotfound
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+var notFoundPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(?:ba)?sh: (?:line \d+: )?([^\s:/]+): command not found$`), // bash
+	regexp.MustCompile(`^zsh: command not found: ([^\s/]+)$`),                       // zsh
+	regexp.MustCompile(`^fish: Unknown command:? '?([^\s'/]+)'?$`),                  // fish
+	regexp.MustCompile(`^(?:da)?sh: \d+: ([^\s:/]+): not found$`),                   // dash
+}
+
+// Detect returns the missing command if line is a shell's command-not-found
+// error.
+func Detect(line string) (string, bool) {
+	line = strings.TrimSpace(ansi.Strip(line))
+	for _, re := range notFoundPatterns {
+		if m := re.FindStringSubmatch(line); m != nil {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// Manager is a package manager and how to install a package with it.
+type Manager struct {
+	Family  string // Key into the package table: debian, fedora, arch, alpine, suse, brew
+	Install string // Install command prefix; the package name is appended
+}
+
+var managers = map[string]Manager{
+	"debian": {Family: "debian", Install: "sudo apt install"},
+	"fedora": {Family: "fedora", Install: "sudo dnf install"},
+	"arch":   {Family: "arch", Install: "sudo pacman -S"},
+	"alpine": {Family: "alpine", Install: "sudo apk add"},
+	"suse":   {Family: "suse", Install: "sudo zypper install"},
+	"brew":   {Family: "brew", Install: "brew install"},
+}
+
+// osReleaseFamilies maps /etc/os-release ID and ID_LIKE values to a family.
+var osReleaseFamilies = map[string]string{
+	"debian":              "debian",
+	"ubuntu":              "debian",
+	"linuxmint":           "debian",
+	"pop":                 "debian",
+	"raspbian":            "debian",
+	"fedora":              "fedora",
+	"rhel":                "fedora",
+	"centos":              "fedora",
+	"rocky":               "fedora",
+	"almalinux":           "fedora",
+	"arch":                "arch",
+	"manjaro":             "arch",
+	"endeavouros":         "arch",
+	"alpine":              "alpine",
+	"suse":                "suse",
+	"opensuse":            "suse",
+	"opensuse-leap":       "suse",
+	"opensuse-tumbleweed": "suse",
+}
+
+// DetectManager returns the host's package manager, or false if it is not
+// one we have package names for.
+func DetectManager() (Manager, bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		return managers["brew"], true
+	case "linux":
+		f, err := os.Open("/etc/os-release")
+		if err != nil {
+			return Manager{}, false
+		}
+		defer f.Close()
+		return managerFromOSRelease(bufio.NewScanner(f))
+	}
+	return Manager{}, false
+}
+
+func managerFromOSRelease(sc *bufio.Scanner) (Manager, bool) {
+	var ids []string
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), "=")
+		if !ok || (key != "ID" && key != "ID_LIKE") {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		if key == "ID" {
+			// ID wins over ID_LIKE.
+			ids = append(strings.Fields(value), ids...)
+		} else {
+			ids = append(ids, strings.Fields(value)...)
+		}
+	}
+	for _, id := range ids {
+		if family, ok := osReleaseFamilies[id]; ok {
+			return managers[family], true
+		}
+	}
+	return Manager{}, false
+}
+
+// Known reports whether command is a tool in the package table, i.e. a real
+// command that is missing rather than a typo.
+func Known(command string) bool {
+	_, ok := packages[command]
+	return ok
+}
+
+// Suggest returns the command that installs command with mgr, or false when
+// the package providing it is unknown.
+func Suggest(command string, mgr Manager) (string, bool) {
+	pkgs, ok := packages[command]
+	if !ok {
+		return "", false
+	}
+	pkg := pkgs.For(mgr.Family)
+	if pkg == "" || mgr.Install == "" {
+		return "", false
+	}
+	return mgr.Install + " " + pkg, true
+}
@@ -0,0 +1,82 @@
+package cmdnotfound
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"bash: htop: command not found", "htop", true},
+		{"bash: line 1: jq: command not found", "jq", true},
+		{"\x1b[31mzsh: command not found: rg\x1b[0m", "rg", true},
+		{"fish: Unknown command: fd", "fd", true},
+		{"fish: Unknown command 'bat'", "bat", true},
+		{"sh: 1: tree: not found", "tree", true},
+		{"./build.sh: line 3: foo: command not found", "", false},
+		{"grep: command not found in docs", "", false},
+		{"bash: ./run: No such file or directory", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Detect(tt.line)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Detect(%q) = %q, %v; want %q, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestManagerFromOSRelease(t *testing.T) {
+	tests := []struct {
+		release string
+		family  string
+		ok      bool
+	}{
+		{"NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\n", "debian", true},
+		{"ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", "fedora", true},
+		{"ID=endeavouros\nID_LIKE=arch\n", "arch", true},
+		{"ID_LIKE=\"suse opensuse\"\nID=\"opensuse-tumbleweed\"\n", "suse", true},
+		{"ID=nixos\n", "", false},
+	}
+	for _, tt := range tests {
+		mgr, ok := managerFromOSRelease(bufio.NewScanner(strings.NewReader(tt.release)))
+		if ok != tt.ok || mgr.Family != tt.family {
+			t.Errorf("managerFromOSRelease(%q) = %q, %v; want %q, %v", tt.release, mgr.Family, ok, tt.family, tt.ok)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		command string
+		family  string
+		want    string
+		ok      bool
+	}{
+		{"htop", "debian", "sudo apt install htop", true},
+		{"fd", "debian", "sudo apt install fd-find", true},
+		{"fd", "arch", "sudo pacman -S fd", true},
+		{"dig", "brew", "brew install bind", true},
+		{"strace", "brew", "", false},
+		{"definitely-not-a-command", "debian", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Suggest(tt.command, managers[tt.family])
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Suggest(%q, %s) = %q, %v; want %q, %v", tt.command, tt.family, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestKnown(t *testing.T) {
+	if !Known("htop") {
+		t.Error("Known(htop) = false, want true")
+	}
+	if Known("gti") {
+		t.Error("Known(gti) = true, want false")
+	}
+}
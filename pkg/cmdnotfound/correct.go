@@ -0,0 +1,96 @@
+package cmdnotfound
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Correct suggests what a failed command line was meant to be, the way zsh's
+// correction does: a recent history entry one edit away from the whole line,
+// or else a binary one edit away from the missing command with the rest of
+// the line kept. history is ordered most recent first.
+func Correct(line, command string, history, binaries []string) (string, bool) {
+	line = strings.TrimSpace(line)
+	for _, entry := range history {
+		entry = strings.TrimSpace(entry)
+		if OneEditApart(line, entry) {
+			return entry, true
+		}
+	}
+
+	fields := strings.Fields(line)
+	i := -1
+	for j, f := range fields {
+		if f == command {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return "", false
+	}
+	for _, bin := range binaries {
+		if OneEditApart(command, bin) {
+			fields[i] = bin
+			return strings.Join(fields, " "), true
+		}
+	}
+	return "", false
+}
+
+// OneEditApart reports whether a and b differ by exactly one insertion,
+// deletion, substitution or swap of adjacent characters.
+func OneEditApart(a, b string) bool {
+	if a == b {
+		return false
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+
+	p := 0
+	for p < len(ra) && ra[p] == rb[p] {
+		p++
+	}
+	if len(ra) != len(rb) {
+		return string(ra[p:]) == string(rb[p+1:])
+	}
+	if string(ra[p+1:]) == string(rb[p+1:]) {
+		return true
+	}
+	return p+1 < len(ra) && ra[p] == rb[p+1] && ra[p+1] == rb[p] && string(ra[p+2:]) == string(rb[p+2:])
+}
+
+// PathBinaries lists the executables found in the directories of pathEnv,
+// sorted and without duplicates.
+func PathBinaries(pathEnv string) []string {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || seen[e.Name()] {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || info.Mode().Perm()&0111 == 0 {
+				continue
+			}
+			seen[e.Name()] = true
+		}
+	}
+	bins := make([]string, 0, len(seen))
+	for name := range seen {
+		bins = append(bins, name)
+	}
+	sort.Strings(bins)
+	return bins
+}
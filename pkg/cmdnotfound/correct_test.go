@@ -0,0 +1,72 @@
+package cmdnotfound
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOneEditApart(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"gti", "git", true},
+		{"gitt", "git", true},
+		{"gt", "git", true},
+		{"got", "git", true},
+		{"sl", "ls", true},
+		{"git", "git", false},
+		{"gxx", "git", false},
+		{"dokcer", "docker", true},
+		{"dcoker", "docker", true},
+		{"dkocer", "docker", false},
+		{"g", "git", false},
+	}
+	for _, tt := range tests {
+		if got := OneEditApart(tt.a, tt.b); got != tt.want {
+			t.Errorf("OneEditApart(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	binaries := []string{"docker", "git", "ls"}
+	tests := []struct {
+		line, command string
+		history       []string
+		want          string
+		ok            bool
+	}{
+		{"gti status", "gti", nil, "git status", true},
+		{"sudo dokcer ps", "dokcer", nil, "sudo docker ps", true},
+		{"sl -la", "sl", []string{"make", "ls -la"}, "ls -la", true},
+		{"mkae build", "mkae", []string{"make build"}, "make build", true},
+		{"frobnicate", "frobnicate", []string{"git status"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Correct(tt.line, tt.command, tt.history, binaries)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Correct(%q) = %q, %v; want %q, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPathBinaries(t *testing.T) {
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(dir1, "git", 0755)
+	write(dir1, "notes.txt", 0644)
+	write(dir2, "git", 0755)
+	write(dir2, "ls", 0755)
+
+	got := PathBinaries(dir1 + string(os.PathListSeparator) + dir2 + string(os.PathListSeparator) + filepath.Join(dir1, "missing"))
+	if want := []string{"git", "ls"}; !slices.Equal(got, want) {
+		t.Fatalf("PathBinaries() = %v, want %v", got, want)
+	}
+}
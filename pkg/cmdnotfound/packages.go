@@ -0,0 +1,85 @@
+package cmdnotfound
+
+// pkgNames lists the package that provides a command in each family. Default
+// is used for families without their own entry; "-" means not packaged.
+type pkgNames struct {
+	Default string
+	Debian  string
+	Fedora  string
+	Arch    string
+	Alpine  string
+	Suse    string
+	Brew    string
+}
+
+// For returns the package name for family, or "" if there is none.
+func (p pkgNames) For(family string) string {
+	name := ""
+	switch family {
+	case "debian":
+		name = p.Debian
+	case "fedora":
+		name = p.Fedora
+	case "arch":
+		name = p.Arch
+	case "alpine":
+		name = p.Alpine
+	case "suse":
+		name = p.Suse
+	case "brew":
+		name = p.Brew
+	}
+	if name == "" {
+		name = p.Default
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// packages is a small command→package table covering commands people
+// commonly miss on a fresh machine, after the distros' command-not-found
+// datasets. Anything else falls back to asking the AI.
+var packages = map[string]pkgNames{
+	"7z":         {Default: "p7zip", Debian: "p7zip-full", Brew: "sevenzip"},
+	"ag":         {Default: "the_silver_searcher", Debian: "silversearcher-ag"},
+	"bat":        {Default: "bat"},
+	"cmake":      {Default: "cmake"},
+	"convert":    {Default: "imagemagick", Fedora: "ImageMagick", Suse: "ImageMagick"},
+	"curl":       {Default: "curl"},
+	"dig":        {Default: "bind-utils", Debian: "dnsutils", Arch: "bind", Alpine: "bind-tools", Brew: "bind"},
+	"docker":     {Default: "docker", Debian: "docker.io", Fedora: "moby-engine"},
+	"fd":         {Default: "fd", Debian: "fd-find", Fedora: "fd-find"},
+	"ffmpeg":     {Default: "ffmpeg"},
+	"fzf":        {Default: "fzf"},
+	"gcc":        {Default: "gcc", Brew: "-"},
+	"gh":         {Default: "gh", Fedora: "gh", Arch: "github-cli", Alpine: "github-cli"},
+	"git":        {Default: "git"},
+	"go":         {Default: "go", Debian: "golang-go", Fedora: "golang"},
+	"htop":       {Default: "htop"},
+	"http":       {Default: "httpie"},
+	"ifconfig":   {Default: "net-tools", Suse: "net-tools-deprecated", Brew: "-"},
+	"jq":         {Default: "jq"},
+	"lsof":       {Default: "lsof"},
+	"make":       {Default: "make"},
+	"nc":         {Default: "netcat", Debian: "netcat-openbsd", Fedora: "nmap-ncat", Arch: "openbsd-netcat", Alpine: "netcat-openbsd"},
+	"netstat":    {Default: "net-tools", Suse: "net-tools-deprecated", Brew: "-"},
+	"node":       {Default: "nodejs", Brew: "node"},
+	"npm":        {Default: "npm", Brew: "node"},
+	"nvim":       {Default: "neovim"},
+	"pip3":       {Default: "python3-pip", Arch: "python-pip", Alpine: "py3-pip", Brew: "python"},
+	"python3":    {Default: "python3", Arch: "python", Brew: "python"},
+	"rg":         {Default: "ripgrep"},
+	"rsync":      {Default: "rsync"},
+	"shellcheck": {Default: "shellcheck", Fedora: "ShellCheck", Suse: "ShellCheck"},
+	"strace":     {Default: "strace", Brew: "-"},
+	"tmux":       {Default: "tmux"},
+	"traceroute": {Default: "traceroute"},
+	"tree":       {Default: "tree"},
+	"unzip":      {Default: "unzip"},
+	"vim":        {Default: "vim"},
+	"wget":       {Default: "wget"},
+	"zip":        {Default: "zip"},
+	"zsh":        {Default: "zsh"},
+}
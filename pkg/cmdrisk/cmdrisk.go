@@ -0,0 +1,467 @@
+// Package cmdrisk estimates how risky a suggested shell command is to run, so
+// the UI can badge it and ask before applying one that destroys data.
+//
+// The classifier is a set of rules over the words of each simple command in
+// the line (split on pipes, ;, && and ||). Anything it does not recognise is
+// considered safe: the point is to catch the well-known foot-guns, not to
+// sandbox the shell.
+package cmdrisk
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Level is how much damage running a command can do.
+type Level int
+
+const (
+	Safe        Level = iota // Only reads, or changes nothing that matters
+	Caution                  // Changes files, packages, processes or remote state
+	Destructive              // Deletes or overwrites data in a way that is hard to undo
+)
+
+// String returns the badge label of l.
+func (l Level) String() string {
+	switch l {
+	case Caution:
+		return "caution"
+	case Destructive:
+		return "destructive"
+	}
+	return "safe"
+}
+
+// Risk is the classification of a command line.
+type Risk struct {
+	Level  Level
+	Reason string // Why, e.g. "deletes files"; empty when safe
+}
+
+// Classify returns the risk of running command line in a POSIX shell. The
+// riskiest simple command in the line decides.
+func Classify(command string) Risk {
+	if strings.Contains(strings.ReplaceAll(command, " ", ""), ":(){") {
+		return Risk{Level: Destructive, Reason: "fork bomb"}
+	}
+	var risk Risk
+	for _, seg := range split(command) {
+		if r := classifySegment(seg); r.Level > risk.Level {
+			risk = r
+		}
+	}
+	return risk
+}
+
+// segment is one simple command: its words, redirect targets and whether its
+// input is piped from the previous command.
+type segment struct {
+	words     []string
+	redirects []string // Targets of > (truncating) redirects
+	piped     bool
+}
+
+// split cuts line into simple commands. Quotes and backslashes are honoured
+// so operators inside strings do not split; expansions are not performed.
+func split(line string) []segment {
+	var (
+		segs    []segment
+		cur     segment
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+		// redirect is set after > until its target word is read.
+		redirect bool
+	)
+	endWord := func() {
+		if !inWord {
+			return
+		}
+		if redirect {
+			cur.redirects = append(cur.redirects, word.String())
+			redirect = false
+		} else {
+			cur.words = append(cur.words, word.String())
+		}
+		word.Reset()
+		inWord = false
+	}
+	endSegment := func(piped bool) {
+		endWord()
+		if len(cur.words) > 0 || len(cur.redirects) > 0 {
+			segs = append(segs, cur)
+		}
+		cur = segment{piped: piped}
+		redirect = false
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			inWord, escaped = true, false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			endWord()
+		case r == ';' || r == '\n':
+			endSegment(false)
+		case r == '|':
+			if i+1 < len(runes) && runes[i+1] == '|' {
+				i++
+				endSegment(false)
+			} else {
+				endSegment(true)
+			}
+		case r == '&':
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				i++
+			}
+			if i > 0 && runes[i-1] == '>' {
+				// 2>&1: the descriptor that follows is no file.
+				redirect = false
+				for i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9' {
+					i++
+				}
+				continue
+			}
+			endSegment(false)
+		case r == '>':
+			appending := i+1 < len(runes) && runes[i+1] == '>'
+			if appending {
+				i++
+			}
+			// A descriptor number right before belongs to the operator.
+			if inWord && isDigits(word.String()) {
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			redirect = !appending
+		case r == '<':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endSegment(false)
+	return segs
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// wrappers run the command that follows them.
+var wrappers = map[string]bool{
+	"env": true, "nohup": true, "time": true, "nice": true, "command": true,
+	"exec": true, "xargs": true, "timeout": true, "watch": true,
+}
+
+// shells run code given on their input.
+var shells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "fish": true,
+	"python": true, "python3": true, "perl": true, "ruby": true, "node": true,
+}
+
+func classifySegment(seg segment) Risk {
+	var risk Risk
+	raise := func(r Risk) {
+		if r.Level > risk.Level {
+			risk = r
+		}
+	}
+
+	for _, target := range seg.redirects {
+		switch {
+		case strings.HasPrefix(target, "/dev/") && target != "/dev/null" && target != "/dev/stdout" && target != "/dev/stderr" && target != "/dev/tty":
+			raise(Risk{Level: Destructive, Reason: "overwrites a device"})
+		case target != "/dev/null" && target != "/dev/stdout" && target != "/dev/stderr" && target != "/dev/tty":
+			raise(Risk{Level: Caution, Reason: "overwrites " + target})
+		}
+	}
+
+	words := seg.words
+	for len(words) > 0 {
+		name := filepath.Base(words[0])
+		switch {
+		case strings.Contains(words[0], "=") && !strings.HasPrefix(words[0], "="):
+			words = words[1:] // Variable assignment
+			continue
+		case name == "sudo" || name == "doas":
+			raise(Risk{Level: Caution, Reason: "runs as root"})
+			words = skipOptions(words[1:])
+			continue
+		case wrappers[name]:
+			words = skipOptions(words[1:])
+			continue
+		}
+		break
+	}
+	if len(words) == 0 {
+		return risk
+	}
+
+	name := filepath.Base(words[0])
+	if seg.piped && shells[name] {
+		raise(Risk{Level: Caution, Reason: "runs piped code"})
+	}
+	raise(classifyProgram(name, words[1:]))
+	return risk
+}
+
+// skipOptions drops the options of a wrapper such as sudo or nice, with a
+// numeric value where one follows.
+func skipOptions(words []string) []string {
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+		words = words[1:]
+		if len(words) > 0 && isDigits(words[0]) {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+var (
+	sqlDestructive = regexp.MustCompile(`(?i)\b(drop\s+(table|database|schema)|truncate\s+table|delete\s+from)\b`)
+	redisFlush     = regexp.MustCompile(`(?i)\bflush(all|db)\b`)
+)
+
+func classifyProgram(name string, args []string) Risk {
+	switch {
+	case name == "rm" || name == "unlink" || name == "shred" || name == "wipefs":
+		return Risk{Level: Destructive, Reason: "deletes files"}
+	case name == "dd":
+		return Risk{Level: Destructive, Reason: "overwrites its output"}
+	case strings.HasPrefix(name, "mkfs") || name == "mke2fs" || name == "fdisk" || name == "sfdisk" || name == "parted":
+		return Risk{Level: Destructive, Reason: "repartitions or formats a disk"}
+	case name == "git":
+		return classifyGit(args)
+	case name == "docker" || name == "podman":
+		return classifyContainer(args)
+	case name == "kubectl":
+		switch subcommand(args) {
+		case "delete":
+			return Risk{Level: Destructive, Reason: "deletes cluster resources"}
+		case "apply", "create", "edit", "patch", "replace", "scale", "rollout", "drain", "cordon", "label", "annotate", "set":
+			return Risk{Level: Caution, Reason: "changes the cluster"}
+		}
+	case name == "terraform" || name == "tofu":
+		switch sub := subcommand(args); {
+		case sub == "destroy" || (sub == "apply" && slices.Contains(args, "-destroy")):
+			return Risk{Level: Destructive, Reason: "destroys infrastructure"}
+		case sub == "apply" || sub == "import" || sub == "state":
+			return Risk{Level: Caution, Reason: "changes infrastructure"}
+		}
+	case name == "psql" || name == "mysql" || name == "sqlite3" || name == "mongosh" || name == "redis-cli":
+		joined := strings.Join(args, " ")
+		if sqlDestructive.MatchString(joined) || (name == "redis-cli" && redisFlush.MatchString(joined)) {
+			return Risk{Level: Destructive, Reason: "deletes data"}
+		}
+	case name == "find":
+		if slices.Contains(args, "-delete") || execsRemove(args) {
+			return Risk{Level: Destructive, Reason: "deletes files"}
+		}
+	case name == "rsync":
+		if slices.ContainsFunc(args, func(a string) bool { return strings.HasPrefix(a, "--delete") }) {
+			return Risk{Level: Destructive, Reason: "deletes files missing from the source"}
+		}
+		return Risk{Level: Caution, Reason: "overwrites files"}
+	case name == "crontab":
+		if slices.Contains(args, "-r") {
+			return Risk{Level: Destructive, Reason: "removes the crontab"}
+		}
+	case name == "chmod" || name == "chown" || name == "chgrp":
+		if hasFlag(args, 'R') && slices.ContainsFunc(args, func(a string) bool { return a == "/" || a == "~" || a == "/*" }) {
+			return Risk{Level: Destructive, Reason: "changes permissions of the whole system"}
+		}
+		return Risk{Level: Caution, Reason: "changes permissions"}
+	case name == "sed" || name == "perl":
+		if slices.ContainsFunc(args, func(a string) bool {
+			return a == "-i" || strings.HasPrefix(a, "-i.") || a == "--in-place" || strings.HasPrefix(a, "-pi")
+		}) {
+			return Risk{Level: Caution, Reason: "edits files in place"}
+		}
+	case name == "tee":
+		if !hasFlag(args, 'a') && !slices.Contains(args, "--append") && len(args) > 0 {
+			return Risk{Level: Caution, Reason: "overwrites files"}
+		}
+	case name == "mv" || name == "cp" || name == "ln" || name == "install" || name == "truncate" || name == "rmdir":
+		return Risk{Level: Caution, Reason: "changes files"}
+	case name == "kill" || name == "pkill" || name == "killall":
+		return Risk{Level: Caution, Reason: "stops processes"}
+	case name == "reboot" || name == "shutdown" || name == "poweroff" || name == "halt":
+		return Risk{Level: Caution, Reason: "restarts or stops the machine"}
+	case name == "systemctl" || name == "service" || name == "launchctl":
+		switch subcommand(args) {
+		case "", "status", "list-units", "list-unit-files", "is-active", "is-enabled", "cat", "show", "list":
+		default:
+			return Risk{Level: Caution, Reason: "changes services"}
+		}
+	case isPackageManager(name):
+		switch subcommand(args) {
+		case "install", "add", "remove", "rm", "uninstall", "purge", "autoremove", "upgrade", "update", "dist-upgrade", "i", "-S", "-R", "-Syu":
+			return Risk{Level: Caution, Reason: "changes installed packages"}
+		}
+	}
+	return Risk{}
+}
+
+func classifyGit(args []string) Risk {
+	// Global options such as -C dir come before the subcommand.
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return Risk{}
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "reset":
+		if slices.Contains(rest, "--hard") {
+			return Risk{Level: Destructive, Reason: "discards uncommitted changes"}
+		}
+		return Risk{Level: Caution, Reason: "moves the branch"}
+	case "clean":
+		if hasFlag(rest, 'f') || slices.Contains(rest, "--force") {
+			return Risk{Level: Destructive, Reason: "deletes untracked files"}
+		}
+	case "push":
+		if hasFlag(rest, 'f') || slices.ContainsFunc(rest, func(a string) bool {
+			return strings.HasPrefix(a, "--force") || strings.HasPrefix(a, "+") || a == "--delete" || a == "--mirror"
+		}) {
+			return Risk{Level: Destructive, Reason: "rewrites or deletes remote history"}
+		}
+		return Risk{Level: Caution, Reason: "publishes commits"}
+	case "branch":
+		if slices.Contains(rest, "-D") || (slices.Contains(rest, "--delete") && slices.Contains(rest, "--force")) {
+			return Risk{Level: Destructive, Reason: "deletes an unmerged branch"}
+		}
+		if slices.Contains(rest, "-d") || slices.Contains(rest, "-m") || slices.Contains(rest, "-M") || slices.Contains(rest, "--delete") {
+			return Risk{Level: Caution, Reason: "changes branches"}
+		}
+	case "checkout":
+		if slices.Contains(rest, "--") || slices.Contains(rest, ".") {
+			return Risk{Level: Destructive, Reason: "discards uncommitted changes"}
+		}
+		return Risk{Level: Caution, Reason: "changes the working tree"}
+	case "restore":
+		if !slices.Contains(rest, "--staged") || slices.Contains(rest, "--worktree") {
+			return Risk{Level: Destructive, Reason: "discards uncommitted changes"}
+		}
+		return Risk{Level: Caution, Reason: "changes the index"}
+	case "stash":
+		if len(rest) > 0 && (rest[0] == "drop" || rest[0] == "clear") {
+			return Risk{Level: Destructive, Reason: "deletes stashed changes"}
+		}
+		return Risk{Level: Caution, Reason: "changes the working tree"}
+	case "commit", "merge", "rebase", "pull", "cherry-pick", "revert", "am", "apply", "switch", "rm", "mv", "tag", "init", "add", "gc", "prune":
+		return Risk{Level: Caution, Reason: "changes the repository"}
+	}
+	return Risk{}
+}
+
+func classifyContainer(args []string) Risk {
+	sub := subcommand(args)
+	rest := args
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	switch {
+	case sub == "system" || sub == "volume" || sub == "image" || sub == "container" || sub == "network" || sub == "builder":
+		switch subcommand(rest) {
+		case "prune", "rm":
+			return Risk{Level: Destructive, Reason: "deletes containers, images or volumes"}
+		}
+	case sub == "rm" || sub == "rmi":
+		if hasFlag(rest, 'v') || slices.Contains(rest, "--volumes") {
+			return Risk{Level: Destructive, Reason: "deletes container volumes"}
+		}
+		return Risk{Level: Caution, Reason: "removes containers or images"}
+	case sub == "stop" || sub == "kill" || sub == "restart" || sub == "run" || sub == "compose" || sub == "push":
+		if sub == "compose" && slices.Contains(rest, "down") && (hasFlag(rest, 'v') || slices.Contains(rest, "--volumes")) {
+			return Risk{Level: Destructive, Reason: "deletes container volumes"}
+		}
+		if sub == "compose" && !slices.ContainsFunc(rest, func(a string) bool {
+			return a == "up" || a == "down" || a == "rm" || a == "stop" || a == "restart" || a == "kill"
+		}) {
+			return Risk{}
+		}
+		return Risk{Level: Caution, Reason: "changes containers"}
+	}
+	return Risk{}
+}
+
+// subcommand returns the first word of args that is not an option.
+func subcommand(args []string) string {
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") || a == "-S" || a == "-R" || a == "-Syu" {
+			return a
+		}
+	}
+	return ""
+}
+
+// hasFlag reports whether a short option cluster in args, such as -rf,
+// contains flag.
+func hasFlag(args []string, flag byte) bool {
+	for _, a := range args {
+		if len(a) > 1 && a[0] == '-' && a[1] != '-' && strings.IndexByte(a[1:], flag) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// execsRemove reports whether find's -exec runs rm.
+func execsRemove(args []string) bool {
+	for i, a := range args {
+		if (a == "-exec" || a == "-execdir" || a == "-ok") && i+1 < len(args) && filepath.Base(args[i+1]) == "rm" {
+			return true
+		}
+	}
+	return false
+}
+
+func isPackageManager(name string) bool {
+	switch name {
+	case "apt", "apt-get", "dnf", "yum", "zypper", "pacman", "apk", "brew", "snap", "port",
+		"npm", "pnpm", "yarn", "pip", "pip3", "pipx", "gem", "cargo", "go":
+		return true
+	}
+	return false
+}
+
+// MatchPattern reports whether command matches a user pattern of commands
+// to treat as destructive. A pattern matches a command alone or with more
+// arguments ("terraform destroy" matches "terraform destroy -auto-approve");
+// * matches any text. Whitespace is collapsed before comparing.
+func MatchPattern(pattern, command string) bool {
+	pattern = strings.Join(strings.Fields(pattern), " ")
+	if pattern == "" {
+		return false
+	}
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	re, err := regexp.Compile("^" + expr + "( .*)?$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strings.Join(strings.Fields(command), " "))
+}
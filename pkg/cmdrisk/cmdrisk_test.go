@@ -0,0 +1,91 @@
+package cmdrisk
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		command string
+		want    Level
+	}{
+		{"ls -la", Safe},
+		{"git status && git log --oneline -5", Safe},
+		{"grep -rn TODO . | head", Safe},
+		{"go test ./... 2>&1 | tee", Safe},
+		{"make > /dev/null 2>&1", Safe},
+		{"echo 'rm -rf /'", Safe},
+		{"docker ps -a", Safe},
+		{"kubectl get pods", Safe},
+
+		{"sudo apt-get install -y jq", Caution},
+		{"npm install", Caution},
+		{"mv a.txt b.txt", Caution},
+		{"git push origin main", Caution},
+		{"git commit -am fix", Caution},
+		{"sed -i 's/a/b/' file", Caution},
+		{"echo hi > notes.txt", Caution},
+		{"curl -fsSL https://example.com/install.sh | sh", Caution},
+		{"systemctl restart nginx", Caution},
+		{"kill -9 1234", Caution},
+		{"chmod +x run.sh", Caution},
+
+		{"rm -rf build", Destructive},
+		{"sudo rm /etc/hosts", Destructive},
+		{"cd repo && git reset --hard HEAD~1", Destructive},
+		{"git clean -fdx", Destructive},
+		{"git push -f origin main", Destructive},
+		{"git push origin +main", Destructive},
+		{"git branch -D feature", Destructive},
+		{"git checkout -- .", Destructive},
+		{"git stash drop", Destructive},
+		{"dd if=image.iso of=/dev/sdb bs=4M", Destructive},
+		{"sudo mkfs.ext4 /dev/sdb1", Destructive},
+		{"docker system prune -af", Destructive},
+		{"kubectl delete namespace staging", Destructive},
+		{"terraform destroy", Destructive},
+		{`psql -c "DROP TABLE users"`, Destructive},
+		{"find . -name '*.tmp' -delete", Destructive},
+		{"find . -name '*.o' -exec rm {} +", Destructive},
+		{"sudo chown -R me /", Destructive},
+		{"cat zero > /dev/sda", Destructive},
+		{":(){ :|:& };:", Destructive},
+		{"ls; FOO=1 xargs rm < list", Destructive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := Classify(tt.command)
+			if got.Level != tt.want {
+				t.Fatalf("Classify(%q) = %v (%q), want %v", tt.command, got.Level, got.Reason, tt.want)
+			}
+			if (got.Level == Safe) != (got.Reason == "") {
+				t.Fatalf("Classify(%q) reason %q does not match level %v", tt.command, got.Reason, got.Level)
+			}
+		})
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	for level, want := range map[Level]string{Safe: "safe", Caution: "caution", Destructive: "destructive"} {
+		if got := level.String(); got != want {
+			t.Fatalf("Level(%d).String() = %q, want %q", level, got, want)
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, command string
+		want             bool
+	}{
+		{"terraform destroy", "terraform destroy -auto-approve", true},
+		{"terraform destroy", "terraform  destroy", true},
+		{"terraform destroy", "terraform destroyer", false},
+		{"kubectl * --all", "kubectl delete pods --all", true},
+		{"kubectl * --all", "kubectl get pods", false},
+		{"  ", "ls", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPattern(tt.pattern, tt.command); got != tt.want {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.command, got, tt.want)
+		}
+	}
+}
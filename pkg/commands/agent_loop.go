@@ -160,14 +160,20 @@ type AgentLoopConfig struct {
 	// Tag identifies the calling flow (e.g. "explain", "chat") in slog
 	// records. Optional; logs use "agent" if empty.
 	Tag string
+
+	// LocalModel is set when the provider is served from this machine.
+	// The loop then waits for ai.DefaultLocalWarmup before its first call
+	// and explains load and out-of-memory failures.
+	LocalModel bool
 }
 
 // RunAgentLoop drives one /explain or /chat invocation: alternating provider
 // streams and tool executions until the model produces a turn with no further
 // tool calls (or we hit the iteration cap).
 //
-// Text deltas are forwarded to out as they arrive. Tool-call lifecycle events
-// (start, approval, finished) are also emitted on out. The function closes
+// Text and reasoning deltas are forwarded to out as they arrive, along with
+// the provider's tool-call, usage and notice events. Tool-call lifecycle
+// events (start, approval, finished) are also emitted on out. The function closes
 // out before returning, regardless of outcome.
 //
 // Cancellation: respects ctx. Each provider call gets its own
@@ -207,8 +213,13 @@ func RunAgentLoop(
 		req.Tools = nil
 	}
 
+	if cfg.LocalModel {
+		waitForLocalModel(ctx, out)
+	}
+
 	totalToolCalls := 0
 	toolCallsThisBatch := 0
+	var usage ai.Usage
 	for iter := 0; ; iter++ {
 		if err := ctx.Err(); err != nil {
 			out <- WtfStreamEvent{Err: err, Done: true}
@@ -256,21 +267,37 @@ func RunAgentLoop(
 		if err != nil {
 			cancel()
 			slog.Error("agent_stream_open_error", "tag", tag, "iter", iter, "error", err)
+			if cfg.LocalModel {
+				err = ai.ExplainLocalModelError(err)
+			}
 			out <- WtfStreamEvent{Err: err, Done: true}
 			return
 		}
 
-		assistantText, drainErr := drainStreamText(ctx, stream, out)
+		assistantText, callUsage, drainErr := drainStream(ctx, stream, out, usage)
 		toolCalls := stream.ToolCalls()
 		stopReason := stream.StopReason()
 		stream.Close()
 		cancel()
+		usage = usage.Add(callUsage)
 
 		if drainErr != nil {
 			slog.Error("agent_stream_error", "tag", tag, "iter", iter, "error", drainErr)
+			if cfg.LocalModel {
+				drainErr = ai.ExplainLocalModelError(drainErr)
+			}
 			out <- WtfStreamEvent{Err: drainErr, Done: true}
 			return
 		}
+		if notice := ai.TruncationNotice(stopReason); notice != "" {
+			out <- WtfStreamEvent{Notice: notice}
+		}
+		if len(toolCalls) == 0 {
+			if reason := ai.RefusalReason(stopReason, assistantText); reason != "" {
+				slog.Info("agent_refusal", "tag", tag, "iter", iter, "reason", reason, "stop_reason", stopReason)
+				out <- WtfStreamEvent{Refusal: reason}
+			}
+		}
 
 		slog.Debug("agent_iteration_response",
 			"tag", tag,
@@ -278,6 +305,8 @@ func RunAgentLoop(
 			"text_chars", utf8.RuneCountInString(assistantText),
 			"tool_calls", len(toolCalls),
 			"stop_reason", stopReason,
+			"input_tokens", callUsage.InputTokens,
+			"output_tokens", callUsage.OutputTokens,
 		)
 
 		if len(toolCalls) == 0 {
@@ -328,27 +357,52 @@ func RunAgentLoop(
 	}
 }
 
-// drainStreamText forwards every non-empty text delta to out and returns the
-// concatenated assistant text and any stream error. Tool-call deltas are
-// already absorbed by the provider's stream wrapper; we only deal with text.
-func drainStreamText(ctx context.Context, stream ai.ChatStream, out chan<- WtfStreamEvent) (string, error) {
+// drainStream forwards the stream's events to out and returns the
+// concatenated assistant text, the token usage of this call and any stream
+// error. Tool-call deltas are already absorbed by the provider's stream
+// wrapper; tool-call events only announce them. Usage is reported as a
+// running total for the whole invocation, on top of base.
+func drainStream(ctx context.Context, stream ai.ChatStream, out chan<- WtfStreamEvent, base ai.Usage) (string, ai.Usage, error) {
 	var sb strings.Builder
+	var callUsage ai.Usage
 	for stream.Next() {
 		if err := ctx.Err(); err != nil {
-			return sb.String(), err
+			return sb.String(), callUsage, err
 		}
-		delta := stream.Content()
-		if delta == "" {
+		var ev WtfStreamEvent
+		switch event := ai.CurrentEvent(stream); event.Kind {
+		case ai.StreamEventContent:
+			if event.Text == "" {
+				continue
+			}
+			sb.WriteString(event.Text)
+			ev.Delta = event.Text
+		case ai.StreamEventReasoning:
+			if event.Text == "" {
+				continue
+			}
+			ev.Reasoning = event.Text
+		case ai.StreamEventToolCall:
+			ev.ToolCallPending = &ToolCallInfo{ID: event.ToolCallID, Name: event.ToolName}
+		case ai.StreamEventUsage:
+			callUsage = event.Usage
+			total := base.Add(callUsage)
+			ev.Usage = &total
+		case ai.StreamEventNotice:
+			if event.Text == "" {
+				continue
+			}
+			ev.Notice = event.Text
+		default:
 			continue
 		}
-		sb.WriteString(delta)
 		select {
-		case out <- WtfStreamEvent{Delta: delta}:
+		case out <- ev:
 		case <-ctx.Done():
-			return sb.String(), ctx.Err()
+			return sb.String(), callUsage, ctx.Err()
 		}
 	}
-	return sb.String(), stream.Err()
+	return sb.String(), callUsage, stream.Err()
 }
 
 // executeOneTool runs the classify+approve+invoke cycle for a single tool
@@ -503,3 +557,15 @@ func toolNames(r *tools.Registry) []string {
 	}
 	return names
 }
+
+// waitForLocalModel holds a request while ai.DefaultLocalWarmup loads the
+// model, telling the user why the answer has not started yet.
+func waitForLocalModel(ctx context.Context, out chan<- WtfStreamEvent) {
+	progress := ai.DefaultLocalWarmup.Progress()
+	if !progress.Loading {
+		return
+	}
+	slog.Info("local_model_request_queued", "model", progress.Model)
+	out <- WtfStreamEvent{Notice: "Waiting for " + progress.Model + " to finish loading..."}
+	_ = ai.DefaultLocalWarmup.Wait(ctx)
+}
@@ -42,15 +42,17 @@ func (p *fakeProvider) CreateChatCompletionStream(_ context.Context, req ai.Chat
 func (p *fakeProvider) Capabilities() ai.ProviderCapabilities { return p.caps }
 
 // fakeStream emits the listed text chunks one at a time, then exposes the
-// supplied toolCalls via ToolCalls() once Next() returns false.
+// supplied toolCalls via ToolCalls() once Next() returns false. When events is
+// set it is emitted instead, as a stream with typed events.
 type fakeStream struct {
 	textChunks []string
+	events     []ai.StreamEvent
 	toolCalls  []ai.ToolCall
 	stopReason string
 	streamErr  error
 
 	idx     int
-	current string
+	current ai.StreamEvent
 	done    bool
 }
 
@@ -58,16 +60,31 @@ func (s *fakeStream) Next() bool {
 	if s.done {
 		return false
 	}
+	if s.events != nil {
+		if s.idx >= len(s.events) {
+			s.done = true
+			return false
+		}
+		s.current = s.events[s.idx]
+		s.idx++
+		return true
+	}
 	if s.idx >= len(s.textChunks) {
 		s.done = true
 		return false
 	}
-	s.current = s.textChunks[s.idx]
+	s.current = ai.StreamEvent{Kind: ai.StreamEventContent, Text: s.textChunks[s.idx]}
 	s.idx++
 	return true
 }
 
-func (s *fakeStream) Content() string          { return s.current }
+func (s *fakeStream) Content() string {
+	if s.current.Kind != ai.StreamEventContent {
+		return ""
+	}
+	return s.current.Text
+}
+func (s *fakeStream) Event() ai.StreamEvent    { return s.current }
 func (s *fakeStream) Err() error               { return s.streamErr }
 func (s *fakeStream) Close() error             { return nil }
 func (s *fakeStream) ToolCalls() []ai.ToolCall { return s.toolCalls }
@@ -174,6 +191,34 @@ func TestRunAgentLoop_NoToolCalls_SingleTurn(t *testing.T) {
 	}
 }
 
+func TestRunAgentLoop_ReportsRefusal(t *testing.T) {
+	provider := &fakeProvider{
+		caps: ai.ProviderCapabilities{Tools: true},
+		streams: []*fakeStream{
+			{textChunks: []string{"I can't share that."}, stopReason: "content_filter"},
+		},
+	}
+
+	ch := make(chan WtfStreamEvent, 8)
+	go RunAgentLoop(context.Background(), provider, ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "hi"}},
+	}, AgentLoopConfig{
+		Registry:      tools.NewRegistry(),
+		Approver:      AutoAllowApprover{},
+		MaxIterations: 5,
+	}, ch)
+
+	var refusals []string
+	for _, e := range drain(t, ch, 2*time.Second) {
+		if e.Refusal != "" {
+			refusals = append(refusals, e.Refusal)
+		}
+	}
+	if fmt.Sprint(refusals) != "[content filter]" {
+		t.Fatalf("refusals = %v, want [content filter]", refusals)
+	}
+}
+
 func TestRunAgentLoop_OneToolCall_TwoTurns(t *testing.T) {
 	echo := &echoTool{}
 	provider := &fakeProvider{
@@ -251,6 +296,87 @@ func TestRunAgentLoop_OneToolCall_TwoTurns(t *testing.T) {
 	}
 }
 
+func TestRunAgentLoop_ForwardsTypedStreamEvents(t *testing.T) {
+	echo := &echoTool{}
+	provider := &fakeProvider{
+		caps: ai.ProviderCapabilities{Tools: true},
+		streams: []*fakeStream{
+			{
+				events: []ai.StreamEvent{
+					{Kind: ai.StreamEventReasoning, Text: "need the file"},
+					{Kind: ai.StreamEventToolCall, ToolCallID: "call_1", ToolName: "echo"},
+					{Kind: ai.StreamEventUsage, Usage: ai.Usage{InputTokens: 100, OutputTokens: 10}},
+				},
+				toolCalls: []ai.ToolCall{
+					{ID: "call_1", Name: "echo", Arguments: json.RawMessage(`{}`)},
+				},
+				stopReason: "tool_calls",
+			},
+			{
+				events: []ai.StreamEvent{
+					{Kind: ai.StreamEventContent, Text: "partial"},
+					{Kind: ai.StreamEventNotice, Text: "rate limited, retrying"},
+					{Kind: ai.StreamEventUsage, Usage: ai.Usage{InputTokens: 150, OutputTokens: 20}},
+				},
+				stopReason: "length",
+			},
+		},
+	}
+
+	ch := make(chan WtfStreamEvent, 32)
+	go RunAgentLoop(context.Background(), provider, ai.ChatRequest{
+		Messages: []ai.Message{{Role: "user", Content: "hi"}},
+	}, AgentLoopConfig{
+		Registry:      newRegistryWithEcho(echo),
+		Approver:      AutoAllowApprover{},
+		MaxIterations: 5,
+	}, ch)
+
+	events := drain(t, ch, 2*time.Second)
+
+	var reasoning, deltas string
+	var pending []string
+	var usages []ai.Usage
+	var notices []string
+	for _, e := range events {
+		reasoning += e.Reasoning
+		deltas += e.Delta
+		if e.ToolCallPending != nil {
+			pending = append(pending, e.ToolCallPending.ID+":"+e.ToolCallPending.Name)
+		}
+		if e.Usage != nil {
+			usages = append(usages, *e.Usage)
+		}
+		if e.Notice != "" {
+			notices = append(notices, e.Notice)
+		}
+	}
+	if reasoning != "need the file" {
+		t.Errorf("reasoning = %q", reasoning)
+	}
+	if deltas != "partial" {
+		t.Errorf("deltas = %q, want only content text", deltas)
+	}
+	if fmt.Sprint(pending) != "[call_1:echo]" {
+		t.Errorf("pending tool calls = %v", pending)
+	}
+	// Usage is a running total across the loop's provider calls.
+	wantUsages := []ai.Usage{{InputTokens: 100, OutputTokens: 10}, {InputTokens: 250, OutputTokens: 30}}
+	if fmt.Sprint(usages) != fmt.Sprint(wantUsages) {
+		t.Errorf("usages = %v, want %v", usages, wantUsages)
+	}
+	wantNotices := []string{"rate limited, retrying", ai.TruncationNotice("length")}
+	if fmt.Sprint(notices) != fmt.Sprint(wantNotices) {
+		t.Errorf("notices = %q, want %q", notices, wantNotices)
+	}
+
+	// Reasoning is not part of the assistant turn sent back to the model.
+	asst := provider.receivedReqs[1].Messages[len(provider.receivedReqs[1].Messages)-2]
+	if asst.Role != "assistant" || asst.Content != "" {
+		t.Errorf("assistant turn = %+v, want empty content", asst)
+	}
+}
+
 // denyApprover always denies and counts how many times it was called.
 type denyApprover struct{ count int }
 
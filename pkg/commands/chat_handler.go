@@ -57,22 +57,13 @@ func (h *ChatHandler) StartChatStreamWithContext(
 	if runCtx == nil {
 		runCtx = context.Background()
 	}
-	// Cap history to last N messages
-	capped := messages
-	if len(messages) > MaxChatHistoryMessages {
-		capped = messages[len(messages)-MaxChatHistoryMessages:]
-	}
-
-	prep, err := prepareAgentRun(ctx, "chat")
+	prep, prompt, err := prepareChat(ctx, messages)
 	if err != nil {
 		return nil, err
 	}
 
-	aiMessages := buildChatMessages(capped, ctx)
+	aiMessages := prompt.messages()
 	toolDefs := prep.registry.Definitions()
-	if len(toolDefs) > 0 && len(aiMessages) > 0 && aiMessages[0].Role == "system" {
-		aiMessages[0].Content = ai.AppendToolInstructions(aiMessages[0].Content, toolDefs)
-	}
 
 	logger := slog.Default()
 	if logger.Enabled(context.Background(), logging.LevelTrace) {
@@ -91,6 +82,7 @@ func (h *ChatHandler) StartChatStreamWithContext(
 		Messages:    aiMessages,
 		Temperature: &prep.temperature,
 		MaxTokens:   &prep.maxTokens,
+		TopP:        prep.topP,
 		Tools:       toolDefs,
 	}
 
@@ -98,7 +90,8 @@ func (h *ChatHandler) StartChatStreamWithContext(
 		"model", prep.model,
 		"message_count", len(aiMessages),
 		"history_messages", len(messages),
-		"capped_history", len(capped),
+		"capped_history", len(prompt.history),
+		"terminal_context_edited", ctx.TerminalContextOverride != nil,
 		"tools", len(toolDefs),
 	)
 
@@ -115,6 +108,7 @@ func (h *ChatHandler) StartChatStreamWithContext(
 			MaxIterations:  prep.maxIterations,
 			PerCallTimeout: time.Duration(prep.timeout) * time.Second,
 			Tag:            "chat",
+			LocalModel:     prep.localModel,
 		}, ch)
 	}()
 
@@ -139,12 +133,110 @@ func (h *ChatHandler) resolveContinuer(ch chan<- WtfStreamEvent) Continuer {
 	return AutoStopContinuer{}
 }
 
+// capChatHistory keeps the last MaxChatHistoryMessages messages plus any
+// pinned messages from before that window, in their original order. When
+// the last user message was sent fresh, nothing before it is kept.
+func capChatHistory(messages []ai.ChatMessage) []ai.ChatMessage {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			if messages[i].Fresh {
+				messages = messages[i:]
+			}
+			break
+		}
+	}
+	if len(messages) <= MaxChatHistoryMessages {
+		return messages
+	}
+	cut := len(messages) - MaxChatHistoryMessages
+	capped := make([]ai.ChatMessage, 0, MaxChatHistoryMessages)
+	for _, msg := range messages[:cut] {
+		if msg.Pinned {
+			capped = append(capped, msg)
+		}
+	}
+	return append(capped, messages[cut:]...)
+}
+
+// ChatPreview is the request a chat message would send, split into the
+// sections the prompt preview shows.
+type ChatPreview struct {
+	Model string
+
+	// SystemPrompt is the chat system prompt, sent ahead of the terminal
+	// context in the system message.
+	SystemPrompt string
+
+	// TerminalContext is the terminal output and metadata excerpt. Setting
+	// Context.TerminalContextOverride sends an edited copy instead.
+	TerminalContext string
+
+	// Instructions are the tool, memory and language instructions that end
+	// the system message.
+	Instructions string
+
+	// History is the conversation after capping to MaxChatHistoryMessages.
+	History []ai.Message
+}
+
+// PreviewChat assembles the request StartChatStreamWithContext would send
+// for messages without sending it.
+func (h *ChatHandler) PreviewChat(ctx *Context, messages []ai.ChatMessage) (ChatPreview, error) {
+	prep, prompt, err := prepareChat(ctx, messages)
+	if err != nil {
+		return ChatPreview{}, err
+	}
+	return ChatPreview{
+		Model:           prep.model,
+		SystemPrompt:    prompt.system,
+		TerminalContext: prompt.terminal,
+		Instructions:    strings.TrimSpace(prompt.extras),
+		History:         prompt.history,
+	}, nil
+}
+
+// prepareChat resolves the run settings and assembles the chat prompt, with
+// the instructions that depend on them.
+func prepareChat(ctx *Context, messages []ai.ChatMessage) (*agentRunPrep, chatPrompt, error) {
+	prep, err := prepareAgentRun(ctx, "chat")
+	if err != nil {
+		return nil, chatPrompt{}, err
+	}
+	ctx.ContextLines = prep.contextLines
+
+	prompt := buildChatPrompt(capChatHistory(messages), ctx)
+	extras := ai.AppendToolInstructions("", prep.registry.Definitions())
+	extras = ai.AppendMemories(extras, prep.memories)
+	prompt.extras = ai.AppendLanguageInstruction(extras, prep.language)
+	return prep, prompt, nil
+}
+
+// chatPrompt is a chat request before it is flattened into messages.
+type chatPrompt struct {
+	system   string
+	terminal string
+	extras   string // Appended verbatim after the terminal context
+	history  []ai.Message
+}
+
+// messages returns a single system message combining the prompt and TTY
+// context, then the history.
+func (p chatPrompt) messages() []ai.Message {
+	msgs := make([]ai.Message, 0, len(p.history)+1)
+	msgs = append(msgs, ai.Message{Role: "system", Content: p.system + "\n\n" + p.terminal + p.extras})
+	return append(msgs, p.history...)
+}
+
 // buildChatMessages constructs AI messages from chat history + terminal context.
 func buildChatMessages(
 	history []ai.ChatMessage,
 	ctx *Context,
 ) []ai.Message {
-	lines := ctx.GetLastNLines(ai.DefaultContextLines)
+	return buildChatPrompt(history, ctx).messages()
+}
+
+func buildChatPrompt(history []ai.ChatMessage, ctx *Context) chatPrompt {
+	lines := ctx.GetLastNLines(ctx.contextLines())
 
 	// Use existing helper (pulls last command/exit code from session)
 	meta := buildTerminalMetadata(ctx)
@@ -152,22 +244,20 @@ func buildChatMessages(
 	// Use chat-specific context builder (background context framing, not diagnostic)
 	termCtx := ai.BuildChatContext(lines, meta)
 
-	// Build messages: single system message combining prompt + TTY context, then history
-	msgs := []ai.Message{
-		{Role: "system", Content: termCtx.SystemPrompt + "\n\n" + termCtx.UserPrompt},
+	prompt := chatPrompt{system: termCtx.SystemPrompt, terminal: termCtx.UserPrompt}
+	if ctx.TerminalContextOverride != nil {
+		prompt.terminal = *ctx.TerminalContextOverride
 	}
 
-	// Append conversation history
 	for _, msg := range history {
 		// Skip ephemeral UI placeholder messages from prompt history.
 		if msg.Role == "assistant" && strings.TrimSpace(msg.Content) == chatThinkingPlaceholder {
 			continue
 		}
-		msgs = append(msgs, ai.Message{
+		prompt.history = append(prompt.history, ai.Message{
 			Role:    msg.Role,
 			Content: msg.Content,
 		})
 	}
-
-	return msgs
+	return prompt
 }
@@ -1,12 +1,18 @@
 package commands
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"wtf_cli/pkg/ai"
+	_ "wtf_cli/pkg/ai/providers"
+	"wtf_cli/pkg/ai/tools"
 	"wtf_cli/pkg/buffer"
 	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
 )
 
 func TestChatHandler_buildChatMessages_IncludesCommandTagInstruction(t *testing.T) {
@@ -110,6 +116,43 @@ func TestChatHandler_MessageCapping(t *testing.T) {
 	}
 }
 
+func TestCapChatHistory_KeepsPinnedMessages(t *testing.T) {
+	history := make([]ai.ChatMessage, MaxChatHistoryMessages+5)
+	for i := range history {
+		history[i] = ai.ChatMessage{Role: "user", Content: fmt.Sprintf("m%d", i)}
+	}
+	history[1].Pinned = true
+
+	capped := capChatHistory(history)
+	if len(capped) != MaxChatHistoryMessages+1 {
+		t.Fatalf("Expected %d messages, got %d", MaxChatHistoryMessages+1, len(capped))
+	}
+	if capped[0].Content != "m1" || !capped[0].Pinned {
+		t.Errorf("Expected pinned message first, got %+v", capped[0])
+	}
+	if last := capped[len(capped)-1].Content; last != fmt.Sprintf("m%d", len(history)-1) {
+		t.Errorf("Expected newest message last, got %q", last)
+	}
+}
+
+func TestCapChatHistory_FreshMessageDropsEarlierTurns(t *testing.T) {
+	history := []ai.ChatMessage{
+		{Role: "user", Content: "old question", Pinned: true},
+		{Role: "assistant", Content: "old answer"},
+		{Role: "user", Content: "new question", Fresh: true},
+		{Role: "assistant", Content: "new answer"},
+		{Role: "user", Content: "follow-up"},
+	}
+
+	if capped := capChatHistory(history); len(capped) != len(history) {
+		t.Fatalf("Expected a follow-up to send the whole conversation, got %+v", capped)
+	}
+	capped := capChatHistory(history[:3])
+	if len(capped) != 1 || capped[0].Content != "new question" {
+		t.Fatalf("Expected only the fresh message, got %+v", capped)
+	}
+}
+
 func TestChatHandler_MessageCapping_ExactLimit(t *testing.T) {
 	// Create exactly MaxChatHistoryMessages
 	history := make([]ai.ChatMessage, MaxChatHistoryMessages)
@@ -216,6 +259,59 @@ func TestBuildTerminalMetadata_NoSession(t *testing.T) {
 	}
 }
 
+func TestBuildTerminalMetadata_Jobs(t *testing.T) {
+	ctx := NewContext(buffer.New(100), nil, "/tmp")
+	ctx.Jobs = []ai.BackgroundJob{{PID: 4242, Command: "python3 -m http.server 8080"}}
+	ctx.Trust = ai.TrustNever
+	meta := buildTerminalMetadata(ctx)
+
+	if len(meta.Jobs) != 1 || meta.Jobs[0].PID != 4242 {
+		t.Fatalf("Expected the background job in the metadata, got %+v", meta.Jobs)
+	}
+}
+
+func TestBuildTerminalMetadata_WithholdsByTrust(t *testing.T) {
+	dir := t.TempDir()
+	makefile := "test: ## Run the tests\n\tgo test ./...\n"
+	if err := os.WriteFile(filepath.Join(dir, "Makefile"), []byte(makefile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := []ai.EnvVar{{Name: "GOFLAGS", Value: "-mod=mod"}}
+
+	cases := []struct {
+		trust     ai.WorkspaceTrust
+		dir       string
+		types     int
+		tasks     int
+		envShared bool
+	}{
+		{ai.TrustTrusted, dir, 1, 1, true},
+		{ai.TrustRestricted, dir, 1, 0, false},
+		{ai.TrustUndecided, dir, 1, 0, false},
+		{ai.TrustNever, "", 0, 0, false},
+	}
+	for _, tc := range cases {
+		ctx := NewContext(buffer.New(100), nil, dir)
+		ctx.Env = env
+		ctx.Workspace = ai.WorkspaceIndex{Root: dir, Files: []string{"Makefile"}, Total: 1}
+		ctx.Trust = tc.trust
+		meta := buildTerminalMetadata(ctx)
+
+		if meta.WorkingDir != tc.dir {
+			t.Errorf("%q: WorkingDir = %q, want %q", tc.trust, meta.WorkingDir, tc.dir)
+		}
+		if len(meta.Project.Types) != tc.types || len(meta.Project.Tasks) != tc.tasks {
+			t.Errorf("%q: project = %+v, want %d type(s) and %d task(s)", tc.trust, meta.Project, tc.types, tc.tasks)
+		}
+		if (len(meta.Env) > 0) != tc.envShared {
+			t.Errorf("%q: env = %v, want shared=%v", tc.trust, meta.Env, tc.envShared)
+		}
+		if (meta.Workspace.Root != "") != tc.envShared {
+			t.Errorf("%q: workspace = %+v, want shared=%v", tc.trust, meta.Workspace, tc.envShared)
+		}
+	}
+}
+
 func TestChatHandler_Name(t *testing.T) {
 	h := &ChatHandler{}
 	if h.Name() != "/chat" {
@@ -283,3 +379,93 @@ func TestChatHandler_buildChatMessages_UsesNonDiagnosticPrompt(t *testing.T) {
 		t.Errorf("Chat system prompt must contain <cmd> instruction, got: %q", systemMsg.Content)
 	}
 }
+
+func TestShareMemories_ByTrust(t *testing.T) {
+	memories := []ai.Memory{{Text: "staging is sg-02"}}
+	cases := []struct {
+		trust ai.WorkspaceTrust
+		path  string
+		want  int
+		tool  bool
+	}{
+		{ai.TrustTrusted, "memories.json", 1, true},
+		{ai.TrustRestricted, "memories.json", 1, true},
+		{ai.TrustRestricted, "", 1, false},
+		{ai.TrustNever, "memories.json", 0, false},
+	}
+	for _, tc := range cases {
+		ctx := NewContext(buffer.New(10), nil, t.TempDir())
+		ctx.Trust = tc.trust
+		ctx.Memories = memories
+		ctx.MemoriesPath = tc.path
+		registry := tools.NewRegistry()
+
+		got := shareMemories(ctx, registry)
+		if len(got) != tc.want {
+			t.Errorf("%q: got %d memories, want %d", tc.trust, len(got), tc.want)
+		}
+		if _, ok := registry.Get("remember"); ok != tc.tool {
+			t.Errorf("%q path %q: remember tool registered = %v, want %v", tc.trust, tc.path, ok, tc.tool)
+		}
+	}
+}
+
+func TestChatHandler_PreviewChat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.OpenRouter.APIKey = "test-key"
+	cfg.ResponseLanguage = "German"
+	if err := os.MkdirAll(filepath.Dir(config.GetConfigPath()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Save(config.GetConfigPath(), cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	buf := buffer.New(100)
+	buf.Write([]byte("make: *** [build] Error 1\n"))
+	ctx := NewContext(buf, nil, t.TempDir())
+	ctx.Trust = ai.TrustRestricted
+	history := []ai.ChatMessage{{Role: "user", Content: "why did make fail?"}}
+
+	preview, err := (&ChatHandler{}).PreviewChat(ctx, history)
+	if err != nil {
+		t.Fatalf("PreviewChat: %v", err)
+	}
+	if preview.Model == "" {
+		t.Error("expected the model to be resolved")
+	}
+	if !strings.Contains(preview.TerminalContext, "make: *** [build] Error 1") {
+		t.Errorf("expected the terminal output in the context, got %q", preview.TerminalContext)
+	}
+	if !strings.Contains(preview.Instructions, "German") {
+		t.Errorf("expected the language instruction, got %q", preview.Instructions)
+	}
+	if len(preview.History) != 1 || preview.History[0].Content != "why did make fail?" {
+		t.Errorf("unexpected history %+v", preview.History)
+	}
+
+	prompt := buildChatPrompt(history, ctx)
+	if prompt.system != preview.SystemPrompt || prompt.terminal != preview.TerminalContext {
+		t.Error("expected the preview to match the assembled prompt")
+	}
+}
+
+func TestChatHandler_buildChatMessages_TerminalContextOverride(t *testing.T) {
+	buf := buffer.New(100)
+	buf.Write([]byte("password=hunter2\n"))
+	ctx := NewContext(buf, nil, "/tmp")
+	edited := "password=<redacted>"
+	ctx.TerminalContextOverride = &edited
+
+	messages := buildChatMessages([]ai.ChatMessage{}, ctx)
+	if strings.Contains(messages[0].Content, "hunter2") {
+		t.Fatalf("expected the edited context to replace the buffer, got %q", messages[0].Content)
+	}
+	if !strings.Contains(messages[0].Content, edited) {
+		t.Fatalf("expected the edited context in the system message, got %q", messages[0].Content)
+	}
+	if !strings.Contains(messages[0].Content, "<cmd>") {
+		t.Fatal("expected the system prompt to be kept")
+	}
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+)
+
+const (
+	chatTitleMaxWords   = 6
+	chatTitleMaxTokens  = 32
+	chatTitleInputRunes = 1500
+	chatTitlePrompt     = "Write a title of 4 to 6 words for the conversation below. " +
+		"Reply with the title only: no quotes, no trailing punctuation."
+)
+
+var errNoChatExchange = errors.New("no completed exchange to title")
+
+// GenerateChatTitle asks the configured provider for a short title that
+// summarizes the first user/assistant exchange in messages.
+func GenerateChatTitle(runCtx context.Context, ctx *Context, messages []ai.ChatMessage) (string, error) {
+	prep, err := prepareAgentRun(ctx, "chat_title")
+	if err != nil {
+		return "", err
+	}
+	return requestChatTitle(runCtx, prep.provider, prep.model, prep.language, messages)
+}
+
+func requestChatTitle(ctx context.Context, provider ai.Provider, model, language string, messages []ai.ChatMessage) (string, error) {
+	transcript := chatTitleTranscript(messages)
+	if transcript == "" {
+		return "", errNoChatExchange
+	}
+
+	temperature := 0.2
+	maxTokens := chatTitleMaxTokens
+	resp, err := provider.CreateChatCompletion(ctx, ai.ChatRequest{
+		Model: model,
+		Messages: []ai.Message{
+			{Role: "system", Content: ai.AppendLanguageInstruction(chatTitlePrompt, language)},
+			{Role: "user", Content: transcript},
+		},
+		Temperature: &temperature,
+		MaxTokens:   &maxTokens,
+	})
+	if err != nil {
+		slog.Warn("chat_title_error", "error", err)
+		return "", err
+	}
+
+	title := CleanChatTitle(resp.Content)
+	if title == "" {
+		slog.Warn("chat_title_empty", "raw", resp.Content)
+		return "", errors.New("model returned an empty title")
+	}
+	slog.Info("chat_title", "title", title)
+	return title, nil
+}
+
+// chatTitleTranscript renders the first user message and the assistant reply
+// that follows it. It returns "" until both exist.
+func chatTitleTranscript(messages []ai.ChatMessage) string {
+	var user string
+	for _, msg := range messages {
+		content := strings.TrimSpace(msg.Content)
+		if content == "" || content == chatThinkingPlaceholder {
+			continue
+		}
+		switch {
+		case msg.Role == "user" && user == "":
+			user = content
+		case msg.Role == "assistant" && user != "":
+			return "User: " + truncateRunes(user, chatTitleInputRunes) +
+				"\n\nAssistant: " + truncateRunes(content, chatTitleInputRunes)
+		}
+	}
+	return ""
+}
+
+// CleanChatTitle normalizes a model-written title: first line only, without
+// markdown, quotes, a "Title:" label or trailing punctuation, capped at
+// chatTitleMaxWords words.
+func CleanChatTitle(raw string) string {
+	line := ""
+	for candidate := range strings.SplitSeq(raw, "\n") {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			line = candidate
+			break
+		}
+	}
+	line = strings.TrimLeft(line, "# ")
+	if len(line) > 6 && strings.EqualFold(line[:6], "title:") {
+		line = line[6:]
+	}
+	line = strings.Trim(line, " \t\"'`*_“”‘’")
+	line = strings.TrimRight(line, ".!?:;,")
+
+	words := strings.Fields(line)
+	if len(words) > chatTitleMaxWords {
+		words = words[:chatTitleMaxWords]
+	}
+	return strings.Join(words, " ")
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
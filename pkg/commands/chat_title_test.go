@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+)
+
+// titleProvider answers CreateChatCompletion with a canned reply.
+type titleProvider struct {
+	fakeProvider
+	reply string
+	err   error
+	req   ai.ChatRequest
+}
+
+func (p *titleProvider) CreateChatCompletion(_ context.Context, req ai.ChatRequest) (ai.ChatResponse, error) {
+	p.req = req
+	return ai.ChatResponse{Content: p.reply}, p.err
+}
+
+func TestRequestChatTitle(t *testing.T) {
+	provider := &titleProvider{reply: "\"Fixing Docker Permission Denied.\"\n"}
+	messages := []ai.ChatMessage{
+		{Role: "user", Content: "why does docker say permission denied?"},
+		{Role: "assistant", Content: "Your user is not in the docker group."},
+	}
+
+	title, err := requestChatTitle(context.Background(), provider, "test-model", "German", messages)
+	if err != nil {
+		t.Fatalf("requestChatTitle() error: %v", err)
+	}
+	if title != "Fixing Docker Permission Denied" {
+		t.Fatalf("Expected cleaned title, got %q", title)
+	}
+	if provider.req.Model != "test-model" {
+		t.Fatalf("Expected model test-model, got %q", provider.req.Model)
+	}
+	if !strings.Contains(provider.req.Messages[0].Content, "German") {
+		t.Fatal("Expected language instruction in title prompt")
+	}
+	if !strings.Contains(provider.req.Messages[1].Content, "docker group") {
+		t.Fatal("Expected transcript to include the assistant reply")
+	}
+}
+
+func TestRequestChatTitle_Errors(t *testing.T) {
+	provider := &titleProvider{reply: "Title"}
+	onlyUser := []ai.ChatMessage{
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: chatThinkingPlaceholder},
+	}
+	if _, err := requestChatTitle(context.Background(), provider, "m", "", onlyUser); !errors.Is(err, errNoChatExchange) {
+		t.Fatalf("Expected errNoChatExchange, got %v", err)
+	}
+
+	messages := []ai.ChatMessage{
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: "hi"},
+	}
+	provider.reply = "  \n"
+	if _, err := requestChatTitle(context.Background(), provider, "m", "", messages); err == nil {
+		t.Fatal("Expected error for an empty title")
+	}
+
+	provider.err = errors.New("boom")
+	if _, err := requestChatTitle(context.Background(), provider, "m", "", messages); err == nil {
+		t.Fatal("Expected provider error to be returned")
+	}
+}
+
+func TestCleanChatTitle(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"Debugging Failing Go Tests", "Debugging Failing Go Tests"},
+		{"Title: Git Rebase Conflicts.", "Git Rebase Conflicts"},
+		{"# **Nginx Reload Errors**", "Nginx Reload Errors"},
+		{"\n\n“Disk Full On Build Server”\nextra", "Disk Full On Build Server"},
+		{"one two three four five six seven eight", "one two three four five six"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanChatTitle(tt.raw); got != tt.want {
+			t.Errorf("CleanChatTitle(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/buffer"
 	"wtf_cli/pkg/capture"
 )
@@ -11,6 +12,76 @@ type Context struct {
 	Session      *capture.SessionContext
 	CurrentDir   string
 	LastExitCode int
+
+	// ModelOverride, when set, replaces the configured model for this run.
+	ModelOverride string
+
+	// ProviderOverride, when set, answers this run with another configured
+	// provider and its model, unless ModelOverride is set too.
+	ProviderOverride string
+
+	// Params override the configured temperature, max tokens and top_p for
+	// this run.
+	Params ai.SamplingParams
+
+	// Env holds the environment variables the user chose to share.
+	Env []ai.EnvVar
+
+	// VirtualEnv is $VIRTUAL_ENV in the user's shell, the Python
+	// virtualenv it has activated. Empty when there is none.
+	VirtualEnv string
+
+	// Pinned holds the output blocks the user pinned to every request.
+	Pinned []ai.PinnedBlock
+
+	// Workspace is the map of the project built by the workspace indexer,
+	// empty when indexing is off. It is sent only to a workspace trusted
+	// with files.
+	Workspace ai.WorkspaceIndex
+
+	// Memories are the facts the user asked to remember for the project.
+	Memories []ai.Memory
+
+	// MemoriesPath is the memory file the remember tool writes to. Empty
+	// leaves the tool out, e.g. in one-shot runs where nobody approves it.
+	MemoriesPath string
+
+	// REPL is the interpreter the user is typing into, or nil at the shell.
+	REPL *capture.REPL
+
+	// Jobs are the shell's background jobs.
+	Jobs []ai.BackgroundJob
+
+	// Trust is the user's decision for the workspace; it limits what
+	// besides the terminal output is sent. Undecided shares as restricted.
+	Trust ai.WorkspaceTrust
+
+	// Args is the text typed after the command name in the palette.
+	Args string
+
+	// Visible holds the lines the terminal shows, as plain text, for
+	// commands that look at the screen. Nil outside the TUI.
+	Visible []string
+
+	// Selection is the text last selected in the terminal, which was also
+	// copied to the clipboard. Empty when there is none.
+	Selection string
+
+	// ContextLines sets how many terminal lines are sent to the model,
+	// replacing the configured amount. Zero means the configured amount.
+	ContextLines int
+
+	// TerminalContextOverride, when set, is sent to chat in place of the
+	// terminal context built from the buffer, e.g. after the user edited it
+	// in the prompt preview.
+	TerminalContextOverride *string
+}
+
+func (c *Context) contextLines() int {
+	if c.ContextLines > 0 {
+		return c.ContextLines
+	}
+	return ai.DefaultContextLines
 }
 
 // NewContext creates a new command context
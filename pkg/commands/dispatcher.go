@@ -9,9 +9,29 @@ import (
 type ResultAction string
 
 const (
-	ResultActionOpenHistoryPicker ResultAction = "open_history_picker"
-	ResultActionOpenSettings      ResultAction = "open_settings"
-	ResultActionToggleChat        ResultAction = "toggle_chat"
+	ResultActionOpenHistoryPicker  ResultAction = "open_history_picker"
+	ResultActionOpenSettings       ResultAction = "open_settings"
+	ResultActionOpenLanguagePicker ResultAction = "open_language_picker"
+	ResultActionOpenProviderPicker ResultAction = "open_provider_picker"
+	ResultActionToggleChat         ResultAction = "toggle_chat"
+	ResultActionExportScript       ResultAction = "export_script"
+	ResultActionOpenEnvPicker      ResultAction = "open_env_picker"
+	ResultActionWatch              ResultAction = "watch"
+	ResultActionOpenPrompts        ResultAction = "open_prompts"
+	ResultActionOpenUnpinPicker    ResultAction = "open_unpin_picker"
+	ResultActionOpenTasksPicker    ResultAction = "open_tasks_picker"
+	ResultActionOpenTrustPicker    ResultAction = "open_trust_picker"
+	ResultActionOpenLogs           ResultAction = "open_logs"
+	ResultActionRemember           ResultAction = "remember"
+	ResultActionOpenMemories       ResultAction = "open_memories"
+	ResultActionOpenSnippets       ResultAction = "open_snippets"
+	ResultActionTrigger            ResultAction = "trigger"
+	ResultActionOpenPeek           ResultAction = "open_peek"
+	ResultActionMark               ResultAction = "mark"
+	ResultActionOpenMarks          ResultAction = "open_marks"
+	ResultActionOpenReplay         ResultAction = "open_replay"
+	ResultActionPrivacy            ResultAction = "privacy"
+	ResultActionReadOnly           ResultAction = "read_only"
 )
 
 // Result represents the result of a command execution
@@ -20,6 +40,8 @@ type Result struct {
 	Content string
 	Action  ResultAction
 	Error   error
+	// Copy, when set, is copied to the clipboard as the result is shown.
+	Copy string
 }
 
 // Handler is the interface for command handlers
@@ -42,9 +64,38 @@ func NewDispatcher() *Dispatcher {
 
 	// Register default handlers
 	d.Register(&ChatHandler{})
-	d.Register(&ExplainHandler{})
+	prompts := &promptExperiment{}
+	for _, focus := range Focuses {
+		d.Register(&ExplainHandler{Focus: focus, prompts: prompts})
+	}
 	d.Register(&HistoryHandler{})
 	d.Register(&SettingsHandler{})
+	d.Register(&LanguageHandler{})
+	d.Register(&ProviderHandler{})
+	d.Register(&ExportScriptHandler{})
+	d.Register(&EnvHandler{})
+	d.Register(&WatchHandler{})
+	d.Register(&PromptsHandler{})
+	d.Register(&UnpinHandler{})
+	d.Register(&TasksHandler{})
+	d.Register(&TrustHandler{})
+	d.Register(&LogsHandler{})
+	d.Register(&RememberHandler{})
+	d.Register(&MemoriesHandler{})
+	d.Register(&SnippetsHandler{})
+	d.Register(&UsageHandler{})
+	d.Register(&DoctorHandler{})
+	d.Register(&TriggerHandler{})
+	d.Register(&PeekHandler{})
+	d.Register(&MarkHandler{})
+	d.Register(&MarksHandler{})
+	d.Register(&ReplayHandler{})
+	d.Register(&PrivacyHandler{})
+	d.Register(&ReadOnlyHandler{})
+	d.Register(&CalcHandler{})
+	d.Register(&TimestampHandler{})
+	d.Register(&Base64Handler{})
+	d.Register(&JSONPrettyHandler{})
 	d.Register(&HelpHandler{})
 
 	return d
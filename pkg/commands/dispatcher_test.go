@@ -31,7 +31,7 @@ func TestNewDispatcher(t *testing.T) {
 	}
 
 	// Check all commands are registered
-	commands := []string{"/chat", "/explain", "/history", "/settings", "/help"}
+	commands := []string{"/chat", "/explain", "/wtf", "/why", "/history", "/settings", "/language", "/help"}
 	for _, cmd := range commands {
 		if _, ok := d.GetHandler(cmd); !ok {
 			t.Errorf("Expected handler for %s to be registered", cmd)
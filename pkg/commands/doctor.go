@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+)
+
+// certExpiryWarning is how long before it expires a client certificate is
+// flagged.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// DoctorHandler handles the /doctor command
+type DoctorHandler struct {
+	now func() time.Time // Nil is time.Now
+}
+
+func (h *DoctorHandler) Name() string { return "/doctor" }
+func (h *DoctorHandler) Description() string {
+	return "Check the config and provider client certificates"
+}
+
+func (h *DoctorHandler) Execute(ctx *Context) *Result {
+	now := time.Now()
+	if h.now != nil {
+		now = h.now()
+	}
+	path := config.GetConfigPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		slog.Warn("doctor_config_load_error", "error", err)
+	}
+	return &Result{
+		Title:   "Doctor",
+		Content: formatDoctor(path, cfg, err, now),
+	}
+}
+
+// clientCertField is a provider's client certificate and the config field
+// it is set in.
+type clientCertField struct {
+	provider string
+	field    string
+	cert     config.ClientCertConfig
+}
+
+func clientCertFields(cfg config.Config) []clientCertField {
+	return []clientCertField{
+		{string(ai.ProviderOpenRouter), "openrouter.client_cert", cfg.OpenRouter.ClientCert},
+		{string(ai.ProviderOpenAI), "providers.openai.client_cert", cfg.Providers.OpenAI.ClientCert},
+		{string(ai.ProviderAnthropic), "providers.anthropic.client_cert", cfg.Providers.Anthropic.ClientCert},
+		{string(ai.ProviderGoogle), "providers.google.client_cert", cfg.Providers.Google.ClientCert},
+	}
+}
+
+func formatDoctor(path string, cfg config.Config, loadErr error, now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Config: %s\n", path)
+	if loadErr != nil {
+		fmt.Fprintf(&sb, "  ✗ %v\n\nPress Esc to close this panel.", loadErr)
+		return sb.String()
+	}
+	sb.WriteString("  ✓ loaded\n")
+	for _, o := range cfg.Env {
+		fmt.Fprintf(&sb, "  %s overrides %s\n", o.Var, o.Path)
+	}
+
+	provider := cfg.LLMProvider
+	if provider == "" {
+		provider = string(ai.ProviderOpenRouter)
+	}
+	fmt.Fprintf(&sb, "\nProvider: %s\n", provider)
+	if u := strings.TrimSpace(ai.ActiveAPIURL(cfg)); u != "" {
+		fmt.Fprintf(&sb, "  API URL: %s\n", u)
+	}
+
+	sb.WriteString("\nClient certificates:\n")
+	found := false
+	for _, f := range clientCertFields(cfg) {
+		if f.cert.IsZero() {
+			continue
+		}
+		found = true
+		active := ""
+		if f.provider == provider {
+			active = " (active)"
+		}
+		fmt.Fprintf(&sb, "  %s%s\n    File:    %s\n", f.field, active, f.cert.CertFile)
+		writeCertStatus(&sb, f.cert, now)
+	}
+	if !found {
+		sb.WriteString("  none configured\n")
+	}
+	sb.WriteString("\nPress Esc to close this panel.")
+	return sb.String()
+}
+
+// writeCertStatus reports whether cert loads and how long it is valid.
+func writeCertStatus(sb *strings.Builder, cert config.ClientCertConfig, now time.Time) {
+	pair, err := ai.LoadClientCert(cert)
+	if err != nil {
+		fmt.Fprintf(sb, "    ✗ %v\n", err)
+		return
+	}
+	leaf := pair.Leaf
+	if leaf == nil {
+		sb.WriteString("    ✗ no certificate found in the file\n")
+		return
+	}
+	fmt.Fprintf(sb, "    Subject: %s\n", leaf.Subject)
+	expires := leaf.NotAfter.UTC().Format("2006-01-02")
+	left := leaf.NotAfter.Sub(now)
+	switch {
+	case left <= 0:
+		fmt.Fprintf(sb, "    ✗ expired on %s\n", expires)
+	case now.Before(leaf.NotBefore):
+		fmt.Fprintf(sb, "    ✗ not valid before %s\n", leaf.NotBefore.UTC().Format("2006-01-02"))
+	case left < certExpiryWarning:
+		fmt.Fprintf(sb, "    ⚠ expires on %s, in %d days: renew it soon\n", expires, int(left.Hours()/24))
+	default:
+		fmt.Fprintf(sb, "    ✓ valid until %s\n", expires)
+	}
+}
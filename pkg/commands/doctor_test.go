@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+func writeTestCert(t *testing.T, notAfter time.Time) config.ClientCertConfig {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gateway-user"},
+		NotBefore:    notAfter.Add(-2 * 365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	cert := config.ClientCertConfig{CertFile: filepath.Join(dir, "client.pem"), KeyFile: filepath.Join(dir, "client.key")}
+	if err := os.WriteFile(cert.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cert.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestFormatDoctor_ClientCerts(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	cfg := config.Default()
+	cfg.LLMProvider = "openai"
+	cfg.Providers.OpenAI.ClientCert = writeTestCert(t, now.Add(10*24*time.Hour))
+	cfg.Providers.Anthropic.ClientCert = writeTestCert(t, now.Add(400*24*time.Hour))
+	cfg.Providers.Google.ClientCert = config.ClientCertConfig{CertFile: "/nonexistent.pem", KeyFile: "/nonexistent.key"}
+
+	got := formatDoctor("/tmp/config.json", cfg, nil, now)
+	for _, want := range []string{
+		"providers.openai.client_cert (active)",
+		"Subject: CN=gateway-user",
+		"⚠ expires on 2026-10-11, in 10 days",
+		"✓ valid until 2027-11-05",
+		"providers.google.client_cert\n    File:    /nonexistent.pem\n    ✗ load client certificate",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	got = formatDoctor("/tmp/config.json", config.Default(), nil, now)
+	if !strings.Contains(got, "Client certificates:\n  none configured") {
+		t.Errorf("expected no certificates, got:\n%s", got)
+	}
+	got = formatDoctor("/tmp/config.json", config.Config{}, errors.New("bad json"), now)
+	if !strings.Contains(got, "✗ bad json") {
+		t.Errorf("expected the load error, got:\n%s", got)
+	}
+}
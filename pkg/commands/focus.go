@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Focus is what an analysis command looks at and what it asks about it.
+// /explain, /wtf and /why are ExplainHandlers with different focuses: they
+// share the agent loop, tools and prompt variants and differ only in the
+// context built for the request.
+type Focus struct {
+	Command     string // e.g. "/wtf"
+	Description string
+	Title       string // Title of the result panel
+
+	// Region is the output sent, one of the config.FocusRegion values.
+	Region string
+	// SystemFacts adds ai.SystemFacts to the request.
+	SystemFacts bool
+	// Task is what the model is asked to do; empty is the /explain request.
+	Task string
+	// ContextLines bounds the terminal lines sent; zero is the configured
+	// amount.
+	ContextLines int
+}
+
+// The built-in analysis commands.
+var (
+	FocusExplain = Focus{
+		Command:     "/explain",
+		Description: "Explain the output on screen",
+		Title:       "WTF Analysis",
+		Region:      config.FocusRegionVisible,
+	}
+	FocusWtf = Focus{
+		Command:     "/wtf",
+		Description: "Diagnose the last failed command",
+		Title:       "WTF Analysis",
+		Region:      config.FocusRegionLastFailure,
+		Task: "The user wants to know why last_command failed. Using its output below and last_exit_code, " +
+			"explain what went wrong and how to fix it. If the output does not show a failure, say so.",
+	}
+	FocusWhy = Focus{
+		Command:     "/why",
+		Description: "Find the root cause, with system facts",
+		Title:       "Root Cause",
+		Region:      config.FocusRegionRecent,
+		SystemFacts: true,
+		Task: "The user wants the root cause of the problem in the terminal output, not just the symptom. " +
+			"Trace the error back to what started it, use the system facts to confirm or rule out causes in the environment, " +
+			"and suggest how to fix the cause.",
+	}
+)
+
+// Focuses are the built-in analysis commands, in palette order.
+var Focuses = []Focus{FocusExplain, FocusWtf, FocusWhy}
+
+// WithConfig returns f with the user's settings for its command applied.
+func (f Focus) WithConfig(cfg config.FocusConfig) Focus {
+	c := cfg.For(f.Command)
+	if c.Region != "" {
+		f.Region = c.Region
+	}
+	if c.SystemFacts != nil {
+		f.SystemFacts = *c.SystemFacts
+	}
+	if c.ContextLines > 0 {
+		f.ContextLines = c.ContextLines
+	}
+	if task := strings.TrimSpace(c.Task); task != "" {
+		f.Task = task
+	}
+	return f
+}
+
+// FocusedOutput is the terminal output a focus selected.
+type FocusedOutput struct {
+	Lines [][]byte
+	// Region is where Lines came from. It differs from the focus's region
+	// when that was not available, e.g. no command has failed yet.
+	Region string
+	// LastLine is the buffer line number of the last line; 0 when unknown.
+	LastLine int
+	// Failed is the failed command the output belongs to, for
+	// config.FocusRegionLastFailure.
+	Failed *capture.CommandRecord
+}
+
+// Output selects up to maxLines lines of the terminal output f looks at,
+// falling back to the most recent output.
+func (f Focus) Output(ctx *Context, maxLines int) FocusedOutput {
+	switch f.Region {
+	case config.FocusRegionVisible:
+		if lines := visibleLines(ctx.Visible, maxLines); len(lines) > 0 {
+			return FocusedOutput{
+				Lines:    lines,
+				Region:   config.FocusRegionVisible,
+				LastLine: locateLastLine(ctx, lines),
+			}
+		}
+	case config.FocusRegionLastFailure:
+		if out, ok := failureOutput(ctx, maxLines); ok {
+			return out
+		}
+	}
+	out := FocusedOutput{Lines: ctx.GetLastNLines(maxLines), Region: config.FocusRegionRecent}
+	if ctx.Buffer != nil {
+		out.LastLine = ctx.Buffer.Total()
+	}
+	return out
+}
+
+// metadata builds the terminal metadata of a request about out.
+func (f Focus) metadata(ctx *Context, out FocusedOutput) ai.TerminalMetadata {
+	meta := buildTerminalMetadata(ctx)
+	meta.LastLine = out.LastLine
+	meta.Task = f.Task
+	if out.Failed != nil {
+		meta.LastCommand = out.Failed.Command
+		meta.ExitCode = out.Failed.ExitCode
+	}
+	if f.SystemFacts {
+		meta.System = ai.CollectSystemFacts(meta.WorkingDir)
+	}
+	return meta
+}
+
+// visibleLines returns the last maxLines of the lines on screen, without
+// the blank rows below the last output.
+func visibleLines(visible []string, maxLines int) [][]byte {
+	end := len(visible)
+	for end > 0 && strings.TrimSpace(visible[end-1]) == "" {
+		end--
+	}
+	start := 0
+	if maxLines > 0 && end-start > maxLines {
+		start = end - maxLines
+	}
+	lines := make([][]byte, 0, end-start)
+	for _, l := range visible[start:end] {
+		lines = append(lines, []byte(l))
+	}
+	return lines
+}
+
+// locateLastLine finds the buffer line number of the last line on screen by
+// its text, so answers can cite lines of a scrolled-back screen. It returns
+// 0 when the line is not in the buffer.
+func locateLastLine(ctx *Context, lines [][]byte) int {
+	if ctx.Buffer == nil || len(lines) == 0 {
+		return 0
+	}
+	want := strings.TrimRight(string(lines[len(lines)-1]), " ")
+	all := ctx.Buffer.GetAll()
+	for i := len(all) - 1; i >= 0; i-- {
+		if strings.TrimRight(ansi.Strip(string(all[i])), " ") == want {
+			return ctx.Buffer.Total() - (len(all) - 1 - i)
+		}
+	}
+	return 0
+}
+
+// failureOutput returns the output of the most recent command that exited
+// with an error, from the buffer while it is still there and from the
+// command's output snapshot after that.
+func failureOutput(ctx *Context, maxLines int) (FocusedOutput, bool) {
+	if ctx.Session == nil {
+		return FocusedOutput{}, false
+	}
+	history := ctx.Session.GetHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		rec := history[i]
+		if rec.ExitCode <= 0 || rec.BufferEnd == 0 {
+			continue
+		}
+		out := FocusedOutput{Region: config.FocusRegionLastFailure, Failed: &rec}
+		if ctx.Buffer != nil && rec.BufferEnd > rec.BufferStart {
+			out.Lines = ctx.Buffer.LinesBetween(rec.BufferStart, rec.BufferEnd)
+			out.LastLine = rec.BufferEnd
+		}
+		if len(out.Lines) == 0 {
+			out.LastLine = 0
+			for _, l := range rec.Output {
+				out.Lines = append(out.Lines, []byte(l))
+			}
+		}
+		if maxLines > 0 && len(out.Lines) > maxLines {
+			out.Lines = out.Lines[len(out.Lines)-maxLines:]
+		}
+		return out, true
+	}
+	return FocusedOutput{}, false
+}
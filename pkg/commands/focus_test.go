@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+)
+
+// focusTestContext has a failed `make` followed by a passing `ls`.
+func focusTestContext() *Context {
+	buf := buffer.New(100)
+	sess := capture.NewSessionContext()
+	write := func(lines ...string) {
+		for _, l := range lines {
+			buf.Write([]byte(l))
+		}
+	}
+
+	start := buf.Total()
+	write("$ make", "cc -o app main.c", "main.c:3: error: expected ';'")
+	sess.AddCommand(capture.CommandRecord{Command: "make", BufferStart: start})
+	sess.FinishLastCommand(buf.Total(), 2, nil)
+
+	start = buf.Total()
+	write("$ ls", "Makefile main.c")
+	sess.AddCommand(capture.CommandRecord{Command: "ls", BufferStart: start})
+	sess.FinishLastCommand(buf.Total(), 0, nil)
+
+	return NewContext(buf, sess, "/tmp")
+}
+
+func joinLines(lines [][]byte) string {
+	parts := make([]string, len(lines))
+	for i, l := range lines {
+		parts[i] = string(l)
+	}
+	return strings.Join(parts, "|")
+}
+
+func TestFocus_LastFailureSelectsFailedCommandOutput(t *testing.T) {
+	ctx := focusTestContext()
+	out := FocusWtf.Output(ctx, 100)
+
+	if out.Region != config.FocusRegionLastFailure || out.Failed == nil || out.Failed.Command != "make" {
+		t.Fatalf("Output = %+v, want the failed make", out)
+	}
+	if got, want := joinLines(out.Lines), "$ make|cc -o app main.c|main.c:3: error: expected ';'"; got != want {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	if out.LastLine != 3 {
+		t.Errorf("LastLine = %d, want 3", out.LastLine)
+	}
+
+	meta := FocusWtf.metadata(ctx, out)
+	if meta.LastCommand != "make" || meta.ExitCode != 2 || meta.Task == "" {
+		t.Errorf("metadata = %+v, want make with exit code 2 and the /wtf task", meta)
+	}
+}
+
+func TestFocus_LastFailureFallsBackToRecentOutput(t *testing.T) {
+	ctx := NewContext(buffer.New(10), capture.NewSessionContext(), "/tmp")
+	ctx.Buffer.Write([]byte("all good"))
+
+	out := FocusWtf.Output(ctx, 100)
+	if out.Region != config.FocusRegionRecent || out.Failed != nil || joinLines(out.Lines) != "all good" {
+		t.Errorf("Output = %+v, want the recent output", out)
+	}
+}
+
+func TestFocus_VisibleSelectsScreenAndLocatesIt(t *testing.T) {
+	ctx := focusTestContext()
+	ctx.Visible = []string{"cc -o app main.c", "main.c:3: error: expected ';'", "", ""}
+
+	out := FocusExplain.Output(ctx, 100)
+	if out.Region != config.FocusRegionVisible {
+		t.Fatalf("Region = %q, want visible", out.Region)
+	}
+	if got, want := joinLines(out.Lines), "cc -o app main.c|main.c:3: error: expected ';'"; got != want {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	if out.LastLine != 3 {
+		t.Errorf("LastLine = %d, want 3", out.LastLine)
+	}
+
+	// Outside the TUI nothing is on screen: the recent output is used.
+	ctx.Visible = nil
+	if out := FocusExplain.Output(ctx, 2); out.Region != config.FocusRegionRecent || joinLines(out.Lines) != "$ ls|Makefile main.c" {
+		t.Errorf("Output without a screen = %+v, want the last 2 lines", out)
+	}
+}
+
+func TestFocus_WithConfig(t *testing.T) {
+	facts := false
+	cfg := config.FocusConfig{Why: config.FocusCommandConfig{
+		Region:       config.FocusRegionLastFailure,
+		SystemFacts:  &facts,
+		ContextLines: 300,
+		Task:         "Blame the network.",
+	}}
+
+	got := FocusWhy.WithConfig(cfg)
+	if got.Region != config.FocusRegionLastFailure || got.SystemFacts || got.ContextLines != 300 || got.Task != "Blame the network." {
+		t.Errorf("WithConfig = %+v", got)
+	}
+	if got := FocusWtf.WithConfig(cfg); got != FocusWtf {
+		t.Errorf("settings of /why changed /wtf: %+v", got)
+	}
+}
+
+func TestExplainHandler_NamedByFocus(t *testing.T) {
+	d := NewDispatcher()
+	for _, focus := range Focuses {
+		h, ok := d.GetHandler(focus.Command)
+		if !ok {
+			t.Fatalf("%s not registered", focus.Command)
+		}
+		if h.Description() != focus.Description {
+			t.Errorf("%s description = %q, want %q", focus.Command, h.Description(), focus.Description)
+		}
+	}
+	if got := (&ExplainHandler{}).Name(); got != "/explain" {
+		t.Errorf("zero ExplainHandler is %q, want /explain", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,8 +32,12 @@ type ApproverFactory func(out chan<- WtfStreamEvent) Approver
 // per-batch limit; suitable for tests and headless flows).
 type ContinuerFactory func(out chan<- WtfStreamEvent) Continuer
 
-// ExplainHandler handles the /explain command.
+// ExplainHandler handles the analysis commands: /explain, /wtf and /why,
+// told apart by their Focus.
 type ExplainHandler struct {
+	// Focus is what the command looks at. Zero is FocusExplain.
+	Focus Focus
+
 	// ApproverFactory builds the approver for each /explain invocation. Wired
 	// up by the UI layer to surface a popup. Nil ⇒ AutoAllowApprover.
 	ApproverFactory ApproverFactory
@@ -40,23 +45,49 @@ type ExplainHandler struct {
 	// ContinuerFactory builds the continuer for each /explain invocation. Wired
 	// up by the UI layer to surface a popup. Nil ⇒ AutoStopContinuer.
 	ContinuerFactory ContinuerFactory
+
+	// prompts picks the system prompt variant of each answer. Handlers of
+	// one dispatcher share it, so the counts cover every analysis command;
+	// nil uses ownPrompts.
+	prompts    *promptExperiment
+	ownPrompts promptExperiment
+}
+
+func (h *ExplainHandler) Name() string        { return h.focus().Command }
+func (h *ExplainHandler) Description() string { return h.focus().Description }
+
+// CommandFocus returns the focus of the command with the user's settings
+// for it applied.
+func (h *ExplainHandler) CommandFocus(cfg config.FocusConfig) Focus {
+	return h.focus().WithConfig(cfg)
+}
+
+func (h *ExplainHandler) focus() Focus {
+	if h.Focus.Command == "" {
+		return FocusExplain
+	}
+	return h.Focus
 }
 
-func (h *ExplainHandler) Name() string        { return "/explain" }
-func (h *ExplainHandler) Description() string { return "Analyze last output and suggest fixes" }
+func (h *ExplainHandler) experiment() *promptExperiment {
+	if h.prompts != nil {
+		return h.prompts
+	}
+	return &h.ownPrompts
+}
 
 func (h *ExplainHandler) Execute(ctx *Context) *Result {
 	// Get last 100 lines of output for analysis
 	lines := ctx.GetLastNLines(ai.DefaultContextLines)
 	if len(lines) == 0 {
 		return &Result{
-			Title:   "WTF Analysis",
+			Title:   h.focus().Title,
 			Content: "No terminal output to analyze yet.",
 		}
 	}
 
 	return &Result{
-		Title:   "WTF Analysis",
+		Title:   h.focus().Title,
 		Content: "Loading...",
 	}
 }
@@ -65,13 +96,35 @@ func (h *ExplainHandler) Execute(ctx *Context) *Result {
 //
 // Most events carry exactly one populated field. Receivers should check fields
 // in this order: Err, ContinuePrompt, ToolApproval, ToolCallStart,
-// ToolCallFinished, Delta, Done. Unknown future variants must be ignored
-// gracefully (no field set ⇒ keep listening).
+// ToolCallFinished, ToolCallPending, Usage, Notice, Refusal, Reasoning, Delta, Done.
+// Unknown future variants must be ignored gracefully (no field set ⇒ keep
+// listening).
 type WtfStreamEvent struct {
 	Delta string
 	Done  bool
 	Err   error
 
+	// Reasoning is a delta of the model's reasoning. It is not part of the
+	// answer and is never sent back to the model.
+	Reasoning string
+
+	// ToolCallPending is set as soon as the model starts composing a tool
+	// call, before its arguments are complete. Only ID and Name are set; the
+	// call is reported again on ToolCallStart when it is about to run.
+	ToolCallPending *ToolCallInfo
+
+	// Usage is the running token total of this invocation, summed across
+	// every provider call of the agent loop.
+	Usage *ai.Usage
+
+	// Notice is an informational message from the provider or the loop,
+	// e.g. that the answer was cut off at the token limit.
+	Notice string
+
+	// Refusal is set when the provider refused to answer: why, e.g.
+	// "content filter". The refusal text itself came as deltas.
+	Refusal string
+
 	// Tool-call lifecycle events. nil unless the agent loop is reporting on a
 	// tool call this iteration.
 	ToolCallStart    *ToolCallInfo
@@ -119,8 +172,7 @@ func (h *ExplainHandler) StartStreamWithContext(runCtx context.Context, ctx *Con
 	if runCtx == nil {
 		runCtx = context.Background()
 	}
-	lines := ctx.GetLastNLines(ai.DefaultContextLines)
-	if len(lines) == 0 {
+	if len(ctx.GetLastNLines(ctx.contextLines())) == 0 {
 		slog.Info("wtf_stream_skip", "reason", "no_output")
 		return nil, nil
 	}
@@ -129,14 +181,46 @@ func (h *ExplainHandler) StartStreamWithContext(runCtx context.Context, ctx *Con
 	if err != nil {
 		return nil, err
 	}
+	focus := h.CommandFocus(prep.focus)
+	maxLines := prep.contextLines
+	if focus.ContextLines > 0 && ctx.ContextLines <= 0 {
+		maxLines = focus.ContextLines
+	}
+	minLines, autoMax, auto := prep.autoContextBounds(ctx, focus)
+	if auto {
+		maxLines = autoMax
+	}
+	out := focus.Output(ctx, maxLines)
+	var size ai.ContextSize
+	if auto {
+		size = ai.ChooseContextSize(out.Lines, minLines, maxLines)
+		if len(out.Lines) > size.Lines {
+			out.Lines = out.Lines[len(out.Lines)-size.Lines:]
+		}
+	}
+	lines := out.Lines
 
-	meta := buildTerminalMetadata(ctx)
+	meta := focus.metadata(ctx, out)
+	if auto {
+		meta.MaxLines = maxLines
+		meta.ContextSize = size.Describe()
+	}
 	messages, termCtx := ai.BuildWtfMessages(lines, meta)
+	variant := h.experiment().pick(prep.prompts)
+	if variant.SystemPrompt != "" && len(messages) > 0 && messages[0].Role == "system" {
+		messages[0].Content = variant.SystemPrompt
+	}
 
 	toolDefs := prep.registry.Definitions()
 	if len(toolDefs) > 0 && len(messages) > 0 && messages[0].Role == "system" {
 		messages[0].Content = ai.AppendToolInstructions(messages[0].Content, toolDefs)
 	}
+	if len(messages) > 0 && messages[0].Role == "system" {
+		// After the variant, so custom system prompts get the advice too.
+		messages[0].Content = ai.AppendErrorDomainGuidance(messages[0].Content, termCtx.ErrorDomain)
+		messages[0].Content = ai.AppendMemories(messages[0].Content, prep.memories)
+		messages[0].Content = ai.AppendLanguageInstruction(messages[0].Content, prep.language)
+	}
 
 	logger := slog.Default()
 	if logger.Enabled(context.Background(), logging.LevelTrace) {
@@ -155,12 +239,20 @@ func (h *ExplainHandler) StartStreamWithContext(runCtx context.Context, ctx *Con
 		Messages:    messages,
 		Temperature: &prep.temperature,
 		MaxTokens:   &prep.maxTokens,
+		TopP:        prep.topP,
 		Tools:       toolDefs,
 	}
 
 	slog.Info("wtf_stream_start",
+		"command", focus.Command,
+		"region", out.Region,
+		"system_facts", focus.SystemFacts,
 		"model", prep.model,
+		"prompt_variant", variant.Name,
+		"prompt_experiment", prep.prompts.Experiment,
 		"lines", len(lines),
+		"context_class", size.Class,
+		"error_domain", termCtx.ErrorDomain,
 		"cwd", ctx.CurrentDir,
 		"temperature", prep.temperature,
 		"max_tokens", prep.maxTokens,
@@ -191,12 +283,19 @@ func (h *ExplainHandler) StartStreamWithContext(runCtx context.Context, ctx *Con
 			MaxIterations:  prep.maxIterations,
 			PerCallTimeout: time.Duration(prep.timeout) * time.Second,
 			Tag:            "explain",
+			LocalModel:     prep.localModel,
 		}, ch)
 	}()
 
 	return ch, nil
 }
 
+// PromptAnswerCounts returns how many answers each system prompt variant has
+// produced this session, keyed by variant name.
+func (h *ExplainHandler) PromptAnswerCounts() map[string]int {
+	return h.experiment().answerCounts()
+}
+
 func (h *ExplainHandler) resolveApprover(ch chan<- WtfStreamEvent) Approver {
 	if h.ApproverFactory != nil {
 		if a := h.ApproverFactory(ch); a != nil {
@@ -223,8 +322,32 @@ type agentRunPrep struct {
 	model         string
 	temperature   float64
 	maxTokens     int
+	topP          *float64 // Nil leaves top_p to the provider
 	timeout       int
 	maxIterations int
+	language      string
+	prompts       config.PromptsConfig
+	localModel    bool // Provider is served from this machine
+	contextLines  int  // Terminal lines to send: ctx.ContextLines, else ai.ContextLines
+	memories      []ai.Memory
+	focus         config.FocusConfig
+	autoContext   config.AutoContextConfig
+}
+
+// autoContextBounds returns the bounds within which the lines sent are
+// chosen from the output, and false when the amount is fixed: auto_context
+// is off, or an alias or the focus command sets context_lines. A local
+// model's cap still applies.
+func (p *agentRunPrep) autoContextBounds(ctx *Context, focus Focus) (minLines, maxLines int, ok bool) {
+	if !p.autoContext.Enabled || ctx.ContextLines > 0 || focus.ContextLines > 0 {
+		return 0, 0, false
+	}
+	minLines, maxLines = p.autoContext.Bounds()
+	if p.localModel {
+		maxLines = min(maxLines, p.contextLines)
+		minLines = min(minLines, maxLines)
+	}
+	return minLines, maxLines, true
 }
 
 // prepareAgentRun loads config, builds the provider, resolves provider
@@ -241,6 +364,13 @@ func prepareAgentRun(ctx *Context, tag string) (*agentRunPrep, error) {
 		return nil, err
 	}
 
+	if override := strings.TrimSpace(ctx.ProviderOverride); override != "" {
+		if !cfg.Policy.AllowsProvider(override) {
+			return nil, fmt.Errorf("provider %s is not allowed by the organization policy", override)
+		}
+		slog.Info(tag+"_stream_provider_override", "provider", override, "configured", cfg.LLMProvider)
+		cfg.LLMProvider = override
+	}
 	slog.Debug(tag+"_stream_provider_config", "llm_provider", cfg.LLMProvider)
 	provider, err := ai.GetProviderFromConfig(cfg)
 	if err != nil {
@@ -249,9 +379,23 @@ func prepareAgentRun(ctx *Context, tag string) (*agentRunPrep, error) {
 	}
 
 	model, temperature, maxTokens, timeout := getProviderSettings(cfg)
-	registry := buildToolRegistry(cfg, ctx.CurrentDir)
+	if override := strings.TrimSpace(ctx.ModelOverride); override != "" {
+		slog.Info(tag+"_stream_model_override", "model", override, "configured", model)
+		model = override
+	}
+	contextLines := ai.ContextLines(cfg)
+	if ctx.ContextLines > 0 {
+		contextLines = ctx.ContextLines
+	}
+	registry := tools.NewRegistry()
+	if ctx.Trust.SharesFiles() {
+		registry = buildToolRegistry(cfg, ctx.CurrentDir)
+	} else {
+		slog.Info(tag+"_stream_tools_withheld", "trust", string(ctx.Trust))
+	}
+	memories := shareMemories(ctx, registry)
 
-	return &agentRunPrep{
+	prep := &agentRunPrep{
 		provider:      provider,
 		registry:      registry,
 		model:         model,
@@ -259,7 +403,48 @@ func prepareAgentRun(ctx *Context, tag string) (*agentRunPrep, error) {
 		maxTokens:     maxTokens,
 		timeout:       timeout,
 		maxIterations: cfg.Agent.MaxIterations,
-	}, nil
+		language:      cfg.ResponseLanguage,
+		prompts:       cfg.Prompts,
+		localModel:    ai.UsesLocalModel(cfg),
+		contextLines:  contextLines,
+		memories:      memories,
+		focus:         cfg.Focus,
+		autoContext:   cfg.AutoContext,
+	}
+	prep.applyParams(ctx.Params, tag)
+	return prep, nil
+}
+
+// applyParams puts the set fields of params over the configured sampling
+// settings.
+func (p *agentRunPrep) applyParams(params ai.SamplingParams, tag string) {
+	if params.IsZero() {
+		return
+	}
+	if params.Temperature != nil {
+		p.temperature = *params.Temperature
+	}
+	if params.MaxTokens != nil {
+		p.maxTokens = *params.MaxTokens
+	}
+	p.topP = params.TopP
+	slog.Info(tag+"_stream_params_override",
+		"temperature", p.temperature,
+		"max_tokens", p.maxTokens,
+		"top_p_set", p.topP != nil,
+	)
+}
+
+// shareMemories returns the remembered facts to put in the system prompt and
+// offers the remember tool, unless the workspace shares nothing.
+func shareMemories(ctx *Context, registry *tools.Registry) []ai.Memory {
+	if !ctx.Trust.SharesSummary() {
+		return nil
+	}
+	if ctx.MemoriesPath != "" && ctx.CurrentDir != "" {
+		registry.Register(tools.NewRemember(ctx.MemoriesPath, ai.ProjectRoot(ctx.CurrentDir)))
+	}
+	return ctx.Memories
 }
 
 // buildToolRegistry constructs the per-invocation tool registry from config.
@@ -346,10 +531,20 @@ func getProviderSettings(cfg config.Config) (model string, temperature float64,
 	return
 }
 
+// maxPromptREPLStatements is how many REPL statements go into the metadata.
+const maxPromptREPLStatements = 10
+
 func buildTerminalMetadata(ctx *Context) ai.TerminalMetadata {
 	meta := ai.TerminalMetadata{
 		WorkingDir: ctx.CurrentDir,
 		ExitCode:   -1,
+		Env:        ctx.Env,
+		Jobs:       ctx.Jobs,
+		Pinned:     ctx.Pinned,
+		Workspace:  ctx.Workspace,
+	}
+	if ctx.Buffer != nil {
+		meta.LastLine = ctx.Buffer.Total()
 	}
 	if ctx.Session != nil {
 		if meta.WorkingDir == "" {
@@ -363,6 +558,31 @@ func buildTerminalMetadata(ctx *Context) ai.TerminalMetadata {
 				meta.WorkingDir = last[0].WorkingDir
 			}
 		}
+		if ctx.REPL != nil {
+			meta.REPL = ai.REPLSession{
+				Label:      ctx.REPL.Label,
+				Statements: ctx.Session.LastREPLStatements(ctx.REPL.Name, maxPromptREPLStatements),
+			}
+		}
+	}
+	return withholdUntrusted(meta, ctx.Trust, ctx.VirtualEnv)
+}
+
+// withholdUntrusted fills in what the workspace trust allows to be read from
+// the project and drops the rest.
+func withholdUntrusted(meta ai.TerminalMetadata, trust ai.WorkspaceTrust, virtualEnv string) ai.TerminalMetadata {
+	if !trust.SharesSummary() {
+		meta.WorkingDir = ""
+		meta.Env = nil
+		meta.Workspace = ai.WorkspaceIndex{}
+		return meta
+	}
+	meta.Toolchain = ai.DetectToolchain(meta.WorkingDir, virtualEnv)
+	meta.Project = ai.DetectProject(meta.WorkingDir)
+	if !trust.SharesFiles() {
+		meta.Project.Tasks = nil
+		meta.Env = nil
+		meta.Workspace = ai.WorkspaceIndex{}
 	}
 	return meta
 }
@@ -516,6 +736,369 @@ func (h *SettingsHandler) Execute(ctx *Context) *Result {
 	}
 }
 
+// LanguageHandler handles the /language command
+type LanguageHandler struct{}
+
+func (h *LanguageHandler) Name() string { return "/language" }
+func (h *LanguageHandler) Description() string {
+	return "Choose the language AI answers are written in"
+}
+
+func (h *LanguageHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Response Language",
+		Action: ResultActionOpenLanguagePicker,
+	}
+}
+
+// ExportScriptHandler handles the /export command
+type ExportScriptHandler struct{}
+
+func (h *ExportScriptHandler) Name() string { return "/export" }
+func (h *ExportScriptHandler) Description() string {
+	return "Save the chat's suggested commands as a shell script"
+}
+
+func (h *ExportScriptHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Export Script",
+		Action: ResultActionExportScript,
+	}
+}
+
+// EnvHandler handles the /env command
+type EnvHandler struct{}
+
+func (h *EnvHandler) Name() string { return "/env" }
+func (h *EnvHandler) Description() string {
+	return "Choose environment variables to share with AI"
+}
+
+func (h *EnvHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Environment",
+		Action: ResultActionOpenEnvPicker,
+	}
+}
+
+// WatchHandler handles the /watch command. The command to watch is taken
+// from ctx.Args; the UI owns the re-run loop.
+type WatchHandler struct{}
+
+func (h *WatchHandler) Name() string { return "/watch" }
+func (h *WatchHandler) Description() string {
+	return "Re-run a command and explain when it changes or fails"
+}
+
+func (h *WatchHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Watch",
+		Action: ResultActionWatch,
+	}
+}
+
+// PromptsHandler handles the /prompts command
+type PromptsHandler struct{}
+
+func (h *PromptsHandler) Name() string { return "/prompts" }
+func (h *PromptsHandler) Description() string {
+	return "Review system prompt variants and pick the default"
+}
+
+func (h *PromptsHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "System Prompts",
+		Action: ResultActionOpenPrompts,
+	}
+}
+
+// UnpinHandler handles the /unpin command
+type UnpinHandler struct{}
+
+func (h *UnpinHandler) Name() string { return "/unpin" }
+func (h *UnpinHandler) Description() string {
+	return "Remove output pinned to every AI request"
+}
+
+func (h *UnpinHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Unpin",
+		Action: ResultActionOpenUnpinPicker,
+	}
+}
+
+// TasksHandler handles the /tasks command
+type TasksHandler struct{}
+
+func (h *TasksHandler) Name() string { return "/tasks" }
+func (h *TasksHandler) Description() string {
+	return "Pick a build, test or run command of this project"
+}
+
+func (h *TasksHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Tasks",
+		Action: ResultActionOpenTasksPicker,
+	}
+}
+
+// TrustHandler handles the /trust command
+type TrustHandler struct{}
+
+func (h *TrustHandler) Name() string { return "/trust" }
+func (h *TrustHandler) Description() string {
+	return "Choose how much of this workspace is shared with AI"
+}
+
+func (h *TrustHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Workspace Trust",
+		Action: ResultActionOpenTrustPicker,
+	}
+}
+
+// LogsHandler handles the /logs command
+type LogsHandler struct{}
+
+func (h *LogsHandler) Name() string        { return "/logs" }
+func (h *LogsHandler) Description() string { return "Follow the end of the wtf_cli log" }
+
+func (h *LogsHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Logs",
+		Action: ResultActionOpenLogs,
+	}
+}
+
+// RememberHandler handles the /remember command. The fact is taken from
+// ctx.Args; the UI stores it for the current project.
+type RememberHandler struct{}
+
+func (h *RememberHandler) Name() string { return "/remember" }
+func (h *RememberHandler) Description() string {
+	return "Remember a fact about this project for future AI requests"
+}
+
+func (h *RememberHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Remember",
+		Action: ResultActionRemember,
+	}
+}
+
+// MemoriesHandler handles the /memories command
+type MemoriesHandler struct{}
+
+func (h *MemoriesHandler) Name() string { return "/memories" }
+func (h *MemoriesHandler) Description() string {
+	return "Review and forget facts remembered for this project"
+}
+
+func (h *MemoriesHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Memories",
+		Action: ResultActionOpenMemories,
+	}
+}
+
+// SnippetsHandler handles the /snippets command
+type SnippetsHandler struct{}
+
+func (h *SnippetsHandler) Name() string { return "/snippets" }
+func (h *SnippetsHandler) Description() string {
+	return "Use, rename, delete or pin scripts saved in ~/.wtf_cli/scripts"
+}
+
+func (h *SnippetsHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Snippets",
+		Action: ResultActionOpenSnippets,
+	}
+}
+
+// ProviderHandler handles the /provider command
+type ProviderHandler struct{}
+
+func (h *ProviderHandler) Name() string { return "/provider" }
+func (h *ProviderHandler) Description() string {
+	return "Switch the AI provider for this session"
+}
+
+func (h *ProviderHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Provider",
+		Action: ResultActionOpenProviderPicker,
+	}
+}
+
+// MarkHandler handles the /mark command. The label is taken from ctx.Args;
+// the UI bookmarks the current end of the scrollback under it.
+type MarkHandler struct{}
+
+func (h *MarkHandler) Name() string { return "/mark" }
+func (h *MarkHandler) Description() string {
+	return "Bookmark the current scrollback position"
+}
+
+func (h *MarkHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Mark",
+		Action: ResultActionMark,
+	}
+}
+
+// MarksHandler handles the /marks command
+type MarksHandler struct{}
+
+func (h *MarksHandler) Name() string { return "/marks" }
+func (h *MarksHandler) Description() string {
+	return "Jump to a bookmark in the scrollback"
+}
+
+func (h *MarksHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Marks",
+		Action: ResultActionOpenMarks,
+	}
+}
+
+// ReplayHandler handles the /replay command. The UI shows the session's
+// commands on a timeline.
+type ReplayHandler struct{}
+
+func (h *ReplayHandler) Name() string { return "/replay" }
+func (h *ReplayHandler) Description() string {
+	return "Replay this session's commands and ask AI about any point"
+}
+
+func (h *ReplayHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Replay",
+		Action: ResultActionOpenReplay,
+	}
+}
+
+// PrivacyHandler handles the /privacy command. ctx.Args is "on", "off" or
+// empty to toggle; the UI switches capture.
+type PrivacyHandler struct{}
+
+func (h *PrivacyHandler) Name() string { return "/privacy" }
+func (h *PrivacyHandler) Description() string {
+	return "Stop capturing terminal output and commands"
+}
+
+func (h *PrivacyHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Privacy",
+		Action: ResultActionPrivacy,
+	}
+}
+
+// ReadOnlyHandler handles the /readonly command. ctx.Args is "on", "off"
+// or empty to toggle; the UI keeps input from the shell.
+type ReadOnlyHandler struct{}
+
+func (h *ReadOnlyHandler) Name() string { return "/readonly" }
+func (h *ReadOnlyHandler) Description() string {
+	return "Stop keys, pastes and commands from reaching the shell"
+}
+
+func (h *ReadOnlyHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Read-only",
+		Action: ResultActionReadOnly,
+	}
+}
+
+// TriggerHandler handles the /trigger command. The subcommand is taken
+// from ctx.Args; the UI evaluates triggers on the terminal output.
+type TriggerHandler struct{}
+
+func (h *TriggerHandler) Name() string { return "/trigger" }
+func (h *TriggerHandler) Description() string {
+	return "Act when terminal output matches a pattern"
+}
+
+func (h *TriggerHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Triggers",
+		Action: ResultActionTrigger,
+	}
+}
+
+// PeekHandler handles the /peek command
+type PeekHandler struct{}
+
+func (h *PeekHandler) Name() string { return "/peek" }
+func (h *PeekHandler) Description() string {
+	return "Show the raw bytes a terminal line was captured from"
+}
+
+func (h *PeekHandler) Execute(ctx *Context) *Result {
+	return &Result{
+		Title:  "Peek",
+		Action: ResultActionOpenPeek,
+	}
+}
+
+// UsageHandler handles the /usage command
+type UsageHandler struct{}
+
+func (h *UsageHandler) Name() string { return "/usage" }
+func (h *UsageHandler) Description() string {
+	return "Show this month's token usage and budgets per provider"
+}
+
+func (h *UsageHandler) Execute(ctx *Context) *Result {
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		slog.Warn("usage_config_load_error", "error", err)
+		cfg = config.Default()
+	}
+	now := time.Now()
+	usage := ai.LoadUsage(ai.DefaultUsagePath(), ai.UsageMonth(now))
+	return &Result{
+		Title:   "Usage",
+		Content: formatUsage(now.Format("January 2006"), usage, cfg.Budgets),
+	}
+}
+
+// formatUsage renders one row per provider that used tokens or has a budget.
+func formatUsage(month string, usage map[string]ai.ProviderUsage, budgets config.BudgetsConfig) string {
+	providers := make([]string, 0, len(usage)+len(budgets.MonthlyTokens))
+	for p := range usage {
+		providers = append(providers, p)
+	}
+	for p := range budgets.MonthlyTokens {
+		if _, ok := usage[p]; !ok {
+			providers = append(providers, p)
+		}
+	}
+	slices.Sort(providers)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Token usage for %s\n\n", month)
+	if len(providers) == 0 {
+		sb.WriteString("No usage recorded this month.\n")
+	} else {
+		fmt.Fprintf(&sb, "%-14s %8s %8s %8s %8s %6s\n", "Provider", "Input", "Output", "Budget", "Left", "Used")
+		for _, p := range providers {
+			u := usage[p]
+			budget, left, used := "-", "-", "-"
+			if limit := budgets.MonthlyLimit(p); limit > 0 {
+				budget = ai.FormatTokens(limit)
+				left = ai.FormatTokens(max(limit-u.Total(), 0))
+				used = fmt.Sprintf("%d%%", u.Total()*100/limit)
+			}
+			fmt.Fprintf(&sb, "%-14s %8s %8s %8s %8s %6s\n", p,
+				ai.FormatTokens(u.InputTokens), ai.FormatTokens(u.OutputTokens), budget, left, used)
+		}
+	}
+	sb.WriteString("\nToken counts are as reported by each provider. Set budgets under\n")
+	sb.WriteString("\"budgets\" in ~/.wtf_cli/config.json.\n\nPress Esc to close this panel.")
+	return sb.String()
+}
+
 // HelpHandler handles the /help command
 type HelpHandler struct{}
 
@@ -531,16 +1114,47 @@ Version: %s
 
 Available Commands:
   /chat     - Toggle chat sidebar
-  /explain  - Analyze last output and suggest fixes
+  /explain  - Explain the output on screen
+  /wtf      - Diagnose the last failed command
+  /why      - Find the root cause, with system facts
   /history  - Show command history
+  /language - Choose the language AI answers are written in
+  /provider - Switch the AI provider for this session; s also saves it
+  /export   - Save the chat's suggested commands as a shell script
+  /env      - Choose environment variables to share with AI
+  /watch    - Re-run a command; Alt+W explains a change: /watch [-n secs] <cmd>, alone stops
+  /prompts  - Review system prompt variants and pick the default
+  /unpin    - Remove output pinned to every AI request
+  /tasks    - Pick a build, test or run command of this project
+  /trust    - Choose how much of this workspace is shared with AI
+  /logs     - Follow the end of the wtf_cli log
+  /remember - Remember a fact about this project for AI requests: /remember <fact>
+  /memories - Review and forget remembered facts
+  /snippets - Use saved scripts; r renames, d deletes, p pins
+  /usage    - Show this month's token usage and budgets
+  /doctor   - Check the config and provider client certificates
+  /trigger  - Act on matching output: /trigger add <regex> <notify|highlight|status|ask|run <cmd>>, alone lists
+  /peek     - Show the raw bytes a terminal line was captured from
+  /mark     - Bookmark the current scrollback position: /mark <label>
+  /marks    - Jump to a bookmark in the scrollback
+  /replay   - Replay this session's commands; a asks AI about the point shown
+  /privacy  - Stop capturing output and commands, and sharing them with AI: /privacy [on|off]
+  /readonly - Keep keys, pastes and commands from the shell, for screen sharing: /readonly [on|off]
+  /calc     - Calculate, or convert sizes and durations: /calc <expr> (e.g. 1.5 GiB in MB)
+  /ts       - Convert between Unix timestamps and dates: /ts [epoch|date]
+  /b64      - Base64-encode, or decode with -d: /b64 [-d] [text]
+  /jsonpp   - Pretty-print JSON: /jsonpp [json]
   /help     - Show this help
 
 Shortcuts:
   Ctrl+T     - Toggle chat sidebar
   Shift+Tab  - Switch focus to chat panel
-  Ctrl+R     - Search command history
+  Ctrl+R     - Search command history (Ctrl+P pins a command's output)
+  Alt+P      - Pin the last terminal selection to every AI request
+  Ctrl+W v   - Split the terminal (Ctrl+W w switches, Ctrl+W q closes)
   Ctrl+C    - Cancel current command
   Ctrl+D    - Exit terminal (press twice)
+  Ctrl+Z    - Suspend wtf_cli (at the shell prompt)
   /         - Open command palette (at empty prompt)
   Esc       - Close command palette or result
 
@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"strings"
 	"testing"
 
+	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/ai/tools"
 	"wtf_cli/pkg/config"
 )
@@ -129,3 +131,38 @@ func TestBuildToolRegistry_OutOfWorkdirAccessDenyDisablesEscapesOnBothTools(t *t
 		t.Error("expected list_directory.AllowEscapes=false under the deny policy")
 	}
 }
+
+func TestFormatUsage(t *testing.T) {
+	usage := map[string]ai.ProviderUsage{
+		"openai": {InputTokens: 1500, OutputTokens: 500},
+	}
+	budgets := config.BudgetsConfig{MonthlyTokens: map[string]int{"openai": 4000, "anthropic": 1000}}
+
+	got := formatUsage("October 2026", usage, budgets)
+	for _, want := range []string{"October 2026", "openai", "1.5k", "4.0k", "2.0k", "50%", "anthropic"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatUsage() missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := formatUsage("October 2026", nil, config.BudgetsConfig{}); !strings.Contains(got, "No usage recorded") {
+		t.Errorf("formatUsage() with no usage = %q", got)
+	}
+}
+
+func TestAgentRunPrep_ApplyParams(t *testing.T) {
+	prep := &agentRunPrep{temperature: 0.7, maxTokens: 2000}
+	prep.applyParams(ai.SamplingParams{}, "chat")
+	if prep.temperature != 0.7 || prep.maxTokens != 2000 || prep.topP != nil {
+		t.Fatalf("expected no params to keep the config, got %+v", prep)
+	}
+
+	temperature, topP := 0.2, 0.9
+	prep.applyParams(ai.SamplingParams{Temperature: &temperature, TopP: &topP}, "chat")
+	if prep.temperature != 0.2 || prep.maxTokens != 2000 {
+		t.Fatalf("expected temperature 0.2 and the configured max tokens, got %+v", prep)
+	}
+	if prep.topP == nil || *prep.topP != 0.9 {
+		t.Fatalf("expected top_p 0.9, got %v", prep.topP)
+	}
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+)
+
+// OneShotRequest describes a single /explain run outside the TUI, e.g. from
+// the shell widget installed by `wtf_cli install-shell-integration`.
+type OneShotRequest struct {
+	Command    string    // Last command line, as reported by the shell
+	ExitCode   int       // Exit status of Command; -1 when unknown
+	WorkingDir string    // Directory the command ran in
+	Output     io.Reader // Captured output of Command; may be nil
+}
+
+// NewOneShotContext builds a command context from a one-shot request. When
+// no output was captured, the command line itself is used as the context so
+// the model still sees what was run.
+func NewOneShotContext(req OneShotRequest) (*Context, error) {
+	buf := buffer.New(ai.DefaultContextLines)
+	lines := 0
+	if req.Output != nil {
+		scanner := bufio.NewScanner(req.Output)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			buf.Write(scanner.Bytes())
+			lines++
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read output: %w", err)
+		}
+	}
+	command := strings.TrimSpace(req.Command)
+	if lines == 0 && command != "" {
+		buf.Write([]byte("$ " + command))
+	}
+
+	session := capture.NewSessionContext()
+	session.SetCurrentDir(req.WorkingDir)
+	if command != "" {
+		now := time.Now()
+		session.AddCommand(capture.CommandRecord{
+			Command:    command,
+			ExitCode:   req.ExitCode,
+			StartTime:  now,
+			EndTime:    now,
+			WorkingDir: req.WorkingDir,
+		})
+	}
+
+	ctx := NewContext(buf, session, req.WorkingDir)
+	ctx.LastExitCode = req.ExitCode
+	ctx.VirtualEnv = os.Getenv("VIRTUAL_ENV") // Run from the user's shell
+	ctx.Trust = ai.LookupWorkspaceTrust(ai.DefaultWorkspaceTrustPath(), req.WorkingDir)
+	ctx.Memories = ai.LoadMemories(ai.DefaultMemoriesPath(), ai.ProjectRoot(req.WorkingDir))
+	return ctx, nil
+}
+
+// RunOneShot streams an /explain answer for req to out. Tool calls run with
+// the headless defaults (auto-allow inside the working directory, stop at the
+// iteration limit). Command markers are removed so the text reads cleanly in
+// a plain terminal.
+func RunOneShot(runCtx context.Context, req OneShotRequest, out io.Writer) error {
+	ctx, err := NewOneShotContext(req)
+	if err != nil {
+		return err
+	}
+
+	handler := &ExplainHandler{}
+	stream, err := handler.StartStreamWithContext(runCtx, ctx)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return fmt.Errorf("nothing to explain")
+	}
+
+	slog.Info("oneshot_start", "command", req.Command, "exit_code", req.ExitCode)
+	w := &cmdMarkerStripper{out: out}
+	for ev := range stream {
+		switch {
+		case ev.Err != nil:
+			return ev.Err
+		case ev.ToolCallStart != nil:
+			slog.Info("oneshot_tool_call", "tool", ev.ToolCallStart.Name)
+		case ev.Delta != "":
+			if err := w.WriteString(ev.Delta); err != nil {
+				return err
+			}
+		case ev.Done:
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			_, err := io.WriteString(out, "\n")
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// cmdMarkerStripper removes <cmd> and </cmd> markers from streamed text. A
+// trailing partial marker is held back until the next chunk completes it.
+type cmdMarkerStripper struct {
+	out     io.Writer
+	pending string
+}
+
+func (s *cmdMarkerStripper) WriteString(chunk string) error {
+	text := strings.NewReplacer("<cmd>", "", "</cmd>", "").Replace(s.pending + chunk)
+	s.pending = ""
+	if i := strings.LastIndex(text, "<"); i >= 0 && isMarkerPrefix(text[i:]) {
+		s.pending = text[i:]
+		text = text[:i]
+	}
+	_, err := io.WriteString(s.out, text)
+	return err
+}
+
+func (s *cmdMarkerStripper) Flush() error {
+	if s.pending == "" {
+		return nil
+	}
+	_, err := io.WriteString(s.out, s.pending)
+	s.pending = ""
+	return err
+}
+
+func isMarkerPrefix(s string) bool {
+	return strings.HasPrefix("<cmd>", s) || strings.HasPrefix("</cmd>", s)
+}
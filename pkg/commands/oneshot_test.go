@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewOneShotContext_UsesOutputAndCommand(t *testing.T) {
+	ctx, err := NewOneShotContext(OneShotRequest{
+		Command:    "  make test ",
+		ExitCode:   2,
+		WorkingDir: "/src",
+		Output:     strings.NewReader("go: build failed\nFAIL\n"),
+	})
+	if err != nil {
+		t.Fatalf("NewOneShotContext() error: %v", err)
+	}
+
+	lines := ctx.GetLastNLines(10)
+	if len(lines) != 2 || string(lines[1]) != "FAIL" {
+		t.Fatalf("unexpected buffer lines: %q", lines)
+	}
+	meta := buildTerminalMetadata(ctx)
+	if meta.LastCommand != "make test" || meta.ExitCode != 2 || meta.WorkingDir != "/src" {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+}
+
+func TestNewOneShotContext_FallsBackToCommandLine(t *testing.T) {
+	ctx, err := NewOneShotContext(OneShotRequest{Command: "git push", ExitCode: 1})
+	if err != nil {
+		t.Fatalf("NewOneShotContext() error: %v", err)
+	}
+	lines := ctx.GetLastNLines(10)
+	if len(lines) != 1 || string(lines[0]) != "$ git push" {
+		t.Fatalf("expected command line as context, got %q", lines)
+	}
+}
+
+func TestCmdMarkerStripper_HandlesSplitMarkers(t *testing.T) {
+	var out strings.Builder
+	w := &cmdMarkerStripper{out: &out}
+	for _, chunk := range []string{"Run <c", "md>ls -la</", "cmd> now, a<b"} {
+		if err := w.WriteString(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "Run ls -la now, a<b" {
+		t.Errorf("got %q", got)
+	}
+}
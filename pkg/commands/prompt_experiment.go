@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"math/rand/v2"
+	"strings"
+	"sync"
+
+	"wtf_cli/pkg/config"
+)
+
+// promptExperiment picks the /explain system prompt variant. It lives on the
+// handler, so rotation, the random pick and the answer counts last for the
+// session.
+type promptExperiment struct {
+	mu     sync.Mutex
+	next   int            // Rotation position for PromptExperimentAlternate
+	sticky string         // Session pick for PromptExperimentRandom
+	counts map[string]int // Answers started per variant
+
+	// intn picks the random variant. Injectable for tests.
+	intn func(n int) int
+}
+
+// PromptVariants returns the variants /explain can use: the built-in prompt
+// (with an empty SystemPrompt) followed by the configured ones.
+func PromptVariants(cfg config.PromptsConfig) []config.PromptVariant {
+	variants := []config.PromptVariant{{Name: config.PromptVariantBuiltin}}
+	for _, v := range cfg.Variants {
+		v.Name = strings.TrimSpace(v.Name)
+		variants = append(variants, v)
+	}
+	return variants
+}
+
+// DefaultPromptVariant returns the name of the configured default variant,
+// falling back to the built-in prompt when it is unset or unknown.
+func DefaultPromptVariant(cfg config.PromptsConfig) string {
+	name := strings.TrimSpace(cfg.Default)
+	for _, v := range PromptVariants(cfg) {
+		if v.Name == name {
+			return name
+		}
+	}
+	return config.PromptVariantBuiltin
+}
+
+// pick returns the variant for the next answer and counts it.
+func (e *promptExperiment) pick(cfg config.PromptsConfig) config.PromptVariant {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	variants := PromptVariants(cfg)
+	chosen := variants[0]
+	switch strings.TrimSpace(cfg.Experiment) {
+	case config.PromptExperimentAlternate:
+		chosen = variants[e.next%len(variants)]
+		e.next++
+	case config.PromptExperimentRandom:
+		if v, ok := findPromptVariant(variants, e.sticky); ok {
+			chosen = v
+			break
+		}
+		intn := e.intn
+		if intn == nil {
+			intn = rand.IntN
+		}
+		chosen = variants[intn(len(variants))]
+		e.sticky = chosen.Name
+	default:
+		chosen, _ = findPromptVariant(variants, DefaultPromptVariant(cfg))
+	}
+
+	if e.counts == nil {
+		e.counts = make(map[string]int)
+	}
+	e.counts[chosen.Name]++
+	return chosen
+}
+
+// answerCounts returns a copy of the answers started per variant.
+func (e *promptExperiment) answerCounts() map[string]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	counts := make(map[string]int, len(e.counts))
+	for name, n := range e.counts {
+		counts[name] = n
+	}
+	return counts
+}
+
+func findPromptVariant(variants []config.PromptVariant, name string) (config.PromptVariant, bool) {
+	for _, v := range variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return config.PromptVariant{}, false
+}
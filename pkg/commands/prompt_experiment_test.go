@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"wtf_cli/pkg/config"
+)
+
+func testPromptsConfig(experiment string) config.PromptsConfig {
+	return config.PromptsConfig{
+		Default:    "terse",
+		Experiment: experiment,
+		Variants: []config.PromptVariant{
+			{Name: "terse", SystemPrompt: "Answer in one line."},
+			{Name: "teacher", SystemPrompt: "Explain the cause step by step."},
+		},
+	}
+}
+
+func pickNames(e *promptExperiment, cfg config.PromptsConfig, n int) []string {
+	var names []string
+	for range n {
+		names = append(names, e.pick(cfg).Name)
+	}
+	return names
+}
+
+func TestPromptExperiment_OffUsesDefault(t *testing.T) {
+	var e promptExperiment
+	got := e.pick(testPromptsConfig(""))
+	if got.Name != "terse" || got.SystemPrompt != "Answer in one line." {
+		t.Fatalf("pick() = %+v, want the terse variant", got)
+	}
+
+	cfg := testPromptsConfig(config.PromptExperimentOff)
+	cfg.Default = "missing"
+	if got := e.pick(cfg); got.Name != config.PromptVariantBuiltin || got.SystemPrompt != "" {
+		t.Fatalf("pick() = %+v, want the built-in prompt for an unknown default", got)
+	}
+}
+
+func TestPromptExperiment_AlternateRotates(t *testing.T) {
+	var e promptExperiment
+	got := pickNames(&e, testPromptsConfig(config.PromptExperimentAlternate), 4)
+	want := []string{config.PromptVariantBuiltin, "terse", "teacher", config.PromptVariantBuiltin}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("picks = %v, want %v", got, want)
+	}
+	counts := e.answerCounts()
+	if counts[config.PromptVariantBuiltin] != 2 || counts["terse"] != 1 || counts["teacher"] != 1 {
+		t.Fatalf("answerCounts() = %v", counts)
+	}
+}
+
+func TestPromptExperiment_RandomIsStickyPerSession(t *testing.T) {
+	calls := 0
+	e := promptExperiment{intn: func(n int) int {
+		calls++
+		return 2
+	}}
+	got := pickNames(&e, testPromptsConfig(config.PromptExperimentRandom), 3)
+	want := []string{"teacher", "teacher", "teacher"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("picks = %v, want %v", got, want)
+	}
+	if calls != 1 {
+		t.Fatalf("expected one random pick per session, got %d", calls)
+	}
+
+	// A pick removed from the config is replaced.
+	cfg := testPromptsConfig(config.PromptExperimentRandom)
+	cfg.Variants = cfg.Variants[:1]
+	e.intn = func(int) int { return 1 }
+	if got := e.pick(cfg).Name; got != "terse" {
+		t.Fatalf("pick() = %q, want terse after the sticky variant was removed", got)
+	}
+}
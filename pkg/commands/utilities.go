@@ -0,0 +1,602 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The offline utilities answer trivial conversions locally, without a model
+// call. Each takes its input from ctx.Args, or from the last terminal
+// selection (which is also what the clipboard holds) when no argument is
+// given, and copies its answer to the clipboard.
+
+const utilityFooter = "\n\nCopied to the clipboard. Press Esc to close this panel."
+
+// utilityInput returns the text a utility works on.
+func utilityInput(ctx *Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if args := strings.TrimSpace(ctx.Args); args != "" {
+		return args
+	}
+	return strings.TrimSpace(ctx.Selection)
+}
+
+// utilityResult shows answer under the input it was computed from and
+// copies it.
+func utilityResult(title, input, answer string) *Result {
+	return &Result{
+		Title:   title,
+		Content: input + "\n\n" + answer + utilityFooter,
+		Copy:    answer,
+	}
+}
+
+func utilityError(title, usage string, err error) *Result {
+	content := usage
+	if err != nil {
+		content = "Error: " + err.Error() + "\n\n" + usage
+	}
+	return &Result{Title: title, Content: content + "\n\nPress Esc to close this panel."}
+}
+
+// CalcHandler handles the /calc command
+type CalcHandler struct{}
+
+func (h *CalcHandler) Name() string { return "/calc" }
+func (h *CalcHandler) Description() string {
+	return "Calculate, or convert sizes and durations, offline"
+}
+
+const calcUsage = `Usage: /calc <expression>
+  /calc 2^10 * 3          + - * / % ^, parentheses, 0x and 0b numbers
+  /calc sqrt(2) * pi      sqrt abs round floor ceil ln log2 log10 sin cos tan min max
+  /calc 1.5 GiB in MB     sizes: B KB MB GB TB PB KiB MiB GiB TiB PiB
+  /calc 90 min to h       durations: ns us ms s min h d w`
+
+func (h *CalcHandler) Execute(ctx *Context) *Result {
+	input := utilityInput(ctx)
+	if input == "" {
+		return utilityError("Calc", calcUsage, nil)
+	}
+	answer, err := calculate(input)
+	if err != nil {
+		return utilityError("Calc", calcUsage, err)
+	}
+	return utilityResult("Calc", input, answer)
+}
+
+// conversionPattern matches "<expression> <unit> to|in <unit>".
+var conversionPattern = regexp.MustCompile(`^(.+?)\s*([A-Za-z]+)\s+(?:to|in)\s+([A-Za-z]+)$`)
+
+// calculate evaluates an expression or a unit conversion.
+func calculate(input string) (string, error) {
+	if m := conversionPattern.FindStringSubmatch(input); m != nil {
+		from, okFrom := lookupUnit(m[2])
+		to, okTo := lookupUnit(m[3])
+		if okFrom && okTo {
+			if from.kind != to.kind {
+				return "", fmt.Errorf("cannot convert %s to %s", m[2], m[3])
+			}
+			value, err := evalExpression(m[1])
+			if err != nil {
+				return "", err
+			}
+			return formatNumber(value*from.factor/to.factor) + " " + to.name, nil
+		}
+	}
+	value, err := evalExpression(input)
+	if err != nil {
+		return "", err
+	}
+	return formatNumber(value), nil
+}
+
+type unit struct {
+	name   string
+	kind   string
+	factor float64 // In bytes or seconds
+}
+
+var units = func() map[string]unit {
+	m := map[string]unit{}
+	add := func(kind string, factor float64, names ...string) {
+		for _, n := range names {
+			m[strings.ToLower(n)] = unit{name: names[0], kind: kind, factor: factor}
+		}
+	}
+	add("size", 1, "B", "bytes", "byte")
+	for i, prefix := range []string{"K", "M", "G", "T", "P"} {
+		add("size", math.Pow(1000, float64(i+1)), prefix+"B")
+		add("size", math.Pow(1024, float64(i+1)), prefix+"iB")
+	}
+	add("time", 1e-9, "ns")
+	add("time", 1e-6, "us", "µs")
+	add("time", 1e-3, "ms")
+	add("time", 1, "s", "sec", "secs", "second", "seconds")
+	add("time", 60, "min", "mins", "minute", "minutes")
+	add("time", 3600, "h", "hr", "hrs", "hour", "hours")
+	add("time", 86400, "d", "day", "days")
+	add("time", 7*86400, "w", "week", "weeks")
+	return m
+}()
+
+func lookupUnit(name string) (unit, bool) {
+	u, ok := units[strings.ToLower(name)]
+	return u, ok
+}
+
+// formatNumber prints integers in full, with their hex form, and other
+// values with up to 12 significant digits.
+func formatNumber(v float64) string {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+		s := strconv.FormatInt(int64(v), 10)
+		if math.Abs(v) >= 16 {
+			s += fmt.Sprintf(" (0x%x)", int64(v))
+		}
+		return s
+	}
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
+
+// evalExpression evaluates an arithmetic expression.
+func evalExpression(expr string) (float64, error) {
+	p := &exprParser{src: expr}
+	p.next()
+	v, err := p.parseSum()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok != "" {
+		return 0, fmt.Errorf("unexpected %q", p.tok)
+	}
+	return v, nil
+}
+
+// exprParser is a recursive descent parser over the tokens of src.
+type exprParser struct {
+	src string
+	pos int
+	tok string // Current token; "" at the end
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+	start := p.pos
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	switch {
+	case unicode.IsDigit(r) || r == '.':
+		for p.pos < len(p.src) {
+			c := p.src[p.pos]
+			isExp := (c == '+' || c == '-') && p.pos > start && (p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E') && !strings.HasPrefix(p.src[start:], "0x")
+			if !isExp && !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && c != '.' && c != '_' {
+				break
+			}
+			p.pos++
+		}
+	case unicode.IsLetter(r):
+		for p.pos < len(p.src) {
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			p.pos += size
+		}
+	case strings.HasPrefix(p.src[p.pos:], "**"):
+		p.pos += 2
+	default:
+		p.pos += size
+	}
+	p.tok = p.src[start:p.pos]
+}
+
+func (p *exprParser) parseSum() (float64, error) {
+	v, err := p.parseProduct()
+	for err == nil && (p.tok == "+" || p.tok == "-") {
+		op := p.tok
+		p.next()
+		var rhs float64
+		if rhs, err = p.parseProduct(); err == nil {
+			if op == "+" {
+				v += rhs
+			} else {
+				v -= rhs
+			}
+		}
+	}
+	return v, err
+}
+
+func (p *exprParser) parseProduct() (float64, error) {
+	v, err := p.parseUnary()
+	for err == nil && (p.tok == "*" || p.tok == "/" || p.tok == "%" || p.tok == "×" || p.tok == "÷") {
+		op := p.tok
+		p.next()
+		var rhs float64
+		if rhs, err = p.parseUnary(); err != nil {
+			break
+		}
+		switch op {
+		case "*", "×":
+			v *= rhs
+		case "/", "÷":
+			if rhs == 0 {
+				return 0, errors.New("division by zero")
+			}
+			v /= rhs
+		case "%":
+			if rhs == 0 {
+				return 0, errors.New("division by zero")
+			}
+			v = math.Mod(v, rhs)
+		}
+	}
+	return v, err
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	switch p.tok {
+	case "-":
+		p.next()
+		v, err := p.parseUnary()
+		return -v, err
+	case "+":
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+// parsePower binds ^ to the right and tighter than a leading minus, so
+// -2^2 is -4.
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil || (p.tok != "^" && p.tok != "**") {
+		return base, err
+	}
+	p.next()
+	exp, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exp), nil
+}
+
+var calcConstants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+var calcFunctions = map[string]func(args []float64) (float64, error){
+	"sqrt":  unaryFunc(math.Sqrt),
+	"abs":   unaryFunc(math.Abs),
+	"round": unaryFunc(math.Round),
+	"floor": unaryFunc(math.Floor),
+	"ceil":  unaryFunc(math.Ceil),
+	"ln":    unaryFunc(math.Log),
+	"log":   unaryFunc(math.Log10),
+	"log10": unaryFunc(math.Log10),
+	"log2":  unaryFunc(math.Log2),
+	"sin":   unaryFunc(math.Sin),
+	"cos":   unaryFunc(math.Cos),
+	"tan":   unaryFunc(math.Tan),
+	"min":   foldFunc(math.Min),
+	"max":   foldFunc(math.Max),
+}
+
+func unaryFunc(f func(float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, errors.New("expects one argument")
+		}
+		return f(args[0]), nil
+	}
+}
+
+func foldFunc(f func(a, b float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, errors.New("expects arguments")
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = f(v, a)
+		}
+		return v, nil
+	}
+}
+
+func (p *exprParser) parsePrimary() (float64, error) {
+	tok := p.tok
+	switch {
+	case tok == "":
+		return 0, errors.New("incomplete expression")
+	case tok == "(":
+		p.next()
+		v, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if p.tok != ")" {
+			return 0, errors.New("missing )")
+		}
+		p.next()
+		return v, nil
+	case tok[0] >= '0' && tok[0] <= '9' || tok[0] == '.':
+		p.next()
+		return parseCalcNumber(tok)
+	}
+	name := strings.ToLower(tok)
+	if v, ok := calcConstants[name]; ok {
+		p.next()
+		return v, nil
+	}
+	f, ok := calcFunctions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown %q", tok)
+	}
+	p.next()
+	if p.tok != "(" {
+		return 0, fmt.Errorf("%s needs (", name)
+	}
+	p.next()
+	var args []float64
+	for p.tok != ")" {
+		v, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+		if p.tok == "," {
+			p.next()
+		} else if p.tok != ")" {
+			return 0, errors.New("missing )")
+		}
+	}
+	p.next()
+	v, err := f(args)
+	if err != nil {
+		return 0, fmt.Errorf("%s %w", name, err)
+	}
+	return v, nil
+}
+
+func parseCalcNumber(tok string) (float64, error) {
+	lower := strings.ToLower(strings.ReplaceAll(tok, "_", ""))
+	if strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "0b") || strings.HasPrefix(lower, "0o") {
+		n, err := strconv.ParseInt(lower, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", tok)
+		}
+		return float64(n), nil
+	}
+	v, err := strconv.ParseFloat(lower, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", tok)
+	}
+	return v, nil
+}
+
+// TimestampHandler handles the /ts command
+type TimestampHandler struct {
+	now func() time.Time // Nil is time.Now
+}
+
+func (h *TimestampHandler) Name() string { return "/ts" }
+func (h *TimestampHandler) Description() string {
+	return "Convert between Unix timestamps and dates"
+}
+
+const tsUsage = `Usage: /ts [epoch|date]
+  /ts 1700000000          seconds, milliseconds, microseconds or nanoseconds
+  /ts 2024-05-01 12:00    a date gives its Unix timestamp
+  /ts                     the current time`
+
+// tsLayouts are the date formats /ts reads, besides Unix timestamps.
+var tsLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.UnixDate,
+	time.RubyDate,
+	time.ANSIC,
+}
+
+func (h *TimestampHandler) Execute(ctx *Context) *Result {
+	now := time.Now()
+	if h.now != nil {
+		now = h.now()
+	}
+	input := utilityInput(ctx)
+	t, fromEpoch, err := parseTimestamp(input, now)
+	if err != nil {
+		return utilityError("Timestamp", tsUsage, err)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "UTC:      %s\n", t.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&sb, "Local:    %s\n", t.Local().Format("Mon 2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&sb, "Relative: %s\n", formatRelative(t.Sub(now)))
+	fmt.Fprintf(&sb, "Unix:     %d (ms %d)", t.Unix(), t.UnixMilli())
+
+	answer := strconv.FormatInt(t.Unix(), 10)
+	if fromEpoch {
+		answer = t.UTC().Format(time.RFC3339)
+	}
+	if input == "" {
+		input = "Now"
+	}
+	return &Result{
+		Title:   "Timestamp",
+		Content: input + "\n\n" + sb.String() + utilityFooter,
+		Copy:    answer,
+	}
+}
+
+// parseTimestamp reads a Unix timestamp, telling its unit by magnitude, or
+// a date. Empty input is now.
+func parseTimestamp(input string, now time.Time) (time.Time, bool, error) {
+	if input == "" {
+		return now, false, nil
+	}
+	if n, err := strconv.ParseInt(input, 10, 64); err == nil {
+		abs := n
+		if abs < 0 {
+			abs = -abs
+		}
+		switch {
+		case abs < 1e11:
+			return time.Unix(n, 0), true, nil
+		case abs < 1e14:
+			return time.UnixMilli(n), true, nil
+		case abs < 1e17:
+			return time.UnixMicro(n), true, nil
+		}
+		return time.Unix(0, n), true, nil
+	}
+	if f, err := strconv.ParseFloat(input, 64); err == nil {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), true, nil
+	}
+	for _, layout := range tsLayouts {
+		if t, err := time.ParseInLocation(layout, input, time.Local); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("not a timestamp or date: %q", input)
+}
+
+// formatRelative renders d as "in 3h 5m" or "2d 4h ago".
+func formatRelative(d time.Duration) string {
+	future := d > 0
+	if d < 0 {
+		d = -d
+	}
+	if d < time.Second {
+		return "now"
+	}
+	var parts []string
+	for _, u := range []struct {
+		size  time.Duration
+		label string
+	}{
+		{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"},
+	} {
+		if n := d / u.size; n > 0 && len(parts) < 2 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.label))
+			d -= n * u.size
+		}
+	}
+	if future {
+		return "in " + strings.Join(parts, " ")
+	}
+	return strings.Join(parts, " ") + " ago"
+}
+
+// Base64Handler handles the /b64 command
+type Base64Handler struct{}
+
+func (h *Base64Handler) Name() string { return "/b64" }
+func (h *Base64Handler) Description() string {
+	return "Base64-encode text, or decode it with -d"
+}
+
+const b64Usage = `Usage: /b64 [-d] [text]
+  /b64 hello              encode
+  /b64 -d aGVsbG8=        decode (standard or URL alphabet, padded or not)
+Without text, the last terminal selection is used.`
+
+func (h *Base64Handler) Execute(ctx *Context) *Result {
+	args := ""
+	if ctx != nil {
+		args = strings.TrimSpace(ctx.Args)
+	}
+	decode := args == "-d" || strings.HasPrefix(args, "-d ")
+	if decode {
+		args = strings.TrimSpace(strings.TrimPrefix(args, "-d"))
+	}
+	input := args
+	if input == "" && ctx != nil {
+		input = strings.TrimSpace(ctx.Selection)
+	}
+	if input == "" {
+		return utilityError("Base64", b64Usage, nil)
+	}
+	if !decode {
+		return utilityResult("Base64", input, base64.StdEncoding.EncodeToString([]byte(input)))
+	}
+	decoded, err := decodeBase64(input)
+	if err != nil {
+		return utilityError("Base64", b64Usage, err)
+	}
+	if !utf8.Valid(decoded) {
+		return &Result{
+			Title:   "Base64",
+			Content: fmt.Sprintf("%s\n\nDecoded %d bytes of binary data:\n%x\n\nPress Esc to close this panel.", input, len(decoded), decoded),
+		}
+	}
+	return utilityResult("Base64", input, string(decoded))
+}
+
+// decodeBase64 accepts the standard and URL alphabets, with or without
+// padding, ignoring whitespace.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("not valid base64")
+}
+
+// JSONPrettyHandler handles the /jsonpp command
+type JSONPrettyHandler struct{}
+
+func (h *JSONPrettyHandler) Name() string { return "/jsonpp" }
+func (h *JSONPrettyHandler) Description() string {
+	return "Pretty-print JSON from the selection or arguments"
+}
+
+const jsonppUsage = `Usage: /jsonpp [json]
+Without JSON, the last terminal selection is used.`
+
+func (h *JSONPrettyHandler) Execute(ctx *Context) *Result {
+	input := utilityInput(ctx)
+	if input == "" {
+		return utilityError("JSON", jsonppUsage, nil)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(input), "", "  "); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			err = fmt.Errorf("%v at offset %d", syntax, syntax.Offset)
+		}
+		return utilityError("JSON", jsonppUsage, err)
+	}
+	return &Result{
+		Title:   "JSON",
+		Content: out.String() + utilityFooter,
+		Copy:    out.String(),
+	}
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalculate(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"1 + 2 * 3", "7"},
+		{"(1 + 2) * 3", "9"},
+		{"2^10 * 3", "3072 (0xc00)"},
+		{"-2^2", "-4"},
+		{"2 ** 3 ** 2", "512 (0x200)"},
+		{"10 / 4", "2.5"},
+		{"7 % 3", "1"},
+		{"0xff + 0b1", "256 (0x100)"},
+		{"1_000 * 1e-3", "1"},
+		{"sqrt(16) + max(1, 5, 3)", "9"},
+		{"1.5 GiB in MB", "1610.612736 MB"},
+		{"90 min to h", "1.5 h"},
+		{"2 * 512 KiB to MiB", "1 MiB"},
+	}
+	for _, tc := range cases {
+		got, err := calculate(tc.in)
+		if err != nil {
+			t.Errorf("calculate(%q) error: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("calculate(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"1 +", "1 / 0", "foo(2)", "(1 + 2", "5 GB to h"} {
+		if got, err := calculate(in); err == nil {
+			t.Errorf("calculate(%q) = %q, want an error", in, got)
+		}
+	}
+}
+
+func TestCalcHandler_UsesSelectionAndCopies(t *testing.T) {
+	result := (&CalcHandler{}).Execute(&Context{Selection: " 6 * 7 "})
+	if result.Copy != "42 (0x2a)" {
+		t.Fatalf("Copy = %q, want the answer", result.Copy)
+	}
+	if !strings.HasPrefix(result.Content, "6 * 7\n\n42") {
+		t.Fatalf("unexpected content: %q", result.Content)
+	}
+
+	result = (&CalcHandler{}).Execute(&Context{})
+	if result.Copy != "" || !strings.Contains(result.Content, "Usage: /calc") {
+		t.Fatalf("expected usage without input, got %#v", result)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	want := time.Unix(1700000000, 0)
+	for _, in := range []string{"1700000000", "1700000000000", "1700000000000000", "1700000000000000000"} {
+		got, fromEpoch, err := parseTimestamp(in, now)
+		if err != nil || !fromEpoch || !got.Equal(want) {
+			t.Errorf("parseTimestamp(%q) = %v, %v, %v; want %v", in, got, fromEpoch, err, want)
+		}
+	}
+
+	got, fromEpoch, err := parseTimestamp("2023-11-14T22:13:20Z", now)
+	if err != nil || fromEpoch || !got.Equal(want) {
+		t.Errorf("parseTimestamp(RFC3339) = %v, %v, %v", got, fromEpoch, err)
+	}
+	if _, _, err := parseTimestamp("yesterday", now); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestTimestampHandler(t *testing.T) {
+	now := time.Unix(1700003600, 0)
+	h := &TimestampHandler{now: func() time.Time { return now }}
+
+	result := h.Execute(&Context{Args: "1700000000"})
+	if result.Copy != "2023-11-14T22:13:20Z" {
+		t.Fatalf("Copy = %q", result.Copy)
+	}
+	for _, want := range []string{"UTC:      2023-11-14T22:13:20Z", "Relative: 1h ago", "Unix:     1700000000 (ms 1700000000000)"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("expected %q in:\n%s", want, result.Content)
+		}
+	}
+
+	result = h.Execute(&Context{Args: "2023-11-14T22:13:20Z"})
+	if result.Copy != "1700000000" {
+		t.Fatalf("Copy = %q, want the Unix timestamp", result.Copy)
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                             "now",
+		90 * time.Second:              "in 1m 30s",
+		-(26*time.Hour + time.Minute): "1d 2h ago",
+	}
+	for d, want := range cases {
+		if got := formatRelative(d); got != want {
+			t.Errorf("formatRelative(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestBase64Handler(t *testing.T) {
+	h := &Base64Handler{}
+	if got := h.Execute(&Context{Args: "hello?"}).Copy; got != "aGVsbG8/" {
+		t.Fatalf("encode = %q", got)
+	}
+	for _, in := range []string{"aGVsbG8/", "aGVsbG8_", "aGk", "aGk="} {
+		got := h.Execute(&Context{Args: "-d " + in}).Copy
+		if got != "hello?" && got != "hi" {
+			t.Errorf("decode %q = %q", in, got)
+		}
+	}
+	if got := h.Execute(&Context{Args: "-d", Selection: "aGk="}).Copy; got != "hi" {
+		t.Fatalf("decode of the selection = %q", got)
+	}
+	if result := h.Execute(&Context{Args: "-d !!"}); result.Copy != "" || !strings.Contains(result.Content, "not valid base64") {
+		t.Fatalf("expected an error, got %#v", result)
+	}
+	if result := h.Execute(&Context{Args: "-d /w=="}); result.Copy != "" || !strings.Contains(result.Content, "binary data") {
+		t.Fatalf("expected binary output to be shown as hex, got %#v", result)
+	}
+}
+
+func TestJSONPrettyHandler(t *testing.T) {
+	h := &JSONPrettyHandler{}
+	result := h.Execute(&Context{Selection: `{"a":1,"b":[true,null]}`})
+	want := "{\n  \"a\": 1,\n  \"b\": [\n    true,\n    null\n  ]\n}"
+	if result.Copy != want {
+		t.Fatalf("Copy = %q, want %q", result.Copy, want)
+	}
+
+	result = h.Execute(&Context{Args: `{"a":}`})
+	if result.Copy != "" || !strings.Contains(result.Content, "at offset") {
+		t.Fatalf("expected a syntax error, got %#v", result)
+	}
+}
@@ -3,27 +3,80 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+
+	"wtf_cli/pkg/sealed"
 )
 
 // Config represents the application configuration
 type Config struct {
-	LLMProvider   string            `json:"llm_provider"`
-	OpenRouter    OpenRouterConfig  `json:"openrouter"`
-	Providers     ProvidersConfig   `json:"providers"`
-	Agent         AgentConfig       `json:"agent"`
-	BufferSize    int               `json:"buffer_size"`
-	ContextWindow int               `json:"context_window"`
-	StatusBar     StatusBarConfig   `json:"status_bar"`
-	UpdateCheck   UpdateCheckConfig `json:"update_check"`
-	LogFile       string            `json:"log_file"`
-	LogFormat     string            `json:"log_format"`
-	LogLevel      string            `json:"log_level"`
+	LLMProvider      string                `json:"llm_provider"`
+	OpenRouter       OpenRouterConfig      `json:"openrouter"`
+	Providers        ProvidersConfig       `json:"providers"`
+	Agent            AgentConfig           `json:"agent"`
+	BufferSize       int                   `json:"buffer_size"`
+	ContextWindow    int                   `json:"context_window"`
+	StatusBar        StatusBarConfig       `json:"status_bar"`
+	UpdateCheck      UpdateCheckConfig     `json:"update_check"`
+	Notifications    NotificationsConfig   `json:"notifications"`
+	ResponseLanguage string                `json:"response_language"`
+	ColorMode        string                `json:"color_mode"`
+	Prewarm          string                `json:"prewarm,omitempty"`
+	Prompts          PromptsConfig         `json:"prompts,omitzero"`
+	LocalModels      LocalModelsConfig     `json:"local_models,omitzero"`
+	Paste            PasteConfig           `json:"paste,omitzero"`
+	History          HistoryConfig         `json:"history,omitzero"`
+	Budgets          BudgetsConfig         `json:"budgets,omitzero"`
+	Sync             SyncConfig            `json:"sync,omitzero"`
+	Aliases          []CommandAlias        `json:"aliases,omitempty"`
+	Hooks            []HookConfig          `json:"hooks,omitempty"`
+	Focus            FocusConfig           `json:"focus,omitzero"`
+	Pickers          PickersConfig         `json:"pickers,omitzero"`
+	Privacy          PrivacyConfig         `json:"privacy,omitzero"`
+	AutoContext      AutoContextConfig     `json:"auto_context,omitzero"`
+	Layout           LayoutConfig          `json:"layout,omitzero"`
+	ConfirmCommands  ConfirmCommandsConfig `json:"confirm_commands,omitzero"`
+	SyntaxCheck      SyntaxCheckConfig     `json:"syntax_check,omitzero"`
+	WorkspaceIndex   WorkspaceIndexConfig  `json:"workspace_index,omitzero"`
+	Recap            RecapConfig           `json:"recap,omitzero"`
+	LogFile          string                `json:"log_file"`
+	LogFormat        string                `json:"log_format"`
+	LogLevel         string                `json:"log_level"`
+	LogRotation      LogRotationConfig     `json:"log_rotation,omitzero"`
+
+	// Policy is the organization policy Load enforced on this config. It
+	// is never saved.
+	Policy Policy `json:"-"`
+	// Env lists the fields Load set from WTF_ environment variables. Save
+	// writes the file's values for them instead.
+	Env EnvOverrides `json:"-"`
 }
 
+// Values accepted for Config.ColorMode. ColorModeAuto detects the terminal's
+// capabilities (honoring NO_COLOR, CLICOLOR_FORCE and TERM=dumb); the others
+// force a color depth regardless of what the terminal reports.
+const (
+	ColorModeAuto      = "auto"
+	ColorModeTrueColor = "truecolor"
+	ColorMode256       = "256"
+	ColorMode16        = "16"
+	ColorModeNone      = "none"
+)
+
+// Values accepted for Config.Prewarm, when the connection to the active
+// provider is opened ahead of the first request. Empty is PrewarmOff.
+const (
+	PrewarmOff     = "off"
+	PrewarmStartup = "startup" // At startup and when settings are saved
+	PrewarmSidebar = "sidebar" // When the chat sidebar opens
+)
+
 // AgentConfig controls the agentic tool-execution loop used by /explain and /chat.
 type AgentConfig struct {
 	// MaxIterations is the number of tool calls the agent may run before the
@@ -77,12 +130,15 @@ type ProvidersConfig struct {
 
 // OpenAIConfig holds OpenAI API configuration.
 type OpenAIConfig struct {
-	APIKey            string  `json:"api_key"`
-	APIURL            string  `json:"api_url"`
-	Model             string  `json:"model"`
-	Temperature       float64 `json:"temperature"`
-	MaxTokens         int     `json:"max_tokens"`
-	APITimeoutSeconds int     `json:"api_timeout_seconds"`
+	APIKey            string           `json:"api_key"`
+	APIURL            string           `json:"api_url"`
+	Model             string           `json:"model"`
+	Temperature       float64          `json:"temperature"`
+	MaxTokens         int              `json:"max_tokens"`
+	APITimeoutSeconds int              `json:"api_timeout_seconds"`
+	RequestOverrides  RequestOverrides `json:"request_overrides,omitzero"`
+	ClientCert        ClientCertConfig `json:"client_cert,omitzero"`
+	Proxy             ProxyConfig      `json:"proxy,omitzero"`
 }
 
 // CopilotConfig holds GitHub Copilot configuration.
@@ -91,37 +147,266 @@ type CopilotConfig struct {
 	Temperature       float64 `json:"temperature"`
 	MaxTokens         int     `json:"max_tokens"`
 	APITimeoutSeconds int     `json:"api_timeout_seconds"`
+	// SessionIdleSeconds is how long the Copilot client and the sessions of
+	// recent conversations are kept for reuse; 0 is 300, negative turns
+	// reuse off.
+	SessionIdleSeconds int `json:"session_idle_seconds,omitzero"`
 }
 
 // AnthropicConfig holds Anthropic API configuration.
 type AnthropicConfig struct {
-	APIKey            string  `json:"api_key"`
-	APIURL            string  `json:"api_url"`
-	Model             string  `json:"model"`
-	Temperature       float64 `json:"temperature"`
-	MaxTokens         int     `json:"max_tokens"`
-	APITimeoutSeconds int     `json:"api_timeout_seconds"`
+	APIKey            string           `json:"api_key"`
+	APIURL            string           `json:"api_url"`
+	Model             string           `json:"model"`
+	Temperature       float64          `json:"temperature"`
+	MaxTokens         int              `json:"max_tokens"`
+	APITimeoutSeconds int              `json:"api_timeout_seconds"`
+	RequestOverrides  RequestOverrides `json:"request_overrides,omitzero"`
+	ClientCert        ClientCertConfig `json:"client_cert,omitzero"`
+	Proxy             ProxyConfig      `json:"proxy,omitzero"`
 }
 
 // GoogleConfig holds Google Gemini API configuration.
 type GoogleConfig struct {
-	APIKey            string  `json:"api_key"`
-	Model             string  `json:"model"`
-	Temperature       float64 `json:"temperature"`
-	MaxTokens         int     `json:"max_tokens"`
-	APITimeoutSeconds int     `json:"api_timeout_seconds"`
+	APIKey            string           `json:"api_key"`
+	Model             string           `json:"model"`
+	Temperature       float64          `json:"temperature"`
+	MaxTokens         int              `json:"max_tokens"`
+	APITimeoutSeconds int              `json:"api_timeout_seconds"`
+	RequestOverrides  RequestOverrides `json:"request_overrides,omitzero"`
+	ClientCert        ClientCertConfig `json:"client_cert,omitzero"`
+	Proxy             ProxyConfig      `json:"proxy,omitzero"`
 }
 
 // OpenRouterConfig holds the OpenRouter API configuration
 type OpenRouterConfig struct {
-	APIKey            string  `json:"api_key"`
-	APIURL            string  `json:"api_url"`
-	HTTPReferer       string  `json:"http_referer"`
-	XTitle            string  `json:"x_title"`
-	Model             string  `json:"model"`
-	Temperature       float64 `json:"temperature"`
-	MaxTokens         int     `json:"max_tokens"`
-	APITimeoutSeconds int     `json:"api_timeout_seconds"`
+	APIKey            string                        `json:"api_key"`
+	APIURL            string                        `json:"api_url"`
+	HTTPReferer       string                        `json:"http_referer"`
+	XTitle            string                        `json:"x_title"`
+	Model             string                        `json:"model"`
+	Temperature       float64                       `json:"temperature"`
+	MaxTokens         int                           `json:"max_tokens"`
+	APITimeoutSeconds int                           `json:"api_timeout_seconds"`
+	Provider          OpenRouterProviderPreferences `json:"provider,omitzero"` // Upstream provider routing
+	Route             string                        `json:"route,omitempty"`   // OpenRouterRouteFallback or empty
+	RequestOverrides  RequestOverrides              `json:"request_overrides,omitzero"`
+	ClientCert        ClientCertConfig              `json:"client_cert,omitzero"`
+	Proxy             ProxyConfig                   `json:"proxy,omitzero"`
+}
+
+// OpenRouterProviderPreferences is sent as the `provider` object of every
+// OpenRouter request. Empty fields leave OpenRouter's defaults in place.
+type OpenRouterProviderPreferences struct {
+	// Order lists upstream providers to try first, e.g. ["anthropic", "azure"].
+	Order []string `json:"order,omitempty"`
+	// AllowFallbacks set to false stops OpenRouter from using providers
+	// outside Order when they are unavailable.
+	AllowFallbacks *bool `json:"allow_fallbacks,omitempty"`
+	// DataCollection set to OpenRouterDataCollectionDeny only routes to
+	// providers that do not store or train on prompts.
+	DataCollection string `json:"data_collection,omitempty"`
+	// ZDR only routes to endpoints with a zero data retention policy.
+	ZDR bool `json:"zdr,omitempty"`
+}
+
+// IsZero reports whether no routing preference is set.
+func (p OpenRouterProviderPreferences) IsZero() bool {
+	return len(p.Order) == 0 && p.AllowFallbacks == nil && p.DataCollection == "" && !p.ZDR
+}
+
+// Values accepted for OpenRouter routing fields.
+const (
+	OpenRouterRouteFallback       = "fallback"
+	OpenRouterDataCollectionAllow = "allow"
+	OpenRouterDataCollectionDeny  = "deny"
+)
+
+// ClientCertConfig is the client certificate presented to a provider that
+// requires mutual TLS, such as an internal LLM gateway. Paths may start
+// with "~/".
+type ClientCertConfig struct {
+	CertFile string `json:"cert_file,omitempty"` // PEM certificate, optionally followed by its chain
+	KeyFile  string `json:"key_file,omitempty"`  // PEM private key
+}
+
+// IsZero reports whether no client certificate is configured.
+func (c ClientCertConfig) IsZero() bool {
+	return strings.TrimSpace(c.CertFile) == "" && strings.TrimSpace(c.KeyFile) == ""
+}
+
+func (c ClientCertConfig) validate(field string) error {
+	if c.IsZero() {
+		return nil
+	}
+	if strings.TrimSpace(c.CertFile) == "" || strings.TrimSpace(c.KeyFile) == "" {
+		return fmt.Errorf("%s needs both cert_file and key_file", field)
+	}
+	return nil
+}
+
+// ProxyConfig routes a provider's traffic where direct egress is blocked:
+// through an HTTP or SOCKS5 proxy, or through an SSH jump host. Without it
+// the HTTP_PROXY and HTTPS_PROXY environment variables apply.
+type ProxyConfig struct {
+	// URL is an http://, https://, socks5:// or socks5h:// proxy URL, with
+	// credentials in its user info if the proxy needs them.
+	URL string `json:"url,omitempty"`
+	// SSHTunnel is a jump host, "[user@]host[:port]", that connections are
+	// opened from. It authenticates with the local ssh agent and checks the
+	// host key against ~/.ssh/known_hosts.
+	SSHTunnel string `json:"ssh_tunnel,omitempty"`
+}
+
+// IsZero reports whether no proxy is configured.
+func (p ProxyConfig) IsZero() bool {
+	return strings.TrimSpace(p.URL) == "" && strings.TrimSpace(p.SSHTunnel) == ""
+}
+
+func (p ProxyConfig) validate(field string) error {
+	rawURL, tunnel := strings.TrimSpace(p.URL), strings.TrimSpace(p.SSHTunnel)
+	if rawURL != "" && tunnel != "" {
+		return fmt.Errorf("%s takes url or ssh_tunnel, not both", field)
+	}
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("%s.url: %w", field, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("%s.url must be http, https, socks5 or socks5h, got: %q", field, u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("%s.url has no host: %q", field, rawURL)
+		}
+	}
+	if tunnel != "" {
+		if _, _, err := ParseSSHTarget(tunnel); err != nil {
+			return fmt.Errorf("%s.ssh_tunnel: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// ParseSSHTarget splits "[user@]host[:port]" into the user, empty when not
+// given, and the address to dial, port 22 by default.
+func ParseSSHTarget(target string) (user, addr string, err error) {
+	target = strings.TrimSpace(target)
+	hostPort := target
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		user, hostPort = target[:i], target[i+1:]
+		if user == "" {
+			return "", "", fmt.Errorf("empty user in %q", target)
+		}
+	}
+	host, port := hostPort, "22"
+	if h, p, splitErr := net.SplitHostPort(hostPort); splitErr == nil {
+		host, port = h, p
+	}
+	if host == "" || strings.ContainsAny(host, " /") {
+		return "", "", fmt.Errorf("want [user@]host[:port], got %q", target)
+	}
+	if n, convErr := strconv.Atoi(port); convErr != nil || n <= 0 || n > 65535 {
+		return "", "", fmt.Errorf("invalid port %q", port)
+	}
+	return user, net.JoinHostPort(host, port), nil
+}
+
+// ProviderTransport is how requests reach a provider: the client
+// certificate presented and the proxy gone through. It is comparable, so
+// clients that share one share a connection pool.
+type ProviderTransport struct {
+	ClientCert ClientCertConfig
+	Proxy      ProxyConfig
+}
+
+// IsZero reports whether requests go out directly without a certificate.
+func (t ProviderTransport) IsZero() bool {
+	return t.ClientCert.IsZero() && t.Proxy.IsZero()
+}
+
+// Transport returns the certificate and proxy of OpenRouter requests.
+func (c OpenRouterConfig) Transport() ProviderTransport {
+	return ProviderTransport{ClientCert: c.ClientCert, Proxy: c.Proxy}
+}
+
+// Transport returns the certificate and proxy of OpenAI requests.
+func (c OpenAIConfig) Transport() ProviderTransport {
+	return ProviderTransport{ClientCert: c.ClientCert, Proxy: c.Proxy}
+}
+
+// Transport returns the certificate and proxy of Anthropic requests.
+func (c AnthropicConfig) Transport() ProviderTransport {
+	return ProviderTransport{ClientCert: c.ClientCert, Proxy: c.Proxy}
+}
+
+// Transport returns the certificate and proxy of Google requests.
+func (c GoogleConfig) Transport() ProviderTransport {
+	return ProviderTransport{ClientCert: c.ClientCert, Proxy: c.Proxy}
+}
+
+// RequestOverrides adds extra fields and headers to every request sent to a
+// provider, for gateways that need e.g. `safe_prompt` or routing tags.
+type RequestOverrides struct {
+	// Body fields are merged into the top level of the JSON request body,
+	// replacing fields of the same name.
+	Body    map[string]any    `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// IsEmpty reports whether no overrides are configured.
+func (r RequestOverrides) IsEmpty() bool {
+	return len(r.Body) == 0 && len(r.Headers) == 0
+}
+
+// reservedOverrideBodyFields are request body fields the providers build
+// from the conversation; overriding them would break the request.
+var reservedOverrideBodyFields = []string{"model", "messages", "stream", "tools", "contents", "system"}
+
+// reservedOverrideHeaders carry credentials or framing and are always set by
+// the provider.
+var reservedOverrideHeaders = []string{"authorization", "x-api-key", "x-goog-api-key", "content-type", "content-length", "host"}
+
+func (r RequestOverrides) validate(field string) error {
+	for key := range r.Body {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%s.body contains an empty field name", field)
+		}
+		if slices.Contains(reservedOverrideBodyFields, key) {
+			return fmt.Errorf("%s.body must not set %q, it is built from the conversation", field, key)
+		}
+	}
+	for name, value := range r.Headers {
+		if !isHeaderName(name) {
+			return fmt.Errorf("%s.headers has an invalid header name: %q", field, name)
+		}
+		if slices.Contains(reservedOverrideHeaders, strings.ToLower(name)) {
+			return fmt.Errorf("%s.headers must not set %q, it is managed by the provider", field, name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s.headers[%q] must be a single line", field, name)
+		}
+	}
+	return nil
+}
+
+// isHeaderName reports whether name is a valid HTTP header field name
+// (an RFC 7230 token).
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
 }
 
 // StatusBarConfig holds status bar UI configuration
@@ -136,6 +421,666 @@ type UpdateCheckConfig struct {
 	IntervalHours int  `json:"interval_hours"`
 }
 
+// LocalModelsConfig applies when the active provider's api_url points at
+// this machine, e.g. an Ollama or llama.cpp server.
+type LocalModelsConfig struct {
+	// ContextLines caps the terminal lines sent with each request, since
+	// local models often have a much smaller context. Zero keeps the
+	// default used for cloud models.
+	ContextLines int `json:"context_lines,omitempty"`
+	// LoadTimeoutSeconds bounds how long the model may take to load at
+	// startup. Requests made meanwhile wait for it. Zero means
+	// DefaultLocalModelLoadTimeoutSeconds.
+	LoadTimeoutSeconds int `json:"load_timeout_seconds,omitempty"`
+}
+
+// DefaultLocalModelLoadTimeoutSeconds is how long a local model may take to
+// load when local_models.load_timeout_seconds is unset.
+const DefaultLocalModelLoadTimeoutSeconds = 180
+
+func (l LocalModelsConfig) validate() error {
+	if l.ContextLines < 0 {
+		return fmt.Errorf("local_models.context_lines must not be negative, got: %d", l.ContextLines)
+	}
+	if l.LoadTimeoutSeconds < 0 {
+		return fmt.Errorf("local_models.load_timeout_seconds must not be negative, got: %d", l.LoadTimeoutSeconds)
+	}
+	return nil
+}
+
+// PasteConfig controls pastes into the terminal.
+type PasteConfig struct {
+	// ConfirmAboveKB asks before pasting more than this many KiB into the
+	// shell. Zero means DefaultPasteConfirmAboveKB; a negative value never
+	// asks.
+	ConfirmAboveKB int `json:"confirm_above_kb,omitempty"`
+	// Terminal and Chat clean up what is pasted into the shell and into
+	// the chat input.
+	Terminal PasteTargetConfig `json:"terminal,omitzero"`
+	Chat     PasteTargetConfig `json:"chat,omitzero"`
+}
+
+// PasteTargetConfig cleans up pastes into one target. The zero value turns
+// CRLF line endings into LF and leaves the rest alone.
+type PasteTargetConfig struct {
+	// StripTrailingNewlines drops the line breaks at the end of a paste,
+	// so a pasted command waits at the prompt instead of running.
+	StripTrailingNewlines bool `json:"strip_trailing_newlines,omitempty"`
+	// KeepCRLF pastes CRLF line endings as they are.
+	KeepCRLF bool `json:"keep_crlf,omitempty"`
+}
+
+// DefaultPasteConfirmAboveKB is the paste size that asks for confirmation
+// when paste.confirm_above_kb is unset.
+const DefaultPasteConfirmAboveKB = 1024
+
+// ConfirmAboveBytes returns the paste size in bytes above which the user is
+// asked first, or 0 when pastes never ask.
+func (p PasteConfig) ConfirmAboveBytes() int {
+	switch {
+	case p.ConfirmAboveKB < 0:
+		return 0
+	case p.ConfirmAboveKB == 0:
+		return DefaultPasteConfirmAboveKB * 1024
+	}
+	return p.ConfirmAboveKB * 1024
+}
+
+// PickersConfig controls the model and option pickers.
+type PickersConfig struct {
+	// PageSize is how many rows PgUp and PgDn move. Zero moves a screenful.
+	PageSize int `json:"page_size,omitempty"`
+}
+
+func (p PickersConfig) validate() error {
+	if p.PageSize < 0 {
+		return fmt.Errorf("pickers.page_size must not be negative, got: %d", p.PageSize)
+	}
+	return nil
+}
+
+// PrivacyConfig controls what wtf_cli keeps of the terminal session.
+type PrivacyConfig struct {
+	// DisableCapture starts in privacy mode: output is not kept in the
+	// buffer, commands are not recorded and AI requests carry no terminal
+	// context. /privacy toggles it for the session.
+	DisableCapture bool `json:"disable_capture,omitempty"`
+}
+
+// AutoContextConfig sizes the terminal output sent with /explain, /wtf and
+// /why from the output itself: a one-line error needs few lines, a stack
+// trace many. A context_lines set on an alias or focus command wins.
+type AutoContextConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MinLines and MaxLines bound the choice. Zero means
+	// DefaultAutoContextMinLines and DefaultAutoContextMaxLines.
+	MinLines int `json:"min_lines,omitempty"`
+	MaxLines int `json:"max_lines,omitempty"`
+}
+
+// Default bounds for auto_context.
+const (
+	DefaultAutoContextMinLines = 20
+	DefaultAutoContextMaxLines = 200
+)
+
+// Bounds returns the fewest and most lines auto_context may choose.
+func (a AutoContextConfig) Bounds() (minLines, maxLines int) {
+	minLines, maxLines = a.MinLines, a.MaxLines
+	if minLines == 0 {
+		minLines = DefaultAutoContextMinLines
+		if maxLines > 0 {
+			minLines = min(minLines, maxLines)
+		}
+	}
+	if maxLines == 0 {
+		maxLines = max(DefaultAutoContextMaxLines, minLines)
+	}
+	return minLines, maxLines
+}
+
+func (a AutoContextConfig) validate() error {
+	if a.MinLines < 0 || a.MaxLines < 0 {
+		return fmt.Errorf("auto_context.min_lines and max_lines must not be negative, got: %d and %d", a.MinLines, a.MaxLines)
+	}
+	if minLines, maxLines := a.Bounds(); minLines > maxLines {
+		return fmt.Errorf("auto_context.min_lines (%d) must not exceed max_lines (%d)", minLines, maxLines)
+	}
+	return nil
+}
+
+// LayoutConfig sets the screen widths at which the chat sidebar changes
+// layout. Zero keeps the default; -1 turns the preset off.
+type LayoutConfig struct {
+	// OverlayBelow is the width below which the sidebar covers the
+	// terminal instead of sharing the screen with it.
+	OverlayBelow int `json:"overlay_below,omitempty"`
+	// ThreeColumnsFrom is the width from which a context panel is shown
+	// between the terminal and the sidebar.
+	ThreeColumnsFrom int `json:"three_columns_from,omitempty"`
+}
+
+func (l LayoutConfig) validate() error {
+	if l.OverlayBelow < -1 || l.ThreeColumnsFrom < -1 {
+		return fmt.Errorf("layout.overlay_below and three_columns_from must be -1 (off), 0 (default) or a width, got: %d and %d", l.OverlayBelow, l.ThreeColumnsFrom)
+	}
+	if l.OverlayBelow > 0 && l.ThreeColumnsFrom > 0 && l.OverlayBelow > l.ThreeColumnsFrom {
+		return fmt.Errorf("layout.overlay_below (%d) must not exceed three_columns_from (%d)", l.OverlayBelow, l.ThreeColumnsFrom)
+	}
+	return nil
+}
+
+// ConfirmCommandsConfig asks before Enter runs a destructive command typed
+// at the prompt, the way the chat sidebar asks before applying one.
+type ConfirmCommandsConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Patterns are more commands to ask about besides the built-in
+	// destructive ones. A pattern matches a command alone or with more
+	// arguments; * matches any text.
+	Patterns []string `json:"patterns,omitempty"`
+	// AskAgain asks even for a command already run in this session or
+	// found in the shell history.
+	AskAgain bool `json:"ask_again,omitempty"`
+}
+
+func (c ConfirmCommandsConfig) validate() error {
+	for i, p := range c.Patterns {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("confirm_commands.patterns[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// SyntaxCheckConfig controls the syntax warnings shown in the status bar
+// while a command is typed at the prompt.
+type SyntaxCheckConfig struct {
+	// Mode is SyntaxCheckAuto (the default when empty), SyntaxCheckBuiltin
+	// or SyntaxCheckOff.
+	Mode string `json:"mode,omitempty"`
+}
+
+// Values accepted for SyntaxCheckConfig.Mode.
+const (
+	SyntaxCheckAuto    = "auto"    // Built-in checks, then shellcheck when installed
+	SyntaxCheckBuiltin = "builtin" // Built-in checks only
+	SyntaxCheckOff     = "off"
+)
+
+func (c SyntaxCheckConfig) validate() error {
+	switch strings.TrimSpace(c.Mode) {
+	case "", SyntaxCheckAuto, SyntaxCheckBuiltin, SyntaxCheckOff:
+		return nil
+	}
+	return fmt.Errorf("syntax_check.mode must be %q, %q or %q, got: %q", SyntaxCheckAuto, SyntaxCheckBuiltin, SyntaxCheckOff, c.Mode)
+}
+
+// WorkspaceIndexConfig controls the map of the current project (its file
+// tree and the start of files such as the README and Makefile) sent with AI
+// requests once the workspace is trusted with files.
+type WorkspaceIndexConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxFiles caps the files listed. Zero means
+	// DefaultWorkspaceIndexMaxFiles.
+	MaxFiles int `json:"max_files,omitempty"`
+	// ExcerptKB is how much of each key file is included. Zero means
+	// DefaultWorkspaceExcerptKB.
+	ExcerptKB int `json:"excerpt_kb,omitempty"`
+}
+
+// Defaults for the workspace_index limits left unset.
+const (
+	DefaultWorkspaceIndexMaxFiles = 300
+	DefaultWorkspaceExcerptKB     = 2
+)
+
+// FileLimit returns how many files the map lists.
+func (c WorkspaceIndexConfig) FileLimit() int {
+	if c.MaxFiles == 0 {
+		return DefaultWorkspaceIndexMaxFiles
+	}
+	return c.MaxFiles
+}
+
+// ExcerptBytes returns how much of each key file the map includes.
+func (c WorkspaceIndexConfig) ExcerptBytes() int {
+	if c.ExcerptKB == 0 {
+		return DefaultWorkspaceExcerptKB * 1024
+	}
+	return c.ExcerptKB * 1024
+}
+
+func (c WorkspaceIndexConfig) validate() error {
+	if c.MaxFiles < 0 {
+		return fmt.Errorf("workspace_index.max_files must not be negative, got: %d", c.MaxFiles)
+	}
+	if c.ExcerptKB < 0 {
+		return fmt.Errorf("workspace_index.excerpt_kb must not be negative, got: %d", c.ExcerptKB)
+	}
+	return nil
+}
+
+// RecapConfig controls the "while you were away" recap shown in the status
+// bar when the user comes back to output they had not seen.
+type RecapConfig struct {
+	// IdleMinutes is how long without input counts as being away. Zero
+	// turns the recap off.
+	IdleMinutes int `json:"idle_minutes,omitempty"`
+}
+
+func (c RecapConfig) validate() error {
+	if c.IdleMinutes < 0 {
+		return fmt.Errorf("recap.idle_minutes must not be negative, got: %d", c.IdleMinutes)
+	}
+	return nil
+}
+
+// HistoryConfig controls the Ctrl+R history search.
+type HistoryConfig struct {
+	// Ignore hides commands from the search. A pattern matches a command
+	// alone or with arguments ("ls" hides "ls -la"); * matches any text.
+	Ignore []string `json:"ignore,omitempty"`
+	// IgnoreSpace hides commands typed with a leading space.
+	IgnoreSpace bool `json:"ignore_space,omitempty"`
+}
+
+// BudgetsConfig caps the tokens each provider may use per calendar month.
+// Usage is counted locally from the token counts providers report.
+type BudgetsConfig struct {
+	// MonthlyTokens maps a provider name, as in llm_provider, to the input
+	// plus output tokens it may use per month. A provider without an entry
+	// is unlimited.
+	MonthlyTokens map[string]int `json:"monthly_tokens,omitempty"`
+	// ShowInStatusBar shows the active provider's remaining budget in the
+	// status bar.
+	ShowInStatusBar bool `json:"show_in_status_bar,omitempty"`
+}
+
+// MonthlyLimit returns the monthly token budget of provider, or 0 when it
+// has none.
+func (b BudgetsConfig) MonthlyLimit(provider string) int {
+	return b.MonthlyTokens[provider]
+}
+
+func (b BudgetsConfig) validate() error {
+	for provider, tokens := range b.MonthlyTokens {
+		if !IsValidProvider(provider) {
+			return fmt.Errorf("budgets.monthly_tokens: unsupported provider %q (supported: %v)", provider, SupportedProviders())
+		}
+		if tokens < 0 {
+			return fmt.Errorf("budgets.monthly_tokens.%s must not be negative, got: %d", provider, tokens)
+		}
+	}
+	return nil
+}
+
+// SyncConfig sets up `wtf_cli sync`, which keeps command history and
+// saved scripts in step across machines through a remote the user owns.
+// Everything stored remotely is encrypted with the sync passphrase.
+type SyncConfig struct {
+	// Backend is where the encrypted state is kept: SyncBackendWebDAV,
+	// SyncBackendS3 or SyncBackendGit. Empty disables sync.
+	Backend string `json:"backend,omitempty"`
+	// KeyFile holds the sync passphrase. The WTF_SYNC_KEY environment
+	// variable takes precedence; without either, `wtf_cli sync` prompts.
+	KeyFile string `json:"key_file,omitempty"`
+	// Auto syncs when wtf_cli exits.
+	Auto   bool             `json:"auto,omitempty"`
+	WebDAV SyncWebDAVConfig `json:"webdav,omitzero"`
+	S3     SyncS3Config     `json:"s3,omitzero"`
+	Git    SyncGitConfig    `json:"git,omitzero"`
+}
+
+// Values accepted for SyncConfig.Backend.
+const (
+	SyncBackendWebDAV = "webdav"
+	SyncBackendS3     = "s3"
+	SyncBackendGit    = "git"
+)
+
+// SyncWebDAVConfig points at the file on a WebDAV server holding the state.
+type SyncWebDAVConfig struct {
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// SyncS3Config points at the object holding the state. Endpoint defaults
+// to AWS; set it for S3-compatible stores such as MinIO. Missing keys are
+// read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+type SyncS3Config struct {
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	Key             string `json:"key,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+}
+
+// SyncGitConfig names the repository holding the state. It is cloned under
+// ~/.wtf_cli and pushed with the user's git credentials.
+type SyncGitConfig struct {
+	URL    string `json:"url,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// Enabled reports whether a sync backend is configured.
+func (s SyncConfig) Enabled() bool {
+	return strings.TrimSpace(s.Backend) != ""
+}
+
+func (s SyncConfig) validate() error {
+	switch strings.TrimSpace(s.Backend) {
+	case "":
+	case SyncBackendWebDAV:
+		if strings.TrimSpace(s.WebDAV.URL) == "" {
+			return fmt.Errorf("sync.webdav.url is required for the webdav backend")
+		}
+	case SyncBackendS3:
+		if strings.TrimSpace(s.S3.Bucket) == "" || strings.TrimSpace(s.S3.Key) == "" {
+			return fmt.Errorf("sync.s3.bucket and sync.s3.key are required for the s3 backend")
+		}
+	case SyncBackendGit:
+		if strings.TrimSpace(s.Git.URL) == "" {
+			return fmt.Errorf("sync.git.url is required for the git backend")
+		}
+	default:
+		return fmt.Errorf("sync.backend must be %q, %q or %q, got: %s", SyncBackendWebDAV, SyncBackendS3, SyncBackendGit, s.Backend)
+	}
+	return nil
+}
+
+// LogRotationConfig caps the size of log_file. Zero values keep the
+// defaults below.
+type LogRotationConfig struct {
+	// MaxSizeMB rotates the log once it reaches this size.
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+	// RotateEveryHours also rotates a log that has been written to for this
+	// long, so a quiet session still starts fresh files. Zero rotates by
+	// size only.
+	RotateEveryHours int `json:"rotate_every_hours,omitempty"`
+	// MaxBackups is the number of rotated files kept.
+	MaxBackups int `json:"max_backups,omitempty"`
+	// MaxAgeDays deletes rotated files older than this.
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+	// Compress set to false keeps rotated files uncompressed.
+	Compress *bool `json:"compress,omitempty"`
+}
+
+// Defaults of LogRotationConfig.
+const (
+	DefaultLogMaxSizeMB  = 5
+	DefaultLogMaxBackups = 5
+	DefaultLogMaxAgeDays = 14
+)
+
+// WithDefaults fills unset fields with the defaults.
+func (r LogRotationConfig) WithDefaults() LogRotationConfig {
+	if r.MaxSizeMB == 0 {
+		r.MaxSizeMB = DefaultLogMaxSizeMB
+	}
+	if r.MaxBackups == 0 {
+		r.MaxBackups = DefaultLogMaxBackups
+	}
+	if r.MaxAgeDays == 0 {
+		r.MaxAgeDays = DefaultLogMaxAgeDays
+	}
+	if r.Compress == nil {
+		compress := true
+		r.Compress = &compress
+	}
+	return r
+}
+
+func (r LogRotationConfig) validate() error {
+	if r.MaxSizeMB < 0 {
+		return fmt.Errorf("log_rotation.max_size_mb must not be negative, got: %d", r.MaxSizeMB)
+	}
+	if r.RotateEveryHours < 0 {
+		return fmt.Errorf("log_rotation.rotate_every_hours must not be negative, got: %d", r.RotateEveryHours)
+	}
+	if r.MaxBackups < 0 {
+		return fmt.Errorf("log_rotation.max_backups must not be negative, got: %d", r.MaxBackups)
+	}
+	if r.MaxAgeDays < 0 {
+		return fmt.Errorf("log_rotation.max_age_days must not be negative, got: %d", r.MaxAgeDays)
+	}
+	return nil
+}
+
+// CommandAlias adds a palette command that runs an existing one with preset
+// arguments, e.g. /wtf-verbose for /explain with more context.
+type CommandAlias struct {
+	// Name is the palette command, including its leading slash.
+	Name string `json:"name"`
+	// Command is the built-in command it runs, e.g. "/explain".
+	Command string `json:"command"`
+	// Args is passed as if typed after the command; text typed after the
+	// alias is appended.
+	Args        string `json:"args,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Model replaces the configured model for AI commands.
+	Model string `json:"model,omitempty"`
+	// ContextLines sets how many terminal lines AI commands send.
+	ContextLines int `json:"context_lines,omitempty"`
+}
+
+func validateAliases(aliases []CommandAlias) error {
+	names := make(map[string]bool, len(aliases))
+	for _, a := range aliases {
+		name := a.Name
+		if len(name) < 2 || name[0] != '/' || strings.ContainsFunc(name[1:], func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+		}) {
+			return fmt.Errorf("aliases: name must be a slash followed by lowercase letters, digits, '-' or '_', got: %q", name)
+		}
+		if names[name] {
+			return fmt.Errorf("aliases: duplicate name %q", name)
+		}
+		names[name] = true
+		if !strings.HasPrefix(a.Command, "/") {
+			return fmt.Errorf("aliases: %s: command must name a slash command, got: %q", name, a.Command)
+		}
+		if a.ContextLines < 0 {
+			return fmt.Errorf("aliases: %s: context_lines must not be negative, got: %d", name, a.ContextLines)
+		}
+	}
+	for _, a := range aliases {
+		if names[a.Command] {
+			return fmt.Errorf("aliases: %s: command %s is itself an alias", a.Name, a.Command)
+		}
+	}
+	return nil
+}
+
+// HookConfig runs a shell command when an event happens, with the event as
+// JSON on its stdin.
+type HookConfig struct {
+	// Event is one of the HookEvent* names.
+	Event string `json:"event"`
+	// Command is run with sh -c in the shell's directory.
+	Command string `json:"command"`
+	// TimeoutSeconds bounds the run. Zero means DefaultHookTimeoutSeconds.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// DefaultHookTimeoutSeconds is how long a hook may run when
+// timeout_seconds is unset.
+const DefaultHookTimeoutSeconds = 10
+
+// Hook event names accepted in HookConfig.Event.
+const (
+	HookEventSessionStart   = "session_start"
+	HookEventSessionEnd     = "session_end"
+	HookEventCommandFailed  = "command_failed"
+	HookEventAnswerReceived = "answer_received"
+	HookEventCommandApplied = "command_applied"
+)
+
+// HookEvents returns the list of supported hook event names.
+func HookEvents() []string {
+	return []string{
+		HookEventSessionStart,
+		HookEventSessionEnd,
+		HookEventCommandFailed,
+		HookEventAnswerReceived,
+		HookEventCommandApplied,
+	}
+}
+
+func validateHooks(hooks []HookConfig) error {
+	for i, h := range hooks {
+		if !slices.Contains(HookEvents(), h.Event) {
+			return fmt.Errorf("hooks[%d]: event must be one of %v, got: %q", i, HookEvents(), h.Event)
+		}
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("hooks[%d]: %s: command must not be empty", i, h.Event)
+		}
+		if h.TimeoutSeconds < 0 {
+			return fmt.Errorf("hooks[%d]: %s: timeout_seconds must not be negative, got: %d", i, h.Event, h.TimeoutSeconds)
+		}
+	}
+	return nil
+}
+
+// FocusConfig changes what the analysis commands send: /explain the output
+// on screen, /wtf the last failed command, /why the root cause. Unset fields
+// keep the command's own behavior.
+type FocusConfig struct {
+	Explain FocusCommandConfig `json:"explain,omitzero"`
+	Wtf     FocusCommandConfig `json:"wtf,omitzero"`
+	Why     FocusCommandConfig `json:"why,omitzero"`
+}
+
+// FocusCommandConfig tunes one analysis command.
+type FocusCommandConfig struct {
+	// Region is the output sent: FocusRegionRecent, FocusRegionVisible or
+	// FocusRegionLastFailure.
+	Region string `json:"region,omitempty"`
+	// SystemFacts adds facts about the machine (load, memory, disk) to the
+	// request.
+	SystemFacts *bool `json:"system_facts,omitempty"`
+	// ContextLines bounds the terminal lines sent.
+	ContextLines int `json:"context_lines,omitempty"`
+	// Task replaces what the model is asked to do with the output.
+	Task string `json:"task,omitempty"`
+}
+
+// Values accepted for FocusCommandConfig.Region.
+const (
+	FocusRegionRecent      = "recent"       // The last lines of output
+	FocusRegionVisible     = "visible"      // What the terminal shows, scrolled back or not
+	FocusRegionLastFailure = "last_failure" // The output of the last command that failed
+)
+
+// For returns the settings of command, e.g. "/wtf"; zero for other commands.
+func (f FocusConfig) For(command string) FocusCommandConfig {
+	switch command {
+	case "/explain":
+		return f.Explain
+	case "/wtf":
+		return f.Wtf
+	case "/why":
+		return f.Why
+	}
+	return FocusCommandConfig{}
+}
+
+func (f FocusConfig) validate() error {
+	for _, command := range []string{"/explain", "/wtf", "/why"} {
+		c := f.For(command)
+		switch c.Region {
+		case "", FocusRegionRecent, FocusRegionVisible, FocusRegionLastFailure:
+		default:
+			return fmt.Errorf("focus.%s.region must be %q, %q or %q, got: %q", command[1:], FocusRegionRecent, FocusRegionVisible, FocusRegionLastFailure, c.Region)
+		}
+		if c.ContextLines < 0 {
+			return fmt.Errorf("focus.%s.context_lines must not be negative, got: %d", command[1:], c.ContextLines)
+		}
+	}
+	return nil
+}
+
+// PromptsConfig defines system prompt variants for /explain and how they are
+// compared. The built-in prompt is always available as PromptVariantBuiltin.
+type PromptsConfig struct {
+	// Default names the variant used when no experiment runs. Empty means
+	// the built-in prompt.
+	Default string `json:"default"`
+	// Experiment is PromptExperimentOff (always Default),
+	// PromptExperimentAlternate (rotate through the built-in prompt and every
+	// variant on each /explain), or PromptExperimentRandom (pick one at
+	// random per session and keep it).
+	Experiment string          `json:"experiment"`
+	Variants   []PromptVariant `json:"variants"`
+}
+
+// PromptVariant is a named system prompt that replaces the built-in /explain
+// prompt. Tool and language instructions are still appended to it.
+type PromptVariant struct {
+	Name         string `json:"name"`
+	SystemPrompt string `json:"system_prompt"`
+}
+
+// PromptVariantBuiltin names the built-in /explain system prompt.
+const PromptVariantBuiltin = "builtin"
+
+// Values accepted for PromptsConfig.Experiment.
+const (
+	PromptExperimentOff       = "off"
+	PromptExperimentAlternate = "alternate"
+	PromptExperimentRandom    = "random"
+)
+
+// NotificationsConfig routes AI and shell events to notification sinks.
+// Each sink is toggled independently and may restrict itself to a subset of
+// events; an empty Events list means "all events".
+type NotificationsConfig struct {
+	// LongCommandSeconds is the minimum runtime for a shell command to raise
+	// a command_finished event.
+	LongCommandSeconds int                    `json:"long_command_seconds"`
+	StatusBar          NotificationSinkConfig `json:"status_bar"`
+	Bell               NotificationSinkConfig `json:"bell"`
+	Desktop            NotificationSinkConfig `json:"desktop"`
+	Webhook            WebhookSinkConfig      `json:"webhook"`
+}
+
+// NotificationSinkConfig toggles a single notification sink.
+type NotificationSinkConfig struct {
+	Enabled bool     `json:"enabled"`
+	Events  []string `json:"events"`
+}
+
+// WebhookSinkConfig configures the webhook notification sink. Events are
+// POSTed to URL as JSON.
+type WebhookSinkConfig struct {
+	Enabled bool     `json:"enabled"`
+	Events  []string `json:"events"`
+	URL     string   `json:"url"`
+}
+
+// Notification event names accepted in NotificationSinkConfig.Events.
+const (
+	NotifyEventStreamFinished  = "stream_finished"
+	NotifyEventStreamError     = "stream_error"
+	NotifyEventCommandFinished = "command_finished"
+	NotifyEventRateLimited     = "rate_limited"
+	NotifyEventWatchChanged    = "watch_changed"
+	NotifyEventTriggerMatched  = "trigger_matched"
+)
+
+// NotificationEvents returns the list of supported notification event names.
+func NotificationEvents() []string {
+	return []string{
+		NotifyEventStreamFinished,
+		NotifyEventStreamError,
+		NotifyEventCommandFinished,
+		NotifyEventRateLimited,
+		NotifyEventWatchChanged,
+		NotifyEventTriggerMatched,
+	}
+}
+
 const (
 	defaultUpdateCheckIntervalHours = 1
 	defaultAgentMaxIterations       = 100
@@ -143,6 +1088,7 @@ const (
 	defaultReadFileMaxBytes         = 65536
 	defaultListDirectoryMaxEntries  = 500
 	defaultListDirectoryMaxBytes    = 65536
+	defaultLongCommandSeconds       = 30
 )
 
 // Default returns a configuration with default values
@@ -194,6 +1140,11 @@ func Default() Config {
 			Enabled:       true,
 			IntervalHours: defaultUpdateCheckIntervalHours,
 		},
+		Notifications: NotificationsConfig{
+			LongCommandSeconds: defaultLongCommandSeconds,
+			StatusBar:          NotificationSinkConfig{Enabled: true},
+		},
+		ColorMode: ColorModeAuto,
 		LogFile:   defaultLogFilePath(),
 		LogFormat: "text",
 		LogLevel:  "info",
@@ -201,7 +1152,9 @@ func Default() Config {
 }
 
 // Load loads configuration from the specified path
-// If the file doesn't exist, creates one with default values
+// If the file doesn't exist, creates one with default values. WTF_
+// environment variables override fields of the result (see EnvPrefix), and
+// the system policy, if any, is enforced on it.
 func Load(configPath string) (Config, error) {
 	// Ensure directory exists
 	configDir := filepath.Dir(configPath)
@@ -210,7 +1163,7 @@ func Load(configPath string) (Config, error) {
 	}
 
 	// Try to read existing config
-	data, err := os.ReadFile(configPath)
+	data, err := sealed.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Create default config
@@ -218,7 +1171,7 @@ func Load(configPath string) (Config, error) {
 			if err := Save(configPath, cfg); err != nil {
 				return Config{}, fmt.Errorf("failed to create default config: %w", err)
 			}
-			return cfg, nil
+			return applyEnvAndPolicy(cfg)
 		}
 		return Config{}, fmt.Errorf("failed to read config: %w", err)
 	}
@@ -231,17 +1184,27 @@ func Load(configPath string) (Config, error) {
 
 	cfg = applyDefaults(cfg, data)
 
-	return cfg, nil
+	return applyEnvAndPolicy(cfg)
 }
 
-// Save saves the configuration to the specified path
+func applyEnvAndPolicy(cfg Config) (Config, error) {
+	cfg, err := applyEnv(cfg, os.Environ())
+	if err != nil {
+		return Config{}, fmt.Errorf("environment override %w", err)
+	}
+	return ApplyPolicy(cfg)
+}
+
+// Save saves the configuration to the specified path, encrypted when
+// sealed.Default is unlocked. Fields overridden from the environment keep
+// their values from the file.
 func Save(configPath string, cfg Config) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(cfg.withoutEnv(), "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := sealed.WriteFile(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
@@ -269,6 +1232,9 @@ func (c Config) Validate() error {
 	if !IsValidProvider(c.LLMProvider) {
 		return fmt.Errorf("unsupported LLM provider: %s (supported: %v)", c.LLMProvider, SupportedProviders())
 	}
+	if !c.Policy.AllowsProvider(c.LLMProvider) {
+		return fmt.Errorf("LLM provider %s is not allowed by your organization (allowed: %v)", c.LLMProvider, c.Policy.AllowedProviders)
+	}
 
 	// Validate provider-specific config based on selected provider
 	switch c.LLMProvider {
@@ -305,6 +1271,49 @@ func (c Config) Validate() error {
 		return fmt.Errorf("update_check.interval_hours must be positive, got: %d", c.UpdateCheck.IntervalHours)
 	}
 
+	if err := c.Notifications.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Prompts.validate(); err != nil {
+		return err
+	}
+
+	if err := c.LocalModels.validate(); err != nil {
+		return err
+	}
+
+	if err := validateAliases(c.Aliases); err != nil {
+		return err
+	}
+	if err := validateHooks(c.Hooks); err != nil {
+		return err
+	}
+	if err := c.Focus.validate(); err != nil {
+		return err
+	}
+	if err := c.Pickers.validate(); err != nil {
+		return err
+	}
+	if err := c.AutoContext.validate(); err != nil {
+		return err
+	}
+	if err := c.Layout.validate(); err != nil {
+		return err
+	}
+	if err := c.ConfirmCommands.validate(); err != nil {
+		return err
+	}
+	if err := c.SyntaxCheck.validate(); err != nil {
+		return err
+	}
+	if err := c.WorkspaceIndex.validate(); err != nil {
+		return err
+	}
+	if err := c.Recap.validate(); err != nil {
+		return err
+	}
+
 	if v := strings.TrimSpace(c.Agent.Tools.OutOfWorkdirAccess); v != "" {
 		switch v {
 		case WorkdirAccessAsk, WorkdirAccessDeny:
@@ -321,6 +1330,20 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if strings.TrimSpace(c.ColorMode) != "" {
+		switch strings.ToLower(strings.TrimSpace(c.ColorMode)) {
+		case ColorModeAuto, ColorModeTrueColor, ColorMode256, ColorMode16, ColorModeNone:
+		default:
+			return fmt.Errorf("color_mode must be one of %s, %s, %s, %s, %s, got: %s", ColorModeAuto, ColorModeTrueColor, ColorMode256, ColorMode16, ColorModeNone, c.ColorMode)
+		}
+	}
+
+	switch strings.TrimSpace(c.Prewarm) {
+	case "", PrewarmOff, PrewarmStartup, PrewarmSidebar:
+	default:
+		return fmt.Errorf("prewarm must be one of %s, %s, %s, got: %s", PrewarmOff, PrewarmStartup, PrewarmSidebar, c.Prewarm)
+	}
+
 	if strings.TrimSpace(c.LogFormat) != "" {
 		switch strings.ToLower(strings.TrimSpace(c.LogFormat)) {
 		case "json", "text":
@@ -329,6 +1352,105 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if err := c.LogRotation.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Budgets.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Sync.validate(); err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(c.ResponseLanguage, "\r\n") {
+		return fmt.Errorf("response_language must be a single line, got: %q", c.ResponseLanguage)
+	}
+
+	return nil
+}
+
+func (p PromptsConfig) validate() error {
+	switch strings.TrimSpace(p.Experiment) {
+	case "", PromptExperimentOff, PromptExperimentAlternate, PromptExperimentRandom:
+	default:
+		return fmt.Errorf("prompts.experiment must be %q, %q or %q, got: %s", PromptExperimentOff, PromptExperimentAlternate, PromptExperimentRandom, p.Experiment)
+	}
+	seen := map[string]bool{PromptVariantBuiltin: true}
+	for i, v := range p.Variants {
+		name := strings.TrimSpace(v.Name)
+		if name == "" {
+			return fmt.Errorf("prompts.variants[%d].name must not be empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("prompts.variants[%d].name %q is already used", i, name)
+		}
+		seen[name] = true
+		if strings.TrimSpace(v.SystemPrompt) == "" {
+			return fmt.Errorf("prompts.variants[%d].system_prompt must not be empty", i)
+		}
+	}
+	if d := strings.TrimSpace(p.Default); d != "" && !seen[d] {
+		return fmt.Errorf("prompts.default: unknown variant %q", p.Default)
+	}
+	return nil
+}
+
+func (n NotificationsConfig) validate() error {
+	if n.LongCommandSeconds < 0 {
+		return fmt.Errorf("notifications.long_command_seconds must not be negative, got: %d", n.LongCommandSeconds)
+	}
+	sinks := []struct {
+		name   string
+		events []string
+	}{
+		{"status_bar", n.StatusBar.Events},
+		{"bell", n.Bell.Events},
+		{"desktop", n.Desktop.Events},
+		{"webhook", n.Webhook.Events},
+	}
+	for _, sink := range sinks {
+		for _, event := range sink.events {
+			if !isNotificationEvent(event) {
+				return fmt.Errorf("notifications.%s.events: unknown event %q (supported: %v)", sink.name, event, NotificationEvents())
+			}
+		}
+	}
+	if n.Webhook.Enabled {
+		parsedURL, err := url.Parse(strings.TrimSpace(n.Webhook.URL))
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			return fmt.Errorf("notifications.webhook.url must be a valid http(s) URL, got: %s", n.Webhook.URL)
+		}
+	}
+	return nil
+}
+
+func isNotificationEvent(event string) bool {
+	for _, e := range NotificationEvents() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (o OpenRouterConfig) validateRouting() error {
+	for i, name := range o.Provider.Order {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("openrouter.provider.order[%d] must not be empty", i)
+		}
+	}
+	switch o.Provider.DataCollection {
+	case "", OpenRouterDataCollectionAllow, OpenRouterDataCollectionDeny:
+	default:
+		return fmt.Errorf("openrouter.provider.data_collection must be %q or %q, got: %s", OpenRouterDataCollectionAllow, OpenRouterDataCollectionDeny, o.Provider.DataCollection)
+	}
+	switch o.Route {
+	case "", OpenRouterRouteFallback:
+	default:
+		return fmt.Errorf("openrouter.route must be %q, got: %s", OpenRouterRouteFallback, o.Route)
+	}
 	return nil
 }
 
@@ -362,7 +1484,17 @@ func (c Config) validateOpenRouter() error {
 		return fmt.Errorf("api_timeout_seconds must be positive, got: %d", c.OpenRouter.APITimeoutSeconds)
 	}
 
-	return nil
+	if err := c.OpenRouter.validateRouting(); err != nil {
+		return err
+	}
+
+	if err := c.OpenRouter.ClientCert.validate("openrouter.client_cert"); err != nil {
+		return err
+	}
+	if err := c.OpenRouter.Proxy.validate("openrouter.proxy"); err != nil {
+		return err
+	}
+	return c.OpenRouter.RequestOverrides.validate("openrouter.request_overrides")
 }
 
 func (c Config) validateOpenAI() error {
@@ -370,21 +1502,39 @@ func (c Config) validateOpenAI() error {
 	if strings.TrimSpace(c.Providers.OpenAI.Model) == "" {
 		// Will use default model
 	}
-	return nil
+	if err := c.Providers.OpenAI.ClientCert.validate("providers.openai.client_cert"); err != nil {
+		return err
+	}
+	if err := c.Providers.OpenAI.Proxy.validate("providers.openai.proxy"); err != nil {
+		return err
+	}
+	return c.Providers.OpenAI.RequestOverrides.validate("providers.openai.request_overrides")
 }
 
 func (c Config) validateAnthropic() error {
 	if c.Providers.Anthropic.APIKey == "" {
 		return fmt.Errorf("Anthropic API key is required (set in config file)")
 	}
-	return nil
+	if err := c.Providers.Anthropic.ClientCert.validate("providers.anthropic.client_cert"); err != nil {
+		return err
+	}
+	if err := c.Providers.Anthropic.Proxy.validate("providers.anthropic.proxy"); err != nil {
+		return err
+	}
+	return c.Providers.Anthropic.RequestOverrides.validate("providers.anthropic.request_overrides")
 }
 
 func (c Config) validateGoogle() error {
 	if strings.TrimSpace(c.Providers.Google.APIKey) == "" {
 		return fmt.Errorf("Google API key is required (set in config file)")
 	}
-	return nil
+	if err := c.Providers.Google.ClientCert.validate("providers.google.client_cert"); err != nil {
+		return err
+	}
+	if err := c.Providers.Google.Proxy.validate("providers.google.proxy"); err != nil {
+		return err
+	}
+	return c.Providers.Google.RequestOverrides.validate("providers.google.request_overrides")
 }
 
 // agentToolsPresence mirrors AgentTools, tracking which fields were present
@@ -441,6 +1591,13 @@ type configPresence struct {
 		Enabled       *bool `json:"enabled"`
 		IntervalHours *int  `json:"interval_hours"`
 	} `json:"update_check"`
+	Notifications *struct {
+		LongCommandSeconds *int `json:"long_command_seconds"`
+		StatusBar          *struct {
+			Enabled *bool `json:"enabled"`
+		} `json:"status_bar"`
+	} `json:"notifications"`
+	ColorMode *string `json:"color_mode"`
 	LogFile   *string `json:"log_file"`
 	LogFormat *string `json:"log_format"`
 	LogLevel  *string `json:"log_level"`
@@ -538,6 +1695,21 @@ func applyDefaults(cfg Config, data []byte) Config {
 		}
 	}
 
+	if presence.Notifications == nil {
+		cfg.Notifications = defaults.Notifications
+	} else {
+		if presence.Notifications.LongCommandSeconds == nil {
+			cfg.Notifications.LongCommandSeconds = defaults.Notifications.LongCommandSeconds
+		}
+		if presence.Notifications.StatusBar == nil || presence.Notifications.StatusBar.Enabled == nil {
+			cfg.Notifications.StatusBar.Enabled = defaults.Notifications.StatusBar.Enabled
+		}
+	}
+
+	if presence.ColorMode == nil || strings.TrimSpace(cfg.ColorMode) == "" {
+		cfg.ColorMode = defaults.ColorMode
+	}
+
 	if presence.LogFile == nil || strings.TrimSpace(cfg.LogFile) == "" {
 		cfg.LogFile = defaults.LogFile
 	}
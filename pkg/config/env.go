@@ -0,0 +1,177 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the names of the environment variables that override
+// config fields: the field's dotted path in upper case with dots turned to
+// underscores, e.g. WTF_LLM_PROVIDER or WTF_PROVIDERS_OPENAI_API_KEY.
+// Overrides apply over the config file and the defaults; the organization
+// policy still applies over them.
+const EnvPrefix = "WTF_"
+
+// EnvOverride is a config field set from the environment.
+type EnvOverride struct {
+	Path string // Dotted path, e.g. "openrouter.api_key"
+	Var  string // e.g. "WTF_OPENROUTER_API_KEY"
+
+	// prior is the value from the file, which Save writes back so the
+	// environment never ends up in the config file.
+	prior reflect.Value
+}
+
+// EnvOverrides are the fields Load set from the environment.
+type EnvOverrides []EnvOverride
+
+// Var returns the variable that set the field at the dotted path, or ""
+// when the environment does not override it.
+func (e EnvOverrides) Var(path string) string {
+	for _, o := range e {
+		if o.Path == path {
+			return o.Var
+		}
+	}
+	return ""
+}
+
+// EnvVar returns the name of the variable that overrides the field at the
+// dotted path.
+func EnvVar(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// EnvPaths returns the dotted path of every field an environment variable
+// can override, sorted.
+func EnvPaths() []string {
+	var paths []string
+	walkConfigFields(reflect.ValueOf(&Config{}).Elem(), "", func(path string, _ reflect.Value) {
+		paths = append(paths, path)
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// applyEnv sets the fields named by the WTF_ variables in environ. Other
+// WTF_ variables, such as WTF_SYNC_KEY, are left alone.
+func applyEnv(cfg Config, environ []string) (Config, error) {
+	vars := make(map[string]string)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(name, EnvPrefix) {
+			vars[name] = value
+		}
+	}
+	if len(vars) == 0 {
+		return cfg, nil
+	}
+
+	var err error
+	var overrides EnvOverrides
+	walkConfigFields(reflect.ValueOf(&cfg).Elem(), "", func(path string, field reflect.Value) {
+		name := EnvVar(path)
+		value, ok := vars[name]
+		if !ok || err != nil {
+			return
+		}
+		prior := reflect.New(field.Type()).Elem()
+		prior.Set(field)
+		if setErr := setFromEnv(field, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", name, setErr)
+			return
+		}
+		overrides = append(overrides, EnvOverride{Path: path, Var: name, prior: prior})
+	})
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Env = overrides
+	return cfg, nil
+}
+
+// withoutEnv returns cfg with the overridden fields set back to their
+// values from the file.
+func (c Config) withoutEnv() Config {
+	if len(c.Env) == 0 {
+		return c
+	}
+	restore := make(map[string]reflect.Value, len(c.Env))
+	for _, o := range c.Env {
+		restore[o.Path] = o.prior
+	}
+	walkConfigFields(reflect.ValueOf(&c).Elem(), "", func(path string, field reflect.Value) {
+		if prior, ok := restore[path]; ok {
+			field.Set(prior)
+		}
+	})
+	c.Env = nil
+	return c
+}
+
+// walkConfigFields calls fn with each settable leaf field of v and its
+// dotted JSON path. Structs are walked into; everything else, maps and
+// lists included, is a leaf.
+func walkConfigFields(v reflect.Value, prefix string, fn func(path string, field reflect.Value)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "-" || name == "" {
+			continue
+		}
+		path := prefix + name
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkConfigFields(field, path+".", fn)
+			continue
+		}
+		fn(path, field)
+	}
+}
+
+// setFromEnv parses value into field: strings as they are, numbers and
+// booleans in Go syntax, and anything else as JSON.
+func setFromEnv(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFromEnv(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a whole number, got %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", value)
+		}
+		field.SetFloat(f)
+	default:
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(value), target.Interface()); err != nil {
+			return fmt.Errorf("expected JSON: %w", err)
+		}
+		field.Set(target.Elem())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	usePolicy(t, `{"settings": {"log_level": "warn"}}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := Default()
+	cfg.OpenRouter.APIKey = "from-file"
+	if err := Save(configPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("WTF_LLM_PROVIDER", "anthropic")
+	t.Setenv("WTF_OPENROUTER_API_KEY", "from-env")
+	t.Setenv("WTF_PROVIDERS_ANTHROPIC_TEMPERATURE", "0.2")
+	t.Setenv("WTF_LAYOUT_OVERLAY_BELOW", "60")
+	t.Setenv("WTF_OPENROUTER_PROVIDER_ALLOW_FALLBACKS", "false")
+	t.Setenv("WTF_ALIASES", `[{"name": "/w", "command": "/watch"}]`)
+	t.Setenv("WTF_LOG_LEVEL", "debug")
+	t.Setenv("WTF_SYNC_KEY", "not a config field")
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.LLMProvider != "anthropic" || cfg.OpenRouter.APIKey != "from-env" || cfg.Providers.Anthropic.Temperature != 0.2 ||
+		cfg.Layout.OverlayBelow != 60 || len(cfg.Aliases) != 1 || cfg.Aliases[0].Name != "/w" {
+		t.Fatalf("expected the environment to override the file, got %+v", cfg)
+	}
+	if af := cfg.OpenRouter.Provider.AllowFallbacks; af == nil || *af {
+		t.Fatalf("expected allow_fallbacks false, got %v", af)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Fatalf("expected the policy over the environment, got log_level %q", cfg.LogLevel)
+	}
+	if got := cfg.Env.Var("openrouter.api_key"); got != "WTF_OPENROUTER_API_KEY" {
+		t.Fatalf("Env.Var() = %q", got)
+	}
+
+	// Saving keeps the file's values for overridden fields.
+	cfg.BufferSize = 4000
+	if err := Save(configPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "from-env") || !strings.Contains(string(data), "from-file") ||
+		!strings.Contains(string(data), `"buffer_size": 4000`) {
+		t.Fatalf("expected environment values left out of the file:\n%s", data)
+	}
+}
+
+func TestLoad_EnvOverrideErrors(t *testing.T) {
+	usePolicy(t, `{}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("WTF_BUFFER_SIZE", "lots")
+	_, err := Load(configPath)
+	if err == nil || !strings.Contains(err.Error(), "WTF_BUFFER_SIZE: expected a whole number") {
+		t.Fatalf("expected an error naming the variable, got %v", err)
+	}
+}
+
+func TestEnvPaths(t *testing.T) {
+	paths := EnvPaths()
+	for _, want := range []string{"llm_provider", "openrouter.api_key", "providers.copilot.session_idle_seconds", "agent.tools.read_file.max_lines"} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("expected %s in EnvPaths()", want)
+		}
+	}
+	if EnvVar("providers.openai.api_key") != "WTF_PROVIDERS_OPENAI_API_KEY" {
+		t.Fatalf("EnvVar() = %q", EnvVar("providers.openai.api_key"))
+	}
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// legacyAliases maps flat fields of the legacy ~/.wtf/config.json, which only
+// knew OpenRouter, to their place in the current schema. Any other field is
+// kept when the same dotted path exists here and reported otherwise.
+var legacyAliases = map[string]string{
+	"api_key":             "openrouter.api_key",
+	"api_url":             "openrouter.api_url",
+	"http_referer":        "openrouter.http_referer",
+	"x_title":             "openrouter.x_title",
+	"model":               "openrouter.model",
+	"temperature":         "openrouter.temperature",
+	"max_tokens":          "openrouter.max_tokens",
+	"api_timeout_seconds": "openrouter.api_timeout_seconds",
+	"api_timeout":         "openrouter.api_timeout_seconds",
+	"provider":            "llm_provider",
+}
+
+// FieldMapping records where a legacy field ended up.
+type FieldMapping struct {
+	From string
+	To   string
+}
+
+// UnmappedField is a legacy field that has no home in the current schema.
+type UnmappedField struct {
+	Name   string
+	Reason string
+}
+
+// Migration is the result of translating a legacy config.
+type Migration struct {
+	Config   Config
+	Mapped   []FieldMapping
+	Unmapped []UnmappedField
+}
+
+// LegacyConfigPath returns where releases before ~/.wtf_cli kept their
+// settings.
+func LegacyConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf", "config.json")
+	}
+	return filepath.Join(homeDir, ".wtf", "config.json")
+}
+
+// NeedsLegacyMigration reports whether a legacy config exists and the
+// current one does not, i.e. Load would otherwise write defaults and the
+// user's API key would be lost.
+func NeedsLegacyMigration(legacyPath, configPath string) bool {
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		return false
+	}
+	info, err := os.Stat(legacyPath)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// MigrateLegacy translates a legacy config file's contents onto the defaults.
+func MigrateLegacy(data []byte) (Migration, error) {
+	var legacy map[string]any
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return Migration{}, fmt.Errorf("failed to parse legacy config: %w", err)
+	}
+
+	defaultData, err := json.Marshal(Default())
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to marshal defaults: %w", err)
+	}
+	var target map[string]any
+	if err := json.Unmarshal(defaultData, &target); err != nil {
+		return Migration{}, fmt.Errorf("failed to decode defaults: %w", err)
+	}
+
+	fields := map[string]any{}
+	flattenLegacy("", legacy, fields)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var m Migration
+	for _, name := range names {
+		value := fields[name]
+		to := legacyFieldPath(name)
+		current, ok := lookupPath(target, to)
+		if !ok {
+			m.Unmapped = append(m.Unmapped, UnmappedField{Name: name, Reason: "no equivalent setting"})
+			continue
+		}
+		if !sameJSONKind(current, value) {
+			m.Unmapped = append(m.Unmapped, UnmappedField{
+				Name:   name,
+				Reason: fmt.Sprintf("expected %s for %s, got %s", jsonKind(current), to, jsonKind(value)),
+			})
+			continue
+		}
+		setPath(target, to, value)
+		m.Mapped = append(m.Mapped, FieldMapping{From: name, To: to})
+	}
+
+	migrated, err := json.Marshal(target)
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return Migration{}, fmt.Errorf("failed to decode migrated config: %w", err)
+	}
+	m.Config = applyDefaults(cfg, migrated)
+	return m, nil
+}
+
+// WriteMigration saves m to configPath after backing up the legacy file and
+// any existing config. It returns the backup paths.
+func WriteMigration(legacyPath, configPath string, m Migration) ([]string, error) {
+	var backups []string
+	for _, path := range []string{legacyPath, configPath} {
+		backup, err := backupFile(path)
+		if err != nil {
+			return backups, err
+		}
+		if backup != "" {
+			backups = append(backups, backup)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return backups, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return backups, Save(configPath, m.Config)
+}
+
+// backupFile copies path next to itself with a timestamp suffix. A missing
+// file is not an error and yields no backup.
+func backupFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s for backup: %w", path, err)
+	}
+	backup := path + ".bak-" + time.Now().Format("20060102-150405")
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return backup, nil
+}
+
+// flattenLegacy records every leaf of obj under its dotted, snake_case path.
+func flattenLegacy(prefix string, obj map[string]any, out map[string]any) {
+	for key, value := range obj {
+		name := snakeCase(key)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenLegacy(name, nested, out)
+			continue
+		}
+		out[name] = value
+	}
+}
+
+func legacyFieldPath(name string) string {
+	if to, ok := legacyAliases[name]; ok {
+		return to
+	}
+	return name
+}
+
+// snakeCase turns Go field names from configs written without JSON tags
+// (APIKey, DryRun) into the schema's spelling (api_key, dry_run).
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		} else if r == '-' {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lookupPath(obj map[string]any, path string) (any, bool) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, ok := obj[part]
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		if obj, ok = value.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func setPath(obj map[string]any, path string, value any) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		obj = obj[part].(map[string]any)
+	}
+	obj[parts[len(parts)-1]] = value
+}
+
+func sameJSONKind(current, value any) bool {
+	return current == nil || jsonKind(current) == jsonKind(value)
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
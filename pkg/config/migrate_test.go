@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateLegacy(t *testing.T) {
+	legacy := `{
+		"APIKey": "sk-or-legacy",
+		"Model": "anthropic/claude-3.5-sonnet",
+		"temperature": "warm",
+		"DryRun": true,
+		"log_level": "debug",
+		"openrouter": {"api_url": "https://gateway.example/v1"}
+	}`
+
+	m, err := MigrateLegacy([]byte(legacy))
+	if err != nil {
+		t.Fatalf("MigrateLegacy() error: %v", err)
+	}
+
+	if m.Config.OpenRouter.APIKey != "sk-or-legacy" {
+		t.Errorf("APIKey = %q, want sk-or-legacy", m.Config.OpenRouter.APIKey)
+	}
+	if m.Config.OpenRouter.Model != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("Model = %q", m.Config.OpenRouter.Model)
+	}
+	if m.Config.OpenRouter.APIURL != "https://gateway.example/v1" {
+		t.Errorf("APIURL = %q", m.Config.OpenRouter.APIURL)
+	}
+	if m.Config.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want debug", m.Config.LogLevel)
+	}
+	if m.Config.OpenRouter.Temperature != Default().OpenRouter.Temperature {
+		t.Errorf("Temperature = %v, want the default", m.Config.OpenRouter.Temperature)
+	}
+	if m.Config.BufferSize != Default().BufferSize {
+		t.Errorf("BufferSize = %d, want the default", m.Config.BufferSize)
+	}
+
+	wantMapped := map[string]string{
+		"api_key":            "openrouter.api_key",
+		"model":              "openrouter.model",
+		"log_level":          "log_level",
+		"openrouter.api_url": "openrouter.api_url",
+	}
+	if len(m.Mapped) != len(wantMapped) {
+		t.Fatalf("Mapped = %+v", m.Mapped)
+	}
+	for _, f := range m.Mapped {
+		if wantMapped[f.From] != f.To {
+			t.Errorf("mapped %s -> %s, want %s", f.From, f.To, wantMapped[f.From])
+		}
+	}
+
+	unmapped := map[string]string{}
+	for _, f := range m.Unmapped {
+		unmapped[f.Name] = f.Reason
+	}
+	if _, ok := unmapped["dry_run"]; !ok || len(unmapped) != 2 {
+		t.Fatalf("Unmapped = %+v, want dry_run and temperature", m.Unmapped)
+	}
+	if !strings.Contains(unmapped["temperature"], "expected number") {
+		t.Errorf("temperature reason = %q", unmapped["temperature"])
+	}
+}
+
+func TestMigrateLegacy_InvalidJSON(t *testing.T) {
+	if _, err := MigrateLegacy([]byte("{not json")); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
+
+func TestWriteMigration_BacksUpAndSaves(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, ".wtf", "config.json")
+	configPath := filepath.Join(dir, ".wtf_cli", "config.json")
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	legacy := []byte(`{"api_key": "sk-or-legacy"}`)
+	if err := os.WriteFile(legacyPath, legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if !NeedsLegacyMigration(legacyPath, configPath) {
+		t.Fatal("expected migration to be needed without a current config")
+	}
+
+	m, err := MigrateLegacy(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, err := WriteMigration(legacyPath, configPath, m)
+	if err != nil {
+		t.Fatalf("WriteMigration() error: %v", err)
+	}
+	if len(backups) != 1 || !strings.HasPrefix(backups[0], legacyPath+".bak-") {
+		t.Fatalf("backups = %v", backups)
+	}
+	if data, err := os.ReadFile(backups[0]); err != nil || string(data) != string(legacy) {
+		t.Fatalf("backup content = %q, %v", data, err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OpenRouter.APIKey != "sk-or-legacy" {
+		t.Errorf("saved APIKey = %q", cfg.OpenRouter.APIKey)
+	}
+	if NeedsLegacyMigration(legacyPath, configPath) {
+		t.Error("expected no migration once the current config exists")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"APIKey":            "api_key",
+		"DryRun":            "dry_run",
+		"APITimeoutSeconds": "api_timeout_seconds",
+		"log_level":         "log_level",
+		"HTTPReferer":       "http_referer",
+		"max-tokens":        "max_tokens",
+	}
+	for in, want := range tests {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultPolicyPath is where administrators install the organization policy.
+const DefaultPolicyPath = "/etc/wtf_cli/policy.json"
+
+// policyPath is read by Load; tests point it elsewhere.
+var policyPath = DefaultPolicyPath
+
+// Policy is a read-only file managed by an organization that locks settings
+// users would otherwise change in their own config.
+type Policy struct {
+	// AllowedProviders limits llm_provider; empty allows every provider.
+	AllowedProviders []string `json:"allowed_providers,omitempty"`
+	// ForceRedaction masks credentials in everything sent to a provider.
+	ForceRedaction bool `json:"force_redaction,omitempty"`
+	// DisabledCommands are palette commands users cannot run, e.g. "/watch".
+	DisabledCommands []string `json:"disabled_commands,omitempty"`
+	// Settings are config values that replace the user's, keyed by dotted
+	// path, e.g. "update_check.enabled" or "notifications.webhook.url".
+	Settings map[string]json.RawMessage `json:"settings,omitempty"`
+}
+
+// IsZero reports whether the policy locks nothing.
+func (p Policy) IsZero() bool {
+	return len(p.AllowedProviders) == 0 && !p.ForceRedaction && len(p.DisabledCommands) == 0 && len(p.Settings) == 0
+}
+
+// AllowsProvider reports whether provider may be used.
+func (p Policy) AllowsProvider(provider string) bool {
+	return len(p.AllowedProviders) == 0 || slices.Contains(p.AllowedProviders, provider)
+}
+
+// Providers returns the providers that may be used.
+func (p Policy) Providers() []string {
+	if len(p.AllowedProviders) == 0 {
+		return SupportedProviders()
+	}
+	return slices.Clone(p.AllowedProviders)
+}
+
+// DisablesCommand reports whether the palette command name is disabled.
+func (p Policy) DisablesCommand(name string) bool {
+	return slices.Contains(p.DisabledCommands, name)
+}
+
+// Locks reports whether the setting at the dotted path is set by the
+// policy. llm_provider is also locked when only one provider is allowed.
+func (p Policy) Locks(path string) bool {
+	if path == "llm_provider" && len(p.AllowedProviders) == 1 {
+		return true
+	}
+	_, ok := p.Settings[path]
+	return ok
+}
+
+// LoadPolicy reads the policy at path. A missing file is an empty policy.
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Policy{}, nil
+		}
+		return Policy{}, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	for _, provider := range p.AllowedProviders {
+		if !IsValidProvider(provider) {
+			return Policy{}, fmt.Errorf("policy %s: unsupported provider in allowed_providers: %s", path, provider)
+		}
+	}
+	for _, name := range p.DisabledCommands {
+		if !strings.HasPrefix(name, "/") {
+			return Policy{}, fmt.Errorf("policy %s: disabled command must start with /: %s", path, name)
+		}
+	}
+	return p, nil
+}
+
+// ApplyPolicy enforces the system policy on cfg, as Load does.
+func ApplyPolicy(cfg Config) (Config, error) {
+	p, err := LoadPolicy(policyPath)
+	if err != nil {
+		return Config{}, err
+	}
+	return p.Apply(cfg)
+}
+
+// Apply returns cfg with the locked settings replaced and the provider
+// switched to an allowed one. The policy is kept in Config.Policy.
+func (p Policy) Apply(cfg Config) (Config, error) {
+	if p.IsZero() {
+		cfg.Policy = p
+		return cfg, nil
+	}
+
+	if len(p.Settings) > 0 {
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		var target map[string]any
+		if err := json.Unmarshal(data, &target); err != nil {
+			return Config{}, fmt.Errorf("failed to decode config: %w", err)
+		}
+		paths := make([]string, 0, len(p.Settings))
+		for path := range p.Settings {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			var value any
+			if err := json.Unmarshal(p.Settings[path], &value); err != nil {
+				return Config{}, fmt.Errorf("policy setting %s: %w", path, err)
+			}
+			current, ok := lookupPath(target, path)
+			if !ok {
+				return Config{}, fmt.Errorf("policy setting %s: no such setting", path)
+			}
+			if !sameJSONKind(current, value) {
+				return Config{}, fmt.Errorf("policy setting %s: expected %s, got %s", path, jsonKind(current), jsonKind(value))
+			}
+			setPath(target, path, value)
+		}
+		if data, err = json.Marshal(target); err != nil {
+			return Config{}, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		locked := Config{Env: cfg.Env}
+		if err := json.Unmarshal(data, &locked); err != nil {
+			return Config{}, fmt.Errorf("failed to decode config: %w", err)
+		}
+		cfg = locked
+	}
+
+	if !p.AllowsProvider(cfg.LLMProvider) {
+		cfg.LLMProvider = p.AllowedProviders[0]
+	}
+	cfg.Policy = p
+	return cfg, nil
+}
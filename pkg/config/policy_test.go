@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func usePolicy(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := policyPath
+	policyPath = path
+	t.Cleanup(func() { policyPath = old })
+}
+
+func TestLoad_EnforcesPolicy(t *testing.T) {
+	usePolicy(t, `{
+		"allowed_providers": ["anthropic", "openai"],
+		"force_redaction": true,
+		"disabled_commands": ["/watch"],
+		"settings": {"update_check.enabled": false, "notifications.webhook.url": "https://hooks.example.com/wtf"}
+	}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := Default()
+	cfg.LLMProvider = "openrouter"
+	cfg.UpdateCheck.Enabled = true
+	if err := Save(configPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.LLMProvider != "anthropic" {
+		t.Errorf("Expected provider switched to the first allowed one, got %q", cfg.LLMProvider)
+	}
+	if cfg.UpdateCheck.Enabled {
+		t.Error("Expected update_check.enabled locked to false")
+	}
+	if cfg.Notifications.Webhook.URL != "https://hooks.example.com/wtf" {
+		t.Errorf("Expected locked webhook URL, got %q", cfg.Notifications.Webhook.URL)
+	}
+	if !cfg.Policy.ForceRedaction || !cfg.Policy.DisablesCommand("/watch") {
+		t.Errorf("Expected the policy to be attached, got %+v", cfg.Policy)
+	}
+	if !cfg.Policy.Locks("update_check.enabled") || cfg.Policy.Locks("buffer_size") {
+		t.Error("Locks reports the wrong settings")
+	}
+
+	cfg.LLMProvider = "openrouter"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "not allowed by your organization") {
+		t.Errorf("Expected a disallowed provider to fail validation, got %v", err)
+	}
+}
+
+func TestLoad_PolicyIsNotSaved(t *testing.T) {
+	usePolicy(t, `{"settings": {"buffer_size": 1000}}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.BufferSize != 1000 {
+		t.Fatalf("Expected locked buffer_size 1000, got %d", cfg.BufferSize)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved["Policy"]; ok {
+		t.Error("Expected the policy not to be written to the user's config")
+	}
+}
+
+func TestPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{name: "bad json", policy: `{`, wantErr: "failed to parse policy"},
+		{name: "unknown provider", policy: `{"allowed_providers": ["acme"]}`, wantErr: "unsupported provider"},
+		{name: "command without slash", policy: `{"disabled_commands": ["watch"]}`, wantErr: "must start with /"},
+		{name: "unknown setting", policy: `{"settings": {"no.such.key": 1}}`, wantErr: "no such setting"},
+		{name: "wrong type", policy: `{"settings": {"buffer_size": "big"}}`, wantErr: "expected number, got string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePolicy(t, tt.policy)
+			_, err := Load(filepath.Join(t.TempDir(), "config.json"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy_MissingFile(t *testing.T) {
+	p, err := LoadPolicy(filepath.Join(t.TempDir(), "policy.json"))
+	if err != nil || !p.IsZero() {
+		t.Fatalf("Expected an empty policy, got %+v, %v", p, err)
+	}
+	if !p.AllowsProvider("copilot") || len(p.Providers()) != len(SupportedProviders()) {
+		t.Error("Expected an empty policy to allow every provider")
+	}
+}
@@ -0,0 +1,354 @@
+// Package control exposes a running session to local automation over a
+// Unix socket: editor integrations and scripts can type into the shell,
+// read the terminal, ask for an explanation, read the answer and post
+// status bar messages.
+//
+// The protocol is JSON-RPC 2.0 with one request or response per line.
+// Requests are answered in order per connection. The session handles each
+// call on its UI goroutine; this package only moves calls and replies.
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"wtf_cli/pkg/unixsock"
+)
+
+// Methods a session answers.
+const (
+	MethodInput      = "input"       // Params: InputParams; result: OK
+	MethodBufferTail = "buffer_tail" // Params: BufferTailParams; result: BufferTailResult
+	MethodExplain    = "explain"     // No params; result: OK
+	MethodLastAnswer = "last_answer" // No params; result: LastAnswerResult
+	MethodStatus     = "status"      // Params: StatusParams; result: OK
+)
+
+// JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000
+)
+
+// callTimeout bounds how long a client waits for the session to answer,
+// e.g. while it is suspended.
+const callTimeout = 10 * time.Second
+
+// InputParams is the text typed into the shell; Enter adds a carriage
+// return so the line runs.
+type InputParams struct {
+	Text  string `json:"text"`
+	Enter bool   `json:"enter,omitempty"`
+}
+
+// BufferTailParams asks for the last Lines lines of terminal output.
+type BufferTailParams struct {
+	Lines int `json:"lines"`
+}
+
+// BufferTailResult holds terminal lines, oldest first, without escape
+// sequences.
+type BufferTailResult struct {
+	Lines []string `json:"lines"`
+}
+
+// LastAnswerResult is the latest AI answer of the conversation. Streaming
+// is set while it is still being written.
+type LastAnswerResult struct {
+	Content   string `json:"content"`
+	Streaming bool   `json:"streaming"`
+}
+
+// StatusParams posts a status bar message. Source identifies the poster:
+// its next message replaces this one and empty Text clears it. Priority is
+// "low", "normal" (default) or "high"; TTLSeconds removes the message after
+// that many seconds, zero keeps it until replaced.
+type StatusParams struct {
+	Source     string `json:"source"`
+	Text       string `json:"text"`
+	Priority   string `json:"priority,omitempty"`
+	TTLSeconds int    `json:"ttl_seconds,omitempty"`
+}
+
+// OK is the result of calls that return nothing else.
+type OK struct {
+	OK bool `json:"ok"`
+}
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// Errorf returns an error reported to the client with code.
+func Errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Call is a request waiting for the session to answer it with Reply.
+type Call struct {
+	Method string
+	Params json.RawMessage
+
+	reply chan response
+}
+
+// Decode unmarshals the call's params into v. Missing params leave v
+// unchanged.
+func (c *Call) Decode(v any) *Error {
+	if len(c.Params) == 0 || string(c.Params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(c.Params, v); err != nil {
+		return Errorf(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
+
+// Reply answers the call with result, or with err when it is not nil.
+// Only the first reply counts.
+func (c *Call) Reply(result any, err *Error) {
+	resp := response{Result: result}
+	if err != nil {
+		resp = response{Error: err}
+	}
+	select {
+	case c.reply <- resp:
+	default:
+	}
+}
+
+// Server accepts automation clients on a Unix socket.
+type Server struct {
+	path  string
+	ln    net.Listener
+	calls chan *Call
+	done  chan struct{}
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// Listen creates the socket at path, usable only by the current user; see
+// unixsock.Listen for what happens when path already exists.
+func Listen(path string) (*Server, error) {
+	ln, err := unixsock.Listen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		path:  path,
+		ln:    ln,
+		calls: make(chan *Call),
+		done:  make(chan struct{}),
+		conns: make(map[net.Conn]struct{}),
+	}
+	go s.acceptLoop()
+	slog.Info("control_listen", "socket", path)
+	return s, nil
+}
+
+// Path returns the socket path.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Next blocks until a client calls a method and returns the call, or
+// returns false once the server is closed.
+func (s *Server) Next() (*Call, bool) {
+	select {
+	case call := <-s.calls:
+		return call, true
+	case <-s.done:
+		return nil, false
+	}
+}
+
+// Close stops accepting clients, disconnects the current ones and removes
+// the socket.
+func (s *Server) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		slog.Info("control_client_connected")
+		go s.serve(conn)
+	}
+}
+
+// serve answers the requests of one client in order.
+func (s *Server) serve(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		slog.Info("control_client_disconnected")
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if enc.Encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: Errorf(CodeParseError, "parse error: %v", err)}) != nil {
+				return
+			}
+			continue
+		}
+		resp := s.dispatch(req)
+		if len(req.ID) == 0 {
+			continue // Notification: no reply
+		}
+		resp.JSONRPC, resp.ID = "2.0", req.ID
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// dispatch hands req to the session and waits for its reply.
+func (s *Server) dispatch(req request) response {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return response{Error: Errorf(CodeInvalidRequest, "invalid request")}
+	}
+	call := &Call{Method: req.Method, Params: req.Params, reply: make(chan response, 1)}
+	timeout := time.NewTimer(callTimeout)
+	defer timeout.Stop()
+
+	select {
+	case s.calls <- call:
+	case <-s.done:
+		return response{Error: Errorf(CodeServerError, "session closed")}
+	case <-timeout.C:
+		return response{Error: Errorf(CodeServerError, "session busy")}
+	}
+	select {
+	case resp := <-call.reply:
+		slog.Debug("control_call", "method", req.Method, "error", resp.Error != nil)
+		return resp
+	case <-s.done:
+		return response{Error: Errorf(CodeServerError, "session closed")}
+	case <-timeout.C:
+		return response{Error: Errorf(CodeServerError, "session did not answer")}
+	}
+}
+
+// Client calls a session's methods, e.g. from scripts written in Go.
+type Client struct {
+	conn   net.Conn
+	enc    *json.Encoder
+	dec    *json.Decoder
+	nextID int
+}
+
+// Dial connects to the control socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(bufio.NewReader(conn))}, nil
+}
+
+// Call invokes method with params and decodes its result into result,
+// which may be nil. A JSON-RPC error is returned as *Error.
+func (c *Client) Call(method string, params, result any) error {
+	c.nextID++
+	req := struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      int    `json:"id"`
+		Method  string `json:"method"`
+		Params  any    `json:"params,omitempty"`
+	}{"2.0", c.nextID, method, params}
+	if err := c.enc.Encode(req); err != nil {
+		return err
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := c.dec.Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Close disconnects from the session.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
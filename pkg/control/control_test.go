@@ -0,0 +1,132 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func socketPath(t *testing.T) string {
+	t.Helper()
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	dir, err := os.MkdirTemp("", "wtfctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "s.sock")
+}
+
+// serveOne answers the next call with handle.
+func serveOne(s *Server, handle func(*Call)) {
+	go func() {
+		if call, ok := s.Next(); ok {
+			handle(call)
+		}
+	}()
+}
+
+func TestServer_CallRoundTrip(t *testing.T) {
+	path := socketPath(t)
+	srv, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer srv.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected socket mode 0600, got %v", info.Mode().Perm())
+	}
+
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer client.Close()
+
+	serveOne(srv, func(call *Call) {
+		var params BufferTailParams
+		if err := call.Decode(&params); err != nil {
+			call.Reply(nil, err)
+			return
+		}
+		if call.Method != MethodBufferTail || params.Lines != 2 {
+			call.Reply(nil, Errorf(CodeInvalidParams, "unexpected call %s %+v", call.Method, params))
+			return
+		}
+		call.Reply(BufferTailResult{Lines: []string{"a", "b"}}, nil)
+	})
+
+	var result BufferTailResult
+	if err := client.Call(MethodBufferTail, BufferTailParams{Lines: 2}, &result); err != nil {
+		t.Fatalf("Call() error: %v", err)
+	}
+	if len(result.Lines) != 2 || result.Lines[1] != "b" {
+		t.Fatalf("Expected [a b], got %v", result.Lines)
+	}
+
+	serveOne(srv, func(call *Call) {
+		call.Reply(nil, Errorf(CodeMethodNotFound, "unknown method %q", call.Method))
+	})
+	err = client.Call("nope", nil, nil)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Fatalf("Expected method-not-found error, got %v", err)
+	}
+}
+
+func TestCall_DecodeInvalidParams(t *testing.T) {
+	call := &Call{Params: json.RawMessage(`{"lines":"many"}`)}
+	var params BufferTailParams
+	if err := call.Decode(&params); err == nil || err.Code != CodeInvalidParams {
+		t.Fatalf("Expected invalid params error, got %v", err)
+	}
+
+	call = &Call{}
+	params = BufferTailParams{Lines: 7}
+	if err := call.Decode(&params); err != nil || params.Lines != 7 {
+		t.Fatalf("Expected missing params to keep defaults, got %+v, %v", params, err)
+	}
+}
+
+func TestListen_RejectsLiveSocketAndOtherFiles(t *testing.T) {
+	path := socketPath(t)
+	srv, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Fatal("Expected error for a socket in use")
+	}
+	srv.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected Close to remove the socket, stat err = %v", err)
+	}
+
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Fatal("Expected error for a path that is not a socket")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected the file left in place, got %v", err)
+	}
+}
+
+func TestServer_CloseUnblocksNext(t *testing.T) {
+	srv, err := Listen(socketPath(t))
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	srv.Close()
+	if _, ok := srv.Next(); ok {
+		t.Fatal("Expected Next to report a closed server")
+	}
+}
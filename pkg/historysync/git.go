@@ -0,0 +1,121 @@
+package historysync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"wtf_cli/pkg/config"
+)
+
+// gitStateFile is the file holding the state in the sync repository.
+const gitStateFile = "wtf_cli_sync.json"
+
+// DefaultGitDir returns where the sync repository is cloned.
+func DefaultGitDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", "sync-git")
+	}
+	return filepath.Join(homeDir, ".wtf_cli", "sync-git")
+}
+
+// Git keeps the state in a file of a git repository, committed and pushed
+// by the git command so the user's SSH keys and credential helpers apply.
+type Git struct {
+	cfg config.SyncGitConfig
+	dir string
+}
+
+// NewGit returns a backend for the repository at cfg.URL, cloned into dir.
+func NewGit(cfg config.SyncGitConfig, dir string) *Git {
+	if cfg.Branch == "" {
+		cfg.Branch = "main"
+	}
+	return &Git{cfg: cfg, dir: dir}
+}
+
+func (g *Git) Fetch(ctx context.Context) ([]byte, error) {
+	if err := g.prepare(ctx); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(g.dir, gitStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoRemote
+	}
+	return data, err
+}
+
+func (g *Git) Store(ctx context.Context, data []byte) error {
+	if err := os.WriteFile(filepath.Join(g.dir, gitStateFile), data, 0600); err != nil {
+		return fmt.Errorf("write %s: %w", gitStateFile, err)
+	}
+	if _, err := g.git(ctx, "add", gitStateFile); err != nil {
+		return err
+	}
+	if _, err := g.git(ctx, "commit", "-q", "-m", "wtf_cli sync"); err != nil {
+		return err
+	}
+	_, err := g.git(ctx, "push", "-q", "origin", "HEAD:refs/heads/"+g.cfg.Branch)
+	return err
+}
+
+// prepare clones the repository on first use and otherwise moves the clone
+// to the remote branch, dropping local commits that failed to push: the
+// next Store carries their content, merged, anyway.
+func (g *Git) prepare(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(g.dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(g.dir), 0700); err != nil {
+			return err
+		}
+		if _, err := g.run(ctx, "", "clone", "-q", g.cfg.URL, g.dir); err != nil {
+			return err
+		}
+		// Commits need an author even where git has none configured.
+		if _, err := g.git(ctx, "config", "user.name", "wtf_cli"); err != nil {
+			return err
+		}
+		if _, err := g.git(ctx, "config", "user.email", "wtf_cli@localhost"); err != nil {
+			return err
+		}
+	}
+	if _, err := g.git(ctx, "remote", "set-url", "origin", g.cfg.URL); err != nil {
+		return err
+	}
+	if _, err := g.git(ctx, "fetch", "-q", "origin"); err != nil {
+		return err
+	}
+	remoteBranch := "refs/remotes/origin/" + g.cfg.Branch
+	if _, err := g.git(ctx, "rev-parse", "-q", "--verify", remoteBranch); err != nil {
+		// An empty repository, or the branch does not exist yet
+		_, err := g.git(ctx, "checkout", "-q", "-B", g.cfg.Branch)
+		return err
+	}
+	_, err := g.git(ctx, "checkout", "-q", "-B", g.cfg.Branch, remoteBranch)
+	if err == nil {
+		_, err = g.git(ctx, "reset", "-q", "--hard", remoteBranch)
+	}
+	return err
+}
+
+func (g *Git) git(ctx context.Context, args ...string) (string, error) {
+	return g.run(ctx, g.dir, args...)
+}
+
+func (g *Git) run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
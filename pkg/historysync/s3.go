@@ -0,0 +1,163 @@
+package historysync
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// S3 keeps the state in one object of an S3 bucket, or of an S3-compatible
+// store. Requests are signed with AWS Signature Version 4 and address the
+// bucket by path, which every compatible store accepts.
+type S3 struct {
+	cfg    config.SyncS3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 returns a backend for the object cfg.Bucket/cfg.Key.
+func NewS3(cfg config.SyncS3Config) *S3 {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if cfg.SecretAccessKey == "" {
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	return &S3{cfg: cfg, client: &http.Client{Timeout: httpTimeout}, now: time.Now}
+}
+
+func (s *S3) Fetch(ctx context.Context) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoRemote
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("s3 GET: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRemoteBytes))
+}
+
+func (s *S3) Store(ctx context.Context, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3 PUT: %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *S3) do(ctx context.Context, method string, body []byte) (*http.Response, error) {
+	endpoint := strings.TrimRight(s.cfg.Endpoint, "/")
+	objectURL := endpoint + "/" + s.cfg.Bucket + "/" + strings.TrimLeft(s.cfg.Key, "/")
+	req, err := http.NewRequestWithContext(ctx, method, objectURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, body, s.cfg, s.now().UTC())
+	return s.client.Do(req)
+}
+
+// signV4 adds the AWS Signature Version 4 headers to req.
+func signV4(req *http.Request, body []byte, cfg config.SyncS3Config, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names := []string{"host"}
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := req.Host
+		if name == "host" {
+			if value == "" {
+				value = req.URL.Host
+			}
+		} else {
+			value = strings.Join(req.Header.Values(name), ",")
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), day)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalPath URI-encodes each segment of the path, keeping the slashes.
+func canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			seg = unescaped
+		}
+		segments[i] = strings.ReplaceAll(url.QueryEscape(seg), "+", "%20")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,155 @@
+package historysync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const pinnedScriptsFilename = "pinned_scripts.json"
+
+// ScriptFile is a script saved in the scripts directory.
+type ScriptFile struct {
+	Name     string
+	Modified time.Time
+	Pinned   bool
+}
+
+// DefaultPinnedScriptsPath returns the file listing the pinned scripts. It
+// is kept out of the scripts directory, so pins stay on this machine.
+func DefaultPinnedScriptsPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", pinnedScriptsFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", pinnedScriptsFilename)
+}
+
+// ListScripts returns the scripts in dir, pinned ones first, then the most
+// recently modified. A missing directory holds no scripts.
+func ListScripts(dir, pinsPath string) ([]ScriptFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read scripts directory: %w", err)
+	}
+	pins := readPinnedScripts(pinsPath)
+	var scripts []ScriptFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		scripts = append(scripts, ScriptFile{
+			Name:     e.Name(),
+			Modified: info.ModTime(),
+			Pinned:   slices.Contains(pins, e.Name()),
+		})
+	}
+	slices.SortStableFunc(scripts, func(a, b ScriptFile) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		return b.Modified.Compare(a.Modified)
+	})
+	return scripts, nil
+}
+
+// RenameScript renames a script in dir without replacing another one. A
+// pin follows the script.
+func RenameScript(dir, pinsPath, oldName, newName string) error {
+	if err := checkScriptName(newName); err != nil {
+		return err
+	}
+	if newName == oldName {
+		return nil
+	}
+	newPath := filepath.Join(dir, newName)
+	if _, err := os.Lstat(newPath); err == nil {
+		return fmt.Errorf("%s already exists", newName)
+	}
+	if err := os.Rename(filepath.Join(dir, oldName), newPath); err != nil {
+		return fmt.Errorf("rename script: %w", err)
+	}
+	return updatePinnedScripts(pinsPath, func(pins []string) []string {
+		if i := slices.Index(pins, oldName); i >= 0 {
+			pins[i] = newName
+		}
+		return pins
+	})
+}
+
+// DeleteScript removes a script from dir and forgets its pin.
+func DeleteScript(dir, pinsPath, name string) error {
+	if err := os.Remove(filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("delete script: %w", err)
+	}
+	return SetScriptPinned(pinsPath, name, false)
+}
+
+// SetScriptPinned pins or unpins a script, keeping it at the top of the
+// list.
+func SetScriptPinned(pinsPath, name string, pinned bool) error {
+	return updatePinnedScripts(pinsPath, func(pins []string) []string {
+		pins = slices.DeleteFunc(pins, func(p string) bool { return p == name })
+		if pinned {
+			pins = append(pins, name)
+		}
+		return pins
+	})
+}
+
+// checkScriptName rejects names sync would not carry: empty, hidden or
+// outside the directory.
+func checkScriptName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("script name is empty")
+	case strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("script name %q must not contain a slash", name)
+	case strings.HasPrefix(name, "."):
+		return fmt.Errorf("script name %q must not start with a dot", name)
+	}
+	return nil
+}
+
+func readPinnedScripts(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var pins []string
+	if json.Unmarshal(data, &pins) != nil {
+		return nil
+	}
+	return pins
+}
+
+func updatePinnedScripts(path string, update func([]string) []string) error {
+	before := readPinnedScripts(path)
+	pins := update(slices.Clone(before))
+	if slices.Equal(pins, before) {
+		return nil
+	}
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal pinned scripts: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create pinned scripts directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write pinned scripts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package historysync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func scriptNames(t *testing.T, dir, pins string) []string {
+	t.Helper()
+	scripts, err := ListScripts(dir, pins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range scripts {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestScriptFiles_RenameDeletePin(t *testing.T) {
+	dir := t.TempDir()
+	pins := filepath.Join(t.TempDir(), "pinned_scripts.json")
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, name := range []string{"old.sh", "new.sh", ".hidden"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("echo "+name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, t0, t0.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := scriptNames(t, dir, pins); len(got) != 2 || got[0] != "new.sh" || got[1] != "old.sh" {
+		t.Fatalf("scripts = %v, want newest first without hidden files", got)
+	}
+	if err := SetScriptPinned(pins, "old.sh", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := scriptNames(t, dir, pins); got[0] != "old.sh" {
+		t.Fatalf("scripts = %v, want the pinned one first", got)
+	}
+
+	if err := RenameScript(dir, pins, "old.sh", "new.sh"); err == nil {
+		t.Fatal("expected renaming onto another script to fail")
+	}
+	for _, bad := range []string{"", "../x.sh", ".x.sh"} {
+		if err := RenameScript(dir, pins, "old.sh", bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+	if err := RenameScript(dir, pins, "old.sh", "deploy.sh"); err != nil {
+		t.Fatal(err)
+	}
+	scripts, _ := ListScripts(dir, pins)
+	if scripts[0].Name != "deploy.sh" || !scripts[0].Pinned {
+		t.Fatalf("scripts = %+v, want deploy.sh pinned first", scripts)
+	}
+
+	if err := DeleteScript(dir, pins, "deploy.sh"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readPinnedScripts(pins); len(got) != 0 {
+		t.Fatalf("pins = %v, want the deleted script unpinned", got)
+	}
+	if got := scriptNames(t, dir, pins); len(got) != 1 || got[0] != "new.sh" {
+		t.Fatalf("scripts = %v, want only new.sh", got)
+	}
+}
@@ -0,0 +1,99 @@
+package historysync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultScriptsDir returns the directory where exported scripts are saved
+// and synced from.
+func DefaultScriptsDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", "scripts")
+	}
+	return filepath.Join(homeDir, ".wtf_cli", "scripts")
+}
+
+// scanScripts brings the scripts in state up to date with dir: new and
+// edited files become snippets stamped with their modification time, and
+// synced snippets whose file is gone become tombstones stamped with now.
+func scanScripts(state State, dir string, now time.Time) (State, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return state, fmt.Errorf("read scripts directory: %w", err)
+	}
+
+	onDisk := make(map[string]Snippet)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return state, fmt.Errorf("read script: %w", err)
+		}
+		onDisk[e.Name()] = Snippet{Name: e.Name(), Content: string(data), Updated: info.ModTime().UTC()}
+	}
+
+	var changes []Snippet
+	known := make(map[string]bool, len(state.Scripts))
+	for _, s := range state.Scripts {
+		known[s.Name] = true
+		file, ok := onDisk[s.Name]
+		switch {
+		case !ok && !s.Deleted:
+			changes = append(changes, Snippet{Name: s.Name, Updated: now.UTC(), Deleted: true})
+		case ok && (s.Deleted || file.Content != s.Content):
+			// A file edited after the snippet was written keeps its
+			// modification time; one restored from elsewhere counts as new.
+			if !file.Updated.After(s.Updated) {
+				file.Updated = now.UTC()
+			}
+			changes = append(changes, file)
+		}
+	}
+	for name, file := range onDisk {
+		if !known[name] {
+			changes = append(changes, file)
+		}
+	}
+	return Merge(state, State{Scripts: changes}), nil
+}
+
+// writeScripts makes dir match the scripts in state.
+func writeScripts(state State, dir string) error {
+	for _, s := range state.Scripts {
+		if strings.ContainsAny(s.Name, `/\`) || s.Name == "" || strings.HasPrefix(s.Name, ".") {
+			continue // Never let a remote name escape the directory
+		}
+		path := filepath.Join(dir, s.Name)
+		if s.Deleted {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove script: %w", err)
+			}
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil && string(data) == s.Content {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("create scripts directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(s.Content), 0755); err != nil {
+			return fmt.Errorf("write script: %w", err)
+		}
+		if err := os.Chtimes(path, s.Updated, s.Updated); err != nil {
+			return fmt.Errorf("write script: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Package historysync keeps command history and saved scripts in step across
+// machines through a remote the user owns (WebDAV, S3 or a git repository).
+//
+// Each machine keeps its own copy of the state. A sync fetches the remote
+// copy, merges it with the local one and stores the result on both sides.
+// Merging never conflicts: history is the union of every machine's entries,
+// and each script keeps its most recently changed version, deletions
+// included. The remote copy is always encrypted with the sync passphrase;
+// the server only ever sees ciphertext.
+package historysync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/sealed"
+)
+
+const stateFilename = "sync_state.json"
+
+// maxHistoryEntries bounds the synced history; the oldest entries go first.
+const maxHistoryEntries = 20000
+
+// State is what a machine shares with the others.
+type State struct {
+	History []Entry   `json:"history"`
+	Scripts []Snippet `json:"scripts"`
+}
+
+// Entry is one command run on one machine.
+type Entry struct {
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Host    string    `json:"host,omitempty"`
+}
+
+// Snippet is a script saved in ~/.wtf_cli/scripts, by file name. A deleted
+// script is kept as a tombstone so the deletion reaches other machines.
+type Snippet struct {
+	Name    string    `json:"name"`
+	Content string    `json:"content,omitempty"`
+	Updated time.Time `json:"updated"`
+	Deleted bool      `json:"deleted,omitempty"`
+}
+
+// DefaultStatePath returns where the local copy of the state is kept.
+func DefaultStatePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", stateFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", stateFilename)
+}
+
+// LoadState reads the local state at path. A missing file is an empty
+// state. The file is sealed like the config when config encryption is on.
+func LoadState(path string) (State, error) {
+	data, err := sealed.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("read sync state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("parse sync state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveState writes state to path.
+func SaveState(path string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal sync state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create sync state directory: %w", err)
+	}
+	if err := sealed.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+	return nil
+}
+
+// RecordHistory adds entries to the local state at path, e.g. the commands
+// of a session that just ended. Entries already recorded are not repeated.
+func RecordHistory(path string, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	state, err := LoadState(path)
+	if err != nil {
+		return err
+	}
+	return SaveState(path, Merge(state, State{History: entries}))
+}
+
+// Merge combines two states. It is commutative, associative and
+// idempotent, so machines converge whatever order they sync in.
+func Merge(a, b State) State {
+	return State{
+		History: mergeHistory(a.History, b.History),
+		Scripts: mergeScripts(a.Scripts, b.Scripts),
+	}
+}
+
+func mergeHistory(a, b []Entry) []Entry {
+	type key struct {
+		command string
+		time    int64
+		host    string
+	}
+	seen := make(map[key]bool, len(a)+len(b))
+	merged := make([]Entry, 0, len(a)+len(b))
+	for _, e := range slices.Concat(a, b) {
+		e.Command = strings.TrimSpace(e.Command)
+		e.Time = e.Time.UTC()
+		k := key{e.Command, e.Time.UnixNano(), e.Host}
+		if e.Command == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		merged = append(merged, e)
+	}
+	slices.SortFunc(merged, compareEntries)
+	if len(merged) > maxHistoryEntries {
+		merged = merged[len(merged)-maxHistoryEntries:]
+	}
+	return merged
+}
+
+// compareEntries orders entries by time, breaking ties by host and command
+// so every machine sorts the same way.
+func compareEntries(a, b Entry) int {
+	if c := a.Time.Compare(b.Time); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Host, b.Host); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Command, b.Command)
+}
+
+func mergeScripts(a, b []Snippet) []Snippet {
+	byName := make(map[string]Snippet, len(a)+len(b))
+	for _, s := range slices.Concat(a, b) {
+		s.Updated = s.Updated.UTC()
+		if old, ok := byName[s.Name]; ok && !newerSnippet(s, old) {
+			continue
+		}
+		byName[s.Name] = s
+	}
+	merged := make([]Snippet, 0, len(byName))
+	for _, s := range byName {
+		merged = append(merged, s)
+	}
+	slices.SortFunc(merged, func(a, b Snippet) int { return strings.Compare(a.Name, b.Name) })
+	return merged
+}
+
+// newerSnippet reports whether s wins over old: the later change wins, and
+// for changes made at the same instant a deletion, then the greater
+// content, so the outcome does not depend on which side is which.
+func newerSnippet(s, old Snippet) bool {
+	if c := s.Updated.Compare(old.Updated); c != 0 {
+		return c > 0
+	}
+	if s.Deleted != old.Deleted {
+		return s.Deleted
+	}
+	return s.Content > old.Content
+}
+
+// Commands returns the synced commands, most recent first.
+func (s State) Commands() []Entry {
+	commands := slices.Clone(s.History)
+	slices.Reverse(commands)
+	return commands
+}
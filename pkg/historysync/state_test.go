@@ -0,0 +1,84 @@
+package historysync
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMerge_HistoryIsUnionInTimeOrder(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	laptop := State{History: []Entry{
+		{Command: "make test", Time: t0, Host: "laptop"},
+		{Command: "git push", Time: t0.Add(2 * time.Minute), Host: "laptop"},
+	}}
+	desk := State{History: []Entry{
+		{Command: "ls", Time: t0.Add(time.Minute), Host: "desk"},
+		{Command: "make test", Time: t0, Host: "laptop"}, // Already synced
+	}}
+
+	merged := Merge(laptop, desk)
+	var got []string
+	for _, e := range merged.History {
+		got = append(got, e.Command)
+	}
+	if want := []string{"make test", "ls", "git push"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("history = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(Merge(desk, laptop), merged) {
+		t.Error("Merge is not commutative")
+	}
+	if !reflect.DeepEqual(Merge(merged, laptop), merged) {
+		t.Error("Merge is not idempotent")
+	}
+	if cmds := merged.Commands(); cmds[0].Command != "git push" {
+		t.Errorf("Commands()[0] = %q, want the most recent", cmds[0].Command)
+	}
+}
+
+func TestMerge_ScriptsKeepLatestChange(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	a := State{Scripts: []Snippet{
+		{Name: "deploy.sh", Content: "v1", Updated: t0},
+		{Name: "old.sh", Content: "x", Updated: t0},
+	}}
+	b := State{Scripts: []Snippet{
+		{Name: "deploy.sh", Content: "v2", Updated: t0.Add(time.Hour)},
+		{Name: "old.sh", Updated: t0.Add(time.Hour), Deleted: true},
+	}}
+
+	merged := Merge(a, b)
+	want := []Snippet{
+		{Name: "deploy.sh", Content: "v2", Updated: t0.Add(time.Hour)},
+		{Name: "old.sh", Updated: t0.Add(time.Hour), Deleted: true},
+	}
+	if !reflect.DeepEqual(merged.Scripts, want) {
+		t.Fatalf("scripts = %+v, want %+v", merged.Scripts, want)
+	}
+
+	// Changes made at the same instant resolve the same way on both sides.
+	c := State{Scripts: []Snippet{{Name: "deploy.sh", Content: "v3", Updated: t0.Add(time.Hour)}}}
+	if !reflect.DeepEqual(Merge(b, c), Merge(c, b)) {
+		t.Error("tie between equal timestamps depends on merge order")
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync_state.json")
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{{Command: "ls", Time: t0, Host: "desk"}}
+
+	for range 2 {
+		if err := RecordHistory(path, entries); err != nil {
+			t.Fatalf("RecordHistory: %v", err)
+		}
+	}
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if len(state.History) != 1 {
+		t.Errorf("history = %+v, want the entry once", state.History)
+	}
+}
@@ -0,0 +1,186 @@
+package historysync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/sealed"
+)
+
+// KeyEnv holds the sync passphrase; it overrides sync.key_file.
+const KeyEnv = "WTF_SYNC_KEY"
+
+// ErrNoRemote is returned by Backend.Fetch before anything was stored.
+var ErrNoRemote = errors.New("nothing stored remotely yet")
+
+// ErrNoKey is returned by Passphrase when neither WTF_SYNC_KEY nor
+// sync.key_file provides the passphrase.
+var ErrNoKey = errors.New("no sync passphrase: set " + KeyEnv + " or sync.key_file")
+
+// Backend stores the encrypted state remotely.
+type Backend interface {
+	// Fetch returns the stored state, or ErrNoRemote.
+	Fetch(ctx context.Context) ([]byte, error)
+	// Store replaces the stored state.
+	Store(ctx context.Context, data []byte) error
+}
+
+// NewBackend returns the backend configured in cfg.
+func NewBackend(cfg config.SyncConfig) (Backend, error) {
+	switch strings.TrimSpace(cfg.Backend) {
+	case config.SyncBackendWebDAV:
+		return NewWebDAV(cfg.WebDAV), nil
+	case config.SyncBackendS3:
+		return NewS3(cfg.S3), nil
+	case config.SyncBackendGit:
+		return NewGit(cfg.Git, DefaultGitDir()), nil
+	case "":
+		return nil, errors.New("sync is not configured: set sync.backend")
+	}
+	return nil, fmt.Errorf("unknown sync backend %q", cfg.Backend)
+}
+
+// Passphrase returns the sync passphrase from WTF_SYNC_KEY or the key file.
+func Passphrase(cfg config.SyncConfig) ([]byte, error) {
+	if key := os.Getenv(KeyEnv); key != "" {
+		return []byte(key), nil
+	}
+	if strings.TrimSpace(cfg.KeyFile) == "" {
+		return nil, ErrNoKey
+	}
+	data, err := os.ReadFile(expandHome(cfg.KeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("read sync key file: %w", err)
+	}
+	key := strings.TrimRight(string(data), "\r\n")
+	if key == "" {
+		return nil, fmt.Errorf("sync key file %s is empty", cfg.KeyFile)
+	}
+	return []byte(key), nil
+}
+
+// expandHome replaces a leading "~/" with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// Syncer runs syncs against one backend.
+type Syncer struct {
+	Backend    Backend
+	Passphrase []byte
+	StatePath  string
+	ScriptsDir string
+	Now        func() time.Time
+}
+
+// Result summarizes a sync.
+type Result struct {
+	History      int // Commands in the merged history
+	NewHistory   int // Commands this machine had not seen
+	Scripts      int // Scripts present after the sync
+	FirstUpload  bool
+	RemoteChange bool // The remote copy was replaced
+}
+
+// Sync merges the local state with the remote one and stores the result on
+// both sides.
+func (s *Syncer) Sync(ctx context.Context) (Result, error) {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	session := &sealed.Session{}
+	session.UnlockPassphrase(s.Passphrase)
+	defer session.Lock()
+
+	local, err := LoadState(s.StatePath)
+	if err != nil {
+		return Result{}, err
+	}
+	local, err = scanScripts(local, s.ScriptsDir, now())
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	remote := State{}
+	data, err := s.Backend.Fetch(ctx)
+	switch {
+	case errors.Is(err, ErrNoRemote):
+		result.FirstUpload = true
+	case err != nil:
+		return Result{}, fmt.Errorf("fetch: %w", err)
+	default:
+		if remote, err = openState(session, data); err != nil {
+			return Result{}, err
+		}
+	}
+
+	merged := Merge(local, remote)
+	result.History = len(merged.History)
+	result.NewHistory = len(merged.History) - len(local.History)
+	for _, sn := range merged.Scripts {
+		if !sn.Deleted {
+			result.Scripts++
+		}
+	}
+
+	if err := writeScripts(merged, s.ScriptsDir); err != nil {
+		return Result{}, err
+	}
+	if err := SaveState(s.StatePath, merged); err != nil {
+		return Result{}, err
+	}
+
+	plain, err := json.Marshal(merged)
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal sync state: %w", err)
+	}
+	if remotePlain, _ := json.Marshal(remote); !result.FirstUpload && string(remotePlain) == string(plain) {
+		return result, nil
+	}
+	sealedState, err := session.Seal(plain)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := s.Backend.Store(ctx, sealedState); err != nil {
+		return Result{}, fmt.Errorf("store: %w", err)
+	}
+	result.RemoteChange = true
+	return result, nil
+}
+
+// openState decrypts a remote state. A remote copy that is not encrypted
+// with a passphrase is refused rather than trusted.
+func openState(session *sealed.Session, data []byte) (State, error) {
+	if method, ok := sealed.MethodOf(data); !ok || method != sealed.MethodPassphrase {
+		return State{}, errors.New("the remote copy is not encrypted with a sync passphrase; refusing to use it")
+	}
+	plain, err := session.Open(data)
+	if errors.Is(err, sealed.ErrWrongSecret) {
+		return State{}, errors.New("wrong sync passphrase for the remote copy")
+	}
+	if err != nil {
+		return State{}, err
+	}
+	var state State
+	if err := json.Unmarshal(plain, &state); err != nil {
+		return State{}, fmt.Errorf("parse remote state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,71 @@
+package historysync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// httpTimeout bounds each request to a remote.
+const httpTimeout = 30 * time.Second
+
+// maxRemoteBytes bounds how much of a remote copy is read.
+const maxRemoteBytes = 64 << 20
+
+// WebDAV keeps the state in one file on a WebDAV server (Nextcloud, a NAS,
+// Apache mod_dav, ...).
+type WebDAV struct {
+	cfg    config.SyncWebDAVConfig
+	client *http.Client
+}
+
+// NewWebDAV returns a backend for the file at cfg.URL.
+func NewWebDAV(cfg config.SyncWebDAVConfig) *WebDAV {
+	return &WebDAV{cfg: cfg, client: &http.Client{Timeout: httpTimeout}}
+}
+
+func (w *WebDAV) Fetch(ctx context.Context) ([]byte, error) {
+	resp, err := w.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoRemote
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webdav GET: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRemoteBytes))
+}
+
+func (w *WebDAV) Store(ctx context.Context, data []byte) error {
+	resp, err := w.do(ctx, http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webdav PUT: %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *WebDAV) do(ctx context.Context, method string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if w.cfg.Username != "" || w.cfg.Password != "" {
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return w.client.Do(req)
+}
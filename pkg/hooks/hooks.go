@@ -0,0 +1,121 @@
+// Package hooks runs the user's scripts when something happens in the
+// session, passing the event to them as JSON on stdin.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// maxLoggedOutput bounds how much of a hook's output goes to the log.
+const maxLoggedOutput = 2048
+
+// Event is what a hook reads from stdin. Fields that do not apply to the
+// event are left out.
+type Event struct {
+	Event           string    `json:"event"` // One of the config.HookEvent* names
+	Time            time.Time `json:"time"`
+	PID             int       `json:"pid"` // wtf_cli's process ID, the same for every event of a session
+	Dir             string    `json:"dir,omitempty"`
+	Command         string    `json:"command,omitempty"`
+	ExitCode        *int      `json:"exit_code,omitempty"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	Question        string    `json:"question,omitempty"`
+	Answer          string    `json:"answer,omitempty"`
+	Provider        string    `json:"provider,omitempty"`
+	Model           string    `json:"model,omitempty"`
+}
+
+// Runner runs the hooks configured for each event.
+type Runner struct {
+	hooks map[string][]config.HookConfig
+}
+
+// New builds a Runner from the hooks config section. It returns nil when
+// there are no hooks; a nil Runner runs nothing.
+func New(hooks []config.HookConfig) *Runner {
+	if len(hooks) == 0 {
+		return nil
+	}
+	r := &Runner{hooks: map[string][]config.HookConfig{}}
+	for _, h := range hooks {
+		r.hooks[h.Event] = append(r.hooks[h.Event], h)
+	}
+	return r
+}
+
+// Has reports whether any hook listens for event, so callers can skip
+// building the event when nothing does.
+func (r *Runner) Has(event string) bool {
+	return r != nil && len(r.hooks[event]) > 0
+}
+
+// Run runs the hooks for ev.Event one after another, in config order.
+// Blocking; callers run it off the UI goroutine. Failures are logged and
+// never returned: a broken script must not affect the session.
+func (r *Runner) Run(ev Event) {
+	if !r.Has(ev.Event) {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if ev.PID == 0 {
+		ev.PID = os.Getpid()
+	}
+	input, err := json.Marshal(ev)
+	if err != nil {
+		slog.Error("hook_encode_error", "event", ev.Event, "error", err)
+		return
+	}
+	for _, h := range r.hooks[ev.Event] {
+		run(h, ev, input)
+	}
+}
+
+// Timeout returns how long h may run.
+func Timeout(h config.HookConfig) time.Duration {
+	if h.TimeoutSeconds == 0 {
+		return config.DefaultHookTimeoutSeconds * time.Second
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+func run(h config.HookConfig, ev Event, input []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout(h))
+	defer cancel()
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", h.Command)
+	cmd.Dir = ev.Dir
+	cmd.Env = append(os.Environ(), "WTF_HOOK_EVENT="+ev.Event)
+	cmd.Stdin = bytes.NewReader(input)
+	// Background children of the script would otherwise keep the output
+	// pipe open past the timeout.
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+
+	attrs := []any{"event", ev.Event, "command", h.Command, "duration", time.Since(start).Round(time.Millisecond)}
+	if text := strings.TrimSpace(string(out)); text != "" {
+		if len(text) > maxLoggedOutput {
+			text = text[:maxLoggedOutput] + "..."
+		}
+		attrs = append(attrs, "output", text)
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = errors.New("timed out after " + Timeout(h).String())
+		}
+		slog.Warn("hook_error", append(attrs, "error", err)...)
+		return
+	}
+	slog.Debug("hook_ran", attrs...)
+}
@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+func TestRunner_NilAndEmpty(t *testing.T) {
+	if r := New(nil); r != nil {
+		t.Fatalf("New(nil) = %v, want nil", r)
+	}
+	var r *Runner
+	if r.Has(config.HookEventSessionStart) {
+		t.Error("nil Runner should have no hooks")
+	}
+	r.Run(Event{Event: config.HookEventSessionStart}) // Must not panic
+}
+
+func TestRunner_RunPassesEventOnStdin(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "event.json")
+	r := New([]config.HookConfig{
+		{Event: config.HookEventCommandFailed, Command: `cat > event.json; echo "$WTF_HOOK_EVENT" > name`},
+		{Event: config.HookEventSessionEnd, Command: "touch ended"},
+	})
+	if !r.Has(config.HookEventCommandFailed) || r.Has(config.HookEventAnswerReceived) {
+		t.Fatal("Has should only report configured events")
+	}
+
+	code := 2
+	r.Run(Event{Event: config.HookEventCommandFailed, Dir: dir, Command: "make test", ExitCode: &code})
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not write its input: %v", err)
+	}
+	var got Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin is not JSON: %v: %s", err, data)
+	}
+	if got.Event != config.HookEventCommandFailed || got.Command != "make test" || got.ExitCode == nil || *got.ExitCode != 2 {
+		t.Errorf("event = %+v", got)
+	}
+	if got.PID != os.Getpid() || got.Time.IsZero() {
+		t.Errorf("PID and Time should be filled in, got %d and %v", got.PID, got.Time)
+	}
+	if name, _ := os.ReadFile(filepath.Join(dir, "name")); strings.TrimSpace(string(name)) != config.HookEventCommandFailed {
+		t.Errorf("WTF_HOOK_EVENT = %q", name)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ended")); err == nil {
+		t.Error("hooks for other events should not run")
+	}
+}
+
+func TestRunner_RunStopsAtTimeout(t *testing.T) {
+	r := New([]config.HookConfig{{Event: config.HookEventSessionStart, Command: "sleep 30", TimeoutSeconds: 1}})
+	start := time.Now()
+	r.Run(Event{Event: config.HookEventSessionStart, Dir: t.TempDir()})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %v, want it stopped near the 1s timeout", elapsed)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	if got := Timeout(config.HookConfig{}); got != config.DefaultHookTimeoutSeconds*time.Second {
+		t.Errorf("default Timeout = %v", got)
+	}
+	if got := Timeout(config.HookConfig{TimeoutSeconds: 3}); got != 3*time.Second {
+		t.Errorf("Timeout = %v, want 3s", got)
+	}
+}
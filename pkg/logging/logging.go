@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"wtf_cli/pkg/config"
 
@@ -13,11 +14,6 @@ import (
 )
 
 const defaultLogFile = "wtf_cli.log"
-const (
-	maxLogSizeMB  = 5
-	maxLogBackups = 5
-	maxLogAgeDays = 14
-)
 
 // LevelTrace enables verbose diagnostic logging below debug.
 const LevelTrace slog.Level = -8
@@ -36,22 +32,23 @@ func Init(cfg config.Config) (*slog.Logger, error) {
 		ReplaceAttr: replaceLevelAttr,
 	}
 
-	logPath := strings.TrimSpace(cfg.LogFile)
-	if logPath == "" {
-		logPath = defaultLogPath()
-	}
+	logPath := Path(cfg)
 	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
 		logger := slog.New(newHandler(cfg.LogFormat, io.Discard, handlerOptions))
 		slog.SetDefault(logger)
 		return logger, err
 	}
 
-	writer := &lumberjack.Logger{
+	rotation := cfg.LogRotation.WithDefaults()
+	var writer io.Writer = &lumberjack.Logger{
 		Filename:   logPath,
-		MaxSize:    maxLogSizeMB,
-		MaxBackups: maxLogBackups,
-		MaxAge:     maxLogAgeDays,
-		Compress:   true,
+		MaxSize:    rotation.MaxSizeMB,
+		MaxBackups: rotation.MaxBackups,
+		MaxAge:     rotation.MaxAgeDays,
+		Compress:   *rotation.Compress,
+	}
+	if rotation.RotateEveryHours > 0 {
+		writer = newAgeRotator(writer.(*lumberjack.Logger), time.Duration(rotation.RotateEveryHours)*time.Hour)
 	}
 
 	logger := slog.New(newHandler(cfg.LogFormat, writer, handlerOptions))
@@ -64,6 +61,14 @@ func SetLevel(level string) {
 	levelVar.Set(parseLogLevel(level))
 }
 
+// Path returns the file cfg logs to.
+func Path(cfg config.Config) string {
+	if logPath := strings.TrimSpace(cfg.LogFile); logPath != "" {
+		return logPath
+	}
+	return defaultLogPath()
+}
+
 func defaultLogPath() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil || strings.TrimSpace(homeDir) == "" {
@@ -1,13 +1,17 @@
 package logging
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"wtf_cli/pkg/config"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func TestInitCreatesLogFile(t *testing.T) {
@@ -38,3 +42,67 @@ func TestInitCreatesLogFile(t *testing.T) {
 		t.Fatalf("Expected log to contain message, got: %s", string(data))
 	}
 }
+
+func TestTailReturnsLastLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wtf_cli.log")
+	var sb strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&sb, "record %04d %s\n", i, strings.Repeat("x", 20))
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := Tail(path, 3)
+	if err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "record 4997") || !strings.HasPrefix(lines[2], "record 4999") {
+		t.Fatalf("unexpected tail: %q", lines)
+	}
+
+	lines, err = Tail(path, 10000)
+	if err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	if len(lines) != 5000 || !strings.HasPrefix(lines[0], "record 0000") {
+		t.Fatalf("expected the whole file, got %d lines starting %q", len(lines), lines[0])
+	}
+}
+
+func TestAgeRotatorRotatesOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wtf_cli.log")
+	log := &lumberjack.Logger{Filename: path}
+	defer log.Close()
+
+	now := time.Now()
+	r := newAgeRotator(log, time.Hour)
+	r.now = func() time.Time { return now }
+
+	if _, err := r.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if _, err := r.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected no rotation within the hour, got %d files", len(entries))
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := r.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Fatalf("expected a rotated file, got %d files", len(entries))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "third\n" {
+		t.Fatalf("expected a fresh log, got %q", data)
+	}
+}
@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// ageRotator rotates the log it wraps once the current file has been in use
+// for longer than every. lumberjack itself only rotates by size.
+type ageRotator struct {
+	mu      sync.Mutex
+	log     *lumberjack.Logger
+	every   time.Duration
+	started time.Time
+	now     func() time.Time
+}
+
+// newAgeRotator wraps log. A file left over from an earlier session counts
+// from its last write, so a stale log is rotated on the first new record.
+func newAgeRotator(log *lumberjack.Logger, every time.Duration) *ageRotator {
+	r := &ageRotator{log: log, every: every, now: time.Now}
+	r.started = r.now()
+	if info, err := os.Stat(log.Filename); err == nil {
+		r.started = info.ModTime()
+	}
+	return r
+}
+
+func (r *ageRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := r.now(); now.Sub(r.started) >= r.every {
+		// A failed rotation keeps writing to the current file; it is
+		// retried on the next record.
+		if err := r.log.Rotate(); err == nil {
+			r.started = now
+		}
+	}
+	return r.log.Write(p)
+}
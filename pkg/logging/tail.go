@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// tailChunk is how much of the file is read per step from the end.
+const tailChunk = 32 * 1024
+
+// Tail returns the last n lines of the file at path, oldest first. It reads
+// backwards from the end, so large logs cost only what is returned.
+func Tail(path string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	end := info.Size()
+	var data []byte
+	for offset := end; offset > 0 && bytes.Count(data, []byte("\n")) <= n; {
+		size := int64(tailChunk)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return nil, nil
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = string(line)
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// DesktopSink shows a native desktop notification via notify-send (Linux)
+// or osascript (macOS).
+type DesktopSink struct {
+	// run executes the notifier command. Injectable for tests.
+	run  func(ctx context.Context, name string, args ...string) error
+	goos string
+}
+
+// NewDesktopSink creates a desktop sink for the host platform.
+func NewDesktopSink() *DesktopSink {
+	return &DesktopSink{
+		run: func(ctx context.Context, name string, args ...string) error {
+			return exec.CommandContext(ctx, name, args...).Run()
+		},
+		goos: runtime.GOOS,
+	}
+}
+
+func (d *DesktopSink) Name() string { return SinkDesktop }
+
+func (d *DesktopSink) Notify(ctx context.Context, ev Event) error {
+	title := ev.Title
+	if strings.TrimSpace(title) == "" {
+		title = "wtf_cli"
+	}
+	switch d.goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(ev.Message), appleScriptQuote(title))
+		return d.run(ctx, "osascript", "-e", script)
+	case "linux":
+		return d.run(ctx, "notify-send", "--app-name=wtf_cli", title, ev.Message)
+	default:
+		return fmt.Errorf("desktop notifications not supported on %s", d.goos)
+	}
+}
+
+func appleScriptQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
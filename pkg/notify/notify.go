@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+// Sink names used for routing and logging.
+const (
+	SinkStatusBar = "status_bar"
+	SinkBell      = "bell"
+	SinkDesktop   = "desktop"
+	SinkWebhook   = "webhook"
+)
+
+// deliverTimeout bounds how long a single background sink may take.
+const deliverTimeout = 10 * time.Second
+
+// Event is a single notification raised by the UI.
+type Event struct {
+	Kind    string // One of the config.NotifyEvent* names
+	Title   string
+	Message string
+	Time    time.Time
+}
+
+// Sink delivers events outside the TUI (desktop popup, webhook, ...).
+// Status bar toasts and the terminal bell are rendered by the UI itself and
+// are not Sinks; the Notifier only reports whether they are routed.
+type Sink interface {
+	Name() string
+	Notify(ctx context.Context, ev Event) error
+}
+
+type route struct {
+	enabled bool
+	events  map[string]bool // nil ⇒ all events
+}
+
+func newRoute(enabled bool, events []string) route {
+	r := route{enabled: enabled}
+	if len(events) > 0 {
+		r.events = make(map[string]bool, len(events))
+		for _, e := range events {
+			r.events[strings.TrimSpace(e)] = true
+		}
+	}
+	return r
+}
+
+func (r route) wants(kind string) bool {
+	if !r.enabled {
+		return false
+	}
+	return r.events == nil || r.events[kind]
+}
+
+// Notifier routes events to the sinks enabled in config.
+type Notifier struct {
+	routes      map[string]route
+	sinks       []Sink
+	longCommand time.Duration
+}
+
+// New builds a Notifier from the notifications config section.
+func New(cfg config.NotificationsConfig) *Notifier {
+	n := &Notifier{
+		routes: map[string]route{
+			SinkStatusBar: newRoute(cfg.StatusBar.Enabled, cfg.StatusBar.Events),
+			SinkBell:      newRoute(cfg.Bell.Enabled, cfg.Bell.Events),
+			SinkDesktop:   newRoute(cfg.Desktop.Enabled, cfg.Desktop.Events),
+			SinkWebhook:   newRoute(cfg.Webhook.Enabled, cfg.Webhook.Events),
+		},
+		longCommand: time.Duration(cfg.LongCommandSeconds) * time.Second,
+	}
+	if cfg.Desktop.Enabled {
+		n.sinks = append(n.sinks, NewDesktopSink())
+	}
+	if cfg.Webhook.Enabled && strings.TrimSpace(cfg.Webhook.URL) != "" {
+		n.sinks = append(n.sinks, NewWebhookSink(cfg.Webhook.URL, nil))
+	}
+	return n
+}
+
+// AddSink registers an additional background sink that receives every event.
+// Used by tests and embedders; config-driven sinks are created by New.
+func (n *Notifier) AddSink(s Sink) {
+	if n == nil || s == nil {
+		return
+	}
+	n.sinks = append(n.sinks, s)
+	if _, ok := n.routes[s.Name()]; !ok {
+		n.routes[s.Name()] = route{enabled: true}
+	}
+}
+
+// Wants reports whether the named sink is enabled for the event kind.
+func (n *Notifier) Wants(sink, kind string) bool {
+	if n == nil {
+		return false
+	}
+	return n.routes[sink].wants(kind)
+}
+
+// LongCommandThreshold returns the minimum command runtime that raises a
+// command_finished event. Zero disables the event.
+func (n *Notifier) LongCommandThreshold() time.Duration {
+	if n == nil {
+		return 0
+	}
+	return n.longCommand
+}
+
+// HasBackgroundSinks reports whether any background sink wants the event, so
+// callers can skip scheduling a delivery goroutine when nothing listens.
+func (n *Notifier) HasBackgroundSinks(kind string) bool {
+	if n == nil {
+		return false
+	}
+	for _, s := range n.sinks {
+		if n.Wants(s.Name(), kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliver sends the event to every background sink routed for its kind.
+// Blocking; callers run it off the UI goroutine. Sink errors are logged and
+// never returned — a failing webhook must not affect the session.
+func (n *Notifier) Deliver(ev Event) {
+	if n == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	for _, s := range n.sinks {
+		if !n.Wants(s.Name(), ev.Kind) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), deliverTimeout)
+		err := s.Notify(ctx, ev)
+		cancel()
+		if err != nil {
+			slog.Warn("notify_sink_error", "sink", s.Name(), "event", ev.Kind, "error", err)
+			continue
+		}
+		slog.Debug("notify_sink_delivered", "sink", s.Name(), "event", ev.Kind)
+	}
+}
+
+// IsRateLimitError reports whether a provider error looks like a rate limit
+// or quota rejection. Providers surface these as plain errors, so this is a
+// best-effort match on the common status codes and phrases.
+func IsRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	text := strings.ToLower(err.Error())
+	for _, marker := range []string{"429", "rate limit", "rate_limit", "ratelimit", "too many requests", "resource_exhausted", "quota exceeded"} {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
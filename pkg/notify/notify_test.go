@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/config"
+)
+
+type recordingSink struct {
+	name   string
+	events []Event
+	err    error
+}
+
+func (r *recordingSink) Name() string { return r.name }
+
+func (r *recordingSink) Notify(_ context.Context, ev Event) error {
+	r.events = append(r.events, ev)
+	return r.err
+}
+
+func TestNotifier_WantsRespectsEnabledAndEvents(t *testing.T) {
+	n := New(config.NotificationsConfig{
+		StatusBar: config.NotificationSinkConfig{Enabled: true},
+		Bell: config.NotificationSinkConfig{
+			Enabled: true,
+			Events:  []string{config.NotifyEventCommandFinished},
+		},
+	})
+
+	if !n.Wants(SinkStatusBar, config.NotifyEventStreamFinished) {
+		t.Error("status bar with no event filter should want every event")
+	}
+	if !n.Wants(SinkBell, config.NotifyEventCommandFinished) {
+		t.Error("bell should want command_finished")
+	}
+	if n.Wants(SinkBell, config.NotifyEventStreamError) {
+		t.Error("bell should not want stream_error when filtered out")
+	}
+	if n.Wants(SinkDesktop, config.NotifyEventStreamFinished) {
+		t.Error("disabled desktop sink should not want events")
+	}
+}
+
+func TestNotifier_NilIsSafe(t *testing.T) {
+	var n *Notifier
+	if n.Wants(SinkStatusBar, config.NotifyEventStreamFinished) {
+		t.Error("nil notifier should not want events")
+	}
+	if n.HasBackgroundSinks(config.NotifyEventStreamFinished) {
+		t.Error("nil notifier should have no background sinks")
+	}
+	n.Deliver(Event{Kind: config.NotifyEventStreamFinished})
+}
+
+func TestNotifier_DeliverSkipsUnroutedAndSwallowsErrors(t *testing.T) {
+	n := New(config.NotificationsConfig{})
+	ok := &recordingSink{name: "ok"}
+	failing := &recordingSink{name: "failing", err: errors.New("boom")}
+	n.AddSink(failing)
+	n.AddSink(ok)
+
+	n.Deliver(Event{Kind: config.NotifyEventStreamError, Title: "t"})
+
+	if len(failing.events) != 1 || len(ok.events) != 1 {
+		t.Fatalf("expected both sinks to receive the event, got %d and %d", len(failing.events), len(ok.events))
+	}
+	if ok.events[0].Time.IsZero() {
+		t.Error("expected Deliver to stamp the event time")
+	}
+}
+
+func TestNew_WebhookSinkRequiresURL(t *testing.T) {
+	n := New(config.NotificationsConfig{
+		Webhook: config.WebhookSinkConfig{Enabled: true},
+	})
+	if n.HasBackgroundSinks(config.NotifyEventStreamFinished) {
+		t.Error("webhook without URL should not register a sink")
+	}
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("POST /chat: 429 Too Many Requests"), true},
+		{errors.New("Error 429, RESOURCE_EXHAUSTED"), true},
+		{errors.New("rate limit exceeded"), true},
+		{errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRateLimitError(tt.err); got != tt.want {
+			t.Errorf("IsRateLimitError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWebhookSink_PostsJSON(t *testing.T) {
+	var gotBody webhookPayload
+	var gotContentType string
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		gotContentType = req.Header.Get("Content-Type")
+		data, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(data, &gotBody); err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})}
+
+	sink := NewWebhookSink("https://hooks.example.com/wtf", client)
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := sink.Notify(context.Background(), Event{
+		Kind:    config.NotifyEventCommandFinished,
+		Title:   "Command finished (1m0s)",
+		Message: "make build",
+		Time:    when,
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", gotContentType)
+	}
+	if gotBody.Event != config.NotifyEventCommandFinished || gotBody.Message != "make build" || !gotBody.Time.Equal(when) {
+		t.Errorf("unexpected payload: %+v", gotBody)
+	}
+}
+
+func TestWebhookSink_NonSuccessStatusIsError(t *testing.T) {
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})}
+	sink := NewWebhookSink("https://hooks.example.com/wtf", client)
+	if err := sink.Notify(context.Background(), Event{Kind: config.NotifyEventStreamError}); err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestDesktopSink_CommandPerPlatform(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	run := func(_ context.Context, name string, args ...string) error {
+		gotName, gotArgs = name, args
+		return nil
+	}
+
+	linux := &DesktopSink{run: run, goos: "linux"}
+	if err := linux.Notify(context.Background(), Event{Title: "Done", Message: "ok"}); err != nil {
+		t.Fatalf("linux Notify() error = %v", err)
+	}
+	if gotName != "notify-send" || gotArgs[len(gotArgs)-2] != "Done" || gotArgs[len(gotArgs)-1] != "ok" {
+		t.Errorf("unexpected linux invocation: %s %v", gotName, gotArgs)
+	}
+
+	darwin := &DesktopSink{run: run, goos: "darwin"}
+	if err := darwin.Notify(context.Background(), Event{Title: `say "hi"`, Message: "ok"}); err != nil {
+		t.Fatalf("darwin Notify() error = %v", err)
+	}
+	if gotName != "osascript" || !strings.Contains(gotArgs[1], `with title "say \"hi\""`) {
+		t.Errorf("unexpected darwin invocation: %s %v", gotName, gotArgs)
+	}
+
+	other := &DesktopSink{run: run, goos: "plan9"}
+	if err := other.Notify(context.Background(), Event{}); err == nil {
+		t.Error("expected error on unsupported platform")
+	}
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookSink POSTs events as JSON to a user-configured URL.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+type webhookPayload struct {
+	Event   string    `json:"event"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+}
+
+// NewWebhookSink creates a webhook sink. A nil client uses http.DefaultClient.
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookSink{url: strings.TrimSpace(url), client: client}
+}
+
+func (w *WebhookSink) Name() string { return SinkWebhook }
+
+func (w *WebhookSink) Notify(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:   ev.Kind,
+		Title:   ev.Title,
+		Message: ev.Message,
+		Time:    ev.Time,
+		Source:  "wtf_cli",
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
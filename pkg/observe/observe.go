@@ -0,0 +1,233 @@
+// Package observe lets a running session publish its terminal output and
+// chat over a Unix socket so read-only observers can follow along.
+//
+// The protocol is one JSON Event per line, server to client only. The server
+// never reads from observers, so an observer cannot type into the shell.
+package observe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/unixsock"
+)
+
+const (
+	// replayLimit is how much recent output a new observer receives so it
+	// does not start from a blank screen.
+	replayLimit = 64 * 1024
+	// clientQueue is how many events may be pending per observer before it
+	// is considered too slow and dropped. The session never waits on one.
+	clientQueue = 256
+)
+
+// Event types.
+const (
+	EventOutput = "output"
+	EventChat   = "chat"
+)
+
+// Event is one update sent to observers.
+type Event struct {
+	Type     string           `json:"type"`
+	Output   []byte           `json:"output,omitempty"`
+	Messages []ai.ChatMessage `json:"messages,omitempty"`
+	Title    string           `json:"title,omitempty"`
+}
+
+// Hub accepts observers on a Unix socket and fans out session events.
+type Hub struct {
+	path string
+	ln   net.Listener
+
+	mu      sync.Mutex
+	clients map[chan Event]struct{}
+	replay  []byte
+	chat    *Event
+	closed  bool
+}
+
+// Listen creates the socket at path, readable only by the current user; see
+// unixsock.Listen for what happens when path already exists.
+func Listen(path string) (*Hub, error) {
+	ln, err := unixsock.Listen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Hub{path: path, ln: ln, clients: make(map[chan Event]struct{})}
+	go h.acceptLoop()
+	slog.Info("observe_listen", "socket", path)
+	return h, nil
+}
+
+// Path returns the socket path.
+func (h *Hub) Path() string {
+	return h.path
+}
+
+// PublishOutput sends terminal output to observers.
+func (h *Hub) PublishOutput(data []byte) {
+	if h == nil || len(data) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replay = append(h.replay, data...)
+	if over := len(h.replay) - replayLimit; over > 0 {
+		h.replay = trimToLine(h.replay[over:])
+	}
+	h.broadcastLocked(Event{Type: EventOutput, Output: bytes.Clone(data)})
+}
+
+// PublishChat sends the current conversation to observers. The whole
+// conversation is sent each time so observers never drift out of sync.
+func (h *Hub) PublishChat(messages []ai.ChatMessage, title string) {
+	if h == nil {
+		return
+	}
+	ev := Event{Type: EventChat, Messages: append([]ai.ChatMessage(nil), messages...), Title: title}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.chat = &ev
+	h.broadcastLocked(ev)
+}
+
+// Close stops accepting observers, disconnects the current ones and removes
+// the socket.
+func (h *Hub) Close() error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	for ch := range h.clients {
+		close(ch)
+		delete(h.clients, ch)
+	}
+	h.mu.Unlock()
+
+	err := h.ln.Close()
+	if rmErr := os.Remove(h.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (h *Hub) acceptLoop() {
+	for {
+		conn, err := h.ln.Accept()
+		if err != nil {
+			return
+		}
+		h.addClient(conn)
+	}
+}
+
+func (h *Hub) addClient(conn net.Conn) {
+	ch := make(chan Event, clientQueue)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.Close()
+		return
+	}
+	if len(h.replay) > 0 {
+		ch <- Event{Type: EventOutput, Output: bytes.Clone(h.replay)}
+	}
+	if h.chat != nil {
+		ch <- *h.chat
+	}
+	h.clients[ch] = struct{}{}
+	count := len(h.clients)
+	h.mu.Unlock()
+
+	slog.Info("observe_client_connected", "observers", count)
+	go h.writeLoop(conn, ch)
+}
+
+func (h *Hub) writeLoop(conn net.Conn, ch chan Event) {
+	defer conn.Close()
+	w := bufio.NewWriter(conn)
+	enc := json.NewEncoder(w)
+	for ev := range ch {
+		if err := enc.Encode(ev); err != nil {
+			break
+		}
+		if len(ch) == 0 {
+			if err := w.Flush(); err != nil {
+				break
+			}
+		}
+	}
+	h.removeClient(ch)
+	slog.Info("observe_client_disconnected")
+}
+
+func (h *Hub) removeClient(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+func (h *Hub) broadcastLocked(ev Event) {
+	for ch := range h.clients {
+		select {
+		case ch <- ev:
+		default:
+			slog.Warn("observe_client_dropped", "reason", "slow")
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// trimToLine drops the partial line at the start of a truncated replay.
+func trimToLine(data []byte) []byte {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return data[i+1:]
+	}
+	return data
+}
+
+// Conn is an observer's connection to a session.
+type Conn struct {
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+// Dial connects to the session socket at path.
+func Dial(path string) (*Conn, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn: conn, dec: json.NewDecoder(bufio.NewReader(conn))}, nil
+}
+
+// Next blocks until the next event arrives.
+func (c *Conn) Next() (Event, error) {
+	var ev Event
+	err := c.dec.Decode(&ev)
+	return ev, err
+}
+
+// Close disconnects from the session.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
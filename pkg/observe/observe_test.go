@@ -0,0 +1,146 @@
+package observe
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/ai"
+)
+
+func socketPath(t *testing.T) string {
+	t.Helper()
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	dir, err := os.MkdirTemp("", "wtfobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "s.sock")
+}
+
+func nextEvent(t *testing.T, c *Conn) Event {
+	t.Helper()
+	type result struct {
+		ev  Event
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ev, err := c.Next()
+		done <- result{ev, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("Next() error: %v", r.err)
+		}
+		return r.ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestHub_ReplaysAndStreams(t *testing.T) {
+	path := socketPath(t)
+	hub, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer hub.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected socket mode 0600, got %v", info.Mode().Perm())
+	}
+
+	hub.PublishOutput([]byte("$ make\r\n"))
+	hub.PublishChat([]ai.ChatMessage{{Role: "user", Content: "why?"}}, "Build Failure")
+
+	conn, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer conn.Close()
+
+	ev := nextEvent(t, conn)
+	if ev.Type != EventOutput || string(ev.Output) != "$ make\r\n" {
+		t.Fatalf("Expected output replay, got %+v", ev)
+	}
+	ev = nextEvent(t, conn)
+	if ev.Type != EventChat || ev.Title != "Build Failure" || len(ev.Messages) != 1 {
+		t.Fatalf("Expected chat replay, got %+v", ev)
+	}
+
+	hub.PublishOutput([]byte("error: boom\r\n"))
+	ev = nextEvent(t, conn)
+	if ev.Type != EventOutput || string(ev.Output) != "error: boom\r\n" {
+		t.Fatalf("Expected live output, got %+v", ev)
+	}
+}
+
+func TestListen_RefusesLiveSocketAndReplacesStale(t *testing.T) {
+	path := socketPath(t)
+	hub, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Fatal("Expected error for a socket in use")
+	}
+	hub.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected Close to remove the socket, got %v", err)
+	}
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	hub, err = Listen(path)
+	if err != nil {
+		t.Fatalf("Expected stale socket to be replaced, got %v", err)
+	}
+	hub.Close()
+}
+
+func TestListen_RefusesRegularFile(t *testing.T) {
+	path := socketPath(t)
+	if err := os.WriteFile(path, []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Fatal("Expected error for a path that is not a socket")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "notes" {
+		t.Fatalf("Expected the file left in place, got %q, %v", data, err)
+	}
+}
+
+func TestHub_ReplayIsBounded(t *testing.T) {
+	path := socketPath(t)
+	hub, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer hub.Close()
+
+	line := []byte("0123456789012345678901234567890123456789\n")
+	for range 4 * replayLimit / len(line) {
+		hub.PublishOutput(line)
+	}
+	hub.mu.Lock()
+	size := len(hub.replay)
+	hub.mu.Unlock()
+	if size > replayLimit || size == 0 {
+		t.Fatalf("Expected replay bounded by %d, got %d", replayLimit, size)
+	}
+}
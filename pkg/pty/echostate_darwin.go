@@ -11,25 +11,26 @@ import (
 // IsEchoDisabled checks if the PTY has echo disabled (password entry mode).
 // This allows detecting when sudo or similar programs are prompting for passwords.
 func IsEchoDisabled(f *os.File) bool {
-	if f == nil {
-		return false
-	}
-	termios, err := unix.IoctlGetTermios(int(f.Fd()), unix.TIOCGETA)
-	if err != nil {
-		return false
-	}
-	return (termios.Lflag & unix.ECHO) == 0
+	echo, _, ok := termiosFlags(f)
+	return ok && !echo
 }
 
 // IsSecretInputMode detects canonical-mode secret entry (e.g. sudo password
 // prompts) by checking that echo is disabled while canonical mode is enabled.
 func IsSecretInputMode(f *os.File) bool {
+	echo, canonical, ok := termiosFlags(f)
+	return ok && !echo && canonical
+}
+
+// termiosFlags reads whether the PTY echoes input and reads it a line at a
+// time. ok is false when the settings cannot be read.
+func termiosFlags(f *os.File) (echo, canonical, ok bool) {
 	if f == nil {
-		return false
+		return false, false, false
 	}
 	termios, err := unix.IoctlGetTermios(int(f.Fd()), unix.TIOCGETA)
 	if err != nil {
-		return false
+		return false, false, false
 	}
-	return (termios.Lflag&unix.ECHO) == 0 && (termios.Lflag&unix.ICANON) != 0
+	return termios.Lflag&unix.ECHO != 0, termios.Lflag&unix.ICANON != 0, true
 }
@@ -16,3 +16,8 @@ func IsEchoDisabled(f *os.File) bool {
 func IsSecretInputMode(f *os.File) bool {
 	return false
 }
+
+// termiosFlags cannot read the PTY settings on unsupported platforms.
+func termiosFlags(f *os.File) (echo, canonical, ok bool) {
+	return false, false, false
+}
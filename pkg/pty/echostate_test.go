@@ -134,3 +134,48 @@ func TestIsSecretInputMode_TermiosToggle(t *testing.T) {
 		t.Error("Expected IsSecretInputMode true for ECHO off + ICANON on")
 	}
 }
+
+func TestIsSecretInput_RawModeNeedsPrompt(t *testing.T) {
+	ptmx, tty, err := cpty.Open()
+	if err != nil {
+		if ptyUnavailable(err) {
+			t.Skipf("PTY unavailable: %v", err)
+		}
+		t.Fatalf("Open PTY failed: %v", err)
+	}
+	defer ptmx.Close()
+	defer tty.Close()
+
+	ttyFD := int(tty.Fd())
+	original, err := getTermiosForTest(ttyFD)
+	if err != nil {
+		t.Fatalf("Failed to get termios: %v", err)
+	}
+	restore := *original
+	defer func() {
+		_ = setTermiosForTest(ttyFD, &restore)
+	}()
+
+	raw := restore
+	raw.Lflag &^= unix.ECHO | unix.ICANON
+	if err := setTermiosForTest(ttyFD, &raw); err != nil {
+		t.Fatalf("Failed to set termios: %v", err)
+	}
+
+	if IsSecretInput(ptmx, "ann@host:~$ ") {
+		t.Error("Expected raw mode without a secret prompt not to be secret input")
+	}
+	if !IsSecretInput(ptmx, "Enter passphrase for key '/home/ann/.ssh/id_ed25519': ") {
+		t.Error("Expected raw mode after a passphrase prompt to be secret input")
+	}
+
+	echoing := restore
+	echoing.Lflag |= unix.ECHO
+	echoing.Lflag &^= unix.ICANON
+	if err := setTermiosForTest(ttyFD, &echoing); err != nil {
+		t.Fatalf("Failed to set termios: %v", err)
+	}
+	if IsSecretInput(ptmx, "Password: ") {
+		t.Error("Expected echoed input not to be secret input")
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package pty
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GetEnviron returns the shell's environment as NAME=value entries by
+// reading /proc/<pid>/environ. The kernel records the environment the shell
+// started with, so variables exported later in the session are not seen.
+func (w *Wrapper) GetEnviron() ([]string, error) {
+	pid := w.GetPID()
+	if pid == 0 {
+		return nil, fmt.Errorf("no process running")
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environ: %w", err)
+	}
+
+	var entries []string
+	for _, entry := range strings.Split(string(data), "\x00") {
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package pty
+
+import "fmt"
+
+// GetEnviron is only implemented on Linux, where /proc exposes the
+// environment of another process.
+func (w *Wrapper) GetEnviron() ([]string, error) {
+	return nil, fmt.Errorf("reading the shell environment is not supported on this platform")
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+
+package pty
+
+import (
+	"os"
+)
+
+// ForegroundProcessGroup returns 0 on unsupported platforms.
+func ForegroundProcessGroup(f *os.File) int {
+	return 0
+}
+
+// ShellInForeground returns false on unsupported platforms.
+func ShellInForeground(f *os.File) bool {
+	return false
+}
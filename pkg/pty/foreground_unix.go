@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package pty
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// ForegroundProcessGroup returns the process group currently in the
+// foreground of the PTY. While the shell sits at its prompt this is the
+// shell's own group; while a command runs it is the command's job.
+// Returns 0 when it cannot be determined.
+func ForegroundProcessGroup(f *os.File) int {
+	if f == nil {
+		return 0
+	}
+	pgrp, err := unix.IoctlGetInt(int(f.Fd()), unix.TIOCGPGRP)
+	if err != nil {
+		return 0
+	}
+	return pgrp
+}
+
+// ShellInForeground reports whether the PTY's session leader — the shell —
+// owns the foreground, i.e. no job is running and the shell sits at its
+// prompt. The shell is spawned as a session leader, so its process group ID
+// equals its session ID; any job it starts gets a group of its own.
+func ShellInForeground(f *os.File) bool {
+	pgrp := ForegroundProcessGroup(f)
+	if pgrp <= 0 {
+		return false
+	}
+	sid, err := unix.Getsid(pgrp)
+	return err == nil && sid == pgrp
+}
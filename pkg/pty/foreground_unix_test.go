@@ -0,0 +1,21 @@
+//go:build linux || darwin
+
+package pty
+
+import "testing"
+
+func TestShellInForeground_IdleShell(t *testing.T) {
+	wrapper := requirePTY(t)
+	defer wrapper.Close()
+
+	if !ShellInForeground(wrapper.ptmx) {
+		t.Fatalf("expected idle shell to own the foreground (pgrp=%d, pid=%d)",
+			ForegroundProcessGroup(wrapper.ptmx), wrapper.GetPID())
+	}
+}
+
+func TestShellInForeground_NilFile(t *testing.T) {
+	if ShellInForeground(nil) {
+		t.Fatal("expected false for a nil file")
+	}
+}
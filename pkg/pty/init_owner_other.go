@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package pty
+
+import "os"
+
+// ownedByCurrentUser returns true on platforms without Unix file ownership.
+func ownedByCurrentUser(info os.FileInfo) bool {
+	return true
+}
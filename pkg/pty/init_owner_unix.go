@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByCurrentUser reports whether info belongs to the effective user.
+func ownedByCurrentUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+	return int(st.Uid) == os.Geteuid()
+}
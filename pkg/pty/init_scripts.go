@@ -0,0 +1,229 @@
+package pty
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wtf_cli/pkg/shellquote"
+)
+
+const (
+	// UserInitScript is sourced into every shell wtf_cli starts. The path is
+	// relative to the home directory.
+	UserInitScript = ".wtf_cli/init.sh"
+	// ProjectInitScript is sourced into shells started in the directory
+	// holding it, once its contents have been trusted with
+	// `wtf_cli trust-init`.
+	ProjectInitScript = ".wtf_init.sh"
+
+	trustedInitFilename = "trusted_init.json"
+)
+
+// InitScript is a bootstrap file sourced into the spawned shell.
+type InitScript struct {
+	Path   string
+	SHA256 string
+}
+
+// SkippedInit is a bootstrap file that exists but was not sourced.
+type SkippedInit struct {
+	Path   string
+	Reason string
+}
+
+// FindInitScripts returns the bootstrap files to source into a shell started
+// in dir: ~/.wtf_cli/init.sh, then dir/.wtf_init.sh if trusted lists its
+// current contents. Missing files are ignored; unsafe or untrusted files are
+// returned as skipped.
+func FindInitScripts(home, dir string, trusted map[string]string) (scripts []InitScript, skipped []SkippedInit) {
+	if home != "" {
+		path := filepath.Join(home, UserInitScript)
+		if script, reason, ok := checkInitScript(path); ok && reason == "" {
+			scripts = append(scripts, script)
+		} else if ok {
+			skipped = append(skipped, SkippedInit{Path: path, Reason: reason})
+		}
+	}
+
+	if dir == "" {
+		return scripts, skipped
+	}
+	path := filepath.Join(dir, ProjectInitScript)
+	script, reason, ok := checkInitScript(path)
+	if !ok {
+		return scripts, skipped
+	}
+	if reason == "" && trusted[path] != script.SHA256 {
+		reason = "not trusted; run `wtf_cli trust-init` in " + dir + " to allow it"
+	}
+	if reason != "" {
+		return scripts, append(skipped, SkippedInit{Path: path, Reason: reason})
+	}
+	return append(scripts, script), skipped
+}
+
+// checkInitScript hashes the file at path. ok is false when it does not
+// exist; reason is set when it exists but must not be sourced.
+func checkInitScript(path string) (script InitScript, reason string, ok bool) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return InitScript{}, "", false
+	}
+	if err != nil {
+		return InitScript{}, err.Error(), true
+	}
+	if !info.Mode().IsRegular() {
+		return InitScript{}, "not a regular file", true
+	}
+	if info.Mode().Perm()&0o022 != 0 {
+		return InitScript{}, "writable by group or others", true
+	}
+	if !ownedByCurrentUser(info) {
+		return InitScript{}, "not owned by the current user", true
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return InitScript{}, err.Error(), true
+	}
+	return InitScript{Path: path, SHA256: sum}, "", true
+}
+
+func fileSHA256(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DefaultTrustedInitPath returns where trusted project bootstrap files are
+// recorded.
+func DefaultTrustedInitPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".wtf_cli", trustedInitFilename)
+	}
+	return filepath.Join(homeDir, ".wtf_cli", trustedInitFilename)
+}
+
+// LoadTrustedInit reads the trusted project bootstrap files as a map of
+// path to SHA-256. A missing or unreadable file trusts nothing.
+func LoadTrustedInit(storePath string) map[string]string {
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		return nil
+	}
+	var trusted map[string]string
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		slog.Warn("shell_init_trust_read_failed", "path", storePath, "error", err)
+		return nil
+	}
+	return trusted
+}
+
+// TrustInitScript records the current contents of the project bootstrap file
+// at scriptPath as trusted. Editing the file revokes the trust.
+func TrustInitScript(storePath, scriptPath string) (InitScript, error) {
+	abs, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return InitScript{}, err
+	}
+	script, reason, ok := checkInitScript(abs)
+	if !ok {
+		return InitScript{}, fmt.Errorf("%s does not exist", abs)
+	}
+	if reason != "" {
+		return InitScript{}, fmt.Errorf("%s: %s", abs, reason)
+	}
+
+	trusted := LoadTrustedInit(storePath)
+	if trusted == nil {
+		trusted = make(map[string]string)
+	}
+	trusted[abs] = script.SHA256
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return InitScript{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(storePath), 0o700); err != nil {
+		return InitScript{}, err
+	}
+	if err := os.WriteFile(storePath, append(data, '\n'), 0o600); err != nil {
+		return InitScript{}, err
+	}
+	return script, nil
+}
+
+// shellInit prepares a shell to source scripts after its own startup files
+// and to print a line for each skipped file. It returns the extra arguments
+// and environment for the shell, and a temporary directory to remove once
+// the shell has exited. Shells other than bash and zsh are not supported.
+func shellInit(shell, home string, scripts []InitScript, skipped []SkippedInit) (args, env []string, tmpDir string, err error) {
+	if len(scripts) == 0 && len(skipped) == 0 {
+		return nil, nil, "", nil
+	}
+	name := filepath.Base(shell)
+	if name != "bash" && name != "zsh" {
+		return nil, nil, "", fmt.Errorf("bootstrap files are not supported for %s", name)
+	}
+
+	tmpDir, err = os.MkdirTemp("", "wtf_cli-init-")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	body := initScriptBody(scripts, skipped)
+
+	switch name {
+	case "bash":
+		rc := "[ -f ~/.bashrc ] && . ~/.bashrc\n" + body
+		rcPath := filepath.Join(tmpDir, "bashrc")
+		if err := os.WriteFile(rcPath, []byte(rc), 0o600); err != nil {
+			_ = os.RemoveAll(tmpDir)
+			return nil, nil, "", err
+		}
+		return []string{"--rcfile", rcPath}, nil, tmpDir, nil
+	default:
+		// zsh reads its startup files from $ZDOTDIR. Point it at tmpDir,
+		// chain to the user's own files and restore ZDOTDIR on the way.
+		// The user's .zshenv may move ZDOTDIR itself; the value it leaves
+		// is the one their .zshrc lives in.
+		userDir := os.Getenv("ZDOTDIR")
+		if userDir == "" {
+			userDir = home
+		}
+		zshenv := "ZDOTDIR=" + shellquote.Quote(userDir) + "\n" +
+			`[ -f "$ZDOTDIR/.zshenv" ] && . "$ZDOTDIR/.zshenv"` + "\n" +
+			`_wtf_zdotdir="$ZDOTDIR"` + "\n" +
+			"ZDOTDIR=" + shellquote.Quote(tmpDir) + "\n"
+		zshrc := `ZDOTDIR="$_wtf_zdotdir"` + "\n" +
+			"unset _wtf_zdotdir\n" +
+			`[ -f "$ZDOTDIR/.zshrc" ] && . "$ZDOTDIR/.zshrc"` + "\n" + body
+		for file, content := range map[string]string{".zshenv": zshenv, ".zshrc": zshrc} {
+			if err := os.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0o600); err != nil {
+				_ = os.RemoveAll(tmpDir)
+				return nil, nil, "", err
+			}
+		}
+		return nil, []string{"ZDOTDIR=" + tmpDir}, tmpDir, nil
+	}
+}
+
+// initScriptBody sources scripts and reports skipped files in the shell.
+func initScriptBody(scripts []InitScript, skipped []SkippedInit) string {
+	var sb strings.Builder
+	for _, s := range skipped {
+		sb.WriteString("printf '%s\\n' " + shellquote.Quote("wtf_cli: skipped "+s.Path+": "+s.Reason) + "\n")
+	}
+	for _, s := range scripts {
+		sb.WriteString(". " + shellquote.Quote(s.Path) + "\n")
+	}
+	return sb.String()
+}
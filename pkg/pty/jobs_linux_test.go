@@ -0,0 +1,70 @@
+//go:build linux
+
+package pty
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeProc adds a fake /proc/<pid> entry.
+func writeProc(t *testing.T, dir string, pid int, stat, cmdline string) {
+	t.Helper()
+	p := filepath.Join(dir, fmt.Sprint(pid))
+	if err := os.MkdirAll(p, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p, "cmdline"), []byte(cmdline), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	proc := t.TempDir()
+	// The shell (session 100) sits at its prompt: its group owns the tty.
+	writeProc(t, proc, 100, "100 (bash) S 1 100 100 34816 100 4194560", "bash\x00")
+	// A background server and a pipeline of two processes.
+	writeProc(t, proc, 200, "200 (python3) S 100 200 100 34816 100 0", "python3\x00-m\x00http.server\x008080\x00")
+	writeProc(t, proc, 301, "301 (grep) S 100 300 100 34816 100 0", "grep\x00err\x00")
+	writeProc(t, proc, 300, "300 (tail) S 100 300 100 34816 100 0", "tail\x00-f\x00app.log\x00")
+	// A job suspended with Ctrl+Z; its name has spaces and parentheses.
+	writeProc(t, proc, 400, "400 (vim (x) y) T 100 400 100 34816 100 0", "")
+	// A zombie, a process in another session and a non-process entry.
+	writeProc(t, proc, 500, "500 (sleep) Z 100 500 100 34816 100 0", "")
+	writeProc(t, proc, 600, "600 (sshd) S 1 600 600 0 -1 0", "sshd\x00")
+	if err := os.MkdirAll(filepath.Join(proc, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := listJobs(proc, 100)
+	if err != nil {
+		t.Fatalf("listJobs() error = %v", err)
+	}
+	want := []Job{
+		{PGID: 200, Command: "python3 -m http.server 8080"},
+		{PGID: 300, Command: "tail -f app.log"},
+		{PGID: 400, Command: "vim (x) y", Stopped: true},
+	}
+	if fmt.Sprint(jobs) != fmt.Sprint(want) {
+		t.Fatalf("listJobs() = %+v, want %+v", jobs, want)
+	}
+}
+
+func TestListJobs_ForegroundJobIsNotListed(t *testing.T) {
+	proc := t.TempDir()
+	writeProc(t, proc, 100, "100 (bash) S 1 100 100 34816 200 4194560", "bash\x00")
+	writeProc(t, proc, 200, "200 (make) S 100 200 100 34816 200 0", "make\x00")
+
+	jobs, err := listJobs(proc, 100)
+	if err != nil {
+		t.Fatalf("listJobs() error = %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected the foreground command to be skipped, got %+v", jobs)
+	}
+}
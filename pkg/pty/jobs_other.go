@@ -0,0 +1,11 @@
+//go:build !linux
+
+package pty
+
+import "fmt"
+
+// GetJobs is only implemented on Linux, where /proc exposes the process
+// groups of another session.
+func (w *Wrapper) GetJobs() ([]Job, error) {
+	return nil, fmt.Errorf("listing shell jobs is not supported on this platform")
+}
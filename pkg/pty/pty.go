@@ -3,16 +3,23 @@ package pty
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
+	"sync"
+	"syscall"
 
 	"github.com/creack/pty"
 )
 
 // Wrapper manages a pseudo-terminal session
 type Wrapper struct {
-	ptmx *os.File  // PTY master
-	cmd  *exec.Cmd // Child process
+	ptmx    *os.File  // PTY master
+	cmd     *exec.Cmd // Child process
+	initDir string    // Generated startup files, removed on Close
+
+	waitOnce sync.Once
+	waitErr  error
 }
 
 // SpawnShell creates a new PTY and spawns the user's shell in it
@@ -23,24 +30,68 @@ func SpawnShell() (*Wrapper, error) {
 		shell = "/bin/bash"
 	}
 
+	args, env, initDir := bootstrapShell(shell)
+
 	// Create command to run the shell
-	cmd := exec.Command(shell)
+	cmd := exec.Command(shell, args...)
 
 	// Inherit environment variables
-	cmd.Env = os.Environ()
+	cmd.Env = append(os.Environ(), env...)
 
 	// Start the command in a PTY
 	ptmx, err := pty.Start(cmd)
 	if err != nil {
+		if initDir != "" {
+			_ = os.RemoveAll(initDir)
+		}
 		return nil, fmt.Errorf("failed to start PTY: %w", err)
 	}
 
 	return &Wrapper{
-		ptmx: ptmx,
-		cmd:  cmd,
+		ptmx:    ptmx,
+		cmd:     cmd,
+		initDir: initDir,
 	}, nil
 }
 
+// SpawnCommand creates a new PTY and runs argv in it in place of a shell,
+// for `wtf_cli exec`. The command looks up argv[0] in PATH and inherits the
+// environment; no shell init is sourced.
+func SpawnCommand(argv []string) (*Wrapper, error) {
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("no command to run")
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = os.Environ()
+
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start PTY: %w", err)
+	}
+	return &Wrapper{ptmx: ptmx, cmd: cmd}, nil
+}
+
+// bootstrapShell finds the bootstrap files for a shell started in the
+// current directory and logs what will be sourced. Failures are logged and
+// leave the shell to start as usual.
+func bootstrapShell(shell string) (args, env []string, initDir string) {
+	home, _ := os.UserHomeDir()
+	dir, _ := os.Getwd()
+	scripts, skipped := FindInitScripts(home, dir, LoadTrustedInit(DefaultTrustedInitPath()))
+	for _, s := range skipped {
+		slog.Warn("shell_init_skipped", "path", s.Path, "reason", s.Reason)
+	}
+	args, env, initDir, err := shellInit(shell, home, scripts, skipped)
+	if err != nil {
+		slog.Warn("shell_init_failed", "shell", shell, "error", err)
+		return nil, nil, ""
+	}
+	for _, s := range scripts {
+		slog.Info("shell_init", "shell", shell, "path", s.Path, "sha256", s.SHA256)
+	}
+	return args, env, initDir
+}
+
 // ProxyIO handles bidirectional I/O between the PTY and stdin/stdout
 func (w *Wrapper) ProxyIO() error {
 	// Copy stdin to PTY
@@ -54,13 +105,34 @@ func (w *Wrapper) ProxyIO() error {
 	return nil
 }
 
-// Wait waits for the shell process to exit
+// Wait waits for the shell process to exit. It may be called more than
+// once; later calls return what the first did.
 func (w *Wrapper) Wait() error {
-	return w.cmd.Wait()
+	w.waitOnce.Do(func() { w.waitErr = w.cmd.Wait() })
+	return w.waitErr
+}
+
+// ExitCode waits for the process to exit and returns its exit status, or
+// 128 plus the signal number when a signal killed it, the way shells report
+// it.
+func (w *Wrapper) ExitCode() int {
+	_ = w.Wait()
+	state := w.cmd.ProcessState
+	if state == nil {
+		return 1
+	}
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
 }
 
 // Close cleans up the PTY resources
 func (w *Wrapper) Close() error {
+	if w.initDir != "" {
+		_ = os.RemoveAll(w.initDir)
+		w.initDir = ""
+	}
 	if w.ptmx != nil {
 		err := w.ptmx.Close()
 		w.ptmx = nil // Prevent double close
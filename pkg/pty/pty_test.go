@@ -1,6 +1,7 @@
 package pty
 
 import (
+	"io"
 	"os"
 	"testing"
 )
@@ -61,3 +62,42 @@ func TestWrapper_Close(t *testing.T) {
 		t.Errorf("Second Close() failed: %v", err)
 	}
 }
+
+func TestSpawnCommand_ExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		argv []string
+		want int
+	}{
+		{"success", []string{"sh", "-c", "exit 0"}, 0},
+		{"failure", []string{"sh", "-c", "exit 3"}, 3},
+		{"signal", []string{"sh", "-c", "kill -TERM $$"}, 128 + 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapper, err := SpawnCommand(tt.argv)
+			if err != nil {
+				if ptyUnavailable(err) {
+					t.Skipf("PTY unavailable: %v", err)
+				}
+				t.Fatalf("SpawnCommand() failed: %v", err)
+			}
+			defer wrapper.Close()
+			// Drain the PTY so the command is not blocked writing to it.
+			go io.Copy(io.Discard, wrapper.GetPTY())
+
+			if got := wrapper.ExitCode(); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+			if got := wrapper.ExitCode(); got != tt.want {
+				t.Errorf("second ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpawnCommand_Empty(t *testing.T) {
+	if _, err := SpawnCommand(nil); err == nil {
+		t.Error("SpawnCommand(nil) should fail")
+	}
+}
@@ -0,0 +1,30 @@
+package pty
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// secretPromptPattern matches the prompts of programs that read a secret,
+// e.g. "[sudo] password for ann:", "Enter passphrase for key
+// '/home/ann/.ssh/id_ed25519':" or gpg's pinentry-tty "PIN:". The prompt
+// must end the line with a colon, so a shell prompt in a directory named
+// "passwords" does not count.
+var secretPromptPattern = regexp.MustCompile(`(?i)(password|passphrase|pass phrase|passcode|\bpin\b|verification code|one-time code)[^:]*:$`)
+
+// IsSecretPrompt reports whether line, the text the cursor sits after,
+// asks for a password, passphrase or PIN.
+func IsSecretPrompt(line string) bool {
+	return secretPromptPattern.MatchString(strings.TrimSpace(line))
+}
+
+// IsSecretInput reports whether the program in the foreground of the PTY is
+// reading a secret. Echo is off either way. Programs that read a line, such
+// as sudo, passwd or read -s, keep canonical mode; those that read raw keys,
+// such as ssh's askpass fallbacks or gpg's pinentry-tty, are recognized by
+// promptLine, the unfinished line they printed.
+func IsSecretInput(f *os.File, promptLine string) bool {
+	echo, canonical, ok := termiosFlags(f)
+	return ok && !echo && (canonical || IsSecretPrompt(promptLine))
+}
@@ -0,0 +1,27 @@
+package pty
+
+import "testing"
+
+func TestIsSecretPrompt(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"[sudo] password for ann: ", true},
+		{"ann@example.com's password: ", true},
+		{"Enter passphrase for key '/home/ann/.ssh/id_ed25519': ", true},
+		{"Passphrase:", true},
+		{"PIN:", true},
+		{"Enter PIN for 'OpenPGP card':", true},
+		{"Verification code: ", true},
+		{"ann@host:~/passwords$ ", false},
+		{"ann@host:~$ echo password", false},
+		{"Spinning up: ", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsSecretPrompt(tt.line); got != tt.want {
+			t.Errorf("IsSecretPrompt(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -26,6 +26,19 @@ func SpawnShellWithBuffer(bufferSize int) (*BufferedWrapper, error) {
 	}, nil
 }
 
+// SpawnCommandWithBuffer runs argv in a new PTY with output buffering.
+func SpawnCommandWithBuffer(bufferSize int, argv []string) (*BufferedWrapper, error) {
+	wrapper, err := SpawnCommand(argv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BufferedWrapper{
+		Wrapper: wrapper,
+		buffer:  buffer.New(bufferSize),
+	}, nil
+}
+
 // lineWriter writes complete lines to the buffer
 type lineWriter struct {
 	buffer      *buffer.CircularBuffer
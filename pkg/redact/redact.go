@@ -0,0 +1,101 @@
+// Package redact finds credentials in free text and masks them before the
+// text leaves the machine.
+package redact
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Match is one credential found in a text. Start and End are byte offsets of
+// the secret itself; for "name=value" style matches that is only the value.
+type Match struct {
+	Kind  string
+	Start int
+	End   int
+}
+
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+	// group is the submatch holding the secret; 0 means the whole match.
+	group int
+}
+
+var patterns = []pattern{
+	{kind: "private key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)(?:-----END [A-Z ]*PRIVATE KEY-----|\z)`)},
+	{kind: "AWS access key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "GitHub token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+	{kind: "API key", re: regexp.MustCompile(`\bsk-(?:ant-|proj-|or-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: "Google API key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{kind: "Slack token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{kind: "JWT", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{kind: "bearer token", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{20,}=*)`), group: 1},
+	{kind: "password or secret", re: regexp.MustCompile(`(?i)\b[\w.-]*(?:api[_-]?key|secret|token|passw(?:or)?d)[\w.-]*["']?\s*[:=]\s*["']?([^\s"'` + "`" + `]{8,})`), group: 1},
+}
+
+// Find returns the credentials in text ordered by position. Overlapping
+// matches are merged into the earliest one.
+func Find(text string) []Match {
+	var matches []Match
+	for _, p := range patterns {
+		for _, idx := range p.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := idx[2*p.group], idx[2*p.group+1]
+			if start < 0 || start == end {
+				continue
+			}
+			matches = append(matches, Match{Kind: p.kind, Start: start, End: end})
+		}
+	}
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.Start != b.Start {
+			return a.Start - b.Start
+		}
+		return b.End - a.End
+	})
+
+	merged := matches[:0]
+	for _, m := range matches {
+		if n := len(merged); n > 0 && m.Start < merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, m.End)
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+// Contains reports whether text holds anything that looks like a credential.
+func Contains(text string) bool {
+	return len(Find(text)) > 0
+}
+
+// Kinds returns the distinct kinds of credential found in text, in order of
+// first appearance.
+func Kinds(text string) []string {
+	var kinds []string
+	for _, m := range Find(text) {
+		if !slices.Contains(kinds, m.Kind) {
+			kinds = append(kinds, m.Kind)
+		}
+	}
+	return kinds
+}
+
+// Redact replaces every credential in text with a [REDACTED ...] marker.
+func Redact(text string) string {
+	matches := Find(text)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m.Start])
+		b.WriteString("[REDACTED " + m.Kind + "]")
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
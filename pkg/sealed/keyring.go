@@ -0,0 +1,81 @@
+package sealed
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	keyringService = "wtf_cli"
+	keyringAccount = "config-encryption-key"
+)
+
+// ErrNoKeyringKey is returned by Keyring.Get when no key has been stored.
+var ErrNoKeyringKey = errors.New("no wtf_cli key in the OS keyring")
+
+// Keyring stores the key of MethodKeyring files.
+type Keyring interface {
+	Get() ([]byte, error)
+	Set(key []byte) error
+}
+
+// SystemKeyring is the OS keyring: the login keychain on macOS, the Secret
+// Service (through secret-tool) elsewhere.
+var SystemKeyring Keyring = commandKeyring{}
+
+// NewKeyringKey returns a random key for MethodKeyring.
+func NewKeyringKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+type commandKeyring struct{}
+
+func (commandKeyring) Get() ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, ErrNoKeyringKey
+		}
+		return nil, fmt.Errorf("keyring unavailable: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("keyring entry is not a wtf_cli key: %w", err)
+	}
+	return key, nil
+}
+
+func (commandKeyring) Set(key []byte) error {
+	secret := hex.EncodeToString(key)
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// security only takes the password as an argument.
+		cmd = exec.Command("security", "add-generic-password", "-U", "-s", keyringService, "-a", keyringAccount, "-w", secret)
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label=wtf_cli config encryption key", "service", keyringService, "account", keyringAccount)
+		cmd.Stdin = strings.NewReader(secret)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("could not store the key in the keyring: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
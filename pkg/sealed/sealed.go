@@ -0,0 +1,286 @@
+// Package sealed encrypts wtf_cli's config and credential files at rest, for
+// machines where file permissions alone do not keep other users out.
+//
+// A sealed file is a JSON envelope holding AES-256-GCM ciphertext. The key is
+// derived from a passphrase with argon2id, or is a random key kept in the OS
+// keyring. The secret is supplied once per process through a Session, which
+// caches derived keys so later reads and writes do not prompt again.
+package sealed
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Method is how the key of a sealed file is obtained.
+type Method string
+
+const (
+	// MethodPassphrase derives the key from a passphrase with argon2id.
+	MethodPassphrase Method = "passphrase"
+	// MethodKeyring uses a random key stored in the OS keyring.
+	MethodKeyring Method = "keyring"
+)
+
+const (
+	formatVersion = 1
+	keySize       = 32
+	saltSize      = 16
+)
+
+// KDFParams are the argon2id cost parameters, stored with each file so they
+// can be raised later without breaking existing files.
+type KDFParams struct {
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memory_kib"`
+	Threads   uint8  `json:"threads"`
+}
+
+// DefaultKDFParams are used when sealing with a passphrase.
+var DefaultKDFParams = KDFParams{Time: 3, MemoryKiB: 64 * 1024, Threads: 4}
+
+// Limits on the KDF parameters of a file being opened. They come from the
+// file itself, so a damaged or hostile file could otherwise make argon2id
+// panic (zero passes or threads) or stall and exhaust memory.
+const (
+	maxKDFTime      = 16
+	maxKDFMemoryKiB = 1024 * 1024 // 1 GiB
+)
+
+// validate reports parameters outside the limits wtf_cli will derive a key
+// with. Threads is a uint8, which already caps it at 255.
+func (p KDFParams) validate() error {
+	switch {
+	case p.Time < 1 || p.Time > maxKDFTime:
+		return fmt.Errorf("key derivation time %d is outside 1-%d", p.Time, maxKDFTime)
+	case p.Threads < 1:
+		return errors.New("key derivation threads must be at least 1")
+	case p.MemoryKiB < 8*uint32(p.Threads) || p.MemoryKiB > maxKDFMemoryKiB:
+		return fmt.Errorf("key derivation memory %d KiB is outside %d-%d KiB", p.MemoryKiB, 8*uint32(p.Threads), maxKDFMemoryKiB)
+	}
+	return nil
+}
+
+var (
+	// ErrLocked is returned when reading a sealed file before the session
+	// was unlocked.
+	ErrLocked = errors.New("file is encrypted; unlock it first")
+	// ErrWrongSecret is returned when the passphrase or key does not open
+	// a sealed file.
+	ErrWrongSecret = errors.New("wrong passphrase or key")
+)
+
+type envelope struct {
+	Version    int        `json:"wtf_cli_encrypted"`
+	Method     Method     `json:"method"`
+	KDF        *KDFParams `json:"kdf,omitempty"`
+	Salt       []byte     `json:"salt,omitempty"`
+	Nonce      []byte     `json:"nonce"`
+	Ciphertext []byte     `json:"ciphertext"`
+}
+
+func parseEnvelope(data []byte) (envelope, bool) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Version == 0 {
+		return envelope{}, false
+	}
+	return env, true
+}
+
+// MethodOf reports how data was sealed, or false for plain contents.
+func MethodOf(data []byte) (Method, bool) {
+	env, ok := parseEnvelope(data)
+	return env.Method, ok
+}
+
+// Session holds the secret for the files of one process.
+type Session struct {
+	mu         sync.Mutex
+	method     Method
+	passphrase []byte
+	key        []byte
+	derived    map[string][]byte // salt -> argon2id key
+	sealSalt   []byte
+}
+
+// Default is the session used by config and credential storage.
+var Default = &Session{}
+
+// UnlockPassphrase makes the session seal and open files with passphrase.
+func (s *Session) UnlockPassphrase(passphrase []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+	s.method = MethodPassphrase
+	s.passphrase = append([]byte(nil), passphrase...)
+}
+
+// UnlockKey makes the session seal and open files with a keyring key.
+func (s *Session) UnlockKey(key []byte) error {
+	if len(key) != keySize {
+		return fmt.Errorf("keyring key must be %d bytes, got %d", keySize, len(key))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+	s.method = MethodKeyring
+	s.key = append([]byte(nil), key...)
+	return nil
+}
+
+// Lock forgets the secret. Files written afterwards are plain.
+func (s *Session) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+}
+
+func (s *Session) reset() {
+	clear(s.passphrase)
+	clear(s.key)
+	for _, k := range s.derived {
+		clear(k)
+	}
+	s.method = ""
+	s.passphrase = nil
+	s.key = nil
+	s.derived = nil
+	s.sealSalt = nil
+}
+
+// Method returns how the session seals files, or "" while locked.
+func (s *Session) Method() Method {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.method
+}
+
+// Open returns the plain contents of data, decrypting it if it is sealed.
+func (s *Session) Open(data []byte) ([]byte, error) {
+	env, ok := parseEnvelope(data)
+	if !ok {
+		return data, nil
+	}
+	if env.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported encrypted file version %d", env.Version)
+	}
+
+	s.mu.Lock()
+	key, err := s.keyFor(env)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongSecret
+	}
+	return plain, nil
+}
+
+// Seal encrypts plain with the session's secret. A locked session returns
+// plain unchanged.
+func (s *Session) Seal(plain []byte) ([]byte, error) {
+	s.mu.Lock()
+	if s.method == "" {
+		s.mu.Unlock()
+		return plain, nil
+	}
+	env := envelope{Version: formatVersion, Method: s.method}
+	if s.method == MethodPassphrase {
+		if s.sealSalt == nil {
+			s.sealSalt = make([]byte, saltSize)
+			if _, err := rand.Read(s.sealSalt); err != nil {
+				s.mu.Unlock()
+				return nil, err
+			}
+		}
+		params := DefaultKDFParams
+		env.KDF = &params
+		env.Salt = s.sealSalt
+	}
+	key, err := s.keyFor(env)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, err
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plain, nil)
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// keyFor returns the key that opens env. s.mu must be held.
+func (s *Session) keyFor(env envelope) ([]byte, error) {
+	if s.method == "" {
+		return nil, ErrLocked
+	}
+	if env.Method != s.method {
+		return nil, fmt.Errorf("file is encrypted with the %s method, but the session was unlocked with the %s method", env.Method, s.method)
+	}
+	if env.Method == MethodKeyring {
+		return s.key, nil
+	}
+	if env.KDF == nil || len(env.Salt) == 0 {
+		return nil, errors.New("encrypted file is missing its key derivation parameters")
+	}
+	if err := env.KDF.validate(); err != nil {
+		return nil, fmt.Errorf("encrypted file has bad key derivation parameters: %w", err)
+	}
+	cacheKey := fmt.Sprintf("%x/%d/%d/%d", env.Salt, env.KDF.Time, env.KDF.MemoryKiB, env.KDF.Threads)
+	if key, ok := s.derived[cacheKey]; ok {
+		return key, nil
+	}
+	key := argon2.IDKey(s.passphrase, env.Salt, env.KDF.Time, env.KDF.MemoryKiB, env.KDF.Threads, keySize)
+	if s.derived == nil {
+		s.derived = make(map[string][]byte)
+	}
+	s.derived[cacheKey] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadFile reads path and decrypts it with the Default session if sealed.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Default.Open(data)
+}
+
+// WriteFile writes data to path, sealed when the Default session is
+// unlocked.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	sealed, err := Default.Seal(data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, perm)
+}
@@ -0,0 +1,166 @@
+package sealed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cheapKDF keeps argon2id fast in tests.
+func cheapKDF(t *testing.T) {
+	t.Helper()
+	saved := DefaultKDFParams
+	DefaultKDFParams = KDFParams{Time: 1, MemoryKiB: 64, Threads: 1}
+	t.Cleanup(func() { DefaultKDFParams = saved })
+}
+
+func TestSession_PassphraseRoundTrip(t *testing.T) {
+	cheapKDF(t)
+	plain := []byte(`{"openrouter":{"api_key":"sk-secret"}}`)
+
+	s := &Session{}
+	s.UnlockPassphrase([]byte("correct horse"))
+	data, err := s.Seal(plain)
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+	if bytes.Contains(data, []byte("sk-secret")) {
+		t.Fatal("expected the sealed file not to contain the plain text")
+	}
+	if m, ok := MethodOf(data); !ok || m != MethodPassphrase {
+		t.Fatalf("MethodOf() = %q, %v; want passphrase", m, ok)
+	}
+
+	other := &Session{}
+	other.UnlockPassphrase([]byte("correct horse"))
+	got, err := other.Open(data)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatalf("Open() = %s, want %s", got, plain)
+	}
+
+	wrong := &Session{}
+	wrong.UnlockPassphrase([]byte("battery staple"))
+	if _, err := wrong.Open(data); !errors.Is(err, ErrWrongSecret) {
+		t.Fatalf("Open() with the wrong passphrase = %v, want ErrWrongSecret", err)
+	}
+	if _, err := (&Session{}).Open(data); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Open() while locked = %v, want ErrLocked", err)
+	}
+}
+
+func TestSession_KeyringRoundTrip(t *testing.T) {
+	key, err := NewKeyringKey()
+	if err != nil {
+		t.Fatalf("NewKeyringKey() error: %v", err)
+	}
+	s := &Session{}
+	if err := s.UnlockKey(key); err != nil {
+		t.Fatalf("UnlockKey() error: %v", err)
+	}
+	data, err := s.Seal([]byte("{}"))
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+	if m, _ := MethodOf(data); m != MethodKeyring {
+		t.Fatalf("MethodOf() = %q, want keyring", m)
+	}
+	if got, err := s.Open(data); err != nil || string(got) != "{}" {
+		t.Fatalf("Open() = %q, %v", got, err)
+	}
+
+	passphrase := &Session{}
+	passphrase.UnlockPassphrase([]byte("x"))
+	if _, err := passphrase.Open(data); err == nil {
+		t.Fatal("expected a passphrase session not to open a keyring file")
+	}
+	if err := s.UnlockKey([]byte("short")); err == nil {
+		t.Fatal("expected an error for a key of the wrong size")
+	}
+}
+
+func TestSession_PlainAndLocked(t *testing.T) {
+	s := &Session{}
+	plain := []byte(`{"llm_provider":"openrouter"}`)
+	if got, err := s.Seal(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("Seal() while locked = %s, %v; want the plain text", got, err)
+	}
+	if got, err := s.Open(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("Open() of a plain file = %s, %v", got, err)
+	}
+
+	cheapKDF(t)
+	s.UnlockPassphrase([]byte("p"))
+	s.Lock()
+	if s.Method() != "" {
+		t.Fatal("expected Lock to forget the method")
+	}
+	if got, _ := s.Seal(plain); !bytes.Equal(got, plain) {
+		t.Fatal("expected writes after Lock to be plain")
+	}
+}
+
+func TestSession_RejectsOutOfBoundsKDF(t *testing.T) {
+	cheapKDF(t)
+	s := &Session{}
+	s.UnlockPassphrase([]byte("p"))
+	data, err := s.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		kdf  KDFParams
+	}{
+		{"zero time", KDFParams{Time: 0, MemoryKiB: 64, Threads: 1}},
+		{"huge time", KDFParams{Time: 1 << 30, MemoryKiB: 64, Threads: 1}},
+		{"zero threads", KDFParams{Time: 1, MemoryKiB: 64, Threads: 0}},
+		{"zero memory", KDFParams{Time: 1, MemoryKiB: 0, Threads: 1}},
+		{"huge memory", KDFParams{Time: 1, MemoryKiB: 1 << 31, Threads: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, _ := parseEnvelope(data)
+			kdf := tt.kdf
+			env.KDF = &kdf
+			tampered, err := json.Marshal(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fresh := &Session{}
+			fresh.UnlockPassphrase([]byte("p"))
+			if _, err := fresh.Open(tampered); err == nil || !strings.Contains(err.Error(), "key derivation") {
+				t.Fatalf("Open() = %v, want a key derivation parameter error", err)
+			}
+		})
+	}
+}
+
+func TestReadWriteFile(t *testing.T) {
+	cheapKDF(t)
+	t.Cleanup(Default.Lock)
+	path := filepath.Join(t.TempDir(), "auth.json")
+
+	Default.UnlockPassphrase([]byte("p"))
+	if err := WriteFile(path, []byte(`{"credentials":{}}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if _, ok := MethodOf(raw); !ok {
+		t.Fatalf("expected a sealed file on disk, got %s", raw)
+	}
+	got, err := ReadFile(path)
+	if err != nil || string(got) != `{"credentials":{}}` {
+		t.Fatalf("ReadFile() = %q, %v", got, err)
+	}
+}
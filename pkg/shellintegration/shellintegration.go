@@ -0,0 +1,225 @@
+// Package shellintegration renders and installs the shell widget that lets
+// users ask wtf_cli about their last command without running the shell
+// inside the TUI.
+package shellintegration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wtf_cli/pkg/shellquote"
+)
+
+// Supported shells.
+const (
+	ShellZsh  = "zsh"
+	ShellBash = "bash"
+)
+
+// DefaultKey is the widget binding used when none is given.
+const DefaultKey = "alt+w"
+
+// outputLines is how much of the recorded session the widget sends.
+const outputLines = 200
+
+const (
+	rcBeginMarker = "# >>> wtf_cli shell integration >>>"
+	rcEndMarker   = "# <<< wtf_cli shell integration <<<"
+)
+
+// Options configure Install.
+type Options struct {
+	Shell   string // ShellZsh or ShellBash
+	Key     string // "alt+<letter>" or "ctrl+<letter>"
+	Binary  string // Absolute path of the wtf_cli executable
+	HomeDir string // Where ~/.wtf_cli and the rc file live
+}
+
+// Result reports what Install wrote.
+type Result struct {
+	ScriptPath string
+	RCPath     string
+	RCUpdated  bool // False when the rc file already sourced the script
+}
+
+// DetectShell returns the shell named by $SHELL when it is supported.
+func DetectShell(shellEnv string) (string, error) {
+	name := filepath.Base(strings.TrimSpace(shellEnv))
+	switch name {
+	case ShellZsh, ShellBash:
+		return name, nil
+	case "", ".":
+		return "", fmt.Errorf("cannot detect shell: $SHELL is empty, pass --shell")
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: zsh, bash)", name)
+	}
+}
+
+// Script renders the widget for shell, bound to key, invoking binary.
+func Script(shell, key, binary string) (string, error) {
+	if strings.TrimSpace(binary) == "" {
+		binary = "wtf_cli"
+	}
+	binding, err := keyBinding(shell, key)
+	if err != nil {
+		return "", err
+	}
+
+	var tmpl string
+	switch shell {
+	case ShellZsh:
+		tmpl = zshTemplate
+	case ShellBash:
+		tmpl = bashTemplate
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: zsh, bash)", shell)
+	}
+
+	r := strings.NewReplacer(
+		"{{BINARY}}", shellquote.Quote(binary),
+		"{{BINDING}}", binding,
+		"{{KEY}}", normalizeKey(key),
+		"{{LINES}}", fmt.Sprint(outputLines),
+	)
+	return r.Replace(commonHeader + tmpl), nil
+}
+
+// Install writes the widget to ~/.wtf_cli/shell/wtf.<shell> and sources it
+// from the shell's rc file. Re-running only refreshes the script.
+func Install(opts Options) (Result, error) {
+	if opts.Key == "" {
+		opts.Key = DefaultKey
+	}
+	script, err := Script(opts.Shell, opts.Key, opts.Binary)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{
+		ScriptPath: filepath.Join(opts.HomeDir, ".wtf_cli", "shell", "wtf."+opts.Shell),
+		RCPath:     filepath.Join(opts.HomeDir, rcFile(opts.Shell)),
+	}
+	if err := os.MkdirAll(filepath.Dir(res.ScriptPath), 0700); err != nil {
+		return Result{}, fmt.Errorf("create shell integration directory: %w", err)
+	}
+	if err := os.WriteFile(res.ScriptPath, []byte(script), 0600); err != nil {
+		return Result{}, fmt.Errorf("write shell integration: %w", err)
+	}
+
+	existing, err := os.ReadFile(res.RCPath)
+	if err != nil && !os.IsNotExist(err) {
+		return Result{}, fmt.Errorf("read %s: %w", res.RCPath, err)
+	}
+	if strings.Contains(string(existing), rcBeginMarker) {
+		return res, nil
+	}
+
+	block := fmt.Sprintf("\n%s\n[ -f %s ] && source %s\n%s\n",
+		rcBeginMarker, shellquote.Quote(res.ScriptPath), shellquote.Quote(res.ScriptPath), rcEndMarker)
+	f, err := os.OpenFile(res.RCPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return Result{}, fmt.Errorf("open %s: %w", res.RCPath, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(block); err != nil {
+		return Result{}, fmt.Errorf("update %s: %w", res.RCPath, err)
+	}
+	res.RCUpdated = true
+	return res, nil
+}
+
+func rcFile(shell string) string {
+	if shell == ShellZsh {
+		return ".zshrc"
+	}
+	return ".bashrc"
+}
+
+// keyBinding converts "alt+w" / "ctrl+g" into the shell's key notation.
+func keyBinding(shell, key string) (string, error) {
+	mod, letter, ok := strings.Cut(normalizeKey(key), "+")
+	if !ok || len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+		return "", fmt.Errorf("invalid key %q: use alt+<letter> or ctrl+<letter>", key)
+	}
+	switch mod {
+	case "alt":
+		return `\e` + letter, nil
+	case "ctrl":
+		if shell == ShellZsh {
+			return "^" + strings.ToUpper(letter), nil
+		}
+		return `\C-` + letter, nil
+	default:
+		return "", fmt.Errorf("invalid key %q: use alt+<letter> or ctrl+<letter>", key)
+	}
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return DefaultKey
+	}
+	return key
+}
+
+const commonHeader = `# wtf_cli shell integration — generated by ` + "`wtf_cli install-shell-integration`" + `.
+# Press {{KEY}} to explain the last command without leaving your shell.
+#
+# The last command and its exit status are always sent. Its output is only
+# available when the shell runs under ` + "`wtf-record`" + `, which logs the session
+# with script(1); otherwise the answer is based on the command alone.
+
+wtf-record() {
+  # The log holds everything shown on screen, so keep it in a private
+  # (0700) directory with an unguessable name.
+  local dir
+  dir="$(mktemp -d "${TMPDIR:-/tmp}/wtf-record.XXXXXX")" || return
+  export WTF_TYPESCRIPT="$dir/typescript"
+  if script --version >/dev/null 2>&1; then
+    script -q -f "$WTF_TYPESCRIPT"      # util-linux
+  else
+    script -q -F "$WTF_TYPESCRIPT"      # BSD / macOS
+  fi
+  rm -rf "$dir"
+  unset WTF_TYPESCRIPT
+}
+
+_wtf_explain_last() {
+  local last_cmd
+  last_cmd="$(fc -ln -1 2>/dev/null)"
+  last_cmd="${last_cmd#"${last_cmd%%[![:space:]]*}"}"
+  if [ -n "$WTF_TYPESCRIPT" ] && [ -r "$WTF_TYPESCRIPT" ]; then
+    tail -n {{LINES}} "$WTF_TYPESCRIPT" | {{BINARY}} explain --command "$last_cmd" --exit-code "$_wtf_last_status" --cwd "$PWD"
+  else
+    {{BINARY}} explain --command "$last_cmd" --exit-code "$_wtf_last_status" --cwd "$PWD" </dev/null
+  fi
+}
+`
+
+const zshTemplate = `
+typeset -g _wtf_last_status=0
+_wtf_precmd() { _wtf_last_status=$? }
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _wtf_precmd
+
+_wtf_explain_widget() {
+  zle -I
+  _wtf_explain_last
+  zle reset-prompt
+}
+zle -N _wtf_explain_widget
+bindkey '{{BINDING}}' _wtf_explain_widget
+`
+
+const bashTemplate = `
+_wtf_last_status=0
+_wtf_prompt_hook() { _wtf_last_status=$?; }
+case ";${PROMPT_COMMAND:-};" in
+  *";_wtf_prompt_hook;"*) ;;
+  *) PROMPT_COMMAND="_wtf_prompt_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+
+bind -x '"{{BINDING}}": _wtf_explain_last'
+`
@@ -0,0 +1,144 @@
+package shellintegration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScript_KeyBindings(t *testing.T) {
+	tests := []struct {
+		shell, key, want string
+	}{
+		{ShellZsh, "alt+w", `bindkey '\ew' _wtf_explain_widget`},
+		{ShellZsh, "Ctrl+G", `bindkey '^G' _wtf_explain_widget`},
+		{ShellBash, "alt+w", `bind -x '"\ew": _wtf_explain_last'`},
+		{ShellBash, "ctrl+g", `bind -x '"\C-g": _wtf_explain_last'`},
+	}
+	for _, tt := range tests {
+		script, err := Script(tt.shell, tt.key, "/usr/local/bin/wtf_cli")
+		if err != nil {
+			t.Fatalf("Script(%s, %s) error: %v", tt.shell, tt.key, err)
+		}
+		if !strings.Contains(script, tt.want) {
+			t.Errorf("Script(%s, %s) missing %q", tt.shell, tt.key, tt.want)
+		}
+		if !strings.Contains(script, "'/usr/local/bin/wtf_cli' explain --command") {
+			t.Errorf("Script(%s) does not invoke one-shot explain with the binary path", tt.shell)
+		}
+	}
+}
+
+func TestScript_RecordsIntoPrivateDir(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	script, err := Script(ShellBash, DefaultKey, "wtf_cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	rc := filepath.Join(dir, "rc.bash")
+	if err := os.WriteFile(rc, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A stand-in for script(1) that reports where it was asked to log.
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fake := "#!/bin/sh\n[ \"$1\" = --version ] && exit 0\necho \"$3\" > \"$OUT\"\nls -ld \"$(dirname \"$3\")\" >> \"$OUT\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "script"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	cmd := exec.Command(bash, "-c", `. "$RC" 2>/dev/null; wtf-record`)
+	cmd.Env = append(os.Environ(), "RC="+rc, "OUT="+out, "TMPDIR="+dir, "PATH="+bin+":"+os.Getenv("PATH"))
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wtf-record: %v: %s", err, msg)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("script was not run: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected fake script output %q", data)
+	}
+	logDir := filepath.Dir(lines[0])
+	if filepath.Dir(logDir) != dir || !strings.HasPrefix(filepath.Base(logDir), "wtf-record.") {
+		t.Errorf("typescript = %q, want it in a fresh directory under TMPDIR", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "drwx------") {
+		t.Errorf("typescript directory is not private: %s", lines[1])
+	}
+	if _, err := os.Stat(logDir); !os.IsNotExist(err) {
+		t.Errorf("expected %s removed after recording, got %v", logDir, err)
+	}
+}
+
+func TestScript_RejectsInvalidInput(t *testing.T) {
+	if _, err := Script("fish", "alt+w", "wtf_cli"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+	for _, key := range []string{"w", "alt+1", "super+w", "alt+ww"} {
+		if _, err := Script(ShellBash, key, "wtf_cli"); err == nil {
+			t.Errorf("expected error for key %q", key)
+		}
+	}
+}
+
+func TestDetectShell(t *testing.T) {
+	if got, err := DetectShell("/usr/bin/zsh"); err != nil || got != ShellZsh {
+		t.Errorf("DetectShell(zsh) = %q, %v", got, err)
+	}
+	if _, err := DetectShell(""); err == nil {
+		t.Error("expected error for empty $SHELL")
+	}
+	if _, err := DetectShell("/usr/bin/fish"); err == nil {
+		t.Error("expected error for fish")
+	}
+}
+
+func TestInstall_IsIdempotent(t *testing.T) {
+	home := t.TempDir()
+	rc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(rc, []byte("export EDITOR=vim\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Shell: ShellBash, Binary: "/opt/wtf_cli", HomeDir: home}
+
+	res, err := Install(opts)
+	if err != nil {
+		t.Fatalf("Install() error: %v", err)
+	}
+	if !res.RCUpdated {
+		t.Error("expected first install to update the rc file")
+	}
+	if _, err := os.Stat(res.ScriptPath); err != nil {
+		t.Fatalf("script not written: %v", err)
+	}
+
+	res, err = Install(opts)
+	if err != nil {
+		t.Fatalf("second Install() error: %v", err)
+	}
+	if res.RCUpdated {
+		t.Error("expected second install to leave the rc file alone")
+	}
+
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "export EDITOR=vim\n") {
+		t.Errorf("existing rc content was not preserved: %q", data)
+	}
+	if n := strings.Count(string(data), rcBeginMarker); n != 1 {
+		t.Errorf("expected one integration block, got %d", n)
+	}
+}
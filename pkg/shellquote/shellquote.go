@@ -0,0 +1,27 @@
+// Package shellquote quotes text for POSIX shells.
+package shellquote
+
+import "strings"
+
+// Quote single-quotes s so a POSIX shell reads it as one literal word.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Join renders argv as a command line, quoting only the arguments a POSIX
+// shell would split or expand, so simple commands stay readable.
+func Join(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg != "" && !strings.ContainsFunc(arg, needsQuote) {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func needsQuote(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r))
+}
@@ -0,0 +1,53 @@
+package shellquote
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"":            "''",
+		"plain":       "'plain'",
+		"with space":  "'with space'",
+		"it's":        `'it'\''s'`,
+		"$HOME; rm *": "'$HOME; rm *'",
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want string
+	}{
+		{[]string{"make", "test"}, "make test"},
+		{[]string{"git", "commit", "-m", "fix it"}, "git commit -m 'fix it'"},
+		{[]string{"echo", ""}, "echo ''"},
+		{[]string{"grep", "a|b", "--color=auto"}, "grep 'a|b' --color=auto"},
+	}
+	for _, tt := range tests {
+		if got := Join(tt.argv); got != tt.want {
+			t.Errorf("Join(%q) = %s, want %s", tt.argv, got, tt.want)
+		}
+	}
+}
+
+func TestQuote_RoundTripsThroughShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	in := `it's "$HOME" \n; *`
+	out, err := exec.Command(sh, "-c", "printf %s "+Quote(in)).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Fatalf("shell read %q, want %q", out, in)
+	}
+}
@@ -0,0 +1,59 @@
+package shellsyntax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Dialect returns the shellcheck dialect for the shell at path (e.g.
+// $SHELL), or "" for a shell shellcheck does not check, such as zsh or fish.
+func Dialect(shell string) string {
+	name := shell[strings.LastIndexByte(shell, '/')+1:]
+	switch name {
+	case "bash", "sh", "dash", "ksh":
+		return name
+	}
+	return ""
+}
+
+// Shellcheck runs the shellcheck binary at path over line as a dialect
+// script and returns the first error it reports. Warnings and style notes
+// are left out: a line still being typed has plenty of those.
+func Shellcheck(ctx context.Context, path, dialect, line string) (Problem, bool, error) {
+	cmd := exec.CommandContext(ctx, path, "--shell="+dialect, "--severity=error", "--format=json1", "-")
+	cmd.Stdin = strings.NewReader(line)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	// Shellcheck exits 1 when it has comments.
+	if exitErr := (*exec.ExitError)(nil); err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return Problem{}, false, fmt.Errorf("shellcheck: %w", err)
+	}
+
+	var out struct {
+		Comments []struct {
+			Line    int    `json:"line"`
+			Column  int    `json:"column"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"comments"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return Problem{}, false, fmt.Errorf("shellcheck output: %w", err)
+	}
+	for _, c := range out.Comments {
+		if c.Line != 1 {
+			continue
+		}
+		return Problem{
+			Message: fmt.Sprintf("%s (SC%d)", strings.TrimSuffix(c.Message, "."), c.Code),
+			Offset:  max(c.Column-1, 0),
+		}, true, nil
+	}
+	return Problem{}, false, nil
+}
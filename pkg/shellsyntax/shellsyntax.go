@@ -0,0 +1,354 @@
+// Package shellsyntax finds obvious syntax errors in a command line as it is
+// typed: unclosed quotes and substitutions, a stray ) or operator, a
+// redirection without a file. It is a small scanner, not a parser; a line it
+// has no complaint about may still fail in the shell, and it only reports
+// what bash, zsh and sh all reject. Shellcheck, when installed, catches more.
+package shellsyntax
+
+import "fmt"
+
+// Problem is a syntax error in a command line.
+type Problem struct {
+	Message string
+	Offset  int // Byte offset in the line where the problem starts
+}
+
+// String renders p for the status bar, e.g. "unclosed double quote (col 6)".
+func (p Problem) String() string {
+	return fmt.Sprintf("%s (col %d)", p.Message, p.Offset+1)
+}
+
+// Check returns the first syntax error in line. A line ending in a
+// backslash, a pipe or && continues on the next line and is not an error.
+func Check(line string) (Problem, bool) {
+	s := scanner{line: line}
+	if p := s.commands(0, 0, ""); p != nil {
+		return *p, true
+	}
+	return Problem{}, false
+}
+
+type scanner struct {
+	line string
+	pos  int
+}
+
+// commands scans command lists up to the byte closing the construct opened
+// at open, or to the end of the line when closing is 0. what names the
+// construct for the error when it is never closed.
+func (s *scanner) commands(closing byte, open int, what string) *Problem {
+	var (
+		afterOp   = true // No word since the start or the last operator
+		redirect  = ""   // A redirection still waiting for its file
+		wordStart = true
+		caseDepth = 0
+	)
+	word := func() {
+		afterOp, redirect, wordStart = false, "", false
+	}
+	operator := func(op string, at int) *Problem {
+		switch {
+		case redirect != "":
+			return &Problem{Message: fmt.Sprintf("missing file name after `%s`", redirect), Offset: at}
+		case op == ";;" && caseDepth == 0, afterOp && op != ";;":
+			return &Problem{Message: fmt.Sprintf("unexpected `%s`", op), Offset: at}
+		}
+		afterOp, wordStart = true, true
+		return nil
+	}
+
+	for s.pos < len(s.line) {
+		c, at := s.line[s.pos], s.pos
+		switch {
+		case c == ' ' || c == '\t':
+			s.pos++
+			wordStart = true
+		case c == '#' && wordStart:
+			s.pos = len(s.line) // A comment runs to the end of the line
+		case c == '\\':
+			s.pos += 2
+			word()
+		case c == '\'':
+			if p := s.single(); p != nil {
+				return p
+			}
+			word()
+		case c == '"':
+			if p := s.double(); p != nil {
+				return p
+			}
+			word()
+		case c == '`' && closing == '`':
+			s.pos++
+			return s.closed(redirect)
+		case c == '`':
+			s.pos++
+			if p := s.commands('`', at, "backquote"); p != nil {
+				return p
+			}
+			word()
+		case c == '$':
+			if p := s.dollar(); p != nil {
+				return p
+			}
+			word()
+		case c == '(':
+			s.pos++
+			if p := s.commands(')', at, "`(`"); p != nil {
+				return p
+			}
+			word()
+		case c == ')' && closing == ')':
+			s.pos++
+			return s.closed(redirect)
+		case c == ')' && caseDepth > 0:
+			// The end of a case pattern: a command or ;; follows.
+			s.pos++
+			afterOp, redirect, wordStart = true, "", true
+		case c == ')':
+			return &Problem{Message: "unexpected `)`", Offset: at}
+		case c == '|' || c == '&' || c == ';':
+			if c == '&' && s.peek(1) == '>' {
+				s.pos += 2
+				s.skipByte('>')
+				redirect = "&>"
+				continue
+			}
+			if p := operator(s.operator(), at); p != nil {
+				return p
+			}
+		case c == '<' || c == '>':
+			if s.peek(1) == '(' {
+				// Process substitution.
+				s.pos += 2
+				if p := s.commands(')', at, fmt.Sprintf("`%c(`", c)); p != nil {
+					return p
+				}
+				word()
+				continue
+			}
+			if redirect != "" {
+				return &Problem{Message: fmt.Sprintf("missing file name after `%s`", redirect), Offset: at}
+			}
+			redirect = s.redirection()
+			wordStart = true
+		default:
+			if wordStart && afterOp {
+				switch s.keyword() {
+				case "case":
+					caseDepth++
+				case "esac":
+					caseDepth = max(caseDepth-1, 0)
+				}
+			} else {
+				s.pos++
+			}
+			word()
+		}
+	}
+
+	if closing != 0 {
+		return &Problem{Message: "unclosed " + what, Offset: open}
+	}
+	if redirect != "" {
+		return &Problem{Message: fmt.Sprintf("missing file name after `%s`", redirect), Offset: len(s.line)}
+	}
+	return nil
+}
+
+// closed finishes a construct at its closing byte.
+func (s *scanner) closed(redirect string) *Problem {
+	if redirect != "" {
+		return &Problem{Message: fmt.Sprintf("missing file name after `%s`", redirect), Offset: s.pos - 1}
+	}
+	return nil
+}
+
+// operator reads a control operator: |, ||, |&, &, &&, ; or ;;.
+func (s *scanner) operator() string {
+	c := s.line[s.pos]
+	s.pos++
+	if s.pos < len(s.line) {
+		next := s.line[s.pos]
+		if next == c || (c == '|' && next == '&') {
+			s.pos++
+			return string([]byte{c, next})
+		}
+	}
+	return string(c)
+}
+
+// redirection reads a redirection operator, e.g. >, >>, 2>&, <<< or <<-.
+func (s *scanner) redirection() string {
+	start := s.pos
+	c := s.line[s.pos]
+	s.pos++
+	switch {
+	case c == '<' && s.skipByte('<'):
+		if !s.skipByte('<') {
+			s.skipByte('-')
+		}
+	case c == '>' && s.skipByte('>'):
+	case s.skipByte('&'), c == '>' && s.skipByte('|'), c == '<' && s.skipByte('>'):
+	}
+	return s.line[start:s.pos]
+}
+
+// keyword reads the word starting at s.pos, in command position, when it is
+// made of letters only and returns it; otherwise it moves past one byte.
+func (s *scanner) keyword() string {
+	end := s.pos
+	for end < len(s.line) && (s.line[end] >= 'a' && s.line[end] <= 'z') {
+		end++
+	}
+	if end == s.pos {
+		s.pos++
+		return ""
+	}
+	word := s.line[s.pos:end]
+	s.pos = end
+	if end < len(s.line) && !isBoundary(s.line[end]) {
+		return ""
+	}
+	return word
+}
+
+func isBoundary(c byte) bool {
+	switch c {
+	case ' ', '\t', ';', '&', '|', '(', ')', '<', '>':
+		return true
+	}
+	return false
+}
+
+// single skips a single-quoted string.
+func (s *scanner) single() *Problem {
+	open := s.pos
+	for s.pos++; s.pos < len(s.line); s.pos++ {
+		if s.line[s.pos] == '\'' {
+			s.pos++
+			return nil
+		}
+	}
+	return &Problem{Message: "unclosed single quote", Offset: open}
+}
+
+// double skips a double-quoted string and the substitutions inside it.
+func (s *scanner) double() *Problem {
+	open := s.pos
+	s.pos++
+	for s.pos < len(s.line) {
+		switch s.line[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return nil
+		case '$':
+			if p := s.dollar(); p != nil {
+				return p
+			}
+		case '`':
+			at := s.pos
+			s.pos++
+			if p := s.commands('`', at, "backquote"); p != nil {
+				return p
+			}
+		default:
+			s.pos++
+		}
+	}
+	return &Problem{Message: "unclosed double quote", Offset: open}
+}
+
+// dollar skips a $ expansion: $(...), $((...)), ${...}, $'...' or a plain
+// variable.
+func (s *scanner) dollar() *Problem {
+	open := s.pos
+	s.pos++
+	switch s.peek(0) {
+	case '(':
+		if s.peek(1) == '(' {
+			return s.arithmetic(open)
+		}
+		s.pos++
+		return s.commands(')', open, "`$(`")
+	case '{':
+		return s.braces(open)
+	case '\'':
+		for s.pos++; s.pos < len(s.line); s.pos++ {
+			switch s.line[s.pos] {
+			case '\\':
+				s.pos++
+			case '\'':
+				s.pos++
+				return nil
+			}
+		}
+		return &Problem{Message: "unclosed `$'` string", Offset: open}
+	}
+	return nil
+}
+
+// arithmetic skips $((...)) by counting parentheses.
+func (s *scanner) arithmetic(open int) *Problem {
+	depth := 0
+	for ; s.pos < len(s.line); s.pos++ {
+		switch s.line[s.pos] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				s.pos++
+				return nil
+			}
+		}
+	}
+	return &Problem{Message: "unclosed `$((`", Offset: open}
+}
+
+// braces skips ${...}, with the quotes and expansions inside it.
+func (s *scanner) braces(open int) *Problem {
+	s.pos++
+	for s.pos < len(s.line) {
+		switch s.line[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '}':
+			s.pos++
+			return nil
+		case '\'':
+			if p := s.single(); p != nil {
+				return p
+			}
+		case '"':
+			if p := s.double(); p != nil {
+				return p
+			}
+		case '$':
+			if p := s.dollar(); p != nil {
+				return p
+			}
+		default:
+			s.pos++
+		}
+	}
+	return &Problem{Message: "unclosed `${`", Offset: open}
+}
+
+func (s *scanner) peek(n int) byte {
+	if s.pos+n < len(s.line) {
+		return s.line[s.pos+n]
+	}
+	return 0
+}
+
+// skipByte moves past c when it is next.
+func (s *scanner) skipByte(c byte) bool {
+	if s.peek(0) == c {
+		s.pos++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package shellsyntax
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck_AcceptsValidLines(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"ls -la",
+		`echo "it's fine" 'say "hi"' $'tab\there'`,
+		"git log --format='%h %s' | head -n 5 && echo done; echo bye &",
+		`echo "$(basename "$(pwd)")" ${HOME:-"/root"} $((1 + (2 * 3)))`,
+		"cat <(sort a) > out 2>&1 < in",
+		"cmd &> log; cmd >> log 2>&-",
+		"for f in *.go; do gofmt -l $f; done",
+		"case $x in a|b) echo ab;; (c) ;; *) echo other;; esac",
+		"f() { echo hi; }; (cd /tmp && ls)",
+		"echo `date` # a comment with ' and )",
+		"make test |",
+		"cat file &&",
+		`echo continued \`,
+		"arr=(one two) ; ((n++))",
+	} {
+		if p, bad := Check(line); bad {
+			t.Errorf("Check(%q) = %v, want no problem", line, p)
+		}
+	}
+}
+
+func TestCheck_ReportsProblems(t *testing.T) {
+	cases := []struct {
+		line    string
+		message string
+		offset  int
+	}{
+		{`echo "hello`, "unclosed double quote", 5},
+		{`echo 'it`, "unclosed single quote", 5},
+		{`echo $(date`, "unclosed `$(`", 5},
+		{`echo "$(date)`, "unclosed double quote", 5},
+		{"echo ${HOME", "unclosed `${`", 5},
+		{"echo $((1 + 2)", "unclosed `$((`", 5},
+		{"echo `date", "unclosed backquote", 5},
+		{"(cd /tmp", "unclosed `(`", 0},
+		{"ls )", "unexpected `)`", 3},
+		{"| grep x", "unexpected `|`", 0},
+		{"ls | | wc", "unexpected `|`", 5},
+		{"ls && || pwd", "unexpected `||`", 6},
+		{"ls ;; pwd", "unexpected `;;`", 3},
+		{"echo hi >", "missing file name after `>`", 9},
+		{"sort < | wc", "missing file name after `<`", 7},
+		{"echo $(ls >)", "missing file name after `>`", 11},
+	}
+	for _, tc := range cases {
+		p, bad := Check(tc.line)
+		if !bad || p.Message != tc.message || p.Offset != tc.offset {
+			t.Errorf("Check(%q) = %+v, %v; want %q at %d", tc.line, p, bad, tc.message, tc.offset)
+		}
+	}
+}
+
+func TestShellcheck_FirstError(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "shellcheck")
+	out := `{"comments":[{"line":1,"column":4,"level":"error","code":1009,"message":"The mentioned syntax error was in this simple command."}]}`
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat >/dev/null\necho '"+out+"'\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	p, found, err := Shellcheck(context.Background(), script, "bash", "ls [")
+	if err != nil || !found {
+		t.Fatalf("Shellcheck() = %v, %v, %v", p, found, err)
+	}
+	if want := "The mentioned syntax error was in this simple command (SC1009)"; p.Message != want || p.Offset != 3 {
+		t.Fatalf("Shellcheck() = %+v, want %q at 3", p, want)
+	}
+	if Dialect("/usr/bin/bash") != "bash" || Dialect("/bin/zsh") != "" {
+		t.Fatal("expected bash checked and zsh left alone")
+	}
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"context"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+)
+
+// aiJob is the state of one AI request: its stream and how its events are
+// being laid into the conversation.
+type aiJob struct {
+	id           int
+	cancel       context.CancelFunc
+	stream       <-chan commands.WtfStreamEvent
+	startPending bool
+	// placeholder is set while the "Thinking..." message stands in for the
+	// answer.
+	placeholder bool
+	// newTurnNeeded is set after a tool call finishes; the next delta
+	// starts a new assistant message.
+	newTurnNeeded   bool
+	throttlePending bool
+	// usage is the usage already recorded for budgets.
+	usage ai.Usage
+}
+
+// aiJobManager owns the session's AI requests, apart from the sidebar that shows
+// them: closing the sidebar leaves a request streaming into the
+// conversation, and an answer finished while it is closed is counted as
+// ready until the sidebar is opened again.
+type aiJobManager struct {
+	aiJob     // The latest request; idle once it ended
+	ready int // Answers finished while the sidebar was closed
+}
+
+// begin starts a request, canceling any running one, and returns its
+// context and id.
+func (j *aiJobManager) begin() (context.Context, int) {
+	if j.cancel != nil {
+		j.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j.aiJob = aiJob{id: j.id + 1, cancel: cancel, startPending: true}
+	return ctx, j.id
+}
+
+// end releases the request's stream and context. Events still in flight are
+// told apart by abandon, not end.
+func (j *aiJobManager) end() {
+	if j.cancel != nil {
+		j.cancel()
+		j.cancel = nil
+	}
+	j.stream = nil
+	j.startPending = false
+	j.throttlePending = false
+	j.newTurnNeeded = false
+}
+
+// abandon ends the request and moves to a new id, so its late events and
+// throttle ticks are dropped.
+func (j *aiJobManager) abandon() {
+	j.end()
+	j.id++
+}
+
+// running reports whether a request is starting or streaming.
+func (j *aiJobManager) running() bool {
+	return j.cancel != nil || j.startPending || j.stream != nil
+}
+
+// noteAnswerReady counts an answer that finished while the sidebar was
+// closed, so the status bar can point to it.
+func (m *Model) noteAnswerReady() {
+	if m.sidebar == nil || m.sidebar.IsVisible() {
+		return
+	}
+	m.aiJobs.ready++
+	m.statusBar.SetAnswersReady(m.aiJobs.ready)
+}
+
+// clearAnswersReady drops the ready badge once the sidebar shows the answers.
+func (m *Model) clearAnswersReady() {
+	m.aiJobs.ready = 0
+	m.statusBar.SetAnswersReady(0)
+}
+
+// streamInView reports whether the sidebar showing the running request is
+// open. Esc cancels a request only then; in the background it goes on
+// until it ends or the sidebar is opened to cancel it.
+func (m Model) streamInView() bool {
+	return m.sidebar == nil || m.sidebar.IsVisible()
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/testutils"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestModel_StreamContinuesAfterSidebarClose(t *testing.T) {
+	m, canceled := modelWithCancelableStream()
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = newModel.(Model)
+	m.showSidebar("test")
+	m.startStreamPlaceholder()
+
+	newModel, _ = m.Update(input.ToggleChatMsg{})
+	m = newModel.(Model)
+	if m.sidebar.IsVisible() {
+		t.Fatal("Expected the sidebar to close")
+	}
+
+	// Esc in the terminal belongs to the shell while the answer runs unseen.
+	newModel, _ = m.Update(testutils.TestKeyEsc)
+	m = newModel.(Model)
+	if *canceled || !m.hasActiveStream() {
+		t.Fatal("Expected Esc not to cancel a background request")
+	}
+
+	for _, event := range []commands.WtfStreamEvent{{Delta: "Run make"}, {Delta: " again."}, {Done: true}} {
+		newModel, _ = m.Update(wtfStreamEventMsg{streamID: m.aiJobs.id, event: event})
+		m = newModel.(Model)
+	}
+	if got := latestAssistantMessageContent(t, m); got != "Run make again." {
+		t.Fatalf("Expected the answer buffered in the conversation, got %q", got)
+	}
+	if m.hasActiveStream() {
+		t.Fatal("Expected the request to end")
+	}
+	if m.aiJobs.ready != 1 {
+		t.Fatalf("Expected one ready answer, got %d", m.aiJobs.ready)
+	}
+	if got := m.statusBar.Render(); !strings.Contains(got, "1 answer ready") {
+		t.Fatalf("Expected the ready badge in the status bar, got %q", got)
+	}
+
+	newModel, _ = m.Update(input.ToggleChatMsg{})
+	m = newModel.(Model)
+	if m.aiJobs.ready != 0 || strings.Contains(m.statusBar.Render(), "answer ready") {
+		t.Fatal("Expected opening the sidebar to clear the ready badge")
+	}
+}
+
+func TestModel_AnswerSeenInSidebarIsNotCountedReady(t *testing.T) {
+	m, _ := modelWithCancelableStream()
+	m.showSidebar("test")
+
+	newModel, _ := m.Update(wtfStreamEventMsg{streamID: m.aiJobs.id, event: commands.WtfStreamEvent{Done: true}})
+	m = newModel.(Model)
+	if m.aiJobs.ready != 0 {
+		t.Fatalf("Expected no ready answer while the sidebar is open, got %d", m.aiJobs.ready)
+	}
+}
+
+func TestAIJobManager_BeginAbandonsPreviousRequest(t *testing.T) {
+	var jobs aiJobManager
+	first, firstID := jobs.begin()
+	jobs.placeholder = true
+	_, secondID := jobs.begin()
+
+	if first.Err() == nil {
+		t.Fatal("Expected the first request's context to be canceled")
+	}
+	if secondID != firstID+1 || jobs.placeholder || !jobs.startPending {
+		t.Fatalf("Expected a fresh request, got %+v", jobs.aiJob)
+	}
+
+	jobs.abandon()
+	if jobs.running() || jobs.id != secondID+1 {
+		t.Fatalf("Expected abandon to end the request and move on, got %+v", jobs.aiJob)
+	}
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+)
+
+// setPaletteAliases offers the user's command aliases in the palette.
+// Aliases that shadow a built-in command or run an unknown one are skipped.
+func (m *Model) setPaletteAliases(aliases []config.CommandAlias) {
+	m.aliases = make(map[string]config.CommandAlias, len(aliases))
+	entries := make([]palette.Command, 0, len(aliases))
+	for _, a := range aliases {
+		if _, builtin := m.dispatcher.GetHandler(a.Name); builtin {
+			slog.Warn("palette_alias_skipped", "alias", a.Name, "reason", "shadows_builtin")
+			continue
+		}
+		if _, ok := m.dispatcher.GetHandler(a.Command); !ok {
+			slog.Warn("palette_alias_skipped", "alias", a.Name, "reason", "unknown_command", "command", a.Command)
+			continue
+		}
+		m.aliases[a.Name] = a
+		description := a.Description
+		if description == "" {
+			description = strings.TrimSpace(a.Command + " " + a.Args)
+		}
+		entries = append(entries, palette.Command{Name: a.Name, Description: description})
+	}
+	if m.palette != nil {
+		m.palette.SetAliases(entries)
+	}
+}
+
+// setPolicy hides the commands the organization policy disables.
+func (m *Model) setPolicy(policy config.Policy) {
+	m.policy = policy
+	if m.palette != nil {
+		m.palette.SetDisabled(policy.DisabledCommands)
+	}
+}
+
+// applyAlias resolves name when it is an alias: it presets ctx from the
+// alias and returns the command to run. Other names are returned as is.
+func (m *Model) applyAlias(name string, ctx *commands.Context) string {
+	alias, ok := m.aliases[name]
+	if !ok {
+		return name
+	}
+	slog.Info("palette_alias", "alias", alias.Name, "command", alias.Command)
+	ctx.Args = strings.TrimSpace(alias.Args + " " + ctx.Args)
+	if alias.Model != "" {
+		ctx.ModelOverride = alias.Model
+	}
+	if alias.ContextLines > 0 {
+		ctx.ContextLines = alias.ContextLines
+	}
+	return alias.Command
+}
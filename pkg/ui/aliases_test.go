@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+)
+
+func TestModel_PaletteAliases(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.setPaletteAliases([]config.CommandAlias{
+		{Name: "/wtf-verbose", Command: "/explain", Args: "why", Model: "gpt-4o", ContextLines: 200, Description: "Explain with more context"},
+		{Name: "/explain", Command: "/help"},
+		{Name: "/nope", Command: "/missing"},
+	})
+
+	if len(m.aliases) != 1 {
+		t.Fatalf("expected only the valid alias to be kept, got %v", m.aliases)
+	}
+
+	ctx := m.commandContext()
+	ctx.Args = "exactly"
+	if got := m.applyAlias("/wtf-verbose", ctx); got != "/explain" {
+		t.Fatalf("applyAlias() = %q, want /explain", got)
+	}
+	if ctx.Args != "why exactly" || ctx.ModelOverride != "gpt-4o" || ctx.ContextLines != 200 {
+		t.Fatalf("unexpected context from alias: args=%q model=%q lines=%d", ctx.Args, ctx.ModelOverride, ctx.ContextLines)
+	}
+
+	plain := m.commandContext()
+	if got := m.applyAlias("/help", plain); got != "/help" || plain.ModelOverride != "" {
+		t.Fatalf("expected non-aliases to pass through, got %q", got)
+	}
+}
+
+func TestModel_PaletteAliasRunsTargetCommand(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.setPaletteAliases([]config.CommandAlias{{Name: "/h", Command: "/help"}})
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/h"})
+	if !m.resultPanel.IsVisible() {
+		t.Fatal("expected the aliased /help to show its result panel")
+	}
+}
+
+func TestModel_PolicyDisablesCommandAndAliases(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.setPaletteAliases([]config.CommandAlias{{Name: "/h", Command: "/help"}})
+	m.setPolicy(config.Policy{DisabledCommands: []string{"/help"}})
+
+	for _, name := range []string{"/help", "/h"} {
+		m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: name})
+		if m.resultPanel.IsVisible() {
+			t.Fatalf("expected %s not to run", name)
+		}
+		if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "disabled by your organization") {
+			t.Fatalf("expected a policy notice for %s, got %q", name, msg)
+		}
+	}
+}
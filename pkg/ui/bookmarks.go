@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ui/components/utils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// bookmarksFieldKey routes option picker selections made from /marks.
+const bookmarksFieldKey = "bookmarks"
+
+// maxBookmarks bounds the bookmarks of a pane; the oldest goes first.
+const maxBookmarks = 50
+
+// bookmarkLabelWidth caps a label shown in /marks.
+const bookmarkLabelWidth = 48
+
+// bookmark is a labelled buffer line, set with /mark. It lasts for the
+// session and belongs to the pane it was set in.
+type bookmark struct {
+	label string
+	line  int // Buffer line number (1-based) that was last when marked
+	at    time.Time
+}
+
+// addBookmark marks the last line of the scrollback with label. A mark with
+// the same label moves to the new position.
+func (m Model) addBookmark(label string) (Model, tea.Cmd) {
+	label = strings.Join(strings.Fields(label), " ")
+	if label == "" {
+		return m, m.flashStatus("Usage: /mark <label>, e.g. /mark before the migration")
+	}
+	if m.buffer == nil || m.buffer.Total() == 0 {
+		return m, m.flashStatus("Nothing in the scrollback to mark yet")
+	}
+
+	marks := make([]bookmark, 0, len(m.bookmarks)+1)
+	for _, b := range m.bookmarks {
+		if b.label != label {
+			marks = append(marks, b)
+		}
+	}
+	marks = append(marks, bookmark{label: label, line: m.buffer.Total(), at: time.Now()})
+	if len(marks) > maxBookmarks {
+		marks = marks[len(marks)-maxBookmarks:]
+	}
+	m.bookmarks = marks
+	slog.Info("bookmark_add", "line", m.buffer.Total(), "count", len(marks))
+	return m, m.flashStatus(fmt.Sprintf("Marked line %d as %q; /marks jumps back", m.buffer.Total(), label))
+}
+
+// openBookmarksPicker lists the bookmarks of the focused pane, newest first.
+func (m Model) openBookmarksPicker() (Model, tea.Cmd) {
+	if len(m.bookmarks) == 0 {
+		return m, m.flashStatus("No bookmarks yet; use /mark <label>")
+	}
+	options := make([]string, 0, len(m.bookmarks))
+	for i := len(m.bookmarks) - 1; i >= 0; i-- {
+		options = append(options, bookmarkOptionLabel(m.bookmarks[i]))
+	}
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Jump to a bookmark", bookmarksFieldKey, options, "")
+	return m, nil
+}
+
+// bookmarkOptionLabel renders a bookmark as
+// "before the migration · line 1204 · 14:03".
+func bookmarkOptionLabel(b bookmark) string {
+	return fmt.Sprintf("%s · line %d · %s", utils.TruncateToWidth(b.label, bookmarkLabelWidth), b.line, b.at.Format("15:04"))
+}
+
+// jumpToBookmark scrolls the terminal back to the bookmark picked in /marks.
+func (m Model) jumpToBookmark(choice string) (Model, tea.Cmd) {
+	for _, b := range m.bookmarks {
+		if bookmarkOptionLabel(b) == choice {
+			slog.Info("bookmark_jump", "line", b.line)
+			return m.showCitedLine(b.line)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/styles"
+)
+
+func TestModel_MarkAndJumpBack(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.viewport.SetSize(40, 2)
+	write := func(lines ...string) {
+		for _, line := range lines {
+			m.buffer.Write([]byte(line))
+			m.viewport.AppendOutput([]byte(line + "\r\n"))
+		}
+	}
+
+	write("$ ./migrate down")
+	m, _ = m.addBookmark("  before   the migration ")
+	write("$ ./migrate up", "applied 12 migrations", "$ make test", "ok")
+
+	if len(m.bookmarks) != 1 || m.bookmarks[0].label != "before the migration" || m.bookmarks[0].line != 1 {
+		t.Fatalf("bookmarks = %+v, want line 1 labelled", m.bookmarks)
+	}
+
+	m, _ = m.openBookmarksPicker()
+	option := bookmarkOptionLabel(m.bookmarks[0])
+	if !strings.Contains(m.optionPicker.View(), "before the migration · line 1") {
+		t.Fatalf("picker = %q, want the bookmark listed", m.optionPicker.View())
+	}
+
+	newModel, _ := m.Update(picker.OptionPickerSelectMsg{FieldKey: bookmarksFieldKey, Value: option})
+	m = newModel.(Model)
+	if !m.scrollMode {
+		t.Fatal("expected scroll mode while showing the bookmark")
+	}
+	if view := m.viewport.View(); !strings.Contains(view, styles.CitedLineStyle.Render("$ ./migrate down")) {
+		t.Fatalf("expected the marked line shown, got %q", view)
+	}
+}
+
+func TestModel_MarkReplacesSameLabel(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.addBookmark("here")
+	if len(m.bookmarks) != 0 {
+		t.Fatal("marked an empty scrollback")
+	}
+
+	m.buffer.Write([]byte("one"))
+	m, _ = m.addBookmark("here")
+	m.buffer.Write([]byte("two"))
+	m, _ = m.addBookmark("here")
+	if len(m.bookmarks) != 1 || m.bookmarks[0].line != 2 {
+		t.Fatalf("bookmarks = %+v, want one at line 2", m.bookmarks)
+	}
+
+	m, _ = m.addBookmark("")
+	if got := m.statusBar.GetMessage(); !strings.HasPrefix(got, "Usage: /mark") {
+		t.Errorf("status message = %q, want usage", got)
+	}
+}
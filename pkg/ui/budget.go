@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// budgetFieldKey routes the option picker asking whether to send a request
+// past the provider's monthly budget.
+const budgetFieldKey = "budget_override"
+
+const (
+	budgetSendOption   = "Send anyway (for this session)"
+	budgetCancelOption = "Cancel"
+)
+
+func (m Model) usageFile() string {
+	if m.usagePath != "" {
+		return m.usagePath
+	}
+	return ai.DefaultUsagePath()
+}
+
+// setBudgets applies the budgets from cfg and the provider they are
+// checked against.
+func (m *Model) setBudgets(cfg config.Config) {
+	m.budgets = cfg.Budgets
+	m.llmProvider, _ = getProviderAndModel(cfg)
+	m.syncBudgetStatus()
+}
+
+// monthlyUsage returns what the active provider used this month.
+func (m Model) monthlyUsage() ai.ProviderUsage {
+	return ai.LoadUsage(m.usageFile(), ai.UsageMonth(time.Now()))[m.llmProvider]
+}
+
+// budgetBlocks reports whether the active provider's budget is used up and
+// the user has not chosen to go past it this session.
+func (m Model) budgetBlocks() bool {
+	limit := m.budgets.MonthlyLimit(m.llmProvider)
+	if limit <= 0 || m.budgetOverrides[m.llmProvider] {
+		return false
+	}
+	return ai.CheckBudget(m.monthlyUsage().Total(), limit) == ai.BudgetExceeded
+}
+
+// askBudgetOverride asks whether to send a request although the budget is
+// used up. Once confirmed, pending is dispatched again.
+func (m Model) askBudgetOverride(pending tea.Msg) (Model, tea.Cmd) {
+	m.budgetPending = pending
+	used := m.monthlyUsage().Total()
+	limit := m.budgets.MonthlyLimit(m.llmProvider)
+	slog.Info("budget_exceeded_prompt", "provider", m.llmProvider, "used", used, "limit", limit)
+	title := fmt.Sprintf("Monthly %s budget used up (%s of %s tokens). Send anyway?",
+		m.llmProvider, ai.FormatTokens(used), ai.FormatTokens(limit))
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show(title, budgetFieldKey, []string{budgetSendOption, budgetCancelOption}, budgetCancelOption)
+	return m, nil
+}
+
+// confirmBudgetOverride lets the active provider past its budget for the
+// rest of the session and resumes the request that asked.
+func (m Model) confirmBudgetOverride(choice string) (Model, tea.Cmd) {
+	pending := m.budgetPending
+	m.budgetPending = nil
+	if choice != budgetSendOption {
+		slog.Info("budget_override_declined", "provider", m.llmProvider)
+		return m, nil
+	}
+	slog.Info("budget_override", "provider", m.llmProvider)
+	m.budgetOverrides[m.llmProvider] = true
+	if pending != nil {
+		return m, func() tea.Msg { return pending }
+	}
+	return m, nil
+}
+
+// recordStreamUsage adds what the running stream used since the last
+// report to the month's usage. total is the stream's running total. A
+// notice is flashed when the budget crosses the warning threshold or runs
+// out.
+func (m *Model) recordStreamUsage(total ai.Usage) tea.Cmd {
+	delta := ai.Usage{
+		InputTokens:  max(total.InputTokens-m.aiJobs.usage.InputTokens, 0),
+		OutputTokens: max(total.OutputTokens-m.aiJobs.usage.OutputTokens, 0),
+	}
+	m.aiJobs.usage = total
+	if delta.IsZero() || m.llmProvider == "" {
+		return nil
+	}
+
+	limit := m.budgets.MonthlyLimit(m.llmProvider)
+	before := m.monthlyUsage().Total()
+	after, err := ai.RecordUsage(m.usageFile(), ai.UsageMonth(time.Now()), m.llmProvider, delta)
+	if err != nil {
+		slog.Warn("usage_record_error", "error", err)
+		return nil
+	}
+	m.syncBudgetStatus()
+
+	was, now := ai.CheckBudget(before, limit), ai.CheckBudget(after.Total(), limit)
+	if now == was {
+		return nil
+	}
+	slog.Info("budget_threshold", "provider", m.llmProvider, "used", after.Total(), "limit", limit)
+	switch now {
+	case ai.BudgetExceeded:
+		return m.flashStatus(fmt.Sprintf("Monthly %s budget used up; new requests will ask first", m.llmProvider))
+	case ai.BudgetWarn:
+		return m.flashStatus(fmt.Sprintf("%d%% of the monthly %s budget used", after.Total()*100/limit, m.llmProvider))
+	}
+	return nil
+}
+
+// syncBudgetStatus shows the remaining budget in the status bar when
+// budgets.show_in_status_bar is set.
+func (m *Model) syncBudgetStatus() {
+	if m.statusBar == nil {
+		return
+	}
+	limit := m.budgets.MonthlyLimit(m.llmProvider)
+	if !m.budgets.ShowInStatusBar || limit <= 0 {
+		m.statusBar.SetBudget("")
+		return
+	}
+	remaining := max(limit-m.monthlyUsage().Total(), 0)
+	m.statusBar.SetBudget("budget: " + ai.FormatTokens(remaining) + " left")
+}
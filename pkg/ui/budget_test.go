@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// newBudgetTestModel returns a model in a trusted workspace whose provider
+// has already used used tokens of a limit-token budget this month.
+func newBudgetTestModel(t *testing.T, used, limit int) Model {
+	t.Helper()
+	buf := buffer.New(100)
+	buf.Write([]byte("make: *** [test] Error 1"))
+	m := NewModel(nil, buf, capture.NewSessionContext(), nil)
+	m.currentDir = t.TempDir()
+	trustWorkspace(t, &m, ai.TrustTrusted)
+	m.usagePath = filepath.Join(t.TempDir(), "usage.json")
+	if used > 0 {
+		if _, err := ai.RecordUsage(m.usagePath, ai.UsageMonth(time.Now()), "openai", ai.Usage{InputTokens: used}); err != nil {
+			t.Fatalf("record usage: %v", err)
+		}
+	}
+	cfg := config.Default()
+	cfg.LLMProvider = "openai"
+	cfg.Budgets = config.BudgetsConfig{MonthlyTokens: map[string]int{"openai": limit}, ShowInStatusBar: true}
+	m.setBudgets(cfg)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return newModel.(Model)
+}
+
+func TestModel_ExceededBudgetAsksBeforeExplain(t *testing.T) {
+	m := newBudgetTestModel(t, 1000, 1000)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/explain"})
+	m = newModel.(Model)
+	if m.hasActiveStream() {
+		t.Fatal("Expected no stream while the budget is used up")
+	}
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the budget prompt")
+	}
+	if !strings.Contains(m.optionPicker.View(), "budget used up") {
+		t.Fatalf("Expected budget prompt title, got:\n%s", m.optionPicker.View())
+	}
+
+	newModel, cmd := m.Update(picker.OptionPickerSelectMsg{FieldKey: budgetFieldKey, Value: budgetSendOption})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the pending /explain to be resumed")
+	}
+	if sel, ok := cmd().(palette.PaletteSelectMsg); !ok || sel.Command != "/explain" {
+		t.Fatalf("Expected the /explain selection to be dispatched again, got %#v", cmd())
+	}
+	if m.budgetBlocks() {
+		t.Fatal("Expected the override to last for the session")
+	}
+}
+
+func TestModel_BudgetCancelDropsRequest(t *testing.T) {
+	m := newBudgetTestModel(t, 1000, 1000)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/explain"})
+	m = newModel.(Model)
+	newModel, cmd := m.Update(picker.OptionPickerSelectMsg{FieldKey: budgetFieldKey, Value: budgetCancelOption})
+	m = newModel.(Model)
+	if cmd != nil {
+		t.Fatalf("Expected nothing resumed, got %#v", cmd())
+	}
+	if !m.budgetBlocks() {
+		t.Fatal("Expected the budget to keep blocking after cancel")
+	}
+}
+
+func TestModel_RecordStreamUsageWarnsOnThreshold(t *testing.T) {
+	m := newBudgetTestModel(t, 700, 1000)
+	if got := m.statusBar.Render(); !strings.Contains(got, "budget: 300 left") {
+		t.Fatalf("Expected remaining budget in the status bar, got %q", got)
+	}
+
+	if cmd := m.recordStreamUsage(ai.Usage{InputTokens: 50}); cmd != nil {
+		t.Fatal("Expected no notice below the warning threshold")
+	}
+	// The stream reports running totals, so only the increase is recorded.
+	if cmd := m.recordStreamUsage(ai.Usage{InputTokens: 50, OutputTokens: 60}); cmd == nil {
+		t.Fatal("Expected a notice when crossing the warning threshold")
+	}
+	if got := m.monthlyUsage(); got.Total() != 810 {
+		t.Fatalf("Expected 810 tokens recorded, got %+v", got)
+	}
+	if !strings.Contains(m.statusBar.Render(), "81% of the monthly openai budget used") {
+		t.Fatalf("Expected warning in the status bar, got %q", m.statusBar.Render())
+	}
+	if !strings.Contains(m.statusBar.Render(), "budget: 190 left") {
+		t.Fatalf("Expected remaining budget updated, got %q", m.statusBar.Render())
+	}
+}
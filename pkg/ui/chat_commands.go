@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// modelDefaultArg is the /model argument that goes back to the configured
+// model.
+const modelDefaultArg = "default"
+
+// handleChatCommand runs a slash command typed in the chat input.
+func (m Model) handleChatCommand(msg sidebar.ChatCommandMsg) (Model, tea.Cmd) {
+	if m.sidebar == nil {
+		return m, nil
+	}
+	slog.Info("chat_command", "name", msg.Name, "args", msg.Args)
+	switch msg.Name {
+	case sidebar.ChatCommandModel:
+		return m.setChatModel(msg.Args)
+	case sidebar.ChatCommandClear:
+		return m.clearChat()
+	case sidebar.ChatCommandRetry:
+		return m.retryLastQuestion()
+	case sidebar.ChatCommandCopy:
+		return m.copyLastAnswer()
+	}
+	return m, nil
+}
+
+// unknownChatCommandHint tells the user that a slash command typed in the
+// chat was sent as a message.
+func unknownChatCommandHint(name string) string {
+	return fmt.Sprintf("/%s is not a chat command, sent as a message. Chat commands: /%s",
+		name, strings.Join(sidebar.ChatCommands, ", /"))
+}
+
+// setChatModel answers with model for the rest of the session, on the
+// provider in use. Without a model it shows the one in use; "default" goes
+// back to the configured one.
+func (m Model) setChatModel(model string) (Model, tea.Cmd) {
+	provider, configured := getProviderAndModel(m.sessionConfig())
+	switch model {
+	case "":
+		return m, m.flashStatus(fmt.Sprintf("Chat model: %s-%s. /model <name> changes it for this session", provider, m.chatModel(configured)))
+	case modelDefaultArg:
+		m.modelOverride = ""
+	default:
+		m.modelOverride = model
+	}
+	m.setActiveLLM(provider, m.chatModel(configured))
+	slog.Info("chat_model_override", "provider", provider, "model", m.chatModel(configured))
+	return m, m.flashStatus(fmt.Sprintf("Using %s-%s for this session", provider, m.chatModel(configured)))
+}
+
+// chatModel returns the model picked with /model, or configured.
+func (m Model) chatModel(configured string) string {
+	if m.modelOverride != "" {
+		return m.modelOverride
+	}
+	return configured
+}
+
+// clearChat starts a new conversation. Pinned terminal output and the
+// settings for this session stay.
+func (m Model) clearChat() (Model, tea.Cmd) {
+	if m.hasActiveStream() {
+		return m, m.flashStatus("Wait for the answer, or press Esc to stop it, before clearing the chat")
+	}
+	m.sidebar.SetMessages(nil)
+	// The next conversation gets a title of its own.
+	m.sidebar.SetTitle("")
+	m.chatTitleRequested = false
+	m.refreshSidebar()
+	return m, m.flashStatus("Chat cleared")
+}
+
+// retryLastQuestion asks the last question again and appends the new
+// answer.
+func (m Model) retryLastQuestion() (Model, tea.Cmd) {
+	if m.hasActiveStream() {
+		return m, nil
+	}
+	history, ok := m.sidebar.LastPrompt()
+	if !ok {
+		return m, m.flashStatus("No question to retry")
+	}
+	slog.Info("chat_retry", "history", len(history))
+	return m.rerunWithContext(m.commandContext(), history, "Asking again")
+}
+
+// copyLastAnswer copies the last answer the way "Copy message" does.
+func (m Model) copyLastAnswer() (Model, tea.Cmd) {
+	chatMsg, ok := m.sidebar.MessageAt(m.sidebar.LastAnswer())
+	if !ok {
+		return m, m.flashStatus("No answer to copy")
+	}
+	text := sidebar.FormatReplies(sidebar.FormatCitations(sidebar.StripCommandMarkers(chatMsg.Content)))
+	return m, tea.Batch(tea.SetClipboard(text), m.flashStatus("Answer copied to clipboard"))
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ui/components/sidebar"
+)
+
+func TestChatCommand_ModelOverridesForSession(t *testing.T) {
+	m := newMessageActionTestModel()
+
+	m, _ = m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandModel, Args: "gpt-4o-mini"})
+	if got := m.commandContext().ModelOverride; got != "gpt-4o-mini" {
+		t.Fatalf("ModelOverride = %q, want gpt-4o-mini", got)
+	}
+	if got := m.sidebar.ActiveLLMLabel(); !strings.Contains(got, "gpt-4o-mini") {
+		t.Errorf("expected the model in the sidebar, got %q", got)
+	}
+
+	m, _ = m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandModel, Args: modelDefaultArg})
+	if got := m.commandContext().ModelOverride; got != "" {
+		t.Fatalf("expected /model default to clear the override, got %q", got)
+	}
+}
+
+func TestChatCommand_ClearCopyRetry(t *testing.T) {
+	m := newMessageActionTestModel()
+
+	m, cmd := m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandCopy})
+	if cmd == nil || m.statusBar.GetMessage() != "Answer copied to clipboard" {
+		t.Fatalf("expected the answer copied, status %q", m.statusBar.GetMessage())
+	}
+
+	m, cmd = m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandRetry})
+	if cmd == nil || !m.hasActiveStream() {
+		t.Fatal("expected /retry to ask again")
+	}
+	if msgs := m.sidebar.GetMessages(); len(msgs) != 3 || msgs[2].Role != "assistant" {
+		t.Fatalf("expected a new answer after the old one, got %+v", msgs)
+	}
+
+	m, _ = m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandClear})
+	if len(m.sidebar.GetMessages()) == 0 {
+		t.Fatal("expected /clear to wait for the answer")
+	}
+	m.endStreamRun()
+	m, _ = m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandClear})
+	if len(m.sidebar.GetMessages()) != 0 {
+		t.Fatal("expected /clear to empty the conversation")
+	}
+
+	m, _ = m.handleChatCommand(sidebar.ChatCommandMsg{Name: sidebar.ChatCommandRetry})
+	if got := m.statusBar.GetMessage(); got != "No question to retry" {
+		t.Errorf("status message = %q", got)
+	}
+}
+
+func TestChatSubmit_UnknownCommandHint(t *testing.T) {
+	m := newMessageActionTestModel()
+	m.ensureSidebar().SetMessages(nil)
+
+	m, _ = m.handleChatSubmit(sidebar.ChatSubmitMsg{Content: "/deploy staging", UnknownCommand: "deploy"})
+	if msgs := m.sidebar.GetMessages(); len(msgs) == 0 || msgs[0].Content != "/deploy staging" {
+		t.Fatalf("expected the text sent as a message, got %+v", msgs)
+	}
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, "/deploy is not a chat command") || !strings.Contains(got, "/retry") {
+		t.Errorf("status message = %q", got)
+	}
+}
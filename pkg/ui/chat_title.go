@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// chatTitleTimeout bounds the background title request so a slow provider
+// never leaves it hanging around.
+const chatTitleTimeout = 30 * time.Second
+
+type chatTitler func(context.Context, *commands.Context, []ai.ChatMessage) (string, error)
+
+type chatTitleMsg struct {
+	title string
+	err   error
+}
+
+// requestChatTitle asks for a conversation title once the first exchange has
+// a real answer. It runs at most once per conversation, /clear starting a
+// new one; a failure keeps the default header.
+func (m *Model) requestChatTitle() tea.Cmd {
+	if m.chatTitleRequested || m.chatTitler == nil || m.sidebar == nil || m.sidebar.Title() != "" {
+		return nil
+	}
+	messages := titleableMessages(m.sidebar.GetMessages())
+	if !hasAnsweredExchange(messages) {
+		return nil
+	}
+	m.chatTitleRequested = true
+
+	titler := m.chatTitler
+	ctx := commands.NewContext(m.buffer, m.session, m.currentDir)
+	if m.privacy {
+		ctx = commands.NewContext(nil, nil, m.currentDir)
+	}
+	return func() tea.Msg {
+		runCtx, cancel := context.WithTimeout(context.Background(), chatTitleTimeout)
+		defer cancel()
+		title, err := titler(runCtx, ctx, messages)
+		return chatTitleMsg{title: title, err: err}
+	}
+}
+
+func (m Model) handleChatTitle(msg chatTitleMsg) (Model, tea.Cmd) {
+	if !m.chatTitleRequested {
+		return m, nil // Asked for a conversation cleared since
+	}
+	if msg.err != nil {
+		slog.Warn("chat_title_failed", "error", msg.err)
+		return m, nil
+	}
+	if m.sidebar != nil && m.sidebar.Title() == "" {
+		m.sidebar.SetTitle(msg.title)
+		m.publishChat()
+	}
+	return m, nil
+}
+
+// titleableMessages drops error notices so they are not summarized as the
+// assistant's answer.
+func titleableMessages(messages []ai.ChatMessage) []ai.ChatMessage {
+	errPrefix := sidebar.MessagePrefix("error")
+	out := make([]ai.ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == "assistant" && strings.HasPrefix(msg.Content, errPrefix) {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+func hasAnsweredExchange(messages []ai.ChatMessage) bool {
+	asked := false
+	for _, msg := range messages {
+		content := strings.TrimSpace(msg.Content)
+		switch {
+		case msg.Role == "user" && content != "":
+			asked = true
+		case msg.Role == "assistant" && asked && content != "" && content != streamThinkingPlaceholder:
+			return true
+		}
+	}
+	return false
+}
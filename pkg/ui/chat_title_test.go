@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/sidebar"
+)
+
+func TestRequestChatTitle_AfterFirstExchange(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	var got []ai.ChatMessage
+	m.chatTitler = func(_ context.Context, _ *commands.Context, messages []ai.ChatMessage) (string, error) {
+		got = messages
+		return "Fixing Docker Permissions", nil
+	}
+
+	m.ensureSidebar().AppendUserMessage("why does docker fail?")
+	m.sidebar.StartAssistantMessageWithContent(streamThinkingPlaceholder)
+	if cmd := m.requestChatTitle(); cmd != nil {
+		t.Fatal("Expected no title request before the assistant answered")
+	}
+
+	m.sidebar.SetLastMessageContent("Add your user to the docker group.")
+	cmd := m.requestChatTitle()
+	if cmd == nil {
+		t.Fatal("Expected a title request after the first exchange")
+	}
+	if again := m.requestChatTitle(); again != nil {
+		t.Fatal("Expected the title to be requested only once")
+	}
+
+	updated, _ := m.Update(cmd())
+	m = updated.(Model)
+	if m.sidebar.Title() != "Fixing Docker Permissions" {
+		t.Fatalf("Expected sidebar title to be set, got %q", m.sidebar.Title())
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected titler to receive the exchange, got %d messages", len(got))
+	}
+}
+
+func TestRequestChatTitle_ClearStartsOver(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	titles := []string{"Fixing Docker Permissions", "Resizing Disks"}
+	m.chatTitler = func(context.Context, *commands.Context, []ai.ChatMessage) (string, error) {
+		title := titles[0]
+		titles = titles[1:]
+		return title, nil
+	}
+
+	m.ensureSidebar().AppendUserMessage("why does docker fail?")
+	m.sidebar.StartAssistantMessageWithContent("Add your user to the docker group.")
+	cmd := m.requestChatTitle()
+	if cmd == nil {
+		t.Fatal("Expected a title request after the first exchange")
+	}
+	// The first title arrives after /clear and belongs to the old chat.
+	stale := cmd()
+	m, _ = m.clearChat()
+	if m.chatTitleRequested {
+		t.Fatal("Expected /clear to allow a new title request")
+	}
+	updated, _ := m.Update(stale)
+	m = updated.(Model)
+	if m.sidebar.Title() != "" {
+		t.Fatalf("Expected the cleared chat to keep no title, got %q", m.sidebar.Title())
+	}
+
+	m.sidebar.AppendUserMessage("how do I grow the disk?")
+	m.sidebar.StartAssistantMessageWithContent("Use growpart.")
+	cmd = m.requestChatTitle()
+	if cmd == nil {
+		t.Fatal("Expected the new conversation to be titled")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.sidebar.Title() != "Resizing Disks" {
+		t.Fatalf("Expected the new title, got %q", m.sidebar.Title())
+	}
+
+	m, _ = m.clearChat()
+	if m.sidebar.Title() != "" {
+		t.Fatalf("Expected /clear to drop the title, got %q", m.sidebar.Title())
+	}
+}
+
+func TestRequestChatTitle_SkipsErrorsAndKeepsDefaultOnFailure(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.chatTitler = func(context.Context, *commands.Context, []ai.ChatMessage) (string, error) {
+		return "", errors.New("provider down")
+	}
+
+	m.ensureSidebar().AppendUserMessage("hello")
+	m.sidebar.AppendErrorMessage("rate limited")
+	if cmd := m.requestChatTitle(); cmd != nil {
+		t.Fatal("Expected an error reply not to count as an answer")
+	}
+
+	m.sidebar.StartAssistantMessageWithContent("hi there")
+	cmd := m.requestChatTitle()
+	if cmd == nil {
+		t.Fatal("Expected a title request once a real answer arrived")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(Model)
+	if m.sidebar.Title() != "" {
+		t.Fatalf("Expected default title after a failed request, got %q", m.sidebar.Title())
+	}
+}
+
+func TestTitleableMessages_DropsErrors(t *testing.T) {
+	messages := []ai.ChatMessage{
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: sidebar.MessagePrefix("error") + "boom"},
+		{Role: "assistant", Content: "hi"},
+	}
+	got := titleableMessages(messages)
+	if len(got) != 2 || got[1].Content != "hi" {
+		t.Fatalf("Expected error message removed, got %+v", got)
+	}
+}
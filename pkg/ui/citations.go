@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Answers cite terminal lines as <line>N</line>, where N counts every line
+// written to the buffer. The message menu lists each cited line; picking one
+// scrolls the terminal back to it and marks it.
+
+const citationOptionPrefix = "Show line "
+
+func citationOption(line int) string {
+	return citationOptionPrefix + strconv.Itoa(line)
+}
+
+func parseCitationOption(option string) (int, bool) {
+	rest, ok := strings.CutPrefix(option, citationOptionPrefix)
+	if !ok {
+		return 0, false
+	}
+	line, err := strconv.Atoi(rest)
+	return line, err == nil && line > 0
+}
+
+// showCitedLine scrolls the terminal to buffer line n (1-based) and marks
+// it. The line is found in the terminal by its text, skipping later lines
+// that read the same.
+func (m Model) showCitedLine(n int) (Model, tea.Cmd) {
+	if m.buffer == nil {
+		return m, nil
+	}
+	total := m.buffer.Total()
+	if n > total {
+		return m, m.flashStatus(fmt.Sprintf("Line %d has not been written yet", n))
+	}
+	lines := m.buffer.LinesBetween(n-1, total)
+	if len(lines) != total-n+1 {
+		return m, m.flashStatus(fmt.Sprintf("Line %d is no longer in the scrollback", n))
+	}
+	text := strings.TrimRight(ansi.Strip(string(lines[0])), " ")
+	skip := 0
+	for _, later := range lines[1:] {
+		if strings.TrimRight(ansi.Strip(string(later)), " ") == text {
+			skip++
+		}
+	}
+
+	m.setScrollMode(true)
+	if !m.viewport.RevealLine(text, skip) {
+		m.setScrollMode(false)
+		slog.Info("citation_not_shown", "line", n)
+		return m, m.flashStatus(fmt.Sprintf("Line %d is not on screen", n))
+	}
+	slog.Info("citation_shown", "line", n, "skip", skip)
+	return m, m.flashStatus(fmt.Sprintf("Showing line %d (Esc returns to live output)", n))
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/ui/styles"
+)
+
+func newCitationTestModel(t *testing.T, answer string) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(3), capture.NewSessionContext(), nil)
+	m.viewport.SetSize(40, 2)
+	for _, line := range []string{"$ make", "error: no rule", "$ ls", "Makefile"} {
+		m.buffer.Write([]byte(line))
+		m.viewport.AppendOutput([]byte(line + "\r\n"))
+	}
+	m.ensureSidebar().Show()
+	m.sidebar.AppendUserMessage("why did make fail?")
+	m.sidebar.StartAssistantMessageWithContent(answer)
+	m.sidebar.RefreshView()
+	return m
+}
+
+func TestModel_MessageMenuShowsCitedLine(t *testing.T) {
+	m := newCitationTestModel(t, "make failed on <line>2</line>.")
+
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 1})
+	if !strings.Contains(m.optionPicker.View(), "Show line 2") {
+		t.Fatalf("expected the cited line in the menu, got %q", m.optionPicker.View())
+	}
+
+	m, _ = m.handleMessageAction(citationOption(2))
+	if !m.scrollMode {
+		t.Fatal("expected scroll mode while showing the cited line")
+	}
+	if view := m.viewport.View(); !strings.Contains(view, styles.CitedLineStyle.Render("error: no rule")) {
+		t.Fatalf("expected the cited line marked, got %q", view)
+	}
+
+	m.setScrollMode(false)
+	if view := m.viewport.View(); strings.Contains(view, styles.CitedLineStyle.Render("error: no rule")) {
+		t.Fatalf("expected the mark cleared with scroll mode, got %q", view)
+	}
+}
+
+func TestModel_CitedLineOutOfScrollback(t *testing.T) {
+	m := newCitationTestModel(t, "see <line>1</line>")
+
+	m, _ = m.handleMessageAction(citationOption(1))
+	if m.scrollMode {
+		t.Fatal("expected no scroll for an evicted line")
+	}
+	if got := m.statusBar.GetMessage(); got != "Line 1 is no longer in the scrollback" {
+		t.Errorf("status message = %q", got)
+	}
+}
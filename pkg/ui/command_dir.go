@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"wtf_cli/pkg/shellquote"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// commandDirFieldKey routes the option picker asking where to run a
+// suggested command when the shell has left the directory it was
+// suggested in.
+const commandDirFieldKey = "command_dir"
+
+const (
+	commandDirCdOption   = "Prepend cd to where it was suggested"
+	commandDirHereOption = "Use it here as is"
+	commandDirCancel     = "Cancel"
+)
+
+// dirMismatch reports whether a command suggested in suggested would run
+// somewhere else from current. An unknown directory never differs.
+func dirMismatch(suggested, current string) bool {
+	return suggested != "" && current != "" && filepath.Clean(suggested) != filepath.Clean(current)
+}
+
+// confirmCommandDir asks whether to prefix cmd with a cd back to dir, where
+// the AI suggested it, before putting it at the prompt.
+func (m Model) confirmCommandDir(cmd, dir string) (Model, tea.Cmd) {
+	m.pendingDirCommand = cmd
+	m.pendingDirCommandDir = dir
+	slog.Info("command_dir_mismatch", "suggested", dir, "current", m.currentDir)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Suggested in "+dir+", the shell is in "+m.currentDir, commandDirFieldKey,
+		[]string{commandDirCdOption, commandDirHereOption, commandDirCancel}, commandDirCdOption)
+	return m, nil
+}
+
+// applyCommandDir puts the command waiting for confirmCommandDir at the
+// prompt, with "cd <dir> && " in front when asked.
+func (m Model) applyCommandDir(choice string) (Model, tea.Cmd) {
+	cmd, dir := m.pendingDirCommand, m.pendingDirCommandDir
+	m.pendingDirCommand, m.pendingDirCommandDir = "", ""
+	switch {
+	case cmd == "" || choice == commandDirCancel:
+		return m, nil
+	case choice == commandDirCdOption:
+		cmd = "cd " + shellquote.Quote(dir) + " && " + cmd
+	case choice != commandDirHereOption:
+		return m, nil
+	}
+	m.replacePromptCommand(cmd)
+	m.setTerminalFocused(true)
+	return m, m.commandAppliedHook(cmd)
+}
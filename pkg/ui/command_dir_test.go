@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newCommandDirTestModel(t *testing.T, cwd string) (Model, *bytes.Buffer) {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	pty := &bytes.Buffer{}
+	m.inputHandler = input.NewInputHandler(pty)
+	m.currentDir = cwd
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return newModel.(Model), pty
+}
+
+func TestModel_CommandFromSameDirIsApplied(t *testing.T) {
+	m, pty := newCommandDirTestModel(t, "/srv/app")
+
+	newModel, _ := m.Update(sidebar.CommandExecuteMsg{Command: "make test", Dir: "/srv/app/"})
+	m = newModel.(Model)
+	if m.optionPicker != nil && m.optionPicker.IsVisible() {
+		t.Fatal("Expected no question when the directory is unchanged")
+	}
+	if got := m.inputHandler.LineBuffer(); got != "make test" {
+		t.Fatalf("Expected the command at the prompt, got %q", got)
+	}
+	if pty.Len() == 0 {
+		t.Fatal("Expected the command written to the PTY")
+	}
+}
+
+func TestModel_CommandFromOtherDirAsksFirst(t *testing.T) {
+	tests := []struct {
+		choice string
+		want   string
+	}{
+		{commandDirCdOption, "cd '/srv/app' && make test"},
+		{commandDirHereOption, "make test"},
+		{commandDirCancel, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.choice, func(t *testing.T) {
+			m, pty := newCommandDirTestModel(t, "/tmp")
+
+			newModel, _ := m.Update(sidebar.CommandExecuteMsg{Command: "make test", Dir: "/srv/app"})
+			m = newModel.(Model)
+			if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+				t.Fatal("Expected the directory question")
+			}
+			if pty.Len() != 0 {
+				t.Fatalf("Expected nothing written before the choice, got %q", pty.String())
+			}
+
+			newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: commandDirFieldKey, Value: tt.choice})
+			m = newModel.(Model)
+			if got := m.inputHandler.LineBuffer(); got != tt.want {
+				t.Fatalf("Expected %q at the prompt, got %q", tt.want, got)
+			}
+			if m.pendingDirCommand != "" {
+				t.Fatal("Expected the pending command cleared")
+			}
+		})
+	}
+}
+
+func TestModel_AssistantMessageRecordsShellDir(t *testing.T) {
+	m, _ := newCommandDirTestModel(t, "")
+	m.cwdFunc = func() (string, error) { return "/srv/app", nil }
+
+	newModel, _ := m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	m.ensureSidebar().StartAssistantMessageWithContent("Run <cmd>make test</cmd>.")
+
+	msgs := m.sidebar.GetMessages()
+	if got := msgs[len(msgs)-1].Dir; got != "/srv/app" {
+		t.Fatalf("Expected the answer to record /srv/app, got %q", got)
+	}
+}
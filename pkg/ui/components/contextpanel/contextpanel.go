@@ -0,0 +1,83 @@
+// Package contextpanel renders the column between the terminal and the chat
+// in the three-column layout: what the next AI request will carry.
+package contextpanel
+
+import (
+	"strings"
+
+	"wtf_cli/pkg/ui/styles"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	borderSize = 1
+	paddingH   = 1
+	title      = "Context"
+)
+
+// Item is one labelled fact, e.g. the working directory.
+type Item struct {
+	Label string
+	Value string
+}
+
+// Panel shows a list of items in a bordered box.
+type Panel struct {
+	width  int
+	height int
+	items  []Item
+}
+
+// New creates an empty panel.
+func New() *Panel {
+	return &Panel{}
+}
+
+// SetSize sets the panel's outer dimensions.
+func (p *Panel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetItems replaces what the panel shows.
+func (p *Panel) SetItems(items []Item) {
+	p.items = items
+}
+
+// View renders the panel, or nothing when it has no room.
+func (p *Panel) View() string {
+	contentWidth := p.width - 2*borderSize - 2*paddingH
+	contentHeight := p.height - 2*borderSize
+	if contentWidth <= 0 || contentHeight <= 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, contentHeight)
+	lines = append(lines, styles.DialogTitleStyle.Render(ansi.Truncate(title, contentWidth, "…")), "")
+	for _, item := range p.items {
+		if item.Value == "" {
+			continue
+		}
+		lines = append(lines, styles.TextMutedStyle.Render(ansi.Truncate(item.Label, contentWidth, "…")))
+		for _, v := range strings.Split(item.Value, "\n") {
+			lines = append(lines, ansi.Truncate(v, contentWidth, "…"))
+		}
+		lines = append(lines, "")
+	}
+	if len(lines) > contentHeight {
+		lines = lines[:contentHeight]
+	}
+	for len(lines) < contentHeight {
+		lines = append(lines, "")
+	}
+	return boxStyle.
+		Width(p.width).
+		Padding(0, paddingH).
+		Render(strings.Join(lines, "\n"))
+}
+
+var boxStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(styles.ColorBorder)
@@ -0,0 +1,264 @@
+package envpicker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/utils"
+	"wtf_cli/pkg/ui/styles"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// EnvPickerSaveMsg is sent when the user confirms which variables to share.
+type EnvPickerSaveMsg struct {
+	Project  string
+	Selected []string
+}
+
+// EnvPickerPanel lists the shell's environment and lets the user tick the
+// variables to include in AI requests. Typing filters by name.
+type EnvPickerPanel struct {
+	project  string
+	vars     []ai.EnvVar
+	filtered []int // Indices into vars matching the filter
+	checked  map[string]bool
+	filter   string
+	selected int
+	scroll   int
+	visible  bool
+	width    int
+	height   int
+}
+
+// NewEnvPickerPanel creates a new environment picker.
+func NewEnvPickerPanel() *EnvPickerPanel {
+	return &EnvPickerPanel{}
+}
+
+// Show displays vars for project with the names in selected ticked.
+func (p *EnvPickerPanel) Show(project string, vars []ai.EnvVar, selected []string) {
+	p.visible = true
+	p.project = project
+	p.vars = append([]ai.EnvVar(nil), vars...)
+	p.checked = map[string]bool{}
+	for _, name := range selected {
+		p.checked[name] = true
+	}
+	p.filter = ""
+	p.selected = 0
+	p.scroll = 0
+	p.updateFiltered()
+}
+
+// Hide hides the picker.
+func (p *EnvPickerPanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible reports whether the picker is visible.
+func (p *EnvPickerPanel) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize updates the picker dimensions.
+func (p *EnvPickerPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// Selected returns the ticked variable names in sorted order.
+func (p *EnvPickerPanel) Selected() []string {
+	names := make([]string, 0, len(p.checked))
+	for _, v := range p.vars {
+		if p.checked[v.Name] {
+			names = append(names, v.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *EnvPickerPanel) updateFiltered() {
+	p.filtered = p.filtered[:0]
+	filter := strings.ToLower(p.filter)
+	for i, v := range p.vars {
+		if filter == "" || strings.Contains(strings.ToLower(v.Name), filter) {
+			p.filtered = append(p.filtered, i)
+		}
+	}
+	p.selected = 0
+	p.ensureVisible(p.listHeight())
+}
+
+// Update handles keyboard input for the picker.
+func (p *EnvPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
+	if !p.visible {
+		return nil
+	}
+
+	listHeight := p.listHeight()
+
+	switch msg.String() {
+	case "up":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down":
+		if p.selected < len(p.filtered)-1 {
+			p.selected++
+		}
+	case "pgup":
+		p.selected = max(p.selected-listHeight, 0)
+	case "pgdown":
+		p.selected = max(min(p.selected+listHeight, len(p.filtered)-1), 0)
+	case "home":
+		p.selected = 0
+	case "end":
+		p.selected = max(len(p.filtered)-1, 0)
+	case "space":
+		if len(p.filtered) > 0 {
+			name := p.vars[p.filtered[p.selected]].Name
+			p.checked[name] = !p.checked[name]
+		}
+	case "enter":
+		p.Hide()
+		save := EnvPickerSaveMsg{Project: p.project, Selected: p.Selected()}
+		return func() tea.Msg {
+			return save
+		}
+	case "esc":
+		p.Hide()
+	case "backspace":
+		if p.filter != "" {
+			runes := []rune(p.filter)
+			p.filter = string(runes[:len(runes)-1])
+			p.updateFiltered()
+		}
+	case "ctrl+u":
+		if p.filter != "" {
+			p.filter = ""
+			p.updateFiltered()
+		}
+	default:
+		if text := msg.Key().Text; text != "" && !strings.ContainsAny(text, " \t") {
+			p.filter += text
+			p.updateFiltered()
+		}
+	}
+
+	p.ensureVisible(listHeight)
+	return nil
+}
+
+// View renders the picker.
+func (p *EnvPickerPanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	boxWidth, contentWidth, listHeight := p.dimensions()
+
+	boxStyle := styles.BoxStyle.Width(boxWidth)
+	titleStyle := styles.TitleStyle
+	normalStyle := styles.TextStyle
+	selectedStyle := styles.SelectedStyle
+	descStyle := styles.TextMutedStyle
+	filterStyle := styles.FilterStyle
+	footerStyle := styles.FooterStyle
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Share environment with AI"))
+	content.WriteString("\n")
+	content.WriteString(descStyle.Render(utils.TruncateToWidth(p.project, contentWidth)))
+	content.WriteString("\n")
+	if p.filter != "" {
+		content.WriteString(filterStyle.Render("Filter: " + p.filter))
+	}
+	content.WriteString("\n")
+
+	if len(p.filtered) == 0 {
+		content.WriteString(descStyle.Render("No matching variables"))
+		for i := 1; i < listHeight; i++ {
+			content.WriteString("\n")
+		}
+	} else {
+		for i := 0; i < listHeight; i++ {
+			index := p.scroll + i
+			if index >= len(p.filtered) {
+				content.WriteString("\n")
+				continue
+			}
+			v := p.vars[p.filtered[index]]
+			box := "[ ]"
+			if p.checked[v.Name] {
+				box = "[x]"
+			}
+			value := utils.EscapeControl(v.Value)
+			if v.Secret {
+				value = ai.RedactedEnvValue
+			}
+			line := utils.TruncateToWidth(fmt.Sprintf("%s %s=%s", box, v.Name, value), contentWidth)
+			if index == p.selected {
+				content.WriteString(selectedStyle.Render(utils.PadPlain(line, contentWidth)))
+			} else {
+				content.WriteString(normalStyle.Render(line))
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	content.WriteString("\n")
+	count := fmt.Sprintf("%d shared", len(p.Selected()))
+	content.WriteString(footerStyle.Render("Space Toggle | Type to filter | Enter Save | Esc Cancel | " + count))
+
+	return boxStyle.Render(content.String())
+}
+
+func (p *EnvPickerPanel) ensureVisible(listHeight int) {
+	if len(p.filtered) == 0 {
+		p.selected = 0
+		p.scroll = 0
+		return
+	}
+	p.selected = max(min(p.selected, len(p.filtered)-1), 0)
+
+	maxScroll := max(len(p.filtered)-listHeight, 0)
+	p.scroll = min(p.scroll, maxScroll)
+	if p.selected < p.scroll {
+		p.scroll = p.selected
+	}
+	if p.selected >= p.scroll+listHeight {
+		p.scroll = p.selected - listHeight + 1
+	}
+	p.scroll = max(p.scroll, 0)
+}
+
+func (p *EnvPickerPanel) dimensions() (int, int, int) {
+	width := p.width
+	height := p.height
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+
+	available := max(width-2, 1)
+	boxWidth := min(available, 90)
+	boxWidth = max(boxWidth, min(40, available))
+	contentWidth := max(boxWidth-4, 1)
+
+	// Title, project, filter, blank before footer, footer.
+	const fixedLines = 5
+	listHeight := max(height-4-fixedLines, 1)
+
+	return boxWidth, contentWidth, listHeight
+}
+
+func (p *EnvPickerPanel) listHeight() int {
+	_, _, listHeight := p.dimensions()
+	return listHeight
+}
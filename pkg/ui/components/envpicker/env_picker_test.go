@@ -0,0 +1,91 @@
+package envpicker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func testVars() []ai.EnvVar {
+	return []ai.EnvVar{
+		{Name: "GOPATH", Value: "/home/me/go"},
+		{Name: "GH_TOKEN", Value: "ghp_secret", Secret: true},
+		{Name: "PATH", Value: "/usr/bin"},
+	}
+}
+
+func TestEnvPicker_ToggleAndSave(t *testing.T) {
+	p := NewEnvPickerPanel()
+	p.Show("/repo", testVars(), []string{"PATH"})
+
+	p.Update(testutils.TestKeySpace) // GOPATH on
+	p.Update(testutils.TestKeyEnd)
+	p.Update(testutils.TestKeySpace) // PATH off
+
+	cmd := p.Update(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("expected save command")
+	}
+	msg, ok := cmd().(EnvPickerSaveMsg)
+	if !ok {
+		t.Fatalf("expected EnvPickerSaveMsg, got %T", cmd())
+	}
+	if msg.Project != "/repo" || !reflect.DeepEqual(msg.Selected, []string{"GOPATH"}) {
+		t.Fatalf("unexpected save message: %+v", msg)
+	}
+	if p.IsVisible() {
+		t.Fatal("expected picker to hide after saving")
+	}
+}
+
+func TestEnvPicker_FilterByName(t *testing.T) {
+	p := NewEnvPickerPanel()
+	p.Show("/repo", testVars(), nil)
+
+	for _, r := range "path" {
+		p.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+	if len(p.filtered) != 2 {
+		t.Fatalf("expected GOPATH and PATH to match, got %d", len(p.filtered))
+	}
+	p.Update(testutils.TestKeyDown)
+	p.Update(testutils.TestKeySpace)
+	if got := p.Selected(); !reflect.DeepEqual(got, []string{"PATH"}) {
+		t.Fatalf("expected filter to select PATH, got %v", got)
+	}
+
+	p.Update(testutils.TestKeyBackspace)
+	if p.filter != "pat" {
+		t.Fatalf("expected backspace to trim the filter, got %q", p.filter)
+	}
+}
+
+func TestEnvPicker_ViewRedactsSecrets(t *testing.T) {
+	p := NewEnvPickerPanel()
+	p.SetSize(100, 30)
+	p.Show("/repo", testVars(), []string{"GH_TOKEN"})
+
+	view := p.View()
+	if strings.Contains(view, "ghp_secret") {
+		t.Fatal("expected secret value to be hidden")
+	}
+	if !strings.Contains(view, "[x] GH_TOKEN="+ai.RedactedEnvValue) {
+		t.Fatalf("expected ticked redacted entry, got:\n%s", view)
+	}
+}
+
+func TestEnvPicker_EscDiscardsChanges(t *testing.T) {
+	p := NewEnvPickerPanel()
+	p.Show("/repo", testVars(), nil)
+	p.Update(testutils.TestKeySpace)
+
+	if cmd := p.Update(testutils.TestKeyEsc); cmd != nil {
+		t.Fatal("expected no command on cancel")
+	}
+	if p.IsVisible() {
+		t.Fatal("expected picker to hide on esc")
+	}
+}
@@ -1,12 +1,15 @@
 package historypicker
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"wtf_cli/pkg/ui/components/utils"
 	"wtf_cli/pkg/ui/styles"
 
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 )
 
 // ShowHistoryPickerMsg is sent to trigger the history picker
@@ -19,9 +22,39 @@ type HistoryPickerSelectMsg struct {
 	Command string
 }
 
+// HistoryPickerCompleteMsg is sent when Tab is pressed in the edit stage.
+// The command is placed on the shell prompt and the shell's own completion
+// is triggered, so the user finishes editing in the terminal.
+type HistoryPickerCompleteMsg struct {
+	Command string
+}
+
+// HistoryPickerPinMsg is sent when Ctrl+P pins the output of a command run
+// this session to the AI context.
+type HistoryPickerPinMsg struct {
+	Command string
+}
+
 // HistoryPickerCancelMsg is sent when picker is cancelled
 type HistoryPickerCancelMsg struct{}
 
+// Preview describes where a session-captured command ran and how its output
+// began, shown next to the list so similar invocations can be told apart.
+type Preview struct {
+	WorkingDir string
+	Output     []string
+}
+
+// Usage is how often and when a command of the list was run.
+type Usage struct {
+	Count    int
+	LastUsed time.Time // Zero when not run this session
+}
+
+// minPreviewWidth is the content width below which the preview pane is
+// dropped and the list uses the full width.
+const minPreviewWidth = 60
+
 // HistoryPickerPanel provides a searchable TUI for command history
 type HistoryPickerPanel struct {
 	commands []string // All history commands
@@ -32,6 +65,15 @@ type HistoryPickerPanel struct {
 	visible  bool     // Panel visibility
 	width    int      // Panel dimensions
 	height   int      // Panel dimensions
+
+	// Edit stage: the selected command is shown in an editable field
+	// before it is injected into the prompt.
+	editing    bool
+	editValue  string
+	editCursor int
+
+	previews map[string]Preview // Keyed by command; session commands only
+	usage    map[string]Usage   // Keyed by command
 }
 
 // NewHistoryPickerPanel creates a new history picker panel
@@ -46,10 +88,25 @@ func (hp *HistoryPickerPanel) Show(initialFilter string, commands []string) {
 	hp.filter = initialFilter
 	hp.selected = 0
 	hp.scroll = 0
+	hp.editing = false
+	hp.editValue = ""
+	hp.editCursor = 0
 	hp.updateFiltered()
 	hp.ensureVisible()
 }
 
+// SetPreviews sets the preview pane content for session-captured commands.
+// Commands without an entry (e.g. from shell history files) show no preview.
+func (hp *HistoryPickerPanel) SetPreviews(previews map[string]Preview) {
+	hp.previews = previews
+}
+
+// SetUsage sets the run counts shown next to commands and the last run
+// time shown in the preview.
+func (hp *HistoryPickerPanel) SetUsage(usage map[string]Usage) {
+	hp.usage = usage
+}
+
 // Hide hides the picker
 func (hp *HistoryPickerPanel) Hide() {
 	hp.visible = false
@@ -60,6 +117,11 @@ func (hp *HistoryPickerPanel) IsVisible() bool {
 	return hp.visible
 }
 
+// IsEditing returns whether the picker is in the edit stage
+func (hp *HistoryPickerPanel) IsEditing() bool {
+	return hp.editing
+}
+
 // SetSize updates the picker dimensions
 func (hp *HistoryPickerPanel) SetSize(width, height int) {
 	hp.width = width
@@ -90,6 +152,10 @@ func (hp *HistoryPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 		return nil
 	}
 
+	if hp.editing {
+		return hp.handleEditKey(msg)
+	}
+
 	listHeight := hp.listHeight()
 	keyStr := msg.String()
 
@@ -142,7 +208,17 @@ func (hp *HistoryPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 		}
 		return nil
 
-	case "enter", "tab":
+	case "enter":
+		// Enter opens the edit stage with the cursor at the end
+		if len(hp.filtered) > 0 && hp.selected >= 0 && hp.selected < len(hp.filtered) {
+			hp.editing = true
+			hp.editValue = hp.filtered[hp.selected]
+			hp.editCursor = len([]rune(hp.editValue))
+		}
+		return nil
+
+	case "tab":
+		// Tab injects the selection as-is
 		if len(hp.filtered) > 0 && hp.selected >= 0 && hp.selected < len(hp.filtered) {
 			cmd := hp.filtered[hp.selected]
 			hp.Hide()
@@ -152,6 +228,16 @@ func (hp *HistoryPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 		}
 		return nil
 
+	case "ctrl+p":
+		// Only session commands have output to pin
+		if cmd, ok := hp.selectedCommand(); ok && hp.hasPreview(cmd) {
+			hp.Hide()
+			return func() tea.Msg {
+				return HistoryPickerPinMsg{Command: cmd}
+			}
+		}
+		return nil
+
 	case "esc":
 		hp.Hide()
 		return func() tea.Msg {
@@ -191,6 +277,111 @@ func (hp *HistoryPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 	}
 }
 
+// handleEditKey handles keyboard input while editing the selected command
+func (hp *HistoryPickerPanel) handleEditKey(msg tea.KeyPressMsg) tea.Cmd {
+	key := msg.Key()
+
+	switch msg.String() {
+	case "enter":
+		cmd := hp.editValue
+		hp.editing = false
+		hp.Hide()
+		return func() tea.Msg {
+			return HistoryPickerSelectMsg{Command: cmd}
+		}
+
+	case "tab":
+		// Hand the command to the shell and let its completion take over
+		cmd := hp.editValue
+		hp.editing = false
+		hp.Hide()
+		return func() tea.Msg {
+			return HistoryPickerCompleteMsg{Command: cmd}
+		}
+
+	case "esc":
+		// Back to the list, keeping filter and selection
+		hp.editing = false
+		hp.editValue = ""
+		hp.editCursor = 0
+		return nil
+
+	case "backspace":
+		runes := []rune(hp.editValue)
+		if hp.editCursor > len(runes) {
+			hp.editCursor = len(runes)
+		}
+		if hp.editCursor > 0 {
+			runes = append(runes[:hp.editCursor-1], runes[hp.editCursor:]...)
+			hp.editCursor--
+			hp.editValue = string(runes)
+		}
+		return nil
+
+	case "delete":
+		runes := []rune(hp.editValue)
+		if hp.editCursor > len(runes) {
+			hp.editCursor = len(runes)
+		}
+		if hp.editCursor < len(runes) {
+			runes = append(runes[:hp.editCursor], runes[hp.editCursor+1:]...)
+			hp.editValue = string(runes)
+		}
+		return nil
+
+	case "left":
+		if hp.editCursor > 0 {
+			hp.editCursor--
+		}
+		return nil
+
+	case "right":
+		if hp.editCursor < len([]rune(hp.editValue)) {
+			hp.editCursor++
+		}
+		return nil
+
+	case "home", "ctrl+a":
+		hp.editCursor = 0
+		return nil
+
+	case "end", "ctrl+e":
+		hp.editCursor = len([]rune(hp.editValue))
+		return nil
+
+	case "ctrl+u":
+		// Delete from cursor to start of line, like readline
+		runes := []rune(hp.editValue)
+		if hp.editCursor > len(runes) {
+			hp.editCursor = len(runes)
+		}
+		hp.editValue = string(runes[hp.editCursor:])
+		hp.editCursor = 0
+		return nil
+
+	default:
+		if key.Text != "" {
+			insert := make([]rune, 0, len(key.Text))
+			for _, r := range key.Text {
+				if r != '\n' && r != '\r' {
+					insert = append(insert, r)
+				}
+			}
+			if len(insert) == 0 {
+				return nil
+			}
+			runes := []rune(hp.editValue)
+			if hp.editCursor > len(runes) {
+				hp.editCursor = len(runes)
+			}
+			runes = append(runes[:hp.editCursor], append(insert, runes[hp.editCursor:]...)...)
+			hp.editCursor += len(insert)
+			hp.editValue = string(runes)
+		}
+		return nil
+	}
+}
+
 // View renders the picker
 func (hp *HistoryPickerPanel) View() string {
 	if !hp.visible {
@@ -213,6 +404,19 @@ func (hp *HistoryPickerPanel) View() string {
 	content.WriteString(titleStyle.Render("Command History Search"))
 	content.WriteString("\n")
 
+	if hp.editing {
+		content.WriteString(descStyle.Render("Edit command:"))
+		content.WriteString("\n\n")
+		content.WriteString(selectedStyle.Render(utils.PadPlain("  "+renderEditValue(hp.editValue, hp.editCursor, contentWidth-2), contentWidth)))
+		content.WriteString("\n")
+		for i := 1; i < listHeight; i++ {
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+		content.WriteString(footerStyle.Render("Enter Insert | Tab Shell Complete | ←→ Move | Esc Back"))
+		return boxStyle.Render(content.String())
+	}
+
 	// Filter input
 	if hp.filter != "" {
 		content.WriteString(filterStyle.Render("Filter: " + hp.filter))
@@ -221,52 +425,139 @@ func (hp *HistoryPickerPanel) View() string {
 	}
 	content.WriteString("\n\n")
 
-	// Command list
-	if len(hp.filtered) == 0 {
-		if hp.filter != "" {
-			content.WriteString(descStyle.Render("No matching commands"))
-		} else {
-			content.WriteString(descStyle.Render("No commands in history"))
-		}
-		for i := 1; i < listHeight; i++ {
-			content.WriteString("\n")
-		}
-	} else {
-		for i := 0; i < listHeight; i++ {
+	// Command list, with the preview pane on the right when there is room
+	listWidth := contentWidth
+	var preview []string
+	if len(hp.previews) > 0 && contentWidth >= minPreviewWidth {
+		listWidth = contentWidth * 55 / 100
+		preview = hp.renderPreview(contentWidth-listWidth-3, listHeight)
+	}
+
+	for i := 0; i < listHeight; i++ {
+		var line string
+		switch {
+		case len(hp.filtered) == 0 && i == 0 && hp.filter != "":
+			line = descStyle.Render(utils.PadPlain("No matching commands", listWidth))
+		case len(hp.filtered) == 0 && i == 0:
+			line = descStyle.Render(utils.PadPlain("No commands in history", listWidth))
+		case hp.scroll+i < len(hp.filtered):
 			index := hp.scroll + i
-			if index >= len(hp.filtered) {
-				content.WriteString("\n")
-				continue
-			}
 			cmd := hp.filtered[index]
+			count := ""
+			if n := hp.usage[cmd].Count; n > 1 {
+				count = fmt.Sprintf(" ×%d", n)
+			}
+			width := listWidth - lipgloss.Width(count)
 
 			// Truncate long commands
-			if len(cmd) > contentWidth-4 {
-				cmd = cmd[:contentWidth-7] + "..."
+			if len(cmd) > width-4 {
+				cmd = cmd[:max(width-7, 0)] + "..."
 			}
 
-			line := "  " + cmd
+			text := utils.PadPlain("  "+cmd, width) + count
 			if index == hp.selected {
-				line = utils.PadPlain(line, contentWidth)
-				content.WriteString(selectedStyle.Render(line))
+				line = selectedStyle.Render(text)
 			} else {
-				content.WriteString(normalStyle.Render(line))
+				line = normalStyle.Render(text)
 			}
-			content.WriteString("\n")
+		default:
+			line = strings.Repeat(" ", listWidth)
+		}
+		if preview != nil {
+			line += descStyle.Render(" │ ") + preview[i]
 		}
+		content.WriteString(line)
+		content.WriteString("\n")
 	}
 
 	// Footer with controls
 	content.WriteString("\n")
-	footerText := "↑↓ Navigate | Tab/Enter Select | Esc Cancel | Ctrl+U Clear"
+	footerText := "↑↓ Navigate | Enter Edit | Tab Insert | Esc Cancel | Ctrl+U Clear"
 	if len(hp.filtered) > listHeight {
-		footerText = "↑↓ Navigate | PgUp/PgDn Scroll | Tab/Enter Select | Esc Cancel"
+		footerText = "↑↓ Navigate | PgUp/PgDn Scroll | Enter Edit | Tab Insert | Esc Cancel"
+	}
+	if cmd, ok := hp.selectedCommand(); ok && hp.hasPreview(cmd) {
+		footerText = "↑↓ Navigate | Enter Edit | Tab Insert | Ctrl+P Pin output | Esc Cancel"
 	}
 	content.WriteString(footerStyle.Render(footerText))
 
 	return boxStyle.Render(content.String())
 }
 
+// renderPreview renders height lines describing the selected command.
+func (hp *HistoryPickerPanel) renderPreview(width, height int) []string {
+	lines := make([]string, height)
+	if width < 1 {
+		return lines
+	}
+	descStyle := styles.TextMutedStyle
+	textStyle := styles.TextStyle
+
+	var selected string
+	if hp.selected >= 0 && hp.selected < len(hp.filtered) {
+		selected = hp.filtered[hp.selected]
+	}
+	p, ok := hp.previews[selected]
+	if !ok {
+		lines[0] = descStyle.Render(utils.TruncateToWidth("Not run in this session", width))
+		return lines
+	}
+
+	row := 0
+	if p.WorkingDir != "" {
+		lines[row] = descStyle.Render(utils.TailPreservingTruncate("in "+p.WorkingDir, width))
+		row++
+	}
+	if used := hp.usage[selected].LastUsed; !used.IsZero() && row < height {
+		lines[row] = descStyle.Render(utils.TruncateToWidth("last run "+used.Format("15:04"), width))
+		row++
+	}
+	if row > 0 {
+		row++
+	}
+	if len(p.Output) == 0 && row < height {
+		lines[row] = descStyle.Render("No captured output")
+		return lines
+	}
+	for _, out := range p.Output {
+		if row >= height {
+			break
+		}
+		lines[row] = textStyle.Render(utils.TruncateToWidth(out, width))
+		row++
+	}
+	return lines
+}
+
+// renderEditValue draws the cursor into value, scrolling horizontally so the
+// cursor stays within width columns.
+func renderEditValue(value string, cursor, width int) string {
+	runes := []rune(value)
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor > len(runes) {
+		cursor = len(runes)
+	}
+	withCursor := make([]rune, 0, len(runes)+1)
+	withCursor = append(withCursor, runes[:cursor]...)
+	withCursor = append(withCursor, '█')
+	withCursor = append(withCursor, runes[cursor:]...)
+
+	if width < 1 || len(withCursor) <= width {
+		return string(withCursor)
+	}
+	start := cursor - width + 1
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+	if end > len(withCursor) {
+		end = len(withCursor)
+	}
+	return string(withCursor[start:end])
+}
+
 // ensureVisible adjusts scroll to keep selected item visible
 func (hp *HistoryPickerPanel) ensureVisible() {
 	listHeight := hp.listHeight()
@@ -362,3 +653,17 @@ func (hp *HistoryPickerPanel) listHeight() int {
 	_, _, listHeight := hp.dimensions()
 	return listHeight
 }
+
+// selectedCommand returns the highlighted command in the list stage.
+func (hp *HistoryPickerPanel) selectedCommand() (string, bool) {
+	if hp.selected < 0 || hp.selected >= len(hp.filtered) {
+		return "", false
+	}
+	return hp.filtered[hp.selected], true
+}
+
+// hasPreview reports whether cmd was run this session.
+func (hp *HistoryPickerPanel) hasPreview(cmd string) bool {
+	_, ok := hp.previews[strings.TrimSpace(cmd)]
+	return ok
+}
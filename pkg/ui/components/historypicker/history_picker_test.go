@@ -1,7 +1,9 @@
 package historypicker
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"wtf_cli/pkg/ui/components/testutils"
 )
@@ -154,6 +156,13 @@ func TestUpdate_EnterSelect(t *testing.T) {
 
 	picker.selected = 1
 
+	if cmd := picker.Update(testutils.TestKeyEnter); cmd != nil {
+		t.Fatal("First Enter should open the edit stage, not select")
+	}
+	if !picker.IsEditing() {
+		t.Fatal("Expected edit stage after Enter")
+	}
+
 	cmd := picker.Update(testutils.TestKeyEnter)
 	if cmd == nil {
 		t.Fatal("Enter should return a command")
@@ -174,6 +183,81 @@ func TestUpdate_EnterSelect(t *testing.T) {
 	}
 }
 
+func TestUpdate_EditStage(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(80, 24)
+	picker.Show("", []string{"git log"})
+
+	picker.Update(testutils.TestKeyEnter)
+	if picker.editValue != "git log" || picker.editCursor != len("git log") {
+		t.Fatalf("Expected pre-filled value with cursor at end, got %q at %d", picker.editValue, picker.editCursor)
+	}
+
+	for _, r := range " -5" {
+		picker.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+	picker.Update(testutils.TestKeyHome)
+	picker.Update(testutils.TestKeyDelete)
+	picker.Update(testutils.NewTextKeyPressMsg("G"))
+
+	if picker.editValue != "Git log -5" {
+		t.Fatalf("Expected edited value %q, got %q", "Git log -5", picker.editValue)
+	}
+	if view := picker.View(); !strings.Contains(view, "Enter Insert | Tab Shell Complete") {
+		t.Errorf("Expected edit footer in view, got:\n%s", view)
+	}
+
+	cmd := picker.Update(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("Enter in edit stage should return a command")
+	}
+	selectMsg, ok := cmd().(HistoryPickerSelectMsg)
+	if !ok || selectMsg.Command != "Git log -5" {
+		t.Fatalf("Expected edited command to be selected, got %#v", selectMsg)
+	}
+}
+
+func TestUpdate_EditStageTabCompletes(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(80, 24)
+	picker.Show("", []string{"cat /etc/ho"})
+
+	picker.Update(testutils.TestKeyEnter)
+	cmd := picker.Update(testutils.TestKeyTab)
+	if cmd == nil {
+		t.Fatal("Tab in edit stage should return a command")
+	}
+	msg := cmd()
+	completeMsg, ok := msg.(HistoryPickerCompleteMsg)
+	if !ok {
+		t.Fatalf("Expected HistoryPickerCompleteMsg, got %T", msg)
+	}
+	if completeMsg.Command != "cat /etc/ho" {
+		t.Errorf("Expected %q, got %q", "cat /etc/ho", completeMsg.Command)
+	}
+	if picker.IsVisible() {
+		t.Error("Picker should be hidden after Tab completion")
+	}
+}
+
+func TestUpdate_EditStageEscReturnsToList(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(80, 24)
+	picker.Show("", []string{"ls", "pwd"})
+	picker.selected = 1
+
+	picker.Update(testutils.TestKeyEnter)
+	if cmd := picker.Update(testutils.TestKeyEsc); cmd != nil {
+		t.Fatal("Esc in edit stage should not cancel the picker")
+	}
+	if picker.IsEditing() || !picker.IsVisible() {
+		t.Fatal("Expected picker back in list stage")
+	}
+	if picker.selected != 1 {
+		t.Errorf("Expected selection preserved, got %d", picker.selected)
+	}
+}
+
 func TestUpdate_EscCancel(t *testing.T) {
 	picker := NewHistoryPickerPanel()
 	picker.SetSize(80, 24)
@@ -418,3 +502,87 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestView_PreviewPane(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(120, 30)
+	picker.SetPreviews(map[string]Preview{
+		"make test": {WorkingDir: "/src/api", Output: []string{"ok  api/handlers", "FAIL api/store"}},
+	})
+	picker.Show("", []string{"make test", "git status"})
+
+	view := picker.View()
+	for _, want := range []string{"in /src/api", "FAIL api/store"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected preview to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	picker.Update(testutils.TestKeyDown)
+	if view := picker.View(); !strings.Contains(view, "Not run in this session") {
+		t.Errorf("Expected placeholder for a shell-history command, got:\n%s", view)
+	}
+}
+
+func TestView_ShowsUsage(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(120, 30)
+	picker.SetPreviews(map[string]Preview{"make test": {WorkingDir: "/src/api"}})
+	picker.SetUsage(map[string]Usage{
+		"make test":  {Count: 3, LastUsed: time.Date(2026, 1, 2, 14, 5, 0, 0, time.Local)},
+		"git status": {Count: 1},
+	})
+	picker.Show("", []string{"make test", "git status"})
+
+	view := picker.View()
+	for _, want := range []string{"×3", "last run 14:05"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in view, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "×1") {
+		t.Errorf("Expected no count for a single use, got:\n%s", view)
+	}
+}
+
+func TestView_PreviewPaneHiddenWhenNarrow(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(60, 24)
+	picker.SetPreviews(map[string]Preview{"make test": {WorkingDir: "/src/api"}})
+	picker.Show("", []string{"make test"})
+
+	if view := picker.View(); strings.Contains(view, "/src/api") {
+		t.Errorf("Expected no preview pane on a narrow screen, got:\n%s", view)
+	}
+}
+
+func TestUpdate_CtrlPPinsSessionCommandOutput(t *testing.T) {
+	picker := NewHistoryPickerPanel()
+	picker.SetSize(120, 30)
+	picker.SetPreviews(map[string]Preview{"make test": {Output: []string{"FAIL api/store"}}})
+	picker.Show("", []string{"git status", "make test"})
+
+	// Shell-history commands have no output to pin.
+	if cmd := picker.Update(testutils.NewCtrlKeyPressMsg('p')); cmd != nil {
+		t.Fatal("Expected Ctrl+P to do nothing for a command not run this session")
+	}
+	if strings.Contains(picker.View(), "Ctrl+P Pin output") {
+		t.Error("Expected no pin hint for a command not run this session")
+	}
+
+	picker.Update(testutils.TestKeyDown)
+	if !strings.Contains(picker.View(), "Ctrl+P Pin output") {
+		t.Errorf("Expected pin hint in footer, got:\n%s", picker.View())
+	}
+	cmd := picker.Update(testutils.NewCtrlKeyPressMsg('p'))
+	if cmd == nil {
+		t.Fatal("Expected Ctrl+P to return a command")
+	}
+	msg, ok := cmd().(HistoryPickerPinMsg)
+	if !ok || msg.Command != "make test" {
+		t.Fatalf("Expected HistoryPickerPinMsg for make test, got %#v", cmd())
+	}
+	if picker.IsVisible() {
+		t.Error("Expected picker to close after pinning")
+	}
+}
@@ -49,3 +49,154 @@ func (lm *LayoutManager) RenderLayout(viewportContent, statusBarContent string)
 func (lm *LayoutManager) GetDimensions() (width, height int) {
 	return lm.width, lm.height
 }
+
+// Mode is how the terminal and the chat sidebar share the screen.
+type Mode int
+
+const (
+	// ModeTerminal shows the terminal alone: the sidebar is closed.
+	ModeTerminal Mode = iota
+	// ModeOverlay is for narrow screens: the sidebar covers the whole
+	// terminal while it has focus, and the terminal is full width again
+	// while it does not.
+	ModeOverlay
+	// ModeSplit puts the sidebar to the right of the terminal.
+	ModeSplit
+	// ModeThreeColumn adds a context panel between the terminal and the
+	// sidebar on wide screens.
+	ModeThreeColumn
+)
+
+// String names the mode for logs.
+func (m Mode) String() string {
+	switch m {
+	case ModeOverlay:
+		return "overlay"
+	case ModeSplit:
+		return "split"
+	case ModeThreeColumn:
+		return "three_column"
+	default:
+		return "terminal"
+	}
+}
+
+// Default width thresholds of the layout presets.
+const (
+	DefaultOverlayBelow     = 80
+	DefaultThreeColumnsFrom = 200
+)
+
+const (
+	// minPaneWidth is the narrowest the terminal or sidebar is squeezed to
+	// in a split.
+	minPaneWidth = 20
+	// terminalShare is the terminal's share of the width in a split.
+	terminalShare = 0.6
+	// contextShare is the context panel's share in three columns.
+	contextShare = 0.2
+)
+
+// Presets are the screen widths at which the layout changes. Zero means
+// the default; a negative value turns the preset off.
+type Presets struct {
+	// OverlayBelow is the width below which the sidebar is an overlay.
+	OverlayBelow int
+	// ThreeColumnsFrom is the width from which the context panel is shown.
+	ThreeColumnsFrom int
+}
+
+func (p Presets) overlayBelow() int {
+	if p.OverlayBelow == 0 {
+		return DefaultOverlayBelow
+	}
+	return p.OverlayBelow
+}
+
+func (p Presets) threeColumnsFrom() int {
+	if p.ThreeColumnsFrom == 0 {
+		return DefaultThreeColumnsFrom
+	}
+	return p.ThreeColumnsFrom
+}
+
+// Columns is where each part of the screen goes. Widths of hidden parts
+// are zero.
+type Columns struct {
+	Mode Mode
+	// TerminalWidth is the terminal's width. In ModeOverlay it stays the
+	// full width, so the shell does not rewrap when the chat opens.
+	TerminalWidth int
+	ContextX      int
+	ContextWidth  int
+	SidebarX      int
+	SidebarWidth  int
+}
+
+// ColumnsFor lays out width: the terminal alone when the sidebar is closed,
+// otherwise the preset for the width.
+func ColumnsFor(width int, sidebarOpen bool, p Presets) Columns {
+	width = max(width, 0)
+	if !sidebarOpen || width == 0 {
+		return Columns{Mode: ModeTerminal, TerminalWidth: width}
+	}
+	if below := p.overlayBelow(); below > 0 && width < below {
+		return Columns{Mode: ModeOverlay, TerminalWidth: width, SidebarWidth: width}
+	}
+	left, right := SplitWidths(width)
+	cols := Columns{Mode: ModeSplit, TerminalWidth: left, SidebarX: left, SidebarWidth: right}
+	if from := p.threeColumnsFrom(); from > 0 && width >= from {
+		// The context panel takes its share from the terminal; the chat
+		// keeps the width it has in a split.
+		ctx := int(float64(width) * contextShare)
+		if left-ctx >= minPaneWidth && ctx >= minPaneWidth {
+			cols.Mode = ModeThreeColumn
+			cols.TerminalWidth = left - ctx
+			cols.ContextX = left - ctx
+			cols.ContextWidth = ctx
+		}
+	}
+	return cols
+}
+
+// SplitWidths divides total between the terminal (left) and the sidebar
+// (right), keeping both at least minPaneWidth wide when there is room.
+func SplitWidths(total int) (left int, right int) {
+	if total <= 0 {
+		return 0, 0
+	}
+
+	if total < minPaneWidth*2 {
+		left = int(float64(total) * terminalShare)
+		if left < 1 {
+			left = 1
+		}
+		right = total - left
+		if right < 1 {
+			right = 1
+			left = total - right
+		}
+		return left, right
+	}
+
+	left = int(float64(total) * terminalShare)
+	right = total - left
+
+	if left < minPaneWidth {
+		left = minPaneWidth
+		right = total - left
+	}
+	if right < minPaneWidth {
+		right = minPaneWidth
+		left = total - right
+	}
+
+	if left < 1 {
+		left = 1
+	}
+	if right < 1 {
+		right = 1
+	}
+
+	return left, right
+}
@@ -0,0 +1,40 @@
+package layout
+
+import "testing"
+
+func TestColumnsFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		width   int
+		open    bool
+		presets Presets
+		want    Columns
+	}{
+		{"closed", 120, false, Presets{}, Columns{Mode: ModeTerminal, TerminalWidth: 120}},
+		{"narrow", 70, true, Presets{}, Columns{Mode: ModeOverlay, TerminalWidth: 70, SidebarWidth: 70}},
+		{"split", 120, true, Presets{}, Columns{Mode: ModeSplit, TerminalWidth: 72, SidebarX: 72, SidebarWidth: 48}},
+		{"wide", 220, true, Presets{}, Columns{Mode: ModeThreeColumn, TerminalWidth: 88, ContextX: 88, ContextWidth: 44, SidebarX: 132, SidebarWidth: 88}},
+		{"overlay off", 70, true, Presets{OverlayBelow: -1}, Columns{Mode: ModeSplit, TerminalWidth: 42, SidebarX: 42, SidebarWidth: 28}},
+		{"three columns off", 220, true, Presets{ThreeColumnsFrom: -1}, Columns{Mode: ModeSplit, TerminalWidth: 132, SidebarX: 132, SidebarWidth: 88}},
+		{"custom thresholds", 100, true, Presets{OverlayBelow: 110}, Columns{Mode: ModeOverlay, TerminalWidth: 100, SidebarWidth: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ColumnsFor(tt.width, tt.open, tt.presets); got != tt.want {
+				t.Fatalf("ColumnsFor(%d) = %+v, want %+v", tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWidths(t *testing.T) {
+	for _, total := range []int{2, 30, 39, 40, 80, 200} {
+		left, right := SplitWidths(total)
+		if left+right != total || left < 1 || right < 1 {
+			t.Fatalf("SplitWidths(%d) = %d, %d", total, left, right)
+		}
+	}
+	if left, right := SplitWidths(45); left != 25 || right != 20 {
+		t.Fatalf("SplitWidths(45) = %d, %d, want the sidebar kept at 20", left, right)
+	}
+}
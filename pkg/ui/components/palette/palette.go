@@ -1,7 +1,9 @@
 package palette
 
 import (
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"wtf_cli/pkg/ui/styles"
 
@@ -18,6 +20,8 @@ type Command struct {
 // CommandPalette displays available slash commands
 type CommandPalette struct {
 	commands []Command
+	aliases  []Command
+	disabled []string
 	selected int
 	filter   string
 	visible  bool
@@ -25,21 +29,74 @@ type CommandPalette struct {
 	height   int
 }
 
+// builtinCommands are the commands every palette offers.
+var builtinCommands = []Command{
+	{Name: "/chat", Description: "Toggle chat sidebar"},
+	{Name: "/explain", Description: "Explain the output on screen"},
+	{Name: "/wtf", Description: "Diagnose the last failed command"},
+	{Name: "/why", Description: "Find the root cause, with system facts"},
+	{Name: "/history", Description: "Show command history"},
+	{Name: "/settings", Description: "Open settings panel"},
+	{Name: "/language", Description: "Choose the language AI answers are written in"},
+	{Name: "/provider", Description: "Switch the AI provider for this session"},
+	{Name: "/export", Description: "Save the chat's suggested commands as a shell script"},
+	{Name: "/env", Description: "Choose environment variables to share with AI"},
+	{Name: "/watch", Description: "Re-run a command and explain when it changes or fails"},
+	{Name: "/prompts", Description: "Review system prompt variants and pick the default"},
+	{Name: "/unpin", Description: "Remove output pinned to every AI request"},
+	{Name: "/tasks", Description: "Pick a build, test or run command of this project"},
+	{Name: "/trust", Description: "Choose how much of this workspace is shared with AI"},
+	{Name: "/logs", Description: "Follow the end of the wtf_cli log"},
+	{Name: "/remember", Description: "Remember a fact about this project for future AI requests"},
+	{Name: "/memories", Description: "Review and forget facts remembered for this project"},
+	{Name: "/snippets", Description: "Use, rename, delete or pin scripts saved in ~/.wtf_cli/scripts"},
+	{Name: "/usage", Description: "Show this month's token usage and budgets per provider"},
+	{Name: "/doctor", Description: "Check the config and provider client certificates"},
+	{Name: "/trigger", Description: "Act when terminal output matches a pattern"},
+	{Name: "/peek", Description: "Show the raw bytes a terminal line was captured from"},
+	{Name: "/mark", Description: "Bookmark the current scrollback position"},
+	{Name: "/marks", Description: "Jump to a bookmark in the scrollback"},
+	{Name: "/replay", Description: "Replay this session's commands and ask AI about any point"},
+	{Name: "/privacy", Description: "Stop capturing terminal output and commands"},
+	{Name: "/readonly", Description: "Stop keys, pastes and commands from reaching the shell"},
+	{Name: "/calc", Description: "Calculate, or convert sizes and durations, offline"},
+	{Name: "/ts", Description: "Convert between Unix timestamps and dates"},
+	{Name: "/b64", Description: "Base64-encode text, or decode it with -d"},
+	{Name: "/jsonpp", Description: "Pretty-print JSON from the selection or arguments"},
+	{Name: "/help", Description: "Show help"},
+}
+
 // NewCommandPalette creates a new command palette
 func NewCommandPalette() *CommandPalette {
 	return &CommandPalette{
-		commands: []Command{
-			{Name: "/chat", Description: "Toggle chat sidebar"},
-			{Name: "/explain", Description: "Analyze last output and suggest fixes"},
-			{Name: "/history", Description: "Show command history"},
-			{Name: "/settings", Description: "Open settings panel"},
-			{Name: "/help", Description: "Show help"},
-		},
+		commands: slices.Clone(builtinCommands),
 		selected: 0,
 		visible:  false,
 	}
 }
 
+// SetAliases lists user-defined aliases after the built-in commands,
+// replacing any set before.
+func (p *CommandPalette) SetAliases(aliases []Command) {
+	p.aliases = slices.Clone(aliases)
+	p.rebuild()
+}
+
+// SetDisabled hides the built-in commands names, e.g. those an
+// organization policy disables.
+func (p *CommandPalette) SetDisabled(names []string) {
+	p.disabled = slices.Clone(names)
+	p.rebuild()
+}
+
+func (p *CommandPalette) rebuild() {
+	p.commands = slices.DeleteFunc(slices.Clone(builtinCommands), func(c Command) bool {
+		return slices.Contains(p.disabled, c.Name)
+	})
+	p.commands = append(p.commands, p.aliases...)
+	p.selected = 0
+}
+
 // Show makes the palette visible
 func (p *CommandPalette) Show() {
 	p.visible = true
@@ -63,14 +120,40 @@ func (p *CommandPalette) SetSize(width, height int) {
 	p.height = height
 }
 
+// query splits the filter into the command part and its arguments, so
+// "watch make test" selects /watch with arguments "make test". Only a first
+// word naming a command exactly is split off; any other filter is matched
+// whole, spaces included.
+func (p *CommandPalette) query() (string, string) {
+	filter := strings.TrimLeft(p.filter, " ")
+	name, args, ok := strings.Cut(filter, " ")
+	if ok && p.isCommand(name) {
+		return name, strings.TrimSpace(args)
+	}
+	return strings.TrimRight(filter, " "), ""
+}
+
+// isCommand reports whether name, with or without its "/", is the name of
+// a listed command.
+func (p *CommandPalette) isCommand(name string) bool {
+	name = "/" + strings.TrimPrefix(name, "/")
+	for _, cmd := range p.commands {
+		if strings.EqualFold(cmd.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // filteredCommands returns commands matching the current filter
 func (p *CommandPalette) filteredCommands() []Command {
-	if p.filter == "" {
+	name, _ := p.query()
+	if name == "" {
 		return p.commands
 	}
 
 	var filtered []Command
-	filter := strings.ToLower(p.filter)
+	filter := strings.ToLower(name)
 	for _, cmd := range p.commands {
 		if strings.Contains(strings.ToLower(cmd.Name), filter) ||
 			strings.Contains(strings.ToLower(cmd.Description), filter) {
@@ -83,10 +166,13 @@ func (p *CommandPalette) filteredCommands() []Command {
 // PaletteSelectMsg is sent when a command is selected
 type PaletteSelectMsg struct {
 	Command string
+	Args    string // Text typed after the command name
 }
 
 // PaletteCancelMsg is sent when palette is cancelled
-type PaletteCancelMsg struct{}
+type PaletteCancelMsg struct {
+	Text string // Literal text typed since "/", to hand back to the shell
+}
 
 // Update handles keyboard input for the palette
 func (p *CommandPalette) Update(msg tea.KeyPressMsg) tea.Cmd {
@@ -110,26 +196,34 @@ func (p *CommandPalette) Update(msg tea.KeyPressMsg) tea.Cmd {
 		// Select current command
 		if len(filtered) > 0 && p.selected < len(filtered) {
 			cmd := filtered[p.selected]
+			_, args := p.query()
 			p.Hide()
 			return func() tea.Msg {
-				return PaletteSelectMsg{Command: cmd.Name}
+				return PaletteSelectMsg{Command: cmd.Name, Args: args}
 			}
 		}
 		return nil
 
 	case "esc":
-		// Cancel palette
+		// Cancel palette; what was typed, including the opening "/",
+		// goes to the shell as if the palette had never opened.
+		text := "/" + p.filter
 		p.Hide()
 		return func() tea.Msg {
-			return PaletteCancelMsg{}
+			return PaletteCancelMsg{Text: text}
 		}
 
 	case "backspace":
-		// Delete filter character
-		if len(p.filter) > 0 {
-			p.filter = p.filter[:len(p.filter)-1]
-			p.selected = 0 // Reset selection when filter changes
+		// Delete filter character; deleting past the "/" closes the palette
+		if len(p.filter) == 0 {
+			p.Hide()
+			return func() tea.Msg {
+				return PaletteCancelMsg{}
+			}
 		}
+		_, size := utf8.DecodeLastRuneInString(p.filter)
+		p.filter = p.filter[:len(p.filter)-size]
+		p.selected = 0 // Reset selection when filter changes
 		return nil
 
 	default:
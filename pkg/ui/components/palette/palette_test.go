@@ -3,6 +3,8 @@ package palette
 import (
 	"testing"
 
+	"wtf_cli/pkg/ui/components/testutils"
+
 	"charm.land/lipgloss/v2"
 )
 
@@ -19,3 +21,123 @@ func TestCommandPalette_ClampsToSmallWidth(t *testing.T) {
 		t.Fatalf("expected width <= 20, got %d", got)
 	}
 }
+
+func TestCommandPalette_PassesArgumentsAfterName(t *testing.T) {
+	p := NewCommandPalette()
+	p.Show()
+	for _, r := range "watch make  test" {
+		p.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+
+	cmd := p.Update(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("expected a select command")
+	}
+	msg, ok := cmd().(PaletteSelectMsg)
+	if !ok {
+		t.Fatalf("expected PaletteSelectMsg, got %T", cmd())
+	}
+	if msg.Command != "/watch" || msg.Args != "make  test" {
+		t.Fatalf("unexpected selection: %+v", msg)
+	}
+}
+
+func TestCommandPalette_MatchesFilterWithSpacesWhole(t *testing.T) {
+	p := NewCommandPalette()
+	p.Show()
+	for _, r := range "output on" {
+		p.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+	if got := p.GetSelectedCommand(); got != "/explain" {
+		t.Fatalf("selected %q, want /explain", got)
+	}
+
+	cmd := p.Update(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("expected a select command")
+	}
+	if msg := cmd().(PaletteSelectMsg); msg.Command != "/explain" || msg.Args != "" {
+		t.Fatalf("unexpected selection: %+v", msg)
+	}
+}
+
+func TestCommandPalette_ListsAliases(t *testing.T) {
+	p := NewCommandPalette()
+	p.SetAliases([]Command{{Name: "/wtf-verbose", Description: "Explain with 200 lines"}})
+	p.Show()
+	for _, r := range "verbose" {
+		p.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+	if got := p.GetSelectedCommand(); got != "/wtf-verbose" {
+		t.Fatalf("selected %q, want /wtf-verbose", got)
+	}
+
+	p.SetAliases(nil)
+	if got := p.GetSelectedCommand(); got == "/wtf-verbose" {
+		t.Fatal("expected the alias to be gone after SetAliases(nil)")
+	}
+}
+
+func TestCommandPalette_HidesDisabledCommands(t *testing.T) {
+	p := NewCommandPalette()
+	p.SetDisabled([]string{"/watch"})
+	p.SetAliases([]Command{{Name: "/wtf-verbose", Description: "Explain with 200 lines"}})
+	p.Show()
+	for _, r := range "watch" {
+		p.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+	if got := p.GetSelectedCommand(); got == "/watch" {
+		t.Fatal("expected /watch to be hidden")
+	}
+
+	p.SetDisabled(nil)
+	if got := p.GetSelectedCommand(); got != "/watch" {
+		t.Fatalf("selected %q, want /watch once enabled again", got)
+	}
+}
+
+func TestCommandPalette_EscReturnsTypedText(t *testing.T) {
+	p := NewCommandPalette()
+	p.Show()
+	for _, r := range "usr/bin" {
+		p.Update(testutils.NewTextKeyPressMsg(string(r)))
+	}
+
+	cmd := p.Update(testutils.TestKeyEsc)
+	if cmd == nil {
+		t.Fatal("expected a cancel command")
+	}
+	msg, ok := cmd().(PaletteCancelMsg)
+	if !ok {
+		t.Fatalf("expected PaletteCancelMsg, got %T", cmd())
+	}
+	if msg.Text != "/usr/bin" {
+		t.Fatalf("expected typed text %q, got %q", "/usr/bin", msg.Text)
+	}
+	if p.IsVisible() {
+		t.Fatal("expected palette to be hidden")
+	}
+}
+
+func TestCommandPalette_BackspacePastSlashCloses(t *testing.T) {
+	p := NewCommandPalette()
+	p.Show()
+	p.Update(testutils.NewTextKeyPressMsg("é"))
+
+	if cmd := p.Update(testutils.TestKeyBackspace); cmd != nil {
+		t.Fatal("expected first backspace to only edit the filter")
+	}
+	if p.filter != "" {
+		t.Fatalf("expected empty filter, got %q", p.filter)
+	}
+	cmd := p.Update(testutils.TestKeyBackspace)
+	if cmd == nil {
+		t.Fatal("expected backspace on empty filter to cancel")
+	}
+	if msg, ok := cmd().(PaletteCancelMsg); !ok || msg.Text != "" {
+		t.Fatalf("expected PaletteCancelMsg without text, got %#v", cmd())
+	}
+	if p.IsVisible() {
+		t.Fatal("expected palette to be hidden")
+	}
+}
@@ -14,9 +14,10 @@ import (
 type OpenModelPickerMsg struct {
 	Options  []ai.ModelInfo
 	Current  string
-	APIURL   string
 	FieldKey string // Which model field this picker is for (e.g., "model", "openai_model", "copilot_model", "anthropic_model")
-	APIKey   string // API key for dynamic model fetching (OpenAI, Anthropic)
+	// Source lists the provider's current models in the background to
+	// replace Options. Nil keeps Options.
+	Source ai.ModelSource
 }
 
 type ModelPickerSelectMsg struct {
@@ -24,11 +25,6 @@ type ModelPickerSelectMsg struct {
 	FieldKey string // Which model field was selected (e.g., "model", "openai_model", "copilot_model", "anthropic_model")
 }
 
-type ModelPickerRefreshMsg struct {
-	Cache ai.ModelCache
-	Err   error
-}
-
 // ModelPickerPanel provides a searchable list of models.
 type ModelPickerPanel struct {
 	options  []ai.ModelInfo
@@ -40,6 +36,7 @@ type ModelPickerPanel struct {
 	height   int
 	current  string
 	fieldKey string // Which model field this picker is for
+	pageSize int    // Rows PgUp/PgDn move; 0 is a screenful
 }
 
 // NewModelPickerPanel creates a new model picker panel.
@@ -111,6 +108,11 @@ func (p *ModelPickerPanel) SetSize(width, height int) {
 	p.height = height
 }
 
+// SetPageSize sets how many rows PgUp and PgDn move; 0 moves a screenful.
+func (p *ModelPickerPanel) SetPageSize(rows int) {
+	p.pageSize = max(rows, 0)
+}
+
 // Update handles keyboard input for the picker.
 func (p *ModelPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 	if !p.visible {
@@ -138,7 +140,7 @@ func (p *ModelPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 
 	case "pgup":
 		if len(filtered) > 0 {
-			p.selected -= listHeight
+			p.selected -= pageStep(p.pageSize, listHeight)
 			if p.selected < 0 {
 				p.selected = 0
 			}
@@ -148,7 +150,7 @@ func (p *ModelPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 
 	case "pgdown":
 		if len(filtered) > 0 {
-			p.selected += listHeight
+			p.selected += pageStep(p.pageSize, listHeight)
 			if p.selected > len(filtered)-1 {
 				p.selected = len(filtered) - 1
 			}
@@ -182,12 +184,18 @@ func (p *ModelPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 		return nil
 
 	case "esc":
+		// Esc clears the search before it closes the picker.
+		if p.filter != "" {
+			p.filter = ""
+			p.ensureVisible(p.filteredOptions(), listHeight)
+			return nil
+		}
 		p.Hide()
 		return nil
 
 	case "backspace":
 		if len(p.filter) > 0 {
-			p.filter = p.filter[:len(p.filter)-1]
+			p.filter = trimLastRune(p.filter)
 			p.selected = 0
 			p.scroll = 0
 		}
@@ -267,27 +275,38 @@ func (p *ModelPickerPanel) View() string {
 			if descWidth > 0 {
 				desc = utils.TruncateToWidth(modelOptionDesc(option), descWidth)
 			}
+			query := strings.TrimSpace(p.filter)
 
 			if index == p.selected {
 				line := "  " + labelText
 				if desc != "" {
 					line += " " + desc
 				}
-				line = utils.PadPlain(line, contentWidth)
-				content.WriteString(selectedStyle.Render(line))
+				padding := contentWidth - lipgloss.Width(line)
+				content.WriteString(selectedStyle.Render("  "))
+				content.WriteString(highlightMatch(labelText, query, selectedStyle, true))
+				if desc != "" {
+					content.WriteString(selectedStyle.Render(" "))
+					content.WriteString(highlightMatch(desc, query, selectedStyle, true))
+				}
+				content.WriteString(selectedStyle.Render(strings.Repeat(" ", max(padding, 0))))
 			} else {
-				line := normalStyle.Render("  " + labelText)
+				content.WriteString(normalStyle.Render("  "))
+				content.WriteString(highlightMatch(labelText, query, normalStyle, false))
 				if desc != "" {
-					line += " " + descStyle.Render(desc)
+					content.WriteString(" " + highlightMatch(desc, query, descStyle, false))
 				}
-				content.WriteString(line)
 			}
 			content.WriteString("\n")
 		}
 	}
 
 	content.WriteString("\n")
-	content.WriteString(footerStyle.Render("Up/Down Navigate | Enter Select | Esc Cancel"))
+	footer := "Up/Down Navigate | Enter Select | Esc Cancel"
+	if p.filter != "" {
+		footer = "Up/Down Navigate | Enter Select | Esc Clear"
+	}
+	content.WriteString(footerStyle.Render(footer))
 
 	return boxStyle.Render(content.String())
 }
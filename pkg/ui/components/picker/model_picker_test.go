@@ -2,12 +2,14 @@ package picker
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/ui/components/testutils"
 
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestModelPicker_ShowSelectCurrent(t *testing.T) {
@@ -108,6 +110,36 @@ func TestModelPicker_ScrollsWithNavigation(t *testing.T) {
 	}
 }
 
+func TestModelPicker_PageSizeAndEscClearsFilter(t *testing.T) {
+	picker := NewModelPickerPanel()
+	picker.SetSize(80, 24)
+	picker.SetPageSize(3)
+
+	options := make([]ai.ModelInfo, 20)
+	for i := range options {
+		options[i] = ai.ModelInfo{ID: fmt.Sprintf("model-%d", i), Name: fmt.Sprintf("Model %d", i)}
+	}
+	picker.Show(options, "", "model")
+
+	picker.Update(testutils.TestKeyPgDown)
+	if picker.selected != 3 {
+		t.Fatalf("Expected PgDn to move 3 rows, got selected=%d", picker.selected)
+	}
+	picker.Update(testutils.TestKeyEnd)
+	if picker.selected != 19 {
+		t.Fatalf("Expected End to select the last model, got %d", picker.selected)
+	}
+
+	picker.Update(testutils.NewTextKeyPressMsg("1"))
+	if !strings.Contains(ansi.Strip(picker.View()), "Esc Clear") {
+		t.Fatal("Expected the footer to offer clearing the search")
+	}
+	picker.Update(testutils.TestKeyEsc)
+	if !picker.visible || picker.filter != "" {
+		t.Fatalf("Expected Esc to clear the filter first, visible=%v filter=%q", picker.visible, picker.filter)
+	}
+}
+
 func TestModelPicker_EscCloses(t *testing.T) {
 	picker := NewModelPickerPanel()
 	picker.SetSize(80, 24)
@@ -7,6 +7,7 @@ import (
 	"wtf_cli/pkg/ui/styles"
 
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 )
 
 type OpenOptionPickerMsg struct {
@@ -21,7 +22,35 @@ type OptionPickerSelectMsg struct {
 	Value    string
 }
 
+// OptionPickerCancelMsg is sent when Esc closes the picker without a
+// selection, so whoever opened it can drop what was waiting on the answer.
+type OptionPickerCancelMsg struct {
+	FieldKey string
+}
+
+// OptionAction is a key that acts on the selected option instead of
+// jumping to it, e.g. d to delete it.
+type OptionAction struct {
+	Key     string // One printable key, e.g. "d"
+	Label   string // Shown in the footer, e.g. "Delete"
+	Confirm bool   // Ask y/n before acting
+	Input   bool   // Ask for text
+	// Prefill returns the text an Input action starts from; nil starts from
+	// the option itself.
+	Prefill func(option string) string
+}
+
+// OptionPickerActionMsg is sent when an action key is used. The picker
+// stays open; the handler shows it again with the updated options.
+type OptionPickerActionMsg struct {
+	FieldKey string
+	Action   string // Key of the action
+	Value    string // Option acted on
+	Input    string // Text entered for an Input action
+}
+
 // OptionPickerPanel provides a simple list picker for settings options.
+// Typing jumps to the first option matching what was typed.
 type OptionPickerPanel struct {
 	title    string
 	fieldKey string
@@ -31,6 +60,11 @@ type OptionPickerPanel struct {
 	visible  bool
 	width    int
 	height   int
+	search   string // Typed text the selection jumped to
+	pageSize int    // Rows PgUp/PgDn move; 0 is a screenful
+	actions  []OptionAction
+	pending  *OptionAction // Action waiting for its confirmation or text
+	input    string        // Text typed for a pending Input action
 }
 
 // NewOptionPickerPanel creates a new option picker panel.
@@ -38,7 +72,8 @@ func NewOptionPickerPanel() *OptionPickerPanel {
 	return &OptionPickerPanel{}
 }
 
-// Show displays the picker for a settings field.
+// Show displays the picker for a settings field. It has no action keys
+// until SetActions is called.
 func (p *OptionPickerPanel) Show(title, fieldKey string, options []string, current string) {
 	p.visible = true
 	p.title = title
@@ -46,6 +81,9 @@ func (p *OptionPickerPanel) Show(title, fieldKey string, options []string, curre
 	p.options = append([]string(nil), options...)
 	p.selected = 0
 	p.scroll = 0
+	p.search = ""
+	p.actions = nil
+	p.pending = nil
 
 	if current != "" {
 		for i, option := range p.options {
@@ -75,15 +113,37 @@ func (p *OptionPickerPanel) SetSize(width, height int) {
 	p.height = height
 }
 
+// SetActions sets the action keys of the picker shown. They act only
+// while nothing has been typed to jump, so other letters still search.
+func (p *OptionPickerPanel) SetActions(actions []OptionAction) {
+	p.actions = actions
+}
+
+// SetPageSize sets how many rows PgUp and PgDn move; 0 moves a screenful.
+func (p *OptionPickerPanel) SetPageSize(rows int) {
+	p.pageSize = max(rows, 0)
+}
+
 // Update handles keyboard input for the picker.
 func (p *OptionPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 	if !p.visible {
 		return nil
 	}
 
+	if p.pending != nil {
+		return p.updatePending(msg)
+	}
+
 	listHeight := p.listHeight()
 
 	keyStr := msg.String()
+	if p.search == "" && len(p.options) > 0 {
+		for i := range p.actions {
+			if p.actions[i].Key == keyStr {
+				return p.startAction(&p.actions[i])
+			}
+		}
+	}
 	switch keyStr {
 	case "up":
 		if p.selected > 0 {
@@ -101,7 +161,7 @@ func (p *OptionPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 
 	case "pgup":
 		if len(p.options) > 0 {
-			p.selected -= listHeight
+			p.selected -= pageStep(p.pageSize, listHeight)
 			if p.selected < 0 {
 				p.selected = 0
 			}
@@ -111,7 +171,7 @@ func (p *OptionPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 
 	case "pgdown":
 		if len(p.options) > 0 {
-			p.selected += listHeight
+			p.selected += pageStep(p.pageSize, listHeight)
 			if p.selected > len(p.options)-1 {
 				p.selected = len(p.options) - 1
 			}
@@ -144,13 +204,95 @@ func (p *OptionPickerPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 		return nil
 
 	case "esc":
+		// Esc clears a search before it closes the picker.
+		if p.search != "" {
+			p.search = ""
+			return nil
+		}
 		p.Hide()
+		fieldKey := p.fieldKey
+		return func() tea.Msg {
+			return OptionPickerCancelMsg{FieldKey: fieldKey}
+		}
+
+	case "backspace":
+		if p.search != "" {
+			p.search = trimLastRune(p.search)
+			p.jump(listHeight)
+		}
 		return nil
 	}
 
+	if text := msg.Key().Text; text != "" {
+		p.search += text
+		p.jump(listHeight)
+	}
 	return nil
 }
 
+// startAction runs action on the selected option, or first asks for its
+// confirmation or text.
+func (p *OptionPickerPanel) startAction(action *OptionAction) tea.Cmd {
+	if action.Confirm || action.Input {
+		p.pending = action
+		p.input = ""
+		if action.Input {
+			p.input = p.options[p.selected]
+			if action.Prefill != nil {
+				p.input = action.Prefill(p.input)
+			}
+		}
+		return nil
+	}
+	return p.actionMsg(action, "")
+}
+
+// updatePending handles keys while an action waits: y or Enter confirms
+// and any other key cancels; an Input action edits its text until Enter.
+func (p *OptionPickerPanel) updatePending(msg tea.KeyPressMsg) tea.Cmd {
+	action := p.pending
+	keyStr := msg.String()
+	if !action.Input {
+		p.pending = nil
+		if keyStr == "y" || keyStr == "enter" {
+			return p.actionMsg(action, "")
+		}
+		return nil
+	}
+	switch keyStr {
+	case "enter":
+		p.pending = nil
+		if text := strings.TrimSpace(p.input); text != "" {
+			return p.actionMsg(action, text)
+		}
+	case "esc":
+		p.pending = nil
+	case "backspace":
+		p.input = trimLastRune(p.input)
+	default:
+		p.input += msg.Key().Text
+	}
+	return nil
+}
+
+func (p *OptionPickerPanel) actionMsg(action *OptionAction, input string) tea.Cmd {
+	msg := OptionPickerActionMsg{
+		FieldKey: p.fieldKey,
+		Action:   action.Key,
+		Value:    p.options[p.selected],
+		Input:    input,
+	}
+	return func() tea.Msg { return msg }
+}
+
+// jump selects the first option matching the search, if any.
+func (p *OptionPickerPanel) jump(listHeight int) {
+	if i := jumpTarget(p.options, p.search); i >= 0 {
+		p.selected = i
+		p.ensureVisible(listHeight)
+	}
+}
+
 // View renders the picker.
 func (p *OptionPickerPanel) View() string {
 	if !p.visible {
@@ -182,24 +324,46 @@ func (p *OptionPickerPanel) View() string {
 				content.WriteString("\n")
 				continue
 			}
-			option := p.options[index]
-			line := "  " + option
+			option := utils.TruncateToWidth(p.options[index], contentWidth-2)
 			if index == p.selected {
-				line = utils.PadPlain(line, contentWidth)
-				content.WriteString(selectedStyle.Render(line))
+				padding := contentWidth - 2 - lipgloss.Width(option)
+				content.WriteString(selectedStyle.Render("  "))
+				content.WriteString(highlightMatch(option, p.search, selectedStyle, true))
+				content.WriteString(selectedStyle.Render(strings.Repeat(" ", max(padding, 0))))
 			} else {
-				content.WriteString(normalStyle.Render(line))
+				content.WriteString(normalStyle.Render("  "))
+				content.WriteString(highlightMatch(option, p.search, normalStyle, false))
 			}
 			content.WriteString("\n")
 		}
 	}
 
 	content.WriteString("\n")
-	content.WriteString(footerStyle.Render("Up/Down Navigate | Enter Select | Esc Cancel"))
+	content.WriteString(footerStyle.Render(utils.TruncateToWidth(p.footer(), contentWidth)))
 
 	return boxStyle.Render(content.String())
 }
 
+func (p *OptionPickerPanel) footer() string {
+	switch {
+	case p.pending != nil && p.pending.Input:
+		return p.pending.Label + ": " + p.input + "█ | Enter Save | Esc Cancel"
+	case p.pending != nil:
+		return p.pending.Label + "? y/Enter Yes | any other key No"
+	case p.search == "" && len(p.actions) > 0:
+		keys := make([]string, 0, len(p.actions))
+		for _, action := range p.actions {
+			keys = append(keys, action.Key+" "+action.Label)
+		}
+		return strings.Join(keys, " | ") + " | Enter Select | Esc Cancel"
+	case p.search == "":
+		return "Type to jump | Up/Down Navigate | Enter Select | Esc Cancel"
+	case jumpTarget(p.options, p.search) < 0:
+		return "Jump: " + p.search + " (no match) | Esc Clear"
+	}
+	return "Jump: " + p.search + " | Enter Select | Esc Clear"
+}
+
 func (p *OptionPickerPanel) ensureVisible(listHeight int) {
 	if len(p.options) == 0 {
 		p.selected = 0
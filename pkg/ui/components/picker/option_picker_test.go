@@ -1,11 +1,15 @@
 package picker
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"wtf_cli/pkg/ui/components/testutils"
 
+	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestOptionPicker_ShowSelectCurrent(t *testing.T) {
@@ -57,6 +61,76 @@ func TestOptionPicker_SelectEmitsMsg(t *testing.T) {
 	}
 }
 
+func TestOptionPicker_TypeToJump(t *testing.T) {
+	picker := NewOptionPickerPanel()
+	picker.SetSize(80, 24)
+	picker.Show("Language", "language", []string{"English", "German", "Spanish", "Portuguese", "Georgian"}, "")
+
+	picker.Update(testutils.NewTextKeyPressMsg("g"))
+	if picker.selected != 1 {
+		t.Fatalf("Expected g to jump to German, got %d", picker.selected)
+	}
+	picker.Update(testutils.NewTextKeyPressMsg("e"))
+	picker.Update(testutils.NewTextKeyPressMsg("o"))
+	if picker.selected != 4 {
+		t.Fatalf("Expected geo to jump to Georgian, got %d", picker.selected)
+	}
+	view := ansi.Strip(picker.View())
+	if !strings.Contains(view, "Jump: geo") {
+		t.Fatalf("Expected the footer to show the search, got:\n%s", view)
+	}
+
+	picker.Update(testutils.TestKeyBackspace)
+	picker.Update(testutils.TestKeyBackspace)
+	if picker.selected != 1 || picker.search != "g" {
+		t.Fatalf("Expected backspace to jump back to German, got %d (%q)", picker.selected, picker.search)
+	}
+
+	// Without a prefix match, the first option containing the text wins.
+	picker.Update(testutils.TestKeyEsc)
+	picker.Update(testutils.NewTextKeyPressMsg("u"))
+	picker.Update(testutils.NewTextKeyPressMsg("g"))
+	if picker.selected != 3 {
+		t.Fatalf("Expected ug to jump to Portuguese, got %d", picker.selected)
+	}
+
+	picker.Update(testutils.TestKeyEsc)
+	if !picker.visible || picker.search != "" {
+		t.Fatal("Expected Esc to clear the search before closing")
+	}
+	cmd := picker.Update(testutils.TestKeyEnter)
+	if msg, ok := cmd().(OptionPickerSelectMsg); !ok || msg.Value != "Portuguese" {
+		t.Fatalf("Expected Portuguese to be selected, got %#v", cmd())
+	}
+}
+
+func TestOptionPicker_PageSize(t *testing.T) {
+	picker := NewOptionPickerPanel()
+	picker.SetSize(80, 40)
+	options := make([]string, 50)
+	for i := range options {
+		options[i] = fmt.Sprintf("option %d", i)
+	}
+	picker.Show("Options", "options", options, "")
+
+	picker.Update(testutils.TestKeyPgDown)
+	if want := picker.listHeight(); picker.selected != want {
+		t.Fatalf("Expected PgDn to move a screenful (%d), got %d", want, picker.selected)
+	}
+
+	picker.SetPageSize(5)
+	picker.Update(testutils.TestKeyHome)
+	picker.Update(testutils.TestKeyPgDown)
+	picker.Update(testutils.TestKeyPgDown)
+	if picker.selected != 10 {
+		t.Fatalf("Expected two pages of 5, got %d", picker.selected)
+	}
+	picker.Update(testutils.TestKeyPgUp)
+	if picker.selected != 5 {
+		t.Fatalf("Expected PgUp to move back 5, got %d", picker.selected)
+	}
+}
+
 func TestOptionPicker_EscCloses(t *testing.T) {
 	picker := NewOptionPickerPanel()
 	picker.SetSize(80, 24)
@@ -65,8 +139,11 @@ func TestOptionPicker_EscCloses(t *testing.T) {
 	picker.Show("Log Level", "log_level", options, "debug")
 
 	cmd := picker.Update(testutils.TestKeyEsc)
-	if cmd != nil {
-		t.Fatal("Expected nil command on Esc")
+	if cmd == nil {
+		t.Fatal("Expected a cancel message on Esc")
+	}
+	if msg, ok := cmd().(OptionPickerCancelMsg); !ok || msg.FieldKey != "log_level" {
+		t.Fatalf("Expected OptionPickerCancelMsg for log_level, got %#v", cmd())
 	}
 	if picker.visible {
 		t.Fatal("Expected picker to be hidden after Esc")
@@ -88,3 +165,63 @@ func TestOptionPicker_ClampsToSmallWidth(t *testing.T) {
 		t.Fatalf("expected width <= 28, got %d", got)
 	}
 }
+
+func TestOptionPicker_Actions(t *testing.T) {
+	picker := NewOptionPickerPanel()
+	picker.SetSize(80, 24)
+	picker.Show("Saved scripts", "snippets", []string{"deploy.sh", "backup.sh"}, "")
+	picker.SetActions([]OptionAction{
+		{Key: "r", Label: "Rename", Input: true, Prefill: strings.ToUpper},
+		{Key: "d", Label: "Delete", Confirm: true},
+		{Key: "p", Label: "Pin"},
+	})
+	actionMsg := func(cmd tea.Cmd) OptionPickerActionMsg {
+		t.Helper()
+		if cmd == nil {
+			t.Fatal("expected an action message")
+		}
+		msg, ok := cmd().(OptionPickerActionMsg)
+		if !ok {
+			t.Fatalf("expected OptionPickerActionMsg, got %T", cmd())
+		}
+		return msg
+	}
+
+	if got := actionMsg(picker.Update(testutils.NewTextKeyPressMsg("p"))); got.Action != "p" || got.Value != "deploy.sh" {
+		t.Fatalf("pin = %+v", got)
+	}
+	if !picker.IsVisible() {
+		t.Fatal("expected the picker to stay open after an action")
+	}
+
+	picker.Update(testutils.TestKeyDown)
+	if cmd := picker.Update(testutils.NewTextKeyPressMsg("d")); cmd != nil {
+		t.Fatal("expected delete to ask first")
+	}
+	if !strings.Contains(picker.View(), "Delete? y/Enter Yes") {
+		t.Fatalf("expected the confirmation in the footer:\n%s", picker.View())
+	}
+	if cmd := picker.Update(testutils.NewTextKeyPressMsg("n")); cmd != nil {
+		t.Fatal("expected any other key to cancel the delete")
+	}
+	picker.Update(testutils.NewTextKeyPressMsg("d"))
+	if got := actionMsg(picker.Update(testutils.NewTextKeyPressMsg("y"))); got.Action != "d" || got.Value != "backup.sh" {
+		t.Fatalf("delete = %+v", got)
+	}
+
+	picker.Update(testutils.NewTextKeyPressMsg("r"))
+	picker.Update(testutils.TestKeyBackspace)
+	picker.Update(testutils.TestKeyBackspace)
+	picker.Update(testutils.NewTextKeyPressMsg("bash"))
+	if !strings.Contains(picker.View(), "Rename: BACKUP.bash") {
+		t.Fatalf("expected the edited name in the footer:\n%s", picker.View())
+	}
+	if got := actionMsg(picker.Update(testutils.TestKeyEnter)); got.Input != "BACKUP.bash" || got.Value != "backup.sh" {
+		t.Fatalf("rename = %+v", got)
+	}
+
+	picker.Update(testutils.NewTextKeyPressMsg("b"))
+	if cmd := picker.Update(testutils.NewTextKeyPressMsg("d")); cmd != nil || picker.search != "bd" {
+		t.Fatalf("expected action keys to extend a search, search = %q", picker.search)
+	}
+}
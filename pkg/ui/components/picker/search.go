@@ -0,0 +1,75 @@
+package picker
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"wtf_cli/pkg/ui/styles"
+
+	"charm.land/lipgloss/v2"
+)
+
+// pageStep returns how many rows PgUp and PgDn move: the configured page
+// size, or a screenful when none is set.
+func pageStep(pageSize, listHeight int) int {
+	if pageSize > 0 {
+		return pageSize
+	}
+	return max(listHeight, 1)
+}
+
+// trimLastRune removes the last character of s.
+func trimLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
+
+// matchIndex returns where query first occurs in label, ignoring case, or
+// -1 when it does not.
+func matchIndex(label, query string) int {
+	if query == "" {
+		return -1
+	}
+	lower := strings.ToLower(label)
+	i := strings.Index(lower, strings.ToLower(query))
+	// Lowercasing can change the byte length of some characters; only
+	// offsets that still line up with label are usable.
+	if i < 0 || len(lower) != len(label) {
+		return -1
+	}
+	return i
+}
+
+// jumpTarget returns the first option query is a prefix of, else the first
+// that contains it, else -1.
+func jumpTarget(labels []string, query string) int {
+	contains := -1
+	for i, label := range labels {
+		switch matchIndex(label, query) {
+		case 0:
+			return i
+		case -1:
+		default:
+			if contains < 0 {
+				contains = i
+			}
+		}
+	}
+	return contains
+}
+
+// highlightMatch renders label in base with the first match of query also
+// in the match style. On the selected row the match is only underlined, to
+// keep the selection's colors.
+func highlightMatch(label, query string, base lipgloss.Style, selected bool) string {
+	i := matchIndex(label, query)
+	if i < 0 {
+		return base.Render(label)
+	}
+	end := min(i+len(query), len(label))
+	match := styles.MatchStyle.Inherit(base)
+	if selected {
+		match = base.Underline(true)
+	}
+	return base.Render(label[:i]) + match.Render(label[i:end]) + base.Render(label[end:])
+}
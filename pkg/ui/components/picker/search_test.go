@@ -0,0 +1,32 @@
+package picker
+
+import (
+	"testing"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestHighlightMatch(t *testing.T) {
+	base := lipgloss.NewStyle()
+	got := highlightMatch("Portuguese", "TUG", base, false)
+	if ansi.Strip(got) != "Portuguese" {
+		t.Fatalf("Expected the label text unchanged, got %q", ansi.Strip(got))
+	}
+	if got == base.Render("Portuguese") {
+		t.Fatal("Expected the match to be styled")
+	}
+	if got := highlightMatch("English", "xyz", base, false); got != base.Render("English") {
+		t.Fatalf("Expected no styling without a match, got %q", got)
+	}
+}
+
+func TestJumpTarget(t *testing.T) {
+	labels := []string{"staging", "production", "prod-eu"}
+	cases := map[string]int{"prod": 1, "eu": 2, "ing": 0, "x": -1, "": -1}
+	for query, want := range cases {
+		if got := jumpTarget(labels, query); got != want {
+			t.Errorf("jumpTarget(%q) = %d, want %d", query, got, want)
+		}
+	}
+}
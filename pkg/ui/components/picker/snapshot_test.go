@@ -0,0 +1,18 @@
+package picker
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestOptionPickerSnapshot(t *testing.T) {
+	sizes := []testutils.Size{testutils.SizeSmall, testutils.SizeNormal}
+	testutils.RequireSnapshots(t, sizes, testutils.Profiles, func(width, height int) string {
+		p := NewOptionPickerPanel()
+		p.SetSize(width, height)
+		p.Show("AI provider for this session", "provider", []string{"openai", "openrouter", "anthropic", "copilot"}, "openrouter")
+		p.SetActions([]OptionAction{{Key: "s", Label: "Switch and save"}})
+		return p.View()
+	})
+}
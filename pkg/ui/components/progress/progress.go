@@ -0,0 +1,110 @@
+// Package progress tracks long-running background operations (model list
+// fetches, auth checks, waiting for a stream to open, large pastes) and
+// renders them as a spinner with a label.
+package progress
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Frames are the spinner frames shared by every busy indicator.
+var Frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Op is one pending operation.
+type Op struct {
+	ID      int
+	Label   string
+	Started time.Time
+
+	cancel context.CancelFunc
+}
+
+// Tracker holds the pending operations, newest last. It is not safe for
+// concurrent use; it lives on the UI model.
+type Tracker struct {
+	ops    []Op
+	nextID int
+	frame  int
+	now    func() time.Time
+}
+
+// New creates an empty tracker.
+func New() *Tracker {
+	return &Tracker{now: time.Now}
+}
+
+// Start records an operation and returns its ID. cancel, if not nil, is
+// called when the user cancels it.
+func (t *Tracker) Start(label string, cancel context.CancelFunc) int {
+	t.nextID++
+	t.ops = append(t.ops, Op{ID: t.nextID, Label: label, Started: t.now(), cancel: cancel})
+	return t.nextID
+}
+
+// SetLabel replaces the label of the operation with id, e.g. to show how far
+// it got. It reports false when the operation is no longer tracked.
+func (t *Tracker) SetLabel(id int, label string) bool {
+	for i := range t.ops {
+		if t.ops[i].ID == id {
+			t.ops[i].Label = label
+			return true
+		}
+	}
+	return false
+}
+
+// Finish removes the operation with id. It reports false when the operation
+// is no longer tracked, e.g. because it was cancelled, so its result should
+// be dropped.
+func (t *Tracker) Finish(id int) bool {
+	for i, op := range t.ops {
+		if op.ID == id {
+			t.ops = append(t.ops[:i], t.ops[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// CancelLatest cancels and removes the newest operation.
+func (t *Tracker) CancelLatest() (Op, bool) {
+	if len(t.ops) == 0 {
+		return Op{}, false
+	}
+	op := t.ops[len(t.ops)-1]
+	t.ops = t.ops[:len(t.ops)-1]
+	if op.cancel != nil {
+		op.cancel()
+	}
+	return op, true
+}
+
+// Active reports whether any operation is pending.
+func (t *Tracker) Active() bool {
+	return len(t.ops) > 0
+}
+
+// Advance moves the spinner to its next frame.
+func (t *Tracker) Advance() {
+	t.frame = (t.frame + 1) % len(Frames)
+}
+
+// View renders the newest operation, e.g. "⠋ Fetching models 3s · Esc to
+// cancel", or "" when nothing is pending. Other pending operations are
+// counted after the label.
+func (t *Tracker) View() string {
+	if len(t.ops) == 0 {
+		return ""
+	}
+	op := t.ops[len(t.ops)-1]
+	label := op.Label
+	if more := len(t.ops) - 1; more > 0 {
+		label += fmt.Sprintf(" (+%d)", more)
+	}
+	if elapsed := t.now().Sub(op.Started); elapsed >= time.Second {
+		label += fmt.Sprintf(" %ds", int(elapsed/time.Second))
+	}
+	return Frames[t.frame] + " " + label + " · Esc to cancel"
+}
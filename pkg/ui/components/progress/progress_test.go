@@ -0,0 +1,62 @@
+package progress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker_StartFinishCancel(t *testing.T) {
+	tr := New()
+	if tr.Active() || tr.View() != "" {
+		t.Fatal("expected an empty tracker to render nothing")
+	}
+
+	cancelled := false
+	first := tr.Start("Fetching models", func() { cancelled = true })
+	second := tr.Start("Checking Copilot login", nil)
+	if got := tr.View(); got != "⠋ Checking Copilot login (+1) · Esc to cancel" {
+		t.Fatalf("unexpected view %q", got)
+	}
+
+	if !tr.Finish(second) || tr.Finish(second) {
+		t.Fatal("expected Finish to report only the first completion")
+	}
+	op, ok := tr.CancelLatest()
+	if !ok || op.ID != first || !cancelled {
+		t.Fatalf("expected the fetch to be cancelled, got %+v %v", op, cancelled)
+	}
+	if tr.Finish(first) {
+		t.Fatal("expected a cancelled operation's result to be dropped")
+	}
+	if tr.Active() {
+		t.Fatal("expected nothing pending")
+	}
+}
+
+func TestTracker_ViewShowsElapsedAndFrame(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tr := New()
+	tr.now = func() time.Time { return now }
+	tr.Start("Fetching models", nil)
+
+	now = now.Add(3500 * time.Millisecond)
+	tr.Advance()
+	if got := tr.View(); got != "⠙ Fetching models 3s · Esc to cancel" {
+		t.Fatalf("unexpected view %q", got)
+	}
+}
+
+func TestTracker_SetLabel(t *testing.T) {
+	tr := New()
+	id := tr.Start("Pasting 2.0 MB", nil)
+	if !tr.SetLabel(id, "Pasting 2.0 MB 50%") {
+		t.Fatal("expected the label of a pending operation to change")
+	}
+	if got := tr.View(); got != "⠋ Pasting 2.0 MB 50% · Esc to cancel" {
+		t.Fatalf("unexpected view %q", got)
+	}
+	tr.Finish(id)
+	if tr.SetLabel(id, "gone") {
+		t.Fatal("expected SetLabel to report a finished operation")
+	}
+}
@@ -0,0 +1,276 @@
+// Package promptpreview renders the request a chat message is about to send:
+// the system prompt, the terminal context and the conversation so far, each
+// with an estimated token count. The user can edit the terminal-context
+// excerpt before sending, e.g. to cut noise or remove something private.
+//
+// The component is presentation-only: it emits SendMsg or CancelMsg and the
+// Model starts (or drops) the chat run.
+package promptpreview
+
+import (
+	"fmt"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/utils"
+	"wtf_cli/pkg/ui/styles"
+
+	"charm.land/bubbles/v2/textarea"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SendMsg is emitted when the user sends the previewed request.
+type SendMsg struct {
+	// TerminalContext is the excerpt to send in place of the one built from
+	// the buffer.
+	TerminalContext string
+	// Edited reports whether the user changed the excerpt.
+	Edited bool
+}
+
+// CancelMsg is emitted when the user closes the preview without sending.
+type CancelMsg struct{}
+
+// section is one part of the request, with its estimated token count.
+type section struct {
+	title   string
+	content string
+	tokens  int
+}
+
+// Panel is the prompt preview overlay.
+type Panel struct {
+	visible bool
+	width   int
+	height  int
+	scrollY int
+	lines   []string
+
+	preview  commands.ChatPreview
+	terminal string // Terminal context to send, possibly edited
+	edited   bool
+
+	editing bool
+	editor  textarea.Model
+}
+
+// NewPanel returns an empty, invisible panel.
+func NewPanel() *Panel {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	// The excerpt can run to hundreds of lines; SetSize sets the height.
+	ta.MaxHeight = 0
+	return &Panel{editor: ta}
+}
+
+// Show displays preview, discarding any earlier edit.
+func (p *Panel) Show(preview commands.ChatPreview) {
+	p.preview = preview
+	p.terminal = preview.TerminalContext
+	p.edited = false
+	p.editing = false
+	p.editor.Blur()
+	p.visible = true
+	p.scrollY = 0
+	p.rebuild()
+}
+
+// Hide hides the panel.
+func (p *Panel) Hide() {
+	p.visible = false
+	p.editing = false
+	p.editor.Blur()
+}
+
+// IsVisible reports whether the panel should be rendered.
+func (p *Panel) IsVisible() bool { return p.visible }
+
+// IsEditing reports whether the terminal-context editor is open.
+func (p *Panel) IsEditing() bool { return p.editing }
+
+// SetSize records the screen dimensions.
+func (p *Panel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.editor.SetWidth(p.contentWidth())
+	p.editor.SetHeight(p.visibleLines())
+	p.rebuild()
+}
+
+// TotalTokens returns the estimated size of the whole request.
+func (p *Panel) TotalTokens() int {
+	total := 0
+	for _, s := range p.sections() {
+		total += s.tokens
+	}
+	return total
+}
+
+// HandlePaste inserts pasted text into the editor while it is open.
+func (p *Panel) HandlePaste(content string) {
+	if p.editing {
+		p.editor.InsertString(content)
+	}
+}
+
+func (p *Panel) sections() []section {
+	sections := []section{
+		newSection("System prompt", p.preview.SystemPrompt),
+		newSection(p.terminalTitle(), p.terminal),
+	}
+	if p.preview.Instructions != "" {
+		sections = append(sections, newSection("Instructions", p.preview.Instructions))
+	}
+	for i, msg := range p.preview.History {
+		sections = append(sections, newSection(fmt.Sprintf("Turn %d · %s", i+1, msg.Role), msg.Content))
+	}
+	return sections
+}
+
+func newSection(title, content string) section {
+	return section{title: title, content: content, tokens: ai.EstimateTokens(content)}
+}
+
+func (p *Panel) terminalTitle() string {
+	if p.edited {
+		return "Terminal context (edited)"
+	}
+	return "Terminal context"
+}
+
+// rebuild lays the sections out as wrapped lines for scrolling.
+func (p *Panel) rebuild() {
+	width := p.contentWidth()
+	p.lines = p.lines[:0]
+	for i, s := range p.sections() {
+		if i > 0 {
+			p.lines = append(p.lines, "")
+		}
+		header := fmt.Sprintf("── %s · ~%d tokens", s.title, s.tokens)
+		p.lines = append(p.lines, styles.TitleStyle.Render(utils.TruncateToWidth(header, width)))
+		content := strings.TrimRight(s.content, "\n")
+		if strings.TrimSpace(content) == "" {
+			p.lines = append(p.lines, styles.FooterStyle.Render("(empty)"))
+			continue
+		}
+		for _, line := range strings.Split(ansi.Wrap(content, width, ""), "\n") {
+			p.lines = append(p.lines, styles.TextStyle.Render(utils.TruncateToWidth(utils.EscapeControl(line), width)))
+		}
+	}
+	p.scrollY = min(p.scrollY, p.maxScroll())
+}
+
+func (p *Panel) panelWidth() int {
+	return max(min(p.width-4, 100), 20)
+}
+
+func (p *Panel) contentWidth() int {
+	return max(p.panelWidth()-styles.BoxStyle.GetHorizontalFrameSize(), 10)
+}
+
+// visibleLines returns how many content lines fit in the panel.
+func (p *Panel) visibleLines() int {
+	// Title, blank line, footer and the box frame.
+	return max(p.height-4-3-styles.BoxStyle.GetVerticalFrameSize(), 5)
+}
+
+func (p *Panel) maxScroll() int {
+	return max(len(p.lines)-p.visibleLines(), 0)
+}
+
+// Update handles a key press. Enter sends, e edits the terminal context and
+// Esc/q cancel; while editing, Ctrl+S keeps the edit and Esc discards it.
+func (p *Panel) Update(msg tea.KeyPressMsg) tea.Cmd {
+	if !p.visible {
+		return nil
+	}
+	if p.editing {
+		return p.updateEditor(msg)
+	}
+
+	switch msg.String() {
+	case "enter":
+		p.Hide()
+		send := SendMsg{TerminalContext: p.terminal, Edited: p.edited}
+		return func() tea.Msg { return send }
+	case "esc", "q":
+		p.Hide()
+		return func() tea.Msg { return CancelMsg{} }
+	case "e":
+		p.editing = true
+		p.editor.SetValue(p.terminal)
+		p.editor.MoveToBegin()
+		return p.editor.Focus()
+	case "up", "k":
+		p.scrollY = max(p.scrollY-1, 0)
+	case "down", "j":
+		p.scrollY = min(p.scrollY+1, p.maxScroll())
+	case "pgup":
+		p.scrollY = max(p.scrollY-p.visibleLines(), 0)
+	case "pgdown", "space":
+		p.scrollY = min(p.scrollY+p.visibleLines(), p.maxScroll())
+	case "home", "g":
+		p.scrollY = 0
+	case "end", "G":
+		p.scrollY = p.maxScroll()
+	}
+	return nil
+}
+
+func (p *Panel) updateEditor(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+s":
+		if value := p.editor.Value(); value != p.terminal {
+			p.terminal = value
+			p.edited = p.terminal != p.preview.TerminalContext
+		}
+		p.closeEditor()
+		return nil
+	case "esc":
+		p.closeEditor()
+		return nil
+	}
+	var cmd tea.Cmd
+	p.editor, cmd = p.editor.Update(msg)
+	return cmd
+}
+
+func (p *Panel) closeEditor() {
+	p.editing = false
+	p.editor.Blur()
+	p.rebuild()
+}
+
+// View renders the panel.
+func (p *Panel) View() string {
+	if !p.visible {
+		return ""
+	}
+	width := p.contentWidth()
+
+	title := fmt.Sprintf("Prompt preview · %s · ~%d tokens", p.preview.Model, p.TotalTokens())
+	var sb strings.Builder
+	sb.WriteString(styles.TitleStyle.Render(utils.TruncateToWidth(title, width)))
+	sb.WriteString("\n\n")
+
+	if p.editing {
+		sb.WriteString(p.editor.View())
+		sb.WriteString("\n")
+		sb.WriteString(styles.FooterStyle.Render(utils.TruncateToWidth("Editing terminal context • Ctrl+S Keep • Esc Discard", width)))
+		return styles.BoxStyle.Width(p.panelWidth()).Render(sb.String())
+	}
+
+	end := min(p.scrollY+p.visibleLines(), len(p.lines))
+	for i := p.scrollY; i < end; i++ {
+		sb.WriteString(p.lines[i])
+		sb.WriteString("\n")
+	}
+	footer := "Enter Send • e Edit terminal context • Esc Cancel"
+	if len(p.lines) > p.visibleLines() {
+		footer = "↑↓ Scroll • " + footer
+	}
+	sb.WriteString(styles.FooterStyle.Render(utils.TruncateToWidth(footer, width)))
+	return styles.BoxStyle.Width(p.panelWidth()).Render(sb.String())
+}
@@ -0,0 +1,144 @@
+package promptpreview
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func testPreview() commands.ChatPreview {
+	return commands.ChatPreview{
+		Model:           "test-model",
+		SystemPrompt:    "You are a terminal assistant.",
+		TerminalContext: "$ make\nmake: *** [build] Error 1",
+		Instructions:    "Always write your answer in German.",
+		History:         []ai.Message{{Role: "user", Content: "why did make fail?"}},
+	}
+}
+
+func press(p *Panel, code rune, text string) tea.Cmd {
+	return p.Update(tea.KeyPressMsg(tea.Key{Code: code, Text: text}))
+}
+
+func TestPanel_ShowsSectionsWithTokens(t *testing.T) {
+	p := NewPanel()
+	p.SetSize(100, 40)
+	p.Show(testPreview())
+
+	view := ansi.Strip(p.View())
+	for _, want := range []string{
+		"Prompt preview · test-model",
+		"System prompt · ~8 tokens",
+		"Terminal context · ~",
+		"Instructions · ~",
+		"Turn 1 · user · ~5 tokens",
+		"make: *** [build] Error 1",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	want := 0
+	for _, text := range []string{"You are a terminal assistant.", "$ make\nmake: *** [build] Error 1", "Always write your answer in German.", "why did make fail?"} {
+		want += ai.EstimateTokens(text)
+	}
+	if got := p.TotalTokens(); got != want {
+		t.Fatalf("TotalTokens = %d, want %d", got, want)
+	}
+}
+
+func TestPanel_SendUnedited(t *testing.T) {
+	p := NewPanel()
+	p.SetSize(100, 40)
+	p.Show(testPreview())
+
+	cmd := press(p, tea.KeyEnter, "")
+	if cmd == nil {
+		t.Fatal("expected Enter to send")
+	}
+	send, ok := cmd().(SendMsg)
+	if !ok {
+		t.Fatalf("expected SendMsg, got %T", cmd())
+	}
+	if send.Edited || send.TerminalContext != testPreview().TerminalContext {
+		t.Fatalf("unexpected send %+v", send)
+	}
+	if p.IsVisible() {
+		t.Fatal("expected the panel to close")
+	}
+}
+
+func TestPanel_EditTerminalContext(t *testing.T) {
+	p := NewPanel()
+	p.SetSize(100, 40)
+	p.Show(testPreview())
+
+	press(p, 'e', "e")
+	if !p.IsEditing() {
+		t.Fatal("expected e to open the editor")
+	}
+	// Enter inserts a newline while editing instead of sending.
+	if cmd := press(p, tea.KeyEnter, ""); cmd != nil {
+		if _, ok := cmd().(SendMsg); ok {
+			t.Fatal("expected Enter to edit, not send")
+		}
+	}
+	p.editor.SetValue("make: *** [build] Error 1")
+	p.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	if p.IsEditing() {
+		t.Fatal("expected Ctrl+S to close the editor")
+	}
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "Terminal context (edited)") {
+		t.Fatalf("expected the edit to be marked:\n%s", view)
+	}
+
+	send, _ := press(p, tea.KeyEnter, "")().(SendMsg)
+	if !send.Edited || send.TerminalContext != "make: *** [build] Error 1" {
+		t.Fatalf("unexpected send %+v", send)
+	}
+}
+
+func TestPanel_EscDiscardsEditThenCancels(t *testing.T) {
+	p := NewPanel()
+	p.SetSize(100, 40)
+	p.Show(testPreview())
+
+	press(p, 'e', "e")
+	p.editor.SetValue("")
+	press(p, tea.KeyEscape, "")
+	if p.IsEditing() || !p.IsVisible() {
+		t.Fatal("expected Esc to close only the editor")
+	}
+
+	cmd := press(p, tea.KeyEscape, "")
+	if _, ok := cmd().(CancelMsg); !ok {
+		t.Fatalf("expected CancelMsg, got %T", cmd())
+	}
+	p.Show(testPreview())
+	send, _ := press(p, tea.KeyEnter, "")().(SendMsg)
+	if send.Edited || send.TerminalContext != testPreview().TerminalContext {
+		t.Fatalf("expected the discarded edit to be dropped, got %+v", send)
+	}
+}
+
+func TestPanel_Scrolls(t *testing.T) {
+	preview := testPreview()
+	preview.TerminalContext = strings.Repeat("line\n", 100)
+	p := NewPanel()
+	p.SetSize(80, 24)
+	p.Show(preview)
+
+	press(p, tea.KeyEnd, "")
+	if p.scrollY != p.maxScroll() || p.scrollY == 0 {
+		t.Fatalf("expected End to scroll to the bottom, got %d of %d", p.scrollY, p.maxScroll())
+	}
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "why did make fail?") {
+		t.Fatalf("expected the last turn at the bottom:\n%s", view)
+	}
+}
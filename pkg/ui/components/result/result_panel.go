@@ -63,15 +63,51 @@ func (rp *ResultPanel) SetSize(width, height int) {
 	rp.height = height
 }
 
+// Title returns the title of the shown content.
+func (rp *ResultPanel) Title() string {
+	return rp.title
+}
+
+// visibleLines returns how many content lines fit in the panel.
+func (rp *ResultPanel) visibleLines() int {
+	panelHeight := rp.height - 4
+	if panelHeight > 30 {
+		panelHeight = 30
+	}
+	visible := panelHeight - 8 // Account for title, footer, borders
+	if visible < 5 {
+		visible = 5
+	}
+	return visible
+}
+
+func (rp *ResultPanel) maxScroll() int {
+	return max(len(rp.lines)-rp.visibleLines(), 0)
+}
+
+// ScrollToBottom shows the last lines, like a pager following a log.
+func (rp *ResultPanel) ScrollToBottom() {
+	rp.scrollY = rp.maxScroll()
+}
+
+// AtBottom reports whether the last line is visible.
+func (rp *ResultPanel) AtBottom() bool {
+	return rp.scrollY >= rp.maxScroll()
+}
+
 // ResultPanelCloseMsg is sent when the result panel is closed
 type ResultPanelCloseMsg struct{}
 
+// ResultPanelChatMsg is sent when the user turns the shown result into a
+// chat conversation to ask follow-ups.
+type ResultPanelChatMsg struct {
+	Title   string
+	Content string
+}
+
 // Update handles keyboard input for the result panel
 func (rp *ResultPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
-	maxScroll := len(rp.lines) - (rp.height - 6) // Account for box borders
-	if maxScroll < 0 {
-		maxScroll = 0
-	}
+	maxScroll := rp.maxScroll()
 
 	keyStr := msg.String()
 	switch keyStr {
@@ -107,6 +143,24 @@ func (rp *ResultPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 			rp.scrollY = maxScroll
 		}
 		return nil
+
+	case "home", "g":
+		rp.scrollY = 0
+		return nil
+
+	case "c":
+		if strings.TrimSpace(rp.content) == "" {
+			return nil
+		}
+		rp.Hide()
+		title, content := rp.title, rp.content
+		return func() tea.Msg {
+			return ResultPanelChatMsg{Title: title, Content: content}
+		}
+
+	case "end", "G":
+		rp.scrollY = maxScroll
+		return nil
 	}
 
 	// 'q' also closes
@@ -155,10 +209,7 @@ func (rp *ResultPanel) View() string {
 	sb.WriteString("\n\n")
 
 	// Content with scrolling
-	visibleLines := panelHeight - 8 // Account for title, footer, borders
-	if visibleLines < 5 {
-		visibleLines = 5
-	}
+	visibleLines := rp.visibleLines()
 
 	endLine := rp.scrollY + visibleLines
 	if endLine > len(rp.lines) {
@@ -176,7 +227,7 @@ func (rp *ResultPanel) View() string {
 		sb.WriteString(footerStyle.Render("↑↓ Scroll • "))
 	}
 
-	sb.WriteString(footerStyle.Render("Esc/q Close"))
+	sb.WriteString(footerStyle.Render("c Chat • Esc/q Close"))
 
 	// Render box
 	box := boxStyle.Render(sb.String())
@@ -0,0 +1,370 @@
+// Package scrubber replays the commands of a session on a timeline: the
+// position can be moved from command to command or played back at the
+// speed the output originally arrived, and the state at the position can be
+// handed to the AI.
+package scrubber
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ui/components/utils"
+	"wtf_cli/pkg/ui/styles"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+const (
+	// tickInterval is how often playback advances.
+	tickInterval = 50 * time.Millisecond
+	// maxIdle caps the pause between commands during playback, so time
+	// spent away from the terminal is not replayed.
+	maxIdle = 2 * time.Second
+)
+
+// Speeds are the playback speeds + and - step through.
+var Speeds = []int{1, 2, 4, 8, 16}
+
+// Step is one command of the replayed session.
+type Step struct {
+	Command string
+	Dir     string
+	// ExitCode is -1 when the shell did not report it.
+	ExitCode int
+	Start    time.Time
+	End      time.Time
+	// Output is the command's output, one plain-text line each.
+	Output []string
+}
+
+func (s Step) duration() time.Duration {
+	if s.End.Before(s.Start) {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// TickMsg advances playback.
+type TickMsg struct {
+	seq int
+}
+
+// AskMsg asks the AI about the session as it was at the position: Steps
+// ends with the command at the position, its output cut where the
+// position is.
+type AskMsg struct {
+	Steps []Step
+}
+
+// Panel shows the replay and its timeline.
+type Panel struct {
+	visible bool
+	width   int
+	height  int
+	steps   []Step
+
+	// The position: step cur with shown of its output lines revealed.
+	cur   int
+	shown int
+
+	playing bool
+	speed   int           // Index into Speeds
+	elapsed time.Duration // Playback time into step cur
+	seq     int           // Identifies the running tick chain
+}
+
+// New creates a hidden scrubber.
+func New() *Panel {
+	return &Panel{}
+}
+
+// Show opens the scrubber on steps, positioned at the end of the last one:
+// the session as it is now.
+func (p *Panel) Show(steps []Step) {
+	p.visible = true
+	p.steps = steps
+	p.playing = false
+	p.seq++
+	p.cur = max(len(steps)-1, 0)
+	p.shown = p.outputLen(p.cur)
+}
+
+// Hide closes the scrubber and stops playback.
+func (p *Panel) Hide() {
+	p.visible = false
+	p.playing = false
+	p.seq++
+}
+
+// IsVisible reports whether the scrubber is open.
+func (p *Panel) IsVisible() bool { return p.visible }
+
+// SetSize sets the screen size the scrubber is drawn in.
+func (p *Panel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// Position returns the step at the position and how many of its output
+// lines are revealed.
+func (p *Panel) Position() (step, shown int) { return p.cur, p.shown }
+
+// Playing reports whether playback is running.
+func (p *Panel) Playing() bool { return p.playing }
+
+// Speed returns the playback speed multiplier.
+func (p *Panel) Speed() int { return Speeds[p.speed] }
+
+func (p *Panel) outputLen(i int) int {
+	if i < 0 || i >= len(p.steps) {
+		return 0
+	}
+	return len(p.steps[i].Output)
+}
+
+func (p *Panel) atEnd() bool {
+	return len(p.steps) == 0 || (p.cur == len(p.steps)-1 && p.shown >= p.outputLen(p.cur))
+}
+
+// jump moves to the end of step i.
+func (p *Panel) jump(i int) {
+	if len(p.steps) == 0 {
+		return
+	}
+	p.cur = min(max(i, 0), len(p.steps)-1)
+	p.shown = p.outputLen(p.cur)
+	p.elapsed = p.steps[p.cur].duration()
+}
+
+// Update handles a key while the scrubber is on top.
+func (p *Panel) Update(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "q":
+		p.Hide()
+		return nil
+	case "left", "h":
+		p.playing = false
+		p.jump(p.cur - 1)
+	case "right", "l":
+		p.playing = false
+		p.jump(p.cur + 1)
+	case "home", "g":
+		p.playing = false
+		p.jump(0)
+	case "end", "G":
+		p.playing = false
+		p.jump(len(p.steps) - 1)
+	case "+", "=":
+		p.speed = min(p.speed+1, len(Speeds)-1)
+	case "-":
+		p.speed = max(p.speed-1, 0)
+	case "space", " ":
+		return p.togglePlay()
+	case "a":
+		p.playing = false
+		steps := p.stepsToPosition()
+		if len(steps) == 0 {
+			return nil
+		}
+		return func() tea.Msg { return AskMsg{Steps: steps} }
+	}
+	return nil
+}
+
+func (p *Panel) togglePlay() tea.Cmd {
+	if p.playing {
+		p.playing = false
+		return nil
+	}
+	if len(p.steps) == 0 {
+		return nil
+	}
+	if p.atEnd() {
+		// Replay from the first command.
+		p.cur, p.shown, p.elapsed = 0, 0, 0
+	}
+	p.playing = true
+	p.seq++
+	return p.tick()
+}
+
+func (p *Panel) tick() tea.Cmd {
+	seq := p.seq
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg { return TickMsg{seq: seq} })
+}
+
+// Tick advances playback by one interval at the current speed.
+func (p *Panel) Tick(msg TickMsg) tea.Cmd {
+	if !p.visible || !p.playing || msg.seq != p.seq {
+		return nil
+	}
+	p.advance(tickInterval * time.Duration(Speeds[p.speed]))
+	if p.atEnd() {
+		p.playing = false
+		return nil
+	}
+	return p.tick()
+}
+
+// advance moves the position d of playback time forward. A command's
+// output is revealed evenly over the time it ran, then the pause before
+// the next command (at most maxIdle) passes.
+func (p *Panel) advance(d time.Duration) {
+	p.elapsed += d
+	for {
+		step := p.steps[p.cur]
+		run := step.duration()
+		n := len(step.Output)
+		if run <= 0 || p.elapsed >= run {
+			p.shown = n
+		} else {
+			p.shown = min(int(float64(n)*float64(p.elapsed)/float64(run))+1, n)
+		}
+		if p.cur == len(p.steps)-1 {
+			return
+		}
+		wait := run + min(max(p.steps[p.cur+1].Start.Sub(step.End), 0), maxIdle)
+		if p.elapsed < wait {
+			return
+		}
+		p.elapsed -= wait
+		p.cur++
+		p.shown = 0
+	}
+}
+
+// stepsToPosition returns the steps up to the position, the last one cut
+// at the revealed output.
+func (p *Panel) stepsToPosition() []Step {
+	if len(p.steps) == 0 {
+		return nil
+	}
+	steps := append([]Step(nil), p.steps[:p.cur+1]...)
+	last := &steps[len(steps)-1]
+	if p.shown < len(last.Output) {
+		last.Output = last.Output[:p.shown]
+		// The command was still running at this point.
+		last.ExitCode = -1
+		last.End = time.Time{}
+	}
+	return steps
+}
+
+// View renders the scrubber.
+func (p *Panel) View() string {
+	if !p.visible {
+		return ""
+	}
+	panelWidth := max(p.width-4, 20)
+	panelHeight := max(p.height-4, 8)
+	contentWidth := max(panelWidth-6, 1)
+	// Title, blank, output, blank, timeline, blank, footer.
+	outputHeight := max(panelHeight-2-6, 1)
+
+	var sb strings.Builder
+	sb.WriteString(styles.TitleStyle.Render(utils.TruncateToWidth(p.title(), contentWidth)))
+	sb.WriteString("\n\n")
+	lines := p.screenLines()
+	if len(lines) > outputHeight {
+		lines = lines[len(lines)-outputHeight:]
+	}
+	for i := range outputHeight {
+		if i < len(lines) {
+			sb.WriteString(lines[i].render(contentWidth))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(p.timeline(contentWidth))
+	sb.WriteString("\n\n")
+	play := "Space play"
+	if p.playing {
+		play = "Space pause"
+	}
+	footer := fmt.Sprintf("←/→ Command • %s • +/- Speed (%d×) • a Ask AI • Esc Close", play, p.Speed())
+	sb.WriteString(styles.FooterStyle.Render(utils.TruncateToWidth(footer, contentWidth)))
+	return styles.BoxStyle.Width(panelWidth).Render(sb.String())
+}
+
+func (p *Panel) title() string {
+	if len(p.steps) == 0 {
+		return "Replay: no commands recorded this session"
+	}
+	step := p.steps[p.cur]
+	title := fmt.Sprintf("Replay %d/%d · %s · $ %s", p.cur+1, len(p.steps), step.Start.Format("15:04:05"), step.Command)
+	switch {
+	case p.shown < len(step.Output):
+		title += " · running"
+	case step.ExitCode > 0:
+		title += fmt.Sprintf(" · exit %d", step.ExitCode)
+	}
+	return title
+}
+
+type screenLine struct {
+	text    string
+	command bool
+}
+
+func (l screenLine) render(width int) string {
+	text := utils.TruncateToWidth(l.text, width)
+	if l.command {
+		return styles.TitleStyle.Render(text)
+	}
+	return styles.TextStyle.Render(text)
+}
+
+// screenLines is the terminal as it looked at the position: each command
+// followed by its output so far.
+func (p *Panel) screenLines() []screenLine {
+	var lines []screenLine
+	for i := 0; i <= p.cur && i < len(p.steps); i++ {
+		step := p.steps[i]
+		lines = append(lines, screenLine{text: "$ " + step.Command, command: true})
+		out := step.Output
+		if i == p.cur {
+			out = out[:min(p.shown, len(out))]
+		}
+		for _, l := range out {
+			lines = append(lines, screenLine{text: l})
+		}
+	}
+	return lines
+}
+
+// timeline draws the command markers, failed commands in red, and the
+// position between them.
+func (p *Panel) timeline(width int) string {
+	n := len(p.steps)
+	if n == 0 || width < 3 {
+		return ""
+	}
+	cells := make([]string, width)
+	for i := range cells {
+		cells[i] = styles.TextMutedStyle.Render("─")
+	}
+	markerX := func(i int) int {
+		if n == 1 {
+			return 0
+		}
+		return i * (width - 1) / (n - 1)
+	}
+	for i, step := range p.steps {
+		style := styles.TextMutedStyle
+		if step.ExitCode > 0 {
+			style = styles.ErrorStyle
+		}
+		cells[markerX(i)] = style.Render("┼")
+	}
+	// The position sits on its command's marker once the output is all
+	// there, and before it while the output is coming in.
+	pos := markerX(p.cur)
+	if total := p.outputLen(p.cur); p.shown < total && p.cur > 0 {
+		prev := markerX(p.cur - 1)
+		pos = prev + (pos-prev)*p.shown/total
+	}
+	cells[pos] = lipgloss.NewStyle().Foreground(styles.ColorAccent).Bold(true).Render("●")
+	return strings.Join(cells, "")
+}
@@ -0,0 +1,120 @@
+package scrubber
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var t0 = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func testSteps() []Step {
+	return []Step{
+		{Command: "ls", ExitCode: 0, Start: t0, End: t0, Output: []string{"a.txt"}},
+		{Command: "make", ExitCode: 2, Start: t0.Add(time.Minute), End: t0.Add(time.Minute + 4*time.Second),
+			Output: []string{"cc main.c", "main.c:3: error", "make: *** Error 2", "done"}},
+		{Command: "git status", ExitCode: 0, Start: t0.Add(2 * time.Minute), End: t0.Add(2 * time.Minute), Output: []string{"clean"}},
+	}
+}
+
+func key(s string) tea.KeyPressMsg {
+	switch s {
+	case "left":
+		return tea.KeyPressMsg{Code: tea.KeyLeft}
+	case "right":
+		return tea.KeyPressMsg{Code: tea.KeyRight}
+	case "space":
+		return tea.KeyPressMsg{Code: tea.KeySpace, Text: " "}
+	}
+	return tea.KeyPressMsg{Code: rune(s[0]), Text: s}
+}
+
+func TestPanel_JumpsBetweenCommands(t *testing.T) {
+	p := New()
+	p.SetSize(100, 30)
+	p.Show(testSteps())
+	if step, shown := p.Position(); step != 2 || shown != 1 {
+		t.Fatalf("Show() position = %d/%d, want the end of the session", step, shown)
+	}
+	p.Update(key("left"))
+	p.Update(key("left"))
+	p.Update(key("left"))
+	if step, _ := p.Position(); step != 0 {
+		t.Fatalf("position = %d after Left at the start", step)
+	}
+	p.Update(key("right"))
+	if step, shown := p.Position(); step != 1 || shown != 4 {
+		t.Fatalf("Right position = %d/%d, want the end of make", step, shown)
+	}
+
+	view := ansi.Strip(p.View())
+	for _, want := range []string{"Replay 2/3", "$ make", "exit 2", "make: *** Error 2", "●"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in view:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "clean") {
+		t.Fatalf("expected nothing after the position:\n%s", view)
+	}
+}
+
+func TestPanel_PlaysAtRecordedSpeed(t *testing.T) {
+	p := New()
+	p.Show(testSteps())
+	if cmd := p.Update(key("space")); cmd == nil || !p.Playing() {
+		t.Fatal("expected Space to start playback")
+	}
+	if step, shown := p.Position(); step != 0 || shown != 0 {
+		t.Fatalf("expected playback from the start, at %d/%d", step, shown)
+	}
+
+	// ls is instant, then the pause before make is capped at maxIdle.
+	p.advance(maxIdle)
+	if step, shown := p.Position(); step != 1 || shown != 1 {
+		t.Fatalf("after the idle cap position = %d/%d, want make's first line", step, shown)
+	}
+	// make printed its 4 lines over 4 seconds.
+	p.advance(2 * time.Second)
+	if step, shown := p.Position(); step != 1 || shown != 3 {
+		t.Fatalf("halfway through make position = %d/%d", step, shown)
+	}
+
+	p.Update(key("+"))
+	p.Update(key("+"))
+	if p.Speed() != 4 {
+		t.Fatalf("Speed() = %d after two +", p.Speed())
+	}
+	p.Update(key("space"))
+	if p.Playing() {
+		t.Fatal("expected Space to pause")
+	}
+	if cmd := p.Tick(TickMsg{seq: p.seq}); cmd != nil {
+		t.Fatal("expected no tick while paused")
+	}
+}
+
+func TestPanel_AskSendsOnlyTheStateAtThePosition(t *testing.T) {
+	p := New()
+	p.Show(testSteps())
+	p.Update(key("left"))
+	p.shown = 2 // Halfway through make's output.
+
+	cmd := p.Update(key("a"))
+	if cmd == nil {
+		t.Fatal("expected a to ask")
+	}
+	ask, ok := cmd().(AskMsg)
+	if !ok || len(ask.Steps) != 2 {
+		t.Fatalf("ask = %#v", cmd())
+	}
+	last := ask.Steps[1]
+	if len(last.Output) != 2 || last.ExitCode != -1 || !last.End.IsZero() {
+		t.Fatalf("expected make cut at the position and still running, got %+v", last)
+	}
+	if len(p.steps[1].Output) != 4 {
+		t.Fatal("expected the replay itself to keep the full output")
+	}
+}
@@ -0,0 +1,120 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/styles"
+
+	"charm.land/lipgloss/v2"
+)
+
+// useSuggestedMaxTokensKey replaces an over-limit Max Tokens value with the
+// suggested one.
+const useSuggestedMaxTokensKey = "a"
+
+var maxTokensWarningStyle = lipgloss.NewStyle().Foreground(styles.ColorWarning)
+
+func isMaxTokensKey(key string) bool {
+	switch key {
+	case "max_tokens", "openai_max_tokens", "copilot_max_tokens", "anthropic_max_tokens", "google_max_tokens":
+		return true
+	}
+	return false
+}
+
+// maxTokensLimit returns the model paired with a Max Tokens field and the
+// largest max_tokens it accepts (0 if unknown).
+func (sp *SettingsPanel) maxTokensLimit(key string) (string, int) {
+	var model string
+	var models []ai.ModelInfo
+	switch key {
+	case "max_tokens":
+		// Only consult the OpenRouter cache once something else has loaded
+		// it; see modelCache.
+		model, models = sp.config.OpenRouter.Model, sp.modelCache.Models
+	case "openai_max_tokens":
+		model = sp.getOpenAIModel()
+	case "copilot_max_tokens":
+		model = sp.getCopilotModel()
+	case "anthropic_max_tokens":
+		model = sp.getAnthropicModel()
+	case "google_max_tokens":
+		model = sp.getGoogleModel()
+	default:
+		return "", 0
+	}
+
+	contextLength := 0
+	for _, info := range models {
+		if info.ID == model {
+			contextLength = info.ContextLength
+			break
+		}
+	}
+	return model, ai.MaxOutputTokens(model, contextLength)
+}
+
+// maxTokensOverLimit reports whether field holds a max_tokens larger than its
+// model accepts, returning the model, its limit and a value to use instead.
+func (sp *SettingsPanel) maxTokensOverLimit(field SettingField) (model string, limit, suggested int, over bool) {
+	if !isMaxTokensKey(field.Key) {
+		return "", 0, 0, false
+	}
+	value, err := strconv.Atoi(field.Value)
+	if err != nil {
+		return "", 0, 0, false
+	}
+	model, limit = sp.maxTokensLimit(field.Key)
+	if limit <= 0 || value <= limit {
+		return "", 0, 0, false
+	}
+	return model, limit, ai.SuggestedMaxTokens(limit), true
+}
+
+// maxTokensWarning is the inline note shown after an over-limit value.
+func (sp *SettingsPanel) maxTokensWarning(field SettingField) string {
+	model, limit, _, over := sp.maxTokensOverLimit(field)
+	if !over {
+		return ""
+	}
+	return maxTokensWarningStyle.Render(fmt.Sprintf("⚠ %s allows %d", model, limit))
+}
+
+// clampMaxTokens lowers an edited max_tokens to the model's limit so the
+// provider does not reject the request.
+func (sp *SettingsPanel) clampMaxTokens(field *SettingField) {
+	model, limit, _, over := sp.maxTokensOverLimit(*field)
+	if !over {
+		return
+	}
+	field.Value = strconv.Itoa(limit)
+	sp.errorMsg = fmt.Sprintf("%s clamped to %d, the most %s allows", field.Label, limit, model)
+}
+
+// selectedMaxTokensSuggestion returns the value to offer when the selected
+// field is an over-limit Max Tokens.
+func (sp *SettingsPanel) selectedMaxTokensSuggestion() (int, bool) {
+	if sp.selected < 0 || sp.selected >= len(sp.fields) {
+		return 0, false
+	}
+	_, _, suggested, over := sp.maxTokensOverLimit(sp.fields[sp.selected])
+	return suggested, over
+}
+
+// useSuggestedMaxTokens applies the suggested value to the selected field.
+func (sp *SettingsPanel) useSuggestedMaxTokens() {
+	if sp.selected < 0 || sp.selected >= len(sp.fields) {
+		return
+	}
+	field := &sp.fields[sp.selected]
+	_, _, suggested, over := sp.maxTokensOverLimit(*field)
+	if !over {
+		return
+	}
+	field.Value = strconv.Itoa(suggested)
+	sp.changed = true
+	sp.errorMsg = ""
+	sp.applyField(field)
+}
@@ -0,0 +1,90 @@
+package settings
+
+import (
+	"wtf_cli/pkg/ui/styles"
+
+	"charm.land/lipgloss/v2"
+)
+
+// managedSuffix marks a setting the organization policy locks.
+const managedSuffix = "(managed by your organization)"
+
+var managedStyle = lipgloss.NewStyle().Foreground(styles.ColorWarning)
+
+// fieldConfigPaths maps settings fields to their dotted config path, as
+// used by the policy file and environment overrides.
+var fieldConfigPaths = map[string]string{
+	"llm_provider":          "llm_provider",
+	"api_key":               "openrouter.api_key",
+	"api_url":               "openrouter.api_url",
+	"model":                 "openrouter.model",
+	"temperature":           "openrouter.temperature",
+	"max_tokens":            "openrouter.max_tokens",
+	"api_timeout":           "openrouter.api_timeout_seconds",
+	"openai_api_key":        "providers.openai.api_key",
+	"openai_model":          "providers.openai.model",
+	"openai_temperature":    "providers.openai.temperature",
+	"openai_max_tokens":     "providers.openai.max_tokens",
+	"copilot_model":         "providers.copilot.model",
+	"copilot_temperature":   "providers.copilot.temperature",
+	"copilot_max_tokens":    "providers.copilot.max_tokens",
+	"anthropic_api_key":     "providers.anthropic.api_key",
+	"anthropic_model":       "providers.anthropic.model",
+	"anthropic_temperature": "providers.anthropic.temperature",
+	"anthropic_max_tokens":  "providers.anthropic.max_tokens",
+	"google_api_key":        "providers.google.api_key",
+	"google_model":          "providers.google.model",
+	"google_temperature":    "providers.google.temperature",
+	"google_max_tokens":     "providers.google.max_tokens",
+	"buffer_size":           "buffer_size",
+	"context_window":        "context_window",
+	"response_language":     "response_language",
+	"log_level":             "log_level",
+	"log_format":            "log_format",
+	"log_file":              "log_file",
+}
+
+// isManaged reports whether the organization policy locks the field.
+func (sp *SettingsPanel) isManaged(key string) bool {
+	path, ok := fieldConfigPaths[key]
+	return ok && sp.config.Policy.Locks(path)
+}
+
+// envVar returns the WTF_ environment variable that sets the field, or "".
+// Edits to such a field would not last, as Save keeps the file's value.
+func (sp *SettingsPanel) envVar(key string) string {
+	path, ok := fieldConfigPaths[key]
+	if !ok {
+		return ""
+	}
+	return sp.config.Env.Var(path)
+}
+
+// lockedSuffix returns the note shown after a locked field's value, or "".
+func (sp *SettingsPanel) lockedSuffix(key string) string {
+	if sp.isManaged(key) {
+		return managedSuffix
+	}
+	if name := sp.envVar(key); name != "" {
+		return "(set by " + name + ")"
+	}
+	return ""
+}
+
+// rejectManaged reports whether the selected field is locked by the policy
+// or the environment, telling the user why it cannot be changed.
+func (sp *SettingsPanel) rejectManaged() bool {
+	if sp.selected >= len(sp.fields) {
+		return false
+	}
+	field := sp.fields[sp.selected]
+	switch {
+	case sp.isManaged(field.Key):
+		sp.errorMsg = field.Label + " is managed by your organization"
+	case sp.envVar(field.Key) != "":
+		sp.errorMsg = field.Label + " is set by " + sp.envVar(field.Key) + "; unset it to change the setting here"
+	default:
+		return false
+	}
+	return true
+}
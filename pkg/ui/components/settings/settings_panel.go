@@ -24,6 +24,16 @@ type SettingField struct {
 	Masked bool   // For sensitive fields like API key
 }
 
+// modelFieldProviders maps each Model field to the provider whose models
+// its picker lists.
+var modelFieldProviders = map[string]ai.ProviderType{
+	"model":           ai.ProviderOpenRouter,
+	"openai_model":    ai.ProviderOpenAI,
+	"copilot_model":   ai.ProviderCopilot,
+	"anthropic_model": ai.ProviderAnthropic,
+	"google_model":    ai.ProviderGoogle,
+}
+
 // SettingsPanel displays and edits configuration
 type SettingsPanel struct {
 	config     config.Config
@@ -39,7 +49,11 @@ type SettingsPanel struct {
 	visible    bool
 	errorMsg   string
 
-	modelCache ai.ModelCache
+	// modelCache is read from disk the first time the OpenRouter model
+	// picker opens rather than on every Show, keeping startup and panel
+	// open free of file I/O on slow home directories.
+	modelCache       ai.ModelCache
+	modelCacheLoaded bool
 
 	copilotAuthMessage string
 	copilotAuthOpen    bool
@@ -61,7 +75,6 @@ func (sp *SettingsPanel) Show(cfg config.Config, configPath string) {
 	sp.editing = false
 	sp.changed = false
 	sp.errorMsg = ""
-	sp.loadModelCache()
 	sp.resetCopilotAuthStatus()
 	sp.buildFields()
 }
@@ -118,6 +131,7 @@ func (sp *SettingsPanel) buildFields() {
 	sp.fields = append(sp.fields,
 		SettingField{Label: "Buffer Size", Key: "buffer_size", Value: fmt.Sprintf("%d", sp.config.BufferSize), Type: "int"},
 		SettingField{Label: "Context Window", Key: "context_window", Value: fmt.Sprintf("%d", sp.config.ContextWindow), Type: "int"},
+		SettingField{Label: "Response Language", Key: "response_language", Value: responseLanguageDisplay(sp.config.ResponseLanguage), Type: "string"},
 		SettingField{Label: "Log Level", Key: "log_level", Value: normalizeLogLevel(sp.config.LogLevel), Type: "string"},
 		SettingField{Label: "Log Format", Key: "log_format", Value: strings.ToLower(strings.TrimSpace(sp.config.LogFormat)), Type: "string"},
 		SettingField{Label: "Log File", Key: "log_file", Value: sp.config.LogFile, Type: "string"},
@@ -292,6 +306,9 @@ func (sp *SettingsPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 
 	case "enter":
 		// Enter edit mode for current field
+		if sp.rejectManaged() {
+			return nil
+		}
 		field := &sp.fields[sp.selected]
 		if field.Type == "info" {
 			// Handle special info fields like copilot auth
@@ -303,7 +320,7 @@ func (sp *SettingsPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 			return nil
 		}
 		if field.Key == "llm_provider" {
-			options := config.SupportedProviders()
+			options := sp.config.Policy.Providers()
 			return func() tea.Msg {
 				return picker.OpenOptionPickerMsg{
 					Title:    "LLM Provider",
@@ -313,61 +330,18 @@ func (sp *SettingsPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 				}
 			}
 		}
-		if field.Key == "model" {
-			options := make([]ai.ModelInfo, len(sp.modelCache.Models))
-			copy(options, sp.modelCache.Models)
-			return func() tea.Msg {
-				return picker.OpenModelPickerMsg{
-					Options:  options,
-					Current:  sp.config.OpenRouter.Model,
-					APIURL:   sp.config.OpenRouter.APIURL,
-					FieldKey: "model",
-				}
-			}
-		}
-		if field.Key == "openai_model" {
-			options := ai.GetProviderModels("openai")
-			apiKey := sp.config.Providers.OpenAI.APIKey
-			return func() tea.Msg {
-				return picker.OpenModelPickerMsg{
-					Options:  options,
-					Current:  sp.config.Providers.OpenAI.Model,
-					FieldKey: "openai_model",
-					APIKey:   apiKey,
-				}
-			}
-		}
-		if field.Key == "copilot_model" {
-			options := ai.GetCopilotModels()
-			return func() tea.Msg {
-				return picker.OpenModelPickerMsg{
-					Options:  options,
-					Current:  sp.config.Providers.Copilot.Model,
-					FieldKey: "copilot_model",
-				}
-			}
-		}
-		if field.Key == "anthropic_model" {
-			options := ai.GetProviderModels("anthropic")
-			apiKey := sp.config.Providers.Anthropic.APIKey
-			return func() tea.Msg {
-				return picker.OpenModelPickerMsg{
-					Options:  options,
-					Current:  sp.config.Providers.Anthropic.Model,
-					FieldKey: "anthropic_model",
-					APIKey:   apiKey,
-				}
+		if provider, ok := modelFieldProviders[field.Key]; ok {
+			if provider == ai.ProviderOpenRouter {
+				sp.ensureModelCache() // Max Tokens reads context lengths from it
 			}
-		}
-		if field.Key == "google_model" {
-			options := ai.GetProviderModels("google")
-			apiKey := sp.config.Providers.Google.APIKey
+			source := ai.GetModelSource(provider, sp.config)
+			options, current, fieldKey := source.Known(), field.Value, field.Key
 			return func() tea.Msg {
 				return picker.OpenModelPickerMsg{
 					Options:  options,
-					Current:  sp.config.Providers.Google.Model,
-					FieldKey: "google_model",
-					APIKey:   apiKey,
+					Current:  current,
+					FieldKey: fieldKey,
+					Source:   source,
 				}
 			}
 		}
@@ -382,6 +356,17 @@ func (sp *SettingsPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 				}
 			}
 		}
+		if field.Key == "response_language" {
+			options := ai.ResponseLanguageOptions
+			return func() tea.Msg {
+				return picker.OpenOptionPickerMsg{
+					Title:    "Response Language",
+					FieldKey: "response_language",
+					Options:  options,
+					Current:  responseLanguageDisplay(sp.config.ResponseLanguage),
+				}
+			}
+		}
 		if field.Key == "log_format" {
 			options := []string{"json", "text"}
 			return func() tea.Msg {
@@ -426,7 +411,17 @@ func (sp *SettingsPanel) Update(msg tea.KeyPressMsg) tea.Cmd {
 		}
 		return nil
 
+	case useSuggestedMaxTokensKey:
+		if sp.rejectManaged() {
+			return nil
+		}
+		sp.useSuggestedMaxTokens()
+		return nil
+
 	case "e":
+		if sp.rejectManaged() {
+			return nil
+		}
 		field := &sp.fields[sp.selected]
 		if field.Key == "model" && field.Type == "string" {
 			sp.editing = true
@@ -452,8 +447,9 @@ func (sp *SettingsPanel) handleEditMode(msg tea.KeyPressMsg) tea.Cmd {
 		if sp.validateValue(field.Type, sp.editValue) {
 			field.Value = sp.editValue
 			sp.changed = true
-			sp.applyField(field)
 			sp.errorMsg = ""
+			sp.clampMaxTokens(field)
+			sp.applyField(field)
 		} else {
 			sp.errorMsg = "Invalid value for " + field.Label
 		}
@@ -643,6 +639,8 @@ func (sp *SettingsPanel) applyField(field *SettingField) {
 		}
 	case "log_level":
 		sp.config.LogLevel = field.Value
+	case "response_language":
+		sp.config.ResponseLanguage = responseLanguageValue(field.Value)
 	case "log_format":
 		sp.config.LogFormat = field.Value
 	case "log_file":
@@ -660,7 +658,11 @@ func (sp *SettingsPanel) saveAndClose() tea.Cmd {
 	}
 }
 
-func (sp *SettingsPanel) loadModelCache() {
+func (sp *SettingsPanel) ensureModelCache() {
+	if sp.modelCacheLoaded {
+		return
+	}
+	sp.modelCacheLoaded = true
 	cachePath := ai.DefaultModelCachePath()
 	cache, err := ai.LoadModelCache(cachePath)
 	if err != nil {
@@ -727,6 +729,14 @@ func (sp *SettingsPanel) View() string {
 		} else {
 			value = field.Value
 		}
+		if !(sp.editing && i == sp.selected) {
+			if warning := sp.maxTokensWarning(field); warning != "" {
+				value += " " + warning
+			}
+			if suffix := sp.lockedSuffix(field.Key); suffix != "" {
+				value += " " + managedStyle.Render(suffix)
+			}
+		}
 
 		var line string
 		if i == sp.selected {
@@ -772,12 +782,18 @@ func (sp *SettingsPanel) View() string {
 			} else {
 				hint = "↑↓ Navigate • Enter: Pick • Esc: Close"
 			}
-		} else if selectedKey == "llm_provider" || selectedKey == "log_level" || selectedKey == "log_format" {
+		} else if selectedKey == "llm_provider" || selectedKey == "log_level" || selectedKey == "log_format" || selectedKey == "response_language" {
 			if sp.changed {
 				hint = "↑↓ Navigate • Enter: Pick • s: Save • Esc: Save & Close"
 			} else {
 				hint = "↑↓ Navigate • Enter: Pick • Esc: Close"
 			}
+		} else if suggested, ok := sp.selectedMaxTokensSuggestion(); ok {
+			if sp.changed {
+				hint = fmt.Sprintf("↑↓ Navigate • Enter: Edit • a: Use %d • s: Save • Esc: Save & Close", suggested)
+			} else {
+				hint = fmt.Sprintf("↑↓ Navigate • Enter: Edit • a: Use %d • Esc: Close", suggested)
+			}
 		} else if selectedKey == "copilot_auth" {
 			if sp.changed {
 				hint = "↑↓ Navigate • Enter: Details • s: Save • Esc: Save & Close"
@@ -823,6 +839,19 @@ func (sp *SettingsPanel) SetLogFormatValue(value string) {
 	sp.changed = true
 }
 
+// SetResponseLanguageValue updates the response language and marks settings
+// as changed.
+func (sp *SettingsPanel) SetResponseLanguageValue(value string) {
+	sp.config.ResponseLanguage = responseLanguageValue(value)
+	sp.changed = true
+	for i := range sp.fields {
+		if sp.fields[i].Key == "response_language" {
+			sp.fields[i].Value = responseLanguageDisplay(sp.config.ResponseLanguage)
+			break
+		}
+	}
+}
+
 // SetProviderValue updates the LLM provider and rebuilds fields.
 func (sp *SettingsPanel) SetProviderValue(value string) {
 	sp.config.LLMProvider = value
@@ -883,6 +912,26 @@ func (sp *SettingsPanel) SetGoogleModelValue(value string) {
 	}
 }
 
+// SetModelFieldValue updates the Model field picked in a model picker,
+// by its key. It reports false for keys that are not Model fields.
+func (sp *SettingsPanel) SetModelFieldValue(fieldKey, value string) bool {
+	switch fieldKey {
+	case "model":
+		sp.SetModelValue(value)
+	case "openai_model":
+		sp.SetOpenAIModelValue(value)
+	case "copilot_model":
+		sp.SetCopilotModelValue(value)
+	case "anthropic_model":
+		sp.SetAnthropicModelValue(value)
+	case "google_model":
+		sp.SetGoogleModelValue(value)
+	default:
+		return false
+	}
+	return true
+}
+
 func (sp *SettingsPanel) setModelValue(value string) {
 	sp.config.OpenRouter.Model = value
 	for i := range sp.fields {
@@ -923,6 +972,7 @@ func (sp *SettingsPanel) selectedFieldKey() string {
 // SetModelCache updates the cached model list for picker use.
 func (sp *SettingsPanel) SetModelCache(cache ai.ModelCache) {
 	sp.modelCache = cache
+	sp.modelCacheLoaded = true
 }
 
 // RefreshCopilotAuthStatus updates only the Copilot auth status field
@@ -1029,3 +1079,21 @@ func normalizeLogLevel(value string) string {
 func logLevelOptions() []string {
 	return []string{"trace", "debug", "info", "warn", "error"}
 }
+
+// responseLanguageDisplay shows an unset response language as "auto".
+func responseLanguageDisplay(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ai.ResponseLanguageAuto
+	}
+	return value
+}
+
+// responseLanguageValue stores "auto" as an empty response language.
+func responseLanguageValue(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, ai.ResponseLanguageAuto) {
+		return ""
+	}
+	return value
+}
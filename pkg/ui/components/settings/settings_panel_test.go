@@ -1,6 +1,7 @@
 package settings
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"wtf_cli/pkg/ui/components/testutils"
 
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestNewSettingsPanel(t *testing.T) {
@@ -418,8 +420,8 @@ func TestSettingsPanel_ModelPicker(t *testing.T) {
 	if len(openMsg.Options) != 2 {
 		t.Errorf("Expected 2 options, got %d", len(openMsg.Options))
 	}
-	if openMsg.APIURL != cfg.OpenRouter.APIURL {
-		t.Fatalf("Expected apiURL %q, got %q", cfg.OpenRouter.APIURL, openMsg.APIURL)
+	if openMsg.Source == nil || openMsg.Source.Provider() != ai.ProviderOpenRouter || !openMsg.Source.CanList() {
+		t.Fatalf("Expected an OpenRouter model source that can list, got %v", openMsg.Source)
 	}
 
 	modelPicker := picker.NewModelPickerPanel()
@@ -472,8 +474,8 @@ func TestSettingsPanel_GoogleModelPicker(t *testing.T) {
 	if openMsg.FieldKey != "google_model" {
 		t.Fatalf("Expected field key google_model, got %q", openMsg.FieldKey)
 	}
-	if openMsg.APIKey != "gemini-test-key" {
-		t.Fatalf("Expected Google API key in picker message, got %q", openMsg.APIKey)
+	if openMsg.Source == nil || openMsg.Source.Provider() != ai.ProviderGoogle || !openMsg.Source.CanList() {
+		t.Fatalf("Expected a Google model source with the API key, got %v", openMsg.Source)
 	}
 	if len(openMsg.Options) != len(ai.GetProviderModels("google")) {
 		t.Fatalf("Expected Google fallback options, got %d", len(openMsg.Options))
@@ -531,6 +533,33 @@ func TestSettingsPanel_OpenLogFormatPicker(t *testing.T) {
 	}
 }
 
+func TestSettingsPanel_ResponseLanguagePicker(t *testing.T) {
+	withTempHome(t, nil)
+
+	sp := NewSettingsPanel()
+	cfg := config.Default()
+	sp.Show(cfg, "/tmp/test_config.json")
+
+	sp.selected = findFieldIndex(t, sp, "response_language")
+	cmd := sp.Update(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("Expected openOptionPickerMsg command")
+	}
+	openMsg := cmd().(picker.OpenOptionPickerMsg)
+	if openMsg.FieldKey != "response_language" || openMsg.Current != "auto" {
+		t.Fatalf("Expected response_language picker with current auto, got %q/%q", openMsg.FieldKey, openMsg.Current)
+	}
+
+	sp.SetResponseLanguageValue("Japanese")
+	if sp.config.ResponseLanguage != "Japanese" || !sp.changed {
+		t.Fatalf("Expected Japanese to be stored and marked changed, got %q", sp.config.ResponseLanguage)
+	}
+	sp.SetResponseLanguageValue("auto")
+	if sp.config.ResponseLanguage != "" {
+		t.Fatalf("Expected auto to clear response language, got %q", sp.config.ResponseLanguage)
+	}
+}
+
 func containsString(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 &&
 		(s == substr || len(s) > len(substr) &&
@@ -566,3 +595,146 @@ func withTempHome(t *testing.T, setup func(string)) {
 		setup(tmpDir)
 	}
 }
+
+func TestSettingsPanel_ShowDefersModelCacheRead(t *testing.T) {
+	withTempHome(t, nil)
+
+	sp := NewSettingsPanel()
+	sp.Show(config.Default(), "/tmp/test_config.json")
+	if sp.modelCacheLoaded {
+		t.Fatal("Show should not read the model cache")
+	}
+
+	sp.selected = findFieldIndex(t, sp, "model")
+	sp.Update(testutils.TestKeyEnter)
+	if !sp.modelCacheLoaded {
+		t.Fatal("opening the model picker should load the model cache")
+	}
+}
+
+func TestSettingsPanel_MaxTokensOverModelLimit(t *testing.T) {
+	withTempHome(t, nil)
+
+	cfg := config.Default()
+	cfg.LLMProvider = "openai"
+	cfg.Providers.OpenAI.Model = "gpt-4"
+	cfg.Providers.OpenAI.MaxTokens = 50000
+
+	sp := NewSettingsPanel()
+	sp.Show(cfg, "/tmp/test_config.json")
+	sp.SetSize(120, 40)
+	sp.selected = findFieldIndex(t, sp, "openai_max_tokens")
+
+	view := ansi.Strip(sp.View())
+	if !strings.Contains(view, "gpt-4 allows 8192") {
+		t.Fatalf("expected inline limit warning, got:\n%s", view)
+	}
+	if !strings.Contains(view, "a: Use 8192") {
+		t.Fatalf("expected suggestion in footer, got:\n%s", view)
+	}
+
+	sp.Update(testutils.NewTextKeyPressMsg("a"))
+	if sp.config.Providers.OpenAI.MaxTokens != 8192 {
+		t.Fatalf("MaxTokens = %d, want 8192", sp.config.Providers.OpenAI.MaxTokens)
+	}
+	if !sp.changed {
+		t.Fatal("expected settings to be marked changed")
+	}
+	if strings.Contains(ansi.Strip(sp.View()), "allows") {
+		t.Fatal("expected warning to clear once within the limit")
+	}
+}
+
+func TestSettingsPanel_MaxTokensEditClampsToModelLimit(t *testing.T) {
+	withTempHome(t, nil)
+
+	cfg := config.Default()
+	cfg.LLMProvider = "anthropic"
+	cfg.Providers.Anthropic.Model = "claude-3-haiku-20240307"
+
+	sp := NewSettingsPanel()
+	sp.Show(cfg, "/tmp/test_config.json")
+	sp.selected = findFieldIndex(t, sp, "anthropic_max_tokens")
+	sp.editing = true
+	sp.editValue = "100000"
+	sp.Update(testutils.TestKeyEnter)
+
+	if sp.config.Providers.Anthropic.MaxTokens != 4096 {
+		t.Fatalf("MaxTokens = %d, want 4096", sp.config.Providers.Anthropic.MaxTokens)
+	}
+	if !strings.Contains(sp.errorMsg, "clamped to 4096") {
+		t.Fatalf("expected clamp notice, got %q", sp.errorMsg)
+	}
+}
+
+func TestSettingsPanel_MaxTokensUsesOpenRouterContextLength(t *testing.T) {
+	withTempHome(t, nil)
+
+	cfg := config.Default()
+	cfg.LLMProvider = "openrouter"
+	cfg.OpenRouter.Model = "vendor/small-model"
+	cfg.OpenRouter.MaxTokens = 8000
+
+	sp := NewSettingsPanel()
+	sp.Show(cfg, "/tmp/test_config.json")
+	sp.SetModelCache(ai.ModelCache{Models: []ai.ModelInfo{{ID: "vendor/small-model", ContextLength: 4096}}})
+
+	field := sp.fields[findFieldIndex(t, sp, "max_tokens")]
+	if _, limit, _, over := sp.maxTokensOverLimit(field); !over || limit != 4096 {
+		t.Fatalf("maxTokensOverLimit = %d, %v; want 4096, true", limit, over)
+	}
+}
+
+func TestSettingsPanel_ManagedFields(t *testing.T) {
+	withTempHome(t, nil)
+
+	sp := NewSettingsPanel()
+	sp.SetSize(100, 40)
+	cfg := config.Default()
+	cfg.Policy = config.Policy{
+		AllowedProviders: []string{"anthropic", "openai"},
+		Settings:         map[string]json.RawMessage{"buffer_size": json.RawMessage("1000")},
+	}
+	sp.Show(cfg, "/tmp/test_config.json")
+
+	view := ansi.Strip(sp.View())
+	if strings.Count(view, managedSuffix) != 1 {
+		t.Fatalf("Expected one managed setting, got:\n%s", view)
+	}
+
+	for i, field := range sp.fields {
+		if field.Key == "buffer_size" {
+			sp.selected = i
+		}
+	}
+	sp.Update(testutils.TestKeyEnter)
+	if sp.editing || !strings.Contains(sp.errorMsg, "managed by your organization") {
+		t.Fatalf("Expected a managed field not to be editable, editing=%v error=%q", sp.editing, sp.errorMsg)
+	}
+
+	sp.selected = 0 // LLM Provider
+	cmd := sp.Update(testutils.TestKeyEnter)
+	msg, ok := cmd().(picker.OpenOptionPickerMsg)
+	if !ok || len(msg.Options) != 2 || msg.Options[0] != "anthropic" {
+		t.Fatalf("Expected only the allowed providers to be offered, got %+v", cmd())
+	}
+}
+
+func TestSettingsPanel_EnvOverriddenFields(t *testing.T) {
+	withTempHome(t, nil)
+
+	sp := NewSettingsPanel()
+	sp.SetSize(100, 40)
+	cfg := config.Default()
+	cfg.Env = config.EnvOverrides{{Path: "buffer_size", Var: "WTF_BUFFER_SIZE"}}
+	sp.Show(cfg, "/tmp/test_config.json")
+
+	if view := ansi.Strip(sp.View()); !strings.Contains(view, "(set by WTF_BUFFER_SIZE)") {
+		t.Fatalf("Expected the overriding variable next to the field, got:\n%s", view)
+	}
+	sp.selected = findFieldIndex(t, sp, "buffer_size")
+	sp.Update(testutils.TestKeyEnter)
+	if sp.editing || !strings.Contains(sp.errorMsg, "is set by WTF_BUFFER_SIZE") {
+		t.Fatalf("Expected an overridden field not to be editable, editing=%v error=%q", sp.editing, sp.errorMsg)
+	}
+}
@@ -0,0 +1,21 @@
+package settings
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestSettingsPanelSnapshot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.LogFile = "/home/user/.wtf_cli/logs/wtf_cli.log"
+	sizes := []testutils.Size{testutils.SizeNormal, testutils.SizeWide}
+	testutils.RequireSnapshots(t, sizes, testutils.Profiles, func(width, height int) string {
+		sp := NewSettingsPanel()
+		sp.Show(cfg, "/home/user/.wtf_cli/config.json")
+		sp.SetSize(width, height)
+		return sp.View()
+	})
+}
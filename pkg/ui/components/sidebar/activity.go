@@ -0,0 +1,108 @@
+package sidebar
+
+import (
+	"fmt"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/progress"
+	"wtf_cli/pkg/ui/styles"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// reasoningToggleKey shows or hides the reasoning of every message. It works
+// with either the input or the conversation focused.
+const reasoningToggleKey = "ctrl+o"
+
+// reasoningSection renders reasoning ahead of a message's content: a one-line
+// summary when collapsed, every line quoted when expanded.
+func reasoningSection(reasoning string, expanded bool) string {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return ""
+	}
+	if !expanded {
+		return fmt.Sprintf("▸ Reasoning, %d words (Ctrl+O to expand)\n\n", len(strings.Fields(reasoning)))
+	}
+	var sb strings.Builder
+	sb.WriteString("▾ Reasoning (Ctrl+O to collapse)\n")
+	for _, line := range strings.Split(reasoning, "\n") {
+		sb.WriteString("│ " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// AppendLastReasoning appends delta to the reasoning of the last message.
+func (s *Sidebar) AppendLastReasoning(delta string) {
+	if len(s.messages) > 0 {
+		s.messages[len(s.messages)-1].Reasoning += delta
+	}
+}
+
+// ToggleReasoning expands or collapses reasoning in every message.
+func (s *Sidebar) ToggleReasoning() {
+	s.showReasoning = !s.showReasoning
+	s.RefreshView()
+}
+
+// ReasoningExpanded reports whether reasoning is shown in full.
+func (s *Sidebar) ReasoningExpanded() bool {
+	return s.showReasoning
+}
+
+// SetActivity shows what the model is doing, e.g. "calling read_file", with
+// a spinner on the line above the input. Empty clears it.
+func (s *Sidebar) SetActivity(activity string) {
+	s.activity = activity
+}
+
+// Activity returns the activity shown above the input.
+func (s *Sidebar) Activity() string {
+	return s.activity
+}
+
+// AdvanceSpinner moves the activity spinner and the typing indicator to
+// their next frame.
+func (s *Sidebar) AdvanceSpinner() {
+	s.spinnerFrame = (s.spinnerFrame + 1) % len(progress.Frames)
+	s.typingFrame = (s.typingFrame + 1) % (len(typingFrames) * typingFrameTicks)
+}
+
+// SetUsage shows the token counts of the current answer above the input.
+func (s *Sidebar) SetUsage(usage ai.Usage) {
+	s.usage = usage
+}
+
+// activitySeparator draws the line above the input with the activity on the
+// left and the token counts on the right. It returns "" when there is
+// nothing to show, leaving the plain separator.
+func (s *Sidebar) activitySeparator(contentWidth int) string {
+	left := ""
+	if s.activity != "" {
+		left = progress.Frames[s.spinnerFrame] + " " + s.activity + " "
+	}
+	right := ""
+	if !s.usage.IsZero() {
+		right = " " + formatUsage(s.usage)
+	}
+	if left == "" && right == "" {
+		return ""
+	}
+	if ansi.StringWidth(left)+ansi.StringWidth(right)+1 > contentWidth {
+		right = "" // The activity matters more while the answer streams
+	}
+	left = truncateToWidth(left, contentWidth)
+	fill := max(contentWidth-ansi.StringWidth(left)-ansi.StringWidth(right), 0)
+	return styles.TextMutedStyle.Render(left) + strings.Repeat("─", fill) + styles.TextMutedStyle.Render(right)
+}
+
+// formatUsage renders token counts compactly, e.g. "1.2k in · 340 out".
+func formatUsage(u ai.Usage) string {
+	text := ai.FormatTokens(u.InputTokens) + " in · " + ai.FormatTokens(u.OutputTokens) + " out"
+	if u.ReasoningTokens > 0 {
+		text += " (" + ai.FormatTokens(u.ReasoningTokens) + " reasoning)"
+	}
+	return text
+}
@@ -0,0 +1,111 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func viewportText(s *Sidebar) string {
+	var lines []string
+	for i := 0; i < s.lineCount(); i++ {
+		lines = append(lines, stripANSICodes(s.lineAt(i)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestSidebar_ReasoningCollapsedUntilToggled(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(60, 30)
+	s.Show()
+	s.StartAssistantMessageWithContent("Run this:\n<cmd>make clean</cmd>")
+	s.AppendLastReasoning("The build cache\nlooks stale.")
+	s.RefreshView()
+
+	text := viewportText(s)
+	if !strings.Contains(text, "▸ Reasoning, 5 words") {
+		t.Fatalf("expected collapsed reasoning summary, got:\n%s", text)
+	}
+	if strings.Contains(text, "looks stale") {
+		t.Fatalf("expected reasoning text hidden while collapsed, got:\n%s", text)
+	}
+
+	s.Update(tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl})
+	if !s.ReasoningExpanded() {
+		t.Fatal("expected Ctrl+O to expand reasoning")
+	}
+	text = viewportText(s)
+	if !strings.Contains(text, "│ The build cache") || !strings.Contains(text, "│ looks stale.") {
+		t.Fatalf("expected reasoning lines when expanded, got:\n%s", text)
+	}
+
+	// Commands after the reasoning still map to their rendered lines.
+	if len(s.cmdRenderedLines) != 1 || s.cmdRenderedLines[0] < 0 {
+		t.Fatalf("cmdRenderedLines = %v, want one rendered command", s.cmdRenderedLines)
+	}
+	if line := stripANSICodes(s.lineAt(s.cmdRenderedLines[0])); !strings.Contains(line, "make clean") {
+		t.Fatalf("command line = %q, want the command", line)
+	}
+}
+
+func TestSidebar_ReasoningToggleKeyWorksFromInput(t *testing.T) {
+	s := NewSidebar()
+	s.Show()
+	s.FocusInput()
+	key := tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl}
+	if !s.ShouldHandleKey(key) {
+		t.Fatal("expected sidebar to claim Ctrl+O with the input focused")
+	}
+	s.Update(key)
+	if !s.ReasoningExpanded() || s.textarea.Value() != "" {
+		t.Fatalf("expected toggle without typing, expanded=%v input=%q", s.ReasoningExpanded(), s.textarea.Value())
+	}
+}
+
+func TestSidebar_ActivityAndUsageInSeparator(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(60, 20)
+	s.Show()
+
+	if got := s.renderSeparator(40); got != strings.Repeat("─", 40) {
+		t.Fatalf("expected plain separator, got %q", got)
+	}
+
+	s.SetActivity("calling read_file")
+	s.SetUsage(ai.Usage{InputTokens: 1234, OutputTokens: 56})
+	got := stripANSICodes(s.renderSeparator(50))
+	if !strings.HasPrefix(got, "⠋ calling read_file ") || !strings.HasSuffix(got, " 1.2k in · 56 out") {
+		t.Fatalf("separator = %q", got)
+	}
+	if w := len([]rune(got)); w != 50 {
+		t.Fatalf("separator width = %d, want 50", w)
+	}
+
+	s.AdvanceSpinner()
+	if got := stripANSICodes(s.renderSeparator(50)); !strings.HasPrefix(got, "⠙ ") {
+		t.Fatalf("expected next spinner frame, got %q", got)
+	}
+
+	// Too narrow for both: the activity wins.
+	if got := stripANSICodes(s.renderSeparator(25)); strings.Contains(got, "in ·") {
+		t.Fatalf("expected usage dropped when narrow, got %q", got)
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	tests := []struct {
+		usage ai.Usage
+		want  string
+	}{
+		{ai.Usage{InputTokens: 12, OutputTokens: 3}, "12 in · 3 out"},
+		{ai.Usage{InputTokens: 2_500_000, OutputTokens: 1500, ReasoningTokens: 900}, "2.5M in · 1.5k out (900 reasoning)"},
+	}
+	for _, tt := range tests {
+		if got := formatUsage(tt.usage); got != tt.want {
+			t.Errorf("formatUsage(%+v) = %q, want %q", tt.usage, got, tt.want)
+		}
+	}
+}
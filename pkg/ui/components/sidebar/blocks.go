@@ -0,0 +1,221 @@
+package sidebar
+
+import (
+	"sort"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/selection"
+)
+
+// userSeparator is drawn above every user message but the first.
+const userSeparator = "───────────────────────"
+
+// messageBlock caches the rendered lines of one message so a stream tick
+// only re-renders the message that changed. Blocks far from the viewport
+// drop their lines and keep just the count; they are re-rendered from the
+// message when scrolled back into view.
+type messageBlock struct {
+	// Inputs the lines were rendered from.
+	content   string
+	reasoning string
+	expanded  bool // Reasoning is shown in full
+	role      string
+	pinned    bool
+	fresh     bool
+	refusal   string
+	first     bool
+	streaming bool // The answer is still coming in
+	width     int
+	valid     bool
+
+	lines    []string       // Rendered lines; nil once evicted
+	count    int            // Rendered line count, kept across eviction
+	start    int            // First line of the block in the whole conversation
+	msgLine  int            // Line the message prefix is on, relative to start
+	cmds     []CommandEntry // Commands in the message
+	cmdLines []int          // Line of each command relative to start; -1 if not rendered
+}
+
+func (b *messageBlock) matches(first bool, msg ai.ChatMessage, width int, expanded, streaming bool) bool {
+	return b.valid && b.width == width && b.first == first && b.streaming == streaming && b.role == msg.Role &&
+		b.pinned == msg.Pinned && b.fresh == msg.Fresh && b.refusal == msg.Refusal && b.content == msg.Content &&
+		b.reasoning == msg.Reasoning && b.expanded == (expanded && msg.Reasoning != "")
+}
+
+// render lays out msg as the block at position first (no leading spacing)
+// or after earlier messages, with its reasoning collapsed unless expanded.
+// A streaming message holds back its unfinished markdown.
+func (b *messageBlock) render(first bool, msg ai.ChatMessage, width int, expanded, streaming bool) {
+	var raw strings.Builder
+	msgRaw := 0
+	if !first {
+		raw.WriteString("\n") // blank line between messages
+		msgRaw++
+		if msg.Role == "user" {
+			raw.WriteString(userSeparator + "\n\n")
+			msgRaw += 2
+		}
+	}
+	if msg.Role == "user" {
+		raw.WriteString(MessagePrefix("user"))
+	} else {
+		raw.WriteString(MessagePrefix("assistant"))
+	}
+	if msg.Pinned {
+		raw.WriteString(pinnedMarker)
+	}
+	if msg.Fresh {
+		raw.WriteString(freshMarker)
+	}
+	expanded = expanded && msg.Reasoning != ""
+	reasoning := reasoningSection(msg.Reasoning, expanded)
+	raw.WriteString(reasoning)
+	contentRaw := msgRaw + strings.Count(reasoning, "\n")
+	content, pending := msg.Content, ""
+	if streaming {
+		content, pending = stabilizeStreaming(content)
+	}
+	raw.WriteString(FormatReplies(FormatCitations(StripCommandMarkers(content))))
+
+	var cmds []CommandEntry
+	if msg.Role == "assistant" {
+		cmds = ExtractCommands(content)
+	}
+	rawLines := make([]int, 0, len(cmds)+1)
+	for _, entry := range cmds {
+		offset := 0
+		if entry.SourceIndex > 0 && entry.SourceIndex <= len(content) {
+			offset = strings.Count(content[:entry.SourceIndex], "\n")
+		}
+		rawLines = append(rawLines, contentRaw+offset)
+	}
+	rawLines = append(rawLines, msgRaw)
+
+	lines, rendered := renderMarkdownWithCommandLines(raw.String(), width, rawLines)
+	if pending != "" {
+		lines = append(lines[:len(lines)-1], renderRawLines(pending, width)...)
+	}
+	if msg.Refusal != "" {
+		lines = append(lines, renderRefusal(msg.Refusal, width)...)
+	}
+	*b = messageBlock{
+		content:   msg.Content,
+		reasoning: msg.Reasoning,
+		expanded:  expanded,
+		role:      msg.Role,
+		pinned:    msg.Pinned,
+		fresh:     msg.Fresh,
+		refusal:   msg.Refusal,
+		first:     first,
+		streaming: streaming,
+		width:     width,
+		valid:     true,
+		lines:     lines,
+		count:     len(lines),
+		start:     b.start,
+		msgLine:   rendered[len(cmds)],
+		cmds:      cmds,
+		cmdLines:  rendered[:len(cmds)],
+	}
+}
+
+// reflowMessages lays out the conversation from the block cache, rendering
+// only blocks whose message or width changed.
+func (s *Sidebar) reflowMessages(width int) {
+	if len(s.blocks) > len(s.messages) {
+		s.blocks = s.blocks[:len(s.messages)]
+	}
+	for len(s.blocks) < len(s.messages) {
+		s.blocks = append(s.blocks, messageBlock{})
+	}
+
+	s.cmdList = s.cmdList[:0]
+	s.cmdRenderedLines = s.cmdRenderedLines[:0]
+	s.msgRenderedLines = s.msgRenderedLines[:0]
+	start := 0
+	for i, msg := range s.messages {
+		b := &s.blocks[i]
+		streaming := s.streaming && i == len(s.messages)-1
+		if !b.matches(i == 0, msg, width, s.showReasoning, streaming) {
+			b.render(i == 0, msg, width, s.showReasoning, streaming)
+		}
+		b.start = start
+		msgLine := b.msgLine
+		if msgLine >= 0 {
+			msgLine += start
+		}
+		s.msgRenderedLines = append(s.msgRenderedLines, msgLine)
+		for j, entry := range b.cmds {
+			line := b.cmdLines[j]
+			if line >= 0 {
+				line += start
+			}
+			entry.Dir = msg.Dir
+			s.cmdList = append(s.cmdList, entry)
+			s.cmdRenderedLines = append(s.cmdRenderedLines, line)
+		}
+		start += b.count
+	}
+	s.lineTotal = start
+}
+
+// evictBlocks drops the rendered lines of blocks well outside the viewport,
+// so memory for a long conversation stays proportional to what is shown.
+func (s *Sidebar) evictBlocks() {
+	height := s.viewportHeight()
+	top := s.scrollY - height
+	bottom := s.scrollY + 2*height
+	for i := range s.blocks {
+		b := &s.blocks[i]
+		if b.start+b.count <= top || b.start >= bottom {
+			b.lines = nil
+		}
+	}
+}
+
+// lineCount returns the number of rendered content lines.
+func (s *Sidebar) lineCount() int {
+	if s.fromMessages {
+		return s.lineTotal
+	}
+	return len(s.lines)
+}
+
+// lineAt returns rendered line i, re-rendering its block if it was evicted.
+func (s *Sidebar) lineAt(i int) string {
+	if !s.fromMessages {
+		return s.lines[i]
+	}
+	idx := sort.Search(len(s.blocks), func(j int) bool {
+		return s.blocks[j].start+s.blocks[j].count > i
+	})
+	b := &s.blocks[idx]
+	if b.lines == nil {
+		b.render(idx == 0, s.messages[idx], b.width, b.expanded, b.streaming)
+	}
+	return b.lines[i-b.start]
+}
+
+// selectedText extracts the selection from the rendered lines it covers.
+func (s *Sidebar) selectedText() string {
+	total := s.lineCount()
+	if s.sel.IsEmpty() || total == 0 {
+		return ""
+	}
+	startRow, _, endRow, _ := s.sel.Normalize()
+	startRow = max(startRow, 0)
+	endRow = min(endRow, total-1)
+	if endRow < startRow {
+		return ""
+	}
+
+	lines := make([]string, 0, endRow-startRow+1)
+	for i := startRow; i <= endRow; i++ {
+		lines = append(lines, s.lineAt(i))
+	}
+	sel := s.sel
+	sel.AnchorRow -= startRow
+	sel.EndRow -= startRow
+	return selection.ExtractText(lines, sel)
+}
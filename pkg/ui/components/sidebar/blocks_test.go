@@ -0,0 +1,83 @@
+package sidebar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newConversationSidebar(t *testing.T, height, turns int) *Sidebar {
+	t.Helper()
+	s := NewSidebar()
+	s.Show()
+	s.SetSize(60, height)
+	for i := range turns {
+		s.AppendUserMessage(fmt.Sprintf("question %d", i))
+		s.StartAssistantMessageWithContent(fmt.Sprintf("Answer %d:\n```\ncode line %d\n```\nRun <cmd>echo %d</cmd> next.", i, i, i))
+	}
+	s.RefreshView()
+	return s
+}
+
+func allLines(s *Sidebar) []string {
+	lines := make([]string, s.lineCount())
+	for i := range lines {
+		lines[i] = s.lineAt(i)
+	}
+	return lines
+}
+
+func TestSidebar_BlocksMatchWholeConversationRender(t *testing.T) {
+	s := newConversationSidebar(t, 200, 3)
+	s.ToggleMessagePin(1)
+	s.RefreshView()
+
+	want := renderMarkdown(StripCommandMarkers(s.RenderMessages()), s.contentWidth())
+	got := allLines(s)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("block render differs from whole render:\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(s.cmdList) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(s.cmdList))
+	}
+	for i, line := range s.cmdRenderedLines {
+		if !strings.Contains(stripANSICodes(got[line]), fmt.Sprintf("echo %d", i)) {
+			t.Errorf("command %d maps to line %d %q", i, line, got[line])
+		}
+	}
+}
+
+func TestSidebar_StreamingOnlyRerendersTail(t *testing.T) {
+	s := newConversationSidebar(t, 200, 3)
+	first := &s.blocks[0].lines[0]
+	before := len(s.blocks[len(s.blocks)-1].lines)
+
+	s.UpdateLastMessage("\nmore\nlines")
+	s.RefreshView()
+
+	if &s.blocks[0].lines[0] != first {
+		t.Fatal("expected unchanged messages to keep their rendered lines")
+	}
+	if after := len(s.blocks[len(s.blocks)-1].lines); after != before+2 {
+		t.Fatalf("expected the tail to grow by 2 lines, got %d -> %d", before, after)
+	}
+}
+
+func TestSidebar_EvictsBlocksOutsideViewport(t *testing.T) {
+	s := newConversationSidebar(t, 20, 40)
+	want := allLines(s)
+	s.RefreshView() // drop what allLines re-rendered
+
+	if s.blocks[0].lines != nil {
+		t.Fatal("expected blocks far above the viewport to be evicted")
+	}
+	if s.blocks[len(s.blocks)-1].lines == nil {
+		t.Fatal("expected the visible tail block to stay rendered")
+	}
+	if got := s.lineAt(0); got != want[0] {
+		t.Fatalf("re-rendered line = %q, want %q", got, want[0])
+	}
+	if s.lineCount() != len(want) {
+		t.Fatalf("lineCount = %d, want %d", s.lineCount(), len(want))
+	}
+}
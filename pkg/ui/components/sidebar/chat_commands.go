@@ -0,0 +1,80 @@
+package sidebar
+
+import (
+	"regexp"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+)
+
+// Chat commands typed in the input instead of a message.
+const (
+	ChatCommandModel = "model" // /model [name|default]: model for this session
+	ChatCommandClear = "clear" // /clear: start a new conversation
+	ChatCommandRetry = "retry" // /retry: ask the last question again
+	ChatCommandCopy  = "copy"  // /copy: copy the last answer
+)
+
+// ChatCommands lists the chat commands, for the unknown command hint.
+var ChatCommands = []string{ChatCommandModel, ChatCommandClear, ChatCommandRetry, ChatCommandCopy}
+
+// chatCommandPattern matches input that reads as a command: a slash and a
+// word, so paths like /etc/hosts are sent as messages without a hint.
+var chatCommandPattern = regexp.MustCompile(`^/([a-zA-Z][\w-]*)(?:\s+(.*))?$`)
+
+// ChatCommandMsg is emitted when a chat command is entered in the input.
+type ChatCommandMsg struct {
+	Name string // One of the ChatCommand constants
+	Args string // Rest of the line, trimmed
+}
+
+// parseChatCommand splits content typed in the input into a chat command
+// and its arguments. name is set for any slash command; known reports
+// whether it is one of ChatCommands.
+func parseChatCommand(content string) (name, args string, known bool) {
+	match := chatCommandPattern.FindStringSubmatch(strings.TrimSpace(content))
+	if match == nil {
+		return "", "", false
+	}
+	name = strings.ToLower(match[1])
+	for _, command := range ChatCommands {
+		if name == command {
+			return name, strings.TrimSpace(match[2]), true
+		}
+	}
+	return name, "", false
+}
+
+// chatInputMsg is what Enter sends for content: a ChatCommandMsg for a
+// chat command, otherwise the message, noting an unknown command so the
+// user can be told it was sent as text.
+func (s *Sidebar) chatInputMsg(content string) any {
+	name, args, known := parseChatCommand(content)
+	if known {
+		return ChatCommandMsg{Name: name, Args: args}
+	}
+	submit := ChatSubmitMsg{Content: content, Preview: s.preview, NoHistory: s.noHistory, UnknownCommand: name}
+	s.noHistory = false
+	return submit
+}
+
+// LastPrompt returns the conversation up to and including the last user
+// message, to ask it again.
+func (s *Sidebar) LastPrompt() ([]ai.ChatMessage, bool) {
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].Role == "user" {
+			return append([]ai.ChatMessage(nil), s.messages[:i+1]...), true
+		}
+	}
+	return nil, false
+}
+
+// LastAnswer returns the index of the last assistant message, or -1.
+func (s *Sidebar) LastAnswer() int {
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].Role == "assistant" {
+			return i
+		}
+	}
+	return -1
+}
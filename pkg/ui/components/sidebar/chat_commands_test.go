@@ -0,0 +1,75 @@
+package sidebar
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseChatCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		name  string
+		args  string
+		known bool
+	}{
+		{"/model gpt-4o", "model", "gpt-4o", true},
+		{"/MODEL  gpt-4o ", "model", "gpt-4o", true},
+		{"/clear", "clear", "", true},
+		{"/retry", "retry", "", true},
+		{"/copy", "copy", "", true},
+		{"/frobnicate now", "frobnicate", "", false},
+		{"/etc/hosts is empty", "", "", false},
+		{"why does /clear not work?", "", "", false},
+	}
+	for _, tt := range tests {
+		name, args, known := parseChatCommand(tt.input)
+		if name != tt.name || args != tt.args || known != tt.known {
+			t.Errorf("parseChatCommand(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.input, name, args, known, tt.name, tt.args, tt.known)
+		}
+	}
+}
+
+func TestSidebar_EnterSendsChatCommand(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.Show()
+	s.FocusInput()
+
+	s.SetInput("/model gpt-4o")
+	cmd := s.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected Enter to send the command")
+	}
+	if msg, ok := cmd().(ChatCommandMsg); !ok || msg.Name != ChatCommandModel || msg.Args != "gpt-4o" {
+		t.Fatalf("expected ChatCommandMsg for /model, got %#v", cmd())
+	}
+	if len(s.GetMessages()) != 0 {
+		t.Fatal("expected the command to stay out of the conversation")
+	}
+
+	s.SetInput("/deploy staging")
+	cmd = s.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if msg, ok := cmd().(ChatSubmitMsg); !ok || msg.Content != "/deploy staging" || msg.UnknownCommand != "deploy" {
+		t.Fatalf("expected an unknown command to be sent as a message, got %#v", cmd())
+	}
+}
+
+func TestSidebar_LastPromptAndAnswer(t *testing.T) {
+	s := NewSidebar()
+	if _, ok := s.LastPrompt(); ok || s.LastAnswer() != -1 {
+		t.Fatal("expected nothing in an empty conversation")
+	}
+	s.AppendUserMessage("first")
+	s.StartAssistantMessageWithContent("one")
+	s.AppendUserMessage("second")
+
+	history, ok := s.LastPrompt()
+	if !ok || len(history) != 3 || history[2].Content != "second" {
+		t.Fatalf("LastPrompt() = %+v", history)
+	}
+	if got := s.LastAnswer(); got != 1 {
+		t.Errorf("LastAnswer() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,30 @@
+package sidebar
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// citationPattern matches a terminal line cited as <line>1204</line>.
+var citationPattern = regexp.MustCompile(`<line>\s*(\d+)\s*</line>`)
+
+// ExtractCitations returns the terminal line numbers cited in content, in
+// order of first appearance and without duplicates.
+func ExtractCitations(content string) []int {
+	var lines []int
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(content, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n <= 0 || seen[n] {
+			continue
+		}
+		seen[n] = true
+		lines = append(lines, n)
+	}
+	return lines
+}
+
+// FormatCitations replaces <line> markers with a visible [line N] marker.
+func FormatCitations(content string) string {
+	return citationPattern.ReplaceAllString(content, "[line $1]")
+}
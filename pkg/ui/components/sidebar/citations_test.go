@@ -0,0 +1,24 @@
+package sidebar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractCitations(t *testing.T) {
+	content := "The build failed on <line>1204</line>, see also <line> 1190 </line> and <line>1204</line>. <line>0</line> <line>x</line>"
+	got := ExtractCitations(content)
+	if want := []int{1204, 1190}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExtractCitations() = %v, want %v", got, want)
+	}
+	if got := ExtractCitations("no citations"); got != nil {
+		t.Fatalf("ExtractCitations() = %v, want nil", got)
+	}
+}
+
+func TestFormatCitations(t *testing.T) {
+	got := FormatCitations("Failed at <line>12</line>.")
+	if want := "Failed at [line 12]."; got != want {
+		t.Fatalf("FormatCitations() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,11 @@
 package sidebar
 
-import "strings"
+import (
+	"sort"
+	"strings"
+
+	"wtf_cli/pkg/cmdrisk"
+)
 
 const (
 	cmdOpenTag  = "<cmd>"
@@ -11,13 +16,45 @@ const (
 type CommandEntry struct {
 	Command     string
 	SourceIndex int
+	Risk        cmdrisk.Risk // How risky the command is to run
+	Dir         string       // Shell directory when the command was suggested
 }
 
-// ExtractCommands parses commands wrapped in <cmd>...</cmd> markers.
+// shellFenceLangs are the code block languages whose lines are commands.
+var shellFenceLangs = map[string]bool{"bash": true, "sh": true, "shell": true, "zsh": true}
+
+// ExtractCommands returns the commands an answer suggests: those wrapped in
+// <cmd>...</cmd> markers and, since models sometimes omit or break the
+// markers, the lines of shell code blocks and "$ "-prefixed lines. Repeated
+// commands are kept once, and entries are in the order they appear.
 func ExtractCommands(content string) []CommandEntry {
 	if content == "" {
 		return nil
 	}
+	entries := extractMarkedCommands(content)
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if cmd, ok := SanitizeCommand(entry.Command); ok {
+			seen[cmd] = true
+		}
+	}
+	added := false
+	for _, entry := range extractUnmarkedCommands(content) {
+		if seen[entry.Command] {
+			continue
+		}
+		seen[entry.Command] = true
+		entries = append(entries, entry)
+		added = true
+	}
+	if added {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].SourceIndex < entries[j].SourceIndex })
+	}
+	return entries
+}
+
+// extractMarkedCommands parses commands wrapped in <cmd>...</cmd> markers.
+func extractMarkedCommands(content string) []CommandEntry {
 
 	entries := make([]CommandEntry, 0, 2)
 	searchStart := 0
@@ -36,9 +73,11 @@ func ExtractCommands(content string) []CommandEntry {
 		}
 		close := cmdStart + closeRel
 
+		command := content[cmdStart:close]
 		entries = append(entries, CommandEntry{
-			Command:     content[cmdStart:close],
+			Command:     command,
 			SourceIndex: cmdStart,
+			Risk:        cmdrisk.Classify(command),
 		})
 
 		searchStart = close + len(cmdCloseTag)
@@ -47,6 +86,75 @@ func ExtractCommands(content string) []CommandEntry {
 	return entries
 }
 
+// extractUnmarkedCommands finds commands without markers: each line of a
+// closed bash, sh, shell or zsh code block, and lines starting with a "$ "
+// prompt anywhere. Comments, lines holding markers and multi-line commands
+// are left out.
+func extractUnmarkedCommands(content string) []CommandEntry {
+	var entries []CommandEntry
+	add := func(line string, start int) {
+		trimmed := strings.TrimSpace(line)
+		command := strings.TrimSpace(strings.TrimPrefix(trimmed, "$ "))
+		if command == "" || strings.HasPrefix(command, "#") || strings.Contains(line, cmdOpenTag) || strings.Contains(line, cmdCloseTag) {
+			return
+		}
+		entries = append(entries, CommandEntry{
+			Command:     command,
+			SourceIndex: start + strings.Index(line, command),
+			Risk:        cmdrisk.Classify(command),
+		})
+	}
+
+	var block []int // Offsets of the lines of the open shell code block
+	inFence, shellFence, continued := false, false, false
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+		line = strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if inFence && shellFence {
+				for _, lineStart := range block {
+					add(content[lineStart:lineEnd(content, lineStart)], lineStart)
+				}
+			}
+			inFence = !inFence
+			shellFence = inFence && shellFenceLangs[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))]
+			block, continued = nil, false
+			continue
+		}
+		// A line ending in a backslash continues on the next one, and a
+		// multi-line command cannot be applied with Enter.
+		wasContinued := continued
+		continued = strings.HasSuffix(trimmed, "\\")
+		if wasContinued || continued {
+			continue
+		}
+		switch {
+		case inFence && shellFence:
+			block = append(block, start)
+		case strings.HasPrefix(trimmed, "$ "):
+			add(line, start)
+		}
+	}
+	return entries
+}
+
+// lineEnd returns the offset of the end of the line starting at start.
+func lineEnd(content string, start int) int {
+	end := strings.IndexByte(content[start:], '\n')
+	if end < 0 {
+		return len(content)
+	}
+	end += start
+	if end > start && content[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
 // StripCommandMarkers removes <cmd> markers while preserving command text.
 func StripCommandMarkers(content string) string {
 	if content == "" {
@@ -1,6 +1,10 @@
 package sidebar
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestExtractCommands_Single(t *testing.T) {
 	content := "Try <cmd>ls -la</cmd> first."
@@ -86,3 +90,41 @@ func TestSanitizeCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractCommands_UnmarkedFallback(t *testing.T) {
+	content := "Check the service:\n" +
+		"```bash\n" +
+		"# restart it\n" +
+		"systemctl restart nginx\n" +
+		"docker run \\\n" +
+		"  -it alpine\n" +
+		"```\n" +
+		"Then look at the logs:\n" +
+		"$ journalctl -u nginx\n" +
+		"or <cmd>systemctl status nginx</cmd>, and again\n" +
+		"```sh\n" +
+		"$ systemctl status nginx\n" +
+		"```\n" +
+		"```text\n" +
+		"not a command\n" +
+		"```\n"
+	entries := ExtractCommands(content)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Command)
+		if !strings.HasPrefix(content[e.SourceIndex:], e.Command) {
+			t.Fatalf("source index of %q points at %q", e.Command, content[e.SourceIndex:])
+		}
+	}
+	want := []string{"systemctl restart nginx", "journalctl -u nginx", "systemctl status nginx"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExtractCommands() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractCommands_UnclosedFenceIgnored(t *testing.T) {
+	if entries := ExtractCommands("Run:\n```bash\nmake test\n"); len(entries) != 0 {
+		t.Fatalf("expected no commands from an unclosed block, got %v", entries)
+	}
+}
@@ -0,0 +1,55 @@
+package sidebar
+
+import "wtf_cli/pkg/ui/styles"
+
+// historyToggleKey switches whether the next message is sent with the
+// earlier conversation. It works with either the input or the
+// conversation focused.
+const historyToggleKey = "ctrl+h"
+
+// freshMarker labels a user message that was sent without the earlier
+// conversation.
+const freshMarker = "[no history] "
+
+// ToggleHistory switches whether the next message is sent with the
+// earlier conversation. Sending a message switches it back on.
+func (s *Sidebar) ToggleHistory() {
+	s.noHistory = !s.noHistory
+}
+
+// HistoryIncluded reports whether the next message is sent with the earlier
+// conversation.
+func (s *Sidebar) HistoryIncluded() bool {
+	return !s.noHistory
+}
+
+// showsHistoryToggle reports whether the checkbox line is drawn above the
+// input: only once the user has chatted, so there is a conversation to
+// leave out.
+func (s *Sidebar) showsHistoryToggle() bool {
+	if s.noHistory {
+		return true
+	}
+	for _, msg := range s.messages {
+		if msg.Role == "user" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderHistoryToggle draws the checkbox for sending the conversation.
+func (s *Sidebar) renderHistoryToggle(contentWidth int) string {
+	line := "[x] Send chat history (Ctrl+H)"
+	if s.noHistory {
+		line = "[ ] Send chat history (Ctrl+H): terminal context only"
+	}
+	return styles.TextMutedStyle.Render(truncateToWidth(line, contentWidth))
+}
+
+// AppendFreshUserMessage adds a user message that is sent without the
+// earlier conversation.
+func (s *Sidebar) AppendFreshUserMessage(content string) {
+	s.AppendUserMessage(content)
+	s.messages[len(s.messages)-1].Fresh = true
+}
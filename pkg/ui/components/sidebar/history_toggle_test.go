@@ -0,0 +1,62 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSidebar_HistoryToggleMarksSubmit(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.Show()
+	s.FocusInput()
+	s.AppendUserMessage("what is in this directory?")
+	s.StartAssistantMessageWithContent("Three files.")
+
+	if view := s.View(); !strings.Contains(view, "[x] Send chat history") {
+		t.Fatalf("expected the checkbox above the input, got:\n%s", view)
+	}
+
+	key := tea.KeyPressMsg{Code: 'h', Mod: tea.ModCtrl}
+	if !s.ShouldHandleKey(key) {
+		t.Fatal("expected sidebar to claim Ctrl+H with the input focused")
+	}
+	s.Update(key)
+	if s.HistoryIncluded() {
+		t.Fatal("expected Ctrl+H to leave the history out")
+	}
+	if view := s.View(); !strings.Contains(view, "[ ] Send chat history") {
+		t.Fatalf("expected the checkbox cleared, got:\n%s", view)
+	}
+
+	s.textarea.SetValue("why did make fail?")
+	cmd := s.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected Enter to submit")
+	}
+	if msg, ok := cmd().(ChatSubmitMsg); !ok || !msg.NoHistory {
+		t.Fatalf("expected a ChatSubmitMsg without history, got %#v", cmd())
+	}
+	if !s.HistoryIncluded() {
+		t.Fatal("expected the history to be sent again after one message")
+	}
+}
+
+func TestSidebar_FreshMessageShowsMarker(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.Show()
+	s.AppendUserMessage("first question")
+	s.AppendFreshUserMessage("unrelated question")
+
+	msgs := s.GetMessages()
+	if msgs[0].Fresh || !msgs[1].Fresh {
+		t.Fatalf("expected only the second message to be fresh, got %+v", msgs)
+	}
+	rendered := s.RenderMessages()
+	if strings.Contains(rendered, freshMarker+"first question") || !strings.Contains(rendered, freshMarker+"unrelated question") {
+		t.Fatalf("expected the marker on the fresh message only, got:\n%s", rendered)
+	}
+}
@@ -0,0 +1,184 @@
+package sidebar
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/styles"
+)
+
+// paramsToggleKey opens the sampling settings of the current conversation.
+// Ctrl+O already expands reasoning.
+const paramsToggleKey = "alt+o"
+
+// Fields of the params popover, in display order.
+const (
+	paramTemperature = iota
+	paramMaxTokens
+	paramTopP
+	paramCount
+)
+
+const (
+	temperatureStep = 0.1
+	maxTemperature  = 2
+	topPStep        = 0.05
+	minMaxTokens    = 256
+	maxMaxTokens    = 131072
+	// fallbackMaxTokens is where max tokens starts from when the config
+	// leaves it to the provider.
+	fallbackMaxTokens = 4096
+)
+
+// SetParamDefaults sets the configured temperature and max tokens the
+// popover shows for fields the conversation does not override.
+func (s *Sidebar) SetParamDefaults(temperature float64, maxTokens int) {
+	s.defaultTemperature = temperature
+	s.defaultMaxTokens = maxTokens
+}
+
+// Params returns the sampling settings set for the current conversation.
+func (s *Sidebar) Params() ai.SamplingParams {
+	return s.params
+}
+
+// ToggleParams opens or closes the params popover.
+func (s *Sidebar) ToggleParams() {
+	s.paramsOpen = !s.paramsOpen
+}
+
+// ParamsOpen reports whether the params popover is shown.
+func (s *Sidebar) ParamsOpen() bool {
+	return s.paramsOpen
+}
+
+// handleParamsKey edits the params with the popover open: Up/Down pick a
+// field, Left/Right change it and Backspace puts it back to the default.
+func (s *Sidebar) handleParamsKey(key string) {
+	switch key {
+	case "esc", "enter", paramsToggleKey:
+		s.paramsOpen = false
+	case "up":
+		s.paramsField = (s.paramsField + paramCount - 1) % paramCount
+	case "down", "tab":
+		s.paramsField = (s.paramsField + 1) % paramCount
+	case "right", "+", "=":
+		s.stepParam(1)
+	case "left", "-":
+		s.stepParam(-1)
+	case "backspace", "delete":
+		switch s.paramsField {
+		case paramTemperature:
+			s.params.Temperature = nil
+		case paramMaxTokens:
+			s.params.MaxTokens = nil
+		case paramTopP:
+			s.params.TopP = nil
+		}
+	}
+}
+
+// stepParam moves the selected field one step up or down from its current
+// value, overriding the default.
+func (s *Sidebar) stepParam(dir int) {
+	switch s.paramsField {
+	case paramTemperature:
+		v := s.defaultTemperature
+		if s.params.Temperature != nil {
+			v = *s.params.Temperature
+		}
+		v = roundParam(min(max(v+float64(dir)*temperatureStep, 0), maxTemperature))
+		s.params.Temperature = &v
+	case paramMaxTokens:
+		v := s.defaultMaxTokens
+		if s.params.MaxTokens != nil {
+			v = *s.params.MaxTokens
+		}
+		if v <= 0 {
+			v = fallbackMaxTokens
+		} else if dir > 0 {
+			v *= 2
+		} else {
+			v /= 2
+		}
+		v = min(max(v, minMaxTokens), maxMaxTokens)
+		s.params.MaxTokens = &v
+	case paramTopP:
+		v := 1.0
+		if s.params.TopP != nil {
+			v = *s.params.TopP
+		}
+		v = roundParam(min(max(v+float64(dir)*topPStep, topPStep), 1))
+		s.params.TopP = &v
+	}
+}
+
+// roundParam drops the float error that repeated steps pile up.
+func roundParam(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func formatParam(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// paramsLabel lists the overridden params for the footer, e.g.
+// "temp 0.2, top_p 0.9", or "" when the conversation uses the defaults.
+func (s *Sidebar) paramsLabel() string {
+	var parts []string
+	if s.params.Temperature != nil {
+		parts = append(parts, "temp "+formatParam(*s.params.Temperature))
+	}
+	if s.params.MaxTokens != nil {
+		parts = append(parts, "max "+strconv.Itoa(*s.params.MaxTokens))
+	}
+	if s.params.TopP != nil {
+		parts = append(parts, "top_p "+formatParam(*s.params.TopP))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// paramsLines is how many lines the popover takes above the input.
+func (s *Sidebar) paramsLines() int {
+	if !s.paramsOpen {
+		return 0
+	}
+	return 1 + paramCount
+}
+
+// renderParams draws the popover: a heading and one line per field, the
+// selected one highlighted.
+func (s *Sidebar) renderParams(contentWidth int) []string {
+	temperature := formatParam(s.defaultTemperature) + " (default)"
+	if s.params.Temperature != nil {
+		temperature = formatParam(*s.params.Temperature)
+	}
+	maxTokens := "provider default"
+	if s.defaultMaxTokens > 0 {
+		maxTokens = strconv.Itoa(s.defaultMaxTokens) + " (default)"
+	}
+	if s.params.MaxTokens != nil {
+		maxTokens = strconv.Itoa(*s.params.MaxTokens)
+	}
+	topP := "provider default"
+	if s.params.TopP != nil {
+		topP = formatParam(*s.params.TopP)
+	}
+
+	lines := []string{styles.TextMutedStyle.Render(truncateToWidth(
+		"This conversation: ←/→ change | Backspace default | Esc close", contentWidth))}
+	for i, field := range []string{
+		"Temperature  " + temperature,
+		"Max tokens   " + maxTokens,
+		"Top p        " + topP,
+	} {
+		if i == s.paramsField {
+			lines = append(lines, styles.SelectedStyle.Render(truncateToWidth("> "+field, contentWidth)))
+			continue
+		}
+		lines = append(lines, truncateToWidth("  "+field, contentWidth))
+	}
+	return lines
+}
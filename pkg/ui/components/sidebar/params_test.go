@@ -0,0 +1,93 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSidebar_ParamsPopoverOverridesConversation(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.SetActiveLLM("openrouter", "gpt-x")
+	s.SetParamDefaults(0.7, 2000)
+	s.Show()
+	s.FocusInput()
+
+	key := tea.KeyPressMsg{Code: 'o', Mod: tea.ModAlt}
+	if !s.ShouldHandleKey(key) {
+		t.Fatal("expected sidebar to claim Alt+O with the input focused")
+	}
+	s.Update(key)
+	if !s.ParamsOpen() {
+		t.Fatal("expected Alt+O to open the params popover")
+	}
+	if view := s.View(); !strings.Contains(view, "Temperature  0.7 (default)") || !strings.Contains(view, "Top p        provider default") {
+		t.Fatalf("expected the defaults in the popover, got:\n%s", view)
+	}
+
+	s.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+	s.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+	s.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	s.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+	s.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	s.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+	if s.textarea.Value() != "" {
+		t.Fatalf("expected the popover to keep keys from the input, got %q", s.textarea.Value())
+	}
+
+	p := s.Params()
+	if p.Temperature == nil || *p.Temperature != 0.5 {
+		t.Fatalf("expected temperature 0.5, got %v", p.Temperature)
+	}
+	if p.MaxTokens == nil || *p.MaxTokens != 4000 {
+		t.Fatalf("expected max tokens 4000, got %v", p.MaxTokens)
+	}
+	if p.TopP == nil || *p.TopP != 0.95 {
+		t.Fatalf("expected top_p 0.95, got %v", p.TopP)
+	}
+
+	s.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if s.ParamsOpen() || !s.IsVisible() {
+		t.Fatal("expected Esc to close the popover and keep the sidebar open")
+	}
+	if footer := s.commandFooterText(200); !strings.Contains(footer, "LLM: openrouter-gpt-x (temp 0.5, max 4000, top_p 0.95)") {
+		t.Fatalf("expected the overrides next to the model, got %q", footer)
+	}
+}
+
+func TestSidebar_ParamsBackspaceRestoresDefault(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.SetParamDefaults(0.7, 0)
+	s.Show()
+	s.ToggleParams()
+
+	s.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	s.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+	if p := s.Params(); p.MaxTokens == nil || *p.MaxTokens != fallbackMaxTokens {
+		t.Fatalf("expected max tokens to start from %d, got %v", fallbackMaxTokens, p.MaxTokens)
+	}
+	s.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	if !s.Params().IsZero() {
+		t.Fatalf("expected Backspace to restore the default, got %+v", s.Params())
+	}
+}
+
+func TestSidebar_SetMessagesResetsParams(t *testing.T) {
+	s := NewSidebar()
+	s.SetParamDefaults(0.7, 2000)
+	s.ToggleParams()
+	s.stepParam(1)
+	if s.Params().IsZero() {
+		t.Fatal("expected an override")
+	}
+
+	s.SetMessages([]ai.ChatMessage{{Role: "user", Content: "hi"}})
+	if !s.Params().IsZero() {
+		t.Fatalf("expected another conversation to use the defaults, got %+v", s.Params())
+	}
+}
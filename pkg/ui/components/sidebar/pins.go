@@ -0,0 +1,42 @@
+package sidebar
+
+import (
+	"fmt"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/styles"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// pinChipLabelWidth caps the label shown in one pinned-output chip.
+const pinChipLabelWidth = 20
+
+// SetPinned sets the output blocks shown as chips above the input. The chip
+// line takes a row from the message viewport while any block is pinned.
+func (s *Sidebar) SetPinned(blocks []ai.PinnedBlock) {
+	s.pinned = append([]ai.PinnedBlock(nil), blocks...)
+	if s.follow || s.scrollY > s.maxScroll() {
+		s.scrollY = s.maxScroll()
+	}
+}
+
+// renderPinnedChips draws one chip per pinned block with its token cost,
+// e.g. "Pinned: [make test ~1.2k] [selection ~40]". Chips that do not fit
+// are counted as "+N".
+func (s *Sidebar) renderPinnedChips(contentWidth int) string {
+	line := "Pinned:"
+	for i, b := range s.pinned {
+		chip := fmt.Sprintf(" [%s ~%s]", truncateToWidth(b.Label, pinChipLabelWidth), ai.FormatTokens(b.Tokens()))
+		more := ""
+		if rest := len(s.pinned) - i - 1; rest > 0 {
+			more = fmt.Sprintf(" +%d", rest)
+		}
+		if i > 0 && ansi.StringWidth(line+chip+more) > contentWidth {
+			line += fmt.Sprintf(" +%d", len(s.pinned)-i)
+			break
+		}
+		line += chip
+	}
+	return styles.TextMutedStyle.Render(truncateToWidth(line, contentWidth))
+}
@@ -0,0 +1,47 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+)
+
+func TestSidebar_PinnedChipsAboveInput(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(50, 20)
+	s.Show()
+	heightBefore := s.viewportHeight()
+
+	s.SetPinned([]ai.PinnedBlock{
+		{Label: "make test", Content: strings.Repeat("x", 4000)},
+		{Label: "selection", Content: "12345678"},
+	})
+	if got := s.viewportHeight(); got != heightBefore-1 {
+		t.Fatalf("viewportHeight() = %d, want %d with the chip line", got, heightBefore-1)
+	}
+	view := stripANSICodes(s.View())
+	if !strings.Contains(view, "Pinned: [make test ~1.0k] [selection ~5]") {
+		t.Fatalf("expected pinned chips with token costs, got:\n%s", view)
+	}
+
+	s.SetPinned(nil)
+	if got := s.viewportHeight(); got != heightBefore {
+		t.Fatalf("viewportHeight() = %d, want %d after unpinning", got, heightBefore)
+	}
+}
+
+func TestSidebar_PinnedChipsOverflowCount(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(40, 20)
+	var blocks []ai.PinnedBlock
+	for _, label := range []string{"first command", "second command", "third command"} {
+		blocks = append(blocks, ai.PinnedBlock{Label: label, Content: "out"})
+	}
+	s.SetPinned(blocks)
+
+	line := stripANSICodes(s.renderPinnedChips(s.contentWidth()))
+	if !strings.HasPrefix(line, "Pinned: [first command ~5]") || !strings.HasSuffix(line, " +2") {
+		t.Fatalf("chip line = %q, want the first chip and a +2 overflow count", line)
+	}
+}
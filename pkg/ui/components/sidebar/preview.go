@@ -0,0 +1,15 @@
+package sidebar
+
+// previewToggleKey turns the prompt preview on or off. While it is on,
+// submitting a message shows the assembled request before it is sent.
+const previewToggleKey = "ctrl+p"
+
+// TogglePreview turns the prompt preview on or off.
+func (s *Sidebar) TogglePreview() {
+	s.preview = !s.preview
+}
+
+// PreviewEnabled reports whether messages are previewed before sending.
+func (s *Sidebar) PreviewEnabled() bool {
+	return s.preview
+}
@@ -0,0 +1,41 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSidebar_PreviewToggleMarksSubmit(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.Show()
+	s.FocusInput()
+
+	key := tea.KeyPressMsg{Code: 'p', Mod: tea.ModCtrl}
+	if !s.ShouldHandleKey(key) {
+		t.Fatal("expected sidebar to claim Ctrl+P with the input focused")
+	}
+	s.Update(key)
+	if !s.PreviewEnabled() {
+		t.Fatal("expected Ctrl+P to turn the preview on")
+	}
+	if footer := s.commandFooterText(80); !strings.HasSuffix(footer, "| Preview") {
+		t.Fatalf("expected the footer to show the preview, got %q", footer)
+	}
+
+	s.textarea.SetValue("why did make fail?")
+	cmd := s.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected Enter to submit")
+	}
+	if msg, ok := cmd().(ChatSubmitMsg); !ok || !msg.Preview {
+		t.Fatalf("expected a previewed ChatSubmitMsg, got %#v", cmd())
+	}
+
+	s.Update(key)
+	if s.PreviewEnabled() {
+		t.Fatal("expected Ctrl+P to turn the preview off again")
+	}
+}
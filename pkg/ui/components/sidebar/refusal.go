@@ -0,0 +1,30 @@
+package sidebar
+
+import "wtf_cli/pkg/ui/styles"
+
+// refusalHint tells what to do about a refused answer; the message menu
+// offers both.
+const refusalHint = "m: rephrase or try another provider"
+
+// MarkLastRefused marks the last message as a refusal by the provider, for
+// reason, e.g. "content filter".
+func (s *Sidebar) MarkLastRefused(reason string) {
+	if n := len(s.messages); n > 0 && s.messages[n-1].Role == "assistant" {
+		s.messages[n-1].Refusal = reason
+	}
+}
+
+// renderRefusal draws the banner under a refused answer, so it does not
+// read as one.
+func renderRefusal(reason string, width int) []string {
+	text := "⛔ Refused by the provider (" + reason + ") · " + refusalHint
+	if width <= 0 {
+		return []string{styles.ErrorStyle.Render(text)}
+	}
+	parts := splitByWidth(text, width)
+	lines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		lines = append(lines, styles.ErrorStyle.Render(part))
+	}
+	return lines
+}
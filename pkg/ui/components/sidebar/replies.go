@@ -0,0 +1,82 @@
+package sidebar
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// maxQuickReplies is how many replies number keys can pick.
+const maxQuickReplies = 9
+
+// replyPattern matches a quick reply offered as <reply>staging</reply>.
+var replyPattern = regexp.MustCompile(`<reply>\s*([^<\n]*?)\s*</reply>`)
+
+// ExtractReplies returns the quick replies an answer offers for its
+// clarifying question, in order and without duplicates.
+func ExtractReplies(content string) []string {
+	var replies []string
+	for _, match := range replyPattern.FindAllStringSubmatch(content, -1) {
+		reply := strings.Join(strings.Fields(match[1]), " ")
+		if reply == "" || containsReply(replies, reply) {
+			continue
+		}
+		replies = append(replies, reply)
+		if len(replies) == maxQuickReplies {
+			break
+		}
+	}
+	return replies
+}
+
+// FormatReplies replaces <reply> markers with the key that sends them, as
+// in "`1` staging".
+func FormatReplies(content string) string {
+	replies := ExtractReplies(content)
+	return replyPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		reply := strings.Join(strings.Fields(replyPattern.FindStringSubmatch(tag)[1]), " ")
+		for i, r := range replies {
+			if r == reply {
+				return "`" + strconv.Itoa(i+1) + "` " + reply
+			}
+		}
+		return reply
+	})
+}
+
+func containsReply(replies []string, reply string) bool {
+	for _, r := range replies {
+		if r == reply {
+			return true
+		}
+	}
+	return false
+}
+
+// quickReplies returns the replies the last answer offers, while they can
+// be sent: nothing is streaming and the input is empty.
+func (s *Sidebar) quickReplies() []string {
+	if s.streaming || !s.fromMessages || len(s.messages) == 0 || s.textarea.Value() != "" {
+		return nil
+	}
+	last := s.messages[len(s.messages)-1]
+	if last.Role != "assistant" {
+		return nil
+	}
+	return ExtractReplies(last.Content)
+}
+
+// quickReplyCmd sends the reply picked with number key key, or returns
+// false when key picks none.
+func (s *Sidebar) quickReplyCmd(key string) (tea.Cmd, bool) {
+	n, err := strconv.Atoi(key)
+	replies := s.quickReplies()
+	if err != nil || len(key) != 1 || n < 1 || n > len(replies) {
+		return nil, false
+	}
+	submit := ChatSubmitMsg{Content: replies[n-1], Preview: s.preview, NoHistory: s.noHistory}
+	s.noHistory = false
+	return func() tea.Msg { return submit }, true
+}
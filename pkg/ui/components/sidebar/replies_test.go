@@ -0,0 +1,97 @@
+package sidebar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestExtractReplies(t *testing.T) {
+	content := "Which environment? <reply>staging</reply> <reply> production </reply> <reply>staging</reply> <reply></reply>"
+	got := ExtractReplies(content)
+	if want := []string{"staging", "production"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExtractReplies() = %v, want %v", got, want)
+	}
+	if got := ExtractReplies("no question"); got != nil {
+		t.Fatalf("ExtractReplies() = %v, want nil", got)
+	}
+
+	var many strings.Builder
+	for i := 0; i < 12; i++ {
+		many.WriteString("<reply>r" + string(rune('a'+i)) + "</reply>")
+	}
+	if got := ExtractReplies(many.String()); len(got) != maxQuickReplies {
+		t.Fatalf("ExtractReplies() returned %d replies, want %d", len(got), maxQuickReplies)
+	}
+}
+
+func TestFormatReplies(t *testing.T) {
+	got := FormatReplies("Which one?\n- <reply>staging</reply>\n- <reply>production</reply>")
+	if want := "Which one?\n- `1` staging\n- `2` production"; got != want {
+		t.Fatalf("FormatReplies() = %q, want %q", got, want)
+	}
+}
+
+func TestSidebar_QuickReplyKeys(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.Show()
+	s.FocusInput()
+	s.SetMessages([]ai.ChatMessage{
+		{Role: "user", Content: "why did the deploy fail?"},
+		{Role: "assistant", Content: "Which environment? <reply>staging</reply> <reply>production</reply>"},
+	})
+	s.RefreshView()
+
+	if footer := s.commandFooterText(80); !strings.Contains(footer, "1-2 Reply") {
+		t.Fatalf("expected the footer to offer the replies, got %q", footer)
+	}
+	key := tea.KeyPressMsg{Code: '2', Text: "2"}
+	if !s.ShouldHandleKey(key) {
+		t.Fatal("expected sidebar to claim the reply key")
+	}
+	cmd := s.Update(key)
+	if cmd == nil {
+		t.Fatal("expected the reply key to submit")
+	}
+	if msg, ok := cmd().(ChatSubmitMsg); !ok || msg.Content != "production" {
+		t.Fatalf("expected ChatSubmitMsg for production, got %#v", cmd())
+	}
+	if s.textarea.Value() != "" {
+		t.Fatalf("expected the input to stay empty, got %q", s.textarea.Value())
+	}
+
+	// Out of range, a digit is typed as usual.
+	if cmd := s.Update(tea.KeyPressMsg{Code: '3', Text: "3"}); cmd != nil {
+		if _, ok := cmd().(ChatSubmitMsg); ok {
+			t.Fatal("expected 3 not to pick a reply")
+		}
+	}
+	if s.textarea.Value() != "3" {
+		t.Fatalf("expected 3 to be typed, got %q", s.textarea.Value())
+	}
+	// Once the user is typing, digits are part of the message.
+	if cmd := s.Update(tea.KeyPressMsg{Code: '1', Text: "1"}); cmd != nil {
+		if _, ok := cmd().(ChatSubmitMsg); ok {
+			t.Fatal("expected 1 to be typed while the input has text")
+		}
+	}
+}
+
+func TestSidebar_QuickRepliesOnlyForLastAnswer(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(80, 20)
+	s.Show()
+	s.SetMessages([]ai.ChatMessage{
+		{Role: "assistant", Content: "Which environment? <reply>staging</reply>"},
+		{Role: "user", Content: "staging"},
+	})
+	s.RefreshView()
+	if got := s.quickReplies(); got != nil {
+		t.Fatalf("expected no replies after the user answered, got %v", got)
+	}
+}
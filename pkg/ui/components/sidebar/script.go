@@ -0,0 +1,110 @@
+package sidebar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ai"
+)
+
+// scriptCommentMax caps the explanation copied above each command.
+const scriptCommentMax = 100
+
+// BuildCommandScript collects the <cmd> suggestions of the assistant messages
+// into a numbered bash script, each step commented with the sentence that
+// introduced it. Repeated commands are kept once. It returns the script and
+// the number of commands; the script is empty when there are none.
+func BuildCommandScript(messages []ai.ChatMessage, title string, now time.Time) (string, int) {
+	type step struct {
+		command string
+		comment string
+	}
+	var steps []step
+	seen := map[string]bool{}
+	for _, msg := range messages {
+		if msg.Role != "assistant" {
+			continue
+		}
+		for _, entry := range ExtractCommands(msg.Content) {
+			command, ok := SanitizeCommand(entry.Command)
+			if !ok || seen[command] {
+				continue
+			}
+			seen[command] = true
+			steps = append(steps, step{command: command, comment: commandComment(msg.Content, entry)})
+		}
+	}
+	if len(steps) == 0 {
+		return "", 0
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&sb, "# Commands suggested by wtf_cli on %s.\n", now.Format("2006-01-02 15:04"))
+	if title = strings.TrimSpace(title); title != "" {
+		fmt.Fprintf(&sb, "# Conversation: %s\n", title)
+	}
+	sb.WriteString("# Review every step before running it.\n")
+	sb.WriteString("set -euo pipefail\n")
+	for i, s := range steps {
+		sb.WriteString("\n")
+		if s.comment != "" {
+			fmt.Fprintf(&sb, "# %d. %s\n", i+1, s.comment)
+		} else {
+			fmt.Fprintf(&sb, "# %d.\n", i+1)
+		}
+		sb.WriteString(s.command + "\n")
+	}
+	return sb.String(), len(steps)
+}
+
+// commandComment returns the prose around a command: its own line when the
+// command sits in a sentence, otherwise the nearest line of text above it.
+func commandComment(content string, entry CommandEntry) string {
+	lines := strings.Split(content, "\n")
+	row := strings.Count(content[:entry.SourceIndex], "\n")
+	// Lines of unmarked commands, e.g. in a code block, are not prose.
+	commandRows := map[int]bool{}
+	for _, e := range extractUnmarkedCommands(content) {
+		commandRows[strings.Count(content[:e.SourceIndex], "\n")] = true
+	}
+
+	if !commandRows[row] && hasProse(withoutCommands(lines[row])) {
+		return cleanComment(StripCommandMarkers(lines[row]))
+	}
+	for i := row - 1; i >= 0 && i >= row-5; i-- {
+		line := strings.TrimSpace(lines[i])
+		if commandRows[i] || strings.HasPrefix(line, "```") || !hasProse(withoutCommands(line)) {
+			continue
+		}
+		return cleanComment(StripCommandMarkers(line))
+	}
+	return ""
+}
+
+// withoutCommands drops the <cmd> entries from line, leaving its prose.
+func withoutCommands(line string) string {
+	for _, entry := range ExtractCommands(line) {
+		line = strings.Replace(line, cmdOpenTag+entry.Command+cmdCloseTag, "", 1)
+	}
+	return line
+}
+
+func hasProse(s string) bool {
+	return strings.Trim(s, " \t`*-:>") != ""
+}
+
+// cleanComment flattens markdown into a single comment line.
+func cleanComment(s string) string {
+	s = strings.NewReplacer("**", "", "`", "").Replace(s)
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.TrimLeft(s, "-*> ")
+	if i := strings.Index(s, ". "); i > 0 && i <= 3 && strings.Trim(s[:i], "0123456789") == "" {
+		s = s[i+2:]
+	}
+	if runes := []rune(s); len(runes) > scriptCommentMax {
+		s = string(runes[:scriptCommentMax-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/ai"
+)
+
+func TestBuildCommandScript_NumbersAndCommentsCommands(t *testing.T) {
+	messages := []ai.ChatMessage{
+		{Role: "user", Content: "my build is broken, <cmd>rm -rf /</cmd>"},
+		{Role: "assistant", Content: "The cache is stale.\n\n1. **Clear** the build cache:\n<cmd>make clean</cmd>\n2. Then run <cmd>make build</cmd> again."},
+		{Role: "assistant", Content: "Retry with <cmd>make clean</cmd> and check `git status`:\n```\n<cmd>git status</cmd>\n```"},
+	}
+	now := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+
+	script, count := BuildCommandScript(messages, "Broken build", now)
+	if count != 3 {
+		t.Fatalf("expected 3 commands, got %d:\n%s", count, script)
+	}
+	for _, want := range []string{
+		"#!/usr/bin/env bash\n",
+		"# Commands suggested by wtf_cli on 2026-01-02 15:04.\n",
+		"# Conversation: Broken build\n",
+		"set -euo pipefail\n",
+		"# 1. Clear the build cache:\nmake clean\n",
+		"# 2. Then run make build again.\nmake build\n",
+		"# 3. Retry with make clean and check git status:\ngit status\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "rm -rf") {
+		t.Error("expected user messages to be skipped")
+	}
+	if strings.Count(script, "\nmake clean\n") != 1 {
+		t.Error("expected repeated commands to be kept once")
+	}
+}
+
+func TestBuildCommandScript_CommentsUnmarkedCommands(t *testing.T) {
+	messages := []ai.ChatMessage{
+		{Role: "assistant", Content: "Restart the service:\n```bash\nsystemctl restart nginx\nsystemctl status nginx\n```"},
+	}
+	script, count := BuildCommandScript(messages, "", time.Now())
+	if count != 2 {
+		t.Fatalf("expected 2 commands, got %d:\n%s", count, script)
+	}
+	for _, want := range []string{
+		"# 1. Restart the service:\nsystemctl restart nginx\n",
+		"# 2. Restart the service:\nsystemctl status nginx\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestBuildCommandScript_EmptyWithoutCommands(t *testing.T) {
+	script, count := BuildCommandScript([]ai.ChatMessage{{Role: "assistant", Content: "No commands here."}}, "", time.Now())
+	if script != "" || count != 0 {
+		t.Fatalf("expected no script, got %d commands:\n%s", count, script)
+	}
+}
+
+func TestCleanComment_TruncatesLongLines(t *testing.T) {
+	got := cleanComment(strings.Repeat("word ", 40))
+	if n := len([]rune(got)); n != scriptCommentMax || !strings.HasSuffix(got, "...") {
+		t.Fatalf("expected %d runes ending in ..., got %d: %q", scriptCommentMax, n, got)
+	}
+}
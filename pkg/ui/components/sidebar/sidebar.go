@@ -1,9 +1,12 @@
 package sidebar
 
 import (
+	"strconv"
 	"strings"
 
 	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/cmdrisk"
+	"wtf_cli/pkg/redact"
 	"wtf_cli/pkg/ui/components/selection"
 	"wtf_cli/pkg/ui/styles"
 
@@ -35,22 +38,42 @@ type Sidebar struct {
 	width   int
 	height  int
 	scrollY int
-	lines   []string
+	lines   []string // Rendered SetContent text; messages render into blocks
 	follow  bool
 	sel     selection.Selection
 
 	// Chat fields
-	textarea         textarea.Model   // Chat input
-	focused          FocusTarget      // Input or Viewport
-	messages         []ai.ChatMessage // Persistent conversation history
-	streaming        bool             // True while assistant response streaming
-	cmdSelectedIdx   int              // Active command index (-1 = none)
-	cmdList          []CommandEntry   // Commands extracted from assistant messages
-	cmdRawLines      []int            // Raw line indices of command entries in stripped content
-	cmdRenderedLines []int            // Rendered line indices corresponding to cmdList entries
-	cmdDirty         bool             // True when command extraction needs refresh
-	activeProvider   string           // Currently selected LLM provider
-	activeModel      string           // Currently selected LLM model
+	textarea           textarea.Model    // Chat input
+	focused            FocusTarget       // Input or Viewport
+	messages           []ai.ChatMessage  // Persistent conversation history
+	streaming          bool              // True while assistant response streaming
+	cmdSelectedIdx     int               // Active command index (-1 = none)
+	cmdList            []CommandEntry    // Commands extracted from assistant messages
+	cmdRenderedLines   []int             // Rendered line indices corresponding to cmdList entries
+	msgRenderedLines   []int             // Rendered line index where each message starts
+	fromMessages       bool              // Viewport shows messages (blocks) rather than SetContent text
+	blocks             []messageBlock    // Per-message render cache, parallel to messages
+	lineTotal          int               // Rendered line count across blocks
+	activeProvider     string            // Currently selected LLM provider
+	activeModel        string            // Currently selected LLM model
+	title              string            // Conversation title; empty shows defaultTitle
+	pendingPaste       string            // Paste held back because it looks like credentials
+	pendingKinds       []string          // Credential kinds found in pendingPaste
+	showReasoning      bool              // Reasoning is expanded in every message
+	activity           string            // What the model is doing, shown above the input
+	spinnerFrame       int               // Current frame of the activity spinner
+	typingFrame        int               // Spinner ticks into the typing indicator's cycle
+	usage              ai.Usage          // Token counts of the current answer
+	pinned             []ai.PinnedBlock  // Output blocks sent with every request
+	preview            bool              // Show the assembled request before sending
+	noHistory          bool              // Send the next message without the conversation
+	params             ai.SamplingParams // Sampling settings set for this conversation
+	paramsOpen         bool              // The params popover is shown
+	paramsField        int               // Field selected in the params popover
+	defaultTemperature float64           // Configured temperature
+	defaultMaxTokens   int               // Configured max tokens, 0 for the provider's
+	confirmCmd         string            // Destructive command waiting for a second Enter
+	workingDir         string            // Shell directory recorded on new assistant messages
 }
 
 // NewSidebar creates a new sidebar component.
@@ -65,13 +88,15 @@ func NewSidebar() *Sidebar {
 		textarea:       ta,
 		focused:        FocusInput,
 		cmdSelectedIdx: -1,
-		cmdDirty:       true,
 		activeProvider: "unknown",
 		activeModel:    "unknown",
 	}
 }
 
-const defaultTitle = "WTF Analysis"
+const (
+	defaultTitle = "WTF Analysis"
+	pinnedMarker = "[pinned] "
+)
 
 // Show makes the sidebar visible, re-rendering from message history if present.
 func (s *Sidebar) Show() {
@@ -104,18 +129,17 @@ func (s *Sidebar) SetSize(width, height int) {
 	s.height = height
 	s.reflow()
 	s.updateActiveCommand()
+	s.evictBlocks()
 }
 
 // SetContent updates the sidebar content.
 func (s *Sidebar) SetContent(content string) {
 	s.content = content
+	s.fromMessages = false
 	s.sel.Clear()
 	if len(s.messages) == 0 {
 		s.cmdList = nil
-		s.cmdRawLines = nil
-		s.cmdRenderedLines = nil
 		s.cmdSelectedIdx = -1
-		s.cmdDirty = false
 	}
 	s.reflow()
 	if s.follow {
@@ -129,6 +153,16 @@ func (s *Sidebar) ShouldHandleKey(msg tea.KeyPressMsg) bool {
 	if !s.visible {
 		return false
 	}
+	if s.pendingPaste != "" || s.paramsOpen {
+		return true
+	}
+	switch msg.String() {
+	case reasoningToggleKey, previewToggleKey, historyToggleKey, paramsToggleKey:
+		return true
+	}
+	if _, ok := s.quickReplyCmd(msg.String()); ok {
+		return true
+	}
 
 	// Handle more keys when input is focused.
 	if s.focused == FocusInput {
@@ -154,7 +188,7 @@ func (s *Sidebar) ShouldHandleKey(msg tea.KeyPressMsg) bool {
 
 	keyStr := msg.String()
 	switch keyStr {
-	case "esc", "enter", "up", "down", "pgup", "pgdown", "q", "y":
+	case "esc", "enter", "up", "down", "pgup", "pgdown", "q", "y", "m":
 		return true
 	}
 
@@ -167,6 +201,42 @@ func (s *Sidebar) Update(msg tea.KeyPressMsg) tea.Cmd {
 		return nil
 	}
 
+	if s.pendingPaste != "" {
+		s.resolvePendingPaste(msg.String())
+		return nil
+	}
+	if s.paramsOpen {
+		s.handleParamsKey(msg.String())
+		return nil
+	}
+	if msg.String() == paramsToggleKey {
+		s.ToggleParams()
+		return nil
+	}
+	if msg.String() == reasoningToggleKey {
+		s.ToggleReasoning()
+		return nil
+	}
+	if msg.String() == previewToggleKey {
+		s.TogglePreview()
+		return nil
+	}
+	if msg.String() == historyToggleKey {
+		s.ToggleHistory()
+		return nil
+	}
+	if cmd, ok := s.quickReplyCmd(msg.String()); ok {
+		return cmd
+	}
+	// Any key but Enter backs out of applying a destructive command; Esc
+	// only does that instead of closing the sidebar.
+	if s.confirmCmd != "" && msg.String() != "enter" {
+		s.confirmCmd = ""
+		if msg.String() == "esc" {
+			return nil
+		}
+	}
+
 	// Handle input focus.
 	if s.focused == FocusInput {
 		switch msg.String() {
@@ -174,10 +244,10 @@ func (s *Sidebar) Update(msg tea.KeyPressMsg) tea.Cmd {
 			if !s.streaming {
 				content, ok := s.SubmitMessage()
 				if ok && content != "" {
-					// Return ChatSubmitMsg to be handled by model.go
-					return func() tea.Msg {
-						return ChatSubmitMsg{Content: content}
-					}
+					// Return ChatSubmitMsg or ChatCommandMsg to be
+					// handled by model.go
+					msg := s.chatInputMsg(content)
+					return func() tea.Msg { return msg }
 				}
 				// When input is empty, Enter applies the selected command.
 				if s.canApplySelectedCommand() {
@@ -218,6 +288,9 @@ func (s *Sidebar) Update(msg tea.KeyPressMsg) tea.Cmd {
 
 	case "y":
 		return s.copyToClipboard()
+
+	case "m":
+		return s.messageMenuCmd()
 	}
 
 	return nil
@@ -237,6 +310,7 @@ func (s *Sidebar) handleScroll(key string) tea.Cmd {
 func (s *Sidebar) scrollViewport(key string) {
 	s.applyScroll(key)
 	s.updateActiveCommand()
+	s.evictBlocks()
 }
 
 // applyScroll mutates scrollY and follow only, without touching command selection.
@@ -351,11 +425,23 @@ func (s *Sidebar) commandVisible(idx int) bool {
 // ChatSubmitMsg is returned when the user submits a chat message.
 type ChatSubmitMsg struct {
 	Content string
+	Preview bool // Show the assembled request before sending it
+	// NoHistory sends the message without the conversation before it.
+	NoHistory bool
+	// UnknownCommand is the name of the slash command the message starts
+	// with when it is not a chat command; it is sent as text all the same.
+	UnknownCommand string
+}
+
+// MessageMenuMsg is emitted when the action menu is requested for a message.
+type MessageMenuMsg struct {
+	Index int // Index into GetMessages()
 }
 
 // CommandExecuteMsg is emitted when a selected command should be applied to PTY input.
 type CommandExecuteMsg struct {
 	Command string
+	Dir     string // Shell directory when the command was suggested
 }
 
 // View renders the sidebar.
@@ -377,7 +463,16 @@ func (s *Sidebar) renderChatView(contentWidth, contentHeight int) string {
 	lines = append(lines, s.renderTitle(contentWidth))
 	lines = append(lines, "")
 	lines = append(lines, s.renderViewport(contentWidth, viewportHeight)...)
-	lines = append(lines, strings.Repeat("─", contentWidth))
+	if len(s.pinned) > 0 {
+		lines = append(lines, s.renderPinnedChips(contentWidth))
+	}
+	if s.showsHistoryToggle() {
+		lines = append(lines, s.renderHistoryToggle(contentWidth))
+	}
+	if s.paramsOpen {
+		lines = append(lines, s.renderParams(contentWidth)...)
+	}
+	lines = append(lines, s.renderSeparator(contentWidth))
 	lines = append(lines, s.renderTextarea(contentWidth)...)
 	lines = append(lines, s.renderFooter(contentWidth))
 
@@ -395,7 +490,11 @@ func (s *Sidebar) renderChatView(contentWidth, contentHeight int) string {
 }
 
 func (s *Sidebar) renderTitle(contentWidth int) string {
-	title := truncateToWidth(defaultTitle, contentWidth)
+	title := s.title
+	if title == "" {
+		title = defaultTitle
+	}
+	title = truncateToWidth(title, contentWidth)
 	titleRendered := styles.DialogTitleStyle.Render(title)
 	fillWidth := contentWidth - lipgloss.Width(title) - 1
 	if fillWidth <= 0 {
@@ -410,10 +509,14 @@ func (s *Sidebar) renderTitle(contentWidth int) string {
 }
 
 func (s *Sidebar) renderViewport(contentWidth, viewportHeight int) []string {
-	commandLines := make(map[int]struct{}, len(s.cmdRenderedLines))
-	for _, idx := range s.cmdRenderedLines {
-		if idx >= 0 {
-			commandLines[idx] = struct{}{}
+	// A line holding several commands is badged with the riskiest.
+	commandLines := make(map[int]int, len(s.cmdRenderedLines))
+	for i, idx := range s.cmdRenderedLines {
+		if idx < 0 || i >= len(s.cmdList) {
+			continue
+		}
+		if prev, ok := commandLines[idx]; !ok || s.cmdList[i].Risk.Level > s.cmdList[prev].Risk.Level {
+			commandLines[idx] = i
 		}
 	}
 
@@ -423,15 +526,13 @@ func (s *Sidebar) renderViewport(contentWidth, viewportHeight int) []string {
 	}
 
 	lines := make([]string, 0, viewportHeight)
-	for i := s.scrollY; i < min(s.scrollY+viewportHeight, len(s.lines)); i++ {
-		line := s.lines[i]
-		if _, ok := commandLines[i]; ok {
-			plain := stripANSICodes(line)
-			if activeCommandLine == i {
-				line = styles.CommandActiveStyle.Render(plain)
-			} else {
-				line = styles.CommandStyle.Render(plain)
-			}
+	for i := s.scrollY; i < min(s.scrollY+viewportHeight, s.lineCount()); i++ {
+		line := s.lineAt(i)
+		if cmd, ok := commandLines[i]; ok {
+			line = renderCommandLine(stripANSICodes(line), s.cmdList[cmd].Risk.Level, activeCommandLine == i, contentWidth)
+		}
+		if i == s.lineCount()-1 && s.showsTypingIndicator() {
+			line = s.withTypingIndicator(line, contentWidth)
 		}
 		if left, right, ok := selection.LineBounds(s.sel, i, lipgloss.Width(line)); ok {
 			line = selection.ApplyLineHighlight(line, left, right)
@@ -444,6 +545,35 @@ func (s *Sidebar) renderViewport(contentWidth, viewportHeight int) []string {
 	return lines
 }
 
+// renderCommandLine styles a suggested command and appends its risk badge,
+// shortening the command when the badge would not fit.
+func renderCommandLine(plain string, level cmdrisk.Level, active bool, width int) string {
+	style := styles.CommandStyle
+	if active {
+		style = styles.CommandActiveStyle
+	}
+	badge := "[" + level.String() + "]"
+	plain = strings.TrimRight(plain, " ")
+	room := width - ansi.StringWidth(badge) - 1
+	if room < 1 {
+		return style.Render(plain)
+	}
+	if ansi.StringWidth(plain) > room {
+		plain = truncateToWidth(plain, room)
+	}
+	return style.Render(plain) + " " + riskBadgeStyle(level).Render(badge)
+}
+
+func riskBadgeStyle(level cmdrisk.Level) lipgloss.Style {
+	switch level {
+	case cmdrisk.Caution:
+		return styles.RiskCautionStyle
+	case cmdrisk.Destructive:
+		return styles.RiskDestructiveStyle
+	}
+	return styles.RiskSafeStyle
+}
+
 func (s *Sidebar) renderTextarea(contentWidth int) []string {
 	s.textarea.SetWidth(contentWidth)
 	textareaLines := strings.Split(s.textarea.View(), "\n")
@@ -458,6 +588,19 @@ func (s *Sidebar) renderTextarea(contentWidth int) []string {
 	return lines
 }
 
+// renderSeparator draws the line above the input, with the model's activity
+// and token counts, or the secrets warning while a paste is held back.
+func (s *Sidebar) renderSeparator(contentWidth int) string {
+	if s.pendingPaste == "" {
+		if line := s.activitySeparator(contentWidth); line != "" {
+			return line
+		}
+		return strings.Repeat("─", contentWidth)
+	}
+	warning := "⚠ Paste looks like it contains a " + strings.Join(s.pendingKinds, ", ")
+	return styles.EditStyle.Render(truncateToWidth(warning, contentWidth))
+}
+
 func (s *Sidebar) renderFooter(contentWidth int) string {
 	return styles.FooterStyle.
 		Width(contentWidth).
@@ -466,7 +609,11 @@ func (s *Sidebar) renderFooter(contentWidth int) string {
 }
 
 func (s *Sidebar) copyToClipboard() tea.Cmd {
-	return tea.SetClipboard(StripCommandMarkers(s.content))
+	content := s.content
+	if s.fromMessages {
+		content = s.RenderMessages()
+	}
+	return tea.SetClipboard(FormatReplies(FormatCitations(StripCommandMarkers(content))))
 }
 
 func (s *Sidebar) commandExecuteCmd() tea.Cmd {
@@ -476,10 +623,124 @@ func (s *Sidebar) commandExecuteCmd() tea.Cmd {
 	if s.cmdSelectedIdx < 0 || s.cmdSelectedIdx >= len(s.cmdList) {
 		return nil
 	}
-	command := s.cmdList[s.cmdSelectedIdx].Command
+	entry := s.cmdList[s.cmdSelectedIdx]
+	if entry.Risk.Level == cmdrisk.Destructive && s.confirmCmd != entry.Command {
+		s.confirmCmd = entry.Command
+		return nil
+	}
+	s.confirmCmd = ""
+	command, dir := entry.Command, entry.Dir
+	return func() tea.Msg {
+		return CommandExecuteMsg{Command: command, Dir: dir}
+	}
+}
+
+func (s *Sidebar) messageMenuCmd() tea.Cmd {
+	if s.streaming {
+		return nil
+	}
+	idx := s.FocusedMessage()
+	if idx < 0 {
+		return nil
+	}
 	return func() tea.Msg {
-		return CommandExecuteMsg{Command: command}
+		return MessageMenuMsg{Index: idx}
+	}
+}
+
+// FocusedMessage returns the index of the assistant message the viewport is
+// on: the one holding the selected command, otherwise the last assistant
+// message that starts at or above the bottom of the viewport. Returns -1 when
+// there is none.
+func (s *Sidebar) FocusedMessage() int {
+	if s.canApplySelectedCommand() {
+		if idx := s.messageAtLine(s.cmdRenderedLines[s.cmdSelectedIdx]); idx >= 0 && s.messages[idx].Role == "assistant" {
+			return idx
+		}
+	}
+	bottom := s.scrollY + s.viewportHeight() - 1
+	best := -1
+	for i, msg := range s.messages {
+		if msg.Role != "assistant" || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		if i < len(s.msgRenderedLines) && s.msgRenderedLines[i] > bottom {
+			break
+		}
+		best = i
+	}
+	return best
+}
+
+// messageAtLine returns the index of the message covering rendered line.
+func (s *Sidebar) messageAtLine(line int) int {
+	idx := -1
+	for i, start := range s.msgRenderedLines {
+		if start < 0 {
+			continue
+		}
+		if start > line {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+// MessageAt returns the message at idx.
+func (s *Sidebar) MessageAt(idx int) (ai.ChatMessage, bool) {
+	if idx < 0 || idx >= len(s.messages) {
+		return ai.ChatMessage{}, false
+	}
+	return s.messages[idx], true
+}
+
+// MessageCommands returns the <cmd> commands in the message at idx.
+func (s *Sidebar) MessageCommands(idx int) []string {
+	msg, ok := s.MessageAt(idx)
+	if !ok {
+		return nil
+	}
+	var cmds []string
+	for _, entry := range ExtractCommands(msg.Content) {
+		if cmd, ok := SanitizeCommand(entry.Command); ok {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// MessageCitations returns the terminal lines cited in the message at idx.
+func (s *Sidebar) MessageCitations(idx int) []int {
+	msg, ok := s.MessageAt(idx)
+	if !ok {
+		return nil
+	}
+	return ExtractCitations(msg.Content)
+}
+
+// ToggleMessagePin flips the pinned flag of the message at idx and returns
+// the new state.
+func (s *Sidebar) ToggleMessagePin(idx int) bool {
+	if idx < 0 || idx >= len(s.messages) {
+		return false
+	}
+	s.messages[idx].Pinned = !s.messages[idx].Pinned
+	return s.messages[idx].Pinned
+}
+
+// PromptFor returns the history that produced the assistant message at idx,
+// ending with the user message that asked for it.
+func (s *Sidebar) PromptFor(idx int) ([]ai.ChatMessage, bool) {
+	if idx < 0 || idx >= len(s.messages) {
+		return nil, false
+	}
+	for i := idx - 1; i >= 0; i-- {
+		if s.messages[i].Role == "user" {
+			return append([]ai.ChatMessage(nil), s.messages[:i+1]...), true
+		}
 	}
+	return nil, false
 }
 
 // ToggleFocus switches focus between viewport and input.
@@ -534,6 +795,17 @@ func (s *Sidebar) SetActiveLLM(provider, model string) {
 	s.activeModel = model
 }
 
+// SetTitle sets the conversation title shown in the header. An empty title
+// restores the default.
+func (s *Sidebar) SetTitle(title string) {
+	s.title = strings.TrimSpace(title)
+}
+
+// Title returns the conversation title, or "" if none has been set.
+func (s *Sidebar) Title() string {
+	return s.title
+}
+
 // ActiveLLMLabel returns the formatted footer label for the active provider/model.
 func (s *Sidebar) ActiveLLMLabel() string {
 	return "LLM: " + s.activeProvider + "-" + s.activeModel
@@ -545,7 +817,11 @@ func (s *Sidebar) AppendUserMessage(content string) {
 		Role:    "user",
 		Content: content,
 	})
-	s.cmdDirty = true
+}
+
+// SetWorkingDir sets the shell directory new assistant messages record.
+func (s *Sidebar) SetWorkingDir(dir string) {
+	s.workingDir = dir
 }
 
 // StartAssistantMessage creates a new empty assistant message.
@@ -553,8 +829,8 @@ func (s *Sidebar) StartAssistantMessage() {
 	s.messages = append(s.messages, ai.ChatMessage{
 		Role:    "assistant",
 		Content: "",
+		Dir:     s.workingDir,
 	})
-	s.cmdDirty = true
 }
 
 // StartAssistantMessageWithContent creates a new assistant message with content.
@@ -562,8 +838,8 @@ func (s *Sidebar) StartAssistantMessageWithContent(content string) {
 	s.messages = append(s.messages, ai.ChatMessage{
 		Role:    "assistant",
 		Content: content,
+		Dir:     s.workingDir,
 	})
-	s.cmdDirty = true
 }
 
 // AppendErrorMessage adds an error message to the chat.
@@ -572,14 +848,12 @@ func (s *Sidebar) AppendErrorMessage(errMsg string) {
 		Role:    "assistant",
 		Content: MessagePrefix("error") + errMsg,
 	})
-	s.cmdDirty = true
 }
 
 // UpdateLastMessage appends delta to the last assistant message.
 func (s *Sidebar) UpdateLastMessage(delta string) {
 	if len(s.messages) > 0 {
 		s.messages[len(s.messages)-1].Content += delta
-		s.cmdDirty = true
 	}
 }
 
@@ -587,7 +861,6 @@ func (s *Sidebar) UpdateLastMessage(delta string) {
 func (s *Sidebar) SetLastMessageContent(content string) {
 	if len(s.messages) > 0 {
 		s.messages[len(s.messages)-1].Content = content
-		s.cmdDirty = true
 	}
 }
 
@@ -595,10 +868,22 @@ func (s *Sidebar) SetLastMessageContent(content string) {
 func (s *Sidebar) RemoveLastMessage() {
 	if len(s.messages) > 0 {
 		s.messages = s.messages[:len(s.messages)-1]
-		s.cmdDirty = true
 	}
 }
 
+// SetReadOnly marks the input as unavailable, for mirrored sessions.
+func (s *Sidebar) SetReadOnly() {
+	s.textarea.Placeholder = "Read-only: observing a shared session"
+	s.BlurInput()
+}
+
+// SetMessages replaces the chat history, e.g. to mirror another session.
+// Params set for the conversation it replaces go back to the defaults.
+func (s *Sidebar) SetMessages(messages []ai.ChatMessage) {
+	s.messages = append([]ai.ChatMessage(nil), messages...)
+	s.params = ai.SamplingParams{}
+}
+
 // GetMessages returns the chat message history.
 func (s *Sidebar) GetMessages() []ai.ChatMessage {
 	return s.messages
@@ -614,17 +899,23 @@ func (s *Sidebar) SubmitMessage() (string, bool) {
 	return content, true
 }
 
+// SetInput replaces the input text, e.g. to give back a message that was not
+// sent after all.
+func (s *Sidebar) SetInput(content string) {
+	s.textarea.SetValue(content)
+}
+
 // RefreshView re-renders the viewport from messages.
 func (s *Sidebar) RefreshView() {
-	s.content = s.RenderMessages()
+	s.content = ""
+	s.fromMessages = true
 	s.sel.Clear()
 	s.reflow()
 	if s.follow {
 		s.scrollY = s.maxScroll()
-		s.updateActiveCommand()
-		return
 	}
 	s.updateActiveCommand()
+	s.evictBlocks()
 }
 
 // RenderMessages renders all messages as markdown.
@@ -637,22 +928,57 @@ func (s *Sidebar) RenderMessages() string {
 		if msg.Role == "user" {
 			// Add separator line before user messages for readability
 			if i > 0 {
-				sb.WriteString("───────────────────────\n\n")
+				sb.WriteString(userSeparator + "\n\n")
 			}
 			sb.WriteString(MessagePrefix("user"))
 		} else {
 			sb.WriteString(MessagePrefix("assistant"))
 		}
+		if msg.Pinned {
+			sb.WriteString(pinnedMarker)
+		}
+		if msg.Fresh {
+			sb.WriteString(freshMarker)
+		}
 		sb.WriteString(msg.Content)
 	}
 	return sb.String()
 }
 
-// HandlePaste routes paste content to the textarea.
+// HandlePaste routes paste content to the textarea. Content that looks like
+// credentials is held back behind a warning until the user decides.
 func (s *Sidebar) HandlePaste(content string) {
-	if s.focused == FocusInput {
-		s.textarea.InsertString(content)
+	if s.focused != FocusInput {
+		return
+	}
+	if kinds := redact.Kinds(content); len(kinds) > 0 {
+		s.pendingPaste = content
+		s.pendingKinds = kinds
+		return
+	}
+	s.textarea.InsertString(content)
+}
+
+// HasPendingPaste reports whether a paste is waiting on the secrets warning.
+func (s *Sidebar) HasPendingPaste() bool {
+	return s.pendingPaste != ""
+}
+
+// resolvePendingPaste handles the secrets warning: r inserts the paste with
+// credentials masked, s inserts it unchanged, c or Esc drops it. Other keys
+// are swallowed so nothing is typed while the warning is up.
+func (s *Sidebar) resolvePendingPaste(key string) {
+	switch key {
+	case "r":
+		s.textarea.InsertString(redact.Redact(s.pendingPaste))
+	case "s":
+		s.textarea.InsertString(s.pendingPaste)
+	case "c", "esc":
+	default:
+		return
 	}
+	s.pendingPaste = ""
+	s.pendingKinds = nil
 }
 
 // HandleWheel handles mouse wheel scrolling.
@@ -685,7 +1011,7 @@ func (s *Sidebar) SelectionPoint(screenX, screenY, originX int) (row, col int, o
 	}
 
 	lineRow := s.scrollY + viewportRow
-	if lineRow < 0 || lineRow >= len(s.lines) {
+	if lineRow < 0 || lineRow >= s.lineCount() {
 		return 0, 0, false
 	}
 	return lineRow, contentCol, true
@@ -710,7 +1036,7 @@ func (s *Sidebar) FinishSelection() string {
 		return ""
 	}
 	s.sel.Finish()
-	text := selection.ExtractText(s.lines, s.sel)
+	text := s.selectedText()
 	s.sel.Clear()
 	return text
 }
@@ -725,61 +1051,26 @@ func (s *Sidebar) HasActiveSelection() bool {
 	return s.sel.Active
 }
 
-// RefreshCommands rebuilds extracted command metadata when command state is dirty.
-func (s *Sidebar) RefreshCommands() {
-	if !s.cmdDirty {
-		return
-	}
-
-	s.cmdList = s.cmdList[:0]
-	s.cmdRawLines = s.cmdRawLines[:0]
-
-	if len(s.messages) == 0 {
-		s.cmdDirty = false
-		s.cmdSelectedIdx = -1
-		return
-	}
-
-	currentLine := 0
-	for i, msg := range s.messages {
-		if i > 0 {
-			currentLine += 2 // blank line spacing between messages
-		}
-		if msg.Role == "user" && i > 0 {
-			currentLine += 2 // separator + blank line before user messages
-		}
-
-		if msg.Role == "assistant" {
-			entries := ExtractCommands(msg.Content)
-			for _, entry := range entries {
-				lineOffset := 0
-				if entry.SourceIndex > 0 && entry.SourceIndex <= len(msg.Content) {
-					lineOffset = strings.Count(msg.Content[:entry.SourceIndex], "\n")
-				}
-				s.cmdList = append(s.cmdList, entry)
-				s.cmdRawLines = append(s.cmdRawLines, currentLine+lineOffset)
-			}
-		}
-
-		currentLine += strings.Count(msg.Content, "\n")
-	}
-
-	s.cmdDirty = false
-}
-
 func (s *Sidebar) reflow() {
 	width := s.contentWidth()
 	if width <= 0 {
 		s.lines = nil
+		s.blocks = nil
+		s.lineTotal = 0
 		s.scrollY = 0
 		s.cmdRenderedLines = nil
+		s.msgRenderedLines = nil
 		s.cmdSelectedIdx = -1
 		return
 	}
 
-	s.RefreshCommands()
-	content := StripCommandMarkers(s.content)
-	s.lines, s.cmdRenderedLines = renderMarkdownWithCommandLines(content, width, s.cmdRawLines)
+	if s.fromMessages {
+		s.reflowMessages(width)
+	} else {
+		s.lines = renderMarkdown(FormatReplies(FormatCitations(StripCommandMarkers(s.content))), width)
+		s.cmdRenderedLines = nil
+		s.msgRenderedLines = nil
+	}
 
 	if s.scrollY > s.maxScroll() {
 		s.scrollY = s.maxScroll()
@@ -810,7 +1101,7 @@ func (s *Sidebar) contentHeight() int {
 func (s *Sidebar) maxScroll() int {
 	viewportHeight := s.viewportHeight()
 
-	max := len(s.lines) - viewportHeight
+	max := s.lineCount() - viewportHeight
 	if max < 0 {
 		return 0
 	}
@@ -818,7 +1109,15 @@ func (s *Sidebar) maxScroll() int {
 }
 
 func (s *Sidebar) chromeLines() int {
-	return 1 + 1 + 1 + sidebarTextareaH + 1 // title + empty line + separator + textarea + footer
+	lines := 1 + 1 + 1 + sidebarTextareaH + 1 // title + empty line + separator + textarea + footer
+	if len(s.pinned) > 0 {
+		lines++ // pinned chips
+	}
+	if s.showsHistoryToggle() {
+		lines++ // history checkbox
+	}
+	lines += s.paramsLines()
+	return lines
 }
 
 func (s *Sidebar) viewportHeight() int {
@@ -830,7 +1129,23 @@ func (s *Sidebar) viewportHeight() int {
 }
 
 func (s *Sidebar) commandFooterText(contentWidth int) string {
+	if s.pendingPaste != "" {
+		return "r Redact before send | s Send as-is | Esc Cancel"
+	}
 	label := s.ActiveLLMLabel()
+	if params := s.paramsLabel(); params != "" {
+		label += " (" + params + ")"
+	}
+	if s.preview {
+		label += " | Preview"
+	}
+	if n := len(s.quickReplies()); n > 0 {
+		label += " | 1-" + strconv.Itoa(n) + " Reply"
+	}
+	if s.awaitingConfirm() {
+		risk := s.cmdList[s.cmdSelectedIdx].Risk
+		return "⚠ Destructive (" + risk.Reason + "): Enter again to apply | Esc Cancel"
+	}
 	if s.canApplySelectedCommand() {
 		hint := "Enter Apply | Up/Down Navigate | Shift+Tab TTY | Ctrl+T Hide"
 		full := label + " | " + hint
@@ -842,6 +1157,12 @@ func (s *Sidebar) commandFooterText(contentWidth int) string {
 	return label
 }
 
+// awaitingConfirm reports whether the selected command is destructive and
+// Enter was pressed once on it.
+func (s *Sidebar) awaitingConfirm() bool {
+	return s.confirmCmd != "" && s.canApplySelectedCommand() && s.cmdList[s.cmdSelectedIdx].Command == s.confirmCmd
+}
+
 func (s *Sidebar) commandSelectionEnabled() bool {
 	return !s.streaming && s.textarea.Value() == "" && len(s.cmdList) > 0
 }
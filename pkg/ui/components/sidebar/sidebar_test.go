@@ -112,3 +112,22 @@ func TestSidebarFinishSelectionExtractsText(t *testing.T) {
 		t.Fatal("expected selection to be inactive after finish")
 	}
 }
+
+func TestSidebar_TitleReplacesDefault(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(60, 20)
+	s.Show()
+
+	if view := s.View(); !strings.Contains(view, defaultTitle) {
+		t.Fatalf("Expected default title in header, got:\n%s", view)
+	}
+
+	s.SetTitle("  Fixing Docker Permissions ")
+	if s.Title() != "Fixing Docker Permissions" {
+		t.Fatalf("Expected trimmed title, got %q", s.Title())
+	}
+	view := s.View()
+	if !strings.Contains(view, "Fixing Docker Permissions") || strings.Contains(view, defaultTitle) {
+		t.Fatalf("Expected conversation title in header, got:\n%s", view)
+	}
+}
@@ -0,0 +1,21 @@
+package sidebar
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestSidebarSnapshot(t *testing.T) {
+	sizes := []testutils.Size{testutils.SizeSmall, testutils.SizeNormal}
+	testutils.RequireSnapshots(t, sizes, testutils.Profiles, func(width, height int) string {
+		s := NewSidebar()
+		s.SetActiveLLM("openai", "gpt-4o")
+		s.Show()
+		s.SetSize(width, height)
+		s.AppendUserMessage("Why did `make test` fail?")
+		s.StartAssistantMessageWithContent("The **build** failed before the tests ran. Run:\n\n```bash\ngo build ./...\n```")
+		s.RefreshView()
+		return s.View()
+	})
+}
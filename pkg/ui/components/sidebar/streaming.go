@@ -0,0 +1,114 @@
+package sidebar
+
+import (
+	"strings"
+
+	"wtf_cli/pkg/ui/styles"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// While an answer streams in, its markdown is unfinished: the last line is
+// cut mid-token and a table at the end may still be growing. Rendering it as
+// is makes markers flash up and table columns jump with every delta, so the
+// streaming message holds those parts back until they are complete.
+
+// typingFrames animate the indicator after the last line of a streaming
+// answer, one frame per typingFrameTicks spinner ticks.
+var typingFrames = []string{"·  ", "·· ", "···"}
+
+const typingFrameTicks = 3
+
+// stabilizeStreaming splits the content of a streaming message into the
+// part rendered as markdown and a trailing table still being written, which
+// is shown raw so its columns settle once. On the unfinished last line,
+// trailing "*" and "`" markers and a tag still being opened ("<cm") are
+// held back, and a line starting with "`" is held back whole: it may be the
+// start of a code fence.
+func stabilizeStreaming(content string) (stable, pending string) {
+	complete, partial := "", content
+	if i := strings.LastIndexByte(content, '\n'); i >= 0 {
+		complete, partial = content[:i+1], content[i+1:]
+	}
+	if i := strings.LastIndexByte(partial, '<'); i >= 0 && isTagStart(partial[i+1:]) {
+		partial = partial[:i]
+	}
+	partial = strings.TrimRight(partial, "*`")
+	if strings.HasPrefix(strings.TrimSpace(partial), "`") {
+		partial = ""
+	}
+
+	lines := strings.Split(complete+partial, "\n")
+	if partial == "" {
+		lines = lines[:len(lines)-1] // Nothing after the last newline yet
+	}
+	start := len(lines)
+	for start > 0 && isTableRow(lines[start-1]) {
+		start--
+	}
+	// The first line shares its row with the message prefix, and rows
+	// inside an unclosed code fence are code, not a table.
+	if start == 0 || start == len(lines) || inCodeFence(lines[:start]) {
+		return complete + partial, ""
+	}
+	return strings.Join(lines[:start], "\n") + "\n", strings.Join(lines[start:], "\n")
+}
+
+// isTagStart reports whether s, following a "<", may still become one of
+// the tags answers use, such as <cmd>, </line> or <reply>.
+func isTagStart(s string) bool {
+	s = strings.TrimPrefix(s, "/")
+	if len(s) > len("reply") {
+		return false
+	}
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// inCodeFence reports whether lines leave a code fence open.
+func inCodeFence(lines []string) bool {
+	open := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			open = !open
+		}
+	}
+	return open
+}
+
+// renderRawLines renders content as plain, muted text wrapped at width.
+func renderRawLines(content string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if width <= 0 || line == "" {
+			lines = append(lines, styles.TextMutedStyle.Render(line))
+			continue
+		}
+		for _, part := range splitByWidth(line, width) {
+			lines = append(lines, styles.TextMutedStyle.Render(part))
+		}
+	}
+	return lines
+}
+
+// withTypingIndicator appends the current typing frame to line when it
+// fits in width.
+func (s *Sidebar) withTypingIndicator(line string, width int) string {
+	frame := typingFrames[s.typingFrame/typingFrameTicks]
+	if ansi.StringWidth(line)+1+ansi.StringWidth(frame) > width {
+		return line
+	}
+	return line + " " + styles.TextMutedStyle.Render(frame)
+}
+
+// showsTypingIndicator reports whether an answer is streaming text, as
+// opposed to running a tool or reasoning, which the activity line shows.
+func (s *Sidebar) showsTypingIndicator() bool {
+	return s.streaming && s.activity == "" && s.fromMessages &&
+		len(s.messages) > 0 && s.messages[len(s.messages)-1].Role == "assistant"
+}
@@ -0,0 +1,57 @@
+package sidebar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStabilizeStreaming(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		stable, pending string
+	}{
+		{"plain", "Hello wor", "Hello wor", ""},
+		{"half bold marker", "Run it **now*", "Run it **now", ""},
+		{"tag being opened", "Try <cm", "Try ", ""},
+		{"comparison kept", "if x < 5 then", "if x < 5 then", ""},
+		{"fence being typed", "Run:\n``", "Run:\n", ""},
+		{"half table", "Results:\n| a | b |\n|---|---|\n| 1 | 2", "Results:\n", "| a | b |\n|---|---|\n| 1 | 2"},
+		{"finished table", "Results:\n| a | b |\n\nDone", "Results:\n| a | b |\n\nDone", ""},
+		{"pipes in code", "```\n| a | b |\n", "```\n| a | b |\n", ""},
+	}
+	for _, tt := range tests {
+		stable, pending := stabilizeStreaming(tt.content)
+		if stable != tt.stable || pending != tt.pending {
+			t.Errorf("%s: stabilizeStreaming(%q) = %q, %q; want %q, %q", tt.name, tt.content, stable, pending, tt.stable, tt.pending)
+		}
+	}
+}
+
+func TestSidebar_StreamingHoldsTableRawUntilDone(t *testing.T) {
+	s := NewSidebar()
+	s.SetSize(60, 20)
+	s.Show()
+	s.AppendUserMessage("compare")
+	s.StartAssistantMessageWithContent("Results:\n| name | size |\n|---|---|\n| a | 1")
+	s.SetStreaming(true)
+	s.RefreshView()
+
+	view := stripANSICodes(s.View())
+	if !strings.Contains(view, "|---|---|") {
+		t.Fatalf("Expected the unfinished table shown raw, got %q", view)
+	}
+	if !strings.Contains(view, "·") {
+		t.Fatalf("Expected a typing indicator while streaming, got %q", view)
+	}
+
+	s.SetStreaming(false)
+	s.RefreshView()
+	view = stripANSICodes(s.View())
+	if strings.Contains(view, "|---|---|") {
+		t.Fatalf("Expected the table rendered once streaming ended, got %q", view)
+	}
+	if strings.Contains(view, "·") {
+		t.Fatalf("Expected no typing indicator after streaming, got %q", view)
+	}
+}
@@ -97,6 +97,8 @@ func MessagePrefix(role string) string {
 		return "**Tool:** "
 	case "error":
 		return "Error: "
+	case "notice":
+		return "**Note:** "
 	default:
 		return "**Assistant:** "
 	}
@@ -1,9 +1,12 @@
 package statusbar
 
 import (
+	"fmt"
 	"strings"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // ResolveGitBranch returns the branch name (or short SHA for detached HEAD)
@@ -25,7 +28,11 @@ func ResolveGitBranch(dir string) string {
 	if err != nil {
 		return ""
 	}
+	return branchLabel(head)
+}
 
+// branchLabel names head's branch, or its short SHA when detached.
+func branchLabel(head *plumbing.Reference) string {
 	if head.Name().IsBranch() {
 		return head.Name().Short()
 	}
@@ -36,3 +43,117 @@ func ResolveGitBranch(dir string) string {
 	}
 	return hash
 }
+
+// maxAheadBehind bounds the commits walked to count ahead/behind, so a
+// branch far from its upstream does not stall the resolver.
+const maxAheadBehind = 999
+
+// GitStatus is the state of a repository shown in the status bar.
+type GitStatus struct {
+	Branch string // Branch name, or short SHA for a detached HEAD
+	Dirty  bool   // Uncommitted changes or untracked files
+	Ahead  int    // Commits on the branch not on its upstream
+	Behind int    // Commits on the upstream not on the branch
+}
+
+// Label renders the status as shown in the status bar, e.g. "main* ↑2 ↓1".
+func (s GitStatus) Label() string {
+	if s.Branch == "" {
+		return ""
+	}
+	label := s.Branch
+	if s.Dirty {
+		label += "*"
+	}
+	if s.Ahead > 0 {
+		label += fmt.Sprintf(" ↑%d", s.Ahead)
+	}
+	if s.Behind > 0 {
+		label += fmt.Sprintf(" ↓%d", s.Behind)
+	}
+	return label
+}
+
+// ResolveGitStatus returns the branch, working tree state and distance from
+// the upstream branch of the repository containing dir. It reads the
+// working tree, so call it off the UI thread; GitCache keeps the result.
+func ResolveGitStatus(dir string) GitStatus {
+	trimmed := strings.TrimSpace(dir)
+	if trimmed == "" {
+		return GitStatus{}
+	}
+	repo, err := git.PlainOpenWithOptions(trimmed, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		return GitStatus{}
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return GitStatus{}
+	}
+
+	status := GitStatus{Branch: branchLabel(head)}
+	if wt, err := repo.Worktree(); err == nil {
+		if st, err := wt.Status(); err == nil {
+			status.Dirty = !st.IsClean()
+		}
+	}
+	if head.Name().IsBranch() {
+		status.Ahead, status.Behind = aheadBehind(repo, head)
+	}
+	return status
+}
+
+// aheadBehind counts the commits between head and the upstream configured
+// for its branch. Both are 0 when the branch has no upstream.
+func aheadBehind(repo *git.Repository, head *plumbing.Reference) (ahead, behind int) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return 0, 0
+	}
+	branch, ok := cfg.Branches[head.Name().Short()]
+	if !ok || branch.Remote == "" || branch.Merge == "" {
+		return 0, 0
+	}
+	upstreamName := plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+	upstream, err := repo.Reference(upstreamName, true)
+	if err != nil {
+		return 0, 0
+	}
+	if upstream.Hash() == head.Hash() {
+		return 0, 0
+	}
+
+	local, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return 0, 0
+	}
+	remote, err := repo.CommitObject(upstream.Hash())
+	if err != nil {
+		return 0, 0
+	}
+	bases, err := local.MergeBase(remote)
+	if err != nil {
+		return 0, 0
+	}
+	ignore := make([]plumbing.Hash, 0, len(bases))
+	for _, base := range bases {
+		ignore = append(ignore, base.Hash)
+	}
+	return countCommits(local, ignore), countCommits(remote, ignore)
+}
+
+// countCommits counts the commits reachable from c, stopping at ignore.
+func countCommits(c *object.Commit, ignore []plumbing.Hash) int {
+	iter := object.NewCommitPreorderIter(c, nil, ignore)
+	defer iter.Close()
+	n := 0
+	for n < maxAheadBehind {
+		if _, err := iter.Next(); err != nil {
+			break
+		}
+		n++
+	}
+	return n
+}
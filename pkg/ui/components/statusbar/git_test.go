@@ -7,6 +7,7 @@ import (
 	"time"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -102,3 +103,69 @@ func TestResolveGitBranch_NestedSubdir(t *testing.T) {
 		t.Fatalf("ResolveGitBranch() = %q, want %q", got, want)
 	}
 }
+
+func TestResolveGitStatus_DirtyAheadBehind(t *testing.T) {
+	dir, repo, base := initTestRepo(t)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Head() failed: %v", err)
+	}
+	branch := head.Name()
+
+	if got := ResolveGitStatus(dir); got != (GitStatus{Branch: branch.Short()}) {
+		t.Fatalf("ResolveGitStatus() = %+v, want a clean branch", got)
+	}
+
+	// Track origin/<branch> at the first commit, then commit locally.
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("Config() failed: %v", err)
+	}
+	cfg.Branches[branch.Short()] = &config.Branch{Name: branch.Short(), Remote: "origin", Merge: branch}
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("SetConfig() failed: %v", err)
+	}
+	remote := plumbing.NewRemoteReferenceName("origin", branch.Short())
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(remote, base)); err != nil {
+		t.Fatalf("SetReference() failed: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree() failed: %v", err)
+	}
+	if _, err := worktree.Commit("second", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	got := ResolveGitStatus(dir)
+	want := GitStatus{Branch: branch.Short(), Dirty: true, Ahead: 1}
+	if got != want {
+		t.Fatalf("ResolveGitStatus() = %+v, want %+v", got, want)
+	}
+	if label := got.Label(); label != branch.Short()+"* ↑1" {
+		t.Fatalf("Label() = %q", label)
+	}
+}
+
+func TestGitStatus_Label(t *testing.T) {
+	tests := []struct {
+		status GitStatus
+		want   string
+	}{
+		{GitStatus{}, ""},
+		{GitStatus{Branch: "main"}, "main"},
+		{GitStatus{Branch: "main", Behind: 3}, "main ↓3"},
+		{GitStatus{Branch: "main", Dirty: true, Ahead: 2, Behind: 1}, "main* ↑2 ↓1"},
+	}
+	for _, tt := range tests {
+		if got := tt.status.Label(); got != tt.want {
+			t.Errorf("%+v.Label() = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package statusbar
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// gitStatusMaxAge bounds how long a cached status is trusted. Edits to the
+// working tree are not watched, so a dirty flag set by an editor outside
+// the shell shows up within this time.
+const gitStatusMaxAge = 30 * time.Second
+
+// GitCache resolves the git status of directories, keeping one result per
+// repository. A result is resolved again when git changes HEAD, the index
+// or a branch (seen by watching the git directory), when Invalidate is
+// called, or once it is older than gitStatusMaxAge. While a repository is
+// being resolved, callers get its previous status instead of waiting.
+type GitCache struct {
+	resolve func(root string) GitStatus
+	now     func() time.Time
+
+	mu      sync.Mutex
+	roots   map[string]gitRoot   // Directory -> repository that contains it
+	repos   map[string]*gitEntry // Repository root -> cached status
+	watched map[string]string    // Watched git directory -> repository root
+	watcher *fsnotify.Watcher    // Nil until a repository is found, or when watching failed
+	noWatch bool                 // Creating the watcher failed; rely on the max age
+}
+
+// gitRoot caches where the repository of a directory is; root is "" outside
+// a repository.
+type gitRoot struct {
+	root string
+	at   time.Time
+}
+
+type gitEntry struct {
+	status    GitStatus
+	at        time.Time
+	valid     bool // status has been resolved at least once
+	stale     bool // Git changed since status was resolved
+	resolving bool
+}
+
+var (
+	sharedGitCache     *GitCache
+	sharedGitCacheOnce sync.Once
+)
+
+// SharedGitCache returns the cache used by every pane, so a repository is
+// watched and resolved once however many panes show it.
+func SharedGitCache() *GitCache {
+	sharedGitCacheOnce.Do(func() {
+		sharedGitCache = NewGitCache(ResolveGitStatus)
+	})
+	return sharedGitCache
+}
+
+// NewGitCache returns a cache resolving statuses with resolve.
+func NewGitCache(resolve func(root string) GitStatus) *GitCache {
+	return &GitCache{
+		resolve: resolve,
+		now:     time.Now,
+		roots:   make(map[string]gitRoot),
+		repos:   make(map[string]*gitEntry),
+		watched: make(map[string]string),
+	}
+}
+
+// Label returns the status bar label for dir, "" outside a repository.
+// It matches the resolver signature the UI model expects.
+func (c *GitCache) Label(dir string) string {
+	return c.Status(dir).Label()
+}
+
+// Status returns the git status of the repository containing dir.
+func (c *GitCache) Status(dir string) GitStatus {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return GitStatus{}
+	}
+	root := c.rootFor(dir)
+	if root == "" {
+		return GitStatus{}
+	}
+
+	c.mu.Lock()
+	entry, ok := c.repos[root]
+	if !ok {
+		entry = &gitEntry{}
+		c.repos[root] = entry
+		c.watchLocked(root)
+	}
+	fresh := entry.valid && !entry.stale && c.now().Sub(entry.at) < gitStatusMaxAge
+	if fresh || entry.resolving {
+		status := entry.status
+		c.mu.Unlock()
+		return status
+	}
+	entry.resolving = true
+	entry.stale = false
+	c.mu.Unlock()
+
+	status := c.resolve(root)
+
+	c.mu.Lock()
+	entry.status = status
+	entry.at = c.now()
+	entry.valid = true
+	entry.resolving = false
+	c.mu.Unlock()
+	return status
+}
+
+// Invalidate makes the next Status call for dir's repository resolve it
+// again, e.g. after a command that may have changed the working tree.
+func (c *GitCache) Invalidate(dir string) {
+	dir = strings.TrimSpace(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.roots[dir]
+	if !ok {
+		return
+	}
+	if r.root == "" {
+		delete(c.roots, dir) // The command may have run `git init`
+		return
+	}
+	if entry, ok := c.repos[r.root]; ok {
+		entry.stale = true
+	}
+}
+
+// Close stops watching git directories.
+func (c *GitCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watcher == nil {
+		return nil
+	}
+	err := c.watcher.Close()
+	c.watcher = nil
+	c.watched = make(map[string]string)
+	return err
+}
+
+// rootFor returns the root of the repository containing dir, "" when there
+// is none. Lookups are cached for gitStatusMaxAge.
+func (c *GitCache) rootFor(dir string) string {
+	c.mu.Lock()
+	r, ok := c.roots[dir]
+	c.mu.Unlock()
+	if ok && c.now().Sub(r.at) < gitStatusMaxAge {
+		return r.root
+	}
+
+	root := findGitRoot(dir)
+	c.mu.Lock()
+	c.roots[dir] = gitRoot{root: root, at: c.now()}
+	c.mu.Unlock()
+	return root
+}
+
+// findGitRoot walks up from dir to the first directory holding .git.
+func findGitRoot(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// gitDirs returns the git directories to watch for root: the one holding
+// HEAD and the index, and the one holding branches. They differ in linked
+// worktrees, whose .git file points at the first.
+func gitDirs(root string) []string {
+	gitDir := filepath.Join(root, ".git")
+	if data, err := os.ReadFile(gitDir); err == nil {
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			return nil
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(root, gitDir)
+		}
+	}
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	dirs := []string{gitDir, filepath.Join(commonDir, "refs", "heads")}
+	if commonDir != gitDir {
+		dirs = append(dirs, commonDir)
+	}
+	remotes := filepath.Join(commonDir, "refs", "remotes")
+	if entries, err := os.ReadDir(remotes); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				dirs = append(dirs, filepath.Join(remotes, e.Name()))
+			}
+		}
+	}
+	return dirs
+}
+
+// watchLocked starts watching root's git directories. Branches nested in
+// subdirectories (feature/x) are not watched and rely on the max age.
+func (c *GitCache) watchLocked(root string) {
+	if c.noWatch {
+		return
+	}
+	if c.watcher == nil {
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			slog.Warn("git_watch_unavailable", "error", err)
+			c.noWatch = true
+			return
+		}
+		c.watcher = w
+		go c.watchEvents(w)
+	}
+	for _, dir := range gitDirs(root) {
+		if _, ok := c.watched[dir]; ok {
+			continue
+		}
+		if err := c.watcher.Add(dir); err != nil {
+			slog.Debug("git_watch_add_error", "dir", dir, "error", err)
+			continue
+		}
+		c.watched[dir] = root
+	}
+}
+
+// watchEvents marks a repository stale whenever one of its watched git
+// directories changes.
+func (c *GitCache) watchEvents(w *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			c.mu.Lock()
+			if root, ok := c.watched[filepath.Dir(ev.Name)]; ok {
+				if entry, ok := c.repos[root]; ok {
+					entry.stale = true
+				}
+			}
+			c.mu.Unlock()
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			slog.Debug("git_watch_error", "error", err)
+		}
+	}
+}
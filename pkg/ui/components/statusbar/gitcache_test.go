@@ -0,0 +1,82 @@
+package statusbar
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCountingGitCache(t *testing.T) (*GitCache, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	c := NewGitCache(func(root string) GitStatus {
+		calls.Add(1)
+		return GitStatus{Branch: "main"}
+	})
+	t.Cleanup(func() { c.Close() })
+	return c, &calls
+}
+
+func TestGitCache_ResolvesOncePerRepository(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	nested := filepath.Join(dir, "sub")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatalf("Mkdir() failed: %v", err)
+	}
+	c, calls := newCountingGitCache(t)
+
+	for _, d := range []string{dir, nested, dir} {
+		if got := c.Label(d); got != "main" {
+			t.Fatalf("Label(%q) = %q, want main", d, got)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("resolved %d times, want once", n)
+	}
+
+	c.Invalidate(nested)
+	c.Label(dir)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("resolved %d times after Invalidate, want twice", n)
+	}
+
+	if got := c.Label(t.TempDir()); got != "" {
+		t.Fatalf("Label() outside a repository = %q, want empty", got)
+	}
+}
+
+func TestGitCache_MaxAge(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	c, calls := newCountingGitCache(t)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Status(dir)
+	now = now.Add(gitStatusMaxAge)
+	c.Status(dir)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("resolved %d times, want a stale status resolved again", n)
+	}
+}
+
+func TestGitCache_GitChangeInvalidates(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	c, calls := newCountingGitCache(t)
+	c.Status(dir)
+
+	head := filepath.Join(dir, ".git", "HEAD")
+	if err := os.WriteFile(head, []byte("ref: refs/heads/other\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a change to .git/HEAD to invalidate the cache")
+		}
+		time.Sleep(10 * time.Millisecond)
+		c.Status(dir)
+	}
+}
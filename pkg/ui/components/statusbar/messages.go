@@ -0,0 +1,92 @@
+package statusbar
+
+import "strings"
+
+// Priority decides which status message is shown when several are posted.
+type Priority int
+
+const (
+	// PriorityLow is for background progress, shown only when nothing else
+	// is, e.g. a local model loading.
+	PriorityLow Priority = iota
+	// PriorityNormal is for results of what the user just did and for
+	// hints.
+	PriorityNormal
+	// PriorityHigh is for messages waiting on a key, e.g. confirming exit.
+	PriorityHigh
+)
+
+// ParsePriority parses "low", "normal" or "high"; empty is normal.
+func ParsePriority(s string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return PriorityLow, true
+	case "", "normal":
+		return PriorityNormal, true
+	case "high":
+		return PriorityHigh, true
+	}
+	return PriorityNormal, false
+}
+
+// Message is a status bar message posted by one source. Each source has at
+// most one message; posting again replaces it.
+type Message struct {
+	Source   string
+	Text     string
+	Priority Priority
+}
+
+// Post shows msg in place of the previous message from its source. Empty
+// text clears the source. Among messages of equal priority, the latest
+// posted is shown.
+func (s *StatusBarView) Post(msg Message) {
+	s.Clear(msg.Source)
+	if msg.Text != "" {
+		s.messages = append(s.messages, msg)
+	}
+}
+
+// Clear removes the message from source.
+func (s *StatusBarView) Clear(source string) {
+	for i, m := range s.messages {
+		if m.Source == source {
+			s.messages = append(s.messages[:i:i], s.messages[i+1:]...)
+			return
+		}
+	}
+}
+
+// ClearText removes the message from source only if it still reads text,
+// so an expiring message does not take a newer one from the same source
+// with it.
+func (s *StatusBarView) ClearText(source, text string) {
+	if s.MessageFrom(source) == text {
+		s.Clear(source)
+	}
+}
+
+// MessageFrom returns the text posted by source, "" when there is none.
+func (s *StatusBarView) MessageFrom(source string) string {
+	for _, m := range s.messages {
+		if m.Source == source {
+			return m.Text
+		}
+	}
+	return ""
+}
+
+// GetMessage returns the message shown: the latest of the highest
+// priority, "" when there is none.
+func (s *StatusBarView) GetMessage() string {
+	shown := -1
+	for i, m := range s.messages {
+		if shown < 0 || m.Priority >= s.messages[shown].Priority {
+			shown = i
+		}
+	}
+	if shown < 0 {
+		return ""
+	}
+	return s.messages[shown].Text
+}
@@ -0,0 +1,51 @@
+package statusbar
+
+import "testing"
+
+func TestStatusBarView_MessagesByPriority(t *testing.T) {
+	sb := NewStatusBarView()
+	sb.Post(Message{Source: "model", Text: "Loading llama… 3s", Priority: PriorityLow})
+	sb.Post(Message{Source: "flash", Text: "Saved", Priority: PriorityNormal})
+	sb.Post(Message{Source: "exit", Text: "Press Ctrl+D again to exit", Priority: PriorityHigh})
+	sb.Post(Message{Source: "plugin", Text: "Deploy done", Priority: PriorityNormal})
+
+	if got := sb.GetMessage(); got != "Press Ctrl+D again to exit" {
+		t.Fatalf("GetMessage() = %q, want the high priority message", got)
+	}
+	sb.Clear("exit")
+	if got := sb.GetMessage(); got != "Deploy done" {
+		t.Fatalf("GetMessage() = %q, want the latest normal message", got)
+	}
+	sb.Post(Message{Source: "model", Text: "Loading llama… 4s", Priority: PriorityLow})
+	if got := sb.GetMessage(); got != "Deploy done" {
+		t.Fatalf("GetMessage() = %q, low priority update took over", got)
+	}
+
+	// Clearing a message by its old text leaves a newer one alone.
+	sb.Post(Message{Source: "flash", Text: "Copied", Priority: PriorityNormal})
+	sb.ClearText("flash", "Saved")
+	if got := sb.MessageFrom("flash"); got != "Copied" {
+		t.Fatalf("MessageFrom(flash) = %q, want the newer toast", got)
+	}
+
+	sb.Clear("plugin")
+	sb.Clear("flash")
+	if got := sb.GetMessage(); got != "Loading llama… 4s" {
+		t.Fatalf("GetMessage() = %q, want the low priority message", got)
+	}
+	sb.Post(Message{Source: "model"})
+	if got := sb.GetMessage(); got != "" {
+		t.Fatalf("GetMessage() = %q, want none after posting empty text", got)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for in, want := range map[string]Priority{"": PriorityNormal, "low": PriorityLow, "High": PriorityHigh} {
+		if got, ok := ParsePriority(in); !ok || got != want {
+			t.Errorf("ParsePriority(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	if _, ok := ParsePriority("urgent"); ok {
+		t.Error("ParsePriority(urgent) succeeded")
+	}
+}
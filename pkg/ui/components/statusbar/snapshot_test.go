@@ -0,0 +1,20 @@
+package statusbar
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestStatusBarViewSnapshot(t *testing.T) {
+	sizes := []testutils.Size{{Width: 60, Height: 1}, {Width: 120, Height: 1}}
+	testutils.RequireSnapshots(t, sizes, testutils.Profiles, func(width, height int) string {
+		s := NewStatusBarView()
+		s.SetWidth(width)
+		s.SetDirectory("/home/user/projects/wtf_cli")
+		s.SetGitBranch(DefaultGitBranchSymbol + " main")
+		s.SetJobs(1)
+		s.Post(Message{Source: "test", Text: "Press Ctrl+D to chat", Priority: PriorityNormal})
+		return s.Render()
+	})
+}
@@ -1,6 +1,7 @@
 package statusbar
 
 import (
+	"fmt"
 	"os"
 	"strings"
 
@@ -14,14 +15,25 @@ const (
 	// DefaultGitBranchSymbol is the glyph used to display git branch status.
 	DefaultGitBranchSymbol = "⎇"
 	gitBranchPad           = " "
+	// PrivacySymbol follows the prefix while privacy mode is on.
+	PrivacySymbol = "🔒"
+	// ReadOnlyLabel follows the prefix while input is kept from the shell.
+	ReadOnlyLabel = "read-only"
 )
 
 // StatusBarView handles the status bar rendering with Lipgloss
 type StatusBarView struct {
 	currentDir  string
 	gitBranch   string
-	message     string
+	messages    []Message // Posted messages, oldest first
+	watchStatus string
+	progress    string
+	jobs        int
+	answers     int
+	budget      string
 	scrollMode  bool
+	privacy     bool
+	readOnly    bool
 	width       int
 	statusStyle lipgloss.Style
 }
@@ -45,16 +57,6 @@ func (s *StatusBarView) SetGitBranch(branch string) {
 	s.gitBranch = strings.TrimSpace(branch)
 }
 
-// SetMessage sets a temporary message
-func (s *StatusBarView) SetMessage(msg string) {
-	s.message = msg
-}
-
-// GetMessage returns the current message
-func (s *StatusBarView) GetMessage() string {
-	return s.message
-}
-
 // SetWidth updates the width for rendering
 func (s *StatusBarView) SetWidth(width int) {
 	s.width = width
@@ -66,6 +68,67 @@ func (s *StatusBarView) SetScrollMode(active bool) {
 	s.scrollMode = active
 }
 
+// SetPrivacy sets whether privacy mode is on, shown as a lock after the
+// prefix.
+func (s *StatusBarView) SetPrivacy(on bool) {
+	s.privacy = on
+}
+
+// SetReadOnly sets whether read-only mode is on, shown after the prefix.
+func (s *StatusBarView) SetReadOnly(on bool) {
+	s.readOnly = on
+}
+
+// SetWatchStatus sets the /watch badge shown on the right. Empty hides it.
+func (s *StatusBarView) SetWatchStatus(status string) {
+	s.watchStatus = status
+}
+
+// SetProgress sets the pending-operation indicator shown on the right, in
+// place of any other badge. Empty hides it.
+func (s *StatusBarView) SetProgress(progress string) {
+	s.progress = progress
+}
+
+// SetJobs sets the number of background jobs of the shell, shown as a
+// "jobs: N" badge on the right. Zero hides it.
+func (s *StatusBarView) SetJobs(n int) {
+	s.jobs = n
+}
+
+// SetAnswersReady sets the number of AI answers that finished while the
+// chat sidebar was closed, shown first on the right. Zero hides it.
+func (s *StatusBarView) SetAnswersReady(n int) {
+	s.answers = n
+}
+
+// SetBudget sets the remaining AI budget badge shown on the right, after
+// the watch and jobs badges. Empty hides it.
+func (s *StatusBarView) SetBudget(budget string) {
+	s.budget = budget
+}
+
+// badges joins the ready answers, watch, jobs and budget badges.
+func (s *StatusBarView) badges() string {
+	var parts []string
+	switch {
+	case s.answers == 1:
+		parts = append(parts, "1 answer ready · Ctrl+T")
+	case s.answers > 1:
+		parts = append(parts, fmt.Sprintf("%d answers ready · Ctrl+T", s.answers))
+	}
+	if s.watchStatus != "" {
+		parts = append(parts, s.watchStatus)
+	}
+	if s.jobs > 0 {
+		parts = append(parts, fmt.Sprintf("jobs: %d", s.jobs))
+	}
+	if s.budget != "" {
+		parts = append(parts, s.budget)
+	}
+	return strings.Join(parts, "  ")
+}
+
 // Render returns the styled status bar string
 func (s *StatusBarView) Render() string {
 	const (
@@ -73,10 +136,15 @@ func (s *StatusBarView) Render() string {
 		contentPadding = 2
 	)
 
+	message := s.GetMessage()
 	rightContent := ""
-	if s.scrollMode {
+	if s.progress != "" {
+		rightContent = s.progress
+	} else if s.scrollMode {
 		rightContent = "[AUTOSCROLL DISABLED]  Esc to resume"
-	} else if s.message == "" {
+	} else if badges := s.badges(); badges != "" {
+		rightContent = badges
+	} else if message == "" {
 		rightContent = "Press / for commands"
 	}
 	rightWidth := ansi.StringWidth(rightContent)
@@ -91,15 +159,22 @@ func (s *StatusBarView) Render() string {
 	}
 
 	leftText := s.currentDir
-	if s.message != "" {
-		leftText = s.message
+	if message != "" {
+		leftText = message
 	}
 
-	leftPrefix := "[wtf_cli]"
+	leftPrefix := "[wtf_cli"
+	if s.privacy {
+		leftPrefix += " " + PrivacySymbol
+	}
+	if s.readOnly {
+		leftPrefix += " " + ReadOnlyLabel
+	}
+	leftPrefix += "]"
 	leftContent := leftPrefix
 	branchAppended := false
 	branchSuffix := ""
-	if s.message == "" && s.gitBranch != "" {
+	if message == "" && s.gitBranch != "" {
 		branchSuffix = gitBranchPad + DefaultGitBranchSymbol + gitBranchPad + s.gitBranch
 	}
 
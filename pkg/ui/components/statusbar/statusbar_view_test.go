@@ -35,10 +35,10 @@ func TestStatusBarView_SetDirectory(t *testing.T) {
 	}
 }
 
-func TestStatusBarView_SetMessage(t *testing.T) {
+func TestStatusBarView_Post(t *testing.T) {
 	sb := NewStatusBarView()
 	sb.SetWidth(100)
-	sb.SetMessage("Important notification")
+	sb.Post(Message{Source: "test", Text: "Important notification"})
 
 	rendered := sb.Render()
 	if !strings.Contains(rendered, "Important notification") {
@@ -216,7 +216,7 @@ func TestStatusBarView_MessagePriority(t *testing.T) {
 	sb := NewStatusBarView()
 	sb.SetWidth(100)
 	sb.SetDirectory("/home")
-	sb.SetMessage("Alert!")
+	sb.Post(Message{Source: "test", Text: "Alert!"})
 
 	rendered := sb.Render()
 
@@ -226,7 +226,7 @@ func TestStatusBarView_MessagePriority(t *testing.T) {
 	}
 
 	// Clear message
-	sb.SetMessage("")
+	sb.Clear("test")
 	rendered = sb.Render()
 
 	// Directory should be shown now
@@ -252,7 +252,7 @@ func TestStatusBarView_GitBranchHiddenWhenMessageActive(t *testing.T) {
 	sb.SetWidth(120)
 	sb.SetDirectory("/home/user/projects/repo")
 	sb.SetGitBranch("main")
-	sb.SetMessage("Important notification")
+	sb.Post(Message{Source: "test", Text: "Important notification"})
 
 	rendered := ansi.Strip(sb.Render())
 	if strings.Contains(rendered, "⎇ main") {
@@ -351,3 +351,79 @@ func TestStatusBarView_ScrollModeWidthPreserved(t *testing.T) {
 		t.Fatalf("expected width 80 in scroll mode, got %d", width)
 	}
 }
+
+func TestStatusBarView_ProgressReplacesBadges(t *testing.T) {
+	sb := NewStatusBarView()
+	sb.SetWidth(100)
+	sb.SetScrollMode(true)
+	sb.SetProgress("⠋ Fetching models · Esc to cancel")
+
+	rendered := ansi.Strip(sb.Render())
+	if !strings.Contains(rendered, "Fetching models") || strings.Contains(rendered, "AUTOSCROLL") {
+		t.Fatalf("Expected the progress in place of the scroll badge, got %q", rendered)
+	}
+
+	sb.SetProgress("")
+	if rendered := ansi.Strip(sb.Render()); !strings.Contains(rendered, "AUTOSCROLL") {
+		t.Fatalf("Expected the scroll badge back, got %q", rendered)
+	}
+}
+
+func TestStatusBarView_JobsBadge(t *testing.T) {
+	sb := NewStatusBarView()
+	sb.SetWidth(100)
+	sb.SetJobs(2)
+
+	rendered := ansi.Strip(sb.Render())
+	if !strings.Contains(rendered, "jobs: 2") || strings.Contains(rendered, "Press / for commands") {
+		t.Fatalf("Expected the jobs badge in place of the hint, got %q", rendered)
+	}
+
+	sb.SetWatchStatus("watch: make test")
+	if rendered := ansi.Strip(sb.Render()); !strings.Contains(rendered, "watch: make test  jobs: 2") {
+		t.Fatalf("Expected the jobs badge after the watch badge, got %q", rendered)
+	}
+
+	sb.SetWatchStatus("")
+	sb.SetJobs(0)
+	if rendered := ansi.Strip(sb.Render()); strings.Contains(rendered, "jobs:") {
+		t.Fatalf("Expected no jobs badge, got %q", rendered)
+	}
+}
+
+func TestStatusBarView_BudgetBadge(t *testing.T) {
+	sb := NewStatusBarView()
+	sb.SetWidth(100)
+	sb.SetBudget("budget: 120k left")
+
+	rendered := ansi.Strip(sb.Render())
+	if !strings.Contains(rendered, "budget: 120k left") || strings.Contains(rendered, "Press / for commands") {
+		t.Fatalf("Expected the budget badge in place of the hint, got %q", rendered)
+	}
+
+	sb.SetJobs(1)
+	if rendered := ansi.Strip(sb.Render()); !strings.Contains(rendered, "jobs: 1  budget: 120k left") {
+		t.Fatalf("Expected the budget badge after the jobs badge, got %q", rendered)
+	}
+}
+
+func TestStatusBarView_AnswersReadyBadge(t *testing.T) {
+	sb := NewStatusBarView()
+	sb.SetWidth(100)
+	sb.SetJobs(1)
+	sb.SetAnswersReady(1)
+
+	if rendered := ansi.Strip(sb.Render()); !strings.Contains(rendered, "1 answer ready · Ctrl+T  jobs: 1") {
+		t.Fatalf("Expected the ready badge before the jobs badge, got %q", rendered)
+	}
+
+	sb.SetAnswersReady(2)
+	if rendered := ansi.Strip(sb.Render()); !strings.Contains(rendered, "2 answers ready") {
+		t.Fatalf("Expected the plural ready badge, got %q", rendered)
+	}
+
+	sb.SetAnswersReady(0)
+	if rendered := ansi.Strip(sb.Render()); strings.Contains(rendered, "ready") {
+		t.Fatalf("Expected no ready badge, got %q", rendered)
+	}
+}
@@ -0,0 +1,83 @@
+package testutils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ui/styles"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/exp/golden"
+)
+
+// Size is a terminal size a component is rendered at.
+type Size struct {
+	Width, Height int
+}
+
+// Sizes most snapshots are taken at: a small terminal, the classic 80x24
+// and a wide one.
+var (
+	SizeSmall  = Size{Width: 60, Height: 16}
+	SizeNormal = Size{Width: 80, Height: 24}
+	SizeWide   = Size{Width: 140, Height: 40}
+)
+
+// Profiles most snapshots are taken in: full color, 16 colors and none.
+var Profiles = []colorprofile.Profile{colorprofile.TrueColor, colorprofile.ANSI, colorprofile.ASCII}
+
+// RenderFunc renders a component at width x height. It is called once per
+// size and profile and should build the component anew each time: some
+// components copy the theme's styles when they are built.
+type RenderFunc func(width, height int) string
+
+// RequireSnapshots renders a component at every size in every profile and
+// compares each result with testdata/<test>/<W>x<H>_<profile>.golden, as
+// the terminal would show it after downsampling. Run the tests with
+// -update to write the golden files.
+//
+// A render wider or taller than its size fails before the comparison:
+// that is the overlay and padding breakage golden files are most often
+// updated over by mistake.
+func RequireSnapshots(t *testing.T, sizes []Size, profiles []colorprofile.Profile, render RenderFunc) {
+	t.Helper()
+	previous := styles.Profile()
+	t.Cleanup(func() { styles.SetProfile(previous) })
+
+	for _, profile := range profiles {
+		for _, size := range sizes {
+			t.Run(fmt.Sprintf("%dx%d_%s", size.Width, size.Height, profile), func(t *testing.T) {
+				styles.SetProfile(profile)
+				out := Downsample(render(size.Width, size.Height), profile)
+				RequireFits(t, out, size)
+				golden.RequireEqual(t, out)
+			})
+		}
+	}
+}
+
+// Downsample converts the colors in s to what profile can show, the way
+// the renderer does for the terminal.
+func Downsample(s string, profile colorprofile.Profile) string {
+	var sb strings.Builder
+	w := &colorprofile.Writer{Forward: &sb, Profile: profile}
+	_, _ = w.WriteString(s)
+	return sb.String()
+}
+
+// RequireFits fails t when out has a line wider than size or more lines
+// than it.
+func RequireFits(t *testing.T, out string, size Size) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) > size.Height {
+		t.Errorf("render is %d lines tall, want at most %d", len(lines), size.Height)
+	}
+	for i, line := range lines {
+		if w := ansi.StringWidth(line); w > size.Width {
+			t.Errorf("line %d is %d cells wide, want at most %d: %q", i+1, w, size.Width, ansi.Strip(line))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package viewport
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Bulk output detection. Every styled append rebuilds the full content, so a
+// large dump (cat of a big file) gets quadratically slower as it grows. Once
+// output arrives faster than bulkEnterBytes per bulkWindow the viewport only
+// shows a plain-text tail and defers styling; it reconciles when a window
+// drops below bulkExitBytes or output goes quiet for BulkIdle.
+const (
+	bulkWindow     = 250 * time.Millisecond
+	bulkEnterBytes = 256 * 1024
+	bulkExitBytes  = 32 * 1024
+
+	// BulkIdle is how long output must pause before raw mode ends.
+	BulkIdle = 200 * time.Millisecond
+)
+
+type bulkState struct {
+	active      bool
+	windowStart time.Time
+	windowBytes int
+	lastAppend  time.Time
+	pending     []byte   // Raw output not yet fed to the line renderer
+	tail        []string // Plain-text last lines shown while active
+}
+
+// observe records n bytes arriving at now. It reports whether output is fast
+// enough for raw mode; while active, that stays true until a whole window
+// falls below the exit threshold.
+func (b *bulkState) observe(n int, now time.Time) bool {
+	b.lastAppend = now
+	if b.windowStart.IsZero() || now.Sub(b.windowStart) >= bulkWindow {
+		slow := b.windowBytes < bulkExitBytes
+		b.windowStart = now
+		b.windowBytes = 0
+		if b.active && slow {
+			b.windowBytes = n
+			return false
+		}
+	}
+	b.windowBytes += n
+	if b.active {
+		return true
+	}
+	return b.windowBytes >= bulkEnterBytes
+}
+
+// appendTail adds sanitized output to the visible tail, keeping at most
+// limit lines. Escape sequences split across chunks may leave stray bytes in
+// the tail; reconciliation replaces it with properly rendered content.
+func (b *bulkState) appendTail(data []byte, limit int) {
+	text := ansi.Strip(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		if i == 0 && len(b.tail) > 0 {
+			b.tail[len(b.tail)-1] += line
+			continue
+		}
+		b.tail = append(b.tail, line)
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	if len(b.tail) > limit {
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-limit:]...)
+	}
+}
+
+// InBulkMode reports whether the viewport is showing raw bulk output.
+func (v *PTYViewport) InBulkMode() bool {
+	return v.bulk.active
+}
+
+// SetClock replaces the clock bulk detection reads, so callers can test
+// bulk mode without depending on how fast the test runs.
+func (v *PTYViewport) SetClock(now func() time.Time) {
+	v.now = now
+}
+
+// SettleBulk ends raw mode once output has been quiet for BulkIdle. It
+// reports whether the viewport is still in raw mode.
+func (v *PTYViewport) SettleBulk() bool {
+	if !v.bulk.active {
+		return false
+	}
+	if v.now().Sub(v.bulk.lastAppend) < BulkIdle {
+		return true
+	}
+	v.reconcileBulk()
+	return false
+}
+
+func (v *PTYViewport) enterBulk() {
+	v.bulk.active = true
+	v.bulk.pending = v.bulk.pending[:0]
+	v.bulk.tail = nil
+	height := v.Viewport.Height()
+	lines := strings.Split(v.content, "\n")
+	v.bulk.appendTail([]byte(strings.Join(lines[max(0, len(lines)-height):], "\n")), height)
+}
+
+// appendBulk buffers data for later styling and updates the plain tail. The
+// cost is proportional to the chunk, not to the accumulated content.
+func (v *PTYViewport) appendBulk(data []byte) {
+	v.bulk.pending = append(v.bulk.pending, data...)
+	v.bulk.appendTail(data, v.Viewport.Height())
+	v.dirty = true
+	if v.pauseAutoScroll {
+		// The user is reading older content; leave it in place.
+		return
+	}
+	v.Viewport.SetContentLines(append([]string(nil), v.bulk.tail...))
+	v.Viewport.GotoBottom()
+}
+
+// reconcileBulk feeds the deferred output through the line renderer in one
+// pass and restores the full styled content.
+func (v *PTYViewport) reconcileBulk() {
+	if !v.bulk.active {
+		return
+	}
+	pending := v.bulk.pending
+	v.bulk.active = false
+	v.bulk.pending = nil
+	v.bulk.tail = nil
+	v.bulk.windowStart = time.Time{}
+	v.bulk.windowBytes = 0
+	v.appendStyled(pending)
+}
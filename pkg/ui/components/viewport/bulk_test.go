@@ -0,0 +1,117 @@
+package viewport
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBulkTestViewport(now *time.Time) PTYViewport {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 5)
+	vp.now = func() time.Time { return *now }
+	return vp
+}
+
+func bulkChunk(start, count int) []byte {
+	var b strings.Builder
+	for i := start; i < start+count; i++ {
+		fmt.Fprintf(&b, "\x1b[32mline %06d\x1b[0m padding padding padding padding\r\n", i)
+	}
+	return []byte(b.String())
+}
+
+func TestBulkMode_EntersOnFastOutput(t *testing.T) {
+	now := time.Unix(0, 0)
+	vp := newBulkTestViewport(&now)
+
+	vp.AppendOutput([]byte("$ cat big.log\r\n"))
+	if vp.InBulkMode() {
+		t.Fatal("Expected small output to stay in styled mode")
+	}
+
+	for i := 0; i < 8; i++ {
+		vp.AppendOutput(bulkChunk(i*1000, 1000))
+		now = now.Add(10 * time.Millisecond)
+	}
+	if !vp.InBulkMode() {
+		t.Fatal("Expected fast output to switch to bulk mode")
+	}
+
+	view := vp.View()
+	if !strings.Contains(view, "line 007999") {
+		t.Fatalf("Expected tail of output in view, got %q", view)
+	}
+	if strings.Contains(view, "\x1b[32m") {
+		t.Fatalf("Expected escape sequences stripped in bulk mode, got %q", view)
+	}
+}
+
+func TestBulkMode_SettleReconcilesContent(t *testing.T) {
+	now := time.Unix(0, 0)
+	vp := newBulkTestViewport(&now)
+
+	for i := 0; i < 8; i++ {
+		vp.AppendOutput(bulkChunk(i*1000, 1000))
+		now = now.Add(10 * time.Millisecond)
+	}
+	if !vp.SettleBulk() {
+		t.Fatal("Expected bulk mode to continue right after output")
+	}
+
+	now = now.Add(BulkIdle)
+	if vp.SettleBulk() {
+		t.Fatal("Expected bulk mode to end after output went quiet")
+	}
+	content := vp.GetContent()
+	if !strings.Contains(content, "line 000000") || !strings.Contains(content, "line 007999") {
+		t.Fatal("Expected reconciled content to hold all output")
+	}
+	if !strings.Contains(content, "\x1b[32m") {
+		t.Fatal("Expected reconciled content to keep styling")
+	}
+}
+
+func TestBulkMode_ExitsWhenRateDrops(t *testing.T) {
+	now := time.Unix(0, 0)
+	vp := newBulkTestViewport(&now)
+
+	for i := 0; i < 8; i++ {
+		vp.AppendOutput(bulkChunk(i*1000, 1000))
+		now = now.Add(10 * time.Millisecond)
+	}
+	if !vp.InBulkMode() {
+		t.Fatal("Expected bulk mode")
+	}
+
+	// A full window of trickling output drops below the exit threshold.
+	now = now.Add(bulkWindow)
+	vp.AppendOutput([]byte("a"))
+	now = now.Add(bulkWindow)
+	vp.AppendOutput([]byte("b\r\n"))
+	if vp.InBulkMode() {
+		t.Fatal("Expected bulk mode to end once output slowed down")
+	}
+	if !strings.Contains(vp.GetContent(), "line 007999") {
+		t.Fatal("Expected bulk output to be reconciled")
+	}
+}
+
+func TestBulkMode_ScrollModeReconciles(t *testing.T) {
+	now := time.Unix(0, 0)
+	vp := newBulkTestViewport(&now)
+
+	for i := 0; i < 8; i++ {
+		vp.AppendOutput(bulkChunk(i*1000, 1000))
+		now = now.Add(10 * time.Millisecond)
+	}
+	vp.SetAutoScroll(false)
+	if vp.InBulkMode() {
+		t.Fatal("Expected entering scroll mode to leave bulk mode")
+	}
+	total, _, _ := vp.Stats()
+	if total < 8000 {
+		t.Fatalf("Expected full content after reconcile, got %d lines", total)
+	}
+}
@@ -0,0 +1,77 @@
+package viewport
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Long lines are soft-wrapped at the viewport width, so one content line can
+// take several rows. The helpers below translate between rows of the wrapped
+// view and positions in the content, which is what survives a width change.
+
+// scrollAnchor is the content position shown in the top row.
+type scrollAnchor struct {
+	line int // Content line
+	col  int // Cell column within the line where the top row starts
+}
+
+// wrappedHeight returns the rows line takes at width, as the viewport wraps it.
+func wrappedHeight(line string, width int) int {
+	w := ansi.StringWidth(line)
+	if w <= width {
+		return 1
+	}
+	return (w + width - 1) / width
+}
+
+// wrapPosition returns the content line shown in row of the wrapped lines
+// and the column the row starts at. Rows past the end map to the last line.
+func wrapPosition(lines []string, width, row int) (line, col int) {
+	if width < 1 {
+		width = 1
+	}
+	top := 0
+	for i, l := range lines {
+		h := wrappedHeight(l, width)
+		if row < top+h {
+			return i, (row - top) * width
+		}
+		top += h
+	}
+	if len(lines) == 0 {
+		return 0, 0
+	}
+	last := len(lines) - 1
+	return last, (wrappedHeight(lines[last], width) - 1) * width
+}
+
+// wrappedRow returns the row of the wrapped lines that shows col of line.
+func wrappedRow(lines []string, width, line, col int) int {
+	if width < 1 {
+		width = 1
+	}
+	row := 0
+	for i := 0; i < line && i < len(lines); i++ {
+		row += wrappedHeight(lines[i], width)
+	}
+	return row + col/width
+}
+
+// viewLines returns what the inner viewport shows, cursor overlay and
+// selection included, split into content lines.
+func (v *PTYViewport) viewLines() []string {
+	return strings.Split(v.Viewport.GetContent(), "\n")
+}
+
+// topAnchor returns the content position in the top row.
+func (v *PTYViewport) topAnchor() scrollAnchor {
+	line, col := wrapPosition(v.viewLines(), v.Viewport.Width(), v.Viewport.YOffset())
+	return scrollAnchor{line: line, col: col}
+}
+
+// restoreAnchor scrolls so that a's line is in the top row again, on the
+// row holding a's column at the current width.
+func (v *PTYViewport) restoreAnchor(a scrollAnchor) {
+	v.Viewport.SetYOffset(wrappedRow(v.viewLines(), v.Viewport.Width(), a.line, a.col))
+}
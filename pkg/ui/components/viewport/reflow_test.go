@@ -0,0 +1,90 @@
+package viewport
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func viewRows(vp *PTYViewport) []string {
+	rows := strings.Split(ansi.Strip(vp.View()), "\n")
+	for i := range rows {
+		rows[i] = strings.TrimRight(rows[i], " ")
+	}
+	return rows
+}
+
+func TestPTYViewport_WrapsLongLines(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(10, 5)
+	vp.SetCursorVisible(false)
+	vp.AppendOutput([]byte("abcdefghijklmnopqrstuvwxy\nnext"))
+
+	rows := viewRows(&vp)
+	want := []string{"abcdefghij", "klmnopqrst", "uvwxy", "next"}
+	for i, w := range want {
+		if rows[i] != w {
+			t.Fatalf("row %d = %q, want %q (rows %q)", i, rows[i], w, rows)
+		}
+	}
+}
+
+func TestPTYViewport_ResizeKeepsScrolledBackLine(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(20, 5)
+	vp.SetCursorVisible(false)
+	var out strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&out, "line %02d %s\n", i, strings.Repeat("x", 15))
+	}
+	vp.AppendOutput([]byte(out.String()))
+
+	vp.SetAutoScroll(false)
+	// Each line takes two rows at width 20; put line 12 at the top.
+	vp.Viewport.SetYOffset(24)
+	if rows := viewRows(&vp); !strings.HasPrefix(rows[0], "line 12") {
+		t.Fatalf("expected line 12 at the top before resizing, got %q", rows)
+	}
+
+	vp.SetSize(40, 5)
+	if rows := viewRows(&vp); !strings.HasPrefix(rows[0], "line 12") {
+		t.Fatalf("expected line 12 at the top after widening, got %q", rows)
+	}
+	vp.SetSize(8, 5)
+	if rows := viewRows(&vp); !strings.HasPrefix(rows[0], "line 12") {
+		t.Fatalf("expected line 12 at the top after narrowing, got %q", rows)
+	}
+}
+
+func TestPTYViewport_ResizeWhileFollowingStaysAtBottom(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(20, 5)
+	var out strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&out, "line %02d %s\n", i, strings.Repeat("x", 15))
+	}
+	vp.AppendOutput([]byte(out.String() + "$ "))
+
+	vp.SetSize(10, 5)
+	if !vp.IsAtBottom() {
+		t.Fatal("expected the viewport to stay at the bottom after narrowing")
+	}
+	if rows := viewRows(&vp); !strings.HasPrefix(rows[len(rows)-1], "$") {
+		t.Fatalf("expected the prompt in the last row, got %q", rows)
+	}
+}
+
+func TestPTYViewport_SelectionOnWrappedLine(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(10, 5)
+	vp.AppendOutput([]byte("abcdefghijklmnopqrstuvwxy\nnext\n"))
+
+	// Row 1 is the second part of the first line.
+	vp.StartSelection(1, 2)
+	vp.UpdateSelection(1, 5)
+	if got := vp.FinishSelection(); got != "mno" {
+		t.Fatalf("selection = %q, want %q", got, "mno")
+	}
+}
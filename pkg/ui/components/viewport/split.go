@@ -0,0 +1,134 @@
+package viewport
+
+import (
+	"fmt"
+	"strings"
+
+	"wtf_cli/pkg/ui/components/utils"
+	"wtf_cli/pkg/ui/styles"
+
+	"charm.land/bubbles/v2/viewport"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// A split view keeps a second, frozen view of the content above the live
+// one, like less +F with a second window: the top half stays where it was
+// scrolled to while the bottom half follows new output. Scrolling moves the
+// top half; the bottom half always shows the end. Both halves share the
+// content, so the top half keeps up with restyling and a reconciled bulk
+// dump.
+
+// minSplitRows is the fewest rows each half needs. A shorter viewport shows
+// only the live half until it grows again.
+const minSplitRows = 3
+
+// splitHeights divides height between the frozen and live halves, leaving
+// one row for the divider. top is 0 when height is too short for both.
+func splitHeights(height int) (top, bottom int) {
+	if height < 2*minSplitRows+1 {
+		return 0, height
+	}
+	top = (height - 1) / 2
+	return top, height - 1 - top
+}
+
+// SetSplit turns the split view on or off. Turning it on freezes the rows
+// on screen in the top half and sends the bottom half back to the end of
+// the output.
+func (v *PTYViewport) SetSplit(on bool) {
+	if on == (v.frozen != nil) {
+		return
+	}
+	v.reconcileBulk()
+	width, height := v.Viewport.Width(), v.height
+	if !on {
+		v.frozen = nil
+		v.Viewport.SetHeight(height)
+		v.Viewport.GotoBottom()
+		v.dirty = true
+		return
+	}
+
+	anchor := v.topAnchor()
+	frozen := viewport.New()
+	frozen.SoftWrap = true
+	v.frozen = &frozen
+	v.pauseAutoScroll = false
+	v.sizeSplit(width, height)
+	v.renderContent()
+	v.restoreFrozenAnchor(anchor)
+	v.Viewport.GotoBottom()
+	v.dirty = true
+}
+
+// IsSplit reports whether the split view is on.
+func (v *PTYViewport) IsSplit() bool {
+	return v.frozen != nil
+}
+
+// Size returns the viewport's dimensions, both halves of a split view and
+// the divider included.
+func (v *PTYViewport) Size() (width, height int) {
+	return v.Viewport.Width(), v.height
+}
+
+// sizeSplit gives each half of a split view its share of height.
+func (v *PTYViewport) sizeSplit(width, height int) {
+	top, bottom := splitHeights(height)
+	v.frozen.SetWidth(width)
+	v.frozen.SetHeight(top)
+	v.Viewport.SetHeight(bottom)
+}
+
+// scrolled returns the view that scrolling moves: the frozen half of a
+// split view, or else the only one.
+func (v *PTYViewport) scrolled() *viewport.Model {
+	if v.frozen != nil && v.frozen.Height() > 0 {
+		return v.frozen
+	}
+	return &v.Viewport
+}
+
+// rowAt maps a screen row of the viewport to the view showing it and the
+// row within that view. ok is false for the divider of a split view and for
+// rows outside the viewport.
+func (v *PTYViewport) rowAt(screenRow int) (view *viewport.Model, row int, ok bool) {
+	if v.frozen == nil || v.frozen.Height() == 0 {
+		return &v.Viewport, screenRow, screenRow >= 0 && screenRow < v.Viewport.Height()
+	}
+	top := v.frozen.Height()
+	switch {
+	case screenRow < 0 || screenRow >= v.height || screenRow == top:
+		return nil, 0, false
+	case screenRow < top:
+		return v.frozen, screenRow, true
+	}
+	return &v.Viewport, screenRow - top - 1, true
+}
+
+// frozenAnchor returns the content position in the frozen half's top row.
+func (v *PTYViewport) frozenAnchor() scrollAnchor {
+	lines := strings.Split(v.frozen.GetContent(), "\n")
+	line, col := wrapPosition(lines, v.frozen.Width(), v.frozen.YOffset())
+	return scrollAnchor{line: line, col: col}
+}
+
+// restoreFrozenAnchor scrolls the frozen half so that a's line is in its
+// top row.
+func (v *PTYViewport) restoreFrozenAnchor(a scrollAnchor) {
+	lines := strings.Split(v.frozen.GetContent(), "\n")
+	v.frozen.SetYOffset(wrappedRow(lines, v.frozen.Width(), a.line, a.col))
+}
+
+// splitView renders the frozen half, the divider and the live half.
+func (v *PTYViewport) splitView() string {
+	lines := strings.Split(v.frozen.GetContent(), "\n")
+	width := v.frozen.Width()
+	top, _ := wrapPosition(lines, width, v.frozen.YOffset())
+	label := fmt.Sprintf("── frozen at line %d of %d · live output below · Ctrl+W f closes ", top+1, len(lines))
+	label = utils.TruncateToWidth(label, width)
+	if pad := width - ansi.StringWidth(label); pad > 0 {
+		label += strings.Repeat("─", pad)
+	}
+	return v.frozen.View() + "\n" + styles.TextMutedStyle.Render(label) + "\n" + v.Viewport.View()
+}
@@ -0,0 +1,79 @@
+package viewport
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func numberedOutput(from, to int) []byte {
+	var sb strings.Builder
+	for i := from; i <= to; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	return []byte(sb.String())
+}
+
+func TestPTYViewport_SplitFreezesTopAndFollowsBelow(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 11)
+	vp.AppendOutput(numberedOutput(1, 50))
+	vp.SetAutoScroll(false)
+	for range 20 {
+		vp.ScrollUp()
+	}
+
+	vp.SetSplit(true)
+	if top, bottom := vp.frozen.Height(), vp.Viewport.Height(); top != 5 || bottom != 5 {
+		t.Fatalf("halves = %d and %d rows, want 5 and 5", top, bottom)
+	}
+	frozenTop := vp.frozen.YOffset()
+	if frozenTop != 20 {
+		t.Fatalf("frozen half starts at row %d, want the row scrolled to (20)", frozenTop)
+	}
+
+	vp.AppendOutput(numberedOutput(51, 60))
+	if vp.frozen.YOffset() != frozenTop {
+		t.Fatalf("frozen half moved to row %d with new output", vp.frozen.YOffset())
+	}
+	view := vp.View()
+	if !strings.Contains(view, "line 21") || !strings.Contains(view, "line 60") {
+		t.Fatalf("expected the frozen rows above the live tail, got:\n%s", view)
+	}
+	if !strings.Contains(view, "frozen at line 21") {
+		t.Fatalf("expected the divider to name the frozen line, got:\n%s", view)
+	}
+
+	vp.ScrollUp()
+	if vp.frozen.YOffset() != frozenTop-1 || !vp.IsAtBottom() {
+		t.Fatal("expected scrolling to move the frozen half and leave the live half at the end")
+	}
+
+	vp.SetSize(30, 21)
+	if top, bottom := vp.frozen.Height(), vp.Viewport.Height(); top != 10 || bottom != 10 {
+		t.Fatalf("halves after resize = %d and %d rows, want 10 and 10", top, bottom)
+	}
+	if got := vp.VisibleLines()[0]; got != "line 20" {
+		t.Fatalf("expected the frozen line kept on resize, got %q", got)
+	}
+
+	vp.SetSplit(false)
+	if vp.Viewport.Height() != 21 || !vp.IsAtBottom() {
+		t.Fatal("expected the whole height back at the end of the output")
+	}
+}
+
+func TestPTYViewport_SplitNeedsRoomForBothHalves(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 5)
+	vp.AppendOutput(numberedOutput(1, 20))
+	vp.SetSplit(true)
+
+	if view := vp.View(); strings.Contains(view, "frozen at") {
+		t.Fatalf("expected only the live half in a short viewport, got:\n%s", view)
+	}
+	vp.SetSize(40, 9)
+	if view := vp.View(); !strings.Contains(view, "frozen at") {
+		t.Fatalf("expected the split back once there is room, got:\n%s", view)
+	}
+}
@@ -1,13 +1,18 @@
 package viewport
 
 import (
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"wtf_cli/pkg/ui/components/selection"
+	"wtf_cli/pkg/ui/styles"
 	"wtf_cli/pkg/ui/terminal"
 
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // PTYViewport wraps Bubble Tea's viewport for displaying PTY output
@@ -21,24 +26,73 @@ type PTYViewport struct {
 	dirty           bool // True if content changed since last View()
 	pauseAutoScroll bool // When true, AppendOutput does not auto-scroll to bottom
 	sel             selection.Selection
+	bulk            bulkState
+	now             func() time.Time
+	highlights      []*regexp.Regexp
+	// highlighted caches whether a content line matches highlights, so a
+	// re-render only runs the patterns on new lines.
+	highlighted map[string]bool
+	cited       int // Content line marked by RevealLine, -1 for none
+	// frozen is the top half of a split view, nil when it is off.
+	frozen *viewport.Model
+	height int // Rows for both halves of a split view and the divider
 }
 
 // NewPTYViewport creates a new PTY viewport
 func NewPTYViewport() PTYViewport {
+	vp := viewport.New()
+	vp.SoftWrap = true
 	return PTYViewport{
-		Viewport:      viewport.New(),
+		Viewport:      vp,
 		content:       "",
 		cursorTracker: terminal.NewCursorTracker(),
 		lineRenderer:  terminal.NewLineRenderer(),
 		showCursor:    true,
+		now:           time.Now,
+		cited:         -1,
 	}
 }
 
-// SetSize updates the viewport dimensions
+// SetSize updates the viewport dimensions. Long lines are re-wrapped at the
+// new width; a viewport following the output stays at the bottom, one
+// scrolled back keeps the same content line at the top.
 func (v *PTYViewport) SetSize(width, height int) {
+	if v.bulk.active {
+		// The tail only holds the old height's worth of lines.
+		v.reconcileBulk()
+	}
+	resized := v.ready && (width != v.Viewport.Width() || height != v.height)
+	following := !v.pauseAutoScroll || v.Viewport.AtBottom()
+	var anchor, frozenAnchor scrollAnchor
+	if resized && !following {
+		anchor = v.topAnchor()
+	}
+	if resized && v.frozen != nil {
+		frozenAnchor = v.frozenAnchor()
+	}
+
 	v.Viewport.SetWidth(width)
 	v.Viewport.SetHeight(height)
+	v.height = height
+	if v.frozen != nil {
+		v.sizeSplit(width, height)
+	}
 	v.ready = true
+	if v.lineRenderer != nil {
+		// The shell wraps its lines at the viewport width, the PTY's.
+		v.lineRenderer.SetWidth(width)
+	}
+
+	switch {
+	case !resized:
+	case following:
+		v.Viewport.GotoBottom()
+	default:
+		v.restoreAnchor(anchor)
+	}
+	if resized && v.frozen != nil {
+		v.restoreFrozenAnchor(frozenAnchor)
+	}
 }
 
 // AppendOutput adds new output to the viewport
@@ -50,6 +104,23 @@ func (v *PTYViewport) AppendOutput(data []byte) {
 		v.sel.Clear()
 	}
 
+	wasBulk := v.bulk.active
+	if v.bulk.observe(len(data), v.now()) {
+		if !wasBulk {
+			v.enterBulk()
+		}
+		v.appendBulk(data)
+		return
+	}
+	if wasBulk {
+		v.reconcileBulk()
+	}
+	v.appendStyled(data)
+}
+
+// appendStyled runs data through the line renderer and re-renders the full
+// content.
+func (v *PTYViewport) appendStyled(data []byte) {
 	if v.lineRenderer != nil {
 		v.lineRenderer.Append(data)
 		v.content = v.lineRenderer.Content()
@@ -86,17 +157,23 @@ func (v *PTYViewport) SetCursorVisible(visible bool) {
 
 // GetContent returns the current viewport content
 func (v *PTYViewport) GetContent() string {
+	v.reconcileBulk()
 	return v.content
 }
 
 // Clear empties the viewport
 func (v *PTYViewport) Clear() {
+	v.bulk = bulkState{}
 	v.content = ""
+	v.cited = -1
 	v.sel.Clear()
 	if v.lineRenderer != nil {
 		v.lineRenderer.Reset()
 	}
 	v.Viewport.SetContent("")
+	if v.frozen != nil {
+		v.frozen.SetContent("")
+	}
 	v.dirty = true // Mark as changed
 }
 
@@ -114,6 +191,9 @@ func (v *PTYViewport) View() string {
 	if !v.ready {
 		return "Loading..."
 	}
+	if v.frozen != nil && v.frozen.Height() > 0 {
+		return v.splitView()
+	}
 
 	return v.Viewport.View()
 }
@@ -122,32 +202,35 @@ func (v *PTYViewport) View() string {
 
 // SetAutoScroll enables or disables auto-scrolling on new output.
 // When disabled, AppendOutput will not move the viewport to the bottom.
-// Re-enabling also snaps the viewport to the bottom immediately.
+// Re-enabling also snaps the viewport to the bottom immediately. The live
+// half of a split view always follows.
 func (v *PTYViewport) SetAutoScroll(enabled bool) {
-	v.pauseAutoScroll = !enabled
+	// Scrolling back needs the full content, not just the raw tail.
+	v.reconcileBulk()
+	v.pauseAutoScroll = !enabled && v.frozen == nil
 	if enabled {
 		v.Viewport.GotoBottom()
 	}
 }
 
-// ScrollUp scrolls the viewport up
+// ScrollUp scrolls the viewport up, or the frozen half of a split view.
 func (v *PTYViewport) ScrollUp() {
-	v.Viewport.ScrollUp(1)
+	v.scrolled().ScrollUp(1)
 }
 
-// ScrollDown scrolls the viewport down
+// ScrollDown scrolls the viewport down, or the frozen half of a split view.
 func (v *PTYViewport) ScrollDown() {
-	v.Viewport.ScrollDown(1)
+	v.scrolled().ScrollDown(1)
 }
 
 // PageUp scrolls up one page
 func (v *PTYViewport) PageUp() {
-	v.Viewport.PageUp()
+	v.scrolled().PageUp()
 }
 
 // PageDown scrolls down one page
 func (v *PTYViewport) PageDown() {
-	v.Viewport.PageDown()
+	v.scrolled().PageDown()
 }
 
 // IsAtBottom returns true if scrolled to bottom
@@ -157,6 +240,7 @@ func (v *PTYViewport) IsAtBottom() bool {
 
 // Stats returns viewport statistics
 func (v *PTYViewport) Stats() (totalLines, visibleLines, scrollPercent int) {
+	v.reconcileBulk()
 	// Count total lines
 	lines := strings.Split(v.content, "\n")
 	totalLines = len(lines)
@@ -180,6 +264,7 @@ func (v *PTYViewport) RenderLines() string {
 // StartSelection begins a mouse text selection from viewport-local screen
 // coordinates.
 func (v *PTYViewport) StartSelection(screenRow, screenCol int) {
+	v.reconcileBulk()
 	row, col, ok := v.selectionContentPoint(screenRow, screenCol, false)
 	if !ok {
 		return
@@ -236,13 +321,186 @@ func (v *PTYViewport) HasSelection() bool {
 	return !v.sel.IsEmpty()
 }
 
+// CommandBlockAt returns the plain text of the command block whose prompt
+// line is shown in screenRow: the prompt, the command and its output up to
+// the next prompt. ok is false unless the shell marks its prompts with
+// OSC 133 and screenRow shows the first line of one.
+func (v *PTYViewport) CommandBlockAt(screenRow int) (text string, ok bool) {
+	v.reconcileBulk()
+	if v.lineRenderer == nil {
+		return "", false
+	}
+	view, viewRow, ok := v.rowAt(screenRow)
+	if !v.ready || !ok {
+		return "", false
+	}
+	viewLines, width := v.viewLines(), v.Viewport.Width()
+	row := view.YOffset() + viewRow
+	line, col := wrapPosition(viewLines, width, row)
+	if col != 0 || wrappedRow(viewLines, width, line, col) != row {
+		return "", false // A wrapped continuation or a row past the end
+	}
+	rows := v.lineRenderer.PromptRows()
+	i := slices.Index(rows, line)
+	if i < 0 {
+		return "", false
+	}
+	lines := strings.Split(v.content, "\n")
+	end := len(lines)
+	if i+1 < len(rows) {
+		end = min(rows[i+1], end)
+	}
+	if line >= end {
+		return "", false
+	}
+	block := make([]string, 0, end-line)
+	for _, l := range lines[line:end] {
+		block = append(block, strings.TrimRight(ansi.Strip(l), " "))
+	}
+	return strings.TrimRight(strings.Join(block, "\n"), "\n"), true
+}
+
+// VisibleLines returns the plain text of the content lines on screen,
+// without the cursor. A line wrapped across the top or bottom row is
+// returned whole. A split view returns the frozen half's lines, then the
+// live half's.
+func (v *PTYViewport) VisibleLines() []string {
+	if !v.ready || v.Viewport.Height() <= 0 {
+		return nil
+	}
+	viewLines, width := v.viewLines(), v.Viewport.Width()
+	text := viewLines // Raw bulk output is shown as is
+	if !v.bulk.active {
+		text = strings.Split(v.content, "\n")
+	}
+	lineRange := func(view *viewport.Model) (top, bottom int) {
+		top, _ = wrapPosition(viewLines, width, view.YOffset())
+		bottom, _ = wrapPosition(viewLines, width, view.YOffset()+view.Height()-1)
+		return top, min(bottom, len(text)-1)
+	}
+	top, bottom := lineRange(&v.Viewport)
+	var lines []string
+	if v.frozen != nil && v.frozen.Height() > 0 && !v.bulk.active {
+		frozenTop, frozenBottom := lineRange(v.frozen)
+		if frozenBottom < top {
+			lines = plainLines(text, frozenTop, frozenBottom)
+		} else {
+			top = min(top, frozenTop)
+		}
+	}
+	return append(lines, plainLines(text, top, bottom)...)
+}
+
+// plainLines returns text[top:bottom+1] without styling or trailing spaces.
+func plainLines(text []string, top, bottom int) []string {
+	if top > bottom {
+		return nil
+	}
+	lines := make([]string, 0, bottom-top+1)
+	for _, l := range text[top : bottom+1] {
+		lines = append(lines, strings.TrimRight(ansi.Strip(l), " "))
+	}
+	return lines
+}
+
+// SetHighlights marks content lines whose text matches any of patterns.
+// Nil or empty removes the marks.
+func (v *PTYViewport) SetHighlights(patterns []*regexp.Regexp) {
+	v.highlights = patterns
+	v.highlighted = nil
+	v.renderContent()
+	v.dirty = true
+}
+
+// maxHighlightCache bounds the highlight cache; it is dropped when full.
+const maxHighlightCache = 8192
+
+// applyHighlights restyles the lines of content matched by a highlight.
+func (v *PTYViewport) applyHighlights(content string) string {
+	if v.highlighted == nil || len(v.highlighted) > maxHighlightCache {
+		v.highlighted = make(map[string]bool)
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		hit, ok := v.highlighted[line]
+		if !ok {
+			plain := ansi.Strip(line)
+			hit = slices.ContainsFunc(v.highlights, func(re *regexp.Regexp) bool { return re.MatchString(plain) })
+			v.highlighted[line] = hit
+		}
+		if hit {
+			lines[i] = styles.TriggerHighlightStyle.Render(ansi.Strip(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RevealLine marks the content line reading text, trailing spaces aside,
+// and scrolls it to the middle of the view. Lines are matched from the
+// bottom up and skip passes over that many later lines with the same text.
+// It reports false when no such line is in the content.
+func (v *PTYViewport) RevealLine(text string, skip int) bool {
+	v.reconcileBulk()
+	want := strings.TrimRight(text, " ")
+	if want == "" {
+		return false
+	}
+	lines := strings.Split(v.content, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimRight(ansi.Strip(lines[i]), " ") != want {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		v.cited = i
+		v.renderContent()
+		v.dirty = true
+		view := v.scrolled()
+		row := wrappedRow(v.viewLines(), view.Width(), i, 0)
+		view.SetYOffset(max(row-view.Height()/2, 0))
+		return true
+	}
+	return false
+}
+
+// ClearCitation removes the mark set by RevealLine.
+func (v *PTYViewport) ClearCitation() {
+	if v.cited < 0 {
+		return
+	}
+	v.cited = -1
+	v.renderContent()
+	v.dirty = true
+}
+
+// markCited restyles the content line marked by RevealLine.
+func (v *PTYViewport) markCited(content string) string {
+	lines := strings.Split(content, "\n")
+	if v.cited >= len(lines) {
+		return content
+	}
+	lines[v.cited] = styles.CitedLineStyle.Render(ansi.Strip(lines[v.cited]))
+	return strings.Join(lines, "\n")
+}
+
 func (v *PTYViewport) renderContent() {
 	content := v.content
+	if len(v.highlights) > 0 {
+		content = v.applyHighlights(content)
+	}
+	if v.cited >= 0 {
+		content = v.markCited(content)
+	}
 	if !v.sel.IsEmpty() {
 		content = selection.ApplyHighlight(content, v.sel)
 	}
 	if v.cursorTracker == nil {
 		v.Viewport.SetContent(content)
+		if v.frozen != nil {
+			v.frozen.SetContent(content)
+		}
 		return
 	}
 	cursorChar := ""
@@ -250,10 +508,16 @@ func (v *PTYViewport) renderContent() {
 		cursorChar = "█"
 	}
 	v.Viewport.SetContent(v.cursorTracker.RenderCursorOverlay(content, cursorChar))
+	if v.frozen != nil {
+		v.frozen.SetContent(v.Viewport.GetContent())
+	}
 }
 
 func (v *PTYViewport) selectionContentPoint(screenRow, screenCol int, clamp bool) (int, int, bool) {
 	height := v.Viewport.Height()
+	if v.frozen != nil && v.frozen.Height() > 0 {
+		height = v.height
+	}
 	width := v.Viewport.Width()
 	if !v.ready || height <= 0 || width <= 0 {
 		return 0, 0, false
@@ -275,5 +539,13 @@ func (v *PTYViewport) selectionContentPoint(screenRow, screenCol int, clamp bool
 		return 0, 0, false
 	}
 
-	return v.Viewport.YOffset() + screenRow, v.Viewport.XOffset() + screenCol, true
+	view, row, ok := v.rowAt(screenRow)
+	if !ok {
+		if !clamp {
+			return 0, 0, false
+		}
+		view, row = v.frozen, v.frozen.Height()-1 // The divider
+	}
+	line, col := wrapPosition(v.viewLines(), width, view.YOffset()+row)
+	return line, col + screenCol, true
 }
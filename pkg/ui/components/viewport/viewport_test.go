@@ -1,8 +1,11 @@
 package viewport
 
 import (
+	"regexp"
 	"strings"
 	"testing"
+
+	"wtf_cli/pkg/ui/styles"
 )
 
 func TestNewPTYViewport(t *testing.T) {
@@ -377,3 +380,107 @@ func TestPTYViewport_AppendOutputClearsSelection(t *testing.T) {
 		t.Fatal("expected AppendOutput to clear selection")
 	}
 }
+
+func TestPTYViewport_CommandBlockAt(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 10)
+	vp.AppendOutput([]byte("welcome\r\n\x1b]133;A\x07$ \x1b[1mls\x1b[0m\r\na b\r\n\x1b]133;A\x07$ pwd\r\n/tmp\r\n\x1b]133;A\x07$ "))
+
+	got, ok := vp.CommandBlockAt(1)
+	if !ok || got != "$ ls\na b" {
+		t.Fatalf("CommandBlockAt(1) = %q, %v; want the ls block", got, ok)
+	}
+	if got, ok := vp.CommandBlockAt(3); !ok || got != "$ pwd\n/tmp" {
+		t.Fatalf("CommandBlockAt(3) = %q, %v; want the pwd block", got, ok)
+	}
+	for _, row := range []int{0, 2, 9} {
+		if got, ok := vp.CommandBlockAt(row); ok {
+			t.Errorf("CommandBlockAt(%d) = %q; want no block off a prompt line", row, got)
+		}
+	}
+}
+
+func TestPTYViewport_SetHighlights(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 10)
+	vp.AppendOutput([]byte("ok pkg/a\r\n\x1b[31mFAIL\x1b[0m pkg/b\r\nok pkg/c\r\n"))
+
+	vp.SetHighlights([]*regexp.Regexp{regexp.MustCompile(`FAIL`)})
+	view := vp.View()
+	if !strings.Contains(view, styles.TriggerHighlightStyle.Render("FAIL pkg/b")) {
+		t.Fatalf("Expected the FAIL line highlighted, got %q", view)
+	}
+	if strings.Contains(view, styles.TriggerHighlightStyle.Render("ok pkg/a")) {
+		t.Fatalf("Expected other lines untouched, got %q", view)
+	}
+
+	vp.SetHighlights(nil)
+	if view := vp.View(); strings.Contains(view, styles.TriggerHighlightStyle.Render("FAIL pkg/b")) {
+		t.Fatalf("Expected highlights removed, got %q", view)
+	}
+}
+
+func TestPTYViewport_RevealLine(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 2)
+	vp.AppendOutput([]byte("FAIL pkg/a\r\nok pkg/b\r\nFAIL pkg/a\r\nok pkg/c\r\nok pkg/d\r\nok pkg/e\r\n"))
+
+	if !vp.RevealLine("FAIL pkg/a", 1) {
+		t.Fatal("Expected the first FAIL line to be found")
+	}
+	if got := vp.Viewport.YOffset(); got != 0 {
+		t.Fatalf("Expected the view scrolled to the top, got offset %d", got)
+	}
+	if view := vp.View(); !strings.Contains(view, styles.CitedLineStyle.Render("FAIL pkg/a")) {
+		t.Fatalf("Expected the cited line marked, got %q", view)
+	}
+
+	if vp.RevealLine("FAIL pkg/a", 2) {
+		t.Fatal("Expected no third FAIL line")
+	}
+
+	vp.ClearCitation()
+	if view := vp.View(); strings.Contains(view, styles.CitedLineStyle.Render("FAIL pkg/a")) {
+		t.Fatalf("Expected the mark removed, got %q", view)
+	}
+}
+
+func TestPTYViewport_VisibleLines(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(40, 2)
+	vp.AppendOutput([]byte("one\r\ntwo\r\nthree\r\nfour"))
+
+	if got := strings.Join(vp.VisibleLines(), "|"); got != "three|four" {
+		t.Fatalf("VisibleLines at the bottom = %q, want %q", got, "three|four")
+	}
+	vp.ScrollUp()
+	vp.ScrollUp()
+	if got := strings.Join(vp.VisibleLines(), "|"); got != "one|two" {
+		t.Fatalf("VisibleLines scrolled back = %q, want %q", got, "one|two")
+	}
+}
+
+func TestPTYViewport_CursorOnWrappedCJKLine(t *testing.T) {
+	vp := NewPTYViewport()
+	vp.SetSize(10, 5)
+
+	vp.AppendOutput([]byte("用户$ 中文字"))
+	lines := strings.Split(vp.View(), "\n")
+	if !strings.HasPrefix(lines[1], "字█") {
+		t.Fatalf("expected the cursor after 字 on the wrapped row, got %q", vp.View())
+	}
+
+	// Left over 字 stays on the wrapped row.
+	vp.AppendOutput([]byte("\x1b[2D"))
+	lines = strings.Split(vp.View(), "\n")
+	if !strings.HasPrefix(lines[1], "\x1b[7m字\x1b[27m") {
+		t.Fatalf("expected the cursor on 字, got %q", vp.View())
+	}
+
+	// Up a row lands on the same column of the first row: 中.
+	vp.AppendOutput([]byte("\x1b[A\x1b[7G"))
+	lines = strings.Split(vp.View(), "\n")
+	if !strings.Contains(lines[0], "$ \x1b[7m中\x1b[27m文") {
+		t.Fatalf("expected the cursor on 中, got %q", vp.View())
+	}
+}
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/cmdrisk"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// confirmHistoryLines is how much of the shell history is searched for an
+// earlier run of a command waiting for confirmation.
+const confirmHistoryLines = 5000
+
+// typedConfirm is a command typed at the prompt that waits for y or Enter
+// before its Enter reaches the shell.
+type typedConfirm struct {
+	command string
+	reason  string
+}
+
+// confirmTypedCommand holds back Enter on a destructive command line and
+// asks in the status bar instead. It reports false when Enter should go to
+// the shell as usual.
+func (m Model) confirmTypedCommand() (Model, bool) {
+	if !m.confirmCommands.Enabled || m.inputHandler == nil {
+		return m, false
+	}
+	line := strings.TrimSpace(m.inputHandler.LineBuffer())
+	if line == "" {
+		return m, false
+	}
+	reason, ok := m.confirmReason(line)
+	if !ok {
+		return m, false
+	}
+	if !m.confirmCommands.AskAgain && m.ranBefore(line) {
+		slog.Debug("typed_confirm_skipped", "reason", "history")
+		return m, false
+	}
+	m.askTypedConfirm(line, reason)
+	return m, true
+}
+
+// askTypedConfirm holds line, already typed at the prompt, until y or Enter
+// runs it.
+func (m *Model) askTypedConfirm(line, reason string) {
+	m.typedConfirm = &typedConfirm{command: line, reason: reason}
+	slog.Info("typed_confirm_ask", "reason", reason)
+	m.postStatus(statusbar.Message{
+		Source:   statusSourceConfirm,
+		Text:     fmt.Sprintf("⚠ %s: %s · y/Enter runs it, any other key cancels", line, reason),
+		Priority: statusbar.PriorityHigh,
+	}, 0)
+}
+
+// confirmReason reports why line needs confirming: the built-in classifier
+// calls it destructive or a configured pattern matches it.
+func (m Model) confirmReason(line string) (string, bool) {
+	if risk := cmdrisk.Classify(line); risk.Level == cmdrisk.Destructive {
+		return risk.Reason, true
+	}
+	for _, p := range m.confirmCommands.Patterns {
+		if cmdrisk.MatchPattern(p, line) {
+			return fmt.Sprintf("matches %q", strings.TrimSpace(p)), true
+		}
+	}
+	return "", false
+}
+
+// ranBefore reports whether line was run earlier this session or is in the
+// shell history or synced history, so the user has typed it on purpose before.
+func (m Model) ranBefore(line string) bool {
+	want := capture.NormalizeHistoryCommand(line)
+	if m.session != nil {
+		for _, rec := range m.session.GetHistory() {
+			if capture.NormalizeHistoryCommand(rec.Command) == want {
+				return true
+			}
+		}
+	}
+	if m.syncedHistory != nil {
+		for _, rec := range m.syncedHistory() {
+			if capture.NormalizeHistoryCommand(rec.Command) == want {
+				return true
+			}
+		}
+	}
+	return m.knownCommands[want]
+}
+
+// shellHistoryLoadedMsg carries the shell history read for the confirm
+// check.
+type shellHistoryLoadedMsg struct {
+	commands []string
+	err      error
+}
+
+// loadShellHistory reads the shell history once, off the UI goroutine, when
+// confirming skips commands run before. Until it arrives every destructive
+// command is asked about.
+func (m Model) loadShellHistory() tea.Cmd {
+	if !m.confirmCommands.Enabled || m.confirmCommands.AskAgain || m.knownCommands != nil || m.shellHistory == nil {
+		return nil
+	}
+	read, environ := m.shellHistory, m.environFunc
+	return func() tea.Msg {
+		var entries []string
+		if environ != nil {
+			if e, err := environ(); err == nil {
+				entries = e
+			}
+		}
+		commands, err := read(entries)
+		return shellHistoryLoadedMsg{commands: commands, err: err}
+	}
+}
+
+func (m Model) handleShellHistoryLoaded(msg shellHistoryLoadedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		slog.Debug("typed_confirm_history_error", "error", msg.err)
+	}
+	known := make(map[string]bool, len(msg.commands))
+	for _, cmd := range msg.commands {
+		known[capture.NormalizeHistoryCommand(cmd)] = true
+	}
+	m.knownCommands = known
+	return m, nil
+}
+
+// answerTypedConfirm sends the held-back Enter on y or Enter and otherwise
+// cancels, leaving the command at the prompt to edit. The key itself never
+// reaches the shell.
+func (m Model) answerTypedConfirm(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	m.typedConfirm = nil
+	m.clearStatus(statusSourceConfirm)
+	switch msg.String() {
+	case "y", "Y", "enter":
+		slog.Info("typed_confirm_run")
+		_, cmd := m.inputHandler.HandleKey(tea.KeyPressMsg{Code: tea.KeyEnter})
+		return m, cmd
+	}
+	slog.Info("typed_confirm_cancel")
+	return m, m.flashStatus("Not run; the command is still at the prompt")
+}
+
+// defaultShellHistory reads the history file of the shell with environ,
+// falling back to wtf_cli's own $SHELL and $HISTFILE when the shell's
+// environment is unknown.
+func defaultShellHistory(environ []string) ([]string, error) {
+	shell, histFile := os.Getenv("SHELL"), os.Getenv("HISTFILE")
+	if len(environ) > 0 {
+		shell, histFile = "", ""
+		for _, entry := range environ {
+			name, value, _ := strings.Cut(entry, "=")
+			switch name {
+			case "SHELL":
+				shell = value
+			case "HISTFILE":
+				histFile = value
+			}
+		}
+	}
+	path, err := capture.HistoryFilePathFor(shell, histFile)
+	if err != nil {
+		return nil, err
+	}
+	return capture.ReadHistoryFile(path, confirmHistoryLines)
+}
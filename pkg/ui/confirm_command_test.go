@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newConfirmTestModel(t *testing.T, cfg config.ConfirmCommandsConfig, history ...string) (Model, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.inputHandler = input.NewInputHandler(&out)
+	m.confirmCommands = cfg
+	m.shellHistory = func([]string) ([]string, error) { return history, nil }
+	if cmd := m.loadShellHistory(); cmd != nil {
+		m, _ = m.handleShellHistoryLoaded(cmd().(shellHistoryLoadedMsg))
+	}
+	return m, &out
+}
+
+func typeLine(t *testing.T, m Model, line string) Model {
+	t.Helper()
+	for _, r := range line {
+		m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return m
+}
+
+var enterKey = tea.KeyPressMsg{Code: tea.KeyEnter}
+
+func TestTypedConfirm_AsksBeforeDestructiveCommand(t *testing.T) {
+	m, out := newConfirmTestModel(t, config.ConfirmCommandsConfig{Enabled: true})
+	m = typeLine(t, m, "rm -rf build")
+	out.Reset()
+
+	m, cmd := m.handleKeyPress(enterKey)
+	if cmd != nil || m.typedConfirm == nil || strings.Contains(out.String(), "\r") {
+		t.Fatalf("expected Enter to be held back, wrote %q", out.String())
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "rm -rf build: deletes files") {
+		t.Fatalf("expected the question in the status bar, got %q", msg)
+	}
+
+	m, cmd = m.handleKeyPress(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if out.String() != "\r" || m.typedConfirm != nil {
+		t.Fatalf("expected y to send Enter, wrote %q", out.String())
+	}
+	if cmd == nil {
+		t.Fatal("expected the command to be submitted")
+	}
+	if submitted, ok := cmd().(input.CommandSubmittedMsg); !ok || submitted.Command != "rm -rf build" {
+		t.Fatalf("submitted %#v", cmd())
+	}
+	if m.statusBar.GetMessage() != "" {
+		t.Fatalf("expected the question cleared, got %q", m.statusBar.GetMessage())
+	}
+}
+
+func TestTypedConfirm_OtherKeyCancels(t *testing.T) {
+	m, out := newConfirmTestModel(t, config.ConfirmCommandsConfig{Enabled: true, Patterns: []string{"terraform destroy"}})
+	m = typeLine(t, m, "terraform destroy -auto-approve")
+	m, _ = m.handleKeyPress(enterKey)
+	if m.typedConfirm == nil {
+		t.Fatal("expected a configured pattern to ask")
+	}
+	out.Reset()
+	m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if out.Len() != 0 || m.typedConfirm != nil {
+		t.Fatalf("expected the answer to be swallowed, wrote %q", out.String())
+	}
+	if m.inputHandler.LineBuffer() != "terraform destroy -auto-approve" {
+		t.Fatalf("expected the command kept at the prompt, got %q", m.inputHandler.LineBuffer())
+	}
+}
+
+func TestTypedConfirm_SkipsCommandsRunBefore(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ConfirmCommandsConfig
+		line string
+		ask  bool
+	}{
+		{"disabled", config.ConfirmCommandsConfig{}, "rm -rf build", false},
+		{"safe", config.ConfirmCommandsConfig{Enabled: true}, "ls -la", false},
+		{"in shell history", config.ConfirmCommandsConfig{Enabled: true}, "rm  -rf dist", false},
+		{"ask again", config.ConfirmCommandsConfig{Enabled: true, AskAgain: true}, "rm -rf dist", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, out := newConfirmTestModel(t, tt.cfg, "git status", "rm -rf dist")
+			m = typeLine(t, m, tt.line)
+			out.Reset()
+			m, _ = m.handleKeyPress(enterKey)
+			if asked := m.typedConfirm != nil; asked != tt.ask {
+				t.Fatalf("asked = %v, want %v (wrote %q)", asked, tt.ask, out.String())
+			}
+		})
+	}
+}
+
+func TestTypedConfirm_LoadsShellHistoryOnceFromTheShellEnvironment(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.inputHandler = input.NewInputHandler(&bytes.Buffer{})
+	m.confirmCommands = config.ConfirmCommandsConfig{Enabled: true}
+	m.environFunc = func() ([]string, error) { return []string{"SHELL=/bin/zsh", "HISTFILE=/tmp/zh"}, nil }
+	reads := 0
+	var gotEnv []string
+	m.shellHistory = func(environ []string) ([]string, error) {
+		reads++
+		gotEnv = environ
+		return []string{"rm -rf dist"}, nil
+	}
+
+	m = typeLine(t, m, "rm -rf dist")
+	m, _ = m.handleKeyPress(enterKey)
+	if reads != 0 {
+		t.Fatalf("expected no history read on Enter, got %d", reads)
+	}
+	if m.typedConfirm == nil {
+		t.Fatal("expected to ask while the history is still loading")
+	}
+	m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: 'n', Text: "n"})
+
+	cmd := m.loadShellHistory()
+	if cmd == nil {
+		t.Fatal("expected a Cmd loading the history")
+	}
+	m, _ = m.handleShellHistoryLoaded(cmd().(shellHistoryLoadedMsg))
+	if reads != 1 || strings.Join(gotEnv, " ") != "SHELL=/bin/zsh HISTFILE=/tmp/zh" {
+		t.Fatalf("reads = %d, environ = %v", reads, gotEnv)
+	}
+	if m.loadShellHistory() != nil {
+		t.Fatal("expected the history to load only once")
+	}
+	m, _ = m.handleKeyPress(enterKey)
+	if m.typedConfirm != nil || reads != 1 {
+		t.Fatalf("expected the cached history to skip the question (reads %d)", reads)
+	}
+}
+
+func TestDefaultShellHistory_ReadsTheShellsHistoryFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("HISTFILE", "")
+	if err := os.WriteFile(filepath.Join(dir, ".zsh_history"), []byte(": 1700000000:0;rm -rf dist\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := defaultShellHistory([]string{"SHELL=/usr/bin/zsh"})
+	if err != nil {
+		t.Fatalf("defaultShellHistory: %v", err)
+	}
+	if len(got) != 1 || got[0] != "rm -rf dist" {
+		t.Fatalf("history = %q, want the zsh history", got)
+	}
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"log/slog"
+	"time"
+
+	"wtf_cli/pkg/control"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// defaultControlTailLines is how many lines buffer_tail returns when the
+	// client does not ask for a number.
+	defaultControlTailLines = 50
+)
+
+// controlCallMsg carries a call from an automation client.
+type controlCallMsg struct {
+	call *control.Call
+}
+
+// SetControlServer lets automation clients of srv drive this session.
+func (m *Model) SetControlServer(srv *control.Server) {
+	m.controlServer = srv
+}
+
+// listenControl waits for the next automation call.
+func listenControl(srv *control.Server) tea.Cmd {
+	if srv == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		call, ok := srv.Next()
+		if !ok {
+			return nil
+		}
+		return controlCallMsg{call: call}
+	}
+}
+
+// handleControlCall answers an automation call on the UI goroutine, where
+// the shell, buffer and conversation can be used safely.
+func (m Model) handleControlCall(msg controlCallMsg) (Model, tea.Cmd) {
+	call := msg.call
+	next := listenControl(m.controlServer)
+	slog.Info("control_call", "method", call.Method)
+
+	switch call.Method {
+	case control.MethodInput:
+		var params control.InputParams
+		if err := call.Decode(&params); err != nil {
+			call.Reply(nil, err)
+			return m, next
+		}
+		if m.inputHandler == nil || m.shellExited {
+			call.Reply(nil, control.Errorf(control.CodeServerError, "no shell is running"))
+			return m, next
+		}
+		text := params.Text
+		if params.Enter {
+			text += "\r"
+		}
+		if err := m.inputHandler.SendToPTY([]byte(text)); err != nil {
+			call.Reply(nil, control.Errorf(control.CodeServerError, "write to shell: %v", err))
+			return m, next
+		}
+		call.Reply(control.OK{OK: true}, nil)
+
+	case control.MethodBufferTail:
+		params := control.BufferTailParams{Lines: defaultControlTailLines}
+		if err := call.Decode(&params); err != nil {
+			call.Reply(nil, err)
+			return m, next
+		}
+		if params.Lines <= 0 {
+			call.Reply(nil, control.Errorf(control.CodeInvalidParams, "lines must be positive"))
+			return m, next
+		}
+		lines := []string{}
+		if m.buffer != nil {
+			for _, line := range m.buffer.GetLastN(params.Lines) {
+				lines = append(lines, ansi.Strip(string(line)))
+			}
+		}
+		call.Reply(control.BufferTailResult{Lines: lines}, nil)
+
+	case control.MethodExplain:
+		if m.hasActiveStream() {
+			call.Reply(nil, control.Errorf(control.CodeServerError, "an AI request is already running"))
+			return m, next
+		}
+		call.Reply(control.OK{OK: true}, nil)
+		return m, tea.Batch(next, func() tea.Msg {
+			return palette.PaletteSelectMsg{Command: "/explain"}
+		})
+
+	case control.MethodLastAnswer:
+		call.Reply(m.lastAnswer(), nil)
+
+	case control.MethodStatus:
+		var params control.StatusParams
+		if err := call.Decode(&params); err != nil {
+			call.Reply(nil, err)
+			return m, next
+		}
+		priority, ok := statusbar.ParsePriority(params.Priority)
+		if !ok {
+			call.Reply(nil, control.Errorf(control.CodeInvalidParams, "priority must be low, normal or high"))
+			return m, next
+		}
+		if params.TTLSeconds < 0 {
+			call.Reply(nil, control.Errorf(control.CodeInvalidParams, "ttl_seconds must not be negative"))
+			return m, next
+		}
+		var cmd tea.Cmd
+		m, cmd = m.handleStatusMessage(StatusMessageMsg{
+			Source:   "control:" + params.Source,
+			Text:     params.Text,
+			Priority: priority,
+			TTL:      time.Duration(params.TTLSeconds) * time.Second,
+		})
+		call.Reply(control.OK{OK: true}, nil)
+		return m, tea.Batch(next, cmd)
+
+	default:
+		call.Reply(nil, control.Errorf(control.CodeMethodNotFound, "unknown method %q", call.Method))
+	}
+	return m, next
+}
+
+// lastAnswer returns the conversation's latest assistant message. The
+// "Thinking..." placeholder is not an answer yet.
+func (m Model) lastAnswer() control.LastAnswerResult {
+	result := control.LastAnswerResult{Streaming: m.hasActiveStream()}
+	if m.sidebar == nil {
+		return result
+	}
+	messages := m.sidebar.GetMessages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		if i == len(messages)-1 && m.aiJobs.placeholder {
+			return result
+		}
+		result.Content = messages[i].Content
+		return result
+	}
+	return result
+}
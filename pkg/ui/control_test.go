@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/control"
+)
+
+// controlRoundTrip sends one call through a real socket and lets m answer
+// it, returning the client's error.
+func controlRoundTrip(t *testing.T, m Model, method string, params, result any) error {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "wtfctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	srv, err := control.Listen(filepath.Join(dir, "s.sock"))
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer srv.Close()
+	m.SetControlServer(srv)
+
+	client, err := control.Dial(srv.Path())
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer client.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- client.Call(method, params, result) }()
+	call, ok := srv.Next()
+	if !ok {
+		t.Fatal("Expected a call")
+	}
+	m.handleControlCall(controlCallMsg{call: call})
+	return <-done
+}
+
+func TestControl_BufferTailStripsEscapes(t *testing.T) {
+	buf := buffer.New(100)
+	for _, line := range []string{"one", "\x1b[31mtwo\x1b[0m", "three"} {
+		buf.Write([]byte(line))
+	}
+	m := NewModel(nil, buf, capture.NewSessionContext(), nil)
+
+	var result control.BufferTailResult
+	if err := controlRoundTrip(t, m, control.MethodBufferTail, control.BufferTailParams{Lines: 2}, &result); err != nil {
+		t.Fatalf("buffer_tail error: %v", err)
+	}
+	if len(result.Lines) != 2 || result.Lines[0] != "two" || result.Lines[1] != "three" {
+		t.Fatalf("Expected [two three], got %q", result.Lines)
+	}
+}
+
+func TestControl_InputWithoutShellFails(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	err := controlRoundTrip(t, m, control.MethodInput, control.InputParams{Text: "ls", Enter: true}, nil)
+	if err == nil {
+		t.Fatal("Expected an error without a shell")
+	}
+}
+
+func TestControl_UnknownMethod(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	err := controlRoundTrip(t, m, "reboot", nil, nil)
+	rpcErr, ok := err.(*control.Error)
+	if !ok || rpcErr.Code != control.CodeMethodNotFound {
+		t.Fatalf("Expected method-not-found, got %v", err)
+	}
+}
+
+func TestControl_StatusPostsAndClearsMessage(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.flashStatus("Saved")
+
+	params := control.StatusParams{Source: "deploy", Text: "Deploying 3/5", Priority: "high"}
+	if err := controlRoundTrip(t, m, control.MethodStatus, params, nil); err != nil {
+		t.Fatalf("status error: %v", err)
+	}
+	if got := m.statusBar.GetMessage(); got != "Deploying 3/5" {
+		t.Fatalf("Expected the posted message on top, got %q", got)
+	}
+
+	params.Text = ""
+	if err := controlRoundTrip(t, m, control.MethodStatus, params, nil); err != nil {
+		t.Fatalf("status error: %v", err)
+	}
+	if got := m.statusBar.GetMessage(); got != "Saved" {
+		t.Fatalf("Expected the earlier toast back after clearing, got %q", got)
+	}
+
+	params.Priority = "urgent"
+	if err := controlRoundTrip(t, m, control.MethodStatus, params, nil); err == nil {
+		t.Fatal("Expected an error for an unknown priority")
+	}
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/envpicker"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// SetEnvironFunc sets how the shell's environment is read for /env.
+func (m *Model) SetEnvironFunc(fn func() ([]string, error)) {
+	m.environFunc = fn
+}
+
+// commandContext builds the context for an AI request, including the
+// environment variables shared for the current project, pinned output and
+// the workspace trust that decides how much of it is sent.
+func (m Model) commandContext() *commands.Context {
+	ctx := commands.NewContext(m.buffer, m.session, m.currentDir)
+	ctx.Env = m.sharedEnv()
+	ctx.VirtualEnv = m.shellEnvValue("VIRTUAL_ENV")
+	ctx.Pinned = append([]ai.PinnedBlock(nil), m.pinnedBlocks...)
+	ctx.Trust = m.workspaceTrust()
+	ctx.REPL = m.activeREPL()
+	ctx.Jobs = m.backgroundJobs()
+	ctx.Memories = m.memories()
+	ctx.MemoriesPath = m.memoriesFile()
+	ctx.Workspace = m.workspace.index
+	ctx.Visible = m.viewport.VisibleLines()
+	ctx.Selection = m.lastSelection
+	ctx.ProviderOverride = m.providerOverride
+	ctx.ModelOverride = m.modelOverride
+	if m.privacy {
+		// Nothing captured from the terminal goes to the AI.
+		ctx.Buffer = nil
+		ctx.Session = nil
+		ctx.Visible = nil
+		ctx.Jobs = nil
+		ctx.REPL = nil
+	}
+	return ctx
+}
+
+// sharedEnv returns the variables ticked in /env for the current project,
+// with their values as the shell has them now.
+func (m Model) sharedEnv() []ai.EnvVar {
+	if m.environFunc == nil {
+		return nil
+	}
+	names := ai.LoadEnvSelection(m.envSelectionsFile(), ai.ProjectRoot(m.currentDir))
+	if len(names) == 0 {
+		return nil
+	}
+	entries, err := m.environFunc()
+	if err != nil {
+		slog.Warn("env_read_failed", "error", err)
+		return nil
+	}
+	return ai.SelectEnv(ai.ParseEnviron(entries), names)
+}
+
+// shellEnvValue returns the value of name in the shell's environment, or ""
+// when it is unset or the environment cannot be read.
+func (m Model) shellEnvValue(name string) string {
+	if m.environFunc == nil {
+		return ""
+	}
+	entries, err := m.environFunc()
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if value, ok := strings.CutPrefix(entry, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func (m Model) envSelectionsFile() string {
+	if m.envSelectionsPath != "" {
+		return m.envSelectionsPath
+	}
+	return ai.DefaultEnvSelectionsPath()
+}
+
+// openEnvPicker shows the shell's environment with the current project's
+// selection ticked.
+func (m Model) openEnvPicker() (Model, tea.Cmd) {
+	if m.environFunc == nil {
+		return m, m.flashStatus("Shell environment is not available")
+	}
+	entries, err := m.environFunc()
+	if err != nil {
+		slog.Warn("env_read_failed", "error", err)
+		return m, m.flashStatus("Could not read shell environment: " + err.Error())
+	}
+
+	project := ai.ProjectRoot(m.currentDir)
+	selected := ai.LoadEnvSelection(m.envSelectionsFile(), project)
+	vars := ai.ParseEnviron(entries)
+	slog.Info("env_picker_open", "project", project, "vars", len(vars), "selected", len(selected))
+
+	if m.envPicker == nil {
+		m.envPicker = envpicker.NewEnvPickerPanel()
+	}
+	m.envPicker.SetSize(m.width, m.height)
+	m.envPicker.Show(project, vars, selected)
+	return m, nil
+}
+
+func (m Model) handleEnvPickerSave(msg envpicker.EnvPickerSaveMsg) (Model, tea.Cmd) {
+	if err := ai.SaveEnvSelection(m.envSelectionsFile(), msg.Project, msg.Selected); err != nil {
+		slog.Error("env_selection_save_error", "error", err)
+		return m, m.flashStatus("Could not save environment selection: " + err.Error())
+	}
+	slog.Info("env_selection_saved", "project", msg.Project, "selected", len(msg.Selected))
+	if len(msg.Selected) == 0 {
+		return m, m.flashStatus("No environment variables shared")
+	}
+	return m, m.flashStatus(fmt.Sprintf("Sharing %d environment variable(s) with AI", len(msg.Selected)))
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/envpicker"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func newEnvTestModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = t.TempDir()
+	m.envSelectionsPath = filepath.Join(t.TempDir(), "env_selections.json")
+	m.SetEnvironFunc(func() ([]string, error) {
+		return []string{"PATH=/usr/bin", "GOFLAGS=-mod=vendor", "GH_TOKEN=ghp_x"}, nil
+	})
+	return m
+}
+
+func TestModel_EnvPaletteOpensPickerAndSavesSelection(t *testing.T) {
+	m := newEnvTestModel(t)
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/env"})
+	if m.envPicker == nil || !m.envPicker.IsVisible() {
+		t.Fatal("expected env picker to be visible")
+	}
+	if !m.hasBlockingOverlay() {
+		t.Fatal("expected env picker to block input")
+	}
+
+	// Vars are sorted: GH_TOKEN, GOFLAGS, PATH. Tick GOFLAGS and the token.
+	m, _ = m.handleKeyPress(testutils.TestKeySpace)
+	m, _ = m.handleKeyPress(testutils.TestKeyDown)
+	m, _ = m.handleKeyPress(testutils.TestKeySpace)
+	m, cmd := m.handleKeyPress(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("expected save command")
+	}
+	save, ok := cmd().(envpicker.EnvPickerSaveMsg)
+	if !ok {
+		t.Fatalf("expected EnvPickerSaveMsg, got %T", cmd())
+	}
+	m, _ = m.handleEnvPickerSave(save)
+
+	got := m.sharedEnv()
+	want := []ai.EnvVar{
+		{Name: "GH_TOKEN", Value: "ghp_x", Secret: true},
+		{Name: "GOFLAGS", Value: "-mod=vendor"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sharedEnv() = %+v, want %+v", got, want)
+	}
+	if ctx := m.commandContext(); !reflect.DeepEqual(ctx.Env, want) {
+		t.Fatalf("expected command context to carry the shared env, got %+v", ctx.Env)
+	}
+
+	other := m
+	other.currentDir = t.TempDir()
+	if env := other.sharedEnv(); env != nil {
+		t.Fatalf("expected selection to be per project, got %+v", env)
+	}
+}
+
+func TestModel_EnvPickerUnavailableFlashes(t *testing.T) {
+	m := newEnvTestModel(t)
+	m.SetEnvironFunc(func() ([]string, error) { return nil, errors.New("not supported") })
+
+	m, cmd := m.openEnvPicker()
+	if cmd == nil {
+		t.Fatal("expected a status toast command")
+	}
+	if m.envPicker != nil && m.envPicker.IsVisible() {
+		t.Fatal("expected no picker when the environment cannot be read")
+	}
+}
+
+func TestModel_CommandContextTakesVirtualEnvFromTheShell(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "/wtf_cli/own/venv")
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	if got := m.commandContext().VirtualEnv; got != "" {
+		t.Fatalf("VirtualEnv = %q without a shell environment, want empty", got)
+	}
+
+	m.SetEnvironFunc(func() ([]string, error) {
+		return []string{"PATH=/usr/bin", "VIRTUAL_ENV=/src/app/.venv"}, nil
+	})
+	if got := m.commandContext().VirtualEnv; got != "/src/app/.venv" {
+		t.Fatalf("VirtualEnv = %q, want the shell's", got)
+	}
+}
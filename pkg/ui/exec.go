@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wtf_cli/pkg/capture"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// execState is the single command `wtf_cli exec` runs in place of a shell.
+type execState struct {
+	command  string     // Command line, shown and recorded in the session
+	exitCode func() int // Waits for the command and returns its status
+	done     bool       // The command exited with status
+	status   int
+}
+
+// execExitMsg carries the exit status of the exec command.
+type execExitMsg struct {
+	status int
+}
+
+// SetExecCommand makes the terminal run one command, for `wtf_cli exec`.
+// The command is recorded as the session's first, so /explain and the chat
+// see it with its output; exitCode is called once the PTY closes.
+func (m *Model) SetExecCommand(command string, exitCode func() int) {
+	m.exec = &execState{command: command, exitCode: exitCode}
+	if m.session == nil {
+		return
+	}
+	now := time.Now()
+	m.session.AddCommand(capture.CommandRecord{
+		Command:    command,
+		ExitCode:   -1,
+		StartTime:  now,
+		EndTime:    now,
+		WorkingDir: m.currentDir,
+		Marked:     true, // Output starts with the buffer and ends with the PTY
+	})
+}
+
+// execRunning reports whether the exec command has yet to exit.
+func (m Model) execRunning() bool {
+	return m.exec != nil && !m.exec.done
+}
+
+// waitExec waits for the exec command in the background once its PTY
+// closed.
+func (m Model) waitExec() tea.Cmd {
+	exitCode := m.exec.exitCode
+	return func() tea.Msg {
+		return execExitMsg{status: exitCode()}
+	}
+}
+
+// handleExecExit records how the exec command ended and offers the
+// end-of-session choices, asking the AI first when it failed.
+func (m Model) handleExecExit(msg execExitMsg) (Model, tea.Cmd) {
+	if m.exec == nil {
+		return m, nil
+	}
+	m.exec.done = true
+	m.exec.status = msg.status
+	m.finishMarkedCommand(msg.status)
+	slog.Info("exec_exit", "command", m.exec.command, "exit_code", msg.status)
+	hooksCmd := m.fireHooks()
+	m, cmd := m.openSessionEnd()
+	return m, tea.Batch(hooksCmd, cmd)
+}
+
+// sessionEndTitle says what ended: the shell, or the exec command and its
+// status.
+func (m Model) sessionEndTitle() string {
+	if m.exec == nil || !m.exec.done {
+		return "The shell exited"
+	}
+	if m.exec.status == 0 {
+		return fmt.Sprintf("%s finished", truncateNotificationDetail(m.exec.command))
+	}
+	return fmt.Sprintf("%s failed with exit status %d", truncateNotificationDetail(m.exec.command), m.exec.status)
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestExec_FailureOffersAskingTheAI(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.SetExecCommand("make test", func() int { return 2 })
+	m.buffer.Write([]byte("--- FAIL: TestParse\n"))
+
+	m, cmd := m.handlePTYError(ptyErrorMsg{err: io.EOF})
+	if cmd == nil || m.shellExited {
+		t.Fatal("Expected the exit status to be waited for first")
+	}
+	msg, ok := cmd().(execExitMsg)
+	if !ok || msg.status != 2 {
+		t.Fatalf("Expected execExitMsg{2}, got %#v", msg)
+	}
+
+	m, _ = m.handleExecExit(msg)
+	if !m.shellExited || m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the end-of-session choices")
+	}
+	if got := m.optionPicker.View(); !strings.Contains(got, "make test failed with exit status 2") {
+		t.Errorf("Expected the command's status in the title, got:\n%s", got)
+	}
+	if got := pickedOption(t, m); got != sessionEndAsk {
+		t.Errorf("Expected %q selected, got %q", sessionEndAsk, got)
+	}
+
+	rec := m.session.GetLastN(1)[0]
+	if rec.Command != "make test" || rec.ExitCode != 2 || rec.BufferEnd == 0 {
+		t.Fatalf("Expected the command recorded with its status and output, got %+v", rec)
+	}
+	if got := m.buffer.LinesBetween(rec.BufferStart, rec.BufferEnd); len(got) != 1 || !strings.Contains(string(got[0]), "FAIL") {
+		t.Errorf("Expected the command's output, got %q", got)
+	}
+}
+
+func TestExec_SuccessTitle(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.SetExecCommand("go build ./...", func() int { return 0 })
+	m.SetPaneSpawner(func() (PaneShell, error) { return PaneShell{}, nil })
+
+	m, _ = m.handleExecExit(execExitMsg{status: 0})
+	if got := m.sessionEndTitle(); got != "go build ./... finished" {
+		t.Errorf("sessionEndTitle() = %q", got)
+	}
+	if got := pickedOption(t, m); got != sessionEndRestart {
+		t.Errorf("Expected the first choice selected after a success, got %q", got)
+	}
+}
+
+// pickedOption returns what Enter picks in the option picker.
+func pickedOption(t *testing.T, m Model) string {
+	t.Helper()
+	cmd := m.optionPicker.Update(testutils.TestKeyEnter)
+	if cmd == nil {
+		t.Fatal("Expected Enter to pick an option")
+	}
+	return cmd().(picker.OptionPickerSelectMsg).Value
+}
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// exportScriptFieldKey routes the destination picker shown when exporting
+// suggested commands as a shell script.
+const exportScriptFieldKey = "export_script"
+
+// Export destinations, in display order.
+const (
+	exportScriptHere     = "Save in current directory"
+	exportScriptHereEdit = "Save in current directory and open in $EDITOR"
+	exportScriptScripts  = "Save in ~/.wtf_cli/scripts"
+	exportScriptName     = "wtf-commands"
+)
+
+// pendingScript is a script waiting for the user to pick where it goes.
+type pendingScript struct {
+	script string
+	count  int
+}
+
+// scriptEditorClosedMsg reports that the editor opened on an exported
+// script has exited.
+type scriptEditorClosedMsg struct {
+	path string
+	err  error
+}
+
+// openExportScriptPicker builds a script from messages and asks where to
+// save it.
+func (m Model) openExportScriptPicker(messages []ai.ChatMessage) (Model, tea.Cmd) {
+	title := ""
+	if m.sidebar != nil {
+		title = m.sidebar.Title()
+	}
+	script, count := sidebar.BuildCommandScript(messages, title, time.Now())
+	if count == 0 {
+		return m, m.flashStatus("No suggested commands to export")
+	}
+	m.pendingScript = &pendingScript{script: script, count: count}
+
+	options := []string{exportScriptHere}
+	if scriptEditor() != "" {
+		options = append(options, exportScriptHereEdit)
+	}
+	options = append(options, exportScriptScripts)
+
+	slog.Info("export_script_picker_open", "commands", count)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Export commands as script", exportScriptFieldKey, options, "")
+	return m, nil
+}
+
+// exportScript writes the pending script to the chosen destination.
+func (m Model) exportScript(choice string) (Model, tea.Cmd) {
+	pending := m.pendingScript
+	m.pendingScript = nil
+	if pending == nil {
+		return m, nil
+	}
+
+	dir := m.currentDir
+	if choice == exportScriptScripts {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return m, m.flashStatus("Could not find home directory: " + err.Error())
+		}
+		dir = filepath.Join(home, ".wtf_cli", "scripts")
+	} else if dir == "" {
+		dir, _ = os.Getwd()
+	}
+
+	path, err := writeScript(dir, pending.script)
+	if err != nil {
+		slog.Error("export_script_error", "dir", dir, "error", err)
+		return m, m.flashStatus("Could not save script: " + err.Error())
+	}
+	slog.Info("export_script_saved", "path", path, "commands", pending.count)
+	status := m.flashStatus(fmt.Sprintf("Saved %d command(s) to %s", pending.count, path))
+
+	if choice != exportScriptHereEdit {
+		return m, status
+	}
+	fields := strings.Fields(scriptEditor())
+	if len(fields) == 0 {
+		return m, status
+	}
+	editor := exec.Command(fields[0], append(fields[1:], path)...)
+	return m, tea.Batch(status, tea.ExecProcess(editor, func(err error) tea.Msg {
+		return scriptEditorClosedMsg{path: path, err: err}
+	}))
+}
+
+func (m Model) handleScriptEditorClosed(msg scriptEditorClosedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		slog.Error("export_script_editor_error", "path", msg.path, "error", msg.err)
+		return m, m.flashStatus("Editor failed: " + msg.err.Error())
+	}
+	return m, nil
+}
+
+// writeScript saves script as an executable file in dir without replacing
+// an earlier export.
+func writeScript(dir, script string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	for i := 0; ; i++ {
+		name := exportScriptName + ".sh"
+		if i > 0 {
+			name = exportScriptName + "-" + strconv.Itoa(i) + ".sh"
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.WriteString(script); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
+
+// scriptEditor returns the user's editor command, preferring $VISUAL.
+func scriptEditor() string {
+	if editor := strings.TrimSpace(os.Getenv("VISUAL")); editor != "" {
+		return editor
+	}
+	return strings.TrimSpace(os.Getenv("EDITOR"))
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/sidebar"
+)
+
+func TestModel_MessageActionExportWritesScript(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	m := newMessageActionTestModel()
+	m.currentDir = t.TempDir()
+
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 1})
+	m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{
+		FieldKey: messageActionFieldKey,
+		Value:    messageActionExportCmds,
+	})
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected destination picker to be visible")
+	}
+	if strings.Contains(m.optionPicker.View(), "$EDITOR") {
+		t.Error("expected no editor option without $EDITOR")
+	}
+
+	for range 2 {
+		m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 1})
+		m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: messageActionFieldKey, Value: messageActionExportCmds})
+		m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: exportScriptFieldKey, Value: exportScriptHere})
+	}
+
+	path := filepath.Join(m.currentDir, "wtf-commands.sh")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected script at %s: %v", path, err)
+	}
+	if !strings.Contains(string(data), "set -euo pipefail\n") || !strings.Contains(string(data), "\nmake clean\n") {
+		t.Fatalf("unexpected script:\n%s", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("expected executable script, got %v (%v)", info.Mode(), err)
+	}
+	if _, err := os.Stat(filepath.Join(m.currentDir, "wtf-commands-1.sh")); err != nil {
+		t.Fatalf("expected second export not to overwrite the first: %v", err)
+	}
+	if m.pendingScript != nil {
+		t.Fatal("expected pending script to be cleared")
+	}
+}
+
+func TestModel_ExportScriptPaletteSavesToScriptsDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "vi")
+	m := newMessageActionTestModel()
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/export"})
+	if !strings.Contains(m.optionPicker.View(), exportScriptHereEdit) {
+		t.Error("expected editor option when $EDITOR is set")
+	}
+	m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: exportScriptFieldKey, Value: exportScriptScripts})
+
+	if _, err := os.Stat(filepath.Join(home, ".wtf_cli", "scripts", "wtf-commands.sh")); err != nil {
+		t.Fatalf("expected script in scripts dir: %v", err)
+	}
+}
+
+func TestModel_ExportScriptWithoutCommandsFlashes(t *testing.T) {
+	m := newMessageActionTestModel()
+	m, cmd := m.openExportScriptPicker(nil)
+	if cmd == nil {
+		t.Fatal("expected a status toast command")
+	}
+	if m.pendingScript != nil || (m.optionPicker != nil && m.optionPicker.IsVisible()) {
+		t.Fatal("expected nothing to export")
+	}
+}
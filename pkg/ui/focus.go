@@ -1,6 +1,10 @@
 package ui
 
-import "log/slog"
+import (
+	"log/slog"
+
+	"wtf_cli/pkg/ai"
+)
 
 // hasBlockingOverlay reports whether an overlay is active that should absorb
 // input and mouse events before terminal/sidebar routing.
@@ -8,25 +12,8 @@ func (m *Model) hasBlockingOverlay() bool {
 	if m.fullScreenMode {
 		return true
 	}
-	if m.settingsPanel != nil && m.settingsPanel.IsVisible() {
-		return true
-	}
-	if m.palette != nil && m.palette.IsVisible() {
-		return true
-	}
-	if m.historyPicker != nil && m.historyPicker.IsVisible() {
-		return true
-	}
-	if m.resultPanel != nil && m.resultPanel.IsVisible() {
-		return true
-	}
-	if m.modelPicker != nil && m.modelPicker.IsVisible() {
-		return true
-	}
-	if m.optionPicker != nil && m.optionPicker.IsVisible() {
-		return true
-	}
-	return false
+	_, open := m.topOverlay()
+	return open
 }
 
 func (m *Model) setTerminalFocused(focused bool) {
@@ -52,15 +39,18 @@ func (m *Model) setScrollMode(active bool) {
 	m.scrollMode = active
 	m.viewport.SetAutoScroll(!active)
 	m.statusBar.SetScrollMode(active)
+	if !active {
+		m.viewport.ClearCitation()
+	}
 }
 
 func (m *Model) showSidebar(reason string) {
-	if m.sidebar == nil {
-		return
-	}
-	m.sidebar.Show()
-	m.sidebar.FocusInput()
+	s := m.ensureSidebar()
+	s.Show()
+	s.FocusInput()
 	m.setTerminalFocused(false)
+	m.clearAnswersReady()
+	ai.DefaultPrewarmer.Start(m.sidebarPrewarmURL, m.sidebarPrewarmTransport)
 	slog.Info("sidebar_open", "reason", reason)
 	m.applyLayout()
 }
@@ -76,10 +66,7 @@ func (m *Model) hideSidebar(reason string) {
 }
 
 func (m *Model) toggleSidebar(reason string) {
-	if m.sidebar == nil {
-		return
-	}
-	if m.sidebar.IsVisible() {
+	if m.sidebar != nil && m.sidebar.IsVisible() {
 		m.hideSidebar(reason)
 		return
 	}
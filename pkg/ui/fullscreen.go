@@ -19,9 +19,7 @@ func (m *Model) enterFullScreen(dataLen int) {
 	slog.Info("fullscreen_enter", "data_len", dataLen)
 	m.setScrollMode(false)
 	m.fullScreenMode = true
-	if m.fullScreenPanel != nil {
-		m.fullScreenPanel.Show()
-	}
+	m.ensureFullScreenPanel().Show()
 	if m.inputHandler != nil {
 		m.inputHandler.SetFullScreenMode(true)
 	}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// SetHistoryAppendFunc sets how commands applied from the sidebar, history
+// picker and hints are written to the shell's history file.
+func (m *Model) SetHistoryAppendFunc(fn func(command string) error) {
+	m.historyAppend = fn
+}
+
+// appendAppliedCommandToHistory writes command to the shell's history file
+// when wtf_cli typed it at the prompt, possibly edited by the user since.
+// The shell records it too, but only flushes on exit (or not at all when
+// killed), so without this the command is missing from other shells.
+func (m *Model) appendAppliedCommandToHistory(command string) {
+	if m.appliedCommand == "" {
+		return
+	}
+	m.appliedCommand = ""
+	if m.historyAppend == nil {
+		return
+	}
+	if err := m.historyAppend(command); err != nil {
+		slog.Warn("history_append_failed", "error", err)
+	}
+}
+
+// trackAppliedCommand forgets the applied command once key, sent to the
+// prompt, abandons it: Ctrl+C, or an edit that clears the line or turns it
+// into another command. Changing its arguments keeps it.
+func (m *Model) trackAppliedCommand(key tea.KeyPressMsg) {
+	if m.appliedCommand == "" || m.inputHandler == nil {
+		return
+	}
+	switch key.String() {
+	case "enter":
+		return // Submitted; handleCommandSubmitted appends it
+	case "ctrl+c":
+		m.appliedCommand = ""
+		return
+	}
+	typed := strings.Fields(m.inputHandler.LineBuffer())
+	applied := strings.Fields(m.appliedCommand)
+	if len(typed) == 0 || len(applied) == 0 || typed[0] != applied[0] {
+		m.appliedCommand = ""
+	}
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestModel_AppendsAppliedCommandToHistory(t *testing.T) {
+	ptyFile, err := os.CreateTemp(t.TempDir(), "pty")
+	if err != nil {
+		t.Fatalf("Failed to create temp PTY file: %v", err)
+	}
+	defer ptyFile.Close()
+
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	var appended []string
+	m.SetHistoryAppendFunc(func(command string) error {
+		appended = append(appended, command)
+		return nil
+	})
+
+	// A command typed by the user is left to the shell.
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "ls"})
+	m = newModel.(Model)
+	if len(appended) != 0 {
+		t.Fatalf("Expected typed command not to be appended, got %v", appended)
+	}
+
+	// An applied command is appended as submitted, edits included, once.
+	newModel, _ = m.Update(sidebar.CommandExecuteMsg{Command: "git status"})
+	m = newModel.(Model)
+	newModel, _ = m.Update(input.CommandSubmittedMsg{Command: "git status -s"})
+	m = newModel.(Model)
+	newModel, _ = m.Update(input.CommandSubmittedMsg{Command: "pwd"})
+	m = newModel.(Model)
+
+	if want := []string{"git status -s"}; !reflect.DeepEqual(appended, want) {
+		t.Fatalf("appended = %v, want %v", appended, want)
+	}
+}
+
+func TestModel_ForgetsAppliedCommandWhenAbandoned(t *testing.T) {
+	ptyFile, err := os.CreateTemp(t.TempDir(), "pty")
+	if err != nil {
+		t.Fatalf("Failed to create temp PTY file: %v", err)
+	}
+	defer ptyFile.Close()
+
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	var appended []string
+	m.SetHistoryAppendFunc(func(command string) error {
+		appended = append(appended, command)
+		return nil
+	})
+	submit := func(m Model) Model {
+		t.Helper()
+		m, cmd := m.handleKeyPress(tea.KeyPressMsg{Code: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("Expected Enter to submit the line")
+		}
+		newModel, _ := m.Update(cmd())
+		return newModel.(Model)
+	}
+
+	// Ctrl+C abandons the applied command.
+	m, _ = m.handleSidebarCommandExecute(sidebar.CommandExecuteMsg{Command: "git status"})
+	m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl})
+	m = typeLine(t, m, "make")
+	m = submit(m)
+
+	// Erasing it and typing another command does too.
+	m, _ = m.handleSidebarCommandExecute(sidebar.CommandExecuteMsg{Command: "git status"})
+	for range len("git status") {
+		m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+	m = typeLine(t, m, "git log")
+	m = submit(m)
+	if len(appended) != 0 {
+		t.Fatalf("Expected abandoned commands not to be appended, got %v", appended)
+	}
+
+	// Editing its arguments keeps it.
+	m, _ = m.handleSidebarCommandExecute(sidebar.CommandExecuteMsg{Command: "git status"})
+	m = typeLine(t, m, " -s")
+	m = submit(m)
+	if want := []string{"git status -s"}; !reflect.DeepEqual(appended, want) {
+		t.Fatalf("appended = %v, want %v", appended, want)
+	}
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"strings"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/historypicker"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// historyPreviewLines is how many output lines are kept per command for the
+// history picker preview.
+const historyPreviewLines = 8
+
+// snapshotLastCommandOutput stores the start of the previous command's output
+// before a new command is recorded, so the preview survives the buffer
+// wrapping around later in the session.
+func (m *Model) snapshotLastCommandOutput() {
+	if m.session == nil || m.buffer == nil {
+		return
+	}
+	last := m.session.GetLastN(1)
+	if len(last) == 0 || last[0].BufferEnd != 0 {
+		return
+	}
+	end := m.buffer.Total()
+	m.session.SetLastOutput(end, m.commandOutputPreview(last[0], end))
+}
+
+// commandOutputPreview returns the first output lines of rec written before
+// mark end. The echoed prompt line (unless the shell marked where output
+// starts) and leading blank lines are skipped.
+func (m *Model) commandOutputPreview(rec capture.CommandRecord, end int) []string {
+	// One extra line for the echoed prompt, a few for leading blanks.
+	limit := rec.BufferStart + historyPreviewLines + 4
+	if end > limit {
+		end = limit
+	}
+	command := strings.TrimSpace(rec.Command)
+
+	var out []string
+	for i, raw := range m.buffer.LinesBetween(rec.BufferStart, end) {
+		line := sanitizePreviewLine(string(raw))
+		if i == 0 && !rec.Marked && command != "" && strings.Contains(line, command) {
+			continue
+		}
+		if len(out) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		out = append(out, line)
+		if len(out) == historyPreviewLines {
+			break
+		}
+	}
+	return out
+}
+
+// historyPreviews builds the picker preview for each session command. When a
+// command ran more than once, the most recent run wins.
+func (m *Model) historyPreviews(records []capture.CommandRecord) map[string]historypicker.Preview {
+	previews := make(map[string]historypicker.Preview, len(records))
+	for _, rec := range records {
+		command := strings.TrimSpace(rec.Command)
+		if command == "" {
+			continue
+		}
+		output := rec.Output
+		if rec.BufferEnd == 0 && m.buffer != nil {
+			// Latest command: its output is still in the buffer.
+			output = m.commandOutputPreview(rec, m.buffer.Total())
+		}
+		previews[command] = historypicker.Preview{
+			WorkingDir: rec.WorkingDir,
+			Output:     output,
+		}
+	}
+	return previews
+}
+
+// historyFilterFor turns the history config into the picker's filter.
+func historyFilterFor(cfg config.HistoryConfig) capture.HistoryFilter {
+	return capture.HistoryFilter{Ignore: cfg.Ignore, IgnoreSpace: cfg.IgnoreSpace}
+}
+
+// sanitizePreviewLine strips escape sequences and control characters so a
+// captured line renders as plain text.
+func sanitizePreviewLine(line string) string {
+	line = ansi.Strip(line)
+	line = strings.ReplaceAll(line, "\t", "    ")
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/input"
+)
+
+func TestModel_HistoryPreviewSnapshotsOutput(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = "/src/api"
+
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "make test"})
+	m = newModel.(Model)
+	m.buffer.Write([]byte("$ make test"))
+	m.buffer.Write([]byte(""))
+	m.buffer.Write([]byte("\x1b[31mFAIL\x1b[0m\tapi/store"))
+
+	newModel, _ = m.Update(input.CommandSubmittedMsg{Command: "git status"})
+	m = newModel.(Model)
+	m.buffer.Write([]byte("$ git status"))
+	m.buffer.Write([]byte("On branch main"))
+
+	first := m.session.GetHistory()[0]
+	if want := []string{"FAIL    api/store"}; !reflect.DeepEqual(first.Output, want) {
+		t.Fatalf("Expected snapshot %q, got %q", want, first.Output)
+	}
+
+	previews := m.historyPreviews(m.session.GetHistory())
+	if got := previews["make test"]; got.WorkingDir != "/src/api" {
+		t.Fatalf("Expected working dir in preview, got %q", got.WorkingDir)
+	}
+	if got := previews["git status"].Output; !reflect.DeepEqual(got, []string{"On branch main"}) {
+		t.Fatalf("Expected live output for the latest command, got %q", got)
+	}
+}
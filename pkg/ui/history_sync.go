@@ -0,0 +1,9 @@
+package ui
+
+import "wtf_cli/pkg/capture"
+
+// SetSyncedHistoryFunc sets where the history picker reads commands synced
+// by `wtf_cli sync`, oldest first.
+func (m *Model) SetSyncedHistoryFunc(fn func() []capture.CommandRecord) {
+	m.syncedHistory = fn
+}
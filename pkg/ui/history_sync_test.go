@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/input"
+)
+
+func TestModel_HistoryPickerListsSyncedCommands(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), ".bash_history")
+	if err := os.WriteFile(histFile, []byte(""), 0o600); err != nil {
+		t.Fatalf("Failed to create history file: %v", err)
+	}
+	t.Setenv("HISTFILE", histFile)
+
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.SetSyncedHistoryFunc(func() []capture.CommandRecord {
+		return []capture.CommandRecord{{Command: "kubectl get pods", StartTime: time.Now().Add(-time.Hour)}}
+	})
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "echo fresh"})
+	m = newModel.(Model)
+
+	newModel, _ = m.Update(input.ShowHistoryPickerMsg{InitialFilter: ""})
+	m = newModel.(Model)
+
+	view := m.historyPicker.View()
+	fresh, synced := strings.Index(view, "echo fresh"), strings.Index(view, "kubectl get pods")
+	if fresh < 0 || synced < 0 {
+		t.Fatalf("Expected both commands in the history picker, got %q", view)
+	}
+	if fresh > synced {
+		t.Errorf("Expected this session's command before the synced one, got %q", view)
+	}
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"time"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/hooks"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// queueHook keeps ev for the next fireHooks, when a hook listens for it.
+// Used where the caller cannot return a command, such as while parsing PTY
+// output.
+func (m *Model) queueHook(ev hooks.Event) {
+	if !m.hooks.Has(ev.Event) {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	m.pendingHooks = append(m.pendingHooks, ev)
+}
+
+// fireHooks runs the hooks of the events queued since the last call, in
+// order, off the UI goroutine.
+func (m *Model) fireHooks() tea.Cmd {
+	if len(m.pendingHooks) == 0 {
+		return nil
+	}
+	events, runner := m.pendingHooks, m.hooks
+	m.pendingHooks = nil
+	return func() tea.Msg {
+		for _, ev := range events {
+			runner.Run(ev)
+		}
+		return nil
+	}
+}
+
+// runHook runs the hooks for ev off the UI goroutine.
+func (m *Model) runHook(ev hooks.Event) tea.Cmd {
+	m.queueHook(ev)
+	return m.fireHooks()
+}
+
+// queueCommandFailedHook raises command_failed for rec when it exited with
+// a non-zero status. Only commands with prompt marks have one.
+func (m *Model) queueCommandFailedHook(rec capture.CommandRecord, exitCode int) {
+	if exitCode <= 0 || m.privacy {
+		return
+	}
+	m.queueHook(hooks.Event{
+		Event:           config.HookEventCommandFailed,
+		Dir:             rec.WorkingDir,
+		Command:         rec.Command,
+		ExitCode:        &exitCode,
+		DurationSeconds: time.Since(rec.StartTime).Round(time.Millisecond).Seconds(),
+	})
+}
+
+// answerReceivedHook raises answer_received with the last question and its
+// answer. Like command_failed it stays quiet in privacy mode.
+func (m *Model) answerReceivedHook() tea.Cmd {
+	if !m.hooks.Has(config.HookEventAnswerReceived) || m.sidebar == nil || m.privacy {
+		return nil
+	}
+	answer := m.sidebar.LastAnswer()
+	if answer < 0 {
+		return nil
+	}
+	messages := m.sidebar.GetMessages()
+	ev := hooks.Event{Event: config.HookEventAnswerReceived, Dir: m.currentDir, Answer: messages[answer].Content}
+	for i := answer - 1; i >= 0 && ev.Question == ""; i-- {
+		if messages[i].Role == "user" {
+			ev.Question = messages[i].Content
+		}
+	}
+	provider, model := getProviderAndModel(m.sessionConfig())
+	ev.Provider, ev.Model = provider, m.chatModel(model)
+	return m.runHook(ev)
+}
+
+// commandAppliedHook raises command_applied for a command put at the
+// prompt from the sidebar.
+func (m *Model) commandAppliedHook(command string) tea.Cmd {
+	return m.runHook(hooks.Event{Event: config.HookEventCommandApplied, Dir: m.currentDir, Command: command})
+}
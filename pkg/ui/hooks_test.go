@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/hooks"
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// recordHook sets m up to run a hook for event that saves its input, and
+// returns the file it goes to.
+func recordHook(t *testing.T, m *Model, event string) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "event.json")
+	m.hooks = hooks.New([]config.HookConfig{{Event: event, Command: "cat > '" + out + "'"}})
+	return out
+}
+
+func readHookEvent(t *testing.T, cmd tea.Cmd, out string) hooks.Event {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command running the hook")
+	}
+	cmd()
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	var ev hooks.Event
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatalf("hook input is not JSON: %v: %s", err, data)
+	}
+	return ev
+}
+
+func TestModel_CommandFailedHook(t *testing.T) {
+	m := newPromptZoneTestModel(t)
+	out := recordHook(t, &m, config.HookEventCommandFailed)
+
+	writePTY(&m, markedPrompt+"true\r\n\x1b]133;C\x07")
+	writePTY(&m, "\x1b]133;D;0\x07"+markedPrompt)
+	if cmd := m.fireHooks(); cmd != nil {
+		t.Fatal("a command that succeeded should not raise command_failed")
+	}
+
+	writePTY(&m, "make test\r\n\x1b]133;C\x07")
+	writePTY(&m, "3 errors\r\n\x1b]133;D;2\x07"+markedPrompt)
+	ev := readHookEvent(t, m.fireHooks(), out)
+	if ev.Event != config.HookEventCommandFailed || ev.Command != "make test" || ev.ExitCode == nil || *ev.ExitCode != 2 {
+		t.Errorf("event = %+v", ev)
+	}
+	if cmd := m.fireHooks(); cmd != nil {
+		t.Error("fired events should not fire again")
+	}
+}
+
+func TestModel_CommandAppliedHook(t *testing.T) {
+	ptyFile, _ := newPipePTY(t)
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	out := recordHook(t, &m, config.HookEventCommandApplied)
+
+	newModel, cmd := m.Update(sidebar.CommandExecuteMsg{Command: "git status"})
+	m = newModel.(Model)
+	if ev := readHookEvent(t, cmd, out); ev.Event != config.HookEventCommandApplied || ev.Command != "git status" {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
+func TestModel_HooksOffWithoutConfig(t *testing.T) {
+	ptyFile, _ := newPipePTY(t)
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	if _, cmd := m.Update(sidebar.CommandExecuteMsg{Command: "git status"}); cmd != nil {
+		t.Error("applying a command should not schedule anything without hooks")
+	}
+}
+
+func TestModel_AnswerReceivedHook(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	out := recordHook(t, &m, config.HookEventAnswerReceived)
+	m.ensureSidebar().AppendUserMessage("why does make fail?")
+	m.sidebar.StartAssistantMessageWithContent("A missing header.")
+
+	m.privacy = true
+	if cmd := m.answerReceivedHook(); cmd != nil {
+		t.Fatal("privacy mode should not raise answer_received")
+	}
+
+	m.privacy = false
+	ev := readHookEvent(t, m.answerReceivedHook(), out)
+	if ev.Event != config.HookEventAnswerReceived || ev.Question != "why does make fail?" || ev.Answer != "A missing header." {
+		t.Errorf("event = %+v", ev)
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ui/styles"
+)
+
+// ImportContext preloads data, e.g. a log captured elsewhere, into the
+// terminal and its buffer before the shell starts. Marker lines naming it
+// keep it apart from live output, for the user and for the AI, which
+// receives the buffer as terminal output. Only the lines the buffer holds
+// are kept.
+func (m *Model) ImportContext(name string, data []byte) {
+	text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if m.buffer == nil || text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	total := len(lines)
+	if keep := m.buffer.Capacity() - 2; keep > 0 && len(lines) > keep {
+		lines = lines[len(lines)-keep:]
+	}
+
+	begin := fmt.Sprintf("--- imported context: %s (%d lines) ---", name, total)
+	if len(lines) < total {
+		begin = fmt.Sprintf("--- imported context: %s (last %d of %d lines) ---", name, len(lines), total)
+	}
+	end := fmt.Sprintf("--- end of imported context: %s ---", name)
+
+	m.buffer.Write([]byte(begin))
+	for _, line := range lines {
+		m.buffer.Write([]byte(line))
+	}
+	m.buffer.Write([]byte(end))
+
+	var out strings.Builder
+	out.WriteString(styles.TextMutedStyle.Render(begin) + "\r\n")
+	for _, line := range lines {
+		out.WriteString(line + "\r\n")
+	}
+	out.WriteString(styles.TextMutedStyle.Render(end) + "\r\n")
+	m.viewport.AppendOutput([]byte(out.String()))
+
+	slog.Info("context_import", "name", name, "lines", total, "kept", len(lines))
+}
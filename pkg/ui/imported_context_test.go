@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestImportContext_MarksFileInBuffer(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = newModel.(Model)
+	m.ImportContext("build.log", []byte("compiling\r\nerror: undefined: foo\n\n"))
+
+	want := []string{
+		"--- imported context: build.log (2 lines) ---",
+		"compiling",
+		"error: undefined: foo",
+		"--- end of imported context: build.log ---",
+	}
+	if got := strings.Split(m.buffer.ExportAsText(), "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("buffer = %q, want %q", got, want)
+	}
+	if view := m.viewport.View(); !strings.Contains(view, "error: undefined: foo") {
+		t.Fatalf("Expected the file in the terminal, got:\n%s", view)
+	}
+}
+
+func TestImportContext_KeepsTailThatFits(t *testing.T) {
+	m := NewModel(nil, buffer.New(5), capture.NewSessionContext(), nil)
+	m.ImportContext("stdin", []byte("1\n2\n3\n4\n5\n6"))
+
+	got := m.buffer.ExportAsText()
+	if !strings.HasPrefix(got, "--- imported context: stdin (last 3 of 6 lines) ---\n4\n5\n6\n") {
+		t.Fatalf("Expected the last lines kept, got %q", got)
+	}
+}
+
+func TestImportContext_EmptyIsIgnored(t *testing.T) {
+	m := NewModel(nil, buffer.New(10), capture.NewSessionContext(), nil)
+	m.ImportContext("empty.log", []byte("\n"))
+	if m.buffer.Size() != 0 {
+		t.Fatalf("Expected nothing imported, got %q", m.buffer.ExportAsText())
+	}
+}
@@ -2,18 +2,40 @@ package input
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 
 	"wtf_cli/pkg/logging"
 
 	tea "charm.land/bubbletea/v2"
 )
 
+// ErrReadOnly is returned for writes to the PTY while read-only mode is on.
+var ErrReadOnly = errors.New("the terminal is read-only")
+
+// readOnlyGate drops writes to the PTY while closed, so no key, paste or
+// command reaches the shell in read-only mode, whatever path it takes.
+// Chunked pastes write from a tea.Cmd goroutine while the UI goroutine
+// toggles the gate, hence the atomic.
+type readOnlyGate struct {
+	w      io.Writer
+	closed atomic.Bool
+}
+
+func (g *readOnlyGate) Write(p []byte) (int, error) {
+	if g.closed.Load() {
+		return 0, ErrReadOnly
+	}
+	return g.w.Write(p)
+}
+
 // InputHandler manages keyboard input routing
 type InputHandler struct {
 	ptyWriter         io.Writer
+	gate              *readOnlyGate
 	atLineStart       bool   // Track if cursor is at start of line (for / detection)
 	lineBuffer        string // Track current line text for Ctrl+R initial filter
 	paletteMode       bool   // True when command palette is active
@@ -29,13 +51,27 @@ type InputHandler struct {
 
 // NewInputHandler creates a new input handler
 func NewInputHandler(ptyWriter io.Writer) *InputHandler {
+	gate := &readOnlyGate{w: ptyWriter}
 	return &InputHandler{
-		ptyWriter:   ptyWriter,
+		ptyWriter:   gate,
+		gate:        gate,
 		atLineStart: true, // Start at line start (fresh prompt)
 		modePending: make([]byte, 0, 8),
 	}
 }
 
+// SetReadOnly sets whether input is kept from the PTY. While it is, keys
+// meant for the shell come back as ReadOnlyKeyMsg and writes fail with
+// ErrReadOnly.
+func (ih *InputHandler) SetReadOnly(on bool) {
+	ih.gate.closed.Store(on)
+}
+
+// IsReadOnly returns whether input is kept from the PTY.
+func (ih *InputHandler) IsReadOnly() bool {
+	return ih.gate.closed.Load()
+}
+
 // SetPaletteMode sets whether the command palette is active
 func (ih *InputHandler) SetPaletteMode(active bool) {
 	ih.paletteMode = active
@@ -62,6 +98,11 @@ func (ih *InputHandler) SetLineBuffer(text string) {
 	ih.atLineStart = len(text) == 0
 }
 
+// LineBuffer returns the text typed at the prompt since the last submit.
+func (ih *InputHandler) LineBuffer() string {
+	return ih.lineBuffer
+}
+
 // ClearLineBuffer clears the internal line buffer.
 // Used when echo is disabled (password entry) to prevent capturing secrets.
 func (ih *InputHandler) ClearLineBuffer() {
@@ -91,8 +132,17 @@ type FocusSwitchMsg struct{}
 
 type CtrlDPressedMsg struct{}
 
+// ReadOnlyKeyMsg is sent for a key that was meant for the shell while
+// read-only mode keeps it from the PTY.
+type ReadOnlyKeyMsg struct{}
+
 // HandleKey processes a key message and returns whether it was handled
 func (ih *InputHandler) HandleKey(msg tea.KeyPressMsg) (handled bool, cmd tea.Cmd) {
+	// READ-ONLY MODE: only the keys that open wtf_cli's own UI work.
+	if ih.gate.closed.Load() {
+		return ih.handleReadOnlyKey(msg)
+	}
+
 	// FULL-SCREEN MODE: bypass all special handling, send directly to PTY
 	if ih.fullScreenMode {
 		ih.sendKeyToPTY(msg)
@@ -287,6 +337,25 @@ func (ih *InputHandler) HandleKey(msg tea.KeyPressMsg) (handled bool, cmd tea.Cm
 	return false, nil
 }
 
+// handleReadOnlyKey opens the palette, the chat and the exit prompt as
+// usual and turns every other key into a ReadOnlyKeyMsg.
+func (ih *InputHandler) handleReadOnlyKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
+	if ih.paletteMode || ih.historyPickerMode {
+		return false, nil
+	}
+	switch msg.String() {
+	case "/":
+		return true, func() tea.Msg { return ShowPaletteMsg{} }
+	case "ctrl+t":
+		return true, func() tea.Msg { return ToggleChatMsg{} }
+	case "shift+tab":
+		return true, func() tea.Msg { return FocusSwitchMsg{} }
+	case "ctrl+d":
+		return true, func() tea.Msg { return CtrlDPressedMsg{} }
+	}
+	return true, func() tea.Msg { return ReadOnlyKeyMsg{} }
+}
+
 // UpdateTerminalModes updates input behavior based on terminal mode sequences.
 func (ih *InputHandler) UpdateTerminalModes(data []byte) {
 	if len(data) == 0 {
@@ -367,37 +436,54 @@ func (ih *InputHandler) SendToPTY(data []byte) error {
 
 // HandlePaste sends pasted content to the PTY, wrapping with bracketed paste
 // delimiters when enabled, and updates line buffer tracking.
-func (ih *InputHandler) HandlePaste(content string) {
-	if content == "" {
-		return
+//
+// Pastes of ChunkedPasteMin bytes or more are not written here: the returned
+// Paste writes them chunk by chunk via WriteNext, and FinishPaste must be
+// called with its final PasteProgressMsg. Smaller pastes return nil.
+func (ih *InputHandler) HandlePaste(content string) *Paste {
+	if content == "" || ih.gate.closed.Load() {
+		return nil
 	}
 
-	if ih.secretMode {
-		if ih.bracketedPasteMode {
-			ih.ptyWriter.Write([]byte("\x1b[200~"))
-		}
-		ih.ptyWriter.Write([]byte(content))
-		if ih.bracketedPasteMode {
-			ih.ptyWriter.Write([]byte("\x1b[201~"))
+	logger := slog.Default()
+	ctx := context.Background()
+	if !ih.secretMode && logger.Enabled(ctx, logging.LevelTrace) {
+		logger.Log(ctx, logging.LevelTrace, "paste_to_pty", "len", len(content), "bracketed", ih.bracketedPasteMode, "chunked", len(content) >= ChunkedPasteMin)
+	}
+
+	if len(content) >= ChunkedPasteMin {
+		return &Paste{
+			w:         ih.ptyWriter,
+			content:   content,
+			bracketed: ih.bracketedPasteMode,
+			track:     !ih.secretMode,
 		}
-		return
 	}
 
-	logger := slog.Default()
-	ctx := context.Background()
-	if logger.Enabled(ctx, logging.LevelTrace) {
-		logger.Log(ctx, logging.LevelTrace, "paste_to_pty", "len", len(content), "bracketed", ih.bracketedPasteMode)
+	if ih.bracketedPasteMode {
+		ih.ptyWriter.Write([]byte(bracketedPasteStart))
 	}
+	ih.ptyWriter.Write([]byte(content))
 	if ih.bracketedPasteMode {
-		ih.ptyWriter.Write([]byte("\x1b[200~"))
+		ih.ptyWriter.Write([]byte(bracketedPasteEnd))
 	}
-	if len(content) > 0 {
-		ih.ptyWriter.Write([]byte(content))
+
+	if !ih.secretMode {
+		ih.trackPasted(content)
 	}
-	if ih.bracketedPasteMode {
-		ih.ptyWriter.Write([]byte("\x1b[201~"))
+	return nil
+}
+
+// FinishPaste updates line buffer tracking with the part of a chunked paste
+// that reached the PTY.
+func (ih *InputHandler) FinishPaste(msg PasteProgressMsg) {
+	if msg.Paste == nil || !msg.Paste.track || msg.Written == 0 {
+		return
 	}
+	ih.trackPasted(msg.Paste.content[:msg.Written])
+}
 
+func (ih *InputHandler) trackPasted(content string) {
 	lastNL := strings.LastIndexAny(content, "\n\r")
 	if lastNL == -1 {
 		ih.lineBuffer += content
@@ -1209,3 +1209,34 @@ func TestInputHandler_SlashPalette_AfterCtrlC(t *testing.T) {
 		t.Fatal("Expected command to show palette")
 	}
 }
+
+func TestInputHandler_ReadOnly(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ih := NewInputHandler(buf)
+	ih.SetReadOnly(true)
+
+	for _, key := range []tea.KeyPressMsg{testutils.NewTextKeyPressMsg("a"), testutils.TestKeyEnter, testutils.TestKeyCtrlC} {
+		handled, cmd := ih.HandleKey(key)
+		if !handled || cmd == nil {
+			t.Fatalf("expected %q to be handled with a notice", key.String())
+		}
+		if _, ok := cmd().(ReadOnlyKeyMsg); !ok {
+			t.Fatalf("expected ReadOnlyKeyMsg for %q", key.String())
+		}
+	}
+	if p := ih.HandlePaste("echo hi"); p != nil {
+		t.Fatal("expected no paste in read-only mode")
+	}
+	if err := ih.SendToPTY([]byte("ls")); err != ErrReadOnly {
+		t.Fatalf("SendToPTY() error = %v, want ErrReadOnly", err)
+	}
+	if buf.Len() != 0 || ih.LineBuffer() != "" {
+		t.Fatalf("expected nothing written, got %q (line buffer %q)", buf.String(), ih.LineBuffer())
+	}
+
+	ih.SetReadOnly(false)
+	ih.HandleKey(testutils.NewTextKeyPressMsg("a"))
+	if buf.String() != "a" {
+		t.Fatalf("expected typing to resume, got %q", buf.String())
+	}
+}
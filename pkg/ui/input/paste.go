@@ -0,0 +1,100 @@
+package input
+
+import (
+	"io"
+	"sync/atomic"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	// ChunkedPasteMin is the smallest paste written to the PTY in chunks.
+	// Smaller pastes are written directly.
+	ChunkedPasteMin = 64 * 1024
+	// PasteChunkSize is how much of a chunked paste each write sends.
+	PasteChunkSize = 4 * 1024
+
+	bracketedPasteStart = "\x1b[200~"
+	bracketedPasteEnd   = "\x1b[201~"
+)
+
+// Paste is a large paste being written to the PTY one chunk at a time. Each
+// WriteNext command writes a single chunk off the UI goroutine and reports a
+// PasteProgressMsg; the UI schedules the next chunk once it sees it, so a
+// shell that reads slowly holds back the paste instead of the UI.
+type Paste struct {
+	w         io.Writer
+	content   string
+	bracketed bool
+	track     bool // Update line tracking when done (false in secret mode)
+
+	// written and started are only touched by the command writing the
+	// current chunk; the next command is created after its result arrives.
+	written   int
+	started   bool
+	cancelled atomic.Bool
+}
+
+// PasteProgressMsg reports a chunk of Paste written to the PTY.
+type PasteProgressMsg struct {
+	Paste   *Paste
+	Written int  // Bytes of the pasted content written so far
+	Total   int  // Size of the pasted content
+	Done    bool // Nothing more will be written: finished, cancelled or failed
+	Err     error
+}
+
+// Cancel stops the paste before its next chunk. A bracketed paste is still
+// closed so the shell leaves paste mode.
+func (p *Paste) Cancel() {
+	p.cancelled.Store(true)
+}
+
+// Cancelled reports whether Cancel was called.
+func (p *Paste) Cancelled() bool {
+	return p.cancelled.Load()
+}
+
+// Len returns the size of the pasted content.
+func (p *Paste) Len() int {
+	return len(p.content)
+}
+
+// WriteNext returns a command that writes the next chunk.
+func (p *Paste) WriteNext() tea.Cmd {
+	return func() tea.Msg {
+		return p.writeChunk()
+	}
+}
+
+func (p *Paste) writeChunk() PasteProgressMsg {
+	var chunk []byte
+	if !p.started {
+		p.started = true
+		if p.bracketed {
+			chunk = append(chunk, bracketedPasteStart...)
+		}
+	}
+
+	cancelled := p.cancelled.Load()
+	end := p.written
+	if !cancelled {
+		end = min(p.written+PasteChunkSize, len(p.content))
+		// Never split a multi-byte character across writes.
+		for end < len(p.content) && !utf8.RuneStart(p.content[end]) {
+			end++
+		}
+		chunk = append(chunk, p.content[p.written:end]...)
+	}
+	done := cancelled || end == len(p.content)
+	if done && p.bracketed {
+		chunk = append(chunk, bracketedPasteEnd...)
+	}
+
+	if _, err := p.w.Write(chunk); err != nil {
+		return PasteProgressMsg{Paste: p, Written: p.written, Total: len(p.content), Done: true, Err: err}
+	}
+	p.written = end
+	return PasteProgressMsg{Paste: p, Written: p.written, Total: len(p.content), Done: done}
+}
@@ -0,0 +1,165 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// chunkRecorder records each write to the PTY separately.
+type chunkRecorder struct {
+	writes []string
+	err    error
+}
+
+func (r *chunkRecorder) Write(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func writeAll(t *testing.T, p *Paste) PasteProgressMsg {
+	t.Helper()
+	for i := 0; i < p.Len(); i++ {
+		msg := p.WriteNext()().(PasteProgressMsg)
+		if msg.Done {
+			return msg
+		}
+	}
+	t.Fatal("paste never finished")
+	return PasteProgressMsg{}
+}
+
+func TestHandlePaste_SmallPasteIsWrittenDirectly(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ih := NewInputHandler(buf)
+
+	if p := ih.HandlePaste("echo hi"); p != nil {
+		t.Fatal("expected no chunked paste for a small paste")
+	}
+	if buf.String() != "echo hi" {
+		t.Fatalf("expected paste written directly, got %q", buf.String())
+	}
+}
+
+func TestHandlePaste_LargePasteIsWrittenInChunks(t *testing.T) {
+	rec := &chunkRecorder{}
+	ih := NewInputHandler(rec)
+	ih.UpdateTerminalModes([]byte("\x1b[?2004h"))
+
+	content := strings.Repeat("x", ChunkedPasteMin) + "\ntail"
+	p := ih.HandlePaste(content)
+	if p == nil {
+		t.Fatal("expected a chunked paste")
+	}
+	if len(rec.writes) != 0 {
+		t.Fatal("expected nothing written before the first chunk")
+	}
+
+	msg := writeAll(t, p)
+	if msg.Err != nil || msg.Written != len(content) || msg.Total != len(content) {
+		t.Fatalf("unexpected final progress: %+v", msg)
+	}
+	for i, w := range rec.writes {
+		if len(w) > PasteChunkSize+len(bracketedPasteStart)+len(bracketedPasteEnd) {
+			t.Fatalf("write %d is %d bytes, larger than a chunk", i, len(w))
+		}
+	}
+	got := strings.Join(rec.writes, "")
+	if got != bracketedPasteStart+content+bracketedPasteEnd {
+		t.Fatal("expected the whole paste wrapped in bracketed paste delimiters")
+	}
+
+	ih.FinishPaste(msg)
+	if ih.LineBuffer() != "tail" {
+		t.Fatalf("expected line buffer %q, got %q", "tail", ih.LineBuffer())
+	}
+}
+
+func TestPaste_CancelClosesBracketedPaste(t *testing.T) {
+	rec := &chunkRecorder{}
+	ih := NewInputHandler(rec)
+	ih.UpdateTerminalModes([]byte("\x1b[?2004h"))
+
+	p := ih.HandlePaste(strings.Repeat("y", ChunkedPasteMin*2))
+	first := p.WriteNext()().(PasteProgressMsg)
+	if first.Done || first.Written != PasteChunkSize {
+		t.Fatalf("unexpected first progress: %+v", first)
+	}
+
+	p.Cancel()
+	last := p.WriteNext()().(PasteProgressMsg)
+	if !last.Done || last.Written != PasteChunkSize {
+		t.Fatalf("expected cancel to stop after the first chunk, got %+v", last)
+	}
+	if rec.writes[len(rec.writes)-1] != bracketedPasteEnd {
+		t.Fatalf("expected cancel to close the bracketed paste, got %q", rec.writes[len(rec.writes)-1])
+	}
+
+	ih.FinishPaste(last)
+	if ih.LineBuffer() != strings.Repeat("y", PasteChunkSize) {
+		t.Fatal("expected line tracking to cover only the written part")
+	}
+}
+
+func TestPaste_KeepsMultibyteCharactersWhole(t *testing.T) {
+	rec := &chunkRecorder{}
+	ih := NewInputHandler(rec)
+
+	p := ih.HandlePaste(strings.Repeat("é", ChunkedPasteMin))
+	writeAll(t, p)
+	for i, w := range rec.writes {
+		if !utf8.ValidString(w) {
+			t.Fatalf("write %d splits a character", i)
+		}
+	}
+}
+
+func TestPaste_WriteErrorEndsPaste(t *testing.T) {
+	rec := &chunkRecorder{err: errors.New("pty closed")}
+	ih := NewInputHandler(rec)
+
+	msg := ih.HandlePaste(strings.Repeat("z", ChunkedPasteMin)).WriteNext()().(PasteProgressMsg)
+	if !msg.Done || msg.Err == nil || msg.Written != 0 {
+		t.Fatalf("expected a failed, finished paste, got %+v", msg)
+	}
+}
+
+func TestHandlePaste_SecretModeSkipsLineTracking(t *testing.T) {
+	rec := &chunkRecorder{}
+	ih := NewInputHandler(rec)
+	ih.SetSecretMode(true)
+
+	p := ih.HandlePaste(strings.Repeat("s", ChunkedPasteMin))
+	ih.FinishPaste(writeAll(t, p))
+	if ih.LineBuffer() != "" {
+		t.Fatal("expected secret pastes to stay out of the line buffer")
+	}
+}
+
+func TestPaste_ReadOnlyStopsPasteInProgress(t *testing.T) {
+	ih := NewInputHandler(io.Discard)
+	p := ih.HandlePaste(strings.Repeat("r", ChunkedPasteMin))
+
+	// The chunk is written off the UI goroutine while /readonly turns on;
+	// go test -race catches unsynchronized access to the gate.
+	done := make(chan PasteProgressMsg)
+	go func() {
+		for {
+			msg := p.WriteNext()().(PasteProgressMsg)
+			if msg.Done {
+				done <- msg
+				return
+			}
+		}
+	}()
+	ih.SetReadOnly(true)
+	if msg := <-done; !errors.Is(msg.Err, ErrReadOnly) && msg.Written != msg.Total {
+		t.Fatalf("expected the paste stopped by read-only mode or finished first, got %+v", msg)
+	}
+}
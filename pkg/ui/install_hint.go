@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"wtf_cli/pkg/cmdnotfound"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// installHintKey accepts the install suggestion for a missing command. It is
+// only claimed while a hint is shown; otherwise it reaches the shell.
+const installHintKey = "alt+i"
+
+// installHint is the pending offer for a command the shell could not find.
+type installHint struct {
+	command string
+	install string // Empty when the package is unknown: ask the AI instead
+}
+
+func (h installHint) statusText() string {
+	if h.install != "" {
+		return fmt.Sprintf("%s not found | Alt+I types: %s", h.command, h.install)
+	}
+	return fmt.Sprintf("%s not found | Alt+I asks AI how to install it", h.command)
+}
+
+// detectCommandNotFound offers a correction, or failing that an install
+// hint, when line is the shell reporting that the command just run does not
+// exist.
+func (m *Model) detectCommandNotFound(line []byte) {
+	command, ok := cmdnotfound.Detect(string(line))
+	if !ok || !m.lastCommandRuns(command) {
+		return
+	}
+	if !cmdnotfound.Known(command) && m.suggestCorrection(command) {
+		return
+	}
+
+	hint := installHint{command: command}
+	if m.packageManager != nil {
+		if mgr, ok := m.packageManager(); ok {
+			hint.install, _ = cmdnotfound.Suggest(command, mgr)
+		}
+	}
+	m.installHint = &hint
+	m.postStatus(statusbar.Message{Source: statusSourceHint, Text: hint.statusText(), Priority: statusbar.PriorityNormal}, 0)
+	slog.Info("command_not_found", "command", command, "install", hint.install)
+}
+
+// lastCommandRuns reports whether the last recorded command invokes command,
+// so a "not found" line printed by e.g. cat does not raise a hint.
+func (m *Model) lastCommandRuns(command string) bool {
+	if m.session == nil {
+		return true
+	}
+	last := m.session.GetLastN(1)
+	if len(last) == 0 {
+		return true
+	}
+	return slices.Contains(strings.Fields(last[0].Command), command)
+}
+
+// clearInstallHint drops the hint and its status message.
+func (m *Model) clearInstallHint() {
+	if m.installHint == nil {
+		return
+	}
+	m.clearStatusText(statusSourceHint, m.installHint.statusText())
+	m.installHint = nil
+}
+
+// acceptInstallHint puts the install command on the prompt for the user to
+// review, or asks the AI when no package is known.
+func (m Model) acceptInstallHint() (Model, tea.Cmd) {
+	hint := *m.installHint
+	m.clearInstallHint()
+
+	if hint.install != "" {
+		slog.Info("install_hint_accept", "command", hint.command, "install", hint.install)
+		m.replacePromptCommand(hint.install)
+		return m, nil
+	}
+
+	if m.hasActiveStream() {
+		return m, m.flashStatus("AI is busy; try again when the answer finishes")
+	}
+	slog.Info("install_hint_ask_ai", "command", hint.command)
+	s := m.ensureSidebar()
+	s.Show()
+	s.FocusInput()
+	m.setTerminalFocused(false)
+	m.applyLayout()
+	question := fmt.Sprintf("The `%s` command is not installed. Which package provides it on my system, and what is the exact command to install it?", hint.command)
+	return m.handleChatSubmit(sidebar.ChatSubmitMsg{Content: question})
+}
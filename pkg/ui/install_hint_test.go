@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/cmdnotfound"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+var altIKey = tea.KeyPressMsg{Code: 'i', Mod: tea.ModAlt}
+
+func newInstallHintTestModel(t *testing.T, command string) (Model, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+	sess := capture.NewSessionContext()
+	sess.AddCommand(capture.CommandRecord{Command: command})
+	m := NewModel(w, buffer.New(100), sess, nil)
+	m.inputHandler = input.NewInputHandler(w)
+	m.packageManager = func() (cmdnotfound.Manager, bool) {
+		return cmdnotfound.Manager{Family: "debian", Install: "sudo apt install"}, true
+	}
+	m.pathBinaries = func() []string { return nil }
+	return m, r
+}
+
+func TestInstallHint_KnownPackage(t *testing.T) {
+	m, r := newInstallHintTestModel(t, "htop")
+
+	m.detectCommandNotFound([]byte("bash: htop: command not found"))
+	if m.installHint == nil {
+		t.Fatal("Expected an install hint")
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "sudo apt install htop") {
+		t.Fatalf("Expected install command in status bar, got %q", msg)
+	}
+
+	updated, _ := m.handleKeyPress(altIKey)
+	m = updated
+	if m.installHint != nil {
+		t.Fatal("Expected hint to be cleared after accepting")
+	}
+	if m.statusBar.GetMessage() != "" {
+		t.Fatalf("Expected status message cleared, got %q", m.statusBar.GetMessage())
+	}
+	got := make([]byte, 64)
+	n, err := r.Read(got)
+	if err != nil {
+		t.Fatalf("read pty: %v", err)
+	}
+	if !strings.Contains(string(got[:n]), "sudo apt install htop") {
+		t.Fatalf("Expected install command typed at the prompt, got %q", got[:n])
+	}
+	if strings.Contains(string(got[:n]), "\r") {
+		t.Fatal("Expected the install command not to be executed")
+	}
+}
+
+func TestInstallHint_UnknownPackageOffersAI(t *testing.T) {
+	m, _ := newInstallHintTestModel(t, "frobnicate --now")
+
+	m.detectCommandNotFound([]byte("zsh: command not found: frobnicate"))
+	if m.installHint == nil || m.installHint.install != "" {
+		t.Fatalf("Expected an AI fallback hint, got %+v", m.installHint)
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "asks AI") {
+		t.Fatalf("Expected AI fallback in status bar, got %q", msg)
+	}
+}
+
+func TestInstallHint_IgnoresOtherCommandsAndClearsOnNextCommand(t *testing.T) {
+	m, _ := newInstallHintTestModel(t, "cat notes.txt")
+
+	m.detectCommandNotFound([]byte("bash: htop: command not found"))
+	if m.installHint != nil {
+		t.Fatal("Expected no hint when the last command did not run htop")
+	}
+
+	m.session.AddCommand(capture.CommandRecord{Command: "htop"})
+	m.detectCommandNotFound([]byte("bash: htop: command not found"))
+	if m.installHint == nil {
+		t.Fatal("Expected a hint")
+	}
+	updated, _ := m.handleCommandSubmitted(input.CommandSubmittedMsg{Command: "ls"})
+	m = updated
+	if m.installHint != nil || m.statusBar.GetMessage() != "" {
+		t.Fatal("Expected the hint to clear when the next command runs")
+	}
+}
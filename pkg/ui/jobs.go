@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"log/slog"
+	"os"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/pty"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// jobsMsg carries the background jobs of the shell on ptyFile.
+type jobsMsg struct {
+	ptyFile *os.File
+	jobs    []pty.Job
+}
+
+// SetJobsFunc sets how the shell's background jobs are listed. Nil hides
+// the jobs badge and leaves them out of the AI context.
+func (m *Model) SetJobsFunc(fn func() ([]pty.Job, error)) {
+	m.jobsFunc = fn
+}
+
+// pollJobs lists the focused shell's jobs off the UI goroutine. It runs on
+// the directory tick and skips a tick while the previous poll is pending.
+func (m *Model) pollJobs() tea.Cmd {
+	if m.jobsFunc == nil || m.jobsPolling {
+		return nil
+	}
+	m.jobsPolling = true
+	list, ptyFile := m.jobsFunc, m.ptyFile
+	return func() tea.Msg {
+		jobs, err := list()
+		if err != nil {
+			slog.Debug("jobs_poll_error", "error", err)
+		}
+		return jobsMsg{ptyFile: ptyFile, jobs: jobs}
+	}
+}
+
+func (m Model) handleJobs(msg jobsMsg) (Model, tea.Cmd) {
+	m.jobsPolling = false
+	if msg.ptyFile != m.ptyFile {
+		// The other pane got focus meanwhile.
+		return m, nil
+	}
+	if len(msg.jobs) != len(m.jobs) {
+		slog.Debug("jobs_changed", "count", len(msg.jobs))
+	}
+	m.jobs = msg.jobs
+	return m, nil
+}
+
+// backgroundJobs returns the focused shell's jobs for the AI context.
+func (m Model) backgroundJobs() []ai.BackgroundJob {
+	if len(m.jobs) == 0 {
+		return nil
+	}
+	jobs := make([]ai.BackgroundJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, ai.BackgroundJob{PID: job.PGID, Command: job.Command, Stopped: job.Stopped})
+	}
+	return jobs
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/pty"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestModel_PollsBackgroundJobs(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = newModel.(Model)
+
+	calls := 0
+	m.SetJobsFunc(func() ([]pty.Job, error) {
+		calls++
+		return []pty.Job{
+			{PGID: 4242, Command: "python3 -m http.server 8080"},
+			{PGID: 4300, Command: "vim notes.md", Stopped: true},
+		}, nil
+	})
+
+	cmd := m.pollJobs()
+	if cmd == nil {
+		t.Fatal("Expected a poll command")
+	}
+	if m.pollJobs() != nil {
+		t.Fatal("Expected no second poll while one is pending")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+	if calls != 1 || m.jobsPolling {
+		t.Fatalf("Expected one finished poll, got %d calls (pending=%v)", calls, m.jobsPolling)
+	}
+
+	view := ansi.Strip(m.View().Content)
+	if !strings.Contains(view, "jobs: 2") {
+		t.Fatalf("Expected the jobs badge in the status bar, got:\n%s", view)
+	}
+	jobs := m.commandContext().Jobs
+	if len(jobs) != 2 || jobs[0].PID != 4242 || !jobs[1].Stopped {
+		t.Fatalf("Expected the jobs in the AI context, got %+v", jobs)
+	}
+}
+
+func TestModel_JobsPollErrorClearsJobs(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.jobs = []pty.Job{{PGID: 1, Command: "sleep 100"}}
+	m.SetJobsFunc(func() ([]pty.Job, error) { return nil, errors.New("no process running") })
+
+	newModel, _ := m.Update(m.pollJobs()())
+	m = newModel.(Model)
+	if len(m.jobs) != 0 || m.commandContext().Jobs != nil {
+		t.Fatalf("Expected no jobs after a failed poll, got %+v", m.jobs)
+	}
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/contextpanel"
+	"wtf_cli/pkg/ui/components/layout"
+)
+
+func layoutPresetsFor(cfg config.LayoutConfig) layout.Presets {
+	return layout.Presets{OverlayBelow: cfg.OverlayBelow, ThreeColumnsFrom: cfg.ThreeColumnsFrom}
+}
+
+// columns lays out a screen width for the sidebar as it is now.
+func (m *Model) columns(width int) layout.Columns {
+	open := m.sidebar != nil && m.sidebar.IsVisible()
+	return layout.ColumnsFor(width, open, m.layoutPresets)
+}
+
+// noteLayoutMode logs when a resize or the sidebar changes the layout.
+func (m *Model) noteLayoutMode(cols layout.Columns, width int) {
+	if cols.Mode == m.layoutMode {
+		return
+	}
+	slog.Info("layout_mode", "from", m.layoutMode, "to", cols.Mode, "width", width)
+	m.layoutMode = cols.Mode
+}
+
+// sidebarCovers reports whether the sidebar is drawn over the terminal:
+// the overlay layout while the chat has focus. Shift+Tab gives the
+// terminal back its screen without closing the chat.
+func (m *Model) sidebarCovers(cols layout.Columns) bool {
+	return cols.Mode == layout.ModeOverlay && !m.terminalFocused
+}
+
+// onSidebar reports whether screen column x shows the sidebar.
+func (m *Model) onSidebar(cols layout.Columns, x int) bool {
+	switch cols.Mode {
+	case layout.ModeOverlay:
+		return m.sidebarCovers(cols)
+	case layout.ModeSplit, layout.ModeThreeColumn:
+		return x >= cols.SidebarX
+	}
+	return false
+}
+
+// onContextPanel reports whether screen column x shows the context panel.
+func onContextPanel(cols layout.Columns, x int) bool {
+	return cols.ContextWidth > 0 && x >= cols.ContextX && x < cols.ContextX+cols.ContextWidth
+}
+
+// contextPanelItems lists what the next AI request carries, for the
+// three-column layout.
+func (m *Model) contextPanelItems() []contextpanel.Item {
+	items := []contextpanel.Item{
+		{Label: "Directory", Value: m.currentDir},
+		{Label: "Git", Value: m.gitBranch},
+	}
+	items = append(items, contextpanel.Item{Label: "Model", Value: m.activeProvider + "-" + m.activeModel})
+	if m.privacy {
+		return append(items, contextpanel.Item{Label: "Output", Value: "not captured (privacy mode)"})
+	}
+	if m.session != nil {
+		if last := m.session.GetLastN(1); len(last) == 1 {
+			value := "$ " + last[0].Command
+			if last[0].BufferEnd > 0 && last[0].ExitCode >= 0 {
+				value += fmt.Sprintf("\nexit %d", last[0].ExitCode)
+			}
+			items = append(items, contextpanel.Item{Label: "Last command", Value: value})
+		}
+	}
+	if m.buffer != nil {
+		items = append(items, contextpanel.Item{Label: "Output", Value: fmt.Sprintf("%d lines captured", m.buffer.Size())})
+	}
+	if len(m.pinnedBlocks) > 0 {
+		labels := make([]string, len(m.pinnedBlocks))
+		for i, b := range m.pinnedBlocks {
+			labels[i] = b.Label
+		}
+		items = append(items, contextpanel.Item{Label: "Pinned", Value: strings.Join(labels, "\n")})
+	}
+	if len(m.jobs) > 0 {
+		items = append(items, contextpanel.Item{Label: "Jobs", Value: fmt.Sprintf("%d in the background", len(m.jobs))})
+	}
+	return items
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/layout"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newLayoutModel(t *testing.T, width int) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 20})
+	m = newModel.(Model)
+	m.viewport.AppendOutput([]byte("terminal-output"))
+	m.ensureSidebar().SetContent("chat-reply")
+	return m
+}
+
+func TestModel_NarrowSidebarCoversTerminal(t *testing.T) {
+	m := newLayoutModel(t, 60)
+	m.showSidebar("test")
+	if m.layoutMode != layout.ModeOverlay {
+		t.Fatalf("layout mode = %v, want overlay", m.layoutMode)
+	}
+	if w := m.viewport.Viewport.Width(); w != 60 {
+		t.Fatalf("terminal width = %d, want the full width kept", w)
+	}
+	view := ansi.Strip(m.renderCanvas().Render())
+	if !strings.Contains(view, "chat-reply") || strings.Contains(view, "terminal-output") {
+		t.Fatalf("expected the chat over the terminal:\n%s", view)
+	}
+
+	newModel, _ := m.handleFocusSwitch()
+	m = newModel
+	if !m.terminalFocused || !m.sidebar.IsVisible() {
+		t.Fatal("expected Shift+Tab to give the terminal focus and keep the chat open")
+	}
+	view = ansi.Strip(m.renderCanvas().Render())
+	if strings.Contains(view, "chat-reply") || !strings.Contains(view, "terminal-output") {
+		t.Fatalf("expected the terminal back:\n%s", view)
+	}
+
+	// A click goes to the terminal while it is showing.
+	newModel, _ = m.handleMouseClick(tea.MouseClickMsg(tea.Mouse{X: 50, Y: 2, Button: tea.MouseLeft}))
+	if !newModel.terminalFocused {
+		t.Fatal("expected the click to stay on the terminal")
+	}
+}
+
+func TestModel_WideSidebarShowsContextPanel(t *testing.T) {
+	m := newLayoutModel(t, 220)
+	m.currentDir = "/srv/app"
+	m.session.AddCommand(capture.CommandRecord{Command: "make test"})
+	m.showSidebar("test")
+	if m.layoutMode != layout.ModeThreeColumn {
+		t.Fatalf("layout mode = %v, want three columns", m.layoutMode)
+	}
+	cols := m.columns(m.width)
+	if w := m.viewport.Viewport.Width(); w != cols.TerminalWidth {
+		t.Fatalf("terminal width = %d, want %d", w, cols.TerminalWidth)
+	}
+	view := ansi.Strip(m.renderCanvas().Render())
+	for _, want := range []string{"terminal-output", "Context", "/srv/app", "$ make test", "chat-reply"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in three columns:\n%s", want, view)
+		}
+	}
+
+	// Clicking the context panel moves focus nowhere.
+	m.setTerminalFocused(true)
+	newModel, _ := m.handleMouseClick(tea.MouseClickMsg(tea.Mouse{X: cols.ContextX + 2, Y: 3, Button: tea.MouseLeft}))
+	if !newModel.terminalFocused || newModel.viewport.HasActiveSelection() {
+		t.Fatal("expected a context panel click to be ignored")
+	}
+
+	m.hideSidebar("test")
+	if m.layoutMode != layout.ModeTerminal {
+		t.Fatalf("layout mode = %v after closing the chat", m.layoutMode)
+	}
+}
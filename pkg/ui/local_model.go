@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// localModelTickInterval is how often the status bar refreshes while a local
+// model loads.
+const localModelTickInterval = 500 * time.Millisecond
+
+type localModelTickMsg struct{}
+
+// localModelFailedMsg reports a model whose load could not be started.
+type localModelFailedMsg struct {
+	model string
+	err   error
+}
+
+// warmLocalModelCmd starts loading cfg's model ahead of the first request
+// when it is served from this machine, and reports the load in the status
+// bar. It returns nil for remote providers. A model already loaded is not
+// reported again.
+func warmLocalModelCmd(cfg config.Config) tea.Cmd {
+	if !ai.UsesLocalModel(cfg) {
+		return nil
+	}
+	return func() tea.Msg {
+		_, model := getProviderAndModel(cfg)
+		provider, err := ai.GetProviderFromConfig(cfg)
+		if err != nil {
+			slog.Warn("local_model_load_skipped", "error", err)
+			return localModelFailedMsg{model: model, err: err}
+		}
+		timeout := cfg.LocalModels.LoadTimeoutSeconds
+		if timeout <= 0 {
+			timeout = config.DefaultLocalModelLoadTimeoutSeconds
+		}
+		if !warmupStart(provider, model, time.Duration(timeout)*time.Second) {
+			return nil
+		}
+		return localModelTickMsg{}
+	}
+}
+
+// warmupStart starts the shared warmup. Variable for tests.
+var warmupStart = ai.DefaultLocalWarmup.Start
+
+func (m Model) handleLocalModelFailed(msg localModelFailedMsg) (Model, tea.Cmd) {
+	return m, m.flashStatus(fmt.Sprintf("Could not load %s: %v", msg.model, msg.err))
+}
+
+// handleLocalModelTick shows the load time while the model loads and the
+// outcome once it is done.
+func (m Model) handleLocalModelTick() (Model, tea.Cmd) {
+	p := ai.DefaultLocalWarmup.Progress()
+	if p.Loading {
+		text := fmt.Sprintf("Loading %s… %ds", p.Model, int(p.Elapsed.Seconds()))
+		m.postStatus(statusbar.Message{Source: statusSourceLocalModel, Text: text, Priority: statusbar.PriorityLow}, 0)
+		return m, tea.Tick(localModelTickInterval, func(time.Time) tea.Msg {
+			return localModelTickMsg{}
+		})
+	}
+	m.clearStatus(statusSourceLocalModel)
+	if p.Err != nil {
+		return m, m.flashStatus(fmt.Sprintf("Could not load %s: %v", p.Model, p.Err))
+	}
+	return m, m.flashStatus(fmt.Sprintf("%s loaded in %.1fs", p.Model, p.Elapsed.Seconds()))
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+)
+
+func TestWarmLocalModelCmd_ReportsOnlyLoadsItStarted(t *testing.T) {
+	orig := warmupStart
+	t.Cleanup(func() { warmupStart = orig })
+	started := false
+	warmupStart = func(ai.Provider, string, time.Duration) bool { return started }
+
+	cfg := config.Default()
+	cfg.OpenRouter.APIURL = "http://localhost:11434/v1"
+	cfg.OpenRouter.APIKey = "test-key"
+
+	if msg := warmLocalModelCmd(cfg)(); msg != nil {
+		t.Fatalf("expected no report for a model already loaded, got %#v", msg)
+	}
+	started = true
+	if _, ok := warmLocalModelCmd(cfg)().(localModelTickMsg); !ok {
+		t.Fatal("expected a started load to be followed in the status bar")
+	}
+}
+
+func TestWarmLocalModelCmd_ReportsProviderFailure(t *testing.T) {
+	cfg := config.Default()
+	cfg.LLMProvider = "no-such-provider"
+	cfg.OpenRouter.APIURL = "http://localhost:11434/v1"
+
+	msg, ok := warmLocalModelCmd(cfg)().(localModelFailedMsg)
+	if !ok {
+		t.Fatal("expected the failure to be reported")
+	}
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handleLocalModelFailed(msg)
+	if got := m.statusBar.GetMessage(); !strings.HasPrefix(got, "Could not load") {
+		t.Fatalf("status = %q, want the load failure", got)
+	}
+}
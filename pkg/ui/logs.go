@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/logging"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	// logsTailLines is how much of the log /logs shows.
+	logsTailLines = 500
+	// logsTickInterval is how often /logs picks up new records.
+	logsTickInterval = time.Second
+)
+
+// logsTickMsg carries the log tail read for the /logs panel. seq ties it to
+// one opening so a reopened panel does not run two refresh loops.
+type logsTickMsg struct {
+	seq     int
+	content string
+	err     error
+}
+
+func (m Model) logFile() string {
+	return logFilePath(m.logPath)
+}
+
+// logFilePath returns override, or the log file the config names.
+func logFilePath(override string) string {
+	if override != "" {
+		return override
+	}
+	return logging.Path(loadUIConfig())
+}
+
+// openLogs shows the end of the current log in the result panel and keeps
+// following it while the panel is open.
+func (m Model) openLogs() (Model, tea.Cmd) {
+	path := m.logFile()
+	content, err := readLogTail(path)
+	if err != nil {
+		slog.Warn("logs_open_failed", "path", path, "error", err)
+		if os.IsNotExist(err) {
+			return m, m.flashStatus("Nothing logged yet: " + path)
+		}
+		return m, m.flashStatus("Could not read log: " + err.Error())
+	}
+
+	slog.Info("logs_open", "path", path)
+	m.logsSeq++
+	m.logsTitle = "Log: " + path
+	m.resultPanel.Show(m.logsTitle, content)
+	m.resultPanel.ScrollToBottom()
+	return m, m.logsTick()
+}
+
+// logsTick waits logsTickInterval, then reads the log tail off the UI
+// goroutine.
+func (m Model) logsTick() tea.Cmd {
+	seq, override := m.logsSeq, m.logPath
+	return tea.Tick(logsTickInterval, func(time.Time) tea.Msg {
+		return readLogsTick(seq, override)
+	})
+}
+
+func readLogsTick(seq int, override string) tea.Msg {
+	content, err := readLogTail(logFilePath(override))
+	return logsTickMsg{seq: seq, content: content, err: err}
+}
+
+// handleLogsTick shows the reloaded log while its panel is open. The view
+// stays at the bottom unless the user scrolled up.
+func (m Model) handleLogsTick(msg logsTickMsg) (Model, tea.Cmd) {
+	if msg.seq != m.logsSeq || !m.resultPanel.IsVisible() || m.resultPanel.Title() != m.logsTitle {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m, m.logsTick()
+	}
+	following := m.resultPanel.AtBottom()
+	m.resultPanel.SetContent(msg.content)
+	if following {
+		m.resultPanel.ScrollToBottom()
+	}
+	return m, m.logsTick()
+}
+
+func readLogTail(path string) (string, error) {
+	lines, err := logging.Tail(path, logsTailLines)
+	if err != nil {
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "(log is empty)", nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
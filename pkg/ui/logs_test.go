@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestModel_LogsFollowsTheLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wtf_cli.log")
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "level=INFO msg=record_%03d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.logPath = path
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = newModel.(Model)
+
+	newModel, cmd := m.Update(palette.PaletteSelectMsg{Command: "/logs"})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected a refresh tick")
+	}
+	view := m.resultPanel.View()
+	if !strings.Contains(view, "record_099") || strings.Contains(view, "record_000") {
+		t.Fatalf("Expected the panel to open at the end of the log, got:\n%s", view)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, "level=INFO msg=record_new")
+	f.Close()
+
+	newModel, cmd = m.Update(readLogsTick(m.logsSeq, m.logPath))
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the refresh to continue while the panel is open")
+	}
+	if !strings.Contains(m.resultPanel.View(), "record_new") {
+		t.Fatalf("Expected the new record to be shown, got:\n%s", m.resultPanel.View())
+	}
+
+	if _, cmd := m.Update(readLogsTick(m.logsSeq-1, m.logPath)); cmd != nil {
+		t.Fatal("Expected a stale tick to stop")
+	}
+	m.resultPanel.Hide()
+	if _, cmd := m.Update(readLogsTick(m.logsSeq, m.logPath)); cmd != nil {
+		t.Fatal("Expected the refresh to stop once the panel is closed")
+	}
+}
+
+func TestModel_LogsMissingFile(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.logPath = filepath.Join(t.TempDir(), "missing.log")
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/logs"})
+	m = newModel.(Model)
+	if m.resultPanel.IsVisible() {
+		t.Fatal("Expected no panel without a log")
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Nothing logged yet") {
+		t.Fatalf("Expected a status message, got %q", msg)
+	}
+}
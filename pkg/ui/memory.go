@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/utils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// memoriesFieldKey routes option picker selections made from /memories.
+const memoriesFieldKey = "memories"
+
+// forgetAllMemories is the /memories entry that forgets every fact of the
+// project.
+const forgetAllMemories = "Forget all"
+
+// memoryLabelWidth caps a fact shown in /memories.
+const memoryLabelWidth = 72
+
+func (m Model) memoriesFile() string {
+	if m.memoriesPath != "" {
+		return m.memoriesPath
+	}
+	return ai.DefaultMemoriesPath()
+}
+
+// memories returns the facts remembered for the current project.
+func (m Model) memories() []ai.Memory {
+	if m.currentDir == "" {
+		return nil
+	}
+	return ai.LoadMemories(m.memoriesFile(), ai.ProjectRoot(m.currentDir))
+}
+
+// remember stores the fact typed after /remember for the current project.
+func (m Model) remember(fact string) (Model, tea.Cmd) {
+	fact = strings.TrimSpace(fact)
+	if fact == "" {
+		return m, m.flashStatus("Usage: /remember <fact>, e.g. /remember our staging host is sg-02")
+	}
+	if m.currentDir == "" {
+		return m, m.flashStatus("Current directory is not known yet")
+	}
+	project := ai.ProjectRoot(m.currentDir)
+	if err := ai.AddMemory(m.memoriesFile(), project, fact); err != nil {
+		slog.Warn("memory_add_failed", "project", project, "error", err)
+		return m, m.flashStatus("Could not remember: " + err.Error())
+	}
+	slog.Info("memory_add", "project", project, "len", len(fact), "source", "command")
+	return m, m.flashStatus("Remembered for " + project)
+}
+
+// openMemoriesPicker lists the facts remembered for the current project so
+// one, or all, can be forgotten.
+func (m Model) openMemoriesPicker() (Model, tea.Cmd) {
+	memories := m.memories()
+	if len(memories) == 0 {
+		return m, m.flashStatus("Nothing remembered for this project; use /remember <fact>")
+	}
+	options := make([]string, 0, len(memories)+1)
+	for i, mem := range memories {
+		options = append(options, memoryOptionLabel(i, mem))
+	}
+	options = append(options, forgetAllMemories)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Forget a memory of "+ai.ProjectRoot(m.currentDir), memoriesFieldKey, options, "")
+	return m, nil
+}
+
+// memoryOptionLabel renders a fact as "1. our staging host is sg-02".
+func memoryOptionLabel(i int, mem ai.Memory) string {
+	return fmt.Sprintf("%d. %s", i+1, utils.TruncateToWidth(mem.Text, memoryLabelWidth))
+}
+
+// forgetMemory removes the fact picked in /memories.
+func (m Model) forgetMemory(choice string) (Model, tea.Cmd) {
+	project := ai.ProjectRoot(m.currentDir)
+	if choice == forgetAllMemories {
+		if err := ai.ForgetMemories(m.memoriesFile(), project); err != nil {
+			return m, m.flashStatus("Could not forget: " + err.Error())
+		}
+		slog.Info("memory_forget", "project", project, "all", true)
+		return m, m.flashStatus("Forgot everything remembered for " + project)
+	}
+	for i, mem := range m.memories() {
+		if memoryOptionLabel(i, mem) != choice {
+			continue
+		}
+		if err := ai.ForgetMemory(m.memoriesFile(), project, i); err != nil {
+			return m, m.flashStatus("Could not forget: " + err.Error())
+		}
+		slog.Info("memory_forget", "project", project)
+		return m, m.flashStatus(fmt.Sprintf("Forgot %q", utils.TruncateToWidth(mem.Text, memoryLabelWidth)))
+	}
+	return m, nil
+}
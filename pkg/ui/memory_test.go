@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newMemoryTestModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = t.TempDir()
+	m.memoriesPath = filepath.Join(t.TempDir(), "memories.json")
+	trustWorkspace(t, &m, ai.TrustRestricted)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return newModel.(Model)
+}
+
+func TestModel_RememberAddsFactToContext(t *testing.T) {
+	m := newMemoryTestModel(t)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/remember", Args: "our staging host is sg-02"})
+	m = newModel.(Model)
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Remembered") {
+		t.Fatalf("Expected a confirmation, got %q", msg)
+	}
+
+	ctx := m.commandContext()
+	if len(ctx.Memories) != 1 || ctx.Memories[0].Text != "our staging host is sg-02" {
+		t.Fatalf("Expected the fact in the command context, got %+v", ctx.Memories)
+	}
+	if ctx.MemoriesPath != m.memoriesPath {
+		t.Fatalf("Expected the memory file in the command context, got %q", ctx.MemoriesPath)
+	}
+}
+
+func TestModel_RememberWithoutFactShowsUsage(t *testing.T) {
+	m := newMemoryTestModel(t)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/remember"})
+	m = newModel.(Model)
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Usage: /remember") {
+		t.Fatalf("Expected usage, got %q", msg)
+	}
+	if len(m.memories()) != 0 {
+		t.Fatal("Expected nothing remembered")
+	}
+}
+
+func TestModel_MemoriesPickerForgetsFact(t *testing.T) {
+	m := newMemoryTestModel(t)
+	project := ai.ProjectRoot(m.currentDir)
+	for _, fact := range []string{"staging is sg-02", "use pnpm"} {
+		if err := ai.AddMemory(m.memoriesPath, project, fact); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/memories"})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the memories picker")
+	}
+	if view := m.optionPicker.View(); !strings.Contains(view, "1. staging is sg-02") || !strings.Contains(view, forgetAllMemories) {
+		t.Fatalf("Expected the facts listed, got:\n%s", view)
+	}
+
+	newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: memoriesFieldKey, Value: "1. staging is sg-02"})
+	m = newModel.(Model)
+	if got := m.memories(); len(got) != 1 || got[0].Text != "use pnpm" {
+		t.Fatalf("Expected only the other fact left, got %+v", got)
+	}
+
+	newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: memoriesFieldKey, Value: forgetAllMemories})
+	m = newModel.(Model)
+	if got := m.memories(); len(got) != 0 {
+		t.Fatalf("Expected everything forgotten, got %+v", got)
+	}
+}
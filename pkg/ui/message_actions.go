@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Picker field keys for the sidebar message menu. They share the option and
+// model pickers with the settings panel and are routed by field key.
+const (
+	messageActionFieldKey = "message_action"
+	rerunModelFieldKey    = "message_rerun_model"
+)
+
+// Message menu entries, in display order.
+const (
+	messageActionCopy        = "Copy message"
+	messageActionCopyCmds    = "Copy all commands"
+	messageActionExportCmds  = "Export commands as script"
+	messageActionRerun       = "Re-run with different model"
+	messageActionPin         = "Pin message"
+	messageActionUnpin       = "Unpin message"
+	messageActionReportBad   = "Report bad answer"
+	messageActionMenuTitle   = "Message actions"
+	messageFeedbackSavedText = "Feedback saved, thanks"
+)
+
+func (m Model) handleSidebarMessageMenu(msg sidebar.MessageMenuMsg) (Model, tea.Cmd) {
+	if m.sidebar == nil {
+		return m, nil
+	}
+	chatMsg, ok := m.sidebar.MessageAt(msg.Index)
+	if !ok || chatMsg.Role != "assistant" {
+		return m, nil
+	}
+	m.messageActionIdx = msg.Index
+
+	options := m.refusalActions(msg.Index, chatMsg)
+	options = append(options, messageActionCopy)
+	if len(m.sidebar.MessageCommands(msg.Index)) > 0 {
+		options = append(options, messageActionCopyCmds, messageActionExportCmds)
+	}
+	for _, line := range m.sidebar.MessageCitations(msg.Index) {
+		options = append(options, citationOption(line))
+	}
+	if _, ok := m.sidebar.PromptFor(msg.Index); ok {
+		options = append(options, messageActionRerun)
+	}
+	if chatMsg.Pinned {
+		options = append(options, messageActionUnpin)
+	} else {
+		options = append(options, messageActionPin)
+	}
+	options = append(options, messageActionReportBad)
+
+	slog.Info("message_menu_open", "index", msg.Index, "options", len(options))
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show(messageActionMenuTitle, messageActionFieldKey, options, "")
+	return m, nil
+}
+
+func (m Model) handleMessageAction(action string) (Model, tea.Cmd) {
+	idx := m.messageActionIdx
+	chatMsg, ok := m.sidebar.MessageAt(idx)
+	if !ok {
+		return m, nil
+	}
+	slog.Info("message_action", "action", action, "index", idx)
+
+	switch action {
+	case messageActionCopy:
+		text := sidebar.FormatReplies(sidebar.FormatCitations(sidebar.StripCommandMarkers(chatMsg.Content)))
+		return m, tea.Batch(tea.SetClipboard(text), m.flashStatus("Message copied to clipboard"))
+
+	case messageActionCopyCmds:
+		cmds := m.sidebar.MessageCommands(idx)
+		if len(cmds) == 0 {
+			return m, nil
+		}
+		return m, tea.Batch(
+			tea.SetClipboard(strings.Join(cmds, "\n")),
+			m.flashStatus(fmt.Sprintf("%d command(s) copied to clipboard", len(cmds))),
+		)
+
+	case messageActionExportCmds:
+		return m.openExportScriptPicker([]ai.ChatMessage{chatMsg})
+
+	case messageActionPin, messageActionUnpin:
+		pinned := m.sidebar.ToggleMessagePin(idx)
+		m.refreshSidebar()
+		if pinned {
+			return m, m.flashStatus("Message pinned to chat context")
+		}
+		return m, m.flashStatus("Message unpinned")
+
+	case messageActionRerun:
+		return m.openRerunModelPicker()
+
+	case messageActionReportBad:
+		return m, m.reportBadAnswer(idx)
+
+	case messageActionRephrase:
+		return m.rephraseMessage(idx)
+
+	case messageActionOtherProvider:
+		return m.openRetryProviderPicker()
+	}
+	if line, ok := parseCitationOption(action); ok {
+		return m.showCitedLine(line)
+	}
+	return m, nil
+}
+
+// openRerunModelPicker lists models for the active provider. Dynamic lists
+// are fetched in the background and update the open picker when they land.
+func (m Model) openRerunModelPicker() (Model, tea.Cmd) {
+	cfg := m.sessionConfig()
+	current := getModelForProvider(cfg)
+
+	provider, _ := getProviderAndModel(cfg)
+	source := ai.GetModelSource(ai.ProviderType(provider), cfg)
+	options := source.Known()
+
+	slog.Info("message_rerun_picker_open", "provider", cfg.LLMProvider, "current", current, "models", len(options))
+	modelPicker := m.ensureModelPicker()
+	modelPicker.SetSize(m.width, m.height)
+	modelPicker.Show(options, current, rerunModelFieldKey)
+	return m, m.runAsync(fetchModelsLabel, listModels(rerunModelFieldKey, source))
+}
+
+// rerunMessage asks the same question that produced the focused message
+// again with model. The new answer is appended to the chat.
+func (m Model) rerunMessage(model string) (Model, tea.Cmd) {
+	if m.sidebar == nil || m.hasActiveStream() {
+		return m, nil
+	}
+	history, ok := m.sidebar.PromptFor(m.messageActionIdx)
+	if !ok {
+		return m, nil
+	}
+	slog.Info("message_rerun", "index", m.messageActionIdx, "model", model, "history", len(history))
+
+	ctx := m.commandContext()
+	ctx.ModelOverride = model
+	return m.rerunWithContext(ctx, history, "Re-running with "+model)
+}
+
+// rerunWithContext answers history again with ctx and appends the answer
+// to the chat.
+func (m Model) rerunWithContext(ctx *commands.Context, history []ai.ChatMessage, status string) (Model, tea.Cmd) {
+	runCtx, streamID := m.beginStreamRun()
+	m.startStreamPlaceholder()
+	return m, tea.Batch(
+		startChatStreamCmd(streamID, runCtx, ctx, m.chatHandler(), history),
+		m.startStreamProgress(),
+		m.flashStatus(status),
+	)
+}
+
+// reportBadAnswer appends the question/answer pair to the local feedback log.
+func (m *Model) reportBadAnswer(idx int) tea.Cmd {
+	chatMsg, ok := m.sidebar.MessageAt(idx)
+	if !ok {
+		return nil
+	}
+	entry := ai.FeedbackEntry{Answer: chatMsg.Content}
+	if history, ok := m.sidebar.PromptFor(idx); ok {
+		entry.Question = history[len(history)-1].Content
+	}
+	entry.Provider, entry.Model = getProviderAndModel(m.sessionConfig())
+	entry.Model = m.chatModel(entry.Model)
+
+	path := m.feedbackPath
+	if path == "" {
+		path = ai.DefaultFeedbackPath()
+	}
+	if err := ai.AppendFeedback(path, entry); err != nil {
+		slog.Error("message_feedback_error", "error", err)
+		return m.flashStatus("Could not save feedback: " + err.Error())
+	}
+	slog.Info("message_feedback_saved", "path", path, "provider", entry.Provider, "model", entry.Model)
+	return m.flashStatus(messageFeedbackSavedText)
+}
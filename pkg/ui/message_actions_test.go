@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/sidebar"
+)
+
+func newMessageActionTestModel() Model {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.ensureSidebar().Show()
+	m.sidebar.AppendUserMessage("why did make fail?")
+	m.sidebar.StartAssistantMessageWithContent("Run <cmd>make clean</cmd> first.")
+	m.sidebar.RefreshView()
+	return m
+}
+
+func TestModel_MessageMenuOpensOptionPicker(t *testing.T) {
+	m := newMessageActionTestModel()
+
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 1})
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected message menu to be visible")
+	}
+	view := m.optionPicker.View()
+	for _, want := range []string{messageActionCopy, messageActionCopyCmds, messageActionRerun, messageActionPin, messageActionReportBad} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected menu to contain %q", want)
+		}
+	}
+}
+
+func TestModel_MessageMenuIgnoresUserMessages(t *testing.T) {
+	m := newMessageActionTestModel()
+
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 0})
+	if m.optionPicker != nil && m.optionPicker.IsVisible() {
+		t.Fatal("expected no menu for a user message")
+	}
+}
+
+func TestModel_MessageActionPinRoutesFromOptionPicker(t *testing.T) {
+	m := newMessageActionTestModel()
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 1})
+
+	m, cmd := m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{
+		FieldKey: messageActionFieldKey,
+		Value:    messageActionPin,
+	})
+	if cmd == nil {
+		t.Fatal("expected a status toast command")
+	}
+	if !m.sidebar.GetMessages()[1].Pinned {
+		t.Fatal("expected message to be pinned")
+	}
+	if got := m.statusBar.GetMessage(); got != "Message pinned to chat context" {
+		t.Errorf("status message = %q", got)
+	}
+}
+
+func TestModel_MessageActionReportBadAnswerWritesFeedback(t *testing.T) {
+	m := newMessageActionTestModel()
+	m.feedbackPath = filepath.Join(t.TempDir(), "feedback.jsonl")
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: 1})
+
+	m, _ = m.handleMessageAction(messageActionReportBad)
+
+	data, err := os.ReadFile(m.feedbackPath)
+	if err != nil {
+		t.Fatalf("read feedback: %v", err)
+	}
+	if !strings.Contains(string(data), "why did make fail?") || !strings.Contains(string(data), "make clean") {
+		t.Errorf("expected question and answer in feedback, got %s", data)
+	}
+	if got := m.statusBar.GetMessage(); got != messageFeedbackSavedText {
+		t.Errorf("status message = %q", got)
+	}
+}
@@ -3,18 +3,32 @@ package ui
 import (
 	"context"
 	"os"
+	"os/exec"
 	"time"
 
+	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/buffer"
 	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/cmdnotfound"
 	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/control"
+	"wtf_cli/pkg/hooks"
+	"wtf_cli/pkg/notify"
+	"wtf_cli/pkg/observe"
 	"wtf_cli/pkg/pty"
+	"wtf_cli/pkg/ui/components/contextpanel"
 	"wtf_cli/pkg/ui/components/continueprompt"
+	"wtf_cli/pkg/ui/components/envpicker"
 	"wtf_cli/pkg/ui/components/fullscreen"
 	"wtf_cli/pkg/ui/components/historypicker"
+	"wtf_cli/pkg/ui/components/layout"
 	"wtf_cli/pkg/ui/components/palette"
 	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/progress"
+	"wtf_cli/pkg/ui/components/promptpreview"
 	"wtf_cli/pkg/ui/components/result"
+	"wtf_cli/pkg/ui/components/scrubber"
 	"wtf_cli/pkg/ui/components/settings"
 	"wtf_cli/pkg/ui/components/sidebar"
 	"wtf_cli/pkg/ui/components/statusbar"
@@ -23,6 +37,7 @@ import (
 	"wtf_cli/pkg/ui/components/welcome"
 	"wtf_cli/pkg/ui/input"
 	"wtf_cli/pkg/ui/terminal"
+	"wtf_cli/pkg/watch"
 
 	tea "charm.land/bubbletea/v2"
 )
@@ -31,6 +46,9 @@ const (
 	streamThinkingPlaceholder = "Thinking..."
 	streamCanceledMessage     = "Request canceled."
 	selectedTextCopiedMessage = "Selected text copied to clipboard"
+	// selectedOutputCopiedMessage is shown for terminal selections, which
+	// can also be pinned to the AI context.
+	selectedOutputCopiedMessage = "Selected text copied to clipboard · Alt+P pins it to AI requests"
 )
 
 // Model represents the Bubble Tea application state
@@ -38,23 +56,53 @@ type Model struct {
 	// PTY connection
 	ptyFile *os.File
 	cwdFunc func() (string, error) // Function to get shell's cwd
-	// secretDetector checks whether the PTY is in canonical secret-input mode.
-	// Injectable for tests.
-	secretDetector func(*os.File) bool
+	// environFunc reads the shell's environment for /env. Nil when the
+	// platform cannot read another process's environment.
+	environFunc func() ([]string, error)
+	// jobsFunc lists the shell's background jobs. Nil when the platform
+	// cannot tell.
+	jobsFunc    func() ([]pty.Job, error)
+	jobs        []pty.Job
+	jobsPolling bool
+	// historyAppend writes a command wtf_cli put at the prompt to the shell's
+	// history file once it runs. Nil disables the write-back.
+	historyAppend func(string) error
+	// syncedHistory returns the commands synced from other machines and
+	// earlier sessions, oldest first. Nil when history sync is off.
+	syncedHistory func() []capture.CommandRecord
+	// appliedCommand is the command wtf_cli typed at the prompt, kept while
+	// the line still holds it.
+	appliedCommand string
+	// secretDetector checks whether the PTY's foreground program reads a
+	// secret, given the unfinished line it printed. Injectable for tests.
+	secretDetector func(f *os.File, promptLine string) bool
 
 	// UI Components
-	viewport       viewport.PTYViewport              // Viewport for PTY output
-	statusBar      *statusbar.StatusBarView          // Status bar at bottom
-	inputHandler   *input.InputHandler               // Input routing to PTY
-	palette        *palette.CommandPalette           // Command palette overlay
-	historyPicker  *historypicker.HistoryPickerPanel // History search picker
-	resultPanel    *result.ResultPanel               // Result panel overlay
-	settingsPanel  *settings.SettingsPanel           // Settings panel overlay
-	modelPicker    *picker.ModelPickerPanel
-	optionPicker   *picker.OptionPickerPanel
-	sidebar        *sidebar.Sidebar // Sidebar for AI suggestions
-	toolApproval   *toolapproval.Panel
-	continuePrompt *continueprompt.Panel
+	viewport      viewport.PTYViewport              // Viewport for PTY output
+	statusBar     *statusbar.StatusBarView          // Status bar at bottom
+	inputHandler  *input.InputHandler               // Input routing to PTY
+	palette       *palette.CommandPalette           // Command palette overlay
+	historyPicker *historypicker.HistoryPickerPanel // History search picker
+	resultPanel   *result.ResultPanel               // Result panel overlay
+	settingsPanel *settings.SettingsPanel           // Settings panel overlay, nil until first opened
+	modelPicker   *picker.ModelPickerPanel
+	optionPicker  *picker.OptionPickerPanel
+	envPicker     *envpicker.EnvPickerPanel
+	sidebar       *sidebar.Sidebar    // Sidebar for AI suggestions, nil until first opened
+	contextPanel  *contextpanel.Panel // Middle column of the three-column layout
+	// activeProvider and activeModel name the LLM answering the chat, and
+	// defaultTemperature and defaultMaxTokens are its configured sampling
+	// settings. Kept here for ensureSidebar, which builds the sidebar later.
+	activeProvider     string
+	activeModel        string
+	defaultTemperature float64
+	defaultMaxTokens   int
+	toolApproval       *toolapproval.Panel
+	continuePrompt     *continueprompt.Panel
+	promptPreview      *promptpreview.Panel
+	scrubber           *scrubber.Panel // /replay; nil until first opened
+	// overlays orders the open overlays above; see syncOverlays.
+	overlays *overlayStack
 
 	// Command system
 	dispatcher *commands.Dispatcher
@@ -74,19 +122,208 @@ type Model struct {
 	buffer     *buffer.CircularBuffer
 	session    *capture.SessionContext
 	currentDir string
-	gitBranch  string
+	gitBranch  string // Git status label, e.g. "main* ↑1"
 
-	// gitBranchResolver resolves a git branch label from a directory path.
+	// gitBranchResolver resolves the git status label of a directory path.
 	// Injectable for tests.
 	gitBranchResolver func(string) string
+	// gitCache backs gitBranchResolver; commands finishing invalidate it.
+	gitCache *statusbar.GitCache
+
+	// notifier routes stream and long-command events to notification sinks.
+	notifier *notify.Notifier
+	// hooks runs the user's scripts on session events; pendingHooks are
+	// the events waiting for fireHooks.
+	hooks        *hooks.Runner
+	pendingHooks []hooks.Event
+	// aliases are the user's palette command aliases by name.
+	aliases map[string]config.CommandAlias
+	// policy is the organization policy of the loaded config.
+	policy config.Policy
+	// localModelWarmup loads a local model at startup; nil for remote
+	// providers.
+	localModelWarmup tea.Cmd
+	// providerPrewarm opens the provider connection at startup; nil unless
+	// prewarm is "startup".
+	providerPrewarm tea.Cmd
+	// sidebarPrewarmURL is the provider endpoint warmed when the sidebar
+	// opens; empty unless prewarm is "sidebar".
+	sidebarPrewarmURL string
+	// sidebarPrewarmTransport is the active provider's certificate and proxy.
+	sidebarPrewarmTransport config.ProviderTransport
+	// foregroundPGID reports the PTY's foreground process group, used to
+	// detect when a submitted command hands control back to the shell.
+	// Injectable for tests.
+	foregroundPGID func(*os.File) int
+	pendingCommand *pendingCommand
+	// shellInForeground reports whether the shell sits at its prompt, which
+	// decides whether Ctrl+Z suspends wtf_cli or the running job and when
+	// run triggers type their snippet.
+	// Injectable for tests.
+	shellInForeground func(*os.File) bool
+	// requestSuspend, when set, suspends wtf_cli in place of tea.Suspend so
+	// the caller can stop catching SIGTSTP first. See SetSuspendRequest.
+	requestSuspend func()
+	// chatTitler names the conversation after its first exchange.
+	// Injectable for tests.
+	chatTitler         chatTitler
+	chatTitleRequested bool
+	// observeHub mirrors output and chat to read-only observers. Nil unless
+	// the session was started with --share.
+	observeHub *observe.Hub
+	// controlServer takes calls from automation clients. Nil unless the
+	// session was started with --control.
+	controlServer *control.Server
+	// packageManager detects the host package manager for install hints.
+	// Injectable for tests.
+	packageManager func() (cmdnotfound.Manager, bool)
+	installHint    *installHint
+	// pathBinaries lists executables on PATH for typo suggestions.
+	// Injectable for tests.
+	pathBinaries func() []string
+	typoHint     *typoHint
+
+	// watchRun runs one iteration of /watch. Injectable for tests.
+	watchRun    func(ctx context.Context, command, dir string) watch.Result
+	watching    *watchState
+	watchChange *watchChange
+	watchSeq    int
+
+	// messageActionIdx is the sidebar message the action menu was opened for.
+	messageActionIdx int
+	// pendingScript holds an exported script until its destination is picked.
+	pendingScript *pendingScript
+	// promptChoices maps /prompts picker labels to variant names.
+	promptChoices map[string]string
+	// taskChoices maps /tasks picker labels to commands.
+	taskChoices map[string]string
+	// pinnedBlocks are sent with every AI request until unpinned.
+	pinnedBlocks []ai.PinnedBlock
+	// bookmarks are the focused pane's /mark positions in the scrollback.
+	bookmarks []bookmark
+	// lastSelection is terminal text copied by mouse selection, waiting
+	// to be pinned with pinSelectionKey.
+	lastSelection string
+	// paneSpawner starts the shell of a split pane. Nil disables splitting.
+	paneSpawner func() (PaneShell, error)
+	// shellProcess is the focused pane's shell when the model started it,
+	// for a split or a restart; nil for the first shell, which main owns.
+	shellProcess ShellProcess
+	// split is the unfocused pane while the terminal is split in two.
+	split *pane
+	// splitFocusRight is set while the right pane has focus.
+	splitFocusRight bool
+	// panePrefix is set after paneKey, while the next key picks a command.
+	panePrefix bool
+	// feedbackPath overrides where "Report bad answer" writes. Empty ⇒
+	// ai.DefaultFeedbackPath(). Injectable for tests.
+	feedbackPath string
+	// envSelectionsPath overrides where /env selections are remembered.
+	// Empty ⇒ ai.DefaultEnvSelectionsPath(). Injectable for tests.
+	envSelectionsPath string
+	// workspaceTrustPath overrides where workspace trust is remembered.
+	// Empty ⇒ ai.DefaultWorkspaceTrustPath(). Injectable for tests.
+	workspaceTrustPath string
+	// memoriesPath overrides where /remember stores facts. Empty ⇒
+	// ai.DefaultMemoriesPath(). Injectable for tests.
+	memoriesPath string
+	// scriptsDir and pinnedScriptsPath override where /snippets finds saved
+	// scripts and their pins. Empty ⇒ the historysync defaults. Injectable
+	// for tests.
+	scriptsDir        string
+	pinnedScriptsPath string
+	// triggers act on terminal output lines matching a pattern for the
+	// session; triggerHits are the matches waiting for the output batch to
+	// be appended, triggerRuns the run snippets waiting for the prompt.
+	triggers    []trigger
+	triggerHits []triggerHit
+	triggerRuns []string
+	// trustPending is the request waiting for the workspace trust prompt.
+	trustPending tea.Msg
+	// budgets are the monthly token budgets, checked against llmProvider
+	// with the usage recorded in usagePath (ai.DefaultUsagePath when empty).
+	budgets     config.BudgetsConfig
+	llmProvider string
+	usagePath   string
+	// budgetOverrides holds providers the user sent past their budget this
+	// session; budgetPending is the request waiting for that answer.
+	budgetOverrides map[string]bool
+	budgetPending   tea.Msg
+
+	// confirmCommands asks before Enter runs a destructive typed command;
+	// typedConfirm is the command waiting for the answer.
+	confirmCommands config.ConfirmCommandsConfig
+	typedConfirm    *typedConfirm
+	// shellHistory reads the history file of the shell with the given
+	// environment, to skip confirming a command run before. Injectable for
+	// tests. It runs once in a Cmd; knownCommands holds the normalized
+	// commands it found and stays nil until they arrive.
+	shellHistory  func(environ []string) ([]string, error)
+	knownCommands map[string]bool
+
+	// syntax checks the command typed at the prompt; see syntax_check.go.
+	syntax syntaxChecker
+
+	// workspace maps the project the shell is in; see workspace_index.go.
+	workspace workspaceIndexer
+
+	// pasteConfirmBytes is the paste size above which the user is asked
+	// first; 0 never asks.
+	pasteConfirmBytes int
+	// pasteTerminal and pasteChat clean up pastes into the shell and the
+	// chat input (paste.terminal, paste.chat).
+	pasteTerminal config.PasteTargetConfig
+	pasteChat     config.PasteTargetConfig
+	// primarySelection reads the primary selection for a middle click.
+	// Injectable for tests.
+	primarySelection func() (string, bool)
+	// providerOverride is the provider picked in /provider for this
+	// session; empty uses llm_provider.
+	providerOverride string
+	// modelOverride is the model picked with /model in the chat for this
+	// session; empty uses the provider's configured model.
+	modelOverride string
+
+	// recap tracks idle time for the "while you were away" recap.
+	recap recapState
+
+	// pendingPaste waits for the large-paste confirmation.
+	pendingPaste string
+	// pendingDirCommand waits for the choice of where to run a suggestion
+	// made in another directory, pendingDirCommandDir.
+	pendingDirCommand    string
+	pendingDirCommandDir string
+	// historyFilter hides ignored commands from the history picker.
+	historyFilter capture.HistoryFilter
+
+	// pickerPageSize is how many rows PgUp/PgDn move in the model and
+	// option pickers; 0 is a screenful.
+	pickerPageSize int
+	// layoutPresets are the widths at which the sidebar layout changes;
+	// layoutMode is the layout last applied.
+	layoutPresets layout.Presets
+	layoutMode    layout.Mode
+	// privacy stops capture for every pane: output stays out of the
+	// buffer, commands out of the session and AI requests get no terminal
+	// context. Set by privacy.disable_capture and /privacy.
+	privacy bool
+	// readOnly keeps every key, paste and command from the PTYs of all
+	// panes, for screen sharing. Set by --read-only and /readonly.
+	readOnly bool
+	// paste is the large paste being written to the PTY in chunks, shown
+	// in the status bar as pasteProgressID.
+	paste           *input.Paste
+	pasteProgressID int
+	// logPath overrides the log /logs shows. Empty ⇒ the configured
+	// log_file. Injectable for tests.
+	logPath string
+	// logsSeq and logsTitle identify the /logs panel being followed.
+	logsSeq   int
+	logsTitle string
 
 	// Streaming state
-	wtfStream               <-chan commands.WtfStreamEvent
-	streamCancel            context.CancelFunc
-	streamID                int
-	streamPlaceholderActive bool
-	streamStartPending      bool
-	toolCallNewTurnNeeded   bool // true after a tool call finishes; next delta starts a new assistant message
+	aiJobs           aiJobManager
+	streamProgressID int // Status bar operation shown until the stream opens
 
 	// UI state
 	width           int
@@ -97,12 +334,19 @@ type Model struct {
 
 	exitPending   bool
 	exitConfirmID int
+	shellExited   bool // The shell ended; the end-of-session choice is offered
 
 	resizeDebounceID int       // Counter to debounce resize events
 	resizeTime       time.Time // When last PTY resize occurred (to suppress prompt reprint)
 	initialResize    bool      // Track if we've done the initial resize
 
 	ptyNormalizer *terminal.Normalizer
+	// rawLines keeps the PTY bytes of recent lines for /peek.
+	rawLines    *rawLineLog
+	promptZones promptZones
+	// repl tells statements typed into an interpreter (python, psql, node)
+	// apart from shell commands.
+	repl *capture.REPLTracker
 
 	// PTY output batching
 	ptyBatchBuffer  []byte        // Accumulated PTY data
@@ -110,10 +354,16 @@ type Model struct {
 	ptyBatchMaxSize int           // Max bytes before forced flush (default: 16KB)
 	ptyBatchMaxWait time.Duration // Max time before flush (default: 16ms)
 
+	bulkSettlePending bool // Whether a viewport bulk-mode poll is scheduled
+
 	// Stream update throttling
-	streamThrottlePending bool
+	streamActivityTicking bool          // The sidebar activity spinner is scheduled
 	streamThrottleDelay   time.Duration // Default: 50ms
 
+	// Background operations shown with a spinner in the status bar
+	progress        *progress.Tracker
+	progressTicking bool
+
 	// Full-screen app support (vim, nano, htop)
 	fullScreenMode  bool
 	fullScreenPanel *fullscreen.FullScreenPanel
@@ -121,10 +371,33 @@ type Model struct {
 
 	startupPTYOutputSeen bool
 	startupUpdateShown   bool
+	// startupQuestion is the --ask question waiting for the shell.
+	startupQuestion string
+	// exec is the command run in place of the shell by `wtf_cli exec`;
+	// nil for a shell.
+	exec *execState
 }
 
-// NewModel creates a new Bubble Tea model
+// startupTarget is the budget for process start to first rendered frame.
+// Exceeding it is logged as a warning so regressions show up in user logs.
+const startupTarget = 50 * time.Millisecond
+
+// processStart approximates process start time for the startup log line.
+var processStart = time.Now()
+
+// NewModel creates a new Bubble Tea model, reading the config file itself.
 func NewModel(ptyFile *os.File, buf *buffer.CircularBuffer, sess *capture.SessionContext, cwdFunc func() (string, error)) Model {
+	return NewModelWithConfig(loadUIConfig(), ptyFile, buf, sess, cwdFunc)
+}
+
+// NewModelWithConfig creates a new Bubble Tea model from an already-loaded
+// config, avoiding a second config read when the caller has one.
+//
+// Overlays that most sessions never open (model/option pickers, the
+// settings panel, the full-screen panel) and the chat sidebar are
+// constructed on first use; see ensureModelPicker, ensureOptionPicker,
+// ensureSettingsPanel, ensureSidebar and ensureFullScreenPanel.
+func NewModelWithConfig(cfg config.Config, ptyFile *os.File, buf *buffer.CircularBuffer, sess *capture.SessionContext, cwdFunc func() (string, error)) Model {
 	initialDir := getCurrentDir()
 	// Try to get initial dir from cwd function
 	if cwdFunc != nil {
@@ -138,24 +411,23 @@ func NewModel(ptyFile *os.File, buf *buffer.CircularBuffer, sess *capture.Sessio
 	viewport.AppendOutput([]byte(welcome.WelcomeMessage()))
 
 	statusBar := statusbar.NewStatusBarView()
-	provider, model := loadProviderAndModelFromConfig()
+	provider, model := getProviderAndModel(cfg)
 
 	m := Model{
 		ptyFile:          ptyFile,
 		cwdFunc:          cwdFunc,
-		secretDetector:   pty.IsSecretInputMode,
+		secretDetector:   pty.IsSecretInput,
 		viewport:         viewport,
 		statusBar:        statusBar,
 		inputHandler:     input.NewInputHandler(ptyFile),
 		palette:          palette.NewCommandPalette(),
 		historyPicker:    historypicker.NewHistoryPickerPanel(),
 		resultPanel:      result.NewResultPanel(),
-		settingsPanel:    settings.NewSettingsPanel(),
-		modelPicker:      picker.NewModelPickerPanel(),
-		optionPicker:     picker.NewOptionPickerPanel(),
-		sidebar:          sidebar.NewSidebar(),
+		contextPanel:     contextpanel.New(),
 		toolApproval:     toolapproval.NewPanel(),
 		continuePrompt:   continueprompt.NewPanel(),
+		promptPreview:    promptpreview.NewPanel(),
+		overlays:         &overlayStack{},
 		dispatcher:       commands.NewDispatcher(),
 		sessionApprovals: commands.NewSessionApprovals(),
 		pathGrants:       commands.NewPathGrants(),
@@ -163,20 +435,132 @@ func NewModel(ptyFile *os.File, buf *buffer.CircularBuffer, sess *capture.Sessio
 		session:          sess,
 		currentDir:       initialDir,
 
-		gitBranchResolver:   statusbar.ResolveGitBranch,
-		fullScreenPanel:     fullscreen.NewFullScreenPanel(80, 24),
+		gitBranchResolver:   statusbar.SharedGitCache().Label,
+		gitCache:            statusbar.SharedGitCache(),
+		notifier:            notify.New(cfg.Notifications),
+		hooks:               hooks.New(cfg.Hooks),
+		foregroundPGID:      pty.ForegroundProcessGroup,
+		shellInForeground:   pty.ShellInForeground,
+		chatTitler:          commands.GenerateChatTitle,
+		packageManager:      cmdnotfound.DetectManager,
+		pathBinaries:        pathBinaries,
+		watchRun:            watch.Run,
 		altScreenState:      terminal.NewAltScreenState(),
 		ptyNormalizer:       terminal.NewNormalizer(),
+		rawLines:            &rawLineLog{},
+		repl:                &capture.REPLTracker{},
+		progress:            progress.New(),
 		ptyBatchMaxSize:     16384,                 // 16KB
 		ptyBatchMaxWait:     16 * time.Millisecond, // ~60fps
 		streamThrottleDelay: 50 * time.Millisecond, // Throttle stream updates
 		terminalFocused:     true,
+		pasteConfirmBytes:   cfg.Paste.ConfirmAboveBytes(),
+		pasteTerminal:       cfg.Paste.Terminal,
+		pasteChat:           cfg.Paste.Chat,
+		primarySelection:    readPrimarySelection,
+		historyFilter:       historyFilterFor(cfg.History),
+		pickerPageSize:      cfg.Pickers.PageSize,
+		layoutPresets:       layoutPresetsFor(cfg.Layout),
+		confirmCommands:     cfg.ConfirmCommands,
+		syntax:              newSyntaxChecker(cfg.SyntaxCheck, os.Getenv("SHELL"), exec.LookPath),
+		workspace:           newWorkspaceIndexer(cfg.WorkspaceIndex),
+		recap:               newRecapState(cfg.Recap),
+		shellHistory:        defaultShellHistory,
+		budgetOverrides:     map[string]bool{},
 	}
-	m.sidebar.SetActiveLLM(provider, model)
+	m.setActiveLLM(provider, model)
+	m.setSamplingDefaults(getSamplingDefaults(cfg))
+	m.setBudgets(cfg)
+	m.localModelWarmup = warmLocalModelCmd(cfg)
+	m.providerPrewarm = prewarmCmd(cfg)
+	m.setPrivacy(cfg.Privacy.DisableCapture)
+	m.sidebarPrewarmURL = sidebarPrewarmURL(cfg)
+	m.sidebarPrewarmTransport = ai.ActiveTransport(cfg)
+	m.setPaletteAliases(cfg.Aliases)
+	m.setPolicy(cfg.Policy)
 	m.installAgentFactories()
 	return m
 }
 
+// ensureModelPicker returns the model picker, constructing it on first use.
+// Callers show it next, so it is pushed on top of the overlay stack.
+func (m *Model) ensureModelPicker() *picker.ModelPickerPanel {
+	if m.modelPicker == nil {
+		m.modelPicker = picker.NewModelPickerPanel()
+		m.modelPicker.SetSize(m.width, m.height)
+	}
+	m.modelPicker.SetPageSize(m.pickerPageSize)
+	m.overlays.push(overlayModelPicker)
+	return m.modelPicker
+}
+
+// ensureOptionPicker returns the option picker, constructing it on first use.
+// Callers show it next, so it is pushed on top of the overlay stack.
+func (m *Model) ensureOptionPicker() *picker.OptionPickerPanel {
+	if m.optionPicker == nil {
+		m.optionPicker = picker.NewOptionPickerPanel()
+		m.optionPicker.SetSize(m.width, m.height)
+	}
+	m.optionPicker.SetPageSize(m.pickerPageSize)
+	m.overlays.push(overlayOptionPicker)
+	return m.optionPicker
+}
+
+// ensureSettingsPanel returns the settings panel, constructing it on first
+// use. Callers show it next, so it is pushed on top of the overlay stack.
+func (m *Model) ensureSettingsPanel() *settings.SettingsPanel {
+	if m.settingsPanel == nil {
+		m.settingsPanel = settings.NewSettingsPanel()
+	}
+	m.settingsPanel.SetSize(m.width, m.height)
+	m.overlays.push(overlaySettings)
+	return m.settingsPanel
+}
+
+// ensureSidebar returns the chat sidebar, constructing it the first time a
+// conversation starts or the sidebar is opened. Until then a nil sidebar
+// stands for a hidden, empty chat.
+func (m *Model) ensureSidebar() *sidebar.Sidebar {
+	if m.sidebar == nil {
+		m.sidebar = sidebar.NewSidebar()
+		m.sidebar.SetActiveLLM(m.activeProvider, m.activeModel)
+		m.sidebar.SetParamDefaults(m.defaultTemperature, m.defaultMaxTokens)
+		m.sidebar.SetWorkingDir(m.currentDir)
+		m.sidebar.SetPinned(m.pinnedBlocks)
+	}
+	return m.sidebar
+}
+
+// setActiveLLM records the provider and model answering the chat.
+func (m *Model) setActiveLLM(provider, model string) {
+	m.activeProvider, m.activeModel = provider, model
+	if m.sidebar != nil {
+		m.sidebar.SetActiveLLM(provider, model)
+	}
+}
+
+// setSamplingDefaults records the configured sampling settings new
+// conversations start from.
+func (m *Model) setSamplingDefaults(temperature float64, maxTokens int) {
+	m.defaultTemperature, m.defaultMaxTokens = temperature, maxTokens
+	if m.sidebar != nil {
+		m.sidebar.SetParamDefaults(temperature, maxTokens)
+	}
+}
+
+// ensureFullScreenPanel returns the full-screen panel, constructing its
+// terminal emulator the first time an alternate-screen app starts.
+func (m *Model) ensureFullScreenPanel() *fullscreen.FullScreenPanel {
+	if m.fullScreenPanel == nil {
+		width, height := m.width, m.height
+		if width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		m.fullScreenPanel = fullscreen.NewFullScreenPanel(width, height)
+	}
+	return m.fullScreenPanel
+}
+
 // chatHandler returns the dispatcher's /chat handler so the call inherits the
 // installed ApproverFactory. Falls back to a fresh handler (auto-allow
 // approver) if the dispatcher disagrees about the type.
@@ -205,10 +589,12 @@ func (m *Model) installAgentFactories() {
 	continuerFactory := func(out chan<- commands.WtfStreamEvent) commands.Continuer {
 		return commands.NewUIContinuer(out)
 	}
-	if h, ok := m.dispatcher.GetHandler("/explain"); ok {
-		if eh, ok := h.(*commands.ExplainHandler); ok {
-			eh.ApproverFactory = approverFactory
-			eh.ContinuerFactory = continuerFactory
+	for _, focus := range commands.Focuses {
+		if h, ok := m.dispatcher.GetHandler(focus.Command); ok {
+			if eh, ok := h.(*commands.ExplainHandler); ok {
+				eh.ApproverFactory = approverFactory
+				eh.ContinuerFactory = continuerFactory
+			}
 		}
 	}
 	if h, ok := m.dispatcher.GetHandler("/chat"); ok {
@@ -226,11 +612,20 @@ func (m Model) Init() tea.Cmd {
 		tickDirectory(),        // Start directory update ticker
 		resolveGitBranchCmd(m.currentDir, m.gitBranchResolver),
 		fetchUpdateCheckCmd(),
+		m.localModelWarmup,
+		m.providerPrewarm,
+		listenControl(m.controlServer),
+		m.loadShellHistory(),
 	)
 }
 
 // Update handles messages and updates model state (Bubble Tea lifecycle method)
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Record overlays the previous message opened before this one opens
+	// more, so the stack keeps their order.
+	m.syncOverlays()
+	m.noteUserInput(msg)
+
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
@@ -254,9 +649,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.PasteMsg:
 		return m.handlePaste(msg)
 
+	case tea.ClipboardMsg:
+		return m.handleClipboard(msg)
+
+	case primarySelectionMsg:
+		return m.handlePrimarySelection(msg)
+
+	case input.PasteProgressMsg:
+		return m.handlePasteProgress(msg)
+
 	case tea.KeyPressMsg:
 		return m.handleKeyPress(msg)
 
+	case tea.ResumeMsg:
+		return m.handleResume()
+
 	case input.ShowPaletteMsg:
 		return m.handleShowPalette()
 
@@ -269,8 +676,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case palette.PaletteSelectMsg:
 		return m.handlePaletteSelect(msg)
 
+	case chatResumeMsg:
+		return m.handleChatResume(msg)
+
+	case chatPreviewMsg:
+		return m.handleChatPreview(msg)
+
+	case promptpreview.SendMsg:
+		return m.handlePromptPreviewSend(msg)
+
+	case promptpreview.CancelMsg:
+		return m.handlePromptPreviewCancel()
+
 	case palette.PaletteCancelMsg:
-		return m.handlePaletteCancel()
+		return m.handlePaletteCancel(msg)
 
 	case input.ShowHistoryPickerMsg:
 		return m.handleShowHistoryPicker(msg)
@@ -278,9 +697,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case historypicker.HistoryPickerSelectMsg:
 		return m.handleHistoryPickerSelect(msg)
 
+	case historypicker.HistoryPickerCompleteMsg:
+		return m.handleHistoryPickerComplete(msg)
+
+	case historypicker.HistoryPickerPinMsg:
+		return m.handleHistoryPickerPin(msg)
+
 	case historypicker.HistoryPickerCancelMsg:
 		return m.handleHistoryPickerCancel()
 
+	case sidebar.MessageMenuMsg:
+		return m.handleSidebarMessageMenu(msg)
+
 	case sidebar.CommandExecuteMsg:
 		return m.handleSidebarCommandExecute(msg)
 
@@ -302,12 +730,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case input.CtrlDPressedMsg:
 		return m.handleCtrlDPressed()
 
+	case input.ReadOnlyKeyMsg:
+		return m, m.flashStatus(readOnlyNotice)
+
+	case shellHistoryLoadedMsg:
+		return m.handleShellHistoryLoaded(msg)
+
 	case exitConfirmTimeoutMsg:
 		return m.handleExitConfirmTimeout(msg)
 
-	case clearStatusMsgMsg:
-		return m.handleClearStatusMsg()
-
 	case picker.OpenModelPickerMsg:
 		return m.handleOpenModelPicker(msg)
 
@@ -320,11 +751,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case picker.OptionPickerSelectMsg:
 		return m.handleOptionPickerSelect(msg)
 
-	case picker.ModelPickerRefreshMsg:
-		return m.handleModelPickerRefresh(msg)
+	case picker.OptionPickerActionMsg:
+		return m.handleOptionPickerAction(msg)
 
-	case providerModelsRefreshMsg:
-		return m.handleProviderModelsRefresh(msg)
+	case picker.OptionPickerCancelMsg:
+		return m.handleOptionPickerCancel(msg)
+
+	case modelListMsg:
+		return m.handleModelList(msg)
 
 	case streamStartResultMsg:
 		return m.handleStreamStartResult(msg)
@@ -333,6 +767,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Result panel closed
 		return m, nil
 
+	case result.ResultPanelChatMsg:
+		return m.handleResultPanelChat(msg)
+
 	case toolapproval.DecisionMsg:
 		return m.handleToolApprovalDecision(msg)
 
@@ -342,8 +779,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case sidebar.ChatSubmitMsg:
 		return m.handleChatSubmit(msg)
 
+	case sidebar.ChatCommandMsg:
+		return m.handleChatCommand(msg)
+
+	case scrubber.TickMsg:
+		if m.scrubber != nil {
+			return m, m.scrubber.Tick(msg)
+		}
+		return m, nil
+
+	case scrubber.AskMsg:
+		return m.handleReplayAsk(msg)
+
 	case wtfStreamEventMsg:
-		if msg.streamID != m.streamID {
+		if msg.streamID != m.aiJobs.id {
 			return m, nil
 		}
 		return m.handleWtfStreamEvent(msg.event)
@@ -354,23 +803,88 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case streamThrottleFlushMsg:
 		return m.handleStreamThrottleFlush(msg)
 
+	case asyncDoneMsg:
+		return m.handleAsyncDone(msg)
+
+	case progressTickMsg:
+		return m.handleProgressTick()
+
+	case streamActivityTickMsg:
+		return m.handleStreamActivityTick()
+
 	case updateCheckMsg:
 		return m.handleUpdateCheck(msg)
 
 	case ptyOutputMsg:
 		return m.handlePTYOutput(msg)
 
+	case chatTitleMsg:
+		return m.handleChatTitle(msg)
+
 	case ptyBatchFlushMsg:
 		return m.handlePTYBatchFlush()
 
+	case viewportBulkSettleMsg:
+		return m.handleBulkSettle()
+
+	case controlCallMsg:
+		return m.handleControlCall(msg)
+
+	case execExitMsg:
+		return m.handleExecExit(msg)
+
 	case ptyErrorMsg:
 		return m.handlePTYError(msg)
 
+	case jobsMsg:
+		return m.handleJobs(msg)
+
 	case directoryUpdateMsg:
 		return m.handleDirectoryUpdate()
 
+	case logsTickMsg:
+		return m.handleLogsTick(msg)
+
+	case localModelFailedMsg:
+		return m.handleLocalModelFailed(msg)
+
+	case localModelTickMsg:
+		return m.handleLocalModelTick()
+
 	case gitBranchMsg:
 		return m.handleGitBranch(msg)
+
+	case statusExpiredMsg:
+		return m.handleStatusExpired(msg)
+
+	case StatusMessageMsg:
+		return m.handleStatusMessage(msg)
+
+	case scriptEditorClosedMsg:
+		return m.handleScriptEditorClosed(msg)
+
+	case envpicker.EnvPickerSaveMsg:
+		return m.handleEnvPickerSave(msg)
+
+	case watchTickMsg:
+		return m.handleWatchTick(msg)
+
+	case watchResultMsg:
+		return m.handleWatchResult(msg)
+
+	case syntaxCheckDueMsg:
+		return m, m.runShellcheck(msg)
+
+	case syntaxCheckResultMsg:
+		m.applyShellcheck(msg)
+		return m, nil
+
+	case workspaceIndexMsg:
+		m.applyWorkspaceIndex(msg)
+		return m, nil
+
+	case providerSwitchMsg:
+		return m.applyProviderSwitch(msg)
 	}
 
 	return m, nil
@@ -15,19 +15,30 @@ func getCurrentDir() string {
 	return dir
 }
 
-func loadProviderAndModelFromConfig() (string, string) {
+// loadUIConfig reads the config file without creating it, falling back to
+// defaults when it is missing or unreadable. The organization policy is
+// enforced either way.
+func loadUIConfig() config.Config {
 	path := config.GetConfigPath()
 	if path == "" {
-		return getProviderAndModel(config.Default())
+		return defaultUIConfig()
 	}
 	if _, err := os.Stat(path); err != nil {
-		return getProviderAndModel(config.Default())
+		return defaultUIConfig()
 	}
 	cfg, err := config.Load(path)
 	if err != nil {
-		return getProviderAndModel(config.Default())
+		return defaultUIConfig()
 	}
-	return getProviderAndModel(cfg)
+	return cfg
+}
+
+func defaultUIConfig() config.Config {
+	cfg, err := config.ApplyPolicy(config.Default())
+	if err != nil {
+		return config.Default()
+	}
+	return cfg
 }
 
 func getProviderAndModel(cfg config.Config) (string, string) {
@@ -72,3 +83,20 @@ func getModelForProvider(cfg config.Config) string {
 		return config.Default().OpenRouter.Model
 	}
 }
+
+// getSamplingDefaults returns the temperature and max tokens configured for
+// the currently selected provider.
+func getSamplingDefaults(cfg config.Config) (float64, int) {
+	switch cfg.LLMProvider {
+	case "openai":
+		return cfg.Providers.OpenAI.Temperature, cfg.Providers.OpenAI.MaxTokens
+	case "copilot":
+		return cfg.Providers.Copilot.Temperature, cfg.Providers.Copilot.MaxTokens
+	case "anthropic":
+		return cfg.Providers.Anthropic.Temperature, cfg.Providers.Anthropic.MaxTokens
+	case "google":
+		return cfg.Providers.Google.Temperature, cfg.Providers.Google.MaxTokens
+	default: // openrouter or unknown
+		return cfg.OpenRouter.Temperature, cfg.OpenRouter.MaxTokens
+	}
+}
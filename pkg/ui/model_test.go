@@ -2,6 +2,7 @@ package ui
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -15,7 +16,9 @@ import (
 	"wtf_cli/pkg/capture"
 	"wtf_cli/pkg/commands"
 	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/pty"
 	"wtf_cli/pkg/ui/components/historypicker"
+	"wtf_cli/pkg/ui/components/layout"
 	"wtf_cli/pkg/ui/components/palette"
 	"wtf_cli/pkg/ui/components/picker"
 	"wtf_cli/pkg/ui/components/settings"
@@ -112,8 +115,8 @@ func TestModel_MouseDragCopiesViewportSelection(t *testing.T) {
 	if cmd == nil {
 		t.Fatal("expected copy command on mouse release")
 	}
-	if got := m.statusBar.GetMessage(); got != selectedTextCopiedMessage {
-		t.Fatalf("expected status message %q, got %q", selectedTextCopiedMessage, got)
+	if got := m.statusBar.GetMessage(); got != selectedOutputCopiedMessage {
+		t.Fatalf("expected status message %q, got %q", selectedOutputCopiedMessage, got)
 	}
 	if m.viewport.HasActiveSelection() || m.viewport.HasSelection() {
 		t.Fatal("expected release to clear viewport selection")
@@ -138,7 +141,7 @@ func TestModel_RightClickDoesNotStartSelection(t *testing.T) {
 func TestModel_MouseClickTerminalPaneFocusesTerminal(t *testing.T) {
 	m := newMouseFocusModel(t)
 	m.setTerminalFocused(false)
-	m.sidebar.StartSelection(0, 0)
+	m.ensureSidebar().StartSelection(0, 0)
 
 	newModel, cmd := m.Update(tea.MouseClickMsg(tea.Mouse{X: 1, Y: 0, Button: tea.MouseLeft}))
 	if cmd != nil {
@@ -164,7 +167,7 @@ func TestModel_MouseClickSidebarMessageFocusesInputAndStartsSelection(t *testing
 	m := newMouseFocusModel(t)
 	m.setTerminalFocused(true)
 	m.viewport.StartSelection(0, 0)
-	left, _ := splitSidebarWidths(m.width)
+	left, _ := layout.SplitWidths(m.width)
 
 	newModel, cmd := m.Update(tea.MouseClickMsg(tea.Mouse{X: left + 3, Y: 3, Button: tea.MouseLeft}))
 	if cmd != nil {
@@ -192,7 +195,7 @@ func TestModel_MouseClickSidebarMessageFocusesInputAndStartsSelection(t *testing
 func TestModel_MouseClickSidebarChromeFocusesInputWithoutSelection(t *testing.T) {
 	m := newMouseFocusModel(t)
 	m.setTerminalFocused(true)
-	left, _ := splitSidebarWidths(m.width)
+	left, _ := layout.SplitWidths(m.width)
 
 	newModel, cmd := m.Update(tea.MouseClickMsg(tea.Mouse{X: left, Y: 0, Button: tea.MouseLeft}))
 	if cmd != nil {
@@ -269,7 +272,7 @@ func newMouseFocusModel(t *testing.T) Model {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
 	m = newModel.(Model)
-	m.sidebar.SetContent("alpha\nbravo\ncharlie")
+	m.ensureSidebar().SetContent("alpha\nbravo\ncharlie")
 	m.sidebar.Show()
 	m.applyLayout()
 	m.viewport.Clear()
@@ -281,16 +284,16 @@ func newMouseFocusModel(t *testing.T) Model {
 
 func TestModel_ClearSelectionStatusOnlyClearsOwnedMessage(t *testing.T) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
-	m.statusBar.SetMessage(selectedTextCopiedMessage)
+	m.copySelectedText("copied")
 
-	newModel, _ := m.Update(clearStatusMsgMsg{})
+	newModel, _ := m.Update(statusExpiredMsg{source: statusSourceSelection, text: selectedTextCopiedMessage})
 	m = newModel.(Model)
 	if got := m.statusBar.GetMessage(); got != "" {
 		t.Fatalf("expected selection status to clear, got %q", got)
 	}
 
-	m.statusBar.SetMessage("Other message")
-	newModel, _ = m.Update(clearStatusMsgMsg{})
+	m.flashStatus("Other message")
+	newModel, _ = m.Update(statusExpiredMsg{source: statusSourceSelection, text: selectedTextCopiedMessage})
 	m = newModel.(Model)
 	if got := m.statusBar.GetMessage(); got != "Other message" {
 		t.Fatalf("expected unrelated status to remain, got %q", got)
@@ -625,7 +628,7 @@ func TestModel_ApplyLayout_ResizesPTYOnSidebarToggle(t *testing.T) {
 
 	newModel, _ = m.Update(input.ToggleChatMsg{})
 	m = newModel.(Model)
-	left, _ := splitSidebarWidths(120)
+	left, _ := layout.SplitWidths(120)
 	assertPTYSize(t, ptm, left, 29)
 
 	newModel, _ = m.Update(input.ToggleChatMsg{})
@@ -691,7 +694,7 @@ func TestModel_BuildExplainUserMessage(t *testing.T) {
 	m := NewModel(nil, buf, sess, nil)
 	ctx := commands.NewContext(buf, sess, "/tmp")
 
-	got := m.buildExplainUserMessage(ctx)
+	got := m.buildExplainUserMessage(ctx, commands.FocusExplain)
 	expected := "[Asked to explain last 3 lines from terminal. Last command: `git status`]"
 	if got != expected {
 		t.Errorf("Expected %q, got %q", expected, got)
@@ -704,13 +707,28 @@ func TestModel_BuildExplainUserMessage_NoCommand(t *testing.T) {
 	m := NewModel(nil, buf, sess, nil)
 	ctx := commands.NewContext(buf, sess, "/tmp")
 
-	got := m.buildExplainUserMessage(ctx)
+	got := m.buildExplainUserMessage(ctx, commands.FocusExplain)
 	expected := "[Asked to explain last 0 lines from terminal. Last command: `N/A`]"
 	if got != expected {
 		t.Errorf("Expected %q, got %q", expected, got)
 	}
 }
 
+func TestModel_BuildExplainUserMessage_Wtf(t *testing.T) {
+	buf := buffer.New(100)
+	sess := capture.NewSessionContext()
+	sess.AddCommand(capture.CommandRecord{Command: "make", BufferStart: buf.Total()})
+	buf.Write([]byte("main.c:3: error: expected ';'"))
+	sess.FinishLastCommand(buf.Total(), 2, nil)
+
+	m := NewModel(nil, buf, sess, nil)
+	got := m.buildExplainUserMessage(commands.NewContext(buf, sess, "/tmp"), commands.FocusWtf)
+	expected := "[Asked why `make` failed with exit code 2, from 1 lines of its output]"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
 func TestModel_ExplainAddsUserPrompt(t *testing.T) {
 	buf := buffer.New(100)
 	buf.Write([]byte("line one"))
@@ -720,6 +738,7 @@ func TestModel_ExplainAddsUserPrompt(t *testing.T) {
 	sess.AddCommand(capture.CommandRecord{Command: "ls -la"})
 
 	m := NewModel(nil, buf, sess, nil)
+	trustWorkspace(t, &m, ai.TrustTrusted)
 	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
 	m = newModel.(Model)
 
@@ -802,6 +821,13 @@ func TestModel_HistoryPickerFlow_FromCommand(t *testing.T) {
 		t.Error("Expected history picker mode to be active")
 	}
 
+	// First Enter opens the edit stage, second Enter injects the command
+	newModel, _ = m.Update(testutils.TestKeyEnter)
+	m = newModel.(Model)
+	if !m.historyPicker.IsEditing() {
+		t.Fatal("Expected history picker edit stage after Enter")
+	}
+
 	newModel, selectCmd := m.Update(testutils.TestKeyEnter)
 	if selectCmd == nil {
 		t.Fatal("Expected selection command after Enter")
@@ -850,6 +876,14 @@ func TestModel_HistoryPickerFlow_FromCommand(t *testing.T) {
 	}
 }
 
+func TestModel_HistoryPickerCompleteWithoutInputHandler(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.inputHandler = nil
+	if _, cmd := m.Update(historypicker.HistoryPickerCompleteMsg{Command: "ls"}); cmd != nil {
+		t.Fatal("Expected nothing to happen without an input handler")
+	}
+}
+
 func TestModel_CommandExecuteMsg_AppliesCommandToPTY(t *testing.T) {
 	tmpDir := t.TempDir()
 	ptyFile, err := os.CreateTemp(tmpDir, "pty")
@@ -1130,7 +1164,7 @@ func TestModel_SecretMode_KeyBypassesPaletteRouting(t *testing.T) {
 	defer ptyFile.Close()
 
 	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
-	m.secretDetector = func(*os.File) bool { return true }
+	m.secretDetector = func(*os.File, string) bool { return true }
 	m.palette.Show()
 
 	newModel, cmd := m.Update(testutils.NewTextKeyPressMsg("/"))
@@ -1160,7 +1194,7 @@ func TestModel_SecretMode_PasteBypassesPaletteRouting(t *testing.T) {
 	defer ptyFile.Close()
 
 	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
-	m.secretDetector = func(*os.File) bool { return true }
+	m.secretDetector = func(*os.File, string) bool { return true }
 	m.palette.Show()
 
 	pasteContent := "secret-paste"
@@ -1182,6 +1216,37 @@ func TestModel_SecretMode_PasteBypassesPaletteRouting(t *testing.T) {
 	}
 }
 
+func TestModel_SecretPromptDropsTypeAhead(t *testing.T) {
+	ptyFile, err := os.CreateTemp(t.TempDir(), "pty")
+	if err != nil {
+		t.Fatalf("Failed to create temp PTY file: %v", err)
+	}
+	defer ptyFile.Close()
+
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	var seenPrompt string
+	m.secretDetector = func(_ *os.File, promptLine string) bool {
+		seenPrompt = promptLine
+		return pty.IsSecretPrompt(promptLine)
+	}
+	m.inputHandler.SetLineBuffer("typed-ahead")
+
+	newModel, _ := m.Update(ptyOutputMsg{data: []byte("ssh host\r\n\x1b[1mEnter passphrase for key 'id_ed25519':\x1b[0m ")})
+	m = newModel.(Model)
+	newModel, _ = m.Update(ptyBatchFlushMsg{})
+	m = newModel.(Model)
+
+	if seenPrompt != "Enter passphrase for key 'id_ed25519': " {
+		t.Fatalf("Expected the detector to get the plain prompt line, got %q", seenPrompt)
+	}
+	if !m.inputHandler.IsSecretMode() {
+		t.Fatal("Expected secret mode once the passphrase prompt is printed")
+	}
+	if got := m.inputHandler.LineBuffer(); got != "" {
+		t.Fatalf("Expected type-ahead dropped on entering secret mode, got %q", got)
+	}
+}
+
 func TestModel_SecretModeFalse_CommandCaptureStillWorks(t *testing.T) {
 	tmpDir := t.TempDir()
 	ptyFile, err := os.CreateTemp(tmpDir, "pty")
@@ -1191,7 +1256,7 @@ func TestModel_SecretModeFalse_CommandCaptureStillWorks(t *testing.T) {
 	defer ptyFile.Close()
 
 	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
-	m.secretDetector = func(*os.File) bool { return false }
+	m.secretDetector = func(*os.File, string) bool { return false }
 
 	step := func(msg tea.Msg) {
 		newModel, cmd := m.Update(msg)
@@ -1236,7 +1301,7 @@ func TestModel_Update_StartCopilotAuthMsg(t *testing.T) {
 func TestModel_Update_CopilotAuthStatusMsg_ShowsPrompt(t *testing.T) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 
-	m.settingsPanel.Show(config.Default(), config.GetConfigPath())
+	m.ensureSettingsPanel().Show(config.Default(), config.GetConfigPath())
 
 	msg := copilotAuthStatusMsg{
 		Status: ai.CopilotAuthStatus{
@@ -1268,7 +1333,7 @@ func TestModel_Update_CopilotAuthStatusMsg_ShowsPrompt(t *testing.T) {
 func TestModel_Update_CopilotAuthStatusMsg_Error(t *testing.T) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 
-	m.settingsPanel.Show(config.Default(), config.GetConfigPath())
+	m.ensureSettingsPanel().Show(config.Default(), config.GetConfigPath())
 
 	testErr := fmt.Errorf("test auth error")
 	msg := copilotAuthStatusMsg{Err: testErr, ShowPrompt: true}
@@ -1284,7 +1349,7 @@ func TestModel_Update_CopilotAuthStatusMsg_Error(t *testing.T) {
 func TestModel_Update_CopilotAuthStatusMsg_PreservesSettingsPanelEdits(t *testing.T) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 
-	m.settingsPanel.Show(config.Default(), config.GetConfigPath())
+	m.ensureSettingsPanel().Show(config.Default(), config.GetConfigPath())
 	m.settingsPanel.SetProviderValue("copilot")
 	if !m.settingsPanel.HasChanges() {
 		t.Fatal("Expected settings panel to have changes")
@@ -1313,7 +1378,7 @@ func TestModel_Update_SettingsSaveMsg_UpdatesSidebarLLMLabel(t *testing.T) {
 	newModel, _ := m.Update(settings.SettingsSaveMsg{ConfigPath: cfgPath, Config: cfg})
 	m = newModel.(Model)
 
-	if got := m.sidebar.ActiveLLMLabel(); got != "LLM: openai-gpt-4.1-mini" {
+	if got := m.ensureSidebar().ActiveLLMLabel(); got != "LLM: openai-gpt-4.1-mini" {
 		t.Fatalf("Expected updated sidebar LLM label, got %q", got)
 	}
 }
@@ -1486,11 +1551,13 @@ func TestModel_FocusSwitch_TerminalFocusedRoutesKeysToPTY(t *testing.T) {
 func TestModel_Update_OpenModelPickerMsg_GoogleStartsFetchWhenAPIKeyPresent(t *testing.T) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 
+	cfg := config.Default()
+	cfg.Providers.Google.APIKey = "test-google-key"
 	newModel, cmd := m.Update(picker.OpenModelPickerMsg{
 		Options:  ai.GetProviderModels("google"),
 		Current:  "gemini-2.5-flash",
 		FieldKey: "google_model",
-		APIKey:   "test-google-key",
+		Source:   ai.GetModelSource(ai.ProviderGoogle, cfg),
 	})
 	m = newModel.(Model)
 
@@ -1509,6 +1576,7 @@ func TestModel_Update_OpenModelPickerMsg_GoogleSkipsFetchWithoutAPIKey(t *testin
 		Options:  ai.GetProviderModels("google"),
 		Current:  "gemini-2.5-flash",
 		FieldKey: "google_model",
+		Source:   ai.GetModelSource(ai.ProviderGoogle, config.Default()),
 	})
 	m = newModel.(Model)
 
@@ -1520,14 +1588,37 @@ func TestModel_Update_OpenModelPickerMsg_GoogleSkipsFetchWithoutAPIKey(t *testin
 	}
 }
 
+func TestModel_Update_ModelListMsg(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = newModel.(Model)
+	m.ensureModelPicker().Show(ai.GetProviderModels("anthropic"), "", "anthropic_model")
+
+	rejected := &ai.ModelListError{Provider: ai.ProviderAnthropic, Kind: ai.ModelListRejected, Err: errors.New("models request failed (401)")}
+	newModel, cmd := m.Update(modelListMsg{FieldKey: "anthropic_model", Provider: ai.ProviderAnthropic, Err: rejected})
+	m = newModel.(Model)
+	if cmd == nil || !strings.Contains(m.statusBar.Render(), "rejected the API key") {
+		t.Fatalf("Expected a rejected key to be reported, got %q", m.statusBar.Render())
+	}
+	if !strings.Contains(m.modelPicker.View(), "Claude 3.5 Sonnet") {
+		t.Fatal("Expected the built-in models to stay after an error")
+	}
+
+	newModel, _ = m.Update(modelListMsg{FieldKey: "anthropic_model", Provider: ai.ProviderAnthropic, Models: []ai.ModelInfo{{ID: "claude-fetched", Name: "claude-fetched"}}})
+	m = newModel.(Model)
+	if !strings.Contains(m.modelPicker.View(), "claude-fetched") {
+		t.Fatalf("Expected the listed models in the picker, got:\n%s", m.modelPicker.View())
+	}
+}
+
 func TestModel_Update_ModelPickerSelectMsg_GoogleUpdatesSettings(t *testing.T) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 
 	cfg := config.Default()
 	cfg.LLMProvider = "google"
 	cfg.Providers.Google.Model = "gemini-2.5-flash"
-	m.settingsPanel.Show(cfg, "/tmp/test_config.json")
-	m.modelPicker.Show(ai.GetProviderModels("google"), cfg.Providers.Google.Model, "google_model")
+	m.ensureSettingsPanel().Show(cfg, "/tmp/test_config.json")
+	m.ensureModelPicker().Show(ai.GetProviderModels("google"), cfg.Providers.Google.Model, "google_model")
 
 	newModel, cmd := m.Update(picker.ModelPickerSelectMsg{
 		ModelID:  "gemini-2.5-pro",
@@ -1716,7 +1807,7 @@ func TestModel_SidebarArrowKeysScrollRenderedChat(t *testing.T) {
 	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 14})
 	m = newModel.(Model)
 	m.showSidebar("test")
-	m.sidebar.StartAssistantMessageWithContent(strings.Join([]string{
+	m.ensureSidebar().StartAssistantMessageWithContent(strings.Join([]string{
 		"Use <cmd>git status</cmd>",
 		"line 1",
 		"line 2",
@@ -1760,7 +1851,7 @@ func TestModel_MouseWheelOverSidebarFocusesAndScrollsSidebar(t *testing.T) {
 	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 14})
 	m = newModel.(Model)
 	m.showSidebar("test")
-	m.sidebar.StartAssistantMessageWithContent(strings.Join([]string{
+	m.ensureSidebar().StartAssistantMessageWithContent(strings.Join([]string{
 		"Use <cmd>git status</cmd>",
 		"line 1",
 		"line 2",
@@ -1780,7 +1871,7 @@ func TestModel_MouseWheelOverSidebarFocusesAndScrollsSidebar(t *testing.T) {
 		t.Fatal("precondition: expected terminal focus")
 	}
 
-	left, _ := splitSidebarWidths(m.width)
+	left, _ := layout.SplitWidths(m.width)
 	beforeView := m.sidebar.View()
 	newModel, _ = m.Update(tea.MouseWheelMsg(tea.Mouse{
 		X:      left + 1,
@@ -1866,3 +1957,93 @@ func TestModel_Typing_ExitsScrollMode(t *testing.T) {
 		t.Error("expected scrollMode=false after typing a character")
 	}
 }
+
+func TestNewModel_DefersRarelyUsedOverlays(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	if m.modelPicker != nil || m.optionPicker != nil || m.fullScreenPanel != nil || m.settingsPanel != nil || m.sidebar != nil {
+		t.Fatal("expected pickers, panels and the sidebar to be constructed lazily")
+	}
+
+	m.width, m.height = 100, 30
+	m.enterFullScreen(0)
+	if m.fullScreenPanel == nil || !m.fullScreenPanel.IsVisible() {
+		t.Fatal("expected full-screen panel to be created and shown on enter")
+	}
+
+	updated, _ := m.Update(picker.OpenOptionPickerMsg{
+		Title:    "LLM Provider",
+		FieldKey: "llm_provider",
+		Options:  []string{"openrouter", "openai"},
+		Current:  "openrouter",
+	})
+	m = updated.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected option picker to be created and shown on open")
+	}
+}
+
+func TestNewModel_BuildsSidebarAndSettingsOnFirstOpen(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.setActiveLLM("openai", "gpt-4.1-mini")
+	m.currentDir = "/srv/app"
+
+	newModel, _ := m.Update(input.ToggleChatMsg{})
+	m = newModel.(Model)
+	if m.sidebar == nil || !m.sidebar.IsVisible() {
+		t.Fatal("expected Ctrl+T to build and show the sidebar")
+	}
+	if got := m.sidebar.ActiveLLMLabel(); got != "LLM: openai-gpt-4.1-mini" {
+		t.Fatalf("expected the sidebar to pick up the active LLM, got %q", got)
+	}
+
+	newModel, _ = m.Update(palette.PaletteSelectMsg{Command: "/settings"})
+	m = newModel.(Model)
+	if m.settingsPanel == nil || !m.settingsPanel.IsVisible() {
+		t.Fatal("expected /settings to build and show the settings panel")
+	}
+}
+
+func TestModel_PaletteEscTypesTextAtPrompt(t *testing.T) {
+	ptyFile, err := os.CreateTemp(t.TempDir(), "pty")
+	if err != nil {
+		t.Fatalf("Failed to create temp PTY file: %v", err)
+	}
+	defer ptyFile.Close()
+
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	m.inputHandler = input.NewInputHandler(ptyFile)
+	m.secretDetector = func(*os.File, string) bool { return false }
+
+	keys := []tea.KeyPressMsg{testutils.NewTextKeyPressMsg("/")}
+	for _, r := range "tmp" {
+		keys = append(keys, testutils.NewTextKeyPressMsg(string(r)))
+	}
+	keys = append(keys, testutils.TestKeyEsc)
+	for _, key := range keys {
+		newModel, cmd := m.Update(key)
+		m = newModel.(Model)
+		for cmd != nil {
+			newModel, cmd = m.Update(cmd())
+			m = newModel.(Model)
+		}
+	}
+
+	if m.palette.IsVisible() || m.inputHandler.IsPaletteMode() {
+		t.Fatal("Expected palette to be closed")
+	}
+	if _, err := ptyFile.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek PTY file: %v", err)
+	}
+	data, err := io.ReadAll(ptyFile)
+	if err != nil {
+		t.Fatalf("Failed to read PTY output: %v", err)
+	}
+	if string(data) != "/tmp" {
+		t.Fatalf("Expected PTY output %q, got %q", "/tmp", string(data))
+	}
+	if got := m.inputHandler.LineBuffer(); got != "/tmp" {
+		t.Fatalf("Expected line buffer %q, got %q", "/tmp", got)
+	}
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/notify"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	notificationToastDuration = 4 * time.Second
+	notificationMaxDetail     = 80
+)
+
+// pendingCommand tracks a submitted shell command until the shell regains
+// the PTY foreground, so long-running commands can raise command_finished.
+type pendingCommand struct {
+	command   string
+	start     time.Time
+	shellPGID int
+}
+
+// notify routes ev to the enabled sinks. Status bar toasts and the terminal
+// bell are applied on the UI goroutine; background sinks (desktop, webhook)
+// are delivered from a tea.Cmd so a slow endpoint never blocks rendering.
+func (m *Model) notify(ev notify.Event) tea.Cmd {
+	if m.notifier == nil {
+		return nil
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	slog.Debug("notify_event", "event", ev.Kind, "title", ev.Title)
+
+	var cmds []tea.Cmd
+	if m.notifier.Wants(notify.SinkStatusBar, ev.Kind) {
+		text := ev.Title
+		if ev.Message != "" {
+			text += ": " + ev.Message
+		}
+		cmds = append(cmds, m.flashStatus(text))
+	}
+	if m.notifier.Wants(notify.SinkBell, ev.Kind) {
+		cmds = append(cmds, tea.Raw("\a"))
+	}
+	if m.notifier.HasBackgroundSinks(ev.Kind) {
+		notifier := m.notifier
+		cmds = append(cmds, func() tea.Msg {
+			notifier.Deliver(ev)
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// flashStatus shows text in the status bar for the toast duration, in
+// place of the previous toast. Messages of higher priority, such as the
+// exit confirmation, stay on top of it.
+func (m *Model) flashStatus(text string) tea.Cmd {
+	return m.postStatus(statusbar.Message{Source: statusSourceFlash, Text: text, Priority: statusbar.PriorityNormal}, notificationToastDuration)
+}
+
+// notifyStreamError raises rate_limited for quota rejections and
+// stream_error for everything else.
+func (m *Model) notifyStreamError(err error) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+	if notify.IsRateLimitError(err) {
+		return m.notify(notify.Event{
+			Kind:    config.NotifyEventRateLimited,
+			Title:   "Rate limit hit",
+			Message: truncateNotificationDetail(err.Error()),
+		})
+	}
+	return m.notify(notify.Event{
+		Kind:    config.NotifyEventStreamError,
+		Title:   "AI request failed",
+		Message: truncateNotificationDetail(err.Error()),
+	})
+}
+
+func (m *Model) notifyStreamFinished() tea.Cmd {
+	return m.notify(notify.Event{
+		Kind:  config.NotifyEventStreamFinished,
+		Title: "AI response ready",
+	})
+}
+
+// trackSubmittedCommand starts timing cmd. The shell owns the PTY foreground
+// at submit time, so its process group is the marker for "back at prompt".
+func (m *Model) trackSubmittedCommand(cmd string) {
+	if m.notifier.LongCommandThreshold() <= 0 || m.foregroundPGID == nil || m.ptyFile == nil {
+		return
+	}
+	shellPGID := m.foregroundPGID(m.ptyFile)
+	if shellPGID <= 0 {
+		m.pendingCommand = nil
+		return
+	}
+	m.pendingCommand = &pendingCommand{
+		command:   cmd,
+		start:     time.Now(),
+		shellPGID: shellPGID,
+	}
+}
+
+// checkPendingCommand runs on the directory tick and raises command_finished
+// once the shell is back in the foreground after a long-running command.
+func (m *Model) checkPendingCommand() tea.Cmd {
+	pending := m.pendingCommand
+	if pending == nil || m.foregroundPGID == nil {
+		return nil
+	}
+	pgid := m.foregroundPGID(m.ptyFile)
+	if pgid > 0 && pgid != pending.shellPGID {
+		return nil // still running
+	}
+	m.pendingCommand = nil
+	elapsed := time.Since(pending.start)
+	if pgid <= 0 || elapsed < m.notifier.LongCommandThreshold() {
+		return nil
+	}
+	return m.notify(notify.Event{
+		Kind:    config.NotifyEventCommandFinished,
+		Title:   fmt.Sprintf("Command finished (%s)", elapsed.Round(time.Second)),
+		Message: truncateNotificationDetail(pending.command),
+	})
+}
+
+func truncateNotificationDetail(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= notificationMaxDetail {
+		return s
+	}
+	return string(runes[:notificationMaxDetail-1]) + "…"
+}
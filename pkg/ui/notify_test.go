@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/notify"
+)
+
+func newNotifyTestModel(cfg config.NotificationsConfig) Model {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.notifier = notify.New(cfg)
+	return m
+}
+
+func TestModel_StreamDoneShowsStatusBarToast(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{
+		StatusBar: config.NotificationSinkConfig{Enabled: true},
+	})
+	m.ensureSidebar().Show()
+	m.sidebar.StartAssistantMessageWithContent("answer")
+
+	m, cmd := m.handleWtfStreamEvent(commands.WtfStreamEvent{Done: true})
+	if cmd == nil {
+		t.Fatal("expected a toast expiry command")
+	}
+	if got := m.statusBar.GetMessage(); got != "AI response ready" {
+		t.Fatalf("status message = %q, want %q", got, "AI response ready")
+	}
+
+	m, _ = m.handleStatusExpired(statusExpiredMsg{source: statusSourceFlash, text: "AI response ready"})
+	if got := m.statusBar.GetMessage(); got != "" {
+		t.Fatalf("expected toast to be cleared, got %q", got)
+	}
+}
+
+func TestModel_ToastExpiryKeepsNewerMessage(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{})
+	m.flashStatus("Saved")
+
+	m, _ = m.handleStatusExpired(statusExpiredMsg{source: statusSourceFlash, text: "AI response ready"})
+	if got := m.statusBar.GetMessage(); got != "Saved" {
+		t.Fatalf("expected newer toast to survive, got %q", got)
+	}
+
+	m, _ = m.handleCtrlDPressed()
+	m, _ = m.handleStatusExpired(statusExpiredMsg{source: statusSourceFlash, text: "Saved"})
+	if got := m.statusBar.GetMessage(); got != "Press Ctrl+D again to exit" {
+		t.Fatalf("expected unrelated message to survive, got %q", got)
+	}
+}
+
+func TestModel_StreamErrorRoutesRateLimit(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{
+		StatusBar: config.NotificationSinkConfig{
+			Enabled: true,
+			Events:  []string{config.NotifyEventRateLimited},
+		},
+	})
+	m.ensureSidebar().Show()
+
+	m, _ = m.handleWtfStreamEvent(commands.WtfStreamEvent{Err: errors.New("429 Too Many Requests")})
+	if got := m.statusBar.GetMessage(); !strings.HasPrefix(got, "Rate limit hit") {
+		t.Fatalf("expected rate limit toast, got %q", got)
+	}
+
+	m.clearStatus(statusSourceFlash)
+	m, _ = m.handleWtfStreamEvent(commands.WtfStreamEvent{Err: errors.New("connection refused")})
+	if got := m.statusBar.GetMessage(); got != "" {
+		t.Fatalf("stream_error is filtered out for the status bar, got %q", got)
+	}
+}
+
+func TestModel_DisabledNotificationsEmitNothing(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{})
+	m.ensureSidebar().Show()
+
+	m, cmd := m.handleWtfStreamEvent(commands.WtfStreamEvent{Done: true})
+	if cmd != nil {
+		t.Fatal("expected no command with all sinks disabled")
+	}
+	if got := m.statusBar.GetMessage(); got != "" {
+		t.Fatalf("expected no toast, got %q", got)
+	}
+}
+
+func TestModel_LongCommandFinishedNotifies(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{
+		LongCommandSeconds: 5,
+		StatusBar:          config.NotificationSinkConfig{Enabled: true},
+	})
+	m.ptyFile = os.Stdin // any non-nil file; foregroundPGID is stubbed
+	pgid := 100
+	m.foregroundPGID = func(*os.File) int { return pgid }
+
+	m.trackSubmittedCommand("make build")
+	if m.pendingCommand == nil {
+		t.Fatal("expected submitted command to be tracked")
+	}
+
+	pgid = 200 // command running in its own job
+	if cmd := m.checkPendingCommand(); cmd != nil || m.pendingCommand == nil {
+		t.Fatal("expected command to still be pending while its job is in the foreground")
+	}
+
+	pgid = 100 // shell back at prompt
+	m.pendingCommand.start = time.Now().Add(-time.Minute)
+	if cmd := m.checkPendingCommand(); cmd == nil {
+		t.Fatal("expected a notification command")
+	}
+	if m.pendingCommand != nil {
+		t.Fatal("expected pending command to be cleared")
+	}
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, "make build") {
+		t.Fatalf("expected toast to mention the command, got %q", got)
+	}
+}
+
+func TestModel_ShortCommandDoesNotNotify(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{
+		LongCommandSeconds: 5,
+		StatusBar:          config.NotificationSinkConfig{Enabled: true},
+	})
+	m.ptyFile = os.Stdin
+	m.foregroundPGID = func(*os.File) int { return 100 }
+
+	m.trackSubmittedCommand("ls")
+	if cmd := m.checkPendingCommand(); cmd != nil {
+		t.Fatal("expected no notification for a quick command")
+	}
+	if m.pendingCommand != nil {
+		t.Fatal("expected pending command to be cleared")
+	}
+}
@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"wtf_cli/pkg/observe"
+	"wtf_cli/pkg/ui/components/layout"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/ui/components/viewport"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// SetObserveHub mirrors this session's output and chat to hub.
+func (m *Model) SetObserveHub(hub *observe.Hub) {
+	m.observeHub = hub
+}
+
+// refreshSidebar re-renders the chat and mirrors it to observers.
+func (m *Model) refreshSidebar() {
+	if m.sidebar == nil {
+		return
+	}
+	// A closed sidebar is laid out again when shown; answers streaming in
+	// the background only go to the conversation.
+	if m.sidebar.IsVisible() {
+		m.sidebar.RefreshView()
+	}
+	m.publishChat()
+}
+
+// publishChat sends the current conversation to observers.
+func (m *Model) publishChat() {
+	if m.observeHub == nil || m.sidebar == nil {
+		return
+	}
+	m.observeHub.PublishChat(m.sidebar.GetMessages(), m.sidebar.Title())
+}
+
+type observeEventMsg struct {
+	event observe.Event
+}
+
+type observeClosedMsg struct {
+	err error
+}
+
+// ObserverModel is the read-only TUI behind `wtf_cli observe`. It renders a
+// shared session's terminal and chat; no key ever reaches the shell.
+type ObserverModel struct {
+	conn     *observe.Conn
+	viewport viewport.PTYViewport
+	sidebar  *sidebar.Sidebar
+	width    int
+	height   int
+	closed   error
+}
+
+// NewObserverModel creates an observer for an established connection.
+func NewObserverModel(conn *observe.Conn) ObserverModel {
+	vp := viewport.NewPTYViewport()
+	vp.SetCursorVisible(false)
+	sb := sidebar.NewSidebar()
+	sb.SetReadOnly()
+	return ObserverModel{
+		conn:     conn,
+		viewport: vp,
+		sidebar:  sb,
+	}
+}
+
+// Init starts reading session events.
+func (m ObserverModel) Init() tea.Cmd {
+	return m.listen()
+}
+
+func (m ObserverModel) listen() tea.Cmd {
+	conn := m.conn
+	return func() tea.Msg {
+		ev, err := conn.Next()
+		if err != nil {
+			return observeClosedMsg{err: err}
+		}
+		return observeEventMsg{event: ev}
+	}
+}
+
+// Update handles session events and the few local keys.
+func (m ObserverModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		return m, nil
+
+	case observeEventMsg:
+		switch msg.event.Type {
+		case observe.EventOutput:
+			m.viewport.AppendOutput(msg.event.Output)
+		case observe.EventChat:
+			m.sidebar.SetMessages(msg.event.Messages)
+			m.sidebar.SetTitle(msg.event.Title)
+			if len(msg.event.Messages) > 0 && !m.sidebar.IsVisible() {
+				m.sidebar.Show()
+				m.layout()
+			}
+			m.sidebar.RefreshView()
+		}
+		return m, m.listen()
+
+	case observeClosedMsg:
+		m.closed = msg.err
+		return m, nil
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "pgup":
+			m.viewport.SetAutoScroll(false)
+			m.viewport.PageUp()
+		case "pgdown":
+			m.viewport.PageDown()
+			if m.viewport.IsAtBottom() {
+				m.viewport.SetAutoScroll(true)
+			}
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m *ObserverModel) layout() {
+	if m.width <= 0 || m.height <= 0 {
+		return
+	}
+	bodyHeight := max(m.height-1, 1)
+	terminalWidth := m.width
+	if m.sidebar.IsVisible() {
+		var sidebarWidth int
+		terminalWidth, sidebarWidth = layout.SplitWidths(m.width)
+		m.sidebar.SetSize(sidebarWidth, bodyHeight)
+	}
+	m.viewport.SetSize(terminalWidth, bodyHeight)
+}
+
+// View renders the mirrored terminal, the chat when there is one, and a
+// status line.
+func (m ObserverModel) View() tea.View {
+	var v tea.View
+	v.AltScreen = true
+	if m.width <= 0 || m.height <= 0 {
+		v.SetContent("Connecting...")
+		return v
+	}
+
+	body := lipgloss.NewStyle().
+		Width(m.viewport.Viewport.Width()).
+		Height(m.viewport.Viewport.Height()).
+		Render(m.viewport.View())
+	if m.sidebar.IsVisible() {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, body, m.sidebar.View())
+	}
+
+	status := "Observing (read-only) | PgUp/PgDn Scroll | q Quit"
+	if m.closed != nil {
+		status = "Session ended | q Quit"
+	}
+	v.SetContent(lipgloss.JoinVertical(lipgloss.Left, body, statusLine(status, m.width)))
+	return v
+}
+
+func statusLine(text string, width int) string {
+	return lipgloss.NewStyle().
+		Width(width).
+		MaxWidth(width).
+		Reverse(true).
+		Render(text)
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/observe"
+	"wtf_cli/pkg/ui/components/testutils"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestObserverModel_MirrorsEventsAndIgnoresInput(t *testing.T) {
+	m := NewObserverModel(nil)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m = updated.(ObserverModel)
+
+	updated, _ = m.Update(observeEventMsg{event: observe.Event{Type: observe.EventOutput, Output: []byte("$ make\r\nerror: boom\r\n")}})
+	m = updated.(ObserverModel)
+	updated, _ = m.Update(observeEventMsg{event: observe.Event{
+		Type:     observe.EventChat,
+		Messages: []ai.ChatMessage{{Role: "user", Content: "why"}, {Role: "assistant", Content: "missing header"}},
+		Title:    "Build Failure",
+	}})
+	m = updated.(ObserverModel)
+
+	view := ansi.Strip(m.View().Content)
+	for _, want := range []string{"error: boom", "Build Failure", "missing header", "read-only"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("Expected %q in observer view, got:\n%s", want, view)
+		}
+	}
+
+	if _, cmd := m.Update(testutils.NewTextKeyPressMsg("l")); cmd != nil {
+		t.Fatal("Expected typing to do nothing in observer mode")
+	}
+	if _, cmd := m.Update(testutils.NewTextKeyPressMsg("q")); cmd == nil {
+		t.Fatal("Expected q to quit")
+	}
+
+	updated, _ = m.Update(observeClosedMsg{err: os.ErrClosed})
+	m = updated.(ObserverModel)
+	if !strings.Contains(m.View().Content, "Session ended") {
+		t.Fatal("Expected session end to be shown")
+	}
+}
+
+func TestModel_PublishesToObservers(t *testing.T) {
+	dir, err := os.MkdirTemp("", "wtfobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hub, err := observe.Listen(filepath.Join(dir, "s.sock"))
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer hub.Close()
+
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.SetObserveHub(hub)
+	m.ptyBatchBuffer = append(m.ptyBatchBuffer, "hello observer\r\n"...)
+	m.flushPTYBatch()
+	m.ensureSidebar().AppendUserMessage("question")
+	m.refreshSidebar()
+
+	conn, err := observe.Dial(hub.Path())
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer conn.Close()
+
+	ev, err := conn.Next()
+	if err != nil || ev.Type != observe.EventOutput || !strings.Contains(string(ev.Output), "hello observer") {
+		t.Fatalf("Expected mirrored output, got %+v (err %v)", ev, err)
+	}
+	ev, err = conn.Next()
+	if err != nil || ev.Type != observe.EventChat || len(ev.Messages) != 1 {
+		t.Fatalf("Expected mirrored chat, got %+v (err %v)", ev, err)
+	}
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// overlayKind names a panel drawn over the terminal and sidebar.
+type overlayKind int
+
+// Overlay kinds, in the order they are stacked when several appear in the
+// same update: later kinds end up on top.
+const (
+	overlayResult overlayKind = iota
+	overlayHistory
+	overlayPalette
+	overlaySettings
+	overlayPromptPreview
+	overlayModelPicker
+	overlayEnvPicker
+	overlayOptionPicker
+	overlayReplay
+	overlayKindCount
+)
+
+var overlayNames = [overlayKindCount]string{
+	overlayResult:        "result_panel",
+	overlayHistory:       "history_picker",
+	overlayPalette:       "palette",
+	overlaySettings:      "settings_panel",
+	overlayPromptPreview: "prompt_preview",
+	overlayModelPicker:   "model_picker",
+	overlayEnvPicker:     "env_picker",
+	overlayOptionPicker:  "option_picker",
+	overlayReplay:        "replay",
+}
+
+func (k overlayKind) String() string {
+	if k < 0 || k >= overlayKindCount {
+		return "unknown"
+	}
+	return overlayNames[k]
+}
+
+// overlayPanel is what the stack needs of an overlay component.
+type overlayPanel interface {
+	IsVisible() bool
+	Update(tea.KeyPressMsg) tea.Cmd
+	View() string
+}
+
+// overlayStack holds the open overlays, bottom first. Every open overlay is
+// drawn, each above the ones opened before it, and the top one owns the
+// keyboard and pastes. The tool-approval and continue popups are not part
+// of it: they are modal and always sit above the stack.
+type overlayStack struct {
+	kinds []overlayKind
+}
+
+// push puts k on top, moving it there if it is already open.
+func (s *overlayStack) push(k overlayKind) {
+	if s == nil {
+		return
+	}
+	s.pop(k)
+	s.kinds = append(s.kinds, k)
+}
+
+// pop removes k wherever it is in the stack.
+func (s *overlayStack) pop(k overlayKind) {
+	if s == nil {
+		return
+	}
+	s.kinds = slices.DeleteFunc(s.kinds, func(o overlayKind) bool { return o == k })
+}
+
+func (s *overlayStack) contains(k overlayKind) bool {
+	return s != nil && slices.Contains(s.kinds, k)
+}
+
+// top returns the overlay that owns input, if any is open.
+func (s *overlayStack) top() (overlayKind, bool) {
+	if s == nil || len(s.kinds) == 0 {
+		return 0, false
+	}
+	return s.kinds[len(s.kinds)-1], true
+}
+
+// overlayPanel returns the component behind k, or nil when it has not been
+// created yet.
+func (m *Model) overlayPanel(k overlayKind) overlayPanel {
+	switch k {
+	case overlayResult:
+		if m.resultPanel != nil {
+			return m.resultPanel
+		}
+	case overlayHistory:
+		if m.historyPicker != nil {
+			return m.historyPicker
+		}
+	case overlayPalette:
+		if m.palette != nil {
+			return m.palette
+		}
+	case overlaySettings:
+		if m.settingsPanel != nil {
+			return m.settingsPanel
+		}
+	case overlayPromptPreview:
+		if m.promptPreview != nil {
+			return m.promptPreview
+		}
+	case overlayModelPicker:
+		if m.modelPicker != nil {
+			return m.modelPicker
+		}
+	case overlayEnvPicker:
+		if m.envPicker != nil {
+			return m.envPicker
+		}
+	case overlayOptionPicker:
+		if m.optionPicker != nil {
+			return m.optionPicker
+		}
+	case overlayReplay:
+		if m.scrubber != nil {
+			return m.scrubber
+		}
+	}
+	return nil
+}
+
+func (m *Model) overlayVisible(k overlayKind) bool {
+	p := m.overlayPanel(k)
+	return p != nil && p.IsVisible()
+}
+
+// syncOverlays brings the stack in line with the components, which still
+// show and hide themselves: overlays that closed are popped, and overlays
+// shown since the last sync are pushed. It returns the open overlays,
+// bottom first.
+func (m *Model) syncOverlays() []overlayKind {
+	if m.overlays == nil {
+		m.overlays = &overlayStack{}
+	}
+	m.overlays.kinds = slices.DeleteFunc(m.overlays.kinds, func(k overlayKind) bool {
+		return !m.overlayVisible(k)
+	})
+	for k := range overlayKindCount {
+		if m.overlayVisible(k) && !m.overlays.contains(k) {
+			m.overlays.push(k)
+		}
+	}
+	return m.overlays.kinds
+}
+
+// topOverlay returns the open overlay that owns input.
+func (m *Model) topOverlay() (overlayKind, bool) {
+	m.syncOverlays()
+	return m.overlays.top()
+}
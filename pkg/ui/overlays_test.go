@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/picker"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestOverlayStack_PushPop(t *testing.T) {
+	var s overlayStack
+	if _, ok := s.top(); ok {
+		t.Fatal("expected an empty stack")
+	}
+	s.push(overlaySettings)
+	s.push(overlayModelPicker)
+	s.push(overlaySettings)
+	if want := []overlayKind{overlayModelPicker, overlaySettings}; !reflect.DeepEqual(s.kinds, want) {
+		t.Fatalf("kinds = %v, want %v", s.kinds, want)
+	}
+	s.pop(overlaySettings)
+	if k, ok := s.top(); !ok || k != overlayModelPicker {
+		t.Fatalf("top = %v, %v; want the model picker", k, ok)
+	}
+}
+
+func TestModel_ModelPickerStacksAboveSettings(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = newModel.(Model)
+	m.ensureSettingsPanel().Show(config.Default(), config.GetConfigPath())
+
+	newModel, _ = m.Update(picker.OpenModelPickerMsg{
+		Options:  ai.GetProviderModels("openai"),
+		Current:  "gpt-4o",
+		FieldKey: "openai_model",
+	})
+	m = newModel.(Model)
+	if got, want := m.syncOverlays(), []overlayKind{overlaySettings, overlayModelPicker}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("overlays = %v, want %v", got, want)
+	}
+	if view, _ := m.Render(); !strings.Contains(view, "gpt-4o") {
+		t.Fatal("expected the model picker to be drawn above settings")
+	}
+
+	// Esc closes only the picker; settings takes the keyboard back.
+	newModel, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = newModel.(Model)
+	if m.modelPicker.IsVisible() || !m.settingsPanel.IsVisible() {
+		t.Fatalf("picker visible=%v settings visible=%v", m.modelPicker.IsVisible(), m.settingsPanel.IsVisible())
+	}
+	if k, ok := m.topOverlay(); !ok || k != overlaySettings {
+		t.Fatalf("top = %v, %v; want settings", k, ok)
+	}
+}
+
+func TestModel_LaterOverlayOwnsInput(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = newModel.(Model)
+	m.palette.Show()
+	m.syncOverlays()
+	m.resultPanel.Show("Result", "body")
+
+	if k, ok := m.topOverlay(); !ok || k != overlayResult {
+		t.Fatalf("top = %v, %v; want the result panel opened last", k, ok)
+	}
+	if !m.hasBlockingOverlay() {
+		t.Fatal("expected open overlays to block mouse and focus routing")
+	}
+
+	m.resultPanel.Hide()
+	if k, ok := m.topOverlay(); !ok || k != overlayPalette {
+		t.Fatalf("top = %v, %v; want the palette once the result panel closed", k, ok)
+	}
+}
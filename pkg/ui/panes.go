@@ -0,0 +1,437 @@
+package ui
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/pty"
+	"wtf_cli/pkg/ui/components/fullscreen"
+	"wtf_cli/pkg/ui/components/statusbar"
+	"wtf_cli/pkg/ui/components/utils"
+	"wtf_cli/pkg/ui/components/viewport"
+	"wtf_cli/pkg/ui/input"
+	"wtf_cli/pkg/ui/styles"
+	"wtf_cli/pkg/ui/terminal"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// paneKey starts a pane command; the key pressed after it picks the command.
+// Pressing it twice sends it to the shell, where it deletes a word.
+const paneKey = "ctrl+w"
+
+const (
+	paneKeyHint      = "Pane: v split · f freeze scrollback · Ctrl+W sends Ctrl+W"
+	paneKeySplitHint = "Pane: w/←/→ switch · q close · f freeze scrollback · Ctrl+W sends Ctrl+W"
+)
+
+// PaneShell is a shell started for a split pane.
+type PaneShell struct {
+	PTY     *os.File
+	Buffer  *buffer.CircularBuffer
+	Cwd     func() (string, error)
+	Environ func() ([]string, error)
+	Jobs    func() ([]pty.Job, error)
+	Process ShellProcess // Closed and reaped with the pane; may be nil
+}
+
+// ShellProcess is the process behind a pane's PTY, a *pty.Wrapper in
+// wtf_cli. Close hangs up on the shell and removes its startup files; Wait
+// reaps it.
+type ShellProcess interface {
+	Close() error
+	Wait() error
+}
+
+// pane is the terminal pane that is not focused while the terminal is split.
+// Focusing it swaps its state with the Model's own fields, so everything that
+// reads m.ptyFile, m.buffer or m.session, including the AI context, follows
+// the focused pane.
+type pane struct {
+	ptyFile         *os.File
+	shellProcess    ShellProcess
+	cwdFunc         func() (string, error)
+	environFunc     func() ([]string, error)
+	jobsFunc        func() ([]pty.Job, error)
+	jobs            []pty.Job
+	inputHandler    *input.InputHandler
+	buffer          *buffer.CircularBuffer
+	session         *capture.SessionContext
+	currentDir      string
+	gitBranch       string
+	viewport        viewport.PTYViewport
+	ptyNormalizer   *terminal.Normalizer
+	rawLines        *rawLineLog
+	promptZones     promptZones
+	repl            *capture.REPLTracker
+	altScreenState  *terminal.AltScreenState
+	fullScreenMode  bool
+	fullScreenPanel *fullscreen.FullScreenPanel
+	scrollMode      bool
+	pendingCommand  *pendingCommand
+	appliedCommand  string
+	installHint     *installHint
+	typoHint        *typoHint
+	bookmarks       []bookmark
+}
+
+// SetPaneSpawner sets how the shell of a new split pane, or of a restart
+// after the shell exited, is started. Nil disables both.
+func (m *Model) SetPaneSpawner(spawn func() (PaneShell, error)) {
+	m.paneSpawner = spawn
+}
+
+func newPane(shell PaneShell, dir string) *pane {
+	if shell.Cwd != nil {
+		if cwd, err := shell.Cwd(); err == nil {
+			dir = cwd
+		}
+	}
+	vp := viewport.NewPTYViewport()
+	vp.SetCursorVisible(false)
+	return &pane{
+		ptyFile:        shell.PTY,
+		shellProcess:   shell.Process,
+		cwdFunc:        shell.Cwd,
+		environFunc:    shell.Environ,
+		jobsFunc:       shell.Jobs,
+		inputHandler:   input.NewInputHandler(shell.PTY),
+		buffer:         shell.Buffer,
+		session:        capture.NewSessionContext(),
+		currentDir:     dir,
+		viewport:       vp,
+		ptyNormalizer:  terminal.NewNormalizer(),
+		rawLines:       &rawLineLog{},
+		repl:           &capture.REPLTracker{},
+		altScreenState: terminal.NewAltScreenState(),
+	}
+}
+
+// swapPanes exchanges the focused pane's state with m.split. Batched output
+// belongs to the focused pane, so callers flush it first.
+func (m *Model) swapPanes() {
+	p := m.split
+	m.ptyFile, p.ptyFile = p.ptyFile, m.ptyFile
+	m.shellProcess, p.shellProcess = p.shellProcess, m.shellProcess
+	m.cwdFunc, p.cwdFunc = p.cwdFunc, m.cwdFunc
+	m.environFunc, p.environFunc = p.environFunc, m.environFunc
+	m.jobsFunc, p.jobsFunc = p.jobsFunc, m.jobsFunc
+	m.jobs, p.jobs = p.jobs, m.jobs
+	m.inputHandler, p.inputHandler = p.inputHandler, m.inputHandler
+	m.buffer, p.buffer = p.buffer, m.buffer
+	m.session, p.session = p.session, m.session
+	m.currentDir, p.currentDir = p.currentDir, m.currentDir
+	m.gitBranch, p.gitBranch = p.gitBranch, m.gitBranch
+	m.viewport, p.viewport = p.viewport, m.viewport
+	m.ptyNormalizer, p.ptyNormalizer = p.ptyNormalizer, m.ptyNormalizer
+	m.rawLines, p.rawLines = p.rawLines, m.rawLines
+	m.promptZones, p.promptZones = p.promptZones, m.promptZones
+	m.repl, p.repl = p.repl, m.repl
+	m.altScreenState, p.altScreenState = p.altScreenState, m.altScreenState
+	m.fullScreenMode, p.fullScreenMode = p.fullScreenMode, m.fullScreenMode
+	m.fullScreenPanel, p.fullScreenPanel = p.fullScreenPanel, m.fullScreenPanel
+	m.scrollMode, p.scrollMode = p.scrollMode, m.scrollMode
+	m.pendingCommand, p.pendingCommand = p.pendingCommand, m.pendingCommand
+	m.appliedCommand, p.appliedCommand = p.appliedCommand, m.appliedCommand
+	m.installHint, p.installHint = p.installHint, m.installHint
+	m.typoHint, p.typoHint = p.typoHint, m.typoHint
+	m.bookmarks, p.bookmarks = p.bookmarks, m.bookmarks
+	m.splitFocusRight = !m.splitFocusRight
+}
+
+// armPaneKey waits for the key that picks a pane command.
+func (m Model) armPaneKey() (Model, tea.Cmd) {
+	m.panePrefix = true
+	hint := paneKeyHint
+	if m.split != nil {
+		hint = paneKeySplitHint
+	}
+	m.postStatus(statusbar.Message{Source: statusSourcePanes, Text: hint, Priority: statusbar.PriorityHigh}, 0)
+	return m, nil
+}
+
+// handlePaneKey runs the pane command picked by msg. Keys that are not pane
+// commands reach the shell after the Ctrl+W that preceded them.
+func (m Model) handlePaneKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	m.panePrefix = false
+	m.clearStatus(statusSourcePanes)
+	switch msg.String() {
+	case "v", "s":
+		return m.splitTerminal()
+	case "w", "tab", "left", "right", "h", "l":
+		if m.split != nil {
+			return m, m.focusOtherPane()
+		}
+	case "q", "c":
+		if m.split != nil {
+			return m.closePane()
+		}
+	case "f":
+		return m.toggleScrollSplit()
+	case paneKey:
+		m.inputHandler.HandleKey(msg)
+		return m, nil
+	}
+	m.inputHandler.HandleKey(tea.KeyPressMsg{Code: 'w', Mod: tea.ModCtrl})
+	return m.handleKeyPress(msg)
+}
+
+// splitTerminal starts a second shell in a pane to the right and focuses it.
+func (m Model) splitTerminal() (Model, tea.Cmd) {
+	if m.split != nil {
+		return m, m.flashStatus("The terminal is already split; Ctrl+W w switches panes")
+	}
+	if m.paneSpawner == nil {
+		return m, m.flashStatus("Split panes are not available")
+	}
+	shell, err := m.paneSpawner()
+	if err != nil {
+		slog.Error("pane_split_error", "error", err)
+		return m, m.flashStatus("Could not start a shell: " + err.Error())
+	}
+
+	m.split = newPane(shell, m.currentDir)
+	m.split.inputHandler.SetReadOnly(m.readOnly)
+	m.splitFocusRight = false
+	m.flushPTYBatch()
+	m.viewport.SetCursorVisible(false)
+	m.swapPanes()
+	m.viewport.SetCursorVisible(m.terminalFocused)
+	m.statusBar.SetScrollMode(m.scrollMode)
+	m.applyLayout()
+	m.applyTriggerHighlights()
+	slog.Info("pane_split", "dir", m.currentDir)
+	return m, tea.Batch(listenToPTY(m.ptyFile), resolveGitBranchCmd(m.currentDir, m.gitBranchResolver))
+}
+
+// toggleScrollSplit splits the focused pane's viewport in two, or joins it
+// again: the top half stays on the rows shown now, scrolled back or not,
+// while the bottom half follows the output. Scrolling then moves the top
+// half.
+func (m Model) toggleScrollSplit() (Model, tea.Cmd) {
+	if m.viewport.IsSplit() {
+		m.viewport.SetSplit(false)
+		slog.Debug("scroll_split", "on", false)
+		return m, nil
+	}
+	m.viewport.SetSplit(true)
+	if m.scrollMode {
+		m.setScrollMode(false)
+	}
+	slog.Debug("scroll_split", "on", true)
+	return m, m.flashStatus("Top half frozen, bottom half follows the output · Alt+↑/↓ and PgUp/PgDn scroll the top")
+}
+
+// focusOtherPane moves keyboard input and AI context to the other pane.
+func (m *Model) focusOtherPane() tea.Cmd {
+	m.clearTextSelections()
+	m.flushPTYBatch()
+	m.viewport.SetCursorVisible(false)
+	m.swapPanes()
+	m.viewport.SetCursorVisible(m.terminalFocused)
+	m.statusBar.SetScrollMode(m.scrollMode)
+	m.applyLayout()
+	slog.Debug("pane_focus", "right", m.splitFocusRight, "dir", m.currentDir)
+	return m.scheduleBulkSettle()
+}
+
+// closePane closes the focused pane's shell and gives the window back to
+// the other pane.
+func (m Model) closePane() (Model, tea.Cmd) {
+	closing := m.ptyFile
+	process := m.removePane(closing)
+	slog.Info("pane_close", "reason", "key")
+	return m, tea.Batch(releaseShell(process, closing), m.scheduleBulkSettle())
+}
+
+// removePane drops the pane reading from ptyFile, leaving the other pane
+// alone in the window, and returns the dropped pane's shell process.
+func (m *Model) removePane(ptyFile *os.File) ShellProcess {
+	if m.split == nil {
+		return nil
+	}
+	m.flushPTYBatch()
+	if ptyFile == m.ptyFile {
+		m.swapPanes()
+	}
+	process := m.split.shellProcess
+	m.split = nil
+	m.splitFocusRight = false
+	m.viewport.SetCursorVisible(m.terminalFocused)
+	m.statusBar.SetScrollMode(m.scrollMode)
+	m.applyLayout()
+	return process
+}
+
+// releaseShell hangs up on the shell of a closed pane and reaps it off the
+// UI goroutine. Without a process, as for the first shell, which main owns,
+// it only closes the PTY.
+func releaseShell(process ShellProcess, ptyFile *os.File) tea.Cmd {
+	stopPTYReader(ptyFile)
+	if process == nil {
+		if ptyFile != nil {
+			if err := ptyFile.Close(); err != nil {
+				slog.Warn("pane_close_error", "error", err)
+			}
+		}
+		return nil
+	}
+	if err := process.Close(); err != nil {
+		slog.Warn("pane_close_error", "error", err)
+	}
+	return func() tea.Msg {
+		_ = process.Wait() // The hung-up shell's exit status is not interesting
+		return nil
+	}
+}
+
+// CloseShells closes and reaps the shells the model started for split
+// panes and restarts. The first shell belongs to the caller.
+func (m Model) CloseShells() {
+	processes := []ShellProcess{m.shellProcess}
+	if m.split != nil {
+		processes = append(processes, m.split.shellProcess)
+	}
+	for _, process := range processes {
+		if process == nil {
+			continue
+		}
+		if err := process.Close(); err != nil {
+			slog.Warn("pane_close_error", "error", err)
+		}
+		_ = process.Wait()
+	}
+}
+
+// handleSplitPaneOutput feeds output of the unfocused pane into its buffer
+// and viewport without batching. Observers only follow the focused pane.
+func (m Model) handleSplitPaneOutput(msg ptyOutputMsg) (Model, tea.Cmd) {
+	hub := m.observeHub
+	pending := m.ptyBatchBuffer
+	m.observeHub = nil
+	m.swapPanes()
+	m.ptyBatchBuffer = msg.data
+	m.flushPTYBatch()
+	m.swapPanes()
+	m.ptyBatchBuffer = pending
+	m.observeHub = hub
+	m.statusBar.SetScrollMode(m.scrollMode)
+	// Scheduled with both panes in place, so the background pane's viewport
+	// is polled too.
+	return m, tea.Batch(m.scheduleBulkSettle(), listenToPTY(msg.pty))
+}
+
+// handleSplitPaneExit closes the pane whose shell exited and returns the
+// command reaping it, or reports false when ptyFile belongs to no pane of a
+// split terminal.
+func (m Model) handleSplitPaneExit(ptyFile *os.File) (Model, tea.Cmd, bool) {
+	if m.split == nil || (ptyFile != m.ptyFile && ptyFile != m.split.ptyFile) {
+		return m, nil, false
+	}
+	process := m.removePane(ptyFile)
+	slog.Info("pane_close", "reason", "shell_exit")
+	return m, releaseShell(process, ptyFile), true
+}
+
+// splitPaneWidths divides the terminal area between the left and right
+// panes, leaving one column for the divider.
+func splitPaneWidths(total int) (left, right int) {
+	if total < 3 {
+		return total, 0
+	}
+	left = (total - 1) / 2
+	return left, total - 1 - left
+}
+
+// resizePanes sizes the unfocused pane for a terminal area of width×height
+// and returns the focused pane's viewport size. Each pane gives its top row
+// to a title.
+func (m *Model) resizePanes(width, height int) (int, int) {
+	left, right := splitPaneWidths(width)
+	focused, other := left, right
+	if m.splitFocusRight {
+		focused, other = right, left
+	}
+	height = max(height-1, 0)
+	m.split.viewport.SetSize(other, height)
+	return focused, height
+}
+
+// resizeSplitPTY keeps the unfocused shell's wrapping in sync with its pane.
+func (m *Model) resizeSplitPTY() {
+	if m.split == nil || m.split.ptyFile == nil || m.split.fullScreenMode {
+		return
+	}
+	width, height := m.split.viewport.Size()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if err := terminal.ResizePTY(m.split.ptyFile, width, height); err != nil {
+		slog.Warn("pty_resize_failed", "width", width, "height", height, "error", err)
+	}
+}
+
+// focusedPaneOffset returns where the focused pane's viewport starts inside
+// a terminal area of the given width, and the pane's width.
+func (m *Model) focusedPaneOffset(width int) (x, y, paneWidth int) {
+	if m.split == nil {
+		return 0, 0, width
+	}
+	left, right := splitPaneWidths(width)
+	if m.splitFocusRight {
+		return left + 1, 1, right
+	}
+	return 0, 1, left
+}
+
+// inUnfocusedPane reports whether screen column x of a terminal area of the
+// given width falls in the unfocused pane.
+func (m *Model) inUnfocusedPane(x, width int) bool {
+	if m.split == nil {
+		return false
+	}
+	left, _ := splitPaneWidths(width)
+	if m.splitFocusRight {
+		return x < left
+	}
+	return x > left
+}
+
+// paneLayers renders both panes of a split terminal area with their titles
+// and the divider between them.
+func (m Model) paneLayers(width, height int, z int) []*lipgloss.Layer {
+	left, right := splitPaneWidths(width)
+	focusedX, otherX := 0, left+1
+	focusedW, otherW := left, right
+	focusedNum, otherNum := 1, 2
+	if m.splitFocusRight {
+		focusedX, otherX = otherX, focusedX
+		focusedW, otherW = otherW, focusedW
+		focusedNum, otherNum = otherNum, focusedNum
+	}
+
+	divider := strings.TrimSuffix(strings.Repeat("│\n", height), "\n")
+	return []*lipgloss.Layer{
+		lipgloss.NewLayer(paneTitle(focusedNum, m.currentDir, focusedW, true)).X(focusedX).Y(0).Z(z),
+		lipgloss.NewLayer(m.viewport.View()).X(focusedX).Y(1).Z(z),
+		lipgloss.NewLayer(paneTitle(otherNum, m.split.currentDir, otherW, false)).X(otherX).Y(0).Z(z),
+		lipgloss.NewLayer(m.split.viewport.View()).X(otherX).Y(1).Z(z),
+		lipgloss.NewLayer(styles.TextMutedStyle.Render(divider)).X(left).Y(0).Z(z),
+	}
+}
+
+// paneTitle renders a pane's title row, e.g. "● 1 ~/src"; the focused pane's
+// title is highlighted.
+func paneTitle(num int, dir string, width int, focused bool) string {
+	marker, style := "○", styles.TextMutedStyle
+	if focused {
+		marker, style = "●", styles.SelectedStyle
+	}
+	text := utils.TruncateToWidth(" "+marker+" "+strconv.Itoa(num)+" "+dir, width)
+	return style.Width(width).Render(text)
+}
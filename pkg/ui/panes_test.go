@@ -0,0 +1,309 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/testutils"
+	"wtf_cli/pkg/ui/components/viewport"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// newPipePTY stands in for a PTY: the model writes to the returned file and
+// the test reads what it wrote from the reader.
+func newPipePTY(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+	return w, r
+}
+
+func newSplitTestModel(t *testing.T) (Model, *os.File, *os.File) {
+	t.Helper()
+	leftPTY, _ := newPipePTY(t)
+	rightPTY, _ := newPipePTY(t)
+	m := NewModel(leftPTY, buffer.New(100), capture.NewSessionContext(), nil)
+	m.SetPaneSpawner(func() (PaneShell, error) {
+		return PaneShell{PTY: rightPTY, Buffer: buffer.New(100)}, nil
+	})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 81, Height: 24})
+	return m, leftPTY, rightPTY
+}
+
+func pressPaneKey(t *testing.T, m Model, key tea.KeyPressMsg) Model {
+	t.Helper()
+	m, _ = m.handleKeyPress(testutils.NewCtrlKeyPressMsg('w'))
+	if !m.panePrefix {
+		t.Fatal("expected Ctrl+W to wait for a pane command")
+	}
+	m, _ = m.handleKeyPress(key)
+	return m
+}
+
+func TestModel_SplitOpensFocusedPaneOnTheRight(t *testing.T) {
+	m, leftPTY, rightPTY := newSplitTestModel(t)
+
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	if m.split == nil {
+		t.Fatal("expected the terminal to be split")
+	}
+	if m.ptyFile != rightPTY || !m.splitFocusRight {
+		t.Fatal("expected the new pane to have focus on the right")
+	}
+	if m.split.ptyFile != leftPTY {
+		t.Fatal("expected the original shell in the unfocused pane")
+	}
+	if got, want := m.viewport.Viewport.Width(), 40; got != want {
+		t.Fatalf("focused pane width = %d, want %d", got, want)
+	}
+	if got, want := m.viewport.Viewport.Height(), 22; got != want {
+		t.Fatalf("focused pane height = %d, want %d (title row reserved)", got, want)
+	}
+
+	view, _ := m.Render()
+	if !strings.Contains(view, "○ 1") || !strings.Contains(view, "● 2") {
+		t.Fatalf("expected both pane titles with the focus marker on pane 2, got:\n%s", view)
+	}
+}
+
+func TestModel_SplitPaneOutputAndContextFollowFocus(t *testing.T) {
+	m, leftPTY, rightPTY := newSplitTestModel(t)
+	leftBuffer := m.buffer
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+
+	m, _ = m.handlePTYOutput(ptyOutputMsg{data: []byte("from left\n"), pty: leftPTY})
+	if !strings.Contains(m.split.viewport.GetContent(), "from left") {
+		t.Fatal("expected unfocused pane output in its own viewport")
+	}
+	if strings.Contains(m.viewport.GetContent(), "from left") {
+		t.Fatal("expected unfocused pane output kept out of the focused viewport")
+	}
+	if m.commandContext().Buffer == leftBuffer {
+		t.Fatal("expected AI context from the focused right pane")
+	}
+
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('w'))
+	if m.ptyFile != leftPTY || m.splitFocusRight {
+		t.Fatal("expected Ctrl+W w to focus the left pane")
+	}
+	if m.commandContext().Buffer != leftBuffer {
+		t.Fatal("expected AI context to follow focus to the left pane")
+	}
+	if m.split.ptyFile != rightPTY {
+		t.Fatal("expected the right pane to be unfocused")
+	}
+}
+
+func TestModel_PaneKeyPassesOtherKeysToShell(t *testing.T) {
+	leftPTY, reader := newPipePTY(t)
+	m := NewModel(leftPTY, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	m = pressPaneKey(t, m, testutils.NewCtrlKeyPressMsg('w'))
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('x'))
+	if m.panePrefix {
+		t.Fatal("expected the pane command to be finished")
+	}
+	_ = leftPTY.Close()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	if string(got) != "\x17\x17x" {
+		t.Fatalf("shell received %q, want %q", got, "\x17\x17x")
+	}
+}
+
+func TestModel_SplitWithoutSpawnerReportsUnavailable(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	if m.split != nil {
+		t.Fatal("expected no split without a pane spawner")
+	}
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, "not available") {
+		t.Fatalf("expected unavailable message, got %q", got)
+	}
+}
+
+func TestModel_ClosingOrExitingPaneKeepsTheOther(t *testing.T) {
+	m, leftPTY, rightPTY := newSplitTestModel(t)
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+
+	// The left shell exits while the right pane has focus.
+	m, cmd := m.handlePTYError(ptyErrorMsg{err: io.EOF, pty: leftPTY})
+	if m.split != nil || m.ptyFile != rightPTY {
+		t.Fatal("expected the right pane alone after the left shell exited")
+	}
+	if cmd == nil {
+		t.Fatal("expected a status flash, not a quit")
+	}
+	if got := m.viewport.Viewport.Width(); got != 81 {
+		t.Fatalf("remaining pane width = %d, want the full 81 columns", got)
+	}
+
+	// Errors from a pane that is already gone are ignored.
+	if _, cmd := m.handlePTYError(ptyErrorMsg{err: errors.New("closed"), pty: leftPTY}); cmd != nil {
+		t.Fatal("expected no command for a closed pane's reader")
+	}
+
+	// Ctrl+W q closes the focused pane.
+	m, _, _ = newSplitTestModel(t)
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('q'))
+	if m.split != nil || m.splitFocusRight {
+		t.Fatal("expected Ctrl+W q to close the focused pane")
+	}
+}
+
+// fakeShellProcess records what happens to a pane's shell.
+type fakeShellProcess struct {
+	closed, waited bool
+}
+
+func (p *fakeShellProcess) Close() error {
+	p.closed = true
+	return nil
+}
+
+func (p *fakeShellProcess) Wait() error {
+	p.waited = true
+	return nil
+}
+
+func TestModel_ClosedPaneShellIsReaped(t *testing.T) {
+	leftPTY, _ := newPipePTY(t)
+	m := NewModel(leftPTY, buffer.New(100), capture.NewSessionContext(), nil)
+	var processes []*fakeShellProcess
+	m.SetPaneSpawner(func() (PaneShell, error) {
+		rightPTY, _ := newPipePTY(t)
+		process := &fakeShellProcess{}
+		processes = append(processes, process)
+		return PaneShell{PTY: rightPTY, Buffer: buffer.New(100), Process: process}, nil
+	})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 81, Height: 24})
+
+	// Ctrl+W q hangs up on the pane's shell and reaps it.
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	m, _ = m.handleKeyPress(testutils.NewCtrlKeyPressMsg('w'))
+	m, cmd := m.handleKeyPress(testutils.NewKeyPressMsg('q'))
+	if !processes[0].closed {
+		t.Fatal("expected closing the pane to close its shell")
+	}
+	if cmd == nil {
+		t.Fatal("expected a command reaping the shell")
+	}
+	cmd()
+	if !processes[0].waited {
+		t.Fatal("expected the closed shell to be reaped")
+	}
+
+	// A pane whose shell exited is reaped too.
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	exited := m.ptyFile
+	m, cmd, closed := m.handleSplitPaneExit(exited)
+	if !closed || cmd == nil {
+		t.Fatal("expected the exited pane closed with a command reaping it")
+	}
+	cmd()
+	if !processes[1].closed || !processes[1].waited {
+		t.Fatal("expected the exited shell closed and reaped")
+	}
+
+	// Shells still open when the program ends are closed by CloseShells,
+	// which leaves the first shell to its owner.
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	m.CloseShells()
+	if !processes[2].closed || !processes[2].waited {
+		t.Fatal("expected CloseShells to close and reap the pane's shell")
+	}
+}
+
+func TestModel_PaneSwitchFlushesBatchedOutput(t *testing.T) {
+	m, _, _ := newSplitTestModel(t)
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+
+	m.ptyBatchBuffer = append(m.ptyBatchBuffer, "from right\n"...)
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('w'))
+	if strings.Contains(m.viewport.GetContent(), "from right") {
+		t.Fatal("expected batched output kept out of the pane that gained focus")
+	}
+	if !strings.Contains(m.split.viewport.GetContent(), "from right") {
+		t.Fatal("expected batched output in the pane it came from")
+	}
+}
+
+func TestModel_PaneKeyFreezesScrollback(t *testing.T) {
+	m, _, _ := newSplitTestModel(t)
+	writePTY(&m, strings.Repeat("build step\n", 60)+"error: first failure\n")
+	m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: tea.KeyPgUp})
+	if !m.scrollMode {
+		t.Fatal("expected PgUp to enter scroll mode")
+	}
+
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('f'))
+	if !m.viewport.IsSplit() || m.scrollMode {
+		t.Fatal("expected a split view following the output below")
+	}
+	m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: tea.KeyPgUp})
+	if m.scrollMode {
+		t.Fatal("expected scrolling the frozen half to leave scroll mode off")
+	}
+
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 100, Height: 30})
+	if _, height := m.viewport.Size(); height != 29 {
+		t.Fatalf("viewport height after resize = %d, want 29", height)
+	}
+	view, _ := m.Render()
+	if !strings.Contains(view, "frozen at line") {
+		t.Fatalf("expected the split divider after a resize, got:\n%s", view)
+	}
+
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('f'))
+	if m.viewport.IsSplit() {
+		t.Fatal("expected Ctrl+W f to join the halves again")
+	}
+}
+
+func TestModel_BackgroundPaneBulkOutputSettles(t *testing.T) {
+	m, leftPTY, _ := newSplitTestModel(t)
+	m = pressPaneKey(t, m, testutils.NewKeyPressMsg('v'))
+	now := time.Unix(0, 0)
+	m.split.viewport.SetClock(func() time.Time { return now })
+
+	chunk := []byte(strings.Repeat("bulk output line with some padding text\r\n", 1000))
+	var cmd tea.Cmd
+	for i := 0; i < 10; i++ {
+		updated, c := m.Update(ptyOutputMsg{data: chunk, pty: leftPTY})
+		m, cmd = updated.(Model), c
+		now = now.Add(10 * time.Millisecond)
+	}
+	if !m.split.viewport.InBulkMode() || m.viewport.InBulkMode() {
+		t.Fatal("expected only the background pane in bulk mode")
+	}
+	if cmd == nil || !m.bulkSettlePending {
+		t.Fatal("expected background bulk output to schedule a settle poll")
+	}
+
+	now = now.Add(viewport.BulkIdle)
+	updated, cmd := m.Update(viewportBulkSettleMsg{})
+	m = updated.(Model)
+	if m.split.viewport.InBulkMode() || m.bulkSettlePending || cmd != nil {
+		t.Fatal("expected the background pane to settle once its output went quiet")
+	}
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// pasteFieldKey routes the option picker asking whether to paste a large
+// blob into the shell.
+const pasteFieldKey = "paste_confirm"
+
+const (
+	pasteConfirmOption = "Paste"
+	pasteCancelOption  = "Cancel"
+)
+
+// pasteToPTY writes a paste meant for the shell. Pastes above the
+// paste.confirm_above_kb threshold ask first.
+func (m Model) pasteToPTY(content string) (Model, tea.Cmd) {
+	if m.pasteConfirmBytes > 0 && len(content) > m.pasteConfirmBytes {
+		m.pendingPaste = content
+		slog.Info("paste_confirm_prompt", "len", len(content))
+		optionPicker := m.ensureOptionPicker()
+		optionPicker.SetSize(m.width, m.height)
+		optionPicker.Show("Paste "+formatPasteSize(len(content))+" into the shell?", pasteFieldKey,
+			[]string{pasteConfirmOption, pasteCancelOption}, pasteCancelOption)
+		return m, nil
+	}
+	return m.writePaste(content)
+}
+
+// confirmPaste writes or drops the paste waiting for confirmation.
+func (m Model) confirmPaste(choice string) (Model, tea.Cmd) {
+	content := m.pendingPaste
+	m.pendingPaste = ""
+	if choice != pasteConfirmOption || content == "" {
+		slog.Info("paste_confirm_declined", "len", len(content))
+		return m, nil
+	}
+	return m.writePaste(content)
+}
+
+// writePaste hands content to the input handler. Large pastes come back as
+// an input.Paste, written chunk by chunk with their progress in the status
+// bar until done or cancelled with Esc.
+func (m Model) writePaste(content string) (Model, tea.Cmd) {
+	if m.inputHandler == nil {
+		return m, nil
+	}
+	if m.readOnly {
+		return m, m.flashStatus(readOnlyNotice)
+	}
+	if m.paste != nil {
+		// Interleaving two pastes would scramble both.
+		return m, m.flashStatus("Still pasting — wait for it to finish or press Esc")
+	}
+	p := m.inputHandler.HandlePaste(content)
+	if p == nil {
+		return m, nil
+	}
+	m.paste = p
+	m.pasteProgressID = m.progress.Start(pasteLabel(0, p.Len()), p.Cancel)
+	slog.Info("paste_chunked_start", "len", p.Len())
+	return m, tea.Batch(m.startProgressTicking(), p.WriteNext())
+}
+
+func (m Model) handlePasteProgress(msg input.PasteProgressMsg) (Model, tea.Cmd) {
+	if msg.Paste == nil || msg.Paste != m.paste {
+		return m, nil
+	}
+	if !msg.Done {
+		m.progress.SetLabel(m.pasteProgressID, pasteLabel(msg.Written, msg.Total))
+		m.syncProgress()
+		return m, msg.Paste.WriteNext()
+	}
+
+	m.paste = nil
+	if m.inputHandler != nil {
+		m.inputHandler.FinishPaste(msg)
+	}
+	// A paste cancelled with Esc is no longer tracked.
+	m.progress.Finish(m.pasteProgressID)
+	m.pasteProgressID = 0
+	m.syncProgress()
+	switch {
+	case msg.Err != nil:
+		slog.Error("paste_chunked_error", "written", msg.Written, "len", msg.Total, "error", msg.Err)
+		return m, m.flashStatus("Paste failed: " + msg.Err.Error())
+	case msg.Paste.Cancelled():
+		slog.Info("paste_chunked_cancelled", "written", msg.Written, "len", msg.Total)
+	default:
+		slog.Info("paste_chunked_done", "len", msg.Total)
+	}
+	return m, nil
+}
+
+func pasteLabel(written, total int) string {
+	label := "Pasting " + formatPasteSize(total)
+	if total > 0 && written > 0 {
+		label += fmt.Sprintf(" %d%%", written*100/total)
+	}
+	return label
+}
+
+// formatPasteSize renders n bytes as KB or MB.
+func formatPasteSize(n int) string {
+	if n >= 1024*1024 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+	return fmt.Sprintf("%d KB", (n+1023)/1024)
+}
+
+// sanitizePaste applies the paste cleanup set up for a target: CRLF line
+// endings become LF, which a shell would otherwise read as two Enters, and
+// trailing line breaks go when asked.
+func sanitizePaste(content string, cfg config.PasteTargetConfig) string {
+	if !cfg.KeepCRLF {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	if cfg.StripTrailingNewlines {
+		content = strings.TrimRight(content, "\r\n")
+	}
+	return content
+}
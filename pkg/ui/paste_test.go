@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newPasteTestModel(t *testing.T, confirmBytes int) (Model, *bytes.Buffer) {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	pty := &bytes.Buffer{}
+	m.inputHandler = input.NewInputHandler(pty)
+	m.pasteConfirmBytes = confirmBytes
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return newModel.(Model), pty
+}
+
+// nextPasteChunk writes the next chunk of the paste in flight and applies
+// its progress message.
+func nextPasteChunk(t *testing.T, m Model) Model {
+	t.Helper()
+	if m.paste == nil {
+		t.Fatal("Expected a paste in flight")
+	}
+	newModel, _ := m.Update(m.paste.WriteNext()())
+	return newModel.(Model)
+}
+
+func TestModel_LargePasteIsWrittenInChunks(t *testing.T) {
+	m, pty := newPasteTestModel(t, 0)
+	content := strings.Repeat("a", input.ChunkedPasteMin*2)
+
+	newModel, _ := m.Update(tea.PasteMsg{Content: content})
+	m = newModel.(Model)
+	if pty.Len() != 0 {
+		t.Fatal("Expected nothing written on the UI goroutine")
+	}
+	if !strings.Contains(m.statusBar.Render(), "Pasting 128 KB") {
+		t.Fatalf("Expected paste progress in the status bar, got %q", m.statusBar.Render())
+	}
+
+	m = nextPasteChunk(t, m)
+	if !strings.Contains(m.statusBar.Render(), "3%") {
+		t.Fatalf("Expected a percentage after the first chunk, got %q", m.statusBar.Render())
+	}
+	for m.paste != nil {
+		m = nextPasteChunk(t, m)
+	}
+	if pty.String() != content {
+		t.Fatalf("Expected the whole paste written, got %d of %d bytes", pty.Len(), len(content))
+	}
+	if m.progress.Active() {
+		t.Fatal("Expected the progress cleared once the paste is done")
+	}
+	if m.inputHandler.LineBuffer() != content {
+		t.Fatal("Expected the pasted text tracked as the current line")
+	}
+}
+
+func TestModel_EscCancelsLargePaste(t *testing.T) {
+	m, pty := newPasteTestModel(t, 0)
+
+	newModel, _ := m.Update(tea.PasteMsg{Content: strings.Repeat("b", input.ChunkedPasteMin)})
+	m = newModel.(Model)
+	m = nextPasteChunk(t, m)
+
+	newModel, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = newModel.(Model)
+	if m.progress.Active() {
+		t.Fatal("Expected Esc to cancel the paste")
+	}
+	m = nextPasteChunk(t, m)
+	if m.paste != nil {
+		t.Fatal("Expected the cancelled paste to stop")
+	}
+	if pty.Len() != input.PasteChunkSize {
+		t.Fatalf("Expected only the first chunk written, got %d bytes", pty.Len())
+	}
+}
+
+func TestModel_PasteAboveThresholdAsksFirst(t *testing.T) {
+	m, pty := newPasteTestModel(t, 1024)
+	content := strings.Repeat("c", 2048)
+
+	newModel, _ := m.Update(tea.PasteMsg{Content: content})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() || !strings.Contains(m.optionPicker.View(), "Paste 2 KB") {
+		t.Fatal("Expected a confirmation before pasting")
+	}
+	if pty.Len() != 0 {
+		t.Fatal("Expected nothing written before confirming")
+	}
+
+	newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: pasteFieldKey, Value: pasteCancelOption})
+	m = newModel.(Model)
+	if pty.Len() != 0 || m.pendingPaste != "" {
+		t.Fatal("Expected a declined paste to be dropped")
+	}
+
+	newModel, _ = m.Update(tea.PasteMsg{Content: content})
+	m = newModel.(Model)
+	newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: pasteFieldKey, Value: pasteConfirmOption})
+	m = newModel.(Model)
+	if pty.String() != content {
+		t.Fatalf("Expected the confirmed paste written, got %d bytes", pty.Len())
+	}
+}
+
+func TestModel_SecondLargePasteWaitsForFirst(t *testing.T) {
+	m, pty := newPasteTestModel(t, 0)
+	content := strings.Repeat("d", input.ChunkedPasteMin)
+
+	newModel, _ := m.Update(tea.PasteMsg{Content: content})
+	m = newModel.(Model)
+	first := m.paste
+	newModel, _ = m.Update(tea.PasteMsg{Content: "echo later"})
+	m = newModel.(Model)
+	if m.paste != first || pty.Len() != 0 {
+		t.Fatal("Expected the second paste to be refused while the first is written")
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Still pasting") {
+		t.Fatalf("Expected a notice, got %q", msg)
+	}
+}
+
+func TestSanitizePaste(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		cfg     config.PasteTargetConfig
+		want    string
+	}{
+		{"crlf collapsed", "make\r\nmake test\r\n", config.PasteTargetConfig{}, "make\nmake test\n"},
+		{"crlf kept", "a\r\nb", config.PasteTargetConfig{KeepCRLF: true}, "a\r\nb"},
+		{"trailing newlines stripped", "ls -la\n\n", config.PasteTargetConfig{StripTrailingNewlines: true}, "ls -la"},
+		{"trailing crlf stripped", "ls\r\n", config.PasteTargetConfig{StripTrailingNewlines: true, KeepCRLF: true}, "ls"},
+		{"inner newlines kept", "a\nb\n", config.PasteTargetConfig{StripTrailingNewlines: true}, "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizePaste(tt.content, tt.cfg); got != tt.want {
+				t.Errorf("sanitizePaste(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_PasteSanitizedPerTarget(t *testing.T) {
+	m, pty := newPasteTestModel(t, 0)
+	m.pasteTerminal = config.PasteTargetConfig{StripTrailingNewlines: true}
+
+	newModel, _ := m.Update(tea.PasteMsg{Content: "rm -rf build\r\n"})
+	m = newModel.(Model)
+	if got := pty.String(); got != "rm -rf build" {
+		t.Fatalf("Expected the command without its newline, got %q", got)
+	}
+
+	m.ensureSidebar().Show()
+	m.setTerminalFocused(false)
+	m.sidebar.FocusInput()
+	newModel, _ = m.Update(tea.PasteMsg{Content: "line one\r\nline two\r\n"})
+	m = newModel.(Model)
+	content, _ := m.sidebar.SubmitMessage()
+	if content != "line one\nline two" {
+		t.Fatalf("Expected CRLF collapsed in the chat input, got %q", content)
+	}
+}
+
+func TestModel_MiddleClickPastesPrimarySelection(t *testing.T) {
+	m, pty := newPasteTestModel(t, 0)
+	m.primarySelection = func() (string, bool) { return "git status", true }
+
+	newModel, cmd := m.Update(tea.MouseClickMsg(tea.Mouse{X: 5, Y: 5, Button: tea.MouseMiddle}))
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the primary selection to be read")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+	if got := pty.String(); got != "git status" {
+		t.Fatalf("Expected the primary selection pasted, got %q", got)
+	}
+
+	// Without a helper the terminal is asked over OSC 52; its answer for
+	// the primary selection is pasted, one for the clipboard is not.
+	m.primarySelection = func() (string, bool) { return "", false }
+	_, cmd = m.Update(tea.MouseClickMsg(tea.Mouse{X: 5, Y: 5, Button: tea.MouseMiddle}))
+	if _, ok := cmd().(primarySelectionMsg); ok {
+		t.Fatal("Expected an OSC 52 read without a helper")
+	}
+	pty.Reset()
+	newModel, _ = m.Update(tea.ClipboardMsg{Content: "whoami", Selection: 'c'})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.ClipboardMsg{Content: "uptime", Selection: 'p'})
+	m = newModel.(Model)
+	if got := pty.String(); got != "uptime" {
+		t.Fatalf("Expected only the primary selection pasted, got %q", got)
+	}
+}
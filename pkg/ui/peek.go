@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"wtf_cli/pkg/ui/components/utils"
+	"wtf_cli/pkg/ui/terminal"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	// peekFieldKey routes option picker selections made from /peek.
+	peekFieldKey = "peek"
+	// rawLogLines is how many recent lines keep the PTY bytes they were
+	// made from.
+	rawLogLines = 200
+	// peekLabelWidth caps a line shown in the /peek picker.
+	peekLabelWidth = 72
+)
+
+// rawLine is the PTY output a buffer line was normalized from.
+type rawLine struct {
+	index int // Buffer mark (Total) of the line
+	text  string
+	raw   []byte
+}
+
+// rawLineLog keeps the raw bytes of a pane's most recent lines so capture
+// bugs can be reported with the output that caused them.
+type rawLineLog struct {
+	lines []rawLine
+}
+
+func (l *rawLineLog) add(index int, text, raw []byte) {
+	if l == nil {
+		return
+	}
+	if len(l.lines) >= rawLogLines {
+		l.lines = append(l.lines[:0], l.lines[len(l.lines)-rawLogLines+1:]...)
+	}
+	l.lines = append(l.lines, rawLine{index: index, text: string(text), raw: raw})
+}
+
+// peekOptionLabel renders a line as "#1204  make: *** [all] Error 2".
+func peekOptionLabel(line rawLine) string {
+	return fmt.Sprintf("#%d  %s", line.index+1, utils.TruncateToWidth(line.text, peekLabelWidth))
+}
+
+// openPeekPicker lists the recent lines of the focused pane, newest first,
+// to show one as it came from the shell.
+func (m Model) openPeekPicker() (Model, tea.Cmd) {
+	if m.rawLines == nil || len(m.rawLines.lines) == 0 {
+		return m, m.flashStatus("No terminal output to peek at yet")
+	}
+	options := make([]string, 0, len(m.rawLines.lines))
+	for i := len(m.rawLines.lines) - 1; i >= 0; i-- {
+		options = append(options, peekOptionLabel(m.rawLines.lines[i]))
+	}
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Peek at the raw output of a line", peekFieldKey, options, "")
+	return m, nil
+}
+
+// showRawLine opens the line picked in /peek in the result panel.
+func (m Model) showRawLine(choice string) (Model, tea.Cmd) {
+	if m.rawLines == nil {
+		return m, nil
+	}
+	for _, line := range m.rawLines.lines {
+		if peekOptionLabel(line) != choice {
+			continue
+		}
+		slog.Info("peek_open", "line", line.index+1, "raw_bytes", len(line.raw))
+		m.resultPanel.Show(fmt.Sprintf("Raw line #%d", line.index+1), formatRawLine(line))
+		return m, nil
+	}
+	return m, nil
+}
+
+// formatRawLine shows a line as normalized, as escaped raw bytes and as a
+// hex dump, ready to paste into a bug report.
+func formatRawLine(line rawLine) string {
+	var b strings.Builder
+	b.WriteString("Normalized:\n")
+	b.WriteString(strconv.Quote(line.text))
+	b.WriteString("\n\nRaw")
+	if len(line.raw) >= terminal.MaxRawLineBytes {
+		b.WriteString(" (end only)")
+	}
+	fmt.Fprintf(&b, ", %d bytes:\n", len(line.raw))
+	b.WriteString(strconv.Quote(string(line.raw)))
+	b.WriteString("\n\nHex:\n")
+	b.WriteString(strings.TrimRight(hex.Dump(line.raw), "\n"))
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+)
+
+func TestPeek_ShowsRawBytesOfPickedLine(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.appendNormalizedLines([]byte("one\r\n\x1b[31mtwo\x1b[0m\r\n"))
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/peek"})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the line picker to be shown")
+	}
+
+	newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: peekFieldKey, Value: "#2  two"})
+	m = newModel.(Model)
+	if !m.resultPanel.IsVisible() || m.resultPanel.Title() != "Raw line #2" {
+		t.Fatalf("Expected the raw line panel, got %q", m.resultPanel.Title())
+	}
+	content := formatRawLine(m.rawLines.lines[1])
+	for _, want := range []string{`"two"`, `"\x1b[31mtwo\x1b[0m\r\n"`, "1b 5b 33 31 6d"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in:\n%s", want, content)
+		}
+	}
+}
+
+func TestRawLineLog_KeepsRecentLines(t *testing.T) {
+	log := &rawLineLog{}
+	for i := 0; i < rawLogLines+5; i++ {
+		log.add(i, []byte("x"), []byte("x\n"))
+	}
+	if len(log.lines) != rawLogLines {
+		t.Fatalf("Expected %d lines, got %d", rawLogLines, len(log.lines))
+	}
+	if log.lines[0].index != 5 || log.lines[rawLogLines-1].index != rawLogLines+4 {
+		t.Fatalf("Expected the newest lines, got %d..%d", log.lines[0].index, log.lines[rawLogLines-1].index)
+	}
+}
+
+func TestPeek_NoOutputYet(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/peek"})
+	m = newModel.(Model)
+	if m.optionPicker != nil && m.optionPicker.IsVisible() {
+		t.Fatal("Expected no picker without output")
+	}
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/historypicker"
+	"wtf_cli/pkg/ui/components/utils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// pinSelectionKey pins the last terminal selection to the AI context. It is
+// only claimed while a selection is waiting to be pinned.
+const pinSelectionKey = "alt+p"
+
+// unpinFieldKey routes option picker selections made from /unpin.
+const unpinFieldKey = "unpin_command"
+
+// unpinAll is the /unpin entry that removes every pinned block.
+const unpinAll = "Unpin all"
+
+// pinSelectionLabelWidth caps the first line of a selection used as its label.
+const pinSelectionLabelWidth = 24
+
+// pinBlock adds block to the output sent with every AI request this session.
+func (m *Model) pinBlock(block ai.PinnedBlock) tea.Cmd {
+	if block.Content == "" {
+		return m.flashStatus("Nothing to pin")
+	}
+	for _, pinned := range m.pinnedBlocks {
+		if pinned.Content == block.Content {
+			return m.flashStatus("Already pinned")
+		}
+	}
+	if len(m.pinnedBlocks) >= ai.MaxPinnedBlocks {
+		return m.flashStatus(fmt.Sprintf("At most %d blocks can be pinned; use /unpin first", ai.MaxPinnedBlocks))
+	}
+	m.pinnedBlocks = append(m.pinnedBlocks, block)
+	m.syncPinnedChips()
+	slog.Info("context_pin", "label", block.Label, "bytes", len(block.Content), "tokens", block.Tokens(), "pinned", len(m.pinnedBlocks))
+	return m.flashStatus(fmt.Sprintf("Pinned %q (~%d tokens) to every AI request", block.Label, block.Tokens()))
+}
+
+// pinLastSelection pins the text last selected in the terminal.
+func (m Model) pinLastSelection() (Model, tea.Cmd) {
+	text := m.lastSelection
+	m.lastSelection = ""
+	label := "selection"
+	if first, _, _ := strings.Cut(strings.TrimSpace(text), "\n"); first != "" {
+		label = utils.TruncateToWidth(strings.TrimSpace(first), pinSelectionLabelWidth)
+	}
+	var lines [][]byte
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, []byte(line))
+	}
+	return m, m.pinBlock(ai.NewPinnedBlock(label, lines))
+}
+
+// handleHistoryPickerPin pins the output of the latest run of msg.Command.
+// Output still in the buffer is pinned in full; older runs fall back to the
+// snapshot of their first lines.
+func (m Model) handleHistoryPickerPin(msg historypicker.HistoryPickerPinMsg) (Model, tea.Cmd) {
+	m.inputHandler.SetHistoryPickerMode(false)
+	command := strings.TrimSpace(msg.Command)
+	rec, ok := m.lastRunOf(command)
+	if !ok {
+		return m, m.flashStatus("No output captured for " + command)
+	}
+	return m, m.pinBlock(ai.NewPinnedBlock(command, m.commandOutput(rec)))
+}
+
+// lastRunOf returns the most recent session record of command.
+func (m *Model) lastRunOf(command string) (capture.CommandRecord, bool) {
+	if m.session == nil {
+		return capture.CommandRecord{}, false
+	}
+	history := m.session.GetHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		if strings.TrimSpace(history[i].Command) == command {
+			return history[i], true
+		}
+	}
+	return capture.CommandRecord{}, false
+}
+
+// commandOutput returns the output lines of rec, skipping the echoed prompt
+// line unless the shell marked where the output starts.
+func (m *Model) commandOutput(rec capture.CommandRecord) [][]byte {
+	if m.buffer != nil {
+		end := rec.BufferEnd
+		if end == 0 {
+			end = m.buffer.Total()
+		}
+		oldest := m.buffer.Total() - m.buffer.Size()
+		if rec.BufferStart >= oldest {
+			lines := m.buffer.LinesBetween(rec.BufferStart, end)
+			if !rec.Marked && len(lines) > 0 && strings.Contains(string(lines[0]), strings.TrimSpace(rec.Command)) {
+				lines = lines[1:]
+			}
+			return lines
+		}
+	}
+	lines := make([][]byte, 0, len(rec.Output))
+	for _, line := range rec.Output {
+		lines = append(lines, []byte(line))
+	}
+	return lines
+}
+
+// openUnpinPicker lists the pinned blocks so one, or all, can be removed.
+func (m Model) openUnpinPicker() (Model, tea.Cmd) {
+	if len(m.pinnedBlocks) == 0 {
+		return m, m.flashStatus("Nothing is pinned; select terminal output or press Ctrl+P in Ctrl+R history to pin it")
+	}
+	options := make([]string, 0, len(m.pinnedBlocks)+1)
+	for i, b := range m.pinnedBlocks {
+		options = append(options, pinOptionLabel(i, b))
+	}
+	options = append(options, unpinAll)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Unpin output", unpinFieldKey, options, "")
+	return m, nil
+}
+
+// pinOptionLabel renders a pinned block as "1. make test · ~120 tokens".
+func pinOptionLabel(i int, b ai.PinnedBlock) string {
+	return fmt.Sprintf("%d. %s · ~%d tokens", i+1, b.Label, b.Tokens())
+}
+
+// unpin removes the pinned block picked in /unpin.
+func (m Model) unpin(choice string) (Model, tea.Cmd) {
+	if choice == unpinAll {
+		slog.Info("context_unpin", "all", true, "pinned", len(m.pinnedBlocks))
+		m.pinnedBlocks = nil
+		m.syncPinnedChips()
+		return m, m.flashStatus("Unpinned all output")
+	}
+	for i, b := range m.pinnedBlocks {
+		if pinOptionLabel(i, b) != choice {
+			continue
+		}
+		m.pinnedBlocks = append(m.pinnedBlocks[:i:i], m.pinnedBlocks[i+1:]...)
+		m.syncPinnedChips()
+		slog.Info("context_unpin", "label", b.Label, "pinned", len(m.pinnedBlocks))
+		return m, m.flashStatus(fmt.Sprintf("Unpinned %q", b.Label))
+	}
+	return m, nil
+}
+
+// syncPinnedChips shows the pinned blocks above the sidebar input.
+func (m *Model) syncPinnedChips() {
+	if m.sidebar != nil {
+		m.sidebar.SetPinned(m.pinnedBlocks)
+	}
+}
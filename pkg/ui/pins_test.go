@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/historypicker"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+var altPKey = tea.KeyPressMsg{Code: 'p', Mod: tea.ModAlt}
+
+func TestModel_PinsTerminalSelection(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 40, Height: 6})
+	m = newModel.(Model)
+	m.viewport.Clear()
+	m.viewport.SetCursorVisible(false)
+	m.viewport.AppendOutput([]byte("panic: nil map\ngoroutine 1"))
+
+	newModel, _ = m.Update(tea.MouseClickMsg(tea.Mouse{X: 0, Y: 0, Button: tea.MouseLeft}))
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.MouseReleaseMsg(tea.Mouse{X: 11, Y: 1, Button: tea.MouseLeft}))
+	m = newModel.(Model)
+	if m.lastSelection == "" {
+		t.Fatal("expected the terminal selection to be kept for pinning")
+	}
+
+	newModel, _ = m.Update(altPKey)
+	m = newModel.(Model)
+	if len(m.pinnedBlocks) != 1 {
+		t.Fatalf("expected one pinned block, got %d", len(m.pinnedBlocks))
+	}
+	block := m.pinnedBlocks[0]
+	if block.Label != "panic: nil map" || block.Content != "panic: nil map\ngoroutine 1" {
+		t.Fatalf("pinned block = %+v", block)
+	}
+	if m.lastSelection != "" {
+		t.Fatal("expected the selection to be consumed")
+	}
+	if got := m.commandContext().Pinned; len(got) != 1 || got[0] != block {
+		t.Fatalf("commandContext().Pinned = %+v, want the pinned block", got)
+	}
+	if !strings.Contains(m.statusBar.GetMessage(), "Pinned \"panic: nil map\"") {
+		t.Fatalf("status = %q", m.statusBar.GetMessage())
+	}
+
+	// Pinning the same text again is refused.
+	m.lastSelection = "panic: nil map\ngoroutine 1"
+	newModel, _ = m.Update(altPKey)
+	m = newModel.(Model)
+	if len(m.pinnedBlocks) != 1 || m.statusBar.GetMessage() != "Already pinned" {
+		t.Fatalf("expected duplicate pin to be refused, got %d blocks and status %q", len(m.pinnedBlocks), m.statusBar.GetMessage())
+	}
+}
+
+func TestModel_PinsHistoryCommandOutputAndUnpins(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "go test ./..."})
+	m = newModel.(Model)
+	m.buffer.Write([]byte("$ go test ./..."))
+	m.buffer.Write([]byte("--- FAIL: TestParse"))
+	m.buffer.Write([]byte("FAIL\twtf_cli/pkg/ai"))
+	newModel, _ = m.Update(input.CommandSubmittedMsg{Command: "ls"})
+	m = newModel.(Model)
+	m.buffer.Write([]byte("$ ls"))
+	m.buffer.Write([]byte("go.mod"))
+
+	newModel, _ = m.Update(historypicker.HistoryPickerPinMsg{Command: "go test ./..."})
+	m = newModel.(Model)
+	if len(m.pinnedBlocks) != 1 {
+		t.Fatalf("expected one pinned block, got %d", len(m.pinnedBlocks))
+	}
+	want := ai.PinnedBlock{Label: "go test ./...", Content: "--- FAIL: TestParse\nFAIL\twtf_cli/pkg/ai"}
+	if m.pinnedBlocks[0] != want {
+		t.Fatalf("pinned block = %+v, want %+v", m.pinnedBlocks[0], want)
+	}
+
+	m, _ = m.openUnpinPicker()
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected /unpin to open the option picker")
+	}
+	m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: unpinFieldKey, Value: pinOptionLabel(0, want)})
+	if len(m.pinnedBlocks) != 0 {
+		t.Fatalf("expected the block to be unpinned, got %+v", m.pinnedBlocks)
+	}
+}
+
+func TestModel_PinLimit(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	for i := range ai.MaxPinnedBlocks + 1 {
+		m.pinBlock(ai.PinnedBlock{Label: "block", Content: strings.Repeat("x", i+1)})
+	}
+	if len(m.pinnedBlocks) != ai.MaxPinnedBlocks {
+		t.Fatalf("expected at most %d pinned blocks, got %d", ai.MaxPinnedBlocks, len(m.pinnedBlocks))
+	}
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// prewarmCmd opens the connection to cfg's provider when prewarm is
+// "startup", so the first answer does not wait for connection setup. It
+// returns nil otherwise.
+func prewarmCmd(cfg config.Config) tea.Cmd {
+	if strings.TrimSpace(cfg.Prewarm) != config.PrewarmStartup {
+		return nil
+	}
+	url := ai.PrewarmURL(cfg)
+	if url == "" {
+		return nil
+	}
+	transport := ai.ActiveTransport(cfg)
+	return func() tea.Msg {
+		ai.DefaultPrewarmer.Start(url, transport)
+		return nil
+	}
+}
+
+// sidebarPrewarmURL returns the endpoint to warm when the sidebar opens, or
+// "" unless prewarm is "sidebar".
+func sidebarPrewarmURL(cfg config.Config) string {
+	if strings.TrimSpace(cfg.Prewarm) != config.PrewarmSidebar {
+		return ""
+	}
+	return ai.PrewarmURL(cfg)
+}
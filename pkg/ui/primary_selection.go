@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// primarySelectionTimeout bounds a clipboard helper reading the primary
+// selection.
+const primarySelectionTimeout = 2 * time.Second
+
+// primarySelectionMsg carries the primary selection read by a clipboard
+// helper.
+type primarySelectionMsg struct {
+	content string
+}
+
+// primarySelectionHelpers are the commands tried, in order, to read the
+// primary selection, each with the variable that says its display server
+// is there.
+var primarySelectionHelpers = []struct {
+	env  string
+	argv []string
+}{
+	{"WAYLAND_DISPLAY", []string{"wl-paste", "--primary", "--no-newline"}},
+	{"DISPLAY", []string{"xclip", "-o", "-selection", "primary"}},
+	{"DISPLAY", []string{"xsel", "--primary", "--output"}},
+}
+
+// readPrimarySelection reads the X11/Wayland primary selection with the
+// first clipboard helper installed for the running display server. ok is
+// false when there is none or it failed.
+func readPrimarySelection() (content string, ok bool) {
+	for _, helper := range primarySelectionHelpers {
+		if os.Getenv(helper.env) == "" {
+			continue
+		}
+		path, err := exec.LookPath(helper.argv[0])
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), primarySelectionTimeout)
+		out, err := exec.CommandContext(ctx, path, helper.argv[1:]...).Output()
+		cancel()
+		if err != nil {
+			slog.Debug("primary_selection_helper_error", "helper", helper.argv[0], "error", err)
+			continue
+		}
+		return string(out), true
+	}
+	return "", false
+}
+
+// pastePrimarySelection pastes the primary selection, for a middle click:
+// the mouse reporting wtf_cli turns on keeps the terminal from pasting it.
+// Without a clipboard helper the terminal is asked over OSC 52, which
+// answers with a tea.ClipboardMsg where it is supported.
+func (m Model) pastePrimarySelection() tea.Cmd {
+	read := m.primarySelection
+	return func() tea.Msg {
+		if content, ok := read(); ok {
+			return primarySelectionMsg{content: content}
+		}
+		return tea.ReadPrimaryClipboard()
+	}
+}
+
+// handleClipboard pastes the primary selection the terminal sent back.
+// Reads of the system clipboard are not asked for and are dropped.
+func (m Model) handleClipboard(msg tea.ClipboardMsg) (Model, tea.Cmd) {
+	if msg.Clipboard() != 'p' {
+		return m, nil
+	}
+	slog.Debug("primary_selection_paste", "source", "osc52", "len", len(msg.Content))
+	return m.handlePaste(tea.PasteMsg{Content: msg.Content})
+}
+
+// handlePrimarySelection pastes the primary selection a helper read.
+func (m Model) handlePrimarySelection(msg primarySelectionMsg) (Model, tea.Cmd) {
+	slog.Debug("primary_selection_paste", "source", "helper", "len", len(msg.content))
+	return m.handlePaste(tea.PasteMsg{Content: msg.content})
+}
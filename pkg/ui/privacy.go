@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// handlePrivacyCommand switches privacy mode: "on", "off", or toggles it
+// when args is empty.
+func (m Model) handlePrivacyCommand(args string) (Model, tea.Cmd) {
+	on := !m.privacy
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+	case "on":
+		on = true
+	case "off":
+		on = false
+	default:
+		return m, m.flashStatus("Usage: /privacy [on|off]")
+	}
+	m.setPrivacy(on)
+	if on {
+		return m, m.flashStatus("Privacy mode on: output and commands are not captured or sent to the AI")
+	}
+	return m, m.flashStatus("Privacy mode off: capturing again")
+}
+
+// setPrivacy turns privacy mode on or off. What was captured before it was
+// turned on is kept.
+func (m *Model) setPrivacy(on bool) {
+	if m.privacy != on {
+		slog.Info("privacy_mode", "on", on)
+	}
+	m.privacy = on
+	m.statusBar.SetPrivacy(on)
+}
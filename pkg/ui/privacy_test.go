@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/input"
+)
+
+func TestModel_PrivacyStopsCapture(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.appendNormalizedLines([]byte("before\r\n"))
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/privacy"})
+	if !m.privacy {
+		t.Fatal("expected /privacy to turn privacy mode on")
+	}
+	if bar := m.statusBar.Render(); !strings.Contains(bar, "[wtf_cli 🔒]") {
+		t.Fatalf("expected a lock in the status bar, got %q", bar)
+	}
+
+	m.appendNormalizedLines([]byte("export TOKEN=secret\r\n"))
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "vault read secret/db"})
+	m = newModel.(Model)
+	if got := m.buffer.Total(); got != 1 {
+		t.Fatalf("buffer has %d lines, want only the one from before", got)
+	}
+	if got := len(m.session.GetHistory()); got != 0 {
+		t.Fatalf("session recorded %d commands in privacy mode", got)
+	}
+	ctx := m.commandContext()
+	if ctx.Buffer != nil || ctx.Session != nil || ctx.Visible != nil {
+		t.Fatalf("expected no terminal context in privacy mode, got %+v", ctx)
+	}
+
+	m, _ = m.handlePrivacyCommand("off")
+	m.appendNormalizedLines([]byte("after\r\n"))
+	if m.privacy || m.buffer.Total() != 2 {
+		t.Fatalf("expected capture to resume, privacy=%v lines=%d", m.privacy, m.buffer.Total())
+	}
+	if bar := m.statusBar.Render(); strings.Contains(bar, "🔒") {
+		t.Fatalf("expected the lock gone, got %q", bar)
+	}
+}
+
+func TestModel_PrivacyFromConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.Privacy.DisableCapture = true
+	m := NewModelWithConfig(cfg, nil, buffer.New(100), capture.NewSessionContext(), nil)
+	if !m.privacy {
+		t.Fatal("expected privacy.disable_capture to start in privacy mode")
+	}
+
+	m, _ = m.handlePrivacyCommand("maybe")
+	if !m.privacy || !strings.Contains(m.statusBar.GetMessage(), "Usage: /privacy") {
+		t.Fatalf("expected usage for an unknown argument, got %q", m.statusBar.GetMessage())
+	}
+}
+
+func TestModel_PrivacyHidesOutputFromTriggersAndHints(t *testing.T) {
+	m, _ := newInstallHintTestModel(t, "htop")
+	m, _ = m.handleTriggerCommand("add FAILED notify")
+	m, _ = m.handlePrivacyCommand("on")
+
+	m.appendNormalizedLines([]byte("--- FAILED: TestA\r\nbash: htop: command not found\r\n"))
+	m.fireTriggers()
+	if len(m.triggerHits) != 0 || !m.triggers[0].fired.IsZero() {
+		t.Fatalf("expected no trigger hit in privacy mode, got %d", len(m.triggerHits))
+	}
+	if m.installHint != nil {
+		t.Fatal("expected no install hint in privacy mode")
+	}
+	if got := m.statusBar.GetMessage(); strings.Contains(got, "FAILED") || strings.Contains(got, "htop") {
+		t.Fatalf("expected no notification in privacy mode, got %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	// asyncTimeout bounds every background operation started with runAsync.
+	asyncTimeout = 20 * time.Second
+	// progressTickInterval is how often the status bar spinner advances.
+	progressTickInterval = 100 * time.Millisecond
+	// streamStartLabel is shown until a /explain or chat stream opens.
+	streamStartLabel = "Waiting for the model"
+)
+
+// asyncFunc is a background operation. It should give up when ctx is done.
+type asyncFunc func(ctx context.Context) tea.Msg
+
+// asyncDoneMsg carries the result of an operation started with runAsync.
+// msg is dispatched unless the user cancelled the operation meanwhile.
+type asyncDoneMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// progressTickMsg advances the status bar spinner.
+type progressTickMsg struct{}
+
+// runAsync runs run in the background with the spinner and label shown in
+// the status bar until it returns. Esc cancels it: its context is cancelled
+// and its result dropped. A nil run returns a nil command.
+func (m *Model) runAsync(label string, run asyncFunc) tea.Cmd {
+	if run == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), asyncTimeout)
+	id := m.progress.Start(label, cancel)
+	slog.Debug("async_start", "id", id, "label", label)
+	return tea.Batch(m.startProgressTicking(), func() tea.Msg {
+		defer cancel()
+		return asyncDoneMsg{id: id, msg: run(ctx)}
+	})
+}
+
+func (m Model) handleAsyncDone(msg asyncDoneMsg) (Model, tea.Cmd) {
+	if !m.progress.Finish(msg.id) {
+		slog.Debug("async_result_dropped", "id", msg.id)
+		return m, nil
+	}
+	m.syncProgress()
+	if msg.msg == nil {
+		return m, nil
+	}
+	next, cmd := m.Update(msg.msg)
+	return next.(Model), cmd
+}
+
+// cancelProgress cancels the newest pending operation.
+func (m Model) cancelProgress() (Model, tea.Cmd) {
+	op, ok := m.progress.CancelLatest()
+	if !ok {
+		return m, nil
+	}
+	slog.Info("async_cancel", "id", op.ID, "label", op.Label)
+	m.syncProgress()
+	return m, m.flashStatus("Cancelled: " + op.Label)
+}
+
+// startProgressTicking shows the pending operations and starts the spinner
+// if it is not already ticking.
+func (m *Model) startProgressTicking() tea.Cmd {
+	m.syncProgress()
+	if m.progressTicking || !m.progress.Active() {
+		return nil
+	}
+	m.progressTicking = true
+	return progressTick()
+}
+
+func progressTick() tea.Cmd {
+	return tea.Tick(progressTickInterval, func(time.Time) tea.Msg {
+		return progressTickMsg{}
+	})
+}
+
+func (m Model) handleProgressTick() (Model, tea.Cmd) {
+	if !m.progress.Active() {
+		m.progressTicking = false
+		m.syncProgress()
+		return m, nil
+	}
+	m.progress.Advance()
+	m.syncProgress()
+	return m, progressTick()
+}
+
+func (m *Model) syncProgress() {
+	if m.statusBar != nil {
+		m.statusBar.SetProgress(m.progress.View())
+	}
+}
+
+// startStreamProgress shows streamStartLabel until the stream opens. Esc
+// reaches cancelActiveStream first, so the stream keeps its own cancel.
+func (m *Model) startStreamProgress() tea.Cmd {
+	m.finishStreamProgress()
+	m.streamProgressID = m.progress.Start(streamStartLabel, nil)
+	return m.startProgressTicking()
+}
+
+func (m *Model) finishStreamProgress() {
+	if m.streamProgressID == 0 {
+		return
+	}
+	m.progress.Finish(m.streamProgressID)
+	m.streamProgressID = 0
+	m.syncProgress()
+}
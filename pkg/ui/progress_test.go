@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newProgressTestModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return newModel.(Model)
+}
+
+func TestModel_RunAsyncShowsProgressUntilDone(t *testing.T) {
+	m := newProgressTestModel(t)
+	m.ensureModelPicker().Show(nil, "", "openai_model")
+
+	cmd := m.runAsync(fetchModelsLabel, func(context.Context) tea.Msg {
+		return modelListMsg{FieldKey: "openai_model", Provider: ai.ProviderOpenAI, Models: []ai.ModelInfo{{ID: "gpt-fetched"}}}
+	})
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+	if !strings.Contains(m.statusBar.Render(), fetchModelsLabel) {
+		t.Fatalf("Expected the operation in the status bar, got %q", m.statusBar.Render())
+	}
+
+	newModel, _ := m.Update(asyncDoneMsg{id: 1, msg: modelListMsg{FieldKey: "openai_model", Provider: ai.ProviderOpenAI, Models: []ai.ModelInfo{{ID: "gpt-fetched"}}}})
+	m = newModel.(Model)
+	if m.progress.Active() || strings.Contains(m.statusBar.Render(), fetchModelsLabel) {
+		t.Fatal("Expected the operation to be cleared")
+	}
+	if !strings.Contains(m.modelPicker.View(), "gpt-fetched") {
+		t.Fatalf("Expected the result dispatched to the picker, got:\n%s", m.modelPicker.View())
+	}
+}
+
+func TestModel_EscCancelsAsyncOperation(t *testing.T) {
+	m := newProgressTestModel(t)
+
+	m.runAsync(copilotAuthLabel, func(context.Context) tea.Msg { return nil })
+	if !m.progress.Active() {
+		t.Fatal("Expected a pending operation")
+	}
+
+	newModel, _ := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = newModel.(Model)
+	if m.progress.Active() {
+		t.Fatal("Expected Esc to cancel the operation")
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Cancelled: "+copilotAuthLabel) {
+		t.Fatalf("Expected a cancel notice, got %q", msg)
+	}
+
+	// A result arriving after the cancel is dropped.
+	m.ensureModelPicker().Show(nil, "", "openai_model")
+	newModel, _ = m.Update(asyncDoneMsg{id: 1, msg: modelListMsg{FieldKey: "openai_model", Provider: ai.ProviderOpenAI, Models: []ai.ModelInfo{{ID: "late"}}}})
+	m = newModel.(Model)
+	if strings.Contains(m.modelPicker.View(), "late") {
+		t.Fatal("Expected the cancelled result to be dropped")
+	}
+}
+
+func TestModel_StreamStartShowsProgress(t *testing.T) {
+	m := newProgressTestModel(t)
+
+	m.beginStreamRun()
+	m.startStreamProgress()
+	if !strings.Contains(m.statusBar.Render(), streamStartLabel) {
+		t.Fatalf("Expected %q in the status bar, got %q", streamStartLabel, m.statusBar.Render())
+	}
+
+	newModel, _ := m.Update(streamStartResultMsg{streamID: m.aiJobs.id, origin: streamOriginChat})
+	m = newModel.(Model)
+	if m.progress.Active() {
+		t.Fatal("Expected the progress cleared once the stream started")
+	}
+}
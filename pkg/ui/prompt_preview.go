@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"log/slog"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/promptpreview"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// chatPreviewMsg carries the request assembled for the prompt preview.
+type chatPreviewMsg struct {
+	preview commands.ChatPreview
+	err     error
+}
+
+// previewChatRun assembles the request answering the conversation in the
+// sidebar without sending it. Loading config and collecting metadata stays
+// off the UI goroutine, like starting a stream.
+func (m Model) previewChatRun() tea.Cmd {
+	ctx := m.commandContext()
+	history := append([]ai.ChatMessage(nil), m.sidebar.GetMessages()...)
+	handler := m.chatHandler()
+	return func() tea.Msg {
+		preview, err := handler.PreviewChat(ctx, history)
+		return chatPreviewMsg{preview: preview, err: err}
+	}
+}
+
+func (m Model) handleChatPreview(msg chatPreviewMsg) (Model, tea.Cmd) {
+	if m.sidebar == nil || m.hasActiveStream() {
+		return m, nil
+	}
+	if msg.err != nil {
+		slog.Error("prompt_preview_error", "error", msg.err)
+		m.sidebar.AppendErrorMessage(msg.err.Error())
+		m.refreshSidebar()
+		return m, nil
+	}
+	slog.Info("prompt_preview_show", "model", msg.preview.Model, "history", len(msg.preview.History))
+	m.promptPreview.SetSize(m.width, m.height)
+	m.promptPreview.Show(msg.preview)
+	return m, nil
+}
+
+// handlePromptPreviewSend starts the run with the terminal context exactly
+// as previewed, so output arriving meanwhile does not change what is sent.
+func (m Model) handlePromptPreviewSend(msg promptpreview.SendMsg) (Model, tea.Cmd) {
+	if m.sidebar == nil || m.hasActiveStream() {
+		return m, nil
+	}
+	slog.Info("prompt_preview_send", "edited", msg.Edited)
+	ctx := m.commandContext()
+	terminal := msg.TerminalContext
+	ctx.TerminalContextOverride = &terminal
+	return m.startChatRunWithContext(ctx)
+}
+
+// handlePromptPreviewCancel takes the unsent message back out of the
+// conversation and returns it to the input.
+func (m Model) handlePromptPreviewCancel() (Model, tea.Cmd) {
+	if m.sidebar == nil {
+		return m, nil
+	}
+	slog.Info("prompt_preview_cancel")
+	messages := m.sidebar.GetMessages()
+	if n := len(messages); n > 0 && messages[n-1].Role == "user" {
+		m.sidebar.SetInput(messages[n-1].Content)
+		m.sidebar.RemoveLastMessage()
+		m.refreshSidebar()
+	}
+	m.sidebar.FocusInput()
+	return m, nil
+}
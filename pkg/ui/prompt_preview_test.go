@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/promptpreview"
+	"wtf_cli/pkg/ui/components/sidebar"
+)
+
+func newPreviewTestModel(t *testing.T) Model {
+	t.Helper()
+	m := newTrustTestModel(t)
+	trustWorkspace(t, &m, ai.TrustRestricted)
+	m.ensureSidebar().Show()
+	return m
+}
+
+func TestModel_PreviewedChatWaitsForSend(t *testing.T) {
+	m := newPreviewTestModel(t)
+
+	newModel, cmd := m.Update(sidebar.ChatSubmitMsg{Content: "why did make fail?", Preview: true})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("expected the request to be assembled")
+	}
+	if m.hasActiveStream() {
+		t.Fatal("expected no stream before the preview is sent")
+	}
+
+	preview := commands.ChatPreview{
+		Model:           "test-model",
+		TerminalContext: "make: *** [test] Error 1",
+		History:         []ai.Message{{Role: "user", Content: "why did make fail?"}},
+	}
+	newModel, _ = m.Update(chatPreviewMsg{preview: preview})
+	m = newModel.(Model)
+	if !m.promptPreview.IsVisible() {
+		t.Fatal("expected the prompt preview")
+	}
+
+	newModel, _ = m.Update(promptpreview.SendMsg{TerminalContext: "edited", Edited: true})
+	m = newModel.(Model)
+	if !m.hasActiveStream() {
+		t.Fatal("expected Send to start the chat run")
+	}
+}
+
+func TestModel_PreviewCancelReturnsMessage(t *testing.T) {
+	m := newPreviewTestModel(t)
+
+	newModel, _ := m.Update(sidebar.ChatSubmitMsg{Content: "why did make fail?", Preview: true})
+	m = newModel.(Model)
+	newModel, _ = m.Update(promptpreview.CancelMsg{})
+	m = newModel.(Model)
+
+	if msgs := m.sidebar.GetMessages(); len(msgs) != 0 {
+		t.Fatalf("expected the unsent message out of the chat, got %+v", msgs)
+	}
+	if content, ok := m.sidebar.SubmitMessage(); !ok || content != "why did make fail?" {
+		t.Fatalf("expected the message back in the input, got %q", content)
+	}
+}
+
+func TestModel_PreviewErrorShownInChat(t *testing.T) {
+	m := newPreviewTestModel(t)
+	m.ensureSidebar().AppendUserMessage("why did make fail?")
+
+	newModel, _ := m.Update(chatPreviewMsg{err: errors.New("no API key configured")})
+	m = newModel.(Model)
+	if m.promptPreview.IsVisible() {
+		t.Fatal("expected no preview on error")
+	}
+	msgs := m.sidebar.GetMessages()
+	if len(msgs) != 2 || msgs[1].Content == "" {
+		t.Fatalf("expected the error in the chat, got %+v", msgs)
+	}
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/terminal"
+)
+
+// commandBlockCopiedMessage is shown when a click on a prompt line copies
+// its command block.
+const commandBlockCopiedMessage = "Command block copied to clipboard · Alt+P pins it to AI requests"
+
+// promptZones follows the OSC 133 marks of a shell configured to emit them.
+// Once one is seen, commands are taken from the marks instead of guessed
+// from prompt-looking lines.
+type promptZones struct {
+	seen bool
+	// inputCol is where the command starts on the prompt line while
+	// awaitingInput, between the command-start mark and the line's end.
+	inputCol      int
+	awaitingInput bool
+}
+
+// applyPromptMarks handles the marks placed before line index line and
+// returns the rest.
+func (m *Model) applyPromptMarks(marks []terminal.LineMark, line int) []terminal.LineMark {
+	for len(marks) > 0 && marks[0].Line <= line {
+		m.handlePromptMark(marks[0])
+		marks = marks[1:]
+	}
+	return marks
+}
+
+func (m *Model) handlePromptMark(mark terminal.LineMark) {
+	if !m.promptZones.seen {
+		m.promptZones.seen = true
+		slog.Info("prompt_marks_detected")
+	}
+	switch mark.Kind {
+	case terminal.MarkPromptStart:
+		m.promptZones.awaitingInput = false
+	case terminal.MarkCommandStart:
+		m.promptZones.awaitingInput = true
+		m.promptZones.inputCol = mark.Col
+	case terminal.MarkOutputStart:
+		if m.lastCommandRunning() && m.buffer != nil {
+			m.session.SetLastOutputStart(m.buffer.Total())
+		}
+	case terminal.MarkCommandEnd:
+		m.finishMarkedCommand(mark.ExitCode)
+	}
+}
+
+// lastCommandRunning reports whether the latest command was delimited by
+// prompt marks and has not finished yet.
+func (m *Model) lastCommandRunning() bool {
+	if m.session == nil {
+		return false
+	}
+	last := m.session.GetLastN(1)
+	return len(last) > 0 && last[0].Marked && last[0].BufferEnd == 0
+}
+
+// finishMarkedCommand records the exit status and exact output end of the
+// running command.
+func (m *Model) finishMarkedCommand(exitCode int) {
+	if !m.lastCommandRunning() || m.buffer == nil {
+		return
+	}
+	rec := m.session.GetLastN(1)[0]
+	end := m.buffer.Total()
+	m.session.FinishLastCommand(end, exitCode, m.commandOutputPreview(rec, end))
+	m.invalidateGitStatus()
+	m.queueCommandFailedHook(rec, exitCode)
+	slog.Debug("prompt_mark_command_end", "command", rec.Command, "exit_code", exitCode)
+}
+
+// captureMarkedCommand records the command typed on line after a
+// command-start mark. Lines without one are prompt output or command output
+// and are ignored.
+func (m *Model) captureMarkedCommand(line []byte) {
+	if !m.promptZones.awaitingInput {
+		return
+	}
+	m.promptZones.awaitingInput = false
+	col := min(m.promptZones.inputCol, len(line))
+	cmd := strings.TrimSpace(string(line[col:]))
+	if cmd == "" {
+		return
+	}
+
+	// A command left running without an end mark (e.g. the shell was
+	// replaced by exec) still gets its output snapshot.
+	m.snapshotLastCommandOutput()
+	now := time.Now()
+	m.session.AddCommand(capture.CommandRecord{
+		Command:    cmd,
+		ExitCode:   -1,
+		StartTime:  now,
+		EndTime:    now,
+		WorkingDir: m.currentDir,
+		// Output starts after this line; the output-start mark, if the
+		// shell sends one, refines it.
+		BufferStart: m.buffer.Total() + 1,
+		Marked:      true,
+	})
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// markedPrompt is a prompt wrapped in OSC 133 marks, as emitted by shells
+// set up for terminal integration.
+const markedPrompt = "\x1b]133;A\x07dev ❯ \x1b]133;B\x07"
+
+func newPromptZoneTestModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m = newModel.(Model)
+	m.viewport.Clear()
+	return m
+}
+
+func writePTY(m *Model, output string) {
+	m.ptyBatchBuffer = append(m.ptyBatchBuffer, output...)
+	m.flushPTYBatch()
+}
+
+func TestModel_PromptMarksDelimitCommands(t *testing.T) {
+	m := newPromptZoneTestModel(t)
+
+	writePTY(&m, markedPrompt+"make test\r\n\x1b]133;C\x07")
+	writePTY(&m, "make test failed: 3 errors\r\ndetails\r\n\x1b]133;D;2\x07"+markedPrompt)
+
+	history := m.session.GetHistory()
+	if len(history) != 1 {
+		t.Fatalf("Expected one command, got %+v", history)
+	}
+	rec := history[0]
+	if rec.Command != "make test" || !rec.Marked {
+		t.Fatalf("Expected marked record of make test, got %+v", rec)
+	}
+	if rec.ExitCode != 2 {
+		t.Fatalf("Expected exit code 2, got %d", rec.ExitCode)
+	}
+	// The first output line mentions the command; with marks it is kept.
+	got := m.commandOutput(rec)
+	if len(got) != 2 || string(got[0]) != "make test failed: 3 errors" {
+		t.Fatalf("Expected exactly the command's output, got %q", got)
+	}
+}
+
+func TestModel_PromptMarksReplacePromptHeuristic(t *testing.T) {
+	m := newPromptZoneTestModel(t)
+
+	writePTY(&m, markedPrompt+"echo 'cost: $ 5'\r\n\x1b]133;C\x07cost: $ 5\r\n\x1b]133;D;0\x07"+markedPrompt)
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "pwd"})
+	m = newModel.(Model)
+	writePTY(&m, "pwd\r\n\x1b]133;C\x07/tmp\r\n\x1b]133;D\x07"+markedPrompt+"\r\n"+markedPrompt)
+
+	var commands []string
+	for _, rec := range m.session.GetHistory() {
+		commands = append(commands, rec.Command)
+	}
+	if strings.Join(commands, "|") != "echo 'cost: $ 5'|pwd" {
+		t.Fatalf("Expected only the marked commands, got %q", commands)
+	}
+	if last := m.session.GetLastN(1)[0]; last.ExitCode != -1 {
+		t.Fatalf("Expected an unreported exit code to stay unknown, got %d", last.ExitCode)
+	}
+}
+
+func TestModel_ClickOnPromptLineCopiesCommandBlock(t *testing.T) {
+	m := newPromptZoneTestModel(t)
+	writePTY(&m, markedPrompt+"ls\r\n\x1b]133;C\x07a b\r\n\x1b]133;D;0\x07"+markedPrompt)
+
+	newModel, _ := m.Update(tea.MouseClickMsg(tea.Mouse{X: 3, Y: 0, Button: tea.MouseLeft}))
+	m = newModel.(Model)
+	newModel, cmd := m.Update(tea.MouseReleaseMsg(tea.Mouse{X: 3, Y: 0, Button: tea.MouseLeft}))
+	m = newModel.(Model)
+
+	if m.lastSelection != "dev ❯ ls\na b" {
+		t.Fatalf("Expected the ls block selected, got %q", m.lastSelection)
+	}
+	if cmd == nil {
+		t.Fatal("Expected the block copied to the clipboard")
+	}
+	if got := m.statusBar.GetMessage(); got != commandBlockCopiedMessage {
+		t.Fatalf("Expected block copy status, got %q", got)
+	}
+
+	newModel, _ = m.Update(tea.MouseClickMsg(tea.Mouse{X: 1, Y: 1, Button: tea.MouseLeft}))
+	m = newModel.(Model)
+	m.lastSelection = ""
+	newModel, _ = m.Update(tea.MouseReleaseMsg(tea.Mouse{X: 1, Y: 1, Button: tea.MouseLeft}))
+	m = newModel.(Model)
+	if m.lastSelection != "" {
+		t.Fatalf("Expected a click on output to copy nothing, got %q", m.lastSelection)
+	}
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/utils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// promptsFieldKey routes option picker selections made from /prompts.
+const promptsFieldKey = "prompts_command"
+
+// promptPreviewWidth caps the prompt excerpt shown next to each variant.
+const promptPreviewWidth = 32
+
+// openPromptsPicker lists the system prompt variants with how many answers
+// each produced this session. Picking one makes it the default.
+func (m Model) openPromptsPicker() (Model, tea.Cmd) {
+	prompts := loadUIConfig().Prompts
+	variants := commands.PromptVariants(prompts)
+	if len(variants) == 1 {
+		return m, m.flashStatus("No prompt variants configured; add them under \"prompts\" in the config file")
+	}
+
+	var counts map[string]int
+	if h, ok := m.dispatcher.GetHandler("/explain"); ok {
+		if eh, ok := h.(*commands.ExplainHandler); ok {
+			counts = eh.PromptAnswerCounts()
+		}
+	}
+
+	defaultName := commands.DefaultPromptVariant(prompts)
+	m.promptChoices = make(map[string]string, len(variants))
+	options := make([]string, 0, len(variants))
+	current := ""
+	for _, v := range variants {
+		label := promptOptionLabel(v, v.Name == defaultName, counts[v.Name])
+		m.promptChoices[label] = v.Name
+		options = append(options, label)
+		if v.Name == defaultName {
+			current = label
+		}
+	}
+
+	title := "System Prompts"
+	if experiment := strings.TrimSpace(prompts.Experiment); experiment != "" && experiment != config.PromptExperimentOff {
+		title += " (experiment: " + experiment + ")"
+	}
+	slog.Info("prompts_picker_open", "variants", len(variants), "default", defaultName, "experiment", prompts.Experiment)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show(title, promptsFieldKey, options, current)
+	return m, nil
+}
+
+// promptOptionLabel renders a variant as "name (default) · 3 answers · excerpt".
+func promptOptionLabel(v config.PromptVariant, isDefault bool, answers int) string {
+	label := v.Name
+	if isDefault {
+		label += " (default)"
+	}
+	label += fmt.Sprintf(" · %d answer", answers)
+	if answers != 1 {
+		label += "s"
+	}
+	excerpt := "Built-in prompt"
+	if v.SystemPrompt != "" {
+		excerpt = strings.Join(strings.Fields(v.SystemPrompt), " ")
+	}
+	return label + " · " + utils.TruncateToWidth(excerpt, promptPreviewWidth)
+}
+
+// promotePromptVariant makes the picked variant the default and ends any
+// running experiment, so the choice applies from the next /explain.
+func (m Model) promotePromptVariant(label string) (Model, tea.Cmd) {
+	name, ok := m.promptChoices[label]
+	m.promptChoices = nil
+	if !ok {
+		return m, nil
+	}
+
+	path := config.GetConfigPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		slog.Error("prompts_save_error", "error", err)
+		return m, m.flashStatus("Could not load config: " + err.Error())
+	}
+	if name == config.PromptVariantBuiltin {
+		cfg.Prompts.Default = ""
+	} else {
+		cfg.Prompts.Default = name
+	}
+	experiment := strings.TrimSpace(cfg.Prompts.Experiment)
+	if experiment != "" {
+		cfg.Prompts.Experiment = config.PromptExperimentOff
+	}
+	if err := config.Save(path, cfg); err != nil {
+		slog.Error("prompts_save_error", "error", err)
+		return m, m.flashStatus("Could not save config: " + err.Error())
+	}
+
+	slog.Info("prompts_promote", "variant", name, "previous_experiment", experiment)
+	if experiment != "" && experiment != config.PromptExperimentOff {
+		return m, m.flashStatus(fmt.Sprintf("%q is now the default prompt; the %s experiment is stopped", name, experiment))
+	}
+	return m, m.flashStatus(fmt.Sprintf("%q is now the default prompt", name))
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+)
+
+func savePromptsConfig(t *testing.T, prompts config.PromptsConfig) {
+	t.Helper()
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Prompts = prompts
+	if err := config.Save(config.GetConfigPath(), cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+}
+
+func TestModel_PromptsCommandWithoutVariants(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	m, cmd := m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/prompts"})
+	if m.optionPicker != nil && m.optionPicker.IsVisible() {
+		t.Fatal("expected no picker without configured variants")
+	}
+	if cmd == nil {
+		t.Fatal("expected a status toast explaining how to add variants")
+	}
+}
+
+func TestModel_PromptsPickerPromotesVariant(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	savePromptsConfig(t, config.PromptsConfig{
+		Experiment: config.PromptExperimentAlternate,
+		Variants:   []config.PromptVariant{{Name: "terse", SystemPrompt: "Answer in one line."}},
+	})
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/prompts"})
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected /prompts to open the option picker")
+	}
+	view := m.optionPicker.View()
+	for _, want := range []string{"experiment: alternate", "builtin (default) · 0 answers", "terse · 0 answers · Answer in one line."} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected picker to show %q, got:\n%s", want, view)
+		}
+	}
+
+	var label string
+	for l, name := range m.promptChoices {
+		if name == "terse" {
+			label = l
+		}
+	}
+	m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: promptsFieldKey, Value: label})
+
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Prompts.Default != "terse" || cfg.Prompts.Experiment != config.PromptExperimentOff {
+		t.Fatalf("prompts = %+v, want terse as default with the experiment off", cfg.Prompts)
+	}
+	if m.promptChoices != nil {
+		t.Fatal("expected picker choices to be cleared")
+	}
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/picker"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// providerFieldKey routes option picker selections and actions made from
+// /provider.
+const providerFieldKey = "provider_switch"
+
+// providerSaveAction switches to the highlighted provider and saves it as
+// llm_provider.
+const providerSaveAction = "s"
+
+var providerActions = []picker.OptionAction{
+	{Key: providerSaveAction, Label: "Switch and save"},
+}
+
+// providerSwitchMsg carries the result of building the provider picked in
+// /provider.
+type providerSwitchMsg struct {
+	provider string
+	save     bool
+	err      error
+}
+
+// sessionConfig returns the config with the provider picked in /provider
+// for this session in place of llm_provider.
+func (m Model) sessionConfig() config.Config {
+	cfg := loadUIConfig()
+	if m.providerOverride != "" {
+		cfg.LLMProvider = m.providerOverride
+	}
+	return cfg
+}
+
+// openProviderPicker lists the providers the policy allows, the one in use
+// selected. Enter switches for this session, s saves the choice as well.
+func (m Model) openProviderPicker() (Model, tea.Cmd) {
+	cfg := m.sessionConfig()
+	current, _ := getProviderAndModel(cfg)
+	options := cfg.Policy.Providers()
+	if len(options) == 0 {
+		return m, m.flashStatus("No provider is allowed by the organization policy")
+	}
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("AI provider for this session", providerFieldKey, options, current)
+	optionPicker.SetActions(providerActions)
+	return m, nil
+}
+
+// handleProviderAction saves the highlighted provider as the default.
+func (m Model) handleProviderAction(msg picker.OptionPickerActionMsg) (Model, tea.Cmd) {
+	if m.optionPicker != nil {
+		m.optionPicker.Hide()
+	}
+	if msg.Action != providerSaveAction {
+		return m, nil
+	}
+	return m.switchProvider(msg.Value, true)
+}
+
+// switchProvider builds provider from the saved config in the background,
+// so a missing API key or an unknown provider is reported before the
+// next question rather than by it.
+func (m Model) switchProvider(provider string, save bool) (Model, tea.Cmd) {
+	cfg := loadUIConfig()
+	if !cfg.Policy.AllowsProvider(provider) {
+		return m, m.flashStatus(fmt.Sprintf("Provider %s is not allowed by the organization policy", provider))
+	}
+	cfg.LLMProvider = provider
+	return m, m.runAsync("Switching to "+provider, func(context.Context) tea.Msg {
+		_, err := ai.GetProviderFromConfig(cfg)
+		return providerSwitchMsg{provider: provider, save: save, err: err}
+	})
+}
+
+// applyProviderSwitch makes the built provider the one used for this
+// session, saving it as llm_provider when asked.
+func (m Model) applyProviderSwitch(msg providerSwitchMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		slog.Warn("provider_switch_error", "provider", msg.provider, "error", msg.err)
+		return m, m.flashStatus(fmt.Sprintf("Could not switch to %s: %v", msg.provider, msg.err))
+	}
+	status := "Using %s for this session"
+	if msg.save {
+		path := config.GetConfigPath()
+		cfg, err := config.Load(path)
+		if err != nil {
+			slog.Error("provider_save_error", "error", err)
+			return m, m.flashStatus("Could not load config: " + err.Error())
+		}
+		cfg.LLMProvider = msg.provider
+		if err := config.Save(path, cfg); err != nil {
+			slog.Error("provider_save_error", "error", err)
+			return m, m.flashStatus("Could not save config: " + err.Error())
+		}
+		status = "Using %s from now on"
+	}
+	m.providerOverride = msg.provider
+	m.modelOverride = "" // A model picked with /model belongs to the old provider
+	if configured, _ := getProviderAndModel(loadUIConfig()); configured == msg.provider {
+		m.providerOverride = ""
+	}
+	provider, model := getProviderAndModel(m.sessionConfig())
+	m.setActiveLLM(provider, model)
+	slog.Info("provider_switch", "provider", provider, "model", model, "saved", msg.save)
+	return m, m.flashStatus(fmt.Sprintf(status, provider+"-"+model))
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"testing"
+
+	_ "wtf_cli/pkg/ai/providers"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/testutils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// newProviderTestModel saves a config with an OpenRouter and an Anthropic
+// key in a temporary home.
+func newProviderTestModel(t *testing.T) Model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.OpenRouter.APIKey = "or-key"
+	cfg.Providers.Anthropic.APIKey = "ant-key"
+	if err := config.Save(config.GetConfigPath(), cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return newModel.(Model)
+}
+
+// finishProviderSwitch runs the background build started by cmd and
+// applies its result.
+func finishProviderSwitch(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("Expected the provider to be built in the background")
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("Expected a batch of commands")
+	}
+	for _, c := range batch {
+		if c == nil {
+			continue
+		}
+		if done, ok := c().(asyncDoneMsg); ok {
+			newModel, _ := m.Update(done)
+			return newModel.(Model)
+		}
+	}
+	t.Fatal("Expected the provider build result")
+	return m
+}
+
+func TestModel_ProviderCommandOpensPickerOnCurrentProvider(t *testing.T) {
+	m := newProviderTestModel(t)
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/provider"})
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected /provider to open the option picker")
+	}
+	sel, ok := m.optionPicker.Update(testutils.TestKeyEnter)().(picker.OptionPickerSelectMsg)
+	if !ok || sel.Value != "openrouter" {
+		t.Fatalf("Expected openrouter selected, got %#v", sel)
+	}
+}
+
+func TestModel_ProviderSwitchAppliesToSessionOnly(t *testing.T) {
+	m := newProviderTestModel(t)
+
+	m, cmd := m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: providerFieldKey, Value: "anthropic"})
+	m = finishProviderSwitch(t, m, cmd)
+	if m.providerOverride != "anthropic" {
+		t.Fatalf("Expected anthropic for this session, got %q", m.providerOverride)
+	}
+	if got := m.commandContext().ProviderOverride; got != "anthropic" {
+		t.Fatalf("Expected requests to use anthropic, got %q", got)
+	}
+	if got := m.ensureSidebar().ActiveLLMLabel(); got != "LLM: anthropic-claude-3-5-sonnet-20241022" {
+		t.Fatalf("Expected the sidebar label to follow, got %q", got)
+	}
+	if cfg := loadUIConfig(); cfg.LLMProvider != "openrouter" {
+		t.Fatalf("Expected the config untouched, got %q", cfg.LLMProvider)
+	}
+}
+
+func TestModel_ProviderSwitchSaveAction(t *testing.T) {
+	m := newProviderTestModel(t)
+	m, _ = m.openProviderPicker()
+
+	m, cmd := m.handleOptionPickerAction(picker.OptionPickerActionMsg{FieldKey: providerFieldKey, Action: providerSaveAction, Value: "anthropic"})
+	m = finishProviderSwitch(t, m, cmd)
+	if m.optionPicker.IsVisible() {
+		t.Fatal("Expected the picker closed")
+	}
+	if cfg := loadUIConfig(); cfg.LLMProvider != "anthropic" {
+		t.Fatalf("Expected anthropic saved, got %q", cfg.LLMProvider)
+	}
+	if m.providerOverride != "" {
+		t.Fatalf("Expected no session override once saved, got %q", m.providerOverride)
+	}
+}
+
+func TestModel_ProviderSwitchRejectsUnusableProvider(t *testing.T) {
+	m := newProviderTestModel(t)
+
+	m, cmd := m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: providerFieldKey, Value: "google"})
+	m = finishProviderSwitch(t, m, cmd)
+	if m.providerOverride != "" {
+		t.Fatalf("Expected no switch without a Google API key, got %q", m.providerOverride)
+	}
+	if got := m.commandContext().ProviderOverride; got != "" {
+		t.Fatalf("Expected requests to keep the configured provider, got %q", got)
+	}
+}
@@ -11,15 +11,23 @@ import (
 )
 
 // PTY message types
+// The pty field tells the panes of a split terminal apart; nil stands for
+// the focused pane.
 type ptyOutputMsg struct {
 	data []byte
+	pty  *os.File
 }
 
 type ptyErrorMsg struct {
 	err error
+	pty *os.File
 }
 
 func (m Model) handlePTYOutput(msg ptyOutputMsg) (Model, tea.Cmd) {
+	if m.split != nil && msg.pty != nil && msg.pty == m.split.ptyFile {
+		return m.handleSplitPaneOutput(msg)
+	}
+
 	// Suppress PTY output briefly after resize to prevent prompt reprint from showing
 	if !m.resizeTime.IsZero() && time.Since(m.resizeTime) < 100*time.Millisecond {
 		// Skip appending to viewport but still schedule next read
@@ -36,7 +44,7 @@ func (m Model) handlePTYOutput(msg ptyOutputMsg) (Model, tea.Cmd) {
 	// Force flush if buffer exceeds threshold
 	if len(m.ptyBatchBuffer) >= m.ptyBatchMaxSize {
 		m.flushPTYBatch()
-		return m, listenToPTY(m.ptyFile)
+		return m, tea.Batch(m.scheduleBulkSettle(), m.fireTriggers(), m.fireHooks(), listenToPTY(m.ptyFile))
 	}
 
 	// Start flush timer if not already pending
@@ -56,24 +64,38 @@ func (m Model) handlePTYBatchFlush() (Model, tea.Cmd) {
 	if len(m.ptyBatchBuffer) > 0 {
 		m.flushPTYBatch()
 	}
-	return m, nil
+	return m, tea.Batch(m.scheduleBulkSettle(), m.fireTriggers(), m.fireHooks())
 }
 
 func (m Model) handlePTYError(msg ptyErrorMsg) (Model, tea.Cmd) {
+	if msg.pty != nil {
+		if m, cmd, closed := m.handleSplitPaneExit(msg.pty); closed {
+			return m, tea.Batch(cmd, m.flashStatus("Shell exited; pane closed"))
+		}
+		if msg.pty != m.ptyFile {
+			return m, nil // Reader of a pane that was already closed
+		}
+	}
 	// PTY error - probably shell exited
 	slog.Error("pty_error", "error", msg.err)
-	return m, tea.Quit
+	if m.shellExited {
+		return m, nil
+	}
+	if m.execRunning() {
+		return m, m.waitExec()
+	}
+	return m.openSessionEnd()
 }
 
-// listenToPTY creates a command that reads from PTY
+// listenToPTY creates a command that waits for the next output of the PTY,
+// read ahead by its ptyReader.
 func listenToPTY(ptyFile *os.File) tea.Cmd {
 	return func() tea.Msg {
-		buf := make([]byte, 4096)
-		n, err := ptyFile.Read(buf)
-		if err != nil {
-			return ptyErrorMsg{err: err}
+		c := ptyReaderFor(ptyFile).next()
+		if c.err != nil {
+			return ptyErrorMsg{err: c.err, pty: ptyFile}
 		}
-		return ptyOutputMsg{data: buf[:n]}
+		return ptyOutputMsg{data: c.data, pty: ptyFile}
 	}
 }
 
@@ -83,10 +105,20 @@ func (m *Model) appendNormalizedLines(data []byte) {
 	}
 
 	lines := m.ptyNormalizer.Append(data)
-	for _, line := range lines {
+	marks := m.ptyNormalizer.Marks()
+	raw := m.ptyNormalizer.RawLines()
+	for i, line := range lines {
+		marks = m.applyPromptMarks(marks, i)
+		if m.privacy {
+			continue
+		}
+		m.detectCommandNotFound(line)
+		m.matchTriggers(line)
 		m.captureCommandFromLine(line)
+		m.rawLines.add(m.buffer.Total(), line, raw[i])
 		m.buffer.Write(line)
 	}
+	m.applyPromptMarks(marks, len(lines))
 }
 
 func (m *Model) captureCommandFromLine(line []byte) {
@@ -94,6 +126,13 @@ func (m *Model) captureCommandFromLine(line []byte) {
 		return
 	}
 
+	if m.repl != nil && m.repl.Owns(string(line)) {
+		return // An echoed REPL statement, recorded when it was submitted
+	}
+	if m.promptZones.seen {
+		m.captureMarkedCommand(line)
+		return
+	}
 	cmd := capture.ExtractCommandFromPrompt(string(line))
 	if cmd == "" {
 		return
@@ -107,10 +146,15 @@ func (m *Model) captureCommandFromLine(line []byte) {
 		}
 	}
 
+	// Without prompt marks, the next command is the first sign that the
+	// previous one finished.
+	m.invalidateGitStatus()
+	m.snapshotLastCommandOutput()
 	m.session.AddCommand(capture.CommandRecord{
-		Command:    cmd,
-		StartTime:  now,
-		EndTime:    now,
-		WorkingDir: m.currentDir,
+		Command:     cmd,
+		StartTime:   now,
+		EndTime:     now,
+		WorkingDir:  m.currentDir,
+		BufferStart: m.buffer.Total(),
 	})
 }
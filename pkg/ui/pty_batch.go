@@ -1,8 +1,23 @@
 package ui
 
+import (
+	"time"
+
+	"wtf_cli/pkg/ui/components/viewport"
+
+	tea "charm.land/bubbletea/v2"
+)
+
 type ptyBatchFlushMsg struct{}
 
+// viewportBulkSettleMsg polls a viewport in raw bulk mode so styled content is
+// restored once output goes quiet.
+type viewportBulkSettleMsg struct{}
+
 func (m *Model) flushPTYBatch() {
+	if len(m.ptyBatchBuffer) == 0 {
+		return
+	}
 	data := m.ptyBatchBuffer
 	m.ptyBatchBuffer = m.ptyBatchBuffer[:0]
 
@@ -39,6 +54,7 @@ func (m *Model) flushPTYBatch() {
 			} else if len(chunk.Data) > 0 {
 				m.appendNormalizedLines(chunk.Data)
 				m.viewport.AppendOutput(chunk.Data)
+				m.observeHub.PublishOutput(chunk.Data)
 			}
 			continue
 		}
@@ -56,6 +72,38 @@ func (m *Model) flushPTYBatch() {
 			// Normal mode: append to viewport AND buffer
 			m.appendNormalizedLines(chunk.Data)
 			m.viewport.AppendOutput(chunk.Data)
+			m.observeHub.PublishOutput(chunk.Data)
 		}
 	}
+	if !m.fullScreenMode {
+		m.syncSecretMode()
+	}
+}
+
+// scheduleBulkSettle starts polling the viewports while one of them shows
+// raw bulk output. It is a no-op if a poll is already pending.
+func (m *Model) scheduleBulkSettle() tea.Cmd {
+	if m.bulkSettlePending || !m.inBulkMode() {
+		return nil
+	}
+	m.bulkSettlePending = true
+	return tea.Tick(viewport.BulkIdle, func(time.Time) tea.Msg {
+		return viewportBulkSettleMsg{}
+	})
+}
+
+// inBulkMode reports whether either pane's viewport shows raw bulk output.
+func (m *Model) inBulkMode() bool {
+	return m.viewport.InBulkMode() || (m.split != nil && m.split.viewport.InBulkMode())
+}
+
+// handleBulkSettle settles each pane's viewport against its own output, so
+// a pane that went to the background mid-dump still leaves raw mode.
+func (m Model) handleBulkSettle() (Model, tea.Cmd) {
+	m.bulkSettlePending = false
+	m.viewport.SettleBulk()
+	if m.split != nil {
+		m.split.viewport.SettleBulk()
+	}
+	return m, m.scheduleBulkSettle()
 }
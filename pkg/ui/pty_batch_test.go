@@ -1,11 +1,15 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"wtf_cli/pkg/buffer"
 	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/viewport"
+
+	tea "charm.land/bubbletea/v2"
 )
 
 func TestPTYOutputBatching(t *testing.T) {
@@ -89,3 +93,39 @@ func TestPTYBatchTimerStartsOnce(t *testing.T) {
 		t.Errorf("Expected 11 bytes buffered (first+second), got %d", len(m.ptyBatchBuffer))
 	}
 }
+
+func TestPTYBulkOutputSchedulesSettle(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	now := time.Unix(0, 0)
+	m.viewport.SetClock(func() time.Time { return now })
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = updated.(Model)
+
+	chunk := []byte(strings.Repeat("bulk output line with some padding text\r\n", 1000))
+	for i := 0; i < 10; i++ {
+		updated, _ = m.Update(ptyOutputMsg{data: chunk})
+		m = updated.(Model)
+		now = now.Add(10 * time.Millisecond)
+	}
+	if !m.viewport.InBulkMode() {
+		t.Fatal("Expected viewport to switch to bulk mode")
+	}
+	if !m.bulkSettlePending {
+		t.Fatal("Expected a bulk settle poll to be scheduled")
+	}
+
+	// Output is still recent, so the poll reschedules itself.
+	updated, cmd := m.Update(viewportBulkSettleMsg{})
+	m = updated.(Model)
+	if cmd == nil || !m.bulkSettlePending {
+		t.Fatal("Expected bulk settle poll to be rescheduled while output is recent")
+	}
+
+	// Once output has been quiet long enough, the poll settles and stops.
+	now = now.Add(viewport.BulkIdle)
+	updated, cmd = m.Update(viewportBulkSettleMsg{})
+	m = updated.(Model)
+	if m.viewport.InBulkMode() || m.bulkSettlePending || cmd != nil {
+		t.Fatal("Expected bulk mode to settle after output went quiet")
+	}
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// PTY output is read by one goroutine per PTY, ahead of the message loop.
+// Reads queue up as chunks, and each listenToPTY command takes every chunk
+// queued so far as one ptyOutputMsg, so a fast producer costs one message
+// per loop round-trip instead of one per read.
+
+const (
+	ptyReadSize    = 32 * 1024  // Bytes per read
+	ptyChunkQueue  = 16         // Chunks read ahead before the reader waits
+	ptyMaxMsgBytes = 256 * 1024 // Bytes coalesced into one ptyOutputMsg
+)
+
+type ptyChunk struct {
+	data []byte
+	err  error
+}
+
+// ptyReader reads one PTY in the background.
+type ptyReader struct {
+	file   *os.File
+	chunks chan ptyChunk
+	done   chan struct{} // Closed by stopPTYReader; nothing reads chunks after
+	stop   sync.Once
+
+	mu   sync.Mutex
+	held *ptyChunk // Chunk taken while coalescing that did not fit
+}
+
+// ptyReaders maps each PTY to its reader. It lives outside the model, which
+// Bubble Tea copies on every update, and the reader outlives the command
+// that started it.
+var ptyReaders = struct {
+	sync.Mutex
+	byFile map[*os.File]*ptyReader
+}{byFile: make(map[*os.File]*ptyReader)}
+
+// ptyReaderFor returns the reader of ptyFile, starting it on first use.
+func ptyReaderFor(ptyFile *os.File) *ptyReader {
+	ptyReaders.Lock()
+	defer ptyReaders.Unlock()
+	if r, ok := ptyReaders.byFile[ptyFile]; ok {
+		return r
+	}
+	r := &ptyReader{file: ptyFile, chunks: make(chan ptyChunk, ptyChunkQueue), done: make(chan struct{})}
+	ptyReaders.byFile[ptyFile] = r
+	go r.run()
+	return r
+}
+
+// run reads until the PTY fails, e.g. because the shell exited, or the
+// reader is stopped.
+func (r *ptyReader) run() {
+	defer close(r.chunks)
+	for {
+		buf := make([]byte, ptyReadSize)
+		n, err := r.file.Read(buf)
+		if n > 0 && !r.send(ptyChunk{data: buf[:n]}) {
+			return
+		}
+		if err != nil {
+			r.send(ptyChunk{err: err})
+			return
+		}
+	}
+}
+
+// send queues c, waiting for room unless the reader is stopped. It reports
+// whether c was queued.
+func (r *ptyReader) send(c ptyChunk) bool {
+	select {
+	case r.chunks <- c:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// stopPTYReader stops the reader of a PTY that is being closed, whose
+// output nobody reads any more, so it does not wait forever on a full
+// queue.
+func stopPTYReader(ptyFile *os.File) {
+	ptyReaders.Lock()
+	r, ok := ptyReaders.byFile[ptyFile]
+	if ok {
+		delete(ptyReaders.byFile, ptyFile)
+	}
+	ptyReaders.Unlock()
+	if ok {
+		r.stop.Do(func() { close(r.done) })
+	}
+}
+
+// next waits for output and returns it together with whatever else is
+// queued, up to ptyMaxMsgBytes. An error is returned on its own, after the
+// output read before it.
+func (r *ptyReader) next() ptyChunk {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	first, ok := r.take()
+	if !ok {
+		return r.finish(io.EOF)
+	}
+	if first.err != nil {
+		return r.finish(first.err)
+	}
+	data := first.data
+	for len(data) < ptyMaxMsgBytes {
+		select {
+		case c, ok := <-r.chunks:
+			if !ok {
+				c = ptyChunk{err: io.EOF}
+			}
+			if c.err != nil || len(data)+len(c.data) > ptyMaxMsgBytes {
+				r.held = &c
+				return ptyChunk{data: data}
+			}
+			data = append(data, c.data...)
+		default:
+			return ptyChunk{data: data}
+		}
+	}
+	return ptyChunk{data: data}
+}
+
+// take returns the held chunk, or waits for the next one.
+func (r *ptyReader) take() (ptyChunk, bool) {
+	if r.held != nil {
+		c := *r.held
+		r.held = nil
+		return c, true
+	}
+	c, ok := <-r.chunks
+	return c, ok
+}
+
+// finish forgets the reader once its PTY failed, so a PTY reopened at the
+// same address gets a new one.
+func (r *ptyReader) finish(err error) ptyChunk {
+	ptyReaders.Lock()
+	if ptyReaders.byFile[r.file] == r {
+		delete(ptyReaders.byFile, r.file)
+	}
+	ptyReaders.Unlock()
+	return ptyChunk{err: err}
+}
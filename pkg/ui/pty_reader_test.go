@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPTYReader_CoalescesQueuedChunks(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	reader := ptyReaderFor(r)
+
+	var want bytes.Buffer
+	for i := 0; i < 8; i++ {
+		chunk := bytes.Repeat([]byte{byte('a' + i)}, 1000)
+		want.Write(chunk)
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	// Let the reader queue everything, then expect it back in few messages.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(reader.chunks) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	var got bytes.Buffer
+	messages := 0
+	for {
+		msg := listenToPTY(r)()
+		if e, ok := msg.(ptyErrorMsg); ok {
+			if e.err != io.EOF || e.pty != r {
+				t.Fatalf("error = %v from %v, want EOF from the pipe", e.err, e.pty)
+			}
+			break
+		}
+		out := msg.(ptyOutputMsg)
+		got.Write(out.data)
+		messages++
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Fatalf("read %d bytes, want the %d written in order", got.Len(), want.Len())
+	}
+	if messages >= 8 {
+		t.Errorf("got %d messages for 8 writes, want them coalesced", messages)
+	}
+
+	ptyReaders.Lock()
+	_, kept := ptyReaders.byFile[r]
+	ptyReaders.Unlock()
+	if kept {
+		t.Error("reader of a failed PTY still registered")
+	}
+}
+
+func TestPTYReader_SplitsAtMessageLimit(t *testing.T) {
+	reader := &ptyReader{chunks: make(chan ptyChunk, 4)}
+	big := make([]byte, ptyMaxMsgBytes-10)
+	reader.chunks <- ptyChunk{data: big}
+	reader.chunks <- ptyChunk{data: make([]byte, 20)}
+
+	if c := reader.next(); len(c.data) != len(big) {
+		t.Fatalf("first message = %d bytes, want %d", len(c.data), len(big))
+	}
+	if c := reader.next(); len(c.data) != 20 {
+		t.Fatalf("second message = %d bytes, want the held 20", len(c.data))
+	}
+}
+
+func TestPTYReader_StopsWhenItsPTYIsReleased(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	baseline := runtime.NumGoroutine()
+	reader := ptyReaderFor(r)
+
+	// Fill the queue with output nobody reads, then keep the reader waiting
+	// to queue more.
+	chunk := bytes.Repeat([]byte{'x'}, ptyReadSize)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(reader.chunks) < ptyChunkQueue && time.Now().Before(deadline) {
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(reader.chunks) < ptyChunkQueue {
+		t.Fatal("expected the queue to fill up")
+	}
+	if _, err := w.Write(chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	if cmd := releaseShell(nil, r); cmd != nil {
+		t.Fatal("expected no command without a process")
+	}
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Fatalf("reader still running: %d goroutines, want %d", n, baseline)
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// readOnlyNotice is flashed when input meant for the shell is dropped.
+const readOnlyNotice = "Read-only: input is not sent to the shell (/readonly off to type)"
+
+// SetReadOnly starts the session in read-only mode, for --read-only.
+func (m *Model) SetReadOnly(on bool) {
+	m.setReadOnly(on)
+}
+
+// handleReadOnlyCommand switches read-only mode: "on", "off", or toggles it
+// when args is empty.
+func (m Model) handleReadOnlyCommand(args string) (Model, tea.Cmd) {
+	on := !m.readOnly
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+	case "on":
+		on = true
+	case "off":
+		on = false
+	default:
+		return m, m.flashStatus("Usage: /readonly [on|off]")
+	}
+	m.setReadOnly(on)
+	if on {
+		return m, m.flashStatus("Read-only mode on: keys, pastes and commands are not sent to the shell")
+	}
+	return m, m.flashStatus("Read-only mode off: typing goes to the shell again")
+}
+
+// setReadOnly turns read-only mode on or off for every pane. The AI, the
+// palette and the chat keep working; nothing reaches the PTY.
+func (m *Model) setReadOnly(on bool) {
+	if m.readOnly != on {
+		slog.Info("read_only_mode", "on", on)
+	}
+	m.readOnly = on
+	if m.inputHandler != nil {
+		m.inputHandler.SetReadOnly(on)
+	}
+	if m.split != nil && m.split.inputHandler != nil {
+		m.split.inputHandler.SetReadOnly(on)
+	}
+	m.statusBar.SetReadOnly(on)
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/ui/components/testutils"
+	"wtf_cli/pkg/ui/input"
+)
+
+func TestModel_ReadOnlyKeepsInputFromShell(t *testing.T) {
+	ptyFile, r := newPipePTY(t)
+	m := NewModel(ptyFile, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/readonly"})
+	if !m.readOnly || !m.inputHandler.IsReadOnly() {
+		t.Fatal("expected /readonly to turn read-only mode on")
+	}
+	if bar := m.statusBar.Render(); !strings.Contains(bar, "[wtf_cli read-only]") {
+		t.Fatalf("expected read-only in the status bar, got %q", bar)
+	}
+
+	m, cmd := m.handleKeyPress(testutils.NewTextKeyPressMsg("x"))
+	if cmd == nil {
+		t.Fatal("expected a notice for a dropped key")
+	}
+	if _, ok := cmd().(input.ReadOnlyKeyMsg); !ok {
+		t.Fatal("expected the key to come back as ReadOnlyKeyMsg")
+	}
+	newModel, _ := m.Update(input.ReadOnlyKeyMsg{})
+	m = newModel.(Model)
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Read-only") {
+		t.Fatalf("expected the read-only notice, got %q", msg)
+	}
+
+	m, _ = m.handleSidebarCommandExecute(sidebar.CommandExecuteMsg{Command: "rm -rf build"})
+	m, _ = m.writePaste("kubectl delete ns prod")
+	if err := m.inputHandler.SendToPTY([]byte("ls\r")); err != input.ErrReadOnly {
+		t.Fatalf("SendToPTY() error = %v, want ErrReadOnly", err)
+	}
+	if m.inputHandler.LineBuffer() != "" || m.appliedCommand != "" {
+		t.Fatalf("expected nothing typed, line buffer %q", m.inputHandler.LineBuffer())
+	}
+
+	m, _ = m.handleReadOnlyCommand("off")
+	if m.readOnly {
+		t.Fatal("expected /readonly off to turn read-only mode off")
+	}
+	m.inputHandler.SendToPTY([]byte("ok"))
+	ptyFile.Close()
+	written, _ := io.ReadAll(r)
+	if string(written) != "ok" {
+		t.Fatalf("PTY got %q, want only what was sent after read-only mode", written)
+	}
+}
+
+func TestModel_ReadOnlyOpensPalette(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.SetReadOnly(true)
+	m.inputHandler.SetLineBuffer("typed before")
+
+	_, cmd := m.handleKeyPress(testutils.NewTextKeyPressMsg("/"))
+	if cmd == nil {
+		t.Fatal("expected / to open the palette")
+	}
+	if _, ok := cmd().(input.ShowPaletteMsg); !ok {
+		t.Fatal("expected ShowPaletteMsg in read-only mode")
+	}
+
+	m, _ = m.handleReadOnlyCommand("maybe")
+	if !m.readOnly || !strings.Contains(m.statusBar.GetMessage(), "Usage: /readonly") {
+		t.Fatalf("expected usage for an unknown argument, got %q", m.statusBar.GetMessage())
+	}
+}
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/pty"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// statusSourceRecap is the "while you were away" recap. It stays until Esc
+// or the next command.
+const statusSourceRecap = "recap"
+
+// recapState tracks whether the user is away and what they last saw.
+type recapState struct {
+	idle    time.Duration // Idle time that counts as away; zero turns recaps off
+	blurred bool          // The terminal window lost focus
+	away    bool          // Idle past idle with output out of sight
+	seen    recapMark     // State at the last input
+	banner  string        // Recap shown in the status bar
+}
+
+// recapMark is what the session looked like when the user last gave input.
+type recapMark struct {
+	time  time.Time
+	lines int
+	jobs  []pty.Job
+}
+
+func newRecapState(cfg config.RecapConfig) recapState {
+	return recapState{
+		idle: time.Duration(cfg.IdleMinutes) * time.Minute,
+		seen: recapMark{time: time.Now()},
+	}
+}
+
+// setRecapConfig applies the recap settings, keeping what was seen.
+func (m *Model) setRecapConfig(cfg config.RecapConfig) {
+	m.recap.idle = time.Duration(cfg.IdleMinutes) * time.Minute
+	if m.recap.idle == 0 {
+		m.recap.away = false
+	}
+}
+
+// isUserInput reports whether msg comes from the user being at the
+// terminal.
+func isUserInput(msg tea.Msg) bool {
+	switch msg.(type) {
+	case tea.KeyPressMsg, tea.MouseClickMsg, tea.MouseWheelMsg, tea.PasteMsg, tea.FocusMsg:
+		return true
+	}
+	return false
+}
+
+// noteUserInput shows the recap when the user comes back from being away
+// and marks what they have seen from now on.
+func (m *Model) noteUserInput(msg tea.Msg) {
+	switch msg.(type) {
+	case tea.FocusMsg:
+		m.recap.blurred = false
+	case tea.BlurMsg:
+		m.recap.blurred = true
+		return
+	}
+	if !isUserInput(msg) || m.recap.idle == 0 {
+		return
+	}
+	if m.recap.away {
+		m.recap.away = false
+		m.showRecap(time.Now())
+	}
+	m.recap.seen = m.recapMarkNow()
+}
+
+func (m *Model) recapMarkNow() recapMark {
+	mark := recapMark{time: time.Now(), jobs: slices.Clone(m.jobs)}
+	if m.buffer != nil {
+		mark.lines = m.buffer.Total()
+	}
+	return mark
+}
+
+// checkAway runs on the directory tick: past the idle time, with the
+// window unfocused or the terminal scrolled back, new output goes unseen.
+func (m *Model) checkAway() {
+	if m.recap.idle == 0 || m.recap.away || time.Since(m.recap.seen.time) < m.recap.idle {
+		return
+	}
+	if m.recap.blurred || m.scrollMode {
+		m.recap.away = true
+		slog.Debug("recap_away", "idle", time.Since(m.recap.seen.time).Round(time.Second))
+	}
+}
+
+// showRecap puts what happened since the user left in the status bar,
+// unless nothing did.
+func (m *Model) showRecap(now time.Time) {
+	text := m.recapText(now)
+	if text == "" {
+		return
+	}
+	m.dismissRecap()
+	m.recap.banner = text + " | Esc dismisses"
+	m.postStatus(statusbar.Message{Source: statusSourceRecap, Text: m.recap.banner, Priority: statusbar.PriorityNormal}, 0)
+	slog.Info("recap_shown", "away", now.Sub(m.recap.seen.time).Round(time.Second))
+}
+
+// dismissRecap removes the recap from the status bar.
+func (m *Model) dismissRecap() {
+	if m.recap.banner == "" {
+		return
+	}
+	m.clearStatusText(statusSourceRecap, m.recap.banner)
+	m.recap.banner = ""
+}
+
+// recapText summarizes the session since the last input, e.g. "Away 25m:
+// 3 commands, last failure: make test (exit 2), job done: sleep 600, 420
+// new lines". Commands and failures need the shell's prompt marks.
+func (m *Model) recapText(now time.Time) string {
+	seen := m.recap.seen
+	var parts []string
+
+	if m.session != nil {
+		commands := 0
+		failure := ""
+		for _, rec := range m.session.GetHistory() {
+			if !rec.Marked || rec.BufferEnd == 0 || !rec.EndTime.After(seen.time) {
+				continue
+			}
+			commands++
+			if rec.ExitCode > 0 {
+				failure = fmt.Sprintf("last failure: %s (exit %d)", truncateNotificationDetail(rec.Command), rec.ExitCode)
+			}
+		}
+		if commands > 0 {
+			parts = append(parts, pluralize(commands, "command", "commands"))
+		}
+		if failure != "" {
+			parts = append(parts, failure)
+		}
+	}
+
+	var done []string
+	for _, job := range seen.jobs {
+		if !slices.ContainsFunc(m.jobs, func(j pty.Job) bool { return j.PGID == job.PGID }) {
+			done = append(done, job.Command)
+		}
+	}
+	switch len(done) {
+	case 0:
+	case 1:
+		parts = append(parts, "job done: "+truncateNotificationDetail(done[0]))
+	default:
+		parts = append(parts, fmt.Sprintf("%d jobs done", len(done)))
+	}
+
+	if m.buffer != nil {
+		if lines := m.buffer.Total() - seen.lines; lines > 0 {
+			parts = append(parts, pluralize(lines, "new line", "new lines"))
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	away := now.Sub(seen.time).Round(time.Minute)
+	return fmt.Sprintf("Away %s: %s", strings.TrimSuffix(away.String(), "0s"), strings.Join(parts, ", "))
+}
+
+func pluralize(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
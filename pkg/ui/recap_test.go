@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/pty"
+	"wtf_cli/pkg/ui/components/testutils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// newRecapTestModel returns a model whose user gave input two minutes ago,
+// with a one-minute idle time and sleep 600 running in the background.
+func newRecapTestModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 30})
+	m = newModel.(Model)
+	m.recap.idle = time.Minute
+	m.recap.seen = recapMark{
+		time: time.Now().Add(-2 * time.Minute),
+		jobs: []pty.Job{{PGID: 42, Command: "sleep 600"}},
+	}
+	return m
+}
+
+func TestModel_RecapShownOnReturnFromUnfocusedWindow(t *testing.T) {
+	m := newRecapTestModel(t)
+	for _, line := range []string{"building", "FAIL", "done"} {
+		m.buffer.Write([]byte(line))
+	}
+	m.session.AddCommand(capture.CommandRecord{
+		Command:   "make test",
+		ExitCode:  2,
+		StartTime: time.Now().Add(-time.Minute),
+		EndTime:   time.Now(),
+		BufferEnd: 3,
+		Marked:    true,
+	})
+
+	newModel, _ := m.Update(tea.BlurMsg{})
+	m = newModel.(Model)
+	newModel, _ = m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	if !m.recap.away {
+		t.Fatal("Expected the user to count as away")
+	}
+
+	newModel, _ = m.Update(tea.FocusMsg{})
+	m = newModel.(Model)
+	want := "Away 2m: 1 command, last failure: make test (exit 2), job done: sleep 600, 3 new lines | Esc dismisses"
+	if m.recap.banner != want {
+		t.Fatalf("Expected recap %q, got %q", want, m.recap.banner)
+	}
+	if !strings.Contains(m.statusBar.Render(), "Away 2m") {
+		t.Fatalf("Expected the recap in the status bar, got %q", m.statusBar.Render())
+	}
+
+	newModel, _ = m.Update(testutils.TestKeyEsc)
+	m = newModel.(Model)
+	if m.recap.banner != "" || strings.Contains(m.statusBar.Render(), "Away") {
+		t.Fatalf("Expected Esc to dismiss the recap, got %q", m.statusBar.Render())
+	}
+}
+
+func TestModel_RecapSkippedWhileOutputIsInView(t *testing.T) {
+	m := newRecapTestModel(t)
+	m.buffer.Write([]byte("tick"))
+
+	newModel, _ := m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	if m.recap.away {
+		t.Fatal("Expected a focused window following output not to count as away")
+	}
+	newModel, _ = m.Update(tea.FocusMsg{})
+	m = newModel.(Model)
+	if m.recap.banner != "" {
+		t.Fatalf("Expected no recap, got %q", m.recap.banner)
+	}
+}
+
+func TestModel_RecapAfterScrollingBack(t *testing.T) {
+	m := newRecapTestModel(t)
+	m.jobs = m.recap.seen.jobs
+	m.scrollMode = true
+	m.buffer.Write([]byte("tick"))
+
+	newModel, _ := m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+	m = newModel.(Model)
+	if m.recap.banner != "Away 2m: 1 new line | Esc dismisses" {
+		t.Fatalf("Expected a recap of the new line, got %q", m.recap.banner)
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// retryProviderFieldKey routes the provider picked to answer a refused
+// question again.
+const retryProviderFieldKey = "message_retry_provider"
+
+// Message menu entries offered on a refused answer.
+const (
+	messageActionRephrase      = "Rephrase and retry"
+	messageActionOtherProvider = "Retry with another provider"
+)
+
+// refusalActions returns the menu entries for answering the question of a
+// refused message another way, or nil for a normal answer.
+func (m Model) refusalActions(idx int, msg ai.ChatMessage) []string {
+	if msg.Refusal == "" {
+		return nil
+	}
+	if _, ok := m.sidebar.PromptFor(idx); !ok {
+		return nil
+	}
+	actions := []string{messageActionRephrase}
+	if len(otherProviders(m.sessionConfig())) > 0 {
+		actions = append(actions, messageActionOtherProvider)
+	}
+	return actions
+}
+
+// rephraseMessage puts the question that was refused back in the input, to
+// be edited and sent again.
+func (m Model) rephraseMessage(idx int) (Model, tea.Cmd) {
+	history, ok := m.sidebar.PromptFor(idx)
+	if !ok {
+		return m, nil
+	}
+	slog.Info("message_rephrase", "index", idx)
+	m.sidebar.SetInput(history[len(history)-1].Content)
+	m.sidebar.FocusInput()
+	return m, m.flashStatus("Edit the question and press Enter to ask again")
+}
+
+// openRetryProviderPicker lists the other providers that are set up.
+func (m Model) openRetryProviderPicker() (Model, tea.Cmd) {
+	options := otherProviders(m.sessionConfig())
+	if len(options) == 0 {
+		return m, m.flashStatus("No other provider is set up")
+	}
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Retry with", retryProviderFieldKey, options, "")
+	return m, nil
+}
+
+// retryWithProvider asks the question of the focused message again with
+// provider and its configured model.
+func (m Model) retryWithProvider(provider string) (Model, tea.Cmd) {
+	if m.sidebar == nil || m.hasActiveStream() {
+		return m, nil
+	}
+	history, ok := m.sidebar.PromptFor(m.messageActionIdx)
+	if !ok {
+		return m, nil
+	}
+	slog.Info("message_retry_provider", "index", m.messageActionIdx, "provider", provider)
+	ctx := m.commandContext()
+	ctx.ProviderOverride = provider
+	return m.rerunWithContext(ctx, history, "Asking "+provider)
+}
+
+// otherProviders returns the providers other than the selected one that
+// the policy allows and that have an API key. Copilot is left out: whether
+// it is signed in is only known by asking it.
+func otherProviders(cfg config.Config) []string {
+	keys := map[string]string{
+		string(ai.ProviderOpenRouter): cfg.OpenRouter.APIKey,
+		string(ai.ProviderOpenAI):     cfg.Providers.OpenAI.APIKey,
+		string(ai.ProviderAnthropic):  cfg.Providers.Anthropic.APIKey,
+		string(ai.ProviderGoogle):     cfg.Providers.Google.APIKey,
+	}
+	current, _ := getProviderAndModel(cfg)
+	var out []string
+	for _, provider := range cfg.Policy.Providers() {
+		if provider == current || strings.TrimSpace(keys[provider]) == "" {
+			continue
+		}
+		out = append(out, provider)
+	}
+	return out
+}
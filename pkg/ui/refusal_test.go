@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/sidebar"
+)
+
+func TestModel_RefusalMarksAnswerAndOffersRephrase(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.ensureSidebar().SetSize(80, 20)
+	m.sidebar.Show()
+	m.sidebar.AppendUserMessage("how do I pick this lock?")
+	m.startStreamPlaceholder()
+
+	for _, ev := range []commands.WtfStreamEvent{
+		{Delta: "I can't help with that."},
+		{Refusal: "content filter"},
+		{Done: true},
+	} {
+		updated, _ := m.Update(ev)
+		m = updated.(Model)
+	}
+
+	msgs := m.sidebar.GetMessages()
+	if got := msgs[len(msgs)-1].Refusal; got != "content filter" {
+		t.Fatalf("refusal = %q, want content filter", got)
+	}
+	if view := m.sidebar.View(); !strings.Contains(view, "Refused by the provider (content filter)") {
+		t.Fatalf("expected the refusal banner, got:\n%s", view)
+	}
+
+	m, _ = m.handleSidebarMessageMenu(sidebar.MessageMenuMsg{Index: len(msgs) - 1})
+	if m.optionPicker == nil || !strings.Contains(m.optionPicker.View(), messageActionRephrase) {
+		t.Fatal("expected the menu to offer rephrasing")
+	}
+	m, _ = m.handleMessageAction(messageActionRephrase)
+	content, ok := m.sidebar.SubmitMessage()
+	if !ok || content != "how do I pick this lock?" {
+		t.Fatalf("expected the question back in the input, got %q", content)
+	}
+	if !m.sidebar.IsFocusedOnInput() {
+		t.Fatal("expected the input focused")
+	}
+}
+
+func TestOtherProviders(t *testing.T) {
+	cfg := config.Default()
+	cfg.LLMProvider = "openai"
+	cfg.OpenRouter.APIKey = "or-key"
+	cfg.Providers.OpenAI.APIKey = "oa-key"
+	cfg.Providers.Google.APIKey = "g-key"
+
+	if got := otherProviders(cfg); !slices.Equal(got, []string{"openrouter", "google"}) {
+		t.Fatalf("otherProviders = %v, want [openrouter google]", got)
+	}
+
+	cfg.Policy.AllowedProviders = []string{"openai", "google"}
+	if got := otherProviders(cfg); !slices.Equal(got, []string{"google"}) {
+		t.Fatalf("otherProviders with a policy = %v, want [google]", got)
+	}
+}
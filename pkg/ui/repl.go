@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"log/slog"
+	"time"
+
+	"wtf_cli/pkg/capture"
+)
+
+// promptLine returns the terminal line being typed on, prompt included.
+func (m Model) promptLine() string {
+	if m.ptyNormalizer == nil {
+		return ""
+	}
+	return string(m.ptyNormalizer.Pending())
+}
+
+// submitREPLStatement records typed as a REPL statement when it was entered
+// at the prompt of an interpreter the last shell command started. It
+// reports false for shell commands, which the caller records as usual.
+func (m *Model) submitREPLStatement(typed string) bool {
+	if m.repl == nil {
+		return false
+	}
+	repl, statement := m.repl.Submit(m.promptLine(), typed)
+	if repl == nil {
+		return false
+	}
+	slog.Debug("repl_statement", "repl", repl.Name, "len", len(statement))
+	if m.session != nil && statement != "" && !m.privacy {
+		m.session.AddREPLStatement(capture.REPLStatement{
+			REPL:      repl.Name,
+			Statement: statement,
+			Time:      time.Now(),
+		})
+	}
+	return true
+}
+
+// activeREPL returns the interpreter whose prompt is showing, or nil at the
+// shell prompt.
+func (m Model) activeREPL() *capture.REPL {
+	if m.repl == nil {
+		return nil
+	}
+	return m.repl.Active(m.promptLine())
+}
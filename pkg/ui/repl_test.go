@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/input"
+)
+
+func TestModel_REPLStatementsStayOutOfShellHistory(t *testing.T) {
+	session := capture.NewSessionContext()
+	m := NewModel(nil, buffer.New(100), session, nil)
+
+	m.ptyNormalizer.Append([]byte("user@host:~$ "))
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "psql mydb"})
+	m = newModel.(Model)
+	m.ptyNormalizer.Append([]byte("psql mydb\r\npsql (16.2)\r\nmydb=# select 1;"))
+	if got := m.activeREPL(); got == nil || got.Name != "psql" {
+		t.Fatalf("Expected psql to be active at its prompt, got %v", got)
+	}
+
+	newModel, _ = m.Update(input.CommandSubmittedMsg{Command: "select 1;"})
+	m = newModel.(Model)
+
+	if got := session.HistorySize(); got != 1 {
+		t.Fatalf("Expected only the psql launch in history, got %d records", got)
+	}
+	if got := session.LastREPLStatements("psql", 5); len(got) != 1 || got[0] != "select 1;" {
+		t.Fatalf("Expected the statement recorded for psql, got %q", got)
+	}
+	if ctx := m.commandContext(); ctx.REPL == nil || ctx.REPL.Name != "psql" {
+		t.Fatalf("Expected the command context to name psql, got %v", ctx.REPL)
+	}
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/ui/components/scrubber"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// replaySteps turns the session's commands into scrubber steps. Output is
+// read from the buffer while it still holds it, otherwise the snapshot
+// taken when the next command started is used. Only commands delimited by
+// prompt marks know when they ended and how they exited.
+func (m Model) replaySteps() []scrubber.Step {
+	if m.session == nil {
+		return nil
+	}
+	history := m.session.GetHistory()
+	steps := make([]scrubber.Step, 0, len(history))
+	for i, rec := range history {
+		step := scrubber.Step{
+			Command:  strings.TrimSpace(rec.Command),
+			Dir:      rec.WorkingDir,
+			ExitCode: -1,
+			Start:    rec.StartTime,
+			End:      rec.StartTime,
+		}
+		if rec.Marked && rec.BufferEnd > 0 {
+			step.ExitCode = rec.ExitCode
+			step.End = rec.EndTime
+		}
+		end := rec.BufferEnd
+		if end == 0 {
+			if i+1 < len(history) {
+				end = history[i+1].BufferStart
+			} else if m.buffer != nil {
+				end = m.buffer.Total()
+			}
+		}
+		var lines [][]byte
+		if m.buffer != nil {
+			lines = m.buffer.LinesBetween(rec.BufferStart, end)
+		}
+		if len(lines) > 0 && len(lines) == end-rec.BufferStart {
+			for j, raw := range lines {
+				line := sanitizePreviewLine(string(raw))
+				if j == 0 && !rec.Marked && step.Command != "" && strings.Contains(line, step.Command) {
+					// The echoed prompt line.
+					continue
+				}
+				step.Output = append(step.Output, line)
+			}
+		} else {
+			step.Output = append([]string(nil), rec.Output...)
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// openReplay shows the session's commands on the scrubber.
+func (m Model) openReplay() (Model, tea.Cmd) {
+	steps := m.replaySteps()
+	if len(steps) == 0 {
+		return m, m.flashStatus("No commands recorded this session yet")
+	}
+	if m.scrubber == nil {
+		m.scrubber = scrubber.New()
+	}
+	m.scrubber.SetSize(m.width, m.height)
+	m.scrubber.Show(steps)
+	slog.Info("replay_open", "commands", len(steps))
+	return m, nil
+}
+
+// handleReplayAsk asks the chat about the session as it was at the
+// scrubber's position. The request carries a buffer and session rebuilt
+// from the steps, so nothing after the position reaches the AI.
+func (m Model) handleReplayAsk(msg scrubber.AskMsg) (Model, tea.Cmd) {
+	if len(msg.Steps) == 0 {
+		return m, nil
+	}
+	if m.privacy {
+		return m, m.flashStatus("Privacy mode is on: terminal history is not shared with AI")
+	}
+	if m.hasActiveStream() {
+		return m, m.flashStatus("AI is busy; try again when the answer finishes")
+	}
+	if m.needsWorkspaceTrust() || m.budgetBlocks() {
+		return m, m.flashStatus("Answer the trust or budget question in the chat first")
+	}
+
+	ctx := replayContext(msg.Steps, m.currentDir)
+	ctx.Trust = m.workspaceTrust()
+	ctx.Memories = m.memories()
+	ctx.MemoriesPath = m.memoriesFile()
+	slog.Info("replay_ask", "commands", len(msg.Steps))
+
+	m.scrubber.Hide()
+	m.showSidebar("replay")
+	m.sidebar.AppendUserMessage(replayQuestion(msg.Steps))
+	m.refreshSidebar()
+	return m.startChatRunWithContext(ctx)
+}
+
+// replayContext rebuilds the terminal buffer and command history from
+// steps.
+func replayContext(steps []scrubber.Step, dir string) *commands.Context {
+	total := 0
+	for _, s := range steps {
+		total += len(s.Output) + 1
+	}
+	buf := buffer.New(max(total, 1))
+	sess := capture.NewSessionContext()
+	for _, s := range steps {
+		rec := capture.CommandRecord{
+			Command:     s.Command,
+			ExitCode:    s.ExitCode,
+			StartTime:   s.Start,
+			EndTime:     s.End,
+			WorkingDir:  s.Dir,
+			BufferStart: buf.Total(),
+			Marked:      true,
+		}
+		buf.Write([]byte("$ " + s.Command))
+		for _, line := range s.Output {
+			buf.Write([]byte(line))
+		}
+		if !s.End.IsZero() {
+			rec.BufferEnd = buf.Total()
+		}
+		sess.AddCommand(rec)
+		if s.Dir != "" {
+			dir = s.Dir
+		}
+	}
+	return commands.NewContext(buf, sess, dir)
+}
+
+// replayQuestion is the chat message sent for the scrubber's position.
+func replayQuestion(steps []scrubber.Step) string {
+	last := steps[len(steps)-1]
+	state := "had just finished"
+	switch {
+	case last.End.IsZero():
+		state = "was still running"
+	case last.ExitCode > 0:
+		state = fmt.Sprintf("had just failed with exit code %d", last.ExitCode)
+	}
+	return fmt.Sprintf("I'm replaying my terminal session. At %s, command %d (`%s`) %s. "+
+		"Looking only at the session up to that point, what was going on, and what should I have done next?",
+		last.Start.Format("15:04:05"), len(steps), last.Command, state)
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/scrubber"
+)
+
+func TestReplaySteps_FromSessionAndBuffer(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	buf := buffer.New(100)
+	sess := capture.NewSessionContext()
+	for _, line := range []string{"$ make", "main.c:3: error", "make: *** Error 2"} {
+		buf.Write([]byte(line))
+	}
+	sess.AddCommand(capture.CommandRecord{
+		Command: "make", ExitCode: 2, StartTime: start, EndTime: start.Add(3 * time.Second),
+		WorkingDir: "/src", BufferStart: 1, BufferEnd: 3, Marked: true,
+	})
+	sess.AddCommand(capture.CommandRecord{Command: "ls", StartTime: start.Add(time.Minute), BufferStart: 3})
+	buf.Write([]byte("a.txt"))
+
+	m := NewModel(nil, buf, sess, nil)
+	steps := m.replaySteps()
+	if len(steps) != 2 {
+		t.Fatalf("replaySteps() = %d steps", len(steps))
+	}
+	if steps[0].ExitCode != 2 || !steps[0].End.Equal(start.Add(3*time.Second)) ||
+		strings.Join(steps[0].Output, "|") != "main.c:3: error|make: *** Error 2" {
+		t.Fatalf("unexpected marked step %+v", steps[0])
+	}
+	// Without prompt marks there is no exit code, and the output runs to
+	// the next command or the end of the buffer.
+	if steps[1].ExitCode != -1 || strings.Join(steps[1].Output, "|") != "a.txt" {
+		t.Fatalf("unexpected unmarked step %+v", steps[1])
+	}
+
+	if _, cmd := NewModel(nil, buffer.New(10), capture.NewSessionContext(), nil).openReplay(); cmd == nil {
+		t.Fatal("expected a status message for an empty session")
+	}
+}
+
+func TestReplayContext_StopsAtThePosition(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	steps := []scrubber.Step{
+		{Command: "cd app", Dir: "/src/app", Start: start, End: start},
+		{Command: "npm test", Dir: "/src/app", ExitCode: -1, Start: start.Add(time.Second), Output: []string{"FAIL login"}},
+	}
+	ctx := replayContext(steps, "/home")
+	if ctx.CurrentDir != "/src/app" {
+		t.Fatalf("CurrentDir = %q", ctx.CurrentDir)
+	}
+	if got := ctx.Buffer.ExportAsText(); got != "$ cd app\n$ npm test\nFAIL login" {
+		t.Fatalf("unexpected buffer %q", got)
+	}
+	history := ctx.Session.GetHistory()
+	if len(history) != 2 || history[1].BufferEnd != 0 || history[1].BufferStart != 1 {
+		t.Fatalf("expected the running command left open, got %+v", history)
+	}
+
+	q := replayQuestion(steps)
+	if !strings.Contains(q, "command 2 (`npm test`) was still running") {
+		t.Fatalf("unexpected question %q", q)
+	}
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// languageFieldKey routes option picker selections made from the /language
+// command. The settings panel uses its own "response_language" key so its
+// choice stays unsaved until the panel is saved.
+const languageFieldKey = "language_command"
+
+func (m Model) openLanguagePicker() (Model, tea.Cmd) {
+	current := strings.TrimSpace(loadUIConfig().ResponseLanguage)
+	if current == "" {
+		current = ai.ResponseLanguageAuto
+	}
+	slog.Info("language_picker_open", "current", current)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Response Language", languageFieldKey, ai.ResponseLanguageOptions, current)
+	return m, nil
+}
+
+// saveResponseLanguage persists the /language choice; it applies from the
+// next /explain or chat request.
+func (m Model) saveResponseLanguage(value string) (Model, tea.Cmd) {
+	language := strings.TrimSpace(value)
+	if strings.EqualFold(language, ai.ResponseLanguageAuto) {
+		language = ""
+	}
+
+	path := config.GetConfigPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		slog.Error("language_save_error", "error", err)
+		return m, m.flashStatus("Could not load config: " + err.Error())
+	}
+	cfg.ResponseLanguage = language
+	if err := config.Save(path, cfg); err != nil {
+		slog.Error("language_save_error", "error", err)
+		return m, m.flashStatus("Could not save config: " + err.Error())
+	}
+
+	slog.Info("language_save", "response_language", language)
+	if language == "" {
+		return m, m.flashStatus("AI answers will follow the language of your question")
+	}
+	return m, m.flashStatus("AI answers will be written in " + language)
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+)
+
+func TestModel_LanguageCommandOpensPicker(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	m, _ = m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/language"})
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected /language to open the option picker")
+	}
+}
+
+func TestModel_LanguagePickerSavesConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	m, cmd := m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: languageFieldKey, Value: "German"})
+	if cmd == nil {
+		t.Fatal("expected a status toast command")
+	}
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.ResponseLanguage != "German" {
+		t.Fatalf("expected response_language German, got %q", cfg.ResponseLanguage)
+	}
+
+	_, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: languageFieldKey, Value: "auto"})
+	cfg, err = config.Load(config.GetConfigPath())
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.ResponseLanguage != "" {
+		t.Fatalf("expected auto to clear response_language, got %q", cfg.ResponseLanguage)
+	}
+}
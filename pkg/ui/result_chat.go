@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"wtf_cli/pkg/ui/components/result"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// handleResultPanelChat continues from a result panel in the sidebar: the
+// result becomes an assistant message, so follow-up questions see it as
+// part of the conversation without running the command again. It is
+// preceded by a bracketed user turn, like /explain's, because providers
+// expect the conversation to open with the user.
+func (m Model) handleResultPanelChat(msg result.ResultPanelChatMsg) (Model, tea.Cmd) {
+	if m.hasActiveStream() {
+		return m, m.flashStatus("AI is busy; try again when the answer finishes")
+	}
+	slog.Info("result_panel_to_chat", "title", msg.Title, "len", len(msg.Content))
+	s := m.ensureSidebar()
+	s.AppendUserMessage(fmt.Sprintf("[Opened the %q result]", msg.Title))
+	s.StartAssistantMessageWithContent(msg.Content)
+	m.showSidebar("result_panel")
+	m.refreshSidebar()
+	return m, nil
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/result"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestModel_ResultPanelConvertsToChat(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = newModel.(Model)
+	m.resultPanel.Show("Tasks", "make build\nmake test")
+
+	newModel, cmd := m.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("expected c to convert the result")
+	}
+	msg, ok := cmd().(result.ResultPanelChatMsg)
+	if !ok {
+		t.Fatalf("expected ResultPanelChatMsg, got %T", cmd())
+	}
+	if m.resultPanel.IsVisible() {
+		t.Fatal("expected the result panel to close")
+	}
+
+	newModel, _ = m.Update(msg)
+	m = newModel.(Model)
+	if !m.sidebar.IsVisible() || !m.sidebar.IsFocusedOnInput() {
+		t.Fatal("expected the sidebar open with the input focused")
+	}
+	msgs := m.sidebar.GetMessages()
+	if len(msgs) != 2 || msgs[0].Role != "user" {
+		t.Fatalf("expected a user turn then the result, got %+v", msgs)
+	}
+	if msgs[1].Role != "assistant" || msgs[1].Content != "make build\nmake test" {
+		t.Fatalf("expected the result as the assistant message, got %+v", msgs[1])
+	}
+}
+
+func TestModel_ResultPanelChatWaitsForStream(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.beginStreamRun()
+
+	newModel, cmd := m.Update(result.ResultPanelChatMsg{Title: "Tasks", Content: "make build"})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("expected a busy notice")
+	}
+	if m.sidebar != nil && len(m.sidebar.GetMessages()) != 0 {
+		t.Fatal("expected the conversation untouched while a stream runs")
+	}
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/input"
+	"wtf_cli/pkg/ui/terminal"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sessionEndFieldKey routes the choice offered once the shell has exited.
+const sessionEndFieldKey = "session_end"
+
+// End-of-session choices, in display order.
+const (
+	sessionEndRestart = "Restart shell"
+	sessionEndAsk     = "Ask the AI about what happened"
+	sessionEndExport  = "Export session"
+	sessionEndQuit    = "Quit"
+)
+
+// openSessionEnd asks what to do now that the shell, or the exec command,
+// has exited. The terminal, its buffer and the conversation stay as they
+// were; keys typed in the dead terminal bring the choice back.
+func (m Model) openSessionEnd() (Model, tea.Cmd) {
+	m.shellExited = true
+	m.fullScreenMode = false
+	m.exitPending = false
+
+	options := make([]string, 0, 4)
+	if m.paneSpawner != nil {
+		options = append(options, sessionEndRestart)
+	}
+	options = append(options, sessionEndAsk, sessionEndExport, sessionEndQuit)
+
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	selected := ""
+	if m.exec != nil && m.exec.status != 0 {
+		selected = sessionEndAsk
+	}
+	optionPicker.Show(m.sessionEndTitle(), sessionEndFieldKey, options, selected)
+	return m, nil
+}
+
+// handleSessionEnd acts on the choice picked in openSessionEnd.
+func (m Model) handleSessionEnd(choice string) (Model, tea.Cmd) {
+	slog.Info("session_end_select", "choice", choice)
+	switch choice {
+	case sessionEndRestart:
+		return m.restartShell()
+	case sessionEndAsk:
+		m.showSidebar("session_end")
+		return m, nil
+	case sessionEndExport:
+		now := time.Now()
+		path, err := writeSessionExport(m.sessionExportDir(), m.sessionExport(now), now)
+		if err != nil {
+			slog.Error("session_export_error", "error", err)
+			m, cmd := m.openSessionEnd()
+			return m, tea.Batch(cmd, m.flashStatus("Could not export session: "+err.Error()))
+		}
+		slog.Info("session_export_saved", "path", path)
+		m, cmd := m.openSessionEnd()
+		return m, tea.Batch(cmd, m.flashStatus("Session saved to "+path))
+	case sessionEndQuit:
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// restartShell starts a new shell in place of the one that exited, closing
+// the old one's PTY and reaping it. The buffer, viewport and command history
+// carry over, so the AI still sees what led up to the exit.
+func (m Model) restartShell() (Model, tea.Cmd) {
+	shell, err := m.paneSpawner()
+	if err != nil {
+		slog.Error("shell_restart_error", "error", err)
+		m, cmd := m.openSessionEnd()
+		return m, tea.Batch(cmd, m.flashStatus("Could not start a shell: "+err.Error()))
+	}
+
+	release := releaseShell(m.shellProcess, m.ptyFile)
+	m.shellExited = false
+	m.exec = nil // The exec command is done; a shell takes its place
+	m.ptyFile = shell.PTY
+	m.shellProcess = shell.Process
+	m.cwdFunc = shell.Cwd
+	m.environFunc = shell.Environ
+	m.jobsFunc = shell.Jobs
+	m.jobs = nil
+	m.inputHandler = input.NewInputHandler(shell.PTY)
+	m.inputHandler.SetReadOnly(m.readOnly)
+	m.ptyNormalizer = terminal.NewNormalizer()
+	m.rawLines = &rawLineLog{}
+	m.promptZones = promptZones{}
+	m.repl = &capture.REPLTracker{}
+	m.altScreenState = terminal.NewAltScreenState()
+	m.ptyBatchBuffer = nil
+	m.setTerminalFocused(true)
+	m.applyLayout()
+	slog.Info("shell_restart", "dir", m.currentDir)
+	return m, tea.Batch(release, m.flashStatus("Shell restarted"), listenToPTY(m.ptyFile))
+}
+
+// sessionExportDir is where Export session saves: the shell's last
+// directory, or the working directory when it is unknown.
+func (m Model) sessionExportDir() string {
+	if m.currentDir != "" {
+		return m.currentDir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// sessionExport renders the terminal buffer and the conversation as
+// Markdown.
+func (m Model) sessionExport(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# wtf_cli session, %s\n\n", now.Format("2006-01-02 15:04"))
+	b.WriteString("## Terminal\n\n```\n")
+	if m.buffer != nil {
+		if text := ansi.Strip(m.buffer.ExportAsText()); text != "" {
+			b.WriteString(text)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("```\n")
+
+	if m.sidebar == nil {
+		return b.String()
+	}
+	messages := m.sidebar.GetMessages()
+	if len(messages) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n## Conversation\n")
+	for _, msg := range messages {
+		role := "Assistant"
+		if msg.Role == "user" {
+			role = "You"
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", role, strings.TrimSpace(msg.Content))
+	}
+	return b.String()
+}
+
+// writeSessionExport saves content in dir under a name stamped with now.
+func writeSessionExport(dir, content string, now time.Time) (string, error) {
+	path := filepath.Join(dir, "wtf-session-"+now.Format("20060102-150405")+".md")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
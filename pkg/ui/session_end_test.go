@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/testutils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSessionEnd_ShellExitOffersChoices(t *testing.T) {
+	m, leftPTY, _ := newSplitTestModel(t)
+
+	m, cmd := m.handlePTYError(ptyErrorMsg{err: io.EOF, pty: leftPTY})
+	if cmd != nil {
+		t.Fatal("Expected the shell exit not to quit")
+	}
+	if !m.shellExited || m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the end-of-session choices")
+	}
+	view := m.optionPicker.View()
+	for _, option := range []string{sessionEndRestart, sessionEndAsk, sessionEndExport, sessionEndQuit} {
+		if !strings.Contains(view, option) {
+			t.Errorf("Expected %q offered, got:\n%s", option, view)
+		}
+	}
+
+	// Esc leaves the terminal on screen; typing in it asks again.
+	m.optionPicker.Hide()
+	m, _ = m.handleKeyPress(testutils.NewKeyPressMsg('l'))
+	if !m.optionPicker.IsVisible() {
+		t.Fatal("Expected a key in the exited terminal to reopen the choices")
+	}
+}
+
+func TestSessionEnd_RestartKeepsBuffer(t *testing.T) {
+	m, leftPTY, rightPTY := newSplitTestModel(t)
+	m.buffer.Write([]byte("make: *** [all] Error 2"))
+	m, _ = m.handlePTYError(ptyErrorMsg{err: io.EOF, pty: leftPTY})
+
+	m, cmd := m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: sessionEndFieldKey, Value: sessionEndRestart})
+	if cmd == nil {
+		t.Fatal("Expected the new shell to be read")
+	}
+	if m.shellExited || m.ptyFile != rightPTY {
+		t.Fatal("Expected the new shell to take over the terminal")
+	}
+	if got := m.buffer.ExportAsText(); !strings.Contains(got, "Error 2") {
+		t.Fatalf("Expected the buffer to carry over, got %q", got)
+	}
+
+	m, _ = m.handleKeyPress(testutils.NewKeyPressMsg('l'))
+	if m.optionPicker.IsVisible() {
+		t.Fatal("Expected keys to reach the restarted shell")
+	}
+}
+
+func TestSessionEnd_RestartClosesTheExitedShell(t *testing.T) {
+	firstPTY, _ := newPipePTY(t)
+	m := NewModel(firstPTY, buffer.New(100), capture.NewSessionContext(), nil)
+	var processes []*fakeShellProcess
+	m.SetPaneSpawner(func() (PaneShell, error) {
+		ptyFile, _ := newPipePTY(t)
+		process := &fakeShellProcess{}
+		processes = append(processes, process)
+		return PaneShell{PTY: ptyFile, Buffer: buffer.New(100), Process: process}, nil
+	})
+	restart := func() {
+		t.Helper()
+		m, _ = m.handlePTYError(ptyErrorMsg{err: io.EOF, pty: m.ptyFile})
+		m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: sessionEndFieldKey, Value: sessionEndRestart})
+	}
+
+	restart()
+	if _, err := firstPTY.Write([]byte("x")); err == nil {
+		t.Fatal("Expected the first shell's PTY closed on restart")
+	}
+	if m.shellProcess != processes[0] {
+		t.Fatal("Expected the restarted shell's process kept")
+	}
+
+	restart()
+	if !processes[0].closed {
+		t.Fatal("Expected the exited restarted shell closed")
+	}
+	m.CloseShells()
+	if !processes[1].closed || !processes[1].waited {
+		t.Fatal("Expected the running shell closed and reaped at exit")
+	}
+}
+
+func TestSessionEnd_AskOpensSidebar(t *testing.T) {
+	m, leftPTY, _ := newSplitTestModel(t)
+	m, _ = m.handlePTYError(ptyErrorMsg{err: io.EOF, pty: leftPTY})
+
+	m, _ = m.handleOptionPickerSelect(picker.OptionPickerSelectMsg{FieldKey: sessionEndFieldKey, Value: sessionEndAsk})
+	if !m.sidebar.IsVisible() || m.terminalFocused {
+		t.Fatal("Expected the chat sidebar to be focused")
+	}
+}
+
+func TestSessionEnd_ExportWritesTerminalAndConversation(t *testing.T) {
+	dir := t.TempDir()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = dir
+	m.buffer.Write([]byte("\x1b[31mpanic: boom\x1b[0m"))
+	m.ensureSidebar().AppendUserMessage("why did it crash?")
+	m.sidebar.StartAssistantMessageWithContent("A nil map was written.")
+
+	m, _ = m.handleSessionEnd(sessionEndExport)
+	matches, _ := filepath.Glob(filepath.Join(dir, "wtf-session-*.md"))
+	if len(matches) != 1 {
+		t.Fatalf("Expected one export, got %v", matches)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	for _, want := range []string{"panic: boom\n", "### You\n\nwhy did it crash?", "### Assistant\n\nA nil map was written."} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in export, got:\n%s", want, data)
+		}
+	}
+	if !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the choices to stay open after exporting")
+	}
+}
+
+func TestSessionEnd_Quit(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	_, cmd := m.handleSessionEnd(sessionEndQuit)
+	if cmd == nil {
+		t.Fatal("Expected a quit command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("Expected Quit to end the program")
+	}
+}
+
+func TestWriteSessionExportName(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.Local)
+	path, err := writeSessionExport(t.TempDir(), "x", now)
+	if err != nil {
+		t.Fatalf("writeSessionExport: %v", err)
+	}
+	if got := filepath.Base(path); got != "wtf-session-20260304-050607.md" {
+		t.Fatalf("name = %q", got)
+	}
+}
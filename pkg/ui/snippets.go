@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"wtf_cli/pkg/historysync"
+	"wtf_cli/pkg/shellquote"
+	"wtf_cli/pkg/ui/components/picker"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// snippetsFieldKey routes option picker selections and actions made from
+// /snippets.
+const snippetsFieldKey = "snippets"
+
+// Action keys of the /snippets picker.
+const (
+	snippetRename = "r"
+	snippetDelete = "d"
+	snippetPin    = "p"
+)
+
+var snippetActions = []picker.OptionAction{
+	{Key: snippetRename, Label: "Rename", Input: true, Prefill: snippetName},
+	{Key: snippetDelete, Label: "Delete", Confirm: true},
+	{Key: snippetPin, Label: "Pin"},
+}
+
+// snippetPinMark prefixes a pinned script in /snippets.
+const snippetPinMark = "📌 "
+
+func (m Model) scriptsDirectory() string {
+	if m.scriptsDir != "" {
+		return m.scriptsDir
+	}
+	return historysync.DefaultScriptsDir()
+}
+
+func (m Model) pinnedScriptsFile() string {
+	if m.pinnedScriptsPath != "" {
+		return m.pinnedScriptsPath
+	}
+	return historysync.DefaultPinnedScriptsPath()
+}
+
+// openSnippetsPicker lists the saved scripts with current selected, or
+// the first when it is gone. It shows the picker again after every action,
+// so the list stays in step with the directory.
+func (m Model) openSnippetsPicker(current string) (Model, tea.Cmd) {
+	scripts, err := historysync.ListScripts(m.scriptsDirectory(), m.pinnedScriptsFile())
+	if err != nil {
+		return m, m.flashStatus("Could not list scripts: " + err.Error())
+	}
+	if len(scripts) == 0 {
+		if m.optionPicker != nil {
+			m.optionPicker.Hide() // The last one was just deleted
+		}
+		return m, m.flashStatus("No saved scripts; /export saves the chat's commands to ~/.wtf_cli/scripts")
+	}
+	options := make([]string, 0, len(scripts))
+	selected := ""
+	for _, s := range scripts {
+		label := snippetOptionLabel(s)
+		if s.Name == current {
+			selected = label
+		}
+		options = append(options, label)
+	}
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Saved scripts", snippetsFieldKey, options, selected)
+	optionPicker.SetActions(snippetActions)
+	return m, nil
+}
+
+// snippetOptionLabel renders a script as "📌 deploy.sh · 2026-10-16 14:02".
+func snippetOptionLabel(s historysync.ScriptFile) string {
+	label := s.Name + " · " + s.Modified.Format("2006-01-02 15:04")
+	if s.Pinned {
+		label = snippetPinMark + label
+	}
+	return label
+}
+
+// snippetName returns the file name in a /snippets label.
+func snippetName(label string) string {
+	label = strings.TrimPrefix(label, snippetPinMark)
+	if i := strings.LastIndex(label, " · "); i >= 0 {
+		return label[:i]
+	}
+	return label
+}
+
+// snippetByLabel finds the script an option of /snippets stands for.
+func (m Model) snippetByLabel(label string) (historysync.ScriptFile, bool) {
+	scripts, _ := historysync.ListScripts(m.scriptsDirectory(), m.pinnedScriptsFile())
+	for _, s := range scripts {
+		if snippetOptionLabel(s) == label {
+			return s, true
+		}
+	}
+	return historysync.ScriptFile{}, false
+}
+
+// useSnippet puts the picked script's path at the shell prompt for the
+// user to run.
+func (m Model) useSnippet(label string) (Model, tea.Cmd) {
+	s, ok := m.snippetByLabel(label)
+	if !ok {
+		return m, nil
+	}
+	slog.Info("snippets_picker_select", "name", s.Name)
+	m.replacePromptCommand(shellquote.Quote(filepath.Join(m.scriptsDirectory(), s.Name)))
+	return m, nil
+}
+
+// handleSnippetAction renames, deletes, pins or unpins the script an
+// action key was used on, then lists the scripts again.
+func (m Model) handleSnippetAction(msg picker.OptionPickerActionMsg) (Model, tea.Cmd) {
+	s, ok := m.snippetByLabel(msg.Value)
+	if !ok {
+		return m.openSnippetsPicker("")
+	}
+	dir, pins := m.scriptsDirectory(), m.pinnedScriptsFile()
+	var status tea.Cmd
+	switch msg.Action {
+	case snippetRename:
+		name := strings.TrimSpace(msg.Input)
+		if err := historysync.RenameScript(dir, pins, s.Name, name); err != nil {
+			status = m.flashStatus("Could not rename: " + err.Error())
+			break
+		}
+		slog.Info("snippet_rename", "from", s.Name, "to", name)
+		status = m.flashStatus(fmt.Sprintf("Renamed %s to %s", s.Name, name))
+		s.Name = name
+	case snippetDelete:
+		if err := historysync.DeleteScript(dir, pins, s.Name); err != nil {
+			status = m.flashStatus("Could not delete: " + err.Error())
+			break
+		}
+		slog.Info("snippet_delete", "name", s.Name)
+		status = m.flashStatus("Deleted " + s.Name)
+	case snippetPin:
+		if err := historysync.SetScriptPinned(pins, s.Name, !s.Pinned); err != nil {
+			status = m.flashStatus("Could not pin: " + err.Error())
+			break
+		}
+		slog.Info("snippet_pin", "name", s.Name, "pinned", !s.Pinned)
+	}
+	m, cmd := m.openSnippetsPicker(s.Name)
+	return m, tea.Batch(status, cmd)
+}
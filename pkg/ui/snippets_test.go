@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestModel_SnippetsRenamePinAndDelete(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.scriptsDir = t.TempDir()
+	m.pinnedScriptsPath = filepath.Join(t.TempDir(), "pinned_scripts.json")
+	if err := os.WriteFile(filepath.Join(m.scriptsDir, "wtf-commands.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/snippets"})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected /snippets to open the picker")
+	}
+	// Every action runs through the picker the way a key press would.
+	act := func(keys ...string) {
+		t.Helper()
+		var msg any
+		for _, key := range keys {
+			if cmd := m.optionPicker.Update(testutils.NewTextKeyPressMsg(key)); cmd != nil {
+				msg = cmd()
+			}
+		}
+		if cmd := m.optionPicker.Update(testutils.TestKeyEnter); cmd != nil {
+			msg = cmd()
+		}
+		action, ok := msg.(picker.OptionPickerActionMsg)
+		if !ok {
+			t.Fatalf("expected an action for %v, got %T", keys, msg)
+		}
+		newModel, _ := m.Update(action)
+		m = newModel.(Model)
+	}
+
+	act("r", "x") // Rename wtf-commands.sh to wtf-commands.shx
+	if _, err := os.Stat(filepath.Join(m.scriptsDir, "wtf-commands.shx")); err != nil {
+		t.Fatalf("expected the script renamed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.scriptsDir, "wtf-commands.sh")); !os.IsNotExist(err) {
+		t.Fatal("expected the old name gone")
+	}
+
+	if cmd := m.optionPicker.Update(testutils.NewTextKeyPressMsg("p")); cmd != nil {
+		newModel, _ := m.Update(cmd())
+		m = newModel.(Model)
+	}
+	if view := m.optionPicker.View(); !strings.Contains(view, snippetPinMark+"wtf-commands.shx") {
+		t.Fatalf("expected the script shown pinned:\n%s", view)
+	}
+
+	act("d") // Enter confirms the delete
+	if _, err := os.Stat(filepath.Join(m.scriptsDir, "wtf-commands.shx")); !os.IsNotExist(err) {
+		t.Fatal("expected the script deleted")
+	}
+	if m.optionPicker.IsVisible() {
+		t.Fatal("expected the picker closed once no script is left")
+	}
+}
+
+func TestSnippetName(t *testing.T) {
+	for label, want := range map[string]string{
+		"deploy.sh · 2026-10-16 14:02":                 "deploy.sh",
+		snippetPinMark + "a · b.sh · 2026-10-16 14:02": "a · b.sh",
+	} {
+		if got := snippetName(label); got != want {
+			t.Errorf("snippetName(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// SetStartupQuestion opens the chat sidebar with question in its input,
+// for --ask. askStartupQuestion sends it once the shell is ready.
+func (m *Model) SetStartupQuestion(question string) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return
+	}
+	m.startupQuestion = question
+	// The first window size lays the sidebar out; there is no size yet.
+	s := m.ensureSidebar()
+	s.Show()
+	s.SetInput(question)
+	s.FocusInput()
+	m.setTerminalFocused(false)
+}
+
+// askStartupQuestion sends the --ask question once the window is laid out,
+// the shell has printed and its directory is known, so the request carries
+// the same context as a question typed by hand. Sending anything from the
+// sidebar first drops it.
+func (m Model) askStartupQuestion() (Model, tea.Cmd) {
+	if m.startupQuestion == "" || !m.ready || !m.startupPTYOutputSeen || m.currentDir == "" {
+		return m, nil
+	}
+	question := m.startupQuestion
+	m.startupQuestion = ""
+	slog.Info("startup_ask", "len", len(question))
+	m.sidebar.SetInput("")
+	return m.handleChatSubmit(sidebar.ChatSubmitMsg{Content: question})
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestModel_StartupQuestionWaitsForShell(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = t.TempDir()
+	trustWorkspace(t, &m, ai.TrustUndecided)
+	m.SetStartupQuestion("  why did my last deploy fail  ")
+	if !m.sidebar.IsVisible() || m.terminalFocused {
+		t.Fatal("expected --ask to open the sidebar focused")
+	}
+
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = newModel.(Model)
+	newModel, _ = m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	if len(m.sidebar.GetMessages()) != 0 {
+		t.Fatal("expected the question held until the shell prints")
+	}
+
+	newModel, _ = m.Update(ptyOutputMsg{data: []byte("$ ")})
+	m = newModel.(Model)
+	newModel, _ = m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	messages := m.sidebar.GetMessages()
+	if len(messages) != 1 || messages[0].Content != "why did my last deploy fail" {
+		t.Fatalf("expected the question sent once the shell is ready, got %+v", messages)
+	}
+
+	newModel, _ = m.Update(directoryUpdateMsg{})
+	m = newModel.(Model)
+	if len(m.sidebar.GetMessages()) != 1 {
+		t.Fatal("expected the question sent only once")
+	}
+}
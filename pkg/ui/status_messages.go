@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Sources of status bar messages. Each owns one message, so a feature
+// clearing its own message never takes another's with it.
+const (
+	statusSourceFlash      = "flash"       // flashStatus toasts
+	statusSourceExit       = "exit"        // Ctrl+D confirmation
+	statusSourcePanes      = "panes"       // Ctrl+W key hint
+	statusSourceHint       = "hint"        // Typo and install hints
+	statusSourceLocalModel = "local_model" // Local model load time
+	statusSourceSelection  = "selection"   // Copied selection
+	statusSourceTrigger    = "trigger"     // Status triggers
+	statusSourceConfirm    = "confirm"     // Destructive typed command
+	statusSourceSyntax     = "syntax"      // Syntax error in the typed command
+
+	// externalStatusPrefix namespaces sources posted through
+	// StatusMessageMsg, so plugins cannot replace built-in messages.
+	externalStatusPrefix = "ext:"
+)
+
+// StatusMessageMsg posts a status bar message from outside the model, e.g.
+// a plugin, script or long-running job sending it to the program. Source
+// identifies the poster: a later message from the same source replaces
+// this one, and empty Text clears it. A message with a TTL is removed once
+// it expires; without one it stays until replaced or cleared.
+type StatusMessageMsg struct {
+	Source   string
+	Text     string
+	Priority statusbar.Priority
+	TTL      time.Duration
+}
+
+// statusExpiredMsg removes a message posted with a TTL, unless its source
+// has posted something else since.
+type statusExpiredMsg struct {
+	source string
+	text   string
+}
+
+// postStatus shows msg in the status bar, for ttl when it is positive.
+func (m *Model) postStatus(msg statusbar.Message, ttl time.Duration) tea.Cmd {
+	if m.statusBar == nil {
+		return nil
+	}
+	m.statusBar.Post(msg)
+	if ttl <= 0 || msg.Text == "" {
+		return nil
+	}
+	return tea.Tick(ttl, func(time.Time) tea.Msg {
+		return statusExpiredMsg{source: msg.Source, text: msg.Text}
+	})
+}
+
+// clearStatus removes the message of source.
+func (m *Model) clearStatus(source string) {
+	if m.statusBar != nil {
+		m.statusBar.Clear(source)
+	}
+}
+
+// clearStatusText removes the message of source if it still reads text.
+func (m *Model) clearStatusText(source, text string) {
+	if m.statusBar != nil {
+		m.statusBar.ClearText(source, text)
+	}
+}
+
+func (m Model) handleStatusExpired(msg statusExpiredMsg) (Model, tea.Cmd) {
+	m.clearStatusText(msg.source, msg.text)
+	return m, nil
+}
+
+func (m Model) handleStatusMessage(msg StatusMessageMsg) (Model, tea.Cmd) {
+	source := strings.TrimSpace(msg.Source)
+	if source == "" {
+		source = "default"
+	}
+	slog.Debug("status_message", "source", source, "priority", int(msg.Priority), "ttl", msg.TTL, "clear", msg.Text == "")
+	return m, m.postStatus(statusbar.Message{
+		Source:   externalStatusPrefix + source,
+		Text:     strings.Join(strings.Fields(ansi.Strip(msg.Text)), " "),
+		Priority: msg.Priority,
+	}, msg.TTL)
+}
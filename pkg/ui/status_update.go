@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/ui/components/statusbar"
 	"wtf_cli/pkg/ui/components/welcome"
 	"wtf_cli/pkg/updatecheck"
 	"wtf_cli/pkg/version"
@@ -40,13 +41,11 @@ type exitConfirmTimeoutMsg struct {
 	id int
 }
 
-type clearStatusMsgMsg struct{}
-
 func (m Model) handleCtrlDPressed() (Model, tea.Cmd) {
 	if m.exitPending {
 		m.exitPending = false
-		m.statusBar.SetMessage("")
-		if m.inputHandler != nil {
+		m.clearStatus(statusSourceExit)
+		if m.inputHandler != nil && !m.readOnly {
 			if err := m.inputHandler.SendToPTY([]byte{4}); err != nil {
 				slog.Error("exit_send_eof_error", "error", err)
 			}
@@ -56,7 +55,7 @@ func (m Model) handleCtrlDPressed() (Model, tea.Cmd) {
 	m.exitPending = true
 	m.exitConfirmID++
 	confirmID := m.exitConfirmID
-	m.statusBar.SetMessage("Press Ctrl+D again to exit")
+	m.postStatus(statusbar.Message{Source: statusSourceExit, Text: "Press Ctrl+D again to exit", Priority: statusbar.PriorityHigh}, 0)
 	return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg {
 		return exitConfirmTimeoutMsg{id: confirmID}
 	})
@@ -65,14 +64,7 @@ func (m Model) handleCtrlDPressed() (Model, tea.Cmd) {
 func (m Model) handleExitConfirmTimeout(msg exitConfirmTimeoutMsg) (Model, tea.Cmd) {
 	if m.exitPending && msg.id == m.exitConfirmID {
 		m.exitPending = false
-		m.statusBar.SetMessage("")
-	}
-	return m, nil
-}
-
-func (m Model) handleClearStatusMsg() (Model, tea.Cmd) {
-	if m.statusBar != nil && m.statusBar.GetMessage() == selectedTextCopiedMessage {
-		m.statusBar.SetMessage("")
+		m.clearStatus(statusSourceExit)
 	}
 	return m, nil
 }
@@ -84,12 +76,19 @@ func (m Model) handleDirectoryUpdate() (Model, tea.Cmd) {
 			m.currentDir = cwd
 		}
 	}
-	// Always resolve git branch on every tick — the resolver is cheap
-	// (reads .git/HEAD) and this ensures branch changes from commands
-	// like `git checkout` are reflected promptly.
+	if m.sidebar != nil {
+		m.sidebar.SetWorkingDir(m.currentDir)
+	}
+	// Resolve the git status on every tick. The resolver answers from a
+	// cache that git changes and finished commands invalidate, so this only
+	// reads the repository when something changed.
 	branchCmd := resolveGitBranchCmd(m.currentDir, m.gitBranchResolver)
+	notifyCmd := m.checkPendingCommand()
+	m.checkAway()
+	indexCmd := m.refreshWorkspaceIndex()
+	m, askCmd := m.askStartupQuestion()
 	// Schedule next update
-	return m, tea.Batch(tickDirectory(), branchCmd)
+	return m, tea.Batch(tickDirectory(), branchCmd, notifyCmd, m.pollJobs(), indexCmd, askCmd)
 }
 
 func (m Model) handleGitBranch(msg gitBranchMsg) (Model, tea.Cmd) {
@@ -124,6 +123,14 @@ func (m Model) handleUpdateCheck(msg updateCheckMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+// invalidateGitStatus makes the next tick read git again after a command,
+// which may have edited files the git watch does not see.
+func (m *Model) invalidateGitStatus() {
+	if m.gitCache != nil {
+		m.gitCache.Invalidate(m.currentDir)
+	}
+}
+
 func resolveGitBranchCmd(dir string, resolver func(string) string) tea.Cmd {
 	trimmed := strings.TrimSpace(dir)
 	if trimmed == "" || resolver == nil {
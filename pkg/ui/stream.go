@@ -9,6 +9,7 @@ import (
 
 	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/commands"
+	"wtf_cli/pkg/config"
 	"wtf_cli/pkg/ui/components/continueprompt"
 	"wtf_cli/pkg/ui/components/sidebar"
 	"wtf_cli/pkg/ui/components/toolapproval"
@@ -20,11 +21,18 @@ import (
 // - First delta refreshes immediately; follow-up deltas are batched until streamThrottleFlushMsg.
 // - Stream listener is re-armed after every stream event, including tool-approval events.
 // - Tool approval modal remains topmost overlay.
+// - The activity spinner ticks only while the sidebar shows an activity.
 
 type streamThrottleFlushMsg struct {
 	streamID int
 }
 
+// streamActivityTickMsg advances the spinner shown next to the model's
+// activity (reasoning, calling a tool) above the sidebar input.
+type streamActivityTickMsg struct{}
+
+const streamActivityInterval = 100 * time.Millisecond
+
 type streamStartOrigin int
 
 const (
@@ -50,22 +58,23 @@ type contextStreamingHandler interface {
 }
 
 func (m Model) handleStreamStartResult(msg streamStartResultMsg) (Model, tea.Cmd) {
-	if msg.streamID != m.streamID {
+	if msg.streamID != m.aiJobs.id {
 		return m, nil
 	}
-	m.streamStartPending = false
+	m.aiJobs.startPending = false
+	m.finishStreamProgress()
 	if msg.err != nil {
 		slog.Error("wtf_stream_start_error", "error", msg.err)
 		if m.sidebar != nil {
 			m.sidebar.SetStreaming(false)
 			m.clearStreamPlaceholder()
 			m.sidebar.AppendErrorMessage(msg.err.Error())
-			m.sidebar.RefreshView()
+			m.refreshSidebar()
 		} else {
 			m.resultPanel.Show("Error", fmt.Sprintf("Error: %v", msg.err))
 		}
 		m.endStreamRun()
-		return m, nil
+		return m, m.notifyStreamError(msg.err)
 	}
 
 	if msg.stream == nil {
@@ -74,7 +83,7 @@ func (m Model) handleStreamStartResult(msg streamStartResultMsg) (Model, tea.Cmd
 			m.clearStreamPlaceholder()
 			if msg.origin == streamOriginExplain && msg.result != nil {
 				m.sidebar.StartAssistantMessageWithContent(msg.result.Content)
-				m.sidebar.RefreshView()
+				m.refreshSidebar()
 			}
 		} else if msg.origin == streamOriginExplain && msg.result != nil {
 			m.resultPanel.Show(msg.result.Title, msg.result.Content)
@@ -83,7 +92,7 @@ func (m Model) handleStreamStartResult(msg streamStartResultMsg) (Model, tea.Cmd
 		return m, nil
 	}
 
-	m.wtfStream = msg.stream
+	m.aiJobs.stream = msg.stream
 	return m, m.continueStreamListen()
 }
 
@@ -163,7 +172,7 @@ func (m Model) handleContinuePromptDecision(msg continueprompt.DecisionMsg) (Mod
 }
 
 func (m Model) handleChatSubmit(msg sidebar.ChatSubmitMsg) (Model, tea.Cmd) {
-	if m.sidebar == nil || msg.Content == "" {
+	if msg.Content == "" {
 		return m, nil
 	}
 
@@ -171,17 +180,44 @@ func (m Model) handleChatSubmit(msg sidebar.ChatSubmitMsg) (Model, tea.Cmd) {
 	if m.hasActiveStream() {
 		return m, nil
 	}
+	m.startupQuestion = ""
+	if msg.UnknownCommand != "" {
+		hint := unknownChatCommandHint(msg.UnknownCommand)
+		msg.UnknownCommand = ""
+		m, cmd := m.handleChatSubmit(msg)
+		return m, tea.Batch(cmd, m.flashStatus(hint))
+	}
 
 	// Add user message to sidebar history
-	m.sidebar.AppendUserMessage(msg.Content)
-	m.sidebar.RefreshView()
+	if msg.NoHistory {
+		m.ensureSidebar().AppendFreshUserMessage(msg.Content)
+	} else {
+		m.ensureSidebar().AppendUserMessage(msg.Content)
+	}
+	m.refreshSidebar()
+	if m.needsWorkspaceTrust() {
+		return m.askWorkspaceTrust(chatResumeMsg{preview: msg.Preview})
+	}
+	if m.budgetBlocks() {
+		return m.askBudgetOverride(chatResumeMsg{preview: msg.Preview})
+	}
+	if msg.Preview {
+		return m, m.previewChatRun()
+	}
+	return m.startChatRun()
+}
+
+// startChatRun answers the conversation in the sidebar.
+func (m Model) startChatRun() (Model, tea.Cmd) {
+	return m.startChatRunWithContext(m.commandContext())
+}
 
-	// Build context and start chat stream
-	ctx := commands.NewContext(m.buffer, m.session, m.currentDir)
+func (m Model) startChatRunWithContext(ctx *commands.Context) (Model, tea.Cmd) {
+	ctx.Params = m.sidebar.Params()
 	history := append([]ai.ChatMessage(nil), m.sidebar.GetMessages()...)
 	runCtx, streamID := m.beginStreamRun()
 	m.startStreamPlaceholder()
-	return m, startChatStreamCmd(streamID, runCtx, ctx, m.chatHandler(), history)
+	return m, tea.Batch(startChatStreamCmd(streamID, runCtx, ctx, m.chatHandler(), history), m.startStreamProgress())
 }
 
 func (m Model) handleWtfStreamEvent(msg commands.WtfStreamEvent) (Model, tea.Cmd) {
@@ -192,7 +228,7 @@ func (m Model) handleWtfStreamEvent(msg commands.WtfStreamEvent) (Model, tea.Cmd
 			m.sidebar.SetStreaming(false)
 			m.clearStreamPlaceholder()
 			m.sidebar.AppendErrorMessage(msg.Err.Error())
-			m.sidebar.RefreshView() // Ensure error is visible immediately
+			m.refreshSidebar() // Ensure error is visible immediately
 		}
 		if m.toolApproval != nil {
 			m.toolApproval.Hide()
@@ -201,7 +237,7 @@ func (m Model) handleWtfStreamEvent(msg commands.WtfStreamEvent) (Model, tea.Cmd
 			m.continuePrompt.Hide()
 		}
 		m.endStreamRun()
-		return m, nil
+		return m, m.notifyStreamError(msg.Err)
 	}
 
 	// Tool approval popup: show modal, keep listening so subsequent events
@@ -238,42 +274,95 @@ func (m Model) handleWtfStreamEvent(msg commands.WtfStreamEvent) (Model, tea.Cmd
 	}
 
 	if msg.ToolCallStart != nil {
+		activityCmd := m.setStreamActivity("running " + msg.ToolCallStart.Name)
 		if m.sidebar != nil {
 			line := formatToolCallStart(msg.ToolCallStart)
-			if m.streamPlaceholderActive {
+			if m.aiJobs.placeholder {
 				m.sidebar.SetLastMessageContent(line)
-				m.streamPlaceholderActive = false
+				m.aiJobs.placeholder = false
 			} else {
 				m.sidebar.UpdateLastMessage(line)
 			}
-			m.sidebar.RefreshView()
+			m.refreshSidebar()
 		}
-		return m, m.continueStreamListen()
+		return m, tea.Batch(activityCmd, m.continueStreamListen())
 	}
 
 	if msg.ToolCallFinished != nil {
+		activityCmd := m.setStreamActivity("thinking")
 		if m.sidebar != nil {
 			m.sidebar.UpdateLastMessage(formatToolCallSuffix(msg.ToolCallFinished))
-			m.sidebar.RefreshView()
+			m.refreshSidebar()
+		}
+		m.aiJobs.newTurnNeeded = true
+		return m, tea.Batch(activityCmd, m.continueStreamListen())
+	}
+
+	if msg.ToolCallPending != nil {
+		return m, tea.Batch(m.setStreamActivity("calling "+msg.ToolCallPending.Name), m.continueStreamListen())
+	}
+
+	if msg.Usage != nil {
+		if m.sidebar != nil {
+			m.sidebar.SetUsage(*msg.Usage)
+		}
+		return m, tea.Batch(m.recordStreamUsage(*msg.Usage), m.continueStreamListen())
+	}
+
+	if msg.Notice != "" {
+		slog.Info("wtf_stream_notice", "notice", msg.Notice)
+		if m.sidebar != nil {
+			line := sidebar.MessagePrefix("notice") + msg.Notice
+			switch {
+			case m.aiJobs.newTurnNeeded:
+				m.aiJobs.newTurnNeeded = false
+				m.sidebar.StartAssistantMessageWithContent(line)
+			case !m.replaceStreamPlaceholder(line):
+				m.sidebar.UpdateLastMessage("\n\n" + line)
+			}
+			m.refreshSidebar()
+		}
+		return m, m.continueStreamListen()
+	}
+
+	if msg.Refusal != "" {
+		slog.Info("wtf_stream_refusal", "reason", msg.Refusal)
+		if m.sidebar != nil {
+			m.sidebar.MarkLastRefused(msg.Refusal)
+			m.refreshSidebar()
 		}
-		m.toolCallNewTurnNeeded = true
 		return m, m.continueStreamListen()
 	}
 
+	if msg.Reasoning != "" {
+		activityCmd := m.setStreamActivity("reasoning")
+		if m.sidebar == nil {
+			return m, tea.Batch(activityCmd, m.continueStreamListen())
+		}
+		// Reasoning after a tool call belongs to the next turn, like its text.
+		if m.aiJobs.newTurnNeeded {
+			m.aiJobs.newTurnNeeded = false
+			m.sidebar.StartAssistantMessage()
+		}
+		m.sidebar.AppendLastReasoning(msg.Reasoning)
+		return m, tea.Batch(activityCmd, m.throttledStreamRefresh())
+	}
+
 	if m.sidebar != nil {
 		if msg.Delta != "" {
 			// Ensure streaming state is active
 			if !m.sidebar.IsStreaming() {
 				m.sidebar.SetStreaming(true)
 			}
+			typingCmd := m.setStreamActivity("")
 
 			// After a tool call, start a fresh assistant message so the
 			// tool call line and the continuation text are visually separate.
-			if m.toolCallNewTurnNeeded {
-				m.toolCallNewTurnNeeded = false
+			if m.aiJobs.newTurnNeeded {
+				m.aiJobs.newTurnNeeded = false
 				m.sidebar.StartAssistantMessageWithContent(msg.Delta)
-				m.sidebar.RefreshView()
-				return m, m.continueStreamListen()
+				m.refreshSidebar()
+				return m, tea.Batch(typingCmd, m.continueStreamListen())
 			}
 
 			// Replace placeholder on first real delta
@@ -281,99 +370,122 @@ func (m Model) handleWtfStreamEvent(msg commands.WtfStreamEvent) (Model, tea.Cmd
 				m.sidebar.UpdateLastMessage(msg.Delta)
 			}
 
-			// Throttle rendering
-			if !m.streamThrottlePending {
-				m.streamThrottlePending = true
-				// Immediate refresh on first chunk for responsiveness
-				m.sidebar.RefreshView()
-				return m, tea.Batch(
-					tea.Tick(m.streamThrottleDelay, func(time.Time) tea.Msg {
-						return streamThrottleFlushMsg{streamID: m.streamID}
-					}),
-					listenToWtfStream(m.streamID, m.wtfStream),
-				)
-			}
-			// Subsequent chunks: just listen, don't schedule another tick
-			return m, m.continueStreamListen()
+			return m, tea.Batch(typingCmd, m.throttledStreamRefresh())
 		}
 		if msg.Done {
 			m.clearStreamPlaceholder()
 			m.sidebar.SetStreaming(false)
-			m.sidebar.RefreshView() // Final refresh
+			m.refreshSidebar() // Final refresh
 			m.endStreamRun()
+			m.noteAnswerReady()
 			// A graceful stop can land right after a ToolCallFinished (e.g. the
 			// user chose Stop at the continuation prompt) with no delta to clear
 			// the flag. Reset it so the next stream's first delta replaces its
 			// placeholder instead of being treated as a post-tool continuation.
-			return m, nil
+			return m, tea.Batch(m.notifyStreamFinished(), m.requestChatTitle(), m.answerReceivedHook())
 		}
 	}
 	return m, m.continueStreamListen()
 }
 
+// throttledStreamRefresh re-renders the sidebar for a streamed delta at most
+// once per throttle delay, and keeps listening to the stream.
+func (m *Model) throttledStreamRefresh() tea.Cmd {
+	if m.aiJobs.throttlePending {
+		// Subsequent chunks: just listen, don't schedule another tick
+		return m.continueStreamListen()
+	}
+	m.aiJobs.throttlePending = true
+	// Immediate refresh on first chunk for responsiveness
+	m.refreshSidebar()
+	streamID := m.aiJobs.id
+	return tea.Batch(
+		tea.Tick(m.streamThrottleDelay, func(time.Time) tea.Msg {
+			return streamThrottleFlushMsg{streamID: streamID}
+		}),
+		listenToWtfStream(m.aiJobs.id, m.aiJobs.stream),
+	)
+}
+
+// setStreamActivity shows what the model is doing above the sidebar input
+// and starts the spinner if it is not already ticking. With no activity, a
+// streaming answer still ticks for its typing indicator.
+func (m *Model) setStreamActivity(activity string) tea.Cmd {
+	if m.sidebar == nil {
+		return nil
+	}
+	m.sidebar.SetActivity(activity)
+	if !m.streamAnimating() || m.streamActivityTicking {
+		return nil
+	}
+	m.streamActivityTicking = true
+	return tea.Tick(streamActivityInterval, func(time.Time) tea.Msg {
+		return streamActivityTickMsg{}
+	})
+}
+
+// streamAnimating reports whether the sidebar shows the activity spinner
+// or the typing indicator.
+func (m Model) streamAnimating() bool {
+	return m.sidebar.Activity() != "" || m.sidebar.IsStreaming()
+}
+
+func (m Model) handleStreamActivityTick() (Model, tea.Cmd) {
+	if m.sidebar == nil || !m.streamAnimating() {
+		m.streamActivityTicking = false
+		return m, nil
+	}
+	m.sidebar.AdvanceSpinner()
+	return m, tea.Tick(streamActivityInterval, func(time.Time) tea.Msg {
+		return streamActivityTickMsg{}
+	})
+}
+
 func (m Model) handleStreamThrottleFlush(msg streamThrottleFlushMsg) (Model, tea.Cmd) {
-	if msg.streamID != 0 && msg.streamID != m.streamID {
+	if msg.streamID != 0 && msg.streamID != m.aiJobs.id {
 		return m, nil
 	}
-	m.streamThrottlePending = false
+	m.aiJobs.throttlePending = false
 
 	// Re-render from chat messages.
 	if m.sidebar != nil {
-		m.sidebar.RefreshView() // Re-renders viewport from messages[]
+		m.refreshSidebar() // Re-renders viewport from messages[]
 	}
 	return m, nil
 }
 
 func (m Model) continueStreamListen() tea.Cmd {
-	if m.wtfStream == nil {
+	if m.aiJobs.stream == nil {
 		return nil
 	}
-	return listenToWtfStream(m.streamID, m.wtfStream)
+	return listenToWtfStream(m.aiJobs.id, m.aiJobs.stream)
 }
 
 func (m *Model) beginStreamRun() (context.Context, int) {
-	if m.streamCancel != nil {
-		m.streamCancel()
-	}
-	m.streamID++
-	runCtx, cancel := context.WithCancel(context.Background())
-	m.streamCancel = cancel
-	m.wtfStream = nil
-	m.streamStartPending = true
-	m.streamThrottlePending = false
-	m.streamPlaceholderActive = false
-	m.toolCallNewTurnNeeded = false
-	return runCtx, m.streamID
+	runCtx, id := m.aiJobs.begin()
+	if m.sidebar != nil {
+		m.sidebar.SetUsage(ai.Usage{})
+	}
+	return runCtx, id
 }
 
 func (m *Model) endStreamRun() {
-	if m.streamCancel != nil {
-		m.streamCancel()
-		m.streamCancel = nil
-	}
-	m.wtfStream = nil
-	m.streamStartPending = false
-	m.streamThrottlePending = false
-	m.toolCallNewTurnNeeded = false
+	m.aiJobs.end()
+	m.setStreamActivity("")
+	m.finishStreamProgress()
 }
 
 func (m Model) hasActiveStream() bool {
-	return m.streamCancel != nil || m.streamStartPending || m.wtfStream != nil
+	return m.aiJobs.running()
 }
 
 func (m Model) cancelActiveStream() (Model, tea.Cmd) {
 	if !m.hasActiveStream() {
 		return m, nil
 	}
-	if m.streamCancel != nil {
-		m.streamCancel()
-		m.streamCancel = nil
-	}
-	m.streamID++
-	m.wtfStream = nil
-	m.streamStartPending = false
-	m.streamThrottlePending = false
-	m.toolCallNewTurnNeeded = false
+	m.aiJobs.abandon()
+	m.setStreamActivity("")
+	m.finishStreamProgress()
 	if m.toolApproval != nil {
 		m.toolApproval.Hide()
 	}
@@ -386,27 +498,45 @@ func (m Model) cancelActiveStream() (Model, tea.Cmd) {
 
 func (m *Model) showStreamCanceledMessage() {
 	if m.sidebar == nil {
-		m.streamPlaceholderActive = false
+		m.aiJobs.placeholder = false
 		return
 	}
-	if m.streamPlaceholderActive {
+	if m.aiJobs.placeholder {
 		m.sidebar.SetLastMessageContent(streamCanceledMessage)
-		m.streamPlaceholderActive = false
+		m.aiJobs.placeholder = false
 	} else {
 		m.sidebar.StartAssistantMessageWithContent(streamCanceledMessage)
 	}
 	m.sidebar.SetStreaming(false)
-	m.sidebar.RefreshView()
+	m.refreshSidebar()
 }
 
-func (m *Model) buildExplainUserMessage(ctx *commands.Context) string {
+func (m *Model) buildExplainUserMessage(ctx *commands.Context, focus commands.Focus) string {
 	if ctx == nil {
 		return "[Asked to explain output from terminal. Last command: N/A]"
 	}
-	lineCount := 0
-	lines := ctx.GetLastNLines(ai.DefaultContextLines)
-	if len(lines) > 0 {
-		lineCount = len(lines)
+	n := ai.DefaultContextLines
+	if ctx.ContextLines > 0 {
+		n = ctx.ContextLines
+	} else if focus.ContextLines > 0 {
+		n = focus.ContextLines
+	}
+	out := focus.Output(ctx, n)
+	if out.Failed != nil {
+		return fmt.Sprintf("[Asked why `%s` failed with exit code %d, from %d lines of its output]",
+			strings.TrimSpace(out.Failed.Command), out.Failed.ExitCode, len(out.Lines))
+	}
+
+	ask := "explain"
+	if focus.SystemFacts {
+		ask = "find the root cause in"
+	}
+	what := fmt.Sprintf("last %d lines from terminal", len(out.Lines))
+	if out.Region == config.FocusRegionVisible {
+		what = fmt.Sprintf("the %d lines on screen", len(out.Lines))
+	}
+	if focus.SystemFacts {
+		what += ", with system facts"
 	}
 
 	command := "N/A"
@@ -417,7 +547,7 @@ func (m *Model) buildExplainUserMessage(ctx *commands.Context) string {
 		}
 	}
 
-	return fmt.Sprintf("[Asked to explain last %d lines from terminal. Last command: `%s`]", lineCount, command)
+	return fmt.Sprintf("[Asked to %s %s. Last command: `%s`]", ask, what, command)
 }
 
 func listenToWtfStream(streamID int, stream <-chan commands.WtfStreamEvent) tea.Cmd {
@@ -463,28 +593,28 @@ func startChatStreamCmd(streamID int, runCtx context.Context, ctx *commands.Cont
 	}
 }
 
+// startStreamPlaceholder shows the thinking placeholder for a request that
+// just started. The answer goes to the sidebar, so it is built if needed.
 func (m *Model) startStreamPlaceholder() {
-	if m.sidebar == nil {
+	if m.aiJobs.placeholder {
 		return
 	}
-	if m.streamPlaceholderActive {
-		return
-	}
-	m.sidebar.SetStreaming(true)
-	m.sidebar.StartAssistantMessageWithContent(streamThinkingPlaceholder)
-	m.streamPlaceholderActive = true
-	m.sidebar.RefreshView()
+	s := m.ensureSidebar()
+	s.SetStreaming(true)
+	s.StartAssistantMessageWithContent(streamThinkingPlaceholder)
+	m.aiJobs.placeholder = true
+	m.refreshSidebar()
 }
 
 func (m *Model) replaceStreamPlaceholder(delta string) bool {
 	if m.sidebar == nil {
 		return false
 	}
-	if !m.streamPlaceholderActive {
+	if !m.aiJobs.placeholder {
 		return false
 	}
 	m.sidebar.SetLastMessageContent(delta)
-	m.streamPlaceholderActive = false
+	m.aiJobs.placeholder = false
 	return true
 }
 
@@ -492,9 +622,14 @@ func (m *Model) clearStreamPlaceholder() {
 	if m.sidebar == nil {
 		return
 	}
-	if m.streamPlaceholderActive {
-		m.sidebar.RemoveLastMessage()
-		m.streamPlaceholderActive = false
+	if m.aiJobs.placeholder {
+		// Keep a message that only got reasoning, dropping the placeholder text.
+		if msgs := m.sidebar.GetMessages(); len(msgs) > 0 && msgs[len(msgs)-1].Reasoning != "" {
+			m.sidebar.SetLastMessageContent("")
+		} else {
+			m.sidebar.RemoveLastMessage()
+		}
+		m.aiJobs.placeholder = false
 	}
 }
 
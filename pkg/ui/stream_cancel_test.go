@@ -12,7 +12,7 @@ import (
 
 func TestModel_EscCancelsActiveStream(t *testing.T) {
 	m, canceled := modelWithCancelableStream()
-	m.sidebar.Show()
+	m.ensureSidebar().Show()
 	m.sidebar.SetStreaming(true)
 	m.sidebar.StartAssistantMessageWithContent("partial response")
 
@@ -28,11 +28,11 @@ func TestModel_EscCancelsActiveStream(t *testing.T) {
 	if m.hasActiveStream() {
 		t.Fatal("expected active stream state to be cleared")
 	}
-	if m.wtfStream != nil {
+	if m.aiJobs.stream != nil {
 		t.Fatal("expected stream channel to be cleared")
 	}
-	if m.streamStartPending {
-		t.Fatal("expected streamStartPending to be false")
+	if m.aiJobs.startPending {
+		t.Fatal("expected aiJobs.startPending to be false")
 	}
 	if m.sidebar.IsStreaming() {
 		t.Fatal("expected sidebar streaming state to be false")
@@ -55,6 +55,7 @@ func TestModel_EscCancelsActiveStream(t *testing.T) {
 
 func TestModel_EscCancelReplacesThinkingPlaceholder(t *testing.T) {
 	m, canceled := modelWithCancelableStream()
+	m.ensureSidebar().Show()
 	m.startStreamPlaceholder()
 
 	updated, _ := m.Update(testutils.TestKeyEsc)
@@ -63,7 +64,7 @@ func TestModel_EscCancelReplacesThinkingPlaceholder(t *testing.T) {
 	if !*canceled {
 		t.Fatal("expected stream cancel func to be called")
 	}
-	if m.streamPlaceholderActive {
+	if m.aiJobs.placeholder {
 		t.Fatal("expected placeholder state to be cleared")
 	}
 	if got := latestAssistantMessageContent(t, m); got != streamCanceledMessage {
@@ -162,7 +163,7 @@ func TestModel_EscLetsHistoryPickerHandleBeforeCancelingActiveStream(t *testing.
 
 func TestModel_EscLetsSettingsHandleBeforeCancelingActiveStream(t *testing.T) {
 	m, canceled := modelWithCancelableStream()
-	m.settingsPanel.Show(config.Default(), "/tmp/test_config.json")
+	m.ensureSettingsPanel().Show(config.Default(), "/tmp/test_config.json")
 
 	updated, cmd := m.Update(testutils.TestKeyEsc)
 	if cmd == nil {
@@ -183,7 +184,8 @@ func TestModel_EscLetsSettingsHandleBeforeCancelingActiveStream(t *testing.T) {
 
 func TestModel_EscCancelIgnoresStaleStreamEvents(t *testing.T) {
 	m, _ := modelWithCancelableStream()
-	oldStreamID := m.streamID
+	m.ensureSidebar().Show()
+	oldStreamID := m.aiJobs.id
 
 	updated, _ := m.Update(testutils.TestKeyEsc)
 	m = updated.(Model)
@@ -202,9 +204,9 @@ func TestModel_EscCancelIgnoresStaleStreamEvents(t *testing.T) {
 func modelWithCancelableStream() (Model, *bool) {
 	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
 	canceled := false
-	m.streamID = 1
-	m.wtfStream = make(chan commands.WtfStreamEvent)
-	m.streamCancel = func() {
+	m.aiJobs.id = 1
+	m.aiJobs.stream = make(chan commands.WtfStreamEvent)
+	m.aiJobs.cancel = func() {
 		canceled = true
 	}
 	return m, &canceled
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/commands"
+)
+
+func TestReasoningEventStaysOutOfAnswer(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.startStreamPlaceholder()
+
+	updated, cmd := m.Update(commands.WtfStreamEvent{Reasoning: "Look at the exit code."})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected reasoning to keep listening and start the spinner")
+	}
+	if got := m.sidebar.Activity(); got != "reasoning" {
+		t.Fatalf("activity = %q, want reasoning", got)
+	}
+
+	updated, _ = m.Update(commands.WtfStreamEvent{Delta: "Exit code 2 means"})
+	m = updated.(Model)
+
+	msgs := m.sidebar.GetMessages()
+	last := msgs[len(msgs)-1]
+	if last.Content != "Exit code 2 means" {
+		t.Errorf("content = %q, want the answer only", last.Content)
+	}
+	if last.Reasoning != "Look at the exit code." {
+		t.Errorf("reasoning = %q", last.Reasoning)
+	}
+	if got := m.sidebar.Activity(); got != "" {
+		t.Errorf("activity = %q, want cleared once the answer streams", got)
+	}
+}
+
+func TestReasoningAfterToolCallStartsNewMessage(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.startStreamPlaceholder()
+
+	for _, ev := range []commands.WtfStreamEvent{
+		{ToolCallStart: &commands.ToolCallInfo{Name: "read_file", ArgsJSON: `{}`}},
+		{ToolCallFinished: &commands.ToolCallInfo{Name: "read_file", Result: "ok"}},
+		{Reasoning: "The file is fine."},
+		{Delta: "All good."},
+	} {
+		updated, _ := m.Update(ev)
+		m = updated.(Model)
+	}
+
+	msgs := m.sidebar.GetMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("messages = %d, want 2", len(msgs))
+	}
+	if msgs[0].Reasoning != "" {
+		t.Errorf("tool message reasoning = %q, want empty", msgs[0].Reasoning)
+	}
+	if msgs[1].Reasoning != "The file is fine." || msgs[1].Content != "All good." {
+		t.Errorf("second message = %+v", msgs[1])
+	}
+}
+
+func TestReasoningOnlyAnswerIsKeptOnDone(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.startStreamPlaceholder()
+
+	updated, _ := m.Update(commands.WtfStreamEvent{Reasoning: "Nothing to say."})
+	m = updated.(Model)
+	updated, _ = m.Update(commands.WtfStreamEvent{Done: true})
+	m = updated.(Model)
+
+	msgs := m.sidebar.GetMessages()
+	if len(msgs) != 1 || msgs[0].Content != "" || msgs[0].Reasoning != "Nothing to say." {
+		t.Fatalf("messages = %+v, want reasoning kept without the placeholder", msgs)
+	}
+}
+
+func TestToolCallPendingShowsActivityUntilDone(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.startStreamPlaceholder()
+
+	updated, _ := m.Update(commands.WtfStreamEvent{ToolCallPending: &commands.ToolCallInfo{ID: "c1", Name: "read_file"}})
+	m = updated.(Model)
+	if got := m.sidebar.Activity(); got != "calling read_file" {
+		t.Fatalf("activity = %q", got)
+	}
+	if !m.streamActivityTicking {
+		t.Fatal("expected spinner tick to be scheduled")
+	}
+
+	updated, cmd := m.Update(streamActivityTickMsg{})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected spinner to keep ticking while active")
+	}
+
+	updated, _ = m.Update(commands.WtfStreamEvent{Done: true})
+	m = updated.(Model)
+	if got := m.sidebar.Activity(); got != "" {
+		t.Fatalf("activity = %q, want cleared on done", got)
+	}
+	updated, cmd = m.Update(streamActivityTickMsg{})
+	m = updated.(Model)
+	if cmd != nil || m.streamActivityTicking {
+		t.Fatal("expected spinner to stop once no activity is shown")
+	}
+}
+
+func TestUsageAndNoticeEvents(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.startStreamPlaceholder()
+
+	for _, ev := range []commands.WtfStreamEvent{
+		{Delta: "Partial answer"},
+		{Usage: &ai.Usage{InputTokens: 2048, OutputTokens: 300}},
+		{Notice: ai.TruncationNotice("length")},
+	} {
+		updated, _ := m.Update(ev)
+		m = updated.(Model)
+	}
+
+	content := latestAssistantMessageContent(t, m)
+	if !strings.Contains(content, "Partial answer\n\n**Note:** The answer was cut off") {
+		t.Errorf("content = %q, want notice after the answer", content)
+	}
+
+	m.ensureSidebar().Show()
+	m.sidebar.SetSize(70, 20)
+	if view := m.sidebar.View(); !strings.Contains(view, "2.0k in · 300 out") {
+		t.Errorf("expected token counts in sidebar, got:\n%s", view)
+	}
+
+	// A new request starts counting from zero.
+	m.beginStreamRun()
+	if view := m.sidebar.View(); strings.Contains(view, " in · ") {
+		t.Errorf("expected token counts reset for a new request, got:\n%s", view)
+	}
+}
@@ -24,7 +24,7 @@ func TestStreamThrottling(t *testing.T) {
 		t.Errorf("Expected content 'chunk1', got %q", got)
 	}
 
-	if !m.streamThrottlePending {
+	if !m.aiJobs.throttlePending {
 		t.Error("Expected throttle to be pending after first chunk")
 	}
 
@@ -40,7 +40,7 @@ func TestStreamThrottling(t *testing.T) {
 	}
 
 	// Timer should still be pending (only one timer set)
-	if !m.streamThrottlePending {
+	if !m.aiJobs.throttlePending {
 		t.Error("Expected throttle to remain pending")
 	}
 
@@ -49,7 +49,7 @@ func TestStreamThrottling(t *testing.T) {
 	m = updated.(Model)
 
 	// Timer should be reset
-	if m.streamThrottlePending {
+	if m.aiJobs.throttlePending {
 		t.Error("Expected throttle to be reset after flush")
 	}
 }
@@ -68,12 +68,12 @@ func TestStreamDoneUpdates(t *testing.T) {
 	m = updated.(Model)
 
 	// Stream should be cleared
-	if m.wtfStream != nil {
+	if m.aiJobs.stream != nil {
 		t.Error("Expected wtfStream to be nil after done")
 	}
 
 	// Throttle should be reset
-	if m.streamThrottlePending {
+	if m.aiJobs.throttlePending {
 		t.Error("Expected throttle to be reset after done")
 	}
 	if m.sidebar.IsStreaming() {
@@ -90,7 +90,7 @@ func TestStreamErrorResetsThrottle(t *testing.T) {
 	updated, _ := m.Update(commands.WtfStreamEvent{Delta: "start"})
 	m = updated.(Model)
 
-	if !m.streamThrottlePending {
+	if !m.aiJobs.throttlePending {
 		t.Error("Expected throttle pending")
 	}
 
@@ -99,12 +99,12 @@ func TestStreamErrorResetsThrottle(t *testing.T) {
 	m = updated.(Model)
 
 	// Throttle should be reset
-	if m.streamThrottlePending {
+	if m.aiJobs.throttlePending {
 		t.Error("Expected throttle to be reset on error")
 	}
 
 	// Stream should be cleared
-	if m.wtfStream != nil {
+	if m.aiJobs.stream != nil {
 		t.Error("Expected wtfStream to be nil after error")
 	}
 	if got := latestAssistantMessageContent(t, m); !strings.Contains(got, "test error") {
@@ -115,7 +115,7 @@ func TestStreamErrorResetsThrottle(t *testing.T) {
 // TestStreamStopAfterToolCallResetsTurnState reproduces the PR #78 review
 // finding: when the agent loop stops gracefully right after a tool call (e.g.
 // the user chose Stop at the continuation prompt), Done arrives with no delta
-// to clear toolCallNewTurnNeeded. If left set, the next stream's first delta is
+// to clear aiJobs.newTurnNeeded. If left set, the next stream's first delta is
 // treated as a post-tool continuation, leaving a stale "Thinking…" placeholder
 // and appending a new message instead of replacing it.
 func TestStreamStopAfterToolCallResetsTurnState(t *testing.T) {
@@ -128,13 +128,13 @@ func TestStreamStopAfterToolCallResetsTurnState(t *testing.T) {
 	m = updated.(Model)
 	updated, _ = m.Update(commands.WtfStreamEvent{ToolCallFinished: &commands.ToolCallInfo{Name: "read_file"}})
 	m = updated.(Model)
-	if !m.toolCallNewTurnNeeded {
-		t.Fatal("expected toolCallNewTurnNeeded set after ToolCallFinished")
+	if !m.aiJobs.newTurnNeeded {
+		t.Fatal("expected aiJobs.newTurnNeeded set after ToolCallFinished")
 	}
 	updated, _ = m.Update(commands.WtfStreamEvent{Done: true})
 	m = updated.(Model)
-	if m.toolCallNewTurnNeeded {
-		t.Fatal("Done after a tool call should clear toolCallNewTurnNeeded")
+	if m.aiJobs.newTurnNeeded {
+		t.Fatal("Done after a tool call should clear aiJobs.newTurnNeeded")
 	}
 
 	// Next stream: placeholder, then the first delta must replace it in place
@@ -144,7 +144,7 @@ func TestStreamStopAfterToolCallResetsTurnState(t *testing.T) {
 	updated, _ = m.Update(commands.WtfStreamEvent{Delta: "hello"})
 	m = updated.(Model)
 
-	if m.streamPlaceholderActive {
+	if m.aiJobs.placeholder {
 		t.Fatal("first delta should have replaced the placeholder")
 	}
 	if afterCount := len(m.sidebar.GetMessages()); afterCount != beforeCount {
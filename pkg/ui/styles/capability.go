@@ -0,0 +1,110 @@
+package styles
+
+import (
+	"io"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
+)
+
+// Color modes accepted by DetectProfile; they mirror config.ColorMode*.
+const (
+	ColorModeAuto      = "auto"
+	ColorModeTrueColor = "truecolor"
+	ColorMode256       = "256"
+	ColorMode16        = "16"
+	ColorModeNone      = "none"
+)
+
+// DetectProfile resolves the color profile to render with. An explicit mode
+// wins over detection; "auto" (or an empty/unknown mode) inspects out and env,
+// which honors NO_COLOR, CLICOLOR, CLICOLOR_FORCE, COLORTERM and TERM=dumb.
+func DetectProfile(mode string, out io.Writer, env []string) colorprofile.Profile {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case ColorModeTrueColor:
+		return colorprofile.TrueColor
+	case ColorMode256:
+		return colorprofile.ANSI256
+	case ColorMode16:
+		return colorprofile.ANSI
+	case ColorModeNone:
+		return colorprofile.ASCII
+	}
+	return colorprofile.Detect(out, env)
+}
+
+// colorful holds the themed styles replaced on monochrome terminals so
+// SetProfile can restore them when switching back to a color profile.
+var colorful = struct {
+	selected, commandActive, code, errorText             lipgloss.Style
+	statusBar, statusBarCyan, statusBarDark              lipgloss.Style
+	dialogButton, dialogActiveButton, dialogContentPanel lipgloss.Style
+	triggerHighlight, citedLine                          lipgloss.Style
+}{
+	selected:           SelectedStyle,
+	commandActive:      CommandActiveStyle,
+	code:               CodeStyle,
+	errorText:          ErrorStyle,
+	statusBar:          StatusBarStyle,
+	statusBarCyan:      StatusBarStyleCyan,
+	statusBarDark:      StatusBarStyleDark,
+	dialogButton:       DialogButtonStyle,
+	dialogActiveButton: DialogActiveButtonStyle,
+	dialogContentPanel: DialogContentPanelStyle,
+	triggerHighlight:   TriggerHighlightStyle,
+	citedLine:          CitedLineStyle,
+}
+
+var profile = colorprofile.TrueColor
+
+// Profile returns the color profile set by SetProfile (TrueColor by default).
+func Profile() colorprofile.Profile {
+	return profile
+}
+
+// Monochrome reports whether the active profile renders no colors at all.
+func Monochrome() bool {
+	return profile <= colorprofile.ASCII
+}
+
+// SetProfile records the terminal's color profile and adapts the theme to it.
+// 16- and 256-color terminals keep the palette and rely on the renderer to
+// downsample it; monochrome terminals swap styles whose meaning is carried by
+// a foreground/background pair (selection, status bar, buttons, code blocks)
+// for reverse video, bold and underline so they stay distinguishable.
+//
+// Styles are copied by some components when they are built, so call this
+// before creating the UI model.
+func SetProfile(p colorprofile.Profile) {
+	profile = p
+	if !Monochrome() {
+		SelectedStyle = colorful.selected
+		CommandActiveStyle = colorful.commandActive
+		CodeStyle = colorful.code
+		ErrorStyle = colorful.errorText
+		StatusBarStyle = colorful.statusBar
+		StatusBarStyleCyan = colorful.statusBarCyan
+		StatusBarStyleDark = colorful.statusBarDark
+		DialogButtonStyle = colorful.dialogButton
+		DialogActiveButtonStyle = colorful.dialogActiveButton
+		DialogContentPanelStyle = colorful.dialogContentPanel
+		TriggerHighlightStyle = colorful.triggerHighlight
+		CitedLineStyle = colorful.citedLine
+		return
+	}
+
+	SelectedStyle = lipgloss.NewStyle().Reverse(true).Bold(true)
+	CommandActiveStyle = lipgloss.NewStyle().Reverse(true).Underline(true)
+	// Code blocks lose their background, so set them apart with italics.
+	CodeStyle = lipgloss.NewStyle().Italic(true)
+	ErrorStyle = lipgloss.NewStyle().Bold(true)
+	StatusBarStyle = lipgloss.NewStyle().Reverse(true).Padding(0, 1).Bold(true)
+	StatusBarStyleCyan = StatusBarStyle
+	StatusBarStyleDark = lipgloss.NewStyle().Reverse(true).Padding(0, 1)
+	DialogButtonStyle = lipgloss.NewStyle().Padding(0, 2)
+	DialogActiveButtonStyle = lipgloss.NewStyle().Reverse(true).Padding(0, 2).Underline(true).Bold(true)
+	DialogContentPanelStyle = lipgloss.NewStyle().Padding(1, 2)
+	TriggerHighlightStyle = lipgloss.NewStyle().Reverse(true)
+	CitedLineStyle = lipgloss.NewStyle().Reverse(true).Underline(true)
+}
@@ -0,0 +1,65 @@
+package styles
+
+import (
+	"bytes"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
+)
+
+func TestDetectProfile_ExplicitModes(t *testing.T) {
+	env := []string{"TERM=xterm-256color", "COLORTERM=truecolor"}
+	tests := map[string]colorprofile.Profile{
+		"truecolor": colorprofile.TrueColor,
+		"256":       colorprofile.ANSI256,
+		"16":        colorprofile.ANSI,
+		" NONE ":    colorprofile.ASCII,
+	}
+	for mode, want := range tests {
+		if got := DetectProfile(mode, &bytes.Buffer{}, env); got != want {
+			t.Errorf("DetectProfile(%q) = %v, want %v", mode, got, want)
+		}
+	}
+}
+
+func TestDetectProfile_AutoHonorsNoColor(t *testing.T) {
+	// TTY_FORCE makes the buffer count as a terminal.
+	env := []string{"TERM=xterm-256color", "TTY_FORCE=1", "NO_COLOR=1"}
+	if got := DetectProfile("auto", &bytes.Buffer{}, env); got != colorprofile.ASCII {
+		t.Fatalf("expected NO_COLOR to disable colors, got %v", got)
+	}
+
+	dumb := []string{"TERM=dumb", "TTY_FORCE=1"}
+	if got := DetectProfile("", &bytes.Buffer{}, dumb); got > colorprofile.ASCII {
+		t.Fatalf("expected TERM=dumb to disable colors, got %v", got)
+	}
+
+	forced := []string{"TERM=xterm-256color", "CLICOLOR_FORCE=1"}
+	if got := DetectProfile("auto", &bytes.Buffer{}, forced); got != colorprofile.ANSI256 {
+		t.Fatalf("expected forced 256 colors, got %v", got)
+	}
+}
+
+func TestSetProfile_MonochromeSwapsColorPairs(t *testing.T) {
+	t.Cleanup(func() { SetProfile(colorprofile.TrueColor) })
+
+	SetProfile(colorprofile.ASCII)
+	if !Monochrome() {
+		t.Fatal("expected ASCII profile to be monochrome")
+	}
+	if !SelectedStyle.GetReverse() || !StatusBarStyle.GetReverse() || !DialogActiveButtonStyle.GetReverse() {
+		t.Fatal("expected selection, status bar and active button to use reverse video")
+	}
+	if _, ok := CodeStyle.GetBackground().(lipgloss.NoColor); !ok || !CodeStyle.GetItalic() {
+		t.Fatal("expected code blocks to drop their background for italics")
+	}
+
+	SetProfile(colorprofile.ANSI)
+	if Monochrome() {
+		t.Fatal("expected 16-color profile to keep colors")
+	}
+	if SelectedStyle.GetReverse() || CodeStyle.GetItalic() {
+		t.Fatal("expected colored styles to be restored")
+	}
+}
@@ -164,6 +164,13 @@ var (
 			Foreground(ColorWarning).
 			Bold(true)
 
+	// MatchStyle marks the part of a picker label that matches the search.
+	// Underlined so it also shows without colors.
+	MatchStyle = lipgloss.NewStyle().
+			Foreground(ColorWarning).
+			Bold(true).
+			Underline(true)
+
 	// PlaceholderStyle for placeholder text
 	PlaceholderStyle = lipgloss.NewStyle().
 				Foreground(ColorPlaceholder).
@@ -180,6 +187,16 @@ var (
 	FooterStyle = lipgloss.NewStyle().
 			Foreground(ColorTextMuted).
 			Italic(true)
+
+	// TriggerHighlightStyle for terminal lines matched by a highlight trigger
+	TriggerHighlightStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(ColorWarning)
+
+	// CitedLineStyle for the terminal line an answer cites
+	CitedLineStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(ColorAccent)
 )
 
 // Code styles
@@ -200,6 +217,11 @@ var (
 				Background(ColorBorderMuted).
 				Underline(true)
 
+	// Risk badges shown after suggested commands.
+	RiskSafeStyle        = lipgloss.NewStyle().Foreground(ColorSuccess)
+	RiskCautionStyle     = lipgloss.NewStyle().Foreground(ColorWarning)
+	RiskDestructiveStyle = lipgloss.NewStyle().Foreground(ColorError).Bold(true)
+
 	// Chat role-label styles distinguish speakers in the sidebar transcript.
 	ChatUserLabelStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("39")). // blue
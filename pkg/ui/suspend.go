@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"log/slog"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// canSuspend reports whether Ctrl+Z should suspend wtf_cli itself. That is
+// only the case at the shell prompt: while a job runs, Ctrl+Z is forwarded
+// so the shell can stop the job as usual.
+func (m Model) canSuspend() bool {
+	return m.shellAtPrompt()
+}
+
+// shellAtPrompt reports whether the shell, not a job it started, owns the
+// terminal.
+func (m Model) shellAtPrompt() bool {
+	if m.ptyFile == nil || m.shellInForeground == nil {
+		return false
+	}
+	return m.shellInForeground(m.ptyFile)
+}
+
+// suspend hands the terminal back to the parent shell. Bubble Tea restores
+// the original termios, leaves the alternate screen and stops the process;
+// the shell keeps running in its own session. A tea.ResumeMsg arrives after
+// SIGCONT.
+func (m Model) suspend() (Model, tea.Cmd) {
+	slog.Info("suspend")
+	if request := m.requestSuspend; request != nil {
+		return m, func() tea.Msg {
+			request()
+			return nil
+		}
+	}
+	return m, tea.Suspend
+}
+
+// SetSuspendRequest routes Ctrl+Z at the prompt through request instead of
+// tea.Suspend. A process catching SIGTSTP must reset the handler before
+// Bubble Tea re-raises the signal, or the signal is caught and the process
+// never stops; request is expected to do that and then send tea.SuspendMsg.
+// Must be called before the model is handed to tea.NewProgram.
+func (m *Model) SetSuspendRequest(request func()) {
+	m.requestSuspend = request
+}
+
+// handleResume runs after SIGCONT, once Bubble Tea has re-entered raw mode.
+// The terminal may have been resized or scribbled on while we were stopped,
+// so re-query its size (which also resizes the PTY) and repaint everything.
+func (m Model) handleResume() (Model, tea.Cmd) {
+	slog.Info("resume")
+	m.applyLayout()
+	return m, tea.Batch(tea.ClearScreen, tea.RequestWindowSize)
+}
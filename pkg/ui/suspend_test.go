@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"os"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/testutils"
+	"wtf_cli/pkg/ui/input"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newSuspendTestModel(t *testing.T, atPrompt bool) (Model, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+	m := NewModel(w, buffer.New(100), capture.NewSessionContext(), nil)
+	m.inputHandler = input.NewInputHandler(w)
+	m.shellInForeground = func(*os.File) bool { return atPrompt }
+	return m, r
+}
+
+func TestModel_CtrlZAtPromptSuspends(t *testing.T) {
+	m, _ := newSuspendTestModel(t, true)
+
+	_, cmd := m.handleKeyPress(testutils.NewCtrlKeyPressMsg('z'))
+	if cmd == nil {
+		t.Fatal("expected a suspend command")
+	}
+	if _, ok := cmd().(tea.SuspendMsg); !ok {
+		t.Fatal("expected tea.SuspendMsg")
+	}
+}
+
+func TestModel_CtrlZAtPromptUsesSuspendRequest(t *testing.T) {
+	m, _ := newSuspendTestModel(t, true)
+	requested := 0
+	m.SetSuspendRequest(func() { requested++ })
+
+	_, cmd := m.handleKeyPress(testutils.NewCtrlKeyPressMsg('z'))
+	if cmd == nil {
+		t.Fatal("expected a suspend command")
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("expected the request to suspend, not %T", msg)
+	}
+	if requested != 1 {
+		t.Fatalf("suspend requested %d times, want 1", requested)
+	}
+}
+
+func TestModel_CtrlZWithRunningJobIsForwarded(t *testing.T) {
+	m, r := newSuspendTestModel(t, false)
+
+	_, cmd := m.handleKeyPress(testutils.NewCtrlKeyPressMsg('z'))
+	if cmd != nil {
+		if _, ok := cmd().(tea.SuspendMsg); ok {
+			t.Fatal("expected Ctrl+Z to reach the job, not suspend wtf_cli")
+		}
+	}
+	got := make([]byte, 1)
+	if _, err := r.Read(got); err != nil || got[0] != 26 {
+		t.Fatalf("expected SUB to be written to the PTY, got %v (err %v)", got, err)
+	}
+}
+
+func TestModel_ResumeRepaints(t *testing.T) {
+	m, _ := newSuspendTestModel(t, true)
+
+	_, cmd := m.Update(tea.ResumeMsg{})
+	if cmd == nil {
+		t.Fatal("expected repaint commands on resume")
+	}
+}
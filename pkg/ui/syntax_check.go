@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/shellsyntax"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	// syntaxCheckDelay is how long typing must pause before shellcheck
+	// runs; the built-in checks run on every key.
+	syntaxCheckDelay  = 300 * time.Millisecond
+	shellcheckTimeout = 2 * time.Second
+)
+
+// syntaxChecker warns in the status bar about a syntax error in the command
+// being typed, before Enter sends it to the shell.
+type syntaxChecker struct {
+	enabled    bool
+	shellcheck string // Path of the shellcheck binary, "" when not used
+	dialect    string // Shellcheck's name for the shell
+	// seq counts checks, so a shellcheck run for an older line is dropped.
+	seq int
+	// unsure is set once a key changed the line in a way the line buffer
+	// does not follow (Tab completion, history recall), until the next
+	// prompt.
+	unsure bool
+}
+
+func newSyntaxChecker(cfg config.SyntaxCheckConfig, shell string, lookPath func(string) (string, error)) syntaxChecker {
+	mode := strings.TrimSpace(cfg.Mode)
+	c := syntaxChecker{enabled: mode != config.SyntaxCheckOff}
+	if !c.enabled || mode == config.SyntaxCheckBuiltin {
+		return c
+	}
+	if c.dialect = shellsyntax.Dialect(shell); c.dialect != "" {
+		if path, err := lookPath("shellcheck"); err == nil {
+			c.shellcheck = path
+		}
+	}
+	return c
+}
+
+type syntaxCheckDueMsg struct {
+	seq  int
+	line string
+}
+
+type syntaxCheckResultMsg struct {
+	seq     int
+	problem shellsyntax.Problem
+	found   bool
+}
+
+// checkPromptSyntax checks the line typed at the prompt after msg reached
+// the shell. Built-in checks answer at once; shellcheck runs once typing
+// pauses.
+func (m *Model) checkPromptSyntax(msg tea.KeyPressMsg) tea.Cmd {
+	if !m.syntax.enabled || m.inputHandler == nil {
+		return nil
+	}
+	m.syntax.seq++
+	line := m.inputHandler.LineBuffer()
+	switch {
+	case line == "":
+		m.syntax.unsure = false
+	case msg.Text == "" && msg.String() != "backspace":
+		m.syntax.unsure = true
+	}
+	m.clearStatus(statusSourceSyntax)
+	if strings.TrimSpace(line) == "" || m.syntax.unsure {
+		return nil
+	}
+	if problem, found := shellsyntax.Check(line); found {
+		m.showSyntaxProblem(problem)
+		return nil
+	}
+	if m.syntax.shellcheck == "" {
+		return nil
+	}
+	due := syntaxCheckDueMsg{seq: m.syntax.seq, line: line}
+	return tea.Tick(syntaxCheckDelay, func(time.Time) tea.Msg { return due })
+}
+
+// runShellcheck runs shellcheck over the line of msg unless the line has
+// changed since.
+func (m Model) runShellcheck(msg syntaxCheckDueMsg) tea.Cmd {
+	if msg.seq != m.syntax.seq {
+		return nil
+	}
+	path, dialect := m.syntax.shellcheck, m.syntax.dialect
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), shellcheckTimeout)
+		defer cancel()
+		problem, found, err := shellsyntax.Shellcheck(ctx, path, dialect, msg.line)
+		if err != nil {
+			slog.Debug("shellcheck_error", "error", err)
+			return nil
+		}
+		return syntaxCheckResultMsg{seq: msg.seq, problem: problem, found: found}
+	}
+}
+
+// applyShellcheck shows what shellcheck found, if the line is still the
+// one it checked.
+func (m *Model) applyShellcheck(msg syntaxCheckResultMsg) {
+	if msg.seq != m.syntax.seq || !msg.found {
+		return
+	}
+	m.showSyntaxProblem(msg.problem)
+}
+
+// showSyntaxProblem posts problem at low priority, so any other message
+// takes its place.
+func (m *Model) showSyntaxProblem(problem shellsyntax.Problem) {
+	slog.Debug("prompt_syntax_problem", "message", problem.Message, "offset", problem.Offset)
+	m.postStatus(statusbar.Message{Source: statusSourceSyntax, Text: "⚠ " + problem.String(), Priority: statusbar.PriorityLow}, 0)
+}
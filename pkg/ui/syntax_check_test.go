@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/shellsyntax"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSyntaxCheck_WarnsWhileTyping(t *testing.T) {
+	m, _ := newConfirmTestModel(t, config.ConfirmCommandsConfig{})
+	m.syntax = newSyntaxChecker(config.SyntaxCheckConfig{Mode: config.SyntaxCheckBuiltin}, "/bin/bash", nil)
+
+	m = typeLine(t, m, `echo "hello`)
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "unclosed double quote (col 6)") {
+		t.Fatalf("expected the unclosed quote flagged, got %q", msg)
+	}
+	m = typeLine(t, m, `"`)
+	if msg := m.statusBar.GetMessage(); msg != "" {
+		t.Fatalf("expected the warning gone once the quote is closed, got %q", msg)
+	}
+
+	m = typeLine(t, m, " |")
+	m, _ = m.handleKeyPress(tea.KeyPressMsg{Code: tea.KeyTab})
+	m = typeLine(t, m, "|")
+	if msg := m.statusBar.GetMessage(); msg != "" {
+		t.Fatalf("expected no check after Tab changed the line, got %q", msg)
+	}
+	m, _ = m.handleKeyPress(enterKey)
+	m = typeLine(t, m, "ls )")
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "unexpected `)`") {
+		t.Fatalf("expected checks back at the next prompt, got %q", msg)
+	}
+}
+
+func TestSyntaxCheck_ShellcheckResultForCurrentLineOnly(t *testing.T) {
+	lookPath := func(string) (string, error) { return "/usr/bin/shellcheck", nil }
+	m, _ := newConfirmTestModel(t, config.ConfirmCommandsConfig{})
+	m.syntax = newSyntaxChecker(config.SyntaxCheckConfig{}, "/bin/bash", lookPath)
+	if m.syntax.shellcheck == "" || m.syntax.dialect != "bash" {
+		t.Fatalf("expected shellcheck for bash, got %+v", m.syntax)
+	}
+	if zsh := newSyntaxChecker(config.SyntaxCheckConfig{}, "/bin/zsh", lookPath); zsh.shellcheck != "" {
+		t.Fatal("expected no shellcheck for zsh")
+	}
+	if none := newSyntaxChecker(config.SyntaxCheckConfig{}, "/bin/bash", func(string) (string, error) { return "", errors.New("not found") }); none.shellcheck != "" || !none.enabled {
+		t.Fatal("expected built-in checks only without shellcheck")
+	}
+
+	m = typeLine(t, m, "if true")
+	stale := m.syntax.seq
+	m = typeLine(t, m, ";")
+	problem := shellsyntax.Problem{Message: "Couldn't parse this if expression (SC1073)", Offset: 0}
+	m.applyShellcheck(syntaxCheckResultMsg{seq: stale, problem: problem, found: true})
+	if msg := m.statusBar.GetMessage(); msg != "" {
+		t.Fatalf("expected a result for an older line dropped, got %q", msg)
+	}
+	m.applyShellcheck(syntaxCheckResultMsg{seq: m.syntax.seq, problem: problem, found: true})
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "SC1073") {
+		t.Fatalf("expected the shellcheck error shown, got %q", msg)
+	}
+	if cmd := m.runShellcheck(syntaxCheckDueMsg{seq: stale, line: "if true"}); cmd != nil {
+		t.Fatal("expected no shellcheck run for an older line")
+	}
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/ui/components/utils"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// tasksFieldKey routes option picker selections made from /tasks.
+const tasksFieldKey = "tasks_command"
+
+// taskDescriptionWidth caps the description shown next to each task.
+const taskDescriptionWidth = 40
+
+// openTasksPicker lists the build, test and run commands of the project the
+// shell is in. Picking one puts it at the prompt.
+func (m Model) openTasksPicker() (Model, tea.Cmd) {
+	project := ai.DetectProject(m.currentDir)
+	if len(project.Tasks) == 0 {
+		return m, m.flashStatus("No project found here (looked for package.json, go.mod, Cargo.toml, Makefile, compose files)")
+	}
+
+	m.taskChoices = make(map[string]string, len(project.Tasks))
+	options := make([]string, 0, len(project.Tasks))
+	for _, t := range project.Tasks {
+		label := taskOptionLabel(t)
+		if _, dup := m.taskChoices[label]; dup {
+			continue
+		}
+		m.taskChoices[label] = t.Command
+		options = append(options, label)
+	}
+
+	slog.Info("tasks_picker_open", "root", project.Root, "types", strings.Join(project.Types, ","), "tasks", len(options))
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Tasks ("+strings.Join(project.Types, ", ")+")", tasksFieldKey, options, "")
+	return m, nil
+}
+
+// taskOptionLabel renders a task as "make test · Run the unit tests".
+func taskOptionLabel(t ai.ProjectTask) string {
+	description := strings.Join(strings.Fields(t.Description), " ")
+	if description == "" {
+		return t.Command
+	}
+	return t.Command + " · " + utils.TruncateToWidth(description, taskDescriptionWidth)
+}
+
+// runTask puts the picked task at the shell prompt for the user to run.
+func (m Model) runTask(label string) (Model, tea.Cmd) {
+	command, ok := m.taskChoices[label]
+	if !ok {
+		return m, nil
+	}
+	slog.Info("tasks_picker_select", "command", command)
+	m.replacePromptCommand(command)
+	return m, nil
+}
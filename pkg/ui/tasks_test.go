@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+)
+
+func TestModel_TasksPickerListsProjectCommands(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Makefile"), []byte("test: ## Run the tests\n\tgo test ./...\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = dir
+
+	m, _ = m.openTasksPicker()
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("expected the tasks picker to open")
+	}
+	if got := m.taskChoices["make test · Run the tests"]; got != "make test" {
+		t.Fatalf("expected make test to be offered, got choices %v", m.taskChoices)
+	}
+
+	m.optionPicker.Hide()
+	m.currentDir = t.TempDir()
+	m, _ = m.openTasksPicker()
+	if m.optionPicker.IsVisible() {
+		t.Fatal("expected no picker outside a project")
+	}
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, "No project found") {
+		t.Fatalf("expected a status message, got %q", got)
+	}
+}
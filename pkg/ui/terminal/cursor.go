@@ -186,9 +186,13 @@ func renderCursorInLine(line string, col int, cursorChar string) string {
 		}
 
 		width := runewidth.RuneWidth(r)
-		if width < 1 {
-			width = 1
+		if width < 1 && visible > 0 {
+			// A combining mark takes no column of its own.
+			b.WriteRune(r)
+			i += size
+			continue
 		}
+		width = max(width, 1)
 		if !applied && col >= visible && col < visible+width {
 			b.WriteString(inverseOn)
 			b.WriteRune(r)
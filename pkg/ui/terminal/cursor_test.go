@@ -355,3 +355,14 @@ func TestCursorTracker_UpdateFromOutput_AbsolutePositionEdgeCases(t *testing.T)
 		t.Errorf("Expected position (9,19), got (%d,%d)", row, col)
 	}
 }
+
+func TestCursorTracker_RenderCursorOverlay_CombiningMarkTakesNoColumn(t *testing.T) {
+	ct := NewCursorTracker()
+	ct.SetPosition(0, 1)
+
+	result := ct.RenderCursorOverlay("e\u0301x", "█")
+
+	if result != "e\u0301\x1b[7mx\x1b[27m" {
+		t.Errorf("Expected the cursor on x, got %q", result)
+	}
+}
@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"slices"
 	"strings"
 	"unicode/utf8"
 
@@ -123,17 +124,68 @@ func (l *lineBuffer) padToCol(col int) {
 	}
 }
 
+// setCellAt writes a cell at col, replacing the cells it covers. A wide
+// cell that is only partly overwritten is blanked, as a terminal does, so
+// the cells after it keep their columns.
 func (l *lineBuffer) setCellAt(col int, text string, width int, style *cellStyle) {
 	if width < 1 {
 		width = 1
 	}
 	l.padToCol(col)
-	idx, hasVisible := l.indexForCol(col)
-	if hasVisible && idx < len(l.cells) && l.cells[idx].width > 0 {
-		l.cells[idx] = lineCell{text: text, width: width, style: style}
+	cell := lineCell{text: text, width: width, style: style}
+	if l.visibleLen() == col {
+		l.cells = append(l.cells, cell)
 		return
 	}
-	l.insertCell(idx, lineCell{text: text, width: width, style: style})
+	l.splitWideAt(col)
+	l.splitWideAt(col + width)
+	idx, _ := l.indexForCol(col)
+	end := idx
+	for covered := 0; end < len(l.cells) && covered < width; end++ {
+		covered += l.cells[end].width
+	}
+	l.cells = slices.Replace(l.cells, idx, end, cell)
+}
+
+// splitWideAt replaces a wide cell that col falls inside of with spaces.
+func (l *lineBuffer) splitWideAt(col int) {
+	visible := 0
+	for i, c := range l.cells {
+		if visible >= col {
+			return
+		}
+		if visible+c.width > col {
+			spaces := make([]lineCell, c.width)
+			for j := range spaces {
+				spaces[j] = lineCell{text: " ", width: 1}
+			}
+			l.cells = slices.Replace(l.cells, i, i+1, spaces...)
+			return
+		}
+		visible += c.width
+	}
+}
+
+// appendToCellBefore adds a zero-width rune, such as a combining mark, to
+// the cell that ends at col. It reports false when there is none.
+func (l *lineBuffer) appendToCellBefore(col int, text string) bool {
+	visible := 0
+	for i, c := range l.cells {
+		visible += c.width
+		if visible == col {
+			l.cells[i].text += text
+			return true
+		}
+		if visible > col {
+			return false
+		}
+	}
+	return false
+}
+
+// rows returns how many rows the line takes when wrapped at width.
+func (l *lineBuffer) rows(width int) int {
+	return max((l.visibleLen()+width-1)/width, 1)
 }
 
 func (l *lineBuffer) insertSpacesAtCol(col int, count int) int {
@@ -151,16 +203,6 @@ func (l *lineBuffer) insertSpacesAtCol(col int, count int) int {
 	return idx
 }
 
-func (l *lineBuffer) deleteCellsAtIndex(idx int, count int) {
-	if count < 1 || idx < 0 || idx >= len(l.cells) {
-		return
-	}
-	if idx+count > len(l.cells) {
-		count = len(l.cells) - idx
-	}
-	l.cells = append(l.cells[:idx], l.cells[idx+count:]...)
-}
-
 func (l *lineBuffer) deleteCellsAtCol(col int, count int) {
 	if count < 1 || len(l.cells) == 0 {
 		return
@@ -239,6 +281,7 @@ type LineRenderer struct {
 	inCSI      bool
 	inOSC      bool
 	oscEsc     bool
+	osc        oscCollector
 	csiParam   int
 	csiHas     bool
 	csiSep     bool
@@ -251,6 +294,18 @@ type LineRenderer struct {
 	savedCol   int
 	savedPen   *cellStyle
 	savedValid bool
+
+	// promptRows are the rows where OSC 133 prompt-start marks were seen,
+	// ascending.
+	promptRows []int
+
+	// width is the terminal's column count; 0 disables wrapping. Lines
+	// longer than width are kept whole, as one content line, and col counts
+	// cells from its start, so col/width is the wrapped row the cursor is on.
+	width int
+	// wrapPending is set after a character fills the last column: the
+	// cursor stays on that row until the next character is printed.
+	wrapPending bool
 }
 
 // NewLineRenderer creates a new line renderer.
@@ -263,6 +318,7 @@ func (r *LineRenderer) Reset() {
 	r.lines = nil
 	r.row = 0
 	r.col = 0
+	r.wrapPending = false
 	r.insertMode = false
 	r.inEscape = false
 	r.inCSI = false
@@ -278,11 +334,107 @@ func (r *LineRenderer) Reset() {
 	r.savedCol = 0
 	r.savedPen = nil
 	r.savedValid = false
+	r.promptRows = nil
+}
+
+// SetWidth sets the terminal's column count, at which the shell's lines
+// wrap. 0 disables wrapping.
+func (r *LineRenderer) SetWidth(width int) {
+	r.width = max(width, 0)
+	r.wrapPending = false
 }
 
-// CursorPosition returns the current cursor row/col (0-indexed).
+// CursorPosition returns the current cursor row/col (0-indexed). col counts
+// cells from the start of the content line, wrapped rows included.
 func (r *LineRenderer) CursorPosition() (row, col int) {
-	return r.row, r.col
+	return r.row, r.cursorCol()
+}
+
+// cursorCol returns the cell the cursor is on. After a character filled the
+// last column, that is still the last column.
+func (r *LineRenderer) cursorCol() int {
+	if r.wrapPending {
+		return r.col - 1
+	}
+	return r.col
+}
+
+// wrapPos returns where the wrapped row the cursor is on starts, and the
+// cursor's column on that row. Without a width the line is one row.
+func (r *LineRenderer) wrapPos() (start, x int) {
+	col := r.cursorCol()
+	if r.width <= 0 {
+		return 0, col
+	}
+	start = col - col%r.width
+	return start, col - start
+}
+
+// moveToColumn moves the cursor to column x of its wrapped row.
+func (r *LineRenderer) moveToColumn(x int) {
+	start, _ := r.wrapPos()
+	x = max(x, 0)
+	if r.width > 0 {
+		x = min(x, r.width-1)
+	}
+	r.col = start + x
+	r.wrapPending = false
+}
+
+// moveRows moves the cursor n wrapped rows down, or up when n is negative,
+// keeping its column. Moving down past the last line adds lines; moving up
+// stops at the first row.
+func (r *LineRenderer) moveRows(n int) {
+	start, x := r.wrapPos()
+	r.wrapPending = false
+	if r.width <= 0 {
+		r.row = max(r.row+n, 0)
+		r.ensureLine(r.row)
+		r.col = x
+		return
+	}
+	r.ensureLine(r.row)
+	sub := start / r.width
+	for ; n < 0; n++ {
+		if sub > 0 {
+			sub--
+			continue
+		}
+		if r.row == 0 {
+			break
+		}
+		r.row--
+		sub = r.lines[r.row].rows(r.width) - 1
+	}
+	for ; n > 0; n-- {
+		if sub+1 < r.lines[r.row].rows(r.width) {
+			sub++
+			continue
+		}
+		r.row++
+		r.ensureLine(r.row)
+		sub = 0
+	}
+	r.col = sub*r.width + x
+}
+
+// eraseToRowEnd clears the cursor's wrapped row from col on. On the last
+// row of the line the line is cut there; otherwise the cells are blanked so
+// the rows below keep their place.
+func (r *LineRenderer) eraseToRowEnd(col int) {
+	line := &r.lines[r.row]
+	if r.width <= 0 {
+		line.truncateFromCol(col)
+		return
+	}
+	end := col - col%r.width + r.width
+	if line.visibleLen() <= end {
+		line.truncateFromCol(col)
+		return
+	}
+	for c := col; c < end; c++ {
+		line.setCellAt(c, " ", 1, nil)
+	}
 }
 
 func (r *LineRenderer) ensureLine(row int) {
@@ -291,26 +443,29 @@ func (r *LineRenderer) ensureLine(row int) {
 	}
 }
 
+// moveCursorLeft moves the cursor n columns left, stopping at the start of
+// its wrapped row as a terminal does.
 func (r *LineRenderer) moveCursorLeft(n int) {
 	if n < 1 {
 		n = 1
 	}
-	r.col -= n
-	if r.col < 0 {
-		r.col = 0
-	}
+	_, x := r.wrapPos()
+	r.moveToColumn(x - n)
 }
 
+// moveCursorRight moves the cursor n columns right, stopping at the end of
+// its wrapped row.
 func (r *LineRenderer) moveCursorRight(n int) {
 	if n < 1 {
 		n = 1
 	}
-	r.col += n
+	_, x := r.wrapPos()
+	r.moveToColumn(x + n)
 }
 
 func (r *LineRenderer) saveCursor() {
 	r.savedRow = r.row
-	r.savedCol = r.col
+	r.savedCol = r.cursorCol()
 	r.savedPen = r.pen
 	r.savedValid = true
 }
@@ -321,6 +476,7 @@ func (r *LineRenderer) restoreCursor() {
 	}
 	r.row = r.savedRow
 	r.col = r.savedCol
+	r.wrapPending = false
 	r.pen = r.savedPen
 	r.ensureLine(r.row)
 }
@@ -375,14 +531,14 @@ func (r *LineRenderer) Append(data []byte) {
 		if r.inOSC {
 			if r.oscEsc {
 				if b == '\\' {
-					r.inOSC = false
+					r.endOSC()
 				}
 				r.oscEsc = false
 				i++
 				continue
 			}
 			if b == 0x07 {
-				r.inOSC = false
+				r.endOSC()
 				i++
 				continue
 			}
@@ -391,6 +547,7 @@ func (r *LineRenderer) Append(data []byte) {
 				i++
 				continue
 			}
+			r.osc.add(b)
 			i++
 			continue
 		}
@@ -407,6 +564,7 @@ func (r *LineRenderer) Append(data []byte) {
 			case ']':
 				r.inEscape = false
 				r.inOSC = true
+				r.osc.reset()
 			case '7':
 				r.saveCursor()
 				r.inEscape = false
@@ -452,12 +610,12 @@ func (r *LineRenderer) Append(data []byte) {
 
 		switch b {
 		case '\r':
-			r.col = 0
+			r.moveToColumn(0)
 			i++
 		case '\n':
-			r.row++
-			r.col = 0
-			r.ensureLine(r.row)
+			// Shells move down through their own wrapped lines with newlines.
+			r.moveRows(1)
+			r.moveToColumn(0)
 			i++
 		case '\t':
 			r.ensureLine(r.row)
@@ -472,38 +630,29 @@ func (r *LineRenderer) Append(data []byte) {
 			i++
 		case 0x01: // Ctrl+A (home)
 			r.col = 0
+			r.wrapPending = false
 			i++
 		case 0x05: // Ctrl+E (end)
 			r.ensureLine(r.row)
 			r.col = r.lines[r.row].visibleLen()
+			r.wrapPending = false
 			i++
 		default:
 			if b >= 0x20 {
 				r.ensureLine(r.row)
 				rn, size := utf8.DecodeRune(data[i:])
 				if rn == utf8.RuneError && size == 1 {
-					if r.insertMode {
-						r.lines[r.row].insertSpacesAtCol(r.col, 1)
-					}
-					r.lines[r.row].setCellAt(r.col, string(rune(b)), 1, r.pen)
-					r.col++
+					r.putCell(string(rune(b)), 1)
 					i++
 					continue
 				}
 				width := runewidth.RuneWidth(rn)
-				if width < 1 {
-					width = 1
-				}
-				if r.insertMode {
-					insertIdx := r.lines[r.row].insertSpacesAtCol(r.col, width)
-					r.lines[r.row].setCellAt(r.col, string(rn), width, r.pen)
-					if width > 1 {
-						r.lines[r.row].deleteCellsAtIndex(insertIdx+1, width-1)
-					}
-				} else {
-					r.lines[r.row].setCellAt(r.col, string(rn), width, r.pen)
+				if width < 1 && r.lines[r.row].appendToCellBefore(r.col, string(rn)) {
+					// A combining mark joins the character before it.
+					i += size
+					continue
 				}
-				r.col += width
+				r.putCell(string(rn), max(width, 1))
 				i += size
 			} else {
 				i++
@@ -512,6 +661,51 @@ func (r *LineRenderer) Append(data []byte) {
 	}
 }
 
+// putCell prints text, width cells wide, at the cursor and advances it. A
+// wide character that does not fit on the row wraps to the next one, as in
+// a terminal, leaving the last column blank.
+func (r *LineRenderer) putCell(text string, width int) {
+	line := &r.lines[r.row]
+	if r.width > 0 && width > 1 {
+		if x := r.col % r.width; x+width > r.width {
+			r.col += r.width - x
+		}
+	}
+	if r.insertMode {
+		line.insertSpacesAtCol(r.col, width)
+	}
+	line.setCellAt(r.col, text, width, r.pen)
+	r.col += width
+	r.wrapPending = r.width > 0 && r.col%r.width == 0
+}
+
+// endOSC finishes an OSC sequence, remembering the row of a prompt-start
+// mark.
+func (r *LineRenderer) endOSC() {
+	r.inOSC = false
+	mark, ok := r.osc.promptMark()
+	if !ok || mark.Kind != MarkPromptStart {
+		return
+	}
+	if n := len(r.promptRows); n > 0 && r.promptRows[n-1] >= r.row {
+		// The prompt was redrawn, e.g. after Ctrl+L or a resize.
+		r.dropPromptRowsAfter(r.row - 1)
+	}
+	r.promptRows = append(r.promptRows, r.row)
+}
+
+func (r *LineRenderer) dropPromptRowsAfter(row int) {
+	for len(r.promptRows) > 0 && r.promptRows[len(r.promptRows)-1] > row {
+		r.promptRows = r.promptRows[:len(r.promptRows)-1]
+	}
+}
+
+// PromptRows returns the rows where shell prompts start, as marked with
+// OSC 133, in ascending order.
+func (r *LineRenderer) PromptRows() []int {
+	return append([]int(nil), r.promptRows...)
+}
+
 // applySGR updates the current pen (rendition) from CSI "m" parameters. An
 // empty/nil params slice means a bare "CSI m", equivalent to SGR 0 (reset).
 func (r *LineRenderer) applySGR(params []int) {
@@ -633,17 +827,13 @@ func (r *LineRenderer) handleCSI(final byte) {
 		if len(r.csiParams) > 0 && r.csiParams[0] > 0 {
 			n = r.csiParams[0]
 		}
-		r.row -= n
-		if r.row < 0 {
-			r.row = 0
-		}
+		r.moveRows(-n)
 	case 'B':
 		n := 1
 		if len(r.csiParams) > 0 && r.csiParams[0] > 0 {
 			n = r.csiParams[0]
 		}
-		r.row += n
-		r.ensureLine(r.row)
+		r.moveRows(n)
 	case 'D':
 		n := 1
 		if len(r.csiParams) > 0 && r.csiParams[0] > 0 {
@@ -661,13 +851,11 @@ func (r *LineRenderer) handleCSI(final byte) {
 		if len(r.csiParams) > 0 && r.csiParams[0] > 0 {
 			col = r.csiParams[0]
 		}
-		r.col = col - 1
-		if r.col < 0 {
-			r.col = 0
-		}
+		r.moveToColumn(col - 1)
 	case 'F':
 		r.ensureLine(r.row)
 		r.col = r.lines[r.row].visibleLen()
+		r.wrapPending = false
 	case 'J':
 		param := 0
 		if len(r.csiParams) > 0 {
@@ -676,15 +864,18 @@ func (r *LineRenderer) handleCSI(final byte) {
 		switch param {
 		case 0: // Erase from cursor to end of display
 			r.ensureLine(r.row)
-			r.lines[r.row].truncateFromCol(r.col)
+			r.lines[r.row].truncateFromCol(r.cursorCol())
 			if r.row+1 < len(r.lines) {
 				r.lines = r.lines[:r.row+1]
 			}
+			r.dropPromptRowsAfter(r.row)
 		case 2: // Erase entire display
 			r.lines = r.lines[:0]
 			r.row = 0
 			r.col = 0
+			r.wrapPending = false
 			r.ensureLine(0)
+			r.promptRows = nil
 		}
 	case 'K':
 		r.ensureLine(r.row)
@@ -694,9 +885,13 @@ func (r *LineRenderer) handleCSI(final byte) {
 		}
 		switch param {
 		case 0: // Clear from cursor to end of line
-			r.lines[r.row].truncateFromCol(r.col)
+			r.eraseToRowEnd(r.cursorCol())
 		case 2: // Clear entire line
-			r.lines[r.row] = lineBuffer{}
+			if start, _ := r.wrapPos(); r.width > 0 && r.lines[r.row].rows(r.width) > 1 {
+				r.eraseToRowEnd(start)
+			} else {
+				r.lines[r.row] = lineBuffer{}
+			}
 		}
 	case 'P':
 		n := 1
@@ -704,14 +899,14 @@ func (r *LineRenderer) handleCSI(final byte) {
 			n = r.csiParams[0]
 		}
 		r.ensureLine(r.row)
-		r.lines[r.row].deleteCellsAtCol(r.col, n)
+		r.lines[r.row].deleteCellsAtCol(r.cursorCol(), n)
 	case '@':
 		n := 1
 		if len(r.csiParams) > 0 && r.csiParams[0] > 0 {
 			n = r.csiParams[0]
 		}
 		r.ensureLine(r.row)
-		r.lines[r.row].insertSpacesAtCol(r.col, n)
+		r.lines[r.row].insertSpacesAtCol(r.cursorCol(), n)
 	case 'X':
 		n := 1
 		if len(r.csiParams) > 0 && r.csiParams[0] > 0 {
@@ -719,7 +914,7 @@ func (r *LineRenderer) handleCSI(final byte) {
 		}
 		r.ensureLine(r.row)
 		for i := 0; i < n; i++ {
-			r.lines[r.row].setCellAt(r.col+i, " ", 1, nil)
+			r.lines[r.row].setCellAt(r.cursorCol()+i, " ", 1, nil)
 		}
 	case 'h':
 		if hasCSIParam(r.csiParams, 4) {
@@ -730,6 +925,7 @@ func (r *LineRenderer) handleCSI(final byte) {
 			r.insertMode = false
 		}
 	case 'H', 'f':
+		r.wrapPending = false
 		if len(r.csiParams) == 0 {
 			r.row = 0
 			r.col = 0
@@ -122,9 +122,10 @@ func TestLineRenderer_WideCharacterOverwrite(t *testing.T) {
 	r.Append([]byte("abc世\x1b[2DX"))
 
 	content := r.Content()
-	// "abc世" (col 7: a=1,b=1,c=1,世=2), back 2 (col 5, middle of 世), then 'X' at col 5
-	if content != "abcX" {
-		t.Fatalf("expected %q, got %q", "abcX", content)
+	// "abc世" ends at col 5; back 2 is col 3, the start of 世. X covers
+	// only its first half, so the second half is blanked and stays a column.
+	if content != "abcX " {
+		t.Fatalf("expected %q, got %q", "abcX ", content)
 	}
 }
 
@@ -1134,3 +1135,145 @@ func BenchmarkLineRenderer_StyledScrollback(b *testing.B) {
 		_ = r.Content()
 	}
 }
+
+// Wrapped lines — the shell wraps at the terminal width
+
+func TestLineRenderer_Wrap_CRReturnsToStartOfWrappedRow(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	r.Append([]byte("0123456789ab\rX"))
+
+	if got := r.Content(); got != "0123456789Xb" {
+		t.Fatalf("expected %q, got %q", "0123456789Xb", got)
+	}
+	if row, col := r.CursorPosition(); row != 0 || col != 11 {
+		t.Fatalf("expected cursor at (0,11), got (%d,%d)", row, col)
+	}
+}
+
+func TestLineRenderer_Wrap_PendingAtLastColumn(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	r.Append([]byte("0123456789"))
+
+	if _, col := r.CursorPosition(); col != 9 {
+		t.Fatalf("expected cursor to stay on the last column, got %d", col)
+	}
+	r.Append([]byte("\r\nnext"))
+	if got := r.Content(); got != "0123456789\nnext" {
+		t.Fatalf("expected %q, got %q", "0123456789\nnext", got)
+	}
+}
+
+func TestLineRenderer_Wrap_ReadlineSpaceCR(t *testing.T) {
+	// Readline forces the wrap with a space and a carriage return, then
+	// goes on typing over the space.
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	r.Append([]byte("$ 12345678 \r9"))
+
+	if got := r.Content(); got != "$ 123456789" {
+		t.Fatalf("expected %q, got %q", "$ 123456789", got)
+	}
+	if row, col := r.CursorPosition(); row != 0 || col != 11 {
+		t.Fatalf("expected cursor at (0,11), got (%d,%d)", row, col)
+	}
+}
+
+func TestLineRenderer_Wrap_CursorUpMovesWithinLine(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	r.Append([]byte("before\r\n$ 123456789abc"))
+	// Ctrl+A: up one row, back to the start, past the prompt.
+	r.Append([]byte("\x1b[A\r\x1b[2CX"))
+
+	if got := r.Content(); got != "before\n$ X23456789abc" {
+		t.Fatalf("expected %q, got %q", "before\n$ X23456789abc", got)
+	}
+	if row, col := r.CursorPosition(); row != 1 || col != 3 {
+		t.Fatalf("expected cursor at (1,3), got (%d,%d)", row, col)
+	}
+
+	// Ctrl+E: readline moves down with a newline.
+	r.Append([]byte("\n\x1b[4C"))
+	if row, col := r.CursorPosition(); row != 1 || col != 14 {
+		t.Fatalf("expected cursor at (1,14), got (%d,%d)", row, col)
+	}
+	r.Append([]byte("\x1b[A\x1b[A"))
+	if row, col := r.CursorPosition(); row != 0 || col != 4 {
+		t.Fatalf("expected cursor up into the previous line at (0,4), got (%d,%d)", row, col)
+	}
+}
+
+func TestLineRenderer_Wrap_CursorMovesStopAtRowEdges(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	r.Append([]byte("0123456789abc\x1b[9D"))
+
+	if _, col := r.CursorPosition(); col != 10 {
+		t.Fatalf("expected cursor left to stop at the row start, got %d", col)
+	}
+	r.Append([]byte("\x1b[A\x1b[20C"))
+	if _, col := r.CursorPosition(); col != 9 {
+		t.Fatalf("expected cursor right to stop at the last column, got %d", col)
+	}
+}
+
+func TestLineRenderer_Wrap_ClearToEOLKeepsLaterRows(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(5)
+	r.Append([]byte("abcdefghij\x1b[A\r\x1b[2C\x1b[K"))
+
+	if got := r.Content(); got != "ab   fghij" {
+		t.Fatalf("expected %q, got %q", "ab   fghij", got)
+	}
+}
+
+func TestLineRenderer_Wrap_CJKPrompt(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	// "用户$ " takes 6 columns; 字 starts the second row.
+	r.Append([]byte("用户$ 中文字"))
+
+	if got := r.Content(); got != "用户$ 中文字" {
+		t.Fatalf("expected %q, got %q", "用户$ 中文字", got)
+	}
+	if row, col := r.CursorPosition(); row != 0 || col != 12 {
+		t.Fatalf("expected cursor at (0,12), got (%d,%d)", row, col)
+	}
+
+	// Up to the first row and overwrite 文, which fills it.
+	r.Append([]byte("\x1b[A\x1b[9G文"))
+	if row, col := r.CursorPosition(); row != 0 || col != 9 {
+		t.Fatalf("expected cursor on the last column at (0,9), got (%d,%d)", row, col)
+	}
+	if got := r.Content(); got != "用户$ 中文字" {
+		t.Fatalf("expected content unchanged, got %q", got)
+	}
+}
+
+func TestLineRenderer_Wrap_WideCharDoesNotSplitAcrossRows(t *testing.T) {
+	r := NewLineRenderer()
+	r.SetWidth(10)
+	r.Append([]byte("用户$ abc中"))
+
+	// 中 does not fit in the last column, so it starts the second row.
+	if got := r.Content(); got != "用户$ abc 中" {
+		t.Fatalf("expected %q, got %q", "用户$ abc 中", got)
+	}
+	if _, col := r.CursorPosition(); col != 12 {
+		t.Fatalf("expected cursor at col 12, got %d", col)
+	}
+}
+
+func TestLineRenderer_CombiningMarkTakesNoColumn(t *testing.T) {
+	r := NewLineRenderer()
+	r.Append([]byte("e\u0301x"))
+
+	if got := r.Content(); got != "e\u0301x" {
+		t.Fatalf("expected %q, got %q", "e\u0301x", got)
+	}
+	if _, col := r.CursorPosition(); col != 2 {
+		t.Fatalf("expected cursor at col 2, got %d", col)
+	}
+}
@@ -1,8 +1,25 @@
 package terminal
 
+// MaxRawLineBytes caps the raw bytes kept for one line by RawLines. A line
+// redrawn many times, like a progress bar, keeps its last bytes.
+const MaxRawLineBytes = 4096
+
+// LineMark places an OSC 133 prompt mark among the lines returned by
+// Append.
+type LineMark struct {
+	PromptMark
+	// Line is the index of the first line completed after the mark; it
+	// equals the number of lines returned when the line was still pending.
+	Line int
+	// Col is the cursor column in that line when the mark arrived. For
+	// MarkCommandStart it is where the typed command begins.
+	Col int
+}
+
 // Normalizer converts raw PTY output into normalized plain-text lines.
 // It handles common control sequences such as CR/LF, backspace, CSI cursor
-// left, OSC title sequences, and tabs.
+// left, OSC title sequences, and tabs. OSC 133 prompt marks are reported by
+// Marks.
 type Normalizer struct {
 	line           []byte
 	col            int
@@ -16,6 +33,10 @@ type Normalizer struct {
 	csiHasParam    bool
 	inOSC          bool
 	oscEscape      bool
+	osc            oscCollector
+	marks          []LineMark
+	raw            []byte
+	rawLines       [][]byte
 }
 
 // NewNormalizer creates a new PTY normalizer instance.
@@ -26,6 +47,8 @@ func NewNormalizer() *Normalizer {
 // Append processes raw PTY data and returns any completed normalized lines.
 // Lines are returned without ANSI/OSC sequences and without trailing newlines.
 func (n *Normalizer) Append(data []byte) [][]byte {
+	n.marks = n.marks[:0]
+	n.rawLines = nil
 	if len(data) == 0 {
 		return nil
 	}
@@ -33,22 +56,24 @@ func (n *Normalizer) Append(data []byte) [][]byte {
 	var lines [][]byte
 
 	for _, b := range data {
+		n.addRaw(b)
 		if n.inOSC {
 			if n.oscEscape {
 				if b == '\\' {
-					n.inOSC = false
+					n.endOSC(len(lines))
 				}
 				n.oscEscape = false
 				continue
 			}
 			if b == 0x07 {
-				n.inOSC = false
+				n.endOSC(len(lines))
 				continue
 			}
 			if b == 0x1b {
 				n.oscEscape = true
 				continue
 			}
+			n.osc.add(b)
 			continue
 		}
 
@@ -63,6 +88,7 @@ func (n *Normalizer) Append(data []byte) [][]byte {
 			if b == ']' {
 				n.inEscape = false
 				n.inOSC = true
+				n.osc.reset()
 				continue
 			}
 			// Ignore other single-char escape sequences.
@@ -214,6 +240,40 @@ func (n *Normalizer) Append(data []byte) [][]byte {
 	return lines
 }
 
+// Marks returns the prompt marks found by the last Append call, in order.
+func (n *Normalizer) Marks() []LineMark {
+	return append([]LineMark(nil), n.marks...)
+}
+
+// RawLines returns the PTY bytes each line returned by the last Append
+// call was made from, escape sequences included, in order. Bytes that
+// produced no line, such as a blank line, count towards the next one.
+func (n *Normalizer) RawLines() [][]byte {
+	return n.rawLines
+}
+
+func (n *Normalizer) addRaw(b byte) {
+	if len(n.raw) >= 2*MaxRawLineBytes {
+		n.raw = append(n.raw[:0], n.raw[len(n.raw)-MaxRawLineBytes:]...)
+	}
+	n.raw = append(n.raw, b)
+}
+
+// endOSC finishes an OSC sequence; completed is the number of lines the
+// current Append call has returned so far.
+func (n *Normalizer) endOSC(completed int) {
+	n.inOSC = false
+	if mark, ok := n.osc.promptMark(); ok {
+		n.marks = append(n.marks, LineMark{PromptMark: mark, Line: completed, Col: max(n.col, 0)})
+	}
+}
+
+// Pending returns the line being written that has no newline yet, such as
+// a prompt and the text typed after it.
+func (n *Normalizer) Pending() []byte {
+	return append([]byte(nil), n.line...)
+}
+
 func (n *Normalizer) flushLine(lines *[][]byte) {
 	if len(n.line) == 0 {
 		return
@@ -221,6 +281,9 @@ func (n *Normalizer) flushLine(lines *[][]byte) {
 	lineCopy := make([]byte, len(n.line))
 	copy(lineCopy, n.line)
 	*lines = append(*lines, lineCopy)
+	raw := n.raw[max(len(n.raw)-MaxRawLineBytes, 0):]
+	n.rawLines = append(n.rawLines, append([]byte(nil), raw...))
+	n.raw = n.raw[:0]
 	n.line = n.line[:0]
 	n.col = 0
 	n.pendingBS = false
@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"strings"
 	"testing"
 
 	"wtf_cli/pkg/capture"
@@ -113,6 +114,45 @@ func TestNormalizer_OSCStripped(t *testing.T) {
 	}
 }
 
+func TestNormalizer_RawLines(t *testing.T) {
+	n := NewNormalizer()
+	lines := n.Append([]byte("\x1b]0;title\x07one\r\n\n\x1b[31mtwo\x1b[0m\nthr"))
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	raw := n.RawLines()
+	if len(raw) != 2 {
+		t.Fatalf("expected 2 raw lines, got %d", len(raw))
+	}
+	if string(raw[0]) != "\x1b]0;title\x07one\r\n" {
+		t.Fatalf("unexpected raw line 0: %q", raw[0])
+	}
+	// The blank line produced no line, so its newline counts towards the next.
+	if string(raw[1]) != "\n\x1b[31mtwo\x1b[0m\n" {
+		t.Fatalf("unexpected raw line 1: %q", raw[1])
+	}
+
+	n.Append([]byte("ee\n"))
+	if raw := n.RawLines(); len(raw) != 1 || string(raw[0]) != "three\n" {
+		t.Fatalf("expected the pending bytes to carry over, got %q", raw)
+	}
+}
+
+func TestNormalizer_RawLinesKeepEnd(t *testing.T) {
+	n := NewNormalizer()
+	progress := strings.Repeat("\r50%", MaxRawLineBytes) + "\rdone\n"
+	n.Append([]byte(progress))
+
+	raw := n.RawLines()
+	if len(raw) != 1 || len(raw[0]) != MaxRawLineBytes {
+		t.Fatalf("expected one raw line of %d bytes, got %d lines", MaxRawLineBytes, len(raw))
+	}
+	if !strings.HasSuffix(string(raw[0]), "\rdone\n") {
+		t.Fatalf("expected the end of the line to be kept, got ...%q", raw[0][len(raw[0])-16:])
+	}
+}
+
 func TestNormalizer_TabExpansion(t *testing.T) {
 	n := NewNormalizer()
 	lines := n.Append([]byte("a\tb\n"))
@@ -0,0 +1,77 @@
+package terminal
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// OSC 133 ("semantic prompt") marks let a shell delimit its prompt, the
+// command typed at it and that command's output. Shells emit them when
+// configured for terminal integration (e.g. iTerm2, WezTerm, VS Code or
+// starship shell integration scripts).
+const (
+	MarkPromptStart  byte = 'A' // The prompt is about to be printed
+	MarkCommandStart byte = 'B' // The prompt ended; the command is typed next
+	MarkOutputStart  byte = 'C' // The command was submitted; its output follows
+	MarkCommandEnd   byte = 'D' // The command finished, optionally with its exit status
+)
+
+// maxOSCPayload bounds how much of an OSC sequence is kept for parsing.
+// Prompt marks are a few bytes; window titles and hyperlinks beyond this are
+// only skipped.
+const maxOSCPayload = 64
+
+// PromptMark is an OSC 133 mark found in PTY output.
+type PromptMark struct {
+	Kind     byte // MarkPromptStart, MarkCommandStart, MarkOutputStart or MarkCommandEnd
+	ExitCode int  // Exit status sent with MarkCommandEnd; -1 when there is none
+}
+
+// parsePromptMark parses the payload of an OSC sequence (between "ESC ]" and
+// its terminator), such as "133;D;1".
+func parsePromptMark(payload []byte) (PromptMark, bool) {
+	rest, ok := bytes.CutPrefix(payload, []byte("133;"))
+	if !ok || len(rest) == 0 {
+		return PromptMark{}, false
+	}
+	mark := PromptMark{Kind: rest[0], ExitCode: -1}
+	switch mark.Kind {
+	case MarkPromptStart, MarkCommandStart, MarkOutputStart:
+	case MarkCommandEnd:
+		if args, ok := bytes.CutPrefix(rest[1:], []byte(";")); ok {
+			status, _, _ := bytes.Cut(args, []byte(";"))
+			if code, err := strconv.Atoi(string(status)); err == nil && code >= 0 {
+				mark.ExitCode = code
+			}
+		}
+	default:
+		return PromptMark{}, false
+	}
+	if len(rest) > 1 && rest[1] != ';' {
+		return PromptMark{}, false
+	}
+	return mark, true
+}
+
+// oscCollector keeps the start of an OSC payload while it is skipped.
+type oscCollector struct {
+	payload []byte
+}
+
+func (c *oscCollector) reset() {
+	c.payload = c.payload[:0]
+}
+
+func (c *oscCollector) add(b byte) {
+	if len(c.payload) < maxOSCPayload {
+		c.payload = append(c.payload, b)
+	}
+}
+
+// promptMark parses the collected payload once the sequence has ended.
+func (c *oscCollector) promptMark() (PromptMark, bool) {
+	if len(c.payload) >= maxOSCPayload {
+		return PromptMark{}, false
+	}
+	return parsePromptMark(c.payload)
+}
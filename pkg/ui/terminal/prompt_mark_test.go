@@ -0,0 +1,76 @@
+package terminal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePromptMark(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    PromptMark
+		ok      bool
+	}{
+		{"133;A", PromptMark{Kind: MarkPromptStart, ExitCode: -1}, true},
+		{"133;A;cl=m;aid=42", PromptMark{Kind: MarkPromptStart, ExitCode: -1}, true},
+		{"133;B", PromptMark{Kind: MarkCommandStart, ExitCode: -1}, true},
+		{"133;C", PromptMark{Kind: MarkOutputStart, ExitCode: -1}, true},
+		{"133;D;2", PromptMark{Kind: MarkCommandEnd, ExitCode: 2}, true},
+		{"133;D;0;aid=42", PromptMark{Kind: MarkCommandEnd, ExitCode: 0}, true},
+		{"133;D", PromptMark{Kind: MarkCommandEnd, ExitCode: -1}, true},
+		{"133;Z", PromptMark{}, false},
+		{"133;AB", PromptMark{}, false},
+		{"0;window title", PromptMark{}, false},
+		{"7;file:///home/user", PromptMark{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePromptMark([]byte(tt.payload))
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parsePromptMark(%q) = %+v, %v; want %+v, %v", tt.payload, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalizer_PromptMarks(t *testing.T) {
+	n := NewNormalizer()
+	lines := n.Append([]byte("\x1b]133;A\x07user@host $ \x1b]133;B\x07make\r\n\x1b]133;C\x1b\\build failed\r\n\x1b]133;D;2\x07\x1b]133;A\x07user@host $ "))
+
+	if want := []string{"user@host $ make", "build failed"}; len(lines) != 2 || string(lines[0]) != want[0] || string(lines[1]) != want[1] {
+		t.Fatalf("Expected %q, got %q", want, lines)
+	}
+	marks := n.Marks()
+	want := []LineMark{
+		{PromptMark: PromptMark{Kind: MarkPromptStart, ExitCode: -1}, Line: 0, Col: 0},
+		{PromptMark: PromptMark{Kind: MarkCommandStart, ExitCode: -1}, Line: 0, Col: 12},
+		{PromptMark: PromptMark{Kind: MarkOutputStart, ExitCode: -1}, Line: 1, Col: 0},
+		{PromptMark: PromptMark{Kind: MarkCommandEnd, ExitCode: 2}, Line: 2, Col: 0},
+		{PromptMark: PromptMark{Kind: MarkPromptStart, ExitCode: -1}, Line: 2, Col: 0},
+	}
+	if !slices.Equal(marks, want) {
+		t.Fatalf("Expected marks %+v, got %+v", want, marks)
+	}
+
+	n.Append([]byte("ls\r\n"))
+	if len(n.Marks()) != 0 {
+		t.Fatalf("Expected marks of the previous call to be dropped, got %+v", n.Marks())
+	}
+}
+
+func TestLineRenderer_PromptRows(t *testing.T) {
+	r := NewLineRenderer()
+	r.Append([]byte("\x1b]133;A\x07$ ls\r\na b\r\n\x1b]133;A\x07$ pwd\r\n/tmp\r\n\x1b]133;A\x07$ "))
+	if got := r.PromptRows(); !slices.Equal(got, []int{0, 2, 4}) {
+		t.Fatalf("Expected prompt rows [0 2 4], got %v", got)
+	}
+
+	// A redrawn prompt replaces the one on the same row.
+	r.Append([]byte("\r\x1b]133;A\x07$ "))
+	if got := r.PromptRows(); !slices.Equal(got, []int{0, 2, 4}) {
+		t.Fatalf("Expected the redrawn prompt once, got %v", got)
+	}
+
+	r.Append([]byte("\x1b[2J"))
+	if got := r.PromptRows(); len(got) != 0 {
+		t.Fatalf("Expected clearing the screen to drop prompt rows, got %v", got)
+	}
+}
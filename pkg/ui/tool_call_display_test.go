@@ -131,8 +131,8 @@ func TestToolCallStartEvent_ReplacesPlaceholderWhenNoTextYet(t *testing.T) {
 	updated, _ := m.Update(commands.WtfStreamEvent{ToolCallStart: info})
 	m = updated.(Model)
 
-	if m.streamPlaceholderActive {
-		t.Error("expected streamPlaceholderActive=false after ToolCallStart replaces it")
+	if m.aiJobs.placeholder {
+		t.Error("expected aiJobs.placeholder=false after ToolCallStart replaces it")
 	}
 	content := latestAssistantMessageContent(t, m)
 	if strings.Contains(content, "Thinking") {
@@ -175,8 +175,8 @@ func TestToolCallFinishedEvent_SetsTurnNeededFlag(t *testing.T) {
 	updated, _ := m.Update(commands.WtfStreamEvent{ToolCallFinished: finished})
 	m = updated.(Model)
 
-	if !m.toolCallNewTurnNeeded {
-		t.Error("expected toolCallNewTurnNeeded=true after ToolCallFinished")
+	if !m.aiJobs.newTurnNeeded {
+		t.Error("expected aiJobs.newTurnNeeded=true after ToolCallFinished")
 	}
 }
 
@@ -215,7 +215,7 @@ func TestNextDeltaAfterToolCallStartsNewMessage(t *testing.T) {
 	if !strings.Contains(msgs[1].Content, "Based on the file,") {
 		t.Errorf("second message should contain continuation text, got %q", msgs[1].Content)
 	}
-	if m.toolCallNewTurnNeeded {
-		t.Error("toolCallNewTurnNeeded should be cleared after first delta of new turn")
+	if m.aiJobs.newTurnNeeded {
+		t.Error("aiJobs.newTurnNeeded should be cleared after first delta of new turn")
 	}
 }
@@ -0,0 +1,305 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/notify"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/statusbar"
+	"wtf_cli/pkg/ui/components/utils"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// triggerAction is what a trigger does when a line of output matches it.
+type triggerAction string
+
+const (
+	triggerNotify    triggerAction = "notify"    // Raise a trigger_matched notification
+	triggerHighlight triggerAction = "highlight" // Mark matching lines in the terminal
+	triggerAsk       triggerAction = "ask"       // Ask the AI to explain the output
+	triggerRun       triggerAction = "run"       // Run a command in the shell
+	triggerStatus    triggerAction = "status"    // Show the line in the status bar
+)
+
+// triggerActionNames maps the names accepted by /trigger add to actions.
+var triggerActionNames = map[string]triggerAction{
+	"notify":      triggerNotify,
+	"highlight":   triggerHighlight,
+	"ask":         triggerAsk,
+	"auto-ask-ai": triggerAsk,
+	"run":         triggerRun,
+	"run-snippet": triggerRun,
+	"status":      triggerStatus,
+}
+
+// triggerCooldown is how long a trigger stays quiet after acting, so a
+// build printing FAILED on every line notifies, asks or runs once.
+const triggerCooldown = 10 * time.Second
+
+// triggerStatusTTL is how long a status trigger shows the matched line.
+const triggerStatusTTL = 30 * time.Second
+
+const (
+	triggerFieldKey   = "triggers"
+	removeAllTriggers = "Remove all"
+	triggerUsage      = "Usage: /trigger add <regex> notify|highlight|status|ask|run <cmd>, /trigger clear"
+	triggerLabelWidth = 72
+)
+
+// trigger watches the terminal output for lines matching pattern.
+type trigger struct {
+	pattern *regexp.Regexp
+	action  triggerAction
+	snippet string    // Command typed into the shell by triggerRun
+	fired   time.Time // Last time the trigger acted
+	// ran is set once a run trigger acts and cleared when the user submits
+	// another command, so a snippet whose own output matches cannot loop.
+	ran bool
+}
+
+// String renders t as it is typed, e.g. `"FAILED" run make test`.
+func (t trigger) String() string {
+	s := fmt.Sprintf("%q %s", t.pattern.String(), t.action)
+	if t.snippet != "" {
+		s += " " + t.snippet
+	}
+	return s
+}
+
+// triggerHit is a trigger matched by an output line, acted on once the batch
+// of output it came in has been appended.
+type triggerHit struct {
+	trigger trigger
+	line    string
+}
+
+// parseTrigger parses the arguments of /trigger add: a regular expression,
+// quoted when it holds spaces, then an action and, for run, the command.
+func parseTrigger(args string) (trigger, error) {
+	expr, rest, err := cutTriggerPattern(strings.TrimSpace(args))
+	if err != nil {
+		return trigger{}, err
+	}
+	if expr == "" {
+		return trigger{}, fmt.Errorf("missing pattern")
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return trigger{}, fmt.Errorf("invalid pattern: %w", err)
+	}
+	name, snippet, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	action, ok := triggerActionNames[strings.ToLower(name)]
+	if !ok {
+		return trigger{}, fmt.Errorf("unknown action %q", name)
+	}
+	snippet = strings.TrimSpace(snippet)
+	switch {
+	case action == triggerRun && snippet == "":
+		return trigger{}, fmt.Errorf("run needs a command")
+	case action != triggerRun && snippet != "":
+		return trigger{}, fmt.Errorf("unexpected %q after %s", snippet, action)
+	}
+	return trigger{pattern: pattern, action: action, snippet: snippet}, nil
+}
+
+// cutTriggerPattern splits the leading pattern off s. A pattern starting
+// with a quote runs to the matching quote; otherwise to the first space.
+func cutTriggerPattern(s string) (pattern, rest string, err error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		pattern, rest, _ = strings.Cut(s, " ")
+		return pattern, rest, nil
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated quote in pattern")
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+// handleTriggerCommand runs /trigger with the text typed after it.
+func (m Model) handleTriggerCommand(args string) (Model, tea.Cmd) {
+	sub, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch sub {
+	case "", "list":
+		return m.openTriggersPicker()
+	case "add":
+		t, err := parseTrigger(rest)
+		if err != nil {
+			return m, m.flashStatus("Trigger not added: " + err.Error() + ". " + triggerUsage)
+		}
+		m.triggers = append(m.triggers, t)
+		m.applyTriggerHighlights()
+		slog.Info("trigger_add", "pattern", t.pattern.String(), "action", string(t.action))
+		return m, m.flashStatus("Trigger added: " + t.String())
+	case "clear":
+		m.triggers = nil
+		m.applyTriggerHighlights()
+		slog.Info("trigger_clear")
+		return m, m.flashStatus("Triggers removed")
+	}
+	return m, m.flashStatus(triggerUsage)
+}
+
+// openTriggersPicker lists the session's triggers so one, or all, can be
+// removed.
+func (m Model) openTriggersPicker() (Model, tea.Cmd) {
+	if len(m.triggers) == 0 {
+		return m, m.flashStatus("No triggers; " + triggerUsage)
+	}
+	options := make([]string, 0, len(m.triggers)+1)
+	for i, t := range m.triggers {
+		options = append(options, triggerOptionLabel(i, t))
+	}
+	options = append(options, removeAllTriggers)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Remove a trigger", triggerFieldKey, options, "")
+	return m, nil
+}
+
+// triggerOptionLabel renders a trigger as `1. "FAILED" notify`.
+func triggerOptionLabel(i int, t trigger) string {
+	return fmt.Sprintf("%d. %s", i+1, utils.TruncateToWidth(t.String(), triggerLabelWidth))
+}
+
+// removeTrigger removes the trigger picked in /trigger.
+func (m Model) removeTrigger(choice string) (Model, tea.Cmd) {
+	if choice == removeAllTriggers {
+		return m.handleTriggerCommand("clear")
+	}
+	for i, t := range m.triggers {
+		if triggerOptionLabel(i, t) != choice {
+			continue
+		}
+		m.triggers = append(m.triggers[:i:i], m.triggers[i+1:]...)
+		m.applyTriggerHighlights()
+		slog.Info("trigger_remove", "pattern", t.pattern.String(), "action", string(t.action))
+		return m, m.flashStatus("Trigger removed: " + t.String())
+	}
+	return m, nil
+}
+
+// applyTriggerHighlights marks the lines matched by highlight triggers in
+// every pane.
+func (m *Model) applyTriggerHighlights() {
+	var patterns []*regexp.Regexp
+	for _, t := range m.triggers {
+		if t.action == triggerHighlight {
+			patterns = append(patterns, t.pattern)
+		}
+	}
+	m.viewport.SetHighlights(patterns)
+	if m.split != nil {
+		m.split.viewport.SetHighlights(patterns)
+	}
+}
+
+// matchTriggers checks a line of output against the triggers. Highlights
+// are drawn by the viewport; other matches wait in triggerHits for
+// fireTriggers.
+func (m *Model) matchTriggers(line []byte) {
+	if len(m.triggers) == 0 || len(line) == 0 {
+		return
+	}
+	text := ansi.Strip(string(line))
+	now := time.Now()
+	for i := range m.triggers {
+		t := &m.triggers[i]
+		if t.action == triggerHighlight || t.ran || now.Sub(t.fired) < triggerCooldown || !t.pattern.MatchString(text) {
+			continue
+		}
+		t.fired = now
+		t.ran = t.action == triggerRun
+		m.triggerHits = append(m.triggerHits, triggerHit{trigger: *t, line: strings.TrimSpace(text)})
+	}
+}
+
+// fireTriggers acts on the triggers matched since the last call.
+func (m *Model) fireTriggers() tea.Cmd {
+	if len(m.triggerHits) == 0 && len(m.triggerRuns) == 0 {
+		return nil
+	}
+	hits := m.triggerHits
+	m.triggerHits = nil
+
+	var (
+		cmds  []tea.Cmd
+		asked bool
+	)
+	for _, hit := range hits {
+		t := hit.trigger
+		slog.Info("trigger_matched", "pattern", t.pattern.String(), "action", string(t.action))
+		switch t.action {
+		case triggerNotify:
+			cmds = append(cmds, m.notify(notify.Event{
+				Kind:    config.NotifyEventTriggerMatched,
+				Title:   "trigger: " + t.pattern.String(),
+				Message: truncateNotificationDetail(hit.line),
+			}))
+		case triggerStatus:
+			cmds = append(cmds, m.postStatus(statusbar.Message{
+				Source:   statusSourceTrigger,
+				Text:     utils.TruncateToWidth(hit.line, triggerLabelWidth),
+				Priority: statusbar.PriorityNormal,
+			}, triggerStatusTTL))
+		case triggerAsk:
+			if asked || m.hasActiveStream() {
+				continue
+			}
+			asked = true
+			cmds = append(cmds, func() tea.Msg {
+				return palette.PaletteSelectMsg{Command: "/explain"}
+			})
+		case triggerRun:
+			m.triggerRuns = append(m.triggerRuns, t.snippet)
+		}
+	}
+	m.runTriggerSnippet()
+	return tea.Batch(cmds...)
+}
+
+// runTriggerSnippet runs the first waiting run trigger snippet once the
+// shell is back at an empty prompt, so it never types into the program that
+// printed the match or onto a half-typed command line. A snippet that would
+// need confirming if typed by hand is typed but held for y or Enter, even
+// when confirm_commands is off: nobody is watching when a trigger fires.
+func (m *Model) runTriggerSnippet() {
+	if len(m.triggerRuns) == 0 || m.inputHandler == nil || m.typedConfirm != nil {
+		return
+	}
+	if !m.shellAtPrompt() || m.inputHandler.LineBuffer() != "" {
+		return // Output arriving later, with the prompt, calls us again
+	}
+	snippet := m.triggerRuns[0]
+	m.triggerRuns = m.triggerRuns[1:]
+	if m.readOnly {
+		slog.Info("trigger_run_skipped", "reason", "read_only")
+		return
+	}
+	if reason, ok := m.confirmReason(snippet); ok {
+		m.replacePromptCommand(snippet)
+		m.askTypedConfirm(snippet, "from a trigger, "+reason)
+		return
+	}
+	slog.Info("trigger_run")
+	m.inputHandler.SendToPTY([]byte(snippet + "\r"))
+}
+
+// resetTriggerRuns lets run triggers act again once the user submits
+// a command. A trigger's own snippet does not count, so confirming it cannot
+// start the trigger over.
+func (m *Model) resetTriggerRuns(command string) {
+	command = strings.TrimSpace(command)
+	for i := range m.triggers {
+		if m.triggers[i].snippet != command {
+			m.triggers[i].ran = false
+		}
+	}
+}
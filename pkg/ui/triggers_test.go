@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/notify"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/input"
+)
+
+func TestParseTrigger(t *testing.T) {
+	tests := []struct {
+		args    string
+		pattern string
+		action  triggerAction
+		snippet string
+		wantErr string
+	}{
+		{args: "FAILED notify", pattern: "FAILED", action: triggerNotify},
+		{args: `"build failed" highlight`, pattern: "build failed", action: triggerHighlight},
+		{args: `'panic: .*' auto-ask-ai`, pattern: "panic: .*", action: triggerAsk},
+		{args: "Killed run-snippet dmesg | tail", pattern: "Killed", action: triggerRun, snippet: "dmesg | tail"},
+		{args: "^Step\\s[0-9]+/[0-9]+ status", pattern: `^Step\s[0-9]+/[0-9]+`, action: triggerStatus},
+		{args: "", wantErr: "missing pattern"},
+		{args: `"open quote notify`, wantErr: "unterminated quote"},
+		{args: "([ notify", wantErr: "invalid pattern"},
+		{args: "FAILED shout", wantErr: `unknown action "shout"`},
+		{args: "FAILED run", wantErr: "run needs a command"},
+		{args: "FAILED notify loudly", wantErr: `unexpected "loudly"`},
+	}
+	for _, tt := range tests {
+		got, err := parseTrigger(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseTrigger(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTrigger(%q) error = %v", tt.args, err)
+			continue
+		}
+		if got.pattern.String() != tt.pattern || got.action != tt.action || got.snippet != tt.snippet {
+			t.Errorf("parseTrigger(%q) = %q %s %q", tt.args, got.pattern, got.action, got.snippet)
+		}
+	}
+}
+
+func TestTriggerCommand_AddAndRemove(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/trigger", Args: "add ERROR highlight"})
+	m = newModel.(Model)
+	if len(m.triggers) != 1 {
+		t.Fatalf("Expected one trigger, got %d", len(m.triggers))
+	}
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, `"ERROR" highlight`) {
+		t.Fatalf("Expected confirmation, got %q", got)
+	}
+
+	newModel, _ = m.Update(palette.PaletteSelectMsg{Command: "/trigger", Args: "add ERROR explode"})
+	m = newModel.(Model)
+	if len(m.triggers) != 1 || !strings.Contains(m.statusBar.GetMessage(), "unknown action") {
+		t.Fatalf("Expected a bad action to be rejected, got %q", m.statusBar.GetMessage())
+	}
+
+	newModel, _ = m.Update(palette.PaletteSelectMsg{Command: "/trigger"})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected /trigger to list triggers in a picker")
+	}
+
+	m, _ = m.removeTrigger(triggerOptionLabel(0, m.triggers[0]))
+	if len(m.triggers) != 0 {
+		t.Fatalf("Expected the trigger removed, got %d", len(m.triggers))
+	}
+}
+
+func TestTriggers_NotifyOncePerCooldown(t *testing.T) {
+	m := newNotifyTestModel(config.NotificationsConfig{
+		StatusBar: config.NotificationSinkConfig{Enabled: true},
+	})
+	m, _ = m.handleTriggerCommand("add FAILED notify")
+
+	m.appendNormalizedLines([]byte("ok 1\n--- FAILED: TestA\n--- FAILED: TestB\n"))
+	if len(m.triggerHits) != 1 {
+		t.Fatalf("Expected one hit within the cooldown, got %d", len(m.triggerHits))
+	}
+	m.fireTriggers()
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, "FAILED: TestA") {
+		t.Fatalf("Expected a trigger notification, got %q", got)
+	}
+
+	m.triggers[0].fired = time.Now().Add(-triggerCooldown)
+	m.appendNormalizedLines([]byte("--- FAILED: TestC\n"))
+	if len(m.triggerHits) != 1 {
+		t.Fatalf("Expected the trigger to act again after the cooldown, got %d", len(m.triggerHits))
+	}
+}
+
+func TestTriggers_StatusShowsMatchedLine(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handleTriggerCommand("add ^Step status")
+
+	m.appendNormalizedLines([]byte("Step 3/7 : RUN go build\n"))
+	if cmd := m.fireTriggers(); cmd == nil {
+		t.Fatal("Expected an expiry for the status message")
+	}
+	if got := m.statusBar.GetMessage(); got != "Step 3/7 : RUN go build" {
+		t.Fatalf("Expected the matched line in the status bar, got %q", got)
+	}
+
+	m.flashStatus("Saved")
+	m, _ = m.handleStatusExpired(statusExpiredMsg{source: statusSourceFlash, text: "Saved"})
+	if got := m.statusBar.GetMessage(); got != "Step 3/7 : RUN go build" {
+		t.Fatalf("Expected the trigger message back after the toast, got %q", got)
+	}
+}
+
+func TestTriggers_NotifyEventIsFilterable(t *testing.T) {
+	cfg := config.NotificationsConfig{StatusBar: config.NotificationSinkConfig{Enabled: true}}
+	if !notify.New(cfg).Wants(notify.SinkStatusBar, config.NotifyEventTriggerMatched) {
+		t.Fatal("Expected trigger_matched among the default events")
+	}
+	cfg.StatusBar.Events = []string{config.NotifyEventStreamFinished}
+	if notify.New(cfg).Wants(notify.SinkStatusBar, config.NotifyEventTriggerMatched) {
+		t.Fatal("Expected trigger_matched to be filtered out")
+	}
+}
+
+func newTriggerRunTestModel(t *testing.T, atPrompt *bool) (Model, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+	m := NewModel(w, buffer.New(100), capture.NewSessionContext(), nil)
+	m.inputHandler = input.NewInputHandler(w)
+	m.shellInForeground = func(*os.File) bool { return *atPrompt }
+	return m, r
+}
+
+func readPTY(t *testing.T, r *os.File) string {
+	t.Helper()
+	got := make([]byte, 64)
+	n, err := r.Read(got)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(got[:n])
+}
+
+func TestTriggers_RunSendsSnippet(t *testing.T) {
+	atPrompt := true
+	m, r := newTriggerRunTestModel(t, &atPrompt)
+	m, _ = m.handleTriggerCommand("add Killed run dmesg | tail -5")
+
+	m.matchTriggers([]byte("\x1b[31mKilled\x1b[0m"))
+	m.fireTriggers()
+
+	if got := readPTY(t, r); got != "dmesg | tail -5\r" {
+		t.Fatalf("Expected the snippet typed into the shell, got %q", got)
+	}
+}
+
+func TestTriggers_RunWaitsForPrompt(t *testing.T) {
+	atPrompt := false
+	m, r := newTriggerRunTestModel(t, &atPrompt)
+	m, _ = m.handleTriggerCommand("add Killed run dmesg")
+
+	m.matchTriggers([]byte("Killed"))
+	m.fireTriggers()
+	if len(m.triggerRuns) != 1 {
+		t.Fatalf("Expected the snippet to wait while a job runs, got %d waiting", len(m.triggerRuns))
+	}
+
+	m.inputHandler.SetLineBuffer("git st")
+	atPrompt = true
+	m.fireTriggers()
+	if len(m.triggerRuns) != 1 {
+		t.Fatal("Expected the snippet to wait while a command is half typed")
+	}
+
+	m.inputHandler.SetLineBuffer("")
+	m.fireTriggers()
+	if len(m.triggerRuns) != 0 {
+		t.Fatal("Expected the snippet to run at the prompt")
+	}
+	if got := readPTY(t, r); got != "dmesg\r" {
+		t.Fatalf("Expected the snippet typed into the shell, got %q", got)
+	}
+}
+
+func TestTriggers_RunOncePerCommand(t *testing.T) {
+	atPrompt := true
+	m, r := newTriggerRunTestModel(t, &atPrompt)
+	m, _ = m.handleTriggerCommand("add Killed run dmesg")
+
+	m.matchTriggers([]byte("Killed"))
+	m.fireTriggers()
+	readPTY(t, r)
+
+	// The snippet's own output matching again must not run it again.
+	m.triggers[0].fired = time.Time{}
+	m.matchTriggers([]byte("oom: Killed process 42"))
+	if len(m.triggerHits) != 0 {
+		t.Fatal("Expected a run trigger to act once per command")
+	}
+	newModel, _ := m.Update(input.CommandSubmittedMsg{Command: "dmesg"})
+	m = newModel.(Model)
+	m.matchTriggers([]byte("Killed"))
+	if len(m.triggerHits) != 0 {
+		t.Fatal("Expected the trigger's own snippet not to re-arm it")
+	}
+
+	newModel, _ = m.Update(input.CommandSubmittedMsg{Command: "make"})
+	m = newModel.(Model)
+	m.matchTriggers([]byte("Killed"))
+	if len(m.triggerHits) != 1 {
+		t.Fatal("Expected the trigger to act again for the next command")
+	}
+}
+
+func TestTriggers_RunConfirmsDestructiveSnippet(t *testing.T) {
+	atPrompt := true
+	m, r := newTriggerRunTestModel(t, &atPrompt)
+	m, _ = m.handleTriggerCommand("add Killed run rm -rf build")
+
+	m.matchTriggers([]byte("Killed"))
+	m.fireTriggers()
+
+	if got := readPTY(t, r); strings.Contains(got, "\r") || !strings.Contains(got, "rm -rf build") {
+		t.Fatalf("Expected the snippet typed but not run, got %q", got)
+	}
+	if m.typedConfirm == nil || m.typedConfirm.command != "rm -rf build" {
+		t.Fatalf("Expected the snippet to wait for confirmation, got %+v", m.typedConfirm)
+	}
+}
+
+func TestTriggers_AskExplainsOncePerBatch(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handleTriggerCommand("add panic ask")
+	m, _ = m.handleTriggerCommand("add segfault ask")
+
+	m.matchTriggers([]byte("panic: runtime error"))
+	m.matchTriggers([]byte("segfault at 0"))
+	cmd := m.fireTriggers()
+	if cmd == nil {
+		t.Fatal("Expected a command asking the AI")
+	}
+	msg, ok := cmd().(palette.PaletteSelectMsg)
+	if !ok || msg.Command != "/explain" {
+		t.Fatalf("Expected /explain to be dispatched once, got %#v", msg)
+	}
+	if m.triggerHits != nil {
+		t.Fatal("Expected the hits to be consumed")
+	}
+}
+
+func TestTriggers_HighlightIsNotFired(t *testing.T) {
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m, _ = m.handleTriggerCommand("add ERROR highlight")
+
+	m.matchTriggers([]byte("ERROR: disk full"))
+	if len(m.triggerHits) != 0 {
+		t.Fatal("Expected highlight triggers to be drawn, not fired")
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"log/slog"
+
+	"wtf_cli/pkg/ai"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// trustFieldKey routes option picker selections made in the workspace trust
+// prompt and from /trust.
+const trustFieldKey = "workspace_trust"
+
+// trustOptions are the choices of the workspace trust prompt, most open
+// first.
+var trustOptions = []struct {
+	label string
+	trust ai.WorkspaceTrust
+}{
+	{"Trust — share project files, tasks and /env variables", ai.TrustTrusted},
+	{"Restricted — share the project type and toolchain only", ai.TrustRestricted},
+	{"Never — share nothing but the terminal output", ai.TrustNever},
+}
+
+// chatResumeMsg starts the chat answer that waited for the trust prompt.
+type chatResumeMsg struct {
+	preview bool // Show the prompt preview first
+}
+
+func (m Model) workspaceTrustFile() string {
+	if m.workspaceTrustPath != "" {
+		return m.workspaceTrustPath
+	}
+	return ai.DefaultWorkspaceTrustPath()
+}
+
+// workspaceTrust returns the remembered decision for the shell's directory.
+func (m Model) workspaceTrust() ai.WorkspaceTrust {
+	return ai.LookupWorkspaceTrust(m.workspaceTrustFile(), m.currentDir)
+}
+
+// needsWorkspaceTrust reports whether an AI request from here has to ask
+// first: the directory is known and nothing was decided for it.
+func (m Model) needsWorkspaceTrust() bool {
+	return m.currentDir != "" && m.workspaceTrust() == ai.TrustUndecided
+}
+
+// askWorkspaceTrust shows the trust prompt. Once answered, pending is
+// dispatched again so the request runs with the decision applied.
+func (m Model) askWorkspaceTrust(pending tea.Msg) (Model, tea.Cmd) {
+	m.trustPending = pending
+	return m.openTrustPicker()
+}
+
+// openTrustPicker shows the trust choices for the current workspace with
+// the remembered one selected.
+func (m Model) openTrustPicker() (Model, tea.Cmd) {
+	if m.currentDir == "" {
+		return m, m.flashStatus("Current directory is not known yet")
+	}
+	workspace := ai.ProjectRoot(m.currentDir)
+	current := m.workspaceTrust()
+	options := make([]string, 0, len(trustOptions))
+	selected := ""
+	for _, o := range trustOptions {
+		options = append(options, o.label)
+		if o.trust == current {
+			selected = o.label
+		}
+	}
+
+	slog.Info("workspace_trust_prompt", "workspace", workspace, "current", string(current), "pending", m.trustPending != nil)
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show("Trust this workspace? "+workspace, trustFieldKey, options, selected)
+	return m, nil
+}
+
+// saveWorkspaceTrust remembers the picked decision for the workspace and
+// resumes the request that asked for it, if any.
+func (m Model) saveWorkspaceTrust(label string) (Model, tea.Cmd) {
+	pending := m.trustPending
+	m.trustPending = nil
+
+	var trust ai.WorkspaceTrust
+	for _, o := range trustOptions {
+		if o.label == label {
+			trust = o.trust
+		}
+	}
+	if !trust.Valid() {
+		return m, nil
+	}
+	workspace := ai.ProjectRoot(m.currentDir)
+	if err := ai.SaveWorkspaceTrust(m.workspaceTrustFile(), workspace, trust); err != nil {
+		slog.Error("workspace_trust_save_error", "error", err)
+		return m, m.flashStatus("Could not save workspace trust: " + err.Error())
+	}
+	slog.Info("workspace_trust_saved", "workspace", workspace, "trust", string(trust))
+
+	if pending != nil {
+		return m, func() tea.Msg { return pending }
+	}
+	return m, m.flashStatus("Workspace " + string(trust) + ": " + workspace)
+}
+
+func (m Model) handleChatResume(msg chatResumeMsg) (Model, tea.Cmd) {
+	if m.sidebar == nil || m.hasActiveStream() {
+		return m, nil
+	}
+	if m.budgetBlocks() {
+		return m.askBudgetOverride(msg)
+	}
+	if msg.preview {
+		return m, m.previewChatRun()
+	}
+	return m.startChatRun()
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/ui/components/picker"
+	"wtf_cli/pkg/ui/components/sidebar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// trustWorkspace points m at a fresh trust store holding trust for its
+// current directory.
+func trustWorkspace(t *testing.T, m *Model, trust ai.WorkspaceTrust) {
+	t.Helper()
+	m.workspaceTrustPath = filepath.Join(t.TempDir(), "workspace_trust.json")
+	if trust == ai.TrustUndecided {
+		return
+	}
+	if err := ai.SaveWorkspaceTrust(m.workspaceTrustPath, ai.ProjectRoot(m.currentDir), trust); err != nil {
+		t.Fatalf("save trust: %v", err)
+	}
+}
+
+func newTrustTestModel(t *testing.T) Model {
+	t.Helper()
+	buf := buffer.New(100)
+	buf.Write([]byte("make: *** [test] Error 1"))
+	m := NewModel(nil, buf, capture.NewSessionContext(), nil)
+	m.currentDir = t.TempDir()
+	trustWorkspace(t, &m, ai.TrustUndecided)
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return newModel.(Model)
+}
+
+func TestModel_ExplainAsksWorkspaceTrustFirst(t *testing.T) {
+	m := newTrustTestModel(t)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/explain"})
+	m = newModel.(Model)
+	if m.hasActiveStream() {
+		t.Fatal("Expected no stream before the workspace is trusted")
+	}
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the trust prompt")
+	}
+	if !strings.Contains(m.optionPicker.View(), "Trust this workspace?") {
+		t.Fatalf("Expected trust prompt title, got:\n%s", m.optionPicker.View())
+	}
+
+	newModel, cmd := m.Update(picker.OptionPickerSelectMsg{FieldKey: trustFieldKey, Value: trustOptions[1].label})
+	m = newModel.(Model)
+	if got := m.workspaceTrust(); got != ai.TrustRestricted {
+		t.Fatalf("Expected restricted trust remembered, got %q", got)
+	}
+	if cmd == nil {
+		t.Fatal("Expected the pending /explain to be resumed")
+	}
+	if sel, ok := cmd().(palette.PaletteSelectMsg); !ok || sel.Command != "/explain" {
+		t.Fatalf("Expected the /explain selection to be dispatched again, got %#v", cmd())
+	}
+
+	if ctx := m.commandContext(); ctx.Trust != ai.TrustRestricted {
+		t.Fatalf("Expected restricted trust in the command context, got %q", ctx.Trust)
+	}
+}
+
+func TestModel_ChatSubmitWaitsForWorkspaceTrust(t *testing.T) {
+	m := newTrustTestModel(t)
+	m.ensureSidebar().Show()
+
+	newModel, _ := m.Update(sidebar.ChatSubmitMsg{Content: "why did make fail?"})
+	m = newModel.(Model)
+	if m.hasActiveStream() {
+		t.Fatal("Expected no stream before the workspace is trusted")
+	}
+	if msgs := m.sidebar.GetMessages(); len(msgs) != 1 || msgs[0].Content != "why did make fail?" {
+		t.Fatalf("Expected the question to stay in the chat, got %+v", msgs)
+	}
+
+	_, cmd := m.Update(picker.OptionPickerSelectMsg{FieldKey: trustFieldKey, Value: trustOptions[2].label})
+	if cmd == nil {
+		t.Fatal("Expected the chat answer to be resumed")
+	}
+	if _, ok := cmd().(chatResumeMsg); !ok {
+		t.Fatalf("Expected chatResumeMsg, got %T", cmd())
+	}
+}
+
+func TestModel_TrustCommandShowsCurrentDecision(t *testing.T) {
+	m := newTrustTestModel(t)
+	trustWorkspace(t, &m, ai.TrustNever)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/trust"})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the trust picker")
+	}
+	if m.trustPending != nil {
+		t.Fatal("Expected nothing pending when opened from /trust")
+	}
+
+	newModel, cmd := m.Update(picker.OptionPickerSelectMsg{FieldKey: trustFieldKey, Value: trustOptions[0].label})
+	m = newModel.(Model)
+	if got := m.workspaceTrust(); got != ai.TrustTrusted {
+		t.Fatalf("Expected trusted, got %q", got)
+	}
+	if cmd == nil {
+		t.Fatal("Expected a status flash")
+	}
+	if msg := m.statusBar.GetMessage(); !strings.Contains(msg, "Workspace trusted") {
+		t.Fatalf("Expected trust status, got %q", msg)
+	}
+}
+
+func TestModel_EscOnTrustPromptDropsPendingRequest(t *testing.T) {
+	m := newTrustTestModel(t)
+
+	newModel, _ := m.Update(palette.PaletteSelectMsg{Command: "/explain"})
+	m = newModel.(Model)
+	if m.optionPicker == nil || !m.optionPicker.IsVisible() {
+		t.Fatal("Expected the trust prompt")
+	}
+	newModel, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected Esc to report the cancelled prompt")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+	if m.trustPending != nil {
+		t.Fatal("Expected Esc to drop the waiting /explain")
+	}
+
+	// A later /trust answer only saves the decision.
+	newModel, _ = m.Update(palette.PaletteSelectMsg{Command: "/trust"})
+	m = newModel.(Model)
+	newModel, _ = m.Update(picker.OptionPickerSelectMsg{FieldKey: trustFieldKey, Value: trustOptions[0].label})
+	m = newModel.(Model)
+	if m.workspaceTrust() != ai.TrustTrusted {
+		t.Fatal("Expected the /trust answer saved")
+	}
+	if got := m.statusBar.GetMessage(); !strings.Contains(got, "Workspace trusted") {
+		t.Fatalf("Expected the saved decision flashed instead of a replayed request, got %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"wtf_cli/pkg/cmdnotfound"
+	"wtf_cli/pkg/ui/components/statusbar"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// typoHintKey accepts a "did you mean" suggestion. It is a plain key, so it
+// is only claimed as the first keystroke at an empty prompt; any other key
+// dismisses the suggestion.
+const typoHintKey = "y"
+
+// typoHint is a corrected command line offered after a mistyped command.
+type typoHint struct {
+	corrected string
+}
+
+func (h typoHint) statusText() string {
+	return fmt.Sprintf("did you mean `%s`? [y]", h.corrected)
+}
+
+// suggestCorrection offers a corrected command line when the missing command
+// looks like a typo of a recent command or a binary on PATH. It reports
+// whether a suggestion was made.
+func (m *Model) suggestCorrection(command string) bool {
+	if m.session == nil {
+		return false
+	}
+	records := m.session.GetHistory()
+	if len(records) == 0 {
+		return false
+	}
+	line := records[len(records)-1].Command
+	history := make([]string, 0, len(records)-1)
+	for i := len(records) - 2; i >= 0; i-- {
+		history = append(history, records[i].Command)
+	}
+	var binaries []string
+	if m.pathBinaries != nil {
+		binaries = m.pathBinaries()
+	}
+
+	corrected, ok := cmdnotfound.Correct(line, command, history, binaries)
+	if !ok {
+		return false
+	}
+	hint := typoHint{corrected: corrected}
+	m.typoHint = &hint
+	m.postStatus(statusbar.Message{Source: statusSourceHint, Text: hint.statusText(), Priority: statusbar.PriorityNormal}, 0)
+	slog.Info("command_typo", "command", line, "suggestion", corrected)
+	return true
+}
+
+// clearTypoHint drops the suggestion and its status message.
+func (m *Model) clearTypoHint() {
+	if m.typoHint == nil {
+		return
+	}
+	m.clearStatusText(statusSourceHint, m.typoHint.statusText())
+	m.typoHint = nil
+}
+
+// handleTypoHintKey accepts the suggestion on typoHintKey at an empty prompt
+// and otherwise dismisses it, letting the key through.
+func (m Model) handleTypoHintKey(msg tea.KeyPressMsg) (Model, bool) {
+	if msg.String() != typoHintKey || m.inputHandler == nil || m.inputHandler.LineBuffer() != "" {
+		m.clearTypoHint()
+		return m, false
+	}
+	corrected := m.typoHint.corrected
+	m.clearTypoHint()
+	slog.Info("command_typo_accept", "suggestion", corrected)
+	m.replacePromptCommand(corrected)
+	return m, true
+}
+
+// pathBinariesCache holds the executables found on PATH the last time it
+// was scanned. Reading every directory on PATH is too slow to repeat in
+// Update for each hint, and PATH rarely changes during a session.
+var pathBinariesCache struct {
+	sync.Mutex
+	path     string
+	binaries []string
+}
+
+func pathBinaries() []string {
+	return cachedPathBinaries(os.Getenv("PATH"))
+}
+
+// cachedPathBinaries returns the executables on pathEnv, scanning only when
+// it differs from the last one scanned.
+func cachedPathBinaries(pathEnv string) []string {
+	c := &pathBinariesCache
+	c.Lock()
+	defer c.Unlock()
+	if c.binaries == nil || c.path != pathEnv {
+		c.path = pathEnv
+		c.binaries = cmdnotfound.PathBinaries(pathEnv)
+	}
+	return c.binaries
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/testutils"
+)
+
+func TestTypoHint_SuggestsBinaryAndInjectsOnY(t *testing.T) {
+	m, r := newInstallHintTestModel(t, "gti status")
+	m.pathBinaries = func() []string { return []string{"git", "grep"} }
+
+	m.detectCommandNotFound([]byte("bash: gti: command not found"))
+	if m.typoHint == nil {
+		t.Fatal("Expected a typo suggestion")
+	}
+	if m.installHint != nil {
+		t.Fatal("Expected the correction to take precedence over an install hint")
+	}
+	if msg := m.statusBar.GetMessage(); msg != "did you mean `git status`? [y]" {
+		t.Fatalf("Unexpected status message %q", msg)
+	}
+
+	updated, _ := m.handleKeyPress(testutils.NewTextKeyPressMsg("y"))
+	m = updated
+	if m.typoHint != nil || m.statusBar.GetMessage() != "" {
+		t.Fatal("Expected the suggestion to clear after accepting")
+	}
+	got := make([]byte, 64)
+	n, err := r.Read(got)
+	if err != nil {
+		t.Fatalf("read pty: %v", err)
+	}
+	if out := string(got[:n]); !strings.HasSuffix(out, "git status") || strings.Contains(out, "y") {
+		t.Fatalf("Expected corrected command typed without the y, got %q", out)
+	}
+}
+
+func TestTypoHint_PrefersHistory(t *testing.T) {
+	m, _ := newInstallHintTestModel(t, "make build")
+	m.session.AddCommand(capture.CommandRecord{Command: "mkae build"})
+	m.pathBinaries = func() []string { return nil }
+
+	m.detectCommandNotFound([]byte("zsh: command not found: mkae"))
+	if m.typoHint == nil || m.typoHint.corrected != "make build" {
+		t.Fatalf("Expected history correction, got %+v", m.typoHint)
+	}
+}
+
+func TestTypoHint_OtherKeyDismisses(t *testing.T) {
+	m, r := newInstallHintTestModel(t, "gti status")
+	m.pathBinaries = func() []string { return []string{"git"} }
+	m.detectCommandNotFound([]byte("bash: gti: command not found"))
+
+	updated, _ := m.handleKeyPress(testutils.NewTextKeyPressMsg("l"))
+	m = updated
+	if m.typoHint != nil || m.statusBar.GetMessage() != "" {
+		t.Fatal("Expected any other key to dismiss the suggestion")
+	}
+	got := make([]byte, 8)
+	n, _ := r.Read(got)
+	if string(got[:n]) != "l" {
+		t.Fatalf("Expected the key to reach the shell, got %q", got[:n])
+	}
+}
+
+func TestTypoHint_KnownToolGetsInstallHint(t *testing.T) {
+	m, _ := newInstallHintTestModel(t, "htop")
+	m.pathBinaries = func() []string { return []string{"top"} }
+
+	m.detectCommandNotFound([]byte("bash: htop: command not found"))
+	if m.typoHint != nil {
+		t.Fatalf("Expected no typo suggestion for a known tool, got %+v", m.typoHint)
+	}
+	if m.installHint == nil {
+		t.Fatal("Expected an install hint")
+	}
+}
+
+func TestCachedPathBinaries_RescansOnlyWhenPATHChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gitx"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := cachedPathBinaries(dir); !slices.Contains(got, "gitx") {
+		t.Fatalf("Expected gitx on PATH, got %v", got)
+	}
+
+	// A new binary in the same PATH is served from the cache...
+	if err := os.WriteFile(filepath.Join(dir, "gity"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := cachedPathBinaries(dir); slices.Contains(got, "gity") {
+		t.Fatalf("Expected the cached scan, got %v", got)
+	}
+	// ...and picked up once PATH changes.
+	if got := cachedPathBinaries(dir + string(os.PathListSeparator)); !slices.Contains(got, "gity") {
+		t.Fatalf("Expected a rescan for a new PATH, got %v", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/capture"
 	"wtf_cli/pkg/commands"
 	"wtf_cli/pkg/config"
@@ -34,16 +35,11 @@ func (m Model) handleFocusSwitch() (Model, tea.Cmd) {
 	if m.hasBlockingOverlay() {
 		return m, nil
 	}
-	if m.sidebar == nil {
-		return m, nil
-	}
-	if m.sidebar.IsVisible() {
+	if m.sidebar != nil && m.sidebar.IsVisible() {
 		m.setTerminalFocused(!m.terminalFocused)
 		return m, nil
 	}
-	if !m.sidebar.IsVisible() {
-		m.showSidebar("shift_tab")
-	}
+	m.showSidebar("shift_tab")
 	return m, nil
 }
 
@@ -51,9 +47,16 @@ func (m Model) handlePaletteSelect(msg palette.PaletteSelectMsg) (Model, tea.Cmd
 	// Command selected from palette
 	slog.Info("palette_select", "command", msg.Command)
 	m.inputHandler.SetPaletteMode(false)
+	selected := msg
 
 	// Execute the command
-	ctx := commands.NewContext(m.buffer, m.session, m.currentDir)
+	ctx := m.commandContext()
+	ctx.Args = msg.Args
+	msg.Command = m.applyAlias(msg.Command, ctx)
+	if m.policy.DisablesCommand(msg.Command) {
+		slog.Info("palette_command_disabled", "command", msg.Command)
+		return m, m.flashStatus(msg.Command + " is disabled by your organization")
+	}
 	handler, ok := m.dispatcher.GetHandler(msg.Command)
 	if !ok {
 		m.resultPanel.Show("Error", "Unknown command: "+msg.Command)
@@ -65,12 +68,55 @@ func (m Model) handlePaletteSelect(msg palette.PaletteSelectMsg) (Model, tea.Cmd
 	case commands.ResultActionOpenSettings:
 		slog.Info("settings_open")
 		cfg, _ := config.Load(config.GetConfigPath())
-		m.settingsPanel.SetSize(m.width, m.height)
-		m.settingsPanel.Show(cfg, config.GetConfigPath())
+		m.ensureSettingsPanel().Show(cfg, config.GetConfigPath())
 		if cfg.LLMProvider == "copilot" {
-			return m, fetchCopilotAuthStatusCmd(false)
+			return m, m.runAsync(copilotAuthLabel, fetchCopilotAuthStatus(false))
 		}
 		return m, nil
+	case commands.ResultActionOpenLanguagePicker:
+		return m.openLanguagePicker()
+	case commands.ResultActionWatch:
+		return m.handleWatchCommand(ctx.Args)
+	case commands.ResultActionOpenEnvPicker:
+		return m.openEnvPicker()
+	case commands.ResultActionOpenPrompts:
+		return m.openPromptsPicker()
+	case commands.ResultActionOpenUnpinPicker:
+		return m.openUnpinPicker()
+	case commands.ResultActionOpenTasksPicker:
+		return m.openTasksPicker()
+	case commands.ResultActionOpenTrustPicker:
+		return m.openTrustPicker()
+	case commands.ResultActionOpenLogs:
+		return m.openLogs()
+	case commands.ResultActionRemember:
+		return m.remember(ctx.Args)
+	case commands.ResultActionOpenMemories:
+		return m.openMemoriesPicker()
+	case commands.ResultActionOpenSnippets:
+		return m.openSnippetsPicker("")
+	case commands.ResultActionOpenProviderPicker:
+		return m.openProviderPicker()
+	case commands.ResultActionMark:
+		return m.addBookmark(ctx.Args)
+	case commands.ResultActionOpenMarks:
+		return m.openBookmarksPicker()
+	case commands.ResultActionOpenReplay:
+		return m.openReplay()
+	case commands.ResultActionPrivacy:
+		return m.handlePrivacyCommand(ctx.Args)
+	case commands.ResultActionReadOnly:
+		return m.handleReadOnlyCommand(ctx.Args)
+	case commands.ResultActionTrigger:
+		return m.handleTriggerCommand(ctx.Args)
+	case commands.ResultActionOpenPeek:
+		return m.openPeekPicker()
+	case commands.ResultActionExportScript:
+		var messages []ai.ChatMessage
+		if m.sidebar != nil {
+			messages = m.sidebar.GetMessages()
+		}
+		return m.openExportScriptPicker(messages)
 	case commands.ResultActionOpenHistoryPicker:
 		slog.Info("history_picker_from_command")
 		// Emit ShowHistoryPickerMsg with empty initial filter
@@ -87,35 +133,40 @@ func (m Model) handlePaletteSelect(msg palette.PaletteSelectMsg) (Model, tea.Cmd
 		if m.hasActiveStream() {
 			return m, nil
 		}
-		isExplain := handler.Name() == "/explain"
-		if m.sidebar != nil {
-			m.sidebar.Show()
-			// Focus input so user can start typing immediately
-			m.sidebar.FocusInput()
-			m.setTerminalFocused(false)
-			slog.Info("sidebar_open", "streaming", true)
-			m.applyLayout()
+		if m.needsWorkspaceTrust() {
+			return m.askWorkspaceTrust(selected)
 		}
-		m.streamPlaceholderActive = false
+		if m.budgetBlocks() {
+			return m.askBudgetOverride(selected)
+		}
+		explain, isExplain := handler.(*commands.ExplainHandler)
+		m.showSidebar("stream")
 		if isExplain && m.sidebar != nil {
-			m.sidebar.AppendUserMessage(m.buildExplainUserMessage(ctx))
-			m.sidebar.RefreshView()
+			m.sidebar.AppendUserMessage(m.buildExplainUserMessage(ctx, explain.CommandFocus(loadUIConfig().Focus)))
+			m.refreshSidebar()
 		}
 		runCtx, streamID := m.beginStreamRun()
 		m.startStreamPlaceholder()
-		return m, startExplainStreamCmd(streamID, runCtx, ctx, streamHandler, result)
+		return m, tea.Batch(startExplainStreamCmd(streamID, runCtx, ctx, streamHandler, result), m.startStreamProgress())
 	}
 
 	// Show result in panel
 	m.resultPanel.Show(result.Title, result.Content)
+	if result.Copy != "" {
+		return m, tea.SetClipboard(result.Copy)
+	}
 
 	return m, nil
 }
 
-func (m Model) handlePaletteCancel() (Model, tea.Cmd) {
-	// Palette cancelled
-	slog.Info("palette_cancel")
+func (m Model) handlePaletteCancel(msg palette.PaletteCancelMsg) (Model, tea.Cmd) {
+	// Palette cancelled: the typed text was meant for the shell after all
+	slog.Info("palette_cancel", "text_len", len(msg.Text))
 	m.inputHandler.SetPaletteMode(false)
+	if msg.Text != "" {
+		m.inputHandler.SendToPTY([]byte(msg.Text))
+		m.inputHandler.SetLineBuffer(msg.Text)
+	}
 	return m, nil
 }
 
@@ -132,10 +183,23 @@ func (m Model) handleShowHistoryPicker(msg input.ShowHistoryPickerMsg) (Model, t
 	if m.session != nil {
 		sessionHistory = m.session.GetHistory()
 	}
-	commands := capture.MergeHistory(bashHistory, sessionHistory)
+	merged := sessionHistory
+	if m.syncedHistory != nil {
+		// This session's commands go last, so they are listed first.
+		merged = append(m.syncedHistory(), sessionHistory...)
+	}
+	entries := capture.MergeHistoryEntries(bashHistory, merged, m.historyFilter)
+	commands := make([]string, 0, len(entries))
+	usage := make(map[string]historypicker.Usage, len(entries))
+	for _, e := range entries {
+		commands = append(commands, e.Command)
+		usage[e.Command] = historypicker.Usage{Count: e.Count, LastUsed: e.LastUsed}
+	}
 
 	if m.historyPicker != nil {
 		m.historyPicker.SetSize(m.width, m.height)
+		m.historyPicker.SetPreviews(m.historyPreviews(sessionHistory))
+		m.historyPicker.SetUsage(usage)
 		m.historyPicker.Show(msg.InitialFilter, commands)
 	}
 	m.inputHandler.SetHistoryPickerMode(true)
@@ -151,6 +215,19 @@ func (m Model) handleHistoryPickerSelect(msg historypicker.HistoryPickerSelectMs
 	return m, nil
 }
 
+func (m Model) handleHistoryPickerComplete(msg historypicker.HistoryPickerCompleteMsg) (Model, tea.Cmd) {
+	// Tab in the edit stage: put the command on the prompt and round-trip a
+	// Tab through the PTY so the shell's own completion finishes the line.
+	slog.Info("history_picker_complete", "command", msg.Command)
+	if m.inputHandler == nil {
+		return m, nil
+	}
+	m.inputHandler.SetHistoryPickerMode(false)
+	m.replacePromptCommand(msg.Command)
+	m.inputHandler.SendToPTY([]byte{9}) // ASCII TAB
+	return m, nil
+}
+
 func (m Model) handleHistoryPickerCancel() (Model, tea.Cmd) {
 	// History picker cancelled
 	slog.Info("history_picker_cancel")
@@ -163,33 +240,56 @@ func (m Model) handleSidebarCommandExecute(msg sidebar.CommandExecuteMsg) (Model
 	if !ok {
 		return m, nil
 	}
+	if m.readOnly {
+		return m, m.flashStatus(readOnlyNotice)
+	}
+	if dirMismatch(msg.Dir, m.currentDir) {
+		return m.confirmCommandDir(cmdText, msg.Dir)
+	}
 	m.replacePromptCommand(cmdText)
 	m.setTerminalFocused(true)
-	return m, nil
+	return m, m.commandAppliedHook(cmdText)
 }
 
 func (m *Model) replacePromptCommand(cmd string) {
-	if m.inputHandler == nil {
+	if m.inputHandler == nil || m.readOnly {
 		return
 	}
 	m.inputHandler.SendToPTY([]byte{21}) // Ctrl+U clears the line
 	m.inputHandler.SendToPTY([]byte(cmd))
 	m.inputHandler.SetLineBuffer(cmd)
+	m.appliedCommand = cmd
 }
 
 func (m Model) handleCommandSubmitted(msg input.CommandSubmittedMsg) (Model, tea.Cmd) {
 	if strings.TrimSpace(msg.Command) == "" {
 		return m, nil
 	}
+	m.resetTriggerRuns(msg.Command)
+	if m.submitREPLStatement(msg.Command) {
+		return m, nil
+	}
+	m.trackSubmittedCommand(msg.Command)
+	m.appendAppliedCommandToHistory(msg.Command)
+	m.lastSelection = ""
+	m.clearInstallHint()
+	m.clearTypoHint()
+	m.dismissRecap()
 
-	if m.session == nil {
+	if m.session == nil || m.promptZones.seen || m.privacy {
+		// With prompt marks the command is recorded as the shell echoes it.
 		return m, nil
 	}
-	m.session.AddCommand(capture.CommandRecord{
+	m.snapshotLastCommandOutput()
+	record := capture.CommandRecord{
 		Command:    msg.Command,
 		StartTime:  time.Now(),
 		EndTime:    time.Now(),
 		WorkingDir: m.currentDir,
-	})
+	}
+	if m.buffer != nil {
+		record.BufferStart = m.buffer.Total()
+	}
+	m.session.AddCommand(record)
 	return m, nil
 }
@@ -9,6 +9,7 @@ import (
 	"wtf_cli/pkg/ui/input"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // Key dispatch priority:
@@ -17,10 +18,10 @@ import (
 // 3. Exit confirmation cancellation
 // 4. Tool approval modal
 // 5. Pickers / settings / palette / history overlays
-// 6. Result panel
+// 6. Result panel, then Esc cancelling a stream or background operation
 // 7. Focus switch
 // 8. Sidebar input
-// 9. Terminal scroll keys
+// 9. Terminal scroll keys and typed command confirmation
 // 10. PTY input handler
 
 func (m Model) handlePaste(msg tea.PasteMsg) (Model, tea.Cmd) {
@@ -29,40 +30,17 @@ func (m Model) handlePaste(msg tea.PasteMsg) (Model, tea.Cmd) {
 	}
 
 	if m.fullScreenMode {
-		if m.inputHandler != nil {
-			m.inputHandler.HandlePaste(msg.Content)
-		}
 		tracePasteRoute("pty_fullscreen", len(msg.Content))
-		return m, nil
+		return m.writePaste(sanitizePaste(msg.Content, m.pasteTerminal))
 	}
 
-	if m.inputHandler != nil {
-		secretMode := m.inSecretMode()
-		m.inputHandler.SetSecretMode(secretMode)
-		if secretMode {
-			m.inputHandler.HandlePaste(msg.Content)
-			return m, nil
-		}
+	if m.syncSecretMode() {
+		return m.writePaste(sanitizePaste(msg.Content, m.pasteTerminal))
 	}
 
 	if m.exitPending {
 		m.exitPending = false
-		m.statusBar.SetMessage("")
-	}
-
-	if m.optionPicker != nil && m.optionPicker.IsVisible() {
-		tracePasteRoute("option_picker", len(msg.Content))
-		return m, applyPasteToOverlay(msg.Content, m.optionPicker.Update)
-	}
-
-	if m.modelPicker != nil && m.modelPicker.IsVisible() {
-		tracePasteRoute("model_picker", len(msg.Content))
-		return m, applyPasteToOverlay(msg.Content, m.modelPicker.Update)
-	}
-
-	if m.settingsPanel.IsVisible() {
-		tracePasteRoute("settings_panel", len(msg.Content))
-		return m, applyPasteToOverlay(msg.Content, m.settingsPanel.Update)
+		m.clearStatus(statusSourceExit)
 	}
 
 	if m.toolApproval != nil && m.toolApproval.IsVisible() {
@@ -75,34 +53,36 @@ func (m Model) handlePaste(msg tea.PasteMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	if m.resultPanel.IsVisible() {
-		tracePasteRoute("result_panel_ignored", len(msg.Content))
-		return m, nil
-	}
-
-	if m.palette.IsVisible() {
-		tracePasteRoute("palette", len(msg.Content))
-		return m, applyPasteToOverlay(msg.Content, m.palette.Update)
-	}
-
-	if m.historyPicker != nil && m.historyPicker.IsVisible() {
-		tracePasteRoute("history_picker", len(msg.Content))
-		return m, applyPasteToOverlay(msg.Content, m.historyPicker.Update)
+	if k, ok := m.topOverlay(); ok {
+		switch k {
+		case overlayPromptPreview:
+			tracePasteRoute(k.String(), len(msg.Content))
+			m.promptPreview.HandlePaste(msg.Content)
+			return m, nil
+		case overlayResult:
+			tracePasteRoute("result_panel_ignored", len(msg.Content))
+			return m, nil
+		}
+		tracePasteRoute(k.String(), len(msg.Content))
+		return m, applyPasteToOverlay(msg.Content, m.overlayPanel(k).Update)
 	}
 
 	// Route paste to sidebar input when focused.
 	if m.sidebar != nil && m.sidebar.IsVisible() {
 		if m.sidebar.IsFocusedOnInput() {
-			m.sidebar.HandlePaste(msg.Content)
+			if content := sanitizePaste(msg.Content, m.pasteChat); content != "" {
+				m.sidebar.HandlePaste(content)
+			}
 			return m, nil
 		}
 	}
 
-	if m.inputHandler != nil {
-		m.inputHandler.HandlePaste(msg.Content)
-	}
 	tracePasteRoute("pty", len(msg.Content))
-	return m, nil
+	content := sanitizePaste(msg.Content, m.pasteTerminal)
+	if content == "" {
+		return m, nil
+	}
+	return m.pasteToPTY(content)
 }
 
 func tracePasteRoute(target string, n int) {
@@ -113,7 +93,24 @@ func tracePasteRoute(target string, n int) {
 }
 
 func (m Model) inSecretMode() bool {
-	return m.ptyFile != nil && m.secretDetector != nil && m.secretDetector(m.ptyFile)
+	return m.ptyFile != nil && m.secretDetector != nil && m.secretDetector(m.ptyFile, ansi.Strip(m.promptLine()))
+}
+
+// syncSecretMode tells the input handler whether a secret is being typed and
+// reports it. It runs on every key, paste and output flush, so a prompt is
+// noticed once it is printed rather than at the next key; entering secret
+// mode drops the line buffer, which may hold keys typed ahead of the prompt.
+func (m *Model) syncSecretMode() bool {
+	if m.inputHandler == nil {
+		return false
+	}
+	secret := m.inSecretMode()
+	if secret && !m.inputHandler.IsSecretMode() {
+		m.inputHandler.ClearLineBuffer()
+		slog.Debug("secret_input_start")
+	}
+	m.inputHandler.SetSecretMode(secret)
+	return secret
 }
 
 func (m Model) hasStreamPromptOverlay() bool {
@@ -137,9 +134,7 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	}
 
 	if m.inputHandler != nil {
-		secretMode := m.inSecretMode()
-		m.inputHandler.SetSecretMode(secretMode)
-		if secretMode {
+		if m.syncSecretMode() {
 			handled, cmd := m.inputHandler.HandleKey(msg)
 			if handled {
 				m.clearTextSelections()
@@ -151,7 +146,7 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 
 	if m.exitPending && msg.String() != "ctrl+d" {
 		m.exitPending = false
-		m.statusBar.SetMessage("")
+		m.clearStatus(statusSourceExit)
 	}
 
 	if msg.String() == "esc" && m.hasActiveStream() && m.hasStreamPromptOverlay() {
@@ -173,41 +168,19 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 		return m, cmd
 	}
 
-	if m.optionPicker != nil && m.optionPicker.IsVisible() {
-		cmd := m.optionPicker.Update(msg)
-		return m, cmd
-	}
-
-	if m.modelPicker != nil && m.modelPicker.IsVisible() {
-		cmd := m.modelPicker.Update(msg)
-		return m, cmd
-	}
-
-	// Priority 5: Overlays (settings, palette, history picker)
-	// These should take precedence even if sidebar is visible
-	if m.settingsPanel != nil && m.settingsPanel.IsVisible() {
-		cmd := m.settingsPanel.Update(msg)
+	// Priority 5: Overlays. The most recently opened one owns the keyboard,
+	// even if the sidebar is visible.
+	if k, ok := m.topOverlay(); ok {
+		cmd := m.overlayPanel(k).Update(msg)
 		return m, cmd
 	}
 
-	if m.palette != nil && m.palette.IsVisible() {
-		cmd := m.palette.Update(msg)
-		return m, cmd
-	}
-
-	if m.historyPicker != nil && m.historyPicker.IsVisible() {
-		cmd := m.historyPicker.Update(msg)
-		return m, cmd
+	if msg.String() == "esc" && m.hasActiveStream() && m.streamInView() {
+		return m.cancelActiveStream()
 	}
 
-	// Priority 6: Result panel
-	if m.resultPanel.IsVisible() {
-		cmd := m.resultPanel.Update(msg)
-		return m, cmd
-	}
-
-	if msg.String() == "esc" && m.hasActiveStream() {
-		return m.cancelActiveStream()
+	if msg.String() == "esc" && m.progress.Active() {
+		return m.cancelProgress()
 	}
 
 	// Intercept Shift+Tab before sidebar/PTY routing so focus switching works
@@ -248,8 +221,47 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	// Handled here (not in InputHandler) so sidebar focus is respected automatically.
 	// Alt+Up/Down are used instead of Shift+Up/Down because Konsole and most terminal
 	// emulators intercept the Shift variants for their own scrollback.
+	if m.terminalFocused && m.shellExited {
+		return m.openSessionEnd()
+	}
+
 	if m.terminalFocused && !m.fullScreenMode {
+		if m.typedConfirm != nil {
+			return m.answerTypedConfirm(msg)
+		}
+		if m.panePrefix {
+			return m.handlePaneKey(msg)
+		}
+		if m.typoHint != nil {
+			var accepted bool
+			if m, accepted = m.handleTypoHintKey(msg); accepted {
+				return m, nil
+			}
+		}
 		switch msg.String() {
+		case "ctrl+z":
+			if m.canSuspend() {
+				return m.suspend()
+			}
+		case paneKey:
+			return m.armPaneKey()
+		case installHintKey:
+			if m.installHint != nil {
+				return m.acceptInstallHint()
+			}
+		case watchExplainKey:
+			if m.watchChange != nil {
+				return m.acceptWatchExplain()
+			}
+		case pinSelectionKey:
+			if m.lastSelection != "" {
+				return m.pinLastSelection()
+			}
+		case "enter":
+			var held bool
+			if m, held = m.confirmTypedCommand(); held {
+				return m, nil
+			}
 		case "alt+up":
 			m.viewport.ScrollUp()
 			if !m.viewport.IsAtBottom() {
@@ -279,17 +291,25 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 				m.setScrollMode(false)
 				return m, nil
 			}
+			if m.recap.banner != "" {
+				m.dismissRecap()
+				return m, nil
+			}
 		}
 	}
 
 	handled, cmd := m.inputHandler.HandleKey(msg)
 	if handled {
 		m.clearTextSelections()
+		m.trackAppliedCommand(msg)
 		// If the user types anything that reaches the PTY or triggers a UI
 		// action, exit scroll mode so they see the output of what they typed.
 		if m.scrollMode {
 			m.setScrollMode(false)
 		}
+		if m.terminalFocused {
+			return m, tea.Batch(cmd, m.checkPromptSyntax(msg))
+		}
 		return m, cmd
 	}
 
@@ -19,6 +19,9 @@ type resizeApplyMsg struct {
 func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 	m.width = msg.Width
 	m.height = msg.Height
+	if !m.ready {
+		logStartupReady(time.Since(processStart))
+	}
 	m.ready = true
 	slog.Debug("window_resize", "width", m.width, "height", m.height)
 
@@ -36,6 +39,20 @@ func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 	})
 }
 
+// logStartupReady records how long it took from process start until the
+// first frame could be laid out.
+func logStartupReady(elapsed time.Duration) {
+	attrs := []any{
+		"elapsed_ms", elapsed.Milliseconds(),
+		"target_ms", startupTarget.Milliseconds(),
+	}
+	if elapsed > startupTarget {
+		slog.Warn("startup_ready_slow", attrs...)
+		return
+	}
+	slog.Info("startup_ready", attrs...)
+}
+
 func (m Model) handleResizeApply(msg resizeApplyMsg) (Model, tea.Cmd) {
 	// Only apply PTY resize if this is the most recent resize event
 	if msg.id != m.resizeDebounceID {
@@ -48,12 +65,12 @@ func (m Model) handleResizeApply(msg resizeApplyMsg) (Model, tea.Cmd) {
 			terminal.ResizePTY(m.ptyFile, contentWidth, contentHeight)
 		} else {
 			viewportHeight := m.height - 1
-			viewportWidth := m.width
-			if m.sidebar != nil && m.sidebar.IsVisible() {
-				left, _ := splitSidebarWidths(m.width)
-				viewportWidth = left
+			viewportWidth := m.columns(m.width).TerminalWidth
+			if m.split != nil {
+				viewportWidth, viewportHeight = m.resizePanes(viewportWidth, viewportHeight)
 			}
 			m.resizePTYViewport(viewportWidth, viewportHeight)
+			m.resizeSplitPTY()
 			// Track resize time to suppress prompt reprint output
 			// Skip suppression on initial resize (first time we get correct size)
 			if m.initialResize {
@@ -93,16 +110,23 @@ func (m *Model) applyLayout() {
 	// Keep shell wrapping in sync with the visible terminal pane when sidebar
 	// visibility changes.
 	m.resizePTYViewport(viewportWidth, viewportHeight)
+	m.resizeSplitPTY()
 }
 
 func (m *Model) resizeComponents(width, height int) (viewportWidth, viewportHeight int) {
 	viewportHeight = height - 1
-	viewportWidth = width
+	cols := m.columns(width)
+	m.noteLayoutMode(cols, width)
+	viewportWidth = cols.TerminalWidth
 
-	if m.sidebar != nil && m.sidebar.IsVisible() {
-		left, right := splitSidebarWidths(width)
-		viewportWidth = left
-		m.sidebar.SetSize(right, viewportHeight)
+	if cols.SidebarWidth > 0 {
+		m.sidebar.SetSize(cols.SidebarWidth, viewportHeight)
+	}
+	if m.contextPanel != nil {
+		m.contextPanel.SetSize(cols.ContextWidth, viewportHeight)
+	}
+	if m.split != nil {
+		viewportWidth, viewportHeight = m.resizePanes(viewportWidth, viewportHeight)
 	}
 
 	m.viewport.SetSize(viewportWidth, viewportHeight)
@@ -112,63 +136,32 @@ func (m *Model) resizeComponents(width, height int) (viewportWidth, viewportHeig
 		resultHeight--
 	}
 	m.resultPanel.SetSize(width, resultHeight)
-	m.settingsPanel.SetSize(width, height)
+	if m.settingsPanel != nil {
+		m.settingsPanel.SetSize(width, height)
+	}
 	if m.toolApproval != nil {
 		m.toolApproval.SetSize(width, height)
 	}
 	if m.continuePrompt != nil {
 		m.continuePrompt.SetSize(width, height)
 	}
+	if m.promptPreview != nil {
+		m.promptPreview.SetSize(width, height)
+	}
 	if m.modelPicker != nil {
 		m.modelPicker.SetSize(width, height)
 	}
 	if m.optionPicker != nil {
 		m.optionPicker.SetSize(width, height)
 	}
+	if m.envPicker != nil {
+		m.envPicker.SetSize(width, height)
+	}
 	if m.historyPicker != nil {
 		m.historyPicker.SetSize(width, height)
 	}
-	return viewportWidth, viewportHeight
-}
-
-func splitSidebarWidths(total int) (left int, right int) {
-	const minPaneWidth = 20
-
-	if total <= 0 {
-		return 0, 0
-	}
-
-	if total < minPaneWidth*2 {
-		left = int(float64(total) * 0.6)
-		if left < 1 {
-			left = 1
-		}
-		right = total - left
-		if right < 1 {
-			right = 1
-			left = total - right
-		}
-		return left, right
-	}
-
-	left = int(float64(total) * 0.6)
-	right = total - left
-
-	if left < minPaneWidth {
-		left = minPaneWidth
-		right = total - left
-	}
-	if right < minPaneWidth {
-		right = minPaneWidth
-		left = total - right
-	}
-
-	if left < 1 {
-		left = 1
+	if m.scrubber != nil {
+		m.scrubber.SetSize(width, height)
 	}
-	if right < 1 {
-		right = 1
-	}
-
-	return left, right
+	return viewportWidth, viewportHeight
 }
@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"log/slog"
+	"strings"
 	"time"
 
+	"wtf_cli/pkg/ui/components/layout"
+	"wtf_cli/pkg/ui/components/statusbar"
 	"wtf_cli/pkg/ui/render"
 
 	tea "charm.land/bubbletea/v2"
@@ -22,8 +26,7 @@ func (m Model) handleMouseWheel(msg tea.MouseWheelMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 	if m.terminalFocused && m.sidebar != nil && m.sidebar.IsVisible() {
-		left, _ := splitSidebarWidths(m.width)
-		if m2.X >= left && m2.X < m.width {
+		if m.onSidebar(m.columns(m.width), m2.X) && m2.X < m.width {
 			m.setTerminalFocused(false)
 			m.sidebar.FocusInput()
 			m.sidebar.HandleWheel(msg)
@@ -50,58 +53,82 @@ func (m Model) handleMouseClick(msg tea.MouseClickMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 	mouse := msg.Mouse()
-	if mouse.Button != tea.MouseLeft {
+	if mouse.Button != tea.MouseLeft && mouse.Button != tea.MouseMiddle {
 		return m, nil
 	}
 	viewportHeight := render.ViewportHeight(m.height)
 	if viewportHeight <= 0 || mouse.Y < 0 || mouse.Y >= viewportHeight || mouse.X < 0 || mouse.X >= m.width {
 		return m, nil
 	}
-	viewportWidth := m.width
+	cols := m.columns(m.width)
+	if mouse.Button == tea.MouseMiddle {
+		return m.handleMiddleClick(mouse, cols)
+	}
+	viewportWidth := cols.TerminalWidth
 	if m.sidebar != nil && m.sidebar.IsVisible() {
-		left, _ := splitSidebarWidths(m.width)
-		viewportWidth = left
-		if mouse.X >= viewportWidth {
+		if m.onSidebar(cols, mouse.X) {
 			m.focusSidebarInputFromMouse()
-			if row, col, ok := m.sidebar.SelectionPoint(mouse.X, mouse.Y, viewportWidth); ok {
+			if row, col, ok := m.sidebar.SelectionPoint(mouse.X, mouse.Y, cols.SidebarX); ok {
 				m.viewport.ClearSelection()
 				m.sidebar.StartSelection(row, col)
 			}
 			return m, nil
 		}
+		if onContextPanel(cols, mouse.X) {
+			return m, nil
+		}
 		m.focusTerminalFromMouse()
 	}
-	if mouse.X >= 0 && mouse.X < viewportWidth {
+	if m.inUnfocusedPane(mouse.X, viewportWidth) {
+		return m, m.focusOtherPane()
+	}
+	x, y, paneWidth := m.focusedPaneOffset(viewportWidth)
+	if mouse.X >= x && mouse.X < x+paneWidth && mouse.Y >= y {
 		if m.sidebar != nil {
 			m.sidebar.ClearSelection()
 		}
-		m.viewport.StartSelection(mouse.Y, mouse.X)
+		m.viewport.StartSelection(mouse.Y-y, mouse.X-x)
 	}
 	return m, nil
 }
 
+// handleMiddleClick focuses the chat input or the terminal under the mouse
+// and pastes the primary selection there.
+func (m Model) handleMiddleClick(mouse tea.Mouse, cols layout.Columns) (Model, tea.Cmd) {
+	if m.sidebar != nil && m.sidebar.IsVisible() {
+		switch {
+		case m.onSidebar(cols, mouse.X):
+			m.focusSidebarInputFromMouse()
+		case onContextPanel(cols, mouse.X):
+			return m, nil
+		default:
+			m.focusTerminalFromMouse()
+		}
+	}
+	if m.terminalFocused && m.inUnfocusedPane(mouse.X, cols.TerminalWidth) {
+		return m, tea.Batch(m.focusOtherPane(), m.pastePrimarySelection())
+	}
+	return m, m.pastePrimarySelection()
+}
+
 func (m Model) handleMouseMotion(msg tea.MouseMotionMsg) (Model, tea.Cmd) {
 	if m.hasBlockingOverlay() {
 		return m, nil
 	}
 	mouse := msg.Mouse()
-	viewportWidth := m.width
+	cols := m.columns(m.width)
+	viewportWidth := cols.TerminalWidth
 	if m.sidebar != nil && m.sidebar.IsVisible() {
-		left, _ := splitSidebarWidths(m.width)
-		viewportWidth = left
 		if m.sidebar.HasActiveSelection() {
-			if row, col, ok := m.sidebar.SelectionPoint(mouse.X, mouse.Y, viewportWidth); ok {
+			if row, col, ok := m.sidebar.SelectionPoint(mouse.X, mouse.Y, cols.SidebarX); ok {
 				m.sidebar.UpdateSelection(row, col)
 			}
 			return m, nil
 		}
 	}
 	if m.viewport.HasActiveSelection() {
-		if mouse.X < viewportWidth {
-			m.viewport.UpdateSelection(mouse.Y, mouse.X)
-		} else {
-			m.viewport.UpdateSelection(mouse.Y, viewportWidth)
-		}
+		x, y, paneWidth := m.focusedPaneOffset(viewportWidth)
+		m.viewport.UpdateSelection(mouse.Y-y, min(max(mouse.X-x, 0), paneWidth))
 	}
 	return m, nil
 }
@@ -111,24 +138,35 @@ func (m Model) handleMouseRelease(msg tea.MouseReleaseMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 	mouse := msg.Mouse()
-	viewportWidth := m.width
+	cols := m.columns(m.width)
+	viewportWidth := cols.TerminalWidth
 	if m.sidebar != nil && m.sidebar.IsVisible() {
-		left, _ := splitSidebarWidths(m.width)
-		viewportWidth = left
 		if m.sidebar.HasActiveSelection() {
-			if row, col, ok := m.sidebar.SelectionPoint(mouse.X, mouse.Y, viewportWidth); ok {
+			if row, col, ok := m.sidebar.SelectionPoint(mouse.X, mouse.Y, cols.SidebarX); ok {
 				m.sidebar.UpdateSelection(row, col)
 			}
 			return m, m.copySelectedText(m.sidebar.FinishSelection())
 		}
 	}
 	if m.viewport.HasActiveSelection() {
-		if mouse.X < viewportWidth {
-			m.viewport.UpdateSelection(mouse.Y, mouse.X)
-		} else {
-			m.viewport.UpdateSelection(mouse.Y, viewportWidth)
+		x, y, paneWidth := m.focusedPaneOffset(viewportWidth)
+		m.viewport.UpdateSelection(mouse.Y-y, min(max(mouse.X-x, 0), paneWidth))
+		text := m.viewport.FinishSelection()
+		message := selectedOutputCopiedMessage
+		if text == "" {
+			// A click without a drag on a marked prompt line takes the
+			// whole command block.
+			if block, ok := m.viewport.CommandBlockAt(mouse.Y - y); ok {
+				text, message = block, commandBlockCopiedMessage
+				slog.Info("command_block_copy", "lines", strings.Count(block, "\n")+1)
+			}
 		}
-		return m, m.copySelectedText(m.viewport.FinishSelection())
+		cmd := m.copySelectedText(text)
+		if text != "" {
+			m.lastSelection = text
+			m.postStatus(statusbar.Message{Source: statusSourceSelection, Text: message, Priority: statusbar.PriorityNormal}, 0)
+		}
+		return m, cmd
 	}
 	return m, nil
 }
@@ -137,14 +175,12 @@ func (m *Model) copySelectedText(text string) tea.Cmd {
 	if text == "" {
 		return nil
 	}
-	if m.statusBar != nil {
-		m.statusBar.SetMessage(selectedTextCopiedMessage)
-	}
+	// The primary selection too, so a middle click in another window
+	// pastes it, as selecting text there would.
 	return tea.Batch(
 		tea.SetClipboard(text),
-		tea.Tick(2*time.Second, func(time.Time) tea.Msg {
-			return clearStatusMsgMsg{}
-		}),
+		tea.SetPrimaryClipboard(text),
+		m.postStatus(statusbar.Message{Source: statusSourceSelection, Text: selectedTextCopiedMessage, Priority: statusbar.PriorityNormal}, 2*time.Second),
 	)
 }
 
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"os/exec"
 	"strings"
 	"time"
 
 	"wtf_cli/pkg/ai"
 	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/hooks"
 	"wtf_cli/pkg/logging"
+	"wtf_cli/pkg/notify"
 	"wtf_cli/pkg/ui/components/picker"
 	"wtf_cli/pkg/ui/components/settings"
 
@@ -30,7 +34,7 @@ func (m Model) handleSettingsClose() (Model, tea.Cmd) {
 
 func (m Model) handleStartCopilotAuth() (Model, tea.Cmd) {
 	slog.Info("copilot_auth_status_request")
-	return m, fetchCopilotAuthStatusCmd(true)
+	return m, m.runAsync(copilotAuthLabel, fetchCopilotAuthStatus(true))
 }
 
 func (m Model) handleCopilotAuthStatus(msg copilotAuthStatusMsg) (Model, tea.Cmd) {
@@ -52,59 +56,48 @@ func (m Model) handleSettingsSave(msg settings.SettingsSaveMsg) (Model, tea.Cmd)
 		slog.Info("settings_save",
 			"provider", msg.Config.LLMProvider,
 			"model", getModelForProvider(msg.Config),
+			"response_language", msg.Config.ResponseLanguage,
 			"log_level", msg.Config.LogLevel,
 			"log_format", msg.Config.LogFormat,
 			"log_file", msg.Config.LogFile,
 		)
 		logging.SetLevel(msg.Config.LogLevel)
+		m.notifier = notify.New(msg.Config.Notifications)
+		m.hooks = hooks.New(msg.Config.Hooks)
 	}
+	// A provider saved in settings replaces the one picked in /provider,
+	// and its model the one picked with /model.
+	m.providerOverride = ""
+	m.modelOverride = ""
 	provider, model := getProviderAndModel(msg.Config)
-	m.sidebar.SetActiveLLM(provider, model)
-	return m, nil
+	m.setActiveLLM(provider, model)
+	m.setSamplingDefaults(getSamplingDefaults(msg.Config))
+	m.setPaletteAliases(msg.Config.Aliases)
+	m.setPolicy(msg.Config.Policy)
+	m.pasteConfirmBytes = msg.Config.Paste.ConfirmAboveBytes()
+	m.pasteTerminal = msg.Config.Paste.Terminal
+	m.pasteChat = msg.Config.Paste.Chat
+	m.historyFilter = historyFilterFor(msg.Config.History)
+	m.pickerPageSize = msg.Config.Pickers.PageSize
+	m.layoutPresets = layoutPresetsFor(msg.Config.Layout)
+	m.confirmCommands = msg.Config.ConfirmCommands
+	m.syntax = newSyntaxChecker(msg.Config.SyntaxCheck, os.Getenv("SHELL"), exec.LookPath)
+	m.workspace = newWorkspaceIndexer(msg.Config.WorkspaceIndex)
+	m.setRecapConfig(msg.Config.Recap)
+	m.clearStatus(statusSourceSyntax)
+	m.applyLayout()
+	m.setBudgets(msg.Config)
+	m.sidebarPrewarmURL = sidebarPrewarmURL(msg.Config)
+	m.sidebarPrewarmTransport = ai.ActiveTransport(msg.Config)
+	return m, tea.Batch(warmLocalModelCmd(msg.Config), prewarmCmd(msg.Config), m.loadShellHistory())
 }
 
 func (m Model) handleOpenModelPicker(msg picker.OpenModelPickerMsg) (Model, tea.Cmd) {
-	slog.Info("model_picker_open", "current", msg.Current, "field_key", msg.FieldKey, "cached_models", len(msg.Options))
-	slog.Debug("model_picker_open_details",
-		"field_key", msg.FieldKey,
-		"api_url", msg.APIURL,
-		"has_api_key", msg.APIKey != "",
-	)
-	if m.modelPicker != nil {
-		m.modelPicker.SetSize(m.width, m.height)
-		m.modelPicker.Show(msg.Options, msg.Current, msg.FieldKey)
-	}
-	// Fetch dynamic model list based on provider
-	var cmd tea.Cmd
-	switch msg.FieldKey {
-	case "model":
-		if msg.APIURL != "" {
-			cmd = refreshModelCacheCmd(msg.APIURL)
-		} else {
-			slog.Debug("model_picker_no_api_url")
-		}
-	case "openai_model":
-		if msg.APIKey != "" {
-			cmd = fetchOpenAIModelsCmd(msg.APIKey)
-		} else {
-			slog.Debug("openai_models_fetch_skipped", "reason", "missing_api_key")
-		}
-	case "copilot_model":
-		cmd = fetchCopilotModelsCmd()
-	case "anthropic_model":
-		if msg.APIKey != "" {
-			cmd = fetchAnthropicModelsCmd(msg.APIKey)
-		} else {
-			slog.Debug("anthropic_models_fetch_skipped", "reason", "missing_api_key")
-		}
-	case "google_model":
-		if msg.APIKey != "" {
-			cmd = fetchGoogleModelsCmd(msg.APIKey)
-		} else {
-			slog.Debug("google_models_fetch_skipped", "reason", "missing_api_key")
-		}
-	}
-	return m, cmd
+	slog.Info("model_picker_open", "current", msg.Current, "field_key", msg.FieldKey, "known_models", len(msg.Options))
+	modelPicker := m.ensureModelPicker()
+	modelPicker.SetSize(m.width, m.height)
+	modelPicker.Show(msg.Options, msg.Current, msg.FieldKey)
+	return m, m.runAsync(fetchModelsLabel, listModels(msg.FieldKey, msg.Source))
 }
 
 func (m Model) handleModelPickerSelect(msg picker.ModelPickerSelectMsg) (Model, tea.Cmd) {
@@ -112,32 +105,20 @@ func (m Model) handleModelPickerSelect(msg picker.ModelPickerSelectMsg) (Model,
 	if m.modelPicker != nil && m.modelPicker.IsVisible() {
 		m.modelPicker.Hide()
 	}
+	if msg.FieldKey == rerunModelFieldKey {
+		return m.rerunMessage(msg.ModelID)
+	}
 	if m.settingsPanel != nil {
-		switch msg.FieldKey {
-		case "model":
-			m.settingsPanel.SetModelValue(msg.ModelID)
-		case "openai_model":
-			m.settingsPanel.SetOpenAIModelValue(msg.ModelID)
-		case "copilot_model":
-			m.settingsPanel.SetCopilotModelValue(msg.ModelID)
-		case "anthropic_model":
-			m.settingsPanel.SetAnthropicModelValue(msg.ModelID)
-		case "google_model":
-			m.settingsPanel.SetGoogleModelValue(msg.ModelID)
-		default:
-			// Fallback to OpenRouter model for backwards compatibility
-			m.settingsPanel.SetModelValue(msg.ModelID)
-		}
+		m.settingsPanel.SetModelFieldValue(msg.FieldKey, msg.ModelID)
 	}
 	return m, nil
 }
 
 func (m Model) handleOpenOptionPicker(msg picker.OpenOptionPickerMsg) (Model, tea.Cmd) {
 	slog.Info("option_picker_open", "field", msg.FieldKey, "current", msg.Current)
-	if m.optionPicker != nil {
-		m.optionPicker.SetSize(m.width, m.height)
-		m.optionPicker.Show(msg.Title, msg.FieldKey, msg.Options, msg.Current)
-	}
+	optionPicker := m.ensureOptionPicker()
+	optionPicker.SetSize(m.width, m.height)
+	optionPicker.Show(msg.Title, msg.FieldKey, msg.Options, msg.Current)
 	return m, nil
 }
 
@@ -146,107 +127,129 @@ func (m Model) handleOptionPickerSelect(msg picker.OptionPickerSelectMsg) (Model
 	if m.optionPicker != nil && m.optionPicker.IsVisible() {
 		m.optionPicker.Hide()
 	}
+	switch msg.FieldKey {
+	case messageActionFieldKey:
+		return m.handleMessageAction(msg.Value)
+	case retryProviderFieldKey:
+		return m.retryWithProvider(msg.Value)
+	case languageFieldKey:
+		return m.saveResponseLanguage(msg.Value)
+	case exportScriptFieldKey:
+		return m.exportScript(msg.Value)
+	case promptsFieldKey:
+		return m.promotePromptVariant(msg.Value)
+	case unpinFieldKey:
+		return m.unpin(msg.Value)
+	case tasksFieldKey:
+		return m.runTask(msg.Value)
+	case trustFieldKey:
+		return m.saveWorkspaceTrust(msg.Value)
+	case memoriesFieldKey:
+		return m.forgetMemory(msg.Value)
+	case snippetsFieldKey:
+		return m.useSnippet(msg.Value)
+	case providerFieldKey:
+		return m.switchProvider(msg.Value, false)
+	case bookmarksFieldKey:
+		return m.jumpToBookmark(msg.Value)
+	case peekFieldKey:
+		return m.showRawLine(msg.Value)
+	case triggerFieldKey:
+		return m.removeTrigger(msg.Value)
+	case sessionEndFieldKey:
+		return m.handleSessionEnd(msg.Value)
+	case pasteFieldKey:
+		return m.confirmPaste(msg.Value)
+	case commandDirFieldKey:
+		return m.applyCommandDir(msg.Value)
+	case budgetFieldKey:
+		return m.confirmBudgetOverride(msg.Value)
+	}
 	if m.settingsPanel != nil {
 		switch msg.FieldKey {
 		case "llm_provider":
 			m.settingsPanel.SetProviderValue(msg.Value)
 			if msg.Value == "copilot" {
-				return m, fetchCopilotAuthStatusCmd(false)
+				return m, m.runAsync(copilotAuthLabel, fetchCopilotAuthStatus(false))
 			}
 		case "log_level":
 			m.settingsPanel.SetLogLevelValue(msg.Value)
 		case "log_format":
 			m.settingsPanel.SetLogFormatValue(msg.Value)
+		case "response_language":
+			m.settingsPanel.SetResponseLanguageValue(msg.Value)
 		}
 	}
 	return m, nil
 }
 
-func (m Model) handleModelPickerRefresh(msg picker.ModelPickerRefreshMsg) (Model, tea.Cmd) {
-	if msg.Err != nil {
-		slog.Error("model_picker_refresh_error", "error", msg.Err)
-		return m, nil
-	}
-	if m.modelPicker != nil && m.modelPicker.IsVisible() {
-		m.modelPicker.UpdateOptions(msg.Cache.Models)
+// handleOptionPickerAction routes an action key used in an option picker.
+func (m Model) handleOptionPickerAction(msg picker.OptionPickerActionMsg) (Model, tea.Cmd) {
+	slog.Info("option_picker_action", "field", msg.FieldKey, "action", msg.Action)
+	switch msg.FieldKey {
+	case snippetsFieldKey:
+		return m.handleSnippetAction(msg)
+	case providerFieldKey:
+		return m.handleProviderAction(msg)
 	}
-	if m.settingsPanel != nil {
-		m.settingsPanel.SetModelCache(msg.Cache)
+	return m, nil
+}
+
+// handleOptionPickerCancel drops whatever waited on an option picker that
+// was closed with Esc.
+func (m Model) handleOptionPickerCancel(msg picker.OptionPickerCancelMsg) (Model, tea.Cmd) {
+	slog.Info("option_picker_cancel", "field", msg.FieldKey)
+	if msg.FieldKey == trustFieldKey {
+		m.trustPending = nil
 	}
-	slog.Info("model_picker_refresh_done", "models", len(msg.Cache.Models))
 	return m, nil
 }
 
-func (m Model) handleProviderModelsRefresh(msg providerModelsRefreshMsg) (Model, tea.Cmd) {
+func (m Model) handleModelList(msg modelListMsg) (Model, tea.Cmd) {
 	if msg.Err != nil {
-		slog.Error("provider_models_refresh_error", "field_key", msg.FieldKey, "error", msg.Err)
+		slog.Error("model_list_error", "provider", msg.Provider, "field_key", msg.FieldKey, "error", msg.Err)
+		if ai.ModelListErrorKind(msg.Err) == ai.ModelListRejected {
+			return m, m.flashStatus(fmt.Sprintf("%s rejected the API key; showing the built-in models", msg.Provider))
+		}
 		return m, nil
 	}
 	if m.modelPicker != nil && m.modelPicker.IsVisible() {
 		m.modelPicker.UpdateOptions(msg.Models)
 	}
-	slog.Info("provider_models_refresh_done", "field_key", msg.FieldKey, "models", len(msg.Models))
+	if msg.Provider == ai.ProviderOpenRouter && m.settingsPanel != nil {
+		// Max Tokens checks OpenRouter models against their context length.
+		m.settingsPanel.SetModelCache(ai.ModelCache{UpdatedAt: time.Now().UTC(), Models: msg.Models})
+	}
+	slog.Info("model_list_done", "provider", msg.Provider, "field_key", msg.FieldKey, "models", len(msg.Models))
 	return m, nil
 }
 
-func refreshModelCacheCmd(apiURL string) tea.Cmd {
-	trimmed := strings.TrimSpace(apiURL)
-	if trimmed == "" {
-		return nil
-	}
-
-	return func() tea.Msg {
-		slog.Info("model_picker_refresh_start", "api_url", trimmed)
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-		defer cancel()
-
-		cache, err := ai.RefreshOpenRouterModelCache(ctx, trimmed, ai.DefaultModelCachePath())
-		return picker.ModelPickerRefreshMsg{Cache: cache, Err: err}
-	}
-}
+// Status bar labels of the background operations started from settings and
+// the model pickers.
+const (
+	fetchModelsLabel = "Fetching models"
+	copilotAuthLabel = "Checking Copilot login"
+)
 
-// providerModelsRefreshMsg is sent when dynamic model fetching completes
-type providerModelsRefreshMsg struct {
-	Models   []ai.ModelInfo
+// modelListMsg carries the models a ModelSource listed for the model
+// picker opened for FieldKey.
+type modelListMsg struct {
 	FieldKey string
+	Provider ai.ProviderType
+	Models   []ai.ModelInfo
 	Err      error
 }
 
-func fetchOpenAIModelsCmd(apiKey string) tea.Cmd {
-	return fetchAPIKeyProviderModelsCmd("openai_model", "openai_models_fetch_start", apiKey, ai.FetchOpenAIModels)
-}
-
-func fetchAnthropicModelsCmd(apiKey string) tea.Cmd {
-	return fetchAPIKeyProviderModelsCmd("anthropic_model", "anthropic_models_fetch_start", apiKey, ai.FetchAnthropicModels)
-}
-
-func fetchGoogleModelsCmd(apiKey string) tea.Cmd {
-	return fetchAPIKeyProviderModelsCmd("google_model", "google_models_fetch_start", apiKey, ai.FetchGoogleModels)
-}
-
-func fetchAPIKeyProviderModelsCmd(fieldKey, logEvent, apiKey string, fetch func(context.Context, string) ([]ai.ModelInfo, error)) tea.Cmd {
-	if apiKey == "" {
+// listModels asks source for the provider's current models. Nil when it
+// cannot ask, which leaves the picker with the models already known.
+func listModels(fieldKey string, source ai.ModelSource) asyncFunc {
+	if source == nil || !source.CanList() {
 		return nil
 	}
-
-	return func() tea.Msg {
-		slog.Info(logEvent)
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-		defer cancel()
-
-		models, err := fetch(ctx, apiKey)
-		return providerModelsRefreshMsg{Models: models, FieldKey: fieldKey, Err: err}
-	}
-}
-
-func fetchCopilotModelsCmd() tea.Cmd {
-	return func() tea.Msg {
-		slog.Info("copilot_models_fetch_start")
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-		defer cancel()
-
-		models, err := ai.FetchCopilotModels(ctx)
-		return providerModelsRefreshMsg{Models: models, FieldKey: "copilot_model", Err: err}
+	return func(ctx context.Context) tea.Msg {
+		slog.Info("model_list_start", "provider", source.Provider(), "field_key", fieldKey)
+		models, err := source.List(ctx)
+		return modelListMsg{FieldKey: fieldKey, Provider: source.Provider(), Models: models, Err: err}
 	}
 }
 
@@ -257,12 +260,9 @@ type copilotAuthStatusMsg struct {
 	ShowPrompt bool
 }
 
-// fetchCopilotAuthStatusCmd queries the Copilot CLI auth status using the SDK.
-func fetchCopilotAuthStatusCmd(showPrompt bool) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-		defer cancel()
-
+// fetchCopilotAuthStatus queries the Copilot CLI auth status using the SDK.
+func fetchCopilotAuthStatus(showPrompt bool) asyncFunc {
+	return func(ctx context.Context) tea.Msg {
 		slog.Info("copilot_auth_status_start")
 		status, err := ai.FetchCopilotAuthStatus(ctx)
 		if err != nil {
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"wtf_cli/pkg/ui/components/layout"
 	"wtf_cli/pkg/ui/render"
 
 	tea "charm.land/bubbletea/v2"
@@ -32,6 +33,8 @@ func (m Model) View() tea.View {
 	if !m.fullScreenMode {
 		v.MouseMode = tea.MouseModeCellMotion
 	}
+	// Focus reports tell the recap when the window is in the background.
+	v.ReportFocus = m.recap.idle > 0
 
 	v.SetContent(m.renderCanvas().Render())
 	return v
@@ -55,19 +58,18 @@ func (m Model) Render() (string, bool) {
 
 func (m Model) renderCanvas() *lipgloss.Canvas {
 	const (
-		baseLayerZ        = 0
-		settingsLayerZ    = 1
-		overlayLayerZ     = 2
-		toolApprovalLayer = 3 // approval/continue popups are topmost overlays (modal)
+		baseLayerZ    = 0
+		overlayLayerZ = 1
 	)
 
 	width := m.width
 	height := m.height
 	if width <= 0 {
-		width = m.viewport.Viewport.Width()
+		width, _ = m.viewport.Size()
 	}
 	if height <= 0 {
-		height = m.viewport.Viewport.Height() + 1
+		_, height = m.viewport.Size()
+		height++
 	}
 	if width <= 0 || height <= 0 {
 		return lipgloss.NewCanvas(0, 0)
@@ -77,28 +79,40 @@ func (m Model) renderCanvas() *lipgloss.Canvas {
 	m.statusBar.SetWidth(width)
 	m.statusBar.SetDirectory(m.currentDir)
 	m.statusBar.SetGitBranch(m.gitBranch)
+	m.statusBar.SetJobs(len(m.jobs))
 
 	viewportHeight := render.ViewportHeight(height)
-	viewportWidth := width
-	sidebarWidth := 0
-	if m.sidebar != nil && m.sidebar.IsVisible() {
-		left, right := splitSidebarWidths(width)
-		viewportWidth = left
-		sidebarWidth = right
-	}
+	cols := m.columns(width)
+	viewportWidth := cols.TerminalWidth
+	covered := m.sidebarCovers(cols)
 
-	layers := make([]*lipgloss.Layer, 0, 5)
+	layers := make([]*lipgloss.Layer, 0, 6)
 
-	if viewportWidth > 0 && viewportHeight > 0 {
+	// In the overlay layout the chat has the terminal's screen until focus
+	// goes back to the terminal.
+	if covered {
+		viewportWidth = 0
+	}
+	if viewportWidth > 0 && viewportHeight > 0 && m.split != nil {
+		layers = append(layers, m.paneLayers(viewportWidth, viewportHeight, baseLayerZ)...)
+	} else if viewportWidth > 0 && viewportHeight > 0 {
 		viewportLayer := lipgloss.NewLayer(m.viewport.View()).
 			X(0).Y(0).
 			Z(baseLayerZ)
 		layers = append(layers, viewportLayer)
 	}
 
-	if sidebarWidth > 0 && viewportHeight > 0 && m.sidebar != nil && m.sidebar.IsVisible() {
+	if cols.ContextWidth > 0 && viewportHeight > 0 && m.contextPanel != nil {
+		m.contextPanel.SetItems(m.contextPanelItems())
+		contextLayer := lipgloss.NewLayer(m.contextPanel.View()).
+			X(cols.ContextX).Y(0).
+			Z(baseLayerZ)
+		layers = append(layers, contextLayer)
+	}
+
+	if cols.SidebarWidth > 0 && viewportHeight > 0 && (cols.Mode != layout.ModeOverlay || covered) {
 		sidebarLayer := lipgloss.NewLayer(m.sidebar.View()).
-			X(viewportWidth).Y(0).
+			X(cols.SidebarX).Y(0).
 			Z(baseLayerZ)
 		layers = append(layers, sidebarLayer)
 	}
@@ -108,26 +122,22 @@ func (m Model) renderCanvas() *lipgloss.Canvas {
 		Z(baseLayerZ)
 	layers = append(layers, statusLayer)
 
-	if m.settingsPanel.IsVisible() {
-		layers = addOverlayLayer(layers, m.settingsPanel.View(), width, height, settingsLayerZ)
-	}
-
-	if m.optionPicker != nil && m.optionPicker.IsVisible() {
-		layers = addOverlayLayer(layers, m.optionPicker.View(), width, height, overlayLayerZ)
-	} else if m.modelPicker != nil && m.modelPicker.IsVisible() {
-		layers = addOverlayLayer(layers, m.modelPicker.View(), width, height, overlayLayerZ)
-	} else if m.resultPanel.IsVisible() {
-		layers = addOverlayLayer(layers, m.resultPanel.View(), width, viewportHeight, overlayLayerZ)
-	} else if m.palette.IsVisible() {
-		layers = addOverlayLayer(layers, m.palette.View(), width, height, overlayLayerZ)
-	} else if m.historyPicker != nil && m.historyPicker.IsVisible() {
-		layers = addOverlayLayer(layers, m.historyPicker.View(), width, height, overlayLayerZ)
+	// Overlays stack in the order they were opened, so one opened from
+	// another (the model picker from settings) is drawn above it.
+	open := m.syncOverlays()
+	for i, k := range open {
+		screenH := height
+		if k == overlayResult {
+			screenH = viewportHeight
+		}
+		layers = addOverlayLayer(layers, m.overlayPanel(k).View(), width, screenH, overlayLayerZ+i)
 	}
+	modalLayerZ := overlayLayerZ + len(open) // approval/continue popups are topmost (modal)
 
 	if m.toolApproval != nil && m.toolApproval.IsVisible() {
-		layers = addOverlayLayer(layers, m.toolApproval.View(), width, height, toolApprovalLayer)
+		layers = addOverlayLayer(layers, m.toolApproval.View(), width, height, modalLayerZ)
 	} else if m.continuePrompt != nil && m.continuePrompt.IsVisible() {
-		layers = addOverlayLayer(layers, m.continuePrompt.View(), width, height, toolApprovalLayer)
+		layers = addOverlayLayer(layers, m.continuePrompt.View(), width, height, modalLayerZ)
 	}
 
 	return lipgloss.NewCanvas(width, height).Compose(lipgloss.NewCompositor(layers...))
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"wtf_cli/pkg/config"
+	"wtf_cli/pkg/notify"
+	"wtf_cli/pkg/ui/components/sidebar"
+	"wtf_cli/pkg/watch"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// watchExplainKey asks the AI about the last change seen by /watch. It is
+// only claimed while a change is pending; otherwise it reaches the shell.
+const watchExplainKey = "alt+w"
+
+const (
+	watchBadgeCommandMax  = 24
+	watchExplainDiffLines = 60
+	watchExplainTailLines = 40
+)
+
+// watchState is the command /watch re-runs in a hidden PTY.
+type watchState struct {
+	id       int
+	command  string
+	dir      string
+	interval time.Duration
+	runs     int
+	last     *watch.Result
+	cancel   context.CancelFunc
+}
+
+// watchChange is a change the user has not asked the AI about yet.
+type watchChange struct {
+	command string
+	kind    watch.Change
+	prev    *watch.Result
+	cur     watch.Result
+}
+
+type watchTickMsg struct {
+	id int
+}
+
+type watchResultMsg struct {
+	id     int
+	result watch.Result
+}
+
+// handleWatchCommand starts watching the command in args, which may begin
+// with "-n <seconds>". Without a command it stops the current watch, or
+// watches the last command run at the prompt.
+func (m Model) handleWatchCommand(args string) (Model, tea.Cmd) {
+	interval, command, err := parseWatchArgs(args)
+	if err != nil {
+		return m, m.flashStatus(err.Error())
+	}
+	if command == "" {
+		if m.watching != nil {
+			command := m.watching.command
+			m.stopWatch()
+			return m, m.flashStatus("Stopped watching " + command)
+		}
+		if m.session != nil {
+			if last := m.session.GetLastN(1); len(last) > 0 {
+				command = strings.TrimSpace(last[0].Command)
+			}
+		}
+		if command == "" {
+			return m, m.flashStatus("Usage: /watch [-n seconds] <command>")
+		}
+	}
+	return m.startWatch(command, interval)
+}
+
+// parseWatchArgs splits an optional "-n <seconds>" prefix from the command.
+func parseWatchArgs(args string) (time.Duration, string, error) {
+	args = strings.TrimSpace(args)
+	rest, ok := strings.CutPrefix(args, "-n ")
+	if !ok {
+		return watch.DefaultInterval, args, nil
+	}
+	value, command, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0, "", fmt.Errorf("/watch: invalid interval %q", value)
+	}
+	interval := max(time.Duration(seconds*float64(time.Second)), watch.MinInterval)
+	return interval, strings.TrimSpace(command), nil
+}
+
+func (m Model) startWatch(command string, interval time.Duration) (Model, tea.Cmd) {
+	m.stopWatch()
+	m.watchSeq++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watching = &watchState{
+		id:       m.watchSeq,
+		command:  command,
+		dir:      m.currentDir,
+		interval: interval,
+		cancel:   cancel,
+	}
+	m.updateWatchBadge()
+	slog.Info("watch_start", "command", command, "interval", interval, "dir", m.currentDir)
+	return m, m.runWatch(ctx)
+}
+
+// stopWatch cancels the running iteration and forgets any pending change.
+func (m *Model) stopWatch() {
+	if m.watching == nil {
+		return
+	}
+	slog.Info("watch_stop", "command", m.watching.command, "runs", m.watching.runs)
+	m.watching.cancel()
+	m.watching = nil
+	m.watchChange = nil
+	m.updateWatchBadge()
+}
+
+func (m *Model) runWatch(ctx context.Context) tea.Cmd {
+	w := m.watching
+	run := m.watchRun
+	id, command, dir := w.id, w.command, w.dir
+	return func() tea.Msg {
+		// A run that never exits (a server, a prompt for input) would stop
+		// the watch for good; time it out like a failure instead.
+		ctx, cancel := context.WithTimeout(ctx, watch.RunTimeout)
+		defer cancel()
+		result := run(ctx, command, dir)
+		if errors.Is(result.Err, context.DeadlineExceeded) {
+			result.Err = fmt.Errorf("timed out after %s: %w", watch.RunTimeout, result.Err)
+		}
+		return watchResultMsg{id: id, result: result}
+	}
+}
+
+func (m Model) handleWatchTick(msg watchTickMsg) (Model, tea.Cmd) {
+	if m.watching == nil || m.watching.id != msg.id {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watching.cancel = cancel
+	return m, m.runWatch(ctx)
+}
+
+func (m Model) handleWatchResult(msg watchResultMsg) (Model, tea.Cmd) {
+	w := m.watching
+	if w == nil || w.id != msg.id || errors.Is(msg.result.Err, context.Canceled) {
+		return m, nil
+	}
+
+	cur := msg.result
+	prev := w.last
+	change := watch.Compare(prev, cur)
+	w.runs++
+	w.last = &cur
+	slog.Debug("watch_run", "command", w.command, "exit_code", cur.ExitCode, "change", change.String(), "duration", cur.Duration)
+
+	next := tea.Tick(w.interval, func(time.Time) tea.Msg {
+		return watchTickMsg{id: w.id}
+	})
+	if change == watch.Unchanged || (change == watch.FirstRun && !cur.Failed()) {
+		m.updateWatchBadge()
+		return m, next
+	}
+
+	kind := change
+	if change == watch.FirstRun {
+		kind = watch.StartedFailing
+	}
+	m.watchChange = &watchChange{command: w.command, kind: kind, prev: prev, cur: cur}
+	m.updateWatchBadge()
+	slog.Info("watch_changed", "command", w.command, "change", kind.String(), "exit_code", cur.ExitCode)
+	return m, tea.Batch(next, m.notify(notify.Event{
+		Kind:    config.NotifyEventWatchChanged,
+		Title:   "watch: " + kind.String(),
+		Message: truncateNotificationDetail(w.command),
+	}))
+}
+
+// updateWatchBadge shows the watched command, its last status and the
+// explain key while a change is pending.
+func (m *Model) updateWatchBadge() {
+	if m.statusBar == nil {
+		return
+	}
+	w := m.watching
+	if w == nil {
+		m.statusBar.SetWatchStatus("")
+		return
+	}
+	mark := "…"
+	if w.last != nil {
+		mark = "✓"
+		if w.last.Failed() {
+			mark = "✗"
+		}
+	}
+	status := fmt.Sprintf("watch %s %s", mark, truncateWatchCommand(w.command))
+	if m.watchChange != nil {
+		status += " | Alt+W explain"
+	}
+	m.statusBar.SetWatchStatus(status)
+}
+
+func truncateWatchCommand(command string) string {
+	runes := []rune(strings.Join(strings.Fields(command), " "))
+	if len(runes) <= watchBadgeCommandMax {
+		return string(runes)
+	}
+	return string(runes[:watchBadgeCommandMax-1]) + "…"
+}
+
+// acceptWatchExplain sends the pending change to the chat sidebar.
+func (m Model) acceptWatchExplain() (Model, tea.Cmd) {
+	if m.hasActiveStream() {
+		return m, m.flashStatus("AI is busy; try again when the answer finishes")
+	}
+	change := *m.watchChange
+	m.watchChange = nil
+	m.updateWatchBadge()
+	slog.Info("watch_explain", "command", change.command, "change", change.kind.String())
+
+	s := m.ensureSidebar()
+	s.Show()
+	s.FocusInput()
+	m.setTerminalFocused(false)
+	m.applyLayout()
+	return m.handleChatSubmit(sidebar.ChatSubmitMsg{Content: watchQuestion(change)})
+}
+
+// watchQuestion describes a change for the AI: what happened, the changed
+// lines and the tail of the latest output.
+func watchQuestion(c watchChange) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "I'm re-running `%s` with /watch and it %s", c.command, c.kind.String())
+	if c.prev != nil && c.prev.ExitCode != c.cur.ExitCode {
+		fmt.Fprintf(&sb, " (exit code %d → %d)", c.prev.ExitCode, c.cur.ExitCode)
+	} else {
+		fmt.Fprintf(&sb, " (exit code %d)", c.cur.ExitCode)
+	}
+	sb.WriteString(".")
+	if c.cur.Err != nil {
+		fmt.Fprintf(&sb, " It could not run: %v.", c.cur.Err)
+	}
+	sb.WriteString("\n\n")
+
+	if c.prev != nil {
+		if diff := watch.Diff(c.prev.Output, c.cur.Output, watchExplainDiffLines); diff != "" {
+			sb.WriteString("Lines changed since the previous run:\n```diff\n" + diff + "\n```\n\n")
+		}
+	}
+	tail := c.cur.Output
+	if lines := strings.Split(tail, "\n"); len(lines) > watchExplainTailLines {
+		tail = strings.Join(lines[len(lines)-watchExplainTailLines:], "\n")
+	}
+	if strings.TrimSpace(tail) == "" {
+		tail = "<no output>"
+	}
+	sb.WriteString("Latest output:\n```\n" + tail + "\n```\n\n")
+	sb.WriteString("What changed, and if it is failing, how do I fix it?")
+	return sb.String()
+}
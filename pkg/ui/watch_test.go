@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/ui/components/palette"
+	"wtf_cli/pkg/watch"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newWatchTestModel(t *testing.T, results ...watch.Result) (Model, *[]string) {
+	t.Helper()
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.currentDir = t.TempDir()
+	var ran []string
+	m.watchRun = func(_ context.Context, command, dir string) watch.Result {
+		ran = append(ran, command)
+		r := results[0]
+		if len(results) > 1 {
+			results = results[1:]
+		}
+		return r
+	}
+	return m, &ran
+}
+
+// runWatchCmd executes the pending watch iteration synchronously.
+func runWatchCmd(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	msg, ok := cmd().(watchResultMsg)
+	if !ok {
+		t.Fatalf("expected watchResultMsg, got %T", cmd())
+	}
+	m, _ = m.handleWatchResult(msg)
+	return m
+}
+
+func TestParseWatchArgs(t *testing.T) {
+	tests := []struct {
+		args     string
+		interval time.Duration
+		command  string
+		wantErr  bool
+	}{
+		{"make test", watch.DefaultInterval, "make test", false},
+		{"-n 2 go test ./...", 2 * time.Second, "go test ./...", false},
+		{"-n 0.1 ls", watch.MinInterval, "ls", false},
+		{"-n soon ls", 0, "", true},
+		{"", watch.DefaultInterval, "", false},
+	}
+	for _, tt := range tests {
+		interval, command, err := parseWatchArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseWatchArgs(%q) error = %v", tt.args, err)
+			continue
+		}
+		if interval != tt.interval || command != tt.command {
+			t.Errorf("parseWatchArgs(%q) = %v, %q; want %v, %q", tt.args, interval, command, tt.interval, tt.command)
+		}
+	}
+}
+
+func TestModel_WatchOffersExplanationWhenCommandStartsFailing(t *testing.T) {
+	m, ran := newWatchTestModel(t,
+		watch.Result{Output: "ok 1\nPASS"},
+		watch.Result{Output: "ok 1\nFAIL: TestX", ExitCode: 1},
+	)
+
+	m, cmd := m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/watch", Args: "make test"})
+	if cmd == nil || m.watching == nil {
+		t.Fatal("expected watch to start")
+	}
+	m = runWatchCmd(t, m, cmd)
+	if m.watchChange != nil {
+		t.Fatal("expected a passing first run not to raise a change")
+	}
+	if view := m.statusBar.Render(); !strings.Contains(view, "watch ✓ make test") {
+		t.Fatalf("expected watch badge, got %q", view)
+	}
+
+	m, cmd = m.handleWatchTick(watchTickMsg{id: m.watching.id})
+	m = runWatchCmd(t, m, cmd)
+	if m.watchChange == nil || m.watchChange.kind != watch.StartedFailing {
+		t.Fatalf("expected started-failing change, got %+v", m.watchChange)
+	}
+	if view := m.statusBar.Render(); !strings.Contains(view, "Alt+W explain") {
+		t.Fatalf("expected explain hint in badge, got %q", view)
+	}
+	if len(*ran) != 2 || (*ran)[1] != "make test" {
+		t.Fatalf("unexpected runs: %v", *ran)
+	}
+
+	m, _ = m.acceptWatchExplain()
+	if m.watchChange != nil {
+		t.Fatal("expected pending change to be consumed")
+	}
+	messages := m.sidebar.GetMessages()
+	if len(messages) == 0 {
+		t.Fatal("expected a question in the chat")
+	}
+	question := messages[0].Content
+	for _, want := range []string{"`make test`", "started failing", "exit code 0 → 1", "- PASS", "+ FAIL: TestX"} {
+		if !strings.Contains(question, want) {
+			t.Errorf("expected question to contain %q, got:\n%s", want, question)
+		}
+	}
+}
+
+func TestModel_WatchTimesOutEachRun(t *testing.T) {
+	m, _ := newWatchTestModel(t)
+	var deadline time.Time
+	m.watchRun = func(ctx context.Context, _, _ string) watch.Result {
+		deadline, _ = ctx.Deadline()
+		return watch.Result{ExitCode: -1, Err: context.DeadlineExceeded}
+	}
+
+	m, cmd := m.handlePaletteSelect(palette.PaletteSelectMsg{Command: "/watch", Args: "tail -f log"})
+	if cmd == nil {
+		t.Fatal("expected watch to start")
+	}
+	m = runWatchCmd(t, m, cmd)
+	if deadline.IsZero() || time.Until(deadline) > watch.RunTimeout {
+		t.Fatalf("expected the run to time out within %s, deadline %v", watch.RunTimeout, deadline)
+	}
+	if m.watchChange == nil || !strings.Contains(m.watchChange.cur.Err.Error(), "timed out after") {
+		t.Fatalf("expected the timeout reported as a failure, got %+v", m.watchChange)
+	}
+}
+
+func TestModel_WatchWithoutArgsStopsAndIgnoresStaleResults(t *testing.T) {
+	m, _ := newWatchTestModel(t, watch.Result{Output: "x"})
+
+	m, cmd := m.handleWatchCommand("-n 2 date")
+	if m.watching == nil || m.watching.interval != 2*time.Second {
+		t.Fatalf("expected watch with 2s interval, got %+v", m.watching)
+	}
+	stale := cmd()
+
+	m, _ = m.handleWatchCommand("")
+	if m.watching != nil {
+		t.Fatal("expected /watch without a command to stop watching")
+	}
+	if view := m.statusBar.Render(); strings.Contains(view, "watch …") {
+		t.Fatalf("expected badge to be cleared, got %q", view)
+	}
+
+	m, next := m.handleWatchResult(stale.(watchResultMsg))
+	if next != nil || m.watching != nil {
+		t.Fatal("expected stale result to be ignored")
+	}
+}
+
+func TestModel_WatchWithoutArgsUsesLastCommand(t *testing.T) {
+	m, _ := newWatchTestModel(t, watch.Result{})
+	m.session.AddCommand(capture.CommandRecord{Command: "cargo check"})
+
+	m, _ = m.handleWatchCommand("")
+	if m.watching == nil || m.watching.command != "cargo check" {
+		t.Fatalf("expected to watch the last command, got %+v", m.watching)
+	}
+}
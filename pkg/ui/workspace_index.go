@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"wtf_cli/pkg/ai"
+	"wtf_cli/pkg/config"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// workspaceIndexTimeout bounds one walk of the project.
+const workspaceIndexTimeout = 5 * time.Second
+
+// workspaceIndexer keeps a map of the project the shell is in, rebuilt in
+// the background on every cd.
+type workspaceIndexer struct {
+	enabled bool
+	limits  ai.WorkspaceIndexLimits
+	dir     string // Directory the last index was started for
+	seq     int    // Counts walks, so one for an earlier directory is dropped
+	index   ai.WorkspaceIndex
+}
+
+func newWorkspaceIndexer(cfg config.WorkspaceIndexConfig) workspaceIndexer {
+	return workspaceIndexer{
+		enabled: cfg.Enabled,
+		limits: ai.WorkspaceIndexLimits{
+			MaxFiles:     cfg.FileLimit(),
+			ExcerptBytes: cfg.ExcerptBytes(),
+		},
+	}
+}
+
+type workspaceIndexMsg struct {
+	seq   int
+	index ai.WorkspaceIndex
+}
+
+// refreshWorkspaceIndex starts indexing the project of the current
+// directory when the shell has moved since the last index. The map of
+// another project is dropped at once rather than sent until the walk ends.
+func (m *Model) refreshWorkspaceIndex() tea.Cmd {
+	w := &m.workspace
+	if !w.enabled || m.currentDir == "" || m.currentDir == w.dir {
+		return nil
+	}
+	w.dir = m.currentDir
+	w.seq++
+	root := ai.WorkspaceRoot(m.currentDir)
+	if root != w.index.Root {
+		w.index = ai.WorkspaceIndex{}
+	}
+	if root == "" {
+		return nil
+	}
+	seq, limits := w.seq, w.limits
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), workspaceIndexTimeout)
+		defer cancel()
+		start := time.Now()
+		index := ai.IndexWorkspace(ctx, root, limits)
+		slog.Debug("workspace_index_built",
+			"root", root,
+			"files", index.Total,
+			"listed", len(index.Files),
+			"excerpts", len(index.Excerpts),
+			"truncated", index.Truncated,
+			"elapsed", time.Since(start),
+		)
+		return workspaceIndexMsg{seq: seq, index: index}
+	}
+}
+
+// applyWorkspaceIndex keeps the map of the latest walk.
+func (m *Model) applyWorkspaceIndex(msg workspaceIndexMsg) {
+	if msg.seq == m.workspace.seq {
+		m.workspace.index = msg.index
+	}
+}
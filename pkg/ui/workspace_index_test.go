@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"wtf_cli/pkg/buffer"
+	"wtf_cli/pkg/capture"
+	"wtf_cli/pkg/config"
+)
+
+func TestWorkspaceIndex_RefreshesOnDirectoryChange(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "Makefile"), []byte("test:\n\tgo test ./...\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(nil, buffer.New(100), capture.NewSessionContext(), nil)
+	m.workspace = newWorkspaceIndexer(config.WorkspaceIndexConfig{Enabled: true})
+	m.currentDir = repo
+	cmd := m.refreshWorkspaceIndex()
+	if cmd == nil {
+		t.Fatal("expected an index walk for a new directory")
+	}
+	if again := m.refreshWorkspaceIndex(); again != nil {
+		t.Fatal("expected no walk while the directory is unchanged")
+	}
+	msg, ok := cmd().(workspaceIndexMsg)
+	if !ok {
+		t.Fatalf("expected workspaceIndexMsg, got %T", cmd())
+	}
+
+	stale := msg
+	stale.seq--
+	m.applyWorkspaceIndex(stale)
+	if m.workspace.index.Root != "" {
+		t.Fatal("expected a walk for an earlier directory dropped")
+	}
+	m.applyWorkspaceIndex(msg)
+	if !slices.Equal(m.workspace.index.Files, []string{"Makefile"}) {
+		t.Fatalf("Files = %v, want [Makefile]", m.workspace.index.Files)
+	}
+	if got := m.commandContext().Workspace.Root; got != repo {
+		t.Fatalf("command context workspace root = %q, want %q", got, repo)
+	}
+
+	m.currentDir = t.TempDir()
+	if cmd := m.refreshWorkspaceIndex(); cmd != nil {
+		t.Fatal("expected no walk outside a project")
+	}
+	if m.workspace.index.Root != "" {
+		t.Fatal("expected the map dropped after leaving the project")
+	}
+}
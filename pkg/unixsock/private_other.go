@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package unixsock
+
+import "net"
+
+// listenPrivate binds path; there is no umask to narrow here.
+func listenPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package unixsock
+
+import (
+	"net"
+	"sync"
+	"syscall"
+)
+
+// umaskMu serializes listeners; the umask is process-wide.
+var umaskMu sync.Mutex
+
+// listenPrivate binds path under umask 077, so the socket is never
+// connectable by other users, not even before Listen narrows it to 0600.
+func listenPrivate(path string) (net.Listener, error) {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	old := syscall.Umask(0o077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,38 @@
+// Package unixsock creates the per-user Unix sockets that sessions listen
+// on for observers (--share) and local automation (--control).
+package unixsock
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Listen creates a socket at path that only the current user can connect
+// to. A stale socket left by a crashed session is replaced; a live one, or
+// any other kind of file, is an error and is left alone.
+func Listen(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s already exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already in use by another session", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := listenPrivate(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
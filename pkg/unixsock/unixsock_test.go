@@ -0,0 +1,88 @@
+package unixsock
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func socketPath(t *testing.T) string {
+	t.Helper()
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	dir, err := os.MkdirTemp("", "wtfsock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "s.sock")
+}
+
+func TestListen_CreatesPrivateSocket(t *testing.T) {
+	path := socketPath(t)
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer ln.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected a 0600 socket, got %v", info.Mode())
+	}
+}
+
+func TestListen_RefusesLiveSocket(t *testing.T) {
+	path := socketPath(t)
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer ln.Close()
+	if _, err := Listen(path); err == nil {
+		t.Fatal("Expected error for a socket in use")
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	path := socketPath(t)
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Expected stale socket to be replaced, got %v", err)
+	}
+	ln.Close()
+}
+
+func TestListen_RefusesOtherFiles(t *testing.T) {
+	path := socketPath(t)
+	if err := os.WriteFile(path, []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Fatal("Expected error for a regular file")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "notes" {
+		t.Fatalf("Expected the file left in place, got %q, %v", data, err)
+	}
+
+	dir := filepath.Join(filepath.Dir(path), "d")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(dir); err == nil {
+		t.Fatal("Expected error for a directory")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("Expected the directory left in place, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package watch re-runs a command in a hidden pseudo-terminal and reports
+// when its output or exit status changes between runs.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/creack/pty"
+)
+
+const (
+	// MaxOutputBytes bounds the output kept from one run; only the tail is
+	// kept, which is where failures are reported.
+	MaxOutputBytes = 64 * 1024
+
+	// DefaultInterval is the pause between the end of one run and the start
+	// of the next.
+	DefaultInterval = 5 * time.Second
+
+	// MinInterval keeps a typo like "-n 0" from spinning the CPU.
+	MinInterval = time.Second
+
+	// RunTimeout bounds one run, so a command that never exits does not
+	// stall the watch.
+	RunTimeout = 5 * time.Minute
+
+	ptyCols = 120
+	ptyRows = 40
+)
+
+// Result is the outcome of one run.
+type Result struct {
+	Output   string // Plain text without escape sequences
+	ExitCode int
+	Err      error // Set when the command could not be started or waited on
+	Duration time.Duration
+}
+
+// Failed reports whether the run did not succeed.
+func (r Result) Failed() bool {
+	return r.Err != nil || r.ExitCode != 0
+}
+
+// Run executes command with the user's shell in a fresh PTY, so programs see
+// a terminal and format output the way they do at the prompt.
+func Run(ctx context.Context, command, dir string) Result {
+	start := time.Now()
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Dir = dir
+
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: ptyCols, Rows: ptyRows})
+	if err != nil {
+		return Result{ExitCode: -1, Err: fmt.Errorf("failed to start: %w", err), Duration: time.Since(start)}
+	}
+	// Killing the shell alone would leave its children holding the PTY open;
+	// closing the master hangs up the whole session.
+	stop := context.AfterFunc(ctx, func() {
+		_ = ptmx.Close()
+		_ = cmd.Process.Kill()
+	})
+	defer stop()
+	out := &tailBuffer{max: MaxOutputBytes}
+	// Reading ends with EIO once the child and its descendants close the PTY.
+	_, _ = io.Copy(out, ptmx)
+	_ = ptmx.Close()
+
+	result := Result{Duration: time.Since(start)}
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			result.ExitCode = -1
+			result.Err = err
+		}
+	}
+	if ctx.Err() != nil {
+		result.Err = ctx.Err()
+	}
+	result.Output = Normalize(string(out.data))
+	return result
+}
+
+// Normalize strips escape sequences, carriage returns and trailing blanks so
+// cosmetic differences between runs are not reported as changes.
+func Normalize(output string) string {
+	output = ansi.Strip(output)
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			line = line[j+1:] // Keep what a terminal would show after a redraw
+		}
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	data []byte
+	max  int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.max; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+// Change classifies how a run differs from the one before it.
+type Change int
+
+const (
+	Unchanged Change = iota
+	FirstRun
+	OutputChanged
+	StartedFailing
+	Recovered
+)
+
+func (c Change) String() string {
+	switch c {
+	case FirstRun:
+		return "first run"
+	case OutputChanged:
+		return "output changed"
+	case StartedFailing:
+		return "started failing"
+	case Recovered:
+		return "recovered"
+	}
+	return "unchanged"
+}
+
+// Compare classifies cur against prev, which is nil before the first run.
+// A change of exit status takes precedence over a change of output.
+func Compare(prev *Result, cur Result) Change {
+	switch {
+	case prev == nil:
+		return FirstRun
+	case !prev.Failed() && cur.Failed():
+		return StartedFailing
+	case prev.Failed() && !cur.Failed():
+		return Recovered
+	case prev.Output != cur.Output || prev.ExitCode != cur.ExitCode:
+		return OutputChanged
+	}
+	return Unchanged
+}
+
+// maxDiffInput bounds the lines compared by Diff, keeping its quadratic
+// table small for chatty commands.
+const maxDiffInput = 400
+
+// Diff returns the lines removed from prev ("- ") and added in cur ("+ "),
+// in order, capped at maxLines with a note when more were cut.
+func Diff(prev, cur string, maxLines int) string {
+	a := tailLines(prev, maxDiffInput)
+	b := tailLines(cur, maxDiffInput)
+
+	// lcs[i][j] is the common subsequence length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	if maxLines > 0 && len(out) > maxLines {
+		cut := len(out) - maxLines
+		out = append(out[:maxLines], fmt.Sprintf("... %d more changed lines", cut))
+	}
+	return strings.Join(out, "\n")
+}
+
+func tailLines(s string, n int) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
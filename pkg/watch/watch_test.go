@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_CapturesOutputAndExitCode(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	r := Run(context.Background(), `printf '\033[31mred\033[0m\r\n'; exit 3`, t.TempDir())
+	if r.Err != nil {
+		t.Fatalf("unexpected error: %v", r.Err)
+	}
+	if r.Output != "red" {
+		t.Fatalf("expected plain output %q, got %q", "red", r.Output)
+	}
+	if r.ExitCode != 3 || !r.Failed() {
+		t.Fatalf("expected exit code 3, got %d", r.ExitCode)
+	}
+}
+
+func TestRun_RunsInDir(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+	r := Run(context.Background(), "pwd -P", dir)
+	if r.Failed() || !strings.HasSuffix(r.Output, strings.TrimPrefix(dir, "/private")) {
+		t.Fatalf("expected output to be %s, got %q (exit %d, err %v)", dir, r.Output, r.ExitCode, r.Err)
+	}
+}
+
+func TestRun_CancelStopsCommand(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	r := Run(ctx, "sleep 30 | cat", t.TempDir())
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("expected cancel to stop the command promptly")
+	}
+	if !errors.Is(r.Err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", r.Err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize("a  \r\nprogress 10%\rprogress 100%\n\x1b[1mb\x1b[0m\n\n")
+	if want := "a\nprogress 100%\nb"; got != want {
+		t.Fatalf("Normalize = %q, want %q", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ok := Result{Output: "PASS"}
+	okOther := Result{Output: "PASS 2"}
+	fail := Result{Output: "FAIL", ExitCode: 1}
+
+	tests := []struct {
+		name string
+		prev *Result
+		cur  Result
+		want Change
+	}{
+		{"first", nil, ok, FirstRun},
+		{"same", &ok, ok, Unchanged},
+		{"output", &ok, okOther, OutputChanged},
+		{"failing", &ok, fail, StartedFailing},
+		{"recovered", &fail, ok, Recovered},
+		{"still failing differently", &fail, Result{Output: "FAIL 2", ExitCode: 1}, OutputChanged},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.prev, tt.cur); got != tt.want {
+			t.Errorf("%s: Compare = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("a\nb\nc", "a\nB\nc\nd", 0)
+	want := "- b\n+ B\n+ d"
+	if got != want {
+		t.Fatalf("Diff =\n%s\nwant\n%s", got, want)
+	}
+
+	capped := Diff("", "1\n2\n3\n4", 2)
+	if capped != "+ 1\n+ 2\n... 2 more changed lines" {
+		t.Fatalf("unexpected capped diff:\n%s", capped)
+	}
+}